@@ -0,0 +1,231 @@
+package main
+
+import (
+	"auth-service/internal/cliout"
+	"auth-service/internal/config"
+	"auth-service/internal/service/backup"
+	"auth-service/internal/service/redis"
+	"context"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// backupKeyEnv - переменная окружения с ключом шифрования резервной копии (32 байта в base64).
+// Ключ не передается флагом, чтобы не попадать в историю команд и список процессов.
+const backupKeyEnv = "AUTH_BACKUP_KEY"
+
+// runCommand выполняет служебную команду, если она указана первым аргументом:
+//
+//	auth-service backup --config ./config.yaml --out ./auth.bak
+//	auth-service restore --config ./config.yaml --in ./auth.bak [--replace]
+//	auth-service migrate-keys --config ./config.yaml --from auth: --to auth2:
+//	auth-service export-bundle --config ./config.yaml --out ./bundle.jwt
+//	auth-service vault-ls --config ./config.yaml [--mount secret] [--path auth]
+//
+// Итог команды печатается в stdout в формате из флага -o (table, json или yaml), журнал - в stderr.
+// Возвращает false, если аргументы не являются командой и нужно запускать сервер.
+func runCommand(ctx context.Context, args []string) (bool, error) {
+	if len(args) == 0 {
+		return false, nil
+	}
+
+	switch args[0] {
+	case "backup":
+		return true, runBackup(ctx, args[1:])
+	case "restore":
+		return true, runRestore(ctx, args[1:])
+	case "migrate-keys":
+		return true, runMigrateKeys(ctx, args[1:])
+	case "export-bundle":
+		return true, runExportBundle(ctx, args[1:])
+	case "vault-ls":
+		return true, runVaultList(ctx, args[1:])
+	default:
+		return false, nil
+	}
+}
+
+// backupResult - итог команды backup.
+type backupResult struct {
+	File  string `json:"file"`
+	Match string `json:"match"`
+	Keys  int    `json:"keys"`
+}
+
+// restoreResult - итог команды restore.
+type restoreResult struct {
+	File      string    `json:"file"`
+	CreatedAt time.Time `json:"created_at"`
+	Restored  int       `json:"restored"`
+	Skipped   int       `json:"skipped"`
+}
+
+// runBackup выгружает состояние сервиса из Redis в зашифрованный файл.
+func runBackup(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	configPath := fs.String("config", "./config.yaml", "path to config file")
+	out := fs.String("out", "", "path to backup file")
+	match := fs.String("match", backup.DefaultMatch, "pattern of redis keys to back up")
+	output := cliout.FormatTable
+	fs.Var(&output, "o", cliout.FlagUsage)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return errors.New("backup: --out is required")
+	}
+
+	key, err := backupKey()
+	if err != nil {
+		return err
+	}
+
+	client, stop, err := commandRedis(ctx, *configPath)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	b, err := backup.New(backup.WithClient(client), backup.WithMatch(*match))
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := b.Dump(ctx)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(*out, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("backup: error create file: %w", err)
+	}
+
+	if err := backup.Encode(f, snapshot, key); err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("backup: error close file: %w", err)
+	}
+
+	return cliout.Write(os.Stdout, output, backupResult{File: *out, Match: *match, Keys: len(snapshot.Entries)})
+}
+
+// runRestore загружает состояние сервиса из зашифрованного файла в Redis.
+func runRestore(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	configPath := fs.String("config", "./config.yaml", "path to config file")
+	in := fs.String("in", "", "path to backup file")
+	replace := fs.Bool("replace", false, "overwrite existing keys")
+	output := cliout.FormatTable
+	fs.Var(&output, "o", cliout.FlagUsage)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *in == "" {
+		return errors.New("restore: --in is required")
+	}
+
+	key, err := backupKey()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(*in)
+	if err != nil {
+		return fmt.Errorf("restore: error open file: %w", err)
+	}
+	defer f.Close() //nolint:errcheck // файл только читается
+
+	snapshot, err := backup.Decode(f, key)
+	if err != nil {
+		return err
+	}
+
+	client, stop, err := commandRedis(ctx, *configPath)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	b, err := backup.New(backup.WithClient(client))
+	if err != nil {
+		return err
+	}
+
+	res, err := b.Restore(ctx, snapshot, *replace)
+	if err != nil {
+		return err
+	}
+
+	return cliout.Write(os.Stdout, output, restoreResult{
+		File:      *in,
+		CreatedAt: snapshot.CreatedAt,
+		Restored:  res.Restored,
+		Skipped:   res.Skipped,
+	})
+}
+
+func backupKey() ([]byte, error) {
+	raw := os.Getenv(backupKeyEnv)
+	if raw == "" {
+		return nil, fmt.Errorf("%s is required", backupKeyEnv)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be base64: %w", backupKeyEnv, err)
+	}
+
+	if len(key) != backup.KeySize {
+		return nil, fmt.Errorf("%s: %w", backupKeyEnv, backup.ErrInvalidKey)
+	}
+
+	return key, nil
+}
+
+// commandRedis подключается к Redis из конфигурации сервиса.
+func commandRedis(ctx context.Context, configPath string) (goredis.UniversalClient, func(), error) {
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	svc, err := redis.New(redis.WithCfg(&cfg.Redis))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := svc.Connect(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	stop := func() {
+		if err := svc.Stop(ctx); err != nil {
+			logrus.WithError(err).Warn("error stop redis")
+		}
+	}
+
+	client, err := svc.Client()
+	if err != nil {
+		stop()
+
+		return nil, nil, err
+	}
+
+	return client, stop, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"auth-service/internal/cliout"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRedisConfig записывает минимальную конфигурацию сервиса с Redis из miniredis.
+func writeRedisConfig(t *testing.T, mr *miniredis.Miniredis) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := fmt.Sprintf(`log_level: "info"
+server:
+  port: 8080
+  shutdown_timeout: 1s
+vault:
+  address: "https://localhost:8200"
+  token: "vault-token"
+redis:
+  type: "single"
+  host: "localhost"
+  port: %s
+`, mr.Port())
+
+	require.NoError(t, os.WriteFile(path, []byte(cfg), 0o600))
+
+	return path
+}
+
+//nolint:paralleltest // тест меняет переменные окружения
+func TestBackupRestore(t *testing.T) {
+	t.Setenv(backupKeyEnv, base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)))
+
+	src := miniredis.RunT(t)
+	src.HSet("auth:apikey:bot", "secret_hash", "abc")
+	src.Set("auth:session:1", "payload")
+
+	dst := miniredis.RunT(t)
+	file := filepath.Join(t.TempDir(), "auth.bak")
+
+	handled, err := runCommand(t.Context(), []string{"backup", "--config", writeRedisConfig(t, src), "--out", file})
+	require.True(t, handled)
+	require.NoError(t, err)
+
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	handled, err = runCommand(t.Context(), []string{"restore", "--config", writeRedisConfig(t, dst), "--in", file})
+	require.True(t, handled)
+	require.NoError(t, err)
+
+	assert.Equal(t, "abc", dst.HGet("auth:apikey:bot", "secret_hash"))
+
+	got, err := dst.Get("auth:session:1")
+	require.NoError(t, err)
+	assert.Equal(t, "payload", got)
+}
+
+//nolint:paralleltest // тест меняет переменные окружения
+func TestRunCommand_Errors(t *testing.T) {
+	handled, err := runCommand(t.Context(), nil)
+	assert.False(t, handled)
+	require.NoError(t, err)
+
+	handled, err = runCommand(t.Context(), []string{"-config", "./config.yaml"})
+	assert.False(t, handled)
+	require.NoError(t, err)
+
+	_, err = runCommand(t.Context(), []string{"backup"})
+	require.ErrorContains(t, err, "--out is required")
+
+	_, err = runCommand(t.Context(), []string{"restore"})
+	require.ErrorContains(t, err, "--in is required")
+
+	_, err = runCommand(t.Context(), []string{"export-bundle"})
+	require.ErrorContains(t, err, "--out is required")
+
+	for _, command := range []string{"backup", "restore", "migrate-keys", "export-bundle"} {
+		_, err = runCommand(t.Context(), []string{command, "-o", "xml"})
+		require.ErrorContains(t, err, cliout.ErrUnknownFormat.Error(), command)
+	}
+
+	t.Setenv(backupKeyEnv, "")
+
+	_, err = runCommand(t.Context(), []string{"backup", "--out", "x"})
+	require.ErrorContains(t, err, backupKeyEnv+" is required")
+
+	t.Setenv(backupKeyEnv, base64.StdEncoding.EncodeToString([]byte("short")))
+
+	_, err = runCommand(t.Context(), []string{"restore", "--in", "x"})
+	require.ErrorContains(t, err, "must be 32 bytes")
+}
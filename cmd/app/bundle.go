@@ -0,0 +1,109 @@
+package main
+
+import (
+	"auth-service/internal/cliout"
+	"auth-service/internal/config"
+	"auth-service/internal/service/job"
+	"auth-service/internal/service/revocation"
+	"auth-service/internal/service/token"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// exportBundleResult - итог команды export-bundle.
+type exportBundleResult struct {
+	File        string    `json:"file"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Keys        int       `json:"keys"`
+	Revocations int       `json:"revocations"`
+	Deactivated int       `json:"deactivated"`
+}
+
+// runExportBundle выгружает подписанный пакет для проверки токенов без обращения к сервису:
+//
+//	auth-service export-bundle --config ./config.yaml --out ./bundle.jwt
+//
+// Срок действия и аудитории пакета берутся из token.bundle, выгрузка работает и при выключенном
+// административном эндпоинте. Отметки об отзыве читаются из Redis, если включен отзыв токенов.
+func runExportBundle(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export-bundle", flag.ContinueOnError)
+	configPath := fs.String("config", "./config.yaml", "path to config file")
+	out := fs.String("out", "", "path to bundle file")
+	output := cliout.FormatTable
+	fs.Var(&output, "o", cliout.FlagUsage)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return errors.New("export-bundle: --out is required")
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	vaultClient, stop, err := commandVault(ctx, cfg.Vault)
+	if err != nil {
+		return fmt.Errorf("export-bundle: %w", err)
+	}
+	defer stop()
+
+	keyOpts := []token.KeysOption{token.WithKVReader(vaultClient)}
+	if cfg.Token.KeysPath != "" {
+		keyOpts = append(keyOpts, token.WithKeysPath(cfg.Token.KeysPath))
+	}
+
+	keys, err := token.NewVaultKeys(keyOpts...)
+	if err != nil {
+		return err
+	}
+
+	var revocations *revocation.Service
+
+	if cfg.Revocation.Enabled {
+		client, stop, err := commandRedis(ctx, *configPath)
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		jobs, err := job.New(job.WithClient(client))
+		if err != nil {
+			return err
+		}
+
+		revocations, err = revocation.New(revocation.WithClient(client), revocation.WithJobs(jobs))
+		if err != nil {
+			return err
+		}
+	}
+
+	exporter, err := newBundleExporter(cfg.Token.Bundle, cfg.Server.ExternalURL, keys, revocations)
+	if err != nil {
+		return err
+	}
+
+	raw, b, err := exporter.Export(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(*out, []byte(raw), 0o600); err != nil {
+		return fmt.Errorf("export-bundle: error write file: %w", err)
+	}
+
+	return cliout.Write(os.Stdout, output, exportBundleResult{
+		File:        *out,
+		ExpiresAt:   b.ExpiresAt.Time,
+		Keys:        len(b.Kids),
+		Revocations: len(b.RevokedBefore),
+		Deactivated: len(b.Deactivated),
+	})
+}
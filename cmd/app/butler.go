@@ -1,12 +1,12 @@
 package main
 
 import (
+	"auth-service/internal/service/lifecycle"
 	"context"
+	"errors"
 	"fmt"
-	"reflect"
-	"runtime"
-	"strings"
 	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -16,46 +16,121 @@ type Butler struct {
 
 	// Для отслеживания количества запущенных горутин
 	wg sync.WaitGroup
+
+	// Для отчета о запуске
+	startedAt  time.Time
+	mu         sync.Mutex
+	components []ComponentReport
+
+	// Хуки, вызываемые при остановке сервиса
+	hooks []shutdownHook
+
+	// Состояние запущенных компонентов, может быть nil
+	lifecycle *lifecycle.Tracker
 }
 
 func NewButler() *Butler {
 	return &Butler{
 		BuildInfo: ReadBuildInfo(),
+		startedAt: time.Now(),
 	}
 }
 
-func (b *Butler) start(caller func() error) {
+// start запускает компонент name в отдельной горутине. Если задан трекер состояния,
+// отмечает в нем запуск, штатное завершение или ошибку компонента.
+func (b *Butler) start(name string, caller func() error) {
 	b.wg.Add(1)
 
+	if b.lifecycle != nil {
+		b.lifecycle.Starting(name)
+	}
+
 	go func() {
 		defer b.wg.Done()
 
-		fn := runtime.FuncForPC(reflect.ValueOf(caller).Pointer()).Name()
-
-		if b.BuildInfo.Name != "" {
-			fn = strings.TrimPrefix(fn, b.BuildInfo.Name+"/")
+		if b.lifecycle != nil {
+			b.lifecycle.Running(name)
 		}
 
 		if err := caller(); err != nil {
-			logrus.WithError(err).Errorf("error in %s", fn)
+			logrus.WithError(err).Errorf("error in %s", name)
+
+			if b.lifecycle != nil {
+				b.lifecycle.Failed(name, err)
+			}
+
 			return
 		}
+
+		if b.lifecycle != nil {
+			b.lifecycle.Stopped(name)
+		}
 	}()
 }
 
-type stopper interface {
-	Stop(ctx context.Context) error
+// defaultShutdownHookTimeout - таймаут хука остановки, если он не указан при регистрации.
+const defaultShutdownHookTimeout = 5 * time.Second
+
+// ShutdownHook - функция, вызываемая при остановке сервиса.
+type ShutdownHook func(ctx context.Context) error
+
+type shutdownHook struct {
+	name    string
+	fn      ShutdownHook
+	timeout time.Duration
+}
+
+// RegisterShutdownHook регистрирует функцию, которая будет вызвана при остановке сервиса.
+// Хуки вызываются в порядке, обратном порядку регистрации (как defer), каждый со своим таймаутом.
+// Если timeout не больше 0, используется таймаут по умолчанию.
+func (b *Butler) RegisterShutdownHook(name string, fn ShutdownHook, timeout time.Duration) error {
+	if name == "" {
+		return errors.New("shutdown hook name is required")
+	}
+
+	if fn == nil {
+		return fmt.Errorf("shutdown hook %s: function is required", name)
+	}
+
+	if timeout <= 0 {
+		timeout = defaultShutdownHookTimeout
+	}
+
+	b.mu.Lock()
+	b.hooks = append(b.hooks, shutdownHook{name: name, fn: fn, timeout: timeout})
+	b.mu.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"name":    name,
+		"timeout": timeout,
+	}).Debug("registered shutdown hook")
+
+	return nil
+}
+
+// shutdown вызывает зарегистрированные хуки остановки в обратном порядке.
+// Ошибка одного хука не мешает вызову остальных.
+func (b *Butler) shutdown(ctx context.Context) {
+	b.mu.Lock()
+	hooks := b.hooks
+	b.hooks = nil
+	b.mu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		b.runHook(ctx, hooks[i])
+	}
 }
 
-func (b *Butler) stop(ctx context.Context, svc stopper) {
-	name := fmt.Sprintf("%T", svc)
+func (b *Butler) runHook(ctx context.Context, hook shutdownHook) {
+	hookCtx, cancel := context.WithTimeout(ctx, hook.timeout)
+	defer cancel()
 
-	if err := svc.Stop(ctx); err != nil {
-		logrus.WithError(err).Errorf("dirty shutdown %s", name)
+	if err := hook.fn(hookCtx); err != nil {
+		logrus.WithError(err).Errorf("dirty shutdown %s", hook.name)
 		return
 	}
 
-	logrus.WithField("name", name).Info("successfully stopped")
+	logrus.WithField("name", hook.name).Info("successfully stopped")
 }
 
 // waitForAll ждет завершения всех запущенных горутин.
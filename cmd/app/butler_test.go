@@ -1,11 +1,13 @@
 package main
 
 import (
+	"auth-service/internal/service/lifecycle"
 	"context"
 	"errors"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -34,7 +36,7 @@ func TestStart(t *testing.T) {
 	}
 
 	butler := NewButler()
-	butler.start(start)
+	butler.start("test", start)
 
 	select {
 	case <-ch:
@@ -44,47 +46,150 @@ func TestStart(t *testing.T) {
 	}
 }
 
-type mockStopper struct {
-	ch  chan bool
-	err error
-}
+func TestStart_Lifecycle(t *testing.T) {
+	t.Parallel()
 
-func (m *mockStopper) Stop(_ context.Context) error {
-	m.ch <- true
-	return m.err
-}
+	tracker, err := lifecycle.New(lifecycle.WithRegisterer(prometheus.NewRegistry()))
+	require.NoError(t, err)
 
-func TestStop(t *testing.T) {
-	t.Parallel()
+	butler := NewButler()
+	butler.lifecycle = tracker
 
-	ctx := t.Context()
+	butler.start("rotation", func() error { return errors.New("vault is sealed") })
+	butler.start("server", func() error { return nil })
+	butler.waitForAll()
 
-	type testCase struct {
-		name    string
-		stopper stopper
-		err     error
-	}
+	components := tracker.Components()
+	require.Len(t, components, 2)
 
-	tests := []testCase{
-		{name: "positive case", stopper: &mockStopper{ch: make(chan bool), err: nil}, err: nil},
-		{name: "negative case", stopper: &mockStopper{ch: make(chan bool), err: errors.New("test error")}, err: errors.New("test error")},
+	assert.Equal(t, lifecycle.StateFailed, components[0].State)
+	assert.Equal(t, "vault is sealed", components[0].LastError)
+	assert.Equal(t, lifecycle.StateStopped, components[1].State)
+
+	// повторный запуск компонента считается перезапуском
+	butler.start("rotation", func() error { return nil })
+	butler.waitForAll()
+
+	components = tracker.Components()
+	assert.Equal(t, lifecycle.StateStopped, components[0].State)
+	assert.Equal(t, uint64(1), components[0].Restarts)
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestRegisterShutdownHook(t *testing.T) {
+	t.Parallel()
+
+	noop := func(context.Context) error { return nil }
+
+	tests := []struct {
+		name        string
+		hookName    string
+		fn          ShutdownHook
+		timeout     time.Duration
+		wantTimeout time.Duration
+		wantErr     require.ErrorAssertionFunc
+	}{
+		{
+			name:        "positive case",
+			hookName:    "kafka",
+			fn:          noop,
+			timeout:     time.Second,
+			wantTimeout: time.Second,
+			wantErr:     require.NoError,
+		},
+		{
+			name:        "positive case: default timeout",
+			hookName:    "kafka",
+			fn:          noop,
+			wantTimeout: defaultShutdownHookTimeout,
+			wantErr:     require.NoError,
+		},
+		{
+			name:    "error case: empty name",
+			fn:      noop,
+			wantErr: require.Error,
+		},
+		{
+			name:     "error case: nil function",
+			hookName: "kafka",
+			wantErr:  require.Error,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			go func() {
-				select {
-				case <-tt.stopper.(*mockStopper).ch:
-					assert.True(t, true)
-				case <-time.After(1 * time.Second):
-					assert.Fail(t, "stop function did not return")
-				}
-			}()
-
 			butler := NewButler()
-			butler.stop(ctx, tt.stopper)
+
+			err := butler.RegisterShutdownHook(tt.hookName, tt.fn, tt.timeout)
+			tt.wantErr(t, err)
+
+			if err != nil {
+				assert.Empty(t, butler.hooks)
+				return
+			}
+
+			require.Len(t, butler.hooks, 1)
+			assert.Equal(t, tt.hookName, butler.hooks[0].name)
+			assert.Equal(t, tt.wantTimeout, butler.hooks[0].timeout)
 		})
 	}
 }
+
+func TestShutdown(t *testing.T) {
+	t.Parallel()
+
+	butler := NewButler()
+
+	var order []string
+
+	hook := func(name string, err error) ShutdownHook {
+		return func(ctx context.Context) error {
+			_, ok := ctx.Deadline()
+			assert.True(t, ok, "hook context must have deadline")
+
+			order = append(order, name)
+
+			return err
+		}
+	}
+
+	require.NoError(t, butler.RegisterShutdownHook("vault", hook("vault", nil), time.Second))
+	require.NoError(t, butler.RegisterShutdownHook("redis", hook("redis", errors.New("test error")), time.Second))
+	require.NoError(t, butler.RegisterShutdownHook("exporter", hook("exporter", nil), time.Second))
+
+	butler.shutdown(t.Context())
+
+	// хуки вызываются в обратном порядке, ошибка одного не мешает остальным
+	assert.Equal(t, []string{"exporter", "redis", "vault"}, order)
+	assert.Empty(t, butler.hooks)
+
+	// повторный вызов ничего не делает
+	butler.shutdown(t.Context())
+	assert.Len(t, order, 3)
+}
+
+func TestShutdown_Timeout(t *testing.T) {
+	t.Parallel()
+
+	butler := NewButler()
+
+	done := make(chan error, 1)
+
+	require.NoError(t, butler.RegisterShutdownHook("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		done <- ctx.Err()
+
+		return ctx.Err()
+	}, 10*time.Millisecond))
+
+	butler.shutdown(t.Context())
+
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		require.Fail(t, "hook timeout was not applied")
+	}
+}
@@ -5,15 +5,77 @@ import (
 	handlerV0 "auth-service/internal/api/v0"
 	"auth-service/internal/config"
 	"auth-service/internal/server"
+	"auth-service/internal/service/abuse"
+	"auth-service/internal/service/apikey"
+	"auth-service/internal/service/auth"
+	"auth-service/internal/service/authz"
+	"auth-service/internal/service/breach"
+	"auth-service/internal/service/bundle"
+	"auth-service/internal/service/canary"
+	"auth-service/internal/service/capture"
+	"auth-service/internal/service/clockdrift"
+	"auth-service/internal/service/codec"
+	"auth-service/internal/service/credpolicy"
+	"auth-service/internal/service/dependency"
+	"auth-service/internal/service/deprecation"
+	"auth-service/internal/service/drain"
+	"auth-service/internal/service/event"
+	"auth-service/internal/service/group"
+	"auth-service/internal/service/janitor"
+	"auth-service/internal/service/job"
+	"auth-service/internal/service/keystats"
+	"auth-service/internal/service/ldap"
+	"auth-service/internal/service/lifecycle"
+	"auth-service/internal/service/loadshed"
+	"auth-service/internal/service/logsampling"
+	"auth-service/internal/service/mail"
+	"auth-service/internal/service/notify"
+	"auth-service/internal/service/oauth"
+	"auth-service/internal/service/peer"
+	"auth-service/internal/service/policy"
+	"auth-service/internal/service/pow"
+	"auth-service/internal/service/qrlogin"
+	"auth-service/internal/service/quota"
+	"auth-service/internal/service/ratelimit"
+	"auth-service/internal/service/readonly"
 	"auth-service/internal/service/redis"
+	"auth-service/internal/service/refresh"
+	"auth-service/internal/service/replication"
+	"auth-service/internal/service/revocation"
+	"auth-service/internal/service/scim"
+	"auth-service/internal/service/securitytxt"
+	"auth-service/internal/service/servercert"
+	"auth-service/internal/service/slo"
+	"auth-service/internal/service/spiffe"
+	"auth-service/internal/service/statekey"
+	"auth-service/internal/service/stats"
+	"auth-service/internal/service/telegram"
+	"auth-service/internal/service/token"
+	"auth-service/internal/service/tracing"
+	"auth-service/internal/service/ttlcheck"
+	"auth-service/internal/service/userstore"
+	"auth-service/internal/service/warmup"
+	"auth-service/internal/service/webauthn"
+	redisstorage "auth-service/internal/storage/redis"
 	"auth-service/internal/storage/vault"
+	"cmp"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"flag"
 	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
-	"syscall"
+	"path"
+	"slices"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	goredis "github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 )
 
@@ -21,10 +83,30 @@ import (
 // @version         1.0
 // @description     API для работы с авторизацией
 // @host            localhost:8080 // Дефолтное значение, будет перезаписано динамически из конфига
+// @securityDefinitions.apikey	AdminToken
+// @in							header
+// @name						Authorization
+// @securityDefinitions.apikey	ApiKey
+// @in							header
+// @name						X-API-Key
+// @securityDefinitions.apikey	BootstrapToken
+// @in							header
+// @name						Authorization
+// @securityDefinitions.apikey	BearerToken
+// @in							header
+// @name						Authorization
 // @basePath        /api/v0 //nolint:godot // swagger комментарии не должны заканчиваться точкой.
 func main() {
 	ctx := context.Background()
 
+	if handled, err := runCommand(ctx, os.Args[1:]); handled {
+		if err != nil {
+			logrus.WithError(err).Fatal("command failed")
+		}
+
+		return
+	}
+
 	butler := NewButler()
 
 	configPath := flag.String("config", "./config.yaml", "path to config file")
@@ -49,34 +131,241 @@ func main() {
 	logrus.WithField("level", logrus.GetLevel()).Info("set log level")
 
 	logrus.WithFields(logrus.Fields{
-		"version": butler.BuildInfo.Version,
-		"commit":  butler.BuildInfo.GitCommit,
-		"date":    butler.BuildInfo.BuildDate,
+		"version":     butler.BuildInfo.Version,
+		"commit":      butler.BuildInfo.GitCommit,
+		"date":        butler.BuildInfo.BuildDate,
+		"environment": config.Env(),
 	}).Info("starting service")
 	defer logrus.Info("shutdown")
 
-	notifyCtx, notify := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
+	warnInsecureSettings(config)
+
+	notifyCtx, notify := signal.NotifyContext(ctx, shutdownSignals()...)
 	defer notify()
 
-	handlerV0 := initHandlerV0(butler.BuildInfo)
-	server := initServer(handlerV0, config.Server)
+	// вывод из балансировки (POST /api/v0/admin/drain) завершает процесс так же, как сигнал
+	notifyCtx, exit := context.WithCancel(notifyCtx)
+	defer exit()
 
-	go butler.start(func() error {
-		return server.Start(notifyCtx)
-	})
+	butler.lifecycle = start(lifecycle.New())
+
+	// трассировка создается до клиентов Vault и Redis, а ее хук остановки регистрируется первым:
+	// хуки вызываются в обратном порядке, поэтому спаны остановки остальных компонентов тоже отправятся
+	tracer := initTracing(config.Tracing, butler.BuildInfo.Version)
+
+	if tracer != nil {
+		registerShutdownHook(butler, "tracing", tracer.Shutdown, tracingShutdownTimeout(config))
+	}
+
+	// сброс нагрузки создается до клиента Vault: адаптивное ограничение выдачи токенов следит за его задержкой
+	shedder := initLoadShedding(config.LoadShedding)
 
-	vaultClient := initVaultClient(config.Vault)
+	started := time.Now()
+	vaultExtra := append(vaultLatency(shedder), vaultRenewal(config.Vault.Renewal, exit)...)
+	vaultExtra = append(vaultExtra, vaultTracing(tracer)...)
+	vaultClient := initVaultClient(config.Vault, vaultExtra...)
 
 	if err := vaultClient.Connect(); err != nil {
 		logrus.WithError(err).Fatal("failed to connect to vault")
 	}
 
-	defer butler.stop(ctx, vaultClient)
+	registerShutdownHook(butler, "vault", vaultClient.Stop, config.Server.ShutdownTimeout)
+	checkVaultCapabilities(ctx, config, vaultClient)
+
+	if config.Vault.Renewal.Enabled {
+		go butler.start("vault-token-renewal", func() error {
+			return vaultClient.WatchToken(notifyCtx)
+		})
+	}
+	butler.track("vault", config.Vault, started, config.Vault.Address)
+
+	started = time.Now()
+	// режим только для чтения создается до Redis: он следит за результатами записей
+	readOnly := initReadOnly(config.ReadOnly)
+	redis := initRedisStorage(ctx, config.Redis, append(readOnlyHooks(config.ReadOnly, readOnly), redisTracing(tracer)...)...)
+
+	registerShutdownHook(butler, "redis", redis.Stop, config.Server.ShutdownTimeout)
+	startService(prometheus.Register(redis.Collector()), "redis metrics")
+	butler.track("redis", config.Redis, started, redisAddrs(config.Redis)...)
+
+	started = time.Now()
+	users := initUserStore(ctx, config.UserStore, vaultClient)
+	deps := initDependencies(config.Dependencies, vaultClient, redis, users)
+
+	go butler.start("dependencies", func() error {
+		return deps.Start(notifyCtx)
+	})
+
+	butler.track("dependencies", config.Dependencies, started)
+
+	if clock := initClockDrift(config.Dependencies.ClockDrift, vaultClient, redis); clock != nil {
+		go butler.start("clock-drift", func() error {
+			return clock.Start(notifyCtx)
+		})
+	}
+
+	if janitor := initJanitor(config.Redis.Janitor, redis); janitor != nil {
+		go butler.start("redis-janitor", func() error {
+			return janitor.Start(notifyCtx)
+		})
+	}
+
+	started = time.Now()
+	capture := initCapture(config.Admin.Capture)
+	keyStats := start(keystats.New())
+	keys := initSigningKeys(config.Token, vaultClient, prometheus.DefaultRegisterer)
+	jobs := initJobs(config.Jobs, redis)
+	events := initEvents(config.Events, redis)
+	revocations := initRevocation(config.Revocation, config.Token.Grace.Period, config.Redis.TTL.Slack, redis, jobs, events)
+
+	if replicator := initReplication(ctx, butler, config, revocations); replicator != nil {
+		go butler.start("replication", func() error {
+			return replicator.Start(notifyCtx)
+		})
+	}
+
+	groups := initGroups(redis)
+	analytics := initStats(config.Admin.Stats, redis)
+	issuer := initIssuer(config.Token, config.Server.ExternalURL, config.Sandbox, keys, keyStats, groups, analytics)
+	records := initCodec(config.Redis)
+	// семейства refresh токенов - сессии входа, их отзыв проверяет validator
+	refreshTokens := initRefresh(config.Token.Refresh, config.Redis.TTL.Slack, redis, records, issuer, analytics)
+	validator := initValidator(config.Token, config.Server.ExternalURL, keys, keyStats, revocations, refreshTokens)
+
+	if ttlChecker := initTTLCheck(config.Redis.TTL, redis, revocations, refreshTokens); ttlChecker != nil {
+		go butler.start("redis-ttl-check", func() error {
+			return ttlChecker.Start(notifyCtx)
+		})
+	}
+
+	if rotation := initKeyRotation(config.Token.Rotation, keys, issuer, validator); rotation != nil {
+		go butler.start("signing-key-rotation", func() error {
+			return rotation.Start(notifyCtx)
+		})
+	}
+	policies := initPolicy(ctx, config.Authz.Policy, vaultClient)
+
+	if policies != nil {
+		go butler.start("policies", func() error {
+			return policies.Start(notifyCtx)
+		})
+	}
+
+	serverCert := initServerCert(ctx, config.Server.TLS.VaultPKI, vaultClient)
+
+	if serverCert != nil {
+		go butler.start("server-certificate", func() error {
+			return serverCert.Start(notifyCtx)
+		})
+	}
+
+	telegramSecrets := initTelegram(ctx, config.Telegram, vaultClient)
+
+	if telegramSecrets != nil {
+		go butler.start("telegram-secrets", func() error {
+			return telegramSecrets.Start(notifyCtx)
+		})
+	}
+
+	authz := initAuthz(config.Authz, groups, policies)
+	accounts := initSCIM(config.Admin.SCIM, redis, revocations)
+	sender := initMail(config.Mail, vaultClient)
+	stateKeys := initStateKeys(ctx, config.StateKeys, vaultClient)
+
+	if stateKeys != nil {
+		go butler.start("state-keys", func() error {
+			return stateKeys.Start(notifyCtx)
+		})
+	}
+
+	federation := initOAuth(config.OAuth, redis, vaultClient, issuer, sender, revocations, stateKeys)
+	svc := services{
+		capture:     capture,
+		keyStats:    keyStats,
+		validator:   validator,
+		issuer:      issuer,
+		groups:      groups,
+		authz:       authz,
+		quota:       initQuota(config.Quota, config.RateLimit, redis),
+		logSampling: initLogSampling(config.Admin.LogSampling, redis),
+		apiKeys:     initAPIKeys(config.Admin.APIKeys, config.Sandbox, redis, vaultClient),
+		spiffe:      initSPIFFE(ctx, config.SPIFFE, vaultClient),
+		serverCert:  serverCert,
+		lifecycle:   butler.lifecycle,
+		redis:       redis,
+		jobs:        jobs,
+		revocations: revocations,
+		qrLogin:     initQRLogin(config.QRLogin, redis, issuer),
+		passkeys:    initWebAuthn(config.WebAuthn, redis, issuer),
+		refresh:     refreshTokens,
+		stats:       analytics,
+		bundles:     initBundles(config.Token.Bundle, config.Server.ExternalURL, keys, revocations),
+		oauth:       federation,
+		directory:   initLDAP(ctx, config.Admin.LDAP, vaultClient, issuer, accounts),
+		scim:        accounts,
+		abuse:       initAbuse(config.Abuse, redis, events),
+		breaches:    initBreach(config.PasswordBreach),
+		credentials: initCredentialsPolicy(config.CredentialsPolicy),
+		notifier:    initNotify(config.Notifications, redis, sender, federation, events),
+		shedder:     shedder,
+		peers:       initPeers(ctx, config.Peers),
+		drainer:     initDrain(config.Server.Drain, exit),
+		readOnly:    readOnly,
+		tracing:     tracer,
+		users:       initUserCache(config.UserStore.Cache, redis, users),
+	}
+
+	svc.telegram = initTelegramLogin(config.Telegram.Login, telegramSecrets, issuer, svc.users)
+	svc.canary = initCanary(config.Token.Canary, issuer, validator, svc.bundles, keys)
+
+	if svc.canary != nil {
+		go butler.start("token-canary", func() error {
+			return svc.canary.Start(notifyCtx)
+		})
+	}
+
+	if svc.peers != nil {
+		go butler.start("peer-jwks", func() error {
+			return svc.peers.Start(notifyCtx)
+		})
+	}
+
+	go butler.start("job-worker", func() error {
+		return jobs.Start(notifyCtx)
+	})
+
+	if svc.logSampling != nil {
+		go butler.start("log-sampling", func() error {
+			return svc.logSampling.Start(notifyCtx)
+		})
+	}
+
+	handlerV0 := initHandlerV0(butler.BuildInfo, config.Server.Debug.HideVersion, deps, svc)
+	server := initServer(handlerV0, config, deps, svc)
+
+	// прогрев до запуска сервера: порт начинает слушаться, когда ключи и соединения уже готовы
+	if runner := initWarmup(config.Startup.Warmup, keys, redis, issuer, validator); runner != nil {
+		warmupStarted := time.Now()
+
+		if err := runner.Run(ctx); err != nil {
+			if config.Startup.Warmup.Required {
+				logrus.WithError(err).Fatal("warm-up failed")
+			}
+
+			logrus.WithError(err).Warn("warm-up failed, starting anyway")
+		}
+
+		butler.track("warmup", config.Startup.Warmup, warmupStarted)
+	}
+
+	go butler.start("server", func() error {
+		return server.Start(notifyCtx)
+	})
 
-	redis := initRedisStorage(ctx, config.Redis)
-	defer butler.stop(ctx, redis)
+	butler.track("server", config.Server, started, fmt.Sprintf(":%d", config.Server.Port))
 
 	logrus.Info("all services started")
+	butler.startupComplete(config.Startup.ReportPath)
 
 	// Ждем сигнал завершения
 	<-notifyCtx.Done()
@@ -84,10 +373,63 @@ func main() {
 
 	// Ждем завершения всех горутин
 	butler.waitForAll()
+
+	// Вызываем хуки остановки в обратном порядке
+	butler.shutdown(ctx)
 	logrus.Info("all services stopped")
 }
 
-func initHandlerV0(buildInfo *BuildInfo) *handlerV0.Handler {
+// services - сервисы, которые используют хендлеры и сервер API.
+type services struct {
+	capture   *capture.Capture
+	keyStats  *keystats.Tracker
+	stats     *stats.Service
+	bundles   *bundle.Exporter
+	validator *token.Validator
+	issuer    *token.Issuer
+	groups    *group.Service
+	authz     *authz.Service
+	quota     *quota.Service
+	apiKeys   *apikey.Service
+	spiffe    *spiffe.Service
+
+	serverCert *servercert.Manager
+
+	logSampling *logsampling.Sampler
+
+	lifecycle *lifecycle.Tracker
+	redis     *redis.Service
+	drainer   *drain.Drainer
+	readOnly  *readonly.Mode
+	canary    *canary.Checker
+
+	jobs        *job.Service
+	revocations *revocation.Service
+
+	qrLogin  *qrlogin.Service
+	passkeys *webauthn.Service
+	oauth    *oauth.Service
+	refresh  *refresh.Service
+
+	directory *ldap.Service
+	scim      *scim.Service
+
+	abuse       *abuse.Service
+	breaches    *breach.Checker
+	credentials *credpolicy.Policy
+	notifier    *notify.Service
+
+	shedder *loadshed.Shedder
+	peers   *peer.TrustStore
+	tracing *tracing.Provider
+
+	// users - внешний сервис пользователей (через кэш, если он включен) или nil
+	users userstore.Store
+
+	telegram *telegram.Authenticator
+}
+
+func initHandlerV0(buildInfo *BuildInfo, hideVersion bool, deps *dependency.Registry, svc services) *handlerV0.Handler {
 	logrus.WithFields(logrus.Fields{
 		"version":   buildInfo.Version,
 		"buildDate": buildInfo.BuildDate,
@@ -99,79 +441,2201 @@ func initHandlerV0(buildInfo *BuildInfo) *handlerV0.Handler {
 			handlerV0.WithVersion(buildInfo.Version),
 			handlerV0.WithBuildDate(buildInfo.BuildDate),
 			handlerV0.WithGitCommit(buildInfo.GitCommit),
+			handlerV0.WithHideVersion(hideVersion),
+			handlerV0.WithCapture(svc.capture),
+			handlerV0.WithKeyStats(svc.keyStats),
+			handlerV0.WithStats(svc.stats),
+			handlerV0.WithBundles(svc.bundles),
+			handlerV0.WithValidator(svc.validator),
+			handlerV0.WithIssuer(svc.issuer),
+			handlerV0.WithGroups(svc.groups),
+			handlerV0.WithAuthz(svc.authz),
+			handlerV0.WithQuota(svc.quota),
+			handlerV0.WithAPIKeys(svc.apiKeys),
+			handlerV0.WithSPIFFE(svc.spiffe),
+			handlerV0.WithLogSampling(svc.logSampling),
+			handlerV0.WithLifecycle(svc.lifecycle),
+			handlerV0.WithRedis(svc.redis),
+			handlerV0.WithDrainer(svc.drainer),
+			handlerV0.WithReadOnly(svc.readOnly),
+			handlerV0.WithCanary(svc.canary),
+			handlerV0.WithDependencies(deps),
+			handlerV0.WithJobs(svc.jobs),
+			handlerV0.WithRevocations(svc.revocations),
+			handlerV0.WithQRLogin(svc.qrLogin),
+			handlerV0.WithPasskeys(svc.passkeys),
+			handlerV0.WithOAuth(svc.oauth),
+			handlerV0.WithTelegram(svc.telegram),
+			handlerV0.WithRefresh(svc.refresh),
+			handlerV0.WithDirectory(svc.directory),
+			handlerV0.WithSCIM(svc.scim),
+			handlerV0.WithAbuse(svc.abuse),
+			handlerV0.WithBreaches(svc.breaches),
+			handlerV0.WithCredentialsPolicy(svc.credentials),
+			handlerV0.WithNotifier(svc.notifier),
 		),
 	)
 }
 
-func initServer(handlerV0 *handlerV0.Handler, cfg config.Server) *server.Server {
+func initServer(handlerV0 *handlerV0.Handler, config *config.Config, deps *dependency.Registry, svc services) *server.Server {
+	cfg := config.Server
+
 	logrus.WithFields(logrus.Fields{
-		"port":            cfg.Port,
-		"shutdownTimeout": cfg.ShutdownTimeout,
+		"port":             cfg.Port,
+		"shutdownTimeout":  cfg.ShutdownTimeout,
+		"trustedProxies":   cfg.TrustedProxies,
+		"realIPHeader":     cfg.RealIPHeader,
+		"reusePort":        cfg.Listener.ReusePort,
+		"socketActivation": cfg.Listener.SocketActivation,
+		"http2":            cfg.HTTP2.Enabled,
+		"h2c":              cfg.HTTP2.H2C,
+		"debugPort":        cfg.Debug.Port,
+		"hideVersion":      cfg.Debug.HideVersion,
+		"securityTxt":      cfg.SecurityTxt.Enabled,
+		"adminAPI":         config.Admin.Token != "" || svc.directory != nil,
+		"scim":             svc.scim != nil,
+		"abuse":            svc.abuse != nil,
 	}).Info("initializing server")
 
-	return start(
-		server.New(
-			server.WithHandlerV0(handlerV0),
-			server.WithPort(cfg.Port),
-			server.WithShutdownTimeout(cfg.ShutdownTimeout),
-		),
-	)
+	opts := []server.Option{
+		server.WithHandlerV0(handlerV0),
+		server.WithPort(cfg.Port),
+		server.WithShutdownTimeout(cfg.ShutdownTimeout),
+		server.WithReusePort(cfg.Listener.ReusePort),
+		server.WithSocketActivation(cfg.Listener.SocketActivation),
+		server.WithHTTP2(cfg.HTTP2.Enabled),
+		server.WithH2C(cfg.HTTP2.H2C),
+		server.WithDebugPort(cfg.Debug.Port),
+		server.WithHideVersion(cfg.Debug.HideVersion),
+		server.WithDependencies(deps),
+		server.WithTrustedProxies(cfg.TrustedProxies),
+		server.WithRealIPHeader(cfg.RealIPHeader),
+		server.WithAdminToken(config.Admin.Token),
+		server.WithCapture(svc.capture),
+	}
+
+	opts = append(opts, rateLimitOptions(config.RateLimit)...)
+
+	if file := initSecurityTxt(cfg.SecurityTxt); file != nil {
+		opts = append(opts, server.WithSecurityTxt(file))
+	}
+
+	if svc.shedder != nil {
+		opts = append(opts, server.WithLoadShedding(svc.shedder))
+	}
+
+	if svc.readOnly != nil {
+		opts = append(opts, server.WithReadOnly(svc.readOnly))
+	}
+
+	if tracker := initSLO(config.SLO); tracker != nil {
+		opts = append(opts, server.WithSLO(tracker))
+	}
+
+	if pow := initProofOfWork(config.ProofOfWork); pow != nil {
+		opts = append(opts, server.WithProofOfWork(pow, config.ProofOfWork.Routes))
+	}
+
+	if svc.validator != nil {
+		opts = append(opts, server.WithAuthentication(initUserValidator(svc.validator, config.Token.UserAudiences)))
+	}
+
+	if svc.peers != nil {
+		opts = append(opts, server.WithPeerAuth(svc.peers, config.Peers.Routes))
+	}
+
+	if svc.directory != nil {
+		opts = append(opts, server.WithAdminValidator(svc.validator.ForAudience(ldap.Audience)))
+	}
+
+	if svc.scim != nil {
+		opts = append(opts, server.WithSCIMToken(config.Admin.SCIM.TokenSHA256))
+	}
+
+	if svc.quota != nil {
+		opts = append(opts, server.WithQuota(svc.quota))
+	}
+
+	if svc.abuse != nil {
+		opts = append(opts, server.WithAbuse(svc.abuse, config.Abuse.Honeypots))
+	}
+
+	if svc.logSampling != nil {
+		opts = append(opts, server.WithLogSampling(svc.logSampling))
+	}
+
+	if deprecations := initDeprecations(cfg.Deprecations); deprecations != nil {
+		opts = append(opts, server.WithDeprecations(deprecations))
+	}
+
+	if svc.tracing != nil {
+		opts = append(opts, server.WithTracing(svc.tracing.TracerProvider()))
+	}
+
+	if tlsConfig := start(serverTLSConfig(cfg.TLS, svc.serverCert)); tlsConfig != nil {
+		opts = append(opts, server.WithTLSConfig(tlsConfig))
+	}
+
+	return start(server.New(opts...))
+}
+
+// initDeprecations создает реестр устаревших маршрутов. Если устаревших маршрутов нет, возвращает nil.
+func initDeprecations(cfg config.Deprecations) *deprecation.Registry {
+	if len(cfg.Routes) == 0 {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"routes":       len(cfg.Routes),
+		"log_interval": cfg.LogInterval,
+	}).Info("initializing deprecated routes")
+
+	routes := make([]deprecation.Route, 0, len(cfg.Routes))
+
+	for _, r := range cfg.Routes {
+		route := deprecation.Route{Method: r.Method, Path: r.Path, Link: r.Link, Successor: r.Successor}
+
+		since, err := time.Parse(time.DateOnly, r.Since)
+		startService(err, "deprecated route "+r.Path)
+
+		route.Since = since
+
+		if r.Sunset != "" {
+			sunset, err := time.Parse(time.DateOnly, r.Sunset)
+			startService(err, "deprecated route "+r.Path)
+
+			route.Sunset = sunset
+		}
+
+		routes = append(routes, route)
+	}
+
+	opts := []deprecation.Option{deprecation.WithRoutes(routes...)}
+
+	if cfg.LogInterval != 0 {
+		opts = append(opts, deprecation.WithLogInterval(cfg.LogInterval))
+	}
+
+	return start(deprecation.New(opts...))
 }
 
-func initVaultClient(cfg config.Vault) *vault.Client {
+// initSecurityTxt создает содержимое security.txt. Если файл отключен, возвращает nil.
+func initSecurityTxt(cfg config.SecurityTxt) *securitytxt.File {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	expires, err := time.Parse(time.RFC3339, cfg.Expires)
+	if err != nil {
+		logrus.WithError(err).Fatal("invalid security.txt expires")
+	}
+
+	file := start(securitytxt.New(
+		securitytxt.WithContacts(cfg.Contacts),
+		securitytxt.WithExpires(expires),
+		securitytxt.WithEncryption(cfg.Encryption),
+		securitytxt.WithAcknowledgments(cfg.Acknowledgments),
+		securitytxt.WithPreferredLanguages(cfg.PreferredLanguages),
+		securitytxt.WithCanonical(cfg.Canonical),
+		securitytxt.WithPolicy(cfg.Policy),
+	))
+
+	if file.Expired(time.Now()) {
+		logrus.WithField("expires", expires).Warn("security.txt is expired, update server.security_txt.expires")
+	}
+
+	return file
+}
+
+// initLoadShedding создает сброс нагрузки по классам эндпоинтов. Если он отключен, возвращает nil.
+func initLoadShedding(cfg config.LoadShedding) *loadshed.Shedder {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	limits := make(map[string]loadshed.Limit, len(cfg.Classes))
+	for class, limit := range cfg.Classes {
+		l := loadshed.Limit{MaxInFlight: limit.MaxInFlight, MaxQueue: limit.MaxQueue, Budget: limit.Budget}
+
+		if limit.Adaptive.Enabled {
+			if dependency.Class(class) != dependency.ClassIssuance {
+				logrus.WithField("class", class).Fatal("adaptive load shedding is supported only for issuance class")
+			}
+
+			l.Adaptive = &loadshed.Adaptive{
+				MinInFlight: limit.Adaptive.MinInFlight,
+				Tolerance:   limit.Adaptive.Tolerance,
+				Backoff:     limit.Adaptive.Backoff,
+			}
+		}
+
+		limits[class] = l
+	}
+
+	logrus.WithField("classes", limits).Info("initializing load shedding")
+
+	return start(loadshed.New(loadshed.WithLimits(limits)))
+}
+
+// initSLO создает учет SLI по классам эндпоинтов. Если он отключен, возвращает nil.
+func initSLO(cfg config.SLO) *slo.Tracker {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithField("classes", cfg.Classes).Info("initializing slo tracking")
+
+	opts := make([]slo.Option, 0, len(dependency.Classes()))
+
+	for _, class := range dependency.Classes() {
+		objective := cfg.Classes[string(class)]
+
+		opts = append(opts, slo.WithObjective(string(class), slo.Objective{
+			BadStatuses: objective.BadStatuses,
+			Latency:     objective.Latency,
+		}))
+	}
+
+	return start(slo.New(opts...))
+}
+
+// vaultLatency возвращает опции клиента Vault, которые сообщают задержку запросов адаптивному
+// ограничению выдачи токенов.
+func vaultLatency(shedder *loadshed.Shedder) []vault.ClientOption {
+	if shedder == nil {
+		return nil
+	}
+
+	return []vault.ClientOption{
+		vault.WithLatencyObserver(shedder.Observer(string(dependency.ClassIssuance))),
+	}
+}
+
+// initTracing создает трассировку OpenTelemetry, если она включена. Иначе возвращает nil.
+func initTracing(cfg config.Tracing, version string) *tracing.Provider {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"protocol":     cfg.Exporter.Protocol,
+		"endpoint":     cfg.Exporter.Endpoint,
+		"insecure":     cfg.Exporter.Insecure,
+		"sample_ratio": cfg.SampleRatio,
+	}).Info("initializing tracing")
+
+	opts := []tracing.Option{
+		tracing.WithEndpoint(cfg.Exporter.Endpoint),
+		tracing.WithInsecure(cfg.Exporter.Insecure),
+		tracing.WithHeaders(cfg.Exporter.Headers),
+		tracing.WithServiceVersion(version),
+	}
+
+	if cfg.Exporter.Protocol != "" {
+		opts = append(opts, tracing.WithProtocol(tracing.Protocol(cfg.Exporter.Protocol)))
+	}
+
+	if cfg.Exporter.Timeout != 0 {
+		opts = append(opts, tracing.WithTimeout(cfg.Exporter.Timeout))
+	}
+
+	if cfg.ServiceName != "" {
+		opts = append(opts, tracing.WithServiceName(cfg.ServiceName))
+	}
+
+	if cfg.SampleRatio != 0 {
+		opts = append(opts, tracing.WithSampleRatio(cfg.SampleRatio))
+	}
+
+	return start(tracing.New(opts...))
+}
+
+// tracingShutdownTimeout возвращает, сколько ждать отправки накопленных спанов при остановке.
+func tracingShutdownTimeout(cfg *config.Config) time.Duration {
+	if cfg.Tracing.ShutdownTimeout != 0 {
+		return cfg.Tracing.ShutdownTimeout
+	}
+
+	return cfg.Server.ShutdownTimeout
+}
+
+// vaultTracing возвращает опции спанов запросов к Vault, если трассировка включена.
+func vaultTracing(tracer *tracing.Provider) []vault.ClientOption {
+	if tracer == nil {
+		return nil
+	}
+
+	return []vault.ClientOption{vault.WithTracing(tracer.TracerProvider())}
+}
+
+// redisTracing возвращает хук спанов команд Redis, если трассировка включена.
+func redisTracing(tracer *tracing.Provider) []goredis.Hook {
+	if tracer == nil {
+		return nil
+	}
+
+	return []goredis.Hook{start(redisstorage.NewTracingHook(tracer.TracerProvider()))}
+}
+
+// initProofOfWork создает proof-of-work сервис. Если маршруты не заданы, защита отключена и возвращается nil.
+func initProofOfWork(cfg config.ProofOfWork) *pow.Service {
+	if len(cfg.Routes) == 0 {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"routes":     cfg.Routes,
+		"difficulty": cfg.Difficulty,
+		"ttl":        cfg.TTL,
+	}).Info("initializing proof of work")
+
+	var opts []pow.Option
+
+	if cfg.Difficulty != 0 {
+		opts = append(opts, pow.WithDifficulty(cfg.Difficulty))
+	}
+
+	if cfg.TTL != 0 {
+		opts = append(opts, pow.WithTTL(cfg.TTL))
+	}
+
+	if cfg.Secret != "" {
+		opts = append(opts, pow.WithSecret(cfg.Secret))
+	}
+
+	return start(pow.New(opts...))
+}
+
+func initVaultClient(cfg config.Vault, extra ...vault.ClientOption) *vault.Client {
 	logrus.WithFields(logrus.Fields{
 		"address":           cfg.Address,
 		"insecure_skip_tls": cfg.InsecureSkipTLS,
+		"tls_pin_file":      cfg.TLSPinFile,
 	}).Info("initializing vault client")
 
+	return start(
+		vault.NewClient(append(vaultOptions(cfg), extra...)...),
+	)
+}
+
+// vaultOptions возвращает опции клиента Vault из конфигурации.
+func vaultOptions(cfg config.Vault) []vault.ClientOption {
 	opts := []vault.ClientOption{
 		vault.WithAddress(cfg.Address),
 		vault.WithToken(cfg.Token),
+		vault.WithMetrics(prometheus.DefaultRegisterer),
 	}
 
 	if cfg.InsecureSkipTLS {
+		logrus.WithFields(logrus.Fields{
+			"setting":     "vault.insecure_skip_tls",
+			"replacement": "vault.tls_pin_file",
+		}).Warn("vault.insecure_skip_tls is deprecated, use vault.tls_pin_file")
+
 		opts = append(opts, vault.WithInsecureSkipTLS(true))
 	}
 
+	if cfg.TLSPinFile != "" {
+		opts = append(opts, vault.WithTLSPin(cfg.TLSPinFile))
+	}
+
 	if cfg.CAPath != "" || cfg.ClientCertPath != "" || cfg.ClientKeyPath != "" {
 		opts = append(opts, vault.WithTLSConfig(cfg.CAPath, cfg.ClientCertPath, cfg.ClientKeyPath))
 	}
 
-	return start(
-		vault.NewClient(opts...),
-	)
+	return opts
 }
 
-func initRedisStorage(ctx context.Context, cfg config.Redis) *redis.Service {
-	redis := start(redis.New(redis.WithCfg(&cfg)))
+// vaultRenewal возвращает опции продления токена Vault: если продлить токен больше нельзя,
+// сервис останавливается так же, как по сигналу.
+func vaultRenewal(cfg config.VaultRenewal, exit func()) []vault.ClientOption {
+	if !cfg.Enabled {
+		return nil
+	}
 
-	startService(redis.Connect(ctx), "redis connect")
+	opts := []vault.ClientOption{vault.WithRenewalFailure(func(err error) {
+		logrus.WithError(err).Error("vault token renewal failed, shutting down")
+		exit()
+	})}
 
-	return redis
+	if cfg.Increment != 0 {
+		opts = append(opts, vault.WithRenewalIncrement(cfg.Increment))
+	}
+
+	return opts
 }
 
-func startService(err error, name string) {
-	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"service": name,
-		}).Fatalf("error creating service: %+v", err)
+// initTTLCheck создает проверку согласованности TTL записей черного списка, сессий и refresh токенов
+// со сроком их действия, если она включена. Иначе, а также если таких записей нет, возвращает nil.
+func initTTLCheck(
+	cfg config.RedisTTL, redis *redis.Service, revocations *revocation.Service, refreshTokens *refresh.Service,
+) *ttlcheck.Checker {
+	if !cfg.Check.Enabled {
+		return nil
 	}
-}
 
-func start[T any](svc T, err error) T {
-	startService(err, fmt.Sprintf("%T", svc))
+	var records []ttlcheck.Record
 
-	return svc
+	if revocations != nil {
+		records = append(records, revocations.TTLRecords()...)
+	}
+
+	if refreshTokens != nil {
+		records = append(records, refreshTokens.TTLRecords()...)
+	}
+
+	if len(records) == 0 {
+		logrus.Warn("redis ttl check is enabled, but revocation and refresh tokens are disabled")
+
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"slack":     cfg.Slack,
+		"interval":  cfg.Check.Interval,
+		"tolerance": cfg.Check.Tolerance,
+		"records":   len(records),
+	}).Info("initializing redis ttl check")
+
+	client, err := redis.Client()
+	startService(err, "redis client")
+
+	opts := []ttlcheck.Option{ttlcheck.WithClient(client), ttlcheck.WithRecords(records...)}
+
+	if cfg.Slack != 0 {
+		opts = append(opts, ttlcheck.WithSlack(cfg.Slack))
+	}
+
+	if cfg.Check.Interval != 0 {
+		opts = append(opts, ttlcheck.WithInterval(cfg.Check.Interval))
+	}
+
+	if cfg.Check.Tolerance != 0 {
+		opts = append(opts, ttlcheck.WithTolerance(cfg.Check.Tolerance))
+	}
+
+	return start(ttlcheck.New(opts...))
 }
 
-// updateSwaggerHost обновляет host в swagger документации на основе конфигурации сервера.
-// Если Host указан в конфиге, используется он, иначе формируется из localhost и порта.
-func updateSwaggerHost(cfg config.Server) {
-	host := cfg.SwaggerHost
-	if host == "" {
-		host = fmt.Sprintf("localhost:%d", cfg.Port)
+// initClockDrift создает проверку расхождения часов, если она включена. Иначе возвращает nil.
+func initClockDrift(cfg config.ClockDrift, vaultClient *vault.Client, redis *redis.Service) *clockdrift.Monitor {
+	if !cfg.Enabled {
+		return nil
 	}
 
-	docs.SwaggerInfo.Host = host
-	logrus.WithField("swagger_host", host).Debug("swagger host updated")
+	logrus.WithFields(logrus.Fields{
+		"interval":   cfg.Interval,
+		"threshold":  cfg.Threshold,
+		"ntp_server": cfg.NTPServer,
+	}).Info("initializing clock drift check")
+
+	opts := []clockdrift.Option{
+		clockdrift.WithSource(string(dependency.Vault), vaultClient.ServerTime),
+		clockdrift.WithSource(string(dependency.Redis), redis.Time),
+	}
+
+	if cfg.NTPServer != "" {
+		opts = append(opts, clockdrift.WithSource("ntp", clockdrift.NTP(cfg.NTPServer)))
+	}
+
+	if cfg.Interval != 0 {
+		opts = append(opts, clockdrift.WithInterval(cfg.Interval))
+	}
+
+	if cfg.Threshold != 0 {
+		opts = append(opts, clockdrift.WithThreshold(cfg.Threshold))
+	}
+
+	return start(clockdrift.New(opts...))
+}
+
+// initJanitor создает поиск ключей Redis без TTL, если он включен. Иначе возвращает nil.
+func initJanitor(cfg config.RedisJanitor, redis *redis.Service) *janitor.Janitor {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"interval": cfg.Interval,
+		"delete":   cfg.Delete,
+		"patterns": cfg.Patterns,
+	}).Info("initializing redis janitor")
+
+	client, err := redis.Client()
+	startService(err, "redis client")
+
+	opts := []janitor.Option{janitor.WithClient(client), janitor.WithDelete(cfg.Delete)}
+
+	if cfg.Interval != 0 {
+		opts = append(opts, janitor.WithInterval(cfg.Interval))
+	}
+
+	if len(cfg.Patterns) != 0 {
+		opts = append(opts, janitor.WithPatterns(cfg.Patterns))
+	}
+
+	return start(janitor.New(opts...))
+}
+
+// initRedisStorage подключается к Redis. extra - дополнительные хуки команд, например учет записей режимом только для чтения.
+func initRedisStorage(ctx context.Context, cfg config.Redis, extra ...goredis.Hook) *redis.Service {
+	hooks := []goredis.Hook{start(redisstorage.NewDeadlineHook(cfg.CommandTimeout, prometheus.DefaultRegisterer))}
+
+	if cfg.Inspect.Enabled {
+		logrus.WithFields(logrus.Fields{
+			"slow_command":   cfg.Inspect.SlowCommand,
+			"big_payload":    cfg.Inspect.BigPayload,
+			"big_collection": cfg.Inspect.BigCollection,
+		}).Info("initializing redis command inspection")
+
+		hooks = append(hooks, start(redisstorage.NewInspectHook(redisstorage.Thresholds{
+			SlowCommand:   cfg.Inspect.SlowCommand,
+			BigPayload:    cfg.Inspect.BigPayload,
+			BigCollection: cfg.Inspect.BigCollection,
+			LogInterval:   cfg.Inspect.LogInterval,
+		}, prometheus.DefaultRegisterer)))
+	}
+
+	if cfg.Type == config.RedisTypeCluster {
+		logrus.WithFields(logrus.Fields{
+			"max_redirects": cfg.Cluster.MaxRedirects,
+			"slot_failures": cfg.Cluster.SlotFailures,
+			"slot_cooldown": cfg.Cluster.SlotCooldown,
+		}).Info("initializing redis cluster topology handling")
+
+		hooks = append(hooks, start(redisstorage.NewTopologyHook(redisstorage.SlotOptions{
+			Failures: cfg.Cluster.SlotFailures,
+			Cooldown: cfg.Cluster.SlotCooldown,
+		}, prometheus.DefaultRegisterer)))
+	}
+
+	hooks = append(hooks, extra...)
+
+	redis := start(redis.New(redis.WithCfg(&cfg), redis.WithHooks(hooks...)))
+
+	startService(redis.Connect(ctx), "redis connect")
+
+	return redis
+}
+
+// initCodec создает кодирование записей сессий и токенов в Redis.
+func initCodec(cfg config.Redis) *codec.Codec {
+	opts := []codec.Option{}
+
+	if cfg.Encoding != "" {
+		opts = append(opts, codec.WithFormat(codec.Format(cfg.Encoding)))
+	}
+
+	return start(codec.New(opts...))
+}
+
+func initGroups(redis *redis.Service) *group.Service {
+	client, err := redis.Client()
+	startService(err, "redis client")
+
+	return start(group.New(group.WithClient(client)))
+}
+
+// initPolicy создает движок политик, если задан их источник. Иначе возвращает nil.
+func initPolicy(ctx context.Context, cfg config.AuthzPolicy, vaultClient *vault.Client) *policy.Engine {
+	var source policy.Option
+
+	switch {
+	case cfg.ModelPath != "":
+		source = policy.WithSource(policy.FileSource{ModelPath: cfg.ModelPath, RulesPath: cfg.RulesPath})
+	case cfg.VaultPath != "":
+		source = policy.WithSource(policy.VaultSource{Client: vaultClient, Path: cfg.VaultPath})
+	default:
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"model_path":      cfg.ModelPath,
+		"rules_path":      cfg.RulesPath,
+		"vault_path":      cfg.VaultPath,
+		"reload_interval": cfg.ReloadInterval,
+	}).Info("initializing authorization policies")
+
+	opts := []policy.Option{source}
+
+	if cfg.ReloadInterval != 0 {
+		opts = append(opts, policy.WithReloadInterval(cfg.ReloadInterval))
+	}
+
+	return start(policy.New(ctx, opts...))
+}
+
+// initStateKeys читает ключи шифрования state из Vault, если они включены. Иначе возвращает nil.
+func initStateKeys(ctx context.Context, cfg config.StateKeys, vaultClient *vault.Client) *statekey.Ring {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"vault_path":      cfg.VaultPath,
+		"reload_interval": cfg.ReloadInterval,
+		"rotation_period": cfg.RotationPeriod,
+	}).Info("initializing state encryption keys")
+
+	opts := []statekey.Option{statekey.WithClient(vaultClient), statekey.WithRotationPeriod(cfg.RotationPeriod)}
+
+	if cfg.VaultPath != "" {
+		opts = append(opts, statekey.WithPath(cfg.VaultPath))
+	}
+
+	if cfg.ReloadInterval != 0 {
+		opts = append(opts, statekey.WithReloadInterval(cfg.ReloadInterval))
+	}
+
+	return start(statekey.New(ctx, opts...))
+}
+
+// initPeers загружает ключи доверенных peer сервисов, если проверка их токенов включена. Иначе возвращает nil.
+func initPeers(ctx context.Context, cfg config.Peers) *peer.TrustStore {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	issuers := make([]peer.Issuer, 0, len(cfg.Issuers))
+	for _, issuer := range cfg.Issuers {
+		issuers = append(issuers, peer.Issuer{Name: issuer.Name, Issuer: issuer.Issuer, JWKSURL: issuer.JWKSURL})
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"audience": cfg.Audience,
+		"issuers":  len(issuers),
+		"routes":   cfg.Routes,
+	}).Info("initializing peer token verification")
+
+	opts := []peer.Option{peer.WithIssuers(issuers...), peer.WithAudience(cfg.Audience)}
+
+	if cfg.RefreshInterval != 0 {
+		opts = append(opts, peer.WithRefreshInterval(cfg.RefreshInterval))
+	}
+
+	return start(peer.New(ctx, opts...))
+}
+
+// initTelegram читает секреты Telegram бота из Vault, если они включены. Иначе возвращает nil.
+func initTelegram(ctx context.Context, cfg config.Telegram, vaultClient *vault.Client) *telegram.Secrets {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"vault_path":      cfg.VaultPath,
+		"reload_interval": cfg.ReloadInterval,
+	}).Info("initializing telegram secrets")
+
+	opts := []telegram.Option{telegram.WithKVReader(vaultClient)}
+
+	if cfg.VaultPath != "" {
+		opts = append(opts, telegram.WithPath(cfg.VaultPath))
+	}
+
+	if cfg.ReloadInterval != 0 {
+		opts = append(opts, telegram.WithReloadInterval(cfg.ReloadInterval))
+	}
+
+	return start(telegram.New(ctx, opts...))
+}
+
+// initTelegramLogin создает вход пользователей Telegram, если он включен. Иначе возвращает nil.
+func initTelegramLogin(
+	cfg config.TelegramLogin, secrets *telegram.Secrets, issuer *token.Issuer, users userstore.Store,
+) *telegram.Authenticator {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if secrets == nil {
+		logrus.Fatal("telegram.login requires telegram to be enabled")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"max_age":    cfg.MaxAge,
+		"token_ttl":  cfg.TokenTTL,
+		"audience":   cfg.Audience,
+		"user_store": users != nil,
+	}).Info("initializing telegram login")
+
+	opts := []telegram.AuthenticatorOption{
+		telegram.WithKeys(secrets),
+		telegram.WithIssuer(issuer),
+	}
+
+	if cfg.MaxAge != 0 {
+		opts = append(opts, telegram.WithMaxAge(cfg.MaxAge))
+	}
+
+	tokenTTL := cfg.TokenTTL
+	if tokenTTL == 0 {
+		tokenTTL = telegram.DefaultTokenTTL
+	}
+
+	opts = append(opts, telegram.WithToken(tokenTTL, cfg.Audience))
+
+	if users != nil {
+		opts = append(opts, telegram.WithUsers(users))
+	}
+
+	return start(telegram.NewAuthenticator(opts...))
+}
+
+func initAuthz(cfg config.Authz, groups *group.Service, policies *policy.Engine) *authz.Service {
+	opts := []authz.Option{
+		authz.WithGroups(groups),
+		authz.WithDecisionLog(cfg.DecisionLog),
+	}
+
+	if policies != nil {
+		opts = append(opts, authz.WithPolicy(policies))
+	}
+
+	return start(authz.New(opts...))
+}
+
+// initJobs создает очередь асинхронных заданий.
+func initJobs(cfg config.Jobs, redis *redis.Service) *job.Service {
+	client, err := redis.Client()
+	startService(err, "redis client")
+
+	opts := []job.Option{job.WithClient(client)}
+
+	if cfg.TTL != 0 {
+		opts = append(opts, job.WithTTL(cfg.TTL))
+	}
+
+	return start(job.New(opts...))
+}
+
+// initRevocation создает сервис отзыва токенов пользователей, если он включен. Иначе возвращает nil.
+// Отозванные токены хранятся в черном списке и после истечения, пока их может принять мягкая проверка (gracePeriod),
+// и еще запас TTL (slack).
+func initRevocation(
+	cfg config.Revocation, gracePeriod, slack time.Duration, redis *redis.Service, jobs *job.Service, events *event.Publisher,
+) *revocation.Service {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"retention": cfg.Retention,
+		"events":    events != nil,
+	}).Info("initializing token revocation")
+
+	client, err := redis.Client()
+	startService(err, "redis client")
+
+	opts := []revocation.Option{
+		revocation.WithClient(client),
+		revocation.WithJobs(jobs),
+		revocation.WithTokenLeeway(gracePeriod),
+	}
+
+	if events != nil {
+		opts = append(opts, revocation.WithEvents(events))
+	}
+
+	if cfg.Retention != 0 {
+		opts = append(opts, revocation.WithRetention(cfg.Retention))
+	}
+
+	if slack != 0 {
+		opts = append(opts, revocation.WithTTLSlack(slack))
+	}
+
+	return start(revocation.New(opts...))
+}
+
+// initReplication создает репликацию отзыва токенов из других регионов и подключается к их Redis.
+// Если она отключена, возвращает nil.
+func initReplication(ctx context.Context, butler *Butler, cfg *config.Config, revocations *revocation.Service) *replication.Replicator {
+	if !cfg.Revocation.Replication.Enabled {
+		return nil
+	}
+
+	if revocations == nil || !cfg.Events.Enabled || cfg.Events.Region == "" {
+		logrus.Fatal("revocation replication requires enabled revocation and events with region")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"region": cfg.Events.Region,
+		"peers":  slices.Sorted(maps.Keys(cfg.Revocation.Replication.Peers)),
+	}).Info("initializing revocation replication")
+
+	peers := make(map[string]goredis.UniversalClient, len(cfg.Revocation.Replication.Peers))
+
+	for name, peerCfg := range cfg.Revocation.Replication.Peers {
+		peer := initRedisStorage(ctx, peerCfg)
+		registerShutdownHook(butler, "redis-"+name, peer.Stop, cfg.Server.ShutdownTimeout)
+
+		client, err := peer.Client()
+		startService(err, "redis client")
+
+		peers[name] = client
+	}
+
+	opts := []replication.Option{
+		replication.WithRegion(cfg.Events.Region),
+		replication.WithPeers(peers),
+		replication.WithApplier(revocations),
+	}
+
+	if cfg.Events.Stream != "" {
+		opts = append(opts, replication.WithStream(cfg.Events.Stream))
+	}
+
+	return start(replication.New(opts...))
+}
+
+// initEvents создает публикацию событий сервиса, если она включена. Иначе возвращает nil.
+func initEvents(cfg config.Events, redis *redis.Service) *event.Publisher {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithField("stream", cfg.Stream).Info("initializing events")
+
+	client, err := redis.Client()
+	startService(err, "redis client")
+
+	opts := []event.Option{event.WithClient(client), event.WithRegion(cfg.Region)}
+
+	if cfg.Stream != "" {
+		opts = append(opts, event.WithStream(cfg.Stream))
+	}
+
+	if cfg.MaxLen != 0 {
+		opts = append(opts, event.WithMaxLen(cfg.MaxLen))
+	}
+
+	return start(event.New(opts...))
+}
+
+// initAbuse создает обнаружение перебора учетных данных и denylist. Если оно отключено, возвращает nil.
+func initAbuse(cfg config.Abuse, redis *redis.Service, events *event.Publisher) *abuse.Service {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"window":    cfg.Window,
+		"banTTL":    cfg.BanTTL,
+		"ip":        cfg.IPUniqueUsernames,
+		"network":   cfg.NetworkUniqueUsernames,
+		"asn":       cfg.ASNUniqueUsernames,
+		"honeypots": len(cfg.Honeypots),
+		"events":    events != nil,
+		"observe":   cfg.Observe,
+	}).Info("initializing abuse protection")
+
+	client, err := redis.Client()
+	startService(err, "redis client")
+
+	opts := []abuse.Option{
+		abuse.WithClient(client),
+		abuse.WithThresholds(cfg.IPUniqueUsernames, cfg.NetworkUniqueUsernames, cfg.ASNUniqueUsernames),
+		abuse.WithASNHeader(cfg.ASNHeader),
+		abuse.WithObserve(cfg.Observe),
+	}
+
+	if events != nil {
+		opts = append(opts, abuse.WithEvents(events))
+	}
+
+	if cfg.Window != 0 {
+		opts = append(opts, abuse.WithWindow(cfg.Window))
+	}
+
+	if cfg.BanTTL != 0 {
+		opts = append(opts, abuse.WithBanTTL(cfg.BanTTL))
+	}
+
+	if cfg.IPv4Prefix != 0 || cfg.IPv6Prefix != 0 {
+		opts = append(opts, abuse.WithNetworkPrefixes(
+			cmp.Or(cfg.IPv4Prefix, abuse.DefaultIPv4Prefix),
+			cmp.Or(cfg.IPv6Prefix, abuse.DefaultIPv6Prefix),
+		))
+	}
+
+	return start(abuse.New(opts...))
+}
+
+// initBreach создает проверку паролей по базе утечек. Если она отключена, возвращает nil.
+func initBreach(cfg config.PasswordBreach) *breach.Checker {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"url":      cfg.URL,
+		"timeout":  cfg.Timeout,
+		"failOpen": cfg.FailOpen,
+		"minCount": cfg.MinCount,
+	}).Info("initializing password breach check")
+
+	opts := []breach.Option{
+		breach.WithFailOpen(cfg.FailOpen),
+		breach.WithHTTPClient(&http.Client{Timeout: cmp.Or(cfg.Timeout, breach.DefaultTimeout)}),
+	}
+
+	if cfg.URL != "" {
+		opts = append(opts, breach.WithURL(cfg.URL))
+	}
+
+	if cfg.MinCount != 0 {
+		opts = append(opts, breach.WithMinCount(cfg.MinCount))
+	}
+
+	return start(breach.New(opts...))
+}
+
+// initCredentialsPolicy создает политику логинов и паролей. Если она отключена, возвращает nil.
+func initCredentialsPolicy(cfg config.CredentialsPolicy) *credpolicy.Policy {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"passwordMinLength": cfg.Password.MinLength,
+		"passwordBanned":    len(cfg.Password.Banned),
+		"usernamePattern":   cfg.Username.Pattern,
+		"usernameReserved":  len(cfg.Username.Reserved),
+	}).Info("initializing credentials policy")
+
+	return start(credpolicy.New(
+		credpolicy.WithPasswordRules(credpolicy.PasswordRules(cfg.Password)),
+		credpolicy.WithUsernameRules(credpolicy.UsernameRules(cfg.Username)),
+	))
+}
+
+// initWarmup создает прогрев сервиса, если он включен. Иначе возвращает nil.
+func initWarmup(cfg config.Warmup, keys *token.VaultKeys, redis *redis.Service, issuer *token.Issuer, validator *token.Validator) *warmup.Runner {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"timeout":           cfg.Timeout,
+		"redis_connections": cfg.RedisConnections,
+		"required":          cfg.Required,
+	}).Info("initializing warm-up")
+
+	opts := []warmup.Option{
+		warmup.WithKeys(keys),
+		warmup.WithRedis(redis, cfg.RedisConnections),
+		warmup.WithSelfCheck(issuer, validator),
+	}
+
+	if cfg.Timeout != 0 {
+		opts = append(opts, warmup.WithTimeout(cfg.Timeout))
+	}
+
+	return start(warmup.New(opts...))
+}
+
+// initUserStore создает клиент внешнего сервиса пользователей, если он включен. Иначе возвращает nil.
+// Токен доступа к сервису читается из Vault при запуске.
+func initUserStore(ctx context.Context, cfg config.UserStore, vaultClient *vault.Client) *userstore.HTTPStore {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"url":         cfg.URL,
+		"timeout":     cfg.Timeout,
+		"health_path": cfg.HealthPath,
+		"token":       cfg.TokenPath != "",
+	}).Info("initializing user store")
+
+	opts := []userstore.HTTPOption{
+		userstore.WithURL(cfg.URL),
+		userstore.WithHTTPClient(&http.Client{Timeout: cmp.Or(cfg.Timeout, userstore.DefaultTimeout)}),
+	}
+
+	if cfg.TokenPath != "" {
+		secret, err := vaultClient.ReadKV(ctx, cfg.TokenPath)
+		startService(err, "user store token")
+
+		token, _ := secret["token"].(string)
+		if token == "" {
+			startService(errors.New("secret must contain token"), "user store token")
+		}
+
+		opts = append(opts, userstore.WithToken(token))
+	}
+
+	if cfg.HealthPath != "" {
+		opts = append(opts, userstore.WithHealthPath(cfg.HealthPath))
+	}
+
+	return start(userstore.NewHTTP(opts...))
+}
+
+// initUserCache оборачивает сервис пользователей кэшем, если он включен. Если сервис пользователей
+// не настроен, возвращает nil.
+func initUserCache(cfg config.UserCache, redis *redis.Service, users *userstore.HTTPStore) userstore.Store {
+	if users == nil {
+		return nil
+	}
+
+	if !cfg.Enabled {
+		return users
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"ttl":          cfg.TTL,
+		"negative_ttl": cfg.NegativeTTL,
+	}).Info("initializing user store cache")
+
+	client, err := redis.Client()
+	startService(err, "redis client")
+
+	opts := []userstore.CacheOption{
+		userstore.WithStore(users),
+		userstore.WithClient(client),
+	}
+
+	if cfg.TTL != 0 {
+		opts = append(opts, userstore.WithTTL(cfg.TTL))
+	}
+
+	if cfg.NegativeTTL != 0 {
+		opts = append(opts, userstore.WithNegativeTTL(cfg.NegativeTTL))
+	}
+
+	return start(userstore.NewCache(opts...))
+}
+
+func initDependencies(
+	cfg config.Dependencies, vaultClient *vault.Client, redis *redis.Service, users *userstore.HTTPStore,
+) *dependency.Registry {
+	logrus.WithFields(logrus.Fields{
+		"check_interval": cfg.CheckInterval,
+		"check_timeout":  cfg.CheckTimeout,
+		"retry_after":    cfg.RetryAfter,
+	}).Info("initializing dependency registry")
+
+	opts := []dependency.Option{
+		dependency.WithChecker(dependency.Vault, vaultClient.Health),
+		dependency.WithChecker(dependency.Redis, redis.Ping),
+	}
+
+	if users != nil {
+		opts = append(opts, dependency.WithChecker(dependency.UserStore, users.Health))
+	}
+
+	if cfg.CheckInterval != 0 {
+		opts = append(opts, dependency.WithInterval(cfg.CheckInterval))
+	}
+
+	if cfg.CheckTimeout != 0 {
+		opts = append(opts, dependency.WithTimeout(cfg.CheckTimeout))
+	}
+
+	if cfg.RetryAfter != 0 {
+		opts = append(opts, dependency.WithRetryAfter(cfg.RetryAfter))
+	}
+
+	return start(dependency.New(opts...))
+}
+
+func initSigningKeys(cfg config.Token, vaultClient *vault.Client, registerer prometheus.Registerer) *token.VaultKeys {
+	opts := []token.KeysOption{
+		token.WithKVReader(vaultClient),
+		token.WithKeysRegisterer(registerer),
+	}
+
+	if cfg.KeysPath != "" {
+		opts = append(opts, token.WithKeysPath(cfg.KeysPath))
+	}
+
+	if cfg.Rotation.Enabled {
+		opts = append(opts,
+			token.WithKVWriter(vaultClient),
+			token.WithRotation(token.KeyRotation{Period: cfg.Rotation.Period, Grace: cfg.Rotation.Grace}),
+		)
+	}
+
+	return start(token.NewVaultKeys(opts...))
+}
+
+// initKeyRotation создает ротацию ключей подписи, если она включена. Иначе возвращает nil.
+func initKeyRotation(cfg config.TokenRotation, keys *token.VaultKeys, issuer *token.Issuer, validator *token.Validator) auth.Service {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	interval := cfg.CheckInterval
+	if interval == 0 {
+		interval = auth.DefaultUpdateKeyInterval
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"period":         cfg.Period,
+		"grace":          cfg.Grace,
+		"check_interval": interval,
+	}).Info("initializing signing key rotation")
+
+	return start(auth.New(
+		auth.WithUpdateKeyInterval(interval),
+		auth.WithVaultClient(keys),
+		auth.WithKeyRotator(keys),
+		auth.WithIssuer(issuer),
+		auth.WithValidator(validator),
+	))
+}
+
+// initValidator создает проверку токенов. Если задан внешний адрес сервиса, токены другого iss отклоняются.
+func initValidator(
+	cfg config.Token, externalURL string, keys *token.VaultKeys, keyStats *keystats.Tracker, revocations *revocation.Service,
+	sessions *refresh.Service,
+) *token.Validator {
+	logrus.WithFields(logrus.Fields{
+		"issuer":                  externalURL,
+		"keys_path":               cfg.KeysPath,
+		"grace_period":            cfg.Grace.Period,
+		"grace_audiences":         cfg.Grace.Audiences,
+		"session_check_audiences": cfg.SessionCheck.Audiences,
+		"coalesce":                cfg.CoalesceValidation,
+	}).Info("initializing token validator")
+
+	opts := []token.ValidatorOption{
+		token.WithKeys(keys),
+		token.WithKeyStats(keyStats),
+	}
+
+	if cfg.Grace.Period != 0 {
+		opts = append(opts, token.WithGrace(token.Grace{
+			Period:    cfg.Grace.Period,
+			Audiences: cfg.Grace.Audiences,
+		}))
+	}
+
+	if cfg.CoalesceValidation {
+		opts = append(opts, token.WithCoalescing(prometheus.DefaultRegisterer))
+	}
+
+	if revocations != nil {
+		opts = append(opts, token.WithRevocations(revocations))
+	}
+
+	if len(cfg.SessionCheck.Audiences) != 0 {
+		if sessions == nil {
+			logrus.Fatal("token.session_check requires refresh tokens to be enabled")
+		}
+
+		opts = append(opts, token.WithSessionCheck(sessions, token.SessionCheck{Audiences: cfg.SessionCheck.Audiences}))
+	}
+
+	// активность скользящих сессий отмечается проверкой их токенов, refresh токены тогда уже включены
+	if sessions != nil && len(cfg.Refresh.Sliding) != 0 {
+		opts = append(opts, token.WithSessionActivity(sessions, slices.Sorted(maps.Keys(cfg.Refresh.Sliding))))
+	}
+
+	if externalURL != "" {
+		opts = append(opts, token.WithExpectedIssuer(externalURL))
+	}
+
+	return start(token.NewValidator(opts...))
+}
+
+// initUserValidator возвращает проверку токенов API пользователя: только токены аудиторий audiences.
+// Без аудиторий принимаются токены любой аудитории.
+func initUserValidator(validator *token.Validator, audiences []string) *token.Validator {
+	if len(audiences) == 0 {
+		logrus.Warn("token.user_audiences is not configured, user API accepts tokens of any audience")
+
+		return validator
+	}
+
+	logrus.WithField("audiences", audiences).Info("initializing user API token audiences")
+
+	return validator.ForAudience(audiences...)
+}
+
+// initIssuer создает выпуск токенов. Внешний адрес сервиса записывается в claim iss.
+func initIssuer(
+	tokenCfg config.Token, externalURL string, sandbox config.Sandbox, keys *token.VaultKeys, keyStats *keystats.Tracker, groups *group.Service,
+	analytics *stats.Service,
+) *token.Issuer {
+	cfg := tokenCfg.Impersonation
+
+	logrus.WithFields(logrus.Fields{
+		"issuer":                 externalURL,
+		"impersonation_max_ttl":  cfg.MaxTTL,
+		"impersonation_scopes":   cfg.Scopes,
+		"max_token_size":         tokenCfg.Limits.MaxTokenSize,
+		"max_custom_claims_size": tokenCfg.Limits.MaxCustomClaimsSize,
+		"sandbox_audiences":      sandbox.Audiences,
+		"guest_audiences":        tokenCfg.Guest.Audiences,
+	}).Info("initializing token issuer")
+
+	opts := []token.IssuerOption{
+		token.WithSigningKeys(keys),
+		token.WithIssuerKeyStats(keyStats),
+		token.WithIssuerURL(externalURL),
+	}
+
+	if groups != nil {
+		opts = append(opts, token.WithGroups(groups))
+	}
+
+	if analytics != nil {
+		opts = append(opts, token.WithIssueStats(analytics))
+	}
+
+	if len(tokenCfg.ClaimSchemas) != 0 {
+		opts = append(opts, token.WithClaimSchemas(claimSchemas(tokenCfg.ClaimSchemas)))
+	}
+
+	if cfg.MaxTTL != 0 || len(cfg.Scopes) != 0 {
+		impersonation := token.Impersonation{MaxTTL: cfg.MaxTTL, Scopes: cfg.Scopes}
+
+		if impersonation.MaxTTL == 0 {
+			impersonation.MaxTTL = token.DefaultImpersonationTTL
+		}
+
+		if len(impersonation.Scopes) == 0 {
+			impersonation.Scopes = []string{token.ScopeRead}
+		}
+
+		opts = append(opts, token.WithImpersonation(impersonation))
+	}
+
+	if limits := tokenCfg.Limits; limits.MaxTokenSize != 0 || limits.MaxCustomClaimsSize != 0 || len(limits.ForbiddenClaims) != 0 {
+		tokenLimits := token.Limits{
+			MaxTokenSize:        limits.MaxTokenSize,
+			MaxCustomClaimsSize: limits.MaxCustomClaimsSize,
+			ForbiddenClaims:     limits.ForbiddenClaims,
+		}
+
+		if tokenLimits.MaxTokenSize == 0 {
+			tokenLimits.MaxTokenSize = token.DefaultMaxTokenSize
+		}
+
+		if tokenLimits.MaxCustomClaimsSize == 0 {
+			tokenLimits.MaxCustomClaimsSize = token.DefaultMaxCustomClaimsSize
+		}
+
+		opts = append(opts, token.WithLimits(tokenLimits))
+	}
+
+	if len(sandbox.Audiences) != 0 {
+		opts = append(opts, token.WithSandbox(token.Sandbox{
+			Audiences: sandbox.Audiences,
+			TTL:       sandboxTTL(sandbox),
+		}))
+	}
+
+	if guest := tokenCfg.Guest; len(guest.Audiences) != 0 {
+		ttl := guest.TTL
+		if ttl == 0 {
+			ttl = token.DefaultGuestTTL
+		}
+
+		opts = append(opts, token.WithGuest(token.Guest{Audiences: guest.Audiences, TTL: ttl}))
+	}
+
+	return start(token.NewIssuer(opts...))
+}
+
+func initQuota(cfg config.Quota, limits config.RateLimit, redis *redis.Service) *quota.Service {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"daily":   cfg.Default.Daily,
+		"monthly": cfg.Default.Monthly,
+		"keys":    len(cfg.Keys),
+		"tiers":   len(limits.Tiers),
+	}).Info("initializing api key quota")
+
+	client, err := redis.Client()
+	startService(err, "redis client")
+
+	keys := make(map[string]quota.Limits, len(cfg.Keys))
+	for id, l := range cfg.Keys {
+		keys[id] = quotaLimits(l)
+	}
+
+	tiers := make(map[string]ratelimit.Rule, len(limits.Tiers))
+	for name, rule := range limits.Tiers {
+		tiers[name] = rateLimitRule(rule)
+	}
+
+	return start(quota.New(
+		quota.WithClient(client),
+		quota.WithDefaultLimits(quotaLimits(cfg.Default)),
+		quota.WithKeyLimits(keys),
+		quota.WithRateLimits(rateLimitRule(limits.APIKey), tiers),
+	))
+}
+
+// initReadOnly создает режим только для чтения, если он включен. Иначе возвращает nil.
+func initReadOnly(cfg config.ReadOnly) *readonly.Mode {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"auto":              cfg.Auto,
+		"failure_threshold": cfg.FailureThreshold,
+		"cooldown":          cfg.Cooldown,
+	}).Info("initializing read-only mode")
+
+	opts := []readonly.Option{}
+
+	if cfg.FailureThreshold != 0 {
+		opts = append(opts, readonly.WithFailureThreshold(cfg.FailureThreshold))
+	}
+
+	if cfg.Cooldown != 0 {
+		opts = append(opts, readonly.WithCooldown(cfg.Cooldown))
+	}
+
+	return start(readonly.New(opts...))
+}
+
+// readOnlyHooks возвращает хук Redis, который включает режим только для чтения после ошибок записи,
+// если включено автоматическое переключение.
+func readOnlyHooks(cfg config.ReadOnly, mode *readonly.Mode) []goredis.Hook {
+	if mode == nil || !cfg.Auto {
+		return nil
+	}
+
+	return []goredis.Hook{start(redisstorage.NewWriteHook(mode.ObserveWrite))}
+}
+
+func initDrain(cfg config.Drain, exit func()) *drain.Drainer {
+	opts := []drain.Option{drain.WithExit(exit)}
+
+	if cfg.Delay != 0 {
+		opts = append(opts, drain.WithDelay(cfg.Delay))
+	}
+
+	if cfg.MaxDelay != 0 {
+		opts = append(opts, drain.WithMaxDelay(cfg.MaxDelay))
+	}
+
+	return start(drain.New(opts...))
+}
+
+func initLogSampling(cfg config.LogSampling, redis *redis.Service) *logsampling.Sampler {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithField("reload_interval", cfg.ReloadInterval).Info("initializing log sampling")
+
+	client, err := redis.Client()
+	startService(err, "redis client")
+
+	opts := []logsampling.Option{logsampling.WithClient(client)}
+
+	if cfg.ReloadInterval != 0 {
+		opts = append(opts, logsampling.WithReloadInterval(cfg.ReloadInterval))
+	}
+
+	return start(logsampling.New(opts...))
+}
+
+// claimSchemas переводит схемы пользовательских claims из конфигурации в схемы выпуска токенов.
+func claimSchemas(cfg map[string]config.TokenClaimSchema) map[string]token.ClaimSchema {
+	res := make(map[string]token.ClaimSchema, len(cfg))
+
+	for aud, schema := range cfg {
+		properties := make(map[string]token.ClaimProperty, len(schema.Properties))
+
+		for name, prop := range schema.Properties {
+			properties[name] = claimProperty(prop)
+		}
+
+		res[aud] = token.ClaimSchema{Properties: properties, AllowUnknown: schema.AllowUnknown}
+	}
+
+	return res
+}
+
+func claimProperty(cfg config.TokenClaimProperty) token.ClaimProperty {
+	prop := token.ClaimProperty{
+		Type:      cfg.Type,
+		Enum:      cfg.Enum,
+		Pattern:   cfg.Pattern,
+		MaxLength: cfg.MaxLength,
+		Minimum:   cfg.Minimum,
+		Maximum:   cfg.Maximum,
+		MaxItems:  cfg.MaxItems,
+	}
+
+	if cfg.Items != nil {
+		items := claimProperty(*cfg.Items)
+		prop.Items = &items
+	}
+
+	return prop
+}
+
+// initStats создает статистику для продуктовой аналитики, если она включена. Иначе возвращает nil.
+func initStats(cfg config.Stats, redis *redis.Service) *stats.Service {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithField("retention", cfg.Retention).Info("initializing statistics")
+
+	client, err := redis.Client()
+	startService(err, "redis client")
+
+	opts := []stats.Option{stats.WithClient(client)}
+
+	if cfg.Retention != 0 {
+		opts = append(opts, stats.WithRetention(cfg.Retention))
+	}
+
+	return start(stats.New(opts...))
+}
+
+func initAPIKeys(cfg config.APIKeys, sandbox config.Sandbox, redis *redis.Service, vaultClient *vault.Client) *apikey.Service {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithField("vault_path", cfg.VaultPath).Info("initializing api keys")
+
+	client, err := redis.Client()
+	startService(err, "redis client")
+
+	opts := []apikey.Option{
+		apikey.WithClient(client),
+		apikey.WithSandboxTTL(sandboxTTL(sandbox)),
+	}
+
+	if cfg.VaultPath != "" {
+		opts = append(opts, apikey.WithVault(vaultClient, cfg.VaultPath))
+	}
+
+	return start(apikey.New(opts...))
+}
+
+// initServerCert выпускает сертификат сервера в Vault PKI. Без сертификата сервер не запускается.
+func initServerCert(ctx context.Context, cfg config.ServerVaultPKI, vaultClient *vault.Client) *servercert.Manager {
+	if cfg.IssuePath == "" {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"issue_path":  cfg.IssuePath,
+		"common_name": cfg.CommonName,
+		"alt_names":   cfg.AltNames,
+		"ip_sans":     cfg.IPSANs,
+		"ttl":         cfg.TTL,
+	}).Info("initializing server certificate")
+
+	opts := []servercert.Option{
+		servercert.WithIssuer(vaultClient, cfg.IssuePath),
+		servercert.WithRequest(vault.IssueRequest{
+			CommonName: cfg.CommonName,
+			AltNames:   cfg.AltNames,
+			IPSANs:     cfg.IPSANs,
+			TTL:        cfg.TTL,
+		}),
+	}
+
+	if cfg.RetryInterval != 0 {
+		opts = append(opts, servercert.WithRetryInterval(cfg.RetryInterval))
+	}
+
+	manager := start(servercert.New(opts...))
+	startService(manager.Load(ctx), "server certificate")
+
+	return manager
+}
+
+// serverTLSConfig возвращает конфигурацию TLS сервера или nil, если сертификат сервера не настроен.
+// При заданном CA клиентов включается mTLS: предъявленный клиентский сертификат проверяется.
+func serverTLSConfig(cfg config.ServerTLS, cert *servercert.Manager) (*tls.Config, error) {
+	if cert == nil {
+		if cfg.ClientCAPath != "" {
+			return nil, errors.New("client ca requires server certificate")
+		}
+
+		return nil, nil //nolint:nilnil // TLS не настроен
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: cert.GetCertificate,
+	}
+
+	if cfg.ClientCAPath == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(cfg.ClientCAPath)
+	if err != nil {
+		return nil, fmt.Errorf("error read client ca: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("client ca does not contain certificates")
+	}
+
+	tlsConfig.ClientCAs = pool
+	// сертификат необязателен: mTLS нужен только внутренним клиентам с привязанными токенами
+	tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+
+	logrus.WithField("client_ca_path", cfg.ClientCAPath).Info("mtls enabled")
+
+	return tlsConfig, nil
+}
+
+// initQRLogin создает сервис входа по QR коду, если он включен. Иначе возвращает nil.
+func initQRLogin(cfg config.QRLogin, redis *redis.Service, issuer *token.Issuer) *qrlogin.Service {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"ttl":       cfg.TTL,
+		"token_ttl": cfg.TokenTTL,
+		"audience":  cfg.Audience,
+	}).Info("initializing qr login")
+
+	client, err := redis.Client()
+	startService(err, "redis client")
+
+	opts := []qrlogin.Option{
+		qrlogin.WithClient(client),
+		qrlogin.WithIssuer(issuer),
+	}
+
+	if cfg.TTL != 0 {
+		opts = append(opts, qrlogin.WithTTL(cfg.TTL))
+	}
+
+	tokenTTL := cfg.TokenTTL
+	if tokenTTL == 0 {
+		tokenTTL = qrlogin.DefaultTokenTTL
+	}
+
+	opts = append(opts, qrlogin.WithToken(tokenTTL, cfg.Audience))
+
+	if cfg.PayloadPrefix != "" {
+		opts = append(opts, qrlogin.WithPayloadPrefix(cfg.PayloadPrefix))
+	}
+
+	return start(qrlogin.New(opts...))
+}
+
+// initBundles создает выгрузку пакетов для проверки токенов без обращения к сервису.
+func initBundles(cfg config.TokenBundle, externalURL string, keys *token.VaultKeys, revocations *revocation.Service) *bundle.Exporter {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"ttl":         cfg.TTL,
+		"audiences":   cfg.Audiences,
+		"revocations": revocations != nil,
+	}).Info("initializing verification bundles")
+
+	return start(newBundleExporter(cfg, externalURL, keys, revocations))
+}
+
+// initCanary создает проверку пробным токеном, если она включена. Иначе возвращает nil.
+// Если включены пакеты проверки, пробный токен проверяется и по пакету.
+func initCanary(
+	cfg config.TokenCanary, issuer *token.Issuer, validator *token.Validator, bundles *bundle.Exporter, keys *token.VaultKeys,
+) *canary.Checker {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"interval": cfg.Interval,
+		"timeout":  cfg.Timeout,
+		"audience": cfg.Audience,
+		"required": cfg.Required,
+		"bundles":  bundles != nil,
+	}).Info("initializing token canary")
+
+	opts := []canary.Option{
+		canary.WithIssuer(issuer),
+		canary.WithValidator(validator),
+		canary.WithAudience(cfg.Audience),
+		canary.WithRequired(cfg.Required),
+	}
+
+	if bundles != nil {
+		opts = append(opts, canary.WithBundles(bundles, keys))
+	}
+
+	if cfg.Interval != 0 {
+		opts = append(opts, canary.WithInterval(cfg.Interval))
+	}
+
+	if cfg.Timeout != 0 {
+		opts = append(opts, canary.WithTimeout(cfg.Timeout))
+	}
+
+	return start(canary.New(opts...))
+}
+
+func newBundleExporter(
+	cfg config.TokenBundle, externalURL string, keys *token.VaultKeys, revocations *revocation.Service,
+) (*bundle.Exporter, error) {
+	opts := []bundle.Option{
+		bundle.WithKeys(keys),
+		bundle.WithIssuer(externalURL),
+		bundle.WithAudiences(cfg.Audiences),
+	}
+
+	if revocations != nil {
+		opts = append(opts, bundle.WithRevocations(revocations))
+	}
+
+	if cfg.TTL != 0 {
+		opts = append(opts, bundle.WithTTL(cfg.TTL))
+	}
+
+	return bundle.New(opts...)
+}
+
+// initRefresh создает выпуск refresh токенов, если они включены. Иначе возвращает nil.
+func initRefresh(
+	cfg config.TokenRefresh, slack time.Duration, redis *redis.Service, records *codec.Codec, issuer *token.Issuer,
+	analytics *stats.Service,
+) *refresh.Service {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"ttl":        cfg.TTL,
+		"family_ttl": cfg.FamilyTTL,
+		"access_ttl": cfg.AccessTTL,
+		"sliding":    slices.Sorted(maps.Keys(cfg.Sliding)),
+		"encoding":   records.Format(),
+	}).Info("initializing refresh tokens")
+
+	client, err := redis.Client()
+	startService(err, "redis client")
+
+	opts := []refresh.Option{
+		refresh.WithClient(client),
+		refresh.WithIssuer(issuer),
+		refresh.WithCodec(records),
+	}
+
+	if cfg.TTL != 0 {
+		opts = append(opts, refresh.WithTTL(cfg.TTL))
+	}
+
+	if cfg.FamilyTTL != 0 {
+		opts = append(opts, refresh.WithFamilyTTL(cfg.FamilyTTL))
+	}
+
+	if cfg.AccessTTL != 0 {
+		opts = append(opts, refresh.WithAccessTTL(cfg.AccessTTL))
+	}
+
+	if slack != 0 {
+		opts = append(opts, refresh.WithTTLSlack(slack))
+	}
+
+	for audience, sliding := range cfg.Sliding {
+		opts = append(opts, refresh.WithSliding(audience, refresh.Sliding{Idle: sliding.Idle, MaxAge: sliding.MaxAge}))
+	}
+
+	if analytics != nil {
+		opts = append(opts, refresh.WithStats(analytics))
+	}
+
+	return start(refresh.New(opts...))
+}
+
+// initWebAuthn создает сервис входа по passkey, если он включен. Иначе возвращает nil.
+func initWebAuthn(cfg config.WebAuthn, redis *redis.Service, issuer *token.Issuer) *webauthn.Service {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"rp_id":             cfg.RPID,
+		"origins":           cfg.Origins,
+		"user_verification": cfg.UserVerification,
+	}).Info("initializing webauthn")
+
+	client, err := redis.Client()
+	startService(err, "redis client")
+
+	opts := []webauthn.Option{
+		webauthn.WithClient(client),
+		webauthn.WithIssuer(issuer),
+		webauthn.WithRelyingParty(cfg.RPID, cfg.RPName, cfg.Origins),
+	}
+
+	if cfg.Timeout != 0 {
+		opts = append(opts, webauthn.WithTimeout(cfg.Timeout))
+	}
+
+	if cfg.UserVerification != "" {
+		opts = append(opts, webauthn.WithUserVerification(cfg.UserVerification))
+	}
+
+	tokenTTL := cfg.TokenTTL
+	if tokenTTL == 0 {
+		tokenTTL = webauthn.DefaultTokenTTL
+	}
+
+	opts = append(opts, webauthn.WithToken(tokenTTL, cfg.Audience))
+
+	return start(webauthn.New(opts...))
+}
+
+// initLDAP создает вход администраторов через корпоративный каталог, если он включен. Иначе возвращает nil.
+// Пароль сервисной учетной записи читается из Vault при запуске. Если заданы учетные записи SCIM,
+// вход разрешен только активным.
+func initLDAP(
+	ctx context.Context, cfg config.LDAP, vaultClient *vault.Client, issuer *token.Issuer, accounts *scim.Service,
+) *ldap.Service {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"url":       cfg.URL,
+		"start_tls": cfg.StartTLS,
+		"base_dn":   cfg.BaseDN,
+		"bind_dn":   cfg.BindDN,
+		"groups":    len(cfg.GroupRoles),
+		"scim":      accounts != nil,
+	}).Info("initializing ldap admin login")
+
+	opts := []ldap.Option{
+		ldap.WithIssuer(issuer),
+		ldap.WithURL(cfg.URL),
+		ldap.WithTLS(start(ldapTLSConfig(cfg.CAPath)), cfg.StartTLS),
+		ldap.WithBaseDN(cfg.BaseDN),
+		ldap.WithGroupRoles(cfg.GroupRoles),
+	}
+
+	if cfg.BindDN != "" {
+		secret, err := vaultClient.ReadKV(ctx, cfg.BindPasswordPath)
+		startService(err, "ldap bind password")
+
+		password, _ := secret["password"].(string)
+		if password == "" {
+			startService(errors.New("secret must contain password"), "ldap bind password")
+		}
+
+		opts = append(opts, ldap.WithBind(cfg.BindDN, password))
+	}
+
+	if accounts != nil {
+		opts = append(opts, ldap.WithAccounts(accounts))
+	}
+
+	if cfg.UserFilter != "" {
+		opts = append(opts, ldap.WithUserFilter(cfg.UserFilter))
+	}
+
+	if cfg.GroupAttribute != "" {
+		opts = append(opts, ldap.WithGroupAttribute(cfg.GroupAttribute))
+	}
+
+	if cfg.GroupFilter != "" {
+		opts = append(opts, ldap.WithGroupSearch(cfg.GroupBaseDN, cfg.GroupFilter))
+	}
+
+	if cfg.Timeout != 0 {
+		opts = append(opts, ldap.WithTimeout(cfg.Timeout))
+	}
+
+	if cfg.TokenTTL != 0 {
+		opts = append(opts, ldap.WithTokenTTL(cfg.TokenTTL))
+	}
+
+	return start(ldap.New(opts...))
+}
+
+// initSCIM создает хранилище учетных записей администраторов, которые ведет корпоративный IdP, если SCIM включен.
+// Иначе возвращает nil. Если включен отзыв токенов, отключение учетной записи сразу отзывает токены администратора.
+func initSCIM(cfg config.SCIM, redis *redis.Service, revocations *revocation.Service) *scim.Service {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithField("deactivations", revocations != nil).Info("initializing scim")
+
+	client, err := redis.Client()
+	startService(err, "redis client")
+
+	opts := []scim.Option{scim.WithClient(client)}
+
+	if revocations != nil {
+		opts = append(opts, scim.WithDeactivations(revocations, ldap.Subject))
+	}
+
+	svc := start(scim.New(opts...))
+
+	if revocations != nil {
+		revocations.RegisterSource("scim", svc)
+	}
+
+	return svc
+}
+
+// ldapTLSConfig возвращает настройки TLS подключения к каталогу. Без CA используются системные.
+func ldapTLSConfig(caPath string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caPath == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(caPath)
+	if err != nil {
+		return nil, fmt.Errorf("error read ldap ca: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("ldap ca does not contain certificates")
+	}
+
+	tlsConfig.RootCAs = pool
+
+	return tlsConfig, nil
+}
+
+// initOAuth создает сервис входа через внешних провайдеров, если он включен. Иначе возвращает nil.
+// Смена почты доступна, если передана отправка писем.
+func initOAuth(
+	cfg config.OAuth,
+	redis *redis.Service,
+	vaultClient *vault.Client,
+	issuer *token.Issuer,
+	sender *mail.Sender,
+	revocations *revocation.Service,
+	stateKeys *statekey.Ring,
+) *oauth.Service {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	names := make([]string, 0, len(cfg.Providers))
+	for _, p := range cfg.Providers {
+		names = append(names, p.Name)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"providers":   names,
+		"success_url": cfg.SuccessURL,
+	}).Info("initializing oauth")
+
+	client, err := redis.Client()
+	startService(err, "redis client")
+
+	opts := []oauth.Option{
+		oauth.WithClient(client),
+		oauth.WithIssuer(issuer),
+		oauth.WithSecrets(vaultClient),
+		oauth.WithSuccessURL(cfg.SuccessURL),
+	}
+
+	for _, p := range cfg.Providers {
+		opts = append(opts, oauth.WithProvider(oauth.Provider{
+			Name:            p.Name,
+			Kind:            p.Kind,
+			AuthURL:         p.AuthURL,
+			TokenURL:        p.TokenURL,
+			UserInfoURL:     p.UserInfoURL,
+			Scopes:          p.Scopes,
+			RedirectURL:     p.RedirectURL,
+			CredentialsPath: p.CredentialsPath,
+		}))
+	}
+
+	if cfg.StateTTL != 0 {
+		opts = append(opts, oauth.WithStateTTL(cfg.StateTTL))
+	}
+
+	tokenTTL := cfg.TokenTTL
+	if tokenTTL == 0 {
+		tokenTTL = oauth.DefaultTokenTTL
+	}
+
+	opts = append(opts, oauth.WithToken(tokenTTL, cfg.Audience))
+
+	if stateKeys != nil {
+		opts = append(opts, oauth.WithStateSealer(stateKeys))
+	}
+
+	opts = append(opts, emailChangeOptions(cfg.EmailChange, sender, revocations)...)
+
+	return start(oauth.New(opts...))
+}
+
+// emailChangeOptions возвращает опции смены почты.
+func emailChangeOptions(cfg config.OAuthEmailChange, sender *mail.Sender, revocations *revocation.Service) []oauth.Option {
+	if sender == nil {
+		return nil
+	}
+
+	opts := []oauth.Option{oauth.WithMail(sender), oauth.WithEmailChangeURL(cfg.ConfirmURL)}
+
+	if cfg.TTL != 0 {
+		opts = append(opts, oauth.WithEmailChangeTTL(cfg.TTL))
+	}
+
+	if cfg.RevokeSessions {
+		if revocations == nil {
+			logrus.Fatal("oauth.email_change.revoke_sessions requires revocation to be enabled")
+		}
+
+		opts = append(opts, oauth.WithSessionRevoker(revocations))
+	}
+
+	return opts
+}
+
+// initNotify создает уведомления пользователей о событиях безопасности, если они включены. Иначе возвращает nil.
+// Канал email доступен, если включены отправка писем и вход через OAuth (почта пользователей хранится в нем),
+// канал telegram - если включен stream событий.
+func initNotify(
+	cfg config.Notifications,
+	redis *redis.Service,
+	sender *mail.Sender,
+	federation *oauth.Service,
+	events *event.Publisher,
+) *notify.Service {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"email":    sender != nil && federation != nil,
+		"telegram": cfg.Telegram && events != nil,
+	}).Info("initializing notifications")
+
+	client, err := redis.Client()
+	startService(err, "redis client")
+
+	opts := []notify.Option{
+		notify.WithClient(client),
+		notify.WithDefaults(notify.Preferences{
+			NewLogin:       cfg.Defaults.NewLogin,
+			PasswordChange: cfg.Defaults.PasswordChange,
+			Channel:        cfg.Defaults.Channel,
+		}),
+	}
+
+	if sender != nil && federation != nil {
+		opts = append(opts, notify.WithEmail(sender, federation))
+	}
+
+	if cfg.Telegram && events != nil {
+		opts = append(opts, notify.WithTelegram(events))
+	}
+
+	return start(notify.New(opts...))
+}
+
+// initMail создает отправку писем, если она включена. Иначе возвращает nil.
+func initMail(cfg config.Mail, vaultClient *vault.Client) *mail.Sender {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"server": cfg.Server,
+		"from":   cfg.From,
+	}).Info("initializing mail")
+
+	opts := []mail.Option{
+		mail.WithServer(cfg.Server),
+		mail.WithFrom(cfg.From),
+	}
+
+	if cfg.Timeout != 0 {
+		opts = append(opts, mail.WithTimeout(cfg.Timeout))
+	}
+
+	if cfg.CredentialsPath != "" {
+		opts = append(opts, mail.WithCredentials(vaultClient, cfg.CredentialsPath))
+	}
+
+	return start(mail.New(opts...))
+}
+
+func initSPIFFE(ctx context.Context, cfg config.SPIFFE, vaultClient *vault.Client) *spiffe.Service {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"trust_domain":  cfg.TrustDomain,
+		"ttl":           cfg.TTL,
+		"pki_sign_path": cfg.PKISignPath,
+		"jwt":           cfg.JWT,
+		"jwt_audiences": cfg.JWTAudiences,
+		"workloads":     len(cfg.Workloads),
+	}).Warn("initializing experimental spiffe svid issuance")
+
+	workloads := make([]spiffe.Workload, 0, len(cfg.Workloads))
+	for _, w := range cfg.Workloads {
+		workloads = append(workloads, spiffe.Workload{Path: w.Path, TokenSHA256: w.TokenSHA256})
+	}
+
+	opts := []spiffe.Option{
+		spiffe.WithTrustDomain(cfg.TrustDomain),
+		spiffe.WithWorkloads(workloads),
+	}
+
+	if cfg.TTL != 0 {
+		opts = append(opts, spiffe.WithTTL(cfg.TTL))
+	}
+
+	if cfg.PKISignPath != "" {
+		opts = append(opts, spiffe.WithX509Signer(vaultClient, cfg.PKISignPath))
+	}
+
+	if cfg.JWT {
+		key, err := spiffe.LoadJWTKey(ctx, vaultClient, cfg.JWTKeyPath)
+		startService(err, "spiffe jwt key")
+
+		opts = append(opts, spiffe.WithJWTKey(key), spiffe.WithJWTAudiences(cfg.JWTAudiences))
+	}
+
+	return start(spiffe.New(opts...))
+}
+
+// sandboxTTL возвращает время жизни данных песочницы.
+func sandboxTTL(cfg config.Sandbox) time.Duration {
+	if cfg.TTL == 0 {
+		return token.MaxSandboxTTL
+	}
+
+	return cfg.TTL
+}
+
+func quotaLimits(cfg config.QuotaLimits) quota.Limits {
+	return quota.Limits{Daily: cfg.Daily, Monthly: cfg.Monthly}
+}
+
+func initCapture(cfg config.Capture) *capture.Capture {
+	var opts []capture.Option
+
+	if cfg.BufferSize != 0 {
+		opts = append(opts, capture.WithBufferSize(cfg.BufferSize))
+	}
+
+	if cfg.MaxBodySize != 0 {
+		opts = append(opts, capture.WithMaxBodySize(cfg.MaxBodySize))
+	}
+
+	return start(capture.New(opts...))
+}
+
+// rateLimitOptions возвращает опции сервера для ограничений частоты запросов.
+func rateLimitOptions(cfg config.RateLimit) []server.Option {
+	opts := []server.Option{
+		server.WithAdminRateLimit(rateLimitRule(cfg.Admin)),
+		server.WithGuestRateLimit(rateLimitRule(cfg.Guest)),
+	}
+
+	if cfg.Observe {
+		logrus.Warn("rate limits are in observe mode: requests over the limit are only logged")
+
+		opts = append(opts, server.WithRateLimitObserver(start(ratelimit.NewObserver(prometheus.DefaultRegisterer))))
+	}
+
+	return opts
+}
+
+func rateLimitRule(cfg config.RateLimitRule) ratelimit.Rule {
+	return ratelimit.Rule{Requests: cfg.Requests, Window: cfg.Window}
+}
+
+// redisAddrs возвращает адреса Redis в зависимости от типа подключения.
+func redisAddrs(cfg config.Redis) []string {
+	if cfg.Type == config.RedisTypeCluster {
+		return cfg.Addrs
+	}
+
+	return []string{fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)}
+}
+
+func registerShutdownHook(butler *Butler, name string, fn ShutdownHook, timeout time.Duration) {
+	startService(butler.RegisterShutdownHook(name, fn, timeout), name+" shutdown hook")
+}
+
+func startService(err error, name string) {
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"service": name,
+		}).Fatalf("error creating service: %+v", err)
+	}
+}
+
+func start[T any](svc T, err error) T {
+	startService(err, fmt.Sprintf("%T", svc))
+
+	return svc
+}
+
+// warnInsecureSettings предупреждает о небезопасных настройках вне dev окружения. В prod они
+// возможны только с явным allow_insecure, иначе конфигурация не загружается.
+func warnInsecureSettings(cfg *config.Config) {
+	if cfg.Env() == config.EnvironmentDev {
+		return
+	}
+
+	for _, setting := range cfg.InsecureSettings() {
+		logrus.WithFields(logrus.Fields{
+			"environment":    cfg.Env(),
+			"allow_insecure": cfg.AllowInsecure,
+		}).Warn("insecure setting: " + setting)
+	}
+}
+
+// updateSwaggerHost обновляет host в swagger документации на основе конфигурации сервера.
+func updateSwaggerHost(cfg config.Server) {
+	if cfg.ExternalURL == "" && cfg.SwaggerHost != "" {
+		logrus.Warn("server.swagger_host is deprecated, use server.external_url")
+	}
+
+	host, basePath, schemes := swaggerTarget(cfg)
+
+	docs.SwaggerInfo.Host = host
+
+	if basePath != "" {
+		docs.SwaggerInfo.BasePath = basePath
+		docs.SwaggerInfo.Schemes = schemes
+	}
+
+	logrus.WithField("swagger_host", host).Debug("swagger host updated")
+}
+
+// swaggerTarget возвращает host, базовый путь и схемы swagger документации. Если задан внешний адрес
+// сервиса, берутся его host, схема и путь. Иначе используется устаревший swagger_host, а без него
+// host формируется из localhost и порта, базовый путь и схемы не меняются.
+func swaggerTarget(cfg config.Server) (string, string, []string) {
+	if cfg.ExternalURL != "" {
+		// адрес проверен при загрузке конфигурации
+		u, _ := url.Parse(cfg.ExternalURL)
+
+		return u.Host, path.Join("/", u.Path, "api/v0"), []string{u.Scheme}
+	}
+
+	if cfg.SwaggerHost != "" {
+		return cfg.SwaggerHost, "", nil
+	}
+
+	return fmt.Sprintf("localhost:%d", cfg.Port), "", nil
 }
@@ -4,9 +4,36 @@ import (
 	"auth-service/docs"
 	handlerV0 "auth-service/internal/api/v0"
 	"auth-service/internal/config"
+	"auth-service/internal/service/codec"
+	"auth-service/internal/service/dependency"
+	"auth-service/internal/service/deprecation"
+	"auth-service/internal/service/notify"
+	"auth-service/internal/service/oauth"
+	"auth-service/internal/service/redis"
+	"auth-service/internal/service/servercert"
+	"auth-service/internal/service/telegram"
+	"auth-service/internal/service/token"
+	"auth-service/internal/service/userstore"
+	"auth-service/internal/storage/vault"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -20,7 +47,7 @@ func TestInitHandlerV0(t *testing.T) {
 		GitCommit: "1234567890",
 	}
 
-	hv0 := initHandlerV0(buildInfo)
+	hv0 := initHandlerV0(buildInfo, false, nil, services{})
 	require.NotNil(t, hv0)
 
 	assert.Equal(t, handlerV0.Version0, hv0.Version())
@@ -35,16 +62,101 @@ func TestInitServer(t *testing.T) {
 		GitCommit: "1234567890",
 	}
 
-	handlerV0 := initHandlerV0(buildInfo)
+	handlerV0 := initHandlerV0(buildInfo, false, nil, services{})
 	require.NotNil(t, handlerV0)
 
-	server := initServer(handlerV0, config.Server{
-		Port:            8080,
-		ShutdownTimeout: 10 * time.Second,
-	})
+	server := initServer(handlerV0, &config.Config{
+		Server: config.Server{
+			Port:            8080,
+			ShutdownTimeout: 10 * time.Second,
+		},
+		RateLimit: config.RateLimit{
+			Admin:   config.RateLimitRule{Requests: 10, Window: time.Minute},
+			Observe: true,
+		},
+		ProofOfWork: config.ProofOfWork{Routes: []string{"/api/v0/otp/request"}},
+	}, nil, services{})
 	require.NotNil(t, server)
 }
 
+func TestRateLimitOptions(t *testing.T) {
+	t.Parallel()
+
+	assert.Len(t, rateLimitOptions(config.RateLimit{}), 2)
+	assert.Len(t, rateLimitOptions(config.RateLimit{Observe: true}), 3)
+}
+
+func TestInitLoadShedding(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initLoadShedding(config.LoadShedding{}))
+
+	assert.Nil(t, vaultLatency(nil))
+
+	// метрики регистрируются в общем реестре, поэтому включенный сброс нагрузки создается один раз
+	shedder := initLoadShedding(config.LoadShedding{
+		Enabled: true,
+		Classes: map[string]config.LoadSheddingLimit{"issuance": {
+			MaxInFlight: 4,
+			Budget:      time.Second,
+			Adaptive:    config.LoadSheddingAdaptive{Enabled: true, MinInFlight: 2},
+		}},
+	})
+	require.NotNil(t, shedder)
+	assert.Len(t, vaultLatency(shedder), 1)
+}
+
+func TestVaultRenewal(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, vaultRenewal(config.VaultRenewal{}, func() {}))
+	assert.Len(t, vaultRenewal(config.VaultRenewal{Enabled: true}, func() {}), 1)
+	assert.Len(t, vaultRenewal(config.VaultRenewal{Enabled: true, Increment: time.Hour}, func() {}), 2)
+}
+
+func TestInitSLO(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initSLO(config.SLO{}))
+
+	// метрики регистрируются в общем реестре, поэтому включенный учет создается один раз
+	tracker := initSLO(config.SLO{
+		Enabled: true,
+		Classes: map[string]config.SLOObjective{"issuance": {BadStatuses: []string{"5xx", "429"}, Latency: time.Second}},
+	})
+	require.NotNil(t, tracker)
+}
+
+func TestInitProofOfWork(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initProofOfWork(config.ProofOfWork{}))
+
+	svc := initProofOfWork(config.ProofOfWork{
+		Routes:     []string{"/api/v0/otp/request"},
+		Difficulty: 12,
+		TTL:        time.Minute,
+		Secret:     "secret",
+	})
+	require.NotNil(t, svc)
+	assert.Equal(t, 12, svc.Difficulty())
+}
+
+func TestInitSecurityTxt(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initSecurityTxt(config.SecurityTxt{}))
+
+	file := initSecurityTxt(config.SecurityTxt{
+		Enabled:            true,
+		Contacts:           []string{"mailto:security@example.com"},
+		Expires:            "2027-01-01T00:00:00Z",
+		PreferredLanguages: []string{"ru", "en"},
+	})
+	require.NotNil(t, file)
+	assert.Equal(t, "Contact: mailto:security@example.com\nExpires: 2027-01-01T00:00:00Z\nPreferred-Languages: ru, en\n", file.String())
+}
+
 func TestUpdateSwaggerHost(t *testing.T) {
 	t.Parallel()
 
@@ -74,6 +186,614 @@ func TestUpdateSwaggerHost(t *testing.T) {
 	}
 }
 
+func TestSwaggerTarget(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		cfg          config.Server
+		wantHost     string
+		wantBasePath string
+		wantSchemes  []string
+	}{
+		{
+			name:         "positive case: external url",
+			cfg:          config.Server{Port: 8080, ExternalURL: "https://auth.zanuda.example/auth", SwaggerHost: "localhost:1234"},
+			wantHost:     "auth.zanuda.example",
+			wantBasePath: "/auth/api/v0",
+			wantSchemes:  []string{"https"},
+		},
+		{
+			name:         "positive case: external url without path",
+			cfg:          config.Server{Port: 8080, ExternalURL: "http://localhost:8080"},
+			wantHost:     "localhost:8080",
+			wantBasePath: "/api/v0",
+			wantSchemes:  []string{"http"},
+		},
+		{
+			name:     "positive case: deprecated swagger host",
+			cfg:      config.Server{Port: 8080, SwaggerHost: "localhost:1234"},
+			wantHost: "localhost:1234",
+		},
+		{
+			name:     "positive case: default",
+			cfg:      config.Server{Port: 8080},
+			wantHost: "localhost:8080",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			host, basePath, schemes := swaggerTarget(tt.cfg)
+			assert.Equal(t, tt.wantHost, host)
+			assert.Equal(t, tt.wantBasePath, basePath)
+			assert.Equal(t, tt.wantSchemes, schemes)
+		})
+	}
+}
+
+func TestInitQuota(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initQuota(config.Quota{}, config.RateLimit{}, nil))
+
+	mr := miniredis.RunT(t)
+
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+
+	// хуки Redis используют уже зарегистрированные метрики, поэтому проверка команд включается в любом тесте
+	redisCfg := config.Redis{
+		Type: config.RedisTypeSingle, Host: mr.Host(), Port: port,
+		Inspect: config.RedisInspect{Enabled: true, BigPayload: 1 << 10},
+	}
+	redis := initRedisStorage(t.Context(), redisCfg)
+
+	t.Cleanup(func() { _ = redis.Stop(context.Background()) })
+
+	svc := initQuota(config.Quota{
+		Enabled: true,
+		Default: config.QuotaLimits{Daily: 100},
+		Keys:    map[string]config.QuotaLimits{"partner": {Monthly: 1000}},
+	}, config.RateLimit{
+		APIKey: config.RateLimitRule{Requests: 60, Window: time.Minute},
+		Tiers:  map[string]config.RateLimitRule{"partner": {Requests: 600, Window: time.Minute}},
+	}, redis)
+	require.NotNil(t, svc)
+}
+
+func TestInitClockDrift(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initClockDrift(config.ClockDrift{}, nil, nil))
+
+	// метрики регистрируются в общем реестре, поэтому включенная проверка создается один раз
+	monitor := initClockDrift(config.ClockDrift{
+		Enabled:   true,
+		Interval:  time.Minute,
+		Threshold: time.Second,
+		NTPServer: "127.0.0.1:123",
+	}, &vault.Client{}, &redis.Service{})
+	require.NotNil(t, monitor)
+}
+
+func TestInitTracing(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initTracing(config.Tracing{}, "1.0.0"))
+	assert.Nil(t, vaultTracing(nil))
+	assert.Nil(t, redisTracing(nil))
+
+	tracer := initTracing(config.Tracing{
+		Enabled:     true,
+		SampleRatio: 0.5,
+		Exporter: config.TracingExporter{
+			Protocol: "http",
+			Endpoint: "127.0.0.1:4318",
+			Insecure: true,
+			Timeout:  time.Second,
+		},
+	}, "1.0.0")
+	require.NotNil(t, tracer)
+
+	t.Cleanup(func() { _ = tracer.Shutdown(context.Background()) })
+
+	assert.Len(t, vaultTracing(tracer), 1)
+	assert.Len(t, redisTracing(tracer), 1)
+
+	cfg := &config.Config{Server: config.Server{ShutdownTimeout: 3 * time.Second}}
+	assert.Equal(t, 3*time.Second, tracingShutdownTimeout(cfg))
+
+	cfg.Tracing.ShutdownTimeout = time.Second
+	assert.Equal(t, time.Second, tracingShutdownTimeout(cfg))
+}
+
+func TestInitJanitor(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initJanitor(config.RedisJanitor{}, nil))
+
+	mr := miniredis.RunT(t)
+
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+
+	redis := initRedisStorage(t.Context(), config.Redis{Type: config.RedisTypeSingle, Host: mr.Host(), Port: port})
+
+	t.Cleanup(func() { _ = redis.Stop(context.Background()) })
+
+	// метрики регистрируются в общем реестре, поэтому включенный janitor создается один раз
+	janitor := initJanitor(config.RedisJanitor{
+		Enabled:  true,
+		Interval: time.Minute,
+		Delete:   true,
+		Patterns: []string{"auth:qrlogin:*"},
+	}, redis)
+	require.NotNil(t, janitor)
+}
+
+func TestInitRevocation(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initRevocation(config.Revocation{}, 0, 0, nil, nil, nil))
+	assert.Nil(t, initEvents(config.Events{}, nil))
+
+	mr := miniredis.RunT(t)
+
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+
+	redis := initRedisStorage(t.Context(), config.Redis{Type: config.RedisTypeSingle, Host: mr.Host(), Port: port})
+
+	t.Cleanup(func() { _ = redis.Stop(context.Background()) })
+
+	jobs := initJobs(config.Jobs{TTL: time.Hour}, redis)
+	require.NotNil(t, jobs)
+
+	events := initEvents(config.Events{Enabled: true, Stream: "events", MaxLen: 100}, redis)
+	require.NotNil(t, events)
+
+	svc := initRevocation(config.Revocation{Enabled: true, Retention: 24 * time.Hour}, 0, time.Minute, redis, jobs, events)
+	require.NotNil(t, svc)
+
+	assert.Nil(t, initTTLCheck(config.RedisTTL{}, redis, svc, nil))
+	assert.Nil(t, initTTLCheck(config.RedisTTL{Check: config.RedisTTLCheck{Enabled: true}}, redis, nil, nil))
+
+	// метрики регистрируются в общем реестре, поэтому включенная проверка TTL создается один раз
+	ttlCheck := config.RedisTTL{Slack: time.Minute, Check: config.RedisTTLCheck{Enabled: true, Interval: time.Hour, Tolerance: time.Minute}}
+	require.NotNil(t, initTTLCheck(ttlCheck, redis, svc, nil))
+
+	assert.Nil(t, initReplication(t.Context(), NewButler(), &config.Config{}, svc))
+
+	// метрики регистрируются в общем реестре, поэтому включенная репликация создается один раз
+	peer := miniredis.RunT(t)
+
+	peerPort, err := strconv.Atoi(peer.Port())
+	require.NoError(t, err)
+
+	butler := NewButler()
+	replicator := initReplication(t.Context(), butler, &config.Config{
+		Events: config.Events{Enabled: true, Region: "eu"},
+		Revocation: config.Revocation{Replication: config.RevocationReplication{
+			Enabled: true,
+			Peers:   map[string]config.Redis{"us": {Type: config.RedisTypeSingle, Host: peer.Host(), Port: peerPort}},
+		}},
+	}, svc)
+	require.NotNil(t, replicator)
+
+	butler.shutdown(t.Context())
+}
+
+func TestInitAbuse(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initAbuse(config.Abuse{}, nil, nil))
+
+	mr := miniredis.RunT(t)
+
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+
+	redis := initRedisStorage(t.Context(), config.Redis{Type: config.RedisTypeSingle, Host: mr.Host(), Port: port})
+
+	t.Cleanup(func() { _ = redis.Stop(context.Background()) })
+
+	svc := initAbuse(config.Abuse{
+		Enabled:           true,
+		Window:            time.Minute,
+		BanTTL:            time.Hour,
+		IPUniqueUsernames: 10,
+		IPv4Prefix:        16,
+		Honeypots:         []string{"/.env"},
+	}, redis, nil)
+	require.NotNil(t, svc)
+}
+
+func TestInitNotify(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initNotify(config.Notifications{}, nil, nil, nil, nil))
+
+	mr := miniredis.RunT(t)
+
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+
+	redis := initRedisStorage(t.Context(), config.Redis{Type: config.RedisTypeSingle, Host: mr.Host(), Port: port})
+
+	t.Cleanup(func() { _ = redis.Stop(context.Background()) })
+
+	events := initEvents(config.Events{Enabled: true}, redis)
+
+	notifier := initNotify(config.Notifications{
+		Enabled:  true,
+		Telegram: true,
+		Defaults: config.NotificationDefaults{PasswordChange: true},
+	}, redis, nil, nil, events)
+	require.NotNil(t, notifier)
+	assert.Equal(t, []string{"telegram"}, notifier.Channels())
+
+	p, err := notifier.Preferences(t.Context(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, notify.Preferences{PasswordChange: true, Channel: "telegram"}, p)
+}
+
+func TestInitDeprecations(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initDeprecations(config.Deprecations{}))
+
+	registry := initDeprecations(config.Deprecations{
+		LogInterval: time.Hour,
+		Routes: []config.DeprecatedRoute{
+			{Method: http.MethodGet, Path: "/api/v0/health", Since: "2026-01-01", Sunset: "2026-07-01", Successor: "/api/v1/health"},
+		},
+	})
+	require.NotNil(t, registry)
+
+	assert.Equal(t, []deprecation.Route{{
+		Method:    http.MethodGet,
+		Path:      "/api/v0/health",
+		Since:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Sunset:    time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+		Successor: "/api/v1/health",
+	}}, registry.Routes())
+}
+
+func TestInitBreach(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initBreach(config.PasswordBreach{}))
+	assert.NotNil(t, initBreach(config.PasswordBreach{Enabled: true, URL: "https://hibp.internal", Timeout: time.Second, MinCount: 3}))
+}
+
+func TestInitCredentialsPolicy(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initCredentialsPolicy(config.CredentialsPolicy{}))
+
+	policy := initCredentialsPolicy(config.CredentialsPolicy{
+		Enabled:  true,
+		Password: config.PasswordPolicy{MinLength: 10},
+		Username: config.UsernamePolicy{Pattern: "[a-z]+", Reserved: []string{"admin"}},
+	})
+	require.NotNil(t, policy)
+	assert.Len(t, policy.Check("admin", "short"), 2)
+}
+
+func TestInitUserLogin(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initQRLogin(config.QRLogin{}, nil, nil))
+
+	mr := miniredis.RunT(t)
+
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+
+	redis := initRedisStorage(t.Context(), config.Redis{Type: config.RedisTypeSingle, Host: mr.Host(), Port: port})
+
+	t.Cleanup(func() { _ = redis.Stop(context.Background()) })
+
+	vaultClient := initVaultClient(config.Vault{
+		Address:         "https://localhost:8200",
+		Token:           "vault-token",
+		InsecureSkipTLS: true,
+	})
+
+	keys := initSigningKeys(config.Token{}, vaultClient, prometheus.NewRegistry())
+	issuer := initIssuer(config.Token{}, "", config.Sandbox{}, keys, nil, nil, nil)
+
+	svc := initQRLogin(config.QRLogin{
+		Enabled:       true,
+		TTL:           time.Minute,
+		Audience:      []string{"dashboard"},
+		PayloadPrefix: "https://t.me/zanuda_bot?start=login-",
+	}, redis, issuer)
+	require.NotNil(t, svc)
+
+	assert.Nil(t, initWebAuthn(config.WebAuthn{}, nil, nil))
+
+	passkeys := initWebAuthn(config.WebAuthn{
+		Enabled:          true,
+		RPID:             "zanuda.example",
+		Origins:          []string{"https://zanuda.example"},
+		Timeout:          time.Minute,
+		UserVerification: "required",
+		Audience:         []string{"dashboard"},
+	}, redis, issuer)
+	require.NotNil(t, passkeys)
+
+	assert.Nil(t, initOAuth(config.OAuth{}, nil, nil, nil, nil, nil, nil))
+
+	federation := initOAuth(config.OAuth{
+		Enabled:    true,
+		SuccessURL: "https://zanuda.example/login",
+		StateTTL:   5 * time.Minute,
+		Providers: []config.OAuthProvider{
+			{Name: "google", Kind: "google", RedirectURL: "https://auth.zanuda.example/api/v0/oauth/google/callback"},
+			{Name: "github", Kind: "github", RedirectURL: "https://auth.zanuda.example/api/v0/oauth/github/callback"},
+		},
+	}, redis, vaultClient, issuer, nil, nil, nil)
+	require.NotNil(t, federation)
+	assert.Equal(t, []string{"github", "google"}, federation.Providers())
+
+	// смена почты без отправки писем недоступна
+	_, err = federation.StartEmailChange(t.Context(), "user-1", "", "new@zanuda.example")
+	require.ErrorIs(t, err, oauth.ErrEmailChangeDisabled)
+
+	assert.Nil(t, initMail(config.Mail{}, nil))
+
+	sender := initMail(config.Mail{
+		Enabled:         true,
+		Server:          "smtp.zanuda.example:587",
+		From:            "Zanuda <auth@zanuda.example>",
+		Timeout:         5 * time.Second,
+		CredentialsPath: "secret/data/auth/smtp",
+	}, vaultClient)
+	require.NotNil(t, sender)
+
+	jobs := initJobs(config.Jobs{TTL: time.Hour}, redis)
+	revocations := initRevocation(config.Revocation{Enabled: true}, 0, 0, redis, jobs, nil)
+
+	federation = initOAuth(config.OAuth{
+		Enabled:     true,
+		Providers:   []config.OAuthProvider{{Name: "google", Kind: "google", RedirectURL: "https://auth.zanuda.example/cb"}},
+		EmailChange: config.OAuthEmailChange{TTL: time.Hour, ConfirmURL: "https://zanuda.example/account/email", RevokeSessions: true},
+	}, redis, vaultClient, issuer, sender, revocations, nil)
+	require.NotNil(t, federation)
+}
+
+func TestInitLDAP(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initLDAP(t.Context(), config.LDAP{}, nil, nil, nil))
+	assert.Nil(t, initSCIM(config.SCIM{}, nil, nil))
+
+	mr := miniredis.RunT(t)
+
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+
+	redis := initRedisStorage(t.Context(), config.Redis{Type: config.RedisTypeSingle, Host: mr.Host(), Port: port})
+
+	t.Cleanup(func() { _ = redis.Stop(context.Background()) })
+
+	jobs := initJobs(config.Jobs{TTL: time.Hour}, redis)
+	revocations := initRevocation(config.Revocation{Enabled: true}, 0, 0, redis, jobs, nil)
+
+	accounts := initSCIM(config.SCIM{Enabled: true, TokenSHA256: strings.Repeat("0", 64)}, redis, revocations)
+	require.NotNil(t, accounts)
+
+	vaultClient := initVaultClient(config.Vault{
+		Address:         "https://localhost:8200",
+		Token:           "vault-token",
+		InsecureSkipTLS: true,
+	})
+
+	keys := initSigningKeys(config.Token{}, vaultClient, prometheus.NewRegistry())
+	issuer := initIssuer(config.Token{}, "", config.Sandbox{}, keys, nil, nil, nil)
+
+	// без сервисной учетной записи Vault не читается
+	directory := initLDAP(t.Context(), config.LDAP{
+		Enabled:     true,
+		URL:         "ldaps://ldap.example.org",
+		BaseDN:      "ou=people,dc=example,dc=org",
+		UserFilter:  "(&(objectClass=user)(sAMAccountName={username}))",
+		GroupFilter: "(&(objectClass=groupOfNames)(member={dn}))",
+		GroupRoles:  map[string][]string{"cn=admins,dc=example,dc=org": {"operator"}},
+		Timeout:     time.Second,
+		TokenTTL:    time.Hour,
+	}, vaultClient, issuer, accounts)
+	require.NotNil(t, directory)
+}
+
+func TestLDAPTLSConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ldapTLSConfig("")
+	require.NoError(t, err)
+	assert.Nil(t, cfg.RootCAs)
+
+	_, err = ldapTLSConfig(filepath.Join(t.TempDir(), "missing.pem"))
+	require.Error(t, err)
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(empty, []byte("not a certificate"), 0o600))
+
+	_, err = ldapTLSConfig(empty)
+	require.Error(t, err)
+}
+
+func TestInitDrain(t *testing.T) {
+	t.Parallel()
+
+	drainer := initDrain(config.Drain{Delay: time.Minute, MaxDelay: time.Hour}, func() {})
+	require.NotNil(t, drainer)
+	assert.True(t, drainer.Ready())
+
+	state, err := drainer.Drain(0)
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, state.ExitAt.Sub(*state.Since))
+}
+
+func TestInitReadOnly(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initReadOnly(config.ReadOnly{}))
+	assert.Nil(t, readOnlyHooks(config.ReadOnly{Auto: true}, nil))
+
+	// метрики регистрируются в общем реестре, поэтому включенный режим создается один раз
+	cfg := config.ReadOnly{Enabled: true, FailureThreshold: 1, Cooldown: time.Minute}
+
+	mode := initReadOnly(cfg)
+	require.NotNil(t, mode)
+	assert.Empty(t, readOnlyHooks(cfg, mode))
+
+	cfg.Auto = true
+	assert.Len(t, readOnlyHooks(cfg, mode), 1)
+
+	mode.ObserveWrite(errors.New("READONLY You can't write against a read only replica."))
+	assert.True(t, mode.Status().Enabled)
+}
+
+func TestInitLogSampling(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initLogSampling(config.LogSampling{}, nil))
+
+	mr := miniredis.RunT(t)
+
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+
+	redis := initRedisStorage(t.Context(), config.Redis{Type: config.RedisTypeSingle, Host: mr.Host(), Port: port})
+
+	t.Cleanup(func() { _ = redis.Stop(context.Background()) })
+
+	sampler := initLogSampling(config.LogSampling{Enabled: true, ReloadInterval: time.Second}, redis)
+	require.NotNil(t, sampler)
+	assert.Empty(t, sampler.Rates())
+}
+
+func TestInitStats(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initStats(config.Stats{}, nil))
+
+	mr := miniredis.RunT(t)
+
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+
+	redis := initRedisStorage(t.Context(), config.Redis{Type: config.RedisTypeSingle, Host: mr.Host(), Port: port})
+
+	t.Cleanup(func() { _ = redis.Stop(context.Background()) })
+
+	analytics := initStats(config.Stats{Enabled: true, Retention: 30 * 24 * time.Hour}, redis)
+	require.NotNil(t, analytics)
+	assert.Equal(t, 30, analytics.MaxDays())
+}
+
+func TestInitAPIKeys(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initAPIKeys(config.APIKeys{}, config.Sandbox{}, nil, nil))
+
+	mr := miniredis.RunT(t)
+
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+
+	redis := initRedisStorage(t.Context(), config.Redis{Type: config.RedisTypeSingle, Host: mr.Host(), Port: port})
+
+	t.Cleanup(func() { _ = redis.Stop(context.Background()) })
+
+	vaultClient := initVaultClient(config.Vault{Address: "https://localhost:8200", Token: "vault-token", CAPath: "/path/to/ca.pem"})
+
+	require.NotNil(t, initAPIKeys(config.APIKeys{Enabled: true}, config.Sandbox{TTL: time.Hour}, redis, nil))
+	require.NotNil(t, initAPIKeys(config.APIKeys{Enabled: true, VaultPath: "secret/data/apikeys"}, config.Sandbox{}, redis, vaultClient))
+}
+
+func TestInitServerCert(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initServerCert(t.Context(), config.ServerVaultPKI{}, nil))
+}
+
+func TestServerTLSConfig(t *testing.T) {
+	t.Parallel()
+
+	got, err := serverTLSConfig(config.ServerTLS{}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	_, err = serverTLSConfig(config.ServerTLS{ClientCAPath: "ca.pem"}, nil)
+	require.ErrorContains(t, err, "client ca requires server certificate")
+
+	cert, err := servercert.New(
+		servercert.WithIssuer(&vault.Client{}, "pki_int/issue/auth-service"),
+		servercert.WithRequest(vault.IssueRequest{CommonName: "auth"}),
+	)
+	require.NoError(t, err)
+
+	got, err = serverTLSConfig(config.ServerTLS{}, cert)
+	require.NoError(t, err)
+	assert.Equal(t, tls.NoClientCert, got.ClientAuth)
+	assert.NotNil(t, got.GetCertificate)
+
+	dir := t.TempDir()
+
+	_, err = serverTLSConfig(config.ServerTLS{ClientCAPath: filepath.Join(dir, "missing.pem")}, cert)
+	require.ErrorContains(t, err, "error read client ca")
+
+	empty := filepath.Join(dir, "empty.pem")
+	require.NoError(t, os.WriteFile(empty, []byte("not a pem"), 0o600))
+
+	_, err = serverTLSConfig(config.ServerTLS{ClientCAPath: empty}, cert)
+	require.ErrorContains(t, err, "does not contain certificates")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{SerialNumber: big.NewInt(1), IsCA: true, NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	ca := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+
+	got, err = serverTLSConfig(config.ServerTLS{ClientCAPath: ca}, cert)
+	require.NoError(t, err)
+	assert.Equal(t, tls.VerifyClientCertIfGiven, got.ClientAuth)
+	assert.NotNil(t, got.ClientCAs)
+}
+
+func TestInitSPIFFE(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initSPIFFE(t.Context(), config.SPIFFE{}, nil))
+
+	vaultClient := initVaultClient(config.Vault{Address: "https://localhost:8200", Token: "vault-token", CAPath: "/path/to/ca.pem"})
+
+	svc := initSPIFFE(t.Context(), config.SPIFFE{
+		Enabled:     true,
+		TrustDomain: "example.org",
+		TTL:         time.Minute,
+		PKISignPath: "pki_int/sign/spiffe",
+		Workloads: []config.SPIFFEWorkload{
+			{Path: "/bot", TokenSHA256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"},
+		},
+	}, vaultClient)
+	require.NotNil(t, svc)
+
+	id, err := svc.Authenticate("test")
+	require.NoError(t, err)
+	assert.Equal(t, "spiffe://example.org/bot", id)
+}
+
 func TestInitVaultClient(t *testing.T) {
 	t.Parallel()
 
@@ -86,3 +806,268 @@ func TestInitVaultClient(t *testing.T) {
 	vaultClient := initVaultClient(cfg)
 	require.NotNil(t, vaultClient)
 }
+
+func TestInitWarmup(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initWarmup(config.Warmup{}, nil, nil, nil, nil))
+
+	vaultClient := initVaultClient(config.Vault{
+		Address:         "https://localhost:8200",
+		Token:           "vault-token",
+		InsecureSkipTLS: true,
+	})
+
+	keys := initSigningKeys(config.Token{}, vaultClient, prometheus.NewRegistry())
+
+	redisCfg := config.Redis{Type: config.RedisTypeSingle, Host: "localhost", Port: 6379}
+
+	redis, err := redis.New(redis.WithCfg(&redisCfg))
+	require.NoError(t, err)
+
+	runner := initWarmup(config.Warmup{
+		Enabled:          true,
+		Timeout:          time.Second,
+		RedisConnections: 2,
+	}, keys, redis, initIssuer(config.Token{}, "", config.Sandbox{}, keys, nil, nil, nil), initValidator(config.Token{}, "", keys, nil, nil, nil))
+	require.NotNil(t, runner)
+}
+
+func TestInitDependencies(t *testing.T) {
+	t.Parallel()
+
+	vaultClient := initVaultClient(config.Vault{
+		Address:         "https://localhost:8200",
+		Token:           "vault-token",
+		InsecureSkipTLS: true,
+	})
+
+	redisCfg := config.Redis{Type: config.RedisTypeSingle, Host: "localhost", Port: 6379}
+
+	redis, err := redis.New(redis.WithCfg(&redisCfg))
+	require.NoError(t, err)
+
+	deps := initDependencies(config.Dependencies{
+		CheckInterval: time.Second,
+		RetryAfter:    3 * time.Second,
+	}, vaultClient, redis, nil)
+	require.NotNil(t, deps)
+
+	assert.Equal(t, 3*time.Second, deps.RetryAfter())
+	assert.Equal(t, dependency.LevelFull, deps.Level())
+	assert.NotContains(t, deps.Statuses(), dependency.UserStore)
+
+	users := initUserStore(t.Context(), config.UserStore{Enabled: true, URL: "http://users.internal"}, vaultClient)
+
+	deps = initDependencies(config.Dependencies{}, vaultClient, redis, users)
+	assert.Contains(t, deps.Statuses(), dependency.UserStore)
+}
+
+func TestInitUserStore(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initUserStore(t.Context(), config.UserStore{}, nil))
+
+	users := initUserStore(t.Context(), config.UserStore{
+		Enabled:    true,
+		URL:        "https://users.internal/api/v1",
+		Timeout:    time.Second,
+		HealthPath: "/ready",
+	}, nil)
+	require.NotNil(t, users)
+
+	mr := miniredis.RunT(t)
+
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+
+	redis := initRedisStorage(t.Context(), config.Redis{Type: config.RedisTypeSingle, Host: mr.Host(), Port: port})
+
+	t.Cleanup(func() { _ = redis.Stop(context.Background()) })
+
+	assert.Nil(t, initUserCache(config.UserCache{Enabled: true}, redis, nil))
+	assert.Equal(t, users, initUserCache(config.UserCache{}, redis, users))
+
+	cached := initUserCache(config.UserCache{Enabled: true, TTL: time.Minute, NegativeTTL: time.Second}, redis, users)
+	assert.IsType(t, &userstore.Cache{}, cached)
+}
+
+func TestInitValidator(t *testing.T) {
+	t.Parallel()
+
+	vaultClient := initVaultClient(config.Vault{
+		Address:         "https://localhost:8200",
+		Token:           "vault-token",
+		InsecureSkipTLS: true,
+	})
+
+	keys := initSigningKeys(config.Token{}, vaultClient, prometheus.NewRegistry())
+
+	validator := initValidator(config.Token{
+		Grace: config.TokenGrace{
+			Period:    time.Minute,
+			Audiences: []string{"telegram-bot"},
+		},
+	}, "https://auth.zanuda.example", keys, nil, nil, nil)
+	require.NotNil(t, validator)
+}
+
+// userTestKeys - ключ подписи и проверки токенов пользователей для тестов.
+type userTestKeys struct{}
+
+func (userTestKeys) Key(_ context.Context, _ string) ([]byte, error) {
+	return []byte("secret"), nil
+}
+
+func (userTestKeys) SigningKey(_ context.Context) (string, []byte, error) {
+	return "key-1", []byte("secret"), nil
+}
+
+func TestInitUserValidator(t *testing.T) {
+	t.Parallel()
+
+	validator, err := token.NewValidator(token.WithKeys(userTestKeys{}))
+	require.NoError(t, err)
+
+	issuer, err := token.NewIssuer(token.WithSigningKeys(userTestKeys{}))
+	require.NoError(t, err)
+
+	issue := func(aud string) string {
+		raw, _, err := issuer.Issue(t.Context(), token.IssueRequest{Subject: "user-1", Audience: []string{aud}, TTL: time.Minute})
+		require.NoError(t, err)
+
+		return raw
+	}
+
+	// без аудиторий API пользователя принимает токены любой аудитории
+	_, err = initUserValidator(validator, nil).Validate(t.Context(), issue("admin"))
+	require.NoError(t, err)
+
+	users := initUserValidator(validator, []string{"web"})
+
+	_, err = users.Validate(t.Context(), issue("web"))
+	require.NoError(t, err)
+
+	_, err = users.Validate(t.Context(), issue("admin"))
+	require.ErrorIs(t, err, token.ErrUnexpectedAudience)
+}
+
+func TestInitKeyRotation(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initKeyRotation(config.TokenRotation{}, nil, nil, nil))
+
+	vaultClient := initVaultClient(config.Vault{
+		Address:         "https://localhost:8200",
+		Token:           "vault-token",
+		InsecureSkipTLS: true,
+	})
+
+	tokenCfg := config.Token{Rotation: config.TokenRotation{Enabled: true, Period: 24 * time.Hour, Grace: time.Hour}}
+	keys := initSigningKeys(tokenCfg, vaultClient, prometheus.NewRegistry())
+
+	issuer := initIssuer(config.Token{}, "", config.Sandbox{}, keys, nil, nil, nil)
+	validator := initValidator(config.Token{}, "", keys, nil, nil, nil)
+
+	require.NotNil(t, initKeyRotation(tokenCfg.Rotation, keys, issuer, validator))
+}
+
+func TestInitCodec(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, codec.FormatJSON, initCodec(config.Redis{}).Format())
+	assert.Equal(t, codec.FormatMsgpack, initCodec(config.Redis{Encoding: "msgpack"}).Format())
+}
+
+func TestInitIssuer(t *testing.T) {
+	t.Parallel()
+
+	vaultClient := initVaultClient(config.Vault{
+		Address:         "https://localhost:8200",
+		Token:           "vault-token",
+		InsecureSkipTLS: true,
+	})
+
+	keys := initSigningKeys(config.Token{}, vaultClient, prometheus.NewRegistry())
+
+	require.NotNil(t, initIssuer(config.Token{}, "", config.Sandbox{}, keys, nil, nil, nil))
+	require.NotNil(t, initIssuer(config.Token{Impersonation: config.Impersonation{MaxTTL: 5 * time.Minute}}, "", config.Sandbox{}, keys, nil, nil, nil))
+	require.NotNil(t, initIssuer(config.Token{Impersonation: config.Impersonation{Scopes: []string{"read:notes"}}}, "", config.Sandbox{}, keys, nil, nil, nil))
+	require.NotNil(t, initIssuer(config.Token{Limits: config.TokenLimits{MaxTokenSize: 2048}}, "", config.Sandbox{}, keys, nil, nil, nil))
+	require.NotNil(t, initIssuer(config.Token{Limits: config.TokenLimits{ForbiddenClaims: []string{"role"}}}, "", config.Sandbox{}, keys, nil, nil, nil))
+	require.NotNil(t, initIssuer(config.Token{}, "https://auth.zanuda.example", config.Sandbox{Audiences: []string{"partner-sandbox"}}, keys, nil, nil, nil))
+
+	issuer := initIssuer(config.Token{ClaimSchemas: map[string]config.TokenClaimSchema{
+		"web": {Properties: map[string]config.TokenClaimProperty{
+			"tags": {Type: "array", Items: &config.TokenClaimProperty{Type: "string", MaxLength: 8}},
+		}},
+	}}, "", config.Sandbox{}, keys, nil, nil, nil)
+	require.NotNil(t, issuer)
+
+	_, _, err := issuer.Issue(t.Context(), token.IssueRequest{
+		Subject:  "user-1",
+		Audience: []string{"web"},
+		TTL:      time.Minute,
+		Claims:   map[string]interface{}{"tags": []string{"much-too-long"}},
+	})
+	require.ErrorContains(t, err, `claim "tags[0]" must be at most 8 characters`)
+}
+
+func TestInitTelegram(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initTelegram(t.Context(), config.Telegram{}, nil))
+}
+
+// telegramKV - секрет Telegram бота в Vault.
+type telegramKV struct{}
+
+func (telegramKV) ReadKV(context.Context, string) (map[string]interface{}, error) {
+	return map[string]interface{}{"bot_token": "123:abc"}, nil
+}
+
+func TestInitTelegramLogin(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initTelegramLogin(config.TelegramLogin{}, nil, nil, nil))
+
+	secrets, err := telegram.New(t.Context(), telegram.WithKVReader(telegramKV{}), telegram.WithRegisterer(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	vaultClient := initVaultClient(config.Vault{
+		Address:         "https://localhost:8200",
+		Token:           "vault-token",
+		InsecureSkipTLS: true,
+	})
+	keys := initSigningKeys(config.Token{}, vaultClient, prometheus.NewRegistry())
+	issuer := initIssuer(config.Token{}, "", config.Sandbox{}, keys, nil, nil, nil)
+
+	require.NotNil(t, initTelegramLogin(config.TelegramLogin{Enabled: true, MaxAge: time.Hour}, secrets, issuer, nil))
+}
+
+func TestInitPeers(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initPeers(t.Context(), config.Peers{}))
+}
+
+func TestInitStateKeys(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initStateKeys(t.Context(), config.StateKeys{}, nil))
+}
+
+func TestInitPolicy(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, initPolicy(t.Context(), config.AuthzPolicy{}, nil))
+
+	engine := initPolicy(t.Context(), config.AuthzPolicy{
+		ModelPath:      "../../policies/authz_model.conf",
+		RulesPath:      "../../policies/authz_policy.csv",
+		ReloadInterval: time.Minute,
+	}, nil)
+	require.NotNil(t, engine)
+
+	require.NotNil(t, initAuthz(config.Authz{DecisionLog: true}, nil, engine))
+}
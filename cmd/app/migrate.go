@@ -0,0 +1,97 @@
+package main
+
+import (
+	"auth-service/internal/cliout"
+	"auth-service/internal/service/keymigrate"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// migrateResult - итог команды migrate-keys. Copied и Skipped - итог копирования (0 при --verify-only),
+// остальные поля - итог проверки.
+type migrateResult struct {
+	From       string   `json:"from"`
+	To         string   `json:"to"`
+	Copied     int      `json:"copied"`
+	Skipped    int      `json:"skipped"`
+	Scanned    int      `json:"scanned"`
+	Missing    int      `json:"missing"`
+	Mismatched int      `json:"mismatched"`
+	Mismatches []string `json:"mismatches,omitempty"`
+}
+
+// runMigrateKeys копирует ключи Redis со старого префикса на новый и проверяет результат:
+//
+//	auth-service migrate-keys --config ./config.yaml --from auth: --to auth2: [--rate 500] [--overwrite] [--verify-only]
+//
+// Возвращает ошибку, если проверка нашла отсутствующие или отличающиеся ключи.
+func runMigrateKeys(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("migrate-keys", flag.ContinueOnError)
+	configPath := fs.String("config", "./config.yaml", "path to config file")
+	from := fs.String("from", "", "old key prefix")
+	to := fs.String("to", "", "new key prefix")
+	rate := fs.Int("rate", 0, "max keys per second, 0 - unlimited")
+	overwrite := fs.Bool("overwrite", false, "overwrite keys that already exist under the new prefix")
+	verifyOnly := fs.Bool("verify-only", false, "only compare keys without copying")
+	output := cliout.FormatTable
+	fs.Var(&output, "o", cliout.FlagUsage)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *from == "" || *to == "" {
+		return errors.New("migrate-keys: --from and --to are required")
+	}
+
+	client, stop, err := commandRedis(ctx, *configPath)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	m, err := keymigrate.New(
+		keymigrate.WithClient(client),
+		keymigrate.WithPrefixes(*from, *to),
+		keymigrate.WithRate(*rate),
+		keymigrate.WithOverwrite(*overwrite),
+	)
+	if err != nil {
+		return err
+	}
+
+	result := migrateResult{From: *from, To: *to}
+
+	if !*verifyOnly {
+		report, err := m.Copy(ctx)
+		if err != nil {
+			return err
+		}
+
+		result.Copied = report.Copied
+		result.Skipped = report.Skipped
+	}
+
+	report, err := m.Verify(ctx)
+	if err != nil {
+		return err
+	}
+
+	result.Scanned = report.Scanned
+	result.Missing = report.Missing
+	result.Mismatched = report.Mismatched
+	result.Mismatches = report.Mismatches
+
+	if err := cliout.Write(os.Stdout, output, result); err != nil {
+		return err
+	}
+
+	if !report.OK() {
+		return fmt.Errorf("migrate-keys: %d missing and %d mismatched keys", report.Missing, report.Mismatched)
+	}
+
+	return nil
+}
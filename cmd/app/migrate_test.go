@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateKeys(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+	mr.HSet("auth:group:1", "name", "family")
+
+	cfg := writeRedisConfig(t, mr)
+
+	// проверка до копирования находит отсутствующие ключи
+	handled, err := runCommand(t.Context(), []string{"migrate-keys", "--config", cfg, "--from", "auth:", "--to", "auth2:", "--verify-only"})
+	require.True(t, handled)
+	require.ErrorContains(t, err, "1 missing")
+
+	_, err = runCommand(t.Context(), []string{"migrate-keys", "--config", cfg, "--from", "auth:", "--to", "auth2:", "--rate", "100"})
+	require.NoError(t, err)
+	assert.Equal(t, "family", mr.HGet("auth2:group:1", "name"))
+
+	_, err = runCommand(t.Context(), []string{"migrate-keys", "--config", cfg, "--from", "auth:"})
+	require.ErrorContains(t, err, "--from and --to are required")
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"auth-service/internal/config"
+	"auth-service/internal/service/oauth"
+	"auth-service/internal/service/preflight"
+	"auth-service/internal/service/statekey"
+	"auth-service/internal/service/telegram"
+	"auth-service/internal/service/token"
+	"auth-service/internal/storage/vault"
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// apiKeyProbeID - ID ключа в пути, на котором проверяются права записи секретов API ключей:
+// секреты записываются в <vault_path>/<id>, а права выдаются на шаблон пути.
+const apiKeyProbeID = "preflight"
+
+// vaultRequirements возвращает права Vault, которые нужны включенным в конфигурации функциям.
+func vaultRequirements(cfg *config.Config) []preflight.Requirement {
+	read := []string{preflight.CapabilityRead}
+	write := []string{preflight.CapabilityCreate, preflight.CapabilityUpdate}
+	// PKI выпускает и подписывает сертификаты запросом POST, для него нужно право update
+	pki := []string{preflight.CapabilityUpdate}
+
+	keysPath := cfg.Token.KeysPath
+	if keysPath == "" {
+		keysPath = token.DefaultKeysPath
+	}
+
+	keysCapabilities := read
+	if cfg.Token.Rotation.Enabled {
+		// ротация записывает новую версию секрета с ключами
+		keysCapabilities = append(read, write...)
+	}
+
+	reqs := []preflight.Requirement{{Purpose: "token.keys_path", Path: keysPath, Capabilities: keysCapabilities}}
+
+	add := func(purpose, path string, capabilities []string) {
+		reqs = append(reqs, preflight.Requirement{Purpose: purpose, Path: path, Capabilities: capabilities})
+	}
+
+	if cfg.Authz.Policy.ModelPath == "" && cfg.Authz.Policy.VaultPath != "" {
+		add("authz.policy.vault_path", cfg.Authz.Policy.VaultPath, read)
+	}
+
+	if cfg.StateKeys.Enabled {
+		// экземпляр, который ротирует ключи, записывает новую версию секрета
+		add("state_keys.vault_path", or(cfg.StateKeys.VaultPath, statekey.DefaultPath), append(read, write...))
+	}
+
+	if cfg.Telegram.Enabled {
+		add("telegram.vault_path", or(cfg.Telegram.VaultPath, telegram.DefaultPath), read)
+	}
+
+	if cfg.UserStore.Enabled && cfg.UserStore.TokenPath != "" {
+		add("user_store.token_path", cfg.UserStore.TokenPath, read)
+	}
+
+	if cfg.Admin.APIKeys.Enabled && cfg.Admin.APIKeys.VaultPath != "" {
+		add("admin.api_keys.vault_path", cfg.Admin.APIKeys.VaultPath+"/"+apiKeyProbeID, write)
+	}
+
+	if cfg.Admin.LDAP.Enabled && cfg.Admin.LDAP.BindDN != "" {
+		add("admin.ldap.bind_password_path", cfg.Admin.LDAP.BindPasswordPath, read)
+	}
+
+	if cfg.OAuth.Enabled {
+		for _, p := range cfg.OAuth.Providers {
+			add(fmt.Sprintf("oauth.providers[%s].credentials_path", p.Name), or(p.CredentialsPath, oauth.DefaultCredentialsPrefix+p.Name), read)
+		}
+	}
+
+	if cfg.Mail.Enabled && cfg.Mail.CredentialsPath != "" {
+		add("mail.credentials_path", cfg.Mail.CredentialsPath, read)
+	}
+
+	if cfg.Server.TLS.VaultPKI.IssuePath != "" {
+		add("server.tls.vault_pki.issue_path", cfg.Server.TLS.VaultPKI.IssuePath, pki)
+	}
+
+	if cfg.SPIFFE.Enabled && cfg.SPIFFE.PKISignPath != "" {
+		add("spiffe.pki_sign_path", cfg.SPIFFE.PKISignPath, pki)
+	}
+
+	if cfg.SPIFFE.Enabled && cfg.SPIFFE.JWT {
+		add("spiffe.jwt_key_path", cfg.SPIFFE.JWTKeyPath, read)
+	}
+
+	return reqs
+}
+
+// checkVaultCapabilities проверяет права токена Vault на пути, нужные включенным функциям, если
+// проверка включена (vault.preflight). При нехватке прав останавливает сервис с отчетом.
+func checkVaultCapabilities(ctx context.Context, cfg *config.Config, vaultClient *vault.Client) {
+	if !cfg.Vault.Preflight {
+		return
+	}
+
+	reqs := vaultRequirements(cfg)
+
+	logrus.WithField("paths", len(reqs)).Info("checking vault token capabilities")
+
+	checker := start(preflight.New(preflight.WithSource(vaultClient), preflight.WithRequirements(reqs...)))
+
+	report, err := checker.Check(ctx)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to check vault capabilities")
+	}
+
+	for _, res := range report.Results {
+		logrus.WithFields(logrus.Fields{
+			"purpose": res.Purpose,
+			"path":    res.Path,
+			"granted": res.Granted,
+			"missing": res.Missing,
+		}).Debug("vault capabilities")
+	}
+
+	if err := report.Err(); err != nil {
+		logrus.Fatal(err)
+	}
+
+	logrus.Info("vault token has required capabilities")
+}
+
+// or возвращает value или fallback, если value пусто.
+func or(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"auth-service/internal/config"
+	"auth-service/internal/service/preflight"
+	"auth-service/internal/service/token"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVaultRequirements(t *testing.T) {
+	t.Parallel()
+
+	read := []string{preflight.CapabilityRead}
+	write := []string{preflight.CapabilityCreate, preflight.CapabilityUpdate}
+
+	tests := []struct {
+		name string
+		cfg  func(cfg *config.Config)
+		want []preflight.Requirement
+	}{
+		{
+			name: "default",
+			cfg:  func(cfg *config.Config) {},
+			want: []preflight.Requirement{
+				{Purpose: "token.keys_path", Path: token.DefaultKeysPath, Capabilities: read},
+			},
+		},
+		{
+			name: "enabled features",
+			cfg: func(cfg *config.Config) {
+				cfg.Token.KeysPath = "kv/data/keys"
+				cfg.Admin.APIKeys.Enabled = true
+				cfg.Admin.APIKeys.VaultPath = "kv/data/api-keys"
+				cfg.Mail.Enabled = true
+				cfg.Mail.CredentialsPath = "kv/data/mail"
+				cfg.Server.TLS.VaultPKI.IssuePath = "pki/issue/auth"
+			},
+			want: []preflight.Requirement{
+				{Purpose: "token.keys_path", Path: "kv/data/keys", Capabilities: read},
+				{Purpose: "admin.api_keys.vault_path", Path: "kv/data/api-keys/preflight", Capabilities: write},
+				{Purpose: "mail.credentials_path", Path: "kv/data/mail", Capabilities: read},
+				{Purpose: "server.tls.vault_pki.issue_path", Path: "pki/issue/auth", Capabilities: []string{preflight.CapabilityUpdate}},
+			},
+		},
+		{
+			name: "signing key rotation",
+			cfg: func(cfg *config.Config) {
+				cfg.Token.Rotation.Enabled = true
+			},
+			want: []preflight.Requirement{
+				{Purpose: "token.keys_path", Path: token.DefaultKeysPath, Capabilities: append(read, write...)},
+			},
+		},
+		{
+			name: "spiffe jwt svid key",
+			cfg: func(cfg *config.Config) {
+				cfg.SPIFFE.Enabled = true
+				cfg.SPIFFE.JWT = true
+				cfg.SPIFFE.JWTKeyPath = "kv/data/spiffe-jwt"
+			},
+			want: []preflight.Requirement{
+				{Purpose: "token.keys_path", Path: token.DefaultKeysPath, Capabilities: read},
+				{Purpose: "spiffe.jwt_key_path", Path: "kv/data/spiffe-jwt", Capabilities: read},
+			},
+		},
+		{
+			name: "disabled features are skipped",
+			cfg: func(cfg *config.Config) {
+				cfg.Mail.CredentialsPath = "kv/data/mail"
+				cfg.Admin.APIKeys.VaultPath = "kv/data/api-keys"
+			},
+			want: []preflight.Requirement{
+				{Purpose: "token.keys_path", Path: token.DefaultKeysPath, Capabilities: read},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &config.Config{}
+			tt.cfg(cfg)
+
+			assert.Equal(t, tt.want, vaultRequirements(cfg))
+		})
+	}
+}
+
+func TestCheckVaultCapabilities_Disabled(t *testing.T) {
+	t.Parallel()
+
+	// без vault.preflight клиент Vault не используется
+	checkVaultCapabilities(context.Background(), &config.Config{}, nil)
+}
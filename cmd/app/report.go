@@ -0,0 +1,139 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fingerprintLength - длина отпечатка конфигурации в hex символах.
+const fingerprintLength = 12
+
+// StartupReport - машиночитаемый отчет о запуске сервиса.
+// Пишется в лог одним событием и, при необходимости, в файл для инструментов деплоя.
+type StartupReport struct {
+	Service    *BuildInfo        `json:"service"`
+	StartedAt  time.Time         `json:"started_at"`
+	DurationMs int64             `json:"duration_ms"`
+	Components []ComponentReport `json:"components"`
+}
+
+// ComponentReport - информация о запущенном компоненте.
+type ComponentReport struct {
+	Name        string   `json:"name"`
+	Fingerprint string   `json:"config_fingerprint,omitempty"`
+	Addresses   []string `json:"addresses,omitempty"`
+	DurationMs  int64    `json:"duration_ms"`
+}
+
+// track сохраняет информацию о запущенном компоненте для отчета о запуске.
+// cfg - конфигурация компонента, в отчет попадает только ее хэш.
+func (b *Butler) track(name string, cfg any, started time.Time, addrs ...string) {
+	component := ComponentReport{
+		Name:        name,
+		Fingerprint: fingerprint(cfg),
+		Addresses:   addrs,
+		DurationMs:  time.Since(started).Milliseconds(),
+	}
+
+	b.mu.Lock()
+	b.components = append(b.components, component)
+	b.mu.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"component":   name,
+		"fingerprint": component.Fingerprint,
+		"duration_ms": component.DurationMs,
+	}).Debug("component started")
+}
+
+// report формирует отчет о запуске по всем отслеженным компонентам.
+func (b *Butler) report() *StartupReport {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	components := make([]ComponentReport, len(b.components))
+	copy(components, b.components)
+
+	return &StartupReport{
+		Service:    b.BuildInfo,
+		StartedAt:  b.startedAt,
+		DurationMs: time.Since(b.startedAt).Milliseconds(),
+		Components: components,
+	}
+}
+
+// startupComplete пишет событие "startup complete" в лог и, если указан путь, сохраняет отчет в файл.
+func (b *Butler) startupComplete(path string) {
+	report := b.report()
+
+	logrus.WithFields(logrus.Fields{
+		"version":     report.Service.Version,
+		"duration_ms": report.DurationMs,
+		"components":  report.Components,
+	}).Info("startup complete")
+
+	if path == "" {
+		return
+	}
+
+	if err := writeStartupReport(path, report); err != nil {
+		logrus.WithError(err).WithField("path", path).Error("failed to write startup report")
+		return
+	}
+
+	logrus.WithField("path", path).Info("startup report written")
+}
+
+// writeStartupReport атомарно записывает отчет в файл: сначала во временный файл, затем переименовывает.
+func writeStartupReport(path string, report *StartupReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshal startup report: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error create temp file: %w", err)
+	}
+
+	defer os.Remove(tmp.Name()) //nolint:errcheck // после переименования файла уже нет
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("error write startup report: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error close temp file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error rename startup report: %w", err)
+	}
+
+	return nil
+}
+
+// fingerprint возвращает укороченный sha256 от JSON представления конфигурации.
+// Позволяет сравнить конфигурации между запусками, не раскрывая секреты.
+func fingerprint(cfg any) string {
+	if cfg == nil {
+		return ""
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])[:fingerprintLength]
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"auth-service/internal/config"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Vault{Address: "https://localhost:8200", Token: "vault-token"}
+
+	got := fingerprint(cfg)
+	require.Len(t, got, fingerprintLength)
+
+	assert.Equal(t, got, fingerprint(cfg), "fingerprint must be stable")
+	assert.NotEqual(t, got, fingerprint(config.Vault{Address: "https://localhost:8200", Token: "other-token"}))
+	assert.NotContains(t, got, "vault-token")
+	assert.Empty(t, fingerprint(nil))
+}
+
+func TestButler_Report(t *testing.T) {
+	t.Parallel()
+
+	butler := NewButler()
+
+	butler.track("vault", config.Vault{Address: "https://localhost:8200"}, time.Now(), "https://localhost:8200")
+	butler.track("dependencies", nil, time.Now())
+
+	report := butler.report()
+	require.NotNil(t, report)
+
+	assert.Equal(t, butler.BuildInfo, report.Service)
+	require.Len(t, report.Components, 2)
+
+	assert.Equal(t, "vault", report.Components[0].Name)
+	assert.Len(t, report.Components[0].Fingerprint, fingerprintLength)
+	assert.Equal(t, []string{"https://localhost:8200"}, report.Components[0].Addresses)
+
+	assert.Equal(t, "dependencies", report.Components[1].Name)
+	assert.Empty(t, report.Components[1].Fingerprint)
+	assert.Empty(t, report.Components[1].Addresses)
+}
+
+func TestWriteStartupReport(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		path    func(t *testing.T) string
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "positive case",
+			path: func(t *testing.T) string {
+				t.Helper()
+
+				return filepath.Join(t.TempDir(), "startup-report.json")
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "error case: directory does not exist",
+			path: func(t *testing.T) string {
+				t.Helper()
+
+				return filepath.Join(t.TempDir(), "not-exists", "startup-report.json")
+			},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			report := &StartupReport{
+				Service:    &BuildInfo{Version: "v1.0.0"},
+				DurationMs: 42,
+				Components: []ComponentReport{{Name: "server", Addresses: []string{":8080"}, DurationMs: 1}},
+			}
+
+			path := tt.path(t)
+
+			err := writeStartupReport(path, report)
+			tt.wantErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			data, err := os.ReadFile(path) //nolint:gosec // путь формируется в тесте
+			require.NoError(t, err)
+
+			var got StartupReport
+
+			require.NoError(t, json.Unmarshal(data, &got))
+			assert.Equal(t, report.Components, got.Components)
+			assert.Equal(t, report.Service.Version, got.Service.Version)
+			assert.Equal(t, int64(42), got.DurationMs)
+
+			// временный файл не остался в директории
+			entries, err := os.ReadDir(filepath.Dir(path))
+			require.NoError(t, err)
+			assert.Len(t, entries, 1)
+		})
+	}
+}
+
+func TestRedisAddrs(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"localhost:6379"}, redisAddrs(config.Redis{Type: config.RedisTypeSingle, Host: "localhost", Port: 6379}))
+	assert.Equal(t, []string{"a:7001", "b:7002"}, redisAddrs(config.Redis{Type: config.RedisTypeCluster, Addrs: []string{"a:7001", "b:7002"}}))
+}
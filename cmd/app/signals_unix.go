@@ -0,0 +1,22 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// shutdownSignals возвращает сигналы штатной остановки: Ctrl+C, SIGTERM от оркестратора и SIGHUP
+// при закрытии терминала, как закрытие консоли на Windows. SIGHUP не перехватывается, если процесс
+// запущен с игнорированием SIGHUP (nohup): подписка вернула бы сигналу действие.
+func shutdownSignals() []os.Signal {
+	signals := []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+	if !signal.Ignored(syscall.SIGHUP) {
+		signals = append(signals, syscall.SIGHUP)
+	}
+
+	return signals
+}
@@ -0,0 +1,38 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdownSignals(t *testing.T) {
+	ctx, stop := signal.NotifyContext(context.Background(), shutdownSignals()...)
+	defer stop()
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("SIGTERM did not stop the context")
+	}
+}
+
+func TestShutdownSignals_IgnoredHangup(t *testing.T) {
+	assert.Contains(t, shutdownSignals(), syscall.SIGHUP)
+
+	// как при запуске через nohup
+	signal.Ignore(syscall.SIGHUP)
+	defer signal.Reset(syscall.SIGHUP)
+
+	assert.NotContains(t, shutdownSignals(), syscall.SIGHUP)
+	assert.Contains(t, shutdownSignals(), syscall.SIGTERM)
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// shutdownSignals возвращает сигналы штатной остановки. На Windows os.Interrupt приходит
+// при Ctrl+C и Ctrl+Break, а SIGTERM - при закрытии консоли, выходе из системы и выключении.
+func shutdownSignals() []os.Signal {
+	return []os.Signal{os.Interrupt, syscall.SIGTERM}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"auth-service/internal/cliout"
+	"auth-service/internal/config"
+	"auth-service/internal/service/token"
+	"auth-service/internal/storage/vault"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// vaultEntry - строка вывода команды vault-ls: папка или секрет без значения.
+type vaultEntry struct {
+	Path      string     `json:"path"`
+	Type      string     `json:"type"`
+	Version   int        `json:"version,omitempty"`
+	Versions  int        `json:"versions,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// runVaultList печатает дерево секретов Vault KV v2, доступных токену сервиса, - только пути
+// и метаданные, без значений:
+//
+//	auth-service vault-ls --config ./config.yaml [--mount secret] [--path auth] [--depth 2]
+//
+// По умолчанию обходится папка с ключами подписи (token.keys_path). Пути, на которые у токена
+// нет прав, выводятся с ошибкой permission denied: так видно ошибки политики и неверный mount
+// без доступа к Vault из консоли.
+func runVaultList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("vault-ls", flag.ContinueOnError)
+	configPath := fs.String("config", "./config.yaml", "path to config file")
+	mount := fs.String("mount", "", "kv v2 mount, by default the mount of token.keys_path")
+	dir := fs.String("path", "", "folder to list, by default the folder of token.keys_path")
+	depth := fs.Int("depth", 0, "max folder depth, 0 - unlimited")
+	output := cliout.FormatTable
+	fs.Var(&output, "o", cliout.FlagUsage)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	keysPath := cfg.Token.KeysPath
+	if keysPath == "" {
+		keysPath = token.DefaultKeysPath
+	}
+
+	defaultMount, defaultDir := splitKVPath(keysPath)
+
+	if *mount == "" {
+		*mount = defaultMount
+	}
+
+	if *dir == "" && !isFlagSet(fs, "path") {
+		*dir = defaultDir
+	}
+
+	vaultClient, stop, err := commandVault(ctx, cfg.Vault)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	entries, err := vaultClient.WalkKV(ctx, *mount, *dir, *depth)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]vaultEntry, 0, len(entries))
+
+	for _, e := range entries {
+		row := vaultEntry{Path: path.Join(*mount, e.Path), Type: "secret"}
+
+		if e.Dir {
+			row.Path += "/"
+			row.Type = "folder"
+		}
+
+		if e.Metadata != nil {
+			row.Version = e.Metadata.CurrentVersion
+			row.Versions = e.Metadata.Versions
+
+			if !e.Metadata.UpdatedAt.IsZero() {
+				row.UpdatedAt = &e.Metadata.UpdatedAt
+			}
+		}
+
+		if e.Err != nil {
+			row.Error = e.Err.Error()
+		}
+
+		rows = append(rows, row)
+	}
+
+	return cliout.Write(os.Stdout, output, rows)
+}
+
+// splitKVPath разбирает путь KV v2 вида <mount>/data/<папка>/<секрет> на mount и папку секрета.
+func splitKVPath(kvPath string) (mount, dir string) {
+	mount, rest, ok := strings.Cut(strings.Trim(kvPath, "/"), "/data/")
+	if !ok {
+		return mount, ""
+	}
+
+	dir, _ = path.Split(rest)
+
+	return mount, strings.TrimSuffix(dir, "/")
+}
+
+// isFlagSet возвращает true, если флаг name передан явно.
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+
+	return set
+}
+
+// commandVault подключается к Vault из конфигурации сервиса.
+func commandVault(ctx context.Context, cfg config.Vault) (*vault.Client, func(), error) {
+	vaultClient, err := vault.NewClient(vaultOptions(cfg)...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := vaultClient.Connect(); err != nil {
+		return nil, nil, fmt.Errorf("error connect to vault: %w", err)
+	}
+
+	stop := func() {
+		if err := vaultClient.Stop(ctx); err != nil {
+			logrus.WithError(err).Warn("error stop vault")
+		}
+	}
+
+	return vaultClient, stop, nil
+}
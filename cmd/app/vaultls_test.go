@@ -0,0 +1,88 @@
+package main
+
+import (
+	"auth-service/internal/cliout"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitKVPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path      string
+		wantMount string
+		wantDir   string
+	}{
+		{path: "secret/data/auth/signing-keys", wantMount: "secret", wantDir: "auth"},
+		{path: "/kv/data/team/auth/keys/", wantMount: "kv", wantDir: "team/auth"},
+		{path: "secret/data/keys", wantMount: "secret", wantDir: ""},
+		{path: "secret/keys", wantMount: "secret/keys", wantDir: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			mount, dir := splitKVPath(tt.path)
+			assert.Equal(t, tt.wantMount, mount)
+			assert.Equal(t, tt.wantDir, dir)
+		})
+	}
+}
+
+func TestVaultList(t *testing.T) {
+	t.Parallel()
+
+	var listed []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/v1/sys/health":
+			_, _ = w.Write([]byte(`{"initialized":true,"sealed":false,"version":"1.15.0"}`))
+		case "/v1/secret/metadata/auth", "/v1/kv/metadata/other":
+			listed = append(listed, r.URL.Path)
+			_, _ = w.Write([]byte(`{"data":{"keys":["signing-keys"]}}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	cfg := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(cfg, []byte(fmt.Sprintf(`log_level: "info"
+server:
+  port: 8080
+  shutdown_timeout: 1s
+vault:
+  address: %q
+  token: "vault-token"
+  insecure_skip_tls: true
+redis:
+  type: "single"
+  host: "localhost"
+  port: 6379
+`, ts.URL)), 0o600))
+
+	// по умолчанию обходится папка ключей подписи, закрытые пути выводятся с ошибкой
+	handled, err := runCommand(t.Context(), []string{"vault-ls", "--config", cfg, "-o", "json"})
+	require.True(t, handled)
+	require.NoError(t, err)
+
+	_, err = runCommand(t.Context(), []string{"vault-ls", "--config", cfg, "--mount", "kv", "--path", "other", "--depth", "1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/v1/secret/metadata/auth", "/v1/kv/metadata/other"}, listed)
+
+	_, err = runCommand(t.Context(), []string{"vault-ls", "-o", "xml"})
+	require.ErrorContains(t, err, cliout.ErrUnknownFormat.Error())
+}
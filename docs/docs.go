@@ -15,16 +15,5806 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/account/email": {
+            "get": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "Возвращает смену почты, ожидающую подтверждения, и какие адреса ее уже подтвердили",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Получить смену почты",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_oauth.EmailChange"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "Отправляет коды подтверждения на текущий и новый адреса. Почта меняется только после подтверждения с обоих адресов, до истечения срока. Новая смена отменяет начатую. Токены имперсонации не принимаются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Начать смену почты",
+                "parameters": [
+                    {
+                        "description": "Новая почта",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.emailChangeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_oauth.EmailChange"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "Коды из отправленных писем перестают действовать",
+                "tags": [
+                    "account"
+                ],
+                "summary": "Отменить смену почты",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/email/confirm": {
+            "post": {
+                "description": "Код из письма подтверждает владение адресом, поэтому токен не нужен. Почта меняется после подтверждения с обоих адресов, после этого на старый адрес отправляется уведомление, а если настроено, токены пользователя отзываются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Подтвердить смену почты",
+                "parameters": [
+                    {
+                        "description": "Код из письма",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.emailChangeConfirmRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_oauth.EmailChange"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "О каких событиях безопасности уведомлять пользователя и куда. Если пользователь их не менял, возвращаются настройки по умолчанию",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Получить настройки уведомлений",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_notify.Preferences"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "Канал должен быть настроен на сервере: email или telegram. Токены имперсонации не принимаются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Изменить настройки уведомлений",
+                "parameters": [
+                    {
+                        "description": "Настройки уведомлений",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_notify.Preferences"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_notify.Preferences"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/apikeys": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Ключ возвращается один раз в формате \u003cid\u003e.\u003cсекрет\u003e. Если настроен apikeys.vault_path, секрет записывается в Vault и не попадает в ответ",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "apikeys"
+                ],
+                "summary": "Выпустить API ключ",
+                "parameters": [
+                    {
+                        "description": "API ключ",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.createAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_apikey.Created"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/apikeys/{id}/rate-limit": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "apikeys"
+                ],
+                "summary": "Ограничение частоты запросов API ключа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID API ключа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.apiKeyRateLimitResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Задается либо tier из rate_limit.tiers, либо requests и window_seconds",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "apikeys"
+                ],
+                "summary": "Изменить ограничение частоты запросов API ключа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID API ключа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Ограничение",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.apiKeyRateLimitRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.apiKeyRateLimitResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "tags": [
+                    "apikeys"
+                ],
+                "summary": "Сбросить ограничение частоты запросов API ключа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID API ключа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/bans": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Действующие блокировки источников запросов: после обращения к ловушке, перебора учетных данных или ручные",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "abuse"
+                ],
+                "summary": "Список блокировок",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/auth-service_internal_service_abuse.Ban"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Блокирует IP (ip:10.0.0.1), сеть (net:10.0.0.0/24) или ASN (asn:64500) на срок блокировки. Повторная блокировка возвращает действующую",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "abuse"
+                ],
+                "summary": "Заблокировать источник",
+                "parameters": [
+                    {
+                        "description": "Источник",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.banRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_abuse.Ban"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "tags": [
+                    "abuse"
+                ],
+                "summary": "Снять блокировку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Источник: ip:\u003cадрес\u003e, net:\u003cсеть CIDR\u003e или asn:\u003cномер\u003e",
+                        "name": "scope",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/canary": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Выпускает пробный токен и проверяет его так же, как токены клиентов: ключ по kid, отзыв, аудитории, а если включен token.bundle - еще и по пакету проверки. Результат обновляет метрики auth_canary_* и поле canary в GET /ready. Полезно сразу после выката или ротации ключей, не дожидаясь очередной проверки по расписанию",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Проверить выпуск токенов пробным токеном",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_canary.Result"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_canary.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/capture": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Возвращает настройки захвата тел запросов и содержимое кольцевого буфера (секреты замаскированы)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Получить захваченные запросы",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.captureResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Включает или выключает захват тел запросов и задает долю (0..1) захватываемых запросов по шаблону маршрута",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Изменить настройки захвата",
+                "parameters": [
+                    {
+                        "description": "Настройки захвата",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_capture.Settings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_capture.Settings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Очистить захваченные запросы",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/deactivations/check": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Ищет пользователей, отключенных в SCIM, токены которых еще принимаются, отключает их и допубликовывает потерянные события. Проверка выполняется асинхронно, статус задания - GET /admin/jobs/{id}",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revocation"
+                ],
+                "summary": "Проверить отключенных пользователей",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_job.Job"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/drain": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Проверка готовности (GET /ready) сразу начинает отвечать 503, чтобы балансировщик перестал отправлять новые запросы. Процесс продолжает обслуживать запросы до истечения delay (по умолчанию server.drain.delay, не больше server.drain.max_delay) и затем завершается так же, как по SIGTERM. Повторный вызов возвращает состояние уже начатого вывода",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Вывести экземпляр из балансировки",
+                "parameters": [
+                    {
+                        "description": "Параметры вывода",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.drainRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_drain.State"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/groups": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Создать группу",
+                "parameters": [
+                    {
+                        "description": "Группа",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.createGroupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_group.Group"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/groups/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Получить группу",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_group.Group"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Удалить группу",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/groups/{id}/check": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Проверяет по роли пользователя в группе: viewer - read, editor - read и write, owner - read, write и manage",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Проверить доступ к группе",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "user",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "read",
+                            "write",
+                            "manage"
+                        ],
+                        "type": "string",
+                        "description": "Действие",
+                        "name": "action",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.groupCheckResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/groups/{id}/members/{user}": {
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Добавить участника или изменить роль",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Роль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.setMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Исключить участника",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/impersonate": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Выпускает ограниченный по времени токен пользователя только со scopes на чтение. В claim act записывается администратор, выполнивший запрос: субъект его токена после входа через каталог или static-admin для статического токена. Каждая выдача пишется в аудит",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Выпустить токен имперсонации",
+                "parameters": [
+                    {
+                        "description": "Запрос на имперсонацию",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.impersonateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.tokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/jobs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Задания хранятся до истечения TTL после последнего обновления, затем возвращается 404",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Статус задания",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID задания",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_job.Job"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/keys/usage": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Количество выпущенных и проверенных токенов по kid с момента запуска. Если передан idle_for, для каждого ключа вычисляется, можно ли его выводить из ротации (не было проверок за период)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Статистика использования ключей",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Период без проверок, например 720h",
+                        "name": "idle_for",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.keyUsageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/log-sampling": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Получить настройки выборочного логирования",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.logSamplingSettings"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Задает долю (0..1) запросов, которые попадают в access log, по шаблону маршрута, например {\"routes\": {\"/api/v0/token/introspect\": 0.01}}",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Изменить настройки выборочного логирования",
+                "parameters": [
+                    {
+                        "description": "Доли логируемых запросов",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.logSamplingSettings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.logSamplingSettings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Сбросить настройки выборочного логирования",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/login": {
+            "post": {
+                "description": "Проверяет логин и пароль в корпоративном каталоге (LDAP/Active Directory) и выпускает токен аудитории admin. Роли токена (scopes admin:viewer, admin:operator) берутся из групп пользователя в каталоге. Если включен SCIM, вход разрешен только учетным записям, которые корпоративный IdP завел и не отключил. Токен принимается административным API вместо статического токена",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Вход администратора через LDAP",
+                "parameters": [
+                    {
+                        "description": "Логин и пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.adminLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.tokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/read-only": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Получить состояние режима только для чтения",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_readonly.Status"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Проверка и интроспекция токенов продолжают работать, а выпуск токенов, регистрация и отзыв отвечают 503, пока режим не выключен через DELETE /admin/read-only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Включить режим только для чтения",
+                "parameters": [
+                    {
+                        "description": "Причина включения",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.readOnlyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_readonly.Status"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Выключает режим, включенный оператором или сервисом. Если записи в Redis снова не пройдут, сервис включит режим сам",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Выключить режим только для чтения",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_readonly.Status"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/refresh-families/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Возвращает семейство refresh токенов одного входа: субъекта, срок действия, отметку об отзыве (например, reuse - повторное использование замененного токена) и токены в порядке выпуска с родителем каждого. Сами токены не раскрываются",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Цепочка обновлений refresh токена",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID семейства",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_refresh.Family"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Отзывает семейство refresh токенов одного входа (ID семейства совпадает с claim sid токенов доступа входа). Токены семейства больше не обновляются, а токены доступа сессии сразу перестают приниматься аудиториями, для которых включена проверка сессии",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Завершить сессию входа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID семейства",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/stats": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Количество входов и уникальных пользователей по дням (UTC), выпущенные токены по аудиториям и количество активных сессий на момент запроса. Уникальные пользователи считаются приблизительно (HyperLogLog, погрешность около 1%). Сессии учитываются, если включены refresh токены",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Статистика для продуктовой аналитики",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Количество дней, включая текущий (по умолчанию 7, не больше срока хранения)",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_stats.Summary"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/deactivation": {
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Сразу отзывает все токены пользователя, в том числе выпущенные после отключения, и публикует событие user.deactivated. Повторное отключение возвращает существующее",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revocation"
+                ],
+                "summary": "Отключить пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_revocation.Deactivation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Снимает отключение и публикует событие user.reactivated. Токены, выпущенные до включения, остаются отозванными",
+                "tags": [
+                    "revocation"
+                ],
+                "summary": "Включить пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/notifications": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Для сервисов, которые сами меняют пароль или выполняют вход. Если пользователь отключил уведомления этого вида, возвращается sent=false",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Уведомить пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Уведомление",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.notificationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.notificationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/sessions": {
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Отзыв выполняется асинхронно. Статус задания - GET /admin/jobs/{id}",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revocation"
+                ],
+                "summary": "Отозвать все токены пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_job.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{user}/groups": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Группы пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.membershipsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/verification-bundle": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Подписанный текущим ключом пакет (JWT с typ auth-bundle+jwt): kid ключей, которыми принимаются токены, отметки об отзыве, отключенные пользователи, iss и аудитории. Пограничные сервисы загружают его через authclient.ParseBundle и проверяют токены без обращения к сервису до истечения пакета. Ключи симметричные, поэтому сами ключи в пакет не входят. То же выгружает команда export-bundle",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Выгрузить пакет проверки токенов",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.verificationBundleResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/apikeys/{id}/usage": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKey": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "apikeys"
+                ],
+                "summary": "Использование квот API ключа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID API ключа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_quota.Usage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "Отзывает refresh токены сессии токена запроса (claim sid) и сам токен запроса: его jti попадает в черный список до истечения. Токены доступа, выпущенные сессией раньше, перестают приниматься аудиториями, для которых включена проверка сессии. Повторный выход с тем же токеном невозможен: токен уже отозван. Токены имперсонации не принимаются",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Выйти",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout_all": {
+            "post": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "Отзывает refresh токены всех сессий пользователя и сразу все его токены доступа, выпущенные до выхода, в том числе токен запроса. Отзыв токенов доступа публикуется событием tokens.revoked для других регионов. Токены имперсонации не принимаются",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Выйти из всех сессий",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/telegram": {
+            "post": {
+                "description": "Принимает initData мини-приложения (поле init_data) или данные виджета входа (id, first_name, username, photo_url, auth_date, hash), проверяет подпись токеном бота и срок auth_date и выдает токен пользователю с этим ID в Telegram. Если подключен сервис пользователей, войти может только зарегистрированный в нем пользователь",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Войти через Telegram",
+                "parameters": [
+                    {
+                        "description": "Данные входа Telegram",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.telegramLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.tokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/authz/check": {
+            "post": {
+                "description": "Отвечает, может ли субъект выполнить действие над ресурсом, по scopes токена и ролям субъекта в группах, а если настроены политики - по политикам. Недействительный токен - allowed=false",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authz"
+                ],
+                "summary": "Проверить доступ к ресурсу",
+                "parameters": [
+                    {
+                        "description": "Запрос",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.authzCheckRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_authz.Decision"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/credentials/check": {
+            "post": {
+                "description": "Проверяет логин и пароль на соответствие политике (credentials_policy): длина, классы символов, запрещенные пароли, формат и зарезервированные логины. Возвращает все нарушения с машиночитаемыми кодами (field, code, params). Пароль проверяется по базе утекших паролей HaveIBeenPwned (k-анонимность: наружу уходят только первые 5 символов SHA-1). Вызывается при регистрации и смене пароля. Если сервис утечек недоступен, пароль принимается без проверки (breach_checked: false) или возвращается 503 - в зависимости от password_breach.fail_open",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "credentials"
+                ],
+                "summary": "Проверить учетные данные",
+                "parameters": [
+                    {
+                        "description": "Учетные данные",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.credentialsCheckRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.credentialsCheckResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.credentialsCheckResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/health": {
             "get": {
-                "description": "Проверить состояние сервера и соединения",
+                "description": "Проверить состояние сервера и соединения. Включает состояние фоновых компонентов: starting, running, stopped, failed, количество перезапусков и последнюю ошибку, а также состояние Redis: статистику пула соединений и основные поля INFO каждого узла. Если раскрытие версии отключено (server.debug.hide_version), возвращает только {\"status\": \"ok\"}, полная информация доступна на внутреннем отладочном порту.",
+                "produces": [
+                    "application/json"
+                ],
                 "summary": "Проверить состояние сервера и соединения",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.healthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/{provider}/callback": {
+            "get": {
+                "description": "Адрес возврата от провайдера. Пользователь провайдера привязывается к локальному субъекту по сохраненной связи или подтвержденной почте, иначе создается новый субъект. Если настроен success_url, браузер перенаправляется туда с токеном во фрагменте адреса, иначе токен возвращается в JSON",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Завершить вход через внешнего провайдера",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя провайдера из конфигурации",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Код авторизации провайдера",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "State из StartOAuth",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ошибка провайдера",
+                        "name": "error",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.tokenResponse"
+                        }
+                    },
+                    "302": {
+                        "description": "Found"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/{provider}/start": {
+            "get": {
+                "description": "Перенаправляет браузер на страницу входа провайдера (Google, GitHub, OpenID Connect). Вход защищен state и PKCE",
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Начать вход через внешнего провайдера",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя провайдера из конфигурации",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/qr-login": {
+            "post": {
+                "description": "Возвращает код и содержимое QR для показа в браузере, а также секрет, которым браузер заберет токен после подтверждения. Код действует несколько минут",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "qr-login"
+                ],
+                "summary": "Начать вход по QR",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_qrlogin.Challenge"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/qr-login/{code}/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "Вызывается приложением или ботом, в котором пользователь уже авторизован. Вход выполняется от имени субъекта токена. Токены имперсонации не принимаются",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "qr-login"
+                ],
+                "summary": "Подтвердить вход по QR",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код из QR",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/qr-login/{code}/token": {
+            "post": {
+                "description": "Браузер опрашивает этот метод с секретом из POST /qr-login. Пока вход не подтвержден, возвращается 202. Токен выдается один раз, после этого код недействителен",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "qr-login"
+                ],
+                "summary": "Получить токен входа по QR",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код из QR",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Секрет браузера",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.qrLoginClaimRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.tokenResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.qrLoginStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ready": {
+            "get": {
+                "description": "200 {\"status\": \"ready\"}, пока экземпляр принимает запросы, и 503 {\"status\": \"draining\"} после POST /admin/drain до завершения процесса. Если включен режим только для чтения, в ответе есть поле read_only с его состоянием. Если включена проверка пробным токеном (token.canary), в ответе есть поле canary с результатом последней проверки; при token.canary.required ответ 503 {\"status\": \"canary_failed\"}, пока проверка не проходит",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Проверить готовность принимать запросы",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.readinessResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.readinessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/scim/v2/Users": {
+            "get": {
+                "description": "Возвращает учетные записи администраторов. Поддерживаются фильтры userName eq \"...\" и externalId eq \"...\"",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scim"
+                ],
+                "summary": "Поиск пользователей SCIM",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Фильтр",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер первой записи, начиная с 1",
+                        "name": "startIndex",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_scim.ListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.scimError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.scimError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.scimError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Заводит учетную запись администратора по запросу корпоративного IdP (SCIM 2.0). Если active не передан, учетная запись активна. Вход администратора через каталог разрешен только активным учетным записям",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scim"
+                ],
+                "summary": "Создание пользователя SCIM",
+                "parameters": [
+                    {
+                        "description": "Пользователь",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_scim.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_scim.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.scimError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.scimError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.scimError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.scimError"
+                        }
+                    }
+                }
+            }
+        },
+        "/scim/v2/Users/{id}": {
+            "get": {
+                "description": "Возвращает учетную запись администратора по ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scim"
+                ],
+                "summary": "Пользователь SCIM",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_scim.User"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.scimError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.scimError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Заменяет атрибуты учетной записи администратора. Если active не передан, учетная запись активна",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scim"
+                ],
+                "summary": "Замена пользователя SCIM",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Пользователь",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_scim.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_scim.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.scimError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.scimError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.scimError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.scimError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет учетную запись администратора. После удаления вход через каталог для этого пользователя запрещен",
+                "tags": [
+                    "scim"
+                ],
+                "summary": "Удаление пользователя SCIM",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.scimError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.scimError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Применяет операции PATCH (add, replace, remove) к учетной записи администратора. Отключение учетной записи - replace active=false",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scim"
+                ],
+                "summary": "Изменение пользователя SCIM",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Операции",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_scim.PatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_scim.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.scimError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.scimError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.scimError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.scimError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "Действующие сессии входа пользователя, начиная с последней: устройство (User-Agent и IP входа), время входа и срок действия. Сессия - это вход с refresh токеном, current отмечает сессию токена запроса. Токены имперсонации не принимаются",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "account"
+                ],
+                "summary": "Список сессий",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.sessionsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "Отзывает refresh токены сессии: они больше не обновляются, а токены доступа сессии сразу перестают приниматься аудиториями, для которых включена проверка сессии. Завершить можно только свою действующую сессию, в том числе текущую. Токены имперсонации не принимаются",
+                "tags": [
+                    "account"
+                ],
+                "summary": "Завершить сессию",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сессии",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/svid/bundle": {
+            "get": {
+                "description": "Открытые ключи (JWKS, use=jwt-svid), которыми проверяются JWT-SVID сервиса. Ключи токенов пользователей не публикуются. Экспериментально",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spiffe"
+                ],
+                "summary": "Trust bundle JWT-SVID",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_spiffe.Bundle"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/svid/jwt": {
+            "post": {
+                "security": [
+                    {
+                        "BootstrapToken": []
+                    }
+                ],
+                "description": "Выпускает короткоживущий токен с SPIFFE ID сервиса в sub, подписанный ключом из trust bundle (typ JWT-SVID, не принимается как токен пользователя). SPIFFE ID определяется по bootstrap токену, аудитории должны быть разрешены в spiffe.jwt_audiences. Если запрос пришел по mTLS с проверенным клиентским сертификатом, токен привязывается к нему (cnf.x5t#S256, RFC 8705). Экспериментально",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spiffe"
+                ],
+                "summary": "Выпустить JWT-SVID",
+                "parameters": [
+                    {
+                        "description": "Аудитории",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.jwtSVIDRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_spiffe.JWTSVID"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/svid/x509": {
+            "post": {
+                "security": [
+                    {
+                        "BootstrapToken": []
+                    }
+                ],
+                "description": "Подписывает CSR сервиса промежуточным CA из Vault PKI. SPIFFE ID определяется по bootstrap токену и записывается в URI SAN. Экспериментально",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spiffe"
+                ],
+                "summary": "Выпустить X.509-SVID",
+                "parameters": [
+                    {
+                        "description": "CSR",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.x509SVIDRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_spiffe.X509SVID"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/token/guest": {
+            "post": {
+                "description": "Выпускает короткоживущий токен без пользователя: субъект guest:\u003cid\u003e, scope guest, аудитории из token.guest. Бот вызывает публичные API с ограничением частоты от имени еще не зарегистрированного пользователя. Гостевые токены не принимаются для действий пользователя. После входа гостевой токен обменивается на полный через POST /token/guest/upgrade",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "token"
+                ],
+                "summary": "Выпустить гостевой токен",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.tokenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/token/guest/upgrade": {
+            "post": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "Принимает гостевой токен в заголовке Authorization и учетные данные пользователя (ответ navigator.credentials.get() для passkey). Выпускает токен владельцу учетных данных так же, как вход по ним, и возвращает субъект гостевого токена, чтобы связать с пользователем данные, собранные до регистрации. Гостевой токен после обмена продолжает действовать до истечения, но дает только scope guest",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "token"
+                ],
+                "summary": "Обменять гостевой токен на полный",
+                "parameters": [
+                    {
+                        "description": "Учетные данные",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.guestUpgradeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.guestUpgradeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/token/introspect": {
+            "post": {
+                "description": "Проверяет подпись и срок действия токена (RFC 7662). Для недействительного токена возвращает active=false. Для аудиторий с мягкой проверкой истекший не более чем на grace-период токен считается активным, в ответе выставляется grace=true. Токен, привязанный к сертификату (cnf.x5t#S256), активен только если передан отпечаток того же сертификата в client_cert_thumbprint",
+                "consumes": [
+                    "application/json",
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "token"
+                ],
+                "summary": "Проверить токен",
+                "parameters": [
+                    {
+                        "description": "Токен",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.introspectRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.introspectResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/token/refresh": {
+            "post": {
+                "description": "Меняет refresh токен на новый того же входа и выпускает токен доступа. Каждый refresh токен действует один раз: повторное использование уже замененного токена считается кражей, и все токены этого входа отзываются - пользователю нужно войти заново. 409 - вход меняли параллельно, токен не заменен и запрос можно повторить",
+                "consumes": [
+                    "application/json",
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "token"
+                ],
+                "summary": "Обновить токен",
+                "parameters": [
+                    {
+                        "description": "Refresh токен",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.refreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.tokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/token/revoke": {
+            "post": {
+                "description": "Отзывает токен по его jti (RFC 7009): токен перестает приниматься сразу, запись хранится до его истечения. Для недействительного, истекшего или уже отозванного токена тоже возвращает 200",
+                "consumes": [
+                    "application/json",
+                    "application/x-www-form-urlencoded"
+                ],
+                "tags": [
+                    "token"
+                ],
+                "summary": "Отозвать токен",
+                "parameters": [
+                    {
+                        "description": "Токен",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.revokeRequest"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webauthn/login/begin": {
+            "post": {
+                "description": "Возвращает параметры для navigator.credentials.get({publicKey}). Бинарные поля в base64url",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webauthn"
+                ],
+                "summary": "Начать вход по passkey",
+                "parameters": [
+                    {
+                        "description": "Пользователь",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.passkeyLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_webauthn.RequestOptions"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webauthn/login/finish": {
+            "post": {
+                "description": "Принимает ответ navigator.credentials.get() и выдает токен владельцу ключа",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webauthn"
+                ],
+                "summary": "Завершить вход по passkey",
+                "parameters": [
+                    {
+                        "description": "Ответ браузера",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_webauthn.AssertionResponse"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.tokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webauthn/register/begin": {
+            "post": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "Возвращает параметры для navigator.credentials.create({publicKey}). Бинарные поля в base64url. Ключ регистрируется пользователю из токена",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webauthn"
+                ],
+                "summary": "Начать регистрацию passkey",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_webauthn.CreationOptions"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webauthn/register/finish": {
+            "post": {
+                "security": [
+                    {
+                        "BearerToken": []
+                    }
+                ],
+                "description": "Принимает ответ navigator.credentials.create(): authenticatorData и publicKey (SPKI) из getAuthenticatorData() и getPublicKey(). Поддерживаются ES256, EdDSA и RS256, attestation не проверяется",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webauthn"
+                ],
+                "summary": "Завершить регистрацию passkey",
+                "parameters": [
+                    {
+                        "description": "Ответ браузера",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_webauthn.RegistrationResponse"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/auth-service_internal_service_webauthn.Credential"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_v0.errorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "auth-service_internal_service_abuse.Ban": {
+            "type": "object",
+            "properties": {
+                "banned_at": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "scope": {
+                    "description": "Scope - заблокированный источник: ip:\u003cадрес\u003e, net:\u003cсеть\u003e или asn:\u003cномер\u003e.",
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_apikey.Created": {
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "description": "APIKey - ключ целиком, возвращается только если секрет не записан в Vault.",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "env": {
+                    "description": "Env и ExpiresAt заполняются для ключей песочницы.",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "vault_path": {
+                    "description": "VaultPath - путь секрета в Vault, если секрет записан туда.",
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_authz.Decision": {
+            "type": "object",
+            "properties": {
+                "allowed": {
+                    "type": "boolean"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_canary.Result": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "kid": {
+                    "description": "Kid - ключ, которым подписан пробный токен.",
+                    "type": "string"
+                },
+                "last_success_at": {
+                    "description": "LastSuccessAt - время последней успешной проверки.",
+                    "type": "string"
+                },
+                "ok": {
+                    "type": "boolean"
+                },
+                "step": {
+                    "description": "Step - шаг, на котором проверка не прошла.",
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_capture.Entry": {
+            "type": "object",
+            "properties": {
+                "method": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "request_body": {
+                    "type": "string"
+                },
+                "response_body": {
+                    "type": "string"
+                },
+                "route": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_capture.Settings": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "routes": {
+                    "description": "Routes - доля запросов (от 0 до 1), которые нужно захватывать, по шаблону маршрута.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "auth-service_internal_service_dependency.State": {
+            "type": "string",
+            "enum": [
+                "unknown",
+                "up",
+                "down"
+            ],
+            "x-enum-varnames": [
+                "StateUnknown",
+                "StateUp",
+                "StateDown"
+            ]
+        },
+        "auth-service_internal_service_dependency.Status": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "state": {
+                    "$ref": "#/definitions/auth-service_internal_service_dependency.State"
+                }
+            }
+        },
+        "auth-service_internal_service_drain.State": {
+            "type": "object",
+            "properties": {
+                "draining": {
+                    "type": "boolean"
+                },
+                "exit_at": {
+                    "type": "string"
+                },
+                "since": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_group.Group": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "members": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/auth-service_internal_service_group.Role"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_group.Role": {
+            "type": "string",
+            "enum": [
+                "viewer",
+                "editor",
+                "owner"
+            ],
+            "x-enum-varnames": [
+                "RoleViewer",
+                "RoleEditor",
+                "RoleOwner"
+            ]
+        },
+        "auth-service_internal_service_job.Job": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "params": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "progress": {
+                    "description": "Progress - выполненная часть задания в процентах.",
+                    "type": "integer"
+                },
+                "result": {
+                    "description": "Result - результат обработчика в JSON, заполняется после успешного выполнения.",
+                    "type": "object"
+                },
+                "status": {
+                    "$ref": "#/definitions/auth-service_internal_service_job.Status"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_job.Status": {
+            "type": "string",
+            "enum": [
+                "queued",
+                "running",
+                "done",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "StatusQueued",
+                "StatusRunning",
+                "StatusDone",
+                "StatusFailed"
+            ]
+        },
+        "auth-service_internal_service_lifecycle.Component": {
+            "type": "object",
+            "properties": {
+                "last_error": {
+                    "type": "string"
+                },
+                "last_error_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "restarts": {
+                    "type": "integer"
+                },
+                "since": {
+                    "type": "string"
+                },
+                "state": {
+                    "$ref": "#/definitions/auth-service_internal_service_lifecycle.State"
+                }
+            }
+        },
+        "auth-service_internal_service_lifecycle.State": {
+            "type": "string",
+            "enum": [
+                "starting",
+                "running",
+                "stopped",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "StateStarting",
+                "StateRunning",
+                "StateStopped",
+                "StateFailed"
+            ]
+        },
+        "auth-service_internal_service_notify.Preferences": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "description": "Channel - куда доставлять уведомления: email или telegram.",
+                    "type": "string"
+                },
+                "new_login": {
+                    "description": "NewLogin - уведомлять о входе в аккаунт.",
+                    "type": "boolean"
+                },
+                "password_change": {
+                    "description": "PasswordChange - уведомлять о смене пароля.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "auth-service_internal_service_oauth.EmailChange": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "description": "Completed - оба адреса подтверждены, почта изменена.",
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "new_confirmed": {
+                    "type": "boolean"
+                },
+                "new_email": {
+                    "type": "string"
+                },
+                "old_confirmed": {
+                    "type": "boolean"
+                },
+                "old_email": {
+                    "type": "string"
+                },
+                "revocation_job": {
+                    "description": "RevocationJob - задание на отзыв токенов пользователя после смены почты.",
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_qrlogin.Challenge": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "description": "Payload - содержимое QR.",
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "Secret - секрет браузера, им забирается токен. В QR не показывается.",
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_qrlogin.Status": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "confirmed"
+            ],
+            "x-enum-varnames": [
+                "StatusPending",
+                "StatusConfirmed"
+            ]
+        },
+        "auth-service_internal_service_quota.Period": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "description": "Limit - квота за период, 0 - без ограничения.",
+                    "type": "integer"
+                },
+                "remaining": {
+                    "description": "Remaining - остаток квоты, для периода без ограничения не заполняется.",
+                    "type": "integer"
+                },
+                "reset_at": {
+                    "type": "string"
+                },
+                "used": {
+                    "type": "integer"
+                }
+            }
+        },
+        "auth-service_internal_service_quota.Usage": {
+            "type": "object",
+            "properties": {
+                "daily": {
+                    "$ref": "#/definitions/auth-service_internal_service_quota.Period"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "monthly": {
+                    "$ref": "#/definitions/auth-service_internal_service_quota.Period"
+                }
+            }
+        },
+        "auth-service_internal_service_readonly.Source": {
+            "type": "string",
+            "enum": [
+                "manual",
+                "auto"
+            ],
+            "x-enum-varnames": [
+                "SourceManual",
+                "SourceAuto"
+            ]
+        },
+        "auth-service_internal_service_readonly.Status": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "since": {
+                    "type": "string"
+                },
+                "source": {
+                    "$ref": "#/definitions/auth-service_internal_service_readonly.Source"
+                },
+                "until": {
+                    "description": "Until - когда сервис снова попробует писать. Только для режима, включенного сервисом",
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_refresh.Device": {
+            "type": "object",
+            "properties": {
+                "ip": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_refresh.Family": {
+            "type": "object",
+            "properties": {
+                "audience": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "device": {
+                    "description": "Device - устройство, с которого выполнен вход.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auth-service_internal_service_refresh.Device"
+                        }
+                    ]
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "description": "LastSeenAt - последняя записанная активность скользящей сессии.",
+                    "type": "string"
+                },
+                "max_expires_at": {
+                    "description": "MaxExpiresAt - абсолютный срок скользящей сессии (см. WithSliding): ExpiresAt продлевается\nпри активности, но не дальше. nil - сессия не скользящая.",
+                    "type": "string"
+                },
+                "revoke_reason": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "description": "RevokedAt - когда семейство отозвано, nil - действует.",
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "tg_id": {
+                    "description": "TelegramID - ID пользователя в Telegram при входе через Telegram, переносится в токены доступа семейства.",
+                    "type": "integer"
+                },
+                "tokens": {
+                    "description": "Tokens - токены семейства в порядке выпуска.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth-service_internal_service_refresh.Node"
+                    }
+                }
+            }
+        },
+        "auth-service_internal_service_refresh.Node": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "parent": {
+                    "description": "Parent - токен, при обновлении которого выпущен этот. Пусто у первого токена семейства.",
+                    "type": "string"
+                },
+                "rotated_at": {
+                    "description": "RotatedAt - когда токен заменен следующим, nil - действующий токен семейства.",
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_revocation.Deactivation": {
+            "type": "object",
+            "properties": {
+                "deactivated_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "description": "EventID - ID события в stream, пусто - событие еще не опубликовано.",
+                    "type": "string"
+                },
+                "region": {
+                    "description": "Region - регион, в котором пользователь отключен, если отключение получено репликацией.",
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_scim.Email": {
+            "type": "object",
+            "properties": {
+                "primary": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_scim.ListResponse": {
+            "type": "object",
+            "properties": {
+                "Resources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth-service_internal_service_scim.User"
+                    }
+                },
+                "itemsPerPage": {
+                    "type": "integer"
+                },
+                "schemas": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "startIndex": {
+                    "type": "integer"
+                },
+                "totalResults": {
+                    "type": "integer"
+                }
+            }
+        },
+        "auth-service_internal_service_scim.Meta": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "string"
+                },
+                "lastModified": {
+                    "type": "string"
+                },
+                "resourceType": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_scim.Name": {
+            "type": "object",
+            "properties": {
+                "familyName": {
+                    "type": "string"
+                },
+                "formatted": {
+                    "type": "string"
+                },
+                "givenName": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_scim.PatchOperation": {
+            "type": "object",
+            "properties": {
+                "op": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "auth-service_internal_service_scim.PatchRequest": {
+            "type": "object",
+            "properties": {
+                "Operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth-service_internal_service_scim.PatchOperation"
+                    }
+                },
+                "schemas": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "auth-service_internal_service_scim.User": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "displayName": {
+                    "type": "string"
+                },
+                "emails": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth-service_internal_service_scim.Email"
+                    }
+                },
+                "externalId": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "meta": {
+                    "$ref": "#/definitions/auth-service_internal_service_scim.Meta"
+                },
+                "name": {
+                    "$ref": "#/definitions/auth-service_internal_service_scim.Name"
+                },
+                "schemas": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "userName": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_spiffe.Bundle": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth-service_internal_service_spiffe.BundleKey"
+                    }
+                }
+            }
+        },
+        "auth-service_internal_service_spiffe.BundleKey": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                },
+                "y": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_spiffe.JWTSVID": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "spiffe_id": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_spiffe.X509SVID": {
+            "type": "object",
+            "properties": {
+                "bundle": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "certificate": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "serial_number": {
+                    "type": "string"
+                },
+                "spiffe_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_stats.Day": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "issued": {
+                    "description": "Issued - количество выпущенных токенов по аудиториям.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "logins": {
+                    "type": "integer"
+                },
+                "unique_users": {
+                    "type": "integer"
+                }
+            }
+        },
+        "auth-service_internal_service_stats.Summary": {
+            "type": "object",
+            "properties": {
+                "active_sessions": {
+                    "description": "ActiveSessions - количество действующих сессий на момент запроса.",
+                    "type": "integer"
+                },
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth-service_internal_service_stats.Day"
+                    }
+                },
+                "from": {
+                    "type": "string"
+                },
+                "issued": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "logins": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "unique_users": {
+                    "description": "UniqueUsers - оценка количества разных пользователей, входивших за период (HyperLogLog,\nпогрешность около 1%).",
+                    "type": "integer"
+                }
+            }
+        },
+        "auth-service_internal_service_webauthn.AssertionResponse": {
+            "type": "object",
+            "properties": {
+                "authenticatorData": {
+                    "type": "string"
+                },
+                "clientDataJSON": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "signature": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_webauthn.AuthenticatorSelection": {
+            "type": "object",
+            "properties": {
+                "residentKey": {
+                    "type": "string"
+                },
+                "userVerification": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_webauthn.CreationOptions": {
+            "type": "object",
+            "properties": {
+                "attestation": {
+                    "type": "string"
+                },
+                "authenticatorSelection": {
+                    "$ref": "#/definitions/auth-service_internal_service_webauthn.AuthenticatorSelection"
+                },
+                "challenge": {
+                    "type": "string"
+                },
+                "excludeCredentials": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth-service_internal_service_webauthn.CredentialDescriptor"
+                    }
+                },
+                "pubKeyCredParams": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth-service_internal_service_webauthn.CredentialParameter"
+                    }
+                },
+                "rp": {
+                    "$ref": "#/definitions/auth-service_internal_service_webauthn.RelyingParty"
+                },
+                "timeout": {
+                    "type": "integer"
+                },
+                "user": {
+                    "$ref": "#/definitions/auth-service_internal_service_webauthn.User"
+                }
+            }
+        },
+        "auth-service_internal_service_webauthn.Credential": {
+            "type": "object",
+            "properties": {
+                "algorithm": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sign_count": {
+                    "type": "integer"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "transports": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "auth-service_internal_service_webauthn.CredentialDescriptor": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "transports": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_webauthn.CredentialParameter": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_webauthn.RegistrationResponse": {
+            "type": "object",
+            "properties": {
+                "authenticatorData": {
+                    "type": "string"
+                },
+                "clientDataJSON": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "description": "название ключа для пользователя, например \"MacBook\"",
+                    "type": "string"
+                },
+                "publicKey": {
+                    "type": "string"
+                },
+                "publicKeyAlgorithm": {
+                    "type": "integer"
+                },
+                "transports": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "auth-service_internal_service_webauthn.RelyingParty": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_webauthn.RequestOptions": {
+            "type": "object",
+            "properties": {
+                "allowCredentials": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth-service_internal_service_webauthn.CredentialDescriptor"
+                    }
+                },
+                "challenge": {
+                    "type": "string"
+                },
+                "rpId": {
+                    "type": "string"
+                },
+                "timeout": {
+                    "type": "integer"
+                },
+                "userVerification": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_service_webauthn.User": {
+            "type": "object",
+            "properties": {
+                "displayName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth-service_internal_storage_redis.Stats": {
+            "type": "object",
+            "properties": {
+                "hits": {
+                    "description": "Hits - сколько раз соединение взято из пула.",
+                    "type": "integer"
+                },
+                "idleConns": {
+                    "type": "integer"
+                },
+                "misses": {
+                    "description": "Misses - сколько раз соединение пришлось создать.",
+                    "type": "integer"
+                },
+                "staleConns": {
+                    "type": "integer"
+                },
+                "timeouts": {
+                    "description": "Timeouts - сколько раз не дождались свободного соединения.",
+                    "type": "integer"
+                },
+                "totalConns": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api_v0.actor": {
+            "type": "object",
+            "properties": {
+                "sub": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.adminLoginRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.apiKeyRateLimitRequest": {
+            "type": "object",
+            "properties": {
+                "requests": {
+                    "description": "не более requests запросов",
+                    "type": "integer"
+                },
+                "tier": {
+                    "description": "тариф из rate_limit.tiers",
+                    "type": "string"
+                },
+                "window_seconds": {
+                    "description": "за window_seconds секунд",
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api_v0.apiKeyRateLimitResponse": {
+            "type": "object",
+            "properties": {
+                "requests": {
+                    "description": "Requests и WindowSeconds - правило, 0 - без ограничения.",
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "Source - откуда взято ограничение: key - собственное, tier - тариф, default - из конфигурации.",
+                    "type": "string"
+                },
+                "tier": {
+                    "type": "string"
+                },
+                "window_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api_v0.authzCheckRequest": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "владелец ресурса, если известен. Доступен политикам",
+                    "type": "string"
+                },
+                "resource": {
+                    "description": "ресурс в формате \u003cтип\u003e:\u003cid\u003e, например group:abc",
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.banRequest": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "scope": {
+                    "description": "Scope - ip:\u003cадрес\u003e, net:\u003cсеть CIDR\u003e или asn:\u003cномер\u003e.",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.captureResponse": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth-service_internal_service_capture.Entry"
+                    }
+                },
+                "settings": {
+                    "$ref": "#/definitions/auth-service_internal_service_capture.Settings"
+                }
+            }
+        },
+        "internal_api_v0.confirmation": {
+            "type": "object",
+            "properties": {
+                "x5t#S256": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.createAPIKeyRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "quota_daily": {
+                    "description": "квота на сутки, 0 - квота из конфигурации",
+                    "type": "integer"
+                },
+                "quota_monthly": {
+                    "description": "квота на месяц, 0 - квота из конфигурации",
+                    "type": "integer"
+                },
+                "sandbox": {
+                    "description": "ключ песочницы: помечается env=sandbox и удаляется через sandbox.ttl",
+                    "type": "boolean"
+                }
+            }
+        },
+        "internal_api_v0.createGroupRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "пользователь, который станет владельцем группы",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.credentialViolation": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code - машиночитаемый код нарушения.",
+                    "type": "string"
+                },
+                "field": {
+                    "description": "Field - поле запроса с нарушением.",
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "params": {
+                    "description": "Params - параметры нарушения, например сколько раз пароль встречается в утечках.",
+                    "type": "object",
+                    "additionalProperties": {}
+                }
+            }
+        },
+        "internal_api_v0.credentialsCheckRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.credentialsCheckResponse": {
+            "type": "object",
+            "properties": {
+                "acceptable": {
+                    "type": "boolean"
+                },
+                "breach_checked": {
+                    "description": "BreachChecked - пароль проверен по базе утечек. false - проверка отключена или пропущена\nиз-за недоступности сервиса утечек.",
+                    "type": "boolean"
+                },
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_api_v0.credentialViolation"
+                    }
+                }
+            }
+        },
+        "internal_api_v0.drainRequest": {
+            "type": "object",
+            "properties": {
+                "delay": {
+                    "description": "время до завершения процесса, например 2m. По умолчанию server.drain.delay",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "причина, пишется в аудит",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.emailChangeConfirmRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.emailChangeRequest": {
+            "type": "object",
+            "properties": {
+                "current_email": {
+                    "description": "CurrentEmail - текущая почта. Нужна только аккаунтам, почта которых привязана до появления смены почты.",
+                    "type": "string"
+                },
+                "new_email": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.errorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.groupCheckResponse": {
+            "type": "object",
+            "properties": {
+                "allowed": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "internal_api_v0.guestUpgradeRequest": {
+            "type": "object",
+            "properties": {
+                "passkey": {
+                    "$ref": "#/definitions/auth-service_internal_service_webauthn.AssertionResponse"
+                }
+            }
+        },
+        "internal_api_v0.guestUpgradeResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "integer"
+                },
+                "guest_sub": {
+                    "description": "GuestSubject - субъект гостевого токена, чтобы связать данные, собранные до регистрации, с пользователем.",
+                    "type": "string"
+                },
+                "jti": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "description": "RefreshToken - refresh токен входа, если они включены (POST /token/refresh).",
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
+                },
+                "sub": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.healthResponse": {
+            "type": "object",
+            "properties": {
+                "buildDate": {
+                    "type": "string"
+                },
+                "components": {
+                    "description": "Components - состояние фоновых компонентов (циклов перечитывания, проверок зависимостей, сервера).",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth-service_internal_service_lifecycle.Component"
+                    }
+                },
+                "gitCommit": {
+                    "type": "string"
+                },
+                "redis": {
+                    "description": "Redis - состояние соединения с Redis.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/internal_api_v0.redisHealth"
+                        }
+                    ]
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.impersonateRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "description": "причина (например, номер обращения), пишется в аудит",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "scopes из разрешенных только на чтение. По умолчанию все разрешенные",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
+                },
+                "subject": {
+                    "description": "пользователь",
+                    "type": "string"
+                },
+                "ttl": {
+                    "description": "время жизни, например 10m. По умолчанию максимальное",
+                    "type": "string"
                 }
             }
+        },
+        "internal_api_v0.introspectRequest": {
+            "type": "object",
+            "properties": {
+                "client_cert_thumbprint": {
+                    "description": "ClientCertThumbprint - отпечаток (x5t#S256) сертификата, с которым клиент обратился к ресурсу.\nОбязателен для токенов, привязанных к сертификату (RFC 8705).",
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.introspectResponse": {
+            "type": "object",
+            "properties": {
+                "acr": {
+                    "type": "string"
+                },
+                "act": {
+                    "$ref": "#/definitions/internal_api_v0.actor"
+                },
+                "active": {
+                    "type": "boolean"
+                },
+                "aud": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "cnf": {
+                    "$ref": "#/definitions/internal_api_v0.confirmation"
+                },
+                "env": {
+                    "type": "string"
+                },
+                "exp": {
+                    "type": "integer"
+                },
+                "grace": {
+                    "type": "boolean"
+                },
+                "groups": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "iat": {
+                    "type": "integer"
+                },
+                "iss": {
+                    "type": "string"
+                },
+                "jti": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scope": {
+                    "type": "string"
+                },
+                "sid": {
+                    "type": "string"
+                },
+                "sub": {
+                    "type": "string"
+                },
+                "tenant": {
+                    "type": "string"
+                },
+                "tg_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api_v0.jwtSVIDRequest": {
+            "type": "object",
+            "properties": {
+                "audience": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "internal_api_v0.keyUsage": {
+            "type": "object",
+            "properties": {
+                "issued": {
+                    "type": "integer"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "last_issued_at": {
+                    "type": "string"
+                },
+                "last_verified_at": {
+                    "type": "string"
+                },
+                "retirable": {
+                    "type": "boolean"
+                },
+                "verified": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api_v0.keyUsageResponse": {
+            "type": "object",
+            "properties": {
+                "idle_for": {
+                    "type": "string"
+                },
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_api_v0.keyUsage"
+                    }
+                }
+            }
+        },
+        "internal_api_v0.logSamplingSettings": {
+            "type": "object",
+            "properties": {
+                "routes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "internal_api_v0.membershipsResponse": {
+            "type": "object",
+            "properties": {
+                "groups": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "internal_api_v0.notificationRequest": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "description": "Detail - подробности для пользователя, например IP.",
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "Kind - вид уведомления: new_login или password_change.",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.notificationResponse": {
+            "type": "object",
+            "properties": {
+                "sent": {
+                    "description": "Sent - уведомление отправлено. false - пользователь отключил уведомления этого вида.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "internal_api_v0.passkeyLoginRequest": {
+            "type": "object",
+            "properties": {
+                "subject": {
+                    "description": "Subject - пользователь. Если не указан, браузер предложит ключи, сохраненные на устройстве.",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.qrLoginClaimRequest": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "description": "секрет из ответа POST /qr-login",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.qrLoginStatusResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "$ref": "#/definitions/auth-service_internal_service_qrlogin.Status"
+                }
+            }
+        },
+        "internal_api_v0.readOnlyRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "description": "Reason - причина включения, показывается в состоянии режима и пишется в журнал аудита.",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.readinessResponse": {
+            "type": "object",
+            "properties": {
+                "canary": {
+                    "description": "Canary - результат последней проверки пробным токеном, если она включена.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auth-service_internal_service_canary.Result"
+                        }
+                    ]
+                },
+                "dependencies": {
+                    "description": "Dependencies - состояние внешних зависимостей (только /readyz).",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/auth-service_internal_service_dependency.Status"
+                    }
+                },
+                "read_only": {
+                    "description": "ReadOnly - состояние режима только для чтения, если он включен. Экземпляр в этом режиме\nостается готовым: проверка токенов работает",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auth-service_internal_service_readonly.Status"
+                        }
+                    ]
+                },
+                "status": {
+                    "description": "Status - ready, draining, canary_failed или dependency_unavailable (только /readyz).",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.redisHealth": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "nodes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "object",
+                        "additionalProperties": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "stats": {
+                    "$ref": "#/definitions/auth-service_internal_storage_redis.Stats"
+                },
+                "status": {
+                    "description": "Status - ok или error.",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.refreshRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.revokeRequest": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                },
+                "token_type_hint": {
+                    "description": "TokenTypeHint - тип токена по RFC 7009. Сервис отзывает только access токены, подсказка не используется.",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.scimError": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "schemas": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scimType": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.sessionResponse": {
+            "type": "object",
+            "properties": {
+                "audience": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "description": "Current - сессия, которой выпущен токен запроса.",
+                    "type": "boolean"
+                },
+                "device": {
+                    "$ref": "#/definitions/auth-service_internal_service_refresh.Device"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "description": "LastSeenAt - последняя активность скользящей сессии.",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.sessionsResponse": {
+            "type": "object",
+            "properties": {
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_api_v0.sessionResponse"
+                    }
+                }
+            }
+        },
+        "internal_api_v0.setMemberRequest": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "enum": [
+                        "viewer",
+                        "editor",
+                        "owner"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auth-service_internal_service_group.Role"
+                        }
+                    ]
+                }
+            }
+        },
+        "internal_api_v0.telegramLoginRequest": {
+            "type": "object",
+            "properties": {
+                "auth_date": {
+                    "type": "integer"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "init_data": {
+                    "description": "InitData - Telegram.WebApp.initData мини-приложения. Если не передан, проверяются поля виджета входа.",
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "photo_url": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.tokenResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "integer"
+                },
+                "jti": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "description": "RefreshToken - refresh токен входа, если они включены (POST /token/refresh).",
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_v0.verificationBundleResponse": {
+            "type": "object",
+            "properties": {
+                "bundle": {
+                    "description": "Bundle - JWT с typ auth-bundle+jwt, загружается authclient.ParseBundle.",
+                    "type": "string"
+                },
+                "deactivated": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "integer"
+                },
+                "keys": {
+                    "type": "integer"
+                },
+                "revocations": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api_v0.x509SVIDRequest": {
+            "type": "object",
+            "properties": {
+                "csr": {
+                    "description": "CSR в формате PEM, подписанный ключом сервиса",
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        },
+        "ApiKey": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerToken": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        },
+        "BootstrapToken": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -33,7 +5823,7 @@ const docTemplate = `{
 var SwaggerInfo = &swag.Spec{
 	Version:          "1.0",
 	Host:             "localhost:8080",
-	BasePath:         "/api/v0",
+	BasePath:         "",
 	Schemes:          []string{},
 	Title:            "Auth Service API",
 	Description:      "API для работы с авторизацией",
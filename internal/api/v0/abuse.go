@@ -0,0 +1,136 @@
+package v0
+
+import (
+	"auth-service/internal/service/abuse"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// banRequest - ручная блокировка источника.
+type banRequest struct {
+	// Scope - ip:<адрес>, net:<сеть CIDR> или asn:<номер>.
+	Scope  string `json:"scope"`
+	Detail string `json:"detail"`
+}
+
+// ListBans возвращает действующие блокировки IP, сетей и ASN.
+//
+// ListBans godoc
+//
+//	@Summary		Список блокировок
+//	@Description	Действующие блокировки источников запросов: после обращения к ловушке, перебора учетных данных или ручные
+//	@Tags			abuse
+//	@Produce		json
+//	@Security		AdminToken
+//	@Success		200	{array}		abuse.Ban
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/admin/bans [get]
+func (s *Handler) ListBans(c echo.Context) error {
+	if s.abuse == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "abuse protection is not configured"})
+	}
+
+	bans, err := s.abuse.Bans(c.Request().Context())
+	if err != nil {
+		logrus.WithError(err).Error("error list bans")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to list bans"})
+	}
+
+	return c.JSON(http.StatusOK, bans)
+}
+
+// CreateBan вручную блокирует IP, сеть или ASN на срок блокировки.
+//
+// CreateBan godoc
+//
+//	@Summary		Заблокировать источник
+//	@Description	Блокирует IP (ip:10.0.0.1), сеть (net:10.0.0.0/24) или ASN (asn:64500) на срок блокировки. Повторная блокировка возвращает действующую
+//	@Tags			abuse
+//	@Accept			json
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			request	body		banRequest	true	"Источник"
+//	@Success		200		{object}	abuse.Ban
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/admin/bans [put]
+func (s *Handler) CreateBan(c echo.Context) error {
+	if s.abuse == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "abuse protection is not configured"})
+	}
+
+	var req banRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+	}
+
+	ban, err := s.abuse.Ban(c.Request().Context(), req.Scope, abuse.ReasonManual, req.Detail)
+	if errors.Is(err, abuse.ErrInvalidScope) {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+	}
+
+	if err != nil {
+		logrus.WithError(err).Error("error ban source")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to ban source"})
+	}
+
+	return c.JSON(http.StatusOK, ban)
+}
+
+// DeleteBan снимает блокировку, например ошибочную.
+//
+// DeleteBan godoc
+//
+//	@Summary		Снять блокировку
+//	@Tags			abuse
+//	@Security		AdminToken
+//	@Param			scope	query	string	true	"Источник: ip:<адрес>, net:<сеть CIDR> или asn:<номер>"
+//	@Success		204
+//	@Failure		400	{object}	errorResponse
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/admin/bans [delete]
+func (s *Handler) DeleteBan(c echo.Context) error {
+	if s.abuse == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "abuse protection is not configured"})
+	}
+
+	err := s.abuse.Unban(c.Request().Context(), c.QueryParam("scope"))
+
+	switch {
+	case errors.Is(err, abuse.ErrInvalidScope):
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+	case errors.Is(err, abuse.ErrNotFound):
+		return c.JSON(http.StatusNotFound, errorResponse{Error: err.Error()})
+	case err != nil:
+		logrus.WithError(err).Error("error unban source")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to unban source"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// loginFailed учитывает неудачный вход для обнаружения перебора учетных данных.
+func (s *Handler) loginFailed(c echo.Context, username string) {
+	if s.abuse == nil {
+		return
+	}
+
+	client := s.abuse.ClientOf(c.RealIP(), c.Request().Header)
+
+	if _, err := s.abuse.LoginFailed(c.Request().Context(), client, username); err != nil {
+		logrus.WithError(err).Error("error count login failure")
+	}
+}
@@ -0,0 +1,120 @@
+package v0
+
+import (
+	"auth-service/internal/service/abuse"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAbuseHandler(t *testing.T, opts ...abuse.Option) (*Handler, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	svc, err := abuse.New(append([]abuse.Option{abuse.WithClient(client), abuse.WithRegisterer(prometheus.NewRegistry())}, opts...)...)
+	require.NoError(t, err)
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"), WithAbuse(svc))
+	require.NoError(t, err)
+
+	return h, mr
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestBans(t *testing.T) {
+	t.Parallel()
+
+	h, mr := newAbuseHandler(t)
+
+	rec := callGroups(t, h.CreateBan, http.MethodPut, "/", `{"scope":"host:example.com"}`, nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callGroups(t, h.CreateBan, http.MethodPut, "/", `{`, nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callGroups(t, h.CreateBan, http.MethodPut, "/", `{"scope":"net:10.0.0.0/24","detail":"scanner"}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var ban abuse.Ban
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&ban))
+	assert.Equal(t, "net:10.0.0.0/24", ban.Scope)
+	assert.Equal(t, abuse.ReasonManual, ban.Reason)
+
+	rec = callGroups(t, h.ListBans, http.MethodGet, "/", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var bans []abuse.Ban
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&bans))
+	assert.Equal(t, []abuse.Ban{ban}, bans)
+
+	rec = callGroups(t, h.DeleteBan, http.MethodDelete, "/?scope=net:10.0.0.0/24", "", nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = callGroups(t, h.DeleteBan, http.MethodDelete, "/?scope=net:10.0.0.0/24", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = callGroups(t, h.DeleteBan, http.MethodDelete, "/?scope=10.0.0.0", "", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// Redis недоступен
+	mr.Close()
+
+	rec = callGroups(t, h.ListBans, http.MethodGet, "/", "", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	rec = callGroups(t, h.CreateBan, http.MethodPut, "/", `{"scope":"ip:10.0.0.1"}`, nil)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	rec = callGroups(t, h.DeleteBan, http.MethodDelete, "/?scope=ip:10.0.0.1", "", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestBans_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	for _, fn := range []echo.HandlerFunc{h.ListBans, h.CreateBan, h.DeleteBan} {
+		rec := callGroups(t, fn, http.MethodGet, "/", "", nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestLoginFailed(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newAbuseHandler(t, abuse.WithThresholds(3, 0, 0))
+
+	for i := range 3 {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+
+		h.loginFailed(echo.New().NewContext(req, httptest.NewRecorder()), fmt.Sprintf("user-%d", i))
+	}
+
+	rec := callGroups(t, h.ListBans, http.MethodGet, "/", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var bans []abuse.Ban
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&bans))
+	require.Len(t, bans, 1)
+	assert.Equal(t, "ip:10.0.0.1", bans[0].Scope)
+	assert.Equal(t, abuse.ReasonCredentialStuffing, bans[0].Reason)
+}
@@ -0,0 +1,86 @@
+package v0
+
+import (
+	"auth-service/internal/service/ldap"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// adminLoginRequest - логин и пароль учетной записи в корпоративном каталоге.
+type adminLoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// AdminLogin выпускает токен административного API по логину и паролю из корпоративного каталога.
+//
+// AdminLogin godoc
+//
+//	@Summary		Вход администратора через LDAP
+//	@Description	Проверяет логин и пароль в корпоративном каталоге (LDAP/Active Directory) и выпускает токен аудитории admin. Роли токена (scopes admin:viewer, admin:operator) берутся из групп пользователя в каталоге. Если включен SCIM, вход разрешен только учетным записям, которые корпоративный IdP завел и не отключил. Токен принимается административным API вместо статического токена
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		adminLoginRequest	true	"Логин и пароль"
+//	@Success		200		{object}	tokenResponse
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401		{object}	errorResponse
+//	@Failure		403		{object}	errorResponse
+//	@Failure		404		{object}	errorResponse
+//	@Failure		503		{object}	errorResponse
+//	@Router			/admin/login [post]
+func (s *Handler) AdminLogin(c echo.Context) error {
+	if s.directory == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "admin login is not configured"})
+	}
+
+	var req adminLoginRequest
+
+	if err := c.Bind(&req); err != nil || req.Username == "" || req.Password == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "username and password are required"})
+	}
+
+	log := logrus.WithFields(logrus.Fields{
+		"username": req.Username,
+		"ip":       c.RealIP(),
+	})
+
+	login, err := s.directory.Login(c.Request().Context(), req.Username, req.Password)
+
+	switch {
+	case errors.Is(err, ldap.ErrInvalidCredentials):
+		log.Warn("admin login failed: invalid credentials")
+		s.loginFailed(c, req.Username)
+
+		return c.JSON(http.StatusUnauthorized, errorResponse{Error: err.Error()})
+	case errors.Is(err, ldap.ErrNoRoles):
+		log.Warn("admin login failed: no admin roles")
+
+		return c.JSON(http.StatusForbidden, errorResponse{Error: err.Error()})
+	case errors.Is(err, ldap.ErrInactive):
+		log.Warn("admin login failed: account is not active")
+
+		return c.JSON(http.StatusForbidden, errorResponse{Error: err.Error()})
+	case err != nil:
+		log.WithError(err).Error("error admin login")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to complete admin login"})
+	}
+
+	log.WithFields(logrus.Fields{
+		"roles": login.Identity.Roles,
+		"jti":   login.Claims.ID,
+	}).Info("admin login completed")
+
+	return c.JSON(http.StatusOK, tokenResponse{
+		AccessToken: login.Token,
+		TokenType:   "Bearer",
+		ExpiresAt:   login.Claims.ExpiresAt.Unix(),
+		Scope:       strings.Join(login.Claims.Scopes, " "),
+		JTI:         login.Claims.ID,
+	})
+}
@@ -0,0 +1,79 @@
+package v0
+
+import (
+	"auth-service/internal/service/ldap"
+	"auth-service/internal/service/token"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminLogin(t *testing.T) {
+	t.Parallel()
+
+	issuer, err := token.NewIssuer(token.WithSigningKeys(testSigningKeys{key: []byte("secret")}))
+	require.NoError(t, err)
+
+	// каталог недоступен: порт закрыт
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, ln.Close())
+
+	directory, err := ldap.New(
+		ldap.WithIssuer(issuer),
+		ldap.WithURL("ldap://"+ln.Addr().String()),
+		ldap.WithBaseDN("ou=people,dc=example,dc=org"),
+		ldap.WithGroupRoles(map[string][]string{"cn=admins,dc=example,dc=org": {ldap.RoleOperator}}),
+	)
+	require.NoError(t, err)
+
+	configured, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"), WithDirectory(directory))
+	require.NoError(t, err)
+
+	notConfigured, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		handler    *Handler
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "error case: not configured",
+			handler:    notConfigured,
+			body:       `{"username":"jdoe","password":"secret"}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "error case: no password",
+			handler:    configured,
+			body:       `{"username":"jdoe"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "error case: invalid body",
+			handler:    configured,
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "error case: directory is unavailable",
+			handler:    configured,
+			body:       `{"username":"jdoe","password":"secret"}`,
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := callAuthorized(t, tt.handler.AdminLogin, "", "", tt.body)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package v0
+
+import (
+	"auth-service/internal/service/apikey"
+	"auth-service/internal/service/quota"
+	"auth-service/internal/service/ratelimit"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// createAPIKeyRequest - запрос на выпуск API ключа.
+type createAPIKeyRequest struct {
+	Name         string `json:"name"`
+	QuotaDaily   int64  `json:"quota_daily"`   // квота на сутки, 0 - квота из конфигурации
+	QuotaMonthly int64  `json:"quota_monthly"` // квота на месяц, 0 - квота из конфигурации
+	Sandbox      bool   `json:"sandbox"`       // ключ песочницы: помечается env=sandbox и удаляется через sandbox.ttl
+}
+
+// CreateAPIKey выпускает API ключ. Если настроена запись секретов в Vault, ключ записывается туда,
+// а в ответе возвращается только путь секрета.
+//
+// CreateAPIKey godoc
+//
+//	@Summary		Выпустить API ключ
+//	@Description	Ключ возвращается один раз в формате <id>.<секрет>. Если настроен apikeys.vault_path, секрет записывается в Vault и не попадает в ответ
+//	@Tags			apikeys
+//	@Accept			json
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			request	body		createAPIKeyRequest	true	"API ключ"
+//	@Success		201		{object}	apikey.Created
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/admin/apikeys [post]
+func (s *Handler) CreateAPIKey(c echo.Context) error {
+	if s.apiKeys == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "api keys are not configured"})
+	}
+
+	var req createAPIKeyRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+	}
+
+	created, err := s.apiKeys.Create(c.Request().Context(), apikey.CreateRequest{
+		Name:         req.Name,
+		QuotaDaily:   req.QuotaDaily,
+		QuotaMonthly: req.QuotaMonthly,
+		Sandbox:      req.Sandbox,
+	})
+	if errors.Is(err, apikey.ErrInvalidArgument) {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+	}
+
+	if err != nil {
+		logrus.WithError(err).Error("error create api key")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to create api key"})
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"api_key":    created.ID,
+		"name":       created.Name,
+		"vault_path": created.VaultPath,
+		"env":        created.Env,
+	}).Info("api key created")
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// APIKeyUsage возвращает использование квот API ключа за текущие сутки и месяц.
+// Ключ может посмотреть только собственное использование. Запрос не расходует квоту.
+//
+// APIKeyUsage godoc
+//
+//	@Summary		Использование квот API ключа
+//	@Tags			apikeys
+//	@Produce		json
+//	@Security		ApiKey
+//	@Param			id	path		string	true	"ID API ключа"
+//	@Success		200	{object}	quota.Usage
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/apikeys/{id}/usage [get]
+func (s *Handler) APIKeyUsage(c echo.Context) error {
+	if s.quota == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "quota is not configured"})
+	}
+
+	key, ok := quota.FromContext(c.Request().Context())
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, errorResponse{Error: "api key is required"})
+	}
+
+	if key.ID != c.Param("id") {
+		return c.JSON(http.StatusForbidden, errorResponse{Error: "usage of another api key"})
+	}
+
+	usage, err := s.quota.Usage(c.Request().Context(), key)
+	if err != nil {
+		logrus.WithError(err).WithField("api_key", key.ID).Error("error get api key usage")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "quota storage is unavailable"})
+	}
+
+	return c.JSON(http.StatusOK, usage)
+}
+
+// apiKeyRateLimitRequest - ограничение частоты запросов API ключа: тариф или собственное правило.
+type apiKeyRateLimitRequest struct {
+	Tier          string `json:"tier,omitempty"`           // тариф из rate_limit.tiers
+	Requests      int    `json:"requests,omitempty"`       // не более requests запросов
+	WindowSeconds int64  `json:"window_seconds,omitempty"` // за window_seconds секунд
+}
+
+// apiKeyRateLimitResponse - действующее ограничение частоты запросов API ключа.
+type apiKeyRateLimitResponse struct {
+	// Source - откуда взято ограничение: key - собственное, tier - тариф, default - из конфигурации.
+	Source string `json:"source"`
+	Tier   string `json:"tier,omitempty"`
+	// Requests и WindowSeconds - правило, 0 - без ограничения.
+	Requests      int   `json:"requests"`
+	WindowSeconds int64 `json:"window_seconds"`
+}
+
+func newAPIKeyRateLimitResponse(limit quota.RateLimit) apiKeyRateLimitResponse {
+	return apiKeyRateLimitResponse{
+		Source:        limit.Source,
+		Tier:          limit.Tier,
+		Requests:      limit.Rule.Requests,
+		WindowSeconds: int64(limit.Rule.Window / time.Second),
+	}
+}
+
+// GetAPIKeyRateLimit возвращает действующее ограничение частоты запросов API ключа.
+//
+// GetAPIKeyRateLimit godoc
+//
+//	@Summary		Ограничение частоты запросов API ключа
+//	@Tags			apikeys
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			id	path		string	true	"ID API ключа"
+//	@Success		200	{object}	apiKeyRateLimitResponse
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/admin/apikeys/{id}/rate-limit [get]
+func (s *Handler) GetAPIKeyRateLimit(c echo.Context) error {
+	if s.quota == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "quota is not configured"})
+	}
+
+	limit, err := s.quota.RateLimit(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return apiKeyRateLimitError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, newAPIKeyRateLimitResponse(limit))
+}
+
+// UpdateAPIKeyRateLimit назначает API ключу тариф или собственное ограничение частоты запросов.
+// Ограничение хранится в записи ключа и начинает действовать со следующего запроса без перезапуска.
+//
+// UpdateAPIKeyRateLimit godoc
+//
+//	@Summary		Изменить ограничение частоты запросов API ключа
+//	@Description	Задается либо tier из rate_limit.tiers, либо requests и window_seconds
+//	@Tags			apikeys
+//	@Accept			json
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			id		path		string					true	"ID API ключа"
+//	@Param			request	body		apiKeyRateLimitRequest	true	"Ограничение"
+//	@Success		200		{object}	apiKeyRateLimitResponse
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		422	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/admin/apikeys/{id}/rate-limit [put]
+func (s *Handler) UpdateAPIKeyRateLimit(c echo.Context) error {
+	if s.quota == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "quota is not configured"})
+	}
+
+	var req apiKeyRateLimitRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+	}
+
+	limit, err := s.quota.SetRateLimit(c.Request().Context(), c.Param("id"), quota.RateLimitOverride{
+		Tier: req.Tier,
+		Rule: ratelimit.Rule{Requests: req.Requests, Window: time.Duration(req.WindowSeconds) * time.Second},
+	})
+	if err != nil {
+		return apiKeyRateLimitError(c, err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"api_key":  c.Param("id"),
+		"source":   limit.Source,
+		"tier":     limit.Tier,
+		"requests": limit.Rule.Requests,
+		"window":   limit.Rule.Window,
+	}).Info("api key rate limit updated")
+
+	return c.JSON(http.StatusOK, newAPIKeyRateLimitResponse(limit))
+}
+
+// ResetAPIKeyRateLimit удаляет тариф и собственное ограничение API ключа: начинает действовать
+// ограничение по умолчанию.
+//
+// ResetAPIKeyRateLimit godoc
+//
+//	@Summary	Сбросить ограничение частоты запросов API ключа
+//	@Tags		apikeys
+//	@Security	AdminToken
+//	@Param		id	path	string	true	"ID API ключа"
+//	@Success	204
+//	@Failure	401
+//	@Failure	404	{object}	errorResponse
+//	@Failure	503	{object}	errorResponse
+//	@Router		/admin/apikeys/{id}/rate-limit [delete]
+func (s *Handler) ResetAPIKeyRateLimit(c echo.Context) error {
+	if s.quota == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "quota is not configured"})
+	}
+
+	if err := s.quota.ResetRateLimit(c.Request().Context(), c.Param("id")); err != nil {
+		return apiKeyRateLimitError(c, err)
+	}
+
+	logrus.WithField("api_key", c.Param("id")).Info("api key rate limit reset")
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func apiKeyRateLimitError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, quota.ErrNotFound):
+		return c.JSON(http.StatusNotFound, errorResponse{Error: err.Error()})
+	case errors.Is(err, quota.ErrUnknownTier), errors.Is(err, quota.ErrInvalidRateLimit):
+		return c.JSON(http.StatusUnprocessableEntity, errorResponse{Error: err.Error()})
+	default:
+		logrus.WithError(err).WithField("api_key", c.Param("id")).Error("error access api key rate limit")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "quota storage is unavailable"})
+	}
+}
@@ -0,0 +1,249 @@
+package v0
+
+import (
+	"auth-service/internal/service/apikey"
+	"auth-service/internal/service/quota"
+	"auth-service/internal/service/ratelimit"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestAPIKeyUsage(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	svc, err := quota.New(quota.WithClient(client))
+	require.NoError(t, err)
+
+	key := quota.Key{ID: "bot", Limits: quota.Limits{Daily: 10}}
+
+	_, err = svc.Consume(t.Context(), key)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		quota    *quota.Service
+		key      *quota.Key
+		id       string
+		wantCode int
+	}{
+		{
+			name:     "not configured",
+			id:       "bot",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "without api key",
+			quota:    svc,
+			id:       "bot",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "another key",
+			quota:    svc,
+			key:      &key,
+			id:       "other",
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "own key",
+			quota:    svc,
+			key:      &key,
+			id:       "bot",
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h, err := New(
+				WithVersion("1.0.0"),
+				WithBuildDate("2021-01-01"),
+				WithGitCommit("1234567890"),
+				WithQuota(tt.quota),
+			)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v0/apikeys/"+tt.id+"/usage", nil)
+			if tt.key != nil {
+				req = req.WithContext(quota.NewContext(req.Context(), *tt.key))
+			}
+
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+
+			require.NoError(t, h.APIKeyUsage(c))
+			require.Equal(t, tt.wantCode, rec.Code)
+
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var usage quota.Usage
+
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&usage))
+			assert.Equal(t, "bot", usage.ID)
+			assert.Equal(t, int64(1), usage.Daily.Used)
+			assert.Equal(t, int64(9), *usage.Daily.Remaining)
+		})
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestCreateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	svc, err := apikey.New(apikey.WithClient(client))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		apiKeys  *apikey.Service
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "not configured",
+			body:     `{"name": "bot"}`,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "invalid body",
+			apiKeys:  svc,
+			body:     `{"name":`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "without name",
+			apiKeys:  svc,
+			body:     `{"quota_daily": 10}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "created",
+			apiKeys:  svc,
+			body:     `{"name": "bot", "quota_daily": 10}`,
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "sandbox",
+			apiKeys:  svc,
+			body:     `{"name": "bot", "quota_daily": 10, "sandbox": true}`,
+			wantCode: http.StatusCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h, err := New(
+				WithVersion("1.0.0"),
+				WithBuildDate("2021-01-01"),
+				WithGitCommit("1234567890"),
+				WithAPIKeys(tt.apiKeys),
+			)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v0/admin/apikeys", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, h.CreateAPIKey(echo.New().NewContext(req, rec)))
+			require.Equal(t, tt.wantCode, rec.Code)
+
+			if tt.wantCode != http.StatusCreated {
+				return
+			}
+
+			var created apikey.Created
+
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+			assert.Equal(t, "bot", created.Name)
+			assert.True(t, strings.HasPrefix(created.APIKey, created.ID+"."))
+			assert.Equal(t, "10", mr.HGet(apikey.RecordKey(created.ID), apikey.FieldQuotaDaily))
+			assert.Equal(t, created.Env, mr.HGet(apikey.RecordKey(created.ID), apikey.FieldEnv))
+			assert.Equal(t, created.Env == apikey.EnvSandbox, created.ExpiresAt != nil)
+		})
+	}
+}
+
+func TestAPIKeyRateLimit(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	svc, err := quota.New(
+		quota.WithClient(client),
+		quota.WithRateLimits(ratelimit.Rule{Requests: 60, Window: time.Minute}, map[string]ratelimit.Rule{
+			"partner": {Requests: 600, Window: time.Minute},
+		}),
+	)
+	require.NoError(t, err)
+
+	mr.HSet(apikey.RecordKey("bot"), apikey.FieldSecretHash, apikey.HashSecret("s3cret"))
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"), WithQuota(svc))
+	require.NoError(t, err)
+
+	params := map[string]string{"id": "bot"}
+
+	rec := callGroups(t, h.GetAPIKeyRateLimit, http.MethodGet, "/", "", params)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"source":"default","requests":60,"window_seconds":60}`, rec.Body.String())
+
+	rec = callGroups(t, h.UpdateAPIKeyRateLimit, http.MethodPut, "/", `{"tier":"partner"}`, params)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"source":"tier","tier":"partner","requests":600,"window_seconds":60}`, rec.Body.String())
+
+	rec = callGroups(t, h.UpdateAPIKeyRateLimit, http.MethodPut, "/", `{"requests":5,"window_seconds":3600}`, params)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"source":"key","requests":5,"window_seconds":3600}`, rec.Body.String())
+
+	rec = callGroups(t, h.UpdateAPIKeyRateLimit, http.MethodPut, "/", `{"tier":"gold"}`, params)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = callGroups(t, h.UpdateAPIKeyRateLimit, http.MethodPut, "/", `[]`, params)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callGroups(t, h.ResetAPIKeyRateLimit, http.MethodDelete, "/", "", params)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = callGroups(t, h.GetAPIKeyRateLimit, http.MethodGet, "/", "", map[string]string{"id": "unknown"})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// без квот ограничения ключей недоступны
+	h, err = New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	rec = callGroups(t, h.ResetAPIKeyRateLimit, http.MethodDelete, "/", "", params)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,97 @@
+package v0
+
+import (
+	"auth-service/internal/service/authz"
+	"auth-service/internal/service/token"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// authzCheckRequest - запрос на проверку доступа. Субъект задается токеном или явно:
+// по токену учитываются его scopes и claim groups, для явного субъекта роли читаются из хранилища.
+type authzCheckRequest struct {
+	Token    string `json:"token,omitempty"`
+	Subject  string `json:"subject,omitempty"`
+	Action   string `json:"action"`
+	Resource string `json:"resource"`        // ресурс в формате <тип>:<id>, например group:abc
+	Owner    string `json:"owner,omitempty"` // владелец ресурса, если известен. Доступен политикам
+}
+
+// AuthzCheck отвечает, может ли субъект выполнить действие над ресурсом.
+//
+// AuthzCheck godoc
+//
+//	@Summary		Проверить доступ к ресурсу
+//	@Description	Отвечает, может ли субъект выполнить действие над ресурсом, по scopes токена и ролям субъекта в группах, а если настроены политики - по политикам. Недействительный токен - allowed=false
+//	@Tags			authz
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		authzCheckRequest	true	"Запрос"
+//	@Success		200		{object}	authz.Decision
+//	@Failure		400		{object}	errorResponse
+//	@Failure		404		{object}	errorResponse
+//	@Failure		503		{object}	errorResponse
+//	@Router			/authz/check [post]
+func (s *Handler) AuthzCheck(c echo.Context) error {
+	if s.authz == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "authorization is not configured"})
+	}
+
+	var body authzCheckRequest
+
+	if err := c.Bind(&body); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+	}
+
+	req := authz.Request{
+		Subject:  body.Subject,
+		Action:   body.Action,
+		Resource: body.Resource,
+		Owner:    body.Owner,
+	}
+
+	if body.Token != "" {
+		if s.validator == nil {
+			return c.JSON(http.StatusNotFound, errorResponse{Error: "token validation is not configured"})
+		}
+
+		claims, err := s.validator.Validate(c.Request().Context(), body.Token)
+		if errors.Is(err, token.ErrInvalidToken) {
+			return c.JSON(http.StatusOK, authz.Decision{Reason: "invalid token"})
+		}
+
+		if err != nil {
+			logrus.WithError(err).Error("error validate token")
+
+			return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "signing keys are unavailable"})
+		}
+
+		req.Subject = claims.Subject
+		req.Groups = claims.Groups
+
+		if req.Groups == nil {
+			req.Groups = map[string]string{}
+		}
+
+		// токен без scopes не ограничивает доступ
+		if len(claims.Scopes) > 0 {
+			req.Scopes = claims.Scopes
+		}
+	}
+
+	decision, err := s.authz.Check(c.Request().Context(), req)
+	if errors.Is(err, authz.ErrInvalidRequest) {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+	}
+
+	if err != nil {
+		logrus.WithError(err).Error("error check access")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "authorization is unavailable"})
+	}
+
+	return c.JSON(http.StatusOK, decision)
+}
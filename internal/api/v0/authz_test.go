@@ -0,0 +1,145 @@
+package v0
+
+import (
+	"auth-service/internal/service/authz"
+	"auth-service/internal/service/group"
+	"auth-service/internal/service/token"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestAuthzCheck(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	groups, err := group.New(group.WithClient(client))
+	require.NoError(t, err)
+
+	g, err := groups.Create(context.Background(), "notes", "owner-1")
+	require.NoError(t, err)
+	require.NoError(t, groups.SetMember(context.Background(), g.ID, "viewer-1", group.RoleViewer))
+
+	az, err := authz.New(authz.WithGroups(groups))
+	require.NoError(t, err)
+
+	key := []byte("secret")
+
+	validator, err := token.NewValidator(token.WithKeys(testKeys{key: key}))
+	require.NoError(t, err)
+
+	issuer, err := token.NewIssuer(token.WithSigningKeys(testSigningKeys{key: key}), token.WithGroups(groups))
+	require.NoError(t, err)
+
+	ownerToken, _, err := issuer.Issue(context.Background(), token.IssueRequest{Subject: "owner-1", TTL: time.Hour})
+	require.NoError(t, err)
+
+	readOnlyToken, _, err := issuer.Impersonate(context.Background(), token.ImpersonateRequest{
+		Actor: "support-1", Subject: "owner-1", Reason: "ticket 1",
+	})
+	require.NoError(t, err)
+
+	h, err := New(
+		WithVersion("1.0.0"),
+		WithBuildDate("2021-01-01"),
+		WithGitCommit("1234567890"),
+		WithValidator(validator),
+		WithAuthz(az),
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		want       string
+	}{
+		{
+			name:       "positive case: subject",
+			body:       `{"subject":"viewer-1","action":"read","resource":"group:` + g.ID + `"}`,
+			wantStatus: http.StatusOK,
+			want:       `{"allowed":true,"reason":"allowed"}`,
+		},
+		{
+			name:       "negative case: subject role",
+			body:       `{"subject":"viewer-1","action":"write","resource":"group:` + g.ID + `"}`,
+			wantStatus: http.StatusOK,
+			want:       `{"allowed":false,"reason":"action is not allowed for subject role"}`,
+		},
+		{
+			name:       "positive case: token",
+			body:       `{"token":"` + ownerToken + `","action":"manage","resource":"group:` + g.ID + `"}`,
+			wantStatus: http.StatusOK,
+			want:       `{"allowed":true,"reason":"allowed"}`,
+		},
+		{
+			name:       "negative case: read-only token",
+			body:       `{"token":"` + readOnlyToken + `","action":"write","resource":"group:` + g.ID + `"}`,
+			wantStatus: http.StatusOK,
+			want:       `{"allowed":false,"reason":"action is not allowed by token scopes"}`,
+		},
+		{
+			name:       "negative case: invalid token",
+			body:       `{"token":"invalid","action":"read","resource":"group:` + g.ID + `"}`,
+			wantStatus: http.StatusOK,
+			want:       `{"allowed":false,"reason":"invalid token"}`,
+		},
+		{
+			name:       "error case: invalid resource",
+			body:       `{"subject":"viewer-1","action":"read","resource":"group"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "error case: invalid body",
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, h.AuthzCheck(echo.New().NewContext(req, rec)))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			if tt.want != "" {
+				assert.JSONEq(t, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthzCheck_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, h.AuthzCheck(echo.New().NewContext(req, rec)))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
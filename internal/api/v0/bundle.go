@@ -0,0 +1,61 @@
+package v0
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// verificationBundleResponse - подписанный пакет для проверки токенов без обращения к сервису.
+type verificationBundleResponse struct {
+	// Bundle - JWT с typ auth-bundle+jwt, загружается authclient.ParseBundle.
+	Bundle      string `json:"bundle"`
+	ExpiresAt   int64  `json:"expires_at"`
+	Keys        int    `json:"keys"`
+	Revocations int    `json:"revocations"`
+	Deactivated int    `json:"deactivated"`
+}
+
+// ExportVerificationBundle выгружает пакет для проверки токенов без обращения к сервису.
+//
+// ExportVerificationBundle godoc
+//
+//	@Summary		Выгрузить пакет проверки токенов
+//	@Description	Подписанный текущим ключом пакет (JWT с typ auth-bundle+jwt): kid ключей, которыми принимаются токены, отметки об отзыве, отключенные пользователи, iss и аудитории. Пограничные сервисы загружают его через authclient.ParseBundle и проверяют токены без обращения к сервису до истечения пакета. Ключи симметричные, поэтому сами ключи в пакет не входят. То же выгружает команда export-bundle
+//	@Tags			admin
+//	@Produce		json
+//	@Security		AdminToken
+//	@Success		200	{object}	verificationBundleResponse
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/admin/verification-bundle [get]
+func (s *Handler) ExportVerificationBundle(c echo.Context) error {
+	if s.bundles == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "verification bundles are not configured"})
+	}
+
+	raw, b, err := s.bundles.Export(c.Request().Context())
+	if err != nil {
+		logrus.WithError(err).Error("error export verification bundle")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to export verification bundle"})
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"expires_at":  b.ExpiresAt.Time,
+		"keys":        len(b.Kids),
+		"revocations": len(b.RevokedBefore),
+		"deactivated": len(b.Deactivated),
+		"ip":          c.RealIP(),
+	}).Info("verification bundle exported")
+
+	return c.JSON(http.StatusOK, verificationBundleResponse{
+		Bundle:      raw,
+		ExpiresAt:   b.ExpiresAt.Unix(),
+		Keys:        len(b.Kids),
+		Revocations: len(b.RevokedBefore),
+		Deactivated: len(b.Deactivated),
+	})
+}
@@ -0,0 +1,79 @@
+package v0
+
+import (
+	"auth-service/internal/service/bundle"
+	"auth-service/internal/service/bundle/mocks"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exportBundle(t *testing.T, h *Handler) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+	require.NoError(t, h.ExportVerificationBundle(c))
+
+	return rec
+}
+
+// signBundle выгружает пакет проверки, подписанный ключом key-1.
+func signBundle(t *testing.T, key []byte) string {
+	t.Helper()
+
+	keys := mocks.NewMockkeySource(gomock.NewController(t))
+	keys.EXPECT().KeyIDs(gomock.Any()).Return([]string{"key-1"}, nil)
+	keys.EXPECT().SigningKey(gomock.Any()).Return("key-1", key, nil)
+
+	exporter, err := bundle.New(bundle.WithKeys(keys), bundle.WithIssuer("https://auth.example.com"))
+	require.NoError(t, err)
+
+	raw, _, err := exporter.Export(t.Context())
+	require.NoError(t, err)
+
+	return raw
+}
+
+func TestExportVerificationBundle(t *testing.T) {
+	t.Parallel()
+
+	keys := mocks.NewMockkeySource(gomock.NewController(t))
+
+	exporter, err := bundle.New(bundle.WithKeys(keys))
+	require.NoError(t, err)
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"), WithBundles(exporter))
+	require.NoError(t, err)
+
+	keys.EXPECT().KeyIDs(gomock.Any()).Return([]string{"key-1", "key-2"}, nil)
+	keys.EXPECT().SigningKey(gomock.Any()).Return("key-2", []byte("secret"), nil)
+
+	rec := exportBundle(t, h)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp verificationBundleResponse
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.NotEmpty(t, resp.Bundle)
+	assert.NotZero(t, resp.ExpiresAt)
+	assert.Equal(t, 2, resp.Keys)
+
+	keys.EXPECT().KeyIDs(gomock.Any()).Return(nil, errors.New("vault is sealed"))
+
+	rec = exportBundle(t, h)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	h.bundles = nil
+
+	rec = exportBundle(t, h)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,49 @@
+package v0
+
+import (
+	"auth-service/internal/service/canary"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// RunCanary запускает проверку пробным токеном вне расписания.
+//
+// RunCanary godoc
+//
+//	@Summary		Проверить выпуск токенов пробным токеном
+//	@Description	Выпускает пробный токен и проверяет его так же, как токены клиентов: ключ по kid, отзыв, аудитории, а если включен token.bundle - еще и по пакету проверки. Результат обновляет метрики auth_canary_* и поле canary в GET /ready. Полезно сразу после выката или ротации ключей, не дожидаясь очередной проверки по расписанию
+//	@Tags			admin
+//	@Produce		json
+//	@Security		AdminToken
+//	@Success		200	{object}	canary.Result
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	canary.Result
+//	@Router			/admin/canary [post]
+func (s *Handler) RunCanary(c echo.Context) error {
+	if s.canary == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "canary is not configured"})
+	}
+
+	res := s.canary.Check(c.Request().Context())
+
+	logrus.WithFields(logrus.Fields{
+		"ok":   res.OK,
+		"step": res.Step,
+		"kid":  res.Kid,
+		"ip":   c.RealIP(),
+	}).Info("canary token check requested")
+
+	return c.JSON(canaryStatus(res), res)
+}
+
+// canaryStatus возвращает код ответа для результата проверки пробным токеном.
+func canaryStatus(res canary.Result) int {
+	if !res.OK {
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusOK
+}
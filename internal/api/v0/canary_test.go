@@ -0,0 +1,97 @@
+package v0
+
+import (
+	"auth-service/internal/service/canary"
+	"auth-service/internal/service/token"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCanary(t *testing.T) {
+	t.Parallel()
+
+	issuer, err := token.NewIssuer(token.WithSigningKeys(testSigningKeys{key: []byte("secret")}))
+	require.NoError(t, err)
+
+	validator, err := token.NewValidator(token.WithKeys(testKeys{key: []byte("secret")}))
+	require.NoError(t, err)
+
+	// проверка ключом, которым токены не подписываются: так выглядят неверно подключенные ключи
+	broken, err := token.NewValidator(token.WithKeys(testKeys{key: []byte("other")}))
+	require.NoError(t, err)
+
+	newChecker := func(validator *token.Validator) *canary.Checker {
+		checker, err := canary.New(
+			canary.WithIssuer(issuer),
+			canary.WithValidator(validator),
+			canary.WithRequired(true),
+			canary.WithRegisterer(prometheus.NewRegistry()),
+		)
+		require.NoError(t, err)
+
+		return checker
+	}
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"), WithCanary(newChecker(validator)))
+	require.NoError(t, err)
+
+	call := func(fn echo.HandlerFunc, method string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		require.NoError(t, fn(echo.New().NewContext(httptest.NewRequest(method, "/", nil), rec)))
+
+		return rec
+	}
+
+	// обязательная проверка еще не проходила: экземпляр не готов
+	rec := call(h.Ready, http.MethodGet)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"status":"canary_failed"}`, rec.Body.String())
+
+	rec = call(h.RunCanary, http.MethodPost)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var res canary.Result
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&res))
+	assert.True(t, res.OK)
+	assert.NotEmpty(t, res.Kid)
+
+	rec = call(h.Ready, http.MethodGet)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var ready readinessResponse
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&ready))
+	assert.Equal(t, "ready", ready.Status)
+	require.NotNil(t, ready.Canary)
+	assert.True(t, ready.Canary.OK)
+
+	h.canary = newChecker(broken)
+
+	rec = call(h.RunCanary, http.MethodPost)
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&res))
+	assert.False(t, res.OK)
+	assert.Equal(t, canary.StepValidate, res.Step)
+	assert.NotEmpty(t, res.Error)
+
+	rec = call(h.Ready, http.MethodGet)
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&ready))
+	assert.Equal(t, "canary_failed", ready.Status)
+	require.NotNil(t, ready.Canary)
+	assert.False(t, ready.Canary.OK)
+
+	h.canary = nil
+
+	assert.Equal(t, http.StatusNotFound, call(h.RunCanary, http.MethodPost).Code)
+}
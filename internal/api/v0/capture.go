@@ -0,0 +1,93 @@
+package v0
+
+import (
+	"auth-service/internal/service/capture"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// captureResponse - настройки захвата и захваченные запросы.
+type captureResponse struct {
+	Settings capture.Settings `json:"settings"`
+	Entries  []capture.Entry  `json:"entries"`
+}
+
+// GetCapture возвращает настройки захвата тел запросов и содержимое буфера.
+//
+// GetCapture godoc
+//
+//	@Summary		Получить захваченные запросы
+//	@Description	Возвращает настройки захвата тел запросов и содержимое кольцевого буфера (секреты замаскированы)
+//	@Tags			admin
+//	@Produce		json
+//	@Security		AdminToken
+//	@Success		200	{object}	captureResponse
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Router			/admin/capture [get]
+func (s *Handler) GetCapture(c echo.Context) error {
+	if s.capture == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "body capture is not configured"})
+	}
+
+	return c.JSON(http.StatusOK, captureResponse{
+		Settings: s.capture.Settings(),
+		Entries:  s.capture.Entries(),
+	})
+}
+
+// UpdateCapture включает или выключает захват и задает долю захватываемых запросов по маршрутам.
+//
+// UpdateCapture godoc
+//
+//	@Summary		Изменить настройки захвата
+//	@Description	Включает или выключает захват тел запросов и задает долю (0..1) захватываемых запросов по шаблону маршрута
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			settings	body		capture.Settings	true	"Настройки захвата"
+//	@Success		200			{object}	capture.Settings
+//	@Failure		400			{object}	errorResponse
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Router			/admin/capture [put]
+func (s *Handler) UpdateCapture(c echo.Context) error {
+	if s.capture == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "body capture is not configured"})
+	}
+
+	var settings capture.Settings
+
+	if err := c.Bind(&settings); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+	}
+
+	if err := s.capture.Update(settings); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, s.capture.Settings())
+}
+
+// ClearCapture очищает буфер захваченных запросов.
+//
+// ClearCapture godoc
+//
+//	@Summary		Очистить захваченные запросы
+//	@Tags			admin
+//	@Security		AdminToken
+//	@Success		204
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Router			/admin/capture [delete]
+func (s *Handler) ClearCapture(c echo.Context) error {
+	if s.capture == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "body capture is not configured"})
+	}
+
+	s.capture.Clear()
+
+	return c.NoContent(http.StatusNoContent)
+}
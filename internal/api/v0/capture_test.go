@@ -0,0 +1,132 @@
+package v0
+
+import (
+	"auth-service/internal/service/capture"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCaptureHandler(t *testing.T, c *capture.Capture) *Handler {
+	t.Helper()
+
+	h, err := New(
+		WithVersion("1.0.0"),
+		WithBuildDate("2021-01-01"),
+		WithGitCommit("1234567890"),
+		WithCapture(c),
+	)
+	require.NoError(t, err)
+
+	return h
+}
+
+func TestGetCapture(t *testing.T) {
+	t.Parallel()
+
+	c, err := capture.New()
+	require.NoError(t, err)
+
+	c.Record(capture.Entry{Route: "/api/v0/health", RequestBody: `{"a":1}`})
+
+	h := newCaptureHandler(t, c)
+
+	e := echo.New()
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, h.GetCapture(e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var got captureResponse
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	assert.False(t, got.Settings.Enabled)
+	require.Len(t, got.Entries, 1)
+	assert.Equal(t, "/api/v0/health", got.Entries[0].Route)
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestUpdateCapture(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "positive case",
+			body:       `{"enabled":true,"routes":{"/api/v0/health":0.5}}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"enabled":true,"routes":{"/api/v0/health":0.5}}`,
+		},
+		{
+			name:       "error case: invalid rate",
+			body:       `{"enabled":true,"routes":{"/api/v0/health":2}}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"rate for route /api/v0/health must be between 0 and 1"}`,
+		},
+		{
+			name:       "error case: invalid body",
+			body:       `{"enabled":`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"invalid request body"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := capture.New()
+			require.NoError(t, err)
+
+			h := newCaptureHandler(t, c)
+
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, h.UpdateCapture(echo.New().NewContext(req, rec)))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.JSONEq(t, tt.wantBody, rec.Body.String())
+		})
+	}
+}
+
+func TestClearCapture(t *testing.T) {
+	t.Parallel()
+
+	c, err := capture.New()
+	require.NoError(t, err)
+
+	c.Record(capture.Entry{Route: "/api/v0/health"})
+
+	h := newCaptureHandler(t, c)
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, h.ClearCapture(echo.New().NewContext(httptest.NewRequest(http.MethodDelete, "/", nil), rec)))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, c.Entries())
+}
+
+func TestCapture_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	h := newCaptureHandler(t, nil)
+
+	for _, fn := range []echo.HandlerFunc{h.GetCapture, h.UpdateCapture, h.ClearCapture} {
+		rec := httptest.NewRecorder()
+
+		require.NoError(t, fn(echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
@@ -0,0 +1,117 @@
+package v0
+
+import (
+	"auth-service/internal/service/breach"
+	"auth-service/internal/service/credpolicy"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Коды нарушений требований к учетным данным.
+const (
+	// violationBreached - пароль встречается в известных утечках.
+	violationBreached = "breached"
+)
+
+// credentialsCheckRequest - учетные данные, которые пользователь задает при регистрации или смене пароля.
+// При смене пароля логин передается, чтобы проверить, что пароль его не содержит.
+type credentialsCheckRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// credentialViolation - нарушение требований к учетным данным.
+type credentialViolation struct {
+	// Field - поле запроса с нарушением.
+	Field string `json:"field"`
+	// Code - машиночитаемый код нарушения.
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Params - параметры нарушения, например сколько раз пароль встречается в утечках.
+	Params map[string]any `json:"params,omitempty"`
+}
+
+// credentialsCheckResponse - результат проверки учетных данных.
+type credentialsCheckResponse struct {
+	Acceptable bool                  `json:"acceptable"`
+	Violations []credentialViolation `json:"violations"`
+	// BreachChecked - пароль проверен по базе утечек. false - проверка отключена или пропущена
+	// из-за недоступности сервиса утечек.
+	BreachChecked bool `json:"breach_checked"`
+}
+
+// CheckCredentials проверяет учетные данные, которые пользователь задает при регистрации или смене пароля:
+// на соответствие политике логинов и паролей и по базе утечек. Сервисы, которые ведут регистрацию,
+// вызывают проверку до сохранения учетных данных и отклоняют их при 422.
+//
+// CheckCredentials godoc
+//
+//	@Summary		Проверить учетные данные
+//	@Description	Проверяет логин и пароль на соответствие политике (credentials_policy): длина, классы символов, запрещенные пароли, формат и зарезервированные логины. Возвращает все нарушения с машиночитаемыми кодами (field, code, params). Пароль проверяется по базе утекших паролей HaveIBeenPwned (k-анонимность: наружу уходят только первые 5 символов SHA-1). Вызывается при регистрации и смене пароля. Если сервис утечек недоступен, пароль принимается без проверки (breach_checked: false) или возвращается 503 - в зависимости от password_breach.fail_open
+//	@Tags			credentials
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		credentialsCheckRequest	true	"Учетные данные"
+//	@Success		200		{object}	credentialsCheckResponse
+//	@Failure		400		{object}	errorResponse
+//	@Failure		404		{object}	errorResponse
+//	@Failure		422		{object}	credentialsCheckResponse
+//	@Failure		503		{object}	errorResponse
+//	@Router			/credentials/check [post]
+func (s *Handler) CheckCredentials(c echo.Context) error {
+	if s.breaches == nil && s.credentialsPolicy == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "credentials check is not configured"})
+	}
+
+	var req credentialsCheckRequest
+
+	if err := c.Bind(&req); err != nil || (req.Username == "" && req.Password == "") {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "username or password is required"})
+	}
+
+	resp := credentialsCheckResponse{Violations: []credentialViolation{}}
+
+	if s.credentialsPolicy != nil {
+		for _, v := range s.credentialsPolicy.Check(req.Username, req.Password) {
+			resp.Violations = append(resp.Violations, credentialViolation(v))
+		}
+	}
+
+	if s.breaches != nil && req.Password != "" {
+		if err := s.checkBreach(c, req.Password, &resp); err != nil {
+			logrus.WithError(err).Error("error check password breach")
+
+			return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: breach.ErrUnavailable.Error()})
+		}
+	}
+
+	resp.Acceptable = len(resp.Violations) == 0
+	if !resp.Acceptable {
+		return c.JSON(http.StatusUnprocessableEntity, resp)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// checkBreach проверяет пароль по базе утечек и добавляет нарушение, если пароль в ней встречается.
+func (s *Handler) checkBreach(c echo.Context, password string, resp *credentialsCheckResponse) error {
+	result, err := s.breaches.Check(c.Request().Context(), password)
+	if err != nil {
+		return err
+	}
+
+	resp.BreachChecked = result.Checked
+
+	if result.Breached {
+		resp.Violations = append(resp.Violations, credentialViolation{
+			Field:   credpolicy.FieldPassword,
+			Code:    violationBreached,
+			Message: "password has appeared in a data breach",
+			Params:  map[string]any{"count": result.Count},
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package v0
+
+import (
+	"auth-service/internal/service/breach"
+	"auth-service/internal/service/credpolicy"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCredentials_Policy(t *testing.T) {
+	t.Parallel()
+
+	policy, err := credpolicy.New(
+		credpolicy.WithPasswordRules(credpolicy.PasswordRules{MinLength: 10, RequireDigit: true}),
+		credpolicy.WithUsernameRules(credpolicy.UsernameRules{Reserved: []string{"admin"}}),
+	)
+	require.NoError(t, err)
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"), WithCredentialsPolicy(policy))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		want       credentialsCheckResponse
+	}{
+		{
+			name:       "positive case: acceptable username only",
+			body:       `{"username":"jdoe"}`,
+			wantStatus: http.StatusOK,
+			want:       credentialsCheckResponse{Acceptable: true, Violations: []credentialViolation{}},
+		},
+		{
+			name:       "negative case: all violations",
+			body:       `{"username":"admin","password":"secret"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			want: credentialsCheckResponse{
+				Violations: []credentialViolation{
+					{Field: "username", Code: credpolicy.CodeReserved, Message: "username is reserved"},
+					{
+						Field:   "password",
+						Code:    credpolicy.CodeTooShort,
+						Message: "password must be at least 10 characters long",
+						Params:  map[string]any{"min_length": float64(10)},
+					},
+					{Field: "password", Code: credpolicy.CodeMissingDigit, Message: "password must contain at least one digit"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := callGroups(t, h.CheckCredentials, http.MethodPost, "/", tt.body, nil)
+			require.Equal(t, tt.wantStatus, rec.Code)
+
+			var got credentialsCheckResponse
+
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestCheckCredentials(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		// SHA-1 пароля "password" - 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+		case "/range/5BAA6":
+			_, _ = fmt.Fprint(w, "1E4C9B93F3F0682250B6CF8331B7EE68FD8:42\r\n")
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	newHandler := func(t *testing.T, failOpen bool) *Handler {
+		t.Helper()
+
+		checker, err := breach.New(breach.WithURL(ts.URL), breach.WithFailOpen(failOpen))
+		require.NoError(t, err)
+
+		h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"), WithBreaches(checker))
+		require.NoError(t, err)
+
+		return h
+	}
+
+	notConfigured, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		handler    *Handler
+		body       string
+		wantStatus int
+		want       *credentialsCheckResponse
+	}{
+		{
+			name:       "error case: not configured",
+			handler:    notConfigured,
+			body:       `{"password":"password"}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "error case: no password",
+			handler:    newHandler(t, false),
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "negative case: breached password",
+			handler:    newHandler(t, false),
+			body:       `{"password":"password"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			want: &credentialsCheckResponse{
+				Violations: []credentialViolation{{
+					Field:   "password",
+					Code:    violationBreached,
+					Message: "password has appeared in a data breach",
+					Params:  map[string]any{"count": float64(42)},
+				}},
+				BreachChecked: true,
+			},
+		},
+		{
+			name:       "error case: breach service is unavailable",
+			handler:    newHandler(t, false),
+			body:       `{"password":"correct horse battery staple"}`,
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "positive case: fail open",
+			handler:    newHandler(t, true),
+			body:       `{"password":"correct horse battery staple"}`,
+			wantStatus: http.StatusOK,
+			want:       &credentialsCheckResponse{Acceptable: true, Violations: []credentialViolation{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := callGroups(t, tt.handler.CheckCredentials, http.MethodPost, "/", tt.body, nil)
+			require.Equal(t, tt.wantStatus, rec.Code)
+
+			if tt.want == nil {
+				return
+			}
+
+			var got credentialsCheckResponse
+
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+			assert.Equal(t, *tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package v0
+
+import (
+	"auth-service/internal/service/canary"
+	"auth-service/internal/service/dependency"
+	"auth-service/internal/service/drain"
+	"auth-service/internal/service/readonly"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// drainRequest - запрос на вывод экземпляра из балансировки.
+type drainRequest struct {
+	Delay  string `json:"delay,omitempty"`  // время до завершения процесса, например 2m. По умолчанию server.drain.delay
+	Reason string `json:"reason,omitempty"` // причина, пишется в аудит
+}
+
+// readinessResponse - ответ проверки готовности.
+type readinessResponse struct {
+	// Status - ready, draining, canary_failed или dependency_unavailable (только /readyz).
+	Status string `json:"status"`
+	// ReadOnly - состояние режима только для чтения, если он включен. Экземпляр в этом режиме
+	// остается готовым: проверка токенов работает
+	ReadOnly *readonly.Status `json:"read_only,omitempty"`
+	// Canary - результат последней проверки пробным токеном, если она включена.
+	Canary *canary.Result `json:"canary,omitempty"`
+	// Dependencies - состояние внешних зависимостей (только /readyz).
+	Dependencies map[dependency.Name]dependency.Status `json:"dependencies,omitempty"`
+}
+
+// Drain выводит экземпляр из балансировки и завершает процесс через заданное время.
+//
+// Drain godoc
+//
+//	@Summary		Вывести экземпляр из балансировки
+//	@Description	Проверка готовности (GET /ready) сразу начинает отвечать 503, чтобы балансировщик перестал отправлять новые запросы. Процесс продолжает обслуживать запросы до истечения delay (по умолчанию server.drain.delay, не больше server.drain.max_delay) и затем завершается так же, как по SIGTERM. Повторный вызов возвращает состояние уже начатого вывода
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			request	body		drainRequest	false	"Параметры вывода"
+//	@Success		202		{object}	drain.State
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Router			/admin/drain [post]
+func (s *Handler) Drain(c echo.Context) error {
+	if s.drainer == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "drain is not configured"})
+	}
+
+	var req drainRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+	}
+
+	var delay time.Duration
+
+	if req.Delay != "" {
+		d, err := time.ParseDuration(req.Delay)
+		if err != nil || d < 0 {
+			return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid delay"})
+		}
+
+		delay = d
+	}
+
+	state, err := s.drainer.Drain(delay)
+	if errors.Is(err, drain.ErrDelayTooLong) {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+	}
+
+	if err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"audit":   "drain",
+		"reason":  req.Reason,
+		"exit_at": state.ExitAt,
+		"ip":      c.RealIP(),
+	}).Warn("instance is draining")
+
+	return c.JSON(http.StatusAccepted, state)
+}
+
+// Ready - проверка готовности для балансировщика. В отличие от /health отвечает 503,
+// когда экземпляр выводится из балансировки.
+//
+// Ready godoc
+//
+//	@Summary		Проверить готовность принимать запросы
+//	@Description	200 {"status": "ready"}, пока экземпляр принимает запросы, и 503 {"status": "draining"} после POST /admin/drain до завершения процесса. Если включен режим только для чтения, в ответе есть поле read_only с его состоянием. Если включена проверка пробным токеном (token.canary), в ответе есть поле canary с результатом последней проверки; при token.canary.required ответ 503 {"status": "canary_failed"}, пока проверка не проходит
+//	@Produce		json
+//	@Success		200	{object}	readinessResponse
+//	@Failure		503	{object}	readinessResponse
+//	@Router			/ready [get]
+func (s *Handler) Ready(c echo.Context) error {
+	code, resp := s.readiness()
+
+	return c.JSON(code, resp)
+}
+
+// readiness собирает ответ проверки готовности: вывод из балансировки, режим только для чтения
+// и проверку пробным токеном.
+func (s *Handler) readiness() (int, readinessResponse) {
+	if s.drainer != nil && !s.drainer.Ready() {
+		return http.StatusServiceUnavailable, readinessResponse{Status: "draining"}
+	}
+
+	resp := readinessResponse{Status: "ready"}
+
+	if s.readOnly != nil {
+		if status := s.readOnly.Status(); status.Enabled {
+			resp.ReadOnly = &status
+		}
+	}
+
+	if s.canary != nil {
+		if last, ok := s.canary.Last(); ok {
+			resp.Canary = &last
+		}
+
+		if !s.canary.Ready() {
+			resp.Status = "canary_failed"
+
+			return http.StatusServiceUnavailable, resp
+		}
+	}
+
+	return http.StatusOK, resp
+}
@@ -0,0 +1,74 @@
+package v0
+
+import (
+	"auth-service/internal/service/drain"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrain(t *testing.T) {
+	t.Parallel()
+
+	drainer, err := drain.New(
+		drain.WithExit(func() {}),
+		drain.WithMaxDelay(time.Hour),
+		drain.WithRegisterer(prometheus.NewRegistry()),
+	)
+	require.NoError(t, err)
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"), WithDrainer(drainer))
+	require.NoError(t, err)
+
+	request := func(handler echo.HandlerFunc, method, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		rec := httptest.NewRecorder()
+		require.NoError(t, handler(echo.New().NewContext(req, rec)))
+
+		return rec
+	}
+
+	rec := request(h.Ready, http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ready"}`, rec.Body.String())
+
+	rec = request(h.Drain, http.MethodPost, `{"delay":"soon"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = request(h.Drain, http.MethodPost, `{"delay":"2h"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = request(h.Ready, http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, rec.Code, "rejected drain must not change readiness")
+
+	rec = request(h.Drain, http.MethodPost, `{"delay":"30m","reason":"node replacement"}`)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	var state drain.State
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&state))
+	assert.True(t, state.Draining)
+	assert.Equal(t, 30*time.Minute, state.ExitAt.Sub(*state.Since))
+
+	rec = request(h.Ready, http.MethodGet, "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"status":"draining"}`, rec.Body.String())
+
+	h.drainer = nil
+
+	rec = request(h.Drain, http.MethodPost, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = request(h.Ready, http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,191 @@
+package v0
+
+import (
+	"auth-service/internal/service/oauth"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+type emailChangeRequest struct {
+	// CurrentEmail - текущая почта. Нужна только аккаунтам, почта которых привязана до появления смены почты.
+	CurrentEmail string `json:"current_email"`
+	NewEmail     string `json:"new_email"`
+}
+
+type emailChangeConfirmRequest struct {
+	Code string `json:"code"`
+}
+
+// GetEmailChange возвращает смену почты пользователя, ожидающую подтверждения.
+//
+// GetEmailChange godoc
+//
+//	@Summary		Получить смену почты
+//	@Description	Возвращает смену почты, ожидающую подтверждения, и какие адреса ее уже подтвердили
+//	@Tags			account
+//	@Produce		json
+//	@Security		BearerToken
+//	@Success		200	{object}	oauth.EmailChange
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/account/email [get]
+func (s *Handler) GetEmailChange(c echo.Context) error {
+	if s.oauth == nil || s.validator == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: oauth.ErrEmailChangeDisabled.Error()})
+	}
+
+	claims, err := s.authenticateUser(c)
+	if claims == nil {
+		return err
+	}
+
+	change, err := s.oauth.EmailChange(c.Request().Context(), claims.Subject)
+	if err != nil {
+		logrus.WithError(err).Error("error get email change")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to get email change"})
+	}
+
+	if change == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: oauth.ErrEmailChangeNotFound.Error()})
+	}
+
+	return c.JSON(http.StatusOK, change)
+}
+
+// StartEmailChange начинает смену почты пользователя.
+//
+// StartEmailChange godoc
+//
+//	@Summary		Начать смену почты
+//	@Description	Отправляет коды подтверждения на текущий и новый адреса. Почта меняется только после подтверждения с обоих адресов, до истечения срока. Новая смена отменяет начатую. Токены имперсонации не принимаются
+//	@Tags			account
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerToken
+//	@Param			request	body		emailChangeRequest	true	"Новая почта"
+//	@Success		202		{object}	oauth.EmailChange
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401		{object}	errorResponse
+//	@Failure		403		{object}	errorResponse
+//	@Failure		404		{object}	errorResponse
+//	@Failure		409		{object}	errorResponse
+//	@Failure		422		{object}	errorResponse
+//	@Failure		503		{object}	errorResponse
+//	@Router			/account/email [post]
+func (s *Handler) StartEmailChange(c echo.Context) error {
+	if s.oauth == nil || s.validator == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: oauth.ErrEmailChangeDisabled.Error()})
+	}
+
+	claims, err := s.authenticateUser(c)
+	if claims == nil {
+		return err
+	}
+
+	var req emailChangeRequest
+
+	if err := c.Bind(&req); err != nil || req.NewEmail == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "new_email is required"})
+	}
+
+	change, err := s.oauth.StartEmailChange(c.Request().Context(), claims.Subject, req.CurrentEmail, req.NewEmail)
+
+	switch {
+	case errors.Is(err, oauth.ErrEmailChangeDisabled):
+		return c.JSON(http.StatusNotFound, errorResponse{Error: err.Error()})
+	case errors.Is(err, oauth.ErrInvalidArgument):
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+	case errors.Is(err, oauth.ErrEmailTaken):
+		return c.JSON(http.StatusConflict, errorResponse{Error: err.Error()})
+	case errors.Is(err, oauth.ErrNoEmail):
+		return c.JSON(http.StatusUnprocessableEntity, errorResponse{Error: err.Error()})
+	case err != nil:
+		logrus.WithError(err).WithField("subject", claims.Subject).Error("error start email change")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to start email change"})
+	}
+
+	return c.JSON(http.StatusAccepted, change)
+}
+
+// CancelEmailChange отменяет смену почты пользователя.
+//
+// CancelEmailChange godoc
+//
+//	@Summary		Отменить смену почты
+//	@Description	Коды из отправленных писем перестают действовать
+//	@Tags			account
+//	@Security		BearerToken
+//	@Success		204
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/account/email [delete]
+func (s *Handler) CancelEmailChange(c echo.Context) error {
+	if s.oauth == nil || s.validator == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: oauth.ErrEmailChangeDisabled.Error()})
+	}
+
+	claims, err := s.authenticateUser(c)
+	if claims == nil {
+		return err
+	}
+
+	if err := s.oauth.CancelEmailChange(c.Request().Context(), claims.Subject); err != nil {
+		logrus.WithError(err).Error("error cancel email change")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to cancel email change"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ConfirmEmailChange подтверждает смену почты кодом из письма.
+//
+// ConfirmEmailChange godoc
+//
+//	@Summary		Подтвердить смену почты
+//	@Description	Код из письма подтверждает владение адресом, поэтому токен не нужен. Почта меняется после подтверждения с обоих адресов, после этого на старый адрес отправляется уведомление, а если настроено, токены пользователя отзываются
+//	@Tags			account
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		emailChangeConfirmRequest	true	"Код из письма"
+//	@Success		200		{object}	oauth.EmailChange
+//	@Failure		400		{object}	errorResponse
+//	@Failure		404		{object}	errorResponse
+//	@Failure		409		{object}	errorResponse
+//	@Failure		503		{object}	errorResponse
+//	@Router			/account/email/confirm [post]
+func (s *Handler) ConfirmEmailChange(c echo.Context) error {
+	if s.oauth == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: oauth.ErrEmailChangeDisabled.Error()})
+	}
+
+	var req emailChangeConfirmRequest
+
+	if err := c.Bind(&req); err != nil || req.Code == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "code is required"})
+	}
+
+	change, err := s.oauth.ConfirmEmailChange(c.Request().Context(), req.Code)
+
+	switch {
+	case errors.Is(err, oauth.ErrEmailChangeNotFound):
+		return c.JSON(http.StatusNotFound, errorResponse{Error: err.Error()})
+	case errors.Is(err, oauth.ErrEmailTaken):
+		return c.JSON(http.StatusConflict, errorResponse{Error: err.Error()})
+	case err != nil:
+		logrus.WithError(err).Error("error confirm email change")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to confirm email change"})
+	}
+
+	return c.JSON(http.StatusOK, change)
+}
@@ -0,0 +1,142 @@
+package v0
+
+import (
+	"auth-service/internal/service/mail"
+	"auth-service/internal/service/oauth"
+	"auth-service/internal/service/token"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMail - отправка писем, запоминающая коды подтверждения по адресам.
+type fakeMail struct {
+	mu    sync.Mutex
+	codes map[string]string
+}
+
+var emailCodePattern = regexp.MustCompile(`Код подтверждения: (\w+)`)
+
+func (f *fakeMail) Send(_ context.Context, msg mail.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if match := emailCodePattern.FindStringSubmatch(msg.Body); match != nil {
+		f.codes[msg.To] = match[1]
+	}
+
+	return nil
+}
+
+func (f *fakeMail) code(to string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.codes[to]
+}
+
+// loginOAuth входит через провайдера и возвращает заголовок авторизации с токеном.
+func loginOAuth(t *testing.T, h *Handler) string {
+	t.Helper()
+
+	state := startOAuth(t, h)
+
+	rec := callOAuth(t, h.OAuthCallback, "corp", url.Values{"code": {"good-code"}, "state": {state}}.Encode())
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp tokenResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+
+	return "Bearer " + resp.AccessToken
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestEmailChange(t *testing.T) {
+	t.Parallel()
+
+	sender := &fakeMail{codes: map[string]string{}}
+
+	h := newOAuthHandler(t, oauth.WithMail(sender))
+
+	validator, err := token.NewValidator(token.WithKeys(testKeys{key: []byte("secret")}))
+	require.NoError(t, err)
+
+	h.validator = validator
+
+	auth := loginOAuth(t, h)
+
+	rec := callAuthorized(t, h.GetEmailChange, "", auth, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = callAuthorized(t, h.StartEmailChange, "", "", `{"new_email":"new@example.com"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = callAuthorized(t, h.StartEmailChange, "", auth, `{}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callAuthorized(t, h.StartEmailChange, "", auth, `{"new_email":"user@example.com"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callAuthorized(t, h.StartEmailChange, "", auth, `{"new_email":"new@example.com"}`)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	var change oauth.EmailChange
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &change))
+	assert.Equal(t, "user@example.com", change.OldEmail)
+	assert.Equal(t, "new@example.com", change.NewEmail)
+
+	rec = callAuthorized(t, h.GetEmailChange, "", auth, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = callAuthorized(t, h.ConfirmEmailChange, "", "", `{}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callAuthorized(t, h.ConfirmEmailChange, "", "", `{"code":"unknown"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = callAuthorized(t, h.ConfirmEmailChange, "", "", `{"code":"`+sender.code("new@example.com")+`"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"completed":false`)
+
+	rec = callAuthorized(t, h.ConfirmEmailChange, "", "", `{"code":"`+sender.code("user@example.com")+`"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"completed":true`)
+
+	// после смены почты отменять нечего
+	rec = callAuthorized(t, h.CancelEmailChange, "", auth, "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = callAuthorized(t, h.GetEmailChange, "", auth, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestEmailChange_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	// без отправки писем смена почты недоступна
+	h := newOAuthHandler(t)
+
+	validator, err := token.NewValidator(token.WithKeys(testKeys{key: []byte("secret")}))
+	require.NoError(t, err)
+
+	h.validator = validator
+
+	rec := callAuthorized(t, h.StartEmailChange, "", loginOAuth(t, h), `{"new_email":"new@example.com"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	h, err = New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	for _, fn := range []echo.HandlerFunc{h.GetEmailChange, h.StartEmailChange, h.CancelEmailChange, h.ConfirmEmailChange} {
+		rec := callAuthorized(t, fn, "", "", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
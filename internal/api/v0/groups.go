@@ -0,0 +1,256 @@
+package v0
+
+import (
+	"auth-service/internal/service/group"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// createGroupRequest - запрос на создание группы.
+type createGroupRequest struct {
+	Name  string `json:"name"`
+	Owner string `json:"owner"` // пользователь, который станет владельцем группы
+}
+
+// setMemberRequest - роль участника группы.
+type setMemberRequest struct {
+	Role group.Role `json:"role" enums:"viewer,editor,owner"`
+}
+
+// membershipsResponse - группы пользователя и его роли в них.
+type membershipsResponse struct {
+	Groups map[string]string `json:"groups"`
+}
+
+// groupCheckResponse - результат проверки доступа к группе.
+type groupCheckResponse struct {
+	Allowed bool `json:"allowed"`
+}
+
+// CreateGroup создает группу.
+//
+// CreateGroup godoc
+//
+//	@Summary		Создать группу
+//	@Tags			groups
+//	@Accept			json
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			request	body		createGroupRequest	true	"Группа"
+//	@Success		201		{object}	group.Group
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/admin/groups [post]
+func (s *Handler) CreateGroup(c echo.Context) error {
+	if s.groups == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "groups are not configured"})
+	}
+
+	var req createGroupRequest
+
+	if err := c.Bind(&req); err != nil || req.Name == "" || req.Owner == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "name and owner are required"})
+	}
+
+	g, err := s.groups.Create(c.Request().Context(), req.Name, req.Owner)
+	if err != nil {
+		return s.groupError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, g)
+}
+
+// GetGroup возвращает группу с участниками.
+//
+// GetGroup godoc
+//
+//	@Summary		Получить группу
+//	@Tags			groups
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			id	path		string	true	"ID группы"
+//	@Success		200	{object}	group.Group
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/admin/groups/{id} [get]
+func (s *Handler) GetGroup(c echo.Context) error {
+	if s.groups == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "groups are not configured"})
+	}
+
+	g, err := s.groups.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return s.groupError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, g)
+}
+
+// DeleteGroup удаляет группу и членство всех ее участников.
+//
+// DeleteGroup godoc
+//
+//	@Summary		Удалить группу
+//	@Tags			groups
+//	@Security		AdminToken
+//	@Param			id	path	string	true	"ID группы"
+//	@Success		204
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/admin/groups/{id} [delete]
+func (s *Handler) DeleteGroup(c echo.Context) error {
+	if s.groups == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "groups are not configured"})
+	}
+
+	if err := s.groups.Delete(c.Request().Context(), c.Param("id")); err != nil {
+		return s.groupError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SetGroupMember добавляет пользователя в группу или меняет его роль.
+//
+// SetGroupMember godoc
+//
+//	@Summary		Добавить участника или изменить роль
+//	@Tags			groups
+//	@Accept			json
+//	@Security		AdminToken
+//	@Param			id		path	string				true	"ID группы"
+//	@Param			user	path	string				true	"ID пользователя"
+//	@Param			request	body	setMemberRequest	true	"Роль"
+//	@Success		204
+//	@Failure		400	{object}	errorResponse
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/admin/groups/{id}/members/{user} [put]
+func (s *Handler) SetGroupMember(c echo.Context) error {
+	if s.groups == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "groups are not configured"})
+	}
+
+	var req setMemberRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+	}
+
+	if err := s.groups.SetMember(c.Request().Context(), c.Param("id"), c.Param("user"), req.Role); err != nil {
+		return s.groupError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RemoveGroupMember исключает пользователя из группы.
+//
+// RemoveGroupMember godoc
+//
+//	@Summary		Исключить участника
+//	@Tags			groups
+//	@Security		AdminToken
+//	@Param			id		path	string	true	"ID группы"
+//	@Param			user	path	string	true	"ID пользователя"
+//	@Success		204
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/admin/groups/{id}/members/{user} [delete]
+func (s *Handler) RemoveGroupMember(c echo.Context) error {
+	if s.groups == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "groups are not configured"})
+	}
+
+	if err := s.groups.RemoveMember(c.Request().Context(), c.Param("id"), c.Param("user")); err != nil {
+		return s.groupError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// UserGroups возвращает группы пользователя и его роли в них.
+//
+// UserGroups godoc
+//
+//	@Summary		Группы пользователя
+//	@Tags			groups
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			user	path		string	true	"ID пользователя"
+//	@Success		200		{object}	membershipsResponse
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/admin/users/{user}/groups [get]
+func (s *Handler) UserGroups(c echo.Context) error {
+	if s.groups == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "groups are not configured"})
+	}
+
+	groups, err := s.groups.Memberships(c.Request().Context(), c.Param("user"))
+	if err != nil {
+		return s.groupError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, membershipsResponse{Groups: groups})
+}
+
+// CheckGroupAccess проверяет, разрешено ли пользователю действие над данными группы.
+//
+// CheckGroupAccess godoc
+//
+//	@Summary		Проверить доступ к группе
+//	@Description	Проверяет по роли пользователя в группе: viewer - read, editor - read и write, owner - read, write и manage
+//	@Tags			groups
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			id		path		string	true	"ID группы"
+//	@Param			user	query		string	true	"ID пользователя"
+//	@Param			action	query		string	true	"Действие"	Enums(read, write, manage)
+//	@Success		200		{object}	groupCheckResponse
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/admin/groups/{id}/check [get]
+func (s *Handler) CheckGroupAccess(c echo.Context) error {
+	if s.groups == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "groups are not configured"})
+	}
+
+	user, action := c.QueryParam("user"), group.Action(c.QueryParam("action"))
+	if user == "" || action == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "user and action are required"})
+	}
+
+	allowed, err := s.groups.Check(c.Request().Context(), c.Param("id"), user, action)
+	if err != nil {
+		return s.groupError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, groupCheckResponse{Allowed: allowed})
+}
+
+// groupError отвечает ошибкой хранилища групп с подходящим статусом.
+func (s *Handler) groupError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, group.ErrNotFound), errors.Is(err, group.ErrNotMember):
+		return c.JSON(http.StatusNotFound, errorResponse{Error: err.Error()})
+	case errors.Is(err, group.ErrInvalidRole), errors.Is(err, group.ErrInvalidArgument):
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+	}
+
+	logrus.WithError(err).Error("error process group request")
+
+	return c.JSON(http.StatusInternalServerError, errorResponse{Error: "internal error"})
+}
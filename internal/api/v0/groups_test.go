@@ -0,0 +1,141 @@
+package v0
+
+import (
+	"auth-service/internal/service/group"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGroupsHandler(t *testing.T) *Handler {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	groups, err := group.New(group.WithClient(client))
+	require.NoError(t, err)
+
+	h, err := New(
+		WithVersion("1.0.0"),
+		WithBuildDate("2021-01-01"),
+		WithGitCommit("1234567890"),
+		WithGroups(groups),
+	)
+	require.NoError(t, err)
+
+	return h
+}
+
+// callGroups вызывает хендлер с параметрами пути и возвращает ответ.
+func callGroups(t *testing.T, fn echo.HandlerFunc, method, target, body string, params map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+
+	names := make([]string, 0, len(params))
+	values := make([]string, 0, len(params))
+
+	for name, value := range params {
+		names = append(names, name)
+		values = append(values, value)
+	}
+
+	c.SetParamNames(names...)
+	c.SetParamValues(values...)
+
+	require.NoError(t, fn(c))
+
+	return rec
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestGroups(t *testing.T) {
+	t.Parallel()
+
+	h := newGroupsHandler(t)
+
+	rec := callGroups(t, h.CreateGroup, http.MethodPost, "/", `{"name":"notes","owner":"user-1"}`, nil)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var g group.Group
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&g))
+	require.NotEmpty(t, g.ID)
+
+	rec = callGroups(t, h.CreateGroup, http.MethodPost, "/", `{"name":"notes"}`, nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callGroups(t, h.SetGroupMember, http.MethodPut, "/", `{"role":"editor"}`, map[string]string{"id": g.ID, "user": "user-2"})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = callGroups(t, h.SetGroupMember, http.MethodPut, "/", `{"role":"admin"}`, map[string]string{"id": g.ID, "user": "user-2"})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callGroups(t, h.SetGroupMember, http.MethodPut, "/", `{"role":"editor"}`, map[string]string{"id": "unknown", "user": "user-2"})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = callGroups(t, h.GetGroup, http.MethodGet, "/", "", map[string]string{"id": g.ID})
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var got group.Group
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	assert.Equal(t, map[string]group.Role{"user-1": group.RoleOwner, "user-2": group.RoleEditor}, got.Members)
+
+	rec = callGroups(t, h.UserGroups, http.MethodGet, "/", "", map[string]string{"user": "user-2"})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"groups":{"`+g.ID+`":"editor"}}`, rec.Body.String())
+
+	rec = callGroups(t, h.CheckGroupAccess, http.MethodGet, "/?user=user-2&action=write", "", map[string]string{"id": g.ID})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"allowed":true}`, rec.Body.String())
+
+	rec = callGroups(t, h.CheckGroupAccess, http.MethodGet, "/?user=user-2&action=manage", "", map[string]string{"id": g.ID})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"allowed":false}`, rec.Body.String())
+
+	rec = callGroups(t, h.CheckGroupAccess, http.MethodGet, "/?user=user-2", "", map[string]string{"id": g.ID})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callGroups(t, h.RemoveGroupMember, http.MethodDelete, "/", "", map[string]string{"id": g.ID, "user": "user-2"})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = callGroups(t, h.RemoveGroupMember, http.MethodDelete, "/", "", map[string]string{"id": g.ID, "user": "user-2"})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = callGroups(t, h.DeleteGroup, http.MethodDelete, "/", "", map[string]string{"id": g.ID})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = callGroups(t, h.GetGroup, http.MethodGet, "/", "", map[string]string{"id": g.ID})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestGroups_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	for _, fn := range []echo.HandlerFunc{
+		h.CreateGroup, h.GetGroup, h.DeleteGroup, h.SetGroupMember,
+		h.RemoveGroupMember, h.UserGroups, h.CheckGroupAccess,
+	} {
+		rec := callGroups(t, fn, http.MethodGet, "/", "", nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
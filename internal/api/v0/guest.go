@@ -0,0 +1,145 @@
+package v0
+
+import (
+	"auth-service/internal/service/token"
+	"auth-service/internal/service/webauthn"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// guestUpgradeRequest - учетные данные, на которые обменивается гостевой токен: ответ navigator.credentials.get().
+type guestUpgradeRequest struct {
+	Passkey *webauthn.AssertionResponse `json:"passkey"`
+}
+
+// guestUpgradeResponse - полный токен пользователя, выпущенный в обмен на гостевой.
+type guestUpgradeResponse struct {
+	tokenResponse
+
+	Subject string `json:"sub"`
+	// GuestSubject - субъект гостевого токена, чтобы связать данные, собранные до регистрации, с пользователем.
+	GuestSubject string `json:"guest_sub"`
+}
+
+// IssueGuestToken выпускает гостевой токен для еще не зарегистрированного пользователя.
+//
+// IssueGuestToken godoc
+//
+//	@Summary		Выпустить гостевой токен
+//	@Description	Выпускает короткоживущий токен без пользователя: субъект guest:<id>, scope guest, аудитории из token.guest. Бот вызывает публичные API с ограничением частоты от имени еще не зарегистрированного пользователя. Гостевые токены не принимаются для действий пользователя. После входа гостевой токен обменивается на полный через POST /token/guest/upgrade
+//	@Tags			token
+//	@Produce		json
+//	@Success		200	{object}	tokenResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		429	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/token/guest [post]
+func (s *Handler) IssueGuestToken(c echo.Context) error {
+	if s.issuer == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "token issuance is not configured"})
+	}
+
+	raw, claims, err := s.issuer.IssueGuest(c.Request().Context())
+	if errors.Is(err, token.ErrGuestDisabled) {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: err.Error()})
+	}
+
+	if err != nil {
+		logrus.WithError(err).Error("error issue guest token")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "token issuance is unavailable"})
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"subject": claims.Subject,
+		"jti":     claims.ID,
+		"ip":      c.RealIP(),
+	}).Debug("guest token issued")
+
+	return c.JSON(http.StatusOK, tokenResponse{
+		AccessToken: raw,
+		TokenType:   "Bearer",
+		ExpiresAt:   claims.ExpiresAt.Unix(),
+		Scope:       strings.Join(claims.Scopes, " "),
+		JTI:         claims.ID,
+	})
+}
+
+// UpgradeGuestToken обменивает гостевой токен и учетные данные пользователя на полный токен.
+//
+// UpgradeGuestToken godoc
+//
+//	@Summary		Обменять гостевой токен на полный
+//	@Description	Принимает гостевой токен в заголовке Authorization и учетные данные пользователя (ответ navigator.credentials.get() для passkey). Выпускает токен владельцу учетных данных так же, как вход по ним, и возвращает субъект гостевого токена, чтобы связать с пользователем данные, собранные до регистрации. Гостевой токен после обмена продолжает действовать до истечения, но дает только scope guest
+//	@Tags			token
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerToken
+//	@Param			request	body		guestUpgradeRequest	true	"Учетные данные"
+//	@Success		200		{object}	guestUpgradeResponse
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401		{object}	errorResponse
+//	@Failure		403		{object}	errorResponse
+//	@Failure		404		{object}	errorResponse
+//	@Failure		503		{object}	errorResponse
+//	@Router			/token/guest/upgrade [post]
+func (s *Handler) UpgradeGuestToken(c echo.Context) error {
+	if s.validator == nil || s.passkeys == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "guest token upgrade is not configured"})
+	}
+
+	guest, ok := token.FromContext(c.Request().Context())
+	if !ok {
+		var err error
+
+		guest, err = s.validateBearer(c)
+		if guest == nil {
+			return err
+		}
+	}
+
+	if !guest.IsGuest() {
+		return c.JSON(http.StatusForbidden, errorResponse{Error: "guest token is required"})
+	}
+
+	var req guestUpgradeRequest
+
+	if err := c.Bind(&req); err != nil || req.Passkey == nil {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "credentials are required"})
+	}
+
+	login, err := s.passkeys.Login(c.Request().Context(), *req.Passkey)
+	if err != nil {
+		return passkeyError(c, err, "error upgrade guest token")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"subject":       login.Claims.Subject,
+		"guest_subject": guest.Subject,
+		"jti":           login.Claims.ID,
+		"ip":            c.RealIP(),
+	}).Info("guest token upgraded")
+
+	resp, err := s.withRefresh(c, tokenResponse{
+		AccessToken: login.Token,
+		TokenType:   "Bearer",
+		ExpiresAt:   login.Claims.ExpiresAt.Unix(),
+		Scope:       strings.Join(login.Claims.Scopes, " "),
+		JTI:         login.Claims.ID,
+	}, login.Claims)
+	if err != nil {
+		return refreshUnavailable(c)
+	}
+
+	s.notifyLogin(c, login.Claims.Subject, "passkey")
+
+	return c.JSON(http.StatusOK, guestUpgradeResponse{
+		tokenResponse: resp,
+		Subject:       login.Claims.Subject,
+		GuestSubject:  guest.Subject,
+	})
+}
@@ -0,0 +1,128 @@
+package v0
+
+import (
+	"auth-service/internal/service/token"
+	"auth-service/internal/service/webauthn"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestGuestToken(t *testing.T) {
+	t.Parallel()
+
+	h, issuer, _ := newPasskeyHandler(t)
+
+	guests, err := token.NewIssuer(
+		token.WithSigningKeys(testSigningKeys{key: []byte("secret")}),
+		token.WithGuest(token.Guest{Audiences: []string{"telegram-bot"}, TTL: 10 * time.Minute}),
+	)
+	require.NoError(t, err)
+
+	h.issuer = guests
+
+	rec := callAuthorized(t, h.IssueGuestToken, "", "", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var guest tokenResponse
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&guest))
+	assert.Equal(t, token.ScopeGuest, guest.Scope)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), time.Unix(guest.ExpiresAt, 0), 2*time.Second)
+
+	guestClaims, err := h.validator.Validate(t.Context(), guest.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, guestClaims.IsGuest())
+
+	// гостевой токен не принимается для действий пользователя
+	rec = callAuthorized(t, h.BeginPasskeyRegistration, "", "Bearer "+guest.AccessToken, "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	// регистрация passkey пользователем
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	passkey := &testPasskey{id: []byte("credential-1"), key: key}
+
+	userToken, _, err := issuer.Issue(t.Context(), token.IssueRequest{Subject: "user-1", TTL: time.Hour})
+	require.NoError(t, err)
+
+	rec = callAuthorized(t, h.BeginPasskeyRegistration, "", "Bearer "+userToken, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var creation webauthn.CreationOptions
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&creation))
+
+	rec = callAuthorized(t, h.FinishPasskeyRegistration, "", "Bearer "+userToken, passkey.register(t, creation))
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	beginLogin := func() string {
+		t.Helper()
+
+		rec := callAuthorized(t, h.BeginPasskeyLogin, "", "", `{"subject":"user-1"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var request webauthn.RequestOptions
+
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&request))
+
+		return `{"passkey":` + passkey.assert(t, request) + `}`
+	}
+
+	// без гостевого токена
+	rec = callAuthorized(t, h.UpgradeGuestToken, "", "", beginLogin())
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// полный токен вместо гостевого
+	rec = callAuthorized(t, h.UpgradeGuestToken, "", "Bearer "+userToken, beginLogin())
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	// без учетных данных
+	rec = callAuthorized(t, h.UpgradeGuestToken, "", "Bearer "+guest.AccessToken, `{}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callAuthorized(t, h.UpgradeGuestToken, "", "Bearer "+guest.AccessToken, beginLogin())
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var upgraded guestUpgradeResponse
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&upgraded))
+	assert.Equal(t, "user-1", upgraded.Subject)
+	assert.Equal(t, guestClaims.Subject, upgraded.GuestSubject)
+
+	claims, err := h.validator.Validate(t.Context(), upgraded.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+	assert.False(t, claims.IsGuest())
+}
+
+func TestGuestToken_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	for _, fn := range []echo.HandlerFunc{h.IssueGuestToken, h.UpgradeGuestToken} {
+		rec := callAuthorized(t, fn, "", "Bearer token", `{}`)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+
+	// выпуск настроен, гостевые токены - нет
+	issuer, err := token.NewIssuer(token.WithSigningKeys(testSigningKeys{key: []byte("secret")}))
+	require.NoError(t, err)
+
+	h.issuer = issuer
+
+	rec := callAuthorized(t, h.IssueGuestToken, "", "", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -1,6 +1,36 @@
 package v0
 
 import (
+	"auth-service/internal/service/abuse"
+	"auth-service/internal/service/apikey"
+	"auth-service/internal/service/authz"
+	"auth-service/internal/service/breach"
+	"auth-service/internal/service/bundle"
+	"auth-service/internal/service/canary"
+	"auth-service/internal/service/capture"
+	"auth-service/internal/service/credpolicy"
+	"auth-service/internal/service/dependency"
+	"auth-service/internal/service/drain"
+	"auth-service/internal/service/group"
+	"auth-service/internal/service/job"
+	"auth-service/internal/service/keystats"
+	"auth-service/internal/service/ldap"
+	"auth-service/internal/service/lifecycle"
+	"auth-service/internal/service/logsampling"
+	"auth-service/internal/service/notify"
+	"auth-service/internal/service/oauth"
+	"auth-service/internal/service/qrlogin"
+	"auth-service/internal/service/quota"
+	"auth-service/internal/service/readonly"
+	"auth-service/internal/service/redis"
+	"auth-service/internal/service/refresh"
+	"auth-service/internal/service/revocation"
+	"auth-service/internal/service/scim"
+	"auth-service/internal/service/spiffe"
+	"auth-service/internal/service/stats"
+	"auth-service/internal/service/telegram"
+	"auth-service/internal/service/token"
+	"auth-service/internal/service/webauthn"
 	"errors"
 
 	"github.com/sirupsen/logrus"
@@ -12,7 +42,59 @@ type Handler struct {
 	buildDate string
 	gitCommit string
 
+	// не раскрывать версию и состояние компонентов в публичном /health
+	hideVersion bool
+
 	apiVersion string
+
+	capture  *capture.Capture
+	keyStats *keystats.Tracker
+	stats    *stats.Service
+	bundles  *bundle.Exporter
+
+	validator *token.Validator
+	issuer    *token.Issuer
+
+	groups *group.Service
+	authz  *authz.Service
+
+	quota   *quota.Service
+	apiKeys *apikey.Service
+
+	logSampling *logsampling.Sampler
+
+	spiffe *spiffe.Service
+
+	lifecycle *lifecycle.Tracker
+	redis     *redis.Service
+	drainer   *drain.Drainer
+	readOnly  *readonly.Mode
+	canary    *canary.Checker
+	deps      *dependency.Registry
+
+	jobs        *job.Service
+	revocations *revocation.Service
+
+	qrLogin  *qrlogin.Service
+	passkeys *webauthn.Service
+	oauth    *oauth.Service
+	refresh  *refresh.Service
+	telegram *telegram.Authenticator
+
+	directory *ldap.Service
+	scim      *scim.Service
+
+	abuse *abuse.Service
+
+	breaches          *breach.Checker
+	credentialsPolicy *credpolicy.Policy
+
+	notifier *notify.Service
+}
+
+// errorResponse - тело ответа с ошибкой.
+type errorResponse struct {
+	Error string `json:"error"`
 }
 
 type handlerOption func(*Handler)
@@ -38,6 +120,231 @@ func WithGitCommit(gitCommit string) handlerOption {
 	}
 }
 
+// WithCapture устанавливает хранилище захваченных запросов для административного API.
+func WithCapture(c *capture.Capture) handlerOption {
+	return func(h *Handler) {
+		h.capture = c
+	}
+}
+
+// WithKeyStats устанавливает счетчик использования ключей подписи.
+func WithKeyStats(tracker *keystats.Tracker) handlerOption {
+	return func(h *Handler) {
+		h.keyStats = tracker
+	}
+}
+
+// WithStats устанавливает статистику входов, сессий и выпуска токенов для продуктовой аналитики.
+func WithStats(svc *stats.Service) handlerOption {
+	return func(h *Handler) {
+		h.stats = svc
+	}
+}
+
+// WithBundles устанавливает выгрузку пакетов для проверки токенов без обращения к сервису.
+func WithBundles(exporter *bundle.Exporter) handlerOption {
+	return func(h *Handler) {
+		h.bundles = exporter
+	}
+}
+
+// WithValidator устанавливает валидатор токенов.
+func WithValidator(v *token.Validator) handlerOption {
+	return func(h *Handler) {
+		h.validator = v
+	}
+}
+
+// WithIssuer устанавливает сервис выпуска токенов.
+func WithIssuer(i *token.Issuer) handlerOption {
+	return func(h *Handler) {
+		h.issuer = i
+	}
+}
+
+// WithGroups устанавливает хранилище групп.
+func WithGroups(g *group.Service) handlerOption {
+	return func(h *Handler) {
+		h.groups = g
+	}
+}
+
+// WithAuthz устанавливает сервис проверки доступа.
+func WithAuthz(a *authz.Service) handlerOption {
+	return func(h *Handler) {
+		h.authz = a
+	}
+}
+
+// WithQuota устанавливает сервис учета квот API ключей.
+func WithQuota(q *quota.Service) handlerOption {
+	return func(h *Handler) {
+		h.quota = q
+	}
+}
+
+// WithAPIKeys устанавливает сервис выпуска API ключей.
+func WithAPIKeys(a *apikey.Service) handlerOption {
+	return func(h *Handler) {
+		h.apiKeys = a
+	}
+}
+
+// WithSPIFFE устанавливает сервис выпуска SVID.
+func WithSPIFFE(svc *spiffe.Service) handlerOption {
+	return func(h *Handler) {
+		h.spiffe = svc
+	}
+}
+
+// WithJobs устанавливает очередь асинхронных заданий.
+func WithJobs(svc *job.Service) handlerOption {
+	return func(h *Handler) {
+		h.jobs = svc
+	}
+}
+
+// WithRevocations устанавливает сервис отзыва токенов пользователей.
+func WithRevocations(svc *revocation.Service) handlerOption {
+	return func(h *Handler) {
+		h.revocations = svc
+	}
+}
+
+// WithQRLogin устанавливает сервис входа по QR коду.
+func WithQRLogin(svc *qrlogin.Service) handlerOption {
+	return func(h *Handler) {
+		h.qrLogin = svc
+	}
+}
+
+// WithTelegram устанавливает вход пользователей Telegram.
+func WithTelegram(a *telegram.Authenticator) handlerOption {
+	return func(h *Handler) {
+		h.telegram = a
+	}
+}
+
+// WithPasskeys устанавливает сервис входа по passkey (WebAuthn).
+func WithPasskeys(svc *webauthn.Service) handlerOption {
+	return func(h *Handler) {
+		h.passkeys = svc
+	}
+}
+
+// WithOAuth устанавливает сервис входа через внешних провайдеров OAuth.
+func WithOAuth(svc *oauth.Service) handlerOption {
+	return func(h *Handler) {
+		h.oauth = svc
+	}
+}
+
+// WithRefresh устанавливает выпуск и ротацию refresh токенов.
+func WithRefresh(svc *refresh.Service) handlerOption {
+	return func(h *Handler) {
+		h.refresh = svc
+	}
+}
+
+// WithDirectory устанавливает вход администраторов через корпоративный каталог (LDAP).
+func WithDirectory(svc *ldap.Service) handlerOption {
+	return func(h *Handler) {
+		h.directory = svc
+	}
+}
+
+// WithSCIM устанавливает учетные записи администраторов, которые заводит корпоративный IdP по SCIM.
+func WithSCIM(svc *scim.Service) handlerOption {
+	return func(h *Handler) {
+		h.scim = svc
+	}
+}
+
+// WithAbuse устанавливает обнаружение перебора учетных данных и denylist.
+func WithAbuse(svc *abuse.Service) handlerOption {
+	return func(h *Handler) {
+		h.abuse = svc
+	}
+}
+
+// WithBreaches устанавливает проверку паролей по базе утечек.
+func WithBreaches(checker *breach.Checker) handlerOption {
+	return func(h *Handler) {
+		h.breaches = checker
+	}
+}
+
+// WithCredentialsPolicy устанавливает политику логинов и паролей.
+func WithCredentialsPolicy(policy *credpolicy.Policy) handlerOption {
+	return func(h *Handler) {
+		h.credentialsPolicy = policy
+	}
+}
+
+// WithNotifier устанавливает уведомления пользователей о событиях безопасности.
+func WithNotifier(svc *notify.Service) handlerOption {
+	return func(h *Handler) {
+		h.notifier = svc
+	}
+}
+
+// WithLifecycle устанавливает трекер состояния фоновых компонентов.
+func WithLifecycle(tracker *lifecycle.Tracker) handlerOption {
+	return func(h *Handler) {
+		h.lifecycle = tracker
+	}
+}
+
+// WithDrainer устанавливает вывод экземпляра из балансировки.
+func WithDrainer(d *drain.Drainer) handlerOption {
+	return func(h *Handler) {
+		h.drainer = d
+	}
+}
+
+// WithReadOnly устанавливает режим только для чтения.
+func WithReadOnly(mode *readonly.Mode) handlerOption {
+	return func(h *Handler) {
+		h.readOnly = mode
+	}
+}
+
+// WithCanary устанавливает проверку пробным токеном, результат которой показывается в /ready.
+func WithCanary(checker *canary.Checker) handlerOption {
+	return func(h *Handler) {
+		h.canary = checker
+	}
+}
+
+// WithDependencies устанавливает реестр зависимостей, состояние которых проверяет /readyz.
+func WithDependencies(registry *dependency.Registry) handlerOption {
+	return func(h *Handler) {
+		h.deps = registry
+	}
+}
+
+// WithRedis устанавливает сервис Redis, состояние которого показывается в /health.
+func WithRedis(svc *redis.Service) handlerOption {
+	return func(h *Handler) {
+		h.redis = svc
+	}
+}
+
+// WithHideVersion отключает раскрытие версии, даты сборки, коммита и состояния компонентов
+// в публичном /health. Полная информация остается в HealthDetails на внутреннем порту.
+func WithHideVersion(hide bool) handlerOption {
+	return func(h *Handler) {
+		h.hideVersion = hide
+	}
+}
+
+// WithLogSampling устанавливает настройки выборочного логирования для административного API.
+func WithLogSampling(sampler *logsampling.Sampler) handlerOption {
+	return func(h *Handler) {
+		h.logSampling = sampler
+	}
+}
+
 // New создает новый хендлер. Автоматически устанавливает версию хендлера на Version0.
 func New(opts ...handlerOption) (*Handler, error) {
 	h := &Handler{}
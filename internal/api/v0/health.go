@@ -1,24 +1,122 @@
 package v0
 
 import (
+	"auth-service/internal/service/lifecycle"
+	redisstorage "auth-service/internal/storage/redis"
+	"context"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
 )
 
+// healthResponse - ответ на проверку состояния сервера.
+type healthResponse struct {
+	Version   string `json:"version"`
+	BuildDate string `json:"buildDate"`
+	GitCommit string `json:"gitCommit"`
+
+	// Components - состояние фоновых компонентов (циклов перечитывания, проверок зависимостей, сервера).
+	Components []lifecycle.Component `json:"components,omitempty"`
+
+	// Redis - состояние соединения с Redis.
+	Redis *redisHealth `json:"redis,omitempty"`
+}
+
+// redisHealth - состояние соединения с Redis: статистика пула и основные поля INFO каждого узла.
+type redisHealth struct {
+	// Status - ok или error.
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+
+	Stats *redisstorage.Stats          `json:"stats,omitempty"`
+	Nodes map[string]map[string]string `json:"nodes,omitempty"`
+}
+
+// redisInfoFields - поля INFO, которые показываются в /health.
+func redisInfoFields() []string {
+	return []string{
+		"redis_version", "role", "uptime_in_seconds",
+		"connected_clients", "blocked_clients",
+		"used_memory", "used_memory_peak", "maxmemory",
+		"keyspace_hits", "keyspace_misses", "evicted_keys",
+	}
+}
+
+// publicHealthResponse - ответ на проверку состояния сервера без версии и состояния компонентов.
+type publicHealthResponse struct {
+	Status string `json:"status"`
+}
+
 // Health необходим для проверки работоспособности сервера.
-// Всегда отвечает 200 ОК
+// Всегда отвечает 200 ОК, в деталях - состояние фоновых компонентов.
+// Если раскрытие версии отключено, отвечает только статусом.
 //
 // Health godoc
 //
 //	@Summary		Проверить состояние сервера и соединения
-//	@Description	Проверить состояние сервера и соединения
-//	@Success		200
+//	@Description	Проверить состояние сервера и соединения. Включает состояние фоновых компонентов: starting, running, stopped, failed, количество перезапусков и последнюю ошибку, а также состояние Redis: статистику пула соединений и основные поля INFO каждого узла. Если раскрытие версии отключено (server.debug.hide_version), возвращает только {"status": "ok"}, полная информация доступна на внутреннем отладочном порту.
+//	@Produce		json
+//	@Success		200	{object}	healthResponse
 //	@Router			/health [get]
 func (s *Handler) Health(c echo.Context) error {
-	return c.JSON(http.StatusOK, map[string]string{
-		"version":   s.version,
-		"buildDate": s.buildDate,
-		"gitCommit": s.gitCommit,
-	})
+	if s.hideVersion {
+		return c.JSON(http.StatusOK, publicHealthResponse{Status: "ok"})
+	}
+
+	return s.HealthDetails(c)
+}
+
+// HealthDetails возвращает версию и состояние фоновых компонентов независимо от настроек раскрытия.
+// Регистрируется только на внутреннем отладочном порту.
+func (s *Handler) HealthDetails(c echo.Context) error {
+	resp := healthResponse{
+		Version:   s.version,
+		BuildDate: s.buildDate,
+		GitCommit: s.gitCommit,
+	}
+
+	if s.lifecycle != nil {
+		resp.Components = s.lifecycle.Components()
+	}
+
+	if s.redis != nil {
+		resp.Redis = s.redisHealth(c.Request().Context())
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// redisHealth проверяет соединение с Redis. Ошибка не меняет код ответа /health: она показывается
+// в статусе Redis.
+func (s *Handler) redisHealth(ctx context.Context) *redisHealth {
+	stats, err := s.redis.Stats()
+	if err != nil {
+		return &redisHealth{Status: "error", Error: err.Error()}
+	}
+
+	health := &redisHealth{Status: "ok", Stats: &stats}
+
+	info, err := s.redis.Info(ctx)
+	if err != nil {
+		health.Status = "error"
+		health.Error = err.Error()
+
+		return health
+	}
+
+	health.Nodes = make(map[string]map[string]string, len(info))
+
+	for addr, fields := range info {
+		node := map[string]string{}
+
+		for _, field := range redisInfoFields() {
+			if value, ok := fields[field]; ok {
+				node[field] = value
+			}
+		}
+
+		health.Nodes[addr] = node
+	}
+
+	return health
 }
@@ -1,11 +1,19 @@
 package v0
 
 import (
+	"auth-service/internal/config"
+	"auth-service/internal/service/lifecycle"
+	"auth-service/internal/service/redis"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -46,6 +54,153 @@ func TestHealth(t *testing.T) {
 	assertResponse(t, resp, expectedBody)
 }
 
+func TestHealth_Components(t *testing.T) {
+	t.Parallel()
+
+	tracker, err := lifecycle.New(lifecycle.WithRegisterer(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	tracker.Starting("policies")
+	tracker.Failed("policies", errors.New("vault is sealed"))
+
+	handler, err := New(
+		WithVersion("1.0.0"),
+		WithBuildDate("2021-01-01"),
+		WithGitCommit("1234567890"),
+		WithLifecycle(tracker),
+	)
+	require.NoError(t, err)
+
+	r := runTestServer(t, handler)
+
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	resp := testRequest(t, ts, http.MethodGet, "/api/v0/health", "", nil)
+
+	defer func() {
+		require.NoError(t, resp.Body.Close())
+	}()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var got healthResponse
+
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+
+	assert.Equal(t, "1.0.0", got.Version)
+	require.Len(t, got.Components, 1)
+	assert.Equal(t, "policies", got.Components[0].Name)
+	assert.Equal(t, lifecycle.StateFailed, got.Components[0].State)
+	assert.Equal(t, "vault is sealed", got.Components[0].LastError)
+	assert.NotNil(t, got.Components[0].LastErrorAt)
+}
+
+func TestHealth_Redis(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+
+	svc, err := redis.New(redis.WithCfg(&config.Redis{Type: config.RedisTypeSingle, Host: mr.Host(), Port: port}))
+	require.NoError(t, err)
+	require.NoError(t, svc.Connect(t.Context()))
+
+	t.Cleanup(func() { _ = svc.Stop(t.Context()) })
+
+	handler, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"), WithRedis(svc))
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(runTestServer(t, handler))
+	t.Cleanup(ts.Close)
+
+	health := func() *redisHealth {
+		resp := testRequest(t, ts, http.MethodGet, "/api/v0/health", "", nil)
+
+		defer func() {
+			require.NoError(t, resp.Body.Close())
+		}()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var got healthResponse
+
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		require.NotNil(t, got.Redis)
+
+		return got.Redis
+	}
+
+	got := health()
+	assert.Equal(t, "ok", got.Status)
+	require.NotNil(t, got.Stats)
+	assert.Equal(t, uint32(1), got.Stats.TotalConns)
+	assert.Equal(t, map[string]map[string]string{mr.Addr(): {"connected_clients": "1"}}, got.Nodes)
+
+	// Redis недоступен - /health отвечает, ошибка в статусе Redis
+	mr.Close()
+
+	got = health()
+	assert.Equal(t, "error", got.Status)
+	assert.NotEmpty(t, got.Error)
+	assert.NotNil(t, got.Stats)
+}
+
+func TestHealth_HideVersion(t *testing.T) {
+	t.Parallel()
+
+	handler, err := New(
+		WithVersion("1.0.0"),
+		WithBuildDate("2021-01-01"),
+		WithGitCommit("1234567890"),
+		WithHideVersion(true),
+	)
+	require.NoError(t, err)
+
+	r := runTestServer(t, handler)
+	r.GET("/health", handler.HealthDetails)
+
+	ts := httptest.NewServer(r)
+	t.Cleanup(ts.Close)
+
+	tests := []struct {
+		name string
+		path string
+		want map[string]string
+	}{
+		{
+			name: "positive case: public health hides version",
+			path: "/api/v0/health",
+			want: map[string]string{"status": "ok"},
+		},
+		{
+			name: "positive case: health details show version",
+			path: "/health",
+			want: map[string]string{
+				"version":   "1.0.0",
+				"buildDate": "2021-01-01",
+				"gitCommit": "1234567890",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp := testRequest(t, ts, http.MethodGet, tt.path, "", nil)
+
+			defer func() {
+				require.NoError(t, resp.Body.Close())
+			}()
+
+			assertResponse(t, resp, tt.want)
+		})
+	}
+}
+
 func assertResponse(t *testing.T, resp *http.Response, body map[string]string) {
 	t.Helper()
 
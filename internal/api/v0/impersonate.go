@@ -0,0 +1,139 @@
+package v0
+
+import (
+	"auth-service/internal/service/token"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// staticAdminActor - сотрудник в claim act и аудите, если запрос выполнен со статическим токеном
+// административного API: у статического токена нет субъекта.
+const staticAdminActor = "static-admin"
+
+// impersonateRequest - запрос на токен имперсонации.
+type impersonateRequest struct {
+	Subject string   `json:"subject"`          // пользователь
+	Reason  string   `json:"reason"`           // причина (например, номер обращения), пишется в аудит
+	TTL     string   `json:"ttl,omitempty"`    // время жизни, например 10m. По умолчанию максимальное
+	Scopes  []string `json:"scopes,omitempty"` // scopes из разрешенных только на чтение. По умолчанию все разрешенные
+}
+
+// tokenResponse - выпущенный токен.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresAt   int64  `json:"expires_at"`
+	Scope       string `json:"scope,omitempty"`
+	JTI         string `json:"jti"`
+	// RefreshToken - refresh токен входа, если они включены (POST /token/refresh).
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// Impersonate выпускает токен пользователя для сотрудника поддержки.
+//
+// Impersonate godoc
+//
+//	@Summary		Выпустить токен имперсонации
+//	@Description	Выпускает ограниченный по времени токен пользователя только со scopes на чтение. В claim act записывается администратор, выполнивший запрос: субъект его токена после входа через каталог или static-admin для статического токена. Каждая выдача пишется в аудит
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			request	body		impersonateRequest	true	"Запрос на имперсонацию"
+//	@Success		200		{object}	tokenResponse
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401
+//	@Failure		403	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		422	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/admin/impersonate [post]
+func (s *Handler) Impersonate(c echo.Context) error {
+	if s.issuer == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "token issuance is not configured"})
+	}
+
+	var req impersonateRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+	}
+
+	var ttl time.Duration
+
+	if req.TTL != "" {
+		d, err := time.ParseDuration(req.TTL)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid ttl"})
+		}
+
+		ttl = d
+	}
+
+	actor := adminActor(c)
+
+	audit := logrus.WithFields(logrus.Fields{
+		"audit":      "impersonation",
+		"actor":      actor,
+		"subject":    req.Subject,
+		"reason":     req.Reason,
+		"ip":         c.RealIP(),
+		"request_id": c.Request().Header.Get(echo.HeaderXRequestID),
+	})
+
+	raw, claims, err := s.issuer.Impersonate(c.Request().Context(), token.ImpersonateRequest{
+		Actor:   actor,
+		Subject: req.Subject,
+		Reason:  req.Reason,
+		TTL:     ttl,
+		Scopes:  req.Scopes,
+	})
+	if errors.Is(err, token.ErrImpersonationDenied) {
+		audit.WithError(err).Warn("impersonation denied")
+
+		return c.JSON(http.StatusForbidden, errorResponse{Error: err.Error()})
+	}
+
+	if errors.Is(err, token.ErrClaimsRejected) {
+		audit.WithError(err).Warn("impersonation token rejected")
+
+		return c.JSON(http.StatusUnprocessableEntity, errorResponse{Error: err.Error()})
+	}
+
+	if err != nil {
+		audit.WithError(err).Error("error issue impersonation token")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "token issuance is unavailable"})
+	}
+
+	audit.WithFields(logrus.Fields{
+		"jti":        claims.ID,
+		"kid":        claims.Kid,
+		"scopes":     claims.Scopes,
+		"expires_at": claims.ExpiresAt,
+	}).Info("impersonation token issued")
+
+	return c.JSON(http.StatusOK, tokenResponse{
+		AccessToken: raw,
+		TokenType:   "Bearer",
+		ExpiresAt:   claims.ExpiresAt.Unix(),
+		Scope:       strings.Join(claims.Scopes, " "),
+		JTI:         claims.ID,
+	})
+}
+
+// adminActor возвращает администратора, выполнившего запрос: субъект токена, который adminAuth
+// сохранил в контекст, или staticAdminActor для статического токена. Тело запроса не учитывается,
+// чтобы администратор не мог записать в токен и аудит чужое имя.
+func adminActor(c echo.Context) string {
+	if claims, ok := token.FromContext(c.Request().Context()); ok && claims.Subject != "" {
+		return claims.Subject
+	}
+
+	return staticAdminActor
+}
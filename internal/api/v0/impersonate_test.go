@@ -0,0 +1,162 @@
+package v0
+
+import (
+	"auth-service/internal/service/token"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSigningKeys - источник ключа подписи для тестов.
+type testSigningKeys struct {
+	key []byte
+	err error
+}
+
+func (k testSigningKeys) SigningKey(_ context.Context) (string, []byte, error) {
+	return "key-1", k.key, k.err
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestImpersonate(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+
+	tests := []struct {
+		name       string
+		keys       *testSigningKeys
+		admin      *token.Claims
+		body       string
+		wantStatus int
+		check      func(t *testing.T, resp tokenResponse)
+	}{
+		{
+			name:       "positive case",
+			keys:       &testSigningKeys{key: key},
+			body:       `{"subject":"user-1","reason":"ticket 42","ttl":"5m"}`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, resp tokenResponse) {
+				t.Helper()
+
+				assert.Equal(t, "Bearer", resp.TokenType)
+				assert.Equal(t, token.ScopeRead, resp.Scope)
+				assert.NotEmpty(t, resp.JTI)
+				assert.WithinDuration(t, time.Now().Add(5*time.Minute), time.Unix(resp.ExpiresAt, 0), 2*time.Second)
+
+				v, err := token.NewValidator(token.WithKeys(testKeys{key: key}))
+				require.NoError(t, err)
+
+				claims, err := v.Validate(t.Context(), resp.AccessToken)
+				require.NoError(t, err)
+
+				assert.Equal(t, "user-1", claims.Subject)
+				assert.Equal(t, &token.Actor{Subject: staticAdminActor}, claims.Actor)
+			},
+		},
+		{
+			name:       "positive case: actor from admin token, not from body",
+			keys:       &testSigningKeys{key: key},
+			admin:      &token.Claims{Subject: "alice"},
+			body:       `{"actor":"mallory","subject":"user-1","reason":"ticket 42"}`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, resp tokenResponse) {
+				t.Helper()
+
+				v, err := token.NewValidator(token.WithKeys(testKeys{key: key}))
+				require.NoError(t, err)
+
+				claims, err := v.Validate(t.Context(), resp.AccessToken)
+				require.NoError(t, err)
+
+				assert.Equal(t, &token.Actor{Subject: "alice"}, claims.Actor)
+			},
+		},
+		{
+			name:       "error case: write scope is denied",
+			keys:       &testSigningKeys{key: key},
+			body:       `{"subject":"user-1","reason":"ticket 42","scopes":["write"]}`,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "error case: no reason",
+			keys:       &testSigningKeys{key: key},
+			body:       `{"subject":"user-1"}`,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "error case: invalid ttl",
+			keys:       &testSigningKeys{key: key},
+			body:       `{"subject":"user-1","reason":"ticket 42","ttl":"soon"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "error case: invalid body",
+			keys:       &testSigningKeys{key: key},
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "error case: signing key is unavailable",
+			keys:       &testSigningKeys{err: errors.New("vault is sealed")},
+			body:       `{"subject":"user-1","reason":"ticket 42"}`,
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "error case: not configured",
+			body:       `{"subject":"user-1","reason":"ticket 42"}`,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := []handlerOption{
+				WithVersion("1.0.0"),
+				WithBuildDate("2021-01-01"),
+				WithGitCommit("1234567890"),
+			}
+
+			if tt.keys != nil {
+				issuer, err := token.NewIssuer(token.WithSigningKeys(*tt.keys))
+				require.NoError(t, err)
+
+				opts = append(opts, WithIssuer(issuer))
+			}
+
+			h, err := New(opts...)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			if tt.admin != nil {
+				req = req.WithContext(token.NewContext(req.Context(), tt.admin))
+			}
+
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			require.NoError(t, h.Impersonate(c))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			if tt.check != nil {
+				var resp tokenResponse
+
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+				tt.check(t, resp)
+			}
+		})
+	}
+}
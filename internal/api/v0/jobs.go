@@ -0,0 +1,44 @@
+package v0
+
+import (
+	"auth-service/internal/service/job"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// GetJob возвращает статус, прогресс и результат асинхронного задания.
+//
+// GetJob godoc
+//
+//	@Summary		Статус задания
+//	@Description	Задания хранятся до истечения TTL после последнего обновления, затем возвращается 404
+//	@Tags			jobs
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			id	path		string	true	"ID задания"
+//	@Success		200	{object}	job.Job
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/admin/jobs/{id} [get]
+func (s *Handler) GetJob(c echo.Context) error {
+	if s.jobs == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "jobs are not configured"})
+	}
+
+	j, err := s.jobs.Get(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, job.ErrNotFound) {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: err.Error()})
+	}
+
+	if err != nil {
+		logrus.WithError(err).Error("error get job")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to get job"})
+	}
+
+	return c.JSON(http.StatusOK, j)
+}
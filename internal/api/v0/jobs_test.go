@@ -0,0 +1,87 @@
+package v0
+
+import (
+	"auth-service/internal/service/job"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestGetJob(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	jobs, err := job.New(job.WithClient(client), job.WithConsumer("test"))
+	require.NoError(t, err)
+
+	jobs.Register("export", func(context.Context, map[string]string, job.Progress) (any, error) {
+		return map[string]int{"users": 3}, nil
+	})
+
+	h, err := New(
+		WithVersion("1.0.0"),
+		WithBuildDate("2021-01-01"),
+		WithGitCommit("1234567890"),
+		WithJobs(jobs),
+	)
+	require.NoError(t, err)
+
+	queued, err := jobs.Enqueue(t.Context(), "export", nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+
+	go func() { done <- jobs.Start(ctx) }()
+
+	var got job.Job
+
+	require.Eventually(t, func() bool {
+		rec := callGroups(t, h.GetJob, http.MethodGet, "/", "", map[string]string{"id": queued.ID})
+		if rec.Code != http.StatusOK {
+			return false
+		}
+
+		got = job.Job{}
+
+		return json.NewDecoder(rec.Body).Decode(&got) == nil && got.Status == job.StatusDone
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.Equal(t, queued.ID, got.ID)
+	assert.Equal(t, 100, got.Progress)
+	assert.JSONEq(t, `{"users":3}`, string(got.Result))
+
+	rec := callGroups(t, h.GetJob, http.MethodGet, "/", "", map[string]string{"id": "unknown"})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// Redis недоступен
+	mr.Close()
+
+	rec = callGroups(t, h.GetJob, http.MethodGet, "/", "", map[string]string{"id": queued.ID})
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestGetJob_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	rec := callGroups(t, h.GetJob, http.MethodGet, "/", "", map[string]string{"id": "job"})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,83 @@
+package v0
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// keyUsageResponse - статистика использования ключей подписи.
+type keyUsageResponse struct {
+	IdleFor string     `json:"idle_for,omitempty"`
+	Keys    []keyUsage `json:"keys"`
+}
+
+// keyUsage - статистика использования ключа. Retirable заполняется, если передан idle_for:
+// true означает, что за этот период ключом не проверили ни одного токена.
+type keyUsage struct {
+	Kid            string     `json:"kid"`
+	Issued         uint64     `json:"issued"`
+	Verified       uint64     `json:"verified"`
+	LastIssuedAt   *time.Time `json:"last_issued_at,omitempty"`
+	LastVerifiedAt *time.Time `json:"last_verified_at,omitempty"`
+	Retirable      *bool      `json:"retirable,omitempty"`
+}
+
+// KeyUsage возвращает статистику выпуска и проверки токенов по ключам подписи.
+//
+// KeyUsage godoc
+//
+//	@Summary		Статистика использования ключей
+//	@Description	Количество выпущенных и проверенных токенов по kid с момента запуска. Если передан idle_for, для каждого ключа вычисляется, можно ли его выводить из ротации (не было проверок за период)
+//	@Tags			admin
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			idle_for	query		string	false	"Период без проверок, например 720h"
+//	@Success		200			{object}	keyUsageResponse
+//	@Failure		400			{object}	errorResponse
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Router			/admin/keys/usage [get]
+func (s *Handler) KeyUsage(c echo.Context) error {
+	if s.keyStats == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "key statistics are not configured"})
+	}
+
+	var idleFor time.Duration
+
+	if raw := c.QueryParam("idle_for"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid idle_for"})
+		}
+
+		idleFor = d
+	}
+
+	usage := s.keyStats.Usage()
+	resp := keyUsageResponse{Keys: make([]keyUsage, 0, len(usage))}
+
+	if idleFor > 0 {
+		resp.IdleFor = idleFor.String()
+	}
+
+	for _, u := range usage {
+		item := keyUsage{
+			Kid:            u.Kid,
+			Issued:         u.Issued,
+			Verified:       u.Verified,
+			LastIssuedAt:   u.LastIssuedAt,
+			LastVerifiedAt: u.LastVerifiedAt,
+		}
+
+		if idleFor > 0 {
+			retirable := s.keyStats.Idle(u.Kid, idleFor)
+			item.Retirable = &retirable
+		}
+
+		resp.Keys = append(resp.Keys, item)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
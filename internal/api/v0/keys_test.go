@@ -0,0 +1,107 @@
+package v0
+
+import (
+	"auth-service/internal/service/keystats"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestKeyUsage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		query      string
+		withStats  bool
+		wantStatus int
+		check      func(t *testing.T, resp keyUsageResponse)
+	}{
+		{
+			name:       "positive case",
+			withStats:  true,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, resp keyUsageResponse) {
+				t.Helper()
+
+				require.Len(t, resp.Keys, 1)
+				assert.Equal(t, "key-1", resp.Keys[0].Kid)
+				assert.Equal(t, uint64(1), resp.Keys[0].Issued)
+				assert.Equal(t, uint64(2), resp.Keys[0].Verified)
+				assert.Nil(t, resp.Keys[0].Retirable)
+				assert.Empty(t, resp.IdleFor)
+			},
+		},
+		{
+			name:       "positive case: with idle_for",
+			query:      "?idle_for=720h",
+			withStats:  true,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, resp keyUsageResponse) {
+				t.Helper()
+
+				require.Len(t, resp.Keys, 1)
+				require.NotNil(t, resp.Keys[0].Retirable)
+				// сервис запущен только что - выводить ключ рано
+				assert.False(t, *resp.Keys[0].Retirable)
+				assert.Equal(t, "720h0m0s", resp.IdleFor)
+			},
+		},
+		{
+			name:       "error case: invalid idle_for",
+			query:      "?idle_for=month",
+			withStats:  true,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "error case: not configured",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := []handlerOption{
+				WithVersion("1.0.0"),
+				WithBuildDate("2021-01-01"),
+				WithGitCommit("1234567890"),
+			}
+
+			if tt.withStats {
+				tracker, err := keystats.New(keystats.WithRegisterer(prometheus.NewRegistry()))
+				require.NoError(t, err)
+
+				tracker.Issued("key-1")
+				tracker.Verified("key-1")
+				tracker.Verified("key-1")
+
+				opts = append(opts, WithKeyStats(tracker))
+			}
+
+			h, err := New(opts...)
+			require.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/"+tt.query, nil), rec)
+
+			require.NoError(t, h.KeyUsage(c))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			if tt.check != nil {
+				var resp keyUsageResponse
+
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+				tt.check(t, resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package v0
+
+import (
+	"auth-service/internal/service/refresh"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Logout завершает текущую сессию пользователя: отзывает ее refresh токены и токен запроса.
+//
+// Logout godoc
+//
+//	@Summary		Выйти
+//	@Description	Отзывает refresh токены сессии токена запроса (claim sid) и сам токен запроса: его jti попадает в черный список до истечения. Токены доступа, выпущенные сессией раньше, перестают приниматься аудиториями, для которых включена проверка сессии. Повторный выход с тем же токеном невозможен: токен уже отозван. Токены имперсонации не принимаются
+//	@Tags			auth
+//	@Security		BearerToken
+//	@Success		204
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/auth/logout [post]
+func (s *Handler) Logout(c echo.Context) error {
+	if s.validator == nil || s.revocations == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "logout is not configured"})
+	}
+
+	claims, err := s.authenticateUser(c)
+	if claims == nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+
+	// сессия отзывается первой: если отзыв токена не удастся, клиент повторит выход тем же токеном
+	if s.refresh != nil && claims.SessionID != "" {
+		err := s.refresh.RevokeSession(ctx, claims.Subject, claims.SessionID, refresh.ReasonLogout)
+		if err != nil && !errors.Is(err, refresh.ErrNotFound) {
+			logrus.WithError(err).Error("error revoke session on logout")
+
+			return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to logout"})
+		}
+	}
+
+	if claims.ID != "" {
+		if err := s.revocations.RevokeToken(ctx, claims.ID, claims.ExpiresAt); err != nil {
+			logrus.WithError(err).WithField("jti", claims.ID).Error("error revoke token on logout")
+
+			return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to logout"})
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"subject": claims.Subject,
+		"session": claims.SessionID,
+		"ip":      c.RealIP(),
+	}).Info("user logged out")
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// LogoutAll завершает все сессии пользователя: отзывает все его refresh токены и токены доступа.
+//
+// LogoutAll godoc
+//
+//	@Summary		Выйти из всех сессий
+//	@Description	Отзывает refresh токены всех сессий пользователя и сразу все его токены доступа, выпущенные до выхода, в том числе токен запроса. Отзыв токенов доступа публикуется событием tokens.revoked для других регионов. Токены имперсонации не принимаются
+//	@Tags			auth
+//	@Security		BearerToken
+//	@Success		204
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/auth/logout_all [post]
+func (s *Handler) LogoutAll(c echo.Context) error {
+	if s.validator == nil || s.revocations == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "logout is not configured"})
+	}
+
+	claims, err := s.authenticateUser(c)
+	if claims == nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	sessions := 0
+
+	if s.refresh != nil {
+		sessions, err = s.refresh.RevokeAll(ctx, claims.Subject, refresh.ReasonLogout)
+		if err != nil {
+			logrus.WithError(err).Error("error revoke sessions on logout")
+
+			return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to logout"})
+		}
+	}
+
+	if err := s.revocations.RevokeSubject(ctx, claims.Subject); err != nil {
+		logrus.WithError(err).Error("error revoke user tokens on logout")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to logout"})
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"subject":  claims.Subject,
+		"sessions": sessions,
+		"ip":       c.RealIP(),
+	}).Info("user logged out of all sessions")
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,130 @@
+package v0
+
+import (
+	"auth-service/internal/service/job"
+	"auth-service/internal/service/refresh"
+	"auth-service/internal/service/revocation"
+	"auth-service/internal/service/token"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestLogout(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	jobs, err := job.New(job.WithClient(client), job.WithConsumer("test"))
+	require.NoError(t, err)
+
+	revocations, err := revocation.New(revocation.WithClient(client), revocation.WithJobs(jobs))
+	require.NoError(t, err)
+
+	issuer, err := token.NewIssuer(token.WithSigningKeys(testSigningKeys{key: key}))
+	require.NoError(t, err)
+
+	validator, err := token.NewValidator(token.WithKeys(testKeys{key: key}), token.WithRevocations(revocations))
+	require.NoError(t, err)
+
+	sessions, err := refresh.New(
+		refresh.WithClient(client),
+		refresh.WithIssuer(issuer),
+		refresh.WithRegisterer(prometheus.NewRegistry()),
+	)
+	require.NoError(t, err)
+
+	h, err := New(
+		WithVersion("1.0.0"),
+		WithBuildDate("2021-01-01"),
+		WithGitCommit("1234567890"),
+		WithValidator(validator),
+		WithIssuer(issuer),
+		WithRefresh(sessions),
+		WithRevocations(revocations),
+	)
+	require.NoError(t, err)
+
+	// login входит пользователем subject и возвращает токен доступа и refresh токен
+	login := func(subject string) tokenResponse {
+		access, claims, err := issuer.Issue(t.Context(), token.IssueRequest{Subject: subject, TTL: time.Minute})
+		require.NoError(t, err)
+
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+
+		resp, err := h.withRefresh(c, tokenResponse{AccessToken: access}, claims)
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.RefreshToken)
+
+		return resp
+	}
+
+	call := func(fn echo.HandlerFunc, access string) int {
+		return callAuthorized(t, fn, "", "Bearer "+access, "").Code
+	}
+
+	refreshed := func(refreshToken string) int {
+		return callAuthorized(t, h.RefreshToken, "", "", `{"refresh_token":"`+refreshToken+`"}`).Code
+	}
+
+	phone, laptop, other := login("user-1"), login("user-1"), login("user-2")
+
+	assert.Equal(t, http.StatusUnauthorized, callAuthorized(t, h.Logout, "", "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, call(h.LogoutAll, "invalid"))
+
+	require.Equal(t, http.StatusNoContent, call(h.Logout, phone.AccessToken))
+
+	// отозваны токен запроса и refresh токены его сессии, остальные сессии действуют
+	assert.Equal(t, http.StatusUnauthorized, call(h.ListSessions, phone.AccessToken))
+	assert.Equal(t, http.StatusUnauthorized, refreshed(phone.RefreshToken))
+	assert.Equal(t, http.StatusOK, call(h.ListSessions, laptop.AccessToken))
+
+	list, err := sessions.Sessions(t.Context(), "user-1")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	require.Equal(t, http.StatusNoContent, call(h.LogoutAll, laptop.AccessToken))
+
+	// отозваны все сессии и токены доступа пользователя
+	assert.Equal(t, http.StatusUnauthorized, call(h.ListSessions, laptop.AccessToken))
+	assert.Equal(t, http.StatusUnauthorized, refreshed(laptop.RefreshToken))
+
+	list, err = sessions.Sessions(t.Context(), "user-1")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	// другие пользователи не затрагиваются
+	assert.Equal(t, http.StatusOK, call(h.ListSessions, other.AccessToken))
+
+	mr.Close()
+
+	assert.Equal(t, http.StatusServiceUnavailable, call(h.Logout, other.AccessToken))
+}
+
+func TestLogout_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	for _, fn := range []echo.HandlerFunc{h.Logout, h.LogoutAll} {
+		rec := httptest.NewRecorder()
+
+		require.NoError(t, fn(echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec)))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
@@ -0,0 +1,108 @@
+package v0
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// logSamplingSettings - доля логируемых запросов (от 0 до 1) по шаблону маршрута.
+// Маршруты без доли логируются полностью.
+type logSamplingSettings struct {
+	Routes map[string]float64 `json:"routes"`
+}
+
+// GetLogSampling возвращает доли логируемых запросов по маршрутам.
+//
+// GetLogSampling godoc
+//
+//	@Summary		Получить настройки выборочного логирования
+//	@Tags			admin
+//	@Produce		json
+//	@Security		AdminToken
+//	@Success		200	{object}	logSamplingSettings
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Router			/admin/log-sampling [get]
+func (s *Handler) GetLogSampling(c echo.Context) error {
+	if s.logSampling == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "log sampling is not configured"})
+	}
+
+	return c.JSON(http.StatusOK, logSamplingSettings{Routes: s.logSampling.Rates()})
+}
+
+// UpdateLogSampling заменяет доли логируемых запросов. Настройки сохраняются в Redis
+// и применяются на всех экземплярах сервиса.
+//
+// UpdateLogSampling godoc
+//
+//	@Summary		Изменить настройки выборочного логирования
+//	@Description	Задает долю (0..1) запросов, которые попадают в access log, по шаблону маршрута, например {"routes": {"/api/v0/token/introspect": 0.01}}
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			settings	body		logSamplingSettings	true	"Доли логируемых запросов"
+//	@Success		200			{object}	logSamplingSettings
+//	@Failure		400			{object}	errorResponse
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/admin/log-sampling [put]
+func (s *Handler) UpdateLogSampling(c echo.Context) error {
+	if s.logSampling == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "log sampling is not configured"})
+	}
+
+	var settings logSamplingSettings
+
+	if err := c.Bind(&settings); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+	}
+
+	for route, rate := range settings.Routes {
+		if rate < 0 || rate > 1 {
+			return c.JSON(http.StatusBadRequest, errorResponse{Error: "rate for route " + route + " must be between 0 and 1"})
+		}
+	}
+
+	if err := s.logSampling.Update(c.Request().Context(), settings.Routes); err != nil {
+		logrus.WithError(err).Error("error update log sampling")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "log sampling storage is unavailable"})
+	}
+
+	logrus.WithField("routes", settings.Routes).Info("log sampling updated")
+
+	return c.JSON(http.StatusOK, logSamplingSettings{Routes: s.logSampling.Rates()})
+}
+
+// ResetLogSampling удаляет все доли: снова логируются все запросы.
+//
+// ResetLogSampling godoc
+//
+//	@Summary		Сбросить настройки выборочного логирования
+//	@Tags			admin
+//	@Security		AdminToken
+//	@Success		204
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/admin/log-sampling [delete]
+func (s *Handler) ResetLogSampling(c echo.Context) error {
+	if s.logSampling == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "log sampling is not configured"})
+	}
+
+	if err := s.logSampling.Reset(c.Request().Context()); err != nil {
+		logrus.WithError(err).Error("error reset log sampling")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "log sampling storage is unavailable"})
+	}
+
+	logrus.Info("log sampling reset")
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,94 @@
+package v0
+
+import (
+	"auth-service/internal/service/logsampling"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLogSamplingHandler(t *testing.T) (*Handler, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	sampler, err := logsampling.New(logsampling.WithClient(client))
+	require.NoError(t, err)
+
+	h, err := New(
+		WithVersion("1.0.0"),
+		WithBuildDate("2021-01-01"),
+		WithGitCommit("1234567890"),
+		WithLogSampling(sampler),
+	)
+	require.NoError(t, err)
+
+	return h, mr
+}
+
+func callLogSampling(t *testing.T, fn echo.HandlerFunc, method, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, "/api/v0/admin/log-sampling", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, fn(echo.New().NewContext(req, rec)))
+
+	return rec
+}
+
+func TestLogSampling(t *testing.T) {
+	t.Parallel()
+
+	h, mr := newLogSamplingHandler(t)
+
+	rec := callLogSampling(t, h.UpdateLogSampling, http.MethodPut, `{"routes": {"/api/v0/token/introspect": 0.01}}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "0.01", mr.HGet("auth:log-sampling", "/api/v0/token/introspect"))
+
+	rec = callLogSampling(t, h.GetLogSampling, http.MethodGet, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var settings logSamplingSettings
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&settings))
+	assert.Equal(t, map[string]float64{"/api/v0/token/introspect": 0.01}, settings.Routes)
+
+	rec = callLogSampling(t, h.UpdateLogSampling, http.MethodPut, `{"routes": {"/x": 1.5}}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callLogSampling(t, h.UpdateLogSampling, http.MethodPut, `{"routes":`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callLogSampling(t, h.ResetLogSampling, http.MethodDelete, "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	assert.False(t, mr.Exists("auth:log-sampling"))
+
+	mr.Close()
+
+	rec = callLogSampling(t, h.ResetLogSampling, http.MethodDelete, "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestLogSampling_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	for _, fn := range []echo.HandlerFunc{h.GetLogSampling, h.UpdateLogSampling, h.ResetLogSampling} {
+		assert.Equal(t, http.StatusNotFound, callLogSampling(t, fn, http.MethodGet, "").Code)
+	}
+}
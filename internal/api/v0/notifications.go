@@ -0,0 +1,188 @@
+package v0
+
+import (
+	"auth-service/internal/service/notify"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// notifyTimeout - сколько ждет доставка уведомления о входе, отправляемого после ответа.
+const notifyTimeout = 30 * time.Second
+
+type notificationRequest struct {
+	// Kind - вид уведомления: new_login или password_change.
+	Kind string `json:"kind"`
+	// Detail - подробности для пользователя, например IP.
+	Detail string `json:"detail"`
+}
+
+type notificationResponse struct {
+	// Sent - уведомление отправлено. false - пользователь отключил уведомления этого вида.
+	Sent bool `json:"sent"`
+}
+
+// GetNotificationPreferences возвращает настройки уведомлений пользователя.
+//
+// GetNotificationPreferences godoc
+//
+//	@Summary		Получить настройки уведомлений
+//	@Description	О каких событиях безопасности уведомлять пользователя и куда. Если пользователь их не менял, возвращаются настройки по умолчанию
+//	@Tags			account
+//	@Produce		json
+//	@Security		BearerToken
+//	@Success		200	{object}	notify.Preferences
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/account/notifications [get]
+func (s *Handler) GetNotificationPreferences(c echo.Context) error {
+	if s.notifier == nil || s.validator == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "notifications are not configured"})
+	}
+
+	claims, err := s.authenticateUser(c)
+	if claims == nil {
+		return err
+	}
+
+	p, err := s.notifier.Preferences(c.Request().Context(), claims.Subject)
+	if err != nil {
+		logrus.WithError(err).Error("error get notification preferences")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to get notification preferences"})
+	}
+
+	return c.JSON(http.StatusOK, p)
+}
+
+// UpdateNotificationPreferences сохраняет настройки уведомлений пользователя.
+//
+// UpdateNotificationPreferences godoc
+//
+//	@Summary		Изменить настройки уведомлений
+//	@Description	Канал должен быть настроен на сервере: email или telegram. Токены имперсонации не принимаются
+//	@Tags			account
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerToken
+//	@Param			request	body		notify.Preferences	true	"Настройки уведомлений"
+//	@Success		200		{object}	notify.Preferences
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401		{object}	errorResponse
+//	@Failure		403		{object}	errorResponse
+//	@Failure		404		{object}	errorResponse
+//	@Failure		422		{object}	errorResponse
+//	@Failure		503		{object}	errorResponse
+//	@Router			/account/notifications [put]
+func (s *Handler) UpdateNotificationPreferences(c echo.Context) error {
+	if s.notifier == nil || s.validator == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "notifications are not configured"})
+	}
+
+	claims, err := s.authenticateUser(c)
+	if claims == nil {
+		return err
+	}
+
+	var p notify.Preferences
+
+	if err := c.Bind(&p); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+	}
+
+	err = s.notifier.SetPreferences(c.Request().Context(), claims.Subject, p)
+
+	switch {
+	case errors.Is(err, notify.ErrChannelUnavailable):
+		return c.JSON(http.StatusUnprocessableEntity, errorResponse{Error: err.Error()})
+	case err != nil:
+		logrus.WithError(err).Error("error save notification preferences")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to save notification preferences"})
+	}
+
+	return c.JSON(http.StatusOK, p)
+}
+
+// SendNotification уведомляет пользователя о событии безопасности, которое произошло в другом
+// сервисе, например о смене пароля. Настройки пользователя учитываются.
+//
+// SendNotification godoc
+//
+//	@Summary		Уведомить пользователя
+//	@Description	Для сервисов, которые сами меняют пароль или выполняют вход. Если пользователь отключил уведомления этого вида, возвращается sent=false
+//	@Tags			account
+//	@Accept			json
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			id		path		string				true	"ID пользователя"
+//	@Param			request	body		notificationRequest	true	"Уведомление"
+//	@Success		200		{object}	notificationResponse
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		422	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/admin/users/{id}/notifications [post]
+func (s *Handler) SendNotification(c echo.Context) error {
+	if s.notifier == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "notifications are not configured"})
+	}
+
+	var req notificationRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+	}
+
+	sent, err := s.notifier.Notify(c.Request().Context(), notify.Notification{
+		Kind:    req.Kind,
+		Subject: c.Param("id"),
+		Detail:  req.Detail,
+	})
+
+	switch {
+	case errors.Is(err, notify.ErrInvalidArgument):
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+	case errors.Is(err, notify.ErrNoAddress), errors.Is(err, notify.ErrChannelUnavailable):
+		return c.JSON(http.StatusUnprocessableEntity, errorResponse{Error: err.Error()})
+	case err != nil:
+		logrus.WithError(err).WithField("subject", c.Param("id")).Error("error send notification")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to send notification"})
+	}
+
+	return c.JSON(http.StatusOK, notificationResponse{Sent: sent})
+}
+
+// notifyLogin уведомляет пользователя о входе, если он этого хочет. Уведомление отправляется
+// после ответа: отправка письма не задерживает вход, а ее ошибка не отменяет его.
+func (s *Handler) notifyLogin(c echo.Context, subject, method string) {
+	if s.notifier == nil {
+		return
+	}
+
+	n := notify.Notification{
+		Kind:    notify.KindNewLogin,
+		Subject: subject,
+		Detail:  method + ", IP " + c.RealIP(),
+		At:      time.Now(),
+	}
+
+	ctx := context.WithoutCancel(c.Request().Context())
+
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+		defer cancel()
+
+		if _, err := s.notifier.Notify(ctx, n); err != nil {
+			logrus.WithError(err).WithField("subject", subject).Warn("error send login notification")
+		}
+	}()
+}
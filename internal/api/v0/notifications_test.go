@@ -0,0 +1,134 @@
+package v0
+
+import (
+	"auth-service/internal/service/event"
+	"auth-service/internal/service/notify"
+	"auth-service/internal/service/token"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newNotifyHandler создает хендлер с входом через OAuth и уведомлениями в Telegram.
+func newNotifyHandler(t *testing.T) (*Handler, *miniredis.Miniredis) {
+	t.Helper()
+
+	h := newOAuthHandler(t)
+
+	validator, err := token.NewValidator(token.WithKeys(testKeys{key: []byte("secret")}))
+	require.NoError(t, err)
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	events, err := event.New(event.WithClient(client))
+	require.NoError(t, err)
+
+	notifier, err := notify.New(notify.WithClient(client), notify.WithTelegram(events))
+	require.NoError(t, err)
+
+	h.validator = validator
+	h.notifier = notifier
+
+	return h, mr
+}
+
+// streamTypes возвращает типы событий в stream.
+func streamTypes(t *testing.T, mr *miniredis.Miniredis) []string {
+	t.Helper()
+
+	entries, err := mr.Stream(event.DefaultStream)
+	if err != nil {
+		return nil
+	}
+
+	types := make([]string, 0, len(entries))
+
+	for _, e := range entries {
+		for i := 0; i+1 < len(e.Values); i += 2 {
+			if e.Values[i] == "type" {
+				types = append(types, e.Values[i+1])
+			}
+		}
+	}
+
+	return types
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestNotificationPreferences(t *testing.T) {
+	t.Parallel()
+
+	h, mr := newNotifyHandler(t)
+
+	// вход уведомляется по настройкам по умолчанию
+	auth := loginOAuth(t, h)
+
+	assert.Eventually(t, func() bool {
+		return len(streamTypes(t, mr)) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"notify.new_login"}, streamTypes(t, mr))
+
+	rec := callAuthorized(t, h.GetNotificationPreferences, "", "", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = callAuthorized(t, h.GetNotificationPreferences, "", auth, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"new_login":true,"password_change":true,"channel":"telegram"}`, rec.Body.String())
+
+	rec = callAuthorized(t, h.UpdateNotificationPreferences, "", auth, `{"new_login":false,"channel":"email"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = callAuthorized(t, h.UpdateNotificationPreferences, "", auth, `[]`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callAuthorized(t, h.UpdateNotificationPreferences, "", auth,
+		`{"new_login":false,"password_change":true,"channel":"telegram"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = callAuthorized(t, h.GetNotificationPreferences, "", auth, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"new_login":false,"password_change":true,"channel":"telegram"}`, rec.Body.String())
+
+	// отключенное уведомление о входе не отправляется
+	loginOAuth(t, h)
+
+	// смена пароля в другом сервисе уведомляется
+	claims, err := h.validator.Validate(t.Context(), auth[len("Bearer "):])
+	require.NoError(t, err)
+
+	params := map[string]string{"id": claims.Subject}
+
+	rec = callGroups(t, h.SendNotification, http.MethodPost, "/", `{"kind":"password_change","detail":"IP 192.0.2.1"}`, params)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"sent":true}`, rec.Body.String())
+
+	rec = callGroups(t, h.SendNotification, http.MethodPost, "/", `{"kind":"new_login"}`, params)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"sent":false}`, rec.Body.String())
+
+	rec = callGroups(t, h.SendNotification, http.MethodPost, "/", `{"kind":"unknown"}`, params)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	assert.Equal(t, []string{"notify.new_login", "notify.password_change"}, streamTypes(t, mr))
+}
+
+func TestNotificationPreferences_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	for _, fn := range []echo.HandlerFunc{h.GetNotificationPreferences, h.UpdateNotificationPreferences, h.SendNotification} {
+		rec := callAuthorized(t, fn, "", "", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
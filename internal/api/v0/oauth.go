@@ -0,0 +1,132 @@
+package v0
+
+import (
+	"auth-service/internal/service/oauth"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// StartOAuth начинает вход через внешнего провайдера.
+//
+// StartOAuth godoc
+//
+//	@Summary		Начать вход через внешнего провайдера
+//	@Description	Перенаправляет браузер на страницу входа провайдера (Google, GitHub, OpenID Connect). Вход защищен state и PKCE
+//	@Tags			oauth
+//	@Param			provider	path	string	true	"Имя провайдера из конфигурации"
+//	@Success		302
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/oauth/{provider}/start [get]
+func (s *Handler) StartOAuth(c echo.Context) error {
+	if s.oauth == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "oauth is not configured"})
+	}
+
+	redirect, err := s.oauth.Start(c.Request().Context(), c.Param("provider"))
+
+	switch {
+	case errors.Is(err, oauth.ErrUnknownProvider):
+		return c.JSON(http.StatusNotFound, errorResponse{Error: err.Error()})
+	case err != nil:
+		logrus.WithError(err).WithField("provider", c.Param("provider")).Error("error start oauth login")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to start oauth login"})
+	}
+
+	return c.Redirect(http.StatusFound, redirect)
+}
+
+// OAuthCallback завершает вход через внешнего провайдера.
+//
+// OAuthCallback godoc
+//
+//	@Summary		Завершить вход через внешнего провайдера
+//	@Description	Адрес возврата от провайдера. Пользователь провайдера привязывается к локальному субъекту по сохраненной связи или подтвержденной почте, иначе создается новый субъект. Если настроен success_url, браузер перенаправляется туда с токеном во фрагменте адреса, иначе токен возвращается в JSON
+//	@Tags			oauth
+//	@Produce		json
+//	@Param			provider	path		string	true	"Имя провайдера из конфигурации"
+//	@Param			code		query		string	false	"Код авторизации провайдера"
+//	@Param			state		query		string	false	"State из StartOAuth"
+//	@Param			error		query		string	false	"Ошибка провайдера"
+//	@Success		200			{object}	tokenResponse
+//	@Success		302
+//	@Failure		400	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		502	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/oauth/{provider}/callback [get]
+func (s *Handler) OAuthCallback(c echo.Context) error {
+	if s.oauth == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "oauth is not configured"})
+	}
+
+	provider := c.Param("provider")
+
+	// пользователь отказался от входа или провайдер отклонил запрос
+	if upstreamErr := c.QueryParam("error"); upstreamErr != "" {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "provider returned error: " + upstreamErr})
+	}
+
+	login, err := s.oauth.Callback(c.Request().Context(), provider, c.QueryParam("code"), c.QueryParam("state"))
+
+	switch {
+	case errors.Is(err, oauth.ErrUnknownProvider):
+		return c.JSON(http.StatusNotFound, errorResponse{Error: err.Error()})
+	case errors.Is(err, oauth.ErrInvalidState), errors.Is(err, oauth.ErrInvalidArgument):
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: oauth.ErrInvalidState.Error()})
+	case errors.Is(err, oauth.ErrUpstream):
+		logrus.WithError(err).WithField("provider", provider).Warn("oauth provider error")
+
+		return c.JSON(http.StatusBadGateway, errorResponse{Error: oauth.ErrUpstream.Error()})
+	case err != nil:
+		logrus.WithError(err).WithField("provider", provider).Error("error complete oauth login")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to complete oauth login"})
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"provider": provider,
+		"subject":  login.Subject,
+		"created":  login.Created,
+		"jti":      login.Claims.ID,
+		"ip":       c.RealIP(),
+	}).Info("oauth login completed")
+
+	response, err := s.withRefresh(c, tokenResponse{
+		AccessToken: login.Token,
+		TokenType:   "Bearer",
+		ExpiresAt:   login.Claims.ExpiresAt.Unix(),
+		Scope:       strings.Join(login.Claims.Scopes, " "),
+		JTI:         login.Claims.ID,
+	}, login.Claims)
+	if err != nil {
+		return refreshUnavailable(c)
+	}
+
+	s.notifyLogin(c, login.Subject, "oauth/"+provider)
+
+	successURL := s.oauth.SuccessURL()
+	if successURL == "" {
+		return c.JSON(http.StatusOK, response)
+	}
+
+	// фрагмент не уходит на сервер фронтенда и не попадает в его логи
+	fragment := url.Values{
+		"access_token": {response.AccessToken},
+		"token_type":   {response.TokenType},
+		"expires_at":   {strconv.FormatInt(response.ExpiresAt, 10)},
+	}
+
+	if response.RefreshToken != "" {
+		fragment.Set("refresh_token", response.RefreshToken)
+	}
+
+	return c.Redirect(http.StatusFound, successURL+"#"+fragment.Encode())
+}
@@ -0,0 +1,186 @@
+package v0
+
+import (
+	"auth-service/internal/service/oauth"
+	"auth-service/internal/service/oauth/mocks"
+	"auth-service/internal/service/token"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOAuthUpstream - тестовый провайдер OpenID Connect.
+func newOAuthUpstream(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "good-code" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "upstream-token"})
+	})
+	mux.HandleFunc("GET /userinfo", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"sub": "u-1", "email": "user@example.com", "email_verified": true})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newOAuthHandler(t *testing.T, opts ...oauth.Option) *Handler {
+	t.Helper()
+
+	upstream := newOAuthUpstream(t)
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	issuer, err := token.NewIssuer(token.WithSigningKeys(testSigningKeys{key: []byte("secret")}))
+	require.NoError(t, err)
+
+	secrets := mocks.NewMocksecretReader(gomock.NewController(t))
+	secrets.EXPECT().ReadKV(gomock.Any(), "secret/data/auth/oauth/corp").
+		Return(map[string]interface{}{"client_id": "client", "client_secret": "secret"}, nil).AnyTimes()
+
+	svc, err := oauth.New(append([]oauth.Option{
+		oauth.WithClient(client),
+		oauth.WithIssuer(issuer),
+		oauth.WithSecrets(secrets),
+		oauth.WithToken(time.Hour, []string{"dashboard"}),
+		oauth.WithProvider(oauth.Provider{
+			Name:        "corp",
+			Kind:        oauth.KindOIDC,
+			AuthURL:     upstream.URL + "/authorize",
+			TokenURL:    upstream.URL + "/token",
+			UserInfoURL: upstream.URL + "/userinfo",
+			RedirectURL: "https://auth.example.com/api/v0/oauth/corp/callback",
+		}),
+	}, opts...)...)
+	require.NoError(t, err)
+
+	h, err := New(
+		WithVersion("1.0.0"),
+		WithBuildDate("2021-01-01"),
+		WithGitCommit("1234567890"),
+		WithOAuth(svc),
+	)
+	require.NoError(t, err)
+
+	return h
+}
+
+func callOAuth(t *testing.T, fn echo.HandlerFunc, provider, query string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+
+	c.SetParamNames("provider")
+	c.SetParamValues(provider)
+
+	require.NoError(t, fn(c))
+
+	return rec
+}
+
+// startOAuth начинает вход и возвращает state из адреса перенаправления.
+func startOAuth(t *testing.T, h *Handler) string {
+	t.Helper()
+
+	rec := callOAuth(t, h.StartOAuth, "corp", "")
+	require.Equal(t, http.StatusFound, rec.Code)
+
+	location, err := url.Parse(rec.Header().Get(echo.HeaderLocation))
+	require.NoError(t, err)
+
+	return location.Query().Get("state")
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestOAuth(t *testing.T) {
+	t.Parallel()
+
+	h := newOAuthHandler(t)
+
+	rec := callOAuth(t, h.StartOAuth, "unknown", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	state := startOAuth(t, h)
+	require.NotEmpty(t, state)
+
+	// провайдер не принял код
+	rec = callOAuth(t, h.OAuthCallback, "corp", url.Values{"code": {"bad-code"}, "state": {state}}.Encode())
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+
+	// state одноразовый
+	rec = callOAuth(t, h.OAuthCallback, "corp", url.Values{"code": {"good-code"}, "state": {state}}.Encode())
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callOAuth(t, h.OAuthCallback, "corp", url.Values{"error": {"access_denied"}}.Encode())
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "access_denied")
+
+	rec = callOAuth(t, h.OAuthCallback, "unknown", url.Values{"code": {"good-code"}, "state": {"x"}}.Encode())
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	state = startOAuth(t, h)
+
+	rec = callOAuth(t, h.OAuthCallback, "corp", url.Values{"code": {"good-code"}, "state": {state}}.Encode())
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp tokenResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.NotEmpty(t, resp.AccessToken)
+	assert.Equal(t, "Bearer", resp.TokenType)
+}
+
+func TestOAuth_SuccessURL(t *testing.T) {
+	t.Parallel()
+
+	h := newOAuthHandler(t, oauth.WithSuccessURL("https://app.example.com/login"))
+
+	state := startOAuth(t, h)
+
+	rec := callOAuth(t, h.OAuthCallback, "corp", url.Values{"code": {"good-code"}, "state": {state}}.Encode())
+	require.Equal(t, http.StatusFound, rec.Code)
+
+	location, err := url.Parse(rec.Header().Get(echo.HeaderLocation))
+	require.NoError(t, err)
+	assert.Equal(t, "app.example.com", location.Host)
+	assert.Empty(t, location.RawQuery)
+
+	fragment, err := url.ParseQuery(location.Fragment)
+	require.NoError(t, err)
+	assert.NotEmpty(t, fragment.Get("access_token"))
+	assert.Equal(t, "Bearer", fragment.Get("token_type"))
+}
+
+func TestOAuth_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	for _, fn := range []echo.HandlerFunc{h.StartOAuth, h.OAuthCallback} {
+		rec := callOAuth(t, fn, "corp", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
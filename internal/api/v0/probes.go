@@ -0,0 +1,57 @@
+package v0
+
+import (
+	"auth-service/internal/service/dependency"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// requiredDependencies - зависимости, без которых экземпляр не готов принимать запросы:
+// без Vault нет ключей подписи, без Redis - сессий и черных списков. Сервис пользователей
+// показывается в ответе, но на готовность не влияет: без него работает проверка токенов.
+func requiredDependencies() []dependency.Name {
+	return []dependency.Name{dependency.Vault, dependency.Redis}
+}
+
+// Liveness - проверка живости для оркестратора (GET /healthz на корне публичного порта, поэтому
+// не в swagger). Всегда 200 {"status": "ok"}: зависимости не проверяются, перезапуск процесса
+// не поможет, если недоступен Vault или Redis.
+func (s *Handler) Liveness(c echo.Context) error {
+	return c.JSON(http.StatusOK, publicHealthResponse{Status: "ok"})
+}
+
+// Readiness - проверка готовности для оркестратора (GET /readyz). То же, что /ready, и дополнительно
+// состояние зависимостей по результатам последней фоновой проверки реестра: 503 dependency_unavailable,
+// если Vault недоступен или запечатан, Redis не отвечает на PING или зависимость еще не проверялась.
+// При отключенном раскрытии версии тексты ошибок зависимостей не показываются.
+func (s *Handler) Readiness(c echo.Context) error {
+	code, resp := s.readiness()
+
+	if s.deps == nil {
+		return c.JSON(code, resp)
+	}
+
+	resp.Dependencies = s.deps.Statuses()
+
+	if s.hideVersion {
+		for name, status := range resp.Dependencies {
+			status.Error = ""
+			resp.Dependencies[name] = status
+		}
+	}
+
+	if code != http.StatusOK {
+		return c.JSON(code, resp)
+	}
+
+	for _, name := range requiredDependencies() {
+		if s.deps.Status(name).State != dependency.StateUp {
+			resp.Status = "dependency_unavailable"
+
+			return c.JSON(http.StatusServiceUnavailable, resp)
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,122 @@
+package v0
+
+import (
+	"auth-service/internal/service/dependency"
+	"auth-service/internal/service/drain"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func callProbe(t *testing.T, fn echo.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, fn(echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)))
+
+	return rec
+}
+
+func TestLiveness(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	rec := callProbe(t, h.Liveness)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+}
+
+func TestReadiness(t *testing.T) {
+	t.Parallel()
+
+	noop := func(context.Context) error { return nil }
+
+	deps, err := dependency.New(
+		dependency.WithChecker(dependency.Vault, noop),
+		dependency.WithChecker(dependency.Redis, noop),
+		dependency.WithChecker(dependency.UserStore, noop),
+	)
+	require.NoError(t, err)
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"), WithDependencies(deps))
+	require.NoError(t, err)
+
+	decode := func(rec *httptest.ResponseRecorder) readinessResponse {
+		var resp readinessResponse
+
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+
+		return resp
+	}
+
+	// зависимости еще не проверялись
+	rec := callProbe(t, h.Readiness)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "dependency_unavailable", decode(rec).Status)
+
+	deps.Set(dependency.Vault, nil)
+	deps.Set(dependency.Redis, nil)
+	deps.Set(dependency.UserStore, errors.New("connection refused"))
+
+	// сервис пользователей на готовность не влияет
+	rec = callProbe(t, h.Readiness)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	resp := decode(rec)
+	assert.Equal(t, "ready", resp.Status)
+	assert.Equal(t, dependency.StateUp, resp.Dependencies[dependency.Vault].State)
+	assert.Equal(t, dependency.StateDown, resp.Dependencies[dependency.UserStore].State)
+	assert.Equal(t, "connection refused", resp.Dependencies[dependency.UserStore].Error)
+
+	deps.Set(dependency.Vault, errors.New("vault is sealed"))
+
+	rec = callProbe(t, h.Readiness)
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	resp = decode(rec)
+	assert.Equal(t, "dependency_unavailable", resp.Status)
+	assert.Equal(t, "vault is sealed", resp.Dependencies[dependency.Vault].Error)
+
+	// /ready зависимости не учитывает
+	assert.Equal(t, http.StatusOK, callProbe(t, h.Ready).Code)
+
+	h.hideVersion = true
+
+	resp = decode(callProbe(t, h.Readiness))
+	assert.Equal(t, dependency.StateDown, resp.Dependencies[dependency.Vault].State)
+	assert.Empty(t, resp.Dependencies[dependency.Vault].Error)
+
+	drainer, err := drain.New(
+		drain.WithExit(func() {}),
+		drain.WithMaxDelay(time.Hour),
+		drain.WithRegisterer(prometheus.NewRegistry()),
+	)
+	require.NoError(t, err)
+
+	_, err = drainer.Drain(time.Hour)
+	require.NoError(t, err)
+
+	h.drainer = drainer
+
+	rec = callProbe(t, h.Readiness)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "draining", decode(rec).Status)
+
+	h.drainer = nil
+	h.deps = nil
+
+	rec = callProbe(t, h.Readiness)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ready"}`, rec.Body.String())
+}
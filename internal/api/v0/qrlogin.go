@@ -0,0 +1,162 @@
+package v0
+
+import (
+	"auth-service/internal/service/qrlogin"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// qrLoginClaimRequest - запрос браузера на токен после подтверждения входа.
+type qrLoginClaimRequest struct {
+	Secret string `json:"secret"` // секрет из ответа POST /qr-login
+}
+
+// qrLoginStatusResponse - вход еще не подтвержден.
+type qrLoginStatusResponse struct {
+	Status qrlogin.Status `json:"status"`
+}
+
+// StartQRLogin создает код для входа по QR.
+//
+// StartQRLogin godoc
+//
+//	@Summary		Начать вход по QR
+//	@Description	Возвращает код и содержимое QR для показа в браузере, а также секрет, которым браузер заберет токен после подтверждения. Код действует несколько минут
+//	@Tags			qr-login
+//	@Produce		json
+//	@Success		201	{object}	qrlogin.Challenge
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/qr-login [post]
+func (s *Handler) StartQRLogin(c echo.Context) error {
+	if s.qrLogin == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "qr login is not configured"})
+	}
+
+	challenge, err := s.qrLogin.Start(c.Request().Context())
+	if err != nil {
+		logrus.WithError(err).Error("error start qr login")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to start qr login"})
+	}
+
+	return c.JSON(http.StatusCreated, challenge)
+}
+
+// ConfirmQRLogin подтверждает вход по QR из авторизованного приложения.
+//
+// ConfirmQRLogin godoc
+//
+//	@Summary		Подтвердить вход по QR
+//	@Description	Вызывается приложением или ботом, в котором пользователь уже авторизован. Вход выполняется от имени субъекта токена. Токены имперсонации не принимаются
+//	@Tags			qr-login
+//	@Produce		json
+//	@Security		BearerToken
+//	@Param			code	path	string	true	"Код из QR"
+//	@Success		204
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		409	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/qr-login/{code}/confirm [post]
+func (s *Handler) ConfirmQRLogin(c echo.Context) error {
+	if s.qrLogin == nil || s.validator == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "qr login is not configured"})
+	}
+
+	claims, err := s.authenticateUser(c)
+	if claims == nil {
+		return err
+	}
+
+	log := logrus.WithFields(logrus.Fields{
+		"subject": claims.Subject,
+		"jti":     claims.ID,
+		"ip":      c.RealIP(),
+	})
+
+	err = s.qrLogin.Confirm(c.Request().Context(), c.Param("code"), claims.Subject)
+
+	switch {
+	case errors.Is(err, qrlogin.ErrNotFound), errors.Is(err, qrlogin.ErrInvalidArgument):
+		return c.JSON(http.StatusNotFound, errorResponse{Error: qrlogin.ErrNotFound.Error()})
+	case errors.Is(err, qrlogin.ErrAlreadyConfirmed):
+		return c.JSON(http.StatusConflict, errorResponse{Error: err.Error()})
+	case err != nil:
+		log.WithError(err).Error("error confirm qr login")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to confirm qr login"})
+	}
+
+	log.Info("qr login confirmed")
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ClaimQRLogin выдает браузеру токен после подтверждения входа.
+//
+// ClaimQRLogin godoc
+//
+//	@Summary		Получить токен входа по QR
+//	@Description	Браузер опрашивает этот метод с секретом из POST /qr-login. Пока вход не подтвержден, возвращается 202. Токен выдается один раз, после этого код недействителен
+//	@Tags			qr-login
+//	@Accept			json
+//	@Produce		json
+//	@Param			code	path		string				true	"Код из QR"
+//	@Param			request	body		qrLoginClaimRequest	true	"Секрет браузера"
+//	@Success		200		{object}	tokenResponse
+//	@Success		202		{object}	qrLoginStatusResponse
+//	@Failure		400		{object}	errorResponse
+//	@Failure		404		{object}	errorResponse
+//	@Failure		503		{object}	errorResponse
+//	@Router			/qr-login/{code}/token [post]
+func (s *Handler) ClaimQRLogin(c echo.Context) error {
+	if s.qrLogin == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "qr login is not configured"})
+	}
+
+	var req qrLoginClaimRequest
+
+	if err := c.Bind(&req); err != nil || req.Secret == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "secret is required"})
+	}
+
+	login, err := s.qrLogin.Claim(c.Request().Context(), c.Param("code"), req.Secret)
+
+	switch {
+	case errors.Is(err, qrlogin.ErrPending):
+		return c.JSON(http.StatusAccepted, qrLoginStatusResponse{Status: qrlogin.StatusPending})
+	case errors.Is(err, qrlogin.ErrNotFound), errors.Is(err, qrlogin.ErrInvalidArgument):
+		return c.JSON(http.StatusNotFound, errorResponse{Error: qrlogin.ErrNotFound.Error()})
+	case err != nil:
+		logrus.WithError(err).Error("error claim qr login")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to complete qr login"})
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"subject": login.Claims.Subject,
+		"jti":     login.Claims.ID,
+		"ip":      c.RealIP(),
+	}).Info("qr login completed")
+
+	resp, err := s.withRefresh(c, tokenResponse{
+		AccessToken: login.Token,
+		TokenType:   "Bearer",
+		ExpiresAt:   login.Claims.ExpiresAt.Unix(),
+		Scope:       strings.Join(login.Claims.Scopes, " "),
+		JTI:         login.Claims.ID,
+	}, login.Claims)
+	if err != nil {
+		return refreshUnavailable(c)
+	}
+
+	s.notifyLogin(c, login.Claims.Subject, "qr")
+
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,172 @@
+package v0
+
+import (
+	"auth-service/internal/service/qrlogin"
+	"auth-service/internal/service/token"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newQRLoginHandler(t *testing.T) (*Handler, *token.Issuer, *miniredis.Miniredis) {
+	t.Helper()
+
+	key := []byte("secret")
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	issuer, err := token.NewIssuer(token.WithSigningKeys(testSigningKeys{key: key}))
+	require.NoError(t, err)
+
+	validator, err := token.NewValidator(token.WithKeys(testKeys{key: key}))
+	require.NoError(t, err)
+
+	svc, err := qrlogin.New(
+		qrlogin.WithClient(client),
+		qrlogin.WithIssuer(issuer),
+		qrlogin.WithToken(time.Hour, []string{"dashboard"}),
+	)
+	require.NoError(t, err)
+
+	h, err := New(
+		WithVersion("1.0.0"),
+		WithBuildDate("2021-01-01"),
+		WithGitCommit("1234567890"),
+		WithValidator(validator),
+		WithQRLogin(svc),
+	)
+	require.NoError(t, err)
+
+	return h, issuer, mr
+}
+
+func callAuthorized(t *testing.T, fn echo.HandlerFunc, code, auth, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	if auth != "" {
+		req.Header.Set(echo.HeaderAuthorization, auth)
+	}
+
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+
+	c.SetParamNames("code")
+	c.SetParamValues(code)
+
+	require.NoError(t, fn(c))
+
+	return rec
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestQRLogin(t *testing.T) {
+	t.Parallel()
+
+	h, issuer, mr := newQRLoginHandler(t)
+
+	rec := callAuthorized(t, h.StartQRLogin, "", "", "")
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var challenge qrlogin.Challenge
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&challenge))
+	require.NotEmpty(t, challenge.Code)
+	require.NotEmpty(t, challenge.Secret)
+
+	claimBody := `{"secret":"` + challenge.Secret + `"}`
+
+	// до подтверждения браузер ждет
+	rec = callAuthorized(t, h.ClaimQRLogin, challenge.Code, "", claimBody)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	assert.JSONEq(t, `{"status":"pending"}`, rec.Body.String())
+
+	userToken, _, err := issuer.Issue(t.Context(), token.IssueRequest{Subject: "user-1", TTL: time.Hour})
+	require.NoError(t, err)
+
+	supportToken, _, err := issuer.Issue(t.Context(), token.IssueRequest{
+		Subject: "user-1",
+		TTL:     time.Hour,
+		Actor:   &token.Actor{Subject: "support-1"},
+	})
+	require.NoError(t, err)
+
+	rec = callAuthorized(t, h.ConfirmQRLogin, challenge.Code, "", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = callAuthorized(t, h.ConfirmQRLogin, challenge.Code, "Bearer invalid", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = callAuthorized(t, h.ConfirmQRLogin, challenge.Code, "Bearer "+supportToken, "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = callAuthorized(t, h.ConfirmQRLogin, "unknown", "Bearer "+userToken, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = callAuthorized(t, h.ConfirmQRLogin, challenge.Code, "Bearer "+userToken, "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = callAuthorized(t, h.ConfirmQRLogin, challenge.Code, "Bearer "+userToken, "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = callAuthorized(t, h.ClaimQRLogin, challenge.Code, "", `{}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callAuthorized(t, h.ClaimQRLogin, challenge.Code, "", `{"secret":"wrong"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = callAuthorized(t, h.ClaimQRLogin, challenge.Code, "", claimBody)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp tokenResponse
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, "Bearer", resp.TokenType)
+
+	claims, err := h.validator.Validate(t.Context(), resp.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+	assert.Equal(t, []string{"dashboard"}, claims.Audience)
+
+	// код используется один раз
+	rec = callAuthorized(t, h.ClaimQRLogin, challenge.Code, "", claimBody)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// Redis недоступен
+	mr.Close()
+
+	rec = callAuthorized(t, h.StartQRLogin, "", "", "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	rec = callAuthorized(t, h.ConfirmQRLogin, challenge.Code, "Bearer "+userToken, "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	rec = callAuthorized(t, h.ClaimQRLogin, challenge.Code, "", claimBody)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestQRLogin_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	for _, fn := range []echo.HandlerFunc{h.StartQRLogin, h.ConfirmQRLogin, h.ClaimQRLogin} {
+		rec := callAuthorized(t, fn, "code", "Bearer token", `{"secret":"secret"}`)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
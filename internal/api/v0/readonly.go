@@ -0,0 +1,102 @@
+package v0
+
+import (
+	"auth-service/internal/service/readonly"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// readOnlyRequest - параметры включения режима только для чтения.
+type readOnlyRequest struct {
+	// Reason - причина включения, показывается в состоянии режима и пишется в журнал аудита.
+	Reason string `json:"reason"`
+}
+
+// GetReadOnly возвращает состояние режима только для чтения.
+//
+// GetReadOnly godoc
+//
+//	@Summary		Получить состояние режима только для чтения
+//	@Tags			admin
+//	@Produce		json
+//	@Security		AdminToken
+//	@Success		200	{object}	readonly.Status
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Router			/admin/read-only [get]
+func (s *Handler) GetReadOnly(c echo.Context) error {
+	if s.readOnly == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "read-only mode is not configured"})
+	}
+
+	return c.JSON(http.StatusOK, s.readOnly.Status())
+}
+
+// EnableReadOnly включает режим только для чтения до его выключения оператором.
+//
+// EnableReadOnly godoc
+//
+//	@Summary		Включить режим только для чтения
+//	@Description	Проверка и интроспекция токенов продолжают работать, а выпуск токенов, регистрация и отзыв отвечают 503, пока режим не выключен через DELETE /admin/read-only
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			request	body		readOnlyRequest	true	"Причина включения"
+//	@Success		200		{object}	readonly.Status
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Router			/admin/read-only [put]
+func (s *Handler) EnableReadOnly(c echo.Context) error {
+	if s.readOnly == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "read-only mode is not configured"})
+	}
+
+	var req readOnlyRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+	}
+
+	if req.Reason == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "reason is required"})
+	}
+
+	return readOnlyChanged(c, "read_only_enable", s.readOnly.Enable(req.Reason))
+}
+
+// DisableReadOnly выключает режим только для чтения, в том числе включенный сервисом после ошибок записи в Redis.
+//
+// DisableReadOnly godoc
+//
+//	@Summary		Выключить режим только для чтения
+//	@Description	Выключает режим, включенный оператором или сервисом. Если записи в Redis снова не пройдут, сервис включит режим сам
+//	@Tags			admin
+//	@Produce		json
+//	@Security		AdminToken
+//	@Success		200	{object}	readonly.Status
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Router			/admin/read-only [delete]
+func (s *Handler) DisableReadOnly(c echo.Context) error {
+	if s.readOnly == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "read-only mode is not configured"})
+	}
+
+	return readOnlyChanged(c, "read_only_disable", s.readOnly.Disable())
+}
+
+// readOnlyChanged пишет изменение режима оператором в журнал аудита и отвечает новым состоянием.
+func readOnlyChanged(c echo.Context, audit string, status readonly.Status) error {
+	logrus.WithFields(logrus.Fields{
+		"audit":   audit,
+		"enabled": status.Enabled,
+		"reason":  status.Reason,
+		"ip":      c.RealIP(),
+	}).Warn("read-only mode changed")
+
+	return c.JSON(http.StatusOK, status)
+}
@@ -0,0 +1,93 @@
+package v0
+
+import (
+	"auth-service/internal/service/readonly"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnly(t *testing.T) {
+	t.Parallel()
+
+	mode, err := readonly.New(readonly.WithFailureThreshold(1), readonly.WithRegisterer(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"), WithReadOnly(mode))
+	require.NoError(t, err)
+
+	request := func(handler echo.HandlerFunc, method, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		rec := httptest.NewRecorder()
+		require.NoError(t, handler(echo.New().NewContext(req, rec)))
+
+		return rec
+	}
+
+	status := func(rec *httptest.ResponseRecorder) readonly.Status {
+		var status readonly.Status
+
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&status))
+
+		return status
+	}
+
+	rec := request(h.GetReadOnly, http.MethodGet, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.False(t, status(rec).Enabled)
+
+	rec = request(h.EnableReadOnly, http.MethodPut, `{}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = request(h.EnableReadOnly, http.MethodPut, `{`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = request(h.EnableReadOnly, http.MethodPut, `{"reason":"redis failover"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	got := status(rec)
+	assert.True(t, got.Enabled)
+	assert.Equal(t, readonly.SourceManual, got.Source)
+	assert.Equal(t, "redis failover", got.Reason)
+
+	// экземпляр в режиме только для чтения остается готовым
+	rec = request(h.Ready, http.MethodGet, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var ready readinessResponse
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&ready))
+	assert.Equal(t, "ready", ready.Status)
+	require.NotNil(t, ready.ReadOnly)
+	assert.Equal(t, "redis failover", ready.ReadOnly.Reason)
+
+	rec = request(h.DisableReadOnly, http.MethodDelete, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.False(t, status(rec).Enabled)
+
+	mode.ObserveWrite(errors.New("READONLY You can't write against a read only replica."))
+
+	rec = request(h.GetReadOnly, http.MethodGet, "")
+	assert.Equal(t, readonly.SourceAuto, status(rec).Source)
+
+	mode.ObserveWrite(nil)
+
+	rec = request(h.Ready, http.MethodGet, "")
+	assert.JSONEq(t, `{"status":"ready"}`, rec.Body.String())
+
+	h.readOnly = nil
+
+	for _, handler := range []echo.HandlerFunc{h.GetReadOnly, h.EnableReadOnly, h.DisableReadOnly} {
+		assert.Equal(t, http.StatusNotFound, request(handler, http.MethodGet, "").Code)
+	}
+}
@@ -0,0 +1,168 @@
+package v0
+
+import (
+	"auth-service/internal/service/refresh"
+	"auth-service/internal/service/token"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// refreshRequest - запрос на обновление токена.
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token" form:"refresh_token"`
+}
+
+// RefreshToken меняет refresh токен на новый и выпускает токен доступа.
+//
+// RefreshToken godoc
+//
+//	@Summary		Обновить токен
+//	@Description	Меняет refresh токен на новый того же входа и выпускает токен доступа. Каждый refresh токен действует один раз: повторное использование уже замененного токена считается кражей, и все токены этого входа отзываются - пользователю нужно войти заново. 409 - вход меняли параллельно, токен не заменен и запрос можно повторить
+//	@Tags			token
+//	@Accept			json,x-www-form-urlencoded
+//	@Produce		json
+//	@Param			request	body		refreshRequest	true	"Refresh токен"
+//	@Success		200		{object}	tokenResponse
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401		{object}	errorResponse
+//	@Failure		404		{object}	errorResponse
+//	@Failure		409		{object}	errorResponse
+//	@Failure		503		{object}	errorResponse
+//	@Router			/token/refresh [post]
+func (s *Handler) RefreshToken(c echo.Context) error {
+	if s.refresh == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "refresh tokens are not configured"})
+	}
+
+	var req refreshRequest
+
+	if err := c.Bind(&req); err != nil || req.RefreshToken == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "refresh_token is required"})
+	}
+
+	rotation, err := s.refresh.Rotate(c.Request().Context(), req.RefreshToken)
+
+	switch {
+	case errors.Is(err, refresh.ErrReused):
+		logrus.WithField("ip", c.RealIP()).Warn("refresh token reuse, all tokens of the login are revoked")
+
+		return c.JSON(http.StatusUnauthorized, errorResponse{Error: refresh.ErrInvalidToken.Error()})
+	case errors.Is(err, refresh.ErrInvalidToken):
+		return c.JSON(http.StatusUnauthorized, errorResponse{Error: err.Error()})
+	case errors.Is(err, refresh.ErrConflict):
+		return c.JSON(http.StatusConflict, errorResponse{Error: err.Error()})
+	case err != nil:
+		logrus.WithError(err).Error("error rotate refresh token")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to refresh token"})
+	}
+
+	return c.JSON(http.StatusOK, tokenResponse{
+		AccessToken:  rotation.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresAt:    rotation.Claims.ExpiresAt.Unix(),
+		Scope:        strings.Join(rotation.Claims.Scopes, " "),
+		JTI:          rotation.Claims.ID,
+		RefreshToken: rotation.Refresh.Raw,
+	})
+}
+
+// GetRefreshFamily возвращает семейство refresh токенов одного входа и цепочку обновлений.
+//
+// GetRefreshFamily godoc
+//
+//	@Summary		Цепочка обновлений refresh токена
+//	@Description	Возвращает семейство refresh токенов одного входа: субъекта, срок действия, отметку об отзыве (например, reuse - повторное использование замененного токена) и токены в порядке выпуска с родителем каждого. Сами токены не раскрываются
+//	@Tags			admin
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			id	path		string	true	"ID семейства"
+//	@Success		200	{object}	refresh.Family
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/admin/refresh-families/{id} [get]
+func (s *Handler) GetRefreshFamily(c echo.Context) error {
+	if s.refresh == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "refresh tokens are not configured"})
+	}
+
+	family, err := s.refresh.Family(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, refresh.ErrNotFound) {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: err.Error()})
+	}
+
+	if err != nil {
+		logrus.WithError(err).Error("error get refresh token family")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to get refresh token family"})
+	}
+
+	return c.JSON(http.StatusOK, family)
+}
+
+// RevokeRefreshFamily завершает сессию входа: отзывает семейство refresh токенов. Токены доступа
+// этой сессии отклоняются сразу для аудиторий с проверкой сессии (token.session_check), для остальных -
+// после истечения.
+//
+// RevokeRefreshFamily godoc
+//
+//	@Summary		Завершить сессию входа
+//	@Description	Отзывает семейство refresh токенов одного входа (ID семейства совпадает с claim sid токенов доступа входа). Токены семейства больше не обновляются, а токены доступа сессии сразу перестают приниматься аудиториями, для которых включена проверка сессии
+//	@Tags			admin
+//	@Security		AdminToken
+//	@Param			id	path	string	true	"ID семейства"
+//	@Success		204
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/admin/refresh-families/{id} [delete]
+func (s *Handler) RevokeRefreshFamily(c echo.Context) error {
+	if s.refresh == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "refresh tokens are not configured"})
+	}
+
+	err := s.refresh.Revoke(c.Request().Context(), c.Param("id"), refresh.ReasonAdmin)
+	if errors.Is(err, refresh.ErrNotFound) {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: err.Error()})
+	}
+
+	if err != nil {
+		logrus.WithError(err).Error("error revoke refresh token family")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to revoke refresh token family"})
+	}
+
+	logrus.WithField("family", c.Param("id")).Info("session revoked by admin")
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// withRefresh добавляет к ответу входа refresh токен, если они включены, и учитывает вход в статистике.
+// Если выпустить refresh токен не удалось, возвращает ошибку: вход без него клиент не отличил бы
+// от входа с выключенными refresh токенами и не узнал бы, что сессию не продлить.
+func (s *Handler) withRefresh(c echo.Context, resp tokenResponse, claims *token.Claims) (tokenResponse, error) {
+	if s.refresh != nil {
+		tok, err := s.refresh.Issue(c.Request().Context(), claims, device(c))
+		if err != nil {
+			logrus.WithError(err).WithField("subject", claims.Subject).Error("error issue refresh token")
+
+			return tokenResponse{}, err
+		}
+
+		resp.RefreshToken = tok.Raw
+	}
+
+	s.countLogin(c, claims.Subject)
+
+	return resp, nil
+}
+
+// refreshUnavailable - ответ входа, для которого не удалось выпустить refresh токен.
+func refreshUnavailable(c echo.Context) error {
+	return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to issue refresh token"})
+}
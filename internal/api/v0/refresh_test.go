@@ -0,0 +1,275 @@
+package v0
+
+import (
+	"auth-service/internal/service/refresh"
+	"auth-service/internal/service/token"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRefreshHandler(t *testing.T) (*Handler, *miniredis.Miniredis) {
+	t.Helper()
+
+	key := []byte("secret")
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	issuer, err := token.NewIssuer(token.WithSigningKeys(testSigningKeys{key: key}))
+	require.NoError(t, err)
+
+	validator, err := token.NewValidator(token.WithKeys(testKeys{key: key}))
+	require.NoError(t, err)
+
+	svc, err := refresh.New(
+		refresh.WithClient(client),
+		refresh.WithIssuer(issuer),
+		refresh.WithAccessTTL(10*time.Minute),
+		refresh.WithRegisterer(prometheus.NewRegistry()),
+	)
+	require.NoError(t, err)
+
+	h, err := New(
+		WithVersion("1.0.0"),
+		WithBuildDate("2021-01-01"),
+		WithGitCommit("1234567890"),
+		WithValidator(validator),
+		WithIssuer(issuer),
+		WithRefresh(svc),
+	)
+	require.NoError(t, err)
+
+	return h, mr
+}
+
+func getRefreshFamily(t *testing.T, h *Handler, id string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+
+	require.NoError(t, h.GetRefreshFamily(c))
+
+	return rec
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	h, mr := newRefreshHandler(t)
+
+	// вход выдает refresh токен вместе с токеном доступа
+	login, err := h.withRefresh(echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder()),
+		tokenResponse{AccessToken: "access"},
+		&token.Claims{Subject: "user-1", Audience: []string{"telegram-bot"}},
+	)
+	require.NoError(t, err)
+	require.NotEmpty(t, login.RefreshToken)
+
+	rec := callAuthorized(t, h.RefreshToken, "", "", `{}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callAuthorized(t, h.RefreshToken, "", "", `{"refresh_token":"unknown"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = callAuthorized(t, h.RefreshToken, "", "", `{"refresh_token":"`+login.RefreshToken+`"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp tokenResponse
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.NotEmpty(t, resp.RefreshToken)
+	assert.NotEqual(t, login.RefreshToken, resp.RefreshToken)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), time.Unix(resp.ExpiresAt, 0), 2*time.Second)
+
+	claims, err := h.validator.Validate(t.Context(), resp.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+	assert.Equal(t, []string{"telegram-bot"}, claims.Audience)
+
+	// повторное использование замененного токена отзывает все токены входа
+	rec = callAuthorized(t, h.RefreshToken, "", "", `{"refresh_token":"`+login.RefreshToken+`"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = callAuthorized(t, h.RefreshToken, "", "", `{"refresh_token":"`+resp.RefreshToken+`"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// Redis недоступен
+	mr.Close()
+
+	rec = callAuthorized(t, h.RefreshToken, "", "", `{"refresh_token":"`+resp.RefreshToken+`"}`)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	rec = getRefreshFamily(t, h, "family-1")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+// touchingIssuer выпускает токены доступа и при каждом выпуске меняет семейство сессии,
+// как параллельная отметка активности: обновление токена все время конфликтует.
+type touchingIssuer struct {
+	*token.Issuer
+	mr *miniredis.Miniredis
+}
+
+func (i touchingIssuer) Issue(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error) {
+	i.mr.HSet("auth:refresh:{"+req.SessionID+"}:family", "last_seen", "1")
+
+	return i.Issuer.Issue(ctx, req)
+}
+
+func TestRefreshToken_Conflict(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	issuer, err := token.NewIssuer(token.WithSigningKeys(testSigningKeys{key: key}))
+	require.NoError(t, err)
+
+	svc, err := refresh.New(
+		refresh.WithClient(client),
+		refresh.WithIssuer(touchingIssuer{Issuer: issuer, mr: mr}),
+		refresh.WithRegisterer(prometheus.NewRegistry()),
+	)
+	require.NoError(t, err)
+
+	h, err := New(
+		WithVersion("1.0.0"),
+		WithBuildDate("2021-01-01"),
+		WithGitCommit("1234567890"),
+		WithIssuer(issuer),
+		WithRefresh(svc),
+	)
+	require.NoError(t, err)
+
+	login, err := h.withRefresh(echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder()),
+		tokenResponse{AccessToken: "access"},
+		&token.Claims{Subject: "user-1"},
+	)
+	require.NoError(t, err)
+
+	// конфликт не отзывает вход: клиент может повторить запрос с тем же токеном
+	rec := callAuthorized(t, h.RefreshToken, "", "", `{"refresh_token":"`+login.RefreshToken+`"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	family, err := svc.Family(t.Context(), login.RefreshToken[:strings.LastIndex(login.RefreshToken, ".")])
+	require.NoError(t, err)
+	assert.Nil(t, family.RevokedAt)
+}
+
+func TestGetRefreshFamily(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newRefreshHandler(t)
+
+	first, err := h.refresh.Issue(t.Context(), &token.Claims{Subject: "user-1"}, refresh.Device{})
+	require.NoError(t, err)
+
+	second, err := h.refresh.Rotate(t.Context(), first.Raw)
+	require.NoError(t, err)
+
+	rec := getRefreshFamily(t, h, first.Family)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var family refresh.Family
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&family))
+	assert.Equal(t, "user-1", family.Subject)
+	require.Len(t, family.Tokens, 2)
+	assert.Equal(t, first.ID, family.Tokens[1].Parent)
+	assert.Equal(t, second.Refresh.ID, family.Tokens[1].ID)
+
+	rec = getRefreshFamily(t, h, "unknown")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestRevokeRefreshFamily(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newRefreshHandler(t)
+
+	access, claims, err := h.issuer.Issue(t.Context(), token.IssueRequest{
+		Subject: "user-1", Audience: []string{"admin-panel"}, TTL: time.Minute,
+	})
+	require.NoError(t, err)
+
+	first, err := h.refresh.Issue(t.Context(), claims, refresh.Device{})
+	require.NoError(t, err)
+	assert.Equal(t, claims.SessionID, first.Family)
+
+	validator, err := token.NewValidator(
+		token.WithKeys(testKeys{key: []byte("secret")}),
+		token.WithSessionCheck(h.refresh, token.SessionCheck{Audiences: []string{"admin-panel"}}),
+	)
+	require.NoError(t, err)
+
+	_, err = validator.Validate(t.Context(), access)
+	require.NoError(t, err)
+
+	revoke := func(id string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodDelete, "/", nil), rec)
+
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+
+		require.NoError(t, h.RevokeRefreshFamily(c))
+
+		return rec
+	}
+
+	assert.Equal(t, http.StatusNoContent, revoke(first.Family).Code)
+	assert.Equal(t, http.StatusNotFound, revoke("unknown").Code)
+
+	// токен доступа сессии отклоняется до истечения
+	_, err = validator.Validate(t.Context(), access)
+	require.ErrorIs(t, err, token.ErrSessionRevoked)
+
+	_, err = h.refresh.Rotate(t.Context(), first.Raw)
+	require.ErrorIs(t, err, refresh.ErrInvalidToken)
+}
+
+func TestRefreshToken_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	rec := callAuthorized(t, h.RefreshToken, "", "", `{"refresh_token":"token"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = getRefreshFamily(t, h, "family-1")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	require.NoError(t, h.RevokeRefreshFamily(echo.New().NewContext(httptest.NewRequest(http.MethodDelete, "/", nil), rec)))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// без refresh токенов вход выдает только токен доступа
+	resp, err := h.withRefresh(echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder()),
+		tokenResponse{AccessToken: "access"}, &token.Claims{Subject: "user-1"})
+	require.NoError(t, err)
+	assert.Empty(t, resp.RefreshToken)
+}
@@ -0,0 +1,152 @@
+package v0
+
+import (
+	"auth-service/internal/service/job"
+	"auth-service/internal/service/revocation"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// RevokeUserSessions ставит в очередь отзыв всех токенов пользователя и сразу возвращает задание.
+// Токены, выпущенные до постановки задания, перестают приниматься после его выполнения.
+//
+// RevokeUserSessions godoc
+//
+//	@Summary		Отозвать все токены пользователя
+//	@Description	Отзыв выполняется асинхронно. Статус задания - GET /admin/jobs/{id}
+//	@Tags			revocation
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			id	path		string	true	"ID пользователя"
+//	@Success		202	{object}	job.Job
+//	@Failure		400	{object}	errorResponse
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/admin/users/{id}/sessions [delete]
+func (s *Handler) RevokeUserSessions(c echo.Context) error {
+	if s.revocations == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "revocation is not configured"})
+	}
+
+	var (
+		queued *job.Job
+		err    error
+	)
+
+	queued, err = s.revocations.Enqueue(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, revocation.ErrInvalidArgument) {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+	}
+
+	if err != nil {
+		logrus.WithError(err).Error("error enqueue revocation")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to enqueue revocation"})
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"job":     queued.ID,
+		"subject": c.Param("id"),
+	}).Info("user tokens revocation enqueued")
+
+	return c.JSON(http.StatusAccepted, queued)
+}
+
+// DeactivateUser отключает пользователя: сразу отзывает все его токены и публикует событие
+// для других сервисов. Токены отключенного пользователя не принимаются до включения.
+//
+// DeactivateUser godoc
+//
+//	@Summary		Отключить пользователя
+//	@Description	Сразу отзывает все токены пользователя, в том числе выпущенные после отключения, и публикует событие user.deactivated. Повторное отключение возвращает существующее
+//	@Tags			revocation
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			id	path		string	true	"ID пользователя"
+//	@Success		200	{object}	revocation.Deactivation
+//	@Failure		400	{object}	errorResponse
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/admin/users/{id}/deactivation [put]
+func (s *Handler) DeactivateUser(c echo.Context) error {
+	if s.revocations == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "revocation is not configured"})
+	}
+
+	d, err := s.revocations.Deactivate(c.Request().Context(), c.Param("id"), "admin")
+	if errors.Is(err, revocation.ErrInvalidArgument) {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+	}
+
+	if err != nil {
+		logrus.WithError(err).Error("error deactivate user")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to deactivate user"})
+	}
+
+	return c.JSON(http.StatusOK, d)
+}
+
+// ReactivateUser снова включает пользователя. Токены, выпущенные до включения, остаются отозванными.
+//
+// ReactivateUser godoc
+//
+//	@Summary		Включить пользователя
+//	@Description	Снимает отключение и публикует событие user.reactivated. Токены, выпущенные до включения, остаются отозванными
+//	@Tags			revocation
+//	@Security		AdminToken
+//	@Param			id	path	string	true	"ID пользователя"
+//	@Success		204
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/admin/users/{id}/deactivation [delete]
+func (s *Handler) ReactivateUser(c echo.Context) error {
+	if s.revocations == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "revocation is not configured"})
+	}
+
+	if err := s.revocations.Reactivate(c.Request().Context(), c.Param("id"), "admin"); err != nil {
+		logrus.WithError(err).Error("error reactivate user")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to reactivate user"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CheckDeactivations ставит в очередь проверку согласованности отключенных пользователей.
+//
+// CheckDeactivations godoc
+//
+//	@Summary		Проверить отключенных пользователей
+//	@Description	Ищет пользователей, отключенных в SCIM, токены которых еще принимаются, отключает их и допубликовывает потерянные события. Проверка выполняется асинхронно, статус задания - GET /admin/jobs/{id}
+//	@Tags			revocation
+//	@Produce		json
+//	@Security		AdminToken
+//	@Success		202	{object}	job.Job
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/admin/deactivations/check [post]
+func (s *Handler) CheckDeactivations(c echo.Context) error {
+	if s.revocations == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "revocation is not configured"})
+	}
+
+	queued, err := s.revocations.EnqueueCheck(c.Request().Context())
+	if err != nil {
+		logrus.WithError(err).Error("error enqueue deactivation check")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to enqueue deactivation check"})
+	}
+
+	logrus.WithField("job", queued.ID).Info("deactivation check enqueued")
+
+	return c.JSON(http.StatusAccepted, queued)
+}
@@ -0,0 +1,149 @@
+package v0
+
+import (
+	"auth-service/internal/service/job"
+	"auth-service/internal/service/revocation"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRevocationHandler(t *testing.T) (*Handler, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	jobs, err := job.New(job.WithClient(client), job.WithConsumer("test"))
+	require.NoError(t, err)
+
+	svc, err := revocation.New(revocation.WithClient(client), revocation.WithJobs(jobs))
+	require.NoError(t, err)
+
+	h, err := New(
+		WithVersion("1.0.0"),
+		WithBuildDate("2021-01-01"),
+		WithGitCommit("1234567890"),
+		WithJobs(jobs),
+		WithRevocations(svc),
+	)
+	require.NoError(t, err)
+
+	return h, mr
+}
+
+func TestRevokeUserSessions(t *testing.T) {
+	t.Parallel()
+
+	h, mr := newRevocationHandler(t)
+
+	rec := callGroups(t, h.RevokeUserSessions, http.MethodDelete, "/", "", map[string]string{"id": "user-1"})
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	var queued job.Job
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&queued))
+	assert.Equal(t, revocation.JobType, queued.Type)
+	assert.Equal(t, "user-1", queued.Params["subject"])
+	assert.Equal(t, job.StatusQueued, queued.Status)
+
+	rec = callGroups(t, h.GetJob, http.MethodGet, "/", "", map[string]string{"id": queued.ID})
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var got job.Job
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	assert.Equal(t, queued.ID, got.ID)
+
+	rec = callGroups(t, h.RevokeUserSessions, http.MethodDelete, "/", "", map[string]string{"id": ""})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// Redis недоступен
+	mr.Close()
+
+	rec = callGroups(t, h.RevokeUserSessions, http.MethodDelete, "/", "", map[string]string{"id": "user-1"})
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+}
+
+func TestRevokeUserSessions_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	rec := callGroups(t, h.RevokeUserSessions, http.MethodDelete, "/", "", map[string]string{"id": "user-1"})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestDeactivateUser(t *testing.T) {
+	t.Parallel()
+
+	h, mr := newRevocationHandler(t)
+
+	rec := callGroups(t, h.DeactivateUser, http.MethodPut, "/", "", map[string]string{"id": "user-1"})
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var d revocation.Deactivation
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&d))
+	assert.Equal(t, "user-1", d.Subject)
+	assert.Equal(t, "admin", d.Source)
+
+	rec = callGroups(t, h.DeactivateUser, http.MethodPut, "/", "", map[string]string{"id": ""})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callGroups(t, h.ReactivateUser, http.MethodDelete, "/", "", map[string]string{"id": "user-1"})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = callGroups(t, h.CheckDeactivations, http.MethodPost, "/", "", nil)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	var queued job.Job
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&queued))
+	assert.Equal(t, revocation.CheckJobType, queued.Type)
+
+	// Redis недоступен
+	mr.Close()
+
+	tests := []struct {
+		name string
+		call func() int
+	}{
+		{name: "deactivate", call: func() int {
+			return callGroups(t, h.DeactivateUser, http.MethodPut, "/", "", map[string]string{"id": "user-1"}).Code
+		}},
+		{name: "reactivate", call: func() int {
+			return callGroups(t, h.ReactivateUser, http.MethodDelete, "/", "", map[string]string{"id": "user-1"}).Code
+		}},
+		{name: "check", call: func() int {
+			return callGroups(t, h.CheckDeactivations, http.MethodPost, "/", "", nil).Code
+		}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, http.StatusServiceUnavailable, tt.call(), tt.name)
+	}
+}
+
+func TestDeactivateUser_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	for _, fn := range []func(c echo.Context) error{h.DeactivateUser, h.ReactivateUser, h.CheckDeactivations} {
+		rec := callGroups(t, fn, http.MethodPut, "/", "", map[string]string{"id": "user-1"})
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
@@ -0,0 +1,328 @@
+package v0
+
+import (
+	"auth-service/internal/service/scim"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// scimContentType - тип содержимого SCIM (RFC 7644, раздел 8.1).
+const scimContentType = "application/scim+json"
+
+// scimMaxBodySize - максимальный размер тела запроса SCIM.
+const scimMaxBodySize = 64 << 10
+
+// scimError - ошибка в формате SCIM (RFC 7644, раздел 3.12).
+type scimError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+func scimJSON(c echo.Context, code int, v any) error {
+	c.Response().Header().Set(echo.HeaderContentType, scimContentType)
+
+	return c.JSON(code, v)
+}
+
+func scimErrorJSON(c echo.Context, code int, scimType, detail string) error {
+	return scimJSON(c, code, scimError{
+		Schemas:  []string{scim.SchemaError},
+		Status:   strconv.Itoa(code),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+// scimFail отвечает ошибкой сервиса SCIM.
+func scimFail(c echo.Context, err error, msg string) error {
+	switch {
+	case errors.Is(err, scim.ErrNotFound):
+		return scimErrorJSON(c, http.StatusNotFound, "", err.Error())
+	case errors.Is(err, scim.ErrUniqueness):
+		return scimErrorJSON(c, http.StatusConflict, "uniqueness", err.Error())
+	case errors.Is(err, scim.ErrInvalidFilter):
+		return scimErrorJSON(c, http.StatusBadRequest, "invalidFilter", err.Error())
+	case errors.Is(err, scim.ErrInvalidPath):
+		return scimErrorJSON(c, http.StatusBadRequest, "invalidPath", err.Error())
+	case errors.Is(err, scim.ErrMutability):
+		return scimErrorJSON(c, http.StatusBadRequest, "mutability", err.Error())
+	case errors.Is(err, scim.ErrInvalidValue):
+		return scimErrorJSON(c, http.StatusBadRequest, "invalidValue", err.Error())
+	}
+
+	logrus.WithError(err).Error(msg)
+
+	return scimErrorJSON(c, http.StatusServiceUnavailable, "", msg)
+}
+
+// scimBody читает тело запроса. Echo не разбирает application/scim+json, поэтому тело читается напрямую.
+func scimBody(c echo.Context) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(c.Request().Body, scimMaxBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", scim.ErrInvalidValue, err)
+	}
+
+	if len(data) > scimMaxBodySize {
+		return nil, fmt.Errorf("%w: body is too large", scim.ErrInvalidValue)
+	}
+
+	return data, nil
+}
+
+func logSCIMUser(u *scim.User, msg string) {
+	logrus.WithFields(logrus.Fields{
+		"scim_id":     u.ID,
+		"username":    u.UserName,
+		"external_id": u.ExternalID,
+		"active":      u.Active,
+	}).Info(msg)
+}
+
+// CreateSCIMUser заводит учетную запись администратора.
+//
+// CreateSCIMUser godoc
+//
+//	@Summary		Создание пользователя SCIM
+//	@Description	Заводит учетную запись администратора по запросу корпоративного IdP (SCIM 2.0). Если active не передан, учетная запись активна. Вход администратора через каталог разрешен только активным учетным записям
+//	@Tags			scim
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		scim.User	true	"Пользователь"
+//	@Success		201		{object}	scim.User
+//	@Failure		400		{object}	scimError
+//	@Failure		401		{object}	errorResponse
+//	@Failure		404		{object}	scimError
+//	@Failure		409		{object}	scimError
+//	@Failure		503		{object}	scimError
+//	@Router			/scim/v2/Users [post]
+func (s *Handler) CreateSCIMUser(c echo.Context) error {
+	if s.scim == nil {
+		return scimErrorJSON(c, http.StatusNotFound, "", "scim is not configured")
+	}
+
+	data, err := scimBody(c)
+	if err != nil {
+		return scimFail(c, err, "")
+	}
+
+	u, err := scim.ParseUser(data)
+	if err != nil {
+		return scimFail(c, err, "")
+	}
+
+	created, err := s.scim.Create(c.Request().Context(), u)
+	if err != nil {
+		return scimFail(c, err, "unable to create user")
+	}
+
+	logSCIMUser(created, "scim user created")
+
+	c.Response().Header().Set(echo.HeaderLocation, c.Request().URL.Path+"/"+created.ID)
+
+	return scimJSON(c, http.StatusCreated, created)
+}
+
+// ListSCIMUsers ищет учетные записи администраторов.
+//
+// ListSCIMUsers godoc
+//
+//	@Summary		Поиск пользователей SCIM
+//	@Description	Возвращает учетные записи администраторов. Поддерживаются фильтры userName eq "..." и externalId eq "..."
+//	@Tags			scim
+//	@Produce		json
+//	@Param			filter		query		string	false	"Фильтр"
+//	@Param			startIndex	query		int		false	"Номер первой записи, начиная с 1"
+//	@Param			count		query		int		false	"Размер страницы"
+//	@Success		200			{object}	scim.ListResponse
+//	@Failure		400			{object}	scimError
+//	@Failure		401			{object}	errorResponse
+//	@Failure		404			{object}	scimError
+//	@Failure		503			{object}	scimError
+//	@Router			/scim/v2/Users [get]
+func (s *Handler) ListSCIMUsers(c echo.Context) error {
+	if s.scim == nil {
+		return scimErrorJSON(c, http.StatusNotFound, "", "scim is not configured")
+	}
+
+	startIndex, err := scimIntParam(c, "startIndex")
+	if err != nil {
+		return scimFail(c, err, "")
+	}
+
+	count, err := scimIntParam(c, "count")
+	if err != nil {
+		return scimFail(c, err, "")
+	}
+
+	resp, err := s.scim.List(c.Request().Context(), c.QueryParam("filter"), startIndex, count)
+	if err != nil {
+		return scimFail(c, err, "unable to list users")
+	}
+
+	return scimJSON(c, http.StatusOK, resp)
+}
+
+func scimIntParam(c echo.Context, name string) (int, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return 0, nil
+	}
+
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s must be an integer", scim.ErrInvalidValue, name)
+	}
+
+	return v, nil
+}
+
+// GetSCIMUser возвращает учетную запись администратора.
+//
+// GetSCIMUser godoc
+//
+//	@Summary		Пользователь SCIM
+//	@Description	Возвращает учетную запись администратора по ID
+//	@Tags			scim
+//	@Produce		json
+//	@Param			id	path		string	true	"ID пользователя"
+//	@Success		200	{object}	scim.User
+//	@Failure		401	{object}	errorResponse
+//	@Failure		404	{object}	scimError
+//	@Failure		503	{object}	scimError
+//	@Router			/scim/v2/Users/{id} [get]
+func (s *Handler) GetSCIMUser(c echo.Context) error {
+	if s.scim == nil {
+		return scimErrorJSON(c, http.StatusNotFound, "", "scim is not configured")
+	}
+
+	u, err := s.scim.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return scimFail(c, err, "unable to get user")
+	}
+
+	return scimJSON(c, http.StatusOK, u)
+}
+
+// ReplaceSCIMUser заменяет учетную запись администратора.
+//
+// ReplaceSCIMUser godoc
+//
+//	@Summary		Замена пользователя SCIM
+//	@Description	Заменяет атрибуты учетной записи администратора. Если active не передан, учетная запись активна
+//	@Tags			scim
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string		true	"ID пользователя"
+//	@Param			request	body		scim.User	true	"Пользователь"
+//	@Success		200		{object}	scim.User
+//	@Failure		400		{object}	scimError
+//	@Failure		401		{object}	errorResponse
+//	@Failure		404		{object}	scimError
+//	@Failure		409		{object}	scimError
+//	@Failure		503		{object}	scimError
+//	@Router			/scim/v2/Users/{id} [put]
+func (s *Handler) ReplaceSCIMUser(c echo.Context) error {
+	if s.scim == nil {
+		return scimErrorJSON(c, http.StatusNotFound, "", "scim is not configured")
+	}
+
+	data, err := scimBody(c)
+	if err != nil {
+		return scimFail(c, err, "")
+	}
+
+	u, err := scim.ParseUser(data)
+	if err != nil {
+		return scimFail(c, err, "")
+	}
+
+	replaced, err := s.scim.Replace(c.Request().Context(), c.Param("id"), u)
+	if err != nil {
+		return scimFail(c, err, "unable to replace user")
+	}
+
+	logSCIMUser(replaced, "scim user replaced")
+
+	return scimJSON(c, http.StatusOK, replaced)
+}
+
+// PatchSCIMUser изменяет учетную запись администратора, в том числе отключает ее (active=false).
+//
+// PatchSCIMUser godoc
+//
+//	@Summary		Изменение пользователя SCIM
+//	@Description	Применяет операции PATCH (add, replace, remove) к учетной записи администратора. Отключение учетной записи - replace active=false
+//	@Tags			scim
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"ID пользователя"
+//	@Param			request	body		scim.PatchRequest	true	"Операции"
+//	@Success		200		{object}	scim.User
+//	@Failure		400		{object}	scimError
+//	@Failure		401		{object}	errorResponse
+//	@Failure		404		{object}	scimError
+//	@Failure		409		{object}	scimError
+//	@Failure		503		{object}	scimError
+//	@Router			/scim/v2/Users/{id} [patch]
+func (s *Handler) PatchSCIMUser(c echo.Context) error {
+	if s.scim == nil {
+		return scimErrorJSON(c, http.StatusNotFound, "", "scim is not configured")
+	}
+
+	data, err := scimBody(c)
+	if err != nil {
+		return scimFail(c, err, "")
+	}
+
+	var req scim.PatchRequest
+
+	if err := json.Unmarshal(data, &req); err != nil {
+		return scimErrorJSON(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+	}
+
+	patched, err := s.scim.Patch(c.Request().Context(), c.Param("id"), req)
+	if err != nil {
+		return scimFail(c, err, "unable to patch user")
+	}
+
+	logSCIMUser(patched, "scim user patched")
+
+	return scimJSON(c, http.StatusOK, patched)
+}
+
+// DeleteSCIMUser удаляет учетную запись администратора.
+//
+// DeleteSCIMUser godoc
+//
+//	@Summary		Удаление пользователя SCIM
+//	@Description	Удаляет учетную запись администратора. После удаления вход через каталог для этого пользователя запрещен
+//	@Tags			scim
+//	@Param			id	path	string	true	"ID пользователя"
+//	@Success		204
+//	@Failure		401	{object}	errorResponse
+//	@Failure		404	{object}	scimError
+//	@Failure		503	{object}	scimError
+//	@Router			/scim/v2/Users/{id} [delete]
+func (s *Handler) DeleteSCIMUser(c echo.Context) error {
+	if s.scim == nil {
+		return scimErrorJSON(c, http.StatusNotFound, "", "scim is not configured")
+	}
+
+	deleted, err := s.scim.Delete(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return scimFail(c, err, "unable to delete user")
+	}
+
+	logSCIMUser(deleted, "scim user deleted")
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,231 @@
+package v0
+
+import (
+	"auth-service/internal/service/scim"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSCIMHandler(t *testing.T) (*Handler, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	t.Cleanup(func() { _ = client.Close() })
+
+	svc, err := scim.New(scim.WithClient(client))
+	require.NoError(t, err)
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"), WithSCIM(svc))
+	require.NoError(t, err)
+
+	return h, mr
+}
+
+func callSCIM(t *testing.T, fn echo.HandlerFunc, method, target, userID, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, "application/scim+json")
+
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+
+	c.SetParamNames("id")
+	c.SetParamValues(userID)
+
+	require.NoError(t, fn(c))
+
+	return rec
+}
+
+func decodeSCIMError(t *testing.T, rec *httptest.ResponseRecorder) scimError {
+	t.Helper()
+
+	var resp scimError
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+
+	return resp
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestSCIMUsers(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newSCIMHandler(t)
+
+	rec := callSCIM(t, h.CreateSCIMUser, http.MethodPost, "/api/v0/scim/v2/Users", "",
+		`{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"userName":"alice","externalId":"ext-1"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, scimContentType, rec.Header().Get(echo.HeaderContentType))
+
+	var alice scim.User
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &alice))
+	assert.True(t, alice.Active)
+	assert.Equal(t, "/api/v0/scim/v2/Users/"+alice.ID, rec.Header().Get(echo.HeaderLocation))
+
+	rec = callSCIM(t, h.CreateSCIMUser, http.MethodPost, "/api/v0/scim/v2/Users", "", `{"userName":"ALICE"}`)
+	require.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, scimError{
+		Schemas:  []string{scim.SchemaError},
+		Status:   "409",
+		ScimType: "uniqueness",
+		Detail:   scim.ErrUniqueness.Error(),
+	}, decodeSCIMError(t, rec))
+
+	rec = callSCIM(t, h.ListSCIMUsers, http.MethodGet, `/api/v0/scim/v2/Users?filter=userName+eq+%22alice%22`, "", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var list scim.ListResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	assert.Equal(t, 1, list.TotalResults)
+	assert.Equal(t, []scim.User{alice}, list.Resources)
+
+	rec = callSCIM(t, h.PatchSCIMUser, http.MethodPatch, "/", alice.ID,
+		`{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"Replace","path":"active","value":"False"}]}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var patched scim.User
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &patched))
+	assert.False(t, patched.Active)
+
+	rec = callSCIM(t, h.ReplaceSCIMUser, http.MethodPut, "/", alice.ID, `{"userName":"alice","displayName":"Alice"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var replaced scim.User
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &replaced))
+	assert.True(t, replaced.Active)
+	assert.Equal(t, "Alice", replaced.DisplayName)
+
+	rec = callSCIM(t, h.GetSCIMUser, http.MethodGet, "/", alice.ID, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = callSCIM(t, h.DeleteSCIMUser, http.MethodDelete, "/", alice.ID, "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = callSCIM(t, h.GetSCIMUser, http.MethodGet, "/", alice.ID, "")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "404", decodeSCIMError(t, rec).Status)
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestSCIMUsers_Errors(t *testing.T) {
+	t.Parallel()
+
+	notConfigured, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	h, _ := newSCIMHandler(t)
+	unavailable, mr := newSCIMHandler(t)
+
+	mr.Close()
+
+	tests := []struct {
+		name         string
+		handler      *Handler
+		fn           func(h *Handler) echo.HandlerFunc
+		method       string
+		target       string
+		body         string
+		wantStatus   int
+		wantScimType string
+	}{
+		{
+			name:       "error case: not configured",
+			handler:    notConfigured,
+			fn:         func(h *Handler) echo.HandlerFunc { return h.CreateSCIMUser },
+			method:     http.MethodPost,
+			body:       `{"userName":"alice"}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:         "error case: invalid body",
+			handler:      h,
+			fn:           func(h *Handler) echo.HandlerFunc { return h.CreateSCIMUser },
+			method:       http.MethodPost,
+			body:         `{`,
+			wantStatus:   http.StatusBadRequest,
+			wantScimType: "invalidValue",
+		},
+		{
+			name:         "error case: no userName",
+			handler:      h,
+			fn:           func(h *Handler) echo.HandlerFunc { return h.CreateSCIMUser },
+			method:       http.MethodPost,
+			body:         `{"displayName":"Alice"}`,
+			wantStatus:   http.StatusBadRequest,
+			wantScimType: "invalidValue",
+		},
+		{
+			name:         "error case: invalid filter",
+			handler:      h,
+			fn:           func(h *Handler) echo.HandlerFunc { return h.ListSCIMUsers },
+			method:       http.MethodGet,
+			target:       "/?filter=userName+sw+%22a%22",
+			wantStatus:   http.StatusBadRequest,
+			wantScimType: "invalidFilter",
+		},
+		{
+			name:         "error case: invalid count",
+			handler:      h,
+			fn:           func(h *Handler) echo.HandlerFunc { return h.ListSCIMUsers },
+			method:       http.MethodGet,
+			target:       "/?count=ten",
+			wantStatus:   http.StatusBadRequest,
+			wantScimType: "invalidValue",
+		},
+		{
+			name:         "error case: invalid patch syntax",
+			handler:      h,
+			fn:           func(h *Handler) echo.HandlerFunc { return h.PatchSCIMUser },
+			method:       http.MethodPatch,
+			body:         `[]`,
+			wantStatus:   http.StatusBadRequest,
+			wantScimType: "invalidSyntax",
+		},
+		{
+			name:       "error case: patch missing user",
+			handler:    h,
+			fn:         func(h *Handler) echo.HandlerFunc { return h.PatchSCIMUser },
+			method:     http.MethodPatch,
+			body:       `{"Operations":[{"op":"replace","path":"active","value":false}]}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "error case: storage is unavailable",
+			handler:    unavailable,
+			fn:         func(h *Handler) echo.HandlerFunc { return h.CreateSCIMUser },
+			method:     http.MethodPost,
+			body:       `{"userName":"alice"}`,
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			target := tt.target
+			if target == "" {
+				target = "/"
+			}
+
+			rec := callSCIM(t, tt.fn(tt.handler), tt.method, target, "missing", tt.body)
+			require.Equal(t, tt.wantStatus, rec.Code)
+
+			resp := decodeSCIMError(t, rec)
+			assert.Equal(t, []string{scim.SchemaError}, resp.Schemas)
+			assert.Equal(t, tt.wantScimType, resp.ScimType)
+		})
+	}
+}
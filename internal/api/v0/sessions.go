@@ -0,0 +1,116 @@
+package v0
+
+import (
+	"auth-service/internal/service/refresh"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// sessionResponse - сессия входа пользователя.
+type sessionResponse struct {
+	refresh.Session
+	// Current - сессия, которой выпущен токен запроса.
+	Current bool `json:"current"`
+}
+
+// sessionsResponse - действующие сессии пользователя.
+type sessionsResponse struct {
+	Sessions []sessionResponse `json:"sessions"`
+}
+
+// ListSessions возвращает действующие сессии входа пользователя.
+//
+// ListSessions godoc
+//
+//	@Summary		Список сессий
+//	@Description	Действующие сессии входа пользователя, начиная с последней: устройство (User-Agent и IP входа), время входа и срок действия. Сессия - это вход с refresh токеном, current отмечает сессию токена запроса. Токены имперсонации не принимаются
+//	@Tags			account
+//	@Produce		json
+//	@Security		BearerToken
+//	@Success		200	{object}	sessionsResponse
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/sessions [get]
+func (s *Handler) ListSessions(c echo.Context) error {
+	if s.refresh == nil || s.validator == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "sessions are not configured"})
+	}
+
+	claims, err := s.authenticateUser(c)
+	if claims == nil {
+		return err
+	}
+
+	sessions, err := s.refresh.Sessions(c.Request().Context(), claims.Subject)
+	if err != nil {
+		logrus.WithError(err).Error("error list sessions")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to list sessions"})
+	}
+
+	resp := sessionsResponse{Sessions: make([]sessionResponse, 0, len(sessions))}
+
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, sessionResponse{
+			Session: session,
+			Current: claims.SessionID != "" && session.ID == claims.SessionID,
+		})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// RevokeSession завершает сессию входа пользователя.
+//
+// RevokeSession godoc
+//
+//	@Summary		Завершить сессию
+//	@Description	Отзывает refresh токены сессии: они больше не обновляются, а токены доступа сессии сразу перестают приниматься аудиториями, для которых включена проверка сессии. Завершить можно только свою действующую сессию, в том числе текущую. Токены имперсонации не принимаются
+//	@Tags			account
+//	@Security		BearerToken
+//	@Param			id	path	string	true	"ID сессии"
+//	@Success		204
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/sessions/{id} [delete]
+func (s *Handler) RevokeSession(c echo.Context) error {
+	if s.refresh == nil || s.validator == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "sessions are not configured"})
+	}
+
+	claims, err := s.authenticateUser(c)
+	if claims == nil {
+		return err
+	}
+
+	err = s.refresh.RevokeSession(c.Request().Context(), claims.Subject, c.Param("id"), refresh.ReasonUser)
+	if errors.Is(err, refresh.ErrNotFound) {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "session not found"})
+	}
+
+	if err != nil {
+		logrus.WithError(err).Error("error revoke session")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to revoke session"})
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"subject": claims.Subject,
+		"session": c.Param("id"),
+		"ip":      c.RealIP(),
+	}).Info("session revoked by user")
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// device возвращает устройство, с которого пришел запрос входа.
+func device(c echo.Context) refresh.Device {
+	return refresh.Device{UserAgent: c.Request().UserAgent(), IP: c.RealIP()}
+}
@@ -0,0 +1,122 @@
+package v0
+
+import (
+	"auth-service/internal/service/token"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestSessions(t *testing.T) {
+	t.Parallel()
+
+	h, mr := newRefreshHandler(t)
+
+	// login входит пользователем subject с устройства userAgent и возвращает токен доступа и refresh токен
+	login := func(subject, userAgent string) (string, tokenResponse) {
+		access, claims, err := h.issuer.Issue(t.Context(), token.IssueRequest{Subject: subject, TTL: time.Minute})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("User-Agent", userAgent)
+
+		resp, err := h.withRefresh(echo.New().NewContext(req, httptest.NewRecorder()), tokenResponse{AccessToken: access}, claims)
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.RefreshToken)
+
+		return claims.SessionID, resp
+	}
+
+	call := func(fn echo.HandlerFunc, method, auth, id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/", nil)
+		if auth != "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+auth)
+		}
+
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+
+		require.NoError(t, fn(c))
+
+		return rec
+	}
+
+	list := func(auth string) sessionsResponse {
+		rec := call(h.ListSessions, http.MethodGet, auth, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var resp sessionsResponse
+
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+
+		return resp
+	}
+
+	phone, phoneLogin := login("user-1", "Telegram/10.0 (iPhone)")
+	laptop, laptopLogin := login("user-1", "Mozilla/5.0")
+	_, otherLogin := login("user-2", "curl/8.0")
+
+	assert.Equal(t, http.StatusUnauthorized, call(h.ListSessions, http.MethodGet, "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, call(h.ListSessions, http.MethodGet, "invalid", "").Code)
+
+	sessions := list(phoneLogin.AccessToken).Sessions
+	require.Len(t, sessions, 2)
+
+	byID := map[string]sessionResponse{}
+	for _, session := range sessions {
+		byID[session.ID] = session
+	}
+
+	assert.True(t, byID[phone].Current)
+	assert.Equal(t, "Telegram/10.0 (iPhone)", byID[phone].Device.UserAgent)
+	assert.Equal(t, "192.0.2.1", byID[phone].Device.IP)
+	assert.False(t, byID[laptop].Current)
+	assert.Equal(t, "Mozilla/5.0", byID[laptop].Device.UserAgent)
+
+	// чужую сессию завершить нельзя
+	rec := call(h.RevokeSession, http.MethodDelete, otherLogin.AccessToken, laptop)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = call(h.RevokeSession, http.MethodDelete, phoneLogin.AccessToken, laptop)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = call(h.RevokeSession, http.MethodDelete, phoneLogin.AccessToken, laptop)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	sessions = list(phoneLogin.AccessToken).Sessions
+	require.Len(t, sessions, 1)
+	assert.Equal(t, phone, sessions[0].ID)
+
+	// refresh токен завершенной сессии больше не обновляется
+	rec = callAuthorized(t, h.RefreshToken, "", "", `{"refresh_token":"`+laptopLogin.RefreshToken+`"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	mr.Close()
+
+	assert.Equal(t, http.StatusServiceUnavailable, call(h.ListSessions, http.MethodGet, phoneLogin.AccessToken, "").Code)
+	assert.Equal(t, http.StatusServiceUnavailable, call(h.RevokeSession, http.MethodDelete, phoneLogin.AccessToken, phone).Code)
+}
+
+func TestSessions_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	for _, fn := range []echo.HandlerFunc{h.ListSessions, h.RevokeSession} {
+		rec := httptest.NewRecorder()
+
+		require.NoError(t, fn(echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
@@ -0,0 +1,72 @@
+package v0
+
+import (
+	"auth-service/internal/service/stats"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultStatsDays - период статистики по умолчанию.
+const defaultStatsDays = 7
+
+// Stats возвращает агрегированную статистику входов, сессий и выпуска токенов.
+//
+// Stats godoc
+//
+//	@Summary		Статистика для продуктовой аналитики
+//	@Description	Количество входов и уникальных пользователей по дням (UTC), выпущенные токены по аудиториям и количество активных сессий на момент запроса. Уникальные пользователи считаются приблизительно (HyperLogLog, погрешность около 1%). Сессии учитываются, если включены refresh токены
+//	@Tags			admin
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			days	query		int	false	"Количество дней, включая текущий (по умолчанию 7, не больше срока хранения)"
+//	@Success		200		{object}	stats.Summary
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/admin/stats [get]
+func (s *Handler) Stats(c echo.Context) error {
+	if s.stats == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "statistics are not configured"})
+	}
+
+	// по умолчанию период не длиннее срока хранения, явно заданный слишком длинный период - ошибка
+	days := min(defaultStatsDays, s.stats.MaxDays())
+
+	if raw := c.QueryParam("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid days"})
+		}
+
+		days = n
+	}
+
+	summary, err := s.stats.Summary(c.Request().Context(), days)
+	if errors.Is(err, stats.ErrInvalidPeriod) {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+	}
+
+	if err != nil {
+		logrus.WithError(err).Error("error get statistics")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to get statistics"})
+	}
+
+	return c.JSON(http.StatusOK, summary)
+}
+
+// countLogin учитывает вход в статистике. Ошибка учета не отменяет вход.
+func (s *Handler) countLogin(c echo.Context, subject string) {
+	if s.stats == nil {
+		return
+	}
+
+	if err := s.stats.Login(c.Request().Context(), subject); err != nil {
+		logrus.WithError(err).WithField("subject", subject).Warn("error count login")
+	}
+}
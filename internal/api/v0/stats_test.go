@@ -0,0 +1,93 @@
+package v0
+
+import (
+	"auth-service/internal/service/stats"
+	"auth-service/internal/service/token"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStatsHandler(t *testing.T) *Handler {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	svc, err := stats.New(stats.WithClient(client), stats.WithRetention(30*24*time.Hour))
+	require.NoError(t, err)
+
+	h, err := New(
+		WithVersion("1.0.0"),
+		WithBuildDate("2021-01-01"),
+		WithGitCommit("1234567890"),
+		WithStats(svc),
+	)
+	require.NoError(t, err)
+
+	return h
+}
+
+func getStats(t *testing.T, h *Handler, query string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/"+query, nil), rec)
+
+	require.NoError(t, h.Stats(c))
+
+	return rec
+}
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	h := newStatsHandler(t)
+
+	// вход учитывается при выдаче токенов входа
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+	for range 2 {
+		_, err := h.withRefresh(c, tokenResponse{AccessToken: "access"}, &token.Claims{Subject: "user-1"})
+		require.NoError(t, err)
+	}
+
+	rec := getStats(t, h, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var summary stats.Summary
+
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &summary))
+	assert.Len(t, summary.Days, defaultStatsDays)
+	assert.Equal(t, int64(2), summary.Logins)
+	assert.Equal(t, int64(1), summary.UniqueUsers)
+
+	rec = getStats(t, h, "?days=30")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &summary))
+	assert.Len(t, summary.Days, 30)
+}
+
+func TestStats_Errors(t *testing.T) {
+	t.Parallel()
+
+	h := newStatsHandler(t)
+
+	assert.Equal(t, http.StatusBadRequest, getStats(t, h, "?days=week").Code)
+	assert.Equal(t, http.StatusBadRequest, getStats(t, h, "?days=0").Code)
+	assert.Equal(t, http.StatusBadRequest, getStats(t, h, "?days=31").Code)
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusNotFound, getStats(t, h, "").Code)
+}
@@ -0,0 +1,164 @@
+package v0
+
+import (
+	"auth-service/internal/service/spiffe"
+	"auth-service/internal/service/token"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// x509SVIDRequest - запрос на X.509-SVID.
+type x509SVIDRequest struct {
+	CSR string `json:"csr"` // CSR в формате PEM, подписанный ключом сервиса
+}
+
+// jwtSVIDRequest - запрос на JWT-SVID.
+type jwtSVIDRequest struct {
+	Audience []string `json:"audience"`
+}
+
+// IssueX509SVID выпускает X.509-SVID (экспериментально).
+//
+// IssueX509SVID godoc
+//
+//	@Summary		Выпустить X.509-SVID
+//	@Description	Подписывает CSR сервиса промежуточным CA из Vault PKI. SPIFFE ID определяется по bootstrap токену и записывается в URI SAN. Экспериментально
+//	@Tags			spiffe
+//	@Accept			json
+//	@Produce		json
+//	@Security		BootstrapToken
+//	@Param			request	body		x509SVIDRequest	true	"CSR"
+//	@Success		200		{object}	spiffe.X509SVID
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401		{object}	errorResponse
+//	@Failure		404		{object}	errorResponse
+//	@Failure		503		{object}	errorResponse
+//	@Router			/svid/x509 [post]
+func (s *Handler) IssueX509SVID(c echo.Context) error {
+	if s.spiffe == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "spiffe is not configured"})
+	}
+
+	var req x509SVIDRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+	}
+
+	svid, err := s.spiffe.IssueX509(c.Request().Context(), bearerToken(c), req.CSR)
+	if err != nil {
+		return svidError(c, err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"spiffe_id":     svid.SPIFFEID,
+		"serial_number": svid.SerialNumber,
+		"expires_at":    svid.ExpiresAt,
+	}).Info("x509 svid issued")
+
+	return c.JSON(http.StatusOK, svid)
+}
+
+// IssueJWTSVID выпускает JWT-SVID (экспериментально).
+//
+// IssueJWTSVID godoc
+//
+//	@Summary		Выпустить JWT-SVID
+//	@Description	Выпускает короткоживущий токен с SPIFFE ID сервиса в sub, подписанный ключом из trust bundle (typ JWT-SVID, не принимается как токен пользователя). SPIFFE ID определяется по bootstrap токену, аудитории должны быть разрешены в spiffe.jwt_audiences. Если запрос пришел по mTLS с проверенным клиентским сертификатом, токен привязывается к нему (cnf.x5t#S256, RFC 8705). Экспериментально
+//	@Tags			spiffe
+//	@Accept			json
+//	@Produce		json
+//	@Security		BootstrapToken
+//	@Param			request	body		jwtSVIDRequest	true	"Аудитории"
+//	@Success		200		{object}	spiffe.JWTSVID
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401		{object}	errorResponse
+//	@Failure		404		{object}	errorResponse
+//	@Failure		503		{object}	errorResponse
+//	@Router			/svid/jwt [post]
+func (s *Handler) IssueJWTSVID(c echo.Context) error {
+	if s.spiffe == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "spiffe is not configured"})
+	}
+
+	var req jwtSVIDRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+	}
+
+	svid, err := s.spiffe.IssueJWT(c.Request().Context(), bearerToken(c), req.Audience, clientCertThumbprint(c))
+	if err != nil {
+		return svidError(c, err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"spiffe_id":  svid.SPIFFEID,
+		"audience":   req.Audience,
+		"bound":      clientCertThumbprint(c) != "",
+		"expires_at": svid.ExpiresAt,
+	}).Info("jwt svid issued")
+
+	return c.JSON(http.StatusOK, svid)
+}
+
+// SVIDBundle возвращает SPIFFE trust bundle с ключом проверки JWT-SVID (экспериментально).
+//
+// SVIDBundle godoc
+//
+//	@Summary		Trust bundle JWT-SVID
+//	@Description	Открытые ключи (JWKS, use=jwt-svid), которыми проверяются JWT-SVID сервиса. Ключи токенов пользователей не публикуются. Экспериментально
+//	@Tags			spiffe
+//	@Produce		json
+//	@Success		200	{object}	spiffe.Bundle
+//	@Failure		404	{object}	errorResponse
+//	@Router			/svid/bundle [get]
+func (s *Handler) SVIDBundle(c echo.Context) error {
+	if s.spiffe == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "spiffe is not configured"})
+	}
+
+	bundle, err := s.spiffe.Bundle()
+	if err != nil {
+		return svidError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, bundle)
+}
+
+// clientCertThumbprint возвращает отпечаток проверенного клиентского сертификата mTLS или пустую строку.
+func clientCertThumbprint(c echo.Context) string {
+	return token.PeerThumbprint(c.Request().TLS)
+}
+
+// bearerToken возвращает токен из заголовка Authorization: Bearer <токен>.
+func bearerToken(c echo.Context) string {
+	header := c.Request().Header.Get(echo.HeaderAuthorization)
+
+	if len(header) > len("Bearer ") && strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return header[len("Bearer "):]
+	}
+
+	return ""
+}
+
+func svidError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, spiffe.ErrUnauthorized):
+		logrus.WithField("ip", c.RealIP()).Warn("svid request with invalid bootstrap token")
+
+		return c.JSON(http.StatusUnauthorized, errorResponse{Error: err.Error()})
+	case errors.Is(err, spiffe.ErrInvalidArgument):
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+	case errors.Is(err, spiffe.ErrNotConfigured):
+		return c.JSON(http.StatusNotFound, errorResponse{Error: err.Error()})
+	default:
+		logrus.WithError(err).Error("error issue svid")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "svid issuance is unavailable"})
+	}
+}
@@ -0,0 +1,326 @@
+package v0
+
+import (
+	"auth-service/internal/service/spiffe"
+	"auth-service/internal/service/token"
+	"auth-service/internal/storage/vault"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSigner - подпись CSR для тестов.
+type testSigner struct {
+	err error
+}
+
+func (s testSigner) SignCertificate(_ context.Context, _ string, req vault.SignRequest) (*vault.Certificate, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return &vault.Certificate{Certificate: "CERT for " + req.URISANs[0], IssuingCA: "CA"}, nil
+}
+
+func newSPIFFE(t *testing.T, opts ...spiffe.Option) *spiffe.Service {
+	t.Helper()
+
+	sum := sha256.Sum256([]byte("bootstrap"))
+
+	svc, err := spiffe.New(append([]spiffe.Option{
+		spiffe.WithTrustDomain("example.org"),
+		spiffe.WithWorkloads([]spiffe.Workload{{Path: "/bot", TokenSHA256: hex.EncodeToString(sum[:])}}),
+	}, opts...)...)
+	require.NoError(t, err)
+
+	return svc
+}
+
+// newJWTSPIFFE создает выпуск JWT-SVID для аудитории notes и возвращает его ключ подписи.
+func newJWTSPIFFE(t *testing.T) (*spiffe.Service, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return newSPIFFE(t, spiffe.WithJWTKey(key), spiffe.WithJWTAudiences([]string{"notes"})), key
+}
+
+// parseSVID проверяет подпись JWT-SVID ключом key и возвращает его claims.
+func parseSVID(t *testing.T, raw string, key *ecdsa.PrivateKey) jwt.MapClaims {
+	t.Helper()
+
+	claims := jwt.MapClaims{}
+
+	_, err := jwt.ParseWithClaims(raw, claims, func(*jwt.Token) (interface{}, error) {
+		return key.Public(), nil
+	}, jwt.WithValidMethods([]string{"ES256"}))
+	require.NoError(t, err)
+
+	return claims
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestIssueJWTSVID(t *testing.T) {
+	t.Parallel()
+
+	svc, key := newJWTSPIFFE(t)
+
+	tests := []struct {
+		name       string
+		svc        *spiffe.Service
+		auth       string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "not configured",
+			auth:       "Bearer bootstrap",
+			body:       `{"audience":["notes"]}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "without token",
+			svc:        svc,
+			body:       `{"audience":["notes"]}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid token",
+			svc:        svc,
+			auth:       "Bearer other",
+			body:       `{"audience":["notes"]}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "without audience",
+			svc:        svc,
+			auth:       "Bearer bootstrap",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "audience is not allowed",
+			svc:        svc,
+			auth:       "Bearer bootstrap",
+			body:       `{"audience":["admin"]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "positive case",
+			svc:        svc,
+			auth:       "Bearer bootstrap",
+			body:       `{"audience":["notes"]}`,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h, err := New(WithVersion("1"), WithBuildDate("2021-01-01"), WithGitCommit("1"), WithSPIFFE(tt.svc))
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v0/svid/jwt", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			if tt.auth != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.auth)
+			}
+
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, h.IssueJWTSVID(echo.New().NewContext(req, rec)))
+			require.Equal(t, tt.wantStatus, rec.Code)
+
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var svid spiffe.JWTSVID
+
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&svid))
+
+			claims := parseSVID(t, svid.Token, key)
+			assert.Equal(t, "spiffe://example.org/bot", claims["sub"])
+			assert.Equal(t, []interface{}{"notes"}, claims["aud"])
+
+			// SVID не принимается как токен пользователя
+			v, err := token.NewValidator(token.WithKeys(testKeys{key: []byte("secret")}))
+			require.NoError(t, err)
+
+			_, err = v.Validate(t.Context(), svid.Token)
+			require.ErrorIs(t, err, token.ErrInvalidToken)
+		})
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestIssueX509SVID(t *testing.T) {
+	t.Parallel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, key)
+	require.NoError(t, err)
+
+	jwtOnly, _ := newJWTSPIFFE(t)
+
+	body, err := json.Marshal(x509SVIDRequest{CSR: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		svc        *spiffe.Service
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "positive case",
+			svc:        newSPIFFE(t, spiffe.WithX509Signer(testSigner{}, "pki/sign/spiffe")),
+			body:       string(body),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "vault error",
+			svc:        newSPIFFE(t, spiffe.WithX509Signer(testSigner{err: errors.New("vault is down")}, "pki/sign/spiffe")),
+			body:       string(body),
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "invalid csr",
+			svc:        newSPIFFE(t, spiffe.WithX509Signer(testSigner{}, "pki/sign/spiffe")),
+			body:       `{"csr":"not a csr"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "jwt only",
+			svc:        jwtOnly,
+			body:       `{"csr":"not a csr"}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "invalid body",
+			svc:        newSPIFFE(t, spiffe.WithX509Signer(testSigner{err: errors.New("vault is down")}, "pki/sign/spiffe")),
+			body:       `{"csr":`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h, err := New(WithVersion("1"), WithBuildDate("2021-01-01"), WithGitCommit("1"), WithSPIFFE(tt.svc))
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v0/svid/x509", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer bootstrap")
+
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, h.IssueX509SVID(echo.New().NewContext(req, rec)))
+			require.Equal(t, tt.wantStatus, rec.Code)
+
+			if tt.wantStatus == http.StatusOK {
+				var svid spiffe.X509SVID
+
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&svid))
+				assert.Equal(t, "CERT for spiffe://example.org/bot", svid.Certificate)
+				assert.Equal(t, []string{"CA"}, svid.Bundle)
+			}
+		})
+	}
+}
+
+func TestIssueJWTSVID_Bound(t *testing.T) {
+	t.Parallel()
+
+	svc, key := newJWTSPIFFE(t)
+
+	h, err := New(WithVersion("1"), WithBuildDate("2021-01-01"), WithGitCommit("1"), WithSPIFFE(svc))
+	require.NoError(t, err)
+
+	cert := &x509.Certificate{Raw: []byte("client certificate")}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v0/svid/jwt", strings.NewReader(`{"audience":["notes"]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer bootstrap")
+	req.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert}},
+	}
+
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, h.IssueJWTSVID(echo.New().NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var svid spiffe.JWTSVID
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&svid))
+
+	claims := parseSVID(t, svid.Token, key)
+	assert.Equal(t, map[string]interface{}{"x5t#S256": token.CertThumbprint(cert)}, claims["cnf"])
+}
+
+func TestSVIDBundle(t *testing.T) {
+	t.Parallel()
+
+	svc, key := newJWTSPIFFE(t)
+
+	for _, tt := range []struct {
+		name       string
+		svc        *spiffe.Service
+		wantStatus int
+	}{
+		{name: "not configured", wantStatus: http.StatusNotFound},
+		{name: "x509 only", svc: newSPIFFE(t, spiffe.WithX509Signer(testSigner{}, "pki/sign/spiffe")), wantStatus: http.StatusNotFound},
+		{name: "positive case", svc: svc, wantStatus: http.StatusOK},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h, err := New(WithVersion("1"), WithBuildDate("2021-01-01"), WithGitCommit("1"), WithSPIFFE(tt.svc))
+			require.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, h.SVIDBundle(echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/api/v0/svid/bundle", nil), rec)))
+			require.Equal(t, tt.wantStatus, rec.Code)
+
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			// ключ из bundle проверяет выпущенные SVID
+			var bundle jose.JSONWebKeySet
+
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&bundle))
+			require.Len(t, bundle.Keys, 1)
+			assert.Equal(t, "jwt-svid", bundle.Keys[0].Use)
+			assert.True(t, key.PublicKey.Equal(bundle.Keys[0].Key))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package v0
+
+import (
+	"auth-service/internal/service/telegram"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// telegramLoginRequest - данные входа Telegram: initData мини-приложения или поля виджета входа.
+type telegramLoginRequest struct {
+	// InitData - Telegram.WebApp.initData мини-приложения. Если не передан, проверяются поля виджета входа.
+	InitData string `json:"init_data,omitempty"`
+	telegram.WidgetData
+}
+
+// TelegramLogin выдает токен пользователю Telegram по подписанным данным мини-приложения или виджета входа.
+//
+// TelegramLogin godoc
+//
+//	@Summary		Войти через Telegram
+//	@Description	Принимает initData мини-приложения (поле init_data) или данные виджета входа (id, first_name, username, photo_url, auth_date, hash), проверяет подпись токеном бота и срок auth_date и выдает токен пользователю с этим ID в Telegram. Если подключен сервис пользователей, войти может только зарегистрированный в нем пользователь
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		telegramLoginRequest	true	"Данные входа Telegram"
+//	@Success		200		{object}	tokenResponse
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401		{object}	errorResponse
+//	@Failure		403		{object}	errorResponse
+//	@Failure		404		{object}	errorResponse
+//	@Failure		503		{object}	errorResponse
+//	@Router			/auth/telegram [post]
+func (s *Handler) TelegramLogin(c echo.Context) error {
+	if s.telegram == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "telegram login is not configured"})
+	}
+
+	var req telegramLoginRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+	}
+
+	var (
+		login *telegram.Login
+		err   error
+	)
+
+	if req.InitData != "" {
+		login, err = s.telegram.LoginInitData(c.Request().Context(), req.InitData)
+	} else {
+		login, err = s.telegram.LoginWidget(c.Request().Context(), req.WidgetData)
+	}
+
+	switch {
+	case errors.Is(err, telegram.ErrInvalidArgument):
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: telegram.ErrInvalidArgument.Error()})
+	case errors.Is(err, telegram.ErrInvalidSignature), errors.Is(err, telegram.ErrExpired):
+		logrus.WithError(err).WithField("ip", c.RealIP()).Warn("telegram login rejected")
+
+		return c.JSON(http.StatusUnauthorized, errorResponse{Error: err.Error()})
+	case errors.Is(err, telegram.ErrUserNotFound), errors.Is(err, telegram.ErrUserDisabled):
+		return c.JSON(http.StatusForbidden, errorResponse{Error: err.Error()})
+	case err != nil:
+		logrus.WithError(err).Error("error telegram login")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to complete telegram login"})
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"subject":     login.Claims.Subject,
+		"telegram_id": login.User.ID,
+		"jti":         login.Claims.ID,
+		"ip":          c.RealIP(),
+	}).Info("telegram login completed")
+
+	resp, err := s.withRefresh(c, tokenResponse{
+		AccessToken: login.Token,
+		TokenType:   "Bearer",
+		ExpiresAt:   login.Claims.ExpiresAt.Unix(),
+		Scope:       strings.Join(login.Claims.Scopes, " "),
+		JTI:         login.Claims.ID,
+	}, login.Claims)
+	if err != nil {
+		return refreshUnavailable(c)
+	}
+
+	s.notifyLogin(c, login.Claims.Subject, "telegram")
+
+	return c.JSON(http.StatusOK, resp)
+}
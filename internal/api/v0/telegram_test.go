@@ -0,0 +1,174 @@
+package v0
+
+import (
+	"auth-service/internal/service/refresh"
+	"auth-service/internal/service/telegram"
+	"auth-service/internal/service/token"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTelegramKeys - ключ проверки initData из токена бота, ключ виджета в тесте не нужен.
+type testTelegramKeys struct {
+	secretKey []byte
+}
+
+func (k testTelegramKeys) SecretKey() []byte { return k.secretKey }
+
+func (k testTelegramKeys) WidgetKey() []byte { return nil }
+
+// telegramInitData возвращает initData пользователя 42, подписанный ключом key.
+func telegramInitData(key []byte, authDate time.Time) string {
+	values := url.Values{
+		"user":      {`{"id":42,"first_name":"Ivan"}`},
+		"auth_date": {strconv.FormatInt(authDate.Unix(), 10)},
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("auth_date=" + values.Get("auth_date") + "\nuser=" + values.Get("user")))
+	values.Set("hash", hex.EncodeToString(mac.Sum(nil)))
+
+	return values.Encode()
+}
+
+func newTelegramHandler(t *testing.T, key []byte) (*Handler, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	issuer, err := token.NewIssuer(token.WithSigningKeys(testSigningKeys{key: []byte("secret")}))
+	require.NoError(t, err)
+
+	refreshTokens, err := refresh.New(
+		refresh.WithClient(client),
+		refresh.WithIssuer(issuer),
+		refresh.WithRegisterer(prometheus.NewRegistry()),
+	)
+	require.NoError(t, err)
+
+	authenticator, err := telegram.NewAuthenticator(
+		telegram.WithKeys(testTelegramKeys{secretKey: key}),
+		telegram.WithIssuer(issuer),
+		telegram.WithToken(time.Hour, []string{"telegram-bot"}),
+	)
+	require.NoError(t, err)
+
+	h, err := New(
+		WithVersion("1.0.0"),
+		WithBuildDate("2021-01-01"),
+		WithGitCommit("1234567890"),
+		WithIssuer(issuer),
+		WithRefresh(refreshTokens),
+		WithTelegram(authenticator),
+	)
+	require.NoError(t, err)
+
+	return h, mr
+}
+
+func TestTelegramLogin(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("telegram-secret-key")
+	h, _ := newTelegramHandler(t, key)
+
+	call := func(t *testing.T, h *Handler, body string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		rec := httptest.NewRecorder()
+
+		require.NoError(t, h.TelegramLogin(echo.New().NewContext(req, rec)))
+
+		return rec
+	}
+
+	body := func(initData string) string {
+		raw, err := json.Marshal(telegramLoginRequest{InitData: initData})
+		require.NoError(t, err)
+
+		return string(raw)
+	}
+
+	t.Run("positive case", func(t *testing.T) {
+		t.Parallel()
+
+		rec := call(t, h, body(telegramInitData(key, time.Now())))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var resp tokenResponse
+
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.NotEmpty(t, resp.AccessToken)
+		assert.NotEmpty(t, resp.RefreshToken)
+		assert.Equal(t, "Bearer", resp.TokenType)
+
+		// токен доступа содержит проверенный ID пользователя в Telegram
+		v, err := token.NewValidator(token.WithKeys(testKeys{key: []byte("secret")}))
+		require.NoError(t, err)
+
+		claims, err := v.Validate(t.Context(), resp.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), claims.TelegramID)
+	})
+
+	t.Run("negative case: refresh token is not issued", func(t *testing.T) {
+		t.Parallel()
+
+		h, mr := newTelegramHandler(t, key)
+		mr.Close()
+
+		rec := call(t, h, body(telegramInitData(key, time.Now())))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "access_token")
+	})
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{name: "invalid signature", body: body(telegramInitData([]byte("other-bot"), time.Now())), wantCode: http.StatusUnauthorized},
+		{name: "expired", body: body(telegramInitData(key, time.Now().Add(-48*time.Hour))), wantCode: http.StatusUnauthorized},
+		{name: "empty widget data", body: `{}`, wantCode: http.StatusBadRequest},
+		{name: "invalid body", body: `{`, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run("negative case: "+tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.wantCode, call(t, h, tt.body).Code)
+		})
+	}
+
+	t.Run("negative case: not configured", func(t *testing.T) {
+		t.Parallel()
+
+		h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusNotFound, call(t, h, body("")).Code)
+	})
+}
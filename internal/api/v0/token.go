@@ -0,0 +1,278 @@
+package v0
+
+import (
+	"auth-service/internal/service/token"
+	"auth-service/pkg/authclient"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// introspectRequest - запрос на проверку токена.
+type introspectRequest struct {
+	Token string `json:"token" form:"token"`
+	// ClientCertThumbprint - отпечаток (x5t#S256) сертификата, с которым клиент обратился к ресурсу.
+	// Обязателен для токенов, привязанных к сертификату (RFC 8705).
+	ClientCertThumbprint string `json:"client_cert_thumbprint,omitempty" form:"client_cert_thumbprint"`
+}
+
+// revokeRequest - запрос на отзыв токена (RFC 7009).
+type revokeRequest struct {
+	Token string `json:"token" form:"token"`
+	// TokenTypeHint - тип токена по RFC 7009. Сервис отзывает только access токены, подсказка не используется.
+	TokenTypeHint string `json:"token_type_hint,omitempty" form:"token_type_hint"`
+}
+
+// introspectResponse - результат проверки токена в формате RFC 7662.
+// Grace выставляется, если токен уже истек, но принят в режиме мягкой проверки для своей аудитории.
+// Env=sandbox у токенов песочницы.
+type introspectResponse struct {
+	Active    bool              `json:"active"`
+	Issuer    string            `json:"iss,omitempty"`
+	Subject   string            `json:"sub,omitempty"`
+	Audience  []string          `json:"aud,omitempty"`
+	ExpiresAt int64             `json:"exp,omitempty"`
+	IssuedAt  int64             `json:"iat,omitempty"`
+	Kid       string            `json:"kid,omitempty"`
+	JTI       string            `json:"jti,omitempty"`
+	Scope     string            `json:"scope,omitempty"`
+	Act       *actor            `json:"act,omitempty"`
+	Groups    map[string]string `json:"groups,omitempty"`
+	Cnf       *confirmation     `json:"cnf,omitempty"`
+	Env       string            `json:"env,omitempty"`
+	Grace     bool              `json:"grace,omitempty"`
+
+	TelegramID int64    `json:"tg_id,omitempty"`
+	Roles      []string `json:"roles,omitempty"`
+	SessionID  string   `json:"sid,omitempty"`
+	AuthLevel  string   `json:"acr,omitempty"`
+	Tenant     string   `json:"tenant,omitempty"`
+}
+
+// confirmation - claim cnf: сертификат, к которому привязан токен.
+type confirmation struct {
+	X5tS256 string `json:"x5t#S256"`
+}
+
+// actor - claim act: кто действует от имени субъекта токена (для токенов имперсонации).
+type actor struct {
+	Subject string `json:"sub"`
+}
+
+// Introspect проверяет токен и возвращает его claims.
+//
+// Introspect godoc
+//
+//	@Summary		Проверить токен
+//	@Description	Проверяет подпись и срок действия токена (RFC 7662). Для недействительного токена возвращает active=false. Для аудиторий с мягкой проверкой истекший не более чем на grace-период токен считается активным, в ответе выставляется grace=true. Токен, привязанный к сертификату (cnf.x5t#S256), активен только если передан отпечаток того же сертификата в client_cert_thumbprint
+//	@Tags			token
+//	@Accept			json,x-www-form-urlencoded
+//	@Produce		json
+//	@Param			request	body		introspectRequest	true	"Токен"
+//	@Success		200		{object}	introspectResponse
+//	@Failure		400		{object}	errorResponse
+//	@Failure		404		{object}	errorResponse
+//	@Failure		503		{object}	errorResponse
+//	@Router			/token/introspect [post]
+func (s *Handler) Introspect(c echo.Context) error {
+	if s.validator == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "token validation is not configured"})
+	}
+
+	var req introspectRequest
+
+	if err := c.Bind(&req); err != nil || req.Token == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "token is required"})
+	}
+
+	claims, err := s.validator.Validate(c.Request().Context(), req.Token)
+	if errors.Is(err, token.ErrInvalidToken) {
+		logrus.WithError(err).Debug("token is not active")
+
+		return c.JSON(http.StatusOK, introspectResponse{Active: false})
+	}
+
+	if err != nil {
+		logrus.WithError(err).Error("error validate token")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "signing keys are unavailable"})
+	}
+
+	if err := token.CheckBinding(claims, req.ClientCertThumbprint); err != nil {
+		logrus.WithError(err).WithField("jti", claims.ID).Warn("certificate-bound token presented without matching certificate")
+
+		return c.JSON(http.StatusOK, introspectResponse{Active: false})
+	}
+
+	resp := introspectResponse{
+		Active:    true,
+		Issuer:    claims.Issuer,
+		Subject:   claims.Subject,
+		Audience:  claims.Audience,
+		ExpiresAt: claims.ExpiresAt.Unix(),
+		Kid:       claims.Kid,
+		JTI:       claims.ID,
+		Scope:     strings.Join(claims.Scopes, " "),
+		Groups:    claims.Groups,
+		Env:       claims.Env,
+		Grace:     claims.Grace,
+
+		TelegramID: claims.TelegramID,
+		Roles:      claims.Roles,
+		SessionID:  claims.SessionID,
+		AuthLevel:  claims.AuthLevel,
+		Tenant:     claims.Tenant,
+	}
+
+	if claims.Actor != nil {
+		resp.Act = &actor{Subject: claims.Actor.Subject}
+	}
+
+	if !claims.IssuedAt.IsZero() {
+		resp.IssuedAt = claims.IssuedAt.Unix()
+	}
+
+	if claims.CertThumbprint != "" {
+		resp.Cnf = &confirmation{X5tS256: claims.CertThumbprint}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// RevokeToken отзывает токен: его jti попадает в черный список до истечения токена.
+// Владение токеном достаточно для его отзыва, поэтому отдельная аутентификация не нужна.
+//
+// RevokeToken godoc
+//
+//	@Summary		Отозвать токен
+//	@Description	Отзывает токен по его jti (RFC 7009): токен перестает приниматься сразу, запись хранится до его истечения. Для недействительного, истекшего или уже отозванного токена тоже возвращает 200
+//	@Tags			token
+//	@Accept			json,x-www-form-urlencoded
+//	@Param			request	body	revokeRequest	true	"Токен"
+//	@Success		200
+//	@Failure		400	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/token/revoke [post]
+func (s *Handler) RevokeToken(c echo.Context) error {
+	if s.validator == nil || s.revocations == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "token revocation is not configured"})
+	}
+
+	var req revokeRequest
+
+	if err := c.Bind(&req); err != nil || req.Token == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "token is required"})
+	}
+
+	claims, err := s.validator.Validate(c.Request().Context(), req.Token)
+	if errors.Is(err, token.ErrInvalidToken) {
+		// по RFC 7009 недействительный токен не ошибка: принимать его уже нечего
+		logrus.WithError(err).Debug("revoked token is not active")
+
+		return c.NoContent(http.StatusOK)
+	}
+
+	if err != nil {
+		logrus.WithError(err).Error("error validate token")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "signing keys are unavailable"})
+	}
+
+	if claims.ID == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "token without jti can not be revoked"})
+	}
+
+	if err := s.revocations.RevokeToken(c.Request().Context(), claims.ID, claims.ExpiresAt); err != nil {
+		logrus.WithError(err).WithField("jti", claims.ID).Error("error revoke token")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to revoke token"})
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"jti":     claims.ID,
+		"subject": claims.Subject,
+	}).Info("token revoked")
+
+	return c.NoContent(http.StatusOK)
+}
+
+// authenticateUser проверяет токен пользователя из заголовка Authorization: Bearer <токен>.
+// Токены имперсонации не принимаются: сотрудник поддержки не должен действовать как сам пользователь.
+// Гостевые токены не принимаются: за ними нет пользователя. Токен, привязанный к сертификату,
+// принимается только с этим сертификатом mTLS, а истекший токен, принятый в режиме мягкой проверки, -
+// только в запросах на чтение.
+// Если токен уже проверил middleware аутентификации, используются claims из контекста запроса,
+// иначе токен проверяется здесь и claims сохраняются в контекст (authclient.ClaimsFromContext).
+// Если токен не принят, пишет ответ с ошибкой и возвращает nil claims.
+func (s *Handler) authenticateUser(c echo.Context) (*token.Claims, error) {
+	claims, ok := token.FromContext(c.Request().Context())
+	if !ok {
+		var err error
+
+		claims, err = s.validateBearer(c)
+		if claims == nil {
+			return nil, err
+		}
+	}
+
+	// middleware аутентификации проверяет то же самое, но обработчики могут быть подключены и без него
+	if err := token.CheckBinding(claims, clientCertThumbprint(c)); err != nil {
+		logrus.WithError(err).WithField("jti", claims.ID).Warn("certificate-bound token presented without matching certificate")
+
+		return nil, c.JSON(http.StatusUnauthorized, errorResponse{Error: "invalid token"})
+	}
+
+	if err := token.CheckGrace(claims, c.Request().Method); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"jti":  claims.ID,
+			"path": c.Path(),
+		}).Debug("grace token rejected for state-changing request")
+
+		return nil, c.JSON(http.StatusUnauthorized, errorResponse{Error: "token is expired"})
+	}
+
+	if claims.Actor != nil {
+		logrus.WithFields(logrus.Fields{
+			"subject": claims.Subject,
+			"actor":   claims.Actor.Subject,
+			"path":    c.Path(),
+		}).Warn("impersonation token rejected for user action")
+
+		return nil, c.JSON(http.StatusForbidden, errorResponse{Error: "impersonation tokens are not accepted"})
+	}
+
+	if claims.IsGuest() {
+		return nil, c.JSON(http.StatusForbidden, errorResponse{Error: "guest tokens are not accepted"})
+	}
+
+	return claims, nil
+}
+
+// validateBearer проверяет токен из заголовка Authorization, если его не проверил middleware
+// аутентификации, и сохраняет claims в контекст запроса.
+func (s *Handler) validateBearer(c echo.Context) (*token.Claims, error) {
+	raw := bearerToken(c)
+	if raw == "" {
+		return nil, c.JSON(http.StatusUnauthorized, errorResponse{Error: "bearer token is required"})
+	}
+
+	claims, err := s.validator.Validate(c.Request().Context(), raw)
+	if errors.Is(err, token.ErrInvalidToken) {
+		return nil, c.JSON(http.StatusUnauthorized, errorResponse{Error: "invalid token"})
+	}
+
+	if err != nil {
+		logrus.WithError(err).Error("error validate token")
+
+		return nil, c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "signing keys are unavailable"})
+	}
+
+	req := c.Request()
+	c.SetRequest(req.WithContext(authclient.NewContext(token.NewContext(req.Context(), claims), claims.Principal())))
+
+	return claims, nil
+}
@@ -0,0 +1,432 @@
+package v0
+
+import (
+	"auth-service/internal/service/job"
+	"auth-service/internal/service/revocation"
+	"auth-service/internal/service/token"
+	"auth-service/pkg/authclient"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testKeys - источник ключей подписи для тестов.
+type testKeys struct {
+	key []byte
+	err error
+}
+
+func (k testKeys) Key(_ context.Context, _ string) ([]byte, error) {
+	return k.key, k.err
+}
+
+func signToken(t *testing.T, key []byte, aud string, exp time.Time) string {
+	t.Helper()
+
+	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   "user-1",
+		Audience:  jwt.ClaimStrings{aud},
+		ExpiresAt: jwt.NewNumericDate(exp),
+	})
+	tok.Header["kid"] = "key-1"
+
+	raw, err := tok.SignedString(key)
+	require.NoError(t, err)
+
+	return raw
+}
+
+func signBoundToken(t *testing.T, key []byte, thumbprint string) string {
+	t.Helper()
+
+	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "svc",
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"cnf": map[string]string{"x5t#S256": thumbprint},
+	})
+	tok.Header["kid"] = "key-1"
+
+	raw, err := tok.SignedString(key)
+	require.NoError(t, err)
+
+	return raw
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestIntrospect(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+
+	tests := []struct {
+		name       string
+		keys       *testKeys
+		body       func(t *testing.T) (string, string)
+		wantStatus int
+		want       *introspectResponse
+	}{
+		{
+			name: "positive case: active token",
+			keys: &testKeys{key: key},
+			body: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return echo.MIMEApplicationJSON, `{"token":"` + signToken(t, key, "web", time.Now().Add(time.Hour)) + `"}`
+			},
+			wantStatus: http.StatusOK,
+			want:       &introspectResponse{Active: true, Subject: "user-1", Audience: []string{"web"}, Kid: "key-1"},
+		},
+		{
+			name: "positive case: expired token in grace mode, form body",
+			keys: &testKeys{key: key},
+			body: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				form := url.Values{"token": {signToken(t, key, "bot", time.Now().Add(-10*time.Second))}}
+
+				return echo.MIMEApplicationForm, form.Encode()
+			},
+			wantStatus: http.StatusOK,
+			want:       &introspectResponse{Active: true, Subject: "user-1", Audience: []string{"bot"}, Kid: "key-1", Grace: true},
+		},
+		{
+			name: "positive case: user claims",
+			keys: &testKeys{key: key},
+			body: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				tok := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+					"sub":    "user-1",
+					"exp":    time.Now().Add(time.Hour).Unix(),
+					"tg_id":  12345,
+					"roles":  []string{"editor"},
+					"sid":    "session-1",
+					"acr":    "mfa",
+					"tenant": "acme",
+				})
+				tok.Header["kid"] = "key-1"
+
+				raw, err := tok.SignedString(key)
+				require.NoError(t, err)
+
+				return echo.MIMEApplicationJSON, `{"token":"` + raw + `"}`
+			},
+			wantStatus: http.StatusOK,
+			want: &introspectResponse{
+				Active: true, Subject: "user-1", Kid: "key-1",
+				TelegramID: 12345, Roles: []string{"editor"}, SessionID: "session-1", AuthLevel: "mfa", Tenant: "acme",
+			},
+		},
+		{
+			name: "positive case: expired token",
+			keys: &testKeys{key: key},
+			body: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return echo.MIMEApplicationJSON, `{"token":"` + signToken(t, key, "web", time.Now().Add(-10*time.Second)) + `"}`
+			},
+			wantStatus: http.StatusOK,
+			want:       &introspectResponse{Active: false},
+		},
+		{
+			name: "positive case: certificate-bound token with matching certificate",
+			keys: &testKeys{key: key},
+			body: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return echo.MIMEApplicationJSON, `{"token":"` + signBoundToken(t, key, "thumb") + `","client_cert_thumbprint":"thumb"}`
+			},
+			wantStatus: http.StatusOK,
+			want:       &introspectResponse{Active: true, Subject: "svc", Kid: "key-1", Cnf: &confirmation{X5tS256: "thumb"}},
+		},
+		{
+			name: "positive case: certificate-bound token with other certificate",
+			keys: &testKeys{key: key},
+			body: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return echo.MIMEApplicationJSON, `{"token":"` + signBoundToken(t, key, "thumb") + `","client_cert_thumbprint":"other"}`
+			},
+			wantStatus: http.StatusOK,
+			want:       &introspectResponse{Active: false},
+		},
+		{
+			name: "positive case: certificate-bound token without certificate",
+			keys: &testKeys{key: key},
+			body: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return echo.MIMEApplicationJSON, `{"token":"` + signBoundToken(t, key, "thumb") + `"}`
+			},
+			wantStatus: http.StatusOK,
+			want:       &introspectResponse{Active: false},
+		},
+		{
+			name: "positive case: verification bundle is not a user token",
+			keys: &testKeys{key: key},
+			body: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return echo.MIMEApplicationJSON, `{"token":"` + signBundle(t, key) + `"}`
+			},
+			wantStatus: http.StatusOK,
+			want:       &introspectResponse{Active: false},
+		},
+		{
+			name: "error case: empty token",
+			keys: &testKeys{key: key},
+			body: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return echo.MIMEApplicationJSON, `{}`
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "error case: keys are unavailable",
+			keys: &testKeys{err: errors.New("vault is sealed")},
+			body: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return echo.MIMEApplicationJSON, `{"token":"` + signToken(t, key, "web", time.Now().Add(time.Hour)) + `"}`
+			},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name: "error case: not configured",
+			body: func(t *testing.T) (string, string) {
+				t.Helper()
+
+				return echo.MIMEApplicationJSON, `{"token":"x"}`
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := []handlerOption{
+				WithVersion("1.0.0"),
+				WithBuildDate("2021-01-01"),
+				WithGitCommit("1234567890"),
+			}
+
+			if tt.keys != nil {
+				v, err := token.NewValidator(
+					token.WithKeys(*tt.keys),
+					token.WithGrace(token.Grace{Period: time.Minute, Audiences: []string{"bot"}}),
+				)
+				require.NoError(t, err)
+
+				opts = append(opts, WithValidator(v))
+			}
+
+			h, err := New(opts...)
+			require.NoError(t, err)
+
+			contentType, body := tt.body(t)
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, contentType)
+
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			require.NoError(t, h.Introspect(c))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			if tt.want == nil {
+				return
+			}
+
+			var got introspectResponse
+
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+
+			if got.Active {
+				assert.NotZero(t, got.ExpiresAt)
+				got.ExpiresAt = 0
+			}
+
+			assert.Equal(t, *tt.want, got)
+		})
+	}
+}
+
+func TestAuthenticateUser(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+
+	v, err := token.NewValidator(
+		token.WithKeys(testKeys{key: key}),
+		token.WithGrace(token.Grace{Period: time.Hour, Audiences: []string{"bot"}}),
+	)
+	require.NoError(t, err)
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"), WithValidator(v))
+	require.NoError(t, err)
+
+	do := func(method, authorization string, state *tls.ConnectionState) (echo.Context, *httptest.ResponseRecorder, *token.Claims) {
+		req := httptest.NewRequest(method, "/", nil)
+		if authorization != "" {
+			req.Header.Set(echo.HeaderAuthorization, authorization)
+		}
+
+		req.TLS = state
+
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+
+		claims, err := h.authenticateUser(c)
+		require.NoError(t, err)
+
+		return c, rec, claims
+	}
+
+	// claims пользователя доступны обработчикам через контекст запроса
+	c, _, claims := do(http.MethodGet, "Bearer "+signToken(t, key, "web", time.Now().Add(time.Hour)), nil)
+	require.NotNil(t, claims)
+
+	principal, ok := authclient.ClaimsFromContext(c.Request().Context())
+	require.True(t, ok)
+	assert.Equal(t, "user-1", principal.UserID)
+
+	c, rec, claims := do(http.MethodGet, "", nil)
+	assert.Nil(t, claims)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	_, ok = authclient.ClaimsFromContext(c.Request().Context())
+	assert.False(t, ok)
+
+	// токен, привязанный к сертификату, принимается только с этим сертификатом mTLS
+	cert := &x509.Certificate{Raw: []byte("client certificate")}
+	mtls := &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}, VerifiedChains: [][]*x509.Certificate{{cert}}}
+	bound := "Bearer " + signBoundToken(t, key, token.CertThumbprint(cert))
+
+	_, _, claims = do(http.MethodPost, bound, mtls)
+	assert.NotNil(t, claims)
+
+	_, rec, claims = do(http.MethodPost, bound, nil)
+	assert.Nil(t, claims)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// истекший токен, принятый в режиме мягкой проверки, годится только для чтения
+	grace := "Bearer " + signToken(t, key, "bot", time.Now().Add(-time.Minute))
+
+	_, _, claims = do(http.MethodGet, grace, nil)
+	require.NotNil(t, claims)
+	assert.True(t, claims.Grace)
+
+	_, rec, claims = do(http.MethodDelete, grace, nil)
+	assert.Nil(t, claims)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.JSONEq(t, `{"error":"token is expired"}`, rec.Body.String())
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestRevokeToken(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	jobs, err := job.New(job.WithClient(client), job.WithConsumer("test"))
+	require.NoError(t, err)
+
+	revocations, err := revocation.New(revocation.WithClient(client), revocation.WithJobs(jobs))
+	require.NoError(t, err)
+
+	v, err := token.NewValidator(token.WithKeys(testKeys{key: key}), token.WithRevocations(revocations))
+	require.NoError(t, err)
+
+	h, err := New(
+		WithVersion("1.0.0"),
+		WithBuildDate("2021-01-01"),
+		WithGitCommit("1234567890"),
+		WithValidator(v),
+		WithRevocations(revocations),
+	)
+	require.NoError(t, err)
+
+	sign := func(jti string) string {
+		tok := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   "user-1",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		})
+		tok.Header["kid"] = "key-1"
+
+		raw, err := tok.SignedString(key)
+		require.NoError(t, err)
+
+		return raw
+	}
+
+	active := func(raw string) bool {
+		rec := callGroups(t, h.Introspect, http.MethodPost, "/", `{"token":"`+raw+`"}`, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp introspectResponse
+
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+
+		return resp.Active
+	}
+
+	revoked, other := sign("token-1"), sign("token-2")
+
+	rec := callGroups(t, h.RevokeToken, http.MethodPost, "/", `{"token":"`+revoked+`"}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// отозван только переданный токен
+	assert.False(t, active(revoked))
+	assert.True(t, active(other))
+	assert.Positive(t, mr.TTL("auth:revocation:jti:token-1"))
+
+	// повторный отзыв и недействительный токен не ошибка
+	rec = callGroups(t, h.RevokeToken, http.MethodPost, "/", `{"token":"`+revoked+`"}`, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = callGroups(t, h.RevokeToken, http.MethodPost, "/", `{"token":"invalid"}`, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = callGroups(t, h.RevokeToken, http.MethodPost, "/", `{}`, nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callGroups(t, h.RevokeToken, http.MethodPost, "/", `{"token":"`+sign("")+`"}`, nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// без Redis нельзя ни проверить, ни отозвать токен
+	mr.Close()
+
+	rec = callGroups(t, h.RevokeToken, http.MethodPost, "/", `{"token":"`+other+`"}`, nil)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	notConfigured, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"), WithValidator(v))
+	require.NoError(t, err)
+
+	rec = callGroups(t, notConfigured.RevokeToken, http.MethodPost, "/", `{"token":"`+other+`"}`, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
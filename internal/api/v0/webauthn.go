@@ -0,0 +1,214 @@
+package v0
+
+import (
+	"auth-service/internal/service/webauthn"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// passkeyLoginRequest - запрос на вход по ключу.
+type passkeyLoginRequest struct {
+	// Subject - пользователь. Если не указан, браузер предложит ключи, сохраненные на устройстве.
+	Subject string `json:"subject,omitempty"`
+}
+
+// BeginPasskeyRegistration создает challenge для регистрации ключа.
+//
+// BeginPasskeyRegistration godoc
+//
+//	@Summary		Начать регистрацию passkey
+//	@Description	Возвращает параметры для navigator.credentials.create({publicKey}). Бинарные поля в base64url. Ключ регистрируется пользователю из токена
+//	@Tags			webauthn
+//	@Produce		json
+//	@Security		BearerToken
+//	@Success		200	{object}	webauthn.CreationOptions
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		503	{object}	errorResponse
+//	@Router			/webauthn/register/begin [post]
+func (s *Handler) BeginPasskeyRegistration(c echo.Context) error {
+	if s.passkeys == nil || s.validator == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "webauthn is not configured"})
+	}
+
+	claims, err := s.authenticateUser(c)
+	if claims == nil {
+		return err
+	}
+
+	options, err := s.passkeys.BeginRegistration(c.Request().Context(), claims.Subject)
+	if err != nil {
+		logrus.WithError(err).Error("error begin passkey registration")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to begin registration"})
+	}
+
+	return c.JSON(http.StatusOK, options)
+}
+
+// FinishPasskeyRegistration проверяет ответ браузера и сохраняет ключ.
+//
+// FinishPasskeyRegistration godoc
+//
+//	@Summary		Завершить регистрацию passkey
+//	@Description	Принимает ответ navigator.credentials.create(): authenticatorData и publicKey (SPKI) из getAuthenticatorData() и getPublicKey(). Поддерживаются ES256, EdDSA и RS256, attestation не проверяется
+//	@Tags			webauthn
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerToken
+//	@Param			request	body		webauthn.RegistrationResponse	true	"Ответ браузера"
+//	@Success		201		{object}	webauthn.Credential
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401		{object}	errorResponse
+//	@Failure		403		{object}	errorResponse
+//	@Failure		404		{object}	errorResponse
+//	@Failure		409		{object}	errorResponse
+//	@Failure		503		{object}	errorResponse
+//	@Router			/webauthn/register/finish [post]
+func (s *Handler) FinishPasskeyRegistration(c echo.Context) error {
+	if s.passkeys == nil || s.validator == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "webauthn is not configured"})
+	}
+
+	claims, err := s.authenticateUser(c)
+	if claims == nil {
+		return err
+	}
+
+	var req webauthn.RegistrationResponse
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+	}
+
+	cred, err := s.passkeys.FinishRegistration(c.Request().Context(), claims.Subject, req)
+	if err != nil {
+		return passkeyError(c, err, "error finish passkey registration")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"subject":   cred.Subject,
+		"algorithm": cred.Algorithm,
+		"ip":        c.RealIP(),
+	}).Info("passkey registered")
+
+	return c.JSON(http.StatusCreated, cred)
+}
+
+// BeginPasskeyLogin создает challenge для входа по ключу.
+//
+// BeginPasskeyLogin godoc
+//
+//	@Summary		Начать вход по passkey
+//	@Description	Возвращает параметры для navigator.credentials.get({publicKey}). Бинарные поля в base64url
+//	@Tags			webauthn
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		passkeyLoginRequest	false	"Пользователь"
+//	@Success		200		{object}	webauthn.RequestOptions
+//	@Failure		400		{object}	errorResponse
+//	@Failure		404		{object}	errorResponse
+//	@Failure		503		{object}	errorResponse
+//	@Router			/webauthn/login/begin [post]
+func (s *Handler) BeginPasskeyLogin(c echo.Context) error {
+	if s.passkeys == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "webauthn is not configured"})
+	}
+
+	var req passkeyLoginRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+	}
+
+	options, err := s.passkeys.BeginLogin(c.Request().Context(), req.Subject)
+	if err != nil {
+		logrus.WithError(err).Error("error begin passkey login")
+
+		return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "unable to begin login"})
+	}
+
+	return c.JSON(http.StatusOK, options)
+}
+
+// FinishPasskeyLogin проверяет подпись ключа и выдает токен.
+//
+// FinishPasskeyLogin godoc
+//
+//	@Summary		Завершить вход по passkey
+//	@Description	Принимает ответ navigator.credentials.get() и выдает токен владельцу ключа
+//	@Tags			webauthn
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		webauthn.AssertionResponse	true	"Ответ браузера"
+//	@Success		200		{object}	tokenResponse
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401		{object}	errorResponse
+//	@Failure		404		{object}	errorResponse
+//	@Failure		503		{object}	errorResponse
+//	@Router			/webauthn/login/finish [post]
+func (s *Handler) FinishPasskeyLogin(c echo.Context) error {
+	if s.passkeys == nil {
+		return c.JSON(http.StatusNotFound, errorResponse{Error: "webauthn is not configured"})
+	}
+
+	var req webauthn.AssertionResponse
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+	}
+
+	login, err := s.passkeys.Login(c.Request().Context(), req)
+	if err != nil {
+		return passkeyError(c, err, "error finish passkey login")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"subject": login.Claims.Subject,
+		"jti":     login.Claims.ID,
+		"ip":      c.RealIP(),
+	}).Info("passkey login completed")
+
+	resp, err := s.withRefresh(c, tokenResponse{
+		AccessToken: login.Token,
+		TokenType:   "Bearer",
+		ExpiresAt:   login.Claims.ExpiresAt.Unix(),
+		Scope:       strings.Join(login.Claims.Scopes, " "),
+		JTI:         login.Claims.ID,
+	}, login.Claims)
+	if err != nil {
+		return refreshUnavailable(c)
+	}
+
+	s.notifyLogin(c, login.Claims.Subject, "passkey")
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func passkeyError(c echo.Context, err error, msg string) error {
+	switch {
+	case errors.Is(err, webauthn.ErrInvalidArgument):
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+	case errors.Is(err, webauthn.ErrCredentialExists):
+		return c.JSON(http.StatusConflict, errorResponse{Error: err.Error()})
+	case errors.Is(err, webauthn.ErrCloned):
+		logrus.WithError(err).WithField("ip", c.RealIP()).Warn("possible cloned passkey")
+
+		return c.JSON(http.StatusUnauthorized, errorResponse{Error: webauthn.ErrVerification.Error()})
+	case errors.Is(err, webauthn.ErrVerification),
+		errors.Is(err, webauthn.ErrChallenge),
+		errors.Is(err, webauthn.ErrUnknownCredential):
+		logrus.WithError(err).WithField("ip", c.RealIP()).Debug("passkey rejected")
+
+		return c.JSON(http.StatusUnauthorized, errorResponse{Error: err.Error()})
+	}
+
+	logrus.WithError(err).Error(msg)
+
+	return c.JSON(http.StatusServiceUnavailable, errorResponse{Error: "webauthn is unavailable"})
+}
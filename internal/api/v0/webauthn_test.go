@@ -0,0 +1,237 @@
+package v0
+
+import (
+	"auth-service/internal/service/token"
+	"auth-service/internal/service/webauthn"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const passkeyRPID = "zanuda.example"
+
+// testPasskey - программный аутентификатор ES256 для тестов.
+type testPasskey struct {
+	id        []byte
+	key       *ecdsa.PrivateKey
+	signCount uint32
+}
+
+func (p *testPasskey) clientData(t *testing.T, typ string, challenge []byte) []byte {
+	t.Helper()
+
+	raw, err := json.Marshal(map[string]string{
+		"type":      typ,
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    "https://" + passkeyRPID,
+	})
+	require.NoError(t, err)
+
+	return raw
+}
+
+func (p *testPasskey) authData(attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(passkeyRPID))
+	flags := byte(0x01 | 0x04)
+
+	if attested {
+		flags |= 0x40
+	}
+
+	data := slices.Concat(rpIDHash[:], []byte{flags}, binary.BigEndian.AppendUint32(nil, p.signCount))
+
+	if attested {
+		data = slices.Concat(data, make([]byte, 16), binary.BigEndian.AppendUint16(nil, uint16(len(p.id))), p.id)
+	}
+
+	return data
+}
+
+func (p *testPasskey) register(t *testing.T, options webauthn.CreationOptions) string {
+	t.Helper()
+
+	publicKey, err := x509.MarshalPKIXPublicKey(&p.key.PublicKey)
+	require.NoError(t, err)
+
+	raw, err := json.Marshal(webauthn.RegistrationResponse{
+		ID:                 p.id,
+		ClientDataJSON:     p.clientData(t, "webauthn.create", options.Challenge),
+		AuthenticatorData:  p.authData(true),
+		PublicKey:          publicKey,
+		PublicKeyAlgorithm: webauthn.AlgES256,
+	})
+	require.NoError(t, err)
+
+	return string(raw)
+}
+
+func (p *testPasskey) assert(t *testing.T, options webauthn.RequestOptions) string {
+	t.Helper()
+
+	p.signCount++
+
+	clientDataJSON := p.clientData(t, "webauthn.get", options.Challenge)
+	authenticatorData := p.authData(false)
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(slices.Concat(authenticatorData, clientDataHash[:]))
+
+	signature, err := ecdsa.SignASN1(rand.Reader, p.key, digest[:])
+	require.NoError(t, err)
+
+	raw, err := json.Marshal(webauthn.AssertionResponse{
+		ID:                p.id,
+		ClientDataJSON:    clientDataJSON,
+		AuthenticatorData: authenticatorData,
+		Signature:         signature,
+	})
+	require.NoError(t, err)
+
+	return string(raw)
+}
+
+func newPasskeyHandler(t *testing.T) (*Handler, *token.Issuer, *miniredis.Miniredis) {
+	t.Helper()
+
+	key := []byte("secret")
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	issuer, err := token.NewIssuer(token.WithSigningKeys(testSigningKeys{key: key}))
+	require.NoError(t, err)
+
+	validator, err := token.NewValidator(token.WithKeys(testKeys{key: key}))
+	require.NoError(t, err)
+
+	svc, err := webauthn.New(
+		webauthn.WithClient(client),
+		webauthn.WithIssuer(issuer),
+		webauthn.WithToken(time.Hour, []string{"dashboard"}),
+		webauthn.WithRelyingParty(passkeyRPID, "Zanuda", []string{"https://" + passkeyRPID}),
+	)
+	require.NoError(t, err)
+
+	h, err := New(
+		WithVersion("1.0.0"),
+		WithBuildDate("2021-01-01"),
+		WithGitCommit("1234567890"),
+		WithValidator(validator),
+		WithPasskeys(svc),
+	)
+	require.NoError(t, err)
+
+	return h, issuer, mr
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestPasskeys(t *testing.T) {
+	t.Parallel()
+
+	h, issuer, mr := newPasskeyHandler(t)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	passkey := &testPasskey{id: []byte("credential-1"), key: key}
+
+	userToken, _, err := issuer.Issue(t.Context(), token.IssueRequest{Subject: "user-1", TTL: time.Hour})
+	require.NoError(t, err)
+
+	rec := callAuthorized(t, h.BeginPasskeyRegistration, "", "", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = callAuthorized(t, h.BeginPasskeyRegistration, "", "Bearer "+userToken, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var creation webauthn.CreationOptions
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&creation))
+	assert.Equal(t, passkeyRPID, creation.RP.ID)
+
+	rec = callAuthorized(t, h.FinishPasskeyRegistration, "", "Bearer "+userToken, `{"id":"!"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	registration := passkey.register(t, creation)
+
+	rec = callAuthorized(t, h.FinishPasskeyRegistration, "", "Bearer "+userToken, registration)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var cred webauthn.Credential
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&cred))
+	assert.Equal(t, "user-1", cred.Subject)
+
+	// challenge уже использован
+	rec = callAuthorized(t, h.FinishPasskeyRegistration, "", "Bearer "+userToken, registration)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = callAuthorized(t, h.BeginPasskeyLogin, "", "", `{"subject":"user-1"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var request webauthn.RequestOptions
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&request))
+	require.Len(t, request.AllowCredentials, 1)
+
+	rec = callAuthorized(t, h.FinishPasskeyLogin, "", "", passkey.assert(t, request))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp tokenResponse
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+
+	claims, err := h.validator.Validate(t.Context(), resp.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+	assert.Equal(t, []string{"dashboard"}, claims.Audience)
+
+	// повтор того же ответа отклоняется
+	rec = callAuthorized(t, h.FinishPasskeyLogin, "", "", passkey.assert(t, request))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// Redis недоступен
+	mr.Close()
+
+	rec = callAuthorized(t, h.BeginPasskeyLogin, "", "", `{}`)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	rec = callAuthorized(t, h.BeginPasskeyRegistration, "", "Bearer "+userToken, "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	rec = callAuthorized(t, h.FinishPasskeyLogin, "", "", passkey.assert(t, request))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestPasskeys_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(WithVersion("1.0.0"), WithBuildDate("2021-01-01"), WithGitCommit("1234567890"))
+	require.NoError(t, err)
+
+	for _, fn := range []echo.HandlerFunc{
+		h.BeginPasskeyRegistration,
+		h.FinishPasskeyRegistration,
+		h.BeginPasskeyLogin,
+		h.FinishPasskeyLogin,
+	} {
+		rec := callAuthorized(t, fn, "", "Bearer token", `{}`)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
@@ -0,0 +1,233 @@
+// Package cliout печатает результат служебных команд в формате, который выбирает вызывающий:
+// json и yaml для скриптов развертывания, table для человека. Имена полей во всех форматах
+// берутся из тегов json, поэтому скрипт может переключаться между json и yaml без изменений.
+package cliout
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Format - формат вывода.
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatYAML  Format = "yaml"
+)
+
+// FlagUsage - описание флага -o для flag.FlagSet.
+const FlagUsage = "output format: table, json or yaml"
+
+// ErrUnknownFormat - неизвестный формат вывода.
+var ErrUnknownFormat = errors.New("unknown output format, expected table, json or yaml")
+
+// String возвращает название формата. Реализует flag.Value.
+func (f *Format) String() string {
+	return string(*f)
+}
+
+// Set устанавливает формат из значения флага. Реализует flag.Value.
+func (f *Format) Set(value string) error {
+	switch format := Format(strings.ToLower(value)); format {
+	case FormatTable, FormatJSON, FormatYAML:
+		*f = format
+
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownFormat, value)
+	}
+}
+
+// Write печатает v в формате format. v - структура, срез структур или отображение.
+func Write(w io.Writer, format Format, v any) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(v)
+	case FormatYAML:
+		return writeYAML(w, v)
+	case FormatTable:
+		return writeTable(w, v)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
+
+// writeYAML печатает v в yaml с именами полей из тегов json: значение проходит через json,
+// а порядок полей структуры сохраняется.
+func writeYAML(w io.Writer, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.MapSlice
+
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		// не объект: срез или скаляр
+		var value any
+
+		if err := yaml.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+
+		return yaml.NewEncoder(w).Encode(value)
+	}
+
+	return yaml.NewEncoder(w).Encode(doc)
+}
+
+// writeTable печатает структуру или отображение двумя колонками (поле и значение),
+// срез структур - таблицей с заголовком из имен полей.
+func writeTable(w io.Writer, v any) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	value := indirect(reflect.ValueOf(v))
+
+	switch {
+	case value.Kind() == reflect.Struct:
+		for _, field := range fields(value.Type()) {
+			fmt.Fprintf(tw, "%s\t%s\n", strings.ToUpper(field.name), cell(value.Field(field.index)))
+		}
+	case value.Kind() == reflect.Map:
+		for _, key := range sortedKeys(value) {
+			fmt.Fprintf(tw, "%v\t%s\n", key.Interface(), cell(value.MapIndex(key)))
+		}
+	case value.Kind() == reflect.Slice && indirectType(value.Type().Elem()).Kind() == reflect.Struct:
+		columns := fields(indirectType(value.Type().Elem()))
+
+		names := make([]string, 0, len(columns))
+		for _, column := range columns {
+			names = append(names, strings.ToUpper(column.name))
+		}
+
+		fmt.Fprintln(tw, strings.Join(names, "\t"))
+
+		for i := range value.Len() {
+			row := indirect(value.Index(i))
+			if !row.IsValid() {
+				continue
+			}
+
+			cells := make([]string, 0, len(columns))
+
+			for _, column := range columns {
+				cells = append(cells, cell(row.Field(column.index)))
+			}
+
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+	default:
+		fmt.Fprintln(tw, cell(value))
+	}
+
+	return tw.Flush()
+}
+
+type field struct {
+	name  string
+	index int
+}
+
+// fields возвращает экспортируемые поля структуры с именами из тегов json.
+func fields(t reflect.Type) []field {
+	result := make([]field, 0, t.NumField())
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		result = append(result, field{name: name, index: i})
+	}
+
+	return result
+}
+
+// cell форматирует значение для ячейки таблицы.
+func cell(v reflect.Value) string {
+	if !v.IsValid() || (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return "-"
+	}
+
+	v = indirect(v)
+	if !v.IsValid() {
+		return "-"
+	}
+
+	if t, ok := v.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return "-"
+		}
+
+		return t.Format(time.RFC3339)
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := make([]string, 0, v.Len())
+		for i := range v.Len() {
+			items = append(items, cell(v.Index(i)))
+		}
+
+		return strings.Join(items, ",")
+	case reflect.Map:
+		items := make([]string, 0, v.Len())
+		for _, key := range sortedKeys(v) {
+			items = append(items, fmt.Sprintf("%v=%s", key.Interface(), cell(v.MapIndex(key))))
+		}
+
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// sortedKeys возвращает ключи отображения по возрастанию их строкового представления.
+func sortedKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+
+	return keys
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	return v
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
@@ -0,0 +1,146 @@
+package cliout
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type result struct {
+	File      string         `json:"file"`
+	Keys      int            `json:"keys"`
+	ExpiresAt time.Time      `json:"expires_at"`
+	Kids      []string       `json:"kids,omitempty"`
+	Counts    map[string]int `json:"counts,omitempty"`
+	Secret    string         `json:"-"`
+}
+
+func testResult() result {
+	return result{
+		File:      "bundle.jwt",
+		Keys:      2,
+		ExpiresAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Kids:      []string{"key-1", "key-2"},
+		Counts:    map[string]int{"b": 2, "a": 1},
+		Secret:    "secret",
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		format Format
+		value  any
+		want   string
+	}{
+		{
+			name:   "json",
+			format: FormatJSON,
+			value:  testResult(),
+			want: `{
+  "file": "bundle.jwt",
+  "keys": 2,
+  "expires_at": "2026-01-02T03:04:05Z",
+  "kids": [
+    "key-1",
+    "key-2"
+  ],
+  "counts": {
+    "a": 1,
+    "b": 2
+  }
+}
+`,
+		},
+		{
+			name:   "yaml keeps field order and json names",
+			format: FormatYAML,
+			value:  testResult(),
+			want: `file: bundle.jwt
+keys: 2
+expires_at: "2026-01-02T03:04:05Z"
+kids:
+- key-1
+- key-2
+counts:
+  a: 1
+  b: 2
+`,
+		},
+		{
+			name:   "yaml list",
+			format: FormatYAML,
+			value:  []string{"a", "b"},
+			want:   "- a\n- b\n",
+		},
+		{
+			name:   "table struct",
+			format: FormatTable,
+			value:  &result{File: "bundle.jwt", Keys: 2, Kids: []string{"key-1", "key-2"}, Counts: map[string]int{"b": 2, "a": 1}},
+			want: `FILE        bundle.jwt
+KEYS        2
+EXPIRES_AT  -
+KIDS        key-1,key-2
+COUNTS      a=1,b=2
+`,
+		},
+		{
+			name:   "table slice",
+			format: FormatTable,
+			value:  []result{{File: "a.jwt", Keys: 1}, {File: "bb.jwt", Keys: 10, Kids: []string{"k"}}},
+			want: `FILE    KEYS  EXPIRES_AT  KIDS  COUNTS
+a.jwt   1     -                 
+bb.jwt  10    -           k     
+`,
+		},
+		{
+			name:   "table map",
+			format: FormatTable,
+			value:  map[string]int{"redis": 1, "vault": 0},
+			want:   "redis  1\nvault  0\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			require.NoError(t, Write(&buf, tt.format, tt.value))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestWrite_UnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	require.ErrorIs(t, Write(&buf, Format("xml"), testResult()), ErrUnknownFormat)
+}
+
+func TestFormat_Flag(t *testing.T) {
+	t.Parallel()
+
+	format := FormatTable
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&format, "o", FlagUsage)
+
+	require.NoError(t, fs.Parse([]string{"-o", "JSON"}))
+	assert.Equal(t, FormatJSON, format)
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&bytes.Buffer{})
+	fs.Var(&format, "o", FlagUsage)
+
+	require.ErrorContains(t, fs.Parse([]string{"-o", "xml"}), ErrUnknownFormat.Error())
+}
@@ -2,7 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -12,27 +14,162 @@ import (
 // Config - конфигурация всего сервиса.
 type Config struct {
 	LogLevel string `yaml:"log_level" validate:"required,oneof=debug info warn error"`
+	// Environment - окружение сервиса: dev, staging или prod (по умолчанию dev). В prod сервис
+	// не запускается с небезопасными настройками (см. InsecureSettings), если не задан AllowInsecure.
+	Environment   string `yaml:"environment" validate:"omitempty,oneof=dev staging prod"`
+	AllowInsecure bool   `yaml:"allow_insecure"` // Разрешить небезопасные настройки в prod (только для аварийных случаев)
 
 	Server Server `yaml:"server" validate:"required"`
 	Vault  Vault  `yaml:"vault" validate:"required"`
 	Redis  Redis  `yaml:"redis" validate:"required"`
+
+	Dependencies      Dependencies      `yaml:"dependencies"`
+	ReadOnly          ReadOnly          `yaml:"read_only"`
+	Startup           Startup           `yaml:"startup"`
+	Admin             Admin             `yaml:"admin"`
+	RateLimit         RateLimit         `yaml:"rate_limit"`
+	LoadShedding      LoadShedding      `yaml:"load_shedding"`
+	SLO               SLO               `yaml:"slo"`
+	Token             Token             `yaml:"token"`
+	Authz             Authz             `yaml:"authz"`
+	ProofOfWork       ProofOfWork       `yaml:"proof_of_work"`
+	Quota             Quota             `yaml:"quota"`
+	SPIFFE            SPIFFE            `yaml:"spiffe"`
+	Sandbox           Sandbox           `yaml:"sandbox"`
+	Revocation        Revocation        `yaml:"revocation"`
+	Jobs              Jobs              `yaml:"jobs"`
+	QRLogin           QRLogin           `yaml:"qr_login"`
+	WebAuthn          WebAuthn          `yaml:"webauthn"`
+	OAuth             OAuth             `yaml:"oauth"`
+	Events            Events            `yaml:"events"`
+	Abuse             Abuse             `yaml:"abuse"`
+	PasswordBreach    PasswordBreach    `yaml:"password_breach"`
+	CredentialsPolicy CredentialsPolicy `yaml:"credentials_policy"`
+	Mail              Mail              `yaml:"mail"`
+	Notifications     Notifications     `yaml:"notifications"`
+	Telegram          Telegram          `yaml:"telegram"`
+	StateKeys         StateKeys         `yaml:"state_keys"`
+	Peers             Peers             `yaml:"peers"`
+	UserStore         UserStore         `yaml:"user_store"`
+	Tracing           Tracing           `yaml:"tracing"`
 }
 
 // Server - конфигурация сервера.
 type Server struct {
 	Port            int           `yaml:"port" validate:"required,min=1024,max=65535"`
 	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" validate:"required,min=1ms"`
-	SwaggerHost     string        `yaml:"swagger_host" validate:"omitempty,hostname_port"` // Опциональный host для swagger (например, "localhost:8080" или "api.example.com")
+	ExternalURL     string        `yaml:"external_url" validate:"omitempty,url"`                                                // Внешний адрес сервиса (например, "https://auth.example.com"): claim iss токенов, swagger и ссылки в письмах
+	SwaggerHost     string        `yaml:"swagger_host" validate:"omitempty,hostname_port"`                                      // Устарело: используйте external_url. Host для swagger, если external_url не задан
+	TrustedProxies  []string      `yaml:"trusted_proxies" validate:"omitempty,dive,cidr"`                                       // CIDR диапазоны прокси, которым доверяем заголовок с IP клиента (опционально)
+	RealIPHeader    string        `yaml:"real_ip_header" validate:"omitempty,oneof=x-forwarded-for x-real-ip cf-connecting-ip"` // Заголовок с IP клиента за доверенным прокси (по умолчанию x-forwarded-for)
+	TLS             ServerTLS     `yaml:"tls"`
+	Listener        Listener      `yaml:"listener"`
+	HTTP2           HTTP2         `yaml:"http2"`
+	Debug           Debug         `yaml:"debug"`
+	SecurityTxt     SecurityTxt   `yaml:"security_txt"`
+	Drain           Drain         `yaml:"drain"`
+	Deprecations    Deprecations  `yaml:"deprecations"`
+}
+
+// Deprecations - устаревшие маршруты API. Ответы получают заголовки Deprecation, Sunset и Link,
+// обращения учитываются в метриках auth_deprecated_* и выборочно пишутся в журнал.
+type Deprecations struct {
+	LogInterval time.Duration     `yaml:"log_interval" validate:"omitempty,min=1s"` // Как часто пишется предупреждение об обращении к маршруту (по умолчанию 1m)
+	Routes      []DeprecatedRoute `yaml:"routes" validate:"dive"`
+}
+
+// DeprecatedRoute - устаревший маршрут.
+type DeprecatedRoute struct {
+	Method    string `yaml:"method" validate:"required,oneof=GET POST PUT PATCH DELETE"`
+	Path      string `yaml:"path" validate:"required,startswith=/"`           // Шаблон маршрута, например /api/v0/health
+	Since     string `yaml:"since" validate:"required,datetime=2006-01-02"`   // Дата объявления маршрута устаревшим
+	Sunset    string `yaml:"sunset" validate:"omitempty,datetime=2006-01-02"` // Дата удаления маршрута (опционально)
+	Link      string `yaml:"link" validate:"omitempty,url"`                   // Документация о переходе (опционально)
+	Successor string `yaml:"successor" validate:"omitempty,startswith=/"`     // Маршрут на замену, например /api/v1/health (опционально)
+}
+
+// Drain - вывод экземпляра из балансировки через POST /api/v0/admin/drain.
+type Drain struct {
+	Delay    time.Duration `yaml:"delay"`                                 // Время от начала вывода до завершения процесса (по умолчанию 30s)
+	MaxDelay time.Duration `yaml:"max_delay" validate:"omitempty,min=1s"` // Наибольшее время, которое можно указать в запросе (по умолчанию 10m)
+}
+
+// Debug - внутренний отладочный порт и раскрытие версии на публичном порту.
+type Debug struct {
+	Port        int  `yaml:"port" validate:"omitempty,min=1024,max=65535"` // Внутренний порт с полным /health, /metrics и /swagger. Метрики и swagger перестают отдаваться на публичном порту. 0 - отключен
+	HideVersion bool `yaml:"hide_version"`                                 // Не раскрывать версию, дату сборки, коммит и состояние компонентов на публичном порту
+}
+
+// SecurityTxt - файл /.well-known/security.txt (RFC 9116) с контактами для сообщений об уязвимостях.
+type SecurityTxt struct {
+	Enabled            bool     `yaml:"enabled"`
+	Contacts           []string `yaml:"contacts" validate:"required_if=Enabled true,omitempty,dive,uri"`                          // mailto:, tel: или https:// адреса
+	Expires            string   `yaml:"expires" validate:"required_if=Enabled true,omitempty,datetime=2006-01-02T15:04:05Z07:00"` // Срок актуальности файла (RFC 3339), рекомендуется не больше года
+	Encryption         string   `yaml:"encryption" validate:"omitempty,url"`                                                      // Ссылка на ключ для шифрования сообщений
+	Acknowledgments    string   `yaml:"acknowledgments" validate:"omitempty,url"`                                                 // Ссылка на страницу благодарностей
+	PreferredLanguages []string `yaml:"preferred_languages" validate:"omitempty,dive,bcp47_language_tag"`                         // Предпочитаемые языки сообщений
+	Canonical          string   `yaml:"canonical" validate:"omitempty,url"`                                                       // Канонический адрес файла
+	Policy             string   `yaml:"policy" validate:"omitempty,url"`                                                          // Ссылка на политику раскрытия уязвимостей
+}
+
+// HTTP2 - поддержка HTTP/2. По умолчанию сервер работает только по HTTP/1.1.
+type HTTP2 struct {
+	Enabled bool `yaml:"enabled"` // HTTP/2 для HTTPS (ALPN h2)
+	H2C     bool `yaml:"h2c"`     // HTTP/2 без шифрования для внутреннего трафика меша (prior knowledge)
+}
+
+// Listener - передача сокета при перезапуске без простоя на одном хосте.
+type Listener struct {
+	ReusePort        bool `yaml:"reuse_port"`        // Включить SO_REUSEPORT: новый процесс слушает порт одновременно со старым
+	SocketActivation bool `yaml:"socket_activation"` // Использовать сокет, переданный systemd (LISTEN_FDS). Если сокета нет, порт открывается как обычно
+}
+
+// ServerTLS - TLS API сервера. Если сертификат не настроен, сервер работает по HTTP.
+type ServerTLS struct {
+	VaultPKI ServerVaultPKI `yaml:"vault_pki"`
+	// ClientCAPath - путь к CA клиентских сертификатов (PEM). Если задан, включается mTLS: сертификат
+	// клиента проверяется, если предъявлен, и выпускаемые для него токены привязываются к сертификату (RFC 8705)
+	ClientCAPath string `yaml:"client_ca_path"`
+	// TerminatedUpstream - TLS завершается перед сервером (балансировщик, ingress, sidecar), а сам сервер
+	// слушает HTTP во внутренней сети. Сервер без сертификата тогда не считается небезопасной настройкой
+	TerminatedUpstream bool `yaml:"terminated_upstream"`
+}
+
+// ServerVaultPKI - выпуск сертификата сервера через Vault PKI с автоматическим продлением
+// после 2/3 срока действия.
+type ServerVaultPKI struct {
+	IssuePath     string        `yaml:"issue_path"`                                     // Путь роли PKI, например pki_int/issue/auth-service. Если не задан, выпуск отключен
+	CommonName    string        `yaml:"common_name" validate:"required_with=IssuePath"` // CN сертификата
+	AltNames      []string      `yaml:"alt_names" validate:"omitempty,dive,hostname"`   // Дополнительные DNS имена
+	IPSANs        []string      `yaml:"ip_sans" validate:"omitempty,dive,ip"`           // IP адреса
+	TTL           time.Duration `yaml:"ttl" validate:"omitempty,min=1m"`                // Время жизни сертификата (по умолчанию TTL роли)
+	RetryInterval time.Duration `yaml:"retry_interval" validate:"omitempty,min=1s"`     // Пауза перед повторным запросом после ошибки (по умолчанию 30s)
 }
 
 // Vault - конфигурация Vault.
 type Vault struct {
 	Address         string `yaml:"address" validate:"required,url"`
 	Token           string `yaml:"token" validate:"required"`
-	InsecureSkipTLS bool   `yaml:"insecure_skip_tls"` // Пропускать проверку TLS сертификата (только для разработки)
+	InsecureSkipTLS bool   `yaml:"insecure_skip_tls"` // Устарело: пропускать проверку TLS сертификата. Для разработки используйте tls_pin_file
 	CAPath          string `yaml:"ca_path"`           // Путь к CA сертификату (опционально)
 	ClientCertPath  string `yaml:"client_cert_path"`  // Путь к клиентскому сертификату (опционально)
 	ClientKeyPath   string `yaml:"client_key_path"`   // Путь к клиентскому ключу (опционально)
+	// TLSPinFile - файл с SHA-256 отпечатком сертификата Vault (trust on first use). Если файла нет,
+	// при первом подключении в него записывается отпечаток предъявленного сертификата, дальше
+	// принимается только он. Не сочетается с insecure_skip_tls и ca_path
+	TLSPinFile string `yaml:"tls_pin_file" validate:"excluded_with=CAPath,excluded_if=InsecureSkipTLS true"`
+	// Preflight - проверить при запуске права токена на пути Vault, нужные включенным функциям,
+	// и остановиться с отчетом о недостающих правах. Токену нужно право на sys/capabilities-self.
+	Preflight bool `yaml:"preflight"`
+
+	Renewal VaultRenewal `yaml:"renewal"`
+}
+
+// VaultRenewal - продление токена сервиса в фоне. Если токен больше нельзя продлить (Vault отказал
+// или достигнут максимальный срок жизни), сервис штатно останавливается, чтобы его перезапустили с новым токеном.
+type VaultRenewal struct {
+	Enabled   bool          `yaml:"enabled"`
+	Increment time.Duration `yaml:"increment" validate:"omitempty,min=1s"` // Срок, на который запрашивается продление (по умолчанию исходный срок токена)
 }
 
 // RedisType - тип подключения к Redis: single - один узел, cluster - кластер.
@@ -53,6 +190,629 @@ type Redis struct {
 	Port int    `yaml:"port" validate:"omitempty,min=1024,max=65535"`
 	// cluster
 	Addrs []string `yaml:"addrs" validate:"omitempty,dive,hostname_port"`
+	// Таймаут команды, если в контексте вызывающего нет дедлайна (по умолчанию 2s)
+	CommandTimeout time.Duration `yaml:"command_timeout" validate:"omitempty,min=10ms"`
+	// Формат записей сессий и токенов: json (по умолчанию) или msgpack. Записи читаются в любом формате,
+	// поэтому msgpack включается после обновления всех экземпляров.
+	Encoding string `yaml:"encoding" validate:"omitempty,oneof=json msgpack"`
+
+	Janitor RedisJanitor `yaml:"janitor"`
+	TTL     RedisTTL     `yaml:"ttl"`
+	Inspect RedisInspect `yaml:"inspect"`
+	Cluster RedisCluster `yaml:"cluster"`
+}
+
+// RedisCluster - обработка изменений топологии кластера: перенаправлений MOVED/ASK при решардинге
+// и отказов отдельных узлов. Слот, команды которого несколько раз подряд не выполнились, на время
+// SlotCooldown считается деградировавшим: команды с его ключами сразу получают ошибку, остальные слоты
+// работают (метрики redis_cluster_redirects_total, redis_cluster_slot_errors_total, redis_cluster_degraded_slots).
+type RedisCluster struct {
+	MaxRedirects    int           `yaml:"max_redirects" validate:"omitempty,min=1,max=16"`     // Сколько раз команда перенаправляется или повторяется на другом узле (по умолчанию 3)
+	MinRetryBackoff time.Duration `yaml:"min_retry_backoff" validate:"omitempty,min=1ms"`      // Минимальная пауза перед повтором (по умолчанию 8ms)
+	MaxRetryBackoff time.Duration `yaml:"max_retry_backoff" validate:"omitempty,min=1ms"`      // Максимальная пауза перед повтором (по умолчанию 512ms)
+	SlotFailures    int           `yaml:"slot_failures" validate:"omitempty,min=1"`            // Сколько ошибок подряд переводят слот в деградацию (по умолчанию 5)
+	SlotCooldown    time.Duration `yaml:"slot_cooldown" validate:"omitempty,min=100ms,max=5m"` // Сколько слот остается в деградации (по умолчанию 5s)
+}
+
+// RedisInspect - поиск медленных команд и больших ключей на стороне клиента (метрики redis_command_payload_bytes
+// и redis_command_problems_total, предупреждения в журнале). Замечает рост записей, например хэша сессии,
+// до того, как он станет проблемой самого Redis.
+type RedisInspect struct {
+	Enabled       bool          `yaml:"enabled"`
+	SlowCommand   time.Duration `yaml:"slow_command" validate:"omitempty,min=1ms"` // Время выполнения медленной команды (по умолчанию 100ms)
+	BigPayload    int           `yaml:"big_payload" validate:"omitempty,min=1"`    // Размер аргументов или ответа команды в байтах (по умолчанию 65536)
+	BigCollection int           `yaml:"big_collection" validate:"omitempty,min=1"` // Количество элементов коллекции (по умолчанию 1000)
+	LogInterval   time.Duration `yaml:"log_interval" validate:"omitempty,min=1s"`  // Как часто пишется предупреждение об одной проблеме команды (по умолчанию 1m)
+}
+
+// RedisTTL - TTL записей черного списка токенов, сессий и refresh токенов: срок действия записи плюс запас Slack.
+type RedisTTL struct {
+	Slack time.Duration `yaml:"slack" validate:"omitempty,min=1s,max=1h"` // Запас сверх срока действия записи (по умолчанию 1m)
+	Check RedisTTLCheck `yaml:"check"`
+}
+
+// RedisTTLCheck - периодический поиск записей, TTL которых расходится со сроком действия в записи
+// (метрика auth_redis_ttl_mismatched_keys). Записи не меняются.
+type RedisTTLCheck struct {
+	Enabled   bool          `yaml:"enabled"`
+	Interval  time.Duration `yaml:"interval" validate:"omitempty,min=1m"`  // Периодичность проверки (по умолчанию 1h)
+	Tolerance time.Duration `yaml:"tolerance" validate:"omitempty,min=1s"` // Допустимое расхождение TTL (по умолчанию 5m)
+}
+
+// RedisJanitor - периодический поиск ключей временных данных без TTL (метрика auth_redis_orphaned_keys).
+// Такие ключи остаются после ошибок или старых версий сервиса и никогда не удаляются сами.
+type RedisJanitor struct {
+	Enabled  bool          `yaml:"enabled"`
+	Interval time.Duration `yaml:"interval" validate:"omitempty,min=1m"`        // Периодичность обхода (по умолчанию 1h)
+	Delete   bool          `yaml:"delete"`                                      // Удалять ключи, которые были без TTL в двух обходах подряд
+	Patterns []string      `yaml:"patterns" validate:"omitempty,dive,required"` // Шаблоны ключей, которые должны истекать (по умолчанию все временные данные сервиса)
+}
+
+// Dependencies - конфигурация проверки внешних зависимостей (Vault, Redis).
+type Dependencies struct {
+	CheckInterval time.Duration `yaml:"check_interval" validate:"omitempty,min=100ms"` // Периодичность проверки (по умолчанию 5s)
+	CheckTimeout  time.Duration `yaml:"check_timeout" validate:"omitempty,min=10ms"`   // Таймаут одной проверки (по умолчанию 2s)
+	RetryAfter    time.Duration `yaml:"retry_after" validate:"omitempty,min=1s"`       // Значение заголовка Retry-After при 503 (по умолчанию равно check_interval)
+	ClockDrift    ClockDrift    `yaml:"clock_drift"`
+}
+
+// ClockDrift - проверка расхождения локальных часов с часами Vault, Redis и, если задан, сервера NTP.
+type ClockDrift struct {
+	Enabled   bool          `yaml:"enabled"`
+	Interval  time.Duration `yaml:"interval" validate:"omitempty,min=1s"`          // Периодичность проверки (по умолчанию 1m)
+	Threshold time.Duration `yaml:"threshold" validate:"omitempty,min=100ms"`      // Допустимое расхождение (по умолчанию 5s)
+	NTPServer string        `yaml:"ntp_server" validate:"omitempty,hostname_port"` // Сервер NTP, например pool.ntp.org:123 (опционально)
+}
+
+// ReadOnly - режим только для чтения: проверка и интроспекция токенов работают, а выпуск, регистрация
+// и отзыв отвечают 503. Включается через PUT /api/v0/admin/read-only или сам, если записи в Redis не проходят.
+type ReadOnly struct {
+	Enabled          bool          `yaml:"enabled"`
+	Auto             bool          `yaml:"auto"`                                         // Включать режим, когда записи в Redis подряд не проходят
+	FailureThreshold int           `yaml:"failure_threshold" validate:"omitempty,min=1"` // Сколько записей подряд должно не пройти (по умолчанию 5)
+	Cooldown         time.Duration `yaml:"cooldown" validate:"omitempty,min=1s"`         // Пауза перед следующей попыткой записи (по умолчанию 30s)
+}
+
+// Startup - конфигурация отчета о запуске.
+type Startup struct {
+	ReportPath string `yaml:"report_path"` // Путь к файлу, куда будет записан JSON отчет о запуске (опционально)
+	Warmup     Warmup `yaml:"warmup"`
+}
+
+// Warmup - прогрев перед началом приема запросов: чтение ключей подписи, открытие соединений с Redis,
+// выпуск и проверка одного токена. Сервер начинает слушать порт только после прогрева.
+type Warmup struct {
+	Enabled          bool          `yaml:"enabled"`
+	Timeout          time.Duration `yaml:"timeout" validate:"omitempty,min=100ms"`       // Таймаут прогрева (по умолчанию 10s)
+	RedisConnections int           `yaml:"redis_connections" validate:"omitempty,min=1"` // Количество соединений с Redis, открываемых заранее (по умолчанию 10)
+	Required         bool          `yaml:"required"`                                     // Если true, ошибка прогрева останавливает запуск, иначе только пишется в лог
+}
+
+// Admin - конфигурация административного API.
+type Admin struct {
+	Token       string      `yaml:"token"` // Токен доступа к административному API. Если не задан, API отключено
+	Capture     Capture     `yaml:"capture"`
+	LogSampling LogSampling `yaml:"log_sampling"`
+	Stats       Stats       `yaml:"stats"`
+	APIKeys     APIKeys     `yaml:"api_keys"`
+	LDAP        LDAP        `yaml:"ldap"`
+	SCIM        SCIM        `yaml:"scim"`
+}
+
+// SCIM - учетные записи администраторов, которые заводит и отключает корпоративный IdP
+// через /api/v0/scim/v2/Users. Если включен вместе с LDAP, вход через каталог разрешен только
+// активным учетным записям SCIM.
+type SCIM struct {
+	Enabled     bool   `yaml:"enabled"`
+	TokenSHA256 string `yaml:"token_sha256" validate:"required_if=Enabled true,omitempty,len=64,hexadecimal"` // SHA-256 (hex) bearer токена IdP
+}
+
+// LDAP - вход администраторов по учетным записям корпоративного каталога (LDAP/Active Directory).
+// Группы каталога сопоставляются с ролями административного API: viewer - только чтение, operator - полный доступ.
+type LDAP struct {
+	Enabled          bool                `yaml:"enabled"`
+	URL              string              `yaml:"url" validate:"required_if=Enabled true,omitempty,url"` // ldap://host:389 или ldaps://host:636
+	StartTLS         bool                `yaml:"start_tls"`                                             // StartTLS для адреса ldap://
+	CAPath           string              `yaml:"ca_path"`                                               // CA сервера каталога (по умолчанию системные)
+	BindDN           string              `yaml:"bind_dn"`                                               // Сервисная учетная запись для поиска пользователей. Пусто - анонимный поиск
+	BindPasswordPath string              `yaml:"bind_password_path" validate:"required_with=BindDN"`    // Секрет Vault KV v2 с паролем сервисной учетной записи в поле password
+	BaseDN           string              `yaml:"base_dn" validate:"required_if=Enabled true"`           // Корень поиска пользователей
+	UserFilter       string              `yaml:"user_filter"`                                           // Фильтр поиска пользователя с {username} (по умолчанию (&(objectClass=person)(uid={username})))
+	GroupAttribute   string              `yaml:"group_attribute"`                                       // Атрибут пользователя со списком групп (по умолчанию memberOf)
+	GroupBaseDN      string              `yaml:"group_base_dn"`                                         // Корень поиска групп (по умолчанию base_dn)
+	GroupFilter      string              `yaml:"group_filter"`                                          // Фильтр поиска групп с {dn} вместо group_attribute, например (&(objectClass=groupOfNames)(member={dn}))
+	GroupRoles       map[string][]string `yaml:"group_roles" validate:"required_if=Enabled true,dive,keys,required,endkeys,dive,oneof=viewer operator"`
+	Timeout          time.Duration       `yaml:"timeout" validate:"omitempty,min=1s"`           // Таймаут входа (по умолчанию 5s)
+	TokenTTL         time.Duration       `yaml:"token_ttl" validate:"omitempty,min=1m,max=24h"` // Время жизни токена администратора (по умолчанию 1h)
+}
+
+// APIKeys - выпуск API ключей через административное API.
+// Если задан VaultPath, секрет ключа записывается в Vault KV и не возвращается в ответе.
+type APIKeys struct {
+	Enabled   bool   `yaml:"enabled"`
+	VaultPath string `yaml:"vault_path"` // Путь KV, под которым сохраняются секреты: <vault_path>/<id>
+}
+
+// Capture - конфигурация выборочного захвата тел запросов для отладки.
+// Сам захват включается в рантайме через административное API.
+type Capture struct {
+	BufferSize  int `yaml:"buffer_size" validate:"omitempty,min=1,max=10000"` // Количество хранимых запросов (по умолчанию 100)
+	MaxBodySize int `yaml:"max_body_size" validate:"omitempty,min=1"`         // Максимальный размер сохраняемого тела в байтах (по умолчанию 4096)
+}
+
+// LogSampling - выборочное логирование запросов к шумным маршрутам.
+// Доли задаются в рантайме через административное API и хранятся в Redis.
+type LogSampling struct {
+	Enabled        bool          `yaml:"enabled"`
+	ReloadInterval time.Duration `yaml:"reload_interval" validate:"omitempty,min=1s"` // Периодичность перечитывания настроек из Redis (по умолчанию 10s)
+}
+
+// Stats - статистика для продуктовой аналитики: входы и уникальные пользователи по дням, активные
+// сессии и выпущенные токены по аудиториям. Хранится в Redis и отдается через GET /api/v0/admin/stats.
+type Stats struct {
+	Enabled   bool          `yaml:"enabled"`
+	Retention time.Duration `yaml:"retention" validate:"omitempty,min=24h"` // Срок хранения статистики за день (по умолчанию 2160h - 90 дней)
+}
+
+// RateLimit - ограничения частоты запросов с одного IP по группам эндпоинтов и запросов API ключей.
+// Ключу можно назначить тариф или собственное ограничение через административное API,
+// они хранятся в записи ключа и имеют приоритет над api_key. Требует включенных квот.
+type RateLimit struct {
+	Admin   RateLimitRule            `yaml:"admin"`
+	Guest   RateLimitRule            `yaml:"guest"`                                                 // Выпуск гостевых токенов (POST /api/v0/token/guest)
+	APIKey  RateLimitRule            `yaml:"api_key"`                                               // Ограничение для ключей без тарифа
+	Tiers   map[string]RateLimitRule `yaml:"tiers" validate:"omitempty,dive,keys,required,endkeys"` // Тарифы, которые можно назначить ключу
+	Observe bool                     `yaml:"observe"`                                               // Режим наблюдения: запросы сверх лимита не отклоняются, а только учитываются в логе и метрике
+}
+
+// RateLimitRule - не более Requests запросов за Window. Если правило не задано, ограничения нет.
+type RateLimitRule struct {
+	Requests int           `yaml:"requests" validate:"omitempty,min=1"`
+	Window   time.Duration `yaml:"window" validate:"required_with=Requests,omitempty,min=1s"`
+}
+
+// Token - конфигурация проверки токенов.
+type Token struct {
+	KeysPath string     `yaml:"keys_path"` // Путь к секрету Vault KV v2 с ключами подписи (по умолчанию secret/data/auth/signing-keys)
+	Grace    TokenGrace `yaml:"grace"`
+
+	SessionCheck TokenSessionCheck `yaml:"session_check"`
+
+	Impersonation Impersonation `yaml:"impersonation"`
+	Limits        TokenLimits   `yaml:"limits"`
+	Guest         TokenGuest    `yaml:"guest"`
+	Refresh       TokenRefresh  `yaml:"refresh"`
+	Rotation      TokenRotation `yaml:"rotation"`
+	Bundle        TokenBundle   `yaml:"bundle"`
+	Canary        TokenCanary   `yaml:"canary"`
+
+	ClaimSchemas map[string]TokenClaimSchema `yaml:"claim_schemas" validate:"omitempty,dive"` // Схемы пользовательских claims по аудиториям
+
+	// UserAudiences - аудитории токенов, которые принимает API пользователя (выход, сессии, аккаунт).
+	// Токены других аудиторий, в том числе администраторов (admin), получают 401. Если не задано, аудитория не проверяется
+	UserAudiences []string `yaml:"user_audiences" validate:"omitempty,dive,required"`
+
+	CoalesceValidation bool `yaml:"coalesce_validation"` // Объединять параллельные проверки одного и того же токена в одну
+}
+
+// TokenLimits - ограничения выпускаемых токенов, чтобы токены не упирались в лимиты заголовков шлюзов.
+type TokenLimits struct {
+	MaxTokenSize        int      `yaml:"max_token_size" validate:"omitempty,min=256"`         // Максимальный размер токена в байтах (по умолчанию 4096)
+	MaxCustomClaimsSize int      `yaml:"max_custom_claims_size" validate:"omitempty,min=1"`   // Максимальный размер пользовательских claims в байтах (по умолчанию 1024)
+	ForbiddenClaims     []string `yaml:"forbidden_claims" validate:"omitempty,dive,required"` // Запрещенные имена пользовательских claims в дополнение к claims сервиса
+}
+
+// TokenClaimSchema - допустимые пользовательские claims токенов аудитории (подмножество JSON Schema).
+// Токен не выпускается, если его claims не соответствуют схеме хотя бы одной из его аудиторий.
+type TokenClaimSchema struct {
+	AllowUnknown bool                          `yaml:"allow_unknown"`                        // Разрешить claims, не описанные в properties (по умолчанию отклоняются)
+	Properties   map[string]TokenClaimProperty `yaml:"properties" validate:"omitempty,dive"` // Допустимые claims
+}
+
+// TokenClaimProperty - ограничения значения пользовательского claim.
+type TokenClaimProperty struct {
+	Type      string              `yaml:"type" validate:"required,oneof=string number integer boolean array object"`
+	Enum      []string            `yaml:"enum"`                                  // Допустимые значения строки
+	Pattern   string              `yaml:"pattern"`                               // Регулярное выражение (RE2), которое должно находиться в строке
+	MaxLength int                 `yaml:"max_length" validate:"omitempty,min=1"` // Наибольшая длина строки в символах
+	Minimum   *float64            `yaml:"minimum"`                               // Наименьшее значение числа
+	Maximum   *float64            `yaml:"maximum"`                               // Наибольшее значение числа
+	MaxItems  int                 `yaml:"max_items" validate:"omitempty,min=1"`  // Наибольшее количество элементов массива
+	Items     *TokenClaimProperty `yaml:"items"`                                 // Схема элементов массива
+}
+
+// TokenGrace - мягкая проверка: токены аудиторий Audiences принимаются, если истекли не более чем Period назад.
+// Если Period не задан, истекшие токены не принимаются.
+type TokenGrace struct {
+	Period    time.Duration `yaml:"period" validate:"omitempty,min=1s,max=1h"`
+	Audiences []string      `yaml:"audiences" validate:"required_with=Period,omitempty,dive,required"`
+}
+
+// TokenSessionCheck - проверка сессии входа: токены аудиторий Audiences отклоняются сразу после
+// завершения их сессии (claim sid), а не после истечения. Каждая проверка - запрос к Redis, поэтому
+// проверка включается только для аудиторий, которым нужен немедленный выход. Нужны refresh токены.
+type TokenSessionCheck struct {
+	Audiences []string `yaml:"audiences" validate:"omitempty,dive,required"`
+}
+
+// TokenGuest - гостевые токены для еще не зарегистрированных пользователей (POST /api/v0/token/guest):
+// без пользователя, со scope guest и коротким временем жизни. Если аудитории не заданы, гостевые токены выключены.
+type TokenGuest struct {
+	TTL       time.Duration `yaml:"ttl" validate:"omitempty,min=1m,max=1h"`       // Время жизни токена (по умолчанию 15m)
+	Audiences []string      `yaml:"audiences" validate:"omitempty,dive,required"` // Аудитории гостевых токенов
+}
+
+// TokenRefresh - refresh токены с ротацией (POST /api/v0/token/refresh). Выдаются при входе пользователя
+// (passkey, QR, OAuth). Повторное использование замененного токена отзывает все токены этого входа.
+type TokenRefresh struct {
+	Enabled   bool          `yaml:"enabled"`
+	TTL       time.Duration `yaml:"ttl" validate:"omitempty,min=1m"`        // Время жизни токена без обновления (по умолчанию 720h)
+	FamilyTTL time.Duration `yaml:"family_ttl" validate:"omitempty,min=1m"` // Через сколько после входа нужно войти заново (по умолчанию 2160h)
+	AccessTTL time.Duration `yaml:"access_ttl" validate:"omitempty,min=1m"` // Время жизни токенов доступа, выпущенных при обновлении (по умолчанию 1h)
+
+	Sliding map[string]TokenSliding `yaml:"sliding" validate:"omitempty,dive,keys,required,endkeys"` // Скользящее истечение сессий по аудиториям
+}
+
+// TokenSliding - скользящее истечение сессии: сессия истекает, если Idle не было активности (проверки
+// ее токенов доступа или обновления refresh токена), но не позже MaxAge после входа. Каждая проверка
+// токена аудитории - запрос к Redis, как у token.session_check; истекшая сессия отклоняется сразу.
+type TokenSliding struct {
+	Idle   time.Duration `yaml:"idle" validate:"required,min=1m"`
+	MaxAge time.Duration `yaml:"max_age" validate:"omitempty,gtefield=Idle"` // Абсолютное время жизни сессии (по умолчанию family_ttl)
+}
+
+// TokenRotation - автоматическая ротация ключей подписи в секрете token.keys_path: каждые Period создается
+// новый ключ и становится текущим, замененный ключ принимается еще Grace. Токену Vault нужны права
+// create и update на секрет. Секрет без отметки о ротации (созданный вручную) ротируется при первой проверке.
+type TokenRotation struct {
+	Enabled       bool          `yaml:"enabled"`
+	Period        time.Duration `yaml:"period" validate:"required_if=Enabled true,omitempty,min=1h"` // Как долго ключ остается текущим
+	Grace         time.Duration `yaml:"grace" validate:"omitempty,min=1m"`                           // Сколько принимается замененный ключ (по умолчанию 24h), не меньше времени жизни токенов
+	CheckInterval time.Duration `yaml:"check_interval" validate:"omitempty,min=1s"`                  // Как часто проверять, не пора ли ротировать ключ (по умолчанию 1m)
+}
+
+// TokenBundle - пакет для проверки токенов без обращения к сервису (GET /api/v0/admin/verification-bundle,
+// команда export-bundle): kid ключей, отметки об отзыве, iss и аудитории, подписанные текущим ключом.
+type TokenBundle struct {
+	Enabled   bool          `yaml:"enabled"`
+	TTL       time.Duration `yaml:"ttl" validate:"omitempty,min=1m,max=168h"`     // Срок действия пакета (по умолчанию 24h)
+	Audiences []string      `yaml:"audiences" validate:"omitempty,dive,required"` // Аудитории, для которых пограничные сервисы принимают токены
+}
+
+// TokenCanary - проверка пробным токеном: при запуске и затем каждые Interval выпускается токен и проверяется
+// так же, как токены клиентов, а если включен token.bundle - еще и по пакету проверки. Результат - в метриках
+// auth_canary_* и в ответе GET /ready. Required - экземпляр не готов, пока проверка не проходит.
+type TokenCanary struct {
+	Enabled  bool          `yaml:"enabled"`
+	Interval time.Duration `yaml:"interval" validate:"omitempty,min=10s"`       // Периодичность проверки (по умолчанию 5m)
+	Timeout  time.Duration `yaml:"timeout" validate:"omitempty,min=100ms"`      // Таймаут одной проверки (по умолчанию 5s)
+	Audience []string      `yaml:"audience" validate:"omitempty,dive,required"` // Аудитории пробного токена
+	Required bool          `yaml:"required"`                                    // Не считать экземпляр готовым, пока проверка не проходит
+}
+
+// Impersonation - ограничения токенов имперсонации, которые выдаются сотрудникам поддержки через административное API.
+type Impersonation struct {
+	MaxTTL time.Duration `yaml:"max_ttl" validate:"omitempty,min=1m,max=1h"` // Максимальное время жизни токена (по умолчанию 15m)
+	Scopes []string      `yaml:"scopes" validate:"omitempty,dive,required"`  // Разрешенные scopes, только на чтение (по умолчанию read)
+}
+
+// Authz - конфигурация проверки доступа (POST /api/v0/authz/check).
+type Authz struct {
+	DecisionLog bool        `yaml:"decision_log"` // Писать каждое решение в лог
+	Policy      AuthzPolicy `yaml:"policy"`
+}
+
+// AuthzPolicy - источник политик Casbin: файлы или секрет Vault (поля model и policy).
+// Если источник не задан, используются встроенные правила по ролям в группах.
+type AuthzPolicy struct {
+	ModelPath      string        `yaml:"model_path" validate:"required_with=RulesPath"`
+	RulesPath      string        `yaml:"rules_path" validate:"required_with=ModelPath"`
+	VaultPath      string        `yaml:"vault_path" validate:"excluded_with=ModelPath"`
+	ReloadInterval time.Duration `yaml:"reload_interval" validate:"omitempty,min=1s"` // Периодичность перечитывания политик (по умолчанию 30s)
+}
+
+// LoadShedding - сброс нагрузки: количество одновременно обрабатываемых запросов ограничивается
+// по классам эндпоинтов (info, validation, session, issuance). Запрос, который не дождется обработки
+// за budget, сразу получает 503 с Retry-After. Классы без ограничения не ограничиваются.
+type LoadShedding struct {
+	Enabled bool                         `yaml:"enabled"`
+	Classes map[string]LoadSheddingLimit `yaml:"classes" validate:"omitempty,dive,keys,oneof=info validation session issuance,endkeys"`
+}
+
+// LoadSheddingLimit - ограничение класса эндпоинтов.
+type LoadSheddingLimit struct {
+	MaxInFlight int           `yaml:"max_in_flight" validate:"required,min=1"` // Сколько запросов обрабатываются одновременно
+	MaxQueue    int           `yaml:"max_queue" validate:"omitempty,min=0"`    // Сколько запросов могут ждать обработки (по умолчанию 0 - не ждут)
+	Budget      time.Duration `yaml:"budget" validate:"required,min=1ms"`      // Сколько запрос может ждать обработки
+
+	Adaptive LoadSheddingAdaptive `yaml:"adaptive"`
+}
+
+// LoadSheddingAdaptive - адаптивное ограничение класса по задержке Vault: когда Vault отвечает
+// медленнее обычного или с ошибками, ограничение снижается, пока не опустится до min_in_flight,
+// затем постепенно растет до max_in_flight. Поддерживается только для класса issuance.
+type LoadSheddingAdaptive struct {
+	Enabled     bool    `yaml:"enabled"`
+	MinInFlight int     `yaml:"min_in_flight" validate:"omitempty,min=1"` // Нижняя граница ограничения (по умолчанию 1)
+	Tolerance   float64 `yaml:"tolerance" validate:"omitempty,gt=1"`      // Во сколько раз задержка может превышать обычную (по умолчанию 2)
+	Backoff     float64 `yaml:"backoff" validate:"omitempty,gt=0,lt=1"`   // Множитель снижения ограничения (по умолчанию 0.9)
+}
+
+// SLO - учет индикаторов уровня обслуживания: хороших и плохих запросов по классам эндпоинтов
+// (info, validation, session, issuance) для оповещений по скорости расходования бюджета ошибок.
+// Все классы учитываются с целью по умолчанию (плохие - 5xx и дольше 500ms), classes ее переопределяют.
+type SLO struct {
+	Enabled bool                    `yaml:"enabled"`
+	Classes map[string]SLOObjective `yaml:"classes" validate:"omitempty,dive,keys,oneof=info validation session issuance,endkeys"`
+}
+
+// SLOObjective - что считается плохим запросом класса эндпоинтов.
+type SLOObjective struct {
+	BadStatuses []string      `yaml:"bad_statuses" validate:"omitempty,dive,required"` // Плохие коды ответа: точные (429) или классы (5xx), по умолчанию 5xx
+	Latency     time.Duration `yaml:"latency" validate:"omitempty,min=1ms"`            // Порог задержки (по умолчанию 500ms)
+}
+
+// ProofOfWork - proof-of-work защита (hashcash) часто атакуемых неаутентифицированных эндпоинтов.
+// Если маршруты не заданы, защита отключена.
+type ProofOfWork struct {
+	Routes     []string      `yaml:"routes" validate:"omitempty,dive,startswith=/"` // Шаблоны маршрутов, например /api/v0/otp/request
+	Difficulty int           `yaml:"difficulty" validate:"omitempty,min=1,max=32"`  // Количество нулевых бит в начале хэша (по умолчанию 20)
+	TTL        time.Duration `yaml:"ttl" validate:"omitempty,min=10s,max=10m"`      // Время жизни challenge (по умолчанию 2m)
+	Secret     string        `yaml:"secret"`                                        // Ключ подписи challenge, общий для всех экземпляров (по умолчанию случайный)
+}
+
+// OAuth - вход через внешних провайдеров (Google, GitHub, OpenID Connect). Пользователь провайдера
+// привязывается к локальному субъекту по подтвержденной почте, связи хранятся в Redis.
+type OAuth struct {
+	Enabled    bool            `yaml:"enabled"`
+	Providers  []OAuthProvider `yaml:"providers" validate:"required_if=Enabled true,dive"`
+	SuccessURL string          `yaml:"success_url" validate:"omitempty,url"`         // Куда перенаправить браузер с токеном во фрагменте адреса. Без него callback отвечает JSON
+	StateTTL   time.Duration   `yaml:"state_ttl" validate:"omitempty,min=1m,max=1h"` // Сколько действует начатый вход (по умолчанию 10m)
+	TokenTTL   time.Duration   `yaml:"token_ttl" validate:"omitempty,min=1m"`        // Время жизни токена после входа (по умолчанию 1h)
+	Audience   []string        `yaml:"audience"`                                     // Аудитория токена после входа
+	// EmailChange - смена почты с подтверждением со старого и нового адресов. Доступна, если включена отправка писем.
+	EmailChange OAuthEmailChange `yaml:"email_change"`
+}
+
+// OAuthEmailChange - смена почты аккаунта.
+type OAuthEmailChange struct {
+	TTL            time.Duration `yaml:"ttl" validate:"omitempty,min=10m,max=168h"` // Сколько ждет подтверждения смена почты (по умолчанию 24h)
+	ConfirmURL     string        `yaml:"confirm_url" validate:"omitempty,uri"`      // Страница фронтенда, подтверждающая смену кодом из параметра code (относительный путь - от server.external_url). Без нее в письме только код
+	RevokeSessions bool          `yaml:"revoke_sessions"`                           // Отозвать токены пользователя после смены почты (нужен revocation)
+}
+
+// OAuthProvider - внешний провайдер OAuth. Для google и github адреса известны заранее.
+type OAuthProvider struct {
+	Name            string   `yaml:"name" validate:"required,alphanum"`                 // Имя в адресе /api/v0/oauth/{name}/start
+	Kind            string   `yaml:"kind" validate:"required,oneof=google github oidc"` // Тип провайдера
+	AuthURL         string   `yaml:"auth_url" validate:"required_if=Kind oidc,omitempty,url"`
+	TokenURL        string   `yaml:"token_url" validate:"required_if=Kind oidc,omitempty,url"`
+	UserInfoURL     string   `yaml:"userinfo_url" validate:"required_if=Kind oidc,omitempty,url"`
+	Scopes          []string `yaml:"scopes"`                               // Запрашиваемые scopes (по умолчанию зависят от типа)
+	RedirectURL     string   `yaml:"redirect_url" validate:"required,uri"` // Адрес /api/v0/oauth/{name}/callback, зарегистрированный у провайдера (относительный путь - от server.external_url)
+	CredentialsPath string   `yaml:"credentials_path"`                     // Секрет Vault KV v2 с client_id и client_secret (по умолчанию secret/data/auth/oauth/{name})
+}
+
+// WebAuthn - вход по passkey в веб-интерфейсе бота. Ключи хранятся в Redis.
+type WebAuthn struct {
+	Enabled          bool          `yaml:"enabled"`
+	RPID             string        `yaml:"rp_id" validate:"required_if=Enabled true"`                       // Домен сайта, к которому привязываются ключи
+	RPName           string        `yaml:"rp_name"`                                                         // Название сайта для пользователя (по умолчанию rp_id)
+	Origins          []string      `yaml:"origins" validate:"required_if=Enabled true,dive,url"`            // Адреса страниц, с которых разрешены вызовы WebAuthn
+	Timeout          time.Duration `yaml:"timeout" validate:"omitempty,min=30s"`                            // Сколько действует challenge (по умолчанию 5m)
+	UserVerification string        `yaml:"user_verification" validate:"omitempty,oneof=required preferred"` // Проверка пользователя аутентификатором (по умолчанию preferred)
+	// Attestation - запрашиваемый attestation. Attestation statement не проверяется, поэтому поддерживается только none.
+	Attestation string        `yaml:"attestation" validate:"omitempty,oneof=none"`
+	TokenTTL    time.Duration `yaml:"token_ttl" validate:"omitempty,min=1m"` // Время жизни токена после входа (по умолчанию 1h)
+	Audience    []string      `yaml:"audience"`                              // Аудитория токена после входа
+}
+
+// QRLogin - вход в браузере по QR коду, подтвержденному из авторизованного приложения или бота.
+type QRLogin struct {
+	Enabled       bool          `yaml:"enabled"`
+	TTL           time.Duration `yaml:"ttl" validate:"omitempty,min=10s,max=10m"` // Сколько действует код (по умолчанию 2m)
+	TokenTTL      time.Duration `yaml:"token_ttl" validate:"omitempty,min=1m"`    // Время жизни токена браузера (по умолчанию 1h)
+	Audience      []string      `yaml:"audience"`                                 // Аудитория токена браузера
+	PayloadPrefix string        `yaml:"payload_prefix"`                           // Префикс содержимого QR, после него идет код
+}
+
+// Jobs - асинхронные задания административного API.
+type Jobs struct {
+	TTL time.Duration `yaml:"ttl" validate:"omitempty,min=1m"` // Сколько хранится задание после последнего обновления (по умолчанию 24h)
+}
+
+// Revocation - отзыв всех токенов пользователя через административное API.
+// Отзыв выполняется асинхронно, при проверке токена читается отметка об отзыве из Redis.
+type Revocation struct {
+	Enabled   bool          `yaml:"enabled"`
+	Retention time.Duration `yaml:"retention" validate:"omitempty,min=1h"` // Сколько хранится отметка об отзыве, не меньше срока жизни токенов (по умолчанию 720h)
+
+	Replication RevocationReplication `yaml:"replication"`
+}
+
+// RevocationReplication - репликация отзыва токенов между регионами: события об отзыве, отключении
+// и включении пользователей читаются из stream Redis других регионов и применяются к локальному отзыву.
+// Требует events с заданным region. При конфликте побеждает отзыв.
+type RevocationReplication struct {
+	Enabled bool             `yaml:"enabled"`
+	Peers   map[string]Redis `yaml:"peers" validate:"required_if=Enabled true,dive"` // Redis других регионов по именам регионов
+}
+
+// Events - публикация событий сервиса (например, отключение пользователя) в Redis stream.
+type Events struct {
+	Enabled bool   `yaml:"enabled"`
+	Stream  string `yaml:"stream"`                             // Stream событий (по умолчанию auth:events)
+	MaxLen  int64  `yaml:"max_len" validate:"omitempty,min=1"` // Примерное количество хранимых событий (по умолчанию 10000)
+	Region  string `yaml:"region"`                             // Регион экземпляра, записывается в события (нужен для репликации отзыва)
+}
+
+// Abuse - обнаружение перебора учетных данных (credential stuffing) и ловушки. Источник, из которого
+// за окно не удались входы с большим количеством разных логинов, или обратившийся к ловушке,
+// временно блокируется в denylist Redis. Пороги 0 - источник такого вида не блокируется.
+type Abuse struct {
+	Enabled                bool          `yaml:"enabled"`
+	Window                 time.Duration `yaml:"window" validate:"omitempty,min=1s"`                  // Окно, в котором считаются неудачные входы (по умолчанию 10m)
+	BanTTL                 time.Duration `yaml:"ban_ttl" validate:"omitempty,min=1s"`                 // Срок блокировки (по умолчанию 1h)
+	IPUniqueUsernames      int64         `yaml:"ip_unique_usernames" validate:"omitempty,min=1"`      // Разных логинов с неудачным входом с одного IP за окно
+	NetworkUniqueUsernames int64         `yaml:"network_unique_usernames" validate:"omitempty,min=1"` // То же для сети (ipv4_prefix, ipv6_prefix)
+	ASNUniqueUsernames     int64         `yaml:"asn_unique_usernames" validate:"omitempty,min=1"`     // То же для ASN. Требует asn_header
+	IPv4Prefix             int           `yaml:"ipv4_prefix" validate:"omitempty,min=8,max=32"`       // Длина префикса сети IPv4 (по умолчанию 24)
+	IPv6Prefix             int           `yaml:"ipv6_prefix" validate:"omitempty,min=16,max=128"`     // Длина префикса сети IPv6 (по умолчанию 64)
+	ASNHeader              string        `yaml:"asn_header"`                                          // Заголовок доверенного прокси с номером ASN клиента
+	Honeypots              []string      `yaml:"honeypots" validate:"omitempty,dive,startswith=/"`    // Пути ловушек. Путь с "/" на конце совпадает со всеми вложенными
+	Observe                bool          `yaml:"observe"`                                             // Режим наблюдения: источники не блокируются автоматически, а только учитываются в логе и метрике
+}
+
+// PasswordBreach - проверка паролей при регистрации и смене пароля по базе утечек HaveIBeenPwned
+// (POST /api/v0/credentials/check). В API уходят только первые 5 символов SHA-1 пароля.
+type PasswordBreach struct {
+	Enabled  bool          `yaml:"enabled"`
+	URL      string        `yaml:"url" validate:"omitempty,url"`         // Адрес API Pwned Passwords или его зеркала (по умолчанию https://api.pwnedpasswords.com)
+	Timeout  time.Duration `yaml:"timeout" validate:"omitempty,min=1ms"` // Таймаут запроса (по умолчанию 2s)
+	FailOpen bool          `yaml:"fail_open"`                            // Принимать пароль без проверки, если API недоступно. Иначе проверка отвечает 503
+	MinCount int           `yaml:"min_count" validate:"omitempty,min=1"` // Сколько раз пароль должен встретиться в утечках, чтобы быть отклоненным (по умолчанию 1)
+}
+
+// Mail - отправка писем через SMTP (подтверждение смены почты).
+type Mail struct {
+	Enabled         bool          `yaml:"enabled"`
+	Server          string        `yaml:"server" validate:"required_if=Enabled true,omitempty,hostname_port"` // Адрес SMTP сервера host:port. STARTTLS включается, если сервер его поддерживает
+	From            string        `yaml:"from" validate:"required_if=Enabled true"`                           // Отправитель, например "Zanuda <auth@zanuda.example>"
+	Timeout         time.Duration `yaml:"timeout" validate:"omitempty,min=1s"`                                // Таймаут отправки письма (по умолчанию 10s)
+	CredentialsPath string        `yaml:"credentials_path"`                                                   // Секрет Vault KV v2 с username и password. Без него письма отправляются без аутентификации
+}
+
+// StateKeys - ключи шифрования значений, которые отдаются браузеру (state входа через OAuth).
+// Хранятся в отдельном от ключей подписи jwt секрете Vault и меняются по своему расписанию.
+type StateKeys struct {
+	Enabled        bool          `yaml:"enabled"`
+	VaultPath      string        `yaml:"vault_path"`                                  // Секрет Vault KV v2 с ключами (по умолчанию secret/data/auth/state-keys)
+	ReloadInterval time.Duration `yaml:"reload_interval" validate:"omitempty,min=1s"` // Периодичность перечитывания ключей (по умолчанию 1m)
+	RotationPeriod time.Duration `yaml:"rotation_period" validate:"omitempty,min=1h"` // Период ротации ключа. Должен быть больше срока жизни зашифрованных значений. 0 - ключи меняются вручную
+}
+
+// Telegram - секреты Telegram бота. Токен бота (им подписываются initData мини-приложений) читается
+// из Vault при старте и перечитывается, поэтому смена токена не требует перезапуска.
+type Telegram struct {
+	Enabled        bool          `yaml:"enabled"`
+	VaultPath      string        `yaml:"vault_path"`                                  // Секрет Vault KV v2 с полем bot_token (по умолчанию secret/data/auth/telegram)
+	ReloadInterval time.Duration `yaml:"reload_interval" validate:"omitempty,min=1s"` // Периодичность перечитывания токена (по умолчанию 5m). При ошибке Vault действует предыдущий
+
+	Login TelegramLogin `yaml:"login"`
+}
+
+// TelegramLogin - вход пользователей Telegram (POST /api/v0/auth/telegram) по initData мини-приложения
+// или данным виджета входа, подписанным токеном бота. Требует включенного telegram. Если подключен
+// сервис пользователей (user_store), входит только зарегистрированный в нем пользователь, и субъект
+// токена - его ID, иначе субъект - telegram:<ID в Telegram>.
+type TelegramLogin struct {
+	Enabled  bool          `yaml:"enabled"`
+	MaxAge   time.Duration `yaml:"max_age" validate:"omitempty,min=1m"`   // Сколько действуют данные входа с момента подписи (по умолчанию 24h)
+	TokenTTL time.Duration `yaml:"token_ttl" validate:"omitempty,min=1m"` // Время жизни токена (по умолчанию 1h)
+	Audience []string      `yaml:"audience"`                              // Аудитория токена
+}
+
+// UserStore - внешний сервис пользователей: сервис авторизации запрашивает у него пользователей
+// по ID и по ID в Telegram и проверку пароля. Доступность сервиса видна в /health.
+type UserStore struct {
+	Enabled    bool          `yaml:"enabled"`
+	URL        string        `yaml:"url" validate:"required_if=Enabled true,omitempty,url"` // Адрес API сервиса пользователей, например http://users:8080/api/v1
+	TokenPath  string        `yaml:"token_path"`                                            // Секрет Vault KV v2 с токеном доступа к сервису в поле token. Пусто - запросы без токена
+	Timeout    time.Duration `yaml:"timeout" validate:"omitempty,min=1ms"`                  // Таймаут запроса (по умолчанию 2s)
+	HealthPath string        `yaml:"health_path" validate:"omitempty,startswith=/"`         // Путь проверки доступности (по умолчанию /health)
+	Cache      UserCache     `yaml:"cache"`
+}
+
+// UserCache - кэш поиска пользователей в Redis, защищающий сервис пользователей от всплесков входов.
+// Проверка пароля не кэшируется.
+type UserCache struct {
+	Enabled     bool          `yaml:"enabled"`
+	TTL         time.Duration `yaml:"ttl" validate:"omitempty,min=1s"`          // Сколько кэшируется найденный пользователь (по умолчанию 30s)
+	NegativeTTL time.Duration `yaml:"negative_ttl" validate:"omitempty,min=1s"` // Сколько кэшируется отсутствие пользователя (по умолчанию 5s)
+}
+
+// Notifications - уведомления пользователей о новом входе и смене пароля. Пользователь выбирает,
+// о чем уведомлять и куда, настройки хранятся в Redis.
+type Notifications struct {
+	Enabled  bool                 `yaml:"enabled"`
+	Telegram bool                 `yaml:"telegram"` // Канал telegram: уведомления публикуются событиями notify.* в stream, бот доставляет их в чат (нужен events)
+	Defaults NotificationDefaults `yaml:"defaults"` // Настройки пользователей, которые их не меняли. Не заданы - все уведомления включены, канал - email, если он доступен
+}
+
+// NotificationDefaults - настройки уведомлений по умолчанию. Канал email доступен, если включены mail и oauth.
+type NotificationDefaults struct {
+	NewLogin       bool   `yaml:"new_login"`
+	PasswordChange bool   `yaml:"password_change"`
+	Channel        string `yaml:"channel" validate:"omitempty,oneof=email telegram"`
+}
+
+// CredentialsPolicy - политика логинов и паролей, которую проверяет POST /api/v0/credentials/check
+// при регистрации и смене пароля. Нулевые значения - требование не проверяется.
+type CredentialsPolicy struct {
+	Enabled  bool           `yaml:"enabled"`
+	Password PasswordPolicy `yaml:"password"`
+	Username UsernamePolicy `yaml:"username"`
+}
+
+// PasswordPolicy - требования к паролю.
+type PasswordPolicy struct {
+	MinLength        int      `yaml:"min_length" validate:"omitempty,min=1"`
+	MaxLength        int      `yaml:"max_length" validate:"omitempty,min=1"`
+	RequireLowercase bool     `yaml:"require_lowercase"`
+	RequireUppercase bool     `yaml:"require_uppercase"`
+	RequireDigit     bool     `yaml:"require_digit"`
+	RequireSymbol    bool     `yaml:"require_symbol"`
+	MinClasses       int      `yaml:"min_classes" validate:"omitempty,min=1,max=4"` // Сколько классов символов из 4 (строчные, прописные, цифры, прочие) должно быть в пароле
+	Banned           []string `yaml:"banned"`                                       // Запрещенные пароли (без учета регистра)
+	DisallowUsername bool     `yaml:"disallow_username"`                            // Пароль не должен содержать логин
+}
+
+// UsernamePolicy - требования к логину.
+type UsernamePolicy struct {
+	MinLength int      `yaml:"min_length" validate:"omitempty,min=1"`
+	MaxLength int      `yaml:"max_length" validate:"omitempty,min=1"`
+	Pattern   string   `yaml:"pattern"`  // Регулярное выражение, которому должен соответствовать весь логин
+	Reserved  []string `yaml:"reserved"` // Зарезервированные логины (без учета регистра)
+}
+
+// Quota - учет квот API ключей (заголовок X-API-Key) по суткам и месяцам в Redis.
+// Квоты из записи ключа в Redis имеют приоритет над конфигурацией.
+type Quota struct {
+	Enabled bool                   `yaml:"enabled"`
+	Default QuotaLimits            `yaml:"default"`                                              // Квоты по умолчанию (без ограничения, если не заданы)
+	Keys    map[string]QuotaLimits `yaml:"keys" validate:"omitempty,dive,keys,required,endkeys"` // Квоты отдельных ключей по ID
+}
+
+// QuotaLimits - квоты за сутки и месяц. Нулевое значение - без ограничения.
+type QuotaLimits struct {
+	Daily   int64 `yaml:"daily" validate:"omitempty,min=1"`
+	Monthly int64 `yaml:"monthly" validate:"omitempty,min=1"`
+}
+
+// Sandbox - песочница для разработчиков партнеров. Токены аудиторий песочницы помечаются
+// claim env=sandbox и живут не дольше TTL, ключи API песочницы удаляются через TTL.
+type Sandbox struct {
+	Audiences []string      `yaml:"audiences" validate:"omitempty,dive,required"`
+	TTL       time.Duration `yaml:"ttl" validate:"omitempty,min=1m,max=24h"` // Время жизни токенов и ключей песочницы (по умолчанию 24h)
+}
+
+// SPIFFE - экспериментальный выпуск SPIFFE SVID внутренним сервисам по bootstrap токену.
+type SPIFFE struct {
+	Enabled     bool             `yaml:"enabled"`
+	TrustDomain string           `yaml:"trust_domain" validate:"required_if=Enabled true,omitempty,fqdn"` // Trust domain, например example.org
+	TTL         time.Duration    `yaml:"ttl" validate:"omitempty,min=1m,max=1h"`                          // Время жизни SVID (по умолчанию 5m)
+	PKISignPath string           `yaml:"pki_sign_path"`                                                   // Путь роли Vault PKI для подписи X.509-SVID, например pki_int/sign/spiffe. Без него X.509-SVID не выдаются
+	JWT         bool             `yaml:"jwt"`                                                             // Выдавать JWT-SVID, подписанные отдельным ключом jwt_key_path
+	Workloads   []SPIFFEWorkload `yaml:"workloads" validate:"required_if=Enabled true,dive"`
+
+	JWTKeyPath   string   `yaml:"jwt_key_path" validate:"required_if=JWT true"`                          // Путь секрета KV v2 с полем private_key: закрытый ключ ECDSA P-256 или RSA в PEM для подписи JWT-SVID
+	JWTAudiences []string `yaml:"jwt_audiences" validate:"required_if=JWT true,omitempty,dive,required"` // Аудитории, для которых выдаются JWT-SVID
+}
+
+// SPIFFEWorkload - сервис, которому выдаются SVID.
+type SPIFFEWorkload struct {
+	Path        string `yaml:"path" validate:"required,startswith=/"`               // Путь SPIFFE ID, например /ns/bots/sa/notes
+	TokenSHA256 string `yaml:"token_sha256" validate:"required,len=64,hexadecimal"` // SHA-256 (hex) bootstrap токена сервиса
 }
 
 // LoadConfig загружает конфигурацию.
@@ -80,9 +840,67 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("config: error validate redis: %w", err)
 	}
 
+	if err := cfg.resolveExternalLinks(); err != nil {
+		return nil, fmt.Errorf("config: error validate external url: %w", err)
+	}
+
+	if err := cfg.checkEnvironment(); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
+// resolveExternalLinks проверяет внешний адрес сервиса и дополняет им относительные ссылки,
+// которые уходят пользователям и провайдерам OAuth.
+func (cfg *Config) resolveExternalLinks() error {
+	var base *url.URL
+
+	if cfg.Server.ExternalURL != "" {
+		u, err := url.Parse(cfg.Server.ExternalURL)
+		if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") || u.RawQuery != "" || u.Fragment != "" {
+			return fmt.Errorf("config: external_url must be an absolute http(s) url without query, got %q", cfg.Server.ExternalURL)
+		}
+
+		u.Path = strings.TrimSuffix(u.Path, "/")
+		cfg.Server.ExternalURL = u.String()
+		base = u
+	}
+
+	resolve := func(name string, link *string) error {
+		if *link == "" {
+			return nil
+		}
+
+		u, err := url.Parse(*link)
+		if err != nil {
+			return fmt.Errorf("config: invalid %s: %w", name, err)
+		}
+
+		if u.IsAbs() {
+			return nil
+		}
+
+		if base == nil {
+			return fmt.Errorf("config: relative %s %q requires server.external_url", name, *link)
+		}
+
+		resolved := base.JoinPath(u.Path)
+		resolved.RawQuery = u.RawQuery
+		*link = resolved.String()
+
+		return nil
+	}
+
+	for i := range cfg.OAuth.Providers {
+		if err := resolve("oauth redirect_url", &cfg.OAuth.Providers[i].RedirectURL); err != nil {
+			return err
+		}
+	}
+
+	return resolve("oauth email_change confirm_url", &cfg.OAuth.EmailChange.ConfirmURL)
+}
+
 func (cfg *Config) validateRedisConfig() error {
 	switch cfg.Redis.Type {
 	case RedisTypeSingle:
@@ -116,5 +934,45 @@ func validateRedisClusterConfig(cfg *Redis) error {
 		return fmt.Errorf("config: host and port are not allowed for cluster redis")
 	}
 
+	if cfg.Cluster.MinRetryBackoff != 0 && cfg.Cluster.MaxRetryBackoff != 0 && cfg.Cluster.MinRetryBackoff > cfg.Cluster.MaxRetryBackoff {
+		return fmt.Errorf("config: cluster min_retry_backoff must not exceed max_retry_backoff")
+	}
+
 	return nil
 }
+
+// Tracing - трассировка OpenTelemetry: спаны входящих запросов, запросов к Vault и команд Redis
+// отправляются по OTLP в коллектор. Контекст трассировки берется из заголовка traceparent.
+type Tracing struct {
+	Enabled         bool            `yaml:"enabled"`
+	ServiceName     string          `yaml:"service_name"`                                  // Имя сервиса в спанах (по умолчанию auth-service)
+	SampleRatio     float64         `yaml:"sample_ratio" validate:"omitempty,gt=0,max=1"`  // Доля трассировок, которые начинает сервис (по умолчанию 1); решение вызывающей стороны соблюдается
+	ShutdownTimeout time.Duration   `yaml:"shutdown_timeout" validate:"omitempty,min=1ms"` // Сколько ждать отправки накопленных спанов при остановке (по умолчанию server.shutdown_timeout)
+	Exporter        TracingExporter `yaml:"exporter"`
+}
+
+// TracingExporter - экспорт спанов по OTLP.
+type TracingExporter struct {
+	Protocol string            `yaml:"protocol" validate:"omitempty,oneof=grpc http"` // Протокол: grpc (порт 4317) или http (порт 4318), по умолчанию grpc
+	Endpoint string            `yaml:"endpoint" validate:"omitempty,hostname_port"`   // Адрес коллектора, например otel-collector:4317
+	Insecure bool              `yaml:"insecure"`                                      // Отправлять без TLS
+	Headers  map[string]string `yaml:"headers"`                                       // Заголовки запросов к коллектору, например для авторизации
+	Timeout  time.Duration     `yaml:"timeout" validate:"omitempty,min=100ms"`        // Таймаут отправки пачки спанов (по умолчанию 10s)
+}
+
+// Peers - токены, которые выпускают другие сервисы bot-zanuda для вызовов между сервисами.
+// Открытые ключи доверенных сервисов загружаются по их JWKS, токены принимаются только на маршрутах routes.
+type Peers struct {
+	Enabled         bool          `yaml:"enabled"`
+	Audience        string        `yaml:"audience" validate:"required_if=Enabled true"`                           // Значение aud, которое peer сервисы указывают в токенах для этого сервиса
+	RefreshInterval time.Duration `yaml:"refresh_interval" validate:"omitempty,min=1m"`                           // Периодичность обновления ключей (по умолчанию 10m)
+	Routes          []string      `yaml:"routes" validate:"required_if=Enabled true,omitempty,dive,startswith=/"` // Шаблоны маршрутов, на которых требуется токен peer сервиса
+	Issuers         []PeerIssuer  `yaml:"issuers" validate:"required_if=Enabled true,omitempty,dive"`             // Доверенные peer сервисы
+}
+
+// PeerIssuer - доверенный peer сервис.
+type PeerIssuer struct {
+	Name    string `yaml:"name" validate:"required"`         // Имя сервиса в логах и метриках
+	Issuer  string `yaml:"issuer" validate:"required"`       // Значение iss в токенах сервиса
+	JWKSURL string `yaml:"jwks_url" validate:"required,url"` // Адрес JWKS с открытыми ключами сервиса
+}
@@ -31,9 +31,10 @@ func TestLoadConfig(t *testing.T) {
 					Token:   "vault-token",
 				},
 				Redis: Redis{
-					Type: RedisTypeSingle,
-					Host: "localhost",
-					Port: 6379,
+					Type:           RedisTypeSingle,
+					Host:           "localhost",
+					Port:           6379,
+					CommandTimeout: 500 * time.Millisecond,
 				},
 			},
 			wantErr: require.NoError,
@@ -43,6 +44,98 @@ func TestLoadConfig(t *testing.T) {
 			configFile: "testdata/invalid.yaml",
 			wantErr:    require.Error,
 		},
+		{
+			name:       "invalid config: trusted proxies and real ip header",
+			configFile: "testdata/invalid_real_ip.yaml",
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "TrustedProxies[0]")
+				require.ErrorContains(t, err, "RealIPHeader")
+			},
+		},
+		{
+			name:       "invalid config: authz policy from files and vault",
+			configFile: "testdata/invalid_authz_policy.yaml",
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "RulesPath")
+				require.ErrorContains(t, err, "VaultPath")
+			},
+		},
+		{
+			name:       "invalid config: proof of work",
+			configFile: "testdata/invalid_proof_of_work.yaml",
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "Routes[0]")
+				require.ErrorContains(t, err, "Difficulty")
+			},
+		},
+		{
+			name:       "invalid config: negative quota",
+			configFile: "testdata/invalid_quota.yaml",
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "Daily")
+			},
+		},
+		{
+			name:       "invalid config: server tls",
+			configFile: "testdata/invalid_server_tls.yaml",
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "CommonName")
+				require.ErrorContains(t, err, "IPSANs[0]")
+			},
+		},
+		{
+			name:       "invalid config: spiffe workload",
+			configFile: "testdata/invalid_spiffe.yaml",
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "Workloads[0].Path")
+				require.ErrorContains(t, err, "Workloads[0].TokenSHA256")
+				require.ErrorContains(t, err, "JWTKeyPath")
+				require.ErrorContains(t, err, "JWTAudiences")
+			},
+		},
+		{
+			name:       "invalid config: external url with query",
+			configFile: "testdata/invalid_external_url.yaml",
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "external_url must be an absolute http(s) url")
+			},
+		},
+		{
+			name:       "invalid config: relative link without external url",
+			configFile: "testdata/invalid_relative_link.yaml",
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "requires server.external_url")
+			},
+		},
+		{
+			name:       "invalid config: vault tls pin with insecure skip tls",
+			configFile: "testdata/invalid_vault_tls_pin.yaml",
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "TLSPinFile")
+			},
+		},
+		{
+			name:       "invalid config: insecure prod",
+			configFile: "testdata/invalid_prod.yaml",
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "vault.insecure_skip_tls is enabled")
+				require.ErrorContains(t, err, "log_level is debug")
+			},
+		},
+		{
+			name:       "invalid config: token grace without audiences",
+			configFile: "testdata/invalid_token_grace.yaml",
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "Audiences")
+			},
+		},
+		{
+			name:       "invalid config: claim schema with unknown type",
+			configFile: "testdata/invalid_claim_schema.yaml",
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "Items.Type")
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -131,6 +224,20 @@ func TestValidateRedisConfig(t *testing.T) {
 			},
 			wantErr: require.Error,
 		},
+		{
+			name: "invalid config: cluster min retry backoff exceeds max",
+			cfg: &Config{
+				Redis: Redis{
+					Type:  RedisTypeCluster,
+					Addrs: []string{"localhost:6379"},
+					Cluster: RedisCluster{
+						MinRetryBackoff: time.Second,
+						MaxRetryBackoff: 100 * time.Millisecond,
+					},
+				},
+			},
+			wantErr: require.Error,
+		},
 	}
 
 	for _, tt := range tests {
@@ -234,6 +341,20 @@ func TestValidateRedisClusterConfig(t *testing.T) {
 			},
 			wantErr: require.Error,
 		},
+		{
+			name: "invalid config: cluster min retry backoff exceeds max",
+			cfg: &Config{
+				Redis: Redis{
+					Type:  RedisTypeCluster,
+					Addrs: []string{"localhost:6379"},
+					Cluster: RedisCluster{
+						MinRetryBackoff: time.Second,
+						MaxRetryBackoff: 100 * time.Millisecond,
+					},
+				},
+			},
+			wantErr: require.Error,
+		},
 	}
 
 	for _, tt := range tests {
@@ -245,3 +366,16 @@ func TestValidateRedisClusterConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadConfig_ExternalURL(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := LoadConfig("testdata/external_url.yaml")
+	require.NoError(t, err)
+
+	// завершающий слэш убирается, относительные ссылки дополняются внешним адресом
+	require.Equal(t, "https://auth.zanuda.example/auth", cfg.Server.ExternalURL)
+	require.Equal(t, "https://auth.zanuda.example/auth/api/v0/oauth/google/callback", cfg.OAuth.Providers[0].RedirectURL)
+	require.Equal(t, "https://github-callback.zanuda.example/cb", cfg.OAuth.Providers[1].RedirectURL)
+	require.Equal(t, "https://auth.zanuda.example/auth/account/email?lang=ru", cfg.OAuth.EmailChange.ConfirmURL)
+}
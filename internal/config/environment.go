@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Окружения сервиса.
+const (
+	EnvironmentDev     = "dev"
+	EnvironmentStaging = "staging"
+	EnvironmentProd    = "prod"
+)
+
+// Env возвращает окружение сервиса. По умолчанию EnvironmentDev.
+func (cfg *Config) Env() string {
+	if cfg.Environment == "" {
+		return EnvironmentDev
+	}
+
+	return cfg.Environment
+}
+
+// InsecureSettings возвращает настройки, недопустимые в рабочем окружении: отключенная проверка
+// сертификата Vault или проверка по отпечатку без CA, Vault или внешний адрес без https, сервер без TLS
+// (если TLS не завершается перед ним - server.tls.terminated_upstream) и отладочный уровень логов.
+// Проверяется для любого окружения, чтобы staging мог предупредить о них заранее.
+func (cfg *Config) InsecureSettings() []string {
+	var settings []string
+
+	if cfg.Vault.InsecureSkipTLS {
+		settings = append(settings, "vault.insecure_skip_tls is enabled")
+	}
+
+	if cfg.Vault.TLSPinFile != "" {
+		settings = append(settings, "vault.tls_pin_file is enabled (trust on first use)")
+	}
+
+	if !strings.HasPrefix(cfg.Vault.Address, "https://") {
+		settings = append(settings, "vault.address is not https")
+	}
+
+	if cfg.Server.TLS.VaultPKI.IssuePath == "" && !cfg.Server.TLS.TerminatedUpstream {
+		settings = append(settings, "server.tls is not configured")
+	}
+
+	if cfg.Server.ExternalURL != "" && !strings.HasPrefix(cfg.Server.ExternalURL, "https://") {
+		settings = append(settings, "server.external_url is not https")
+	}
+
+	if cfg.LogLevel == "debug" {
+		settings = append(settings, "log_level is debug")
+	}
+
+	return settings
+}
+
+// checkEnvironment запрещает запуск в рабочем окружении с небезопасными настройками,
+// если они не разрешены явно флагом allow_insecure.
+func (cfg *Config) checkEnvironment() error {
+	if cfg.Env() != EnvironmentProd || cfg.AllowInsecure {
+		return nil
+	}
+
+	if settings := cfg.InsecureSettings(); len(settings) > 0 {
+		return fmt.Errorf("config: insecure settings in %s environment (set allow_insecure to override): %s",
+			EnvironmentProd, strings.Join(settings, ", "))
+	}
+
+	return nil
+}
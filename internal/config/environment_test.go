@@ -0,0 +1,110 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestConfig_CheckEnvironment(t *testing.T) {
+	t.Parallel()
+
+	secure := func() *Config {
+		return &Config{
+			LogLevel:    "info",
+			Environment: EnvironmentProd,
+			Server: Server{
+				ExternalURL: "https://auth.zanuda.example",
+				TLS:         ServerTLS{VaultPKI: ServerVaultPKI{IssuePath: "pki_int/issue/auth-service"}},
+			},
+			Vault: Vault{Address: "https://vault:8200"},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		modify       func(cfg *Config)
+		wantSettings []string
+		wantErr      bool
+	}{
+		{
+			name: "positive case: secure prod",
+		},
+		{
+			name:   "positive case: dev by default",
+			modify: func(cfg *Config) { cfg.Environment = ""; cfg.LogLevel = "debug" },
+			wantSettings: []string{
+				"log_level is debug",
+			},
+		},
+		{
+			name: "positive case: explicit override",
+			modify: func(cfg *Config) {
+				cfg.AllowInsecure = true
+				cfg.Vault.InsecureSkipTLS = true
+			},
+			wantSettings: []string{"vault.insecure_skip_tls is enabled"},
+		},
+		{
+			name: "positive case: tls terminated upstream",
+			modify: func(cfg *Config) {
+				cfg.Server.TLS = ServerTLS{TerminatedUpstream: true}
+			},
+		},
+		{
+			name: "error case: tls terminated upstream does not waive vault checks",
+			modify: func(cfg *Config) {
+				cfg.Server.TLS = ServerTLS{TerminatedUpstream: true}
+				cfg.Vault.InsecureSkipTLS = true
+			},
+			wantSettings: []string{"vault.insecure_skip_tls is enabled"},
+			wantErr:      true,
+		},
+		{
+			name:         "error case: tls pin in prod",
+			modify:       func(cfg *Config) { cfg.Vault.TLSPinFile = "/var/lib/auth-service/vault.pin" },
+			wantSettings: []string{"vault.tls_pin_file is enabled (trust on first use)"},
+			wantErr:      true,
+		},
+		{
+			name: "error case: insecure prod",
+			modify: func(cfg *Config) {
+				cfg.LogLevel = "debug"
+				cfg.Vault = Vault{Address: "http://vault:8200", InsecureSkipTLS: true}
+				cfg.Server.ExternalURL = "http://auth.zanuda.example"
+				cfg.Server.TLS = ServerTLS{}
+			},
+			wantSettings: []string{
+				"vault.insecure_skip_tls is enabled",
+				"vault.address is not https",
+				"server.tls is not configured",
+				"server.external_url is not https",
+				"log_level is debug",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := secure()
+			if tt.modify != nil {
+				tt.modify(cfg)
+			}
+
+			assert.Equal(t, tt.wantSettings, cfg.InsecureSettings())
+
+			err := cfg.checkEnvironment()
+			if tt.wantErr {
+				require.ErrorContains(t, err, "set allow_insecure to override")
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
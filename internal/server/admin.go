@@ -0,0 +1,85 @@
+package server
+
+import (
+	serverMiddleware "auth-service/internal/server/middleware"
+	"auth-service/internal/service/ldap"
+	"auth-service/internal/service/token"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// errAdminForbidden - токен администратора действителен, но его роли не разрешают запрос.
+var errAdminForbidden = errors.New("admin role does not allow this request")
+
+// adminTokenValidator - проверка токенов администраторов, выпущенных после входа через каталог.
+type adminTokenValidator interface {
+	Validate(ctx context.Context, raw string) (*token.Claims, error)
+}
+
+// adminEnabled возвращает true, если административное API включено: задан статический токен
+// или вход администраторов через каталог.
+func (s *Server) adminEnabled() bool {
+	return s.adminToken != "" || s.adminValidator != nil
+}
+
+// adminAuth возвращает middleware, которое пропускает только запросы с токеном административного API
+// в заголовке "Authorization: Bearer <token>". Кроме статического токена принимаются токены аудитории
+// admin: роль viewer разрешает только чтение, operator - любые запросы.
+func (s *Server) adminAuth() echo.MiddlewareFunc {
+	return middleware.KeyAuthWithConfig(middleware.KeyAuthConfig{
+		KeyLookup:  "header:" + echo.HeaderAuthorization,
+		AuthScheme: "Bearer",
+		Validator: func(key string, c echo.Context) (bool, error) {
+			if s.adminToken != "" && subtle.ConstantTimeCompare([]byte(key), []byte(s.adminToken)) == 1 {
+				return true, nil
+			}
+
+			if s.adminValidator == nil {
+				return false, nil
+			}
+
+			claims, err := s.adminValidator.Validate(c.Request().Context(), key)
+			if err != nil || !slices.Contains(claims.Audience, ldap.Audience) || claims.Actor != nil {
+				return false, nil
+			}
+
+			if !adminAllowed(claims.Scopes, c.Request().Method) {
+				return false, errAdminForbidden
+			}
+
+			req := c.Request()
+			c.SetRequest(req.WithContext(serverMiddleware.WithClaims(req.Context(), claims)))
+
+			return true, nil
+		},
+		ErrorHandler: func(err error, _ echo.Context) error {
+			var missing *middleware.ErrKeyAuthMissing
+
+			switch {
+			case errors.As(err, &missing):
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			case errors.Is(err, errAdminForbidden):
+				return echo.NewHTTPError(http.StatusForbidden, err.Error())
+			default:
+				return &echo.HTTPError{Code: http.StatusUnauthorized, Message: "Unauthorized", Internal: err}
+			}
+		},
+	})
+}
+
+// adminAllowed проверяет, что роли токена разрешают метод запроса.
+func adminAllowed(scopes []string, method string) bool {
+	if slices.Contains(scopes, ldap.Scope(ldap.RoleOperator)) {
+		return true
+	}
+
+	readOnly := method == http.MethodGet || method == http.MethodHead
+
+	return readOnly && slices.Contains(scopes, ldap.Scope(ldap.RoleViewer))
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"auth-service/internal/service/ratelimit"
+	"auth-service/internal/service/token"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminAuth(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+	}{
+		{name: "positive case", header: "Bearer admin-token", wantStatus: http.StatusOK},
+		{name: "error case: wrong token", header: "Bearer wrong", wantStatus: http.StatusUnauthorized},
+		{name: "error case: no header", header: "", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := &Server{adminToken: "admin-token"}
+
+			e := echo.New()
+			e.GET("/admin", func(c echo.Context) error { return c.NoContent(http.StatusOK) }, s.adminAuth())
+
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			if tt.header != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.header)
+			}
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+// fakeAdminValidator - проверка токенов администраторов по таблице.
+type fakeAdminValidator map[string]*token.Claims
+
+func (v fakeAdminValidator) Validate(_ context.Context, raw string) (*token.Claims, error) {
+	claims, ok := v[raw]
+	if !ok {
+		return nil, token.ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestAdminAuth_Validator(t *testing.T) {
+	t.Parallel()
+
+	validator := fakeAdminValidator{
+		"operator": {Audience: []string{"admin"}, Scopes: []string{"admin:operator"}},
+		"viewer":   {Audience: []string{"admin"}, Scopes: []string{"admin:viewer"}},
+		"no-role":  {Audience: []string{"admin"}},
+		"user":     {Audience: []string{"dashboard"}, Scopes: []string{"admin:operator"}},
+		"actor": {
+			Audience: []string{"admin"},
+			Scopes:   []string{"admin:operator"},
+			Actor:    &token.Actor{Subject: "support"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		token      string
+		wantStatus int
+	}{
+		{name: "positive case: operator writes", method: http.MethodPut, token: "operator", wantStatus: http.StatusOK},
+		{name: "positive case: viewer reads", method: http.MethodGet, token: "viewer", wantStatus: http.StatusOK},
+		{name: "error case: viewer writes", method: http.MethodPut, token: "viewer", wantStatus: http.StatusForbidden},
+		{name: "error case: no role", method: http.MethodGet, token: "no-role", wantStatus: http.StatusForbidden},
+		{name: "error case: other audience", method: http.MethodGet, token: "user", wantStatus: http.StatusUnauthorized},
+		{name: "error case: impersonation", method: http.MethodGet, token: "actor", wantStatus: http.StatusUnauthorized},
+		{name: "error case: invalid token", method: http.MethodGet, token: "wrong", wantStatus: http.StatusUnauthorized},
+		// статический токен не задан, пустая строка не должна совпасть
+		{name: "error case: empty static token", method: http.MethodGet, token: "", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := &Server{adminValidator: validator}
+
+			e := echo.New()
+			e.Any("/admin", func(c echo.Context) error {
+				// claims администратора доступны обработчику и журналу запросов
+				if _, ok := token.FromContext(c.Request().Context()); !ok {
+					return c.NoContent(http.StatusInternalServerError)
+				}
+
+				return c.NoContent(http.StatusOK)
+			}, s.adminAuth())
+
+			req := httptest.NewRequest(tt.method, "/admin", nil)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestAdminRateLimit(t *testing.T) {
+	t.Parallel()
+
+	s := &Server{
+		adminToken:     "admin-token",
+		adminRateLimit: ratelimit.Rule{Requests: 1, Window: time.Minute},
+		limiter:        ratelimit.New(),
+	}
+
+	e := echo.New()
+	e.GET("/admin", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, s.rateLimit("admin", s.adminRateLimit), s.adminAuth())
+
+	do := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	// попытка подбора токена тоже расходует лимит
+	assert.Equal(t, http.StatusUnauthorized, do("wrong").Code)
+	assert.Equal(t, http.StatusTooManyRequests, do("admin-token").Code)
+}
+
+func TestRateLimit_Disabled(t *testing.T) {
+	t.Parallel()
+
+	s := &Server{}
+
+	e := echo.New()
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, s.rateLimit("admin", ratelimit.Rule{}))
+
+	for range 3 {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+	}
+}
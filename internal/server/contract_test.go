@@ -0,0 +1,495 @@
+package server
+
+import (
+	"auth-service/docs"
+	handlerV0 "auth-service/internal/api/v0"
+	"auth-service/internal/service/capture"
+	"auth-service/internal/service/credpolicy"
+	"auth-service/internal/service/group"
+	"auth-service/internal/service/keystats"
+	"auth-service/internal/service/logsampling"
+	"auth-service/internal/service/stats"
+	"auth-service/internal/service/token"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// Контрактные тесты: запросы к каждой операции swagger документации проходят через настоящие
+// маршруты и обработчик версии 0, а ответы сверяются с документацией - код ответа должен быть
+// описан, а тело соответствовать схеме. Так документация и реализация не расходятся, когда
+// добавляются эндпоинты.
+
+const (
+	contractBasePath   = "/api/v0"
+	contractAdminToken = "admin-token"
+	contractSCIMToken  = "scim-token"
+)
+
+// swaggerSpec - часть swagger 2.0 документации, нужная для сверки ответов.
+type swaggerSpec struct {
+	Paths       map[string]map[string]swaggerOperation `json:"paths"`
+	Definitions map[string]*swaggerSchema              `json:"definitions"`
+}
+
+type swaggerOperation struct {
+	Responses map[string]swaggerResponse `json:"responses"`
+}
+
+type swaggerResponse struct {
+	Schema *swaggerSchema `json:"schema"`
+}
+
+// swaggerSchema - подмножество JSON Schema, которое генерирует swag.
+type swaggerSchema struct {
+	Ref                  string                    `json:"$ref"`
+	Type                 string                    `json:"type"`
+	Properties           map[string]*swaggerSchema `json:"properties"`
+	AdditionalProperties *swaggerSchema            `json:"additionalProperties"`
+	Items                *swaggerSchema            `json:"items"`
+	Enum                 []interface{}             `json:"enum"`
+	AllOf                []*swaggerSchema          `json:"allOf"`
+}
+
+func loadSwaggerSpec(t *testing.T) *swaggerSpec {
+	t.Helper()
+
+	var spec swaggerSpec
+
+	require.NoError(t, json.Unmarshal([]byte(docs.SwaggerInfo.ReadDoc()), &spec))
+	require.NotEmpty(t, spec.Paths)
+
+	return &spec
+}
+
+// check сверяет значение value по пути path со схемой и возвращает расхождения.
+// null допускается для любого типа: swag не описывает nullable, а Go кодирует пустые срезы,
+// словари и указатели как null.
+func (s *swaggerSpec) check(schema *swaggerSchema, path string, value interface{}) []string {
+	if schema == nil {
+		return nil
+	}
+
+	if schema.Ref != "" {
+		def, ok := s.Definitions[strings.TrimPrefix(schema.Ref, "#/definitions/")]
+		if !ok {
+			return []string{fmt.Sprintf("%s: unknown definition %s", path, schema.Ref)}
+		}
+
+		return s.check(def, path, value)
+	}
+
+	var res []string
+
+	for _, sub := range schema.AllOf {
+		res = append(res, s.check(sub, path, value)...)
+	}
+
+	if value == nil {
+		return res
+	}
+
+	if len(schema.Enum) != 0 && !slices.Contains(schema.Enum, value) {
+		res = append(res, fmt.Sprintf("%s: %v is not one of %v", path, value, schema.Enum))
+	}
+
+	wrongType := func() []string {
+		return append(res, fmt.Sprintf("%s: %s expected, got %T", path, schema.Type, value))
+	}
+
+	switch schema.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return wrongType()
+		}
+
+		res = append(res, s.checkObject(schema, path, obj)...)
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return wrongType()
+		}
+
+		for i, item := range items {
+			res = append(res, s.check(schema.Items, path+"["+strconv.Itoa(i)+"]", item)...)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return wrongType()
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != math.Trunc(n) {
+			return wrongType()
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return wrongType()
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return wrongType()
+		}
+	}
+
+	return res
+}
+
+// checkObject сверяет свойства объекта. Свойство, которого нет в документации, - расхождение:
+// клиенты, сгенерированные по документации, о нем не узнают.
+func (s *swaggerSpec) checkObject(schema *swaggerSchema, path string, obj map[string]interface{}) []string {
+	var res []string
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop, ok := schema.Properties[name]
+
+		switch {
+		case ok:
+			res = append(res, s.check(prop, path+"."+name, obj[name])...)
+		case schema.AdditionalProperties != nil:
+			res = append(res, s.check(schema.AdditionalProperties, path+"."+name, obj[name])...)
+		case schema.Properties != nil:
+			res = append(res, fmt.Sprintf("%s.%s: undocumented property", path, name))
+		}
+	}
+
+	return res
+}
+
+// contractKeys - ключи подписи для контрактных тестов.
+type contractKeys struct{}
+
+func (contractKeys) SigningKey(context.Context) (string, []byte, error) {
+	return "key-1", []byte("secret"), nil
+}
+
+func (contractKeys) Key(context.Context, string) ([]byte, error) {
+	return []byte("secret"), nil
+}
+
+// newContractServer создает сервер с настоящим обработчиком версии 0 и сервисами на miniredis.
+func newContractServer(t *testing.T) *Server {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	registry := prometheus.NewRegistry()
+
+	issuer, err := token.NewIssuer(token.WithSigningKeys(contractKeys{}))
+	require.NoError(t, err)
+
+	validator, err := token.NewValidator(token.WithKeys(contractKeys{}))
+	require.NoError(t, err)
+
+	captured, err := capture.New()
+	require.NoError(t, err)
+
+	keyStats, err := keystats.New(keystats.WithRegisterer(registry))
+	require.NoError(t, err)
+
+	groups, err := group.New(group.WithClient(client))
+	require.NoError(t, err)
+
+	sampler, err := logsampling.New(logsampling.WithClient(client))
+	require.NoError(t, err)
+
+	analytics, err := stats.New(stats.WithClient(client))
+	require.NoError(t, err)
+
+	policy, err := credpolicy.New(credpolicy.WithPasswordRules(credpolicy.PasswordRules{MinLength: 10}))
+	require.NoError(t, err)
+
+	h, err := handlerV0.New(
+		handlerV0.WithVersion("1.0.0"),
+		handlerV0.WithBuildDate("2026-01-01"),
+		handlerV0.WithGitCommit("1234567890"),
+		handlerV0.WithIssuer(issuer),
+		handlerV0.WithValidator(validator),
+		handlerV0.WithCapture(captured),
+		handlerV0.WithKeyStats(keyStats),
+		handlerV0.WithGroups(groups),
+		handlerV0.WithLogSampling(sampler),
+		handlerV0.WithStats(analytics),
+		handlerV0.WithCredentialsPolicy(policy),
+	)
+	require.NoError(t, err)
+
+	sum := sha256.Sum256([]byte(contractSCIMToken))
+
+	server, err := New(
+		WithPort(8080),
+		WithShutdownTimeout(100*time.Millisecond),
+		WithHandlerV0(h),
+		WithAdminToken(contractAdminToken),
+		WithSCIMToken(hex.EncodeToString(sum[:])),
+	)
+	require.NoError(t, err)
+
+	server.e = echo.New()
+	server.registerAPIRoutes(server.e)
+
+	return server
+}
+
+// contractRequest - запрос к операции документации.
+type contractRequest struct {
+	method string
+	// path - путь в документации, например /admin/groups/{id}
+	path string
+	// params - значения параметров пути. Отсутствующие заменяются на "test".
+	params map[string]string
+	query  string
+	body   string
+	// token - токен в заголовке Authorization. По умолчанию токен, который требует операция.
+	token string
+	// want - ожидаемый код ответа. Нулевое значение - любой описанный код.
+	want int
+}
+
+var pathParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// unregistered - описанные операции, которые сервер контрактных тестов не регистрирует.
+var unregistered = map[string]bool{
+	// регистрируется только со входом через каталог
+	"POST " + contractBasePath + "/admin/login": true,
+}
+
+func (r contractRequest) target() string {
+	target := contractBasePath + pathParam.ReplaceAllStringFunc(r.path, func(m string) string {
+		if v, ok := r.params[m[1:len(m)-1]]; ok {
+			return v
+		}
+
+		return "test"
+	})
+
+	if r.query != "" {
+		target += "?" + r.query
+	}
+
+	return target
+}
+
+// defaultToken возвращает токен, который требует операция: административный или SCIM.
+func defaultToken(path string) string {
+	switch {
+	case strings.HasPrefix(path, "/admin/") && path != "/admin/login":
+		return contractAdminToken
+	case strings.HasPrefix(path, "/scim/"):
+		return contractSCIMToken
+	default:
+		return ""
+	}
+}
+
+// replay выполняет запрос и сверяет ответ с документацией операции.
+func replay(t *testing.T, spec *swaggerSpec, server *Server, r contractRequest) {
+	t.Helper()
+
+	op, ok := spec.Paths[r.path][strings.ToLower(r.method)]
+	require.True(t, ok, "operation %s %s is not documented", r.method, r.path)
+
+	req := httptest.NewRequest(r.method, r.target(), strings.NewReader(r.body))
+	if r.body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+
+	tok := r.token
+	if tok == "" {
+		tok = defaultToken(r.path)
+	}
+
+	if tok != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+tok)
+	}
+
+	rec := httptest.NewRecorder()
+	server.e.ServeHTTP(rec, req)
+
+	if r.want != 0 && rec.Code != r.want {
+		t.Errorf("%s %s: status %d, want %d, body: %s", r.method, r.path, rec.Code, r.want, rec.Body.String())
+	}
+
+	resp, ok := op.Responses[strconv.Itoa(rec.Code)]
+	if !ok {
+		t.Errorf("%s %s: status %d is not documented, body: %s", r.method, r.path, rec.Code, rec.Body.String())
+
+		return
+	}
+
+	if resp.Schema == nil || rec.Body.Len() == 0 {
+		return
+	}
+
+	if !strings.HasPrefix(rec.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		return
+	}
+
+	var body interface{}
+
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Errorf("%s %s: status %d: invalid json: %v", r.method, r.path, rec.Code, err)
+
+		return
+	}
+
+	for _, diff := range spec.check(resp.Schema, "body", body) {
+		t.Errorf("%s %s: status %d: %s", r.method, r.path, rec.Code, diff)
+	}
+}
+
+func TestContract_Routes(t *testing.T) {
+	t.Parallel()
+
+	spec := loadSwaggerSpec(t)
+	server := newContractServer(t)
+
+	documented := map[string]bool{}
+
+	for path, ops := range spec.Paths {
+		for method := range ops {
+			documented[strings.ToUpper(method)+" "+contractBasePath+pathParam.ReplaceAllString(path, ":$1")] = true
+		}
+	}
+
+	registered := map[string]bool{}
+
+	for _, r := range server.e.Routes() {
+		if r.Method != echo.RouteNotFound {
+			registered[r.Method+" "+r.Path] = true
+		}
+	}
+
+	for op := range unregistered {
+		delete(documented, op)
+	}
+
+	require.Equal(t, documented, registered, "swagger documentation and routes differ")
+}
+
+func TestContract_Responses(t *testing.T) {
+	t.Parallel()
+
+	spec := loadSwaggerSpec(t)
+	server := newContractServer(t)
+
+	// каждая операция с пустым запросом: ответы с ошибками тоже должны быть описаны
+	for path, ops := range spec.Paths {
+		for method := range ops {
+			r := contractRequest{method: strings.ToUpper(method), path: path}
+			if unregistered[r.method+" "+contractBasePath+pathParam.ReplaceAllString(path, ":$1")] {
+				continue
+			}
+
+			if r.method != http.MethodGet && r.method != http.MethodDelete {
+				r.body = "{}"
+			}
+
+			replay(t, spec, server, r)
+		}
+	}
+
+	// успешные ответы настроенных сервисов, по порядку: запросы зависят от предыдущих
+	requests := []contractRequest{
+		{method: http.MethodGet, path: "/health", want: http.StatusOK},
+		{method: http.MethodGet, path: "/ready", want: http.StatusOK},
+		{method: http.MethodPost, path: "/credentials/check", body: `{"username":"alice","password":"short"}`, want: http.StatusUnprocessableEntity},
+		{method: http.MethodPost, path: "/credentials/check", body: `{"username":"alice","password":"long enough password"}`, want: http.StatusOK},
+		{method: http.MethodPost, path: "/token/introspect", body: `{"token":"invalid"}`, want: http.StatusOK},
+		{method: http.MethodPut, path: "/admin/capture", body: `{"enabled":true,"routes":{"/api/v0/health":1}}`, want: http.StatusOK},
+		{method: http.MethodGet, path: "/health", want: http.StatusOK},
+		{method: http.MethodGet, path: "/admin/capture", want: http.StatusOK},
+		{method: http.MethodDelete, path: "/admin/capture", want: http.StatusNoContent},
+		{method: http.MethodGet, path: "/admin/keys/usage", want: http.StatusOK},
+		{method: http.MethodGet, path: "/admin/keys/usage", query: "idle_for=invalid", want: http.StatusBadRequest},
+		{method: http.MethodPost, path: "/admin/groups", body: `{"name":"team","owner":"alice"}`, want: http.StatusCreated},
+		{method: http.MethodGet, path: "/admin/users/{user}/groups", params: map[string]string{"user": "alice"}, want: http.StatusOK},
+		{method: http.MethodPut, path: "/admin/log-sampling", body: `{"routes":{"/api/v0/health":0.5}}`, want: http.StatusOK},
+		{method: http.MethodGet, path: "/admin/log-sampling", want: http.StatusOK},
+		{method: http.MethodDelete, path: "/admin/log-sampling", want: http.StatusNoContent},
+		{method: http.MethodGet, path: "/admin/stats", want: http.StatusOK},
+		{method: http.MethodGet, path: "/admin/stats", token: "wrong", want: http.StatusUnauthorized},
+	}
+
+	for _, r := range requests {
+		replay(t, spec, server, r)
+	}
+}
+
+func TestSwaggerSpec_Check(t *testing.T) {
+	t.Parallel()
+
+	spec := &swaggerSpec{Definitions: map[string]*swaggerSchema{
+		"item": {Type: "object", Properties: map[string]*swaggerSchema{
+			"id":    {Type: "integer"},
+			"state": {Type: "string", Enum: []interface{}{"on", "off"}},
+		}},
+	}}
+
+	schema := &swaggerSchema{Type: "object", Properties: map[string]*swaggerSchema{
+		"items":  {Type: "array", Items: &swaggerSchema{Ref: "#/definitions/item"}},
+		"labels": {Type: "object", AdditionalProperties: &swaggerSchema{Type: "string"}},
+	}}
+
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "positive case", body: `{"items":[{"id":1,"state":"on"}],"labels":{"a":"b"}}`},
+		{name: "positive case: null", body: `{"items":null,"labels":null}`},
+		{
+			name: "error case: undocumented property",
+			body: `{"items":[{"id":1,"extra":true}]}`,
+			want: []string{"body.items[0].extra: undocumented property"},
+		},
+		{
+			name: "error case: wrong types",
+			body: `{"items":[{"id":1.5,"state":"unknown"}],"labels":{"a":1}}`,
+			want: []string{
+				"body.items[0].id: integer expected, got float64",
+				"body.items[0].state: unknown is not one of [on off]",
+				"body.labels.a: string expected, got float64",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var body interface{}
+
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &body))
+			require.Equal(t, tt.want, spec.check(schema, "body", body))
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"auth-service/internal/service/securitytxt"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	echoSwagger "github.com/swaggo/echo-swagger"
+)
+
+// registerInfoRoutes регистрирует на публичном порту служебные маршруты: security.txt, проверки
+// живости и готовности для оркестратора, метрики и swagger.
+// Если настроен отладочный порт, метрики и swagger отдаются только на нем. Swagger раскрывает версию,
+// поэтому при отключенном раскрытии версии не отдается на публичном порту.
+func (s *Server) registerInfoRoutes(e *echo.Echo) {
+	if s.securityTxt != nil {
+		e.GET(securitytxt.Path, s.serveSecurityTxt)
+	}
+
+	e.GET("/healthz", s.api.h0.Liveness)
+	e.GET("/readyz", s.api.h0.Readiness)
+
+	if s.debugPort != 0 {
+		return
+	}
+
+	e.GET("/metrics", metricsHandler())
+
+	if !s.hideVersion {
+		e.GET("/swagger/*", echoSwagger.WrapHandler)
+	}
+}
+
+// serveSecurityTxt отдает /.well-known/security.txt.
+func (s *Server) serveSecurityTxt(c echo.Context) error {
+	return c.String(http.StatusOK, s.securityTxt.String())
+}
+
+// createDebugRoutes создает сервер внутреннего отладочного порта: полный /health с версией
+// и состоянием компонентов, метрики и swagger. Порт не должен быть доступен извне.
+func (s *Server) createDebugRoutes() {
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+
+	e.Use(middleware.Recover())
+
+	e.GET("/health", s.api.h0.HealthDetails)
+	e.GET("/metrics", metricsHandler())
+	e.GET("/swagger/*", echoSwagger.WrapHandler)
+
+	s.debug = e
+}
+
+// startDebug запускает отладочный сервер, если он настроен. Ошибка запуска отправляется в errChan.
+func (s *Server) startDebug(errChan chan<- error) {
+	if s.debug == nil {
+		return
+	}
+
+	go func() {
+		err := s.debug.Start(fmt.Sprintf(":%d", s.debugPort))
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errChan <- fmt.Errorf("error start debug server: %w", err)
+		}
+	}()
+}
+
+// shutdownDebug останавливает отладочный сервер, если он настроен.
+func (s *Server) shutdownDebug(ctx context.Context) error {
+	if s.debug == nil {
+		return nil
+	}
+
+	return s.debug.Shutdown(ctx)
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"auth-service/internal/server/mocks"
+	"auth-service/internal/service/securitytxt"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterInfoRoutes(t *testing.T) {
+	t.Parallel()
+
+	file, err := securitytxt.New(
+		securitytxt.WithContacts([]string{"mailto:security@example.com"}),
+		securitytxt.WithExpires(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)),
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		server *Server
+		want   []string
+	}{
+		{
+			name:   "positive case: metrics and swagger on public port",
+			server: &Server{},
+			want:   []string{"/healthz", "/readyz", "/metrics", "/swagger/*"},
+		},
+		{
+			name:   "positive case: hide version",
+			server: &Server{hideVersion: true},
+			want:   []string{"/healthz", "/readyz", "/metrics"},
+		},
+		{
+			name:   "positive case: debug port",
+			server: &Server{debugPort: 9090},
+			want:   []string{"/healthz", "/readyz"},
+		},
+		{
+			name:   "positive case: security.txt",
+			server: &Server{debugPort: 9090, securityTxt: file},
+			want:   []string{"/healthz", "/readyz", securitytxt.Path},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.server.api.h0 = mocks.NewMockhandler(gomock.NewController(t))
+
+			e := echo.New()
+			tt.server.registerInfoRoutes(e)
+
+			paths := []string{}
+			for _, r := range e.Routes() {
+				paths = append(paths, r.Path)
+			}
+
+			assert.ElementsMatch(t, tt.want, paths)
+		})
+	}
+}
+
+func TestServeSecurityTxt(t *testing.T) {
+	t.Parallel()
+
+	file, err := securitytxt.New(
+		securitytxt.WithContacts([]string{"mailto:security@example.com"}),
+		securitytxt.WithExpires(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)),
+	)
+	require.NoError(t, err)
+
+	s := &Server{securityTxt: file}
+	s.api.h0 = mocks.NewMockhandler(gomock.NewController(t))
+
+	e := echo.New()
+	s.registerInfoRoutes(e)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, securitytxt.Path, nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, echo.MIMETextPlainCharsetUTF8, rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, "Contact: mailto:security@example.com\nExpires: 2027-01-01T00:00:00Z\n", rec.Body.String())
+}
+
+func TestRun_Debug(t *testing.T) {
+	t.Parallel()
+
+	port, debugPort := freePort(t), freePort(t)
+
+	h := mocks.NewMockhandler(gomock.NewController(t))
+	h.EXPECT().Version().Return("v0")
+	h.EXPECT().HealthDetails(gomock.Any()).DoAndReturn(func(c echo.Context) error {
+		return c.String(http.StatusOK, "details")
+	}).AnyTimes()
+
+	server, err := New(
+		WithPort(port),
+		WithDebugPort(debugPort),
+		WithShutdownTimeout(time.Second),
+		WithHandlerV0(h),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+
+	// маршруты prometheus можно зарегистрировать только один раз на процесс, поэтому без createRoutes
+	server.e = echo.New()
+	server.createDebugRoutes()
+
+	go func() { done <- server.run(ctx) }()
+
+	var body string
+
+	require.Eventually(t, func() bool {
+		resp, err := getDebugHealth(t, debugPort)
+		if err != nil {
+			return false
+		}
+
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return false
+		}
+
+		body = string(data)
+
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 20*time.Millisecond)
+
+	assert.Equal(t, "details", body)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	_, err = getDebugHealth(t, debugPort) //nolint:bodyclose // сервер остановлен, ответа нет
+	require.Error(t, err)
+}
+
+func getDebugHealth(t *testing.T, port int) (*http.Response, error) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, fmt.Sprintf("http://localhost:%d/health", port), nil)
+	require.NoError(t, err)
+
+	return http.DefaultClient.Do(req)
+}
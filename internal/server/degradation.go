@@ -0,0 +1,138 @@
+package server
+
+import (
+	"auth-service/internal/service/dependency"
+	"auth-service/internal/service/loadshed"
+	"auth-service/internal/service/readonly"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// unavailableResponse - тело ответа 503, когда эндпоинт не может обслужить запрос из-за недоступных зависимостей.
+type unavailableResponse struct {
+	Error             string            `json:"error"`
+	Level             dependency.Level  `json:"level"`
+	Unavailable       []dependency.Name `json:"unavailable"`
+	RetryAfterSeconds int               `json:"retry_after_seconds"`
+}
+
+// readOnlyResponse - тело ответа 503, когда эндпоинт, который пишет данные, вызван в режиме только для чтения.
+type readOnlyResponse struct {
+	Error    string          `json:"error"`
+	ReadOnly readonly.Status `json:"read_only"`
+	// RetryAfterSeconds - через сколько сервис снова попробует писать. 0, если режим включен оператором
+	RetryAfterSeconds int `json:"retry_after_seconds,omitempty"`
+}
+
+// routeClassKey - ключ класса эндпоинта в контексте запроса.
+const routeClassKey = "route_class"
+
+// routeClass возвращает класс эндпоинта запроса или пустую строку, если маршрут без класса.
+func routeClass(c echo.Context) string {
+	class, _ := c.Get(routeClassKey).(dependency.Class)
+
+	return string(class)
+}
+
+// requires возвращает middleware, которое отвечает 503 с заголовком Retry-After,
+// если недоступна хотя бы одна из зависимостей, нужных эндпоинтам класса.
+// Если реестр зависимостей не задан, запросы пропускаются без проверки.
+// В режиме только для чтения запросы, кроме GET и HEAD, к эндпоинтам классов, которые пишут данные, отклоняются.
+// Если включен сброс нагрузки, количество одновременно обрабатываемых запросов класса ограничивается.
+// Класс сохраняется в контексте запроса для учета SLI (routeClass).
+func (s *Server) requires(class dependency.Class) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(routeClassKey, class)
+
+			if s.readOnly != nil && class.Writes() && !safeMethod(c.Request().Method) {
+				if status := s.readOnly.Status(); status.Enabled {
+					return s.rejectReadOnly(c, class, status)
+				}
+			}
+
+			if s.deps == nil {
+				return s.shed(c, next, class)
+			}
+
+			unavailable := s.deps.Unavailable(class.Requires()...)
+			if len(unavailable) == 0 {
+				return s.shed(c, next, class)
+			}
+
+			retryAfter := int(math.Ceil(s.deps.RetryAfter().Seconds()))
+
+			c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+
+			return c.JSON(http.StatusServiceUnavailable, unavailableResponse{
+				Error:             "service temporarily unavailable",
+				Level:             s.deps.Level(),
+				Unavailable:       unavailable,
+				RetryAfterSeconds: retryAfter,
+			})
+		}
+	}
+}
+
+// rejectReadOnly отвечает 503 на запрос к эндпоинту, который пишет данные, в режиме только для чтения.
+// Retry-After выставляется, только если режим включен сервисом и известно время следующей попытки записи.
+func (s *Server) rejectReadOnly(c echo.Context, class dependency.Class, status readonly.Status) error {
+	s.readOnly.Reject(string(class))
+
+	resp := readOnlyResponse{Error: "service is in read-only mode", ReadOnly: status}
+
+	if status.Until != nil {
+		resp.RetryAfterSeconds = max(1, int(math.Ceil(time.Until(*status.Until).Seconds())))
+
+		c.Response().Header().Set("Retry-After", strconv.Itoa(resp.RetryAfterSeconds))
+	}
+
+	return c.JSON(http.StatusServiceUnavailable, resp)
+}
+
+// safeMethod сообщает, что метод не меняет данные.
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// overloadedResponse - тело ответа 503, когда запрос отклонен из-за перегрузки.
+type overloadedResponse struct {
+	Error             string `json:"error"`
+	RetryAfterSeconds int    `json:"retry_after_seconds"`
+}
+
+// shed обрабатывает запрос класса, если для него есть место, и сразу отвечает 503 с Retry-After,
+// если запрос не дождется обработки за бюджет ожидания класса.
+func (s *Server) shed(c echo.Context, next echo.HandlerFunc, class dependency.Class) error {
+	if s.shedder == nil {
+		return next(c)
+	}
+
+	release, err := s.shedder.Acquire(c.Request().Context(), string(class))
+
+	var rejected *loadshed.RejectedError
+
+	switch {
+	case errors.As(err, &rejected):
+		retryAfter := max(1, int(math.Ceil(rejected.RetryAfter.Seconds())))
+
+		c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+
+		return c.JSON(http.StatusServiceUnavailable, overloadedResponse{
+			Error:             "service is overloaded",
+			RetryAfterSeconds: retryAfter,
+		})
+	case err != nil:
+		// клиент ушел, пока запрос ждал в очереди
+		return err
+	}
+
+	defer release()
+
+	return next(c)
+}
@@ -0,0 +1,290 @@
+package server
+
+import (
+	serverMiddleware "auth-service/internal/server/middleware"
+	"auth-service/internal/service/dependency"
+	"auth-service/internal/service/loadshed"
+	"auth-service/internal/service/readonly"
+	"auth-service/internal/service/slo"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestRequires(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		class        dependency.Class
+		createDeps   func(t *testing.T) *dependency.Registry
+		wantStatus   int
+		wantResponse *unavailableResponse
+		wantRetry    string
+	}{
+		{
+			name:       "positive case: registry is not set",
+			class:      dependency.ClassIssuance,
+			createDeps: func(t *testing.T) *dependency.Registry { t.Helper(); return nil },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:  "positive case: dependencies are up",
+			class: dependency.ClassIssuance,
+			createDeps: func(t *testing.T) *dependency.Registry {
+				t.Helper()
+
+				deps, err := dependency.New()
+				require.NoError(t, err)
+
+				deps.Set(dependency.Vault, nil)
+				deps.Set(dependency.Redis, nil)
+
+				return deps
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:  "positive case: validation works without vault",
+			class: dependency.ClassValidation,
+			createDeps: func(t *testing.T) *dependency.Registry {
+				t.Helper()
+
+				deps, err := dependency.New()
+				require.NoError(t, err)
+
+				deps.Set(dependency.Vault, errors.New("sealed"))
+				deps.Set(dependency.Redis, nil)
+
+				return deps
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:  "negative case: issuance without vault",
+			class: dependency.ClassIssuance,
+			createDeps: func(t *testing.T) *dependency.Registry {
+				t.Helper()
+
+				deps, err := dependency.New(dependency.WithRetryAfter(1500 * time.Millisecond))
+				require.NoError(t, err)
+
+				deps.Set(dependency.Vault, errors.New("sealed"))
+				deps.Set(dependency.Redis, nil)
+
+				return deps
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantRetry:  "2",
+			wantResponse: &unavailableResponse{
+				Error:             "service temporarily unavailable",
+				Level:             dependency.LevelDegraded,
+				Unavailable:       []dependency.Name{dependency.Vault},
+				RetryAfterSeconds: 2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := &Server{deps: tt.createDeps(t)}
+
+			e := echo.New()
+			rec := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+			h := s.requires(tt.class)(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			require.NoError(t, h(c))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantRetry, rec.Header().Get("Retry-After"))
+
+			if tt.wantResponse != nil {
+				var got unavailableResponse
+
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+				assert.Equal(t, *tt.wantResponse, got)
+			}
+		})
+	}
+}
+
+func TestRequires_LoadShedding(t *testing.T) {
+	t.Parallel()
+
+	shedder, err := loadshed.New(
+		loadshed.WithRegisterer(prometheus.NewRegistry()),
+		loadshed.WithLimits(map[string]loadshed.Limit{
+			string(dependency.ClassIssuance): {MaxInFlight: 1, Budget: time.Second},
+		}),
+	)
+	require.NoError(t, err)
+
+	s := &Server{shedder: shedder}
+
+	started, done := make(chan struct{}), make(chan struct{})
+
+	e := echo.New()
+	e.GET("/slow", func(c echo.Context) error {
+		close(started)
+		<-done
+
+		return c.NoContent(http.StatusOK)
+	}, s.requires(dependency.ClassIssuance))
+	e.GET("/session", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, s.requires(dependency.ClassSession))
+
+	slow := httptest.NewRecorder()
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+
+		e.ServeHTTP(slow, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	}()
+
+	<-started
+
+	// места нет, а очередь не настроена - запрос сразу отклоняется
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"error":"service is overloaded","retry_after_seconds":1}`, rec.Body.String())
+
+	// другой класс не ограничен
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/session", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	close(done)
+	<-finished
+
+	assert.Equal(t, http.StatusOK, slow.Code)
+}
+
+func TestRequires_SLO(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewRegistry()
+
+	tracker, err := slo.New(
+		slo.WithRegisterer(registry),
+		slo.WithObjective(string(dependency.ClassSession), slo.Objective{}),
+	)
+	require.NoError(t, err)
+
+	deps, err := dependency.New()
+	require.NoError(t, err)
+
+	deps.Set(dependency.Redis, nil)
+
+	s := &Server{deps: deps}
+
+	e := echo.New()
+	e.Use(serverMiddleware.SLO(tracker, routeClass))
+	e.GET("/session", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, s.requires(dependency.ClassSession))
+	e.GET("/info", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, s.requires(dependency.ClassInfo))
+
+	do := func(path string) {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	do("/session")
+	do("/info")
+
+	// отказ из-за недоступной зависимости - плохой запрос класса
+	deps.Set(dependency.Redis, errors.New("connection refused"))
+	do("/session")
+
+	expected := `
+# HELP auth_slo_requests_total Количество запросов по классам эндпоинтов, индикаторам (availability, latency) и результату (good, bad).
+# TYPE auth_slo_requests_total counter
+auth_slo_requests_total{class="session",result="bad",sli="availability"} 1
+auth_slo_requests_total{class="session",result="bad",sli="latency"} 0
+auth_slo_requests_total{class="session",result="good",sli="availability"} 1
+auth_slo_requests_total{class="session",result="good",sli="latency"} 1
+`
+
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "auth_slo_requests_total"))
+}
+
+func TestRequires_ReadOnly(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewRegistry()
+
+	mode, err := readonly.New(readonly.WithFailureThreshold(1), readonly.WithCooldown(time.Minute), readonly.WithRegisterer(registry))
+	require.NoError(t, err)
+
+	s := &Server{readOnly: mode}
+
+	call := func(class dependency.Class, method string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(method, "/", nil), rec)
+
+		h := s.requires(class)(func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+
+		require.NoError(t, h(c))
+
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, call(dependency.ClassIssuance, http.MethodPost).Code)
+
+	mode.Enable("redis failover")
+
+	rec := call(dependency.ClassIssuance, http.MethodPost)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Empty(t, rec.Header().Get("Retry-After"))
+
+	var got readOnlyResponse
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	assert.Equal(t, "service is in read-only mode", got.Error)
+	assert.Equal(t, readonly.SourceManual, got.ReadOnly.Source)
+	assert.Equal(t, "redis failover", got.ReadOnly.Reason)
+
+	// проверка токенов и чтение продолжают работать
+	assert.Equal(t, http.StatusOK, call(dependency.ClassValidation, http.MethodPost).Code)
+	assert.Equal(t, http.StatusOK, call(dependency.ClassSession, http.MethodGet).Code)
+	assert.Equal(t, http.StatusServiceUnavailable, call(dependency.ClassSession, http.MethodDelete).Code)
+
+	mode.Disable()
+	mode.ObserveWrite(errors.New("READONLY You can't write against a read only replica."))
+
+	rec = call(dependency.ClassSession, http.MethodPost)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+
+	expected := `
+# HELP auth_read_only_rejected_total Количество запросов, отклоненных в режиме только для чтения, по классам эндпоинтов.
+# TYPE auth_read_only_rejected_total counter
+auth_read_only_rejected_total{class="issuance"} 1
+auth_read_only_rejected_total{class="session"} 2
+`
+
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "auth_read_only_rejected_total"))
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Переменные окружения и первый дескриптор сокетов, переданных systemd (sd_listen_fds).
+const (
+	listenPIDEnv   = "LISTEN_PID"
+	listenFDsEnv   = "LISTEN_FDS"
+	listenFDsStart = 3
+)
+
+// listen создает слушающий сокет сервера.
+// Если включена активация сокетом и systemd передал сокет, используется он.
+// Иначе сокет открывается на порту сервера, при необходимости с SO_REUSEPORT,
+// чтобы новый процесс мог начать принимать соединения до остановки старого.
+func (s *Server) listen(ctx context.Context) (net.Listener, error) {
+	if s.socketActivation {
+		l, err := activatedListener()
+		if err != nil {
+			return nil, err
+		}
+
+		if l != nil {
+			logrus.WithField("addr", l.Addr().String()).Info("using socket passed by systemd")
+			return l, nil
+		}
+
+		logrus.Info("socket activation is enabled, but no socket was passed, binding port")
+	}
+
+	lc := net.ListenConfig{}
+
+	if s.reusePort {
+		lc.Control = reusePortControl
+	}
+
+	l, err := lc.Listen(ctx, "tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		return nil, fmt.Errorf("error listen port %d: %w", s.port, err)
+	}
+
+	return l, nil
+}
+
+// activatedListener возвращает первый сокет, переданный systemd, или nil, если сокеты не передавались
+// или предназначены другому процессу. Переменные окружения удаляются, чтобы их не унаследовали дочерние процессы.
+func activatedListener() (net.Listener, error) {
+	pid, fds := os.Getenv(listenPIDEnv), os.Getenv(listenFDsEnv)
+
+	if pid == "" || fds == "" {
+		return nil, nil //nolint:nilnil // отсутствие сокета - не ошибка
+	}
+
+	defer func() {
+		_ = os.Unsetenv(listenPIDEnv)
+		_ = os.Unsetenv(listenFDsEnv)
+	}()
+
+	if pid != strconv.Itoa(os.Getpid()) {
+		return nil, nil //nolint:nilnil // сокеты переданы другому процессу
+	}
+
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("invalid %s: %q", listenFDsEnv, fds)
+	}
+
+	if n > 1 {
+		logrus.WithField("fds", n).Warn("systemd passed several sockets, using the first one")
+	}
+
+	return listenerFromFD(listenFDsStart)
+}
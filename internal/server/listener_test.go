@@ -0,0 +1,85 @@
+package server
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+
+	return port
+}
+
+func TestListen_SocketActivationFallback(t *testing.T) {
+	t.Setenv(listenPIDEnv, "")
+	t.Setenv(listenFDsEnv, "")
+
+	s := &Server{port: freePort(t), socketActivation: true}
+
+	l, err := s.listen(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, s.port, l.Addr().(*net.TCPAddr).Port)
+	require.NoError(t, l.Close())
+}
+
+func TestActivatedListener(t *testing.T) {
+	tests := []struct {
+		name     string
+		pid      string
+		fds      string
+		wantNil  bool
+		wantErr  require.ErrorAssertionFunc
+		wantKeep bool
+	}{
+		{
+			name:     "positive case: not activated",
+			wantNil:  true,
+			wantErr:  require.NoError,
+			wantKeep: true,
+		},
+		{
+			name:    "positive case: sockets for another process",
+			pid:     "1",
+			fds:     "1",
+			wantNil: true,
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: invalid fds",
+			pid:     strconv.Itoa(os.Getpid()),
+			fds:     "abc",
+			wantNil: true,
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(listenPIDEnv, tt.pid)
+			t.Setenv(listenFDsEnv, tt.fds)
+
+			l, err := activatedListener()
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.wantNil, l == nil)
+
+			if !tt.wantKeep {
+				// переменные удаляются, чтобы их не унаследовали дочерние процессы
+				_, ok := os.LookupEnv(listenFDsEnv)
+				assert.False(t, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// listenerFromFD создает listener из открытого дескриптора сокета. Исходный дескриптор закрывается,
+// listener работает с его копией.
+func listenerFromFD(fd uintptr) (net.Listener, error) {
+	f := os.NewFile(fd, "LISTEN_FD_"+strconv.Itoa(int(fd)))
+	defer f.Close()
+
+	l, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("error use socket passed by systemd: %w", err)
+	}
+
+	return l, nil
+}
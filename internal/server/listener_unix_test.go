@@ -0,0 +1,74 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package server
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListen_ReusePort(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		reusePort bool
+		wantErr   require.ErrorAssertionFunc
+	}{
+		{
+			name:      "positive case: second listener on the same port",
+			reusePort: true,
+			wantErr:   require.NoError,
+		},
+		{
+			name:    "error case: port is busy without reuse port",
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := &Server{port: freePort(t), reusePort: tt.reusePort}
+
+			first, err := s.listen(t.Context())
+			require.NoError(t, err)
+
+			defer first.Close()
+
+			second, err := s.listen(t.Context())
+			tt.wantErr(t, err)
+
+			if second != nil {
+				require.NoError(t, second.Close())
+			}
+		})
+	}
+}
+
+func TestListenerFromFD(t *testing.T) {
+	t.Parallel()
+
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	defer l.Close()
+
+	f, err := l.(*net.TCPListener).File()
+	require.NoError(t, err)
+
+	got, err := listenerFromFD(f.Fd())
+	require.NoError(t, err)
+
+	defer got.Close()
+
+	assert.Equal(t, l.Addr().String(), got.Addr().String())
+
+	conn, err := net.Dial("tcp", got.Addr().String())
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package server
+
+import (
+	"errors"
+	"net"
+)
+
+// listenerFromFD возвращает ошибку: на Windows нет передачи сокета через дескриптор (LISTEN_FDS).
+func listenerFromFD(_ uintptr) (net.Listener, error) {
+	return nil, errors.New("socket activation is not supported on windows")
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenerFromFD(t *testing.T) {
+	t.Parallel()
+
+	_, err := listenerFromFD(3)
+	require.ErrorContains(t, err, "not supported on windows")
+}
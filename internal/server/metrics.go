@@ -0,0 +1,150 @@
+package server
+
+import (
+	"auth-service/internal/service/metricguard"
+	"auth-service/internal/service/traceid"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// metricsNamespace и metricsSubsystem - префикс метрик HTTP запросов: auth_http_*.
+	metricsNamespace = "auth"
+	metricsSubsystem = "http"
+
+	// maxUnmatchedPaths - сколько разных путей без маршрута попадает в метрики. Пути сканеров и опечатки
+	// после лимита считаются одной меткой metricguard.Overflow.
+	maxUnmatchedPaths = 100
+	// maxHosts - сколько разных заголовков Host попадает в метрики.
+	maxHosts = 20
+)
+
+const (
+	kb = 1 << 10
+	mb = 1 << 20
+)
+
+// sizeBuckets - интервалы гистограмм размеров запросов и ответов: от 1KB до 10MB.
+var sizeBuckets = []float64{1 * kb, 2 * kb, 5 * kb, 10 * kb, 100 * kb, 500 * kb, 1 * mb, 2.5 * mb, 5 * mb, 10 * mb}
+
+// metricsMiddleware возвращает сбор метрик HTTP запросов с ограничением числа значений меток.
+// Метка url - шаблон маршрута (/users/:id). Для запросов без маршрута - нормализованный путь,
+// метки host и url ограничены по числу разных значений, нестандартные методы объединены.
+// К гистограмме задержки прикрепляется идентификатор трассировки запроса (exemplar).
+func metricsMiddleware(registerer prometheus.Registerer) (echo.MiddlewareFunc, error) {
+	overflow := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_metrics_label_overflow_total",
+		Help: "Количество значений меток метрик HTTP запросов, замененных на other из-за лимита разных значений.",
+	}, []string{"label"})
+
+	labels := []string{"code", "method", "host", "url"}
+
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "requests_total",
+		Help:      "Количество HTTP запросов по коду ответа, методу, хосту и маршруту.",
+	}, labels)
+
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "request_duration_seconds",
+		Help:      "Время обработки HTTP запросов в секундах.",
+		Buckets:   prometheus.DefBuckets,
+	}, labels)
+
+	requestSize := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "request_size_bytes",
+		Help:      "Размер тел HTTP запросов в байтах по Content-Length.",
+		Buckets:   sizeBuckets,
+	}, labels)
+
+	responseSize := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "response_size_bytes",
+		Help:      "Размер тел HTTP ответов в байтах.",
+		Buckets:   sizeBuckets,
+	}, labels)
+
+	for _, collector := range []prometheus.Collector{overflow, requests, duration, requestSize, responseSize} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	paths := metricguard.NewGuard(maxUnmatchedPaths, overflow.WithLabelValues("url"))
+	hosts := metricguard.NewGuard(maxHosts, overflow.WithLabelValues("host"))
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			err := next(c)
+
+			elapsed := time.Since(start)
+			req := c.Request()
+
+			url := c.Path()
+			if url == "" {
+				url = paths.Value(metricguard.NormalizePath(strings.ToValidUTF8(req.URL.Path, "\uFFFD")))
+			}
+
+			values := []string{
+				strconv.Itoa(metricsStatus(c, err)),
+				metricguard.Method(req.Method),
+				hosts.Value(req.Host),
+				url,
+			}
+
+			requests.WithLabelValues(values...).Inc()
+			traceid.Observe(req.Context(), duration.WithLabelValues(values...), elapsed.Seconds())
+			requestSize.WithLabelValues(values...).Observe(float64(max(req.ContentLength, 0)))
+			responseSize.WithLabelValues(values...).Observe(float64(c.Response().Size))
+
+			return err
+		}
+	}, nil
+}
+
+// metricsStatus возвращает код ответа на запрос. Если обработчик вернул ошибку, ответ запишет
+// обработчик ошибок echo уже после middleware, поэтому код берется из ошибки.
+func metricsStatus(c echo.Context, err error) int {
+	status := c.Response().Status
+	if err == nil {
+		return status
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		status = httpErr.Code
+	}
+
+	if status == 0 || status == http.StatusOK {
+		status = http.StatusInternalServerError
+	}
+
+	return status
+}
+
+// metricsHandler отдает метрики общего реестра. Exemplar попадают в ответ только в формате
+// OpenMetrics, поэтому он отдается клиентам, которые его запрашивают (Prometheus с включенными exemplar).
+func metricsHandler() echo.HandlerFunc {
+	h := promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+			DisableCompression: true,
+			EnableOpenMetrics:  true,
+		}))
+
+	return echo.WrapHandler(h)
+}
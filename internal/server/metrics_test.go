@@ -0,0 +1,145 @@
+package server
+
+import (
+	serverMiddleware "auth-service/internal/server/middleware"
+	"auth-service/internal/service/metricguard"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewRegistry()
+
+	metrics, err := metricsMiddleware(registry)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(metrics)
+	e.GET("/users/:id", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	request := func(method, path string) {
+		req := httptest.NewRequest(method, path, nil)
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	request(http.MethodGet, "/users/1")
+	request(http.MethodGet, "/users/2")
+	request(http.MethodGet, "/missing/123456")
+	request(http.MethodGet, "/missing/654321")
+	request("PROPFIND", "/users/3")
+
+	for i := range maxUnmatchedPaths + 10 {
+		request(http.MethodGet, fmt.Sprintf("/scan%d.php", i))
+	}
+
+	assert.InDelta(t, 2, requestsTotal(t, registry, http.MethodGet, "/users/:id"), 0)
+	assert.InDelta(t, 2, requestsTotal(t, registry, http.MethodGet, "/missing/:id"), 0)
+	assert.InDelta(t, 1, requestsTotal(t, registry, metricguard.OtherMethod, "/users/:id"), 0)
+	assert.InDelta(t, 11, requestsTotal(t, registry, http.MethodGet, metricguard.Overflow), 0)
+
+	require.NoError(t, metricguard.SelfCheck(registry))
+
+	// повторная регистрация в том же реестре - конфликт
+	_, err = metricsMiddleware(registry)
+	require.Error(t, err)
+}
+
+func TestMetricsMiddleware_Exemplar(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewRegistry()
+
+	metrics, err := metricsMiddleware(registry)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(serverMiddleware.Mesh(), metrics)
+	e.GET("/users/:id", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	// запрос без трассировки не заменяет exemplar
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/2", nil))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	var exemplars []string
+
+	for _, family := range families {
+		if family.GetName() != "auth_http_request_duration_seconds" {
+			continue
+		}
+
+		for _, m := range family.GetMetric() {
+			for _, b := range m.GetHistogram().GetBucket() {
+				for _, l := range b.GetExemplar().GetLabel() {
+					exemplars = append(exemplars, l.GetName()+"="+l.GetValue())
+				}
+			}
+		}
+	}
+
+	assert.Equal(t, []string{"trace_id=4bf92f3577b34da6a3ce929d0e0e4736"}, exemplars)
+}
+
+func TestMetricsHandler(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	e.GET("/metrics", metricsHandler())
+
+	// Prometheus с включенными exemplar запрашивает OpenMetrics
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "application/openmetrics-text")
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+}
+
+// requestsTotal возвращает значение счетчика запросов с методом и url.
+func requestsTotal(t *testing.T, registry *prometheus.Registry, method, url string) float64 {
+	t.Helper()
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != "auth_http_requests_total" {
+			continue
+		}
+
+		for _, m := range family.GetMetric() {
+			labels := make(map[string]string, len(m.GetLabel()))
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+
+			if labels["method"] == method && labels["url"] == url {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+
+	return 0
+}
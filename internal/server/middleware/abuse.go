@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"auth-service/internal/service/abuse"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Denylist - middleware, которое отклоняет запросы заблокированных IP, сетей и ASN с кодом 403
+// и Retry-After до окончания блокировки. Если Redis недоступен, запросы пропускаются.
+func Denylist(svc *abuse.Service) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ban, err := svc.Banned(c.Request().Context(), svc.ClientOf(c.RealIP(), c.Request().Header))
+			if err != nil {
+				logrus.WithError(err).Error("error check denylist")
+
+				return next(c)
+			}
+
+			if ban == nil {
+				return next(c)
+			}
+
+			retryAfter := int(math.Ceil(time.Until(ban.ExpiresAt).Seconds()))
+			c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(max(retryAfter, 1)))
+
+			return c.JSON(http.StatusForbidden, ErrorResponse{Error: "source is banned"})
+		}
+	}
+}
+
+// Honeypot - middleware ловушек: запрос к одному из путей paths сразу блокирует IP клиента.
+// Путь, заканчивающийся на "/", совпадает со всеми вложенными путями. Клиенту отвечается 404,
+// как будто ловушки нет.
+func Honeypot(svc *abuse.Service, paths []string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			path := c.Request().URL.Path
+			if !matchHoneypot(paths, path) {
+				return next(c)
+			}
+
+			if _, err := svc.Honeypot(c.Request().Context(), svc.ClientOf(c.RealIP(), c.Request().Header), path); err != nil {
+				logrus.WithError(err).Error("error ban honeypot client")
+			}
+
+			return echo.ErrNotFound
+		}
+	}
+}
+
+// matchHoneypot сообщает, является ли путь ловушкой.
+func matchHoneypot(paths []string, path string) bool {
+	for _, p := range paths {
+		if path == p || (strings.HasSuffix(p, "/") && strings.HasPrefix(path, p)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"auth-service/internal/service/abuse"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestHoneypotAndDenylist(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	svc, err := abuse.New(abuse.WithClient(client), abuse.WithRegisterer(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(Denylist(svc))
+	e.Use(Honeypot(svc, []string{"/.env", "/wp-admin/"}))
+	e.GET("/", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	do := func(path, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = ip + ":1234"
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		ip             string
+		wantCode       int
+		wantRetryAfter bool
+	}{
+		{
+			name:     "positive case: regular request",
+			path:     "/",
+			ip:       "10.0.0.1",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "positive case: honeypot looks like missing page",
+			path:     "/wp-admin/install.php",
+			ip:       "10.0.0.1",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:           "negative case: banned after honeypot",
+			path:           "/",
+			ip:             "10.0.0.1",
+			wantCode:       http.StatusForbidden,
+			wantRetryAfter: true,
+		},
+		{
+			name:     "positive case: other ip of the network is not banned",
+			path:     "/",
+			ip:       "10.0.0.2",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "positive case: not a honeypot",
+			path:     "/.envrc",
+			ip:       "10.0.0.3",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "positive case: not banned after missing page",
+			path:     "/",
+			ip:       "10.0.0.3",
+			wantCode: http.StatusOK,
+		},
+	}
+
+	// шаги зависят друг от друга, поэтому выполняются последовательно
+	for _, tt := range tests {
+		rec := do(tt.path, tt.ip)
+		assert.Equal(t, tt.wantCode, rec.Code, tt.name)
+		assert.Equal(t, tt.wantRetryAfter, rec.Header().Get(echo.HeaderRetryAfter) != "", tt.name)
+	}
+
+	// без Redis запросы пропускаются
+	mr.Close()
+
+	assert.Equal(t, http.StatusOK, do("/", "10.0.0.1").Code)
+}
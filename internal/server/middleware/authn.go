@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"auth-service/internal/service/token"
+	"auth-service/pkg/authclient"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Authenticate - middleware, которое один раз за запрос проверяет токен пользователя из заголовка
+// "Authorization: Bearer <jwt>" и сохраняет claims в контекст запроса: token.FromContext для обработчиков
+// сервиса и authclient.ClaimsFromContext для типизированных claims пользователя.
+// Запрос без токена пропускается: требует ли маршрут аутентификацию, решает обработчик.
+// Недействительный токен получает 401, недоступность ключей подписи - 503. 401 получает и токен,
+// привязанный к сертификату (cnf), если запрос пришел без этого сертификата mTLS, и истекший токен,
+// принятый в режиме мягкой проверки, в изменяющем запросе.
+func Authenticate(validator *token.Validator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			raw, ok := strings.CutPrefix(req.Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if !ok || raw == "" {
+				return next(c)
+			}
+
+			claims, err := validator.Validate(req.Context(), raw)
+			if errors.Is(err, token.ErrInvalidToken) {
+				logrus.WithError(err).WithField("route", c.Path()).Debug("bearer token rejected")
+
+				return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "invalid token"})
+			}
+
+			if err != nil {
+				logrus.WithError(err).Error("error validate token")
+
+				return c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "signing keys are unavailable"})
+			}
+
+			if err := checkPresentation(req, claims); err != nil {
+				logrus.WithError(err).WithFields(logrus.Fields{
+					"jti":   claims.ID,
+					"route": c.Path(),
+				}).Warn("bearer token rejected")
+
+				if errors.Is(err, token.ErrGraceReadOnly) {
+					return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "token is expired"})
+				}
+
+				return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "invalid token"})
+			}
+
+			c.SetRequest(req.WithContext(WithClaims(req.Context(), claims)))
+
+			return next(c)
+		}
+	}
+}
+
+// checkPresentation проверяет, что проверенный токен можно использовать в этом запросе:
+// привязку к клиентскому сертификату и ограничение мягкой проверки.
+func checkPresentation(req *http.Request, claims *token.Claims) error {
+	if err := token.CheckBinding(claims, token.PeerThumbprint(req.TLS)); err != nil {
+		return err
+	}
+
+	return token.CheckGrace(claims, req.Method)
+}
+
+// WithClaims сохраняет claims проверенного токена в контекст так же, как Authenticate.
+func WithClaims(ctx context.Context, claims *token.Claims) context.Context {
+	return authclient.NewContext(token.NewContext(ctx, claims), claims.Principal())
+}
+
+// LogSubject - CustomTagFunc логгера запросов echo: пишет субъект токена, принятого при аутентификации,
+// чтобы в журнале запросов было видно, кто выполнил запрос. Для токенов имперсонации пишет
+// "<субъект> by <actor>".
+func LogSubject(c echo.Context, buf *bytes.Buffer) (int, error) {
+	claims, ok := token.FromContext(c.Request().Context())
+	if !ok {
+		return 0, nil
+	}
+
+	subject := claims.Subject
+	if claims.Actor != nil {
+		subject += " by " + claims.Actor.Subject
+	}
+
+	// значение пишется внутрь строки JSON
+	data, err := json.Marshal(subject)
+	if err != nil {
+		return 0, err
+	}
+
+	return buf.Write(data[1 : len(data)-1])
+}
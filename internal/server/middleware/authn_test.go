@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"auth-service/internal/service/token"
+	"auth-service/pkg/authclient"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testKeys - ключи подписи для тестов. Ключ broken недоступен.
+type testKeys struct{}
+
+func (testKeys) Key(_ context.Context, kid string) ([]byte, error) {
+	if kid == "broken" {
+		return nil, errors.New("vault is sealed")
+	}
+
+	return []byte("secret"), nil
+}
+
+func signUserToken(t *testing.T, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tok.Header["kid"] = kid
+
+	raw, err := tok.SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	return raw
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	validator, err := token.NewValidator(
+		token.WithKeys(testKeys{}),
+		token.WithGrace(token.Grace{Period: time.Hour, Audiences: []string{"bot"}}),
+	)
+	require.NoError(t, err)
+
+	exp := time.Now().Add(time.Hour).Unix()
+
+	cert := &x509.Certificate{Raw: []byte("client certificate")}
+	mtls := &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}, VerifiedChains: [][]*x509.Certificate{{cert}}}
+	bound := signUserToken(t, "key-1", jwt.MapClaims{
+		"sub": "svc", "exp": exp, "cnf": map[string]string{"x5t#S256": token.CertThumbprint(cert)},
+	})
+	grace := signUserToken(t, "key-1", jwt.MapClaims{"sub": "bot-1", "aud": "bot", "exp": time.Now().Add(-time.Minute).Unix()})
+
+	e := echo.New()
+	e.Use(Authenticate(validator))
+	e.Match([]string{http.MethodGet, http.MethodPost}, "/account", func(c echo.Context) error {
+		claims, ok := token.FromContext(c.Request().Context())
+		if !ok {
+			return c.String(http.StatusOK, "anonymous")
+		}
+
+		principal, ok := authclient.ClaimsFromContext(c.Request().Context())
+		if !ok {
+			return c.NoContent(http.StatusInternalServerError)
+		}
+
+		return c.String(http.StatusOK, claims.Subject+"/"+principal.Tenant)
+	})
+
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		tls           *tls.ConnectionState
+		wantCode      int
+		wantBody      string
+	}{
+		{
+			name:     "positive case: no token",
+			wantCode: http.StatusOK,
+			wantBody: "anonymous",
+		},
+		{
+			name:          "positive case: valid token",
+			authorization: "Bearer " + signUserToken(t, "key-1", jwt.MapClaims{"sub": "user-1", "exp": exp, "tenant": "acme"}),
+			wantCode:      http.StatusOK,
+			wantBody:      "user-1/acme",
+		},
+		{
+			name:          "error case: expired token",
+			authorization: "Bearer " + signUserToken(t, "key-1", jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(-time.Hour).Unix()}),
+			wantCode:      http.StatusUnauthorized,
+			wantBody:      `{"error":"invalid token"}`,
+		},
+		{
+			name:          "positive case: certificate-bound token with its certificate",
+			method:        http.MethodPost,
+			authorization: "Bearer " + bound,
+			tls:           mtls,
+			wantCode:      http.StatusOK,
+			wantBody:      "svc/",
+		},
+		{
+			name:          "error case: certificate-bound token without certificate",
+			authorization: "Bearer " + bound,
+			wantCode:      http.StatusUnauthorized,
+			wantBody:      `{"error":"invalid token"}`,
+		},
+		{
+			name:          "positive case: grace token for read",
+			authorization: "Bearer " + grace,
+			wantCode:      http.StatusOK,
+			wantBody:      "bot-1/",
+		},
+		{
+			name:          "error case: grace token for state-changing request",
+			method:        http.MethodPost,
+			authorization: "Bearer " + grace,
+			wantCode:      http.StatusUnauthorized,
+			wantBody:      `{"error":"token is expired"}`,
+		},
+		{
+			name:          "error case: keys are unavailable",
+			authorization: "Bearer " + signUserToken(t, "broken", jwt.MapClaims{"sub": "user-1", "exp": exp}),
+			wantCode:      http.StatusServiceUnavailable,
+			wantBody:      `{"error":"signing keys are unavailable"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			req := httptest.NewRequest(method, "/account", nil)
+			if tt.authorization != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.authorization)
+			}
+
+			req.TLS = tt.tls
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Equal(t, tt.wantBody, strings.TrimSpace(rec.Body.String()))
+		})
+	}
+}
+
+func TestLogSubject(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		claims *token.Claims
+		want   string
+	}{
+		{
+			name: "positive case: anonymous",
+		},
+		{
+			name:   "positive case: user",
+			claims: &token.Claims{Subject: `user-"1"`},
+			want:   `user-\"1\"`,
+		},
+		{
+			name:   "positive case: impersonation",
+			claims: &token.Claims{Subject: "user-1", Actor: &token.Actor{Subject: "support-1"}},
+			want:   "user-1 by support-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.claims != nil {
+				req = req.WithContext(WithClaims(req.Context(), tt.claims))
+			}
+
+			var buf bytes.Buffer
+
+			_, err := LogSubject(echo.New().NewContext(req, httptest.NewRecorder()), &buf)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"auth-service/internal/service/capture"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// Capture - middleware, которое сохраняет тела запросов и ответов выбранных маршрутов в буфер.
+// Решение о захвате принимается до обработки запроса, поэтому тела читаются только у попавших в выборку запросов.
+func Capture(c *capture.Capture) echo.MiddlewareFunc {
+	return middleware.BodyDumpWithConfig(middleware.BodyDumpConfig{
+		Skipper: func(ctx echo.Context) bool {
+			return !c.ShouldSample(ctx.Path())
+		},
+		Handler: func(ctx echo.Context, reqBody, resBody []byte) {
+			c.Record(capture.Entry{
+				Time:         time.Now(),
+				Method:       ctx.Request().Method,
+				Route:        ctx.Path(),
+				Path:         ctx.Request().URL.Path,
+				Status:       ctx.Response().Status,
+				RequestBody:  capture.Redact(reqBody),
+				ResponseBody: capture.Redact(resBody),
+			})
+		},
+	})
+}
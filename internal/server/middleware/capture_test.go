@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"auth-service/internal/service/capture"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapture(t *testing.T) {
+	t.Parallel()
+
+	c, err := capture.New()
+	require.NoError(t, err)
+
+	require.NoError(t, c.Update(capture.Settings{
+		Enabled: true,
+		Routes:  map[string]float64{"/login": 1},
+	}))
+
+	e := echo.New()
+	e.Use(Capture(c))
+
+	handler := func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"access_token": "jwt", "user": "bot"})
+	}
+
+	e.POST("/login", handler)
+	e.POST("/other", handler)
+
+	for _, path := range []string{"/login", "/other"} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"username":"bot","password":"qwerty"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		// тело ответа клиенту не изменено
+		assert.Contains(t, rec.Body.String(), `"access_token":"jwt"`)
+	}
+
+	entries := c.Entries()
+	require.Len(t, entries, 1)
+
+	assert.Equal(t, "/login", entries[0].Route)
+	assert.Equal(t, http.MethodPost, entries[0].Method)
+	assert.Equal(t, http.StatusOK, entries[0].Status)
+	assert.JSONEq(t, `{"username":"bot","password":"[REDACTED]"}`, entries[0].RequestBody)
+	assert.JSONEq(t, `{"user":"bot","access_token":"[REDACTED]"}`, entries[0].ResponseBody)
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"auth-service/internal/service/deprecation"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Deprecation - middleware, которое добавляет в ответы устаревших маршрутов заголовки Deprecation,
+// Sunset и Link и учитывает обращения к ним. Запрос обрабатывается как обычно: маршрут продолжает
+// работать до удаления из кода.
+func Deprecation(registry *deprecation.Registry) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			headers, ok := registry.Observe(c.Request().Method, c.Path(), c.RealIP())
+			if ok {
+				h := c.Response().Header()
+
+				for name, values := range headers {
+					for _, v := range values {
+						h.Add(name, v)
+					}
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"auth-service/internal/service/deprecation"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeprecation(t *testing.T) {
+	t.Parallel()
+
+	registry, err := deprecation.New(
+		deprecation.WithRegisterer(prometheus.NewRegistry()),
+		deprecation.WithRoutes(deprecation.Route{
+			Method:    http.MethodGet,
+			Path:      "/users/:id",
+			Since:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			Successor: "/v1/users/:id",
+		}),
+	)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(Deprecation(registry))
+
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e.GET("/users/:id", ok)
+	e.POST("/users/:id", ok)
+
+	do := func(method string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(method, "/users/42", nil))
+
+		return rec
+	}
+
+	// маршрут сопоставляется по шаблону и продолжает работать
+	rec := do(http.MethodGet)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "@1767225600", rec.Header().Get("Deprecation"))
+	assert.Equal(t, `</v1/users/:id>; rel="successor-version"`, rec.Header().Get("Link"))
+
+	// другой метод того же шаблона не устарел
+	rec = do(http.MethodPost)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Deprecation"))
+}
@@ -0,0 +1,86 @@
+// Package middleware содержит middleware сервера, не входящие в стандартный набор echo.
+package middleware
+
+import (
+	"auth-service/internal/service/traceid"
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// meshHeaders возвращает заголовки сервис-меша и трассировки, которые сервис принимает
+// от вызывающей стороны и передает дальше в исходящие запросы.
+func meshHeaders() []string {
+	return []string{
+		echo.HeaderXRequestID,
+		echo.HeaderXForwardedFor,
+		"Traceparent",
+		"Tracestate",
+		"B3",
+		"X-B3-Traceid",
+		"X-B3-Spanid",
+		"X-B3-Parentspanid",
+		"X-B3-Sampled",
+		"X-B3-Flags",
+	}
+}
+
+type meshKey struct{}
+
+// Mesh - middleware, которое сохраняет заголовки меша из входящего запроса в контекст запроса,
+// чтобы их можно было передать в исходящие запросы (InjectMesh), и возвращает X-Request-ID в ответе.
+// Идентификатор трассировки из заголовков сохраняется в контексте для exemplar метрик (traceid.FromContext).
+func Mesh() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			headers := http.Header{}
+
+			for _, name := range meshHeaders() {
+				if values := req.Header.Values(name); len(values) > 0 {
+					headers[name] = values
+				}
+			}
+
+			if id := headers.Get(echo.HeaderXRequestID); id != "" {
+				c.Response().Header().Set(echo.HeaderXRequestID, id)
+			}
+
+			if len(headers) > 0 {
+				ctx := context.WithValue(req.Context(), meshKey{}, headers)
+
+				if id := traceid.FromHeader(headers); id != "" {
+					ctx = traceid.NewContext(ctx, id)
+				}
+
+				c.SetRequest(req.WithContext(ctx))
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// MeshFromContext возвращает копию заголовков меша, сохраненных в контексте.
+// Если заголовков нет, возвращает пустой http.Header.
+func MeshFromContext(ctx context.Context) http.Header {
+	headers, ok := ctx.Value(meshKey{}).(http.Header)
+	if !ok {
+		return http.Header{}
+	}
+
+	return headers.Clone()
+}
+
+// InjectMesh копирует заголовки меша из контекста в заголовки исходящего запроса.
+// Уже установленные заголовки не перезаписываются.
+func InjectMesh(ctx context.Context, dst http.Header) {
+	for name, values := range MeshFromContext(ctx) {
+		if dst.Get(name) != "" {
+			continue
+		}
+
+		dst[name] = values
+	}
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"auth-service/internal/service/traceid"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestMesh(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		headers       map[string]string
+		wantHeaders   http.Header
+		wantRequestID string
+		wantTraceID   string
+	}{
+		{
+			name: "positive case: mesh headers",
+			headers: map[string]string{
+				"X-Request-ID":    "req-1",
+				"traceparent":     "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				"x-b3-traceid":    "4bf92f3577b34da6",
+				"X-Forwarded-For": "203.0.113.1, 10.0.0.1",
+				"Authorization":   "Bearer secret",
+			},
+			wantHeaders: http.Header{
+				"X-Request-Id":    {"req-1"},
+				"Traceparent":     {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+				"X-B3-Traceid":    {"4bf92f3577b34da6"},
+				"X-Forwarded-For": {"203.0.113.1, 10.0.0.1"},
+			},
+			wantRequestID: "req-1",
+			wantTraceID:   "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name:        "positive case: no mesh headers",
+			headers:     map[string]string{"Authorization": "Bearer secret"},
+			wantHeaders: http.Header{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			var (
+				got     http.Header
+				traceID string
+			)
+
+			h := Mesh()(func(c echo.Context) error {
+				got = MeshFromContext(c.Request().Context())
+				traceID = traceid.FromContext(c.Request().Context())
+
+				return c.NoContent(http.StatusOK)
+			})
+
+			require.NoError(t, h(c))
+			assert.Equal(t, tt.wantHeaders, got)
+			assert.Equal(t, tt.wantTraceID, traceID)
+			assert.Equal(t, tt.wantRequestID, rec.Header().Get(echo.HeaderXRequestID))
+		})
+	}
+}
+
+func TestInjectMesh(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(t.Context(), meshKey{}, http.Header{
+		"Traceparent":  {"00-trace-span-01"},
+		"X-Request-Id": {"req-1"},
+	})
+
+	dst := http.Header{}
+	dst.Set(echo.HeaderXRequestID, "already-set")
+
+	InjectMesh(ctx, dst)
+
+	assert.Equal(t, "00-trace-span-01", dst.Get("Traceparent"))
+	assert.Equal(t, "already-set", dst.Get(echo.HeaderXRequestID))
+
+	empty := http.Header{}
+	InjectMesh(t.Context(), empty)
+	assert.Empty(t, empty)
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"auth-service/internal/service/peer"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+type peerKey struct{}
+
+// PeerAuth - middleware, требующее токен peer сервиса в заголовке "Authorization: Bearer <jwt>"
+// для указанных маршрутов (шаблонов вида /api/v0/token/introspect). Если токена нет или он не прошел
+// проверку, отвечает 401. Claims проверенного токена сохраняются в контекст запроса (PeerFromContext).
+// Остальные маршруты пропускаются без проверки.
+func PeerAuth(store *peer.TrustStore, routes []string) echo.MiddlewareFunc {
+	protected := make(map[string]struct{}, len(routes))
+	for _, route := range routes {
+		protected[route] = struct{}{}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, ok := protected[c.Path()]; !ok {
+				return next(c)
+			}
+
+			raw, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if !ok || raw == "" {
+				return peerUnauthorized(c, "peer token required")
+			}
+
+			req := c.Request()
+
+			claims, err := store.Verify(req.Context(), raw)
+			if err != nil {
+				logrus.WithError(err).WithField("route", c.Path()).Warn("peer token rejected")
+
+				msg := "invalid peer token"
+				if errors.Is(err, peer.ErrUntrustedIssuer) {
+					msg = "untrusted peer"
+				}
+
+				return peerUnauthorized(c, msg)
+			}
+
+			c.SetRequest(req.WithContext(context.WithValue(req.Context(), peerKey{}, claims)))
+
+			return next(c)
+		}
+	}
+}
+
+func peerUnauthorized(c echo.Context, msg string) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="peer"`)
+
+	return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: msg})
+}
+
+// PeerFromContext возвращает claims токена peer сервиса, сохраненные PeerAuth.
+func PeerFromContext(ctx context.Context) (*peer.Claims, bool) {
+	claims, ok := ctx.Value(peerKey{}).(*peer.Claims)
+
+	return claims, ok
+}
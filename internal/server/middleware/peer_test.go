@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"auth-service/internal/service/peer"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestPeerAuth(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: key.Public(), KeyID: "rsa-1", Algorithm: "RS256", Use: "sig"},
+		}})
+	}))
+	t.Cleanup(jwks.Close)
+
+	store, err := peer.New(t.Context(),
+		peer.WithIssuers(peer.Issuer{Name: "notes", Issuer: "https://notes.example", JWKSURL: jwks.URL}),
+		peer.WithAudience("auth-service"),
+		peer.WithRegisterer(prometheus.NewRegistry()),
+	)
+	require.NoError(t, err)
+
+	sign := func(iss string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+			Issuer:    iss,
+			Subject:   "notes-bot",
+			Audience:  jwt.ClaimStrings{"auth-service"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		})
+		token.Header["kid"] = "rsa-1"
+
+		raw, err := token.SignedString(key)
+		require.NoError(t, err)
+
+		return raw
+	}
+
+	e := echo.New()
+	e.Use(PeerAuth(store, []string{"/internal/:id"}))
+
+	handler := func(c echo.Context) error {
+		claims, ok := PeerFromContext(c.Request().Context())
+		if !ok {
+			return c.NoContent(http.StatusOK)
+		}
+
+		return c.String(http.StatusOK, claims.Peer+"/"+claims.Subject)
+	}
+	e.GET("/internal/:id", handler)
+	e.GET("/public", handler)
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		wantCode      int
+		wantBody      string
+	}{
+		{
+			name:     "positive case: public route",
+			path:     "/public",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:          "positive case: peer token",
+			path:          "/internal/1",
+			authorization: "Bearer " + sign("https://notes.example"),
+			wantCode:      http.StatusOK,
+			wantBody:      "notes/notes-bot",
+		},
+		{
+			name:     "error case: no token",
+			path:     "/internal/1",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":"peer token required"}`,
+		},
+		{
+			name:          "error case: untrusted issuer",
+			path:          "/internal/1",
+			authorization: "Bearer " + sign("https://evil.example"),
+			wantCode:      http.StatusUnauthorized,
+			wantBody:      `{"error":"untrusted peer"}`,
+		},
+		{
+			name:          "error case: invalid token",
+			path:          "/internal/1",
+			authorization: "Bearer not-a-jwt",
+			wantCode:      http.StatusUnauthorized,
+			wantBody:      `{"error":"invalid peer token"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.authorization)
+			}
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Equal(t, tt.wantBody, strings.TrimSpace(rec.Body.String()))
+
+			if tt.wantCode == http.StatusUnauthorized {
+				assert.Equal(t, `Bearer realm="peer"`, rec.Header().Get(echo.HeaderWWWAuthenticate))
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"auth-service/internal/service/pow"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Заголовки согласования proof-of-work.
+const (
+	// HeaderPoWChallenge - challenge, выданный сервером.
+	HeaderPoWChallenge = "X-PoW-Challenge"
+	// HeaderPoWDifficulty - требуемое количество нулевых бит в начале sha256(<challenge>:<nonce>).
+	HeaderPoWDifficulty = "X-PoW-Difficulty"
+	// HeaderPoWSolution - решение клиента в формате <challenge>:<nonce>.
+	HeaderPoWSolution = "X-PoW-Solution"
+)
+
+// ProofOfWorkResponse - тело ответа 428 с новым challenge.
+type ProofOfWorkResponse struct {
+	Error      string `json:"error"`
+	Challenge  string `json:"challenge"`
+	Difficulty int    `json:"difficulty"`
+}
+
+// ProofOfWork - middleware, требующее решения proof-of-work задачи для указанных маршрутов
+// (шаблонов вида /api/v0/otp/request). Если заголовок X-PoW-Solution отсутствует или решение неверно,
+// отвечает 428 и выдает новый challenge в заголовках X-PoW-Challenge и X-PoW-Difficulty и в теле ответа.
+// Остальные маршруты пропускаются без проверки.
+func ProofOfWork(svc *pow.Service, routes []string) echo.MiddlewareFunc {
+	protected := make(map[string]struct{}, len(routes))
+	for _, route := range routes {
+		protected[route] = struct{}{}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			route := c.Path()
+			if _, ok := protected[route]; !ok {
+				return next(c)
+			}
+
+			solution := c.Request().Header.Get(HeaderPoWSolution)
+
+			err := svc.Verify(route, solution)
+			if err == nil {
+				return next(c)
+			}
+
+			challenge, cerr := svc.Challenge(route)
+			if cerr != nil {
+				logrus.WithError(cerr).Error("error create proof-of-work challenge")
+
+				return c.JSON(http.StatusInternalServerError, ProofOfWorkResponse{Error: "internal server error"})
+			}
+
+			msg := "proof of work required"
+			if solution != "" {
+				msg = err.Error()
+			}
+
+			h := c.Response().Header()
+			h.Set(HeaderPoWChallenge, challenge)
+			h.Set(HeaderPoWDifficulty, strconv.Itoa(svc.Difficulty()))
+
+			return c.JSON(http.StatusPreconditionRequired, ProofOfWorkResponse{
+				Error:      msg,
+				Challenge:  challenge,
+				Difficulty: svc.Difficulty(),
+			})
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"auth-service/internal/service/pow"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProofOfWork(t *testing.T) {
+	t.Parallel()
+
+	svc, err := pow.New(pow.WithDifficulty(8))
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(ProofOfWork(svc, []string{"/otp/request"}))
+
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e.POST("/otp/request", ok)
+	e.POST("/other", ok)
+
+	do := func(path, solution string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		if solution != "" {
+			req.Header.Set(HeaderPoWSolution, solution)
+		}
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	// незащищенный маршрут пропускается
+	assert.Equal(t, http.StatusOK, do("/other", "").Code)
+
+	rec := do("/otp/request", "")
+	require.Equal(t, http.StatusPreconditionRequired, rec.Code)
+	assert.Equal(t, "8", rec.Header().Get(HeaderPoWDifficulty))
+
+	var body ProofOfWorkResponse
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, "proof of work required", body.Error)
+	assert.Equal(t, rec.Header().Get(HeaderPoWChallenge), body.Challenge)
+	assert.Equal(t, 8, body.Difficulty)
+
+	// решение поддельного challenge не принимается
+	rec = do("/otp/request", pow.Solve("1.2.3", body.Difficulty))
+	require.Equal(t, http.StatusPreconditionRequired, rec.Code)
+	assert.NotEqual(t, body.Challenge, rec.Header().Get(HeaderPoWChallenge))
+
+	solution := pow.Solve(body.Challenge, body.Difficulty)
+
+	assert.Equal(t, http.StatusOK, do("/otp/request", solution).Code)
+
+	rec = do("/otp/request", solution)
+	require.Equal(t, http.StatusPreconditionRequired, rec.Code)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, pow.ErrReused.Error(), body.Error)
+	assert.Equal(t, strconv.Itoa(svc.Difficulty()), rec.Header().Get(HeaderPoWDifficulty))
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"auth-service/internal/service/quota"
+	"auth-service/internal/service/ratelimit"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Заголовки квот API ключа.
+const (
+	// HeaderAPIKey - API ключ клиента в формате <id>.<секрет>.
+	HeaderAPIKey = "X-API-Key"
+	// HeaderQuotaDailyRemaining - остаток квоты на текущие сутки (если квота задана).
+	HeaderQuotaDailyRemaining = "X-Quota-Daily-Remaining"
+	// HeaderQuotaMonthlyRemaining - остаток квоты на текущий месяц (если квота задана).
+	HeaderQuotaMonthlyRemaining = "X-Quota-Monthly-Remaining"
+)
+
+// ErrorResponse - тело ответа с ошибкой.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Quota - middleware учета квот API ключей. Запросы без заголовка X-API-Key пропускаются без учета.
+// Неизвестный ключ - 401, исчерпанная квота - 429 с Retry-After до сброса квоты.
+// Запросы к маршрутам uncounted аутентифицируются, но не учитываются (например, просмотр использования).
+// Аутентифицированный ключ сохраняется в контексте запроса (quota.FromContext).
+//
+// Если задан limiter, частота запросов ключа ограничивается его правилом (тариф или собственное
+// ограничение из записи ключа, см. quota.RateLimit) до учета квоты: отклоненный запрос квоту не расходует.
+func Quota(svc *quota.Service, limiter *ratelimit.Limiter, observer *ratelimit.Observer, uncounted ...string) echo.MiddlewareFunc {
+	skip := make(map[string]struct{}, len(uncounted))
+	for _, route := range uncounted {
+		skip[route] = struct{}{}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			apiKey := c.Request().Header.Get(HeaderAPIKey)
+			if apiKey == "" {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+
+			key, err := svc.Authenticate(ctx, apiKey)
+			if err != nil {
+				return quotaError(c, err)
+			}
+
+			c.SetRequest(c.Request().WithContext(quota.NewContext(ctx, key)))
+
+			consume := func(c echo.Context) error {
+				if _, ok := skip[c.Path()]; ok {
+					return next(c)
+				}
+
+				return consumeQuota(c, next, svc, key)
+			}
+
+			if limiter == nil || !key.RateLimit.Rule.Enabled() {
+				return consume(c)
+			}
+
+			return limit(c, consume, limiter, "apikey", key.ID, key.RateLimit.Rule, observer)
+		}
+	}
+}
+
+// consumeQuota учитывает запрос ключа в квоте и передает его дальше или отвечает 429, если квота исчерпана.
+func consumeQuota(c echo.Context, next echo.HandlerFunc, svc *quota.Service, key quota.Key) error {
+	usage, err := svc.Consume(c.Request().Context(), key)
+
+	h := c.Response().Header()
+	setRemaining(h, HeaderQuotaDailyRemaining, usage.Daily.Remaining)
+	setRemaining(h, HeaderQuotaMonthlyRemaining, usage.Monthly.Remaining)
+
+	if errors.Is(err, quota.ErrExhausted) {
+		retryAfter := seconds(usage.RetryAfter(time.Now()))
+		h.Set(echo.HeaderRetryAfter, strconv.Itoa(retryAfter))
+
+		return c.JSON(http.StatusTooManyRequests, TooManyRequestsResponse{
+			Error:             err.Error(),
+			RetryAfterSeconds: retryAfter,
+		})
+	}
+
+	if err != nil {
+		return quotaError(c, err)
+	}
+
+	return next(c)
+}
+
+func quotaError(c echo.Context, err error) error {
+	if errors.Is(err, quota.ErrInvalidKey) {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+	}
+
+	logrus.WithError(err).Error("error check api key quota")
+
+	return c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "quota storage is unavailable"})
+}
+
+func setRemaining(h http.Header, name string, remaining *int64) {
+	if remaining != nil {
+		h.Set(name, strconv.FormatInt(*remaining, 10))
+	}
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"auth-service/internal/service/quota"
+	"auth-service/internal/service/ratelimit"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestQuota(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	sum := sha256.Sum256([]byte("s3cret"))
+	mr.HSet("auth:apikey:bot", "secret_hash", hex.EncodeToString(sum[:]))
+
+	svc, err := quota.New(quota.WithClient(client), quota.WithDefaultLimits(quota.Limits{Daily: 2}))
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(Quota(svc, nil, nil, "/usage"))
+
+	handler := func(c echo.Context) error {
+		key, ok := quota.FromContext(c.Request().Context())
+		if !ok {
+			return c.String(http.StatusOK, "anonymous")
+		}
+
+		return c.String(http.StatusOK, key.ID)
+	}
+	e.GET("/", handler)
+	e.GET("/usage", handler)
+
+	do := func(path, apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if apiKey != "" {
+			req.Header.Set(HeaderAPIKey, apiKey)
+		}
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec := do("/", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "anonymous", rec.Body.String())
+
+	assert.Equal(t, http.StatusUnauthorized, do("/", "bot.wrong").Code)
+
+	rec = do("/", "bot.s3cret")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "bot", rec.Body.String())
+	assert.Equal(t, "1", rec.Header().Get(HeaderQuotaDailyRemaining))
+	assert.Empty(t, rec.Header().Get(HeaderQuotaMonthlyRemaining))
+
+	rec = do("/", "bot.s3cret")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "0", rec.Header().Get(HeaderQuotaDailyRemaining))
+
+	rec = do("/", "bot.s3cret")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get(echo.HeaderRetryAfter))
+
+	var body TooManyRequestsResponse
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, quota.ErrExhausted.Error(), body.Error)
+	assert.Positive(t, body.RetryAfterSeconds)
+
+	// просмотр использования не учитывается и доступен при исчерпанной квоте
+	rec = do("/usage", "bot.s3cret")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "bot", rec.Body.String())
+
+	// хранилище недоступно
+	mr.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, do("/", "bot.s3cret").Code)
+}
+
+func TestQuota_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	sum := sha256.Sum256([]byte("s3cret"))
+	mr.HSet("auth:apikey:bot", "secret_hash", hex.EncodeToString(sum[:]), "rate_limit_tier", "free")
+	mr.HSet("auth:apikey:partner", "secret_hash", hex.EncodeToString(sum[:]), "rate_limit_requests", "3", "rate_limit_window", "60")
+
+	svc, err := quota.New(
+		quota.WithClient(client),
+		quota.WithRateLimits(ratelimit.Rule{}, map[string]ratelimit.Rule{"free": {Requests: 1, Window: time.Minute}}),
+	)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(Quota(svc, ratelimit.New(), nil))
+	e.GET("/", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	do := func(apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HeaderAPIKey, apiKey)
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec := do("bot.s3cret")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get(HeaderRateLimitLimit))
+
+	rec = do("bot.s3cret")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get(echo.HeaderRetryAfter))
+
+	// отклоненный запрос не расходует квоту
+	usage, err := svc.Usage(t.Context(), quota.Key{ID: "bot"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), usage.Daily.Used)
+
+	// собственное ограничение ключа выше тарифа
+	for range 3 {
+		rec = do("partner.s3cret")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "3", rec.Header().Get(HeaderRateLimitLimit))
+	}
+
+	assert.Equal(t, http.StatusTooManyRequests, do("partner.s3cret").Code)
+}
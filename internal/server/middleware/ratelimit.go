@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"auth-service/internal/service/ratelimit"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Заголовки ограничения частоты запросов (draft-ietf-httpapi-ratelimit-headers).
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+)
+
+// TooManyRequestsResponse - тело ответа 429.
+type TooManyRequestsResponse struct {
+	Error             string `json:"error"`
+	RetryAfterSeconds int    `json:"retry_after_seconds"`
+}
+
+// RateLimit - middleware, ограничивающее частоту запросов с одного IP к группе эндпоинтов.
+// На каждый ответ добавляет заголовки RateLimit-*, при превышении лимита отвечает 429
+// с заголовком Retry-After и временем ожидания в теле, чтобы клиенты могли корректно подождать.
+// IP клиента определяется с учетом доверенных прокси.
+//
+// Если задан observer (режим наблюдения), запросы сверх лимита не отклоняются, а только учитываются,
+// и заголовки RateLimit-* не добавляются, чтобы клиенты не замедлялись раньше включения ограничений.
+func RateLimit(limiter *ratelimit.Limiter, group string, rule ratelimit.Rule, observer *ratelimit.Observer) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			return limit(c, next, limiter, group, c.RealIP(), rule, observer)
+		}
+	}
+}
+
+// limit учитывает запрос клиента client из группы group и либо передает его дальше, либо отвечает 429.
+func limit(c echo.Context, next echo.HandlerFunc, limiter *ratelimit.Limiter, group, client string, rule ratelimit.Rule, observer *ratelimit.Observer) error {
+	res := limiter.Allow(group+":"+client, rule)
+
+	if observer != nil {
+		if !res.Allowed {
+			observer.Observe(group, client, rule)
+		}
+
+		return next(c)
+	}
+
+	reset := seconds(res.ResetAfter)
+
+	h := c.Response().Header()
+	h.Set(HeaderRateLimitLimit, strconv.Itoa(res.Limit))
+	h.Set(HeaderRateLimitRemaining, strconv.Itoa(res.Remaining))
+	h.Set(HeaderRateLimitReset, strconv.Itoa(reset))
+
+	if res.Allowed {
+		return next(c)
+	}
+
+	h.Set(echo.HeaderRetryAfter, strconv.Itoa(reset))
+
+	return c.JSON(http.StatusTooManyRequests, TooManyRequestsResponse{
+		Error:             "too many requests",
+		RetryAfterSeconds: reset,
+	})
+}
+
+// seconds округляет длительность вверх до целых секунд, но не меньше 1.
+func seconds(d time.Duration) int {
+	return max(1, int(math.Ceil(d.Seconds())))
+}
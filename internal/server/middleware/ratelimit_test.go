@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"auth-service/internal/service/ratelimit"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+
+	limiter := ratelimit.New()
+	rule := ratelimit.Rule{Requests: 2, Window: time.Minute}
+
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, RateLimit(limiter, "login", rule, nil))
+
+	do := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec := do("192.0.2.1:1000")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get(HeaderRateLimitLimit))
+	assert.Equal(t, "1", rec.Header().Get(HeaderRateLimitRemaining))
+	assert.Equal(t, "60", rec.Header().Get(HeaderRateLimitReset))
+	assert.Empty(t, rec.Header().Get(echo.HeaderRetryAfter))
+
+	rec = do("192.0.2.1:1001")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "0", rec.Header().Get(HeaderRateLimitRemaining))
+
+	rec = do("192.0.2.1:1002")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "0", rec.Header().Get(HeaderRateLimitRemaining))
+	assert.NotEmpty(t, rec.Header().Get(echo.HeaderRetryAfter))
+
+	var body TooManyRequestsResponse
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, "too many requests", body.Error)
+	assert.Positive(t, body.RetryAfterSeconds)
+	assert.Equal(t, rec.Header().Get(echo.HeaderRetryAfter), rec.Header().Get(HeaderRateLimitReset))
+
+	// другой IP не затронут
+	rec = do("192.0.2.2:1000")
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRateLimit_Observe(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+
+	observer, err := ratelimit.NewObserver(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	rule := ratelimit.Rule{Requests: 1, Window: time.Minute}
+
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, RateLimit(ratelimit.New(), "login", rule, observer))
+
+	for range 3 {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "192.0.2.1:1000"
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		// в режиме наблюдения запросы сверх лимита пропускаются без заголовков ограничения
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get(HeaderRateLimitRemaining))
+		assert.Empty(t, rec.Header().Get(echo.HeaderRetryAfter))
+	}
+}
+
+func TestSeconds(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 1, seconds(0))
+	assert.Equal(t, 1, seconds(100*time.Millisecond))
+	assert.Equal(t, 2, seconds(1500*time.Millisecond))
+	assert.Equal(t, 60, seconds(time.Minute))
+}
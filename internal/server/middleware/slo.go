@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"auth-service/internal/service/slo"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SLO - middleware учета хороших и плохих запросов по классам эндпоинтов. Класс запроса возвращает
+// class после обработки: он становится известен только маршруту. Запросы без класса не учитываются.
+func SLO(tracker *slo.Tracker, class func(c echo.Context) string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			err := next(c)
+
+			if name := class(c); name != "" {
+				tracker.Observe(name, responseStatus(c, err), time.Since(start))
+			}
+
+			return err
+		}
+	}
+}
+
+// responseStatus возвращает код ответа на запрос. Если обработчик вернул ошибку, ответ еще не записан:
+// его запишет обработчик ошибок echo с кодом ошибки.
+func responseStatus(c echo.Context, err error) int {
+	if err == nil || c.Response().Committed {
+		return c.Response().Status
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"auth-service/internal/service/slo"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSLO(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewRegistry()
+
+	tracker, err := slo.New(slo.WithObjective("session", slo.Objective{}), slo.WithRegisterer(registry))
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(SLO(tracker, func(c echo.Context) string {
+		class, _ := c.Get("class").(string)
+		return class
+	}))
+
+	classified := func(h echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("class", "session")
+			return h(c)
+		}
+	}
+
+	e.GET("/ok", classified(func(c echo.Context) error { return c.NoContent(http.StatusOK) }))
+	e.GET("/unavailable", classified(func(c echo.Context) error { return c.NoContent(http.StatusServiceUnavailable) }))
+	e.GET("/http-error", classified(func(echo.Context) error { return echo.NewHTTPError(http.StatusBadGateway) }))
+	e.GET("/not-found", classified(func(echo.Context) error { return echo.ErrNotFound }))
+	e.GET("/error", classified(func(echo.Context) error { return errors.New("boom") }))
+	e.GET("/unclassified", func(echo.Context) error { return errors.New("boom") })
+
+	for _, path := range []string{"/ok", "/unavailable", "/http-error", "/not-found", "/error", "/unclassified", "/unknown"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	expected := `
+# HELP auth_slo_requests_total Количество запросов по классам эндпоинтов, индикаторам (availability, latency) и результату (good, bad).
+# TYPE auth_slo_requests_total counter
+auth_slo_requests_total{class="session",result="bad",sli="availability"} 3
+auth_slo_requests_total{class="session",result="bad",sli="latency"} 0
+auth_slo_requests_total{class="session",result="good",sli="availability"} 2
+auth_slo_requests_total{class="session",result="good",sli="latency"} 2
+`
+
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "auth_slo_requests_total"))
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"auth-service/internal/service/traceid"
+	"auth-service/internal/service/tracing"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing - middleware, которое создает серверный спан на каждый запрос. Контекст трассировки берется
+// из заголовка traceparent, поэтому спан продолжает трассировку вызывающей стороны. Спан передается
+// через контекст запроса: спаны Vault и Redis становятся его дочерними. Если спан записывается,
+// его идентификатор трассировки используется для exemplar метрик (traceid.FromContext).
+func Tracing(provider trace.TracerProvider) echo.MiddlewareFunc {
+	tracer := tracing.Tracer(provider)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			// маршрут известен после поиска обработчика; для неизвестных маршрутов путь не пишется в имя спана
+			route := c.Path()
+
+			name := req.Method
+			if route != "" {
+				name += " " + route
+			}
+
+			ctx := tracing.Propagator.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+			ctx, span := tracer.Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(req.Method),
+					semconv.HTTPRoute(route),
+					semconv.URLPath(req.URL.Path),
+					semconv.ClientAddress(c.RealIP()),
+				),
+			)
+			defer span.End()
+
+			if sc := span.SpanContext(); sc.IsSampled() {
+				ctx = traceid.NewContext(ctx, sc.TraceID().String())
+			}
+
+			c.SetRequest(req.WithContext(ctx))
+
+			err := next(c)
+
+			status := responseStatus(c, err)
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+
+			if err != nil {
+				span.RecordError(err)
+			}
+
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, strconv.Itoa(status))
+			}
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"auth-service/internal/service/traceid"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	var seen string
+
+	e := echo.New()
+	e.Use(Tracing(provider))
+	e.GET("/users/:id", func(c echo.Context) error {
+		seen = traceid.FromContext(c.Request().Context())
+
+		assert.True(t, trace.SpanFromContext(c.Request().Context()).SpanContext().IsValid())
+
+		return c.NoContent(http.StatusOK)
+	})
+	e.GET("/error", func(echo.Context) error { return errors.New("boom") })
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/error", nil))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	// спан продолжает трассировку вызывающей стороны, маршрут - шаблон, а не путь
+	ok := spans[0]
+	assert.Equal(t, "GET /users/:id", ok.Name())
+	assert.Equal(t, trace.SpanKindServer, ok.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", ok.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", ok.Parent().SpanID().String())
+	assert.True(t, ok.Parent().IsRemote())
+	assert.Contains(t, ok.Attributes(), semconv.HTTPRoute("/users/:id"))
+	assert.Contains(t, ok.Attributes(), semconv.HTTPResponseStatusCode(http.StatusOK))
+	assert.Equal(t, codes.Unset, ok.Status().Code)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", seen)
+
+	failed := spans[1]
+	assert.Equal(t, "GET /error", failed.Name())
+	assert.False(t, failed.Parent().IsValid())
+	assert.Contains(t, failed.Attributes(), semconv.HTTPResponseStatusCode(http.StatusInternalServerError))
+	assert.Equal(t, codes.Error, failed.Status().Code)
+	require.Len(t, failed.Events(), 1)
+	assert.Equal(t, "exception", failed.Events()[0].Name)
+}
@@ -34,104 +34,2845 @@ func (m *Mockhandler) EXPECT() *MockhandlerMockRecorder {
 	return m.recorder
 }
 
+// APIKeyUsage mocks base method.
+func (m *Mockhandler) APIKeyUsage(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "APIKeyUsage", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// APIKeyUsage indicates an expected call of APIKeyUsage.
+func (mr *MockhandlerMockRecorder) APIKeyUsage(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "APIKeyUsage", reflect.TypeOf((*Mockhandler)(nil).APIKeyUsage), c)
+}
+
+// AdminLogin mocks base method.
+func (m *Mockhandler) AdminLogin(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminLogin", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdminLogin indicates an expected call of AdminLogin.
+func (mr *MockhandlerMockRecorder) AdminLogin(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminLogin", reflect.TypeOf((*Mockhandler)(nil).AdminLogin), c)
+}
+
+// AuthzCheck mocks base method.
+func (m *Mockhandler) AuthzCheck(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthzCheck", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AuthzCheck indicates an expected call of AuthzCheck.
+func (mr *MockhandlerMockRecorder) AuthzCheck(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthzCheck", reflect.TypeOf((*Mockhandler)(nil).AuthzCheck), c)
+}
+
+// BeginPasskeyLogin mocks base method.
+func (m *Mockhandler) BeginPasskeyLogin(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginPasskeyLogin", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BeginPasskeyLogin indicates an expected call of BeginPasskeyLogin.
+func (mr *MockhandlerMockRecorder) BeginPasskeyLogin(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginPasskeyLogin", reflect.TypeOf((*Mockhandler)(nil).BeginPasskeyLogin), c)
+}
+
+// BeginPasskeyRegistration mocks base method.
+func (m *Mockhandler) BeginPasskeyRegistration(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginPasskeyRegistration", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BeginPasskeyRegistration indicates an expected call of BeginPasskeyRegistration.
+func (mr *MockhandlerMockRecorder) BeginPasskeyRegistration(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginPasskeyRegistration", reflect.TypeOf((*Mockhandler)(nil).BeginPasskeyRegistration), c)
+}
+
+// CancelEmailChange mocks base method.
+func (m *Mockhandler) CancelEmailChange(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelEmailChange", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelEmailChange indicates an expected call of CancelEmailChange.
+func (mr *MockhandlerMockRecorder) CancelEmailChange(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelEmailChange", reflect.TypeOf((*Mockhandler)(nil).CancelEmailChange), c)
+}
+
+// CheckCredentials mocks base method.
+func (m *Mockhandler) CheckCredentials(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckCredentials", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckCredentials indicates an expected call of CheckCredentials.
+func (mr *MockhandlerMockRecorder) CheckCredentials(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckCredentials", reflect.TypeOf((*Mockhandler)(nil).CheckCredentials), c)
+}
+
+// CheckDeactivations mocks base method.
+func (m *Mockhandler) CheckDeactivations(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckDeactivations", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckDeactivations indicates an expected call of CheckDeactivations.
+func (mr *MockhandlerMockRecorder) CheckDeactivations(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckDeactivations", reflect.TypeOf((*Mockhandler)(nil).CheckDeactivations), c)
+}
+
+// CheckGroupAccess mocks base method.
+func (m *Mockhandler) CheckGroupAccess(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckGroupAccess", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckGroupAccess indicates an expected call of CheckGroupAccess.
+func (mr *MockhandlerMockRecorder) CheckGroupAccess(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckGroupAccess", reflect.TypeOf((*Mockhandler)(nil).CheckGroupAccess), c)
+}
+
+// ClaimQRLogin mocks base method.
+func (m *Mockhandler) ClaimQRLogin(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimQRLogin", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimQRLogin indicates an expected call of ClaimQRLogin.
+func (mr *MockhandlerMockRecorder) ClaimQRLogin(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimQRLogin", reflect.TypeOf((*Mockhandler)(nil).ClaimQRLogin), c)
+}
+
+// ClearCapture mocks base method.
+func (m *Mockhandler) ClearCapture(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearCapture", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearCapture indicates an expected call of ClearCapture.
+func (mr *MockhandlerMockRecorder) ClearCapture(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearCapture", reflect.TypeOf((*Mockhandler)(nil).ClearCapture), c)
+}
+
+// ConfirmEmailChange mocks base method.
+func (m *Mockhandler) ConfirmEmailChange(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEmailChange", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmEmailChange indicates an expected call of ConfirmEmailChange.
+func (mr *MockhandlerMockRecorder) ConfirmEmailChange(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEmailChange", reflect.TypeOf((*Mockhandler)(nil).ConfirmEmailChange), c)
+}
+
+// ConfirmQRLogin mocks base method.
+func (m *Mockhandler) ConfirmQRLogin(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmQRLogin", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmQRLogin indicates an expected call of ConfirmQRLogin.
+func (mr *MockhandlerMockRecorder) ConfirmQRLogin(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmQRLogin", reflect.TypeOf((*Mockhandler)(nil).ConfirmQRLogin), c)
+}
+
+// CreateAPIKey mocks base method.
+func (m *Mockhandler) CreateAPIKey(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockhandlerMockRecorder) CreateAPIKey(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*Mockhandler)(nil).CreateAPIKey), c)
+}
+
+// CreateBan mocks base method.
+func (m *Mockhandler) CreateBan(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBan", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBan indicates an expected call of CreateBan.
+func (mr *MockhandlerMockRecorder) CreateBan(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBan", reflect.TypeOf((*Mockhandler)(nil).CreateBan), c)
+}
+
+// CreateGroup mocks base method.
+func (m *Mockhandler) CreateGroup(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGroup", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateGroup indicates an expected call of CreateGroup.
+func (mr *MockhandlerMockRecorder) CreateGroup(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroup", reflect.TypeOf((*Mockhandler)(nil).CreateGroup), c)
+}
+
+// CreateSCIMUser mocks base method.
+func (m *Mockhandler) CreateSCIMUser(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSCIMUser", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSCIMUser indicates an expected call of CreateSCIMUser.
+func (mr *MockhandlerMockRecorder) CreateSCIMUser(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSCIMUser", reflect.TypeOf((*Mockhandler)(nil).CreateSCIMUser), c)
+}
+
+// DeactivateUser mocks base method.
+func (m *Mockhandler) DeactivateUser(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateUser", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeactivateUser indicates an expected call of DeactivateUser.
+func (mr *MockhandlerMockRecorder) DeactivateUser(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateUser", reflect.TypeOf((*Mockhandler)(nil).DeactivateUser), c)
+}
+
+// DeleteBan mocks base method.
+func (m *Mockhandler) DeleteBan(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBan", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBan indicates an expected call of DeleteBan.
+func (mr *MockhandlerMockRecorder) DeleteBan(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBan", reflect.TypeOf((*Mockhandler)(nil).DeleteBan), c)
+}
+
+// DeleteGroup mocks base method.
+func (m *Mockhandler) DeleteGroup(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGroup", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGroup indicates an expected call of DeleteGroup.
+func (mr *MockhandlerMockRecorder) DeleteGroup(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGroup", reflect.TypeOf((*Mockhandler)(nil).DeleteGroup), c)
+}
+
+// DeleteSCIMUser mocks base method.
+func (m *Mockhandler) DeleteSCIMUser(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSCIMUser", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSCIMUser indicates an expected call of DeleteSCIMUser.
+func (mr *MockhandlerMockRecorder) DeleteSCIMUser(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSCIMUser", reflect.TypeOf((*Mockhandler)(nil).DeleteSCIMUser), c)
+}
+
+// DisableReadOnly mocks base method.
+func (m *Mockhandler) DisableReadOnly(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableReadOnly", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableReadOnly indicates an expected call of DisableReadOnly.
+func (mr *MockhandlerMockRecorder) DisableReadOnly(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableReadOnly", reflect.TypeOf((*Mockhandler)(nil).DisableReadOnly), c)
+}
+
+// Drain mocks base method.
+func (m *Mockhandler) Drain(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Drain", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Drain indicates an expected call of Drain.
+func (mr *MockhandlerMockRecorder) Drain(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Drain", reflect.TypeOf((*Mockhandler)(nil).Drain), c)
+}
+
+// EnableReadOnly mocks base method.
+func (m *Mockhandler) EnableReadOnly(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableReadOnly", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableReadOnly indicates an expected call of EnableReadOnly.
+func (mr *MockhandlerMockRecorder) EnableReadOnly(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableReadOnly", reflect.TypeOf((*Mockhandler)(nil).EnableReadOnly), c)
+}
+
+// ExportVerificationBundle mocks base method.
+func (m *Mockhandler) ExportVerificationBundle(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportVerificationBundle", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportVerificationBundle indicates an expected call of ExportVerificationBundle.
+func (mr *MockhandlerMockRecorder) ExportVerificationBundle(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportVerificationBundle", reflect.TypeOf((*Mockhandler)(nil).ExportVerificationBundle), c)
+}
+
+// FinishPasskeyLogin mocks base method.
+func (m *Mockhandler) FinishPasskeyLogin(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishPasskeyLogin", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishPasskeyLogin indicates an expected call of FinishPasskeyLogin.
+func (mr *MockhandlerMockRecorder) FinishPasskeyLogin(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishPasskeyLogin", reflect.TypeOf((*Mockhandler)(nil).FinishPasskeyLogin), c)
+}
+
+// FinishPasskeyRegistration mocks base method.
+func (m *Mockhandler) FinishPasskeyRegistration(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishPasskeyRegistration", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishPasskeyRegistration indicates an expected call of FinishPasskeyRegistration.
+func (mr *MockhandlerMockRecorder) FinishPasskeyRegistration(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishPasskeyRegistration", reflect.TypeOf((*Mockhandler)(nil).FinishPasskeyRegistration), c)
+}
+
+// GetAPIKeyRateLimit mocks base method.
+func (m *Mockhandler) GetAPIKeyRateLimit(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyRateLimit", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetAPIKeyRateLimit indicates an expected call of GetAPIKeyRateLimit.
+func (mr *MockhandlerMockRecorder) GetAPIKeyRateLimit(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyRateLimit", reflect.TypeOf((*Mockhandler)(nil).GetAPIKeyRateLimit), c)
+}
+
+// GetCapture mocks base method.
+func (m *Mockhandler) GetCapture(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCapture", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetCapture indicates an expected call of GetCapture.
+func (mr *MockhandlerMockRecorder) GetCapture(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCapture", reflect.TypeOf((*Mockhandler)(nil).GetCapture), c)
+}
+
+// GetEmailChange mocks base method.
+func (m *Mockhandler) GetEmailChange(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailChange", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetEmailChange indicates an expected call of GetEmailChange.
+func (mr *MockhandlerMockRecorder) GetEmailChange(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailChange", reflect.TypeOf((*Mockhandler)(nil).GetEmailChange), c)
+}
+
+// GetGroup mocks base method.
+func (m *Mockhandler) GetGroup(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroup", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetGroup indicates an expected call of GetGroup.
+func (mr *MockhandlerMockRecorder) GetGroup(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroup", reflect.TypeOf((*Mockhandler)(nil).GetGroup), c)
+}
+
+// GetJob mocks base method.
+func (m *Mockhandler) GetJob(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockhandlerMockRecorder) GetJob(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*Mockhandler)(nil).GetJob), c)
+}
+
+// GetLogSampling mocks base method.
+func (m *Mockhandler) GetLogSampling(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLogSampling", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetLogSampling indicates an expected call of GetLogSampling.
+func (mr *MockhandlerMockRecorder) GetLogSampling(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLogSampling", reflect.TypeOf((*Mockhandler)(nil).GetLogSampling), c)
+}
+
+// GetNotificationPreferences mocks base method.
+func (m *Mockhandler) GetNotificationPreferences(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationPreferences", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetNotificationPreferences indicates an expected call of GetNotificationPreferences.
+func (mr *MockhandlerMockRecorder) GetNotificationPreferences(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationPreferences", reflect.TypeOf((*Mockhandler)(nil).GetNotificationPreferences), c)
+}
+
+// GetReadOnly mocks base method.
+func (m *Mockhandler) GetReadOnly(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReadOnly", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetReadOnly indicates an expected call of GetReadOnly.
+func (mr *MockhandlerMockRecorder) GetReadOnly(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReadOnly", reflect.TypeOf((*Mockhandler)(nil).GetReadOnly), c)
+}
+
+// GetRefreshFamily mocks base method.
+func (m *Mockhandler) GetRefreshFamily(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshFamily", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetRefreshFamily indicates an expected call of GetRefreshFamily.
+func (mr *MockhandlerMockRecorder) GetRefreshFamily(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshFamily", reflect.TypeOf((*Mockhandler)(nil).GetRefreshFamily), c)
+}
+
+// GetSCIMUser mocks base method.
+func (m *Mockhandler) GetSCIMUser(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSCIMUser", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetSCIMUser indicates an expected call of GetSCIMUser.
+func (mr *MockhandlerMockRecorder) GetSCIMUser(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSCIMUser", reflect.TypeOf((*Mockhandler)(nil).GetSCIMUser), c)
+}
+
+// Health mocks base method.
+func (m *Mockhandler) Health(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Health", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Health indicates an expected call of Health.
+func (mr *MockhandlerMockRecorder) Health(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Health", reflect.TypeOf((*Mockhandler)(nil).Health), c)
+}
+
+// HealthDetails mocks base method.
+func (m *Mockhandler) HealthDetails(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HealthDetails", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HealthDetails indicates an expected call of HealthDetails.
+func (mr *MockhandlerMockRecorder) HealthDetails(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HealthDetails", reflect.TypeOf((*Mockhandler)(nil).HealthDetails), c)
+}
+
+// Impersonate mocks base method.
+func (m *Mockhandler) Impersonate(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Impersonate", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Impersonate indicates an expected call of Impersonate.
+func (mr *MockhandlerMockRecorder) Impersonate(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Impersonate", reflect.TypeOf((*Mockhandler)(nil).Impersonate), c)
+}
+
+// Introspect mocks base method.
+func (m *Mockhandler) Introspect(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Introspect", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Introspect indicates an expected call of Introspect.
+func (mr *MockhandlerMockRecorder) Introspect(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Introspect", reflect.TypeOf((*Mockhandler)(nil).Introspect), c)
+}
+
+// IssueGuestToken mocks base method.
+func (m *Mockhandler) IssueGuestToken(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueGuestToken", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IssueGuestToken indicates an expected call of IssueGuestToken.
+func (mr *MockhandlerMockRecorder) IssueGuestToken(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueGuestToken", reflect.TypeOf((*Mockhandler)(nil).IssueGuestToken), c)
+}
+
+// IssueJWTSVID mocks base method.
+func (m *Mockhandler) IssueJWTSVID(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueJWTSVID", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IssueJWTSVID indicates an expected call of IssueJWTSVID.
+func (mr *MockhandlerMockRecorder) IssueJWTSVID(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueJWTSVID", reflect.TypeOf((*Mockhandler)(nil).IssueJWTSVID), c)
+}
+
+// IssueX509SVID mocks base method.
+func (m *Mockhandler) IssueX509SVID(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueX509SVID", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IssueX509SVID indicates an expected call of IssueX509SVID.
+func (mr *MockhandlerMockRecorder) IssueX509SVID(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueX509SVID", reflect.TypeOf((*Mockhandler)(nil).IssueX509SVID), c)
+}
+
+// KeyUsage mocks base method.
+func (m *Mockhandler) KeyUsage(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyUsage", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// KeyUsage indicates an expected call of KeyUsage.
+func (mr *MockhandlerMockRecorder) KeyUsage(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyUsage", reflect.TypeOf((*Mockhandler)(nil).KeyUsage), c)
+}
+
+// ListBans mocks base method.
+func (m *Mockhandler) ListBans(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBans", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListBans indicates an expected call of ListBans.
+func (mr *MockhandlerMockRecorder) ListBans(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBans", reflect.TypeOf((*Mockhandler)(nil).ListBans), c)
+}
+
+// ListSCIMUsers mocks base method.
+func (m *Mockhandler) ListSCIMUsers(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSCIMUsers", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListSCIMUsers indicates an expected call of ListSCIMUsers.
+func (mr *MockhandlerMockRecorder) ListSCIMUsers(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSCIMUsers", reflect.TypeOf((*Mockhandler)(nil).ListSCIMUsers), c)
+}
+
+// ListSessions mocks base method.
+func (m *Mockhandler) ListSessions(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockhandlerMockRecorder) ListSessions(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*Mockhandler)(nil).ListSessions), c)
+}
+
+// Liveness mocks base method.
+func (m *Mockhandler) Liveness(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Liveness", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Liveness indicates an expected call of Liveness.
+func (mr *MockhandlerMockRecorder) Liveness(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Liveness", reflect.TypeOf((*Mockhandler)(nil).Liveness), c)
+}
+
+// Logout mocks base method.
+func (m *Mockhandler) Logout(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockhandlerMockRecorder) Logout(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*Mockhandler)(nil).Logout), c)
+}
+
+// LogoutAll mocks base method.
+func (m *Mockhandler) LogoutAll(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogoutAll", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogoutAll indicates an expected call of LogoutAll.
+func (mr *MockhandlerMockRecorder) LogoutAll(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoutAll", reflect.TypeOf((*Mockhandler)(nil).LogoutAll), c)
+}
+
+// OAuthCallback mocks base method.
+func (m *Mockhandler) OAuthCallback(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OAuthCallback", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// OAuthCallback indicates an expected call of OAuthCallback.
+func (mr *MockhandlerMockRecorder) OAuthCallback(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OAuthCallback", reflect.TypeOf((*Mockhandler)(nil).OAuthCallback), c)
+}
+
+// PatchSCIMUser mocks base method.
+func (m *Mockhandler) PatchSCIMUser(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchSCIMUser", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchSCIMUser indicates an expected call of PatchSCIMUser.
+func (mr *MockhandlerMockRecorder) PatchSCIMUser(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchSCIMUser", reflect.TypeOf((*Mockhandler)(nil).PatchSCIMUser), c)
+}
+
+// ReactivateUser mocks base method.
+func (m *Mockhandler) ReactivateUser(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReactivateUser", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReactivateUser indicates an expected call of ReactivateUser.
+func (mr *MockhandlerMockRecorder) ReactivateUser(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReactivateUser", reflect.TypeOf((*Mockhandler)(nil).ReactivateUser), c)
+}
+
+// Readiness mocks base method.
+func (m *Mockhandler) Readiness(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Readiness", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Readiness indicates an expected call of Readiness.
+func (mr *MockhandlerMockRecorder) Readiness(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Readiness", reflect.TypeOf((*Mockhandler)(nil).Readiness), c)
+}
+
+// Ready mocks base method.
+func (m *Mockhandler) Ready(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ready", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ready indicates an expected call of Ready.
+func (mr *MockhandlerMockRecorder) Ready(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ready", reflect.TypeOf((*Mockhandler)(nil).Ready), c)
+}
+
+// RefreshToken mocks base method.
+func (m *Mockhandler) RefreshToken(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshToken", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshToken indicates an expected call of RefreshToken.
+func (mr *MockhandlerMockRecorder) RefreshToken(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*Mockhandler)(nil).RefreshToken), c)
+}
+
+// RemoveGroupMember mocks base method.
+func (m *Mockhandler) RemoveGroupMember(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveGroupMember", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveGroupMember indicates an expected call of RemoveGroupMember.
+func (mr *MockhandlerMockRecorder) RemoveGroupMember(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveGroupMember", reflect.TypeOf((*Mockhandler)(nil).RemoveGroupMember), c)
+}
+
+// ReplaceSCIMUser mocks base method.
+func (m *Mockhandler) ReplaceSCIMUser(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceSCIMUser", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceSCIMUser indicates an expected call of ReplaceSCIMUser.
+func (mr *MockhandlerMockRecorder) ReplaceSCIMUser(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceSCIMUser", reflect.TypeOf((*Mockhandler)(nil).ReplaceSCIMUser), c)
+}
+
+// ResetAPIKeyRateLimit mocks base method.
+func (m *Mockhandler) ResetAPIKeyRateLimit(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetAPIKeyRateLimit", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetAPIKeyRateLimit indicates an expected call of ResetAPIKeyRateLimit.
+func (mr *MockhandlerMockRecorder) ResetAPIKeyRateLimit(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetAPIKeyRateLimit", reflect.TypeOf((*Mockhandler)(nil).ResetAPIKeyRateLimit), c)
+}
+
+// ResetLogSampling mocks base method.
+func (m *Mockhandler) ResetLogSampling(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetLogSampling", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetLogSampling indicates an expected call of ResetLogSampling.
+func (mr *MockhandlerMockRecorder) ResetLogSampling(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetLogSampling", reflect.TypeOf((*Mockhandler)(nil).ResetLogSampling), c)
+}
+
+// RevokeRefreshFamily mocks base method.
+func (m *Mockhandler) RevokeRefreshFamily(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshFamily", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshFamily indicates an expected call of RevokeRefreshFamily.
+func (mr *MockhandlerMockRecorder) RevokeRefreshFamily(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshFamily", reflect.TypeOf((*Mockhandler)(nil).RevokeRefreshFamily), c)
+}
+
+// RevokeSession mocks base method.
+func (m *Mockhandler) RevokeSession(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockhandlerMockRecorder) RevokeSession(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*Mockhandler)(nil).RevokeSession), c)
+}
+
+// RevokeToken mocks base method.
+func (m *Mockhandler) RevokeToken(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeToken", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeToken indicates an expected call of RevokeToken.
+func (mr *MockhandlerMockRecorder) RevokeToken(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*Mockhandler)(nil).RevokeToken), c)
+}
+
+// RevokeUserSessions mocks base method.
+func (m *Mockhandler) RevokeUserSessions(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserSessions", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserSessions indicates an expected call of RevokeUserSessions.
+func (mr *MockhandlerMockRecorder) RevokeUserSessions(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserSessions", reflect.TypeOf((*Mockhandler)(nil).RevokeUserSessions), c)
+}
+
+// RunCanary mocks base method.
+func (m *Mockhandler) RunCanary(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunCanary", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunCanary indicates an expected call of RunCanary.
+func (mr *MockhandlerMockRecorder) RunCanary(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunCanary", reflect.TypeOf((*Mockhandler)(nil).RunCanary), c)
+}
+
+// SVIDBundle mocks base method.
+func (m *Mockhandler) SVIDBundle(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SVIDBundle", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SVIDBundle indicates an expected call of SVIDBundle.
+func (mr *MockhandlerMockRecorder) SVIDBundle(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SVIDBundle", reflect.TypeOf((*Mockhandler)(nil).SVIDBundle), c)
+}
+
+// SendNotification mocks base method.
+func (m *Mockhandler) SendNotification(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendNotification", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendNotification indicates an expected call of SendNotification.
+func (mr *MockhandlerMockRecorder) SendNotification(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendNotification", reflect.TypeOf((*Mockhandler)(nil).SendNotification), c)
+}
+
+// SetGroupMember mocks base method.
+func (m *Mockhandler) SetGroupMember(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetGroupMember", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetGroupMember indicates an expected call of SetGroupMember.
+func (mr *MockhandlerMockRecorder) SetGroupMember(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetGroupMember", reflect.TypeOf((*Mockhandler)(nil).SetGroupMember), c)
+}
+
+// StartEmailChange mocks base method.
+func (m *Mockhandler) StartEmailChange(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartEmailChange", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartEmailChange indicates an expected call of StartEmailChange.
+func (mr *MockhandlerMockRecorder) StartEmailChange(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartEmailChange", reflect.TypeOf((*Mockhandler)(nil).StartEmailChange), c)
+}
+
+// StartOAuth mocks base method.
+func (m *Mockhandler) StartOAuth(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartOAuth", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartOAuth indicates an expected call of StartOAuth.
+func (mr *MockhandlerMockRecorder) StartOAuth(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartOAuth", reflect.TypeOf((*Mockhandler)(nil).StartOAuth), c)
+}
+
+// StartQRLogin mocks base method.
+func (m *Mockhandler) StartQRLogin(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartQRLogin", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartQRLogin indicates an expected call of StartQRLogin.
+func (mr *MockhandlerMockRecorder) StartQRLogin(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartQRLogin", reflect.TypeOf((*Mockhandler)(nil).StartQRLogin), c)
+}
+
+// Stats mocks base method.
+func (m *Mockhandler) Stats(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockhandlerMockRecorder) Stats(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*Mockhandler)(nil).Stats), c)
+}
+
+// TelegramLogin mocks base method.
+func (m *Mockhandler) TelegramLogin(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TelegramLogin", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TelegramLogin indicates an expected call of TelegramLogin.
+func (mr *MockhandlerMockRecorder) TelegramLogin(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TelegramLogin", reflect.TypeOf((*Mockhandler)(nil).TelegramLogin), c)
+}
+
+// UpdateAPIKeyRateLimit mocks base method.
+func (m *Mockhandler) UpdateAPIKeyRateLimit(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAPIKeyRateLimit", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAPIKeyRateLimit indicates an expected call of UpdateAPIKeyRateLimit.
+func (mr *MockhandlerMockRecorder) UpdateAPIKeyRateLimit(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAPIKeyRateLimit", reflect.TypeOf((*Mockhandler)(nil).UpdateAPIKeyRateLimit), c)
+}
+
+// UpdateCapture mocks base method.
+func (m *Mockhandler) UpdateCapture(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCapture", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCapture indicates an expected call of UpdateCapture.
+func (mr *MockhandlerMockRecorder) UpdateCapture(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCapture", reflect.TypeOf((*Mockhandler)(nil).UpdateCapture), c)
+}
+
+// UpdateLogSampling mocks base method.
+func (m *Mockhandler) UpdateLogSampling(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLogSampling", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLogSampling indicates an expected call of UpdateLogSampling.
+func (mr *MockhandlerMockRecorder) UpdateLogSampling(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLogSampling", reflect.TypeOf((*Mockhandler)(nil).UpdateLogSampling), c)
+}
+
+// UpdateNotificationPreferences mocks base method.
+func (m *Mockhandler) UpdateNotificationPreferences(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNotificationPreferences", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateNotificationPreferences indicates an expected call of UpdateNotificationPreferences.
+func (mr *MockhandlerMockRecorder) UpdateNotificationPreferences(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotificationPreferences", reflect.TypeOf((*Mockhandler)(nil).UpdateNotificationPreferences), c)
+}
+
+// UpgradeGuestToken mocks base method.
+func (m *Mockhandler) UpgradeGuestToken(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpgradeGuestToken", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpgradeGuestToken indicates an expected call of UpgradeGuestToken.
+func (mr *MockhandlerMockRecorder) UpgradeGuestToken(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpgradeGuestToken", reflect.TypeOf((*Mockhandler)(nil).UpgradeGuestToken), c)
+}
+
+// UserGroups mocks base method.
+func (m *Mockhandler) UserGroups(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserGroups", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UserGroups indicates an expected call of UserGroups.
+func (mr *MockhandlerMockRecorder) UserGroups(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserGroups", reflect.TypeOf((*Mockhandler)(nil).UserGroups), c)
+}
+
+// Version mocks base method.
+func (m *Mockhandler) Version() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Version")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Version indicates an expected call of Version.
+func (mr *MockhandlerMockRecorder) Version() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Version", reflect.TypeOf((*Mockhandler)(nil).Version))
+}
+
+// MockversionHandler is a mock of versionHandler interface.
+type MockversionHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockversionHandlerMockRecorder
+}
+
+// MockversionHandlerMockRecorder is the mock recorder for MockversionHandler.
+type MockversionHandlerMockRecorder struct {
+	mock *MockversionHandler
+}
+
+// NewMockversionHandler creates a new mock instance.
+func NewMockversionHandler(ctrl *gomock.Controller) *MockversionHandler {
+	mock := &MockversionHandler{ctrl: ctrl}
+	mock.recorder = &MockversionHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockversionHandler) EXPECT() *MockversionHandlerMockRecorder {
+	return m.recorder
+}
+
+// Version mocks base method.
+func (m *MockversionHandler) Version() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Version")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Version indicates an expected call of Version.
+func (mr *MockversionHandlerMockRecorder) Version() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Version", reflect.TypeOf((*MockversionHandler)(nil).Version))
+}
+
+// MockhealthHandler is a mock of healthHandler interface.
+type MockhealthHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockhealthHandlerMockRecorder
+}
+
+// MockhealthHandlerMockRecorder is the mock recorder for MockhealthHandler.
+type MockhealthHandlerMockRecorder struct {
+	mock *MockhealthHandler
+}
+
+// NewMockhealthHandler creates a new mock instance.
+func NewMockhealthHandler(ctrl *gomock.Controller) *MockhealthHandler {
+	mock := &MockhealthHandler{ctrl: ctrl}
+	mock.recorder = &MockhealthHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockhealthHandler) EXPECT() *MockhealthHandlerMockRecorder {
+	return m.recorder
+}
+
 // Health mocks base method.
-func (m *Mockhandler) Health(c echo.Context) error {
+func (m *MockhealthHandler) Health(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Health", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Health indicates an expected call of Health.
+func (mr *MockhealthHandlerMockRecorder) Health(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Health", reflect.TypeOf((*MockhealthHandler)(nil).Health), c)
+}
+
+// HealthDetails mocks base method.
+func (m *MockhealthHandler) HealthDetails(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HealthDetails", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HealthDetails indicates an expected call of HealthDetails.
+func (mr *MockhealthHandlerMockRecorder) HealthDetails(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HealthDetails", reflect.TypeOf((*MockhealthHandler)(nil).HealthDetails), c)
+}
+
+// Liveness mocks base method.
+func (m *MockhealthHandler) Liveness(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Liveness", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Liveness indicates an expected call of Liveness.
+func (mr *MockhealthHandlerMockRecorder) Liveness(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Liveness", reflect.TypeOf((*MockhealthHandler)(nil).Liveness), c)
+}
+
+// Readiness mocks base method.
+func (m *MockhealthHandler) Readiness(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Readiness", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Readiness indicates an expected call of Readiness.
+func (mr *MockhealthHandlerMockRecorder) Readiness(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Readiness", reflect.TypeOf((*MockhealthHandler)(nil).Readiness), c)
+}
+
+// MockdrainHandler is a mock of drainHandler interface.
+type MockdrainHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockdrainHandlerMockRecorder
+}
+
+// MockdrainHandlerMockRecorder is the mock recorder for MockdrainHandler.
+type MockdrainHandlerMockRecorder struct {
+	mock *MockdrainHandler
+}
+
+// NewMockdrainHandler creates a new mock instance.
+func NewMockdrainHandler(ctrl *gomock.Controller) *MockdrainHandler {
+	mock := &MockdrainHandler{ctrl: ctrl}
+	mock.recorder = &MockdrainHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockdrainHandler) EXPECT() *MockdrainHandlerMockRecorder {
+	return m.recorder
+}
+
+// Drain mocks base method.
+func (m *MockdrainHandler) Drain(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Drain", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Drain indicates an expected call of Drain.
+func (mr *MockdrainHandlerMockRecorder) Drain(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Drain", reflect.TypeOf((*MockdrainHandler)(nil).Drain), c)
+}
+
+// Ready mocks base method.
+func (m *MockdrainHandler) Ready(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ready", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ready indicates an expected call of Ready.
+func (mr *MockdrainHandlerMockRecorder) Ready(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ready", reflect.TypeOf((*MockdrainHandler)(nil).Ready), c)
+}
+
+// RunCanary mocks base method.
+func (m *MockdrainHandler) RunCanary(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunCanary", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunCanary indicates an expected call of RunCanary.
+func (mr *MockdrainHandlerMockRecorder) RunCanary(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunCanary", reflect.TypeOf((*MockdrainHandler)(nil).RunCanary), c)
+}
+
+// MockreadOnlyHandler is a mock of readOnlyHandler interface.
+type MockreadOnlyHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockreadOnlyHandlerMockRecorder
+}
+
+// MockreadOnlyHandlerMockRecorder is the mock recorder for MockreadOnlyHandler.
+type MockreadOnlyHandlerMockRecorder struct {
+	mock *MockreadOnlyHandler
+}
+
+// NewMockreadOnlyHandler creates a new mock instance.
+func NewMockreadOnlyHandler(ctrl *gomock.Controller) *MockreadOnlyHandler {
+	mock := &MockreadOnlyHandler{ctrl: ctrl}
+	mock.recorder = &MockreadOnlyHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockreadOnlyHandler) EXPECT() *MockreadOnlyHandlerMockRecorder {
+	return m.recorder
+}
+
+// DisableReadOnly mocks base method.
+func (m *MockreadOnlyHandler) DisableReadOnly(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableReadOnly", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableReadOnly indicates an expected call of DisableReadOnly.
+func (mr *MockreadOnlyHandlerMockRecorder) DisableReadOnly(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableReadOnly", reflect.TypeOf((*MockreadOnlyHandler)(nil).DisableReadOnly), c)
+}
+
+// EnableReadOnly mocks base method.
+func (m *MockreadOnlyHandler) EnableReadOnly(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableReadOnly", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableReadOnly indicates an expected call of EnableReadOnly.
+func (mr *MockreadOnlyHandlerMockRecorder) EnableReadOnly(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableReadOnly", reflect.TypeOf((*MockreadOnlyHandler)(nil).EnableReadOnly), c)
+}
+
+// GetReadOnly mocks base method.
+func (m *MockreadOnlyHandler) GetReadOnly(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReadOnly", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetReadOnly indicates an expected call of GetReadOnly.
+func (mr *MockreadOnlyHandlerMockRecorder) GetReadOnly(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReadOnly", reflect.TypeOf((*MockreadOnlyHandler)(nil).GetReadOnly), c)
+}
+
+// MockkeyStatsHandler is a mock of keyStatsHandler interface.
+type MockkeyStatsHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockkeyStatsHandlerMockRecorder
+}
+
+// MockkeyStatsHandlerMockRecorder is the mock recorder for MockkeyStatsHandler.
+type MockkeyStatsHandlerMockRecorder struct {
+	mock *MockkeyStatsHandler
+}
+
+// NewMockkeyStatsHandler creates a new mock instance.
+func NewMockkeyStatsHandler(ctrl *gomock.Controller) *MockkeyStatsHandler {
+	mock := &MockkeyStatsHandler{ctrl: ctrl}
+	mock.recorder = &MockkeyStatsHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockkeyStatsHandler) EXPECT() *MockkeyStatsHandlerMockRecorder {
+	return m.recorder
+}
+
+// KeyUsage mocks base method.
+func (m *MockkeyStatsHandler) KeyUsage(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyUsage", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// KeyUsage indicates an expected call of KeyUsage.
+func (mr *MockkeyStatsHandlerMockRecorder) KeyUsage(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyUsage", reflect.TypeOf((*MockkeyStatsHandler)(nil).KeyUsage), c)
+}
+
+// MockstatsHandler is a mock of statsHandler interface.
+type MockstatsHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockstatsHandlerMockRecorder
+}
+
+// MockstatsHandlerMockRecorder is the mock recorder for MockstatsHandler.
+type MockstatsHandlerMockRecorder struct {
+	mock *MockstatsHandler
+}
+
+// NewMockstatsHandler creates a new mock instance.
+func NewMockstatsHandler(ctrl *gomock.Controller) *MockstatsHandler {
+	mock := &MockstatsHandler{ctrl: ctrl}
+	mock.recorder = &MockstatsHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockstatsHandler) EXPECT() *MockstatsHandlerMockRecorder {
+	return m.recorder
+}
+
+// Stats mocks base method.
+func (m *MockstatsHandler) Stats(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockstatsHandlerMockRecorder) Stats(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockstatsHandler)(nil).Stats), c)
+}
+
+// MocktokenHandler is a mock of tokenHandler interface.
+type MocktokenHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MocktokenHandlerMockRecorder
+}
+
+// MocktokenHandlerMockRecorder is the mock recorder for MocktokenHandler.
+type MocktokenHandlerMockRecorder struct {
+	mock *MocktokenHandler
+}
+
+// NewMocktokenHandler creates a new mock instance.
+func NewMocktokenHandler(ctrl *gomock.Controller) *MocktokenHandler {
+	mock := &MocktokenHandler{ctrl: ctrl}
+	mock.recorder = &MocktokenHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktokenHandler) EXPECT() *MocktokenHandlerMockRecorder {
+	return m.recorder
+}
+
+// AuthzCheck mocks base method.
+func (m *MocktokenHandler) AuthzCheck(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthzCheck", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AuthzCheck indicates an expected call of AuthzCheck.
+func (mr *MocktokenHandlerMockRecorder) AuthzCheck(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthzCheck", reflect.TypeOf((*MocktokenHandler)(nil).AuthzCheck), c)
+}
+
+// ExportVerificationBundle mocks base method.
+func (m *MocktokenHandler) ExportVerificationBundle(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportVerificationBundle", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportVerificationBundle indicates an expected call of ExportVerificationBundle.
+func (mr *MocktokenHandlerMockRecorder) ExportVerificationBundle(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportVerificationBundle", reflect.TypeOf((*MocktokenHandler)(nil).ExportVerificationBundle), c)
+}
+
+// GetRefreshFamily mocks base method.
+func (m *MocktokenHandler) GetRefreshFamily(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshFamily", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetRefreshFamily indicates an expected call of GetRefreshFamily.
+func (mr *MocktokenHandlerMockRecorder) GetRefreshFamily(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshFamily", reflect.TypeOf((*MocktokenHandler)(nil).GetRefreshFamily), c)
+}
+
+// Impersonate mocks base method.
+func (m *MocktokenHandler) Impersonate(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Impersonate", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Impersonate indicates an expected call of Impersonate.
+func (mr *MocktokenHandlerMockRecorder) Impersonate(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Impersonate", reflect.TypeOf((*MocktokenHandler)(nil).Impersonate), c)
+}
+
+// Introspect mocks base method.
+func (m *MocktokenHandler) Introspect(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Introspect", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Introspect indicates an expected call of Introspect.
+func (mr *MocktokenHandlerMockRecorder) Introspect(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Introspect", reflect.TypeOf((*MocktokenHandler)(nil).Introspect), c)
+}
+
+// IssueGuestToken mocks base method.
+func (m *MocktokenHandler) IssueGuestToken(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueGuestToken", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IssueGuestToken indicates an expected call of IssueGuestToken.
+func (mr *MocktokenHandlerMockRecorder) IssueGuestToken(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueGuestToken", reflect.TypeOf((*MocktokenHandler)(nil).IssueGuestToken), c)
+}
+
+// RefreshToken mocks base method.
+func (m *MocktokenHandler) RefreshToken(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshToken", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshToken indicates an expected call of RefreshToken.
+func (mr *MocktokenHandlerMockRecorder) RefreshToken(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MocktokenHandler)(nil).RefreshToken), c)
+}
+
+// RevokeRefreshFamily mocks base method.
+func (m *MocktokenHandler) RevokeRefreshFamily(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshFamily", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshFamily indicates an expected call of RevokeRefreshFamily.
+func (mr *MocktokenHandlerMockRecorder) RevokeRefreshFamily(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshFamily", reflect.TypeOf((*MocktokenHandler)(nil).RevokeRefreshFamily), c)
+}
+
+// RevokeToken mocks base method.
+func (m *MocktokenHandler) RevokeToken(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeToken", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeToken indicates an expected call of RevokeToken.
+func (mr *MocktokenHandlerMockRecorder) RevokeToken(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MocktokenHandler)(nil).RevokeToken), c)
+}
+
+// UpgradeGuestToken mocks base method.
+func (m *MocktokenHandler) UpgradeGuestToken(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpgradeGuestToken", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpgradeGuestToken indicates an expected call of UpgradeGuestToken.
+func (mr *MocktokenHandlerMockRecorder) UpgradeGuestToken(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpgradeGuestToken", reflect.TypeOf((*MocktokenHandler)(nil).UpgradeGuestToken), c)
+}
+
+// MockgroupHandler is a mock of groupHandler interface.
+type MockgroupHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockgroupHandlerMockRecorder
+}
+
+// MockgroupHandlerMockRecorder is the mock recorder for MockgroupHandler.
+type MockgroupHandlerMockRecorder struct {
+	mock *MockgroupHandler
+}
+
+// NewMockgroupHandler creates a new mock instance.
+func NewMockgroupHandler(ctrl *gomock.Controller) *MockgroupHandler {
+	mock := &MockgroupHandler{ctrl: ctrl}
+	mock.recorder = &MockgroupHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockgroupHandler) EXPECT() *MockgroupHandlerMockRecorder {
+	return m.recorder
+}
+
+// CheckGroupAccess mocks base method.
+func (m *MockgroupHandler) CheckGroupAccess(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckGroupAccess", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckGroupAccess indicates an expected call of CheckGroupAccess.
+func (mr *MockgroupHandlerMockRecorder) CheckGroupAccess(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckGroupAccess", reflect.TypeOf((*MockgroupHandler)(nil).CheckGroupAccess), c)
+}
+
+// CreateGroup mocks base method.
+func (m *MockgroupHandler) CreateGroup(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGroup", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateGroup indicates an expected call of CreateGroup.
+func (mr *MockgroupHandlerMockRecorder) CreateGroup(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroup", reflect.TypeOf((*MockgroupHandler)(nil).CreateGroup), c)
+}
+
+// DeleteGroup mocks base method.
+func (m *MockgroupHandler) DeleteGroup(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGroup", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGroup indicates an expected call of DeleteGroup.
+func (mr *MockgroupHandlerMockRecorder) DeleteGroup(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGroup", reflect.TypeOf((*MockgroupHandler)(nil).DeleteGroup), c)
+}
+
+// GetGroup mocks base method.
+func (m *MockgroupHandler) GetGroup(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroup", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetGroup indicates an expected call of GetGroup.
+func (mr *MockgroupHandlerMockRecorder) GetGroup(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroup", reflect.TypeOf((*MockgroupHandler)(nil).GetGroup), c)
+}
+
+// RemoveGroupMember mocks base method.
+func (m *MockgroupHandler) RemoveGroupMember(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveGroupMember", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveGroupMember indicates an expected call of RemoveGroupMember.
+func (mr *MockgroupHandlerMockRecorder) RemoveGroupMember(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveGroupMember", reflect.TypeOf((*MockgroupHandler)(nil).RemoveGroupMember), c)
+}
+
+// SetGroupMember mocks base method.
+func (m *MockgroupHandler) SetGroupMember(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetGroupMember", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetGroupMember indicates an expected call of SetGroupMember.
+func (mr *MockgroupHandlerMockRecorder) SetGroupMember(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetGroupMember", reflect.TypeOf((*MockgroupHandler)(nil).SetGroupMember), c)
+}
+
+// UserGroups mocks base method.
+func (m *MockgroupHandler) UserGroups(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserGroups", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UserGroups indicates an expected call of UserGroups.
+func (mr *MockgroupHandlerMockRecorder) UserGroups(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserGroups", reflect.TypeOf((*MockgroupHandler)(nil).UserGroups), c)
+}
+
+// MockapiKeyHandler is a mock of apiKeyHandler interface.
+type MockapiKeyHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockapiKeyHandlerMockRecorder
+}
+
+// MockapiKeyHandlerMockRecorder is the mock recorder for MockapiKeyHandler.
+type MockapiKeyHandlerMockRecorder struct {
+	mock *MockapiKeyHandler
+}
+
+// NewMockapiKeyHandler creates a new mock instance.
+func NewMockapiKeyHandler(ctrl *gomock.Controller) *MockapiKeyHandler {
+	mock := &MockapiKeyHandler{ctrl: ctrl}
+	mock.recorder = &MockapiKeyHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockapiKeyHandler) EXPECT() *MockapiKeyHandlerMockRecorder {
+	return m.recorder
+}
+
+// APIKeyUsage mocks base method.
+func (m *MockapiKeyHandler) APIKeyUsage(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "APIKeyUsage", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// APIKeyUsage indicates an expected call of APIKeyUsage.
+func (mr *MockapiKeyHandlerMockRecorder) APIKeyUsage(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "APIKeyUsage", reflect.TypeOf((*MockapiKeyHandler)(nil).APIKeyUsage), c)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockapiKeyHandler) CreateAPIKey(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockapiKeyHandlerMockRecorder) CreateAPIKey(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockapiKeyHandler)(nil).CreateAPIKey), c)
+}
+
+// GetAPIKeyRateLimit mocks base method.
+func (m *MockapiKeyHandler) GetAPIKeyRateLimit(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyRateLimit", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetAPIKeyRateLimit indicates an expected call of GetAPIKeyRateLimit.
+func (mr *MockapiKeyHandlerMockRecorder) GetAPIKeyRateLimit(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyRateLimit", reflect.TypeOf((*MockapiKeyHandler)(nil).GetAPIKeyRateLimit), c)
+}
+
+// ResetAPIKeyRateLimit mocks base method.
+func (m *MockapiKeyHandler) ResetAPIKeyRateLimit(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetAPIKeyRateLimit", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetAPIKeyRateLimit indicates an expected call of ResetAPIKeyRateLimit.
+func (mr *MockapiKeyHandlerMockRecorder) ResetAPIKeyRateLimit(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetAPIKeyRateLimit", reflect.TypeOf((*MockapiKeyHandler)(nil).ResetAPIKeyRateLimit), c)
+}
+
+// UpdateAPIKeyRateLimit mocks base method.
+func (m *MockapiKeyHandler) UpdateAPIKeyRateLimit(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAPIKeyRateLimit", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAPIKeyRateLimit indicates an expected call of UpdateAPIKeyRateLimit.
+func (mr *MockapiKeyHandlerMockRecorder) UpdateAPIKeyRateLimit(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAPIKeyRateLimit", reflect.TypeOf((*MockapiKeyHandler)(nil).UpdateAPIKeyRateLimit), c)
+}
+
+// MockrevocationHandler is a mock of revocationHandler interface.
+type MockrevocationHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockrevocationHandlerMockRecorder
+}
+
+// MockrevocationHandlerMockRecorder is the mock recorder for MockrevocationHandler.
+type MockrevocationHandlerMockRecorder struct {
+	mock *MockrevocationHandler
+}
+
+// NewMockrevocationHandler creates a new mock instance.
+func NewMockrevocationHandler(ctrl *gomock.Controller) *MockrevocationHandler {
+	mock := &MockrevocationHandler{ctrl: ctrl}
+	mock.recorder = &MockrevocationHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockrevocationHandler) EXPECT() *MockrevocationHandlerMockRecorder {
+	return m.recorder
+}
+
+// CheckDeactivations mocks base method.
+func (m *MockrevocationHandler) CheckDeactivations(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckDeactivations", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckDeactivations indicates an expected call of CheckDeactivations.
+func (mr *MockrevocationHandlerMockRecorder) CheckDeactivations(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckDeactivations", reflect.TypeOf((*MockrevocationHandler)(nil).CheckDeactivations), c)
+}
+
+// DeactivateUser mocks base method.
+func (m *MockrevocationHandler) DeactivateUser(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateUser", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeactivateUser indicates an expected call of DeactivateUser.
+func (mr *MockrevocationHandlerMockRecorder) DeactivateUser(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateUser", reflect.TypeOf((*MockrevocationHandler)(nil).DeactivateUser), c)
+}
+
+// ReactivateUser mocks base method.
+func (m *MockrevocationHandler) ReactivateUser(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReactivateUser", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReactivateUser indicates an expected call of ReactivateUser.
+func (mr *MockrevocationHandlerMockRecorder) ReactivateUser(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReactivateUser", reflect.TypeOf((*MockrevocationHandler)(nil).ReactivateUser), c)
+}
+
+// RevokeUserSessions mocks base method.
+func (m *MockrevocationHandler) RevokeUserSessions(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserSessions", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserSessions indicates an expected call of RevokeUserSessions.
+func (mr *MockrevocationHandlerMockRecorder) RevokeUserSessions(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserSessions", reflect.TypeOf((*MockrevocationHandler)(nil).RevokeUserSessions), c)
+}
+
+// MockjobHandler is a mock of jobHandler interface.
+type MockjobHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockjobHandlerMockRecorder
+}
+
+// MockjobHandlerMockRecorder is the mock recorder for MockjobHandler.
+type MockjobHandlerMockRecorder struct {
+	mock *MockjobHandler
+}
+
+// NewMockjobHandler creates a new mock instance.
+func NewMockjobHandler(ctrl *gomock.Controller) *MockjobHandler {
+	mock := &MockjobHandler{ctrl: ctrl}
+	mock.recorder = &MockjobHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockjobHandler) EXPECT() *MockjobHandlerMockRecorder {
+	return m.recorder
+}
+
+// GetJob mocks base method.
+func (m *MockjobHandler) GetJob(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockjobHandlerMockRecorder) GetJob(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockjobHandler)(nil).GetJob), c)
+}
+
+// MockpasskeyHandler is a mock of passkeyHandler interface.
+type MockpasskeyHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockpasskeyHandlerMockRecorder
+}
+
+// MockpasskeyHandlerMockRecorder is the mock recorder for MockpasskeyHandler.
+type MockpasskeyHandlerMockRecorder struct {
+	mock *MockpasskeyHandler
+}
+
+// NewMockpasskeyHandler creates a new mock instance.
+func NewMockpasskeyHandler(ctrl *gomock.Controller) *MockpasskeyHandler {
+	mock := &MockpasskeyHandler{ctrl: ctrl}
+	mock.recorder = &MockpasskeyHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockpasskeyHandler) EXPECT() *MockpasskeyHandlerMockRecorder {
+	return m.recorder
+}
+
+// BeginPasskeyLogin mocks base method.
+func (m *MockpasskeyHandler) BeginPasskeyLogin(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginPasskeyLogin", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BeginPasskeyLogin indicates an expected call of BeginPasskeyLogin.
+func (mr *MockpasskeyHandlerMockRecorder) BeginPasskeyLogin(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginPasskeyLogin", reflect.TypeOf((*MockpasskeyHandler)(nil).BeginPasskeyLogin), c)
+}
+
+// BeginPasskeyRegistration mocks base method.
+func (m *MockpasskeyHandler) BeginPasskeyRegistration(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginPasskeyRegistration", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BeginPasskeyRegistration indicates an expected call of BeginPasskeyRegistration.
+func (mr *MockpasskeyHandlerMockRecorder) BeginPasskeyRegistration(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginPasskeyRegistration", reflect.TypeOf((*MockpasskeyHandler)(nil).BeginPasskeyRegistration), c)
+}
+
+// FinishPasskeyLogin mocks base method.
+func (m *MockpasskeyHandler) FinishPasskeyLogin(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishPasskeyLogin", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishPasskeyLogin indicates an expected call of FinishPasskeyLogin.
+func (mr *MockpasskeyHandlerMockRecorder) FinishPasskeyLogin(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishPasskeyLogin", reflect.TypeOf((*MockpasskeyHandler)(nil).FinishPasskeyLogin), c)
+}
+
+// FinishPasskeyRegistration mocks base method.
+func (m *MockpasskeyHandler) FinishPasskeyRegistration(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishPasskeyRegistration", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishPasskeyRegistration indicates an expected call of FinishPasskeyRegistration.
+func (mr *MockpasskeyHandlerMockRecorder) FinishPasskeyRegistration(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishPasskeyRegistration", reflect.TypeOf((*MockpasskeyHandler)(nil).FinishPasskeyRegistration), c)
+}
+
+// MockoauthHandler is a mock of oauthHandler interface.
+type MockoauthHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockoauthHandlerMockRecorder
+}
+
+// MockoauthHandlerMockRecorder is the mock recorder for MockoauthHandler.
+type MockoauthHandlerMockRecorder struct {
+	mock *MockoauthHandler
+}
+
+// NewMockoauthHandler creates a new mock instance.
+func NewMockoauthHandler(ctrl *gomock.Controller) *MockoauthHandler {
+	mock := &MockoauthHandler{ctrl: ctrl}
+	mock.recorder = &MockoauthHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockoauthHandler) EXPECT() *MockoauthHandlerMockRecorder {
+	return m.recorder
+}
+
+// OAuthCallback mocks base method.
+func (m *MockoauthHandler) OAuthCallback(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OAuthCallback", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// OAuthCallback indicates an expected call of OAuthCallback.
+func (mr *MockoauthHandlerMockRecorder) OAuthCallback(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OAuthCallback", reflect.TypeOf((*MockoauthHandler)(nil).OAuthCallback), c)
+}
+
+// StartOAuth mocks base method.
+func (m *MockoauthHandler) StartOAuth(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartOAuth", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartOAuth indicates an expected call of StartOAuth.
+func (mr *MockoauthHandlerMockRecorder) StartOAuth(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartOAuth", reflect.TypeOf((*MockoauthHandler)(nil).StartOAuth), c)
+}
+
+// MocktelegramHandler is a mock of telegramHandler interface.
+type MocktelegramHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MocktelegramHandlerMockRecorder
+}
+
+// MocktelegramHandlerMockRecorder is the mock recorder for MocktelegramHandler.
+type MocktelegramHandlerMockRecorder struct {
+	mock *MocktelegramHandler
+}
+
+// NewMocktelegramHandler creates a new mock instance.
+func NewMocktelegramHandler(ctrl *gomock.Controller) *MocktelegramHandler {
+	mock := &MocktelegramHandler{ctrl: ctrl}
+	mock.recorder = &MocktelegramHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktelegramHandler) EXPECT() *MocktelegramHandlerMockRecorder {
+	return m.recorder
+}
+
+// TelegramLogin mocks base method.
+func (m *MocktelegramHandler) TelegramLogin(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TelegramLogin", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TelegramLogin indicates an expected call of TelegramLogin.
+func (mr *MocktelegramHandlerMockRecorder) TelegramLogin(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TelegramLogin", reflect.TypeOf((*MocktelegramHandler)(nil).TelegramLogin), c)
+}
+
+// MockadminLoginHandler is a mock of adminLoginHandler interface.
+type MockadminLoginHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockadminLoginHandlerMockRecorder
+}
+
+// MockadminLoginHandlerMockRecorder is the mock recorder for MockadminLoginHandler.
+type MockadminLoginHandlerMockRecorder struct {
+	mock *MockadminLoginHandler
+}
+
+// NewMockadminLoginHandler creates a new mock instance.
+func NewMockadminLoginHandler(ctrl *gomock.Controller) *MockadminLoginHandler {
+	mock := &MockadminLoginHandler{ctrl: ctrl}
+	mock.recorder = &MockadminLoginHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockadminLoginHandler) EXPECT() *MockadminLoginHandlerMockRecorder {
+	return m.recorder
+}
+
+// AdminLogin mocks base method.
+func (m *MockadminLoginHandler) AdminLogin(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminLogin", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdminLogin indicates an expected call of AdminLogin.
+func (mr *MockadminLoginHandlerMockRecorder) AdminLogin(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminLogin", reflect.TypeOf((*MockadminLoginHandler)(nil).AdminLogin), c)
+}
+
+// MockscimHandler is a mock of scimHandler interface.
+type MockscimHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockscimHandlerMockRecorder
+}
+
+// MockscimHandlerMockRecorder is the mock recorder for MockscimHandler.
+type MockscimHandlerMockRecorder struct {
+	mock *MockscimHandler
+}
+
+// NewMockscimHandler creates a new mock instance.
+func NewMockscimHandler(ctrl *gomock.Controller) *MockscimHandler {
+	mock := &MockscimHandler{ctrl: ctrl}
+	mock.recorder = &MockscimHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockscimHandler) EXPECT() *MockscimHandlerMockRecorder {
+	return m.recorder
+}
+
+// CreateSCIMUser mocks base method.
+func (m *MockscimHandler) CreateSCIMUser(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSCIMUser", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSCIMUser indicates an expected call of CreateSCIMUser.
+func (mr *MockscimHandlerMockRecorder) CreateSCIMUser(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSCIMUser", reflect.TypeOf((*MockscimHandler)(nil).CreateSCIMUser), c)
+}
+
+// DeleteSCIMUser mocks base method.
+func (m *MockscimHandler) DeleteSCIMUser(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSCIMUser", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSCIMUser indicates an expected call of DeleteSCIMUser.
+func (mr *MockscimHandlerMockRecorder) DeleteSCIMUser(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSCIMUser", reflect.TypeOf((*MockscimHandler)(nil).DeleteSCIMUser), c)
+}
+
+// GetSCIMUser mocks base method.
+func (m *MockscimHandler) GetSCIMUser(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSCIMUser", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetSCIMUser indicates an expected call of GetSCIMUser.
+func (mr *MockscimHandlerMockRecorder) GetSCIMUser(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSCIMUser", reflect.TypeOf((*MockscimHandler)(nil).GetSCIMUser), c)
+}
+
+// ListSCIMUsers mocks base method.
+func (m *MockscimHandler) ListSCIMUsers(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSCIMUsers", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListSCIMUsers indicates an expected call of ListSCIMUsers.
+func (mr *MockscimHandlerMockRecorder) ListSCIMUsers(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSCIMUsers", reflect.TypeOf((*MockscimHandler)(nil).ListSCIMUsers), c)
+}
+
+// PatchSCIMUser mocks base method.
+func (m *MockscimHandler) PatchSCIMUser(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchSCIMUser", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchSCIMUser indicates an expected call of PatchSCIMUser.
+func (mr *MockscimHandlerMockRecorder) PatchSCIMUser(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchSCIMUser", reflect.TypeOf((*MockscimHandler)(nil).PatchSCIMUser), c)
+}
+
+// ReplaceSCIMUser mocks base method.
+func (m *MockscimHandler) ReplaceSCIMUser(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceSCIMUser", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceSCIMUser indicates an expected call of ReplaceSCIMUser.
+func (mr *MockscimHandlerMockRecorder) ReplaceSCIMUser(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceSCIMUser", reflect.TypeOf((*MockscimHandler)(nil).ReplaceSCIMUser), c)
+}
+
+// MockcredentialsHandler is a mock of credentialsHandler interface.
+type MockcredentialsHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockcredentialsHandlerMockRecorder
+}
+
+// MockcredentialsHandlerMockRecorder is the mock recorder for MockcredentialsHandler.
+type MockcredentialsHandlerMockRecorder struct {
+	mock *MockcredentialsHandler
+}
+
+// NewMockcredentialsHandler creates a new mock instance.
+func NewMockcredentialsHandler(ctrl *gomock.Controller) *MockcredentialsHandler {
+	mock := &MockcredentialsHandler{ctrl: ctrl}
+	mock.recorder = &MockcredentialsHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockcredentialsHandler) EXPECT() *MockcredentialsHandlerMockRecorder {
+	return m.recorder
+}
+
+// CheckCredentials mocks base method.
+func (m *MockcredentialsHandler) CheckCredentials(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckCredentials", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckCredentials indicates an expected call of CheckCredentials.
+func (mr *MockcredentialsHandlerMockRecorder) CheckCredentials(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckCredentials", reflect.TypeOf((*MockcredentialsHandler)(nil).CheckCredentials), c)
+}
+
+// MocknotificationHandler is a mock of notificationHandler interface.
+type MocknotificationHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MocknotificationHandlerMockRecorder
+}
+
+// MocknotificationHandlerMockRecorder is the mock recorder for MocknotificationHandler.
+type MocknotificationHandlerMockRecorder struct {
+	mock *MocknotificationHandler
+}
+
+// NewMocknotificationHandler creates a new mock instance.
+func NewMocknotificationHandler(ctrl *gomock.Controller) *MocknotificationHandler {
+	mock := &MocknotificationHandler{ctrl: ctrl}
+	mock.recorder = &MocknotificationHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocknotificationHandler) EXPECT() *MocknotificationHandlerMockRecorder {
+	return m.recorder
+}
+
+// GetNotificationPreferences mocks base method.
+func (m *MocknotificationHandler) GetNotificationPreferences(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationPreferences", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetNotificationPreferences indicates an expected call of GetNotificationPreferences.
+func (mr *MocknotificationHandlerMockRecorder) GetNotificationPreferences(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationPreferences", reflect.TypeOf((*MocknotificationHandler)(nil).GetNotificationPreferences), c)
+}
+
+// SendNotification mocks base method.
+func (m *MocknotificationHandler) SendNotification(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendNotification", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendNotification indicates an expected call of SendNotification.
+func (mr *MocknotificationHandlerMockRecorder) SendNotification(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendNotification", reflect.TypeOf((*MocknotificationHandler)(nil).SendNotification), c)
+}
+
+// UpdateNotificationPreferences mocks base method.
+func (m *MocknotificationHandler) UpdateNotificationPreferences(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNotificationPreferences", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateNotificationPreferences indicates an expected call of UpdateNotificationPreferences.
+func (mr *MocknotificationHandlerMockRecorder) UpdateNotificationPreferences(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotificationPreferences", reflect.TypeOf((*MocknotificationHandler)(nil).UpdateNotificationPreferences), c)
+}
+
+// MocksessionHandler is a mock of sessionHandler interface.
+type MocksessionHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MocksessionHandlerMockRecorder
+}
+
+// MocksessionHandlerMockRecorder is the mock recorder for MocksessionHandler.
+type MocksessionHandlerMockRecorder struct {
+	mock *MocksessionHandler
+}
+
+// NewMocksessionHandler creates a new mock instance.
+func NewMocksessionHandler(ctrl *gomock.Controller) *MocksessionHandler {
+	mock := &MocksessionHandler{ctrl: ctrl}
+	mock.recorder = &MocksessionHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocksessionHandler) EXPECT() *MocksessionHandlerMockRecorder {
+	return m.recorder
+}
+
+// ListSessions mocks base method.
+func (m *MocksessionHandler) ListSessions(c echo.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Health", c)
+	ret := m.ctrl.Call(m, "ListSessions", c)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Health indicates an expected call of Health.
-func (mr *MockhandlerMockRecorder) Health(c interface{}) *gomock.Call {
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MocksessionHandlerMockRecorder) ListSessions(c interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Health", reflect.TypeOf((*Mockhandler)(nil).Health), c)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MocksessionHandler)(nil).ListSessions), c)
 }
 
-// Version mocks base method.
-func (m *Mockhandler) Version() string {
+// Logout mocks base method.
+func (m *MocksessionHandler) Logout(c echo.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Version")
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "Logout", c)
+	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Version indicates an expected call of Version.
-func (mr *MockhandlerMockRecorder) Version() *gomock.Call {
+// Logout indicates an expected call of Logout.
+func (mr *MocksessionHandlerMockRecorder) Logout(c interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Version", reflect.TypeOf((*Mockhandler)(nil).Version))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MocksessionHandler)(nil).Logout), c)
 }
 
-// MockversionHandler is a mock of versionHandler interface.
-type MockversionHandler struct {
+// LogoutAll mocks base method.
+func (m *MocksessionHandler) LogoutAll(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogoutAll", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogoutAll indicates an expected call of LogoutAll.
+func (mr *MocksessionHandlerMockRecorder) LogoutAll(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoutAll", reflect.TypeOf((*MocksessionHandler)(nil).LogoutAll), c)
+}
+
+// RevokeSession mocks base method.
+func (m *MocksessionHandler) RevokeSession(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MocksessionHandlerMockRecorder) RevokeSession(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MocksessionHandler)(nil).RevokeSession), c)
+}
+
+// MockemailChangeHandler is a mock of emailChangeHandler interface.
+type MockemailChangeHandler struct {
 	ctrl     *gomock.Controller
-	recorder *MockversionHandlerMockRecorder
+	recorder *MockemailChangeHandlerMockRecorder
 }
 
-// MockversionHandlerMockRecorder is the mock recorder for MockversionHandler.
-type MockversionHandlerMockRecorder struct {
-	mock *MockversionHandler
+// MockemailChangeHandlerMockRecorder is the mock recorder for MockemailChangeHandler.
+type MockemailChangeHandlerMockRecorder struct {
+	mock *MockemailChangeHandler
 }
 
-// NewMockversionHandler creates a new mock instance.
-func NewMockversionHandler(ctrl *gomock.Controller) *MockversionHandler {
-	mock := &MockversionHandler{ctrl: ctrl}
-	mock.recorder = &MockversionHandlerMockRecorder{mock}
+// NewMockemailChangeHandler creates a new mock instance.
+func NewMockemailChangeHandler(ctrl *gomock.Controller) *MockemailChangeHandler {
+	mock := &MockemailChangeHandler{ctrl: ctrl}
+	mock.recorder = &MockemailChangeHandlerMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockversionHandler) EXPECT() *MockversionHandlerMockRecorder {
+func (m *MockemailChangeHandler) EXPECT() *MockemailChangeHandlerMockRecorder {
 	return m.recorder
 }
 
-// Version mocks base method.
-func (m *MockversionHandler) Version() string {
+// CancelEmailChange mocks base method.
+func (m *MockemailChangeHandler) CancelEmailChange(c echo.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Version")
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "CancelEmailChange", c)
+	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Version indicates an expected call of Version.
-func (mr *MockversionHandlerMockRecorder) Version() *gomock.Call {
+// CancelEmailChange indicates an expected call of CancelEmailChange.
+func (mr *MockemailChangeHandlerMockRecorder) CancelEmailChange(c interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Version", reflect.TypeOf((*MockversionHandler)(nil).Version))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelEmailChange", reflect.TypeOf((*MockemailChangeHandler)(nil).CancelEmailChange), c)
 }
 
-// MockhealthHandler is a mock of healthHandler interface.
-type MockhealthHandler struct {
+// ConfirmEmailChange mocks base method.
+func (m *MockemailChangeHandler) ConfirmEmailChange(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEmailChange", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmEmailChange indicates an expected call of ConfirmEmailChange.
+func (mr *MockemailChangeHandlerMockRecorder) ConfirmEmailChange(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEmailChange", reflect.TypeOf((*MockemailChangeHandler)(nil).ConfirmEmailChange), c)
+}
+
+// GetEmailChange mocks base method.
+func (m *MockemailChangeHandler) GetEmailChange(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailChange", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetEmailChange indicates an expected call of GetEmailChange.
+func (mr *MockemailChangeHandlerMockRecorder) GetEmailChange(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailChange", reflect.TypeOf((*MockemailChangeHandler)(nil).GetEmailChange), c)
+}
+
+// StartEmailChange mocks base method.
+func (m *MockemailChangeHandler) StartEmailChange(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartEmailChange", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartEmailChange indicates an expected call of StartEmailChange.
+func (mr *MockemailChangeHandlerMockRecorder) StartEmailChange(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartEmailChange", reflect.TypeOf((*MockemailChangeHandler)(nil).StartEmailChange), c)
+}
+
+// MockabuseHandler is a mock of abuseHandler interface.
+type MockabuseHandler struct {
 	ctrl     *gomock.Controller
-	recorder *MockhealthHandlerMockRecorder
+	recorder *MockabuseHandlerMockRecorder
 }
 
-// MockhealthHandlerMockRecorder is the mock recorder for MockhealthHandler.
-type MockhealthHandlerMockRecorder struct {
-	mock *MockhealthHandler
+// MockabuseHandlerMockRecorder is the mock recorder for MockabuseHandler.
+type MockabuseHandlerMockRecorder struct {
+	mock *MockabuseHandler
 }
 
-// NewMockhealthHandler creates a new mock instance.
-func NewMockhealthHandler(ctrl *gomock.Controller) *MockhealthHandler {
-	mock := &MockhealthHandler{ctrl: ctrl}
-	mock.recorder = &MockhealthHandlerMockRecorder{mock}
+// NewMockabuseHandler creates a new mock instance.
+func NewMockabuseHandler(ctrl *gomock.Controller) *MockabuseHandler {
+	mock := &MockabuseHandler{ctrl: ctrl}
+	mock.recorder = &MockabuseHandlerMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockhealthHandler) EXPECT() *MockhealthHandlerMockRecorder {
+func (m *MockabuseHandler) EXPECT() *MockabuseHandlerMockRecorder {
 	return m.recorder
 }
 
-// Health mocks base method.
-func (m *MockhealthHandler) Health(c echo.Context) error {
+// CreateBan mocks base method.
+func (m *MockabuseHandler) CreateBan(c echo.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Health", c)
+	ret := m.ctrl.Call(m, "CreateBan", c)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Health indicates an expected call of Health.
-func (mr *MockhealthHandlerMockRecorder) Health(c interface{}) *gomock.Call {
+// CreateBan indicates an expected call of CreateBan.
+func (mr *MockabuseHandlerMockRecorder) CreateBan(c interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Health", reflect.TypeOf((*MockhealthHandler)(nil).Health), c)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBan", reflect.TypeOf((*MockabuseHandler)(nil).CreateBan), c)
+}
+
+// DeleteBan mocks base method.
+func (m *MockabuseHandler) DeleteBan(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBan", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBan indicates an expected call of DeleteBan.
+func (mr *MockabuseHandlerMockRecorder) DeleteBan(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBan", reflect.TypeOf((*MockabuseHandler)(nil).DeleteBan), c)
+}
+
+// ListBans mocks base method.
+func (m *MockabuseHandler) ListBans(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBans", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListBans indicates an expected call of ListBans.
+func (mr *MockabuseHandlerMockRecorder) ListBans(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBans", reflect.TypeOf((*MockabuseHandler)(nil).ListBans), c)
+}
+
+// MockqrLoginHandler is a mock of qrLoginHandler interface.
+type MockqrLoginHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockqrLoginHandlerMockRecorder
+}
+
+// MockqrLoginHandlerMockRecorder is the mock recorder for MockqrLoginHandler.
+type MockqrLoginHandlerMockRecorder struct {
+	mock *MockqrLoginHandler
+}
+
+// NewMockqrLoginHandler creates a new mock instance.
+func NewMockqrLoginHandler(ctrl *gomock.Controller) *MockqrLoginHandler {
+	mock := &MockqrLoginHandler{ctrl: ctrl}
+	mock.recorder = &MockqrLoginHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockqrLoginHandler) EXPECT() *MockqrLoginHandlerMockRecorder {
+	return m.recorder
+}
+
+// ClaimQRLogin mocks base method.
+func (m *MockqrLoginHandler) ClaimQRLogin(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimQRLogin", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimQRLogin indicates an expected call of ClaimQRLogin.
+func (mr *MockqrLoginHandlerMockRecorder) ClaimQRLogin(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimQRLogin", reflect.TypeOf((*MockqrLoginHandler)(nil).ClaimQRLogin), c)
+}
+
+// ConfirmQRLogin mocks base method.
+func (m *MockqrLoginHandler) ConfirmQRLogin(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmQRLogin", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmQRLogin indicates an expected call of ConfirmQRLogin.
+func (mr *MockqrLoginHandlerMockRecorder) ConfirmQRLogin(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmQRLogin", reflect.TypeOf((*MockqrLoginHandler)(nil).ConfirmQRLogin), c)
+}
+
+// StartQRLogin mocks base method.
+func (m *MockqrLoginHandler) StartQRLogin(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartQRLogin", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartQRLogin indicates an expected call of StartQRLogin.
+func (mr *MockqrLoginHandlerMockRecorder) StartQRLogin(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartQRLogin", reflect.TypeOf((*MockqrLoginHandler)(nil).StartQRLogin), c)
+}
+
+// MocksvidHandler is a mock of svidHandler interface.
+type MocksvidHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MocksvidHandlerMockRecorder
+}
+
+// MocksvidHandlerMockRecorder is the mock recorder for MocksvidHandler.
+type MocksvidHandlerMockRecorder struct {
+	mock *MocksvidHandler
+}
+
+// NewMocksvidHandler creates a new mock instance.
+func NewMocksvidHandler(ctrl *gomock.Controller) *MocksvidHandler {
+	mock := &MocksvidHandler{ctrl: ctrl}
+	mock.recorder = &MocksvidHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocksvidHandler) EXPECT() *MocksvidHandlerMockRecorder {
+	return m.recorder
+}
+
+// IssueJWTSVID mocks base method.
+func (m *MocksvidHandler) IssueJWTSVID(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueJWTSVID", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IssueJWTSVID indicates an expected call of IssueJWTSVID.
+func (mr *MocksvidHandlerMockRecorder) IssueJWTSVID(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueJWTSVID", reflect.TypeOf((*MocksvidHandler)(nil).IssueJWTSVID), c)
+}
+
+// IssueX509SVID mocks base method.
+func (m *MocksvidHandler) IssueX509SVID(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueX509SVID", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IssueX509SVID indicates an expected call of IssueX509SVID.
+func (mr *MocksvidHandlerMockRecorder) IssueX509SVID(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueX509SVID", reflect.TypeOf((*MocksvidHandler)(nil).IssueX509SVID), c)
+}
+
+// SVIDBundle mocks base method.
+func (m *MocksvidHandler) SVIDBundle(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SVIDBundle", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SVIDBundle indicates an expected call of SVIDBundle.
+func (mr *MocksvidHandlerMockRecorder) SVIDBundle(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SVIDBundle", reflect.TypeOf((*MocksvidHandler)(nil).SVIDBundle), c)
+}
+
+// MockcaptureHandler is a mock of captureHandler interface.
+type MockcaptureHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockcaptureHandlerMockRecorder
+}
+
+// MockcaptureHandlerMockRecorder is the mock recorder for MockcaptureHandler.
+type MockcaptureHandlerMockRecorder struct {
+	mock *MockcaptureHandler
+}
+
+// NewMockcaptureHandler creates a new mock instance.
+func NewMockcaptureHandler(ctrl *gomock.Controller) *MockcaptureHandler {
+	mock := &MockcaptureHandler{ctrl: ctrl}
+	mock.recorder = &MockcaptureHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockcaptureHandler) EXPECT() *MockcaptureHandlerMockRecorder {
+	return m.recorder
+}
+
+// ClearCapture mocks base method.
+func (m *MockcaptureHandler) ClearCapture(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearCapture", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearCapture indicates an expected call of ClearCapture.
+func (mr *MockcaptureHandlerMockRecorder) ClearCapture(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearCapture", reflect.TypeOf((*MockcaptureHandler)(nil).ClearCapture), c)
+}
+
+// GetCapture mocks base method.
+func (m *MockcaptureHandler) GetCapture(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCapture", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetCapture indicates an expected call of GetCapture.
+func (mr *MockcaptureHandlerMockRecorder) GetCapture(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCapture", reflect.TypeOf((*MockcaptureHandler)(nil).GetCapture), c)
+}
+
+// UpdateCapture mocks base method.
+func (m *MockcaptureHandler) UpdateCapture(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCapture", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCapture indicates an expected call of UpdateCapture.
+func (mr *MockcaptureHandlerMockRecorder) UpdateCapture(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCapture", reflect.TypeOf((*MockcaptureHandler)(nil).UpdateCapture), c)
+}
+
+// MocklogSamplingHandler is a mock of logSamplingHandler interface.
+type MocklogSamplingHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MocklogSamplingHandlerMockRecorder
+}
+
+// MocklogSamplingHandlerMockRecorder is the mock recorder for MocklogSamplingHandler.
+type MocklogSamplingHandlerMockRecorder struct {
+	mock *MocklogSamplingHandler
+}
+
+// NewMocklogSamplingHandler creates a new mock instance.
+func NewMocklogSamplingHandler(ctrl *gomock.Controller) *MocklogSamplingHandler {
+	mock := &MocklogSamplingHandler{ctrl: ctrl}
+	mock.recorder = &MocklogSamplingHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocklogSamplingHandler) EXPECT() *MocklogSamplingHandlerMockRecorder {
+	return m.recorder
+}
+
+// GetLogSampling mocks base method.
+func (m *MocklogSamplingHandler) GetLogSampling(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLogSampling", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetLogSampling indicates an expected call of GetLogSampling.
+func (mr *MocklogSamplingHandlerMockRecorder) GetLogSampling(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLogSampling", reflect.TypeOf((*MocklogSamplingHandler)(nil).GetLogSampling), c)
+}
+
+// ResetLogSampling mocks base method.
+func (m *MocklogSamplingHandler) ResetLogSampling(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetLogSampling", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetLogSampling indicates an expected call of ResetLogSampling.
+func (mr *MocklogSamplingHandlerMockRecorder) ResetLogSampling(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetLogSampling", reflect.TypeOf((*MocklogSamplingHandler)(nil).ResetLogSampling), c)
+}
+
+// UpdateLogSampling mocks base method.
+func (m *MocklogSamplingHandler) UpdateLogSampling(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLogSampling", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLogSampling indicates an expected call of UpdateLogSampling.
+func (mr *MocklogSamplingHandlerMockRecorder) UpdateLogSampling(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLogSampling", reflect.TypeOf((*MocklogSamplingHandler)(nil).UpdateLogSampling), c)
 }
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// RealIPHeaderXFF - реальный IP берется из X-Forwarded-For (крайний правый недоверенный адрес).
+	RealIPHeaderXFF = "x-forwarded-for"
+	// RealIPHeaderXRealIP - реальный IP берется из X-Real-IP.
+	RealIPHeaderXRealIP = "x-real-ip"
+	// RealIPHeaderCloudflare - реальный IP берется из CF-Connecting-IP.
+	RealIPHeaderCloudflare = "cf-connecting-ip"
+
+	headerCFConnectingIP = "CF-Connecting-IP"
+)
+
+func isKnownRealIPHeader(header string) bool {
+	switch header {
+	case "", RealIPHeaderXFF, RealIPHeaderXRealIP, RealIPHeaderCloudflare:
+		return true
+	}
+
+	return false
+}
+
+// ipExtractor возвращает способ определения реального IP клиента.
+// Без доверенных прокси используется IP соединения, чтобы клиент не мог подменить его заголовком.
+// Заголовок учитывается, только если соединение пришло от доверенного прокси.
+func (s *Server) ipExtractor() echo.IPExtractor {
+	if len(s.trustedNets) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	opts := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+
+	for _, ipNet := range s.trustedNets {
+		opts = append(opts, echo.TrustIPRange(ipNet))
+	}
+
+	switch s.realIPHeader {
+	case RealIPHeaderXRealIP:
+		return echo.ExtractIPFromRealIPHeader(opts...)
+	case RealIPHeaderCloudflare:
+		return s.extractIPFromCloudflareHeader()
+	default:
+		return echo.ExtractIPFromXFFHeader(opts...)
+	}
+}
+
+// extractIPFromCloudflareHeader берет IP из CF-Connecting-IP, если запрос пришел от доверенного прокси.
+func (s *Server) extractIPFromCloudflareHeader() echo.IPExtractor {
+	direct := echo.ExtractIPDirect()
+
+	return func(req *http.Request) string {
+		directIP := direct(req)
+
+		if !s.isTrustedProxy(net.ParseIP(directIP)) {
+			return directIP
+		}
+
+		ip := net.ParseIP(strings.TrimSpace(req.Header.Get(headerCFConnectingIP)))
+		if ip == nil {
+			return directIP
+		}
+
+		return ip.String()
+	}
+}
+
+func (s *Server) isTrustedProxy(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	for _, ipNet := range s.trustedNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"auth-service/internal/server/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestIPExtractor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		realIPHeader   string
+		remoteAddr     string
+		headers        map[string]string
+		want           string
+	}{
+		{
+			name:       "no trusted proxies: header is ignored",
+			remoteAddr: "10.0.0.5:1234",
+			headers:    map[string]string{echo.HeaderXForwardedFor: "203.0.113.7"},
+			want:       "10.0.0.5",
+		},
+		{
+			name:           "x-forwarded-for: request from trusted proxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.5:1234",
+			headers:        map[string]string{echo.HeaderXForwardedFor: "203.0.113.7"},
+			want:           "203.0.113.7",
+		},
+		{
+			name:           "x-forwarded-for: spoofed header behind trusted proxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			realIPHeader:   RealIPHeaderXFF,
+			remoteAddr:     "10.0.0.5:1234",
+			headers:        map[string]string{echo.HeaderXForwardedFor: "198.51.100.1, 203.0.113.7"},
+			want:           "203.0.113.7",
+		},
+		{
+			name:           "x-forwarded-for: request not from trusted proxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "192.0.2.10:1234",
+			headers:        map[string]string{echo.HeaderXForwardedFor: "203.0.113.7"},
+			want:           "192.0.2.10",
+		},
+		{
+			name:           "x-real-ip: request from trusted proxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			realIPHeader:   RealIPHeaderXRealIP,
+			remoteAddr:     "10.0.0.5:1234",
+			headers:        map[string]string{echo.HeaderXRealIP: "203.0.113.7", echo.HeaderXForwardedFor: "198.51.100.1"},
+			want:           "203.0.113.7",
+		},
+		{
+			name:           "cf-connecting-ip: request from trusted proxy",
+			trustedProxies: []string{"173.245.48.0/20"},
+			realIPHeader:   RealIPHeaderCloudflare,
+			remoteAddr:     "173.245.48.1:1234",
+			headers:        map[string]string{headerCFConnectingIP: "203.0.113.7"},
+			want:           "203.0.113.7",
+		},
+		{
+			name:           "cf-connecting-ip: request not from trusted proxy",
+			trustedProxies: []string{"173.245.48.0/20"},
+			realIPHeader:   RealIPHeaderCloudflare,
+			remoteAddr:     "192.0.2.10:1234",
+			headers:        map[string]string{headerCFConnectingIP: "203.0.113.7"},
+			want:           "192.0.2.10",
+		},
+		{
+			name:           "cf-connecting-ip: invalid header value",
+			trustedProxies: []string{"173.245.48.0/20"},
+			realIPHeader:   RealIPHeaderCloudflare,
+			remoteAddr:     "173.245.48.1:1234",
+			headers:        map[string]string{headerCFConnectingIP: "not-an-ip"},
+			want:           "173.245.48.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			h := mocks.NewMockhandler(ctrl)
+			h.EXPECT().Version().Return("v0")
+
+			server, err := New(
+				WithPort(8080),
+				WithShutdownTimeout(100*time.Millisecond),
+				WithHandlerV0(h),
+				WithTrustedProxies(tt.trustedProxies),
+				WithRealIPHeader(tt.realIPHeader),
+			)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			assert.Equal(t, tt.want, server.ipExtractor()(req))
+		})
+	}
+}
+
+func TestIsKnownRealIPHeader(t *testing.T) {
+	t.Parallel()
+
+	for _, header := range []string{"", RealIPHeaderXFF, RealIPHeaderXRealIP, RealIPHeaderCloudflare} {
+		assert.True(t, isKnownRealIPHeader(header), header)
+	}
+
+	assert.False(t, isKnownRealIPHeader("forwarded"))
+}
@@ -0,0 +1,13 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package server
+
+import (
+	"errors"
+	"syscall"
+)
+
+// reusePortControl возвращает ошибку: SO_REUSEPORT не поддерживается на этой платформе.
+func reusePortControl(_, _ string, _ syscall.RawConn) error {
+	return errors.New("SO_REUSEPORT is not supported on this platform")
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// scimAuth возвращает middleware, которое пропускает только запросы корпоративного IdP с токеном SCIM
+// в заголовке "Authorization: Bearer <token>". В конфигурации хранится только SHA-256 токена.
+func (s *Server) scimAuth() echo.MiddlewareFunc {
+	want := strings.ToLower(s.scimTokenSHA256)
+
+	return middleware.KeyAuthWithConfig(middleware.KeyAuthConfig{
+		KeyLookup:  "header:" + echo.HeaderAuthorization,
+		AuthScheme: "Bearer",
+		Validator: func(key string, _ echo.Context) (bool, error) {
+			sum := sha256.Sum256([]byte(key))
+
+			return subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(want)) == 1, nil
+		},
+	})
+}
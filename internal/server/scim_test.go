@@ -0,0 +1,93 @@
+package server
+
+import (
+	"auth-service/internal/server/mocks"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scimTokenSHA256 - SHA-256 строки "scim-token".
+const scimTokenSHA256 = "AE7370645E03C7C8AF559179D3C40C931DFFCC8863EA4BF42D59A7F509F6E735"
+
+func TestSCIMAuth(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+	}{
+		{name: "positive case", header: "Bearer scim-token", wantStatus: http.StatusOK},
+		{name: "error case: wrong token", header: "Bearer wrong", wantStatus: http.StatusUnauthorized},
+		{name: "error case: hash instead of token", header: "Bearer " + scimTokenSHA256, wantStatus: http.StatusUnauthorized},
+		{name: "error case: no header", header: "", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// регистр hex в конфигурации не важен
+			s := &Server{scimTokenSHA256: scimTokenSHA256}
+
+			e := echo.New()
+			e.GET("/scim", func(c echo.Context) error { return c.NoContent(http.StatusOK) }, s.scimAuth())
+
+			req := httptest.NewRequest(http.MethodGet, "/scim", nil)
+			if tt.header != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.header)
+			}
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestRegisterAPIRoutes_SCIM(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h := mocks.NewMockhandler(ctrl)
+	h.EXPECT().Version().Return("v0").Times(1)
+
+	server, err := New(
+		WithPort(8080),
+		WithShutdownTimeout(100*time.Millisecond),
+		WithHandlerV0(h),
+		WithSCIMToken(scimTokenSHA256),
+	)
+	require.NoError(t, err)
+
+	e := echo.New()
+	server.registerAPIRoutes(e)
+
+	routes := map[string]bool{}
+
+	for _, r := range e.Routes() {
+		if strings.HasPrefix(r.Path, "/api/v0/scim/") && r.Method != echo.RouteNotFound {
+			routes[r.Method+" "+r.Path] = true
+		}
+	}
+
+	assert.Equal(t, map[string]bool{
+		"POST /api/v0/scim/v2/Users":       true,
+		"GET /api/v0/scim/v2/Users":        true,
+		"GET /api/v0/scim/v2/Users/:id":    true,
+		"PUT /api/v0/scim/v2/Users/:id":    true,
+		"PATCH /api/v0/scim/v2/Users/:id":  true,
+		"DELETE /api/v0/scim/v2/Users/:id": true,
+	}, routes)
+}
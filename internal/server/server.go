@@ -2,17 +2,36 @@ package server
 
 import (
 	handlerV0 "auth-service/internal/api/v0"
+	serverMiddleware "auth-service/internal/server/middleware"
+	"auth-service/internal/service/abuse"
+	"auth-service/internal/service/capture"
+	"auth-service/internal/service/dependency"
+	"auth-service/internal/service/deprecation"
+	"auth-service/internal/service/loadshed"
+	"auth-service/internal/service/logsampling"
+	"auth-service/internal/service/metricguard"
+	"auth-service/internal/service/peer"
+	"auth-service/internal/service/pow"
+	"auth-service/internal/service/quota"
+	"auth-service/internal/service/ratelimit"
+	"auth-service/internal/service/readonly"
+	"auth-service/internal/service/securitytxt"
+	"auth-service/internal/service/slo"
+	"auth-service/internal/service/token"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"strings"
 	"time"
 
-	"github.com/labstack/echo-contrib/echoprometheus"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
-	echoSwagger "github.com/swaggo/echo-swagger"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Server - сервер.
@@ -22,8 +41,80 @@ type Server struct {
 	port            int
 	shutdownTimeout time.Duration
 
+	// конфигурация TLS. Если не задана, сервер работает по HTTP
+	tlsConfig *tls.Config
+
+	// передача сокета при перезапуске без простоя: SO_REUSEPORT или сокет от systemd
+	reusePort        bool
+	socketActivation bool
+
+	// HTTP/2 поверх TLS и HTTP/2 без шифрования (h2c) для внутренних клиентов
+	http2 bool
+	h2c   bool
+
 	e *echo.Echo
 
+	// внутренний отладочный порт с полным /health, метриками и swagger. 0 - отключен
+	debugPort int
+	debug     *echo.Echo
+	// не раскрывать версию на публичном порту
+	hideVersion bool
+	// содержимое /.well-known/security.txt. Если не задано, файл не отдается
+	securityTxt *securitytxt.File
+
+	deps *dependency.Registry
+	// режим только для чтения: эндпоинты, которые пишут данные, отвечают 503
+	readOnly *readonly.Mode
+
+	// прокси, которым разрешено передавать реальный IP клиента в заголовке
+	trustedProxies []string
+	trustedNets    []*net.IPNet
+	realIPHeader   string
+
+	// токен административного API. Если не задан и нет входа через каталог, административные маршруты не регистрируются
+	adminToken string
+	// проверка токенов администраторов, выпущенных после входа через каталог (LDAP)
+	adminValidator adminTokenValidator
+	// проверка токенов пользователей на маршрутах аккаунта. Если не задана, токен проверяет обработчик
+	authn   *token.Validator
+	capture *capture.Capture
+
+	// SHA-256 (hex) токена SCIM. Если не задан, маршруты SCIM не регистрируются
+	scimTokenSHA256 string
+
+	limiter        *ratelimit.Limiter
+	adminRateLimit ratelimit.Rule
+	guestRateLimit ratelimit.Rule
+	observer       *ratelimit.Observer
+	shedder        *loadshed.Shedder
+
+	// учет хороших и плохих запросов по классам эндпоинтов
+	slo *slo.Tracker
+
+	// proof-of-work защита неаутентифицированных эндпоинтов
+	pow       *pow.Service
+	powRoutes []string
+
+	// проверка токенов peer сервисов на внутренних маршрутах
+	peers      *peer.TrustStore
+	peerRoutes []string
+
+	// учет квот API ключей
+	quota *quota.Service
+
+	// выборочное логирование запросов к шумным маршрутам
+	logSampling *logsampling.Sampler
+
+	// denylist и ловушки для обнаружения перебора учетных данных
+	abuse     *abuse.Service
+	honeypots []string
+
+	// устаревшие маршруты: заголовки о снятии и учет обращений
+	deprecations *deprecation.Registry
+
+	// трассировка входящих запросов
+	tracing trace.TracerProvider
+
 	api struct {
 		h0 handler
 	}
@@ -32,7 +123,30 @@ type Server struct {
 //go:generate mockgen -source=server.go -destination=mocks/handler_mock.go -package=mocks handler
 type handler interface {
 	healthHandler
+	drainHandler
+	readOnlyHandler
 	versionHandler
+	captureHandler
+	keyStatsHandler
+	statsHandler
+	tokenHandler
+	groupHandler
+	apiKeyHandler
+	logSamplingHandler
+	svidHandler
+	revocationHandler
+	jobHandler
+	qrLoginHandler
+	passkeyHandler
+	oauthHandler
+	telegramHandler
+	adminLoginHandler
+	scimHandler
+	abuseHandler
+	credentialsHandler
+	emailChangeHandler
+	notificationHandler
+	sessionHandler
 }
 
 type versionHandler interface {
@@ -42,6 +156,154 @@ type versionHandler interface {
 
 type healthHandler interface {
 	Health(c echo.Context) error
+	HealthDetails(c echo.Context) error
+	Liveness(c echo.Context) error
+	Readiness(c echo.Context) error
+}
+
+type drainHandler interface {
+	Ready(c echo.Context) error
+	Drain(c echo.Context) error
+	RunCanary(c echo.Context) error
+}
+
+type readOnlyHandler interface {
+	GetReadOnly(c echo.Context) error
+	EnableReadOnly(c echo.Context) error
+	DisableReadOnly(c echo.Context) error
+}
+
+type keyStatsHandler interface {
+	KeyUsage(c echo.Context) error
+}
+
+type statsHandler interface {
+	Stats(c echo.Context) error
+}
+
+type tokenHandler interface {
+	Introspect(c echo.Context) error
+	RevokeToken(c echo.Context) error
+	Impersonate(c echo.Context) error
+	AuthzCheck(c echo.Context) error
+	IssueGuestToken(c echo.Context) error
+	UpgradeGuestToken(c echo.Context) error
+	ExportVerificationBundle(c echo.Context) error
+	RefreshToken(c echo.Context) error
+	GetRefreshFamily(c echo.Context) error
+	RevokeRefreshFamily(c echo.Context) error
+}
+
+type groupHandler interface {
+	CreateGroup(c echo.Context) error
+	GetGroup(c echo.Context) error
+	DeleteGroup(c echo.Context) error
+	SetGroupMember(c echo.Context) error
+	RemoveGroupMember(c echo.Context) error
+	UserGroups(c echo.Context) error
+	CheckGroupAccess(c echo.Context) error
+}
+
+type apiKeyHandler interface {
+	CreateAPIKey(c echo.Context) error
+	APIKeyUsage(c echo.Context) error
+	GetAPIKeyRateLimit(c echo.Context) error
+	UpdateAPIKeyRateLimit(c echo.Context) error
+	ResetAPIKeyRateLimit(c echo.Context) error
+}
+
+type revocationHandler interface {
+	RevokeUserSessions(c echo.Context) error
+	DeactivateUser(c echo.Context) error
+	ReactivateUser(c echo.Context) error
+	CheckDeactivations(c echo.Context) error
+}
+
+type jobHandler interface {
+	GetJob(c echo.Context) error
+}
+
+type passkeyHandler interface {
+	BeginPasskeyRegistration(c echo.Context) error
+	FinishPasskeyRegistration(c echo.Context) error
+	BeginPasskeyLogin(c echo.Context) error
+	FinishPasskeyLogin(c echo.Context) error
+}
+
+type oauthHandler interface {
+	StartOAuth(c echo.Context) error
+	OAuthCallback(c echo.Context) error
+}
+
+type telegramHandler interface {
+	TelegramLogin(c echo.Context) error
+}
+
+type adminLoginHandler interface {
+	AdminLogin(c echo.Context) error
+}
+
+type scimHandler interface {
+	CreateSCIMUser(c echo.Context) error
+	ListSCIMUsers(c echo.Context) error
+	GetSCIMUser(c echo.Context) error
+	ReplaceSCIMUser(c echo.Context) error
+	PatchSCIMUser(c echo.Context) error
+	DeleteSCIMUser(c echo.Context) error
+}
+
+type credentialsHandler interface {
+	CheckCredentials(c echo.Context) error
+}
+
+type notificationHandler interface {
+	GetNotificationPreferences(c echo.Context) error
+	UpdateNotificationPreferences(c echo.Context) error
+	SendNotification(c echo.Context) error
+}
+
+type sessionHandler interface {
+	ListSessions(c echo.Context) error
+	RevokeSession(c echo.Context) error
+	Logout(c echo.Context) error
+	LogoutAll(c echo.Context) error
+}
+
+type emailChangeHandler interface {
+	GetEmailChange(c echo.Context) error
+	StartEmailChange(c echo.Context) error
+	CancelEmailChange(c echo.Context) error
+	ConfirmEmailChange(c echo.Context) error
+}
+
+type abuseHandler interface {
+	ListBans(c echo.Context) error
+	CreateBan(c echo.Context) error
+	DeleteBan(c echo.Context) error
+}
+
+type qrLoginHandler interface {
+	StartQRLogin(c echo.Context) error
+	ConfirmQRLogin(c echo.Context) error
+	ClaimQRLogin(c echo.Context) error
+}
+
+type svidHandler interface {
+	IssueX509SVID(c echo.Context) error
+	IssueJWTSVID(c echo.Context) error
+	SVIDBundle(c echo.Context) error
+}
+
+type captureHandler interface {
+	GetCapture(c echo.Context) error
+	UpdateCapture(c echo.Context) error
+	ClearCapture(c echo.Context) error
+}
+
+type logSamplingHandler interface {
+	GetLogSampling(c echo.Context) error
+	UpdateLogSampling(c echo.Context) error
+	ResetLogSampling(c echo.Context) error
 }
 
 // Option - опция для настройки сервера.
@@ -61,6 +323,66 @@ func WithShutdownTimeout(shutdownTimeout time.Duration) Option {
 	}
 }
 
+// WithTLSConfig - включает TLS. Сертификат может подменяться на лету через tls.Config.GetCertificate.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(s *Server) {
+		s.tlsConfig = cfg
+	}
+}
+
+// WithReusePort - включает SO_REUSEPORT, чтобы новый процесс мог слушать порт одновременно со старым.
+func WithReusePort(enabled bool) Option {
+	return func(s *Server) {
+		s.reusePort = enabled
+	}
+}
+
+// WithSocketActivation - включает использование сокета, переданного systemd (socket activation).
+// Если сокет не передан, сервер сам открывает порт.
+func WithSocketActivation(enabled bool) Option {
+	return func(s *Server) {
+		s.socketActivation = enabled
+	}
+}
+
+// WithHTTP2 - включает HTTP/2 для HTTPS сервера (ALPN h2).
+func WithHTTP2(enabled bool) Option {
+	return func(s *Server) {
+		s.http2 = enabled
+	}
+}
+
+// WithH2C - включает HTTP/2 без шифрования (h2c, prior knowledge) для HTTP сервера.
+// Предназначено для внутреннего трафика внутри меша.
+func WithH2C(enabled bool) Option {
+	return func(s *Server) {
+		s.h2c = enabled
+	}
+}
+
+// WithDebugPort - включает внутренний отладочный порт с полным /health, метриками и swagger.
+// Метрики и swagger перестают отдаваться на публичном порту.
+func WithDebugPort(port int) Option {
+	return func(s *Server) {
+		s.debugPort = port
+	}
+}
+
+// WithHideVersion - отключает раскрытие версии на публичном порту: swagger не отдается.
+// Ответ /health настраивается в хендлере.
+func WithHideVersion(hide bool) Option {
+	return func(s *Server) {
+		s.hideVersion = hide
+	}
+}
+
+// WithSecurityTxt - включает отдачу /.well-known/security.txt.
+func WithSecurityTxt(f *securitytxt.File) Option {
+	return func(s *Server) {
+		s.securityTxt = f
+	}
+}
+
 // WithHandlerV0 - устанавливает хендлер версии 0.
 func WithHandlerV0(handler handler) Option {
 	return func(s *Server) {
@@ -68,12 +390,206 @@ func WithHandlerV0(handler handler) Option {
 	}
 }
 
+// WithDependencies - устанавливает реестр зависимостей, по которому
+// эндпоинты, требующие недоступных зависимостей, отвечают 503.
+func WithDependencies(deps *dependency.Registry) Option {
+	return func(s *Server) {
+		s.deps = deps
+	}
+}
+
+// WithReadOnly - включает режим только для чтения: пока он действует, эндпоинты,
+// которые пишут данные, отвечают 503, а проверка токенов продолжает работать.
+func WithReadOnly(mode *readonly.Mode) Option {
+	return func(s *Server) {
+		s.readOnly = mode
+	}
+}
+
+// WithTrustedProxies - устанавливает CIDR диапазоны доверенных прокси.
+// Реальный IP клиента берется из X-Forwarded-For только если запрос пришел через доверенный прокси.
+func WithTrustedProxies(cidrs []string) Option {
+	return func(s *Server) {
+		s.trustedProxies = cidrs
+	}
+}
+
+// WithRealIPHeader - устанавливает заголовок, из которого берется реальный IP клиента
+// за доверенным прокси: x-forwarded-for (по умолчанию), x-real-ip или cf-connecting-ip.
+func WithRealIPHeader(header string) Option {
+	return func(s *Server) {
+		s.realIPHeader = header
+	}
+}
+
+// WithAdminToken - устанавливает токен доступа к административному API.
+func WithAdminToken(token string) Option {
+	return func(s *Server) {
+		s.adminToken = token
+	}
+}
+
+// WithAdminValidator - включает вход администраторов через каталог: административное API принимает
+// токены аудитории admin с ролями администратора. validator должен принимать только аудиторию admin
+// (token.Validator.ForAudience).
+func WithAdminValidator(validator adminTokenValidator) Option {
+	return func(s *Server) {
+		s.adminValidator = validator
+	}
+}
+
+// WithSCIMToken - включает SCIM API для корпоративного IdP. tokenSHA256 - SHA-256 (hex) токена IdP.
+func WithSCIMToken(tokenSHA256 string) Option {
+	return func(s *Server) {
+		s.scimTokenSHA256 = tokenSHA256
+	}
+}
+
+// WithCapture - устанавливает хранилище для выборочного захвата тел запросов.
+func WithCapture(c *capture.Capture) Option {
+	return func(s *Server) {
+		s.capture = c
+	}
+}
+
+// WithAdminRateLimit - устанавливает ограничение частоты запросов к административному API с одного IP.
+func WithAdminRateLimit(rule ratelimit.Rule) Option {
+	return func(s *Server) {
+		s.adminRateLimit = rule
+	}
+}
+
+// WithGuestRateLimit - устанавливает ограничение частоты выпуска гостевых токенов с одного IP.
+func WithGuestRateLimit(rule ratelimit.Rule) Option {
+	return func(s *Server) {
+		s.guestRateLimit = rule
+	}
+}
+
+// WithRateLimitObserver - включает режим наблюдения для ограничений частоты запросов:
+// запросы сверх лимита не отклоняются, а только учитываются observer.
+func WithRateLimitObserver(observer *ratelimit.Observer) Option {
+	return func(s *Server) {
+		s.observer = observer
+	}
+}
+
+// WithLoadShedding - включает сброс нагрузки: количество одновременно обрабатываемых запросов
+// ограничивается по классам эндпоинтов (dependency.Class), лишние запросы сразу получают 503.
+func WithLoadShedding(shedder *loadshed.Shedder) Option {
+	return func(s *Server) {
+		s.shedder = shedder
+	}
+}
+
+// WithSLO - включает учет хороших и плохих запросов по классам эндпоинтов (dependency.Class)
+// для оповещений о расходовании бюджета ошибок.
+func WithSLO(tracker *slo.Tracker) Option {
+	return func(s *Server) {
+		s.slo = tracker
+	}
+}
+
+// WithProofOfWork - требует решения proof-of-work задачи для указанных маршрутов (например, /api/v0/otp/request).
+func WithProofOfWork(svc *pow.Service, routes []string) Option {
+	return func(s *Server) {
+		s.pow = svc
+		s.powRoutes = routes
+	}
+}
+
+// WithAuthentication - проверяет токен пользователя один раз за запрос на маршрутах аккаунта
+// и передает claims обработчикам и журналу запросов через контекст. Чтобы маршруты принимали только
+// токены пользователей, validator ограничивается их аудиториями (token.Validator.ForAudience).
+func WithAuthentication(validator *token.Validator) Option {
+	return func(s *Server) {
+		s.authn = validator
+	}
+}
+
+// WithPeerAuth - требует токен доверенного peer сервиса для указанных внутренних маршрутов
+// (например, /api/v0/token/introspect).
+func WithPeerAuth(store *peer.TrustStore, routes []string) Option {
+	return func(s *Server) {
+		s.peers = store
+		s.peerRoutes = routes
+	}
+}
+
+// WithQuota - включает учет квот для запросов с API ключом в заголовке X-API-Key.
+func WithQuota(svc *quota.Service) Option {
+	return func(s *Server) {
+		s.quota = svc
+	}
+}
+
+// WithLogSampling - включает выборочное логирование запросов по маршрутам.
+func WithLogSampling(sampler *logsampling.Sampler) Option {
+	return func(s *Server) {
+		s.logSampling = sampler
+	}
+}
+
+// WithAbuse - включает denylist: запросы заблокированных IP, сетей и ASN отклоняются.
+// Запрос к одному из путей honeypots сразу блокирует IP клиента.
+func WithAbuse(svc *abuse.Service, honeypots []string) Option {
+	return func(s *Server) {
+		s.abuse = svc
+		s.honeypots = honeypots
+	}
+}
+
+// WithDeprecations - помечает устаревшие маршруты: ответы получают заголовки Deprecation, Sunset и Link,
+// обращения учитываются в метриках и журнале. Каждый маршрут реестра должен быть зарегистрирован.
+func WithDeprecations(registry *deprecation.Registry) Option {
+	return func(s *Server) {
+		s.deprecations = registry
+	}
+}
+
+// WithTracing - создает серверный спан на каждый запрос, продолжая трассировку вызывающей стороны
+// (заголовок traceparent).
+func WithTracing(provider trace.TracerProvider) Option {
+	return func(s *Server) {
+		s.tracing = provider
+	}
+}
+
 // New - создает новый сервер. Принимает опции для настройки сервера.
 // Доступные опции:
 //
 //   - WithPort - устанавливает порт сервера.
 //   - WithHandlerV0 - устанавливает хендлер версии 0.
 //   - WithShutdownTimeout - устанавливает таймаут graceful shutdown.
+//   - WithTLSConfig - включает TLS (опционально).
+//   - WithReusePort - включает SO_REUSEPORT (опционально).
+//   - WithSocketActivation - включает использование сокета от systemd (опционально).
+//   - WithHTTP2 - включает HTTP/2 для HTTPS (опционально).
+//   - WithH2C - включает h2c для HTTP (опционально).
+//   - WithDebugPort - включает внутренний отладочный порт (опционально).
+//   - WithHideVersion - отключает раскрытие версии на публичном порту (опционально).
+//   - WithSecurityTxt - включает отдачу security.txt (опционально).
+//   - WithDependencies - устанавливает реестр зависимостей (опционально).
+//   - WithReadOnly - включает режим только для чтения (опционально).
+//   - WithTrustedProxies - устанавливает доверенные прокси (опционально).
+//   - WithRealIPHeader - устанавливает заголовок с реальным IP клиента (опционально).
+//   - WithAdminToken - включает административное API (опционально).
+//   - WithAdminValidator - включает вход администраторов через каталог (опционально).
+//   - WithSCIMToken - включает SCIM API для корпоративного IdP (опционально).
+//   - WithCapture - включает выборочный захват тел запросов (опционально).
+//   - WithAdminRateLimit - ограничивает частоту запросов к административному API (опционально).
+//   - WithGuestRateLimit - ограничивает частоту выпуска гостевых токенов (опционально).
+//   - WithRateLimitObserver - включает режим наблюдения для ограничений частоты (опционально).
+//   - WithLoadShedding - включает сброс нагрузки по классам эндпоинтов (опционально).
+//   - WithSLO - включает учет SLI по классам эндпоинтов (опционально).
+//   - WithProofOfWork - включает proof-of-work защиту маршрутов (опционально).
+//   - WithAuthentication - включает проверку токенов пользователей в middleware (опционально).
+//   - WithPeerAuth - включает проверку токенов peer сервисов на внутренних маршрутах (опционально).
+//   - WithQuota - включает учет квот API ключей (опционально).
+//   - WithLogSampling - включает выборочное логирование запросов (опционально).
+//   - WithAbuse - включает denylist и ловушки (опционально).
+//   - WithDeprecations - помечает устаревшие маршруты (опционально).
+//   - WithTracing - включает трассировку запросов (опционально).
 func New(opts ...Option) (*Server, error) {
 	s := &Server{}
 	for _, opt := range opts {
@@ -92,10 +608,32 @@ func New(opts ...Option) (*Server, error) {
 		return nil, fmt.Errorf("shutdown timeout is required")
 	}
 
+	if s.debugPort != 0 && s.debugPort == s.port {
+		return nil, fmt.Errorf("debug port must differ from server port %d", s.port)
+	}
+
 	if !checkHandlerVersion(s.api.h0, handlerV0.Version0) {
 		return nil, fmt.Errorf("expected handler version is %s, got %s", handlerV0.Version0, s.api.h0.Version())
 	}
 
+	for _, cidr := range s.trustedProxies {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+
+		s.trustedNets = append(s.trustedNets, ipNet)
+	}
+
+	if !isKnownRealIPHeader(s.realIPHeader) {
+		return nil, fmt.Errorf("unknown real ip header: %s", s.realIPHeader)
+	}
+
+	// ограничение частоты запросов API ключей задается в записи ключа, поэтому с квотами счетчики нужны всегда
+	if s.adminRateLimit.Enabled() || s.guestRateLimit.Enabled() || s.quota != nil {
+		s.limiter = ratelimit.New()
+	}
+
 	return s, nil
 }
 
@@ -110,17 +648,52 @@ func (s *Server) Start(ctx context.Context) error {
 		return err
 	}
 
+	return s.run(ctx)
+}
+
+// run запускает HTTP или HTTPS сервер с уже созданными маршрутами и останавливает его при отмене контекста.
+func (s *Server) run(ctx context.Context) error {
+	l, err := s.listen(ctx)
+	if err != nil {
+		return err
+	}
+
+	addr := fmt.Sprintf(":%d", s.port)
+
+	if s.tlsConfig == nil {
+		s.e.Server.Protocols = s.protocols()
+		s.e.Listener = l
+	} else {
+		tlsConfig := s.serverTLSConfig()
+
+		s.e.TLSServer.Addr = addr
+		s.e.TLSServer.TLSConfig = tlsConfig
+		s.e.TLSServer.Protocols = s.protocols()
+		s.e.TLSListener = tls.NewListener(l, tlsConfig)
+	}
+
 	// запускаем сервер в отдельной горутине
-	errChan := make(chan error, 1)
+	errChan := make(chan error, 2)
+
+	s.startDebug(errChan)
 
 	go func() {
-		errChan <- s.e.Start(fmt.Sprintf(":%d", s.port))
+		if s.tlsConfig == nil {
+			errChan <- s.e.Start(addr)
+
+			return
+		}
+
+		errChan <- s.e.StartServer(s.e.TLSServer)
 	}()
 
 	// ждем либо ошибку запуска, либо отмену контекста
 	select {
 	case err := <-errChan:
-		return err
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.shutdownTimeout)
+		defer cancel()
+
+		return errors.Join(err, s.e.Shutdown(shutdownCtx), s.shutdownDebug(shutdownCtx))
 	case <-ctx.Done():
 		// контекст отменен - делаем graceful shutdown
 		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.shutdownTimeout)
@@ -131,39 +704,268 @@ func (s *Server) Start(ctx context.Context) error {
 			"shutdownTimeout": s.shutdownTimeout,
 		}).Info("shutting down server")
 
-		return s.e.Shutdown(shutdownCtx)
+		return errors.Join(s.e.Shutdown(shutdownCtx), s.shutdownDebug(shutdownCtx))
+	}
+}
+
+// protocols возвращает протоколы, которые принимает сервер.
+// HTTP/1.1 доступен всегда, HTTP/2 - если включен для соответствующего режима.
+func (s *Server) protocols() *http.Protocols {
+	p := &http.Protocols{}
+	p.SetHTTP1(true)
+
+	if s.tlsConfig != nil {
+		p.SetHTTP2(s.http2)
+	} else {
+		p.SetUnencryptedHTTP2(s.h2c)
+	}
+
+	return p
+}
+
+// serverTLSConfig возвращает конфигурацию TLS с протоколами для ALPN.
+// Слушающий сокет создается сервером, поэтому net/http сам не добавляет h2 в NextProtos.
+func (s *Server) serverTLSConfig() *tls.Config {
+	cfg := s.tlsConfig.Clone()
+
+	if s.http2 {
+		cfg.NextProtos = []string{"h2", "http/1.1"}
+	} else {
+		cfg.NextProtos = []string{"http/1.1"}
+	}
+
+	return cfg
+}
+
+// rateLimit возвращает middleware ограничения частоты запросов для группы эндпоинтов.
+// Если правило не задано, запросы не ограничиваются. В режиме наблюдения превышения только учитываются.
+func (s *Server) rateLimit(group string, rule ratelimit.Rule) echo.MiddlewareFunc {
+	if !rule.Enabled() || s.limiter == nil {
+		return func(next echo.HandlerFunc) echo.HandlerFunc { return next }
+	}
+
+	return serverMiddleware.RateLimit(s.limiter, group, rule, s.observer)
+}
+
+// logSkipper возвращает функцию, которая пропускает запись в access log в соответствии
+// с долями логируемых запросов по маршрутам. Без настроек логируются все запросы.
+func (s *Server) logSkipper() middleware.Skipper {
+	if s.logSampling == nil {
+		return middleware.DefaultSkipper
+	}
+
+	return func(c echo.Context) bool {
+		return !s.logSampling.ShouldLog(c.Path())
+	}
+}
+
+// requestLogFormat - формат журнала запросов echo с субъектом аутентифицированного запроса.
+func requestLogFormat() string {
+	return strings.TrimSuffix(middleware.DefaultLoggerConfig.Format, "}\n") + `,"subject":"${custom}"}` + "\n"
+}
+
+// authenticate возвращает middleware проверки токена пользователя. Если проверка не включена,
+// запрос передается обработчику без изменений.
+func (s *Server) authenticate() echo.MiddlewareFunc {
+	if s.authn == nil {
+		return func(next echo.HandlerFunc) echo.HandlerFunc { return next }
+	}
+
+	return serverMiddleware.Authenticate(s.authn)
+}
+
+// apiKeyUsagePath - маршрут просмотра использования квот, сам он квоту не расходует.
+const apiKeyUsagePath = "/api/v0/apikeys/:id/usage"
+
+// registerAPIRoutes регистрирует маршруты API всех версий.
+func (s *Server) registerAPIRoutes(e *echo.Echo) {
+	api := e.Group("api/")
+
+	// v0
+	apiv0 := api.Group("v0/")
+
+	apiv0.GET("health", s.api.h0.Health, s.requires(dependency.ClassInfo))
+	apiv0.GET("ready", s.api.h0.Ready)
+	apiv0.POST("token/introspect", s.api.h0.Introspect, s.requires(dependency.ClassValidation))
+	apiv0.POST("token/revoke", s.api.h0.RevokeToken, s.requires(dependency.ClassSession))
+	apiv0.POST("token/guest", s.api.h0.IssueGuestToken, s.rateLimit("guest", s.guestRateLimit), s.requires(dependency.ClassIssuance))
+	apiv0.POST("token/refresh", s.api.h0.RefreshToken, s.requires(dependency.ClassIssuance))
+	apiv0.POST("token/guest/upgrade", s.api.h0.UpgradeGuestToken, s.requires(dependency.ClassIssuance), s.authenticate())
+	apiv0.POST("authz/check", s.api.h0.AuthzCheck, s.requires(dependency.ClassValidation))
+	apiv0.GET("apikeys/:id/usage", s.api.h0.APIKeyUsage, s.requires(dependency.ClassSession))
+	apiv0.POST("svid/x509", s.api.h0.IssueX509SVID, s.requires(dependency.ClassIssuance))
+	apiv0.POST("svid/jwt", s.api.h0.IssueJWTSVID, s.requires(dependency.ClassIssuance))
+	apiv0.GET("svid/bundle", s.api.h0.SVIDBundle)
+	apiv0.POST("qr-login", s.api.h0.StartQRLogin, s.requires(dependency.ClassSession))
+	apiv0.POST("qr-login/:code/confirm", s.api.h0.ConfirmQRLogin, s.requires(dependency.ClassSession), s.authenticate())
+	apiv0.POST("qr-login/:code/token", s.api.h0.ClaimQRLogin, s.requires(dependency.ClassIssuance))
+	apiv0.POST("webauthn/register/begin", s.api.h0.BeginPasskeyRegistration, s.requires(dependency.ClassSession), s.authenticate())
+	apiv0.POST("webauthn/register/finish", s.api.h0.FinishPasskeyRegistration, s.requires(dependency.ClassSession), s.authenticate())
+	apiv0.POST("webauthn/login/begin", s.api.h0.BeginPasskeyLogin, s.requires(dependency.ClassSession))
+	apiv0.POST("webauthn/login/finish", s.api.h0.FinishPasskeyLogin, s.requires(dependency.ClassIssuance))
+	apiv0.GET("oauth/:provider/start", s.api.h0.StartOAuth, s.requires(dependency.ClassSession))
+	apiv0.GET("oauth/:provider/callback", s.api.h0.OAuthCallback, s.requires(dependency.ClassIssuance))
+	apiv0.POST("auth/telegram", s.api.h0.TelegramLogin, s.requires(dependency.ClassIssuance))
+	apiv0.POST("auth/logout", s.api.h0.Logout, s.requires(dependency.ClassSession), s.authenticate())
+	apiv0.POST("auth/logout_all", s.api.h0.LogoutAll, s.requires(dependency.ClassSession), s.authenticate())
+	apiv0.POST("credentials/check", s.api.h0.CheckCredentials)
+	apiv0.GET("account/email", s.api.h0.GetEmailChange, s.requires(dependency.ClassSession), s.authenticate())
+	apiv0.POST("account/email", s.api.h0.StartEmailChange, s.requires(dependency.ClassSession), s.authenticate())
+	apiv0.DELETE("account/email", s.api.h0.CancelEmailChange, s.requires(dependency.ClassSession), s.authenticate())
+	apiv0.POST("account/email/confirm", s.api.h0.ConfirmEmailChange, s.requires(dependency.ClassSession), s.authenticate())
+	apiv0.GET("account/notifications", s.api.h0.GetNotificationPreferences, s.requires(dependency.ClassSession), s.authenticate())
+	apiv0.PUT("account/notifications", s.api.h0.UpdateNotificationPreferences, s.requires(dependency.ClassSession), s.authenticate())
+	apiv0.GET("sessions", s.api.h0.ListSessions, s.requires(dependency.ClassSession), s.authenticate())
+	apiv0.DELETE("sessions/:id", s.api.h0.RevokeSession, s.requires(dependency.ClassSession), s.authenticate())
+
+	if s.adminValidator != nil {
+		apiv0.POST("admin/login", s.api.h0.AdminLogin, s.rateLimit("admin", s.adminRateLimit))
+	}
+
+	if s.adminEnabled() {
+		admin := apiv0.Group("admin/", s.rateLimit("admin", s.adminRateLimit), s.adminAuth())
+
+		admin.GET("capture", s.api.h0.GetCapture)
+		admin.PUT("capture", s.api.h0.UpdateCapture)
+		admin.DELETE("capture", s.api.h0.ClearCapture)
+
+		admin.GET("keys/usage", s.api.h0.KeyUsage)
+		admin.GET("stats", s.api.h0.Stats, s.requires(dependency.ClassSession))
+		admin.POST("drain", s.api.h0.Drain)
+		admin.GET("read-only", s.api.h0.GetReadOnly)
+		admin.PUT("read-only", s.api.h0.EnableReadOnly)
+		admin.DELETE("read-only", s.api.h0.DisableReadOnly)
+		admin.GET("verification-bundle", s.api.h0.ExportVerificationBundle, s.requires(dependency.ClassIssuance))
+		admin.POST("canary", s.api.h0.RunCanary, s.requires(dependency.ClassIssuance))
+
+		admin.POST("apikeys", s.api.h0.CreateAPIKey, s.requires(dependency.ClassSession))
+		admin.GET("apikeys/:id/rate-limit", s.api.h0.GetAPIKeyRateLimit, s.requires(dependency.ClassSession))
+		admin.PUT("apikeys/:id/rate-limit", s.api.h0.UpdateAPIKeyRateLimit, s.requires(dependency.ClassSession))
+		admin.DELETE("apikeys/:id/rate-limit", s.api.h0.ResetAPIKeyRateLimit, s.requires(dependency.ClassSession))
+
+		admin.GET("log-sampling", s.api.h0.GetLogSampling)
+		admin.PUT("log-sampling", s.api.h0.UpdateLogSampling, s.requires(dependency.ClassSession))
+		admin.DELETE("log-sampling", s.api.h0.ResetLogSampling, s.requires(dependency.ClassSession))
+
+		admin.POST("impersonate", s.api.h0.Impersonate, s.requires(dependency.ClassIssuance))
+
+		groups := admin.Group("", s.requires(dependency.ClassSession))
+		groups.POST("groups", s.api.h0.CreateGroup)
+		groups.GET("groups/:id", s.api.h0.GetGroup)
+		groups.DELETE("groups/:id", s.api.h0.DeleteGroup)
+		groups.PUT("groups/:id/members/:user", s.api.h0.SetGroupMember)
+		groups.DELETE("groups/:id/members/:user", s.api.h0.RemoveGroupMember)
+		groups.GET("groups/:id/check", s.api.h0.CheckGroupAccess)
+		groups.GET("users/:user/groups", s.api.h0.UserGroups)
+
+		admin.DELETE("users/:id/sessions", s.api.h0.RevokeUserSessions, s.requires(dependency.ClassSession))
+		admin.PUT("users/:id/deactivation", s.api.h0.DeactivateUser, s.requires(dependency.ClassSession))
+		admin.DELETE("users/:id/deactivation", s.api.h0.ReactivateUser, s.requires(dependency.ClassSession))
+		admin.POST("deactivations/check", s.api.h0.CheckDeactivations, s.requires(dependency.ClassSession))
+		admin.POST("users/:id/notifications", s.api.h0.SendNotification, s.requires(dependency.ClassSession))
+		admin.GET("jobs/:id", s.api.h0.GetJob, s.requires(dependency.ClassSession))
+		admin.GET("refresh-families/:id", s.api.h0.GetRefreshFamily, s.requires(dependency.ClassSession))
+		admin.DELETE("refresh-families/:id", s.api.h0.RevokeRefreshFamily, s.requires(dependency.ClassSession))
+
+		admin.GET("bans", s.api.h0.ListBans, s.requires(dependency.ClassSession))
+		admin.PUT("bans", s.api.h0.CreateBan, s.requires(dependency.ClassSession))
+		admin.DELETE("bans", s.api.h0.DeleteBan, s.requires(dependency.ClassSession))
+	}
+
+	if s.scimTokenSHA256 != "" {
+		users := apiv0.Group("scim/v2/Users", s.scimAuth(), s.requires(dependency.ClassSession))
+		users.POST("", s.api.h0.CreateSCIMUser)
+		users.GET("", s.api.h0.ListSCIMUsers)
+		users.GET("/:id", s.api.h0.GetSCIMUser)
+		users.PUT("/:id", s.api.h0.ReplaceSCIMUser)
+		users.PATCH("/:id", s.api.h0.PatchSCIMUser)
+		users.DELETE("/:id", s.api.h0.DeleteSCIMUser)
 	}
 }
 
 func (s *Server) createRoutes() error {
 	e := echo.New()
-
-	// Swagger UI route - must be registered before other middleware
-	e.GET("/swagger/*", echoSwagger.WrapHandler)
+	e.IPExtractor = s.ipExtractor()
 
 	skipper := func(c echo.Context) bool {
 		return strings.Contains(c.Request().URL.Path, "swagger")
 	}
 
+	// снаружи recover, чтобы паника учитывалась как ответ 500
+	if s.slo != nil {
+		e.Use(serverMiddleware.SLO(s.slo, routeClass))
+	}
+
 	e.Use(middleware.RecoverWithConfig(middleware.RecoverConfig{Skipper: skipper}))
-	e.Use(middleware.Logger())
+	e.Use(serverMiddleware.Mesh())
+
+	if s.tracing != nil {
+		e.Use(serverMiddleware.Tracing(s.tracing))
+	}
 
-	e.Use(echoprometheus.NewMiddleware("webserver")) // adds middleware to gather metrics
-	e.GET("/metrics", echoprometheus.NewHandler())   // adds route to serve gathered metrics
+	e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
+		Skipper:       s.logSkipper(),
+		Format:        requestLogFormat(),
+		CustomTagFunc: serverMiddleware.LogSubject,
+	}))
 
-	api := e.Group("api/")
+	if s.abuse != nil {
+		e.Use(serverMiddleware.Denylist(s.abuse))
 
-	// v0
-	apiv0 := api.Group("v0/")
+		if len(s.honeypots) > 0 {
+			e.Use(serverMiddleware.Honeypot(s.abuse, s.honeypots))
+		}
+	}
+
+	if s.capture != nil {
+		e.Use(serverMiddleware.Capture(s.capture))
+	}
+
+	if s.pow != nil && len(s.powRoutes) > 0 {
+		e.Use(serverMiddleware.ProofOfWork(s.pow, s.powRoutes))
+	}
+
+	if s.peers != nil && len(s.peerRoutes) > 0 {
+		e.Use(serverMiddleware.PeerAuth(s.peers, s.peerRoutes))
+	}
+
+	if s.quota != nil {
+		e.Use(serverMiddleware.Quota(s.quota, s.limiter, s.observer, apiKeyUsagePath))
+	}
+
+	if s.deprecations != nil {
+		e.Use(serverMiddleware.Deprecation(s.deprecations))
+	}
+
+	metrics, err := metricsMiddleware(prometheus.DefaultRegisterer)
+	if err != nil {
+		return fmt.Errorf("error create metrics middleware: %w", err)
+	}
+
+	e.Use(metrics) // adds middleware to gather metrics
 
-	apiv0.GET("health", s.api.h0.Health)
+	s.registerInfoRoutes(e)
+	s.registerAPIRoutes(e)
 
 	s.e = e
 
+	if s.debugPort != 0 {
+		s.createDebugRoutes()
+	}
+
 	if len(s.e.Routes()) == 0 {
 		return errors.New("no routes initialized")
 	}
 
+	if err := s.checkDeprecations(); err != nil {
+		return err
+	}
+
+	// все метрики к этому моменту зарегистрированы: конфликт лучше увидеть при запуске, а не в пустом /metrics
+	if err := metricguard.SelfCheck(prometheus.DefaultGatherer); err != nil {
+		return err
+	}
+
 	logrus.WithFields(logrus.Fields{
 		"routes": len(s.e.Routes()),
 		"port":   s.port,
@@ -171,3 +973,24 @@ func (s *Server) createRoutes() error {
 
 	return nil
 }
+
+// checkDeprecations проверяет, что устаревшие маршруты зарегистрированы: опечатку в конфигурации
+// лучше увидеть при запуске, чем не получить заголовки о снятии.
+func (s *Server) checkDeprecations() error {
+	if s.deprecations == nil {
+		return nil
+	}
+
+	registered := make(map[string]struct{}, len(s.e.Routes()))
+	for _, r := range s.e.Routes() {
+		registered[r.Method+" "+r.Path] = struct{}{}
+	}
+
+	for _, r := range s.deprecations.Routes() {
+		if _, ok := registered[r.Method+" "+r.Path]; !ok {
+			return fmt.Errorf("deprecated route %s %s is not registered", r.Method, r.Path)
+		}
+	}
+
+	return nil
+}
@@ -3,13 +3,32 @@ package server
 import (
 	handlerV0 "auth-service/internal/api/v0"
 	"auth-service/internal/server/mocks"
+	"auth-service/internal/service/deprecation"
+	"auth-service/internal/service/logsampling"
+	"auth-service/internal/service/peer"
+	"auth-service/internal/service/pow"
+	"auth-service/internal/service/token"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"math/big"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -18,6 +37,11 @@ import (
 func TestNewServer(t *testing.T) {
 	t.Parallel()
 
+	testPoW, err := pow.New()
+	require.NoError(t, err)
+
+	testPeers := &peer.TrustStore{}
+
 	tests := []struct {
 		name       string
 		createOpts func(t *testing.T, mockHandler *mocks.Mockhandler) []Option
@@ -50,6 +74,64 @@ func TestNewServer(t *testing.T) {
 			},
 			wantErr: require.NoError,
 		},
+		{
+			name: "positive case: with proof of work",
+			createOpts: func(t *testing.T, mockHandler *mocks.Mockhandler) []Option {
+				t.Helper()
+
+				mockHandler.EXPECT().Version().Return("v0")
+
+				return []Option{
+					WithPort(8080),
+					WithShutdownTimeout(100 * time.Millisecond),
+					WithHandlerV0(mockHandler),
+					WithProofOfWork(testPoW, []string{"/api/v0/otp/request"}),
+				}
+			},
+			createWant: func(t *testing.T, mockHandler *mocks.Mockhandler) *Server {
+				t.Helper()
+
+				return &Server{
+					port:            8080,
+					shutdownTimeout: 100 * time.Millisecond,
+					pow:             testPoW,
+					powRoutes:       []string{"/api/v0/otp/request"},
+					api: struct {
+						h0 handler
+					}{h0: mockHandler},
+				}
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: with peer auth",
+			createOpts: func(t *testing.T, mockHandler *mocks.Mockhandler) []Option {
+				t.Helper()
+
+				mockHandler.EXPECT().Version().Return("v0")
+
+				return []Option{
+					WithPort(8080),
+					WithShutdownTimeout(100 * time.Millisecond),
+					WithHandlerV0(mockHandler),
+					WithPeerAuth(testPeers, []string{"/api/v0/token/introspect"}),
+				}
+			},
+			createWant: func(t *testing.T, mockHandler *mocks.Mockhandler) *Server {
+				t.Helper()
+
+				return &Server{
+					port:            8080,
+					shutdownTimeout: 100 * time.Millisecond,
+					peers:           testPeers,
+					peerRoutes:      []string{"/api/v0/token/introspect"},
+					api: struct {
+						h0 handler
+					}{h0: mockHandler},
+				}
+			},
+			wantErr: require.NoError,
+		},
 		{
 			name: "error case: handler is required",
 			createOpts: func(t *testing.T, mockHandler *mocks.Mockhandler) []Option {
@@ -98,6 +180,44 @@ func TestNewServer(t *testing.T) {
 				require.ErrorContains(t, err, "port is required")
 			},
 		},
+		{
+			name: "error case: invalid trusted proxy",
+			createOpts: func(t *testing.T, mockHandler *mocks.Mockhandler) []Option {
+				t.Helper()
+
+				mockHandler.EXPECT().Version().Return("v0")
+
+				return []Option{
+					WithPort(8080),
+					WithShutdownTimeout(100 * time.Millisecond),
+					WithHandlerV0(mockHandler),
+					WithTrustedProxies([]string{"10.0.0.1"}),
+				}
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.Error(t, err)
+				require.ErrorContains(t, err, "invalid trusted proxy")
+			},
+		},
+		{
+			name: "error case: unknown real ip header",
+			createOpts: func(t *testing.T, mockHandler *mocks.Mockhandler) []Option {
+				t.Helper()
+
+				mockHandler.EXPECT().Version().Return("v0")
+
+				return []Option{
+					WithPort(8080),
+					WithShutdownTimeout(100 * time.Millisecond),
+					WithHandlerV0(mockHandler),
+					WithRealIPHeader("forwarded"),
+				}
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.Error(t, err)
+				require.ErrorContains(t, err, "unknown real ip header")
+			},
+		},
 		{
 			name: "error case: shutdown timeout is required",
 			createOpts: func(t *testing.T, mockHandler *mocks.Mockhandler) []Option {
@@ -113,6 +233,23 @@ func TestNewServer(t *testing.T) {
 				require.ErrorContains(t, err, "shutdown timeout is required")
 			},
 		},
+		{
+			name: "negative case: debug port equals server port",
+			createOpts: func(t *testing.T, mockHandler *mocks.Mockhandler) []Option {
+				t.Helper()
+
+				return []Option{
+					WithPort(8080),
+					WithDebugPort(8080),
+					WithShutdownTimeout(100 * time.Millisecond),
+					WithHandlerV0(mockHandler),
+				}
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.Error(t, err)
+				require.ErrorContains(t, err, "debug port must differ from server port")
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -162,10 +299,180 @@ func TestCreateRoutes(t *testing.T) {
 			Path:   "/api/v0/health",
 			Name:   "webserver/internal/server.handler.Health-fm",
 		},
+		{
+			Method: http.MethodGet,
+			Path:   "/api/v0/ready",
+			Name:   "webserver/internal/server.handler.Ready-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/token/introspect",
+			Name:   "webserver/internal/server.handler.Introspect-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/token/revoke",
+			Name:   "webserver/internal/server.handler.RevokeToken-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/token/guest",
+			Name:   "webserver/internal/server.handler.IssueGuestToken-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/token/guest/upgrade",
+			Name:   "webserver/internal/server.handler.UpgradeGuestToken-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/token/refresh",
+			Name:   "webserver/internal/server.handler.RefreshToken-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/authz/check",
+			Name:   "webserver/internal/server.handler.AuthzCheck-fm",
+		},
+		{
+			Method: http.MethodGet,
+			Path:   "/api/v0/apikeys/:id/usage",
+			Name:   "webserver/internal/server.handler.APIKeyUsage-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/svid/x509",
+			Name:   "webserver/internal/server.handler.IssueX509SVID-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/svid/jwt",
+			Name:   "webserver/internal/server.handler.IssueJWTSVID-fm",
+		},
+		{
+			Method: http.MethodGet,
+			Path:   "/api/v0/svid/bundle",
+			Name:   "webserver/internal/server.handler.SVIDBundle-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/qr-login",
+			Name:   "webserver/internal/server.handler.StartQRLogin-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/qr-login/:code/confirm",
+			Name:   "webserver/internal/server.handler.ConfirmQRLogin-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/qr-login/:code/token",
+			Name:   "webserver/internal/server.handler.ClaimQRLogin-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/webauthn/register/begin",
+			Name:   "webserver/internal/server.handler.BeginPasskeyRegistration-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/webauthn/register/finish",
+			Name:   "webserver/internal/server.handler.FinishPasskeyRegistration-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/webauthn/login/begin",
+			Name:   "webserver/internal/server.handler.BeginPasskeyLogin-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/webauthn/login/finish",
+			Name:   "webserver/internal/server.handler.FinishPasskeyLogin-fm",
+		},
+		{
+			Method: http.MethodGet,
+			Path:   "/api/v0/oauth/:provider/start",
+			Name:   "webserver/internal/server.handler.StartOAuth-fm",
+		},
+		{
+			Method: http.MethodGet,
+			Path:   "/api/v0/oauth/:provider/callback",
+			Name:   "webserver/internal/server.handler.OAuthCallback-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/auth/telegram",
+			Name:   "webserver/internal/server.handler.TelegramLogin-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/credentials/check",
+			Name:   "webserver/internal/server.handler.CheckCredentials-fm",
+		},
+		{
+			Method: http.MethodGet,
+			Path:   "/api/v0/account/email",
+			Name:   "webserver/internal/server.handler.GetEmailChange-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/account/email",
+			Name:   "webserver/internal/server.handler.StartEmailChange-fm",
+		},
+		{
+			Method: http.MethodDelete,
+			Path:   "/api/v0/account/email",
+			Name:   "webserver/internal/server.handler.CancelEmailChange-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/account/email/confirm",
+			Name:   "webserver/internal/server.handler.ConfirmEmailChange-fm",
+		},
+		{
+			Method: http.MethodGet,
+			Path:   "/api/v0/account/notifications",
+			Name:   "webserver/internal/server.handler.GetNotificationPreferences-fm",
+		},
+		{
+			Method: http.MethodPut,
+			Path:   "/api/v0/account/notifications",
+			Name:   "webserver/internal/server.handler.UpdateNotificationPreferences-fm",
+		},
+		{
+			Method: http.MethodGet,
+			Path:   "/api/v0/sessions",
+			Name:   "webserver/internal/server.handler.ListSessions-fm",
+		},
+		{
+			Method: http.MethodDelete,
+			Path:   "/api/v0/sessions/:id",
+			Name:   "webserver/internal/server.handler.RevokeSession-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/auth/logout",
+			Name:   "webserver/internal/server.handler.Logout-fm",
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/auth/logout_all",
+			Name:   "webserver/internal/server.handler.LogoutAll-fm",
+		},
+		{
+			Method: http.MethodGet,
+			Path:   "/healthz",
+			Name:   "webserver/internal/server.handler.Liveness-fm",
+		},
+		{
+			Method: http.MethodGet,
+			Path:   "/readyz",
+			Name:   "webserver/internal/server.handler.Readiness-fm",
+		},
 		{
 			Method: http.MethodGet,
 			Path:   "/metrics",
-			Name:   "github.com/labstack/echo-contrib/echoprometheus.NewHandler",
+			Name:   "github.com/labstack/echo/v4.WrapHandler.func1",
 		},
 		{
 			Method: http.MethodGet,
@@ -181,7 +488,7 @@ func TestCreateRoutes(t *testing.T) {
 	// not found routes
 	notFound := []string{}
 
-	// path: method
+	// method path: method
 	expRoutesMap := routesMap(t, expectedRoutes)
 
 	for expectedPath, expectedMethod := range expRoutesMap {
@@ -197,6 +504,192 @@ func TestCreateRoutes(t *testing.T) {
 	}
 }
 
+func TestCreateRoutes_Admin(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h := mocks.NewMockhandler(ctrl)
+	h.EXPECT().Version().Return("v0").Times(1)
+
+	server, err := New(
+		WithPort(8080),
+		WithShutdownTimeout(100*time.Millisecond),
+		WithHandlerV0(h),
+		WithAdminToken("admin-token"),
+	)
+	require.NoError(t, err)
+
+	e := echo.New()
+	server.registerAPIRoutes(e)
+
+	adminRoutes := map[string]bool{}
+
+	for _, r := range e.Routes() {
+		if strings.HasPrefix(r.Path, "/api/v0/admin/") && r.Method != echo.RouteNotFound {
+			adminRoutes[r.Method+" "+r.Path] = true
+		}
+	}
+
+	assert.Equal(t, map[string]bool{
+		"GET /api/v0/admin/capture":    true,
+		"PUT /api/v0/admin/capture":    true,
+		"DELETE /api/v0/admin/capture": true,
+		"GET /api/v0/admin/keys/usage": true,
+		"GET /api/v0/admin/stats":      true,
+
+		"GET /api/v0/admin/verification-bundle": true,
+		"POST /api/v0/admin/canary":             true,
+
+		"POST /api/v0/admin/apikeys":                  true,
+		"GET /api/v0/admin/apikeys/:id/rate-limit":    true,
+		"PUT /api/v0/admin/apikeys/:id/rate-limit":    true,
+		"DELETE /api/v0/admin/apikeys/:id/rate-limit": true,
+
+		"GET /api/v0/admin/log-sampling":    true,
+		"PUT /api/v0/admin/log-sampling":    true,
+		"DELETE /api/v0/admin/log-sampling": true,
+		"POST /api/v0/admin/impersonate":    true,
+
+		"POST /api/v0/admin/groups":                     true,
+		"GET /api/v0/admin/groups/:id":                  true,
+		"DELETE /api/v0/admin/groups/:id":               true,
+		"PUT /api/v0/admin/groups/:id/members/:user":    true,
+		"DELETE /api/v0/admin/groups/:id/members/:user": true,
+		"GET /api/v0/admin/groups/:id/check":            true,
+		"GET /api/v0/admin/users/:user/groups":          true,
+
+		"DELETE /api/v0/admin/users/:id/sessions":     true,
+		"PUT /api/v0/admin/users/:id/deactivation":    true,
+		"DELETE /api/v0/admin/users/:id/deactivation": true,
+		"POST /api/v0/admin/deactivations/check":      true,
+		"POST /api/v0/admin/drain":                    true,
+		"GET /api/v0/admin/read-only":                 true,
+		"PUT /api/v0/admin/read-only":                 true,
+		"DELETE /api/v0/admin/read-only":              true,
+		"POST /api/v0/admin/users/:id/notifications":  true,
+		"GET /api/v0/admin/jobs/:id":                  true,
+		"GET /api/v0/admin/refresh-families/:id":      true,
+		"DELETE /api/v0/admin/refresh-families/:id":   true,
+
+		"GET /api/v0/admin/bans":    true,
+		"PUT /api/v0/admin/bans":    true,
+		"DELETE /api/v0/admin/bans": true,
+	}, adminRoutes)
+}
+
+func TestRegisterAPIRoutes_AdminLogin(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h := mocks.NewMockhandler(ctrl)
+	h.EXPECT().Version().Return("v0").Times(1)
+
+	// вход через каталог включает административное API и без статического токена
+	server, err := New(
+		WithPort(8080),
+		WithShutdownTimeout(100*time.Millisecond),
+		WithHandlerV0(h),
+		WithAdminValidator(fakeAdminValidator{}),
+	)
+	require.NoError(t, err)
+
+	e := echo.New()
+	server.registerAPIRoutes(e)
+
+	routes := map[string]bool{}
+	for _, r := range e.Routes() {
+		routes[r.Method+" "+r.Path] = true
+	}
+
+	assert.True(t, routes["POST /api/v0/admin/login"])
+	assert.True(t, routes["GET /api/v0/admin/jobs/:id"])
+}
+
+// userKeys - ключи подписи токенов пользователей для тестов.
+type userKeys struct{}
+
+func (userKeys) Key(_ context.Context, _ string) ([]byte, error) {
+	return []byte("secret"), nil
+}
+
+func TestRegisterAPIRoutes_Authentication(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	h := mocks.NewMockhandler(ctrl)
+	h.EXPECT().Version().Return("v0")
+
+	validator, err := token.NewValidator(token.WithKeys(userKeys{}))
+	require.NoError(t, err)
+
+	server, err := New(
+		WithPort(8080),
+		WithShutdownTimeout(100*time.Millisecond),
+		WithHandlerV0(h),
+		WithAuthentication(validator.ForAudience("web")),
+	)
+	require.NoError(t, err)
+
+	e := echo.New()
+	server.registerAPIRoutes(e)
+
+	do := func(raw string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v0/account/notifications", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+raw)
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	// недействительный токен отклоняется до обработчика
+	assert.Equal(t, http.StatusUnauthorized, do("not-a-jwt"))
+
+	// обработчик получает claims из контекста
+	h.EXPECT().GetNotificationPreferences(gomock.Any()).DoAndReturn(func(c echo.Context) error {
+		claims, ok := token.FromContext(c.Request().Context())
+		if !ok {
+			return c.NoContent(http.StatusInternalServerError)
+		}
+
+		return c.String(http.StatusOK, claims.Subject)
+	})
+
+	sign := func(aud string) string {
+		tok := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+			Subject:   "user-1",
+			Audience:  jwt.ClaimStrings{aud},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		})
+		tok.Header["kid"] = "key-1"
+
+		raw, err := tok.SignedString([]byte("secret"))
+		require.NoError(t, err)
+
+		return raw
+	}
+
+	// токен администратора не принимается API пользователя
+	assert.Equal(t, http.StatusUnauthorized, do(sign("admin")))
+
+	assert.Equal(t, http.StatusOK, do(sign("web")))
+}
+
+func TestRequestLogFormat(t *testing.T) {
+	t.Parallel()
+
+	format := requestLogFormat()
+
+	assert.True(t, strings.HasSuffix(format, `,"subject":"${custom}"}`+"\n"))
+	assert.Equal(t, 1, strings.Count(format, "}\n"))
+}
+
 func TestCheckHandlerVersion(t *testing.T) {
 	t.Parallel()
 
@@ -232,9 +725,269 @@ func routesMap(t *testing.T, routes []*echo.Route) map[string]string {
 
 	res := map[string]string{}
 
+	// на одном пути может быть несколько методов
 	for _, r := range routes {
-		res[r.Path] = r.Method
+		res[r.Method+" "+r.Path] = r.Method
 	}
 
 	return res
 }
+
+func TestLogSkipper(t *testing.T) {
+	t.Parallel()
+
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/api/v0/token/introspect", nil), httptest.NewRecorder())
+	c.SetPath("/api/v0/token/introspect")
+
+	assert.False(t, (&Server{}).logSkipper()(c))
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	sampler, err := logsampling.New(logsampling.WithClient(client))
+	require.NoError(t, err)
+	require.NoError(t, sampler.Update(t.Context(), map[string]float64{"/api/v0/token/introspect": 0}))
+
+	skipper := (&Server{logSampling: sampler}).logSkipper()
+	assert.True(t, skipper(c))
+
+	c.SetPath("/api/v0/health")
+	assert.False(t, skipper(c))
+}
+
+func TestRun_TLS(t *testing.T) {
+	t.Parallel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert := &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+
+	h := mocks.NewMockhandler(gomock.NewController(t))
+	h.EXPECT().Version().Return("v0")
+
+	server, err := New(
+		WithPort(port),
+		WithShutdownTimeout(time.Second),
+		WithHandlerV0(h),
+		WithTLSConfig(&tls.Config{
+			MinVersion: tls.VersionTLS12,
+			GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+				return cert, nil
+			},
+		}),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+
+	// маршруты prometheus можно зарегистрировать только один раз на процесс, поэтому без createRoutes
+	server.e = echo.New()
+	server.e.GET("/ping", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	go func() { done <- server.run(ctx) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // самоподписанный сертификат в тесте
+	}}
+
+	require.Eventually(t, func() bool {
+		resp, err := client.Get(fmt.Sprintf("https://localhost:%d/ping", port))
+		if err != nil {
+			return false
+		}
+
+		defer resp.Body.Close()
+
+		return resp.StatusCode == http.StatusOK && resp.TLS != nil
+	}, 5*time.Second, 20*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestRun_HTTP2(t *testing.T) {
+	t.Parallel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	tlsConfig := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		scheme    string
+		protocols func() *http.Protocols
+		wantProto int
+	}{
+		{
+			name:   "positive case: h2c",
+			opts:   []Option{WithH2C(true)},
+			scheme: "http",
+			protocols: func() *http.Protocols {
+				p := &http.Protocols{}
+				p.SetUnencryptedHTTP2(true)
+
+				return p
+			},
+			wantProto: 2,
+		},
+		{
+			name:   "positive case: http/1.1 without h2c",
+			scheme: "http",
+			protocols: func() *http.Protocols {
+				p := &http.Protocols{}
+				p.SetHTTP1(true)
+
+				return p
+			},
+			wantProto: 1,
+		},
+		{
+			name:   "positive case: http2 over tls",
+			opts:   []Option{WithTLSConfig(tlsConfig), WithHTTP2(true)},
+			scheme: "https",
+			protocols: func() *http.Protocols {
+				p := &http.Protocols{}
+				p.SetHTTP1(true)
+				p.SetHTTP2(true)
+
+				return p
+			},
+			wantProto: 2,
+		},
+		{
+			name:   "positive case: tls without http2",
+			opts:   []Option{WithTLSConfig(tlsConfig)},
+			scheme: "https",
+			protocols: func() *http.Protocols {
+				p := &http.Protocols{}
+				p.SetHTTP1(true)
+				p.SetHTTP2(true)
+
+				return p
+			},
+			wantProto: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			port := freePort(t)
+
+			h := mocks.NewMockhandler(gomock.NewController(t))
+			h.EXPECT().Version().Return("v0")
+
+			server, err := New(append([]Option{
+				WithPort(port),
+				WithShutdownTimeout(time.Second),
+				WithHandlerV0(h),
+			}, tt.opts...)...)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(t.Context())
+			done := make(chan error)
+
+			// маршруты prometheus можно зарегистрировать только один раз на процесс, поэтому без createRoutes
+			server.e = echo.New()
+			server.e.GET("/ping", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+			go func() { done <- server.run(ctx) }()
+
+			client := &http.Client{Transport: &http.Transport{
+				Protocols:       tt.protocols(),
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // самоподписанный сертификат в тесте
+			}}
+
+			var proto int
+
+			require.Eventually(t, func() bool {
+				resp, err := client.Get(fmt.Sprintf("%s://localhost:%d/ping", tt.scheme, port))
+				if err != nil {
+					return false
+				}
+
+				defer resp.Body.Close()
+
+				proto = resp.ProtoMajor
+
+				return resp.StatusCode == http.StatusOK
+			}, 5*time.Second, 20*time.Millisecond)
+
+			assert.Equal(t, tt.wantProto, proto)
+
+			cancel()
+			require.NoError(t, <-done)
+		})
+	}
+}
+
+func TestCheckDeprecations(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h := mocks.NewMockhandler(ctrl)
+	h.EXPECT().Version().Return("v0").Times(2)
+
+	newServer := func(path string) *Server {
+		registry, err := deprecation.New(
+			deprecation.WithRegisterer(prometheus.NewRegistry()),
+			deprecation.WithRoutes(deprecation.Route{Method: http.MethodGet, Path: path, Since: time.Now()}),
+		)
+		require.NoError(t, err)
+
+		server, err := New(
+			WithPort(8080),
+			WithShutdownTimeout(100*time.Millisecond),
+			WithHandlerV0(h),
+			WithDeprecations(registry),
+		)
+		require.NoError(t, err)
+
+		server.e = echo.New()
+		server.registerAPIRoutes(server.e)
+
+		return server
+	}
+
+	require.NoError(t, newServer("/api/v0/health").checkDeprecations())
+	require.EqualError(t, newServer("/api/v0/healthz").checkDeprecations(), "deprecated route GET /api/v0/healthz is not registered")
+}
@@ -0,0 +1,511 @@
+// Package abuse защищает вход от перебора учетных данных (credential stuffing). Сервис считает
+// неудачные входы с разными логинами из одного IP, сети и ASN, ловит обращения к ловушкам (honeypot)
+// и при превышении порогов временно блокирует источник в denylist Redis. О каждой блокировке
+// сообщается метрикой, ошибкой в логе и событием в stream, чтобы можно было настроить алерт.
+// В режиме наблюдения источники не блокируются автоматически: сервис только сообщает метрикой
+// и в логе, кого бы заблокировал, чтобы пороги можно было подобрать на реальном трафике.
+package abuse
+
+import (
+	"auth-service/internal/service/event"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	keyPrefix = "auth:abuse:"
+
+	// DefaultWindow - окно, в котором считаются неудачные входы.
+	DefaultWindow = 10 * time.Minute
+	// DefaultBanTTL - срок блокировки.
+	DefaultBanTTL = time.Hour
+	// DefaultIPv4Prefix - длина префикса сети для IPv4.
+	DefaultIPv4Prefix = 24
+	// DefaultIPv6Prefix - длина префикса сети для IPv6.
+	DefaultIPv6Prefix = 64
+)
+
+// Причины блокировки.
+const (
+	ReasonHoneypot           = "honeypot"
+	ReasonCredentialStuffing = "credential_stuffing"
+	ReasonManual             = "manual"
+)
+
+// Виды источников блокировки.
+const (
+	ScopeIP      = "ip"
+	ScopeNetwork = "net"
+	ScopeASN     = "asn"
+)
+
+var (
+	// ErrInvalidScope - источник блокировки задан неверно.
+	ErrInvalidScope = errors.New("invalid scope")
+	// ErrNotFound - блокировка не найдена.
+	ErrNotFound = errors.New("ban not found")
+)
+
+//go:generate mockgen -source=abuse.go -destination=mocks/abuse_mock.go -package=mocks
+type eventPublisher interface {
+	Publish(ctx context.Context, e event.Event) (string, error)
+}
+
+// Client - источник запроса.
+type Client struct {
+	IP net.IP
+	// ASN - номер автономной системы из заголовка доверенного прокси, пусто - неизвестен.
+	ASN string
+}
+
+// Ban - блокировка источника.
+type Ban struct {
+	// Scope - заблокированный источник: ip:<адрес>, net:<сеть> или asn:<номер>.
+	Scope     string    `json:"scope"`
+	Reason    string    `json:"reason"`
+	Detail    string    `json:"detail,omitempty"`
+	BannedAt  time.Time `json:"banned_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Service - обнаружение перебора учетных данных и denylist.
+//
+// Ключи:
+//   - auth:abuse:ban:<scope> - JSON блокировки, живет до окончания блокировки;
+//   - auth:abuse:bans - множество заблокированных источников;
+//   - auth:abuse:failures:{<scope>}:<окно> - HyperLogLog логинов неудачных входов источника за окно.
+type Service struct {
+	client redis.UniversalClient
+	events eventPublisher
+
+	window     time.Duration
+	banTTL     time.Duration
+	ipv4Prefix int
+	ipv6Prefix int
+	asnHeader  string
+
+	// пороги количества разных логинов с неудачным входом за окно, 0 - источник не блокируется
+	ipThreshold      int64
+	networkThreshold int64
+	asnThreshold     int64
+
+	// observe - режим наблюдения: автоматические блокировки только учитываются
+	observe bool
+
+	registerer   prometheus.Registerer
+	bans         *prometheus.CounterVec
+	observed     *prometheus.CounterVec
+	honeypotHits prometheus.Counter
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Service.
+type Option func(*Service)
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(s *Service) {
+		s.client = client
+	}
+}
+
+// WithEvents устанавливает публикацию событий о блокировках.
+func WithEvents(events eventPublisher) Option {
+	return func(s *Service) {
+		s.events = events
+	}
+}
+
+// WithWindow устанавливает окно, в котором считаются неудачные входы. По умолчанию DefaultWindow.
+func WithWindow(window time.Duration) Option {
+	return func(s *Service) {
+		s.window = window
+	}
+}
+
+// WithBanTTL устанавливает срок блокировки. По умолчанию DefaultBanTTL.
+func WithBanTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		s.banTTL = ttl
+	}
+}
+
+// WithNetworkPrefixes устанавливает длины префиксов сети для IPv4 и IPv6.
+// По умолчанию DefaultIPv4Prefix и DefaultIPv6Prefix.
+func WithNetworkPrefixes(ipv4, ipv6 int) Option {
+	return func(s *Service) {
+		s.ipv4Prefix = ipv4
+		s.ipv6Prefix = ipv6
+	}
+}
+
+// WithASNHeader устанавливает заголовок, в котором доверенный прокси передает номер ASN клиента.
+func WithASNHeader(header string) Option {
+	return func(s *Service) {
+		s.asnHeader = header
+	}
+}
+
+// WithThresholds устанавливает, после скольких разных логинов с неудачным входом за окно
+// блокируются IP, сеть и ASN. 0 - источник такого вида не блокируется.
+func WithThresholds(ip, network, asn int64) Option {
+	return func(s *Service) {
+		s.ipThreshold = ip
+		s.networkThreshold = network
+		s.asnThreshold = asn
+	}
+}
+
+// WithObserve включает режим наблюдения: при превышении порогов и обращении к ловушке источник
+// не блокируется, а только учитывается в метрике auth_abuse_observed_bans_total и в логе.
+// Ручные блокировки через административное API действуют и в этом режиме.
+func WithObserve(observe bool) Option {
+	return func(s *Service) {
+		s.observe = observe
+	}
+}
+
+// WithRegisterer устанавливает реестр метрик. По умолчанию используется prometheus.DefaultRegisterer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(s *Service) {
+		s.registerer = registerer
+	}
+}
+
+// New создает новый Service и регистрирует его метрики.
+func New(opts ...Option) (*Service, error) {
+	s := &Service{
+		window:     DefaultWindow,
+		banTTL:     DefaultBanTTL,
+		ipv4Prefix: DefaultIPv4Prefix,
+		ipv6Prefix: DefaultIPv6Prefix,
+		registerer: prometheus.DefaultRegisterer,
+		now:        time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.client == nil {
+		return nil, errors.New("redis client is required")
+	}
+
+	if s.registerer == nil {
+		return nil, errors.New("registerer is required")
+	}
+
+	if s.window <= 0 || s.banTTL <= 0 {
+		return nil, errors.New("window and ban ttl must be positive")
+	}
+
+	if s.ipv4Prefix < 1 || s.ipv4Prefix > 32 || s.ipv6Prefix < 1 || s.ipv6Prefix > 128 {
+		return nil, fmt.Errorf("invalid network prefixes /%d, /%d", s.ipv4Prefix, s.ipv6Prefix)
+	}
+
+	s.bans = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_abuse_bans_total",
+		Help: "Количество блокировок источников запросов по причине (honeypot, credential_stuffing, manual) и виду источника (ip, net, asn).",
+	}, []string{"reason", "scope"})
+
+	s.observed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_abuse_observed_bans_total",
+		Help: "Количество блокировок, которые были бы выполнены без режима наблюдения, по причине и виду источника.",
+	}, []string{"reason", "scope"})
+
+	s.honeypotHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "auth_abuse_honeypot_hits_total",
+		Help: "Количество обращений к ловушкам.",
+	})
+
+	for _, c := range []prometheus.Collector{s.bans, s.observed, s.honeypotHits} {
+		if err := s.registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func banKey(scope string) string {
+	return keyPrefix + "ban:" + scope
+}
+
+func bansKey() string {
+	return keyPrefix + "bans"
+}
+
+// ClientOf возвращает источник запроса по IP клиента и заголовкам доверенного прокси.
+func (s *Service) ClientOf(ip string, header http.Header) Client {
+	c := Client{IP: net.ParseIP(ip)}
+
+	if s.asnHeader != "" {
+		c.ASN = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(header.Get(s.asnHeader))), "AS")
+	}
+
+	return c
+}
+
+// scopes возвращает источники запроса, которые могут быть заблокированы: IP, сеть и ASN.
+func (s *Service) scopes(c Client) []string {
+	scopes := []string{}
+
+	if c.IP != nil {
+		bits, prefix := 32, s.ipv4Prefix
+
+		ip := c.IP.To4()
+		if ip == nil {
+			ip, bits, prefix = c.IP.To16(), 128, s.ipv6Prefix
+		}
+
+		network := net.IPNet{IP: ip.Mask(net.CIDRMask(prefix, bits)), Mask: net.CIDRMask(prefix, bits)}
+		scopes = append(scopes, ScopeIP+":"+ip.String(), ScopeNetwork+":"+network.String())
+	}
+
+	if _, err := strconv.ParseUint(c.ASN, 10, 32); err == nil {
+		scopes = append(scopes, ScopeASN+":"+c.ASN)
+	}
+
+	return scopes
+}
+
+// ParseScope проверяет источник блокировки: ip:<адрес>, net:<сеть CIDR> или asn:<номер>.
+func ParseScope(scope string) (string, error) {
+	kind, value, _ := strings.Cut(scope, ":")
+
+	switch kind {
+	case ScopeIP:
+		if ip := net.ParseIP(value); ip != nil {
+			if ip4 := ip.To4(); ip4 != nil {
+				ip = ip4
+			}
+
+			return ScopeIP + ":" + ip.String(), nil
+		}
+	case ScopeNetwork:
+		if _, network, err := net.ParseCIDR(value); err == nil {
+			return ScopeNetwork + ":" + network.String(), nil
+		}
+	case ScopeASN:
+		asn := strings.TrimPrefix(strings.ToUpper(value), "AS")
+		if _, err := strconv.ParseUint(asn, 10, 32); err == nil {
+			return ScopeASN + ":" + asn, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %q, expected ip:<address>, net:<cidr> or asn:<number>", ErrInvalidScope, scope)
+}
+
+// Banned возвращает действующую блокировку источника запроса или nil, если источник не заблокирован.
+func (s *Service) Banned(ctx context.Context, c Client) (*Ban, error) {
+	scopes := s.scopes(c)
+	if len(scopes) == 0 {
+		return nil, nil //nolint:nilnil // источник неизвестен - блокировать нечего
+	}
+
+	cmds := make([]*redis.StringCmd, 0, len(scopes))
+
+	// ключи могут лежать в разных слотах кластера, поэтому пайплайн без транзакции
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for _, scope := range scopes {
+			cmds = append(cmds, p.Get(ctx, banKey(scope)))
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("abuse: error get ban: %w", err)
+	}
+
+	for _, cmd := range cmds {
+		// ошибка redis.Nil первой команды пайплайна проставляется и следующим, поэтому смотрим на значение
+		data := cmd.Val()
+		if data == "" {
+			continue
+		}
+
+		var ban Ban
+		if err := json.Unmarshal([]byte(data), &ban); err != nil {
+			return nil, fmt.Errorf("abuse: error decode ban: %w", err)
+		}
+
+		return &ban, nil
+	}
+
+	return nil, nil //nolint:nilnil // источник не заблокирован - не ошибка
+}
+
+// Ban блокирует источник на срок блокировки. Повторная блокировка действующей не продлевает ее
+// и не вызывает алерт повторно, возвращается существующая.
+func (s *Service) Ban(ctx context.Context, scope, reason, detail string) (*Ban, error) {
+	scope, err := ParseScope(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC().Truncate(time.Second)
+	ban := &Ban{Scope: scope, Reason: reason, Detail: detail, BannedAt: now, ExpiresAt: now.Add(s.banTTL)}
+
+	data, err := json.Marshal(ban)
+	if err != nil {
+		return nil, fmt.Errorf("abuse: error encode ban: %w", err)
+	}
+
+	created, err := s.client.SetNX(ctx, banKey(scope), data, s.banTTL).Result()
+	if err != nil {
+		return nil, fmt.Errorf("abuse: error save ban: %w", err)
+	}
+
+	if !created {
+		existing, err := s.get(ctx, scope)
+		// блокировка истекла между SETNX и GET - пробуем заблокировать снова
+		if errors.Is(err, ErrNotFound) {
+			return s.Ban(ctx, scope, reason, detail)
+		}
+
+		return existing, err
+	}
+
+	if err := s.client.SAdd(ctx, bansKey(), scope).Err(); err != nil {
+		return nil, fmt.Errorf("abuse: error save ban: %w", err)
+	}
+
+	s.alert(ctx, ban)
+
+	return ban, nil
+}
+
+// autoBan блокирует источник по решению сервиса. В режиме наблюдения источник не блокируется:
+// блокировка только учитывается в метрике и логе, возвращается nil.
+func (s *Service) autoBan(ctx context.Context, scope, reason, detail string) (*Ban, error) {
+	if !s.observe {
+		return s.Ban(ctx, scope, reason, detail)
+	}
+
+	kind, _, _ := strings.Cut(scope, ":")
+	s.observed.WithLabelValues(reason, kind).Inc()
+
+	logrus.WithFields(logrus.Fields{
+		"scope":  scope,
+		"reason": reason,
+		"detail": detail,
+	}).Warn("source would be banned (observe mode)")
+
+	return nil, nil //nolint:nilnil // в режиме наблюдения блокировки нет
+}
+
+// alert сообщает о блокировке метрикой, ошибкой в логе и событием в stream.
+func (s *Service) alert(ctx context.Context, ban *Ban) {
+	kind, _, _ := strings.Cut(ban.Scope, ":")
+	s.bans.WithLabelValues(ban.Reason, kind).Inc()
+
+	log := logrus.WithFields(logrus.Fields{
+		"scope":      ban.Scope,
+		"reason":     ban.Reason,
+		"detail":     ban.Detail,
+		"expires_at": ban.ExpiresAt,
+	})
+	log.Error("source banned")
+
+	if s.events == nil {
+		return
+	}
+
+	_, err := s.events.Publish(ctx, event.Event{
+		Type:    event.TypeSourceBanned,
+		Subject: ban.Scope,
+		Source:  ban.Reason,
+		At:      ban.BannedAt,
+	})
+	if err != nil {
+		log.WithError(err).Error("error publish ban")
+	}
+}
+
+// get возвращает блокировку источника.
+func (s *Service) get(ctx context.Context, scope string) (*Ban, error) {
+	data, err := s.client.Get(ctx, banKey(scope)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("abuse: error get ban: %w", err)
+	}
+
+	var ban Ban
+	if err := json.Unmarshal(data, &ban); err != nil {
+		return nil, fmt.Errorf("abuse: error decode ban: %w", err)
+	}
+
+	return &ban, nil
+}
+
+// Unban снимает блокировку источника.
+func (s *Service) Unban(ctx context.Context, scope string) error {
+	scope, err := ParseScope(scope)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.client.Del(ctx, banKey(scope)).Result()
+	if err != nil {
+		return fmt.Errorf("abuse: error delete ban: %w", err)
+	}
+
+	if err := s.client.SRem(ctx, bansKey(), scope).Err(); err != nil {
+		return fmt.Errorf("abuse: error delete ban: %w", err)
+	}
+
+	if deleted == 0 {
+		return ErrNotFound
+	}
+
+	logrus.WithField("scope", scope).Info("source unbanned")
+
+	return nil
+}
+
+// Bans возвращает действующие блокировки. Истекшие блокировки удаляются из множества.
+func (s *Service) Bans(ctx context.Context) ([]Ban, error) {
+	scopes, err := s.client.SMembers(ctx, bansKey()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("abuse: error list bans: %w", err)
+	}
+
+	slices.Sort(scopes)
+
+	bans := make([]Ban, 0, len(scopes))
+
+	for _, scope := range scopes {
+		ban, err := s.get(ctx, scope)
+		if errors.Is(err, ErrNotFound) {
+			if err := s.client.SRem(ctx, bansKey(), scope).Err(); err != nil {
+				return nil, fmt.Errorf("abuse: error delete ban: %w", err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		bans = append(bans, *ban)
+	}
+
+	return bans, nil
+}
@@ -0,0 +1,352 @@
+package abuse
+
+import (
+	"auth-service/internal/service/abuse/mocks"
+	"auth-service/internal/service/event"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newService(t *testing.T, opts ...Option) (*Service, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	s, err := New(append([]Option{WithClient(client), WithRegisterer(prometheus.NewRegistry())}, opts...)...)
+	require.NoError(t, err)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	return s, mr
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	t.Cleanup(func() { _ = client.Close() })
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{
+			name: "positive case",
+			opts: []Option{WithClient(client), WithRegisterer(prometheus.NewRegistry())},
+		},
+		{
+			name:    "error case: no client",
+			opts:    []Option{WithRegisterer(prometheus.NewRegistry())},
+			wantErr: true,
+		},
+		{
+			name:    "error case: invalid prefix",
+			opts:    []Option{WithClient(client), WithRegisterer(prometheus.NewRegistry()), WithNetworkPrefixes(33, 64)},
+			wantErr: true,
+		},
+		{
+			name:    "error case: zero window",
+			opts:    []Option{WithClient(client), WithRegisterer(prometheus.NewRegistry()), WithWindow(-time.Second)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := New(tt.opts...)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, s)
+		})
+	}
+}
+
+func TestParseScope(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scope   string
+		want    string
+		wantErr bool
+	}{
+		{scope: "ip:10.0.0.1", want: "ip:10.0.0.1"},
+		{scope: "ip:::ffff:10.0.0.1", want: "ip:10.0.0.1"},
+		{scope: "net:10.0.0.17/24", want: "net:10.0.0.0/24"},
+		{scope: "asn:AS13335", want: "asn:13335"},
+		{scope: "ip:localhost", wantErr: true},
+		{scope: "net:10.0.0.1", wantErr: true},
+		{scope: "asn:cloudflare", wantErr: true},
+		{scope: "host:example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseScope(tt.scope)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidScope)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_ClientOf(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newService(t, WithASNHeader("X-ASN"), WithNetworkPrefixes(16, 48))
+
+	header := http.Header{}
+	header.Set("X-ASN", "AS13335")
+
+	c := s.ClientOf("10.1.2.3", header)
+	assert.Equal(t, "13335", c.ASN)
+	assert.Equal(t, []string{"ip:10.1.2.3", "net:10.1.0.0/16", "asn:13335"}, s.scopes(c))
+
+	c = s.ClientOf("2001:db8:1:2::1", http.Header{})
+	assert.Empty(t, c.ASN)
+	assert.Equal(t, []string{"ip:2001:db8:1:2::1", "net:2001:db8:1::/48"}, s.scopes(c))
+
+	assert.Empty(t, s.scopes(s.ClientOf("unknown", http.Header{})))
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService_Ban(t *testing.T) {
+	t.Parallel()
+
+	events := mocks.NewMockeventPublisher(gomock.NewController(t))
+	s, mr := newService(t, WithEvents(events), WithBanTTL(time.Hour))
+
+	_, err := s.Ban(t.Context(), "host:example.com", ReasonManual, "")
+	require.ErrorIs(t, err, ErrInvalidScope)
+
+	events.EXPECT().Publish(gomock.Any(), event.Event{
+		Type:    event.TypeSourceBanned,
+		Subject: "net:10.0.0.0/24",
+		Source:  ReasonManual,
+		At:      s.now(),
+	}).Return("1-0", nil)
+
+	ban, err := s.Ban(t.Context(), "net:10.0.0.5/24", ReasonManual, "by operator")
+	require.NoError(t, err)
+	assert.Equal(t, &Ban{
+		Scope:     "net:10.0.0.0/24",
+		Reason:    ReasonManual,
+		Detail:    "by operator",
+		BannedAt:  s.now(),
+		ExpiresAt: s.now().Add(time.Hour),
+	}, ban)
+	assert.Equal(t, time.Hour, mr.TTL(banKey("net:10.0.0.0/24")))
+	assert.InDelta(t, 1, testutil.ToFloat64(s.bans.WithLabelValues(ReasonManual, ScopeNetwork)), 0)
+
+	// повторная блокировка возвращает существующую без нового алерта
+	again, err := s.Ban(t.Context(), "net:10.0.0.0/24", ReasonHoneypot, "")
+	require.NoError(t, err)
+	assert.Equal(t, ban, again)
+
+	banned, err := s.Banned(t.Context(), Client{IP: net.ParseIP("10.0.0.77")})
+	require.NoError(t, err)
+	assert.Equal(t, ban, banned)
+
+	banned, err = s.Banned(t.Context(), Client{IP: net.ParseIP("10.0.1.1")})
+	require.NoError(t, err)
+	assert.Nil(t, banned)
+
+	bans, err := s.Bans(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []Ban{*ban}, bans)
+
+	require.NoError(t, s.Unban(t.Context(), "net:10.0.0.0/24"))
+	require.ErrorIs(t, s.Unban(t.Context(), "net:10.0.0.0/24"), ErrNotFound)
+
+	banned, err = s.Banned(t.Context(), Client{IP: net.ParseIP("10.0.0.77")})
+	require.NoError(t, err)
+	assert.Nil(t, banned)
+
+	// истекшие блокировки не возвращаются
+	require.NoError(t, s.client.SAdd(t.Context(), bansKey(), "ip:10.0.0.1").Err())
+
+	bans, err = s.Bans(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, bans)
+	assert.False(t, mr.Exists(bansKey()))
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService_LoginFailed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		clients   []Client
+		usernames int
+		wantScope string
+	}{
+		{
+			name:      "positive case: bans ip",
+			opts:      []Option{WithThresholds(5, 0, 0)},
+			clients:   []Client{{IP: net.ParseIP("10.0.0.1")}},
+			usernames: 5,
+			wantScope: "ip:10.0.0.1",
+		},
+		{
+			name:      "positive case: same usernames are not counted",
+			opts:      []Option{WithThresholds(5, 0, 0)},
+			clients:   []Client{{IP: net.ParseIP("10.0.0.1")}},
+			usernames: 1,
+		},
+		{
+			name:      "positive case: bans network of many ips",
+			opts:      []Option{WithThresholds(5, 6, 0)},
+			clients:   []Client{{IP: net.ParseIP("10.0.0.1")}, {IP: net.ParseIP("10.0.0.2")}, {IP: net.ParseIP("10.0.0.3")}},
+			usernames: 2,
+			wantScope: "net:10.0.0.0/24",
+		},
+		{
+			name:      "positive case: bans asn",
+			opts:      []Option{WithThresholds(0, 0, 4)},
+			clients:   []Client{{IP: net.ParseIP("10.0.0.1"), ASN: "64500"}, {IP: net.ParseIP("192.168.0.1"), ASN: "64500"}},
+			usernames: 2,
+			wantScope: "asn:64500",
+		},
+		{
+			name:      "positive case: disabled thresholds",
+			clients:   []Client{{IP: net.ParseIP("10.0.0.1"), ASN: "64500"}},
+			usernames: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, _ := newService(t, tt.opts...)
+
+			var (
+				ban *Ban
+				err error
+			)
+
+			for i, c := range tt.clients {
+				for j := range tt.usernames {
+					ban, err = s.LoginFailed(t.Context(), c, fmt.Sprintf("user-%d-%d", i, j))
+					require.NoError(t, err)
+
+					// повторный вход с тем же логином не увеличивает счетчик
+					_, err = s.LoginFailed(t.Context(), c, fmt.Sprintf("USER-%d-%d", i, j))
+					require.NoError(t, err)
+				}
+			}
+
+			if tt.wantScope == "" {
+				assert.Nil(t, ban)
+				return
+			}
+
+			require.NotNil(t, ban)
+			assert.Equal(t, tt.wantScope, ban.Scope)
+			assert.Equal(t, ReasonCredentialStuffing, ban.Reason)
+
+			banned, err := s.Banned(t.Context(), tt.clients[0])
+			require.NoError(t, err)
+			assert.NotNil(t, banned)
+		})
+	}
+}
+
+func TestService_LoginFailed_Window(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newService(t, WithThresholds(3, 0, 0), WithWindow(time.Minute))
+
+	c := Client{IP: net.ParseIP("10.0.0.1")}
+	start := s.now()
+
+	for i, offset := range []time.Duration{0, time.Minute, 3 * time.Minute} {
+		s.now = func() time.Time { return start.Add(offset) }
+
+		ban, err := s.LoginFailed(t.Context(), c, fmt.Sprintf("user-%d", i))
+		require.NoError(t, err)
+		// неудачи старше предыдущего окна не учитываются
+		assert.Nil(t, ban)
+	}
+}
+
+func TestService_Honeypot(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newService(t)
+
+	_, err := s.Honeypot(t.Context(), Client{}, "/.env")
+	require.ErrorIs(t, err, ErrInvalidScope)
+
+	ban, err := s.Honeypot(t.Context(), Client{IP: net.ParseIP("10.0.0.1")}, "/.env")
+	require.NoError(t, err)
+	assert.Equal(t, "ip:10.0.0.1", ban.Scope)
+	assert.Equal(t, ReasonHoneypot, ban.Reason)
+	assert.InDelta(t, 2, testutil.ToFloat64(s.honeypotHits), 0)
+}
+
+func TestService_Observe(t *testing.T) {
+	t.Parallel()
+
+	s, mr := newService(t, WithThresholds(2, 0, 0), WithObserve(true))
+
+	c := Client{IP: net.ParseIP("10.0.0.1")}
+
+	for _, username := range []string{"alice", "bob", "carol"} {
+		ban, err := s.LoginFailed(t.Context(), c, username)
+		require.NoError(t, err)
+		assert.Nil(t, ban)
+	}
+
+	ban, err := s.Honeypot(t.Context(), c, "/.env")
+	require.NoError(t, err)
+	assert.Nil(t, ban)
+
+	// источник не заблокирован, блокировки только учтены
+	ban, err = s.Banned(t.Context(), c)
+	require.NoError(t, err)
+	assert.Nil(t, ban)
+	assert.False(t, mr.Exists(banKey("ip:10.0.0.1")))
+
+	assert.InDelta(t, 2, testutil.ToFloat64(s.observed.WithLabelValues(ReasonCredentialStuffing, ScopeIP)), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(s.observed.WithLabelValues(ReasonHoneypot, ScopeIP)), 0)
+	assert.InDelta(t, 0, testutil.ToFloat64(s.bans.WithLabelValues(ReasonHoneypot, ScopeIP)), 0)
+
+	// ручная блокировка действует
+	_, err = s.Ban(t.Context(), "ip:10.0.0.1", ReasonManual, "")
+	require.NoError(t, err)
+
+	ban, err = s.Banned(t.Context(), c)
+	require.NoError(t, err)
+	require.NotNil(t, ban)
+	assert.Equal(t, ReasonManual, ban.Reason)
+}
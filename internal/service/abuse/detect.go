@@ -0,0 +1,101 @@
+package abuse
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// failuresKey возвращает ключ HyperLogLog логинов неудачных входов источника за окно.
+// Источник в hash tag, чтобы ключи соседних окон лежали в одном слоте кластера для PFCOUNT.
+func failuresKey(scope string, window int64) string {
+	return keyPrefix + "failures:{" + scope + "}:" + strconv.FormatInt(window, 10)
+}
+
+// threshold возвращает порог для вида источника.
+func (s *Service) threshold(scope string) int64 {
+	kind, _, _ := strings.Cut(scope, ":")
+
+	switch kind {
+	case ScopeIP:
+		return s.ipThreshold
+	case ScopeNetwork:
+		return s.networkThreshold
+	case ScopeASN:
+		return s.asnThreshold
+	default:
+		return 0
+	}
+}
+
+// LoginFailed учитывает неудачный вход с логином username. Если из IP, сети или ASN клиента
+// за текущее и предыдущее окно не удались входы с количеством разных логинов не меньше порога,
+// источник блокируется (в режиме наблюдения - только учитывается). Возвращает блокировку самого широкого из заблокированных источников или nil.
+func (s *Service) LoginFailed(ctx context.Context, c Client, username string) (*Ban, error) {
+	window := s.now().UnixNano() / int64(s.window)
+
+	var (
+		scopes []string
+		counts []*redis.IntCmd
+	)
+
+	for _, scope := range s.scopes(c) {
+		if s.threshold(scope) > 0 {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for _, scope := range scopes {
+			current := failuresKey(scope, window)
+
+			p.PFAdd(ctx, current, strings.ToLower(username))
+			p.Expire(ctx, current, 2*s.window)
+			counts = append(counts, p.PFCount(ctx, current, failuresKey(scope, window-1)))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("abuse: error count login failures: %w", err)
+	}
+
+	var ban *Ban
+
+	// источники идут от узкого к широкому: IP, сеть, ASN
+	for i, scope := range scopes {
+		usernames := counts[i].Val()
+		if usernames < s.threshold(scope) {
+			continue
+		}
+
+		ban, err = s.autoBan(ctx, scope, ReasonCredentialStuffing, fmt.Sprintf("%d usernames failed to log in within %s", usernames, s.window))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ban, nil
+}
+
+// Honeypot учитывает обращение к ловушке по пути path и сразу блокирует IP клиента.
+// Блокируется только IP: сетью могут пользоваться и обычные клиенты.
+// В режиме наблюдения IP не блокируется.
+func (s *Service) Honeypot(ctx context.Context, c Client, path string) (*Ban, error) {
+	s.honeypotHits.Inc()
+
+	logrus.WithFields(logrus.Fields{
+		"ip":   c.IP.String(),
+		"path": path,
+	}).Warn("honeypot hit")
+
+	if c.IP == nil {
+		return nil, fmt.Errorf("%w: client ip is unknown", ErrInvalidScope)
+	}
+
+	return s.autoBan(ctx, s.scopes(c)[0], ReasonHoneypot, "request to "+path)
+}
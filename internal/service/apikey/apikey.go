@@ -0,0 +1,252 @@
+// Package apikey выпускает API ключи сервисов и хранит их записи в Redis.
+// Ключ выдается клиенту один раз в формате <id>.<секрет>, в Redis хранится только хэш секрета.
+// Если задан путь в Vault, секрет записывается туда, чтобы сервисы-потребители забирали его
+// из Vault, а не копировали из ответа API.
+package apikey
+
+import (
+	"auth-service/internal/service/id"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	idLength     = 16
+	secretLength = 32
+
+	keyPrefix = "auth:"
+)
+
+// Поля записи API ключа auth:apikey:<id>.
+const (
+	FieldName         = "name"
+	FieldCreatedAt    = "created_at"
+	FieldSecretHash   = "secret_hash"
+	FieldQuotaDaily   = "quota_daily"
+	FieldQuotaMonthly = "quota_monthly"
+	FieldEnv          = "env"
+	// FieldRateLimitTier - тариф ограничения частоты запросов из конфигурации.
+	FieldRateLimitTier = "rate_limit_tier"
+	// FieldRateLimitRequests и FieldRateLimitWindow - собственное ограничение частоты запросов ключа.
+	FieldRateLimitRequests = "rate_limit_requests"
+	FieldRateLimitWindow   = "rate_limit_window"
+)
+
+const (
+	// EnvSandbox - окружение ключей песочницы.
+	EnvSandbox = "sandbox"
+	// DefaultSandboxTTL - время жизни ключей песочницы по умолчанию.
+	DefaultSandboxTTL = 24 * time.Hour
+)
+
+// ErrInvalidArgument - не заполнены обязательные параметры.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// RecordKey возвращает ключ Redis с записью API ключа.
+func RecordKey(id string) string {
+	return keyPrefix + "apikey:" + id
+}
+
+// HashSecret возвращает хэш секрета, который хранится в записи ключа.
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(sum[:])
+}
+
+//go:generate mockgen -source=apikey.go -destination=mocks/apikey_mock.go -package=mocks secretWriter
+type secretWriter interface {
+	WriteKV(ctx context.Context, path string, data map[string]interface{}) error
+}
+
+// CreateRequest - параметры нового API ключа.
+type CreateRequest struct {
+	Name string
+	// Квоты ключа, 0 - квота из конфигурации.
+	QuotaDaily   int64
+	QuotaMonthly int64
+	// Sandbox - ключ песочницы: помечается env=sandbox и удаляется через sandbox TTL.
+	Sandbox bool
+}
+
+// Created - выпущенный API ключ.
+type Created struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	// APIKey - ключ целиком, возвращается только если секрет не записан в Vault.
+	APIKey string `json:"api_key,omitempty"`
+	// VaultPath - путь секрета в Vault, если секрет записан туда.
+	VaultPath string `json:"vault_path,omitempty"`
+	// Env и ExpiresAt заполняются для ключей песочницы.
+	Env       string     `json:"env,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Service - выпуск API ключей.
+type Service struct {
+	client redis.UniversalClient
+
+	vault     secretWriter
+	vaultPath string
+
+	sandboxTTL time.Duration
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Service.
+type Option func(*Service)
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(s *Service) {
+		s.client = client
+	}
+}
+
+// WithVault включает запись секретов в Vault KV v2: секрет ключа записывается в <path>/<id>.
+func WithVault(writer secretWriter, path string) Option {
+	return func(s *Service) {
+		s.vault = writer
+		s.vaultPath = strings.TrimSuffix(path, "/")
+	}
+}
+
+// WithSandboxTTL устанавливает время жизни ключей песочницы.
+func WithSandboxTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		s.sandboxTTL = ttl
+	}
+}
+
+// New создает новый Service.
+func New(opts ...Option) (*Service, error) {
+	s := &Service{
+		sandboxTTL: DefaultSandboxTTL,
+		now:        time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.client == nil {
+		return nil, errors.New("redis client is required")
+	}
+
+	if s.vault != nil && s.vaultPath == "" {
+		return nil, errors.New("vault path is required")
+	}
+
+	if s.sandboxTTL <= 0 || s.sandboxTTL > DefaultSandboxTTL {
+		return nil, fmt.Errorf("sandbox ttl must be in (0, %s]", DefaultSandboxTTL)
+	}
+
+	return s, nil
+}
+
+// Create выпускает API ключ. Если включена запись в Vault и она не удалась,
+// запись ключа удаляется, чтобы не осталось ключа, секрет которого никому не известен.
+func (s *Service) Create(ctx context.Context, req CreateRequest) (*Created, error) {
+	if req.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidArgument)
+	}
+
+	if req.QuotaDaily < 0 || req.QuotaMonthly < 0 {
+		return nil, fmt.Errorf("%w: quota must not be negative", ErrInvalidArgument)
+	}
+
+	keyID, err := id.Generate(idLength)
+	if err != nil {
+		return nil, fmt.Errorf("apikey: error generate id: %w", err)
+	}
+
+	raw := make([]byte, secretLength)
+
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("apikey: error generate secret: %w", err)
+	}
+
+	secret := hex.EncodeToString(raw)
+	created := &Created{
+		ID:        keyID,
+		Name:      req.Name,
+		CreatedAt: s.now().UTC().Truncate(time.Second),
+	}
+
+	fields := []any{
+		FieldName, req.Name,
+		FieldCreatedAt, created.CreatedAt.Unix(),
+		FieldSecretHash, HashSecret(secret),
+	}
+
+	if req.QuotaDaily != 0 {
+		fields = append(fields, FieldQuotaDaily, req.QuotaDaily)
+	}
+
+	if req.QuotaMonthly != 0 {
+		fields = append(fields, FieldQuotaMonthly, req.QuotaMonthly)
+	}
+
+	if req.Sandbox {
+		expiresAt := created.CreatedAt.Add(s.sandboxTTL)
+
+		created.Env = EnvSandbox
+		created.ExpiresAt = &expiresAt
+		fields = append(fields, FieldEnv, EnvSandbox)
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, RecordKey(keyID), fields...)
+
+		if created.ExpiresAt != nil {
+			pipe.ExpireAt(ctx, RecordKey(keyID), *created.ExpiresAt)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("apikey: error save key: %w", err)
+	}
+
+	apiKey := keyID + "." + secret
+
+	if s.vault == nil {
+		created.APIKey = apiKey
+
+		return created, nil
+	}
+
+	created.VaultPath = s.vaultPath + "/" + keyID
+
+	secretData := map[string]interface{}{
+		"id":      keyID,
+		"name":    req.Name,
+		"api_key": apiKey,
+	}
+
+	if created.ExpiresAt != nil {
+		secretData["env"] = EnvSandbox
+		secretData["expires_at"] = created.ExpiresAt.Format(time.RFC3339)
+	}
+
+	err = s.vault.WriteKV(ctx, created.VaultPath, secretData)
+	if err != nil {
+		if delErr := s.client.Del(ctx, RecordKey(keyID)).Err(); delErr != nil {
+			err = errors.Join(err, fmt.Errorf("error delete key: %w", delErr))
+		}
+
+		return nil, fmt.Errorf("apikey: error write secret to vault: %w", err)
+	}
+
+	return created, nil
+}
@@ -0,0 +1,138 @@
+package apikey
+
+import (
+	"auth-service/internal/service/apikey/mocks"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedis(t *testing.T) (redis.UniversalClient, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client, mr
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	_, err := New()
+	require.ErrorContains(t, err, "redis client is required")
+
+	client, _ := newRedis(t)
+
+	_, err = New(WithClient(client), WithVault(mocks.NewMocksecretWriter(gomock.NewController(t)), ""))
+	require.ErrorContains(t, err, "vault path is required")
+
+	_, err = New(WithClient(client), WithSandboxTTL(48*time.Hour))
+	require.ErrorContains(t, err, "sandbox ttl must be in")
+}
+
+func TestCreate(t *testing.T) {
+	t.Parallel()
+
+	client, mr := newRedis(t)
+
+	s, err := New(WithClient(client))
+	require.NoError(t, err)
+
+	created, err := s.Create(t.Context(), CreateRequest{Name: "notes-bot", QuotaDaily: 100})
+	require.NoError(t, err)
+	require.Len(t, created.ID, idLength)
+	assert.Empty(t, created.VaultPath)
+
+	keyID, secret, ok := strings.Cut(created.APIKey, ".")
+	require.True(t, ok)
+	assert.Equal(t, created.ID, keyID)
+
+	assert.Equal(t, "notes-bot", mr.HGet(RecordKey(keyID), FieldName))
+	assert.Equal(t, HashSecret(secret), mr.HGet(RecordKey(keyID), FieldSecretHash))
+	assert.Equal(t, "100", mr.HGet(RecordKey(keyID), FieldQuotaDaily))
+	assert.Empty(t, mr.HGet(RecordKey(keyID), FieldQuotaMonthly))
+
+	_, err = s.Create(t.Context(), CreateRequest{})
+	require.ErrorIs(t, err, ErrInvalidArgument)
+
+	_, err = s.Create(t.Context(), CreateRequest{Name: "bot", QuotaMonthly: -1})
+	require.ErrorIs(t, err, ErrInvalidArgument)
+}
+
+func TestCreate_Sandbox(t *testing.T) {
+	t.Parallel()
+
+	client, mr := newRedis(t)
+
+	s, err := New(WithClient(client), WithSandboxTTL(time.Hour))
+	require.NoError(t, err)
+
+	created, err := s.Create(t.Context(), CreateRequest{Name: "partner-dev", Sandbox: true})
+	require.NoError(t, err)
+	assert.Equal(t, EnvSandbox, created.Env)
+	require.NotNil(t, created.ExpiresAt)
+	assert.Equal(t, created.CreatedAt.Add(time.Hour), *created.ExpiresAt)
+
+	assert.Equal(t, EnvSandbox, mr.HGet(RecordKey(created.ID), FieldEnv))
+	assert.InDelta(t, time.Hour.Seconds(), mr.TTL(RecordKey(created.ID)).Seconds(), 2)
+
+	// ключ песочницы удаляется по истечении TTL
+	mr.FastForward(time.Hour + time.Second)
+	assert.False(t, mr.Exists(RecordKey(created.ID)))
+
+	created, err = s.Create(t.Context(), CreateRequest{Name: "prod"})
+	require.NoError(t, err)
+	assert.Empty(t, created.Env)
+	assert.Nil(t, created.ExpiresAt)
+	assert.Zero(t, mr.TTL(RecordKey(created.ID)))
+}
+
+func TestCreate_Vault(t *testing.T) {
+	t.Parallel()
+
+	client, mr := newRedis(t)
+	ctrl := gomock.NewController(t)
+	writer := mocks.NewMocksecretWriter(ctrl)
+
+	s, err := New(WithClient(client), WithVault(writer, "secret/data/auth/apikeys/"))
+	require.NoError(t, err)
+
+	var written map[string]interface{}
+
+	writer.EXPECT().WriteKV(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, path string, data map[string]interface{}) error {
+			assert.True(t, strings.HasPrefix(path, "secret/data/auth/apikeys/"))
+
+			written = data
+
+			return nil
+		})
+
+	created, err := s.Create(t.Context(), CreateRequest{Name: "notes-bot"})
+	require.NoError(t, err)
+	assert.Empty(t, created.APIKey)
+	assert.Equal(t, "secret/data/auth/apikeys/"+created.ID, created.VaultPath)
+
+	apiKey, ok := written["api_key"].(string)
+	require.True(t, ok)
+
+	_, secret, _ := strings.Cut(apiKey, ".")
+	assert.Equal(t, HashSecret(secret), mr.HGet(RecordKey(created.ID), FieldSecretHash))
+
+	// ошибка записи в Vault - запись ключа удаляется
+	writer.EXPECT().WriteKV(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("permission denied"))
+
+	_, err = s.Create(t.Context(), CreateRequest{Name: "other-bot"})
+	require.ErrorContains(t, err, "permission denied")
+	assert.Len(t, mr.Keys(), 1)
+}
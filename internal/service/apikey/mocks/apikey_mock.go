@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: apikey.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MocksecretWriter is a mock of secretWriter interface.
+type MocksecretWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MocksecretWriterMockRecorder
+}
+
+// MocksecretWriterMockRecorder is the mock recorder for MocksecretWriter.
+type MocksecretWriterMockRecorder struct {
+	mock *MocksecretWriter
+}
+
+// NewMocksecretWriter creates a new mock instance.
+func NewMocksecretWriter(ctrl *gomock.Controller) *MocksecretWriter {
+	mock := &MocksecretWriter{ctrl: ctrl}
+	mock.recorder = &MocksecretWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocksecretWriter) EXPECT() *MocksecretWriterMockRecorder {
+	return m.recorder
+}
+
+// WriteKV mocks base method.
+func (m *MocksecretWriter) WriteKV(ctx context.Context, path string, data map[string]interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteKV", ctx, path, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteKV indicates an expected call of WriteKV.
+func (mr *MocksecretWriterMockRecorder) WriteKV(ctx, path, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteKV", reflect.TypeOf((*MocksecretWriter)(nil).WriteKV), ctx, path, data)
+}
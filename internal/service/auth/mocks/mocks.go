@@ -5,9 +5,95 @@
 package mocks
 
 import (
+	token "auth-service/internal/service/token"
+	context "context"
+	reflect "reflect"
+
 	gomock "github.com/golang/mock/gomock"
 )
 
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// IssueToken mocks base method.
+func (m *MockService) IssueToken(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueToken", ctx, req)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*token.Claims)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// IssueToken indicates an expected call of IssueToken.
+func (mr *MockServiceMockRecorder) IssueToken(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueToken", reflect.TypeOf((*MockService)(nil).IssueToken), ctx, req)
+}
+
+// Start mocks base method.
+func (m *MockService) Start(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockServiceMockRecorder) Start(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockService)(nil).Start), ctx)
+}
+
+// Stop mocks base method.
+func (m *MockService) Stop(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stop", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stop indicates an expected call of Stop.
+func (mr *MockServiceMockRecorder) Stop(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockService)(nil).Stop), ctx)
+}
+
+// ValidateToken mocks base method.
+func (m *MockService) ValidateToken(ctx context.Context, raw string) (*token.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateToken", ctx, raw)
+	ret0, _ := ret[0].(*token.Claims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidateToken indicates an expected call of ValidateToken.
+func (mr *MockServiceMockRecorder) ValidateToken(ctx, raw interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateToken", reflect.TypeOf((*MockService)(nil).ValidateToken), ctx, raw)
+}
+
 // MockvaultClient is a mock of vaultClient interface.
 type MockvaultClient struct {
 	ctrl     *gomock.Controller
@@ -30,3 +116,134 @@ func NewMockvaultClient(ctrl *gomock.Controller) *MockvaultClient {
 func (m *MockvaultClient) EXPECT() *MockvaultClientMockRecorder {
 	return m.recorder
 }
+
+// SigningKey mocks base method.
+func (m *MockvaultClient) SigningKey(ctx context.Context) (string, []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SigningKey", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].([]byte)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SigningKey indicates an expected call of SigningKey.
+func (mr *MockvaultClientMockRecorder) SigningKey(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SigningKey", reflect.TypeOf((*MockvaultClient)(nil).SigningKey), ctx)
+}
+
+// MockkeyRotator is a mock of keyRotator interface.
+type MockkeyRotator struct {
+	ctrl     *gomock.Controller
+	recorder *MockkeyRotatorMockRecorder
+}
+
+// MockkeyRotatorMockRecorder is the mock recorder for MockkeyRotator.
+type MockkeyRotatorMockRecorder struct {
+	mock *MockkeyRotator
+}
+
+// NewMockkeyRotator creates a new mock instance.
+func NewMockkeyRotator(ctrl *gomock.Controller) *MockkeyRotator {
+	mock := &MockkeyRotator{ctrl: ctrl}
+	mock.recorder = &MockkeyRotatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockkeyRotator) EXPECT() *MockkeyRotatorMockRecorder {
+	return m.recorder
+}
+
+// Rotate mocks base method.
+func (m *MockkeyRotator) Rotate(ctx context.Context) (*token.Rotation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rotate", ctx)
+	ret0, _ := ret[0].(*token.Rotation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rotate indicates an expected call of Rotate.
+func (mr *MockkeyRotatorMockRecorder) Rotate(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rotate", reflect.TypeOf((*MockkeyRotator)(nil).Rotate), ctx)
+}
+
+// MocktokenIssuer is a mock of tokenIssuer interface.
+type MocktokenIssuer struct {
+	ctrl     *gomock.Controller
+	recorder *MocktokenIssuerMockRecorder
+}
+
+// MocktokenIssuerMockRecorder is the mock recorder for MocktokenIssuer.
+type MocktokenIssuerMockRecorder struct {
+	mock *MocktokenIssuer
+}
+
+// NewMocktokenIssuer creates a new mock instance.
+func NewMocktokenIssuer(ctrl *gomock.Controller) *MocktokenIssuer {
+	mock := &MocktokenIssuer{ctrl: ctrl}
+	mock.recorder = &MocktokenIssuerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktokenIssuer) EXPECT() *MocktokenIssuerMockRecorder {
+	return m.recorder
+}
+
+// Issue mocks base method.
+func (m *MocktokenIssuer) Issue(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Issue", ctx, req)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*token.Claims)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Issue indicates an expected call of Issue.
+func (mr *MocktokenIssuerMockRecorder) Issue(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Issue", reflect.TypeOf((*MocktokenIssuer)(nil).Issue), ctx, req)
+}
+
+// MocktokenValidator is a mock of tokenValidator interface.
+type MocktokenValidator struct {
+	ctrl     *gomock.Controller
+	recorder *MocktokenValidatorMockRecorder
+}
+
+// MocktokenValidatorMockRecorder is the mock recorder for MocktokenValidator.
+type MocktokenValidatorMockRecorder struct {
+	mock *MocktokenValidator
+}
+
+// NewMocktokenValidator creates a new mock instance.
+func NewMocktokenValidator(ctrl *gomock.Controller) *MocktokenValidator {
+	mock := &MocktokenValidator{ctrl: ctrl}
+	mock.recorder = &MocktokenValidatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktokenValidator) EXPECT() *MocktokenValidatorMockRecorder {
+	return m.recorder
+}
+
+// Validate mocks base method.
+func (m *MocktokenValidator) Validate(ctx context.Context, raw string) (*token.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", ctx, raw)
+	ret0, _ := ret[0].(*token.Claims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MocktokenValidatorMockRecorder) Validate(ctx, raw interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MocktokenValidator)(nil).Validate), ctx, raw)
+}
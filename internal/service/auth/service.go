@@ -1,22 +1,69 @@
 package auth
 
 import (
+	"auth-service/internal/service/token"
+	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/sirupsen/logrus"
 )
 
+// Service - сервис для работы с авторизацией: выпуск и проверка jwt токенов и периодическое
+// обновление ключа авторизации из vault, а если задана ротация - замена устаревшего ключа новым.
+//
+//go:generate mockgen -source=service.go -destination=mocks/mocks.go -package=mocks
+type Service interface {
+	// IssueToken выпускает токен.
+	IssueToken(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error)
+	// ValidateToken проверяет токен и возвращает его claims.
+	ValidateToken(ctx context.Context, raw string) (*token.Claims, error)
+	// Start обновляет (или ротирует) ключ авторизации, пока не отменен ctx или не вызван Stop.
+	Start(ctx context.Context) error
+	// Stop останавливает обновление ключа и ждет его завершения.
+	Stop(ctx context.Context) error
+}
+
+// DefaultUpdateKeyInterval - периодичность обновления ключа авторизации по умолчанию.
+const DefaultUpdateKeyInterval = time.Minute
+
 // service - сервис для работы с авторизацией.
 // используется для получения ключа авторизации из vault и его обновления, а также для генерации jwt токенов.
 type service struct {
-	updateKeyInterval time.Duration // периодичность, с которой нужно обновлять ключ
-	vaultClient       vaultClient   // клиент для доступа к vault
+	updateKeyInterval time.Duration  // периодичность, с которой нужно обновлять ключ
+	vaultClient       vaultClient    // клиент для доступа к vault
+	issuer            tokenIssuer    // выпуск токенов
+	validator         tokenValidator // проверка токенов
+	rotator           keyRotator     // ротация ключа, nil - ключ только перечитывается
+
+	started  atomic.Bool
+	stopOnce sync.Once
+	stop     chan struct{} // закрывается в Stop
+	done     chan struct{} // закрывается, когда Start завершился
 }
 
 // vaultClient - интерфейс для доступа к vault.
-//
-//go:generate mockgen -source=service.go -destination=mocks/mocks.go -package=mocks
 type vaultClient interface {
-	// здесь методы для доступа к vault
+	// SigningKey перечитывает из vault и возвращает kid и ключ, которым подписываются новые токены.
+	SigningKey(ctx context.Context) (string, []byte, error)
+}
+
+// keyRotator - интерфейс для ротации ключа авторизации.
+type keyRotator interface {
+	// Rotate перечитывает ключи из vault и заменяет текущий ключ, если подошел срок. Возвращает nil, если ротация не нужна.
+	Rotate(ctx context.Context) (*token.Rotation, error)
+}
+
+// tokenIssuer - интерфейс для выпуска токенов.
+type tokenIssuer interface {
+	Issue(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error)
+}
+
+// tokenValidator - интерфейс для проверки токенов.
+type tokenValidator interface {
+	Validate(ctx context.Context, raw string) (*token.Claims, error)
 }
 
 type option func(*service)
@@ -35,9 +82,34 @@ func WithVaultClient(client vaultClient) option {
 	}
 }
 
+// WithKeyRotator включает ротацию ключа: с периодичностью обновления ключа вместо перечитывания
+// проверяется, не пора ли заменить текущий ключ новым.
+func WithKeyRotator(rotator keyRotator) option {
+	return func(s *service) {
+		s.rotator = rotator
+	}
+}
+
+// WithIssuer устанавливает выпуск токенов.
+func WithIssuer(issuer tokenIssuer) option {
+	return func(s *service) {
+		s.issuer = issuer
+	}
+}
+
+// WithValidator устанавливает проверку токенов.
+func WithValidator(validator tokenValidator) option {
+	return func(s *service) {
+		s.validator = validator
+	}
+}
+
 // New создает новый сервис для работы с авторизацией.
-func New(opts ...option) (*service, error) {
-	s := &service{}
+func New(opts ...option) (Service, error) {
+	s := &service{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
 
 	for _, opt := range opts {
 		opt(s)
@@ -51,5 +123,80 @@ func New(opts ...option) (*service, error) {
 		return nil, errors.New("vault client is required")
 	}
 
+	if s.issuer == nil {
+		return nil, errors.New("issuer is required")
+	}
+
+	if s.validator == nil {
+		return nil, errors.New("validator is required")
+	}
+
 	return s, nil
 }
+
+// IssueToken выпускает токен.
+func (s *service) IssueToken(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error) {
+	return s.issuer.Issue(ctx, req)
+}
+
+// ValidateToken проверяет токен и возвращает его claims.
+func (s *service) ValidateToken(ctx context.Context, raw string) (*token.Claims, error) {
+	return s.validator.Validate(ctx, raw)
+}
+
+// Start обновляет ключ авторизации с периодичностью updateKeyInterval, пока не отменен ctx
+// или не вызван Stop. Если задана ротация, ключ при этом заменяется, когда подошел срок.
+// Ошибка обновления или ротации не останавливает сервис: действует ранее прочитанный ключ.
+func (s *service) Start(ctx context.Context) error {
+	if !s.started.CompareAndSwap(false, true) {
+		return errors.New("auth service is already started")
+	}
+
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.updateKeyInterval)
+	defer ticker.Stop()
+
+	for {
+		s.updateKey(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// updateKey перечитывает ключ или, если задана ротация, ротирует его.
+func (s *service) updateKey(ctx context.Context) {
+	if s.rotator == nil {
+		if _, _, err := s.vaultClient.SigningKey(ctx); err != nil && ctx.Err() == nil {
+			logrus.WithError(err).Warn("error update signing key")
+		}
+
+		return
+	}
+
+	if _, err := s.rotator.Rotate(ctx); err != nil && ctx.Err() == nil {
+		logrus.WithError(err).Error("error rotate signing key, keeping current")
+	}
+}
+
+// Stop останавливает обновление ключа и ждет завершения Start. Если Start не запускался, сразу возвращает nil.
+func (s *service) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	if !s.started.Load() {
+		return nil
+	}
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -2,6 +2,9 @@ package auth
 
 import (
 	"auth-service/internal/service/auth/mocks"
+	"auth-service/internal/service/token"
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -10,50 +13,54 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+type testDeps struct {
+	vault     *mocks.MockvaultClient
+	issuer    *mocks.MocktokenIssuer
+	validator *mocks.MocktokenValidator
+}
+
+func newDeps(t *testing.T) testDeps {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+
+	return testDeps{
+		vault:     mocks.NewMockvaultClient(ctrl),
+		issuer:    mocks.NewMocktokenIssuer(ctrl),
+		validator: mocks.NewMocktokenValidator(ctrl),
+	}
+}
+
 //nolint:funlen // длинный тест - это ок
 func TestNewService(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
 		name       string
-		createOpts func(t *testing.T, mockVaultClient *mocks.MockvaultClient) []option
-		createWant func(t *testing.T, mockVaultClient *mocks.MockvaultClient) *service
+		createOpts func(deps testDeps) []option
 		wantErr    require.ErrorAssertionFunc
 	}{
 		{
 			name: "positive case",
-			createOpts: func(t *testing.T, mockVaultClient *mocks.MockvaultClient) []option {
-				t.Helper()
-
+			createOpts: func(deps testDeps) []option {
 				return []option{
 					WithUpdateKeyInterval(1 * time.Second),
-					WithVaultClient(mockVaultClient),
-				}
-			},
-			createWant: func(t *testing.T, mockVaultClient *mocks.MockvaultClient) *service {
-				t.Helper()
-
-				return &service{
-					updateKeyInterval: 1 * time.Second,
-					vaultClient:       mockVaultClient,
+					WithVaultClient(deps.vault),
+					WithIssuer(deps.issuer),
+					WithValidator(deps.validator),
 				}
 			},
 			wantErr: require.NoError,
 		},
 		{
 			name: "error case: update key interval is required",
-			createOpts: func(t *testing.T, mockVaultClient *mocks.MockvaultClient) []option {
-				t.Helper()
-
+			createOpts: func(deps testDeps) []option {
 				return []option{
-					WithVaultClient(mockVaultClient),
+					WithVaultClient(deps.vault),
+					WithIssuer(deps.issuer),
+					WithValidator(deps.validator),
 				}
 			},
-			createWant: func(t *testing.T, mockVaultClient *mocks.MockvaultClient) *service {
-				t.Helper()
-
-				return nil
-			},
 			wantErr: func(t require.TestingT, err error, i ...interface{}) {
 				require.Error(t, err)
 				require.ErrorContains(t, err, "update key interval is required")
@@ -61,38 +68,160 @@ func TestNewService(t *testing.T) {
 		},
 		{
 			name: "error case: vault client is required",
-			createOpts: func(t *testing.T, mockVaultClient *mocks.MockvaultClient) []option {
-				t.Helper()
-
+			createOpts: func(deps testDeps) []option {
 				return []option{
 					WithUpdateKeyInterval(1 * time.Second),
+					WithIssuer(deps.issuer),
+					WithValidator(deps.validator),
 				}
 			},
-			createWant: func(t *testing.T, mockVaultClient *mocks.MockvaultClient) *service {
-				t.Helper()
-
-				return nil
-			},
 			wantErr: func(t require.TestingT, err error, i ...interface{}) {
 				require.Error(t, err)
 				require.ErrorContains(t, err, "vault client is required")
 			},
 		},
+		{
+			name: "error case: issuer is required",
+			createOpts: func(deps testDeps) []option {
+				return []option{
+					WithUpdateKeyInterval(1 * time.Second),
+					WithVaultClient(deps.vault),
+					WithValidator(deps.validator),
+				}
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "issuer is required")
+			},
+		},
+		{
+			name: "error case: validator is required",
+			createOpts: func(deps testDeps) []option {
+				return []option{
+					WithUpdateKeyInterval(1 * time.Second),
+					WithVaultClient(deps.vault),
+					WithIssuer(deps.issuer),
+				}
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "validator is required")
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
+			deps := newDeps(t)
 
-			mockVaultClient := mocks.NewMockvaultClient(ctrl)
-
-			got, err := New(tt.createOpts(t, mockVaultClient)...)
+			got, err := New(tt.createOpts(deps)...)
 			tt.wantErr(t, err)
 
-			assert.Equal(t, tt.createWant(t, mockVaultClient), got)
+			if err != nil {
+				assert.Nil(t, got)
+				return
+			}
+
+			s, ok := got.(*service)
+			require.True(t, ok)
+			assert.Equal(t, 1*time.Second, s.updateKeyInterval)
+			assert.Equal(t, deps.vault, s.vaultClient)
 		})
 	}
 }
+
+func TestService_Token(t *testing.T) {
+	t.Parallel()
+
+	deps := newDeps(t)
+
+	s, err := New(WithUpdateKeyInterval(time.Second), WithVaultClient(deps.vault), WithIssuer(deps.issuer), WithValidator(deps.validator))
+	require.NoError(t, err)
+
+	req := token.IssueRequest{Subject: "user-1"}
+	claims := &token.Claims{Subject: "user-1"}
+
+	deps.issuer.EXPECT().Issue(gomock.Any(), req).Return("raw", claims, nil)
+	deps.validator.EXPECT().Validate(gomock.Any(), "raw").Return(claims, nil)
+
+	raw, got, err := s.IssueToken(t.Context(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "raw", raw)
+	assert.Equal(t, claims, got)
+
+	got, err = s.ValidateToken(t.Context(), raw)
+	require.NoError(t, err)
+	assert.Equal(t, claims, got)
+}
+
+func TestService_StartStop(t *testing.T) {
+	t.Parallel()
+
+	deps := newDeps(t)
+
+	s, err := New(WithUpdateKeyInterval(time.Millisecond), WithVaultClient(deps.vault), WithIssuer(deps.issuer), WithValidator(deps.validator))
+	require.NoError(t, err)
+
+	// ошибка обновления ключа не останавливает сервис
+	updated := make(chan struct{})
+
+	deps.vault.EXPECT().SigningKey(gomock.Any()).Return("", nil, errors.New("vault is down"))
+	deps.vault.EXPECT().SigningKey(gomock.Any()).DoAndReturn(func(context.Context) (string, []byte, error) {
+		close(updated)
+
+		return "kid", []byte("key"), nil
+	})
+	deps.vault.EXPECT().SigningKey(gomock.Any()).Return("kid", []byte("key"), nil).AnyTimes()
+
+	started := make(chan error, 1)
+
+	go func() {
+		started <- s.Start(t.Context())
+	}()
+
+	<-updated
+
+	require.NoError(t, s.Stop(t.Context()))
+	require.NoError(t, <-started)
+
+	require.Error(t, s.Start(t.Context()), "service is already started")
+	require.NoError(t, s.Stop(t.Context()))
+}
+
+func TestService_Rotation(t *testing.T) {
+	t.Parallel()
+
+	deps := newDeps(t)
+	rotator := mocks.NewMockkeyRotator(gomock.NewController(t))
+
+	s, err := New(
+		WithUpdateKeyInterval(time.Millisecond),
+		WithVaultClient(deps.vault),
+		WithKeyRotator(rotator),
+		WithIssuer(deps.issuer),
+		WithValidator(deps.validator),
+	)
+	require.NoError(t, err)
+
+	// с ротацией ключ не перечитывается отдельно, ошибка ротации не останавливает сервис
+	rotated := make(chan struct{})
+
+	rotator.EXPECT().Rotate(gomock.Any()).Return(nil, errors.New("permission denied"))
+	rotator.EXPECT().Rotate(gomock.Any()).DoAndReturn(func(context.Context) (*token.Rotation, error) {
+		close(rotated)
+
+		return &token.Rotation{Kid: "k2", Previous: "k1"}, nil
+	})
+	rotator.EXPECT().Rotate(gomock.Any()).Return(nil, nil).AnyTimes()
+
+	started := make(chan error, 1)
+
+	go func() {
+		started <- s.Start(t.Context())
+	}()
+
+	<-rotated
+
+	require.NoError(t, s.Stop(t.Context()))
+	require.NoError(t, <-started)
+}
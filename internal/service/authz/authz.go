@@ -0,0 +1,229 @@
+// Package authz принимает решения о доступе: может ли субъект выполнить действие над ресурсом.
+// Решение учитывает scopes токена и роли субъекта в группах, чтобы другие сервисы
+// не дублировали у себя логику авторизации.
+package authz
+
+import (
+	"auth-service/internal/service/group"
+	"auth-service/internal/service/policy"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ResourceGroup - тип ресурса "группа": доступ определяется ролью субъекта в группе.
+const ResourceGroup = "group"
+
+// ErrInvalidRequest - запрос на проверку заполнен некорректно.
+var ErrInvalidRequest = errors.New("invalid authz request")
+
+// Причины решений.
+const (
+	ReasonAllowed         = "allowed"
+	ReasonScope           = "action is not allowed by token scopes"
+	ReasonNotMember       = "subject is not a member of the group"
+	ReasonRole            = "action is not allowed for subject role"
+	ReasonUnknownResource = "unknown resource type"
+	ReasonPolicyAllowed   = "allowed by policy"
+	ReasonPolicyDenied    = "denied by policy"
+)
+
+// groupSource - источник членства в группах.
+//
+//go:generate mockgen -source=authz.go -destination=mocks/authz_mock.go -package=mocks
+type groupSource interface {
+	Memberships(ctx context.Context, userID string) (map[string]string, error)
+}
+
+// policyEngine - движок политик. Если задан, решение принимает он, а не встроенные правила групп.
+type policyEngine interface {
+	Enforce(in policy.Input) (bool, error)
+}
+
+// Request - запрос на проверку доступа.
+type Request struct {
+	Subject string
+	Action  string
+	// Resource - ресурс в формате <тип>:<id>, например group:abc.
+	Resource string
+	// Scopes - scopes токена. nil означает, что субъект проверяется без токена и scopes не ограничивают доступ.
+	Scopes []string
+	// Groups - роли субъекта в группах из токена. Если nil, читаются из хранилища групп.
+	Groups map[string]string
+	// Owner - владелец ресурса, если он известен вызывающему сервису. Доступен политикам.
+	Owner string
+}
+
+// Decision - решение о доступе.
+type Decision struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason"`
+}
+
+// Service - сервис проверки доступа.
+type Service struct {
+	groups      groupSource
+	policy      policyEngine
+	decisionLog bool
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Service.
+type Option func(*Service)
+
+// WithGroups устанавливает источник членства в группах.
+func WithGroups(groups groupSource) Option {
+	return func(s *Service) {
+		s.groups = groups
+	}
+}
+
+// WithPolicy устанавливает движок политик.
+func WithPolicy(engine policyEngine) Option {
+	return func(s *Service) {
+		s.policy = engine
+	}
+}
+
+// WithDecisionLog включает запись каждого решения в лог.
+func WithDecisionLog(enabled bool) Option {
+	return func(s *Service) {
+		s.decisionLog = enabled
+	}
+}
+
+// New создает новый Service.
+func New(opts ...Option) (*Service, error) {
+	s := &Service{now: time.Now}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.groups == nil {
+		return nil, errors.New("groups are required")
+	}
+
+	return s, nil
+}
+
+// Check проверяет, может ли субъект выполнить действие над ресурсом.
+func (s *Service) Check(ctx context.Context, req Request) (Decision, error) {
+	decision, source, err := s.decide(ctx, req)
+	if err != nil {
+		return Decision{}, err
+	}
+
+	if s.decisionLog {
+		logrus.WithFields(logrus.Fields{
+			"subject":  req.Subject,
+			"action":   req.Action,
+			"resource": req.Resource,
+			"allowed":  decision.Allowed,
+			"reason":   decision.Reason,
+			"source":   source,
+		}).Info("authz decision")
+	}
+
+	return decision, nil
+}
+
+// decide принимает решение и возвращает, кем оно принято: scopes, policy или builtin.
+func (s *Service) decide(ctx context.Context, req Request) (Decision, string, error) {
+	resourceType, resourceID, ok := strings.Cut(req.Resource, ":")
+
+	switch {
+	case req.Subject == "":
+		return Decision{}, "", fmt.Errorf("%w: subject is required", ErrInvalidRequest)
+	case req.Action == "":
+		return Decision{}, "", fmt.Errorf("%w: action is required", ErrInvalidRequest)
+	case !ok || resourceType == "" || resourceID == "":
+		return Decision{}, "", fmt.Errorf("%w: resource must be in format <type>:<id>", ErrInvalidRequest)
+	}
+
+	if req.Scopes != nil && !scopeAllows(req.Scopes, req.Action, resourceType) {
+		return Decision{Reason: ReasonScope}, "scopes", nil
+	}
+
+	var (
+		role     string
+		isMember bool
+	)
+
+	if resourceType == ResourceGroup {
+		var err error
+
+		role, isMember, err = s.groupRole(ctx, req, resourceID)
+		if err != nil {
+			return Decision{}, "", err
+		}
+	}
+
+	if s.policy != nil {
+		now := s.now().UTC()
+
+		allowed, err := s.policy.Enforce(policy.Input{
+			Subject:  req.Subject,
+			Resource: req.Resource,
+			Action:   req.Action,
+			Env: policy.Env{
+				Role:         role,
+				ResourceType: resourceType,
+				ResourceID:   resourceID,
+				Owner:        req.Owner,
+				Hour:         now.Hour(),
+				Weekday:      int(now.Weekday()),
+			},
+		})
+		if err != nil {
+			return Decision{}, "", fmt.Errorf("authz: %w", err)
+		}
+
+		if allowed {
+			return Decision{Allowed: true, Reason: ReasonPolicyAllowed}, "policy", nil
+		}
+
+		return Decision{Reason: ReasonPolicyDenied}, "policy", nil
+	}
+
+	switch {
+	case resourceType != ResourceGroup:
+		return Decision{Reason: ReasonUnknownResource}, "builtin", nil
+	case !isMember:
+		return Decision{Reason: ReasonNotMember}, "builtin", nil
+	case !group.Role(role).Allows(group.Action(req.Action)):
+		return Decision{Reason: ReasonRole}, "builtin", nil
+	}
+
+	return Decision{Allowed: true, Reason: ReasonAllowed}, "builtin", nil
+}
+
+// groupRole возвращает роль субъекта в группе: из токена или из хранилища групп.
+func (s *Service) groupRole(ctx context.Context, req Request, groupID string) (string, bool, error) {
+	groups := req.Groups
+
+	if groups == nil {
+		var err error
+
+		groups, err = s.groups.Memberships(ctx, req.Subject)
+		if err != nil {
+			return "", false, fmt.Errorf("authz: error get groups: %w", err)
+		}
+	}
+
+	role, ok := groups[groupID]
+
+	return role, ok, nil
+}
+
+// scopeAllows возвращает true, если среди scopes есть действие целиком ("read")
+// или действие над типом ресурса ("read:group").
+func scopeAllows(scopes []string, action, resourceType string) bool {
+	return slices.Contains(scopes, action) || slices.Contains(scopes, action+":"+resourceType)
+}
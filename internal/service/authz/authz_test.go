@@ -0,0 +1,209 @@
+package authz
+
+import (
+	"auth-service/internal/service/authz/mocks"
+	"auth-service/internal/service/policy"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	_, err := New()
+	require.Error(t, err)
+
+	_, err = New(WithGroups(mocks.NewMockgroupSource(gomock.NewController(t))))
+	require.NoError(t, err)
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService_Check(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		req     Request
+		setup   func(groups *mocks.MockgroupSource)
+		want    Decision
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "positive case: role from storage",
+			req:  Request{Subject: "user-1", Action: "write", Resource: "group:g1"},
+			setup: func(groups *mocks.MockgroupSource) {
+				groups.EXPECT().Memberships(gomock.Any(), "user-1").Return(map[string]string{"g1": "editor"}, nil)
+			},
+			want:    Decision{Allowed: true, Reason: ReasonAllowed},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: role from token",
+			req:     Request{Subject: "user-1", Action: "read", Resource: "group:g1", Groups: map[string]string{"g1": "viewer"}},
+			want:    Decision{Allowed: true, Reason: ReasonAllowed},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: scope for resource type",
+			req: Request{
+				Subject: "user-1", Action: "write", Resource: "group:g1",
+				Scopes: []string{"write:group"}, Groups: map[string]string{"g1": "owner"},
+			},
+			want:    Decision{Allowed: true, Reason: ReasonAllowed},
+			wantErr: require.NoError,
+		},
+		{
+			name: "negative case: read-only scopes",
+			req: Request{
+				Subject: "user-1", Action: "write", Resource: "group:g1",
+				Scopes: []string{"read"}, Groups: map[string]string{"g1": "owner"},
+			},
+			want:    Decision{Reason: ReasonScope},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: role does not allow action",
+			req:     Request{Subject: "user-1", Action: "manage", Resource: "group:g1", Groups: map[string]string{"g1": "editor"}},
+			want:    Decision{Reason: ReasonRole},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: not a member",
+			req:     Request{Subject: "user-1", Action: "read", Resource: "group:g2", Groups: map[string]string{"g1": "owner"}},
+			want:    Decision{Reason: ReasonNotMember},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: unknown resource type",
+			req:     Request{Subject: "user-1", Action: "read", Resource: "note:n1"},
+			want:    Decision{Reason: ReasonUnknownResource},
+			wantErr: require.NoError,
+		},
+		{
+			name: "error case: storage error",
+			req:  Request{Subject: "user-1", Action: "read", Resource: "group:g1"},
+			setup: func(groups *mocks.MockgroupSource) {
+				groups.EXPECT().Memberships(gomock.Any(), "user-1").Return(nil, errors.New("redis is down"))
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "error case: invalid resource",
+			req:  Request{Subject: "user-1", Action: "read", Resource: "group"},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrInvalidRequest)
+			},
+		},
+		{
+			name: "error case: no subject",
+			req:  Request{Action: "read", Resource: "group:g1"},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrInvalidRequest)
+			},
+		},
+		{
+			name: "error case: no action",
+			req:  Request{Subject: "user-1", Resource: "group:g1"},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrInvalidRequest)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			groups := mocks.NewMockgroupSource(gomock.NewController(t))
+			if tt.setup != nil {
+				tt.setup(groups)
+			}
+
+			s, err := New(WithGroups(groups))
+			require.NoError(t, err)
+
+			got, err := s.Check(t.Context(), tt.req)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService_Check_Policy(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 6, 22, 30, 0, 0, time.UTC) // понедельник
+
+	tests := []struct {
+		name    string
+		req     Request
+		setup   func(engine *mocks.MockpolicyEngine)
+		want    Decision
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "positive case: allowed by policy",
+			req: Request{
+				Subject: "user-1", Action: "write", Resource: "group:g1",
+				Groups: map[string]string{"g1": "editor"}, Owner: "user-2",
+			},
+			setup: func(engine *mocks.MockpolicyEngine) {
+				engine.EXPECT().Enforce(policy.Input{
+					Subject: "user-1", Resource: "group:g1", Action: "write",
+					Env: policy.Env{Role: "editor", ResourceType: "group", ResourceID: "g1", Owner: "user-2", Hour: 22, Weekday: 1},
+				}).Return(true, nil)
+			},
+			want:    Decision{Allowed: true, Reason: ReasonPolicyAllowed},
+			wantErr: require.NoError,
+		},
+		{
+			name: "negative case: denied by policy",
+			req:  Request{Subject: "user-1", Action: "read", Resource: "note:n1"},
+			setup: func(engine *mocks.MockpolicyEngine) {
+				engine.EXPECT().Enforce(gomock.Any()).Return(false, nil)
+			},
+			want:    Decision{Reason: ReasonPolicyDenied},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: scopes are checked before policy",
+			req:     Request{Subject: "user-1", Action: "write", Resource: "note:n1", Scopes: []string{"read"}},
+			setup:   func(engine *mocks.MockpolicyEngine) {},
+			want:    Decision{Reason: ReasonScope},
+			wantErr: require.NoError,
+		},
+		{
+			name: "error case: policy error",
+			req:  Request{Subject: "user-1", Action: "read", Resource: "note:n1"},
+			setup: func(engine *mocks.MockpolicyEngine) {
+				engine.EXPECT().Enforce(gomock.Any()).Return(false, errors.New("invalid matcher"))
+			},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			engine := mocks.NewMockpolicyEngine(ctrl)
+			tt.setup(engine)
+
+			s, err := New(WithGroups(mocks.NewMockgroupSource(ctrl)), WithPolicy(engine), WithDecisionLog(true))
+			require.NoError(t, err)
+
+			s.now = func() time.Time { return now }
+
+			got, err := s.Check(t.Context(), tt.req)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
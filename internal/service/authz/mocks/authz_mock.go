@@ -0,0 +1,89 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: authz.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	policy "auth-service/internal/service/policy"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockgroupSource is a mock of groupSource interface.
+type MockgroupSource struct {
+	ctrl     *gomock.Controller
+	recorder *MockgroupSourceMockRecorder
+}
+
+// MockgroupSourceMockRecorder is the mock recorder for MockgroupSource.
+type MockgroupSourceMockRecorder struct {
+	mock *MockgroupSource
+}
+
+// NewMockgroupSource creates a new mock instance.
+func NewMockgroupSource(ctrl *gomock.Controller) *MockgroupSource {
+	mock := &MockgroupSource{ctrl: ctrl}
+	mock.recorder = &MockgroupSourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockgroupSource) EXPECT() *MockgroupSourceMockRecorder {
+	return m.recorder
+}
+
+// Memberships mocks base method.
+func (m *MockgroupSource) Memberships(ctx context.Context, userID string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Memberships", ctx, userID)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Memberships indicates an expected call of Memberships.
+func (mr *MockgroupSourceMockRecorder) Memberships(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Memberships", reflect.TypeOf((*MockgroupSource)(nil).Memberships), ctx, userID)
+}
+
+// MockpolicyEngine is a mock of policyEngine interface.
+type MockpolicyEngine struct {
+	ctrl     *gomock.Controller
+	recorder *MockpolicyEngineMockRecorder
+}
+
+// MockpolicyEngineMockRecorder is the mock recorder for MockpolicyEngine.
+type MockpolicyEngineMockRecorder struct {
+	mock *MockpolicyEngine
+}
+
+// NewMockpolicyEngine creates a new mock instance.
+func NewMockpolicyEngine(ctrl *gomock.Controller) *MockpolicyEngine {
+	mock := &MockpolicyEngine{ctrl: ctrl}
+	mock.recorder = &MockpolicyEngineMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockpolicyEngine) EXPECT() *MockpolicyEngineMockRecorder {
+	return m.recorder
+}
+
+// Enforce mocks base method.
+func (m *MockpolicyEngine) Enforce(in policy.Input) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enforce", in)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enforce indicates an expected call of Enforce.
+func (mr *MockpolicyEngineMockRecorder) Enforce(in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enforce", reflect.TypeOf((*MockpolicyEngine)(nil).Enforce), in)
+}
@@ -0,0 +1,281 @@
+// Package backup выгружает и загружает состояние сервиса, хранящееся в Redis (сессии, API ключи,
+// refresh токены, группы), для миграции между кластерами Redis и учений по восстановлению.
+// Снимок шифруется AES-256-GCM, поэтому файл резервной копии можно хранить вне защищенного контура.
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// DefaultMatch - шаблон ключей состояния сервиса.
+	DefaultMatch = "auth:*"
+
+	snapshotVersion = 1
+	scanCount       = 500
+)
+
+// Типы ключей Redis, которые поддерживает резервная копия.
+const (
+	typeString = "string"
+	typeHash   = "hash"
+	typeSet    = "set"
+	typeZSet   = "zset"
+	typeList   = "list"
+)
+
+// ErrUnsupportedType - ключ имеет тип, который не поддерживается резервной копией.
+var ErrUnsupportedType = errors.New("unsupported key type")
+
+// Snapshot - снимок ключей Redis.
+type Snapshot struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Match     string    `json:"match"`
+	Entries   []Entry   `json:"entries"`
+}
+
+// Entry - ключ Redis со значением и оставшимся временем жизни.
+type Entry struct {
+	Key  string `json:"key"`
+	Type string `json:"type"`
+	// TTL - оставшееся время жизни в миллисекундах на момент снимка, 0 - без ограничения.
+	TTL int64 `json:"ttl_ms,omitempty"`
+
+	String string            `json:"string,omitempty"`
+	Hash   map[string]string `json:"hash,omitempty"`
+	Set    []string          `json:"set,omitempty"`
+	List   []string          `json:"list,omitempty"`
+	ZSet   []redis.Z         `json:"zset,omitempty"`
+}
+
+// Result - итог восстановления.
+type Result struct {
+	Restored int `json:"restored"`
+	// Skipped - ключи, которые уже существуют и не были перезаписаны.
+	Skipped int `json:"skipped"`
+}
+
+// Backup - выгрузка и загрузка ключей Redis.
+type Backup struct {
+	client redis.UniversalClient
+	match  string
+	now    func() time.Time
+}
+
+// Option - опция для настройки Backup.
+type Option func(*Backup)
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(b *Backup) {
+		b.client = client
+	}
+}
+
+// WithMatch устанавливает шаблон выгружаемых ключей. По умолчанию auth:*.
+func WithMatch(match string) Option {
+	return func(b *Backup) {
+		b.match = match
+	}
+}
+
+// New создает новый Backup.
+func New(opts ...Option) (*Backup, error) {
+	b := &Backup{match: DefaultMatch, now: time.Now}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	if b.client == nil {
+		return nil, errors.New("redis client is required")
+	}
+
+	if b.match == "" {
+		return nil, errors.New("match is required")
+	}
+
+	return b, nil
+}
+
+// Dump выгружает все ключи, подходящие под шаблон. В кластере обходятся все мастер-узлы.
+func (b *Backup) Dump(ctx context.Context) (*Snapshot, error) {
+	snapshot := &Snapshot{
+		Version:   snapshotVersion,
+		CreatedAt: b.now().UTC(),
+		Match:     b.match,
+	}
+
+	var mu sync.Mutex
+
+	err := Scan(ctx, b.client, b.match, func(ctx context.Context, client redis.UniversalClient, key string) error {
+		entry, err := ReadEntry(ctx, client, key)
+		if errors.Is(err, redis.Nil) {
+			// ключ истек или удален во время обхода
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		snapshot.Entries = append(snapshot.Entries, entry)
+		mu.Unlock()
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("backup: error dump: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// Scan вызывает fn для каждого ключа, подходящего под шаблон. В кластере обходятся все мастер-узлы
+// (параллельно), fn получает клиент узла, на котором находится ключ.
+func Scan(ctx context.Context, client redis.UniversalClient, match string, fn func(ctx context.Context, client redis.UniversalClient, key string) error) error {
+	scan := func(ctx context.Context, client redis.UniversalClient) error {
+		iter := client.Scan(ctx, 0, match, scanCount).Iterator()
+
+		for iter.Next(ctx) {
+			if err := fn(ctx, client, iter.Val()); err != nil {
+				return err
+			}
+		}
+
+		return iter.Err()
+	}
+
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return scan(ctx, node)
+		})
+	}
+
+	return scan(ctx, client)
+}
+
+// ReadEntry читает ключ вместе с оставшимся временем жизни. Элементы множества сортируются,
+// чтобы записи можно было сравнивать. Если ключа нет, возвращает redis.Nil.
+func ReadEntry(ctx context.Context, client redis.UniversalClient, key string) (Entry, error) {
+	typ, err := client.Type(ctx, key).Result()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{Key: key, Type: typ}
+
+	switch typ {
+	case "none":
+		return Entry{}, redis.Nil
+	case typeString:
+		entry.String, err = client.Get(ctx, key).Result()
+	case typeHash:
+		entry.Hash, err = client.HGetAll(ctx, key).Result()
+	case typeSet:
+		entry.Set, err = client.SMembers(ctx, key).Result()
+		slices.Sort(entry.Set)
+	case typeZSet:
+		entry.ZSet, err = client.ZRangeWithScores(ctx, key, 0, -1).Result()
+	case typeList:
+		entry.List, err = client.LRange(ctx, key, 0, -1).Result()
+	default:
+		return Entry{}, fmt.Errorf("%w %q: %s", ErrUnsupportedType, typ, key)
+	}
+
+	if err != nil {
+		return Entry{}, err
+	}
+
+	ttl, err := client.PTTL(ctx, key).Result()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if ttl > 0 {
+		entry.TTL = ttl.Milliseconds()
+	}
+
+	return entry, nil
+}
+
+// Restore загружает ключи из снимка. Существующие ключи перезаписываются, только если replace.
+// Каждый ключ восстанавливается в отдельной транзакции вместе со временем жизни.
+func (b *Backup) Restore(ctx context.Context, snapshot *Snapshot, replace bool) (Result, error) {
+	var res Result
+
+	if snapshot.Version != snapshotVersion {
+		return res, fmt.Errorf("backup: unsupported snapshot version %d", snapshot.Version)
+	}
+
+	for _, entry := range snapshot.Entries {
+		if !replace {
+			exists, err := b.client.Exists(ctx, entry.Key).Result()
+			if err != nil {
+				return res, fmt.Errorf("backup: error check key %s: %w", entry.Key, err)
+			}
+
+			if exists != 0 {
+				res.Skipped++
+
+				continue
+			}
+		}
+
+		if err := WriteEntry(ctx, b.client, entry); err != nil {
+			return res, fmt.Errorf("backup: error restore key %s: %w", entry.Key, err)
+		}
+
+		res.Restored++
+	}
+
+	return res, nil
+}
+
+// WriteEntry записывает ключ вместе со временем жизни в одной транзакции, заменяя существующий.
+func WriteEntry(ctx context.Context, client redis.UniversalClient, entry Entry) error {
+	_, err := client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.Del(ctx, entry.Key)
+
+		switch entry.Type {
+		case typeString:
+			p.Set(ctx, entry.Key, entry.String, 0)
+		case typeHash:
+			p.HSet(ctx, entry.Key, entry.Hash)
+		case typeSet:
+			p.SAdd(ctx, entry.Key, toAny(entry.Set)...)
+		case typeZSet:
+			p.ZAdd(ctx, entry.Key, entry.ZSet...)
+		case typeList:
+			p.RPush(ctx, entry.Key, toAny(entry.List)...)
+		default:
+			return fmt.Errorf("%w %q", ErrUnsupportedType, entry.Type)
+		}
+
+		if entry.TTL > 0 {
+			p.PExpire(ctx, entry.Key, time.Duration(entry.TTL)*time.Millisecond)
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+func toAny(values []string) []any {
+	res := make([]any, len(values))
+	for i, v := range values {
+		res[i] = v
+	}
+
+	return res
+}
@@ -0,0 +1,116 @@
+package backup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedis(t *testing.T) (redis.UniversalClient, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client, mr
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	_, err := New()
+	require.Error(t, err)
+
+	client, _ := newRedis(t)
+
+	_, err = New(WithClient(client), WithMatch(""))
+	require.Error(t, err)
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestDumpRestore(t *testing.T) {
+	t.Parallel()
+
+	src, srcMr := newRedis(t)
+	ctx := t.Context()
+
+	srcMr.Set("auth:session:1", "payload")
+	srcMr.SetTTL("auth:session:1", time.Hour)
+	srcMr.HSet("auth:apikey:bot", "secret_hash", "abc", "quota_daily", "10")
+	_, err := srcMr.SAdd("auth:user:1:sessions", "1", "2")
+	require.NoError(t, err)
+	_, err = srcMr.ZAdd("auth:refresh:index", 1.5, "r1")
+	require.NoError(t, err)
+	_, err = srcMr.Push("auth:events", "a", "b")
+	require.NoError(t, err)
+	srcMr.Set("other:key", "not backed up")
+
+	b, err := New(WithClient(src))
+	require.NoError(t, err)
+
+	snapshot, err := b.Dump(ctx)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Entries, 5)
+	assert.Equal(t, DefaultMatch, snapshot.Match)
+
+	dst, dstMr := newRedis(t)
+	dstMr.Set("auth:session:1", "stale")
+
+	restorer, err := New(WithClient(dst))
+	require.NoError(t, err)
+
+	res, err := restorer.Restore(ctx, snapshot, false)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Restored: 4, Skipped: 1}, res)
+
+	got, err := dstMr.Get("auth:session:1")
+	require.NoError(t, err)
+	assert.Equal(t, "stale", got)
+
+	res, err = restorer.Restore(ctx, snapshot, true)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Restored: 5}, res)
+
+	got, err = dstMr.Get("auth:session:1")
+	require.NoError(t, err)
+	assert.Equal(t, "payload", got)
+	assert.Equal(t, time.Hour, dstMr.TTL("auth:session:1"))
+
+	assert.Equal(t, "10", dstMr.HGet("auth:apikey:bot", "quota_daily"))
+
+	members, err := dstMr.Members("auth:user:1:sessions")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, members)
+
+	score, err := dstMr.ZScore("auth:refresh:index", "r1")
+	require.NoError(t, err)
+	assert.InDelta(t, 1.5, score, 0)
+
+	list, err := dstMr.List("auth:events")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, list)
+
+	assert.False(t, dstMr.Exists("other:key"))
+	assert.Equal(t, time.Duration(0), dstMr.TTL("auth:apikey:bot"))
+}
+
+func TestRestore_UnsupportedVersion(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newRedis(t)
+
+	b, err := New(WithClient(client))
+	require.NoError(t, err)
+
+	_, err = b.Restore(t.Context(), &Snapshot{Version: 99}, false)
+	require.ErrorContains(t, err, "unsupported snapshot version")
+
+	_, err = b.Restore(t.Context(), &Snapshot{Version: snapshotVersion, Entries: []Entry{{Key: "auth:x", Type: "stream"}}}, false)
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
@@ -0,0 +1,95 @@
+package backup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// KeySize - размер ключа шифрования (AES-256).
+const KeySize = 32
+
+// magic - сигнатура и версия формата файла резервной копии.
+var magic = []byte("AUTHBK1\n")
+
+// ErrInvalidKey - ключ шифрования неверного размера.
+var ErrInvalidKey = errors.New("backup key must be 32 bytes")
+
+// ErrDecrypt - файл поврежден или зашифрован другим ключом.
+var ErrDecrypt = errors.New("backup: unable to decrypt snapshot: wrong key or corrupted file")
+
+// Encode шифрует снимок и записывает его в w: сигнатура, nonce, шифртекст JSON.
+func Encode(w io.Writer, snapshot *Snapshot, key []byte) error {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	plain, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("backup: error marshal snapshot: %w", err)
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("backup: error generate nonce: %w", err)
+	}
+
+	for _, chunk := range [][]byte{magic, nonce, aead.Seal(nil, nonce, plain, magic)} {
+		if _, err := w.Write(chunk); err != nil {
+			return fmt.Errorf("backup: error write snapshot: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Decode читает и расшифровывает снимок, записанный Encode.
+func Decode(r io.Reader, key []byte) (*Snapshot, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("backup: error read snapshot: %w", err)
+	}
+
+	if len(data) < len(magic)+aead.NonceSize() || string(data[:len(magic)]) != string(magic) {
+		return nil, errors.New("backup: not a backup file")
+	}
+
+	data = data[len(magic):]
+
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], magic)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	var snapshot Snapshot
+
+	if err := json.Unmarshal(plain, &snapshot); err != nil {
+		return nil, fmt.Errorf("backup: error unmarshal snapshot: %w", err)
+	}
+
+	return &snapshot, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("backup: error create cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
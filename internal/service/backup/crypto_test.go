@@ -0,0 +1,47 @@
+package backup
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	t.Parallel()
+
+	key := bytes.Repeat([]byte{1}, KeySize)
+	snapshot := &Snapshot{
+		Version:   snapshotVersion,
+		CreatedAt: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Match:     DefaultMatch,
+		Entries:   []Entry{{Key: "auth:apikey:bot", Type: typeHash, Hash: map[string]string{"secret_hash": "abc"}}},
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, Encode(&buf, snapshot, key))
+	assert.NotContains(t, buf.String(), "secret_hash")
+
+	encoded := buf.Bytes()
+
+	got, err := Decode(bytes.NewReader(encoded), key)
+	require.NoError(t, err)
+	assert.Equal(t, snapshot, got)
+
+	_, err = Decode(bytes.NewReader(encoded), bytes.Repeat([]byte{2}, KeySize))
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	tampered := bytes.Clone(encoded)
+	tampered[len(tampered)-1] ^= 0xff
+
+	_, err = Decode(bytes.NewReader(tampered), key)
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	_, err = Decode(bytes.NewReader([]byte("garbage")), key)
+	require.ErrorContains(t, err, "not a backup file")
+
+	require.ErrorIs(t, Encode(&buf, snapshot, []byte("short")), ErrInvalidKey)
+}
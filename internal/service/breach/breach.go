@@ -0,0 +1,178 @@
+// Package breach проверяет пароли по базе утекших паролей HaveIBeenPwned (Pwned Passwords).
+// Используется k-анонимность: в сервис уходят только первые 5 символов SHA-1 пароля,
+// а совпадение ищется среди вернувшихся суффиксов локально.
+package breach
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1" //nolint:gosec // SHA-1 требует API Pwned Passwords, для хранения паролей не используется
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultURL - адрес API Pwned Passwords.
+	DefaultURL = "https://api.pwnedpasswords.com"
+	// DefaultTimeout - таймаут запроса к API.
+	DefaultTimeout = 2 * time.Second
+	// DefaultMinCount - сколько раз пароль должен встретиться в утечках, чтобы считаться скомпрометированным.
+	DefaultMinCount = 1
+
+	// prefixLength - сколько первых символов хеша уходит в API.
+	prefixLength = 5
+)
+
+// ErrUnavailable - API недоступно, а проверка настроена как обязательная (fail closed).
+var ErrUnavailable = errors.New("password breach check is unavailable")
+
+// Result - результат проверки пароля.
+type Result struct {
+	// Checked - проверка выполнена. false - API недоступно и проверка пропущена (fail open).
+	Checked bool `json:"checked"`
+	// Breached - пароль встречается в утечках не меньше порога раз.
+	Breached bool `json:"breached"`
+	// Count - сколько раз пароль встречается в утечках.
+	Count int `json:"count"`
+}
+
+// Checker - проверка паролей по Pwned Passwords.
+type Checker struct {
+	httpClient *http.Client
+	url        string
+	minCount   int
+	failOpen   bool
+}
+
+// Option - опция для настройки Checker.
+type Option func(*Checker)
+
+// WithHTTPClient устанавливает HTTP клиент. По умолчанию клиент с таймаутом DefaultTimeout.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Checker) {
+		c.httpClient = client
+	}
+}
+
+// WithURL устанавливает адрес API, например зеркала внутри сети. По умолчанию DefaultURL.
+func WithURL(url string) Option {
+	return func(c *Checker) {
+		c.url = url
+	}
+}
+
+// WithMinCount устанавливает, сколько раз пароль должен встретиться в утечках, чтобы считаться
+// скомпрометированным. По умолчанию DefaultMinCount.
+func WithMinCount(count int) Option {
+	return func(c *Checker) {
+		c.minCount = count
+	}
+}
+
+// WithFailOpen устанавливает поведение при недоступности API: true - пароль принимается без проверки,
+// false - проверка возвращает ErrUnavailable.
+func WithFailOpen(failOpen bool) Option {
+	return func(c *Checker) {
+		c.failOpen = failOpen
+	}
+}
+
+// New создает новый Checker.
+func New(opts ...Option) (*Checker, error) {
+	c := &Checker{
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		url:        DefaultURL,
+		minCount:   DefaultMinCount,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.httpClient == nil {
+		return nil, errors.New("http client is required")
+	}
+
+	if c.url == "" {
+		return nil, errors.New("url is required")
+	}
+
+	if c.minCount < 1 {
+		return nil, errors.New("min count must be positive")
+	}
+
+	c.url = strings.TrimRight(c.url, "/")
+
+	return c, nil
+}
+
+// Check проверяет, встречается ли пароль в утечках.
+func (c *Checker) Check(ctx context.Context, password string) (Result, error) {
+	sum := sha1.Sum([]byte(password)) //nolint:gosec // SHA-1 требует API Pwned Passwords
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	count, err := c.count(ctx, hash[:prefixLength], hash[prefixLength:])
+	if err != nil {
+		if c.failOpen {
+			logrus.WithError(err).Warn("password breach check failed, accepting password")
+
+			return Result{}, nil
+		}
+
+		return Result{}, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	return Result{Checked: true, Breached: count >= c.minCount, Count: count}, nil
+}
+
+// count запрашивает суффиксы хешей с префиксом prefix и возвращает, сколько раз встречается suffix.
+func (c *Checker) count(ctx context.Context, prefix, suffix string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+"/range/"+prefix, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error create request: %w", err)
+	}
+
+	// дополнение ответа фиктивными суффиксами скрывает по размеру ответа, какой префикс запрошен
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error request range: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+
+	for scanner.Scan() {
+		hashSuffix, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok || !strings.EqualFold(hashSuffix, suffix) {
+			continue
+		}
+
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid count %q: %w", value, err)
+		}
+
+		// фиктивные суффиксы дополнения имеют счетчик 0
+		return count, nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("error read range: %w", err)
+	}
+
+	return 0, nil
+}
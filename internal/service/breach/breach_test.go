@@ -0,0 +1,145 @@
+package breach
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// SHA-1 пароля "password" - 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8.
+const passwordSuffix = "1E4C9B93F3F0682250B6CF8331B7EE68FD8"
+
+func newRangeServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	rangeHandler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/range/5BAA6" || r.Header.Get("Add-Padding") != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, "0018A45C4D1DEF81644B54AB7F969B88D65:3\r\n%s:42\r\n00D4F6E8FA6EECAD2A3AA415EEC418D38EC:0\r\n", passwordSuffix)
+	}
+
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		opts     []Option
+		password string
+		want     Result
+		wantErr  error
+	}{
+		{
+			name:     "positive case: breached password",
+			handler:  rangeHandler,
+			password: "password",
+			want:     Result{Checked: true, Breached: true, Count: 42},
+		},
+		{
+			name:     "positive case: below min count",
+			handler:  rangeHandler,
+			opts:     []Option{WithMinCount(100)},
+			password: "password",
+			want:     Result{Checked: true, Breached: false, Count: 42},
+		},
+		{
+			name: "positive case: unknown password",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = fmt.Fprint(w, "0018A45C4D1DEF81644B54AB7F969B88D65:3\r\n")
+			},
+			password: "password",
+			want:     Result{Checked: true},
+		},
+		{
+			name: "positive case: fail open",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			opts:     []Option{WithFailOpen(true)},
+			password: "password",
+			want:     Result{},
+		},
+		{
+			name: "error case: fail closed",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			password: "password",
+			wantErr:  ErrUnavailable,
+		},
+		{
+			name: "error case: timeout",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+				w.WriteHeader(http.StatusOK)
+			},
+			opts:     []Option{WithHTTPClient(&http.Client{Timeout: 20 * time.Millisecond})},
+			password: "password",
+			wantErr:  ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newRangeServer(t, tt.handler)
+
+			c, err := New(append([]Option{WithURL(ts.URL + "/")}, tt.opts...)...)
+			require.NoError(t, err)
+
+			got, err := c.Check(t.Context(), tt.password)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{name: "positive case: defaults"},
+		{name: "error case: no url", opts: []Option{WithURL("")}, wantErr: true},
+		{name: "error case: zero min count", opts: []Option{WithMinCount(0)}, wantErr: true},
+		{name: "error case: no http client", opts: []Option{WithHTTPClient(nil)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(tt.opts...)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Package bundle выгружает пакет для проверки токенов без обращения к сервису (authclient.Bundle):
+// kid ключей, которыми принимаются токены, снимок отзывов с черным списком jti и ожидаемые iss и aud.
+// Пакет подписан текущим ключом подписи и действует ограниченное время, после которого пограничный
+// сервис должен загрузить новый. Токены подписываются симметричными ключами, поэтому сами ключи в пакет не входят:
+// сервис, который проверяет токены, уже получает их из Vault.
+package bundle
+
+import (
+	"auth-service/internal/service/revocation"
+	"auth-service/pkg/authclient"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// DefaultTTL - срок действия пакета по умолчанию.
+	DefaultTTL = 24 * time.Hour
+	// MaxTTL - максимальный срок действия пакета: отзыв, записанный после выгрузки, пограничный сервис
+	// узнает только из следующего пакета.
+	MaxTTL = 7 * 24 * time.Hour
+)
+
+//go:generate mockgen -source=bundle.go -destination=mocks/bundle_mock.go -package=mocks
+type keySource interface {
+	SigningKey(ctx context.Context) (string, []byte, error)
+	KeyIDs(ctx context.Context) ([]string, error)
+}
+
+// revocationSource - хранилище отметок об отзыве.
+type revocationSource interface {
+	Snapshot(ctx context.Context) (*revocation.Snapshot, error)
+}
+
+// Exporter - выгрузка пакетов проверки.
+type Exporter struct {
+	keys        keySource
+	revocations revocationSource
+
+	issuer    string
+	audiences []string
+	ttl       time.Duration
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Exporter.
+type Option func(*Exporter)
+
+// WithKeys устанавливает ключи подписи.
+func WithKeys(keys keySource) Option {
+	return func(e *Exporter) {
+		e.keys = keys
+	}
+}
+
+// WithRevocations устанавливает хранилище отзывов. Если не задано, пакет не содержит отзывов:
+// сервис тоже не проверяет их при проверке токенов.
+func WithRevocations(revocations revocationSource) Option {
+	return func(e *Exporter) {
+		e.revocations = revocations
+	}
+}
+
+// WithIssuer устанавливает внешний адрес сервиса: iss пакета и ожидаемый iss токенов.
+func WithIssuer(issuer string) Option {
+	return func(e *Exporter) {
+		e.issuer = issuer
+	}
+}
+
+// WithAudiences устанавливает аудитории, для которых выпускаются токены.
+func WithAudiences(audiences []string) Option {
+	return func(e *Exporter) {
+		e.audiences = audiences
+	}
+}
+
+// WithTTL устанавливает срок действия пакета. По умолчанию DefaultTTL, не больше MaxTTL.
+func WithTTL(ttl time.Duration) Option {
+	return func(e *Exporter) {
+		e.ttl = ttl
+	}
+}
+
+// New создает новый Exporter.
+func New(opts ...Option) (*Exporter, error) {
+	e := &Exporter{
+		ttl: DefaultTTL,
+		now: time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if e.keys == nil {
+		return nil, errors.New("keys are required")
+	}
+
+	if e.ttl <= 0 || e.ttl > MaxTTL {
+		return nil, fmt.Errorf("ttl must be in (0, %s]", MaxTTL)
+	}
+
+	return e, nil
+}
+
+// Export собирает и подписывает пакет. Возвращает подписанный пакет (JWT с typ authclient.BundleType)
+// и его содержимое.
+func (e *Exporter) Export(ctx context.Context) (string, *authclient.Bundle, error) {
+	kids, err := e.keys.KeyIDs(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("bundle: error get key ids: %w", err)
+	}
+
+	now := e.now()
+
+	b := &authclient.Bundle{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    e.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(e.ttl)),
+		},
+		TokenIssuer: e.issuer,
+		Audiences:   e.audiences,
+		Kids:        kids,
+	}
+
+	if e.revocations != nil {
+		snapshot, err := e.revocations.Snapshot(ctx)
+		if err != nil {
+			return "", nil, fmt.Errorf("bundle: error get revocations: %w", err)
+		}
+
+		b.RevokedBefore = make(map[string]int64, len(snapshot.RevokedBefore))
+		for subject, at := range snapshot.RevokedBefore {
+			b.RevokedBefore[subject] = at.Unix()
+		}
+
+		b.RevokedTokens = make(map[string]int64, len(snapshot.RevokedTokens))
+		for jti, exp := range snapshot.RevokedTokens {
+			b.RevokedTokens[jti] = exp.Unix()
+		}
+
+		b.Deactivated = snapshot.Deactivated
+	}
+
+	kid, key, err := e.keys.SigningKey(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("bundle: error get signing key: %w", err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, b)
+	token.Header["kid"] = kid
+	token.Header["typ"] = authclient.BundleType
+
+	raw, err := token.SignedString(key)
+	if err != nil {
+		return "", nil, fmt.Errorf("bundle: error sign bundle: %w", err)
+	}
+
+	return raw, b, nil
+}
@@ -0,0 +1,158 @@
+package bundle
+
+import (
+	"auth-service/internal/service/bundle/mocks"
+	"auth-service/internal/service/revocation"
+	"auth-service/internal/service/token"
+	"auth-service/pkg/authclient"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	keys := mocks.NewMockkeySource(gomock.NewController(t))
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			opts:    []Option{WithKeys(keys), WithTTL(time.Hour)},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: no keys",
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: ttl exceeds max",
+			opts:    []Option{WithKeys(keys), WithTTL(MaxTTL + time.Second)},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(tt.opts...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+func TestExporter_Export(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	keys := mocks.NewMockkeySource(ctrl)
+	revocations := mocks.NewMockrevocationSource(ctrl)
+
+	keys.EXPECT().KeyIDs(gomock.Any()).Return([]string{"key-1", "key-2"}, nil)
+	keys.EXPECT().SigningKey(gomock.Any()).Return("key-2", []byte("secret-2"), nil)
+	revocations.EXPECT().Snapshot(gomock.Any()).Return(&revocation.Snapshot{
+		RevokedBefore: map[string]time.Time{"user-1": time.Unix(100, 0)},
+		RevokedTokens: map[string]time.Time{"token-1": time.Unix(300, 0)},
+		Deactivated:   []string{"user-2"},
+	}, nil)
+
+	e, err := New(
+		WithKeys(keys),
+		WithRevocations(revocations),
+		WithIssuer("https://auth.example.com"),
+		WithAudiences([]string{"telegram-bot"}),
+		WithTTL(time.Hour),
+	)
+	require.NoError(t, err)
+
+	raw, b, err := e.Export(t.Context())
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), b.ExpiresAt.Time, 2*time.Second)
+
+	parsed, err := authclient.ParseBundle(raw, func(t *jwt.Token) (interface{}, error) {
+		if t.Header["kid"] != "key-2" {
+			return nil, errors.New("unknown key")
+		}
+
+		return []byte("secret-2"), nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://auth.example.com", parsed.TokenIssuer)
+	assert.Equal(t, []string{"telegram-bot"}, parsed.Audiences)
+	assert.Equal(t, []string{"key-1", "key-2"}, parsed.Kids)
+	assert.Equal(t, map[string]int64{"user-1": 100}, parsed.RevokedBefore)
+	assert.Equal(t, map[string]int64{"token-1": 300}, parsed.RevokedTokens)
+	assert.Equal(t, []string{"user-2"}, parsed.Deactivated)
+}
+
+func TestExporter_Export_Errors(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	keys := mocks.NewMockkeySource(ctrl)
+	revocations := mocks.NewMockrevocationSource(ctrl)
+
+	e, err := New(WithKeys(keys), WithRevocations(revocations))
+	require.NoError(t, err)
+
+	keys.EXPECT().KeyIDs(gomock.Any()).Return(nil, errors.New("vault is sealed"))
+
+	_, _, err = e.Export(t.Context())
+	require.Error(t, err)
+
+	keys.EXPECT().KeyIDs(gomock.Any()).Return([]string{"key-1"}, nil)
+	revocations.EXPECT().Snapshot(gomock.Any()).Return(nil, errors.New("redis is down"))
+
+	_, _, err = e.Export(t.Context())
+	require.Error(t, err)
+
+	// без хранилища отзывов пакет выгружается без отзывов
+	e.revocations = nil
+
+	keys.EXPECT().KeyIDs(gomock.Any()).Return([]string{"key-1"}, nil)
+	keys.EXPECT().SigningKey(gomock.Any()).Return("key-1", []byte("secret-1"), nil)
+
+	_, b, err := e.Export(t.Context())
+	require.NoError(t, err)
+	assert.Nil(t, b.RevokedBefore)
+}
+
+// signingKey - ключ подписи токенов пользователей для Validator.
+type signingKey []byte
+
+func (k signingKey) Key(_ context.Context, _ string) ([]byte, error) {
+	return k, nil
+}
+
+func TestExporter_Export_NotUserToken(t *testing.T) {
+	t.Parallel()
+
+	keys := mocks.NewMockkeySource(gomock.NewController(t))
+	keys.EXPECT().KeyIDs(gomock.Any()).Return([]string{"key-1"}, nil)
+	keys.EXPECT().SigningKey(gomock.Any()).Return("key-1", []byte("secret"), nil)
+
+	e, err := New(WithKeys(keys), WithIssuer("https://auth.example.com"))
+	require.NoError(t, err)
+
+	raw, _, err := e.Export(t.Context())
+	require.NoError(t, err)
+
+	// пакет подписан ключом токенов пользователей, но токеном пользователя не принимается
+	v, err := token.NewValidator(token.WithKeys(signingKey("secret")), token.WithExpectedIssuer("https://auth.example.com"))
+	require.NoError(t, err)
+
+	_, err = v.Validate(t.Context(), raw)
+	require.ErrorIs(t, err, token.ErrInvalidToken)
+}
@@ -0,0 +1,105 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: bundle.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	revocation "auth-service/internal/service/revocation"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockkeySource is a mock of keySource interface.
+type MockkeySource struct {
+	ctrl     *gomock.Controller
+	recorder *MockkeySourceMockRecorder
+}
+
+// MockkeySourceMockRecorder is the mock recorder for MockkeySource.
+type MockkeySourceMockRecorder struct {
+	mock *MockkeySource
+}
+
+// NewMockkeySource creates a new mock instance.
+func NewMockkeySource(ctrl *gomock.Controller) *MockkeySource {
+	mock := &MockkeySource{ctrl: ctrl}
+	mock.recorder = &MockkeySourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockkeySource) EXPECT() *MockkeySourceMockRecorder {
+	return m.recorder
+}
+
+// KeyIDs mocks base method.
+func (m *MockkeySource) KeyIDs(ctx context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyIDs", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// KeyIDs indicates an expected call of KeyIDs.
+func (mr *MockkeySourceMockRecorder) KeyIDs(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyIDs", reflect.TypeOf((*MockkeySource)(nil).KeyIDs), ctx)
+}
+
+// SigningKey mocks base method.
+func (m *MockkeySource) SigningKey(ctx context.Context) (string, []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SigningKey", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].([]byte)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SigningKey indicates an expected call of SigningKey.
+func (mr *MockkeySourceMockRecorder) SigningKey(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SigningKey", reflect.TypeOf((*MockkeySource)(nil).SigningKey), ctx)
+}
+
+// MockrevocationSource is a mock of revocationSource interface.
+type MockrevocationSource struct {
+	ctrl     *gomock.Controller
+	recorder *MockrevocationSourceMockRecorder
+}
+
+// MockrevocationSourceMockRecorder is the mock recorder for MockrevocationSource.
+type MockrevocationSourceMockRecorder struct {
+	mock *MockrevocationSource
+}
+
+// NewMockrevocationSource creates a new mock instance.
+func NewMockrevocationSource(ctrl *gomock.Controller) *MockrevocationSource {
+	mock := &MockrevocationSource{ctrl: ctrl}
+	mock.recorder = &MockrevocationSourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockrevocationSource) EXPECT() *MockrevocationSourceMockRecorder {
+	return m.recorder
+}
+
+// Snapshot mocks base method.
+func (m *MockrevocationSource) Snapshot(ctx context.Context) (*revocation.Snapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot", ctx)
+	ret0, _ := ret[0].(*revocation.Snapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockrevocationSourceMockRecorder) Snapshot(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockrevocationSource)(nil).Snapshot), ctx)
+}
@@ -0,0 +1,365 @@
+// Package canary проверяет выпуск и проверку токенов целиком на пробном (canary) токене. Токен
+// выпускается текущим ключом подписи и проверяется так же, как токены клиентов: ключ по kid, отзыв,
+// проверки аудитории. Если включены пакеты проверки, пакет выгружается, его подпись проверяется,
+// а kid токена ищется в нем так же, как это делает пограничный сервис. Проверка выполняется при
+// запуске - после каждого выката - и затем периодически, поэтому неверно подключенные ключи видны
+// в метриках и в ответе /ready раньше, чем на них споткнутся запросы клиентов.
+package canary
+
+import (
+	"auth-service/internal/service/token"
+	"auth-service/pkg/authclient"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultInterval - периодичность проверки по умолчанию.
+	DefaultInterval = 5 * time.Minute
+	// DefaultTimeout - таймаут одной проверки по умолчанию.
+	DefaultTimeout = 5 * time.Second
+	// Subject - субъект пробных токенов.
+	Subject = "auth-service-canary"
+
+	// tokenTTL - время жизни пробного токена: он нужен только на время проверки.
+	tokenTTL = time.Minute
+)
+
+// Шаги проверки.
+const (
+	StepIssue    = "issue"    // выпуск токена
+	StepValidate = "validate" // проверка токена
+	StepBundle   = "bundle"   // поиск kid токена в пакете проверки
+)
+
+//go:generate mockgen -source=canary.go -destination=mocks/canary_mock.go -package=mocks
+type tokenIssuer interface {
+	Issue(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error)
+}
+
+type tokenValidator interface {
+	Validate(ctx context.Context, raw string) (*token.Claims, error)
+}
+
+// bundleExporter - выгрузка пакетов проверки токенов.
+type bundleExporter interface {
+	Export(ctx context.Context) (string, *authclient.Bundle, error)
+}
+
+// keyProvider - ключи, которыми проверяется подпись пакета.
+type keyProvider interface {
+	Key(ctx context.Context, kid string) ([]byte, error)
+}
+
+// Result - результат проверки.
+type Result struct {
+	OK bool `json:"ok"`
+	// Step - шаг, на котором проверка не прошла.
+	Step  string `json:"step,omitempty"`
+	Error string `json:"error,omitempty"`
+	// Kid - ключ, которым подписан пробный токен.
+	Kid       string    `json:"kid,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+	// LastSuccessAt - время последней успешной проверки.
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+}
+
+// Checker - проверка пробным токеном.
+type Checker struct {
+	issuer    tokenIssuer
+	validator tokenValidator
+	bundles   bundleExporter
+	keys      keyProvider
+
+	audience []string
+	interval time.Duration
+	timeout  time.Duration
+	required bool
+
+	registerer  prometheus.Registerer
+	success     prometheus.Gauge
+	lastSuccess prometheus.Gauge
+	failures    *prometheus.CounterVec
+
+	mu   sync.Mutex
+	last *Result
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Checker.
+type Option func(*Checker)
+
+// WithIssuer устанавливает выпуск токенов.
+func WithIssuer(issuer tokenIssuer) Option {
+	return func(c *Checker) {
+		c.issuer = issuer
+	}
+}
+
+// WithValidator устанавливает проверку токенов.
+func WithValidator(validator tokenValidator) Option {
+	return func(c *Checker) {
+		c.validator = validator
+	}
+}
+
+// WithBundles включает шаг проверки пакета: пакет выгружается exporter, его подпись проверяется
+// ключами keys, в нем ищется kid пробного токена.
+func WithBundles(exporter bundleExporter, keys keyProvider) Option {
+	return func(c *Checker) {
+		c.bundles = exporter
+		c.keys = keys
+	}
+}
+
+// WithAudience устанавливает аудитории пробного токена, например чтобы проверить схемы claims
+// и проверку сессии этих аудиторий. По умолчанию токен без аудитории.
+func WithAudience(audience []string) Option {
+	return func(c *Checker) {
+		c.audience = audience
+	}
+}
+
+// WithInterval устанавливает периодичность проверки. По умолчанию DefaultInterval.
+func WithInterval(interval time.Duration) Option {
+	return func(c *Checker) {
+		c.interval = interval
+	}
+}
+
+// WithTimeout устанавливает таймаут одной проверки. По умолчанию DefaultTimeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Checker) {
+		c.timeout = timeout
+	}
+}
+
+// WithRequired делает проверку обязательной для готовности экземпляра (Ready).
+func WithRequired(required bool) Option {
+	return func(c *Checker) {
+		c.required = required
+	}
+}
+
+// WithRegisterer устанавливает реестр метрик. По умолчанию используется prometheus.DefaultRegisterer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(c *Checker) {
+		c.registerer = registerer
+	}
+}
+
+// New создает новый Checker и регистрирует его метрики.
+func New(opts ...Option) (*Checker, error) {
+	c := &Checker{
+		interval:   DefaultInterval,
+		timeout:    DefaultTimeout,
+		registerer: prometheus.DefaultRegisterer,
+		now:        time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.issuer == nil || c.validator == nil {
+		return nil, errors.New("issuer and validator are required")
+	}
+
+	if (c.bundles == nil) != (c.keys == nil) {
+		return nil, errors.New("bundle exporter and keys are required together")
+	}
+
+	if c.interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+
+	if c.timeout <= 0 {
+		return nil, errors.New("timeout must be positive")
+	}
+
+	if c.registerer == nil {
+		return nil, errors.New("registerer is required")
+	}
+
+	c.success = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "auth_canary_success",
+		Help: "1, если последняя проверка пробным токеном прошла.",
+	})
+
+	c.lastSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "auth_canary_last_success_timestamp_seconds",
+		Help: "Время последней успешной проверки пробным токеном (unix).",
+	})
+
+	c.failures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_canary_failures_total",
+		Help: "Количество проваленных проверок пробным токеном по шагам: issue, validate, bundle.",
+	}, []string{"step"})
+
+	for _, collector := range []prometheus.Collector{c.success, c.lastSuccess, c.failures} {
+		if err := c.registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Start проверяет сразу и затем периодически до отмены контекста.
+func (c *Checker) Start(ctx context.Context) error {
+	c.Check(ctx)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			c.Check(ctx)
+		}
+	}
+}
+
+// Check выпускает и проверяет пробный токен, обновляет метрики и сохраняет результат.
+func (c *Checker) Check(ctx context.Context) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	kid, step, err := c.check(ctx)
+
+	res := Result{
+		OK:        err == nil,
+		Step:      step,
+		Kid:       kid,
+		CheckedAt: c.now().UTC(),
+	}
+
+	c.mu.Lock()
+
+	var recovered bool
+
+	if c.last != nil {
+		res.LastSuccessAt = c.last.LastSuccessAt
+		recovered = !c.last.OK
+	}
+
+	if res.OK {
+		res.LastSuccessAt = &res.CheckedAt
+	} else {
+		res.Error = err.Error()
+	}
+
+	c.last = &res
+	c.mu.Unlock()
+
+	if !res.OK {
+		c.success.Set(0)
+		c.failures.WithLabelValues(step).Inc()
+
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"step": step,
+			"kid":  kid,
+		}).Error("canary token check failed")
+
+		return res
+	}
+
+	c.success.Set(1)
+	c.lastSuccess.Set(float64(res.CheckedAt.Unix()))
+
+	if recovered {
+		logrus.WithField("kid", kid).Info("canary token check recovered")
+	}
+
+	return res
+}
+
+// check выполняет шаги проверки. Возвращает kid пробного токена и шаг, на котором проверка не прошла.
+func (c *Checker) check(ctx context.Context) (string, string, error) {
+	raw, issued, err := c.issuer.Issue(ctx, token.IssueRequest{Subject: Subject, Audience: c.audience, TTL: tokenTTL})
+	if err != nil {
+		return "", StepIssue, err
+	}
+
+	claims, err := c.validator.Validate(ctx, raw)
+	if err != nil {
+		return issued.Kid, StepValidate, err
+	}
+
+	if claims.ID != issued.ID || claims.Subject != Subject || claims.Kid != issued.Kid {
+		return issued.Kid, StepValidate, fmt.Errorf("validated claims do not match issued token: jti %q, sub %q, kid %q",
+			claims.ID, claims.Subject, claims.Kid)
+	}
+
+	if c.bundles == nil {
+		return issued.Kid, "", nil
+	}
+
+	if err := c.checkBundle(ctx, claims); err != nil {
+		return issued.Kid, StepBundle, err
+	}
+
+	return issued.Kid, "", nil
+}
+
+// checkBundle выгружает пакет и проверяет его так же, как пограничный сервис: подпись пакета
+// и то, что токен с kid пробного токена в нем принимается.
+func (c *Checker) checkBundle(ctx context.Context, claims *token.Claims) error {
+	raw, _, err := c.bundles.Export(ctx)
+	if err != nil {
+		return err
+	}
+
+	b, err := authclient.ParseBundle(raw, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+
+		return c.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		return err
+	}
+
+	if !b.Trusted(claims.Kid) {
+		return fmt.Errorf("kid %q is not trusted by verification bundle", claims.Kid)
+	}
+
+	if b.Revoked(claims.ID, claims.Subject, claims.IssuedAt) {
+		return errors.New("canary token is revoked by verification bundle")
+	}
+
+	return nil
+}
+
+// Last возвращает результат последней проверки или false, если проверок еще не было.
+func (c *Checker) Last() (Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.last == nil {
+		return Result{}, false
+	}
+
+	return *c.last, true
+}
+
+// Ready возвращает false, если проверка обязательна (WithRequired), а последняя проверка
+// не прошла или проверок еще не было.
+func (c *Checker) Ready() bool {
+	if !c.required {
+		return true
+	}
+
+	last, ok := c.Last()
+
+	return ok && last.OK
+}
@@ -0,0 +1,270 @@
+package canary
+
+import (
+	"auth-service/internal/service/canary/mocks"
+	"auth-service/internal/service/token"
+	"auth-service/pkg/authclient"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var bundleKey = []byte("secret")
+
+func signBundle(t *testing.T, b *authclient.Bundle) string {
+	t.Helper()
+
+	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, b)
+	tok.Header["kid"] = "key-1"
+	tok.Header["typ"] = authclient.BundleType
+
+	raw, err := tok.SignedString(bundleKey)
+	require.NoError(t, err)
+
+	return raw
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	issuer := mocks.NewMocktokenIssuer(ctrl)
+	validator := mocks.NewMocktokenValidator(ctrl)
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			opts:    []Option{WithIssuer(issuer), WithValidator(validator)},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: no issuer",
+			opts:    []Option{WithValidator(validator)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: no validator",
+			opts:    []Option{WithIssuer(issuer)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: bundles without keys",
+			opts:    []Option{WithIssuer(issuer), WithValidator(validator), WithBundles(mocks.NewMockbundleExporter(ctrl), nil)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: zero interval",
+			opts:    []Option{WithIssuer(issuer), WithValidator(validator), WithInterval(0)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: zero timeout",
+			opts:    []Option{WithIssuer(issuer), WithValidator(validator), WithTimeout(0)},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := append([]Option{WithRegisterer(prometheus.NewRegistry())}, tt.opts...)
+
+			_, err := New(opts...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	issued := &token.Claims{ID: "jti-1", Kid: "key-1", Subject: Subject, IssuedAt: now}
+	errIssue := errors.New("vault is unavailable")
+
+	bundle := func(b *authclient.Bundle) func(t *testing.T) string {
+		return func(t *testing.T) string {
+			t.Helper()
+
+			b.ExpiresAt = jwt.NewNumericDate(now.Add(time.Hour))
+
+			return signBundle(t, b)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		issueErr  error
+		validated *token.Claims
+		bundle    func(t *testing.T) string
+		wantStep  string
+	}{
+		{
+			name:      "positive case",
+			validated: issued,
+		},
+		{
+			name:      "positive case: bundle",
+			validated: issued,
+			bundle:    bundle(&authclient.Bundle{Kids: []string{"key-1"}}),
+		},
+		{
+			name:     "error case: issue",
+			issueErr: errIssue,
+			wantStep: StepIssue,
+		},
+		{
+			name:     "error case: validate",
+			wantStep: StepValidate,
+		},
+		{
+			name:      "error case: claims mismatch",
+			validated: &token.Claims{ID: "jti-2", Kid: "key-1", Subject: Subject},
+			wantStep:  StepValidate,
+		},
+		{
+			name:      "error case: kid not in bundle",
+			validated: issued,
+			bundle:    bundle(&authclient.Bundle{Kids: []string{"key-0"}}),
+			wantStep:  StepBundle,
+		},
+		{
+			name:      "error case: canary revoked in bundle",
+			validated: issued,
+			bundle: bundle(&authclient.Bundle{
+				Kids:          []string{"key-1"},
+				RevokedBefore: map[string]int64{Subject: now.Unix()},
+			}),
+			wantStep: StepBundle,
+		},
+		{
+			name:      "error case: canary jti revoked in bundle",
+			validated: issued,
+			bundle: bundle(&authclient.Bundle{
+				Kids:          []string{"key-1"},
+				RevokedTokens: map[string]int64{"jti-1": now.Add(time.Hour).Unix()},
+			}),
+			wantStep: StepBundle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			issuer := mocks.NewMocktokenIssuer(ctrl)
+			validator := mocks.NewMocktokenValidator(ctrl)
+			reg := prometheus.NewRegistry()
+
+			if tt.issueErr != nil {
+				issuer.EXPECT().Issue(gomock.Any(), gomock.Any()).Return("", nil, tt.issueErr)
+			} else {
+				issuer.EXPECT().Issue(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, req token.IssueRequest) (string, *token.Claims, error) {
+						assert.Equal(t, Subject, req.Subject)
+						assert.Equal(t, tokenTTL, req.TTL)
+
+						return "raw", issued, nil
+					})
+
+				if tt.validated != nil {
+					validator.EXPECT().Validate(gomock.Any(), "raw").Return(tt.validated, nil)
+				} else {
+					validator.EXPECT().Validate(gomock.Any(), "raw").Return(nil, token.ErrInvalidToken)
+				}
+			}
+
+			opts := []Option{WithIssuer(issuer), WithValidator(validator), WithRequired(true), WithRegisterer(reg)}
+
+			if tt.bundle != nil {
+				exporter := mocks.NewMockbundleExporter(ctrl)
+				keys := mocks.NewMockkeyProvider(ctrl)
+
+				exporter.EXPECT().Export(gomock.Any()).Return(tt.bundle(t), nil, nil)
+				keys.EXPECT().Key(gomock.Any(), "key-1").Return(bundleKey, nil)
+
+				opts = append(opts, WithBundles(exporter, keys))
+			}
+
+			c, err := New(opts...)
+			require.NoError(t, err)
+
+			assert.False(t, c.Ready())
+
+			res := c.Check(t.Context())
+
+			last, ok := c.Last()
+			require.True(t, ok)
+			assert.Equal(t, res, last)
+
+			if tt.wantStep != "" {
+				assert.False(t, res.OK)
+				assert.Equal(t, tt.wantStep, res.Step)
+				assert.NotEmpty(t, res.Error)
+				assert.Nil(t, res.LastSuccessAt)
+				assert.False(t, c.Ready())
+				assert.InDelta(t, 0, testutil.ToFloat64(c.success), 0)
+				assert.InDelta(t, 1, testutil.ToFloat64(c.failures.WithLabelValues(tt.wantStep)), 0)
+
+				return
+			}
+
+			assert.True(t, res.OK)
+			assert.Empty(t, res.Step)
+			assert.Equal(t, "key-1", res.Kid)
+			require.NotNil(t, res.LastSuccessAt)
+			assert.Equal(t, res.CheckedAt, *res.LastSuccessAt)
+			assert.True(t, c.Ready())
+			assert.InDelta(t, 1, testutil.ToFloat64(c.success), 0)
+			assert.InDelta(t, float64(res.CheckedAt.Unix()), testutil.ToFloat64(c.lastSuccess), 0)
+		})
+	}
+}
+
+func TestChecker_Check_KeepsLastSuccess(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	issuer := mocks.NewMocktokenIssuer(ctrl)
+	validator := mocks.NewMocktokenValidator(ctrl)
+
+	issued := &token.Claims{ID: "jti-1", Kid: "key-1", Subject: Subject}
+
+	gomock.InOrder(
+		issuer.EXPECT().Issue(gomock.Any(), gomock.Any()).Return("raw", issued, nil),
+		issuer.EXPECT().Issue(gomock.Any(), gomock.Any()).Return("", nil, errors.New("vault is unavailable")),
+	)
+	validator.EXPECT().Validate(gomock.Any(), "raw").Return(issued, nil)
+
+	c, err := New(WithIssuer(issuer), WithValidator(validator), WithRegisterer(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	_, ok := c.Last()
+	assert.False(t, ok)
+
+	first := c.Check(t.Context())
+	require.True(t, first.OK)
+
+	second := c.Check(t.Context())
+	require.False(t, second.OK)
+	require.NotNil(t, second.LastSuccessAt)
+	assert.Equal(t, first.CheckedAt, *second.LastSuccessAt)
+
+	// проверка не обязательна: экземпляр готов, даже если она не проходит
+	assert.True(t, c.Ready())
+}
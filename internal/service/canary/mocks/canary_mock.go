@@ -0,0 +1,168 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: canary.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	token "auth-service/internal/service/token"
+	authclient "auth-service/pkg/authclient"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MocktokenIssuer is a mock of tokenIssuer interface.
+type MocktokenIssuer struct {
+	ctrl     *gomock.Controller
+	recorder *MocktokenIssuerMockRecorder
+}
+
+// MocktokenIssuerMockRecorder is the mock recorder for MocktokenIssuer.
+type MocktokenIssuerMockRecorder struct {
+	mock *MocktokenIssuer
+}
+
+// NewMocktokenIssuer creates a new mock instance.
+func NewMocktokenIssuer(ctrl *gomock.Controller) *MocktokenIssuer {
+	mock := &MocktokenIssuer{ctrl: ctrl}
+	mock.recorder = &MocktokenIssuerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktokenIssuer) EXPECT() *MocktokenIssuerMockRecorder {
+	return m.recorder
+}
+
+// Issue mocks base method.
+func (m *MocktokenIssuer) Issue(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Issue", ctx, req)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*token.Claims)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Issue indicates an expected call of Issue.
+func (mr *MocktokenIssuerMockRecorder) Issue(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Issue", reflect.TypeOf((*MocktokenIssuer)(nil).Issue), ctx, req)
+}
+
+// MocktokenValidator is a mock of tokenValidator interface.
+type MocktokenValidator struct {
+	ctrl     *gomock.Controller
+	recorder *MocktokenValidatorMockRecorder
+}
+
+// MocktokenValidatorMockRecorder is the mock recorder for MocktokenValidator.
+type MocktokenValidatorMockRecorder struct {
+	mock *MocktokenValidator
+}
+
+// NewMocktokenValidator creates a new mock instance.
+func NewMocktokenValidator(ctrl *gomock.Controller) *MocktokenValidator {
+	mock := &MocktokenValidator{ctrl: ctrl}
+	mock.recorder = &MocktokenValidatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktokenValidator) EXPECT() *MocktokenValidatorMockRecorder {
+	return m.recorder
+}
+
+// Validate mocks base method.
+func (m *MocktokenValidator) Validate(ctx context.Context, raw string) (*token.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", ctx, raw)
+	ret0, _ := ret[0].(*token.Claims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MocktokenValidatorMockRecorder) Validate(ctx, raw interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MocktokenValidator)(nil).Validate), ctx, raw)
+}
+
+// MockbundleExporter is a mock of bundleExporter interface.
+type MockbundleExporter struct {
+	ctrl     *gomock.Controller
+	recorder *MockbundleExporterMockRecorder
+}
+
+// MockbundleExporterMockRecorder is the mock recorder for MockbundleExporter.
+type MockbundleExporterMockRecorder struct {
+	mock *MockbundleExporter
+}
+
+// NewMockbundleExporter creates a new mock instance.
+func NewMockbundleExporter(ctrl *gomock.Controller) *MockbundleExporter {
+	mock := &MockbundleExporter{ctrl: ctrl}
+	mock.recorder = &MockbundleExporterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockbundleExporter) EXPECT() *MockbundleExporterMockRecorder {
+	return m.recorder
+}
+
+// Export mocks base method.
+func (m *MockbundleExporter) Export(ctx context.Context) (string, *authclient.Bundle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*authclient.Bundle)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockbundleExporterMockRecorder) Export(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockbundleExporter)(nil).Export), ctx)
+}
+
+// MockkeyProvider is a mock of keyProvider interface.
+type MockkeyProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockkeyProviderMockRecorder
+}
+
+// MockkeyProviderMockRecorder is the mock recorder for MockkeyProvider.
+type MockkeyProviderMockRecorder struct {
+	mock *MockkeyProvider
+}
+
+// NewMockkeyProvider creates a new mock instance.
+func NewMockkeyProvider(ctrl *gomock.Controller) *MockkeyProvider {
+	mock := &MockkeyProvider{ctrl: ctrl}
+	mock.recorder = &MockkeyProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockkeyProvider) EXPECT() *MockkeyProviderMockRecorder {
+	return m.recorder
+}
+
+// Key mocks base method.
+func (m *MockkeyProvider) Key(ctx context.Context, kid string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Key", ctx, kid)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Key indicates an expected call of Key.
+func (mr *MockkeyProviderMockRecorder) Key(ctx, kid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Key", reflect.TypeOf((*MockkeyProvider)(nil).Key), ctx, kid)
+}
@@ -0,0 +1,201 @@
+// Package capture реализует выборочный захват тел запросов и ответов для отладки.
+// Захваченные данные хранятся в кольцевом буфере в памяти, секреты в JSON маскируются.
+package capture
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBufferSize  = 100
+	defaultMaxBodySize = 4096
+)
+
+// Entry - захваченная пара запрос/ответ.
+type Entry struct {
+	Time         time.Time `json:"time"`
+	Method       string    `json:"method"`
+	Route        string    `json:"route"`
+	Path         string    `json:"path"`
+	Status       int       `json:"status"`
+	RequestBody  string    `json:"request_body"`
+	ResponseBody string    `json:"response_body"`
+}
+
+// Settings - настройки захвата, меняются в рантайме через административное API.
+type Settings struct {
+	Enabled bool `json:"enabled"`
+	// Routes - доля запросов (от 0 до 1), которые нужно захватывать, по шаблону маршрута.
+	Routes map[string]float64 `json:"routes"`
+}
+
+// Capture - хранилище захваченных запросов.
+type Capture struct {
+	bufferSize  int
+	maxBodySize int
+
+	mu       sync.Mutex
+	settings Settings
+	entries  []Entry
+	next     int
+	full     bool
+
+	// sample возвращает случайное число в [0, 1). Подменяется в тестах.
+	sample func() float64
+}
+
+// Option - опция для настройки Capture.
+type Option func(*Capture)
+
+// WithBufferSize устанавливает количество хранимых записей.
+func WithBufferSize(size int) Option {
+	return func(c *Capture) {
+		c.bufferSize = size
+	}
+}
+
+// WithMaxBodySize устанавливает максимальный размер сохраняемого тела в байтах. Более длинные тела обрезаются.
+func WithMaxBodySize(size int) Option {
+	return func(c *Capture) {
+		c.maxBodySize = size
+	}
+}
+
+// New создает новый Capture. Захват по умолчанию выключен.
+func New(opts ...Option) (*Capture, error) {
+	c := &Capture{
+		bufferSize:  defaultBufferSize,
+		maxBodySize: defaultMaxBodySize,
+		settings:    Settings{Routes: map[string]float64{}},
+		sample:      rand.Float64, //nolint:gosec // криптостойкость для выборки не нужна
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.bufferSize <= 0 {
+		return nil, errors.New("buffer size must be greater than 0")
+	}
+
+	if c.maxBodySize <= 0 {
+		return nil, errors.New("max body size must be greater than 0")
+	}
+
+	c.entries = make([]Entry, c.bufferSize)
+
+	return c, nil
+}
+
+// Settings возвращает копию текущих настроек.
+func (c *Capture) Settings() Settings {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	routes := make(map[string]float64, len(c.settings.Routes))
+	for route, rate := range c.settings.Routes {
+		routes[route] = rate
+	}
+
+	return Settings{Enabled: c.settings.Enabled, Routes: routes}
+}
+
+// Update заменяет настройки захвата.
+// Административные маршруты захватывать нельзя, чтобы не зациклить выдачу захваченных данных.
+func (c *Capture) Update(settings Settings) error {
+	routes := make(map[string]float64, len(settings.Routes))
+
+	for route, rate := range settings.Routes {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("rate for route %s must be between 0 and 1", route)
+		}
+
+		if strings.Contains(route, "/admin/") {
+			return fmt.Errorf("capture of admin route %s is not allowed", route)
+		}
+
+		routes[route] = rate
+	}
+
+	c.mu.Lock()
+	c.settings = Settings{Enabled: settings.Enabled, Routes: routes}
+	c.mu.Unlock()
+
+	return nil
+}
+
+// ShouldSample решает, нужно ли захватить запрос к маршруту.
+func (c *Capture) ShouldSample(route string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.settings.Enabled {
+		return false
+	}
+
+	rate, ok := c.settings.Routes[route]
+	if !ok || rate <= 0 {
+		return false
+	}
+
+	return c.sample() < rate
+}
+
+// Record сохраняет запись в буфер, маскируя секреты и обрезая тела.
+// Если буфер заполнен, перезаписывается самая старая запись.
+func (c *Capture) Record(entry Entry) {
+	entry.RequestBody = c.truncate(entry.RequestBody)
+	entry.ResponseBody = c.truncate(entry.ResponseBody)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[c.next] = entry
+	c.next = (c.next + 1) % c.bufferSize
+
+	if c.next == 0 {
+		c.full = true
+	}
+}
+
+// Entries возвращает захваченные записи от старых к новым.
+func (c *Capture) Entries() []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.full {
+		res := make([]Entry, c.next)
+		copy(res, c.entries[:c.next])
+
+		return res
+	}
+
+	res := make([]Entry, 0, c.bufferSize)
+	res = append(res, c.entries[c.next:]...)
+	res = append(res, c.entries[:c.next]...)
+
+	return res
+}
+
+// Clear очищает буфер.
+func (c *Capture) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make([]Entry, c.bufferSize)
+	c.next = 0
+	c.full = false
+}
+
+func (c *Capture) truncate(body string) string {
+	if len(body) <= c.maxBodySize {
+		return body
+	}
+
+	return body[:c.maxBodySize] + fmt.Sprintf("...(truncated %d bytes)", len(body)-c.maxBodySize)
+}
@@ -0,0 +1,126 @@
+package capture
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{name: "positive case: defaults", wantErr: require.NoError},
+		{name: "positive case: custom sizes", opts: []Option{WithBufferSize(10), WithMaxBodySize(128)}, wantErr: require.NoError},
+		{name: "error case: buffer size", opts: []Option{WithBufferSize(0)}, wantErr: require.Error},
+		{name: "error case: max body size", opts: []Option{WithMaxBodySize(-1)}, wantErr: require.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := New(tt.opts...)
+			tt.wantErr(t, err)
+
+			if err == nil {
+				assert.False(t, c.Settings().Enabled)
+				assert.Empty(t, c.Entries())
+			}
+		})
+	}
+}
+
+func TestCapture_Update(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		settings Settings
+		wantErr  require.ErrorAssertionFunc
+	}{
+		{
+			name:     "positive case",
+			settings: Settings{Enabled: true, Routes: map[string]float64{"/api/v0/health": 0.5}},
+			wantErr:  require.NoError,
+		},
+		{
+			name:     "error case: rate is greater than 1",
+			settings: Settings{Enabled: true, Routes: map[string]float64{"/api/v0/health": 1.5}},
+			wantErr:  require.Error,
+		},
+		{
+			name:     "error case: admin route",
+			settings: Settings{Enabled: true, Routes: map[string]float64{"/api/v0/admin/capture": 1}},
+			wantErr:  require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := New()
+			require.NoError(t, err)
+
+			err = c.Update(tt.settings)
+			tt.wantErr(t, err)
+
+			if err == nil {
+				assert.Equal(t, tt.settings, c.Settings())
+			} else {
+				assert.Equal(t, Settings{Routes: map[string]float64{}}, c.Settings())
+			}
+		})
+	}
+}
+
+func TestCapture_ShouldSample(t *testing.T) {
+	t.Parallel()
+
+	c, err := New()
+	require.NoError(t, err)
+
+	c.sample = func() float64 { return 0.3 }
+
+	// выключено
+	require.NoError(t, c.Update(Settings{Enabled: false, Routes: map[string]float64{"/a": 1}}))
+	assert.False(t, c.ShouldSample("/a"))
+
+	require.NoError(t, c.Update(Settings{Enabled: true, Routes: map[string]float64{"/a": 0.5, "/b": 0.1}}))
+	assert.True(t, c.ShouldSample("/a"))
+	assert.False(t, c.ShouldSample("/b"))
+	assert.False(t, c.ShouldSample("/c"))
+}
+
+func TestCapture_Record(t *testing.T) {
+	t.Parallel()
+
+	c, err := New(WithBufferSize(3), WithMaxBodySize(5))
+	require.NoError(t, err)
+
+	for i := range 5 {
+		c.Record(Entry{Path: fmt.Sprintf("/%d", i), RequestBody: "0123456789", ResponseBody: "ok"})
+	}
+
+	entries := c.Entries()
+	require.Len(t, entries, 3)
+
+	// остаются последние записи, от старых к новым
+	assert.Equal(t, "/2", entries[0].Path)
+	assert.Equal(t, "/3", entries[1].Path)
+	assert.Equal(t, "/4", entries[2].Path)
+
+	assert.True(t, strings.HasPrefix(entries[0].RequestBody, "01234...(truncated 5 bytes)"))
+	assert.Equal(t, "ok", entries[0].ResponseBody)
+
+	c.Clear()
+	assert.Empty(t, c.Entries())
+}
@@ -0,0 +1,83 @@
+package capture
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// redacted - значение, которым заменяются секреты.
+const redacted = "[REDACTED]"
+
+// secretKeys возвращает подстроки имен полей, значения которых считаются секретами.
+func secretKeys() []string {
+	return []string{
+		"password",
+		"secret",
+		"token",
+		"authorization",
+		"otp",
+		"code",
+		"hash",
+		"init_data",
+		"initdata",
+		"key",
+	}
+}
+
+// Redact маскирует значения секретных полей в JSON теле.
+// Тела, которые не являются JSON, не сохраняются вовсе: в них нельзя надежно найти секреты.
+func Redact(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var v any
+
+	if err := json.Unmarshal(body, &v); err != nil {
+		return fmt.Sprintf("[non-json body omitted, %d bytes]", len(body))
+	}
+
+	data, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return fmt.Sprintf("[body omitted, %d bytes]", len(body))
+	}
+
+	return string(data)
+}
+
+func redactValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			if isSecretKey(k) {
+				val[k] = redacted
+				continue
+			}
+
+			val[k] = redactValue(item)
+		}
+
+		return val
+	case []any:
+		for i, item := range val {
+			val[i] = redactValue(item)
+		}
+
+		return val
+	default:
+		return val
+	}
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+
+	for _, secret := range secretKeys() {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+
+	return false
+}
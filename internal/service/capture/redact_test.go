@@ -0,0 +1,46 @@
+package capture
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "empty body",
+			body: "",
+			want: "",
+		},
+		{
+			name: "secrets are redacted",
+			body: `{"username":"bot","password":"qwerty","refresh_token":"abc","nested":{"client_secret":"s","id":1}}`,
+			want: `{"nested":{"client_secret":"[REDACTED]","id":1},"password":"[REDACTED]","refresh_token":"[REDACTED]","username":"bot"}`,
+		},
+		{
+			name: "secrets in arrays",
+			body: `[{"Authorization":"Bearer x"},{"name":"ok"}]`,
+			want: `[{"Authorization":"[REDACTED]"},{"name":"ok"}]`,
+		},
+		{
+			name: "non json body",
+			body: "password=qwerty",
+			want: "[non-json body omitted, 15 bytes]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, Redact([]byte(tt.body)))
+		})
+	}
+}
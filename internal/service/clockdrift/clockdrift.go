@@ -0,0 +1,245 @@
+// Package clockdrift следит за расхождением локальных часов с часами внешних источников
+// (Vault, Redis, NTP). Токены выпускаются и проверяются по локальному времени, поэтому
+// экземпляр с ушедшими часами выпускает токены, которые остальные экземпляры считают еще не
+// действующими или уже истекшими. Расхождение выставляется в метрику и пишется в журнал,
+// если превышает порог.
+package clockdrift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultInterval - периодичность проверки по умолчанию.
+	DefaultInterval = time.Minute
+	// DefaultThreshold - допустимое расхождение по умолчанию. Vault отдает время с точностью
+	// до секунды, поэтому порог меньше пары секунд давал бы ложные срабатывания.
+	DefaultThreshold = 5 * time.Second
+	// DefaultTimeout - таймаут запроса времени у одного источника по умолчанию.
+	DefaultTimeout = 2 * time.Second
+)
+
+// Source - источник эталонного времени.
+type Source func(ctx context.Context) (time.Time, error)
+
+// Result - результат проверки одного источника.
+type Result struct {
+	// Drift - насколько часы источника впереди локальных. Отрицательное значение - локальные часы спешат.
+	Drift time.Duration
+	// RTT - время запроса к источнику, погрешность оценки не больше половины RTT.
+	RTT time.Duration
+	Err error
+}
+
+// Exceeded возвращает true, если расхождение по модулю больше порога.
+func (r Result) Exceeded(threshold time.Duration) bool {
+	return r.Err == nil && r.Drift.Abs() > threshold
+}
+
+// Monitor - проверка расхождения часов.
+type Monitor struct {
+	sources   map[string]Source
+	interval  time.Duration
+	threshold time.Duration
+	timeout   time.Duration
+
+	registerer prometheus.Registerer
+	drift      *prometheus.GaugeVec
+	exceeded   *prometheus.GaugeVec
+	errors     *prometheus.CounterVec
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Monitor.
+type Option func(*Monitor)
+
+// WithSource добавляет источник эталонного времени.
+func WithSource(name string, source Source) Option {
+	return func(m *Monitor) {
+		m.sources[name] = source
+	}
+}
+
+// WithInterval устанавливает периодичность проверки. По умолчанию DefaultInterval.
+func WithInterval(interval time.Duration) Option {
+	return func(m *Monitor) {
+		m.interval = interval
+	}
+}
+
+// WithThreshold устанавливает допустимое расхождение. По умолчанию DefaultThreshold.
+func WithThreshold(threshold time.Duration) Option {
+	return func(m *Monitor) {
+		m.threshold = threshold
+	}
+}
+
+// WithTimeout устанавливает таймаут запроса времени у одного источника. По умолчанию DefaultTimeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(m *Monitor) {
+		m.timeout = timeout
+	}
+}
+
+// WithRegisterer устанавливает реестр метрик. По умолчанию используется prometheus.DefaultRegisterer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(m *Monitor) {
+		m.registerer = registerer
+	}
+}
+
+// New создает новый Monitor и регистрирует его метрики.
+func New(opts ...Option) (*Monitor, error) {
+	m := &Monitor{
+		sources:    map[string]Source{},
+		interval:   DefaultInterval,
+		threshold:  DefaultThreshold,
+		timeout:    DefaultTimeout,
+		registerer: prometheus.DefaultRegisterer,
+		now:        time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if len(m.sources) == 0 {
+		return nil, errors.New("at least one time source is required")
+	}
+
+	for name, source := range m.sources {
+		if source == nil {
+			return nil, fmt.Errorf("time source %s is nil", name)
+		}
+	}
+
+	if m.interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+
+	if m.threshold <= 0 {
+		return nil, errors.New("threshold must be positive")
+	}
+
+	if m.timeout <= 0 {
+		return nil, errors.New("timeout must be positive")
+	}
+
+	if m.registerer == nil {
+		return nil, errors.New("registerer is required")
+	}
+
+	m.drift = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "auth_clock_drift_seconds",
+		Help: "Расхождение часов источника с локальными в секундах: положительное - локальные часы отстают.",
+	}, []string{"source"})
+
+	m.exceeded = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "auth_clock_drift_exceeded",
+		Help: "1, если расхождение часов с источником больше допустимого.",
+	}, []string{"source"})
+
+	m.errors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_clock_drift_check_errors_total",
+		Help: "Количество ошибок получения времени у источника.",
+	}, []string{"source"})
+
+	for _, c := range []prometheus.Collector{m.drift, m.exceeded, m.errors} {
+		if err := m.registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// Start проверяет часы сразу и затем периодически до отмены контекста.
+func (m *Monitor) Start(ctx context.Context) error {
+	m.Check(ctx)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			m.Check(ctx)
+		}
+	}
+}
+
+// Check запрашивает время у всех источников, обновляет метрики и пишет в журнал превышения порога.
+func (m *Monitor) Check(ctx context.Context) map[string]Result {
+	names := make([]string, 0, len(m.sources))
+	for name := range m.sources {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	results := make(map[string]Result, len(names))
+
+	for _, name := range names {
+		res := m.measure(ctx, m.sources[name])
+		results[name] = res
+
+		log := logrus.WithField("source", name)
+
+		if res.Err != nil {
+			m.errors.WithLabelValues(name).Inc()
+
+			if ctx.Err() == nil {
+				log.WithError(res.Err).Warn("error get time for clock drift check")
+			}
+
+			continue
+		}
+
+		m.drift.WithLabelValues(name).Set(res.Drift.Seconds())
+
+		log = log.WithFields(logrus.Fields{
+			"drift":     res.Drift.String(),
+			"rtt":       res.RTT.String(),
+			"threshold": m.threshold.String(),
+		})
+
+		if res.Exceeded(m.threshold) {
+			m.exceeded.WithLabelValues(name).Set(1)
+			log.Warn("local clock drift exceeds threshold, tokens may be rejected by other instances")
+		} else {
+			m.exceeded.WithLabelValues(name).Set(0)
+			log.Debug("clock drift checked")
+		}
+	}
+
+	return results
+}
+
+// measure оценивает расхождение с источником: время источника сравнивается с локальным
+// временем середины запроса.
+func (m *Monitor) measure(ctx context.Context, source Source) Result {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	sent := m.now()
+
+	remote, err := source(ctx)
+	if err != nil {
+		return Result{Err: err}
+	}
+
+	rtt := m.now().Sub(sent)
+	local := sent.Add(rtt / 2)
+
+	return Result{Drift: remote.Sub(local), RTT: rtt}
+}
@@ -0,0 +1,178 @@
+package clockdrift
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fixedSource(t time.Time) Source {
+	return func(context.Context) (time.Time, error) { return t, nil }
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	source := fixedSource(time.Now())
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr string
+	}{
+		{
+			name: "ok",
+			opts: []Option{WithSource("redis", source)},
+		},
+		{
+			name:    "no sources",
+			wantErr: "at least one time source is required",
+		},
+		{
+			name:    "nil source",
+			opts:    []Option{WithSource("redis", nil)},
+			wantErr: "time source redis is nil",
+		},
+		{
+			name:    "zero interval",
+			opts:    []Option{WithSource("redis", source), WithInterval(0)},
+			wantErr: "interval must be positive",
+		},
+		{
+			name:    "zero threshold",
+			opts:    []Option{WithSource("redis", source), WithThreshold(0)},
+			wantErr: "threshold must be positive",
+		},
+		{
+			name:    "zero timeout",
+			opts:    []Option{WithSource("redis", source), WithTimeout(0)},
+			wantErr: "timeout must be positive",
+		},
+		{
+			name:    "nil registerer",
+			opts:    []Option{WithSource("redis", source), WithRegisterer(nil)},
+			wantErr: "registerer is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := New(append([]Option{WithRegisterer(prometheus.NewRegistry())}, tt.opts...)...)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, m)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, m)
+		})
+	}
+}
+
+func TestMonitor_Check(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	m, err := New(
+		WithSource("vault", fixedSource(now.Add(10*time.Second))),
+		WithSource("redis", fixedSource(now.Add(-time.Second))),
+		WithThreshold(5*time.Second),
+		WithRegisterer(prometheus.NewRegistry()),
+	)
+	require.NoError(t, err)
+
+	// запрос к источнику длится 200ms: локальное время середины запроса - now+100ms
+	calls := 0
+	m.now = func() time.Time {
+		calls++
+		if calls%2 == 0 {
+			return now.Add(200 * time.Millisecond)
+		}
+
+		return now
+	}
+
+	results := m.Check(t.Context())
+	require.Len(t, results, 2)
+
+	assert.Equal(t, 9900*time.Millisecond, results["vault"].Drift)
+	assert.Equal(t, 200*time.Millisecond, results["vault"].RTT)
+	assert.True(t, results["vault"].Exceeded(5*time.Second))
+
+	assert.Equal(t, -1100*time.Millisecond, results["redis"].Drift)
+	assert.False(t, results["redis"].Exceeded(5*time.Second))
+
+	assert.InDelta(t, 9.9, testutil.ToFloat64(m.drift.WithLabelValues("vault")), 1e-9)
+	assert.InDelta(t, -1.1, testutil.ToFloat64(m.drift.WithLabelValues("redis")), 1e-9)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.exceeded.WithLabelValues("vault")), 0)
+	assert.InDelta(t, 0, testutil.ToFloat64(m.exceeded.WithLabelValues("redis")), 0)
+}
+
+func TestMonitor_Check_Errors(t *testing.T) {
+	t.Parallel()
+
+	m, err := New(
+		WithSource("slow", func(ctx context.Context) (time.Time, error) {
+			<-ctx.Done()
+
+			return time.Time{}, ctx.Err()
+		}),
+		WithSource("ntp", func(context.Context) (time.Time, error) { return time.Time{}, errors.New("unreachable") }),
+		WithTimeout(10*time.Millisecond),
+		WithRegisterer(prometheus.NewRegistry()),
+	)
+	require.NoError(t, err)
+
+	results := m.Check(t.Context())
+	require.ErrorIs(t, results["slow"].Err, context.DeadlineExceeded)
+	require.EqualError(t, results["ntp"].Err, "unreachable")
+	assert.False(t, results["ntp"].Exceeded(time.Second))
+
+	assert.InDelta(t, 1, testutil.ToFloat64(m.errors.WithLabelValues("slow")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.errors.WithLabelValues("ntp")), 0)
+}
+
+func TestMonitor_Start(t *testing.T) {
+	t.Parallel()
+
+	checked := make(chan struct{}, 1)
+
+	m, err := New(
+		WithSource("redis", func(context.Context) (time.Time, error) {
+			select {
+			case checked <- struct{}{}:
+			default:
+			}
+
+			return time.Now(), nil
+		}),
+		WithRegisterer(prometheus.NewRegistry()),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+
+	go func() { done <- m.Start(ctx) }()
+
+	// первая проверка выполняется сразу, не дожидаясь интервала
+	select {
+	case <-checked:
+	case <-time.After(time.Second):
+		t.Fatal("clock was not checked on start")
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,91 @@
+package clockdrift
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	// ntpPacketSize - размер пакета SNTP (RFC 4330).
+	ntpPacketSize = 48
+	// ntpEpochOffset - секунды между эпохой NTP (1900) и эпохой Unix (1970).
+	ntpEpochOffset = 2208988800
+	// ntpClientRequest - LI = 0, версия 4, режим 3 (клиент).
+	ntpClientRequest = 0x23
+	// ntpModeServer - режим 4 (сервер) в ответе.
+	ntpModeServer = 4
+)
+
+// NTP возвращает источник времени, который опрашивает сервер NTP по SNTP (RFC 4330).
+// Адрес указывается как host:port, например pool.ntp.org:123.
+func NTP(addr string) Source {
+	return func(ctx context.Context) (time.Time, error) {
+		var d net.Dialer
+
+		conn, err := d.DialContext(ctx, "udp", addr)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("ntp: error dial %s: %w", addr, err)
+		}
+		defer conn.Close()
+
+		if deadline, ok := ctx.Deadline(); ok {
+			if err := conn.SetDeadline(deadline); err != nil {
+				return time.Time{}, fmt.Errorf("ntp: error set deadline: %w", err)
+			}
+		}
+
+		req := make([]byte, ntpPacketSize)
+		req[0] = ntpClientRequest
+
+		if _, err := conn.Write(req); err != nil {
+			return time.Time{}, fmt.Errorf("ntp: error send request: %w", err)
+		}
+
+		resp := make([]byte, ntpPacketSize)
+
+		n, err := conn.Read(resp)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("ntp: error read response: %w", err)
+		}
+
+		return parseNTP(resp[:n])
+	}
+}
+
+// parseNTP возвращает время отправки ответа сервером (transmit timestamp).
+func parseNTP(resp []byte) (time.Time, error) {
+	if len(resp) < ntpPacketSize {
+		return time.Time{}, errors.New("ntp: response is too short")
+	}
+
+	if mode := resp[0] & 0x07; mode != ntpModeServer {
+		return time.Time{}, fmt.Errorf("ntp: unexpected mode %d", mode)
+	}
+
+	// stratum 0 - kiss-o'-death: сервер просит не опрашивать его
+	if resp[1] == 0 {
+		return time.Time{}, fmt.Errorf("ntp: kiss-o'-death %q", resp[12:16])
+	}
+
+	seconds := binary.BigEndian.Uint32(resp[40:44])
+	fraction := binary.BigEndian.Uint32(resp[44:48])
+
+	if seconds == 0 {
+		return time.Time{}, errors.New("ntp: empty transmit timestamp")
+	}
+
+	unix := int64(seconds) - ntpEpochOffset
+
+	// старший бит 0 - время после переполнения счетчика в 2036 году (RFC 4330, раздел 3)
+	if seconds&0x80000000 == 0 {
+		unix += 1 << 32
+	}
+
+	nanos := (int64(fraction) * int64(time.Second)) >> 32
+
+	return time.Unix(unix, nanos), nil
+}
@@ -0,0 +1,81 @@
+package clockdrift
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ntpResponse собирает ответ сервера NTP с временем отправки at.
+func ntpResponse(at time.Time, stratum byte) []byte {
+	resp := make([]byte, ntpPacketSize)
+	resp[0] = 0x24 // версия 4, режим 4 (сервер)
+	resp[1] = stratum
+
+	seconds := uint32(at.Unix() + ntpEpochOffset) //nolint:gosec // переполнение в 2036 году ожидаемо
+	fraction := uint32((int64(at.Nanosecond()) << 32) / int64(time.Second))
+
+	binary.BigEndian.PutUint32(resp[40:44], seconds)
+	binary.BigEndian.PutUint32(resp[44:48], fraction)
+
+	return resp
+}
+
+func TestParseNTP(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 10, 16, 12, 30, 0, 500_000_000, time.UTC)
+
+	got, err := parseNTP(ntpResponse(at, 2))
+	require.NoError(t, err)
+	assert.WithinDuration(t, at, got, time.Microsecond)
+
+	// после переполнения 32-битного счетчика секунд NTP
+	after := time.Date(2040, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	got, err = parseNTP(ntpResponse(after, 2))
+	require.NoError(t, err)
+	assert.Equal(t, after.Unix(), got.Unix())
+
+	_, err = parseNTP(make([]byte, 10))
+	require.ErrorContains(t, err, "too short")
+
+	_, err = parseNTP(ntpResponse(at, 0))
+	require.ErrorContains(t, err, "kiss-o'-death")
+
+	client := ntpResponse(at, 2)
+	client[0] = ntpClientRequest
+
+	_, err = parseNTP(client)
+	require.ErrorContains(t, err, "unexpected mode 3")
+}
+
+func TestNTP(t *testing.T) {
+	t.Parallel()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	at := time.Date(2026, 10, 16, 12, 30, 0, 0, time.UTC)
+
+	go func() {
+		req := make([]byte, ntpPacketSize)
+
+		_, addr, err := conn.ReadFrom(req)
+		if err != nil || req[0] != ntpClientRequest {
+			return
+		}
+
+		_, _ = conn.WriteTo(ntpResponse(at, 1), addr)
+	}()
+
+	got, err := NTP(conn.LocalAddr().String())(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, at, got.UTC())
+}
@@ -0,0 +1,139 @@
+// Package codec кодирует записи, которые сервис хранит в Redis (сессии, токены). Кроме JSON
+// поддерживается MessagePack: записи в нем в 2-3 раза меньше и быстрее разбираются.
+//
+// Запись MessagePack начинается с конверта из двух байт: маркера 0xc1 (этот байт не встречается
+// в начале JSON и не используется в MessagePack) и версии формата. По версии новые экземпляры
+// сервиса смогут менять формат, а старые - отказываться от записей, которые не понимают, вместо
+// того чтобы прочитать их неверно. Записи без маркера читаются как JSON.
+//
+// Переход с JSON: Unmarshal читает оба формата независимо от формата записи, поэтому сначала все
+// экземпляры обновляются с форматом json, затем включается msgpack. Старые записи остаются
+// в JSON и перезаписываются в MessagePack при изменении или истекают. Вернуться на json можно
+// так же: записи MessagePack читаются при любом формате записи.
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Format - формат новых записей.
+type Format string
+
+const (
+	// FormatJSON - JSON без конверта, формат по умолчанию.
+	FormatJSON Format = "json"
+	// FormatMsgpack - MessagePack в конверте с версией.
+	FormatMsgpack Format = "msgpack"
+)
+
+const (
+	// envelopeMarker - первый байт записи в конверте.
+	envelopeMarker byte = 0xc1
+	// versionMsgpack - версия конверта: MessagePack, поля структур по именам из тегов json.
+	versionMsgpack byte = 1
+
+	// structTag - тег имен полей. Используются теги json, чтобы имена полей совпадали в обоих форматах.
+	structTag = "json"
+)
+
+var (
+	// ErrUnsupportedVersion - запись в конверте неизвестной версии, например записанная более новой версией сервиса.
+	ErrUnsupportedVersion = errors.New("codec: unsupported record version")
+	// ErrEmpty - пустая запись.
+	ErrEmpty = errors.New("codec: empty record")
+)
+
+// Codec - кодирование записей в выбранном формате.
+type Codec struct {
+	format Format
+}
+
+// Option - опция для настройки Codec.
+type Option func(*Codec)
+
+// WithFormat устанавливает формат новых записей. По умолчанию FormatJSON.
+func WithFormat(format Format) Option {
+	return func(c *Codec) {
+		c.format = format
+	}
+}
+
+// New создает новый Codec.
+func New(opts ...Option) (*Codec, error) {
+	c := &Codec{format: FormatJSON}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	switch c.format {
+	case FormatJSON, FormatMsgpack:
+	default:
+		return nil, fmt.Errorf("unknown format %q", c.format)
+	}
+
+	return c, nil
+}
+
+// Format возвращает формат новых записей.
+func (c *Codec) Format() Format {
+	return c.format
+}
+
+// Marshal кодирует v в формате новых записей.
+func (c *Codec) Marshal(v any) ([]byte, error) {
+	if c.format == FormatJSON {
+		return json.Marshal(v)
+	}
+
+	buf := bytes.NewBuffer([]byte{envelopeMarker, versionMsgpack})
+
+	enc := msgpack.NewEncoder(buf)
+	enc.SetCustomStructTag(structTag)
+	enc.UseCompactInts(true)
+
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("codec: error encode msgpack: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal декодирует запись в любом поддерживаемом формате, независимо от формата новых записей.
+func (c *Codec) Unmarshal(data []byte, v any) error {
+	return Unmarshal(data, v)
+}
+
+// Unmarshal декодирует запись: в конверте - по версии конверта, без конверта - как JSON.
+// Неизвестные поля пропускаются в обоих форматах.
+func Unmarshal(data []byte, v any) error {
+	if len(data) == 0 {
+		return ErrEmpty
+	}
+
+	if data[0] != envelopeMarker {
+		return json.Unmarshal(data, v)
+	}
+
+	if len(data) < 2 {
+		return fmt.Errorf("%w: truncated envelope", ErrUnsupportedVersion)
+	}
+
+	if version := data[1]; version != versionMsgpack {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+
+	dec := msgpack.NewDecoder(bytes.NewReader(data[2:]))
+	dec.SetCustomStructTag(structTag)
+
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("codec: error decode msgpack: %w", err)
+	}
+
+	return nil
+}
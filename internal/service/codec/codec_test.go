@@ -0,0 +1,190 @@
+package codec
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type record struct {
+	ID        string     `json:"id"`
+	Parent    string     `json:"parent,omitempty"`
+	Count     int        `json:"count"`
+	CreatedAt time.Time  `json:"created_at"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty"`
+}
+
+// recordV2 - record с полем, добавленным в новой версии сервиса.
+type recordV2 struct {
+	record
+
+	Device string `json:"device"`
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		want    Format
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case: json by default",
+			want:    FormatJSON,
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: msgpack",
+			opts:    []Option{WithFormat(FormatMsgpack)},
+			want:    FormatMsgpack,
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: unknown format",
+			opts:    []Option{WithFormat("protobuf")},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := New(tt.opts...)
+			tt.wantErr(t, err)
+
+			if err == nil {
+				assert.Equal(t, tt.want, c.Format())
+			}
+		})
+	}
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	rotated := now.Add(time.Hour)
+
+	for _, format := range []Format{FormatJSON, FormatMsgpack} {
+		t.Run(string(format), func(t *testing.T) {
+			t.Parallel()
+
+			c, err := New(WithFormat(format))
+			require.NoError(t, err)
+
+			for _, want := range []record{
+				{ID: "t1", Count: 3, CreatedAt: now},
+				{ID: "t2", Parent: "t1", CreatedAt: now, RotatedAt: &rotated},
+			} {
+				data, err := c.Marshal(want)
+				require.NoError(t, err)
+
+				var got record
+
+				require.NoError(t, c.Unmarshal(data, &got))
+				assert.Equal(t, want.ID, got.ID)
+				assert.Equal(t, want.Parent, got.Parent)
+				assert.Equal(t, want.Count, got.Count)
+				assert.True(t, want.CreatedAt.Equal(got.CreatedAt))
+
+				if want.RotatedAt == nil {
+					assert.Nil(t, got.RotatedAt)
+				} else {
+					require.NotNil(t, got.RotatedAt)
+					assert.True(t, want.RotatedAt.Equal(*got.RotatedAt))
+				}
+			}
+		})
+	}
+}
+
+func TestCodec_Marshal_Msgpack(t *testing.T) {
+	t.Parallel()
+
+	c, err := New(WithFormat(FormatMsgpack))
+	require.NoError(t, err)
+
+	rec := record{ID: "abcdefghijklmnop", Parent: "qrstuvwxyzabcdef", CreatedAt: time.Now()}
+
+	data, err := c.Marshal(rec)
+	require.NoError(t, err)
+	require.Equal(t, []byte{envelopeMarker, versionMsgpack}, data[:2])
+
+	legacy, err := json.Marshal(rec)
+	require.NoError(t, err)
+	assert.Less(t, len(data), len(legacy))
+
+	// поля записываются по именам из тегов json
+	var fields map[string]any
+
+	require.NoError(t, msgpack.Unmarshal(data[2:], &fields))
+	assert.Contains(t, fields, "created_at")
+	assert.NotContains(t, fields, "rotated_at")
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	msgpackCodec, err := New(WithFormat(FormatMsgpack))
+	require.NoError(t, err)
+
+	newer, err := msgpackCodec.Marshal(recordV2{record: record{ID: "t1"}, Device: "phone"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantErr error
+	}{
+		{
+			name: "positive case: legacy json",
+			data: []byte(`{"id":"t1","created_at":"2026-01-01T00:00:00Z"}`),
+			want: "t1",
+		},
+		{
+			name: "positive case: unknown fields are skipped",
+			data: newer,
+			want: "t1",
+		},
+		{
+			name:    "negative case: empty",
+			data:    nil,
+			wantErr: ErrEmpty,
+		},
+		{
+			name:    "negative case: truncated envelope",
+			data:    []byte{envelopeMarker},
+			wantErr: ErrUnsupportedVersion,
+		},
+		{
+			name:    "negative case: unknown version",
+			data:    []byte{envelopeMarker, 2, 0x80},
+			wantErr: ErrUnsupportedVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got record
+
+			err := Unmarshal(tt.data, &got)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.ID)
+		})
+	}
+}
@@ -0,0 +1,286 @@
+// Package credpolicy проверяет логины и пароли, которые пользователь задает при регистрации
+// и смене пароля, на соответствие настраиваемой политике: длина, классы символов, запрещенные
+// пароли, формат и зарезервированные логины. Нарушения возвращаются с машиночитаемыми кодами.
+package credpolicy
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Поля, к которым относятся нарушения.
+const (
+	FieldUsername = "username"
+	FieldPassword = "password"
+)
+
+// Коды нарушений.
+const (
+	CodeTooShort         = "too_short"
+	CodeTooLong          = "too_long"
+	CodeMissingLowercase = "missing_lowercase"
+	CodeMissingUppercase = "missing_uppercase"
+	CodeMissingDigit     = "missing_digit"
+	CodeMissingSymbol    = "missing_symbol"
+	CodeTooFewClasses    = "too_few_character_classes"
+	CodeBanned           = "banned"
+	CodeContainsUsername = "contains_username"
+	CodeInvalidFormat    = "invalid_format"
+	CodeReserved         = "reserved"
+)
+
+// Violation - нарушение политики.
+type Violation struct {
+	Field   string
+	Code    string
+	Message string
+	// Params - параметры нарушенного требования, например минимальная длина.
+	Params map[string]any
+}
+
+// PasswordRules - требования к паролю. Нулевые значения - требование не проверяется.
+type PasswordRules struct {
+	MinLength int
+	MaxLength int
+
+	RequireLowercase bool
+	RequireUppercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	// MinClasses - сколько разных классов символов (строчные, прописные, цифры, прочие) должно быть в пароле.
+	MinClasses int
+
+	// Banned - запрещенные пароли, сравниваются без учета регистра.
+	Banned []string
+	// DisallowUsername - пароль не должен содержать логин.
+	DisallowUsername bool
+}
+
+// UsernameRules - требования к логину. Нулевые значения - требование не проверяется.
+type UsernameRules struct {
+	MinLength int
+	MaxLength int
+	// Pattern - регулярное выражение, которому должен соответствовать весь логин.
+	Pattern string
+	// Reserved - зарезервированные логины, сравниваются без учета регистра.
+	Reserved []string
+}
+
+// Policy - политика логинов и паролей.
+type Policy struct {
+	password PasswordRules
+	username UsernameRules
+
+	pattern  *regexp.Regexp
+	banned   map[string]struct{}
+	reserved map[string]struct{}
+}
+
+// Option - опция для настройки Policy.
+type Option func(*Policy)
+
+// WithPasswordRules устанавливает требования к паролю.
+func WithPasswordRules(rules PasswordRules) Option {
+	return func(p *Policy) {
+		p.password = rules
+	}
+}
+
+// WithUsernameRules устанавливает требования к логину.
+func WithUsernameRules(rules UsernameRules) Option {
+	return func(p *Policy) {
+		p.username = rules
+	}
+}
+
+// New создает новую Policy.
+func New(opts ...Option) (*Policy, error) {
+	p := &Policy{}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if p.password.MaxLength != 0 && p.password.MaxLength < p.password.MinLength {
+		return nil, errors.New("password max length is less than min length")
+	}
+
+	if p.username.MaxLength != 0 && p.username.MaxLength < p.username.MinLength {
+		return nil, errors.New("username max length is less than min length")
+	}
+
+	if p.password.MinClasses > len(characterClasses) {
+		return nil, fmt.Errorf("password min classes must not exceed %d", len(characterClasses))
+	}
+
+	if p.username.Pattern != "" {
+		pattern, err := regexp.Compile(`^(?:` + p.username.Pattern + `)$`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid username pattern: %w", err)
+		}
+
+		p.pattern = pattern
+	}
+
+	p.banned = lowerSet(p.password.Banned)
+	p.reserved = lowerSet(p.username.Reserved)
+
+	return p, nil
+}
+
+// lowerSet возвращает множество значений в нижнем регистре.
+func lowerSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[strings.ToLower(v)] = struct{}{}
+	}
+
+	return set
+}
+
+// Check проверяет логин и пароль. Пустой логин или пароль не проверяется.
+// Возвращает все нарушения, пустой список - учетные данные соответствуют политике.
+func (p *Policy) Check(username, password string) []Violation {
+	violations := []Violation{}
+
+	if username != "" {
+		violations = append(violations, p.checkUsername(username)...)
+	}
+
+	if password != "" {
+		violations = append(violations, p.checkPassword(username, password)...)
+	}
+
+	return violations
+}
+
+// checkLength проверяет длину значения поля в символах.
+func checkLength(field, value string, minLength, maxLength int) []Violation {
+	length := utf8.RuneCountInString(value)
+
+	switch {
+	case minLength != 0 && length < minLength:
+		return []Violation{{
+			Field:   field,
+			Code:    CodeTooShort,
+			Message: fmt.Sprintf("%s must be at least %d characters long", field, minLength),
+			Params:  map[string]any{"min_length": minLength},
+		}}
+	case maxLength != 0 && length > maxLength:
+		return []Violation{{
+			Field:   field,
+			Code:    CodeTooLong,
+			Message: fmt.Sprintf("%s must be at most %d characters long", field, maxLength),
+			Params:  map[string]any{"max_length": maxLength},
+		}}
+	default:
+		return nil
+	}
+}
+
+// checkUsername проверяет логин.
+func (p *Policy) checkUsername(username string) []Violation {
+	violations := checkLength(FieldUsername, username, p.username.MinLength, p.username.MaxLength)
+
+	if p.pattern != nil && !p.pattern.MatchString(username) {
+		violations = append(violations, Violation{
+			Field:   FieldUsername,
+			Code:    CodeInvalidFormat,
+			Message: "username has invalid format",
+			Params:  map[string]any{"pattern": p.username.Pattern},
+		})
+	}
+
+	if _, ok := p.reserved[strings.ToLower(username)]; ok {
+		violations = append(violations, Violation{
+			Field:   FieldUsername,
+			Code:    CodeReserved,
+			Message: "username is reserved",
+		})
+	}
+
+	return violations
+}
+
+// characterClass - класс символов пароля.
+type characterClass struct {
+	code     string
+	name     string
+	matches  func(r rune) bool
+	required func(rules PasswordRules) bool
+}
+
+// characterClasses - классы символов в порядке проверки.
+var characterClasses = []characterClass{
+	{
+		code: CodeMissingLowercase, name: "lowercase letter", matches: unicode.IsLower,
+		required: func(rules PasswordRules) bool { return rules.RequireLowercase },
+	},
+	{
+		code: CodeMissingUppercase, name: "uppercase letter", matches: unicode.IsUpper,
+		required: func(rules PasswordRules) bool { return rules.RequireUppercase },
+	},
+	{
+		code: CodeMissingDigit, name: "digit", matches: unicode.IsDigit,
+		required: func(rules PasswordRules) bool { return rules.RequireDigit },
+	},
+	{
+		code: CodeMissingSymbol, name: "symbol",
+		matches:  func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) },
+		required: func(rules PasswordRules) bool { return rules.RequireSymbol },
+	},
+}
+
+// checkPassword проверяет пароль.
+func (p *Policy) checkPassword(username, password string) []Violation {
+	violations := checkLength(FieldPassword, password, p.password.MinLength, p.password.MaxLength)
+
+	classes := 0
+
+	for _, class := range characterClasses {
+		if strings.ContainsFunc(password, class.matches) {
+			classes++
+
+			continue
+		}
+
+		if class.required(p.password) {
+			violations = append(violations, Violation{
+				Field:   FieldPassword,
+				Code:    class.code,
+				Message: "password must contain at least one " + class.name,
+			})
+		}
+	}
+
+	if classes < p.password.MinClasses {
+		violations = append(violations, Violation{
+			Field:   FieldPassword,
+			Code:    CodeTooFewClasses,
+			Message: fmt.Sprintf("password must contain at least %d of: lowercase letters, uppercase letters, digits, symbols", p.password.MinClasses),
+			Params:  map[string]any{"min_classes": p.password.MinClasses},
+		})
+	}
+
+	if _, ok := p.banned[strings.ToLower(password)]; ok {
+		violations = append(violations, Violation{
+			Field:   FieldPassword,
+			Code:    CodeBanned,
+			Message: "password is too common",
+		})
+	}
+
+	if p.password.DisallowUsername && username != "" && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		violations = append(violations, Violation{
+			Field:   FieldPassword,
+			Code:    CodeContainsUsername,
+			Message: "password must not contain username",
+		})
+	}
+
+	return violations
+}
@@ -0,0 +1,166 @@
+package credpolicy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func codes(violations []Violation) []string {
+	result := []string{}
+	for _, v := range violations {
+		result = append(result, v.Field+":"+v.Code)
+	}
+
+	return result
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestPolicy_Check(t *testing.T) {
+	t.Parallel()
+
+	policy, err := New(
+		WithPasswordRules(PasswordRules{
+			MinLength:        10,
+			MaxLength:        64,
+			RequireDigit:     true,
+			MinClasses:       3,
+			Banned:           []string{"Password123!"},
+			DisallowUsername: true,
+		}),
+		WithUsernameRules(UsernameRules{
+			MinLength: 3,
+			MaxLength: 16,
+			Pattern:   `[a-z0-9_]+`,
+			Reserved:  []string{"admin", "root"},
+		}),
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		want     []string
+	}{
+		{
+			name:     "positive case: acceptable credentials",
+			username: "jdoe",
+			password: "Correct-horse-7",
+			want:     []string{},
+		},
+		{
+			name:     "positive case: only password",
+			password: "Correct-horse-7",
+			want:     []string{},
+		},
+		{
+			name:     "negative case: short password without digit",
+			username: "jdoe",
+			password: "abcDEF",
+			want:     []string{"password:too_short", "password:missing_digit", "password:too_few_character_classes"},
+		},
+		{
+			name:     "negative case: too long password",
+			password: "Aa1" + strings.Repeat("a", 62),
+			want:     []string{"password:too_long"},
+		},
+		{
+			name:     "negative case: banned password",
+			password: "password123!",
+			want:     []string{"password:banned"},
+		},
+		{
+			name:     "negative case: password contains username",
+			username: "jdoe",
+			password: "my-JDOE-pass-1",
+			want:     []string{"password:contains_username"},
+		},
+		{
+			name:     "negative case: reserved username",
+			username: "Admin",
+			want:     []string{"username:invalid_format", "username:reserved"},
+		},
+		{
+			name:     "negative case: invalid username",
+			username: "jd",
+			want:     []string{"username:too_short"},
+		},
+		{
+			name:     "negative case: pattern must match whole username",
+			username: "john.doe",
+			want:     []string{"username:invalid_format"},
+		},
+		{
+			name:     "negative case: unicode length",
+			username: "jdoe",
+			password: "пароль-1А",
+			want:     []string{"password:too_short"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, codes(policy.Check(tt.username, tt.password)))
+		})
+	}
+}
+
+func TestPolicy_Check_Params(t *testing.T) {
+	t.Parallel()
+
+	policy, err := New(WithPasswordRules(PasswordRules{MinLength: 12}))
+	require.NoError(t, err)
+
+	assert.Equal(t, []Violation{{
+		Field:   FieldPassword,
+		Code:    CodeTooShort,
+		Message: "password must be at least 12 characters long",
+		Params:  map[string]any{"min_length": 12},
+	}}, policy.Check("", "short"))
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{name: "positive case: empty policy"},
+		{
+			name:    "error case: invalid pattern",
+			opts:    []Option{WithUsernameRules(UsernameRules{Pattern: "[a-z"})},
+			wantErr: true,
+		},
+		{
+			name:    "error case: max length less than min length",
+			opts:    []Option{WithPasswordRules(PasswordRules{MinLength: 10, MaxLength: 8})},
+			wantErr: true,
+		},
+		{
+			name:    "error case: too many classes",
+			opts:    []Option{WithPasswordRules(PasswordRules{MinClasses: 5})},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(tt.opts...)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package dependency
+
+// Class - класс эндпоинта по набору необходимых ему зависимостей.
+type Class string
+
+const (
+	// ClassInfo - служебные эндпоинты (health, версия), не требуют зависимостей.
+	ClassInfo Class = "info"
+	// ClassValidation - проверка токенов. Работает по закэшированным ключам, поэтому не требует зависимостей.
+	ClassValidation Class = "validation"
+	// ClassSession - операции с сессиями и черным списком, требуют Redis.
+	ClassSession Class = "session"
+	// ClassIssuance - выпуск токенов, требует ключ подписи из Vault и запись сессии в Redis.
+	ClassIssuance Class = "issuance"
+)
+
+// Classes возвращает все классы эндпоинтов.
+func Classes() []Class {
+	return []Class{ClassInfo, ClassValidation, ClassSession, ClassIssuance}
+}
+
+// Requires возвращает список зависимостей, необходимых эндпоинтам класса.
+func (c Class) Requires() []Name {
+	switch c {
+	case ClassSession:
+		return []Name{Redis}
+	case ClassIssuance:
+		return []Name{Vault, Redis}
+	case ClassInfo, ClassValidation:
+		return nil
+	}
+
+	return nil
+}
+
+// Writes сообщает, меняют ли эндпоинты класса данные: в режиме только для чтения такие запросы отклоняются.
+func (c Class) Writes() bool {
+	return c == ClassSession || c == ClassIssuance
+}
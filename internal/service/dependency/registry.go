@@ -0,0 +1,272 @@
+package dependency
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Name - имя внешней зависимости сервиса.
+type Name string
+
+const (
+	// Vault - хранилище секретов (ключи подписи).
+	Vault Name = "vault"
+	// Redis - хранилище сессий, черных списков и кэша.
+	Redis Name = "redis"
+	// UserStore - внешний сервис пользователей. Проверяется, только если настроен.
+	UserStore Name = "user_store"
+)
+
+// State - состояние зависимости.
+type State string
+
+const (
+	// StateUnknown - зависимость еще не проверялась.
+	StateUnknown State = "unknown"
+	// StateUp - зависимость доступна.
+	StateUp State = "up"
+	// StateDown - зависимость недоступна.
+	StateDown State = "down"
+)
+
+// Level - уровень деградации сервиса.
+type Level string
+
+const (
+	// LevelFull - все зависимости доступны, сервис работает полностью.
+	LevelFull Level = "full"
+	// LevelDegraded - часть зависимостей недоступна, работает только часть функционала.
+	LevelDegraded Level = "degraded"
+	// LevelUnavailable - все зависимости недоступны.
+	LevelUnavailable Level = "unavailable"
+)
+
+// Status - статус зависимости на момент последней проверки.
+type Status struct {
+	State     State     `json:"state"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Checker - функция проверки зависимости. Возвращает ошибку, если зависимость недоступна.
+type Checker func(ctx context.Context) error
+
+const (
+	defaultInterval = 5 * time.Second
+	defaultTimeout  = 2 * time.Second
+)
+
+// Registry - реестр состояний зависимостей.
+// Периодически опрашивает зарегистрированные проверки и хранит результат,
+// на основе которого сервер решает, какие эндпоинты могут обслуживать запросы.
+type Registry struct {
+	interval   time.Duration
+	timeout    time.Duration
+	retryAfter time.Duration
+
+	checkers map[Name]Checker
+
+	mu       sync.RWMutex
+	statuses map[Name]Status
+}
+
+// Option - опция для настройки реестра.
+type Option func(*Registry)
+
+// WithChecker регистрирует проверку зависимости.
+func WithChecker(name Name, checker Checker) Option {
+	return func(r *Registry) {
+		r.checkers[name] = checker
+	}
+}
+
+// WithInterval устанавливает периодичность проверки зависимостей.
+func WithInterval(interval time.Duration) Option {
+	return func(r *Registry) {
+		r.interval = interval
+	}
+}
+
+// WithTimeout устанавливает таймаут одной проверки.
+func WithTimeout(timeout time.Duration) Option {
+	return func(r *Registry) {
+		r.timeout = timeout
+	}
+}
+
+// WithRetryAfter устанавливает значение, которое будет отдано клиенту в заголовке Retry-After.
+// По умолчанию совпадает с периодичностью проверки.
+func WithRetryAfter(retryAfter time.Duration) Option {
+	return func(r *Registry) {
+		r.retryAfter = retryAfter
+	}
+}
+
+// New создает новый реестр зависимостей.
+func New(opts ...Option) (*Registry, error) {
+	r := &Registry{
+		interval: defaultInterval,
+		timeout:  defaultTimeout,
+		checkers: map[Name]Checker{},
+		statuses: map[Name]Status{},
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.interval <= 0 {
+		return nil, errors.New("interval must be greater than 0")
+	}
+
+	if r.timeout <= 0 {
+		return nil, errors.New("timeout must be greater than 0")
+	}
+
+	if r.retryAfter <= 0 {
+		r.retryAfter = r.interval
+	}
+
+	for name, checker := range r.checkers {
+		if checker == nil {
+			return nil, errors.New("checker for " + string(name) + " is nil")
+		}
+
+		r.statuses[name] = Status{State: StateUnknown}
+	}
+
+	return r, nil
+}
+
+// Set сохраняет результат проверки зависимости. Ошибка nil означает, что зависимость доступна.
+func (r *Registry) Set(name Name, err error) {
+	status := Status{State: StateUp, CheckedAt: time.Now()}
+
+	if err != nil {
+		status.State = StateDown
+		status.Error = err.Error()
+	}
+
+	r.mu.Lock()
+	prev, ok := r.statuses[name]
+	r.statuses[name] = status
+	r.mu.Unlock()
+
+	if ok && prev.State != status.State {
+		logrus.WithFields(logrus.Fields{
+			"dependency": name,
+			"from":       prev.State,
+			"to":         status.State,
+			"error":      status.Error,
+		}).Warn("dependency state changed")
+	}
+}
+
+// Status возвращает статус зависимости.
+func (r *Registry) Status(name Name) Status {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	status, ok := r.statuses[name]
+	if !ok {
+		return Status{State: StateUnknown}
+	}
+
+	return status
+}
+
+// Statuses возвращает копию статусов всех зависимостей.
+func (r *Registry) Statuses() map[Name]Status {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := make(map[Name]Status, len(r.statuses))
+	for name, status := range r.statuses {
+		res[name] = status
+	}
+
+	return res
+}
+
+// Unavailable возвращает отсортированный список недоступных зависимостей из переданных.
+// Зависимость, которая еще не проверялась, считается доступной, чтобы не отклонять запросы на старте.
+func (r *Registry) Unavailable(names ...Name) []Name {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var res []Name
+
+	for _, name := range names {
+		if status, ok := r.statuses[name]; ok && status.State == StateDown {
+			res = append(res, name)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+
+	return res
+}
+
+// Level возвращает текущий уровень деградации сервиса.
+func (r *Registry) Level() Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	down := 0
+
+	for _, status := range r.statuses {
+		if status.State == StateDown {
+			down++
+		}
+	}
+
+	switch {
+	case down == 0:
+		return LevelFull
+	case down == len(r.statuses):
+		return LevelUnavailable
+	default:
+		return LevelDegraded
+	}
+}
+
+// RetryAfter возвращает время, через которое клиенту имеет смысл повторить запрос.
+func (r *Registry) RetryAfter() time.Duration {
+	return r.retryAfter
+}
+
+// Check выполняет все проверки один раз и сохраняет результаты.
+func (r *Registry) Check(ctx context.Context) {
+	for name, checker := range r.checkers {
+		checkCtx, cancel := context.WithTimeout(ctx, r.timeout)
+		r.Set(name, checker(checkCtx))
+		cancel()
+	}
+}
+
+// Start запускает периодическую проверку зависимостей. Блокирует до отмены контекста.
+func (r *Registry) Start(ctx context.Context) error {
+	logrus.WithFields(logrus.Fields{
+		"interval":     r.interval,
+		"dependencies": len(r.checkers),
+	}).Info("starting dependency checks")
+
+	r.Check(ctx)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.Check(ctx)
+		}
+	}
+}
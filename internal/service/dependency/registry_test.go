@@ -0,0 +1,225 @@
+package dependency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	okChecker := func(context.Context) error { return nil }
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		check   func(t *testing.T, r *Registry)
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "positive case: defaults",
+			opts: []Option{WithChecker(Vault, okChecker)},
+			check: func(t *testing.T, r *Registry) {
+				t.Helper()
+
+				assert.Equal(t, defaultInterval, r.interval)
+				assert.Equal(t, defaultTimeout, r.timeout)
+				assert.Equal(t, defaultInterval, r.RetryAfter())
+				assert.Equal(t, StateUnknown, r.Status(Vault).State)
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: custom retry after",
+			opts: []Option{WithInterval(time.Second), WithRetryAfter(10 * time.Second)},
+			check: func(t *testing.T, r *Registry) {
+				t.Helper()
+
+				assert.Equal(t, time.Second, r.interval)
+				assert.Equal(t, 10*time.Second, r.RetryAfter())
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "error case: interval is zero",
+			opts: []Option{WithInterval(0)},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "interval must be greater than 0")
+			},
+		},
+		{
+			name: "error case: timeout is zero",
+			opts: []Option{WithTimeout(0)},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "timeout must be greater than 0")
+			},
+		},
+		{
+			name: "error case: checker is nil",
+			opts: []Option{WithChecker(Redis, nil)},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "checker for redis is nil")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := New(tt.opts...)
+			tt.wantErr(t, err)
+
+			if tt.check != nil {
+				require.NotNil(t, r)
+				tt.check(t, r)
+			}
+		})
+	}
+}
+
+func TestRegistry_Level(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		statuses map[Name]error
+		want     Level
+	}{
+		{name: "all up", statuses: map[Name]error{Vault: nil, Redis: nil}, want: LevelFull},
+		{name: "one down", statuses: map[Name]error{Vault: errors.New("sealed"), Redis: nil}, want: LevelDegraded},
+		{name: "all down", statuses: map[Name]error{Vault: errors.New("sealed"), Redis: errors.New("timeout")}, want: LevelUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := New()
+			require.NoError(t, err)
+
+			for name, err := range tt.statuses {
+				r.Set(name, err)
+			}
+
+			assert.Equal(t, tt.want, r.Level())
+		})
+	}
+}
+
+func TestRegistry_Unavailable(t *testing.T) {
+	t.Parallel()
+
+	r, err := New(
+		WithChecker(Vault, func(context.Context) error { return nil }),
+		WithChecker(Redis, func(context.Context) error { return nil }),
+	)
+	require.NoError(t, err)
+
+	// еще не проверялись - считаются доступными
+	assert.Empty(t, r.Unavailable(ClassIssuance.Requires()...))
+
+	r.Set(Vault, errors.New("sealed"))
+	r.Set(Redis, nil)
+
+	assert.Equal(t, []Name{Vault}, r.Unavailable(ClassIssuance.Requires()...))
+	assert.Empty(t, r.Unavailable(ClassSession.Requires()...))
+	assert.Empty(t, r.Unavailable(ClassValidation.Requires()...))
+
+	status := r.Status(Vault)
+	assert.Equal(t, StateDown, status.State)
+	assert.Equal(t, "sealed", status.Error)
+	assert.False(t, status.CheckedAt.IsZero())
+}
+
+func TestRegistry_Check(t *testing.T) {
+	t.Parallel()
+
+	r, err := New(
+		WithChecker(Vault, func(context.Context) error { return errors.New("connection refused") }),
+		WithChecker(Redis, func(ctx context.Context) error {
+			_, ok := ctx.Deadline()
+			assert.True(t, ok, "check must have deadline")
+
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+
+	r.Check(t.Context())
+
+	statuses := r.Statuses()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, StateDown, statuses[Vault].State)
+	assert.Equal(t, StateUp, statuses[Redis].State)
+	assert.Equal(t, LevelDegraded, r.Level())
+}
+
+func TestRegistry_Start(t *testing.T) {
+	t.Parallel()
+
+	calls := make(chan struct{}, 10)
+
+	r, err := New(
+		WithInterval(10*time.Millisecond),
+		WithChecker(Redis, func(context.Context) error {
+			calls <- struct{}{}
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	done := make(chan error)
+
+	go func() {
+		done <- r.Start(ctx)
+	}()
+
+	for range 2 {
+		select {
+		case <-calls:
+		case <-time.After(time.Second):
+			require.Fail(t, "checker was not called")
+		}
+	}
+
+	cancel()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.Fail(t, "registry did not stop")
+	}
+}
+
+func TestClass_Requires(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		class Class
+		want  []Name
+	}{
+		{class: ClassInfo, want: nil},
+		{class: ClassValidation, want: nil},
+		{class: ClassSession, want: []Name{Redis}},
+		{class: ClassIssuance, want: []Name{Vault, Redis}},
+		{class: Class("unknown"), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.class), func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.class.Requires())
+		})
+	}
+}
@@ -0,0 +1,242 @@
+// Package deprecation ведет реестр устаревших маршрутов API. Ответы устаревших маршрутов
+// сообщают клиентам о снятии заголовками Deprecation (RFC 9745), Sunset (RFC 8594) и Link,
+// а обращения к ним учитываются в метриках и выборочно пишутся в журнал: по последнему
+// обращению видно, когда маршрут можно удалять.
+package deprecation
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultLogInterval - как часто по умолчанию пишется предупреждение об обращении к устаревшему маршруту.
+const DefaultLogInterval = time.Minute
+
+// Route - устаревший маршрут.
+type Route struct {
+	// Method - HTTP метод, например GET.
+	Method string
+	// Path - шаблон маршрута echo, например /api/v0/health.
+	Path string
+	// Since - когда маршрут объявлен устаревшим.
+	Since time.Time
+	// Sunset - когда маршрут будет удален. Нулевое значение - дата не назначена.
+	Sunset time.Time
+	// Link - документация о снятии маршрута (опционально).
+	Link string
+	// Successor - маршрут, который заменяет устаревший, например /api/v1/health (опционально).
+	Successor string
+}
+
+// Headers возвращает заголовки ответа устаревшего маршрута.
+func (r Route) Headers() http.Header {
+	h := http.Header{}
+	h.Set("Deprecation", "@"+strconv.FormatInt(r.Since.Unix(), 10))
+
+	if !r.Sunset.IsZero() {
+		h.Set("Sunset", r.Sunset.UTC().Format(http.TimeFormat))
+	}
+
+	if r.Link != "" {
+		h.Add("Link", fmt.Sprintf(`<%s>; rel="deprecation"; type="text/html"`, r.Link))
+	}
+
+	if r.Successor != "" {
+		h.Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, r.Successor))
+	}
+
+	return h
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
+
+// Registry - реестр устаревших маршрутов.
+type Registry struct {
+	routes      []Route
+	entries     map[string]*entry
+	logInterval time.Duration
+
+	registerer prometheus.Registerer
+	requests   *prometheus.CounterVec
+	lastSeen   *prometheus.GaugeVec
+
+	now func() time.Time
+}
+
+// entry - устаревший маршрут и состояние выборочного журнала по нему.
+type entry struct {
+	Route
+
+	headers http.Header
+
+	mu sync.Mutex
+	// logged - когда обращение последний раз записано в журнал
+	logged time.Time
+	// suppressed - сколько обращений не записано в журнал с тех пор
+	suppressed int
+}
+
+// Option - опция для настройки Registry.
+type Option func(*Registry)
+
+// WithRoutes добавляет устаревшие маршруты.
+func WithRoutes(routes ...Route) Option {
+	return func(r *Registry) {
+		r.routes = append(r.routes, routes...)
+	}
+}
+
+// WithLogInterval устанавливает, как часто пишется предупреждение об обращении к маршруту:
+// не чаще раза за интервал на маршрут, с количеством пропущенных обращений. По умолчанию DefaultLogInterval.
+func WithLogInterval(interval time.Duration) Option {
+	return func(r *Registry) {
+		r.logInterval = interval
+	}
+}
+
+// WithRegisterer устанавливает реестр метрик. По умолчанию используется prometheus.DefaultRegisterer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(r *Registry) {
+		r.registerer = registerer
+	}
+}
+
+// New создает новый Registry и регистрирует его метрики.
+func New(opts ...Option) (*Registry, error) {
+	r := &Registry{
+		entries:     map[string]*entry{},
+		logInterval: DefaultLogInterval,
+		registerer:  prometheus.DefaultRegisterer,
+		now:         time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	for _, route := range r.routes {
+		if err := r.add(route); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.logInterval <= 0 {
+		return nil, errors.New("log interval must be positive")
+	}
+
+	if r.registerer == nil {
+		return nil, errors.New("registerer is required")
+	}
+
+	r.requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_deprecated_requests_total",
+		Help: "Количество обращений к устаревшим маршрутам.",
+	}, []string{"method", "route"})
+
+	r.lastSeen = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "auth_deprecated_last_request_timestamp_seconds",
+		Help: "Время последнего обращения к устаревшему маршруту (unix).",
+	}, []string{"method", "route"})
+
+	for _, c := range []prometheus.Collector{r.requests, r.lastSeen} {
+		if err := r.registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// add проверяет и добавляет устаревший маршрут.
+func (r *Registry) add(route Route) error {
+	if route.Method == "" || route.Path == "" {
+		return errors.New("route method and path are required")
+	}
+
+	key := routeKey(route.Method, route.Path)
+
+	if route.Since.IsZero() {
+		return fmt.Errorf("route %s: deprecation date is required", key)
+	}
+
+	if !route.Sunset.IsZero() && route.Sunset.Before(route.Since) {
+		return fmt.Errorf("route %s: sunset must not be before deprecation date", key)
+	}
+
+	if route.Link != "" {
+		if u, err := url.Parse(route.Link); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("route %s: link must be an absolute url", key)
+		}
+	}
+
+	if _, ok := r.entries[key]; ok {
+		return fmt.Errorf("route %s is deprecated twice", key)
+	}
+
+	r.entries[key] = &entry{Route: route, headers: route.Headers()}
+
+	return nil
+}
+
+// Routes возвращает устаревшие маршруты.
+func (r *Registry) Routes() []Route {
+	return slices.Clone(r.routes)
+}
+
+// Observe учитывает обращение к маршруту. Если маршрут устарел, возвращает заголовки для ответа.
+// client попадает в журнал, чтобы было видно, кого предупредить о снятии.
+func (r *Registry) Observe(method, path, client string) (http.Header, bool) {
+	e, ok := r.entries[routeKey(method, path)]
+	if !ok {
+		return nil, false
+	}
+
+	now := r.now()
+
+	r.requests.WithLabelValues(method, path).Inc()
+	r.lastSeen.WithLabelValues(method, path).Set(float64(now.Unix()))
+
+	e.mu.Lock()
+
+	if now.Sub(e.logged) < r.logInterval {
+		e.suppressed++
+		e.mu.Unlock()
+
+		return e.headers, true
+	}
+
+	suppressed := e.suppressed
+	e.logged, e.suppressed = now, 0
+
+	e.mu.Unlock()
+
+	fields := logrus.Fields{
+		"method":     method,
+		"route":      path,
+		"client":     client,
+		"suppressed": suppressed,
+	}
+
+	if !e.Sunset.IsZero() {
+		fields["sunset"] = e.Sunset.UTC().Format(time.DateOnly)
+	}
+
+	if !e.Sunset.IsZero() && !now.Before(e.Sunset) {
+		logrus.WithFields(fields).Error("request to deprecated route after sunset")
+	} else {
+		logrus.WithFields(fields).Warn("request to deprecated route")
+	}
+
+	return e.headers, true
+}
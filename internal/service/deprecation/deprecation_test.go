@@ -0,0 +1,138 @@
+package deprecation
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	since  = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset = time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	route := Route{Method: http.MethodGet, Path: "/api/v0/health", Since: since}
+
+	tests := []struct {
+		name    string
+		route   Route
+		opts    []Option
+		wantErr string
+	}{
+		{name: "positive case", route: route},
+		{name: "error case: no path", route: Route{Method: http.MethodGet, Since: since}, wantErr: "route method and path are required"},
+		{
+			name:    "error case: no deprecation date",
+			route:   Route{Method: http.MethodGet, Path: "/api/v0/health"},
+			wantErr: "route GET /api/v0/health: deprecation date is required",
+		},
+		{
+			name:    "error case: sunset before deprecation",
+			route:   Route{Method: http.MethodGet, Path: "/api/v0/health", Since: sunset, Sunset: since},
+			wantErr: "route GET /api/v0/health: sunset must not be before deprecation date",
+		},
+		{
+			name:    "error case: relative link",
+			route:   Route{Method: http.MethodGet, Path: "/api/v0/health", Since: since, Link: "/docs"},
+			wantErr: "route GET /api/v0/health: link must be an absolute url",
+		},
+		{
+			name:    "error case: duplicate route",
+			route:   route,
+			opts:    []Option{WithRoutes(route)},
+			wantErr: "route GET /api/v0/health is deprecated twice",
+		},
+		{name: "error case: log interval", route: route, opts: []Option{WithLogInterval(0)}, wantErr: "log interval must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := append([]Option{WithRegisterer(prometheus.NewRegistry()), WithRoutes(tt.route)}, tt.opts...)
+
+			_, err := New(opts...)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRoute_Headers(t *testing.T) {
+	t.Parallel()
+
+	h := Route{
+		Since:     since,
+		Sunset:    sunset,
+		Link:      "https://docs.example.com/migration/v1",
+		Successor: "/api/v1/health",
+	}.Headers()
+
+	assert.Equal(t, "@1767225600", h.Get("Deprecation"))
+	assert.Equal(t, "Wed, 01 Jul 2026 00:00:00 GMT", h.Get("Sunset"))
+	assert.Equal(t, []string{
+		`<https://docs.example.com/migration/v1>; rel="deprecation"; type="text/html"`,
+		`</api/v1/health>; rel="successor-version"`,
+	}, h.Values("Link"))
+
+	h = Route{Since: since}.Headers()
+	assert.Empty(t, h.Get("Sunset"))
+	assert.Empty(t, h.Values("Link"))
+}
+
+//nolint:paralleltest // тест перехватывает глобальный журнал logrus
+func TestRegistry_Observe(t *testing.T) {
+	hook := test.NewGlobal()
+	t.Cleanup(hook.Reset)
+
+	r, err := New(
+		WithRegisterer(prometheus.NewRegistry()),
+		WithLogInterval(time.Minute),
+		WithRoutes(Route{Method: http.MethodGet, Path: "/api/v0/health", Since: since, Sunset: sunset}),
+	)
+	require.NoError(t, err)
+
+	now := sunset.Add(-time.Hour)
+	r.now = func() time.Time { return now }
+
+	_, ok := r.Observe(http.MethodPost, "/api/v0/health", "10.0.0.1")
+	assert.False(t, ok)
+
+	// предупреждение пишется не чаще раза в минуту, пропущенные обращения считаются
+	for range 3 {
+		headers, ok := r.Observe(http.MethodGet, "/api/v0/health", "10.0.0.1")
+		require.True(t, ok)
+		assert.Equal(t, "@1767225600", headers.Get("Deprecation"))
+	}
+
+	require.Len(t, hook.AllEntries(), 1)
+	assert.Equal(t, logrus.WarnLevel, hook.LastEntry().Level)
+
+	now = now.Add(time.Hour)
+
+	_, ok = r.Observe(http.MethodGet, "/api/v0/health", "10.0.0.2")
+	require.True(t, ok)
+	require.Len(t, hook.AllEntries(), 2)
+
+	// после даты удаления обращения пишутся как ошибки
+	entry := hook.LastEntry()
+	assert.Equal(t, logrus.ErrorLevel, entry.Level)
+	assert.Equal(t, 2, entry.Data["suppressed"])
+	assert.Equal(t, "10.0.0.2", entry.Data["client"])
+
+	assert.InDelta(t, 4, testutil.ToFloat64(r.requests.WithLabelValues(http.MethodGet, "/api/v0/health")), 0)
+	assert.InDelta(t, float64(now.Unix()), testutil.ToFloat64(r.lastSeen.WithLabelValues(http.MethodGet, "/api/v0/health")), 0)
+}
@@ -0,0 +1,171 @@
+// Package drain выводит экземпляр сервиса из балансировки по команде оператора. После начала
+// вывода проверка готовности отвечает отказом, и балансировщик перестает отправлять новые запросы,
+// а процесс продолжает обслуживать уже открытые соединения заданное время и затем завершается
+// так же, как по SIGTERM.
+package drain
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultDelay - время от начала вывода до завершения процесса по умолчанию.
+	DefaultDelay = 30 * time.Second
+	// DefaultMaxDelay - наибольшее время до завершения, которое можно запросить, по умолчанию.
+	DefaultMaxDelay = 10 * time.Minute
+)
+
+// ErrDelayTooLong - запрошено время до завершения больше допустимого.
+var ErrDelayTooLong = errors.New("drain delay exceeds the maximum")
+
+// State - состояние вывода из балансировки.
+type State struct {
+	Draining bool       `json:"draining"`
+	Since    *time.Time `json:"since,omitempty"`
+	ExitAt   *time.Time `json:"exit_at,omitempty"`
+}
+
+// Drainer - вывод экземпляра из балансировки.
+type Drainer struct {
+	delay    time.Duration
+	maxDelay time.Duration
+	exit     func()
+
+	mu    sync.Mutex
+	since time.Time
+	// exitAt - время завершения процесса. Нулевое, пока вывод не начат
+	exitAt time.Time
+
+	registerer prometheus.Registerer
+	draining   prometheus.Gauge
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Drainer.
+type Option func(*Drainer)
+
+// WithDelay устанавливает время до завершения, если оно не указано в запросе. По умолчанию DefaultDelay.
+func WithDelay(delay time.Duration) Option {
+	return func(d *Drainer) {
+		d.delay = delay
+	}
+}
+
+// WithMaxDelay устанавливает наибольшее время до завершения. По умолчанию DefaultMaxDelay.
+func WithMaxDelay(maxDelay time.Duration) Option {
+	return func(d *Drainer) {
+		d.maxDelay = maxDelay
+	}
+}
+
+// WithExit устанавливает функцию завершения процесса, которая вызывается по истечении времени вывода.
+func WithExit(exit func()) Option {
+	return func(d *Drainer) {
+		d.exit = exit
+	}
+}
+
+// WithRegisterer устанавливает реестр метрик. По умолчанию используется prometheus.DefaultRegisterer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(d *Drainer) {
+		d.registerer = registerer
+	}
+}
+
+// New создает новый Drainer и регистрирует его метрики.
+func New(opts ...Option) (*Drainer, error) {
+	d := &Drainer{
+		delay:      DefaultDelay,
+		maxDelay:   DefaultMaxDelay,
+		registerer: prometheus.DefaultRegisterer,
+		now:        time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	if d.exit == nil {
+		return nil, errors.New("exit function is required")
+	}
+
+	if d.maxDelay <= 0 {
+		return nil, errors.New("max delay must be positive")
+	}
+
+	if d.delay < 0 || d.delay > d.maxDelay {
+		return nil, errors.New("delay must be between 0 and max delay")
+	}
+
+	if d.registerer == nil {
+		return nil, errors.New("registerer is required")
+	}
+
+	d.draining = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "auth_draining",
+		Help: "1, если экземпляр выводится из балансировки и скоро завершится.",
+	})
+
+	if err := d.registerer.Register(d.draining); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// Drain начинает вывод из балансировки: готовность сразу становится false, а через delay
+// вызывается функция завершения. Если delay равен 0, используется время по умолчанию.
+// Повторный вызов не продлевает и не сокращает уже начатый вывод, а возвращает его состояние.
+func (d *Drainer) Drain(delay time.Duration) (State, error) {
+	if delay > d.maxDelay {
+		return State{}, ErrDelayTooLong
+	}
+
+	if delay <= 0 {
+		delay = d.delay
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.exitAt.IsZero() {
+		d.since = d.now()
+		d.exitAt = d.since.Add(delay)
+		time.AfterFunc(delay, d.exit)
+
+		d.draining.Set(1)
+	}
+
+	return d.state(), nil
+}
+
+// Ready возвращает false, если начат вывод из балансировки.
+func (d *Drainer) Ready() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.exitAt.IsZero()
+}
+
+// State возвращает состояние вывода из балансировки.
+func (d *Drainer) State() State {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.state()
+}
+
+func (d *Drainer) state() State {
+	if d.exitAt.IsZero() {
+		return State{}
+	}
+
+	since, exitAt := d.since, d.exitAt
+
+	return State{Draining: true, Since: &since, ExitAt: &exitAt}
+}
@@ -0,0 +1,118 @@
+package drain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	exit := func() {}
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr string
+	}{
+		{
+			name: "ok",
+			opts: []Option{WithExit(exit)},
+		},
+		{
+			name:    "no exit",
+			opts:    nil,
+			wantErr: "exit function is required",
+		},
+		{
+			name:    "zero max delay",
+			opts:    []Option{WithExit(exit), WithMaxDelay(0)},
+			wantErr: "max delay must be positive",
+		},
+		{
+			name:    "delay over max",
+			opts:    []Option{WithExit(exit), WithDelay(time.Hour), WithMaxDelay(time.Minute)},
+			wantErr: "delay must be between 0 and max delay",
+		},
+		{
+			name:    "nil registerer",
+			opts:    []Option{WithExit(exit), WithRegisterer(nil)},
+			wantErr: "registerer is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			d, err := New(append([]Option{WithRegisterer(prometheus.NewRegistry())}, tt.opts...)...)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, d)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, d.Ready())
+			assert.Equal(t, State{}, d.State())
+		})
+	}
+}
+
+func TestDrainer_Drain(t *testing.T) {
+	t.Parallel()
+
+	exited := make(chan struct{})
+
+	d, err := New(
+		WithExit(func() { close(exited) }),
+		WithMaxDelay(time.Minute),
+		WithRegisterer(prometheus.NewRegistry()),
+	)
+	require.NoError(t, err)
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+
+	_, err = d.Drain(time.Hour)
+	require.ErrorIs(t, err, ErrDelayTooLong)
+	assert.True(t, d.Ready(), "rejected drain must not change readiness")
+
+	state, err := d.Drain(50 * time.Millisecond)
+	require.NoError(t, err)
+
+	assert.False(t, d.Ready())
+	assert.True(t, state.Draining)
+	assert.Equal(t, now, *state.Since)
+	assert.Equal(t, now.Add(50*time.Millisecond), *state.ExitAt)
+	assert.InDelta(t, 1, testutil.ToFloat64(d.draining), 0)
+
+	// повторный вызов не меняет время завершения и не вызывает exit второй раз
+	again, err := d.Drain(time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, state, again)
+
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		t.Fatal("exit was not called")
+	}
+}
+
+func TestDrainer_Drain_DefaultDelay(t *testing.T) {
+	t.Parallel()
+
+	d, err := New(WithExit(func() {}), WithDelay(time.Minute), WithRegisterer(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	state, err := d.Drain(0)
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Minute, state.ExitAt.Sub(*state.Since))
+}
@@ -0,0 +1,193 @@
+// Package event публикует события сервиса (например, отключение пользователя) в Redis stream,
+// чтобы другие сервисы (бот, веб-интерфейс) могли сразу завершить сессии пользователя у себя.
+// Потребители читают stream своей группой и не теряют события при перезапуске.
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// DefaultStream - stream событий по умолчанию.
+	DefaultStream = "auth:events"
+	// DefaultMaxLen - примерное количество хранимых событий. Старые события вытесняются.
+	DefaultMaxLen = 10000
+)
+
+// Типы событий.
+const (
+	// TypeUserDeactivated - пользователь отключен, все его токены отозваны.
+	TypeUserDeactivated = "user.deactivated"
+	// TypeUserReactivated - пользователь снова включен. Ранее выпущенные токены остаются отозванными.
+	TypeUserReactivated = "user.reactivated"
+	// TypeSourceBanned - IP, сеть или ASN временно заблокированы из-за перебора учетных данных
+	// или обращения к ловушке. Subject - заблокированный источник, Source - причина.
+	TypeSourceBanned = "source.banned"
+	// TypeTokensRevoked - отозваны токены пользователя, выпущенные не позже At.
+	TypeTokensRevoked = "tokens.revoked"
+	// TypeTokenRevoked - отозван один токен. Subject - jti токена, ExpiresAt - срок действия токена.
+	TypeTokenRevoked = "token.revoked"
+	// TypeNotifyPrefix - префикс уведомлений пользователю для доставки в Telegram: notify.new_login,
+	// notify.password_change. Subject - пользователь, Source - подробности события.
+	TypeNotifyPrefix = "notify."
+)
+
+// Event - событие сервиса.
+type Event struct {
+	Type    string
+	Subject string
+	// Source - кто вызвал событие, например admin или scim.
+	Source string
+	At     time.Time
+	// Region - регион, в котором произошло событие. Пусто - регион публикующего экземпляра.
+	Region string
+	// ExpiresAt - срок действия отозванного токена для TypeTokenRevoked. Нулевое время не публикуется.
+	ExpiresAt time.Time
+}
+
+// Publisher - публикация событий в Redis stream.
+type Publisher struct {
+	client redis.UniversalClient
+	stream string
+	maxLen int64
+	region string
+}
+
+// Option - опция для настройки Publisher.
+type Option func(*Publisher)
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(p *Publisher) {
+		p.client = client
+	}
+}
+
+// WithStream устанавливает stream событий. По умолчанию DefaultStream.
+func WithStream(stream string) Option {
+	return func(p *Publisher) {
+		p.stream = stream
+	}
+}
+
+// WithMaxLen устанавливает примерное количество хранимых событий. По умолчанию DefaultMaxLen.
+func WithMaxLen(maxLen int64) Option {
+	return func(p *Publisher) {
+		p.maxLen = maxLen
+	}
+}
+
+// WithRegion устанавливает регион экземпляра, который записывается в события без региона.
+// По умолчанию регион не записывается.
+func WithRegion(region string) Option {
+	return func(p *Publisher) {
+		p.region = region
+	}
+}
+
+// New создает новый Publisher.
+func New(opts ...Option) (*Publisher, error) {
+	p := &Publisher{
+		stream: DefaultStream,
+		maxLen: DefaultMaxLen,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if p.client == nil {
+		return nil, errors.New("redis client is required")
+	}
+
+	if p.stream == "" {
+		return nil, errors.New("stream is required")
+	}
+
+	if p.maxLen <= 0 {
+		return nil, errors.New("max len must be positive")
+	}
+
+	return p, nil
+}
+
+// Publish публикует событие и возвращает его ID в stream.
+func (p *Publisher) Publish(ctx context.Context, e Event) (string, error) {
+	if e.Type == "" || e.Subject == "" {
+		return "", errors.New("event: type and subject are required")
+	}
+
+	values := map[string]interface{}{
+		"type":    e.Type,
+		"subject": e.Subject,
+		"source":  e.Source,
+		"at":      strconv.FormatInt(e.At.Unix(), 10),
+	}
+
+	if e.Region == "" {
+		e.Region = p.region
+	}
+
+	if e.Region != "" {
+		values["region"] = e.Region
+	}
+
+	if !e.ExpiresAt.IsZero() {
+		values["expires_at"] = strconv.FormatInt(e.ExpiresAt.Unix(), 10)
+	}
+
+	eventID, err := p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: p.stream,
+		MaxLen: p.maxLen,
+		Approx: true,
+		Values: values,
+	}).Result()
+	if err != nil {
+		return "", fmt.Errorf("event: error publish %s: %w", e.Type, err)
+	}
+
+	return eventID, nil
+}
+
+// Parse разбирает событие из сообщения stream.
+func Parse(msg redis.XMessage) (Event, error) {
+	str := func(field string) string {
+		value, _ := msg.Values[field].(string)
+
+		return value
+	}
+
+	at, err := strconv.ParseInt(str("at"), 10, 64)
+	if err != nil {
+		return Event{}, fmt.Errorf("event: invalid time of message %s: %w", msg.ID, err)
+	}
+
+	e := Event{
+		Type:    str("type"),
+		Subject: str("subject"),
+		Source:  str("source"),
+		At:      time.Unix(at, 0).UTC(),
+		Region:  str("region"),
+	}
+
+	if e.Type == "" || e.Subject == "" {
+		return Event{}, fmt.Errorf("event: message %s has no type or subject", msg.ID)
+	}
+
+	if raw := str("expires_at"); raw != "" {
+		exp, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return Event{}, fmt.Errorf("event: invalid expiry of message %s: %w", msg.ID, err)
+		}
+
+		e.ExpiresAt = time.Unix(exp, 0).UTC()
+	}
+
+	return e, nil
+}
@@ -0,0 +1,185 @@
+package event
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	t.Cleanup(func() { _ = client.Close() })
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{name: "positive case", opts: []Option{WithClient(client)}, wantErr: require.NoError},
+		{name: "positive case: custom stream", opts: []Option{WithClient(client), WithStream("events"), WithMaxLen(10)}, wantErr: require.NoError},
+		{name: "error case: client is nil", wantErr: require.Error},
+		{name: "error case: empty stream", opts: []Option{WithClient(client), WithStream("")}, wantErr: require.Error},
+		{name: "error case: zero max len", opts: []Option{WithClient(client), WithMaxLen(0)}, wantErr: require.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(tt.opts...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+func TestPublisher_Publish(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	t.Cleanup(func() { _ = client.Close() })
+
+	p, err := New(WithClient(client), WithStream("events"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	at := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	eventID, err := p.Publish(ctx, Event{Type: TypeUserDeactivated, Subject: "42", Source: "admin", At: at})
+	require.NoError(t, err)
+
+	messages, err := client.XRange(ctx, "events", "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, eventID, messages[0].ID)
+	assert.Equal(t, map[string]interface{}{
+		"type":    TypeUserDeactivated,
+		"subject": "42",
+		"source":  "admin",
+		"at":      "1735689600",
+	}, messages[0].Values)
+
+	_, err = p.Publish(ctx, Event{Type: TypeTokenRevoked, Subject: "jti-1", At: at, ExpiresAt: at.Add(time.Hour)})
+	require.NoError(t, err)
+
+	messages, err = client.XRange(ctx, "events", "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "1735693200", messages[1].Values["expires_at"])
+
+	_, err = p.Publish(ctx, Event{Type: TypeUserDeactivated})
+	require.Error(t, err)
+
+	mr.Close()
+
+	_, err = p.Publish(ctx, Event{Type: TypeUserDeactivated, Subject: "42"})
+	require.Error(t, err)
+}
+
+func TestPublisher_Region(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	t.Cleanup(func() { _ = client.Close() })
+
+	p, err := New(WithClient(client), WithStream("events"), WithRegion("eu"))
+	require.NoError(t, err)
+
+	at := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// событие без региона получает регион экземпляра, событие из другого региона сохраняет свой
+	for _, e := range []Event{
+		{Type: TypeTokensRevoked, Subject: "42", At: at},
+		{Type: TypeUserDeactivated, Subject: "42", Source: "admin", At: at, Region: "us"},
+	} {
+		_, err := p.Publish(t.Context(), e)
+		require.NoError(t, err)
+	}
+
+	messages, err := client.XRange(t.Context(), "events", "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+
+	var got []Event
+
+	for _, msg := range messages {
+		e, err := Parse(msg)
+		require.NoError(t, err)
+
+		got = append(got, e)
+	}
+
+	assert.Equal(t, []Event{
+		{Type: TypeTokensRevoked, Subject: "42", At: at, Region: "eu"},
+		{Type: TypeUserDeactivated, Subject: "42", Source: "admin", At: at, Region: "us"},
+	}, got)
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		values map[string]interface{}
+		want   Event
+		wantOk bool
+	}{
+		{
+			name:   "positive case",
+			values: map[string]interface{}{"type": TypeTokensRevoked, "subject": "42", "at": "1735689600"},
+			want:   Event{Type: TypeTokensRevoked, Subject: "42", At: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			wantOk: true,
+		},
+		{
+			name: "positive case: token revocation",
+			values: map[string]interface{}{
+				"type": TypeTokenRevoked, "subject": "jti-1", "at": "1735689600", "expires_at": "1735693200", "region": "eu",
+			},
+			want: Event{
+				Type:      TypeTokenRevoked,
+				Subject:   "jti-1",
+				At:        time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				Region:    "eu",
+				ExpiresAt: time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC),
+			},
+			wantOk: true,
+		},
+		{
+			name:   "error case: invalid expiry",
+			values: map[string]interface{}{"type": TypeTokenRevoked, "subject": "jti-1", "at": "1735689600", "expires_at": "soon"},
+		},
+		{
+			name:   "error case: invalid time",
+			values: map[string]interface{}{"type": TypeTokensRevoked, "subject": "42", "at": "yesterday"},
+		},
+		{
+			name:   "error case: no subject",
+			values: map[string]interface{}{"type": TypeTokensRevoked, "at": "1735689600"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Parse(redis.XMessage{ID: "1-0", Values: tt.values})
+			if !tt.wantOk {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
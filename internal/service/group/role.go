@@ -0,0 +1,46 @@
+package group
+
+import "slices"
+
+// Role - роль участника в группе.
+type Role string
+
+const (
+	// RoleViewer - только чтение данных группы.
+	RoleViewer Role = "viewer"
+	// RoleEditor - чтение и изменение данных группы.
+	RoleEditor Role = "editor"
+	// RoleOwner - полный доступ, включая управление участниками.
+	RoleOwner Role = "owner"
+)
+
+// Action - действие над данными группы.
+type Action string
+
+const (
+	// ActionRead - чтение.
+	ActionRead Action = "read"
+	// ActionWrite - изменение.
+	ActionWrite Action = "write"
+	// ActionManage - управление группой и ее участниками.
+	ActionManage Action = "manage"
+)
+
+// permissions - действия, разрешенные ролям.
+var permissions = map[Role][]Action{
+	RoleViewer: {ActionRead},
+	RoleEditor: {ActionRead, ActionWrite},
+	RoleOwner:  {ActionRead, ActionWrite, ActionManage},
+}
+
+// Valid возвращает true, если роль известна.
+func (r Role) Valid() bool {
+	_, ok := permissions[r]
+
+	return ok
+}
+
+// Allows возвращает true, если роль разрешает действие.
+func (r Role) Allows(action Action) bool {
+	return slices.Contains(permissions[r], action)
+}
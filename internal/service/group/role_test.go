@@ -0,0 +1,35 @@
+package group
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRole_Allows(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		role   Role
+		action Action
+		want   bool
+	}{
+		{role: RoleViewer, action: ActionRead, want: true},
+		{role: RoleViewer, action: ActionWrite, want: false},
+		{role: RoleEditor, action: ActionWrite, want: true},
+		{role: RoleEditor, action: ActionManage, want: false},
+		{role: RoleOwner, action: ActionManage, want: true},
+		{role: Role("guest"), action: ActionRead, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role)+"/"+string(tt.action), func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.role.Allows(tt.action))
+		})
+	}
+
+	assert.True(t, RoleOwner.Valid())
+	assert.False(t, Role("guest").Valid())
+}
@@ -0,0 +1,272 @@
+// Package group хранит группы (общие пространства заметок) и членство пользователей в них.
+// Роли участников попадают в claim groups выпускаемых токенов и используются для проверки доступа.
+package group
+
+import (
+	"auth-service/internal/service/id"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	idLength = 12
+
+	keyPrefix = "auth:"
+)
+
+var (
+	// ErrNotFound - группа не найдена.
+	ErrNotFound = errors.New("group not found")
+	// ErrNotMember - пользователь не состоит в группе.
+	ErrNotMember = errors.New("user is not a member of the group")
+	// ErrInvalidRole - неизвестная роль.
+	ErrInvalidRole = errors.New("invalid role")
+	// ErrInvalidArgument - не заполнены обязательные параметры.
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+// Group - группа пользователей.
+type Group struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	CreatedAt time.Time       `json:"created_at"`
+	Members   map[string]Role `json:"members"`
+}
+
+// Service - хранилище групп в Redis.
+//
+// Ключи:
+//   - auth:group:<id> - hash с полями name, created_at;
+//   - auth:group:<id>:members - hash пользователь -> роль;
+//   - auth:user:<id>:groups - hash группа -> роль, для быстрого заполнения claim groups.
+//
+// Ключи группы и пользователя лежат в разных слотах кластера, поэтому изменения выполняются
+// пайплайном без транзакции. Источником истины считается auth:group:<id>:members.
+type Service struct {
+	client redis.UniversalClient
+	now    func() time.Time
+}
+
+// Option - опция для настройки Service.
+type Option func(*Service)
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(s *Service) {
+		s.client = client
+	}
+}
+
+// New создает новый Service.
+func New(opts ...Option) (*Service, error) {
+	s := &Service{now: time.Now}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.client == nil {
+		return nil, errors.New("redis client is required")
+	}
+
+	return s, nil
+}
+
+func groupKey(id string) string {
+	return keyPrefix + "group:" + id
+}
+
+func membersKey(id string) string {
+	return keyPrefix + "group:" + id + ":members"
+}
+
+func userGroupsKey(userID string) string {
+	return keyPrefix + "user:" + userID + ":groups"
+}
+
+// Create создает группу, owner становится ее владельцем.
+func (s *Service) Create(ctx context.Context, name, owner string) (*Group, error) {
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidArgument)
+	}
+
+	if owner == "" {
+		return nil, fmt.Errorf("%w: owner is required", ErrInvalidArgument)
+	}
+
+	groupID, err := id.Generate(idLength)
+	if err != nil {
+		return nil, fmt.Errorf("group: error generate id: %w", err)
+	}
+
+	g := &Group{
+		ID:        groupID,
+		Name:      name,
+		CreatedAt: s.now().UTC().Truncate(time.Second),
+		Members:   map[string]Role{owner: RoleOwner},
+	}
+
+	_, err = s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, groupKey(g.ID), "name", g.Name, "created_at", g.CreatedAt.Unix())
+		p.HSet(ctx, membersKey(g.ID), owner, string(RoleOwner))
+		p.HSet(ctx, userGroupsKey(owner), g.ID, string(RoleOwner))
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("group: error create group: %w", err)
+	}
+
+	return g, nil
+}
+
+// Get возвращает группу вместе с участниками.
+func (s *Service) Get(ctx context.Context, groupID string) (*Group, error) {
+	fields, err := s.client.HGetAll(ctx, groupKey(groupID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("group: error get group: %w", err)
+	}
+
+	if len(fields) == 0 {
+		return nil, ErrNotFound
+	}
+
+	members, err := s.client.HGetAll(ctx, membersKey(groupID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("group: error get members: %w", err)
+	}
+
+	createdAt, _ := strconv.ParseInt(fields["created_at"], 10, 64)
+
+	g := &Group{
+		ID:        groupID,
+		Name:      fields["name"],
+		CreatedAt: time.Unix(createdAt, 0).UTC(),
+		Members:   make(map[string]Role, len(members)),
+	}
+
+	for user, role := range members {
+		g.Members[user] = Role(role)
+	}
+
+	return g, nil
+}
+
+// Delete удаляет группу и членство всех ее участников.
+func (s *Service) Delete(ctx context.Context, groupID string) error {
+	g, err := s.Get(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for user := range g.Members {
+			p.HDel(ctx, userGroupsKey(user), groupID)
+		}
+
+		p.Del(ctx, membersKey(groupID))
+		p.Del(ctx, groupKey(groupID))
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("group: error delete group: %w", err)
+	}
+
+	return nil
+}
+
+// SetMember добавляет пользователя в группу или меняет его роль.
+func (s *Service) SetMember(ctx context.Context, groupID, userID string, role Role) error {
+	if userID == "" {
+		return fmt.Errorf("%w: user id is required", ErrInvalidArgument)
+	}
+
+	if !role.Valid() {
+		return fmt.Errorf("%w: %s", ErrInvalidRole, role)
+	}
+
+	if err := s.exists(ctx, groupID); err != nil {
+		return err
+	}
+
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, membersKey(groupID), userID, string(role))
+		p.HSet(ctx, userGroupsKey(userID), groupID, string(role))
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("group: error set member: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveMember исключает пользователя из группы.
+func (s *Service) RemoveMember(ctx context.Context, groupID, userID string) error {
+	if err := s.exists(ctx, groupID); err != nil {
+		return err
+	}
+
+	var removed *redis.IntCmd
+
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		removed = p.HDel(ctx, membersKey(groupID), userID)
+		p.HDel(ctx, userGroupsKey(userID), groupID)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("group: error remove member: %w", err)
+	}
+
+	if removed.Val() == 0 {
+		return ErrNotMember
+	}
+
+	return nil
+}
+
+// Memberships возвращает группы пользователя и его роли в них.
+func (s *Service) Memberships(ctx context.Context, userID string) (map[string]string, error) {
+	res, err := s.client.HGetAll(ctx, userGroupsKey(userID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("group: error get memberships: %w", err)
+	}
+
+	return res, nil
+}
+
+// Check проверяет, разрешено ли пользователю действие над данными группы.
+func (s *Service) Check(ctx context.Context, groupID, userID string, action Action) (bool, error) {
+	role, err := s.client.HGet(ctx, membersKey(groupID), userID).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("group: error get member role: %w", err)
+	}
+
+	return Role(role).Allows(action), nil
+}
+
+// exists возвращает ErrNotFound, если группы нет.
+func (s *Service) exists(ctx context.Context, groupID string) error {
+	n, err := s.client.Exists(ctx, groupKey(groupID)).Result()
+	if err != nil {
+		return fmt.Errorf("group: error check group: %w", err)
+	}
+
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
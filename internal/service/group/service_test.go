@@ -0,0 +1,119 @@
+package group
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newService(t *testing.T) (*Service, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	s, err := New(WithClient(client))
+	require.NoError(t, err)
+
+	return s, mr
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	_, err := New()
+	require.Error(t, err)
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService(t *testing.T) {
+	t.Parallel()
+
+	s, mr := newService(t)
+	ctx := t.Context()
+
+	g, err := s.Create(ctx, "family notes", "user-1")
+	require.NoError(t, err)
+	require.Len(t, g.ID, idLength)
+
+	// участники и роли
+	require.NoError(t, s.SetMember(ctx, g.ID, "user-2", RoleEditor))
+	require.NoError(t, s.SetMember(ctx, g.ID, "user-3", RoleViewer))
+	require.ErrorIs(t, s.SetMember(ctx, g.ID, "user-4", Role("guest")), ErrInvalidRole)
+	require.ErrorIs(t, s.SetMember(ctx, "unknown", "user-4", RoleViewer), ErrNotFound)
+
+	got, err := s.Get(ctx, g.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "family notes", got.Name)
+	assert.Equal(t, g.CreatedAt, got.CreatedAt)
+	assert.Equal(t, map[string]Role{"user-1": RoleOwner, "user-2": RoleEditor, "user-3": RoleViewer}, got.Members)
+
+	memberships, err := s.Memberships(ctx, "user-2")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{g.ID: "editor"}, memberships)
+
+	// проверка доступа
+	for _, tc := range []struct {
+		user   string
+		action Action
+		want   bool
+	}{
+		{user: "user-1", action: ActionManage, want: true},
+		{user: "user-2", action: ActionWrite, want: true},
+		{user: "user-3", action: ActionWrite, want: false},
+		{user: "user-4", action: ActionRead, want: false},
+	} {
+		allowed, err := s.Check(ctx, g.ID, tc.user, tc.action)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, allowed, "%s %s", tc.user, tc.action)
+	}
+
+	// исключение участника
+	require.NoError(t, s.RemoveMember(ctx, g.ID, "user-3"))
+	require.ErrorIs(t, s.RemoveMember(ctx, g.ID, "user-3"), ErrNotMember)
+
+	memberships, err = s.Memberships(ctx, "user-3")
+	require.NoError(t, err)
+	assert.Empty(t, memberships)
+
+	// удаление группы
+	require.NoError(t, s.Delete(ctx, g.ID))
+	require.ErrorIs(t, s.Delete(ctx, g.ID), ErrNotFound)
+
+	_, err = s.Get(ctx, g.ID)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	memberships, err = s.Memberships(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Empty(t, memberships)
+	assert.Empty(t, mr.Keys())
+}
+
+func TestService_Errors(t *testing.T) {
+	t.Parallel()
+
+	s, mr := newService(t)
+	ctx := t.Context()
+
+	_, err := s.Create(ctx, "", "user-1")
+	require.ErrorIs(t, err, ErrInvalidArgument)
+
+	_, err = s.Create(ctx, "notes", "")
+	require.ErrorIs(t, err, ErrInvalidArgument)
+
+	mr.Close()
+
+	_, err = s.Create(ctx, "notes", "user-1")
+	require.Error(t, err)
+
+	_, err = s.Memberships(ctx, "user-1")
+	require.Error(t, err)
+
+	_, err = s.Check(ctx, "group", "user-1", ActionRead)
+	require.Error(t, err)
+}
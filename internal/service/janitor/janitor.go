@@ -0,0 +1,290 @@
+// Package janitor ищет ключи Redis, которые должны истекать, но остались без TTL: из-за ошибок
+// или после старых версий сервиса. Такие ключи никогда не удаляются и со временем занимают всю
+// память Redis. Ключи ищутся обходом SCAN по шаблонам временных данных и учитываются в метриках.
+// Если включено удаление, ключ удаляется, только если он был без TTL и в предыдущем обходе:
+// так не удаляются ключи, TTL которых еще не успели выставить после записи.
+package janitor
+
+import (
+	"auth-service/internal/service/backup"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultInterval - периодичность обхода по умолчанию.
+const DefaultInterval = time.Hour
+
+// noExpire - значение TTL ключа без срока жизни.
+const noExpire = time.Duration(-1)
+
+// DefaultPatterns возвращает шаблоны ключей, которые сервис всегда записывает с TTL.
+// Постоянные данные (API ключи, группы, пользователи SCIM, passkeys, настройки уведомлений) сюда не входят.
+func DefaultPatterns() []string {
+	return []string{
+		"auth:revocation:subject:*",
+		"auth:refresh:*",
+		"auth:qrlogin:*",
+		"auth:webauthn:challenge:*",
+		"auth:oauth:state:*",
+		"auth:oauth:email-change:*",
+		"auth:oauth:email-change-code:*",
+		"auth:jobs:job:*",
+		"auth:abuse:failures:*",
+		"auth:apikey:*:usage:*",
+	}
+}
+
+// Report - результат обхода.
+type Report struct {
+	// Scanned - сколько ключей проверено.
+	Scanned int `json:"scanned"`
+	// Orphaned - ключи без TTL по шаблонам.
+	Orphaned map[string]int `json:"orphaned"`
+	// Deleted - сколько ключей удалено.
+	Deleted int `json:"deleted"`
+}
+
+// Janitor - поиск и удаление ключей без TTL.
+type Janitor struct {
+	client   redis.UniversalClient
+	patterns []string
+	interval time.Duration
+	delete   bool
+
+	mu sync.Mutex
+	// suspects - ключи без TTL, найденные в предыдущем обходе.
+	suspects map[string]struct{}
+
+	registerer prometheus.Registerer
+	orphaned   *prometheus.GaugeVec
+	deleted    *prometheus.CounterVec
+	runs       *prometheus.CounterVec
+}
+
+// Option - опция для настройки Janitor.
+type Option func(*Janitor)
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(j *Janitor) {
+		j.client = client
+	}
+}
+
+// WithPatterns устанавливает шаблоны ключей, которые должны истекать. По умолчанию DefaultPatterns.
+func WithPatterns(patterns []string) Option {
+	return func(j *Janitor) {
+		j.patterns = patterns
+	}
+}
+
+// WithInterval устанавливает периодичность обхода. По умолчанию DefaultInterval.
+func WithInterval(interval time.Duration) Option {
+	return func(j *Janitor) {
+		j.interval = interval
+	}
+}
+
+// WithDelete включает удаление ключей без TTL. По умолчанию ключи только учитываются в метриках.
+func WithDelete(enabled bool) Option {
+	return func(j *Janitor) {
+		j.delete = enabled
+	}
+}
+
+// WithRegisterer устанавливает реестр метрик. По умолчанию используется prometheus.DefaultRegisterer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(j *Janitor) {
+		j.registerer = registerer
+	}
+}
+
+// New создает новый Janitor и регистрирует его метрики.
+func New(opts ...Option) (*Janitor, error) {
+	j := &Janitor{
+		patterns:   DefaultPatterns(),
+		interval:   DefaultInterval,
+		suspects:   make(map[string]struct{}),
+		registerer: prometheus.DefaultRegisterer,
+	}
+
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	if j.client == nil {
+		return nil, errors.New("redis client is required")
+	}
+
+	if len(j.patterns) == 0 {
+		return nil, errors.New("patterns are required")
+	}
+
+	if j.interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+
+	if j.registerer == nil {
+		return nil, errors.New("registerer is required")
+	}
+
+	j.orphaned = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "auth_redis_orphaned_keys",
+		Help: "Количество ключей без TTL по шаблону временных данных в последнем обходе.",
+	}, []string{"pattern"})
+
+	j.deleted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_redis_orphaned_keys_deleted_total",
+		Help: "Количество удаленных ключей без TTL по шаблону.",
+	}, []string{"pattern"})
+
+	j.runs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_redis_janitor_runs_total",
+		Help: "Количество обходов ключей без TTL по результату.",
+	}, []string{"result"})
+
+	for _, c := range []prometheus.Collector{j.orphaned, j.deleted, j.runs} {
+		if err := j.registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return j, nil
+}
+
+// Start периодически обходит ключи до отмены контекста.
+func (j *Janitor) Start(ctx context.Context) error {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := j.Run(ctx); err != nil && ctx.Err() == nil {
+				logrus.WithError(err).Warn("error collect orphaned redis keys")
+			}
+		}
+	}
+}
+
+// Run обходит ключи по всем шаблонам, обновляет метрики и, если включено удаление, удаляет ключи,
+// которые были без TTL и в предыдущем обходе.
+func (j *Janitor) Run(ctx context.Context) (*Report, error) {
+	report, err := j.run(ctx)
+	if err != nil {
+		j.runs.WithLabelValues("error").Inc()
+
+		return nil, err
+	}
+
+	j.runs.WithLabelValues("ok").Inc()
+
+	log := logrus.WithFields(logrus.Fields{
+		"scanned":  report.Scanned,
+		"orphaned": report.Orphaned,
+		"deleted":  report.Deleted,
+	})
+
+	if orphans(report) > 0 {
+		log.Warn("orphaned redis keys found")
+	} else {
+		log.Debug("no orphaned redis keys")
+	}
+
+	return report, nil
+}
+
+func (j *Janitor) run(ctx context.Context) (*Report, error) {
+	report := &Report{Orphaned: make(map[string]int, len(j.patterns))}
+	found := make(map[string]struct{})
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, pattern := range j.patterns {
+		var mu sync.Mutex
+
+		// в кластере узлы обходятся параллельно
+		err := backup.Scan(ctx, j.client, pattern, func(ctx context.Context, client redis.UniversalClient, key string) error {
+			ttl, err := client.TTL(ctx, key).Result()
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Scanned++
+
+			if ttl != noExpire {
+				return nil
+			}
+
+			report.Orphaned[pattern]++
+			found[key] = struct{}{}
+
+			if _, ok := j.suspects[key]; !ok || !j.delete {
+				return nil
+			}
+
+			// ключ мог получить TTL после проверки: удаляется, только если TTL так и нет
+			deleted, err := deleteIfPersistent(ctx, client, key)
+			if err != nil {
+				return err
+			}
+
+			if deleted {
+				report.Deleted++
+				j.deleted.WithLabelValues(pattern).Inc()
+				delete(found, key)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("janitor: error scan %s: %w", pattern, err)
+		}
+
+		j.orphaned.WithLabelValues(pattern).Set(float64(report.Orphaned[pattern]))
+	}
+
+	j.suspects = found
+
+	return report, nil
+}
+
+// deleteScript удаляет ключ, только если у него нет TTL.
+var deleteScript = redis.NewScript(`
+if redis.call("TTL", KEYS[1]) == -1 then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+func deleteIfPersistent(ctx context.Context, client redis.UniversalClient, key string) (bool, error) {
+	n, err := deleteScript.Run(ctx, client, []string{key}).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+func orphans(report *Report) int {
+	total := 0
+
+	for _, n := range report.Orphaned {
+		total += n
+	}
+
+	return total
+}
@@ -0,0 +1,124 @@
+package janitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newJanitor(t *testing.T, opts ...Option) (*Janitor, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	j, err := New(append([]Option{WithClient(client), WithRegisterer(prometheus.NewRegistry())}, opts...)...)
+	require.NoError(t, err)
+
+	return j, mr
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	t.Cleanup(func() { _ = client.Close() })
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			opts:    []Option{WithClient(client)},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: no client",
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: no patterns",
+			opts:    []Option{WithClient(client), WithPatterns(nil)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: negative interval",
+			opts:    []Option{WithClient(client), WithInterval(-time.Second)},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(append(tt.opts, WithRegisterer(prometheus.NewRegistry()))...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+func TestJanitor_Run(t *testing.T) {
+	t.Parallel()
+
+	j, mr := newJanitor(t)
+
+	mr.Set("auth:qrlogin:orphan", "1")
+	mr.Set("auth:qrlogin:ok", "1")
+	mr.SetTTL("auth:qrlogin:ok", time.Minute)
+	// постоянные данные не проверяются
+	mr.HSet("auth:apikey:bot", "secret_hash", "abc")
+
+	report, err := j.Run(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Scanned)
+	assert.Equal(t, 1, report.Orphaned["auth:qrlogin:*"])
+	assert.Zero(t, report.Deleted)
+	assert.InDelta(t, 1, testutil.ToFloat64(j.orphaned.WithLabelValues("auth:qrlogin:*")), 0)
+
+	// без удаления ключ остается
+	_, err = j.Run(t.Context())
+	require.NoError(t, err)
+	assert.True(t, mr.Exists("auth:qrlogin:orphan"))
+}
+
+func TestJanitor_Run_Delete(t *testing.T) {
+	t.Parallel()
+
+	j, mr := newJanitor(t, WithDelete(true))
+
+	mr.Set("auth:qrlogin:orphan", "1")
+	mr.Set("auth:qrlogin:late", "1")
+
+	// первый обход только запоминает ключи без TTL
+	report, err := j.Run(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Orphaned["auth:qrlogin:*"])
+	assert.Zero(t, report.Deleted)
+
+	// TTL выставлен между обходами - ключ не удаляется
+	mr.SetTTL("auth:qrlogin:late", time.Minute)
+
+	report, err = j.Run(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Deleted)
+	assert.False(t, mr.Exists("auth:qrlogin:orphan"))
+	assert.True(t, mr.Exists("auth:qrlogin:late"))
+	assert.InDelta(t, 1, testutil.ToFloat64(j.deleted.WithLabelValues("auth:qrlogin:*")), 0)
+
+	mr.Close()
+
+	_, err = j.Run(t.Context())
+	require.Error(t, err)
+	assert.InDelta(t, 1, testutil.ToFloat64(j.runs.WithLabelValues("error")), 0)
+}
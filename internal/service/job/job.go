@@ -0,0 +1,390 @@
+// Package job выполняет долгие административные операции асинхронно. Задания ставятся
+// в Redis stream и обрабатываются одной из реплик, статус, прогресс и результат задания
+// хранятся в Redis до истечения TTL и доступны через GET /api/v0/admin/jobs/{id}.
+package job
+
+import (
+	"auth-service/internal/service/id"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	keyPrefix = "auth:jobs:"
+
+	// streamKey - очередь заданий.
+	streamKey = keyPrefix + "queue"
+	// consumerGroup - группа обработчиков очереди: каждое задание обрабатывает одна реплика.
+	consumerGroup = "auth-service"
+
+	jobIDLength = 16
+
+	// DefaultTTL - сколько хранится задание после последнего обновления.
+	DefaultTTL = 24 * time.Hour
+
+	// readBlock - сколько обработчик ждет новые задания в одном чтении.
+	readBlock = time.Second
+	// retryInterval - пауза перед повторным чтением после ошибки Redis.
+	retryInterval = time.Second
+)
+
+// Status - состояние задания.
+type Status string
+
+const (
+	StatusQueued  Status = "queued"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+var (
+	// ErrNotFound - задание не найдено или уже удалено по TTL.
+	ErrNotFound = errors.New("job not found")
+	// ErrUnknownType - для типа задания не зарегистрирован обработчик.
+	ErrUnknownType = errors.New("unknown job type")
+)
+
+// Job - асинхронное задание.
+type Job struct {
+	ID     string            `json:"id"`
+	Type   string            `json:"type"`
+	Status Status            `json:"status"`
+	Params map[string]string `json:"params,omitempty"`
+	// Progress - выполненная часть задания в процентах.
+	Progress int `json:"progress"`
+	// Result - результат обработчика в JSON, заполняется после успешного выполнения.
+	Result    json.RawMessage `json:"result,omitempty" swaggertype:"object"`
+	Error     string          `json:"error,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// Progress сообщает выполненную часть задания в процентах.
+type Progress func(percent int)
+
+// Handler выполняет задание одного типа. Возвращенный результат сохраняется в задании в JSON.
+type Handler func(ctx context.Context, params map[string]string, progress Progress) (any, error)
+
+// Service - очередь заданий.
+//
+// Ключи:
+//   - auth:jobs:job:<id> - hash с заданием;
+//   - auth:jobs:queue - stream заданий, читается группой auth-service.
+type Service struct {
+	client redis.UniversalClient
+
+	ttl      time.Duration
+	consumer string
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Service.
+type Option func(*Service)
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(s *Service) {
+		s.client = client
+	}
+}
+
+// WithTTL устанавливает, сколько хранится задание после последнего обновления. По умолчанию DefaultTTL.
+func WithTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		s.ttl = ttl
+	}
+}
+
+// WithConsumer устанавливает имя обработчика в группе. По умолчанию имя хоста.
+func WithConsumer(name string) Option {
+	return func(s *Service) {
+		s.consumer = name
+	}
+}
+
+// New создает новый Service.
+func New(opts ...Option) (*Service, error) {
+	s := &Service{
+		ttl:      DefaultTTL,
+		handlers: make(map[string]Handler),
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.client == nil {
+		return nil, errors.New("redis client is required")
+	}
+
+	if s.ttl <= 0 {
+		return nil, errors.New("ttl must be positive")
+	}
+
+	if s.consumer == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("job: error get hostname: %w", err)
+		}
+
+		s.consumer = hostname
+	}
+
+	return s, nil
+}
+
+// Register регистрирует обработчик заданий типа jobType. Все реплики должны регистрировать
+// одинаковые типы: задание обрабатывает реплика, которая первой прочитала его из очереди.
+func (s *Service) Register(jobType string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers[jobType] = handler
+}
+
+func (s *Service) handler(jobType string) (Handler, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	h, ok := s.handlers[jobType]
+
+	return h, ok
+}
+
+func jobKey(id string) string {
+	return keyPrefix + "job:" + id
+}
+
+// Enqueue ставит в очередь задание типа jobType.
+func (s *Service) Enqueue(ctx context.Context, jobType string, params map[string]string) (*Job, error) {
+	if _, ok := s.handler(jobType); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, jobType)
+	}
+
+	jobID, err := id.Generate(jobIDLength)
+	if err != nil {
+		return nil, fmt.Errorf("job: error generate id: %w", err)
+	}
+
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("job: error marshal params: %w", err)
+	}
+
+	now := s.now().UTC().Truncate(time.Second)
+
+	job := &Job{
+		ID:        jobID,
+		Type:      jobType,
+		Status:    StatusQueued,
+		Params:    params,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	// задание и очередь лежат в разных слотах кластера, поэтому пайплайн без транзакции:
+	// задание пишется первым, чтобы обработчик всегда его находил
+	_, err = s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, jobKey(job.ID),
+			"type", job.Type,
+			"status", string(job.Status),
+			"params", string(rawParams),
+			"progress", 0,
+			"created_at", job.CreatedAt.Unix(),
+			"updated_at", job.UpdatedAt.Unix(),
+		)
+		p.Expire(ctx, jobKey(job.ID), s.ttl)
+		p.XAdd(ctx, &redis.XAddArgs{
+			Stream: streamKey,
+			Values: map[string]interface{}{"job": job.ID},
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("job: error enqueue: %w", err)
+	}
+
+	return job, nil
+}
+
+// Get возвращает задание.
+func (s *Service) Get(ctx context.Context, jobID string) (*Job, error) {
+	data, err := s.client.HGetAll(ctx, jobKey(jobID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("job: error get job: %w", err)
+	}
+
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, jobID)
+	}
+
+	job := &Job{
+		ID:        jobID,
+		Type:      data["type"],
+		Status:    Status(data["status"]),
+		Error:     data["error"],
+		CreatedAt: unixField(data, "created_at"),
+		UpdatedAt: unixField(data, "updated_at"),
+	}
+
+	job.Progress, _ = strconv.Atoi(data["progress"])
+
+	if raw := data["params"]; raw != "" {
+		if err := json.Unmarshal([]byte(raw), &job.Params); err != nil {
+			return nil, fmt.Errorf("job: error unmarshal params: %w", err)
+		}
+	}
+
+	if raw := data["result"]; raw != "" {
+		job.Result = json.RawMessage(raw)
+	}
+
+	return job, nil
+}
+
+// Start обрабатывает очередь заданий. Блокирует до отмены контекста.
+func (s *Service) Start(ctx context.Context) error {
+	err := s.client.XGroupCreateMkStream(ctx, streamKey, consumerGroup, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("job: error create consumer group: %w", err)
+	}
+
+	logrus.WithField("consumer", s.consumer).Info("starting job worker")
+
+	for ctx.Err() == nil {
+		if err := s.process(ctx); err != nil && ctx.Err() == nil {
+			logrus.WithError(err).Error("error process jobs")
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(retryInterval):
+			}
+		}
+	}
+
+	return nil
+}
+
+// process читает и обрабатывает одну пачку заданий.
+func (s *Service) process(ctx context.Context) error {
+	streams, err := s.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    consumerGroup,
+		Consumer: s.consumer,
+		Streams:  []string{streamKey, ">"},
+		Count:    10,
+		Block:    readBlock,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("error read jobs: %w", err)
+	}
+
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			jobID, _ := msg.Values["job"].(string)
+			s.handle(ctx, jobID)
+
+			if err := s.client.XAck(ctx, streamKey, consumerGroup, msg.ID).Err(); err != nil {
+				return fmt.Errorf("error ack job: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// handle выполняет задание и записывает его статус и результат.
+func (s *Service) handle(ctx context.Context, jobID string) {
+	log := logrus.WithField("job", jobID)
+
+	job, err := s.Get(ctx, jobID)
+	if err != nil {
+		// задание удалено по TTL до обработки - обновлять нечего
+		log.WithError(err).Error("error load job")
+
+		return
+	}
+
+	log = log.WithField("type", job.Type)
+
+	handler, ok := s.handler(job.Type)
+	if !ok {
+		log.Error("no handler for job type")
+		s.update(ctx, jobID, "status", string(StatusFailed), "error", ErrUnknownType.Error())
+
+		return
+	}
+
+	s.update(ctx, jobID, "status", string(StatusRunning))
+
+	result, err := handler(ctx, job.Params, func(percent int) {
+		s.update(ctx, jobID, "progress", min(max(percent, 0), 100))
+	})
+	if err != nil {
+		log.WithError(err).Error("job failed")
+		s.update(ctx, jobID, "status", string(StatusFailed), "error", err.Error())
+
+		return
+	}
+
+	values := []interface{}{"status", string(StatusDone), "progress", 100}
+
+	if result != nil {
+		raw, err := json.Marshal(result)
+		if err != nil {
+			log.WithError(err).Error("error marshal job result")
+			s.update(ctx, jobID, "status", string(StatusFailed), "error", "error marshal result")
+
+			return
+		}
+
+		values = append(values, "result", string(raw))
+	}
+
+	log.Info("job done")
+	s.update(ctx, jobID, values...)
+}
+
+// update обновляет поля задания и продлевает его TTL.
+func (s *Service) update(ctx context.Context, jobID string, values ...interface{}) {
+	values = append(values, "updated_at", s.now().UTC().Unix())
+
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, jobKey(jobID), values...)
+		p.Expire(ctx, jobKey(jobID), s.ttl)
+
+		return nil
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("job", jobID).Error("error update job")
+	}
+}
+
+func unixField(data map[string]string, field string) time.Time {
+	value, err := strconv.ParseInt(data[field], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(value, 0).UTC()
+}
@@ -0,0 +1,229 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newService(t *testing.T) (*Service, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	s, err := New(WithClient(client), WithConsumer("test"))
+	require.NoError(t, err)
+
+	return s, mr
+}
+
+// run запускает обработчик очереди до завершения теста.
+func run(t *testing.T, s *Service) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+
+	go func() { done <- s.Start(ctx) }()
+
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+}
+
+func waitStatus(t *testing.T, s *Service, jobID string, status Status) *Job {
+	t.Helper()
+
+	var got *Job
+
+	require.Eventually(t, func() bool {
+		j, err := s.Get(t.Context(), jobID)
+		if err != nil || j.Status != status {
+			return false
+		}
+
+		got = j
+
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	return got
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	t.Cleanup(func() { _ = client.Close() })
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			opts:    []Option{WithClient(client)},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: custom ttl",
+			opts:    []Option{WithClient(client), WithTTL(time.Hour), WithConsumer("replica-1")},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: client is nil",
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: negative ttl",
+			opts:    []Option{WithClient(client), WithTTL(-time.Hour)},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(tt.opts...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService(t *testing.T) {
+	t.Parallel()
+
+	s, mr := newService(t)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	release := make(chan struct{})
+
+	s.Register("export", func(ctx context.Context, params map[string]string, progress Progress) (any, error) {
+		progress(50)
+
+		select {
+		case <-release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		return map[string]string{"file": params["name"] + ".json"}, nil
+	})
+
+	_, err := s.Enqueue(t.Context(), "unknown", nil)
+	require.ErrorIs(t, err, ErrUnknownType)
+
+	job, err := s.Enqueue(t.Context(), "export", map[string]string{"name": "users"})
+	require.NoError(t, err)
+	assert.Equal(t, StatusQueued, job.Status)
+	assert.Equal(t, DefaultTTL, mr.TTL(jobKey(job.ID)))
+
+	got, err := s.Get(t.Context(), job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, job, got)
+
+	_, err = s.Get(t.Context(), "unknown")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	run(t, s)
+
+	// прогресс виден, пока задание выполняется
+	waitStatus(t, s, job.ID, StatusRunning)
+	require.Eventually(t, func() bool {
+		got, err = s.Get(t.Context(), job.ID)
+		return err == nil && got.Progress == 50
+	}, 5*time.Second, 10*time.Millisecond)
+
+	close(release)
+
+	got = waitStatus(t, s, job.ID, StatusDone)
+	assert.Equal(t, 100, got.Progress)
+	assert.JSONEq(t, `{"file":"users.json"}`, string(got.Result))
+	assert.Empty(t, got.Error)
+
+	// задание обработано и подтверждено
+	pending, err := s.client.XPending(t.Context(), streamKey, consumerGroup).Result()
+	require.NoError(t, err)
+	assert.Zero(t, pending.Count)
+}
+
+func TestService_Failed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		handler Handler
+		wantErr string
+	}{
+		{
+			name: "handler error",
+			handler: func(context.Context, map[string]string, Progress) (any, error) {
+				return nil, errors.New("purge failed")
+			},
+			wantErr: "purge failed",
+		},
+		{
+			name: "result is not json",
+			handler: func(context.Context, map[string]string, Progress) (any, error) {
+				return func() {}, nil
+			},
+			wantErr: "error marshal result",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, _ := newService(t)
+			s.Register("purge", tt.handler)
+
+			job, err := s.Enqueue(t.Context(), "purge", nil)
+			require.NoError(t, err)
+
+			s.handle(t.Context(), job.ID)
+
+			got, err := s.Get(t.Context(), job.ID)
+			require.NoError(t, err)
+			assert.Equal(t, StatusFailed, got.Status)
+			assert.Equal(t, tt.wantErr, got.Error)
+			assert.Nil(t, got.Result)
+		})
+	}
+}
+
+func TestService_UnregisteredType(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newService(t)
+	s.Register("purge", func(context.Context, map[string]string, Progress) (any, error) { return nil, nil })
+
+	job, err := s.Enqueue(t.Context(), "purge", nil)
+	require.NoError(t, err)
+
+	// реплика без обработчика этого типа помечает задание как неудачное
+	other, err := New(WithClient(s.client), WithConsumer("other"))
+	require.NoError(t, err)
+
+	other.handle(t.Context(), job.ID)
+
+	got, err := s.Get(t.Context(), job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, got.Status)
+	assert.Equal(t, ErrUnknownType.Error(), got.Error)
+}
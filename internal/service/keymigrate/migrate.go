@@ -0,0 +1,276 @@
+// Package keymigrate копирует ключи Redis со старого префикса (схемы) на новый для blue/green смены
+// раскладки ключей без простоя: сначала новые ключи заполняются копией, затем проверяются,
+// и только после этого сервис переключается на новый префикс. Старые ключи не удаляются.
+package keymigrate
+
+import (
+	"auth-service/internal/service/backup"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// maxMismatches - сколько расхождений сохраняется в отчете проверки.
+const maxMismatches = 100
+
+// RewriteFunc преобразует ключ старой схемы в ключ новой. Новый ключ уже имеет новый префикс.
+type RewriteFunc func(entry backup.Entry) (backup.Entry, error)
+
+// Report - итог копирования или проверки.
+type Report struct {
+	Scanned int `json:"scanned"`
+	Copied  int `json:"copied"`
+	// Skipped - ключи, которые уже есть под новым префиксом и не перезаписывались.
+	Skipped    int      `json:"skipped"`
+	Missing    int      `json:"missing"`
+	Mismatched int      `json:"mismatched"`
+	Mismatches []string `json:"mismatches,omitempty"` // не более 100 ключей
+}
+
+// OK возвращает true, если проверка не нашла расхождений.
+func (r Report) OK() bool {
+	return r.Missing == 0 && r.Mismatched == 0
+}
+
+// Migrator - копирование ключей между префиксами.
+type Migrator struct {
+	client redis.UniversalClient
+
+	from string
+	to   string
+
+	rate      int
+	overwrite bool
+	rewrite   RewriteFunc
+
+	mu     sync.Mutex
+	report Report
+}
+
+// Option - опция для настройки Migrator.
+type Option func(*Migrator)
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(m *Migrator) {
+		m.client = client
+	}
+}
+
+// WithPrefixes устанавливает старый и новый префиксы ключей.
+func WithPrefixes(from, to string) Option {
+	return func(m *Migrator) {
+		m.from = from
+		m.to = to
+	}
+}
+
+// WithRate ограничивает количество обрабатываемых ключей в секунду, чтобы не нагружать Redis.
+// По умолчанию без ограничения.
+func WithRate(keysPerSecond int) Option {
+	return func(m *Migrator) {
+		m.rate = keysPerSecond
+	}
+}
+
+// WithOverwrite разрешает перезаписывать уже существующие ключи под новым префиксом
+// (например, при повторном прогоне для догонки изменений).
+func WithOverwrite(overwrite bool) Option {
+	return func(m *Migrator) {
+		m.overwrite = overwrite
+	}
+}
+
+// WithRewrite устанавливает преобразование значений при смене схемы. По умолчанию значения копируются как есть.
+func WithRewrite(fn RewriteFunc) Option {
+	return func(m *Migrator) {
+		m.rewrite = fn
+	}
+}
+
+// New создает новый Migrator.
+func New(opts ...Option) (*Migrator, error) {
+	m := &Migrator{
+		rewrite: func(entry backup.Entry) (backup.Entry, error) { return entry, nil },
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.client == nil {
+		return nil, errors.New("redis client is required")
+	}
+
+	if m.from == "" || m.to == "" {
+		return nil, errors.New("prefixes are required")
+	}
+
+	// иначе новые ключи попадут под обход старого префикса
+	if strings.HasPrefix(m.to, m.from) || strings.HasPrefix(m.from, m.to) {
+		return nil, errors.New("prefixes must not overlap")
+	}
+
+	if m.rate < 0 {
+		return nil, errors.New("rate must not be negative")
+	}
+
+	return m, nil
+}
+
+// Copy копирует ключи со старого префикса на новый вместе со временем жизни.
+func (m *Migrator) Copy(ctx context.Context) (Report, error) {
+	return m.run(ctx, m.copyKey)
+}
+
+// Verify сравнивает ключи старого префикса с новыми (после преобразования схемы).
+// Время жизни сравнивается только по наличию, так как оно уменьшается между чтениями.
+func (m *Migrator) Verify(ctx context.Context) (Report, error) {
+	return m.run(ctx, m.verifyKey)
+}
+
+func (m *Migrator) run(ctx context.Context, fn func(ctx context.Context, client redis.UniversalClient, key string) error) (Report, error) {
+	m.mu.Lock()
+	m.report = Report{}
+	m.mu.Unlock()
+
+	throttle := func() error { return nil }
+
+	if m.rate > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(m.rate))
+		defer ticker.Stop()
+
+		throttle = func() error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+				return nil
+			}
+		}
+	}
+
+	err := backup.Scan(ctx, m.client, m.from+"*", func(ctx context.Context, client redis.UniversalClient, key string) error {
+		if err := throttle(); err != nil {
+			return err
+		}
+
+		m.update(func(r *Report) { r.Scanned++ })
+
+		return fn(ctx, client, key)
+	})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err != nil {
+		return m.report, fmt.Errorf("keymigrate: %w", err)
+	}
+
+	return m.report, nil
+}
+
+func (m *Migrator) update(fn func(r *Report)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fn(&m.report)
+}
+
+// target читает ключ старой схемы и возвращает его в новой.
+func (m *Migrator) target(ctx context.Context, client redis.UniversalClient, key string) (backup.Entry, error) {
+	entry, err := backup.ReadEntry(ctx, client, key)
+	if err != nil {
+		return backup.Entry{}, err
+	}
+
+	entry.Key = m.to + strings.TrimPrefix(key, m.from)
+
+	return m.rewrite(entry)
+}
+
+func (m *Migrator) copyKey(ctx context.Context, client redis.UniversalClient, key string) error {
+	entry, err := m.target(ctx, client, key)
+	if errors.Is(err, redis.Nil) {
+		// ключ истек во время обхода
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("error read %s: %w", key, err)
+	}
+
+	// новый ключ может лежать в другом слоте кластера, поэтому пишем через общий клиент
+	if !m.overwrite {
+		exists, err := m.client.Exists(ctx, entry.Key).Result()
+		if err != nil {
+			return fmt.Errorf("error check %s: %w", entry.Key, err)
+		}
+
+		if exists != 0 {
+			m.update(func(r *Report) { r.Skipped++ })
+
+			return nil
+		}
+	}
+
+	if err := backup.WriteEntry(ctx, m.client, entry); err != nil {
+		return fmt.Errorf("error write %s: %w", entry.Key, err)
+	}
+
+	m.update(func(r *Report) { r.Copied++ })
+
+	return nil
+}
+
+func (m *Migrator) verifyKey(ctx context.Context, client redis.UniversalClient, key string) error {
+	want, err := m.target(ctx, client, key)
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("error read %s: %w", key, err)
+	}
+
+	got, err := backup.ReadEntry(ctx, m.client, want.Key)
+	if errors.Is(err, redis.Nil) {
+		m.mismatch(want.Key, func(r *Report) { r.Missing++ })
+
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("error read %s: %w", want.Key, err)
+	}
+
+	if (want.TTL > 0) != (got.TTL > 0) {
+		m.mismatch(want.Key, func(r *Report) { r.Mismatched++ })
+
+		return nil
+	}
+
+	want.TTL, got.TTL = 0, 0
+
+	if !reflect.DeepEqual(want, got) {
+		m.mismatch(want.Key, func(r *Report) { r.Mismatched++ })
+	}
+
+	return nil
+}
+
+func (m *Migrator) mismatch(key string, fn func(r *Report)) {
+	m.update(func(r *Report) {
+		fn(r)
+
+		if len(r.Mismatches) < maxMismatches {
+			r.Mismatches = append(r.Mismatches, key)
+		}
+	})
+}
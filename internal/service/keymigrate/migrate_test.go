@@ -0,0 +1,159 @@
+package keymigrate
+
+import (
+	"auth-service/internal/service/backup"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedis(t *testing.T) (redis.UniversalClient, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client, mr
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newRedis(t)
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr string
+	}{
+		{name: "without client", opts: []Option{WithPrefixes("a:", "b:")}, wantErr: "redis client is required"},
+		{name: "without prefixes", opts: []Option{WithClient(client)}, wantErr: "prefixes are required"},
+		{name: "overlapping prefixes", opts: []Option{WithClient(client), WithPrefixes("auth:", "auth:v2:")}, wantErr: "prefixes must not overlap"},
+		{name: "negative rate", opts: []Option{WithClient(client), WithPrefixes("a:", "b:"), WithRate(-1)}, wantErr: "rate must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(tt.opts...)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestCopyVerify(t *testing.T) {
+	t.Parallel()
+
+	client, mr := newRedis(t)
+	ctx := t.Context()
+
+	mr.HSet("auth:group:1", "name", "family")
+	mr.Set("auth:session:1", "payload")
+	mr.SetTTL("auth:session:1", time.Hour)
+	_, err := mr.SAdd("auth:user:1:sessions", "b", "a")
+	require.NoError(t, err)
+	mr.Set("other:key", "untouched")
+
+	m, err := New(WithClient(client), WithPrefixes("auth:", "auth2:"), WithRate(1000))
+	require.NoError(t, err)
+
+	// до копирования все ключи отсутствуют
+	report, err := m.Verify(ctx)
+	require.NoError(t, err)
+	assert.False(t, report.OK())
+	assert.Equal(t, 3, report.Missing)
+
+	report, err = m.Copy(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Report{Scanned: 3, Copied: 3}, report)
+
+	assert.Equal(t, "family", mr.HGet("auth2:group:1", "name"))
+	assert.Equal(t, time.Hour, mr.TTL("auth2:session:1"))
+	assert.False(t, mr.Exists("other2:key"))
+	// старые ключи остаются
+	assert.True(t, mr.Exists("auth:group:1"))
+
+	report, err = m.Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, report.OK())
+	assert.Equal(t, 3, report.Scanned)
+
+	// расхождение после изменения старого ключа
+	mr.HSet("auth:group:1", "name", "renamed")
+
+	report, err = m.Verify(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Mismatched)
+	assert.Equal(t, []string{"auth2:group:1"}, report.Mismatches)
+
+	// без overwrite существующие ключи не трогаются
+	report, err = m.Copy(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Skipped)
+	assert.Equal(t, "family", mr.HGet("auth2:group:1", "name"))
+
+	catchUp, err := New(WithClient(client), WithPrefixes("auth:", "auth2:"), WithOverwrite(true))
+	require.NoError(t, err)
+
+	report, err = catchUp.Copy(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Copied)
+	assert.Equal(t, "renamed", mr.HGet("auth2:group:1", "name"))
+}
+
+func TestCopy_Rewrite(t *testing.T) {
+	t.Parallel()
+
+	client, mr := newRedis(t)
+
+	mr.Set("v1:user:1", "legacy")
+
+	m, err := New(
+		WithClient(client),
+		WithPrefixes("v1:", "v2:"),
+		WithRewrite(func(entry backup.Entry) (backup.Entry, error) {
+			entry.String = "converted:" + entry.String
+
+			return entry, nil
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = m.Copy(t.Context())
+	require.NoError(t, err)
+
+	got, err := mr.Get("v2:user:1")
+	require.NoError(t, err)
+	assert.Equal(t, "converted:legacy", got)
+
+	report, err := m.Verify(t.Context())
+	require.NoError(t, err)
+	assert.True(t, report.OK())
+}
+
+func TestCopy_Canceled(t *testing.T) {
+	t.Parallel()
+
+	client, mr := newRedis(t)
+
+	mr.Set("a:1", "1")
+	mr.Set("a:2", "2")
+
+	m, err := New(WithClient(client), WithPrefixes("a:", "b:"), WithRate(1))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, err = m.Copy(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
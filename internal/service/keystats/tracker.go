@@ -0,0 +1,164 @@
+// Package keystats считает использование ключей подписи по kid:
+// сколько токенов выпущено и проверено каждым ключом и когда ключ использовался последний раз.
+// По этим данным оператор решает, когда старый ключ можно выводить из ротации.
+package keystats
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Usage - статистика использования ключа с момента запуска сервиса.
+type Usage struct {
+	Kid            string     `json:"kid"`
+	Issued         uint64     `json:"issued"`
+	Verified       uint64     `json:"verified"`
+	LastIssuedAt   *time.Time `json:"last_issued_at,omitempty"`
+	LastVerifiedAt *time.Time `json:"last_verified_at,omitempty"`
+}
+
+// Tracker - счетчик использования ключей. Данные хранятся в памяти и дублируются в метрики,
+// чтобы историю за длительный период можно было получить из Prometheus.
+type Tracker struct {
+	registerer prometheus.Registerer
+
+	issued       *prometheus.CounterVec
+	verified     *prometheus.CounterVec
+	lastVerified *prometheus.GaugeVec
+
+	mu    sync.Mutex
+	usage map[string]*Usage
+
+	startedAt time.Time
+	now       func() time.Time
+}
+
+// Option - опция для настройки Tracker.
+type Option func(*Tracker)
+
+// WithRegisterer устанавливает реестр метрик. По умолчанию используется prometheus.DefaultRegisterer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(t *Tracker) {
+		t.registerer = registerer
+	}
+}
+
+// New создает новый Tracker и регистрирует его метрики.
+func New(opts ...Option) (*Tracker, error) {
+	t := &Tracker{
+		registerer: prometheus.DefaultRegisterer,
+		usage:      map[string]*Usage{},
+		now:        time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	if t.registerer == nil {
+		return nil, errors.New("registerer is required")
+	}
+
+	t.issued = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_key_tokens_issued_total",
+		Help: "Количество токенов, подписанных ключом.",
+	}, []string{"kid"})
+
+	t.verified = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_key_tokens_verified_total",
+		Help: "Количество токенов, успешно проверенных ключом.",
+	}, []string{"kid"})
+
+	t.lastVerified = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "auth_key_last_verified_timestamp_seconds",
+		Help: "Время последней успешной проверки токена ключом (unix time).",
+	}, []string{"kid"})
+
+	for _, c := range []prometheus.Collector{t.issued, t.verified, t.lastVerified} {
+		if err := t.registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	t.startedAt = t.now()
+
+	return t, nil
+}
+
+// Issued учитывает выпуск токена ключом kid.
+func (t *Tracker) Issued(kid string) {
+	now := t.now()
+
+	t.mu.Lock()
+	u := t.get(kid)
+	u.Issued++
+	u.LastIssuedAt = &now
+	t.mu.Unlock()
+
+	t.issued.WithLabelValues(kid).Inc()
+}
+
+// Verified учитывает успешную проверку токена ключом kid.
+func (t *Tracker) Verified(kid string) {
+	now := t.now()
+
+	t.mu.Lock()
+	u := t.get(kid)
+	u.Verified++
+	u.LastVerifiedAt = &now
+	t.mu.Unlock()
+
+	t.verified.WithLabelValues(kid).Inc()
+	t.lastVerified.WithLabelValues(kid).Set(float64(now.Unix()))
+}
+
+// Usage возвращает статистику по всем ключам, отсортированную по kid.
+func (t *Tracker) Usage() []Usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	res := make([]Usage, 0, len(t.usage))
+	for _, u := range t.usage {
+		res = append(res, *u)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Kid < res[j].Kid })
+
+	return res
+}
+
+// Idle возвращает true, если ключом не проверялся ни один токен за период d.
+// Учитывается только время работы текущего процесса: если сервис запущен позже, чем d назад,
+// ключ не считается простаивающим, так как данных за весь период нет.
+func (t *Tracker) Idle(kid string, d time.Duration) bool {
+	now := t.now()
+
+	if now.Sub(t.startedAt) < d {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	u, ok := t.usage[kid]
+	if !ok || u.LastVerifiedAt == nil {
+		return true
+	}
+
+	return now.Sub(*u.LastVerifiedAt) >= d
+}
+
+// get возвращает статистику ключа, создавая ее при необходимости. Вызывается под мьютексом.
+func (t *Tracker) get(kid string) *Usage {
+	u, ok := t.usage[kid]
+	if !ok {
+		u = &Usage{Kid: kid}
+		t.usage[kid] = u
+	}
+
+	return u
+}
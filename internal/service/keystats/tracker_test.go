@@ -0,0 +1,89 @@
+package keystats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewRegistry()
+
+	_, err := New(WithRegisterer(registry))
+	require.NoError(t, err)
+
+	// повторная регистрация тех же метрик - ошибка
+	_, err = New(WithRegisterer(registry))
+	require.Error(t, err)
+
+	_, err = New(WithRegisterer(nil))
+	require.ErrorContains(t, err, "registerer is required")
+}
+
+func TestTracker(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	tracker, err := New(WithRegisterer(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	tracker.now = func() time.Time { return now }
+
+	tracker.Issued("key-2")
+	tracker.Issued("key-2")
+	tracker.Verified("key-2")
+	tracker.Verified("key-1")
+
+	usage := tracker.Usage()
+	require.Len(t, usage, 2)
+
+	assert.Equal(t, "key-1", usage[0].Kid)
+	assert.Equal(t, uint64(0), usage[0].Issued)
+	assert.Equal(t, uint64(1), usage[0].Verified)
+	assert.Nil(t, usage[0].LastIssuedAt)
+	assert.Equal(t, now, *usage[0].LastVerifiedAt)
+
+	assert.Equal(t, "key-2", usage[1].Kid)
+	assert.Equal(t, uint64(2), usage[1].Issued)
+	assert.Equal(t, uint64(1), usage[1].Verified)
+
+	assert.InDelta(t, 2, testutil.ToFloat64(tracker.issued.WithLabelValues("key-2")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(tracker.verified.WithLabelValues("key-1")), 0)
+	assert.InDelta(t, float64(now.Unix()), testutil.ToFloat64(tracker.lastVerified.WithLabelValues("key-1")), 0)
+}
+
+func TestTracker_Idle(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	startedAt := now.Add(-30 * 24 * time.Hour)
+
+	tracker, err := New(WithRegisterer(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	tracker.startedAt = startedAt
+	tracker.now = func() time.Time { return now.Add(-10 * 24 * time.Hour) }
+	tracker.Verified("old")
+
+	tracker.now = func() time.Time { return now.Add(-time.Hour) }
+	tracker.Verified("current")
+
+	tracker.now = func() time.Time { return now }
+
+	week := 7 * 24 * time.Hour
+
+	assert.True(t, tracker.Idle("old", week))
+	assert.False(t, tracker.Idle("current", week))
+	assert.True(t, tracker.Idle("never-used", week))
+
+	// сервис работает меньше периода - данных недостаточно
+	tracker.startedAt = now.Add(-24 * time.Hour)
+	assert.False(t, tracker.Idle("old", week))
+}
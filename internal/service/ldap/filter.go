@@ -0,0 +1,27 @@
+package ldap
+
+import (
+	"fmt"
+	"strings"
+
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+// checkFilter проверяет, что фильтр содержит подстановку и разбирается.
+func checkFilter(filter, placeholder string) error {
+	if !strings.Contains(filter, placeholder) {
+		return fmt.Errorf("filter %q must contain %s", filter, placeholder)
+	}
+
+	if _, err := goldap.CompileFilter(substitute(filter, placeholder, "x")); err != nil {
+		return fmt.Errorf("invalid filter %q: %w", filter, err)
+	}
+
+	return nil
+}
+
+// substitute подставляет в фильтр значение, экранированное по RFC 4515: логин или DN
+// не могут изменить условие фильтра.
+func substitute(filter, placeholder, value string) string {
+	return strings.ReplaceAll(filter, placeholder, goldap.EscapeFilter(value))
+}
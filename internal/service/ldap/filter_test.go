@@ -0,0 +1,76 @@
+package ldap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckFilter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		filter  string
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			filter:  "(&(objectClass=person)(uid={username}))",
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: substrings",
+			filter:  "(&(cn=a*b)(uid={username}))",
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: no placeholder",
+			filter:  "(uid=admin)",
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: no parentheses",
+			filter:  "uid={username}",
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: unbalanced",
+			filter:  "(&(uid={username})",
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: bad escape",
+			filter:  "(&(cn=\\zz)(uid={username}))",
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.wantErr(t, checkFilter(tt.filter, "{username}"))
+		})
+	}
+}
+
+func TestSubstitute(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "jdoe", want: "(uid=jdoe)"},
+		{value: "*", want: "(uid=\\2a)"},
+		{value: "a)(uid=*", want: "(uid=a\\29\\28uid=\\2a)"},
+		{value: "cn=x\\,dc=y", want: "(uid=cn=x\\5c,dc=y)"},
+		{value: "\x00", want: "(uid=\\00)"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, substitute("(uid={username})", "{username}", tt.value))
+	}
+}
@@ -0,0 +1,481 @@
+// Package ldap реализует вход администраторов по учетным записям корпоративного каталога
+// (LDAP или Active Directory): пароль проверяется bind от имени пользователя, группы
+// пользователя в каталоге сопоставляются с ролями административного API.
+package ldap
+
+import (
+	"auth-service/internal/service/token"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+const (
+	// Audience - аудитория токенов административного API.
+	Audience = "admin"
+
+	// RoleViewer - только чтение административного API.
+	RoleViewer = "viewer"
+	// RoleOperator - полный доступ к административному API.
+	RoleOperator = "operator"
+
+	// DefaultUserFilter - фильтр поиска пользователя. {username} заменяется экранированным логином.
+	DefaultUserFilter = "(&(objectClass=person)(uid={username}))"
+	// DefaultGroupAttribute - атрибут пользователя со списком его групп.
+	DefaultGroupAttribute = "memberOf"
+	// DefaultTimeout - таймаут входа, включая подключение и все запросы к каталогу.
+	DefaultTimeout = 5 * time.Second
+	// DefaultTokenTTL - время жизни токена администратора.
+	DefaultTokenTTL = time.Hour
+
+	scopePrefix   = "admin:"
+	subjectPrefix = "ldap:"
+
+	// noAttributes - запрос записей без атрибутов (RFC 4511, раздел 4.5.1.8).
+	noAttributes = "1.1"
+)
+
+var (
+	// ErrInvalidCredentials - пользователь не найден или пароль не подошел.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrNoRoles - пользователь не состоит ни в одной группе, которой назначена роль.
+	ErrNoRoles = errors.New("user has no admin roles")
+	// ErrUnavailable - каталог недоступен или вернул ошибку.
+	ErrUnavailable = errors.New("ldap is unavailable")
+	// ErrInactive - учетная запись администратора не заведена или отключена корпоративным IdP.
+	ErrInactive = errors.New("admin account is not active")
+)
+
+// Scope возвращает scope токена для роли административного API.
+func Scope(role string) string {
+	return scopePrefix + role
+}
+
+// Subject возвращает субъект токенов администратора с логином username.
+func Subject(username string) string {
+	return subjectPrefix + username
+}
+
+// ValidRole возвращает true, если роль административного API известна.
+func ValidRole(role string) bool {
+	return role == RoleViewer || role == RoleOperator
+}
+
+//go:generate mockgen -source=ldap.go -destination=mocks/ldap_mock.go -package=mocks
+type tokenIssuer interface {
+	Issue(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error)
+}
+
+// accountChecker - учетные записи администраторов, которые ведет корпоративный IdP.
+type accountChecker interface {
+	Active(ctx context.Context, username string) (bool, error)
+}
+
+// Identity - пользователь каталога.
+type Identity struct {
+	Username string
+	DN       string
+	Groups   []string
+	Roles    []string
+}
+
+// Login - токен администратора, выпущенный после входа.
+type Login struct {
+	Token    string
+	Claims   *token.Claims
+	Identity *Identity
+}
+
+// Service - вход администраторов через LDAP.
+type Service struct {
+	issuer tokenIssuer
+	// учетные записи из IdP, nil - вход разрешен всем пользователям каталога с ролями
+	accounts accountChecker
+
+	url       string
+	tlsConfig *tls.Config
+	startTLS  bool
+
+	// сервисная учетная запись для поиска пользователя и групп, пусто - анонимный поиск
+	bindDN       string
+	bindPassword string
+
+	baseDN         string
+	userFilter     string
+	groupAttribute string
+	groupBaseDN    string
+	groupFilter    string
+	// роли групп, ключ - DN группы в нижнем регистре
+	groupRoles map[string][]string
+
+	timeout  time.Duration
+	tokenTTL time.Duration
+}
+
+// Option - опция для настройки Service.
+type Option func(*Service)
+
+// WithIssuer устанавливает выпуск токенов администратора.
+func WithIssuer(issuer tokenIssuer) Option {
+	return func(s *Service) {
+		s.issuer = issuer
+	}
+}
+
+// WithAccounts разрешает вход только пользователям с активной учетной записью, заведенной корпоративным IdP.
+func WithAccounts(accounts accountChecker) Option {
+	return func(s *Service) {
+		s.accounts = accounts
+	}
+}
+
+// WithURL устанавливает адрес сервера: ldap://host:389 или ldaps://host:636.
+func WithURL(rawURL string) Option {
+	return func(s *Service) {
+		s.url = rawURL
+	}
+}
+
+// WithTLS устанавливает настройки TLS. startTLS включает StartTLS для адресов ldap://.
+func WithTLS(cfg *tls.Config, startTLS bool) Option {
+	return func(s *Service) {
+		s.tlsConfig = cfg
+		s.startTLS = startTLS
+	}
+}
+
+// WithBind устанавливает сервисную учетную запись, от имени которой ищутся пользователи и группы.
+func WithBind(dn, password string) Option {
+	return func(s *Service) {
+		s.bindDN = dn
+		s.bindPassword = password
+	}
+}
+
+// WithBaseDN устанавливает корень поиска пользователей.
+func WithBaseDN(dn string) Option {
+	return func(s *Service) {
+		s.baseDN = dn
+	}
+}
+
+// WithUserFilter устанавливает фильтр поиска пользователя. По умолчанию DefaultUserFilter.
+// Для Active Directory обычно (&(objectClass=user)(sAMAccountName={username})).
+func WithUserFilter(filter string) Option {
+	return func(s *Service) {
+		s.userFilter = filter
+	}
+}
+
+// WithGroupAttribute устанавливает атрибут пользователя со списком групп. По умолчанию DefaultGroupAttribute.
+func WithGroupAttribute(attr string) Option {
+	return func(s *Service) {
+		s.groupAttribute = attr
+	}
+}
+
+// WithGroupSearch включает поиск групп вместо атрибута пользователя, например для OpenLDAP без memberOf.
+// В filter {dn} заменяется экранированным DN пользователя. Пустой baseDN - корень поиска пользователей.
+func WithGroupSearch(baseDN, filter string) Option {
+	return func(s *Service) {
+		s.groupBaseDN = baseDN
+		s.groupFilter = filter
+	}
+}
+
+// WithGroupRoles устанавливает роли административного API для групп каталога (ключ - DN группы).
+func WithGroupRoles(roles map[string][]string) Option {
+	return func(s *Service) {
+		for group, groupRoles := range roles {
+			key := strings.ToLower(group)
+			s.groupRoles[key] = append(s.groupRoles[key], groupRoles...)
+		}
+	}
+}
+
+// WithTimeout устанавливает таймаут входа. По умолчанию DefaultTimeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *Service) {
+		s.timeout = timeout
+	}
+}
+
+// WithTokenTTL устанавливает время жизни токена администратора. По умолчанию DefaultTokenTTL.
+func WithTokenTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		s.tokenTTL = ttl
+	}
+}
+
+// New создает новый Service.
+func New(opts ...Option) (*Service, error) {
+	s := &Service{
+		userFilter:     DefaultUserFilter,
+		groupAttribute: DefaultGroupAttribute,
+		groupRoles:     make(map[string][]string),
+		timeout:        DefaultTimeout,
+		tokenTTL:       DefaultTokenTTL,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.issuer == nil {
+		return nil, errors.New("issuer is required")
+	}
+
+	u, err := url.Parse(s.url)
+	if err != nil || (u.Scheme != "ldap" && u.Scheme != "ldaps") || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid ldap url %q", s.url)
+	}
+
+	s.tlsConfig = s.tlsConfig.Clone()
+	if s.tlsConfig == nil {
+		s.tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if s.tlsConfig.ServerName == "" {
+		s.tlsConfig.ServerName = u.Hostname()
+	}
+
+	if s.baseDN == "" {
+		return nil, errors.New("base dn is required")
+	}
+
+	if err := checkFilter(s.userFilter, "{username}"); err != nil {
+		return nil, fmt.Errorf("user filter: %w", err)
+	}
+
+	if s.groupFilter != "" {
+		if err := checkFilter(s.groupFilter, "{dn}"); err != nil {
+			return nil, fmt.Errorf("group filter: %w", err)
+		}
+	}
+
+	if len(s.groupRoles) == 0 {
+		return nil, errors.New("group roles are required")
+	}
+
+	for group, roles := range s.groupRoles {
+		for _, role := range roles {
+			if !ValidRole(role) {
+				return nil, fmt.Errorf("group %s: unknown role %q", group, role)
+			}
+		}
+	}
+
+	if s.timeout <= 0 {
+		return nil, errors.New("timeout must be positive")
+	}
+
+	if s.tokenTTL <= 0 {
+		return nil, errors.New("token ttl must be positive")
+	}
+
+	return s, nil
+}
+
+// Login проверяет логин и пароль в каталоге и выпускает токен администратора с ролями из групп пользователя.
+func (s *Service) Login(ctx context.Context, username, password string) (*Login, error) {
+	identity, err := s.Authenticate(ctx, username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := make([]string, 0, len(identity.Roles))
+	for _, role := range identity.Roles {
+		scopes = append(scopes, Scope(role))
+	}
+
+	raw, claims, err := s.issuer.Issue(ctx, token.IssueRequest{
+		Subject:  Subject(identity.Username),
+		Audience: []string{Audience},
+		TTL:      s.tokenTTL,
+		Scopes:   scopes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ldap: error issue token: %w", err)
+	}
+
+	return &Login{Token: raw, Claims: claims, Identity: identity}, nil
+}
+
+// Authenticate проверяет логин и пароль в каталоге и возвращает пользователя с его ролями.
+func (s *Service) Authenticate(ctx context.Context, username, password string) (*Identity, error) {
+	// bind с пустым паролем - анонимный и проходит на большинстве серверов (RFC 4513, раздел 5.1.2)
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	c, err := s.dial(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	defer c.Close()
+
+	if err := s.serviceBind(c); err != nil {
+		return nil, err
+	}
+
+	filter := substitute(s.userFilter, "{username}", username)
+
+	res, err := c.Search(s.searchRequest(s.baseDN, filter, 2, s.groupAttribute))
+	if err != nil && !goldap.IsErrorWithCode(err, goldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("%w: search user: %w", ErrUnavailable, err)
+	}
+
+	// несколько записей - фильтр настроен неоднозначно, такой вход не пропускается
+	if res == nil || len(res.Entries) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+
+	user := res.Entries[0]
+
+	if err := c.Bind(user.DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+
+		return nil, fmt.Errorf("%w: bind user: %w", ErrUnavailable, err)
+	}
+
+	groups := user.GetAttributeValues(s.groupAttribute)
+
+	if s.groupFilter != "" {
+		groups, err = s.searchGroups(c, user.DN)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	identity := &Identity{
+		Username: username,
+		DN:       user.DN,
+		Groups:   groups,
+		Roles:    s.roles(groups),
+	}
+
+	if len(identity.Roles) == 0 {
+		return nil, ErrNoRoles
+	}
+
+	// проверка после bind: статус учетной записи не раскрывается без верного пароля
+	if err := s.checkActive(ctx, username); err != nil {
+		return nil, err
+	}
+
+	return identity, nil
+}
+
+func (s *Service) checkActive(ctx context.Context, username string) error {
+	if s.accounts == nil {
+		return nil
+	}
+
+	active, err := s.accounts.Active(ctx, username)
+	if err != nil {
+		return fmt.Errorf("ldap: error check account: %w", err)
+	}
+
+	if !active {
+		return ErrInactive
+	}
+
+	return nil
+}
+
+// dial подключается к серверу и при необходимости выполняет StartTLS. Соединение закрывается
+// при отмене ctx, каждый запрос ограничен таймаутом входа.
+func (s *Service) dial(ctx context.Context) (*goldap.Conn, error) {
+	dialer := &net.Dialer{Timeout: s.timeout}
+
+	c, err := goldap.DialURL(s.url, goldap.DialWithDialer(dialer), goldap.DialWithTLSConfig(s.tlsConfig))
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetTimeout(s.timeout)
+	context.AfterFunc(ctx, c.Close)
+
+	if s.startTLS && strings.HasPrefix(s.url, "ldap://") {
+		if err := c.StartTLS(s.tlsConfig); err != nil {
+			c.Close()
+
+			return nil, fmt.Errorf("start tls: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+// searchRequest возвращает запрос поиска по всему поддереву base.
+func (s *Service) searchRequest(base, filter string, sizeLimit int, attributes ...string) *goldap.SearchRequest {
+	return goldap.NewSearchRequest(base, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases,
+		sizeLimit, int(s.timeout.Seconds()), false, filter, attributes, nil)
+}
+
+// serviceBind аутентифицирует соединение сервисной учетной записью, если она задана.
+func (s *Service) serviceBind(c *goldap.Conn) error {
+	if s.bindDN == "" {
+		return nil
+	}
+
+	if err := c.Bind(s.bindDN, s.bindPassword); err != nil {
+		return fmt.Errorf("%w: service bind: %w", ErrUnavailable, err)
+	}
+
+	return nil
+}
+
+// searchGroups ищет группы пользователя. Поиск выполняется от имени сервисной учетной записи:
+// у пользователя может не быть прав на чтение групп.
+func (s *Service) searchGroups(c *goldap.Conn, userDN string) ([]string, error) {
+	if err := s.serviceBind(c); err != nil {
+		return nil, err
+	}
+
+	base := s.groupBaseDN
+	if base == "" {
+		base = s.baseDN
+	}
+
+	filter := substitute(s.groupFilter, "{dn}", userDN)
+
+	res, err := c.Search(s.searchRequest(base, filter, 0, noAttributes))
+	if err != nil {
+		return nil, fmt.Errorf("%w: search groups: %w", ErrUnavailable, err)
+	}
+
+	groups := make([]string, 0, len(res.Entries))
+	for _, e := range res.Entries {
+		groups = append(groups, e.DN)
+	}
+
+	return groups, nil
+}
+
+// roles возвращает роли групп без повторов в порядке сортировки.
+func (s *Service) roles(groups []string) []string {
+	var roles []string
+
+	for _, group := range groups {
+		roles = append(roles, s.groupRoles[strings.ToLower(group)]...)
+	}
+
+	slices.Sort(roles)
+
+	return slices.Compact(roles)
+}
@@ -0,0 +1,470 @@
+package ldap
+
+import (
+	"auth-service/internal/service/ldap/mocks"
+	"auth-service/internal/service/token"
+	"errors"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	serviceDN       = "cn=auth,ou=services,dc=example,dc=org"
+	servicePassword = "service-secret"
+	baseDN          = "ou=people,dc=example,dc=org"
+	adminsDN        = "cn=Auth-Admins,ou=groups,dc=example,dc=org"
+	supportDN       = "cn=support,ou=groups,dc=example,dc=org"
+)
+
+// entry - запись каталога в ответе fakeDirectory.
+type entry struct {
+	dn         string
+	attributes map[string][]string
+}
+
+// fakeDirectory - тестовый сервер LDAP. Отвечает на bind по таблице паролей,
+// на поиск - по заранее заданным результатам для базового DN и фильтра.
+type fakeDirectory struct {
+	t         *testing.T
+	addr      string
+	passwords map[string]string
+	results   map[string][]entry
+	mu        sync.Mutex
+	// binds - DN успешных bind по порядку.
+	binds []string
+}
+
+func newFakeDirectory(t *testing.T) *fakeDirectory {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = ln.Close() })
+
+	d := &fakeDirectory{
+		t:         t,
+		addr:      ln.Addr().String(),
+		passwords: map[string]string{serviceDN: servicePassword},
+		results:   make(map[string][]entry),
+	}
+
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			d.serve(nc)
+		}
+	}()
+
+	return d
+}
+
+func (d *fakeDirectory) url() string {
+	return "ldap://" + d.addr
+}
+
+// addSearch задает результат поиска.
+func (d *fakeDirectory) addSearch(base, filter string, entries ...entry) {
+	compiled, err := goldap.CompileFilter(filter)
+	require.NoError(d.t, err)
+
+	d.results[base+"|"+string(compiled.Bytes())] = entries
+}
+
+func (d *fakeDirectory) serve(nc net.Conn) {
+	defer func() { _ = nc.Close() }()
+
+	for {
+		msg, err := ber.ReadPacket(nc)
+		if err != nil || len(msg.Children) < 2 {
+			return
+		}
+
+		id, _ := msg.Children[0].Value.(int64)
+		op := msg.Children[1]
+
+		switch op.Tag {
+		case goldap.ApplicationBindRequest:
+			dn, _ := op.Children[1].Value.(string)
+			password := op.Children[2].Data.String()
+
+			code := int64(goldap.LDAPResultInvalidCredentials)
+			if want, ok := d.passwords[dn]; ok && want == password {
+				code = goldap.LDAPResultSuccess
+
+				d.mu.Lock()
+				d.binds = append(d.binds, dn)
+				d.mu.Unlock()
+			}
+
+			d.reply(nc, id, result(goldap.ApplicationBindResponse, code))
+		case goldap.ApplicationSearchRequest:
+			base, _ := op.Children[0].Value.(string)
+
+			for _, e := range d.results[base+"|"+string(op.Children[6].Bytes())] {
+				found := ber.Encode(ber.ClassApplication, ber.TypeConstructed, goldap.ApplicationSearchResultEntry, nil, "")
+				found.AppendChild(octetString(e.dn))
+
+				attrs := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+				for name, values := range e.attributes {
+					attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+					attr.AppendChild(octetString(name))
+
+					set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+					for _, v := range values {
+						set.AppendChild(octetString(v))
+					}
+
+					attr.AppendChild(set)
+					attrs.AppendChild(attr)
+				}
+
+				found.AppendChild(attrs)
+				d.reply(nc, id, found)
+			}
+
+			d.reply(nc, id, result(goldap.ApplicationSearchResultDone, goldap.LDAPResultSuccess))
+		default:
+			return
+		}
+	}
+}
+
+func (d *fakeDirectory) boundDNs() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return slices.Clone(d.binds)
+}
+
+func (d *fakeDirectory) reply(nc net.Conn, id int64, op *ber.Packet) {
+	msg := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+	msg.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, ""))
+	msg.AppendChild(op)
+
+	_, err := nc.Write(msg.Bytes())
+	require.NoError(d.t, err)
+}
+
+// result возвращает ответ LDAP с кодом результата.
+func result(tag ber.Tag, code int64) *ber.Packet {
+	p := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "")
+	p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, ""))
+	p.AppendChild(octetString(""))
+	p.AppendChild(octetString(""))
+
+	return p
+}
+
+func octetString(value string) *ber.Packet {
+	return ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "")
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	issuer := mocks.NewMocktokenIssuer(gomock.NewController(t))
+
+	roles := map[string][]string{adminsDN: {RoleOperator}}
+	required := []Option{WithIssuer(issuer), WithURL("ldap://ldap.example.org"), WithBaseDN(baseDN), WithGroupRoles(roles)}
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			opts:    required,
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: all options",
+			opts: append(required,
+				WithURL("ldaps://ldap.example.org:636"),
+				WithTLS(nil, false),
+				WithBind(serviceDN, servicePassword),
+				WithUserFilter("(&(objectClass=user)(sAMAccountName={username}))"),
+				WithGroupAttribute("memberOf"),
+				WithGroupSearch("ou=groups,dc=example,dc=org", "(&(objectClass=groupOfNames)(member={dn}))"),
+				WithTimeout(time.Second),
+				WithTokenTTL(time.Hour),
+			),
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: issuer is nil",
+			opts:    []Option{WithURL("ldap://ldap.example.org"), WithBaseDN(baseDN), WithGroupRoles(roles)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: invalid url scheme",
+			opts:    append(required, WithURL("http://ldap.example.org")),
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: no base dn",
+			opts:    append(required, WithBaseDN("")),
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: user filter without placeholder",
+			opts:    append(required, WithUserFilter("(uid=admin)")),
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: invalid user filter",
+			opts:    append(required, WithUserFilter("(uid={username}")),
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: group filter without placeholder",
+			opts:    append(required, WithGroupSearch("", "(objectClass=groupOfNames)")),
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: no group roles",
+			opts:    []Option{WithIssuer(issuer), WithURL("ldap://ldap.example.org"), WithBaseDN(baseDN)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: unknown role",
+			opts:    append(required, WithGroupRoles(map[string][]string{supportDN: {"root"}})),
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: zero timeout",
+			opts:    append(required, WithTimeout(0)),
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: zero token ttl",
+			opts:    append(required, WithTokenTTL(0)),
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(tt.opts...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService_Login(t *testing.T) {
+	t.Parallel()
+
+	d := newFakeDirectory(t)
+	d.passwords["uid=jdoe,"+baseDN] = "jdoe-secret"
+	d.passwords["uid=guest,"+baseDN] = "guest-secret"
+
+	d.addSearch(baseDN, "(&(objectClass=person)(uid=jdoe))", entry{
+		dn: "uid=jdoe," + baseDN,
+		// регистр DN группы в каталоге может отличаться от конфигурации
+		attributes: map[string][]string{"memberOf": {"CN=auth-admins,OU=groups,DC=example,DC=org", supportDN}},
+	})
+	d.addSearch(baseDN, "(&(objectClass=person)(uid=guest))", entry{dn: "uid=guest," + baseDN})
+	d.addSearch(baseDN, "(&(objectClass=person)(uid=\\2a))",
+		entry{dn: "uid=jdoe," + baseDN},
+		entry{dn: "uid=guest," + baseDN},
+	)
+
+	issuer := mocks.NewMocktokenIssuer(gomock.NewController(t))
+
+	s, err := New(
+		WithIssuer(issuer),
+		WithURL(d.url()),
+		WithBind(serviceDN, servicePassword),
+		WithBaseDN(baseDN),
+		WithGroupRoles(map[string][]string{
+			adminsDN:  {RoleOperator},
+			supportDN: {RoleViewer, RoleOperator},
+		}),
+		WithTokenTTL(30*time.Minute),
+	)
+	require.NoError(t, err)
+
+	issuer.EXPECT().Issue(gomock.Any(), token.IssueRequest{
+		Subject:  "ldap:jdoe",
+		Audience: []string{Audience},
+		TTL:      30 * time.Minute,
+		Scopes:   []string{"admin:operator", "admin:viewer"},
+	}).Return("jwt", &token.Claims{Subject: "ldap:jdoe"}, nil)
+
+	login, err := s.Login(t.Context(), "jdoe", "jdoe-secret")
+	require.NoError(t, err)
+	assert.Equal(t, "jwt", login.Token)
+	assert.Equal(t, "uid=jdoe,"+baseDN, login.Identity.DN)
+	assert.Equal(t, []string{RoleOperator, RoleViewer}, login.Identity.Roles)
+	assert.Equal(t, []string{serviceDN, "uid=jdoe," + baseDN}, d.boundDNs())
+
+	_, err = s.Login(t.Context(), "jdoe", "wrong")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	// пустой пароль не отправляется на сервер: такой bind анонимный
+	_, err = s.Login(t.Context(), "jdoe", "")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = s.Login(t.Context(), "unknown", "secret")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	// логин экранируется и не расширяет фильтр до нескольких пользователей
+	_, err = s.Login(t.Context(), "*", "jdoe-secret")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = s.Login(t.Context(), "guest", "guest-secret")
+	require.ErrorIs(t, err, ErrNoRoles)
+}
+
+func TestService_GroupSearch(t *testing.T) {
+	t.Parallel()
+
+	d := newFakeDirectory(t)
+	d.passwords["uid=jdoe,"+baseDN] = "jdoe-secret"
+
+	d.addSearch(baseDN, "(&(objectClass=person)(uid=jdoe))", entry{dn: "uid=jdoe," + baseDN})
+	d.addSearch("ou=groups,dc=example,dc=org", "(&(objectClass=groupOfNames)(member=uid=jdoe,"+baseDN+"))",
+		entry{dn: supportDN},
+	)
+
+	s, err := New(
+		WithIssuer(mocks.NewMocktokenIssuer(gomock.NewController(t))),
+		WithURL(d.url()),
+		WithBind(serviceDN, servicePassword),
+		WithBaseDN(baseDN),
+		WithGroupSearch("ou=groups,dc=example,dc=org", "(&(objectClass=groupOfNames)(member={dn}))"),
+		WithGroupRoles(map[string][]string{supportDN: {RoleViewer}}),
+	)
+	require.NoError(t, err)
+
+	identity, err := s.Authenticate(t.Context(), "jdoe", "jdoe-secret")
+	require.NoError(t, err)
+	assert.Equal(t, []string{supportDN}, identity.Groups)
+	assert.Equal(t, []string{RoleViewer}, identity.Roles)
+
+	// группы ищутся от имени сервисной учетной записи
+	assert.Equal(t, []string{serviceDN, "uid=jdoe," + baseDN, serviceDN}, d.boundDNs())
+}
+
+func TestService_Errors(t *testing.T) {
+	t.Parallel()
+
+	d := newFakeDirectory(t)
+	d.passwords["uid=jdoe,"+baseDN] = "jdoe-secret"
+	d.addSearch(baseDN, "(&(objectClass=person)(uid=jdoe))", entry{
+		dn:         "uid=jdoe," + baseDN,
+		attributes: map[string][]string{"memberOf": {adminsDN}},
+	})
+
+	issuer := mocks.NewMocktokenIssuer(gomock.NewController(t))
+
+	opts := []Option{
+		WithIssuer(issuer),
+		WithURL(d.url()),
+		WithBaseDN(baseDN),
+		WithGroupRoles(map[string][]string{adminsDN: {RoleOperator}}),
+	}
+
+	// неверный пароль сервисной учетной записи - ошибка конфигурации, а не пользователя
+	s, err := New(append(opts, WithBind(serviceDN, "wrong"))...)
+	require.NoError(t, err)
+
+	_, err = s.Authenticate(t.Context(), "jdoe", "jdoe-secret")
+	require.ErrorIs(t, err, ErrUnavailable)
+
+	// ошибка выпуска токена
+	s, err = New(opts...)
+	require.NoError(t, err)
+
+	issuer.EXPECT().Issue(gomock.Any(), gomock.Any()).Return("", nil, errors.New("vault is down"))
+
+	_, err = s.Login(t.Context(), "jdoe", "jdoe-secret")
+	require.Error(t, err)
+
+	// сервер недоступен
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, ln.Close())
+
+	s, err = New(append(opts, WithURL("ldap://"+ln.Addr().String()))...)
+	require.NoError(t, err)
+
+	_, err = s.Authenticate(t.Context(), "jdoe", "jdoe-secret")
+	require.ErrorIs(t, err, ErrUnavailable)
+}
+
+func TestService_Accounts(t *testing.T) {
+	t.Parallel()
+
+	d := newFakeDirectory(t)
+	d.passwords["uid=jdoe,"+baseDN] = "jdoe-secret"
+	d.addSearch(baseDN, "(&(objectClass=person)(uid=jdoe))", entry{
+		dn:         "uid=jdoe," + baseDN,
+		attributes: map[string][]string{"memberOf": {adminsDN}},
+	})
+
+	tests := []struct {
+		name     string
+		password string
+		active   bool
+		err      error
+		// checked - проверялась ли учетная запись
+		checked bool
+		wantErr error
+	}{
+		{name: "active", password: "jdoe-secret", active: true, checked: true},
+		{name: "inactive", password: "jdoe-secret", checked: true, wantErr: ErrInactive},
+		{name: "storage error", password: "jdoe-secret", err: errors.New("redis is down"), checked: true},
+		// статус учетной записи не проверяется, пока пароль неверный
+		{name: "wrong password", password: "wrong", wantErr: ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			accounts := mocks.NewMockaccountChecker(gomock.NewController(t))
+			if tt.checked {
+				accounts.EXPECT().Active(gomock.Any(), "jdoe").Return(tt.active, tt.err)
+			}
+
+			s, err := New(
+				WithIssuer(mocks.NewMocktokenIssuer(gomock.NewController(t))),
+				WithAccounts(accounts),
+				WithURL(d.url()),
+				WithBaseDN(baseDN),
+				WithGroupRoles(map[string][]string{adminsDN: {RoleOperator}}),
+			)
+			require.NoError(t, err)
+
+			identity, err := s.Authenticate(t.Context(), "jdoe", tt.password)
+
+			switch {
+			case tt.wantErr != nil:
+				require.ErrorIs(t, err, tt.wantErr)
+			case tt.err != nil:
+				require.ErrorIs(t, err, tt.err)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, "jdoe", identity.Username)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ldap.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	token "auth-service/internal/service/token"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MocktokenIssuer is a mock of tokenIssuer interface.
+type MocktokenIssuer struct {
+	ctrl     *gomock.Controller
+	recorder *MocktokenIssuerMockRecorder
+}
+
+// MocktokenIssuerMockRecorder is the mock recorder for MocktokenIssuer.
+type MocktokenIssuerMockRecorder struct {
+	mock *MocktokenIssuer
+}
+
+// NewMocktokenIssuer creates a new mock instance.
+func NewMocktokenIssuer(ctrl *gomock.Controller) *MocktokenIssuer {
+	mock := &MocktokenIssuer{ctrl: ctrl}
+	mock.recorder = &MocktokenIssuerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktokenIssuer) EXPECT() *MocktokenIssuerMockRecorder {
+	return m.recorder
+}
+
+// Issue mocks base method.
+func (m *MocktokenIssuer) Issue(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Issue", ctx, req)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*token.Claims)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Issue indicates an expected call of Issue.
+func (mr *MocktokenIssuerMockRecorder) Issue(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Issue", reflect.TypeOf((*MocktokenIssuer)(nil).Issue), ctx, req)
+}
+
+// MockaccountChecker is a mock of accountChecker interface.
+type MockaccountChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockaccountCheckerMockRecorder
+}
+
+// MockaccountCheckerMockRecorder is the mock recorder for MockaccountChecker.
+type MockaccountCheckerMockRecorder struct {
+	mock *MockaccountChecker
+}
+
+// NewMockaccountChecker creates a new mock instance.
+func NewMockaccountChecker(ctrl *gomock.Controller) *MockaccountChecker {
+	mock := &MockaccountChecker{ctrl: ctrl}
+	mock.recorder = &MockaccountCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockaccountChecker) EXPECT() *MockaccountCheckerMockRecorder {
+	return m.recorder
+}
+
+// Active mocks base method.
+func (m *MockaccountChecker) Active(ctx context.Context, username string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Active", ctx, username)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Active indicates an expected call of Active.
+func (mr *MockaccountCheckerMockRecorder) Active(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Active", reflect.TypeOf((*MockaccountChecker)(nil).Active), ctx, username)
+}
@@ -0,0 +1,185 @@
+// Package lifecycle отслеживает состояние фоновых компонентов сервиса (циклов перечитывания,
+// проверок зависимостей, HTTP сервера): запущен ли компонент, сколько раз перезапускался
+// и с какой ошибкой завершился. Состояние дублируется в метрики, чтобы можно было настроить
+// алерт на тихо умерший фоновый цикл.
+package lifecycle
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// State - состояние компонента.
+type State string
+
+const (
+	StateStarting State = "starting"
+	StateRunning  State = "running"
+	StateStopped  State = "stopped"
+	StateFailed   State = "failed"
+)
+
+// states - все состояния, для каждого выставляется gauge 0 или 1.
+var states = []State{StateStarting, StateRunning, StateStopped, StateFailed}
+
+// Component - состояние компонента.
+type Component struct {
+	Name        string     `json:"name"`
+	State       State      `json:"state"`
+	Restarts    uint64     `json:"restarts"`
+	Since       time.Time  `json:"since"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
+
+// Tracker - состояние компонентов.
+type Tracker struct {
+	registerer prometheus.Registerer
+
+	state    *prometheus.GaugeVec
+	restarts *prometheus.CounterVec
+	failures *prometheus.CounterVec
+
+	mu         sync.Mutex
+	components map[string]*Component
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Tracker.
+type Option func(*Tracker)
+
+// WithRegisterer устанавливает реестр метрик. По умолчанию используется prometheus.DefaultRegisterer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(t *Tracker) {
+		t.registerer = registerer
+	}
+}
+
+// New создает новый Tracker и регистрирует его метрики.
+func New(opts ...Option) (*Tracker, error) {
+	t := &Tracker{
+		registerer: prometheus.DefaultRegisterer,
+		components: map[string]*Component{},
+		now:        time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	if t.registerer == nil {
+		return nil, errors.New("registerer is required")
+	}
+
+	t.state = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "auth_component_state",
+		Help: "Состояние компонента: 1 для текущего состояния (starting, running, stopped, failed), 0 для остальных.",
+	}, []string{"component", "state"})
+
+	t.restarts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_component_restarts_total",
+		Help: "Количество повторных запусков компонента.",
+	}, []string{"component"})
+
+	t.failures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_component_failures_total",
+		Help: "Количество завершений компонента с ошибкой.",
+	}, []string{"component"})
+
+	for _, c := range []prometheus.Collector{t.state, t.restarts, t.failures} {
+		if err := t.registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// Starting отмечает запуск компонента. Повторный запуск компонента с тем же именем считается перезапуском.
+func (t *Tracker) Starting(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c, ok := t.components[name]
+	if !ok {
+		c = &Component{Name: name}
+		t.components[name] = c
+	} else {
+		c.Restarts++
+		t.restarts.WithLabelValues(name).Inc()
+	}
+
+	t.set(c, StateStarting)
+}
+
+// Running отмечает, что компонент работает.
+func (t *Tracker) Running(name string) {
+	t.transition(name, StateRunning, nil)
+}
+
+// Stopped отмечает штатное завершение компонента.
+func (t *Tracker) Stopped(name string) {
+	t.transition(name, StateStopped, nil)
+}
+
+// Failed отмечает завершение компонента с ошибкой.
+func (t *Tracker) Failed(name string, err error) {
+	t.transition(name, StateFailed, err)
+}
+
+// Components возвращает состояние компонентов, отсортированное по имени.
+func (t *Tracker) Components() []Component {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	res := make([]Component, 0, len(t.components))
+	for _, c := range t.components {
+		res = append(res, *c)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+
+	return res
+}
+
+func (t *Tracker) transition(name string, state State, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c, ok := t.components[name]
+	if !ok {
+		c = &Component{Name: name}
+		t.components[name] = c
+	}
+
+	if err != nil {
+		at := t.now()
+
+		c.LastError = err.Error()
+		c.LastErrorAt = &at
+
+		t.failures.WithLabelValues(name).Inc()
+	}
+
+	t.set(c, state)
+}
+
+// set меняет состояние компонента. Вызывается под mu.
+func (t *Tracker) set(c *Component, state State) {
+	c.State = state
+	c.Since = t.now()
+
+	for _, s := range states {
+		value := 0.0
+		if s == state {
+			value = 1
+		}
+
+		t.state.WithLabelValues(c.Name, string(s)).Set(value)
+	}
+}
@@ -0,0 +1,66 @@
+package lifecycle
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(WithRegisterer(nil))
+	require.ErrorContains(t, err, "registerer is required")
+
+	registry := prometheus.NewRegistry()
+
+	_, err = New(WithRegisterer(registry))
+	require.NoError(t, err)
+
+	_, err = New(WithRegisterer(registry))
+	require.Error(t, err)
+}
+
+func TestTracker(t *testing.T) {
+	t.Parallel()
+
+	tracker, err := New(WithRegisterer(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+
+	tracker.Starting("server")
+	tracker.Running("server")
+	tracker.Starting("rotation")
+	tracker.Running("rotation")
+	tracker.Failed("rotation", errors.New("vault is sealed"))
+
+	assert.Equal(t, []Component{
+		{Name: "rotation", State: StateFailed, Since: now, LastError: "vault is sealed", LastErrorAt: &now},
+		{Name: "server", State: StateRunning, Since: now},
+	}, tracker.Components())
+
+	assert.InDelta(t, 1, testutil.ToFloat64(tracker.state.WithLabelValues("rotation", "failed")), 0)
+	assert.InDelta(t, 0, testutil.ToFloat64(tracker.state.WithLabelValues("rotation", "running")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(tracker.failures.WithLabelValues("rotation")), 0)
+
+	// перезапуск сохраняет последнюю ошибку
+	tracker.Starting("rotation")
+	tracker.Running("rotation")
+
+	got := tracker.Components()[0]
+	assert.Equal(t, StateRunning, got.State)
+	assert.Equal(t, uint64(1), got.Restarts)
+	assert.Equal(t, "vault is sealed", got.LastError)
+	assert.InDelta(t, 1, testutil.ToFloat64(tracker.restarts.WithLabelValues("rotation")), 0)
+
+	tracker.Stopped("server")
+	assert.Equal(t, StateStopped, tracker.Components()[1].State)
+	assert.InDelta(t, 1, testutil.ToFloat64(tracker.state.WithLabelValues("server", "stopped")), 0)
+}
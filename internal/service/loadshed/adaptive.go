@@ -0,0 +1,103 @@
+package loadshed
+
+import (
+	"math"
+	"time"
+)
+
+// Значения адаптивного ограничения по умолчанию.
+const (
+	DefaultTolerance = 2.0
+	DefaultBackoff   = 0.9
+
+	// веса измерений в текущей и обычной задержке: обычная меняется медленно, чтобы
+	// кратковременный рост задержки не становился нормой
+	shortWeight    = 0.2
+	baselineWeight = 0.01
+)
+
+// Adaptive - адаптивное ограничение группы по задержке зависимости (AIMD). Пока текущая задержка
+// не превышает обычную более чем в Tolerance раз, ограничение растет на 1 после каждого измерения
+// до MaxInFlight группы. Когда задержка растет или зависимость отвечает ошибкой, ограничение
+// умножается на Backoff, но не опускается ниже MinInFlight.
+type Adaptive struct {
+	// MinInFlight - нижняя граница ограничения, по умолчанию 1.
+	MinInFlight int
+	// Tolerance - во сколько раз текущая задержка может превышать обычную, по умолчанию DefaultTolerance.
+	Tolerance float64
+	// Backoff - множитель снижения ограничения, по умолчанию DefaultBackoff.
+	Backoff float64
+}
+
+func (a Adaptive) withDefaults() Adaptive {
+	if a.MinInFlight < 1 {
+		a.MinInFlight = 1
+	}
+
+	if a.Tolerance == 0 {
+		a.Tolerance = DefaultTolerance
+	}
+
+	if a.Backoff == 0 {
+		a.Backoff = DefaultBackoff
+	}
+
+	return a
+}
+
+type adaptiveState struct {
+	config Adaptive
+
+	short    time.Duration // текущая задержка
+	baseline time.Duration // обычная задержка
+	limit    float64       // дробное ограничение, чтобы снижение на малых значениях не застревало
+}
+
+// Observer возвращает функцию, которой сообщается задержка и ошибка каждого обращения к зависимости
+// группы. Для групп без адаптивного ограничения функция ничего не делает.
+func (s *Shedder) Observer(name string) func(latency time.Duration, err error) {
+	g, ok := s.groups[name]
+	if !ok || g.adaptive == nil {
+		return func(time.Duration, error) {}
+	}
+
+	return func(latency time.Duration, err error) {
+		g.mu.Lock()
+		short, baseline, limit := g.adapt(latency, err)
+		g.mu.Unlock()
+
+		s.limit.WithLabelValues(name).Set(float64(limit))
+		s.latency.WithLabelValues(name, "short").Set(short.Seconds())
+		s.latency.WithLabelValues(name, "baseline").Set(baseline.Seconds())
+	}
+}
+
+// adapt пересчитывает ограничение по измерению. Вызывается под мьютексом.
+// Возвращает текущую и обычную задержку и новое ограничение.
+func (g *group) adapt(latency time.Duration, err error) (time.Duration, time.Duration, int) {
+	a := g.adaptive
+
+	if a.limit == 0 {
+		a.limit = float64(g.current)
+	}
+
+	overloaded := err != nil
+
+	if !overloaded {
+		a.short = ewma(a.short, latency, shortWeight)
+		a.baseline = ewma(a.baseline, latency, baselineWeight)
+		overloaded = float64(a.short) > a.config.Tolerance*float64(a.baseline)
+	}
+
+	if overloaded {
+		a.limit = math.Max(float64(a.config.MinInFlight), a.limit*a.config.Backoff)
+	} else {
+		a.limit = math.Min(float64(g.limit.MaxInFlight), a.limit+1)
+	}
+
+	g.current = int(a.limit)
+	// ограничение выросло - свободные места достаются ожидающим
+	g.wake()
+
+	return a.short, a.baseline, g.current
+}
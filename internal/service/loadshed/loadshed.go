@@ -0,0 +1,310 @@
+// Package loadshed сбрасывает нагрузку при перегрузке: ограничивает количество одновременно
+// обрабатываемых запросов в группе эндпоинтов и сразу отклоняет запросы, которые не успеют
+// дождаться обработки за бюджет ожидания. Клиент быстро получает отказ вместо ответа по таймауту,
+// а сервис не тратит ресурсы на запросы, которые уже никому не нужны.
+//
+// Ограничение группы может быть адаптивным (см. Adaptive): оно снижается, когда растет задержка
+// зависимости, от которой зависят запросы группы, и постепенно восстанавливается, когда задержка
+// возвращается к обычной. Так перегруженная зависимость не получает еще больше запросов.
+package loadshed
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ewmaWeight - вес нового измерения в скользящем среднем времени обработки.
+const ewmaWeight = 0.1
+
+// Причины отказа.
+const (
+	ReasonQueueFull = "queue_full"
+	ReasonBudget    = "budget"
+	ReasonTimeout   = "timeout"
+)
+
+// ErrOverloaded - запрос отклонен из-за перегрузки группы.
+var ErrOverloaded = errors.New("overloaded")
+
+// Limit - ограничение группы эндпоинтов.
+type Limit struct {
+	// MaxInFlight - сколько запросов группы обрабатываются одновременно.
+	MaxInFlight int
+	// MaxQueue - сколько запросов могут ждать обработки, 0 - запросы сверх MaxInFlight сразу отклоняются.
+	MaxQueue int
+	// Budget - сколько запрос может ждать обработки.
+	Budget time.Duration
+	// Adaptive - адаптивное ограничение, nil - ограничение постоянное. MaxInFlight - его верхняя граница.
+	Adaptive *Adaptive
+}
+
+// RejectedError - отказ в обработке запроса.
+type RejectedError struct {
+	Group  string
+	Reason string
+	// RetryAfter - через сколько имеет смысл повторить запрос.
+	RetryAfter time.Duration
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("%s: group %q, %s", ErrOverloaded, e.Group, e.Reason)
+}
+
+func (e *RejectedError) Unwrap() error {
+	return ErrOverloaded
+}
+
+type group struct {
+	name  string
+	limit Limit
+
+	mu       sync.Mutex
+	current  int        // действующее ограничение одновременных запросов
+	inFlight int        // обрабатываемые запросы
+	waiters  *list.List // ожидающие запросы, chan struct{} закрывается, когда запросу передано место
+	avg      time.Duration
+
+	adaptive *adaptiveState
+}
+
+// Shedder - сброс нагрузки по группам эндпоинтов.
+type Shedder struct {
+	limits map[string]Limit
+	groups map[string]*group
+
+	registerer prometheus.Registerer
+	rejected   *prometheus.CounterVec
+	inFlight   *prometheus.GaugeVec
+	limit      *prometheus.GaugeVec
+	latency    *prometheus.GaugeVec
+}
+
+// Option - опция для настройки Shedder.
+type Option func(*Shedder)
+
+// WithLimits устанавливает ограничения групп. Запросы групп без ограничения не ограничиваются.
+func WithLimits(limits map[string]Limit) Option {
+	return func(s *Shedder) {
+		s.limits = limits
+	}
+}
+
+// WithRegisterer устанавливает реестр метрик. По умолчанию используется prometheus.DefaultRegisterer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(s *Shedder) {
+		s.registerer = registerer
+	}
+}
+
+// New создает новый Shedder и регистрирует его метрики.
+func New(opts ...Option) (*Shedder, error) {
+	s := &Shedder{registerer: prometheus.DefaultRegisterer}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.registerer == nil {
+		return nil, errors.New("registerer is required")
+	}
+
+	s.groups = make(map[string]*group, len(s.limits))
+
+	for name, limit := range s.limits {
+		g, err := newGroup(name, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		s.groups[name] = g
+	}
+
+	s.rejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_loadshed_rejected_total",
+		Help: "Количество запросов, отклоненных из-за перегрузки, по группам и причинам (queue_full, budget, timeout).",
+	}, []string{"group", "reason"})
+
+	s.inFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "auth_loadshed_in_flight",
+		Help: "Количество запросов, которые обрабатываются в группе.",
+	}, []string{"group"})
+
+	s.limit = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "auth_loadshed_limit",
+		Help: "Действующее ограничение одновременно обрабатываемых запросов группы.",
+	}, []string{"group"})
+
+	s.latency = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "auth_loadshed_dependency_latency_seconds",
+		Help: "Задержка зависимости, по которой адаптируется ограничение группы: текущая (short) и обычная (baseline).",
+	}, []string{"group", "window"})
+
+	for _, c := range []prometheus.Collector{s.rejected, s.inFlight, s.limit, s.latency} {
+		if err := s.registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, g := range s.groups {
+		s.limit.WithLabelValues(name).Set(float64(g.current))
+	}
+
+	return s, nil
+}
+
+func newGroup(name string, limit Limit) (*group, error) {
+	if limit.MaxInFlight < 1 || limit.MaxQueue < 0 || limit.Budget <= 0 {
+		return nil, fmt.Errorf("invalid limit of group %q: max in flight and budget must be positive", name)
+	}
+
+	g := &group{name: name, limit: limit, current: limit.MaxInFlight, waiters: list.New()}
+
+	if limit.Adaptive != nil {
+		a := limit.Adaptive.withDefaults()
+		if a.MinInFlight > limit.MaxInFlight || a.Tolerance <= 1 || a.Backoff <= 0 || a.Backoff >= 1 {
+			return nil, fmt.Errorf("invalid adaptive limit of group %q: min in flight must not exceed max, tolerance must be > 1, backoff in (0, 1)", name)
+		}
+
+		g.adaptive = &adaptiveState{config: a}
+	}
+
+	return g, nil
+}
+
+// Acquire занимает место для обработки запроса группы. Если мест нет, запрос ждет в очереди,
+// пока ожидаемое время ожидания укладывается в бюджет. Возвращает функцию, которую нужно вызвать
+// после обработки запроса, или *RejectedError, если запрос нужно отклонить.
+func (s *Shedder) Acquire(ctx context.Context, name string) (func(), error) {
+	g, ok := s.groups[name]
+	if !ok {
+		return func() {}, nil
+	}
+
+	g.mu.Lock()
+
+	if g.inFlight < g.current {
+		g.inFlight++
+		g.mu.Unlock()
+
+		return s.release(g), nil
+	}
+
+	queued := g.waiters.Len() + 1
+	if queued > g.limit.MaxQueue {
+		g.mu.Unlock()
+
+		return nil, s.reject(g, ReasonQueueFull)
+	}
+
+	if g.estimate(queued) > g.limit.Budget {
+		g.mu.Unlock()
+
+		return nil, s.reject(g, ReasonBudget)
+	}
+
+	ready := make(chan struct{})
+	waiter := g.waiters.PushBack(ready)
+	g.mu.Unlock()
+
+	timer := time.NewTimer(g.limit.Budget)
+	defer timer.Stop()
+
+	var err error
+
+	select {
+	case <-ready:
+		return s.release(g), nil
+	case <-timer.C:
+		err = s.reject(g, ReasonTimeout)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	select {
+	case <-ready:
+		// место передано одновременно с отказом - возвращаем его следующему
+		g.inFlight--
+		g.wake()
+	default:
+		g.waiters.Remove(waiter)
+	}
+
+	return nil, err
+}
+
+// release учитывает начало обработки и возвращает функцию ее завершения.
+func (s *Shedder) release(g *group) func() {
+	s.inFlight.WithLabelValues(g.name).Inc()
+
+	start := time.Now()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			s.inFlight.WithLabelValues(g.name).Dec()
+
+			g.mu.Lock()
+			defer g.mu.Unlock()
+
+			g.observe(time.Since(start))
+			g.inFlight--
+			g.wake()
+		})
+	}
+}
+
+func (s *Shedder) reject(g *group, reason string) error {
+	s.rejected.WithLabelValues(g.name, reason).Inc()
+
+	return &RejectedError{Group: g.name, Reason: reason, RetryAfter: max(g.average(), g.limit.Budget)}
+}
+
+// wake передает свободные места ожидающим запросам. Вызывается под мьютексом.
+func (g *group) wake() {
+	for g.inFlight < g.current && g.waiters.Len() > 0 {
+		ready, _ := g.waiters.Remove(g.waiters.Front()).(chan struct{})
+		g.inFlight++
+		close(ready)
+	}
+}
+
+// estimate оценивает, сколько будет ждать запрос, перед которым в очереди queued-1 запросов:
+// каждые current запросов очереди освобождают места за среднее время обработки.
+// Вызывается под мьютексом.
+func (g *group) estimate(queued int) time.Duration {
+	rounds := math.Ceil(float64(queued) / float64(g.current))
+
+	return time.Duration(rounds) * g.avg
+}
+
+func (g *group) average() time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.avg
+}
+
+// observe учитывает время обработки запроса. Вызывается под мьютексом.
+func (g *group) observe(d time.Duration) {
+	g.avg = ewma(g.avg, d, ewmaWeight)
+}
+
+// ewma возвращает скользящее среднее с новым измерением d. Первое измерение становится средним.
+func ewma(avg, d time.Duration, weight float64) time.Duration {
+	if avg == 0 {
+		return d
+	}
+
+	return time.Duration(weight*float64(d) + (1-weight)*float64(avg))
+}
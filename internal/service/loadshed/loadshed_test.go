@@ -0,0 +1,239 @@
+package loadshed
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newShedder(t *testing.T, limits map[string]Limit) *Shedder {
+	t.Helper()
+
+	s, err := New(WithLimits(limits), WithRegisterer(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	return s
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{
+			name: "positive case",
+			opts: []Option{WithRegisterer(prometheus.NewRegistry()), WithLimits(map[string]Limit{
+				"issuance": {MaxInFlight: 1, Budget: time.Second},
+			})},
+		},
+		{
+			name:    "error case: no registerer",
+			opts:    []Option{WithRegisterer(nil)},
+			wantErr: true,
+		},
+		{
+			name: "error case: no budget",
+			opts: []Option{WithRegisterer(prometheus.NewRegistry()), WithLimits(map[string]Limit{
+				"issuance": {MaxInFlight: 1},
+			})},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(tt.opts...)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestShedder_Acquire(t *testing.T) {
+	t.Parallel()
+
+	s := newShedder(t, map[string]Limit{"issuance": {MaxInFlight: 1, MaxQueue: 1, Budget: 50 * time.Millisecond}})
+
+	release, err := s.Acquire(t.Context(), "issuance")
+	require.NoError(t, err)
+
+	// группа без ограничения не ограничивается
+	other, err := s.Acquire(t.Context(), "session")
+	require.NoError(t, err)
+	other()
+
+	// место не освободилось за бюджет
+	_, err = s.Acquire(t.Context(), "issuance")
+
+	var rejected *RejectedError
+
+	require.ErrorAs(t, err, &rejected)
+	assert.Equal(t, ReasonTimeout, rejected.Reason)
+	require.ErrorIs(t, err, ErrOverloaded)
+
+	// место освобождается, пока запрос ждет в очереди
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		release()
+	}()
+
+	release, err = s.Acquire(t.Context(), "issuance")
+	require.NoError(t, err)
+
+	release()
+	release() // повторный вызов ничего не делает
+
+	assert.InDelta(t, 0, testutil.ToFloat64(s.inFlight.WithLabelValues("issuance")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(s.rejected.WithLabelValues("issuance", ReasonTimeout)), 0)
+}
+
+func TestShedder_Acquire_Shed(t *testing.T) {
+	t.Parallel()
+
+	s := newShedder(t, map[string]Limit{"issuance": {MaxInFlight: 1, MaxQueue: 1, Budget: 50 * time.Millisecond}})
+
+	release, err := s.Acquire(t.Context(), "issuance")
+	require.NoError(t, err)
+
+	t.Cleanup(release)
+
+	// очередь занята
+	waiter := s.groups["issuance"].waiters.PushBack(make(chan struct{}))
+
+	_, err = s.Acquire(t.Context(), "issuance")
+	assert.Equal(t, ReasonQueueFull, reason(t, err))
+
+	s.groups["issuance"].waiters.Remove(waiter)
+
+	// запросы обрабатываются дольше бюджета - ждать бессмысленно
+	s.groups["issuance"].observe(time.Second)
+
+	start := time.Now()
+
+	_, err = s.Acquire(t.Context(), "issuance")
+	assert.Equal(t, ReasonBudget, reason(t, err))
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	// запрос отменен клиентом
+	s.groups["issuance"].avg = 0
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, err = s.Acquire(ctx, "issuance")
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func reason(t *testing.T, err error) string {
+	t.Helper()
+
+	var rejected *RejectedError
+	if !errors.As(err, &rejected) {
+		t.Fatalf("expected rejection, got %v", err)
+	}
+
+	return rejected.Reason
+}
+
+func TestGroup_Estimate(t *testing.T) {
+	t.Parallel()
+
+	g := &group{limit: Limit{MaxInFlight: 4}, current: 4}
+	assert.Equal(t, time.Duration(0), g.estimate(10))
+
+	g.observe(100 * time.Millisecond)
+	g.observe(200 * time.Millisecond)
+	assert.Equal(t, 110*time.Millisecond, g.average())
+
+	assert.Equal(t, 110*time.Millisecond, g.estimate(4))
+	assert.Equal(t, 220*time.Millisecond, g.estimate(5))
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestShedder_Observer(t *testing.T) {
+	t.Parallel()
+
+	s := newShedder(t, map[string]Limit{
+		"issuance": {MaxInFlight: 10, MaxQueue: 10, Budget: time.Second, Adaptive: &Adaptive{MinInFlight: 2}},
+		"session":  {MaxInFlight: 10, Budget: time.Second},
+	})
+
+	observe := s.Observer("issuance")
+	g := s.groups["issuance"]
+
+	// группа без адаптивного ограничения и неизвестная группа не меняются
+	s.Observer("session")(time.Second, errors.New("vault is down"))
+	s.Observer("unknown")(time.Second, nil)
+	assert.Equal(t, 10, s.groups["session"].current)
+
+	// задержка обычная - ограничение на максимуме
+	for range 20 {
+		observe(10*time.Millisecond, nil)
+	}
+
+	assert.Equal(t, 10, g.current)
+
+	// задержка выросла - ограничение снижается до минимума
+	for range 30 {
+		observe(time.Second, nil)
+	}
+
+	assert.Equal(t, 2, g.current)
+	assert.InDelta(t, 2, testutil.ToFloat64(s.limit.WithLabelValues("issuance")), 0)
+	assert.Positive(t, testutil.ToFloat64(s.latency.WithLabelValues("issuance", "short")))
+
+	// заняты оба места, третий запрос ждет в очереди
+	first, err := s.Acquire(t.Context(), "issuance")
+	require.NoError(t, err)
+
+	second, err := s.Acquire(t.Context(), "issuance")
+	require.NoError(t, err)
+
+	acquired := make(chan func())
+
+	go func() {
+		release, err := s.Acquire(t.Context(), "issuance")
+		assert.NoError(t, err)
+
+		acquired <- release
+	}()
+
+	assert.Eventually(t, func() bool {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		return g.waiters.Len() == 1
+	}, time.Second, time.Millisecond)
+
+	// задержка вернулась к обычной - ограничение растет и ожидающий запрос получает место
+	for range 30 {
+		observe(10*time.Millisecond, nil)
+	}
+
+	third := <-acquired
+
+	// ошибки зависимости снижают ограничение
+	observe(10*time.Millisecond, errors.New("vault is down"))
+	assert.Equal(t, 9, g.current)
+
+	for _, release := range []func(){first, second, third} {
+		release()
+	}
+
+	assert.Equal(t, 0, g.inFlight)
+}
@@ -0,0 +1,191 @@
+// Package logsampling управляет выборочным логированием запросов к шумным маршрутам.
+// Доли логируемых запросов меняются в рантайме через административное API и хранятся в Redis,
+// поэтому применяются на всех экземплярах сервиса.
+package logsampling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// redisKey - hash шаблон маршрута -> доля логируемых запросов.
+	redisKey = "auth:log-sampling"
+
+	defaultReloadInterval = 10 * time.Second
+)
+
+// Sampler - доли логируемых запросов по шаблонам маршрутов.
+// Маршруты без заданной доли логируются полностью.
+type Sampler struct {
+	client         redis.UniversalClient
+	reloadInterval time.Duration
+
+	mu    sync.RWMutex
+	rates map[string]float64
+
+	// sample возвращает случайное число в [0, 1). Подменяется в тестах.
+	sample func() float64
+}
+
+// Option - опция для настройки Sampler.
+type Option func(*Sampler)
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(s *Sampler) {
+		s.client = client
+	}
+}
+
+// WithReloadInterval устанавливает периодичность перечитывания настроек из Redis. По умолчанию 10s.
+func WithReloadInterval(interval time.Duration) Option {
+	return func(s *Sampler) {
+		s.reloadInterval = interval
+	}
+}
+
+// New создает новый Sampler. До первой загрузки из Redis логируются все запросы.
+func New(opts ...Option) (*Sampler, error) {
+	s := &Sampler{
+		reloadInterval: defaultReloadInterval,
+		rates:          map[string]float64{},
+		sample:         rand.Float64, //nolint:gosec // криптостойкость для выборки не нужна
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.client == nil {
+		return nil, errors.New("redis client is required")
+	}
+
+	if s.reloadInterval <= 0 {
+		return nil, errors.New("reload interval must be positive")
+	}
+
+	return s, nil
+}
+
+// Rates возвращает копию текущих долей.
+func (s *Sampler) Rates() map[string]float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return maps.Clone(s.rates)
+}
+
+// ShouldLog решает, нужно ли писать в лог запрос к маршруту.
+func (s *Sampler) ShouldLog(route string) bool {
+	s.mu.RLock()
+	rate, ok := s.rates[route]
+	s.mu.RUnlock()
+
+	if !ok || rate >= 1 {
+		return true
+	}
+
+	if rate <= 0 {
+		return false
+	}
+
+	return s.sample() < rate
+}
+
+// Update заменяет доли логируемых запросов и сохраняет их в Redis.
+func (s *Sampler) Update(ctx context.Context, rates map[string]float64) error {
+	values := make([]any, 0, 2*len(rates))
+
+	for route, rate := range rates {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("rate for route %s must be between 0 and 1", route)
+		}
+
+		values = append(values, route, strconv.FormatFloat(rate, 'f', -1, 64))
+	}
+
+	_, err := s.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.Del(ctx, redisKey)
+
+		if len(values) > 0 {
+			p.HSet(ctx, redisKey, values...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("logsampling: error save rates: %w", err)
+	}
+
+	s.set(maps.Clone(rates))
+
+	return nil
+}
+
+// Reset удаляет все доли: снова логируются все запросы.
+func (s *Sampler) Reset(ctx context.Context) error {
+	return s.Update(ctx, nil)
+}
+
+// Load перечитывает доли из Redis.
+func (s *Sampler) Load(ctx context.Context) error {
+	raw, err := s.client.HGetAll(ctx, redisKey).Result()
+	if err != nil {
+		return fmt.Errorf("logsampling: error load rates: %w", err)
+	}
+
+	rates := make(map[string]float64, len(raw))
+
+	for route, value := range raw {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("logsampling: invalid rate %q for route %s", value, route)
+		}
+
+		rates[route] = rate
+	}
+
+	s.set(rates)
+
+	return nil
+}
+
+// Start загружает доли и перечитывает их с заданной периодичностью, чтобы изменения,
+// сделанные через другой экземпляр, применялись везде. Ошибки загрузки пишутся в лог,
+// при этом остаются предыдущие доли. Завершается при отмене контекста.
+func (s *Sampler) Start(ctx context.Context) error {
+	ticker := time.NewTicker(s.reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Load(ctx); err != nil && ctx.Err() == nil {
+			logrus.WithError(err).Warn("error reload log sampling rates")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Sampler) set(rates map[string]float64) {
+	if rates == nil {
+		rates = map[string]float64{}
+	}
+
+	s.mu.Lock()
+	s.rates = rates
+	s.mu.Unlock()
+}
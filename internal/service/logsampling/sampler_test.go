@@ -0,0 +1,105 @@
+package logsampling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSampler(t *testing.T, opts ...Option) (*Sampler, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	s, err := New(append([]Option{WithClient(client)}, opts...)...)
+	require.NoError(t, err)
+
+	return s, mr
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	_, err := New()
+	require.Error(t, err)
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	t.Cleanup(func() { _ = client.Close() })
+
+	_, err = New(WithClient(client), WithReloadInterval(0))
+	require.Error(t, err)
+}
+
+func TestShouldLog(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newSampler(t)
+	s.sample = func() float64 { return 0.5 }
+
+	require.NoError(t, s.Update(t.Context(), map[string]float64{
+		"/api/v0/token/introspect": 0.01,
+		"/api/v0/authz/check":      0.9,
+		"/api/v0/health":           0,
+		"/metrics":                 1,
+	}))
+
+	assert.False(t, s.ShouldLog("/api/v0/token/introspect"))
+	assert.True(t, s.ShouldLog("/api/v0/authz/check"))
+	assert.False(t, s.ShouldLog("/api/v0/health"))
+	assert.True(t, s.ShouldLog("/metrics"))
+	assert.True(t, s.ShouldLog("/api/v0/admin/capture"))
+}
+
+func TestUpdate(t *testing.T) {
+	t.Parallel()
+
+	s, mr := newSampler(t)
+	ctx := t.Context()
+
+	require.ErrorContains(t, s.Update(ctx, map[string]float64{"/x": 2}), "must be between 0 and 1")
+
+	require.NoError(t, s.Update(ctx, map[string]float64{"/x": 0.25}))
+	assert.Equal(t, "0.25", mr.HGet(redisKey, "/x"))
+	assert.Equal(t, map[string]float64{"/x": 0.25}, s.Rates())
+
+	// другой экземпляр видит изменения после загрузки
+	other, err := New(WithClient(s.client))
+	require.NoError(t, err)
+	require.NoError(t, other.Load(ctx))
+	assert.Equal(t, map[string]float64{"/x": 0.25}, other.Rates())
+
+	require.NoError(t, s.Reset(ctx))
+	assert.False(t, mr.Exists(redisKey))
+	assert.Empty(t, s.Rates())
+
+	mr.HSet(redisKey, "/y", "often")
+	require.ErrorContains(t, s.Load(ctx), "invalid rate")
+}
+
+func TestStart(t *testing.T) {
+	t.Parallel()
+
+	s, mr := newSampler(t, WithReloadInterval(10*time.Millisecond))
+	mr.HSet(redisKey, "/x", "0.5")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+
+	go func() { done <- s.Start(ctx) }()
+
+	require.Eventually(t, func() bool { return s.Rates()["/x"] == 0.5 }, time.Second, 5*time.Millisecond)
+
+	mr.HSet(redisKey, "/x", "0.1")
+	require.Eventually(t, func() bool { return s.Rates()["/x"] == 0.1 }, time.Second, 5*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
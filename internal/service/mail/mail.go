@@ -0,0 +1,261 @@
+// Package mail отправляет письма через SMTP. Если сервер поддерживает STARTTLS, соединение
+// шифруется. Логин и пароль SMTP хранятся в Vault и читаются на каждую отправку, поэтому
+// ротация не требует перезапуска. Без STARTTLS учетные данные не отправляются.
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout - таймаут отправки письма.
+const DefaultTimeout = 10 * time.Second
+
+// ErrInvalidArgument - не заполнены обязательные параметры письма.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+//go:generate mockgen -source=mail.go -destination=mocks/mail_mock.go -package=mocks
+type secretReader interface {
+	ReadKV(ctx context.Context, path string) (map[string]interface{}, error)
+}
+
+// Message - письмо.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender - отправка писем через SMTP.
+type Sender struct {
+	addr    string
+	from    string
+	timeout time.Duration
+	// sender - адрес отправителя без имени для команды MAIL FROM.
+	sender string
+
+	secrets         secretReader
+	credentialsPath string
+
+	tlsConfig *tls.Config
+}
+
+// Option - опция для настройки Sender.
+type Option func(*Sender)
+
+// WithServer устанавливает адрес SMTP сервера в виде host:port.
+func WithServer(addr string) Option {
+	return func(s *Sender) {
+		s.addr = addr
+	}
+}
+
+// WithFrom устанавливает адрес отправителя.
+func WithFrom(from string) Option {
+	return func(s *Sender) {
+		s.from = from
+	}
+}
+
+// WithTimeout устанавливает таймаут отправки письма. По умолчанию DefaultTimeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *Sender) {
+		s.timeout = timeout
+	}
+}
+
+// WithCredentials устанавливает секрет Vault KV с username и password SMTP. Без него письма
+// отправляются без аутентификации.
+func WithCredentials(secrets secretReader, path string) Option {
+	return func(s *Sender) {
+		s.secrets = secrets
+		s.credentialsPath = path
+	}
+}
+
+// WithTLSConfig устанавливает настройки TLS для STARTTLS. По умолчанию проверяется сертификат host.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(s *Sender) {
+		s.tlsConfig = cfg
+	}
+}
+
+// New создает новый Sender.
+func New(opts ...Option) (*Sender, error) {
+	s := &Sender{timeout: DefaultTimeout}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	host, _, err := net.SplitHostPort(s.addr)
+	if err != nil || host == "" {
+		return nil, fmt.Errorf("invalid smtp server address %q", s.addr)
+	}
+
+	from, err := netmail.ParseAddress(s.from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address %q: %w", s.from, err)
+	}
+
+	s.sender = from.Address
+
+	if s.timeout <= 0 {
+		return nil, errors.New("timeout must be positive")
+	}
+
+	if s.secrets != nil && s.credentialsPath == "" {
+		return nil, errors.New("credentials path is required")
+	}
+
+	if s.tlsConfig == nil {
+		s.tlsConfig = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+	}
+
+	return s, nil
+}
+
+// Send отправляет письмо.
+func (s *Sender) Send(ctx context.Context, msg Message) error {
+	to, err := netmail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("%w: invalid recipient %q", ErrInvalidArgument, msg.To)
+	}
+
+	if msg.Subject == "" || msg.Body == "" {
+		return fmt.Errorf("%w: subject and body are required", ErrInvalidArgument)
+	}
+
+	auth, err := s.auth(ctx)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("mail: error connect to smtp server: %w", err)
+	}
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+
+		return fmt.Errorf("mail: error set deadline: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, s.tlsConfig.ServerName)
+	if err != nil {
+		_ = conn.Close()
+
+		return fmt.Errorf("mail: error start smtp session: %w", err)
+	}
+
+	defer client.Close()
+
+	if err := s.send(client, auth, to.Address, msg); err != nil {
+		return err
+	}
+
+	if err := client.Quit(); err != nil {
+		return fmt.Errorf("mail: error quit smtp session: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Sender) send(client *smtp.Client, auth smtp.Auth, to string, msg Message) error {
+	encrypted, _ := client.Extension("STARTTLS")
+	if encrypted {
+		if err := client.StartTLS(s.tlsConfig); err != nil {
+			return fmt.Errorf("mail: error starttls: %w", err)
+		}
+	}
+
+	if auth != nil {
+		// smtp.PlainAuth разрешает пароль без TLS для localhost, здесь - никогда
+		if !encrypted {
+			return errors.New("mail: smtp server does not support starttls, credentials are not sent")
+		}
+
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("mail: error authenticate: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.sender); err != nil {
+		return fmt.Errorf("mail: error set sender: %w", err)
+	}
+
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("mail: error set recipient: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("mail: error start data: %w", err)
+	}
+
+	if _, err := w.Write(s.format(to, msg)); err != nil {
+		_ = w.Close()
+
+		return fmt.Errorf("mail: error write message: %w", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("mail: error send message: %w", err)
+	}
+
+	return nil
+}
+
+// auth читает учетные данные SMTP из Vault.
+func (s *Sender) auth(ctx context.Context) (smtp.Auth, error) {
+	if s.secrets == nil {
+		return nil, nil //nolint:nilnil // аутентификация не настроена - не ошибка
+	}
+
+	data, err := s.secrets.ReadKV(ctx, s.credentialsPath)
+	if err != nil {
+		return nil, fmt.Errorf("mail: error read smtp credentials: %w", err)
+	}
+
+	username, _ := data["username"].(string)
+	password, _ := data["password"].(string)
+
+	if username == "" || password == "" {
+		return nil, errors.New("mail: smtp credentials must contain username and password")
+	}
+
+	return smtp.PlainAuth("", username, password, s.tlsConfig.ServerName), nil
+}
+
+// format собирает письмо в формате RFC 5322. Заголовки очищаются от переводов строк,
+// чтобы данные пользователя не могли добавить свои заголовки.
+func (s *Sender) format(to string, msg Message) []byte {
+	clean := strings.NewReplacer("\r", "", "\n", "")
+
+	var b strings.Builder
+
+	b.WriteString("From: " + s.from + "\r\n")
+	b.WriteString("To: " + to + "\r\n")
+	b.WriteString("Subject: " + mime.BEncoding.Encode("utf-8", clean.Replace(msg.Subject)) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+
+	return []byte(b.String())
+}
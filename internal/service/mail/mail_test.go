@@ -0,0 +1,222 @@
+package mail
+
+import (
+	"auth-service/internal/service/mail/mocks"
+	"bufio"
+	"errors"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpServer - тестовый SMTP сервер без STARTTLS и аутентификации.
+type smtpServer struct {
+	addr string
+
+	mu       sync.Mutex
+	commands []string
+	data     string
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	t.Helper()
+
+	l, err := (&net.ListenConfig{}).Listen(t.Context(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = l.Close() })
+
+	s := &smtpServer{addr: l.Addr().String()}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+
+	_ = tp.PrintfLine("220 localhost ESMTP")
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.commands = append(s.commands, line)
+		s.mu.Unlock()
+
+		switch verb := strings.ToUpper(strings.Fields(line)[0]); verb {
+		case "EHLO":
+			_ = tp.PrintfLine("250-localhost")
+			_ = tp.PrintfLine("250 8BITMIME")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+
+			data, _ := tp.ReadDotBytes()
+
+			s.mu.Lock()
+			s.data = string(data)
+			s.mu.Unlock()
+
+			_ = tp.PrintfLine("250 ok")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("250 ok")
+		}
+	}
+}
+
+func (s *smtpServer) received() ([]string, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.commands, s.data
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	secrets := mocks.NewMocksecretReader(gomock.NewController(t))
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{
+			name: "positive case",
+			opts: []Option{WithServer("smtp.example.com:587"), WithFrom("Auth <auth@example.com>")},
+		},
+		{
+			name: "positive case: credentials",
+			opts: []Option{
+				WithServer("smtp.example.com:587"),
+				WithFrom("auth@example.com"),
+				WithCredentials(secrets, "secret/data/auth/smtp"),
+			},
+		},
+		{
+			name:    "error case: invalid server",
+			opts:    []Option{WithServer("smtp.example.com"), WithFrom("auth@example.com")},
+			wantErr: true,
+		},
+		{
+			name:    "error case: invalid from",
+			opts:    []Option{WithServer("smtp.example.com:587"), WithFrom("auth")},
+			wantErr: true,
+		},
+		{
+			name:    "error case: invalid timeout",
+			opts:    []Option{WithServer("smtp.example.com:587"), WithFrom("auth@example.com"), WithTimeout(0)},
+			wantErr: true,
+		},
+		{
+			name: "error case: credentials path is empty",
+			opts: []Option{
+				WithServer("smtp.example.com:587"),
+				WithFrom("auth@example.com"),
+				WithCredentials(secrets, ""),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := New(tt.opts...)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, s)
+		})
+	}
+}
+
+func TestSender_Send(t *testing.T) {
+	t.Parallel()
+
+	server := newSMTPServer(t)
+
+	s, err := New(WithServer(server.addr), WithFrom("Auth <auth@example.com>"), WithTimeout(time.Second))
+	require.NoError(t, err)
+
+	require.NoError(t, s.Send(t.Context(), Message{
+		To:      "user@example.com",
+		Subject: "Смена почты\r\nBcc: evil@example.com",
+		Body:    "line 1\nline 2",
+	}))
+
+	commands, data := server.received()
+	assert.Contains(t, commands, "MAIL FROM:<auth@example.com> BODY=8BITMIME")
+	assert.Contains(t, commands, "RCPT TO:<user@example.com>")
+
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader(data)))
+	header, err := reader.ReadMIMEHeader()
+	require.NoError(t, err)
+
+	assert.Equal(t, "user@example.com", header.Get("To"))
+	assert.Equal(t, "=?utf-8?b?0KHQvNC10L3QsCDQv9C+0YfRgtGLQmNjOiBldmlsQGV4YW1wbGUuY29t?=", header.Get("Subject"))
+	assert.Empty(t, header.Get("Bcc"))
+	assert.Contains(t, data, "\n\nline 1\nline 2")
+}
+
+func TestSender_Send_Errors(t *testing.T) {
+	t.Parallel()
+
+	server := newSMTPServer(t)
+
+	secrets := mocks.NewMocksecretReader(gomock.NewController(t))
+
+	s, err := New(WithServer(server.addr), WithFrom("auth@example.com"), WithCredentials(secrets, "secret/data/auth/smtp"))
+	require.NoError(t, err)
+
+	err = s.Send(t.Context(), Message{To: "user", Subject: "s", Body: "b"})
+	require.ErrorIs(t, err, ErrInvalidArgument)
+
+	err = s.Send(t.Context(), Message{To: "user@example.com"})
+	require.ErrorIs(t, err, ErrInvalidArgument)
+
+	secrets.EXPECT().ReadKV(gomock.Any(), "secret/data/auth/smtp").Return(nil, errors.New("vault is down"))
+	require.Error(t, s.Send(t.Context(), Message{To: "user@example.com", Subject: "s", Body: "b"}))
+
+	secrets.EXPECT().ReadKV(gomock.Any(), "secret/data/auth/smtp").Return(map[string]interface{}{"username": "auth"}, nil)
+	require.Error(t, s.Send(t.Context(), Message{To: "user@example.com", Subject: "s", Body: "b"}))
+
+	// сервер без STARTTLS: пароль не отправляется открытым текстом
+	secrets.EXPECT().ReadKV(gomock.Any(), "secret/data/auth/smtp").
+		Return(map[string]interface{}{"username": "auth", "password": "secret"}, nil)
+	require.Error(t, s.Send(t.Context(), Message{To: "user@example.com", Subject: "s", Body: "b"}))
+
+	commands, _ := server.received()
+	for _, c := range commands {
+		assert.NotContains(t, c, "AUTH")
+	}
+}
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: mail.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MocksecretReader is a mock of secretReader interface.
+type MocksecretReader struct {
+	ctrl     *gomock.Controller
+	recorder *MocksecretReaderMockRecorder
+}
+
+// MocksecretReaderMockRecorder is the mock recorder for MocksecretReader.
+type MocksecretReaderMockRecorder struct {
+	mock *MocksecretReader
+}
+
+// NewMocksecretReader creates a new mock instance.
+func NewMocksecretReader(ctrl *gomock.Controller) *MocksecretReader {
+	mock := &MocksecretReader{ctrl: ctrl}
+	mock.recorder = &MocksecretReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocksecretReader) EXPECT() *MocksecretReaderMockRecorder {
+	return m.recorder
+}
+
+// ReadKV mocks base method.
+func (m *MocksecretReader) ReadKV(ctx context.Context, path string) (map[string]interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadKV", ctx, path)
+	ret0, _ := ret[0].(map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadKV indicates an expected call of ReadKV.
+func (mr *MocksecretReaderMockRecorder) ReadKV(ctx, path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadKV", reflect.TypeOf((*MocksecretReader)(nil).ReadKV), ctx, path)
+}
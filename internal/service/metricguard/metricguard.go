@@ -0,0 +1,132 @@
+// Package metricguard защищает метрики от роста числа временных рядов. Значения меток, которые
+// приходят из запроса (путь, Host, метод), не попадают в метрики как есть: путь без маршрута
+// нормализуется (идентификаторы заменяются на :id), а после заданного числа разных значений
+// метки новые значения заменяются на Overflow. SelfCheck проверяет при запуске, что
+// зарегистрированные метрики собираются без конфликтов.
+package metricguard
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Overflow - значение метки сверх лимита разных значений.
+	Overflow = "other"
+	// OtherMethod - значение метки для нестандартных HTTP методов.
+	OtherMethod = "OTHER"
+	// IDSegment - замена идентификатора в пути.
+	IDSegment = ":id"
+
+	// minIDLength - минимальная длина сегмента из букв и цифр, который считается идентификатором (токены, хэши).
+	minIDLength = 16
+)
+
+// Guard ограничивает число разных значений одной метки: первые limit значений проходят как есть,
+// остальные заменяются на Overflow.
+type Guard struct {
+	limit    int
+	overflow prometheus.Counter
+
+	mu   sync.RWMutex
+	seen map[string]struct{}
+}
+
+// NewGuard создает ограничение на limit разных значений. overflow считает замененные значения, может быть nil.
+func NewGuard(limit int, overflow prometheus.Counter) *Guard {
+	return &Guard{
+		limit:    limit,
+		overflow: overflow,
+		seen:     make(map[string]struct{}, limit),
+	}
+}
+
+// Value возвращает значение метки: само значение, если оно уже встречалось или лимит не исчерпан, иначе Overflow.
+func (g *Guard) Value(value string) string {
+	g.mu.RLock()
+	_, ok := g.seen[value]
+	g.mu.RUnlock()
+
+	if ok {
+		return value
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.seen[value]; ok {
+		return value
+	}
+
+	if len(g.seen) >= g.limit {
+		if g.overflow != nil {
+			g.overflow.Inc()
+		}
+
+		return Overflow
+	}
+
+	g.seen[value] = struct{}{}
+
+	return value
+}
+
+// NormalizePath заменяет в пути сегменты, похожие на идентификаторы (числа, UUID, длинные токены и хэши), на IDSegment.
+func NormalizePath(path string) string {
+	segments := strings.Split(path, "/")
+
+	for i, segment := range segments {
+		if isID(segment) {
+			segments[i] = IDSegment
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// isID возвращает true, если сегмент пути похож на идентификатор.
+func isID(segment string) bool {
+	if segment == "" {
+		return false
+	}
+
+	digits := 0
+
+	for _, r := range segment {
+		switch {
+		case unicode.IsDigit(r):
+			digits++
+		case r > unicode.MaxASCII, !unicode.IsLetter(r) && r != '-' && r != '_':
+			return false
+		}
+	}
+
+	// число или длинная строка из букв и цифр (UUID, токен, хэш)
+	return digits == len(segment) || (digits > 0 && len(segment) >= minIDLength)
+}
+
+// Method возвращает метод для метки: стандартные методы как есть, остальные - OtherMethod.
+func Method(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return OtherMethod
+	}
+}
+
+// SelfCheck собирает все метрики gatherer. Ошибка означает конфликт регистрации: метрики с одним именем
+// и разными метками или описанием, повторяющиеся ряды. Такие метрики Prometheus не может собрать.
+func SelfCheck(gatherer prometheus.Gatherer) error {
+	if _, err := gatherer.Gather(); err != nil {
+		return fmt.Errorf("metricguard: inconsistent metrics: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package metricguard
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuard_Value(t *testing.T) {
+	t.Parallel()
+
+	overflow := prometheus.NewCounter(prometheus.CounterOpts{Name: "overflow_total"})
+	g := NewGuard(2, overflow)
+
+	assert.Equal(t, "a", g.Value("a"))
+	assert.Equal(t, "b", g.Value("b"))
+	assert.Equal(t, Overflow, g.Value("c"))
+	assert.Equal(t, Overflow, g.Value("d"))
+
+	// уже встреченные значения проходят и после исчерпания лимита
+	assert.Equal(t, "a", g.Value("a"))
+	assert.Equal(t, "b", g.Value("b"))
+
+	assert.InDelta(t, 2, testutil.ToFloat64(overflow), 0)
+}
+
+func TestGuard_Value_NilOverflow(t *testing.T) {
+	t.Parallel()
+
+	g := NewGuard(0, nil)
+
+	assert.Equal(t, Overflow, g.Value("a"))
+}
+
+func TestGuard_Value_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	g := NewGuard(10, nil)
+
+	var wg sync.WaitGroup
+
+	for i := range 100 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			g.Value(fmt.Sprintf("v%d", i))
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Len(t, g.seen, 10)
+}
+
+func TestNormalizePath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/", want: "/"},
+		{path: "/api/v0/users", want: "/api/v0/users"},
+		{path: "/api/v0/users/12345", want: "/api/v0/users/:id"},
+		{path: "/api/v0/users/12345/keys/67", want: "/api/v0/users/:id/keys/:id"},
+		{path: "/keys/3f2b9c1e-8a4d-4c2b-9f1e-7d6a5b4c3d2e", want: "/keys/:id"},
+		{path: "/tokens/eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9", want: "/tokens/:id"},
+		// обычные слова и короткие сегменты с цифрами остаются: это часть маршрута
+		{path: "/.well-known/openid-configuration", want: "/.well-known/openid-configuration"},
+		{path: "/api/v0/oauth2/token", want: "/api/v0/oauth2/token"},
+		{path: "/wp-admin/setup-config.php", want: "/wp-admin/setup-config.php"},
+		{path: "/files/документ123456789012345", want: "/files/документ123456789012345"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, NormalizePath(tt.path))
+		})
+	}
+}
+
+func TestMethod(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, http.MethodGet, Method(http.MethodGet))
+	assert.Equal(t, http.MethodDelete, Method(http.MethodDelete))
+	assert.Equal(t, OtherMethod, Method("PROPFIND"))
+	assert.Equal(t, OtherMethod, Method("get"))
+}
+
+// duplicateCollector отдает один и тот же ряд дважды и не описывает метрики, поэтому регистрируется без ошибки.
+type duplicateCollector struct{}
+
+func (duplicateCollector) Describe(chan<- *prometheus.Desc) {}
+
+func (duplicateCollector) Collect(ch chan<- prometheus.Metric) {
+	desc := prometheus.NewDesc("duplicate_total", "duplicate", nil, nil)
+
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, 1)
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, 1)
+}
+
+func TestSelfCheck(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{Name: "ok_total", Help: "ok"}))
+
+	require.NoError(t, SelfCheck(registry))
+
+	registry.MustRegister(duplicateCollector{})
+
+	err := SelfCheck(registry)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "metricguard: inconsistent metrics")
+}
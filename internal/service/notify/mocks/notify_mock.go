@@ -0,0 +1,127 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: notify.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	event "auth-service/internal/service/event"
+	mail "auth-service/internal/service/mail"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockmailSender is a mock of mailSender interface.
+type MockmailSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockmailSenderMockRecorder
+}
+
+// MockmailSenderMockRecorder is the mock recorder for MockmailSender.
+type MockmailSenderMockRecorder struct {
+	mock *MockmailSender
+}
+
+// NewMockmailSender creates a new mock instance.
+func NewMockmailSender(ctrl *gomock.Controller) *MockmailSender {
+	mock := &MockmailSender{ctrl: ctrl}
+	mock.recorder = &MockmailSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockmailSender) EXPECT() *MockmailSenderMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockmailSender) Send(ctx context.Context, msg mail.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockmailSenderMockRecorder) Send(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockmailSender)(nil).Send), ctx, msg)
+}
+
+// MockemailResolver is a mock of emailResolver interface.
+type MockemailResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockemailResolverMockRecorder
+}
+
+// MockemailResolverMockRecorder is the mock recorder for MockemailResolver.
+type MockemailResolverMockRecorder struct {
+	mock *MockemailResolver
+}
+
+// NewMockemailResolver creates a new mock instance.
+func NewMockemailResolver(ctrl *gomock.Controller) *MockemailResolver {
+	mock := &MockemailResolver{ctrl: ctrl}
+	mock.recorder = &MockemailResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockemailResolver) EXPECT() *MockemailResolverMockRecorder {
+	return m.recorder
+}
+
+// AccountEmail mocks base method.
+func (m *MockemailResolver) AccountEmail(ctx context.Context, subject string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AccountEmail", ctx, subject)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AccountEmail indicates an expected call of AccountEmail.
+func (mr *MockemailResolverMockRecorder) AccountEmail(ctx, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccountEmail", reflect.TypeOf((*MockemailResolver)(nil).AccountEmail), ctx, subject)
+}
+
+// MockeventPublisher is a mock of eventPublisher interface.
+type MockeventPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockeventPublisherMockRecorder
+}
+
+// MockeventPublisherMockRecorder is the mock recorder for MockeventPublisher.
+type MockeventPublisherMockRecorder struct {
+	mock *MockeventPublisher
+}
+
+// NewMockeventPublisher creates a new mock instance.
+func NewMockeventPublisher(ctrl *gomock.Controller) *MockeventPublisher {
+	mock := &MockeventPublisher{ctrl: ctrl}
+	mock.recorder = &MockeventPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockeventPublisher) EXPECT() *MockeventPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockeventPublisher) Publish(ctx context.Context, e event.Event) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, e)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockeventPublisherMockRecorder) Publish(ctx, e interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockeventPublisher)(nil).Publish), ctx, e)
+}
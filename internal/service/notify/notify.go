@@ -0,0 +1,332 @@
+// Package notify уведомляет пользователей о событиях безопасности аккаунта: новом входе и смене
+// пароля. Пользователь сам выбирает, о чем уведомлять и куда: на почту или в Telegram. Письма
+// отправляются через SMTP, а уведомления в Telegram публикуются событием в stream, из которого
+// их доставляет бот: чат пользователя известен только ему.
+package notify
+
+import (
+	"auth-service/internal/service/event"
+	"auth-service/internal/service/mail"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const keyPrefix = "auth:notify:"
+
+// Виды уведомлений.
+const (
+	KindNewLogin       = "new_login"
+	KindPasswordChange = "password_change"
+)
+
+// Каналы доставки.
+const (
+	ChannelEmail    = "email"
+	ChannelTelegram = "telegram"
+)
+
+var (
+	// ErrInvalidArgument - не заполнены обязательные параметры или задан неизвестный вид уведомления.
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrChannelUnavailable - канал доставки не настроен.
+	ErrChannelUnavailable = errors.New("notification channel is not available")
+	// ErrNoAddress - у пользователя нет адреса для выбранного канала.
+	ErrNoAddress = errors.New("no address for notification channel")
+)
+
+//go:generate mockgen -source=notify.go -destination=mocks/notify_mock.go -package=mocks
+type mailSender interface {
+	Send(ctx context.Context, msg mail.Message) error
+}
+
+// emailResolver возвращает почту пользователя.
+type emailResolver interface {
+	AccountEmail(ctx context.Context, subject string) (string, error)
+}
+
+type eventPublisher interface {
+	Publish(ctx context.Context, e event.Event) (string, error)
+}
+
+// Preferences - настройки уведомлений пользователя.
+type Preferences struct {
+	// NewLogin - уведомлять о входе в аккаунт.
+	NewLogin bool `json:"new_login"`
+	// PasswordChange - уведомлять о смене пароля.
+	PasswordChange bool `json:"password_change"`
+	// Channel - куда доставлять уведомления: email или telegram.
+	Channel string `json:"channel"`
+}
+
+// enabled возвращает, включено ли уведомление вида kind.
+func (p Preferences) enabled(kind string) bool {
+	switch kind {
+	case KindNewLogin:
+		return p.NewLogin
+	case KindPasswordChange:
+		return p.PasswordChange
+	default:
+		return false
+	}
+}
+
+// Notification - уведомление о событии безопасности.
+type Notification struct {
+	Kind    string
+	Subject string
+	// Detail - подробности для пользователя, например способ входа или IP.
+	Detail string
+	At     time.Time
+}
+
+// Service - уведомления о событиях безопасности.
+//
+// Ключи:
+//   - auth:notify:preferences:<субъект> - hash настроек пользователя (new_login, password_change, channel).
+type Service struct {
+	client   redis.UniversalClient
+	mail     mailSender
+	emails   emailResolver
+	events   eventPublisher
+	defaults Preferences
+}
+
+// Option - опция для настройки Service.
+type Option func(*Service)
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(s *Service) {
+		s.client = client
+	}
+}
+
+// WithEmail включает канал email: письма отправляются на почту, которую возвращает emails.
+func WithEmail(sender mailSender, emails emailResolver) Option {
+	return func(s *Service) {
+		s.mail = sender
+		s.emails = emails
+	}
+}
+
+// WithTelegram включает канал telegram: уведомления публикуются событиями notify.<вид>, бот доставляет их в чат пользователя.
+func WithTelegram(events eventPublisher) Option {
+	return func(s *Service) {
+		s.events = events
+	}
+}
+
+// WithDefaults устанавливает настройки пользователей, которые их не меняли. По умолчанию
+// уведомления включены. Если канал не задан, выбирается первый настроенный из email и telegram.
+func WithDefaults(defaults Preferences) Option {
+	return func(s *Service) {
+		s.defaults = defaults
+	}
+}
+
+// New создает новый Service.
+func New(opts ...Option) (*Service, error) {
+	s := &Service{}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.client == nil {
+		return nil, errors.New("redis client is required")
+	}
+
+	if (s.mail == nil) != (s.emails == nil) {
+		return nil, errors.New("email channel requires both sender and email resolver")
+	}
+
+	channels := s.Channels()
+	if len(channels) == 0 {
+		return nil, errors.New("at least one notification channel is required")
+	}
+
+	if s.defaults == (Preferences{}) {
+		s.defaults = Preferences{NewLogin: true, PasswordChange: true}
+	}
+
+	if s.defaults.Channel == "" {
+		s.defaults.Channel = channels[0]
+	}
+
+	if !slices.Contains(channels, s.defaults.Channel) {
+		return nil, fmt.Errorf("default channel %q is not available", s.defaults.Channel)
+	}
+
+	return s, nil
+}
+
+func preferencesKey(subject string) string {
+	return keyPrefix + "preferences:" + subject
+}
+
+// Channels возвращает настроенные каналы доставки.
+func (s *Service) Channels() []string {
+	channels := []string{}
+
+	if s.mail != nil {
+		channels = append(channels, ChannelEmail)
+	}
+
+	if s.events != nil {
+		channels = append(channels, ChannelTelegram)
+	}
+
+	return channels
+}
+
+// Preferences возвращает настройки уведомлений пользователя. Если пользователь их не менял,
+// возвращаются настройки по умолчанию.
+func (s *Service) Preferences(ctx context.Context, subject string) (Preferences, error) {
+	data, err := s.client.HGetAll(ctx, preferencesKey(subject)).Result()
+	if err != nil {
+		return Preferences{}, fmt.Errorf("notify: error get preferences: %w", err)
+	}
+
+	if len(data) == 0 {
+		return s.defaults, nil
+	}
+
+	newLogin, _ := strconv.ParseBool(data["new_login"])
+	passwordChange, _ := strconv.ParseBool(data["password_change"])
+
+	return Preferences{NewLogin: newLogin, PasswordChange: passwordChange, Channel: data["channel"]}, nil
+}
+
+// SetPreferences сохраняет настройки уведомлений пользователя.
+func (s *Service) SetPreferences(ctx context.Context, subject string, p Preferences) error {
+	if subject == "" {
+		return fmt.Errorf("%w: subject is required", ErrInvalidArgument)
+	}
+
+	if !slices.Contains(s.Channels(), p.Channel) {
+		return fmt.Errorf("%w: %q", ErrChannelUnavailable, p.Channel)
+	}
+
+	err := s.client.HSet(ctx, preferencesKey(subject),
+		"new_login", strconv.FormatBool(p.NewLogin),
+		"password_change", strconv.FormatBool(p.PasswordChange),
+		"channel", p.Channel,
+	).Err()
+	if err != nil {
+		return fmt.Errorf("notify: error save preferences: %w", err)
+	}
+
+	return nil
+}
+
+// Notify доставляет уведомление, если пользователь его не отключил. Возвращает, было ли уведомление отправлено.
+func (s *Service) Notify(ctx context.Context, n Notification) (bool, error) {
+	if n.Subject == "" || (n.Kind != KindNewLogin && n.Kind != KindPasswordChange) {
+		return false, fmt.Errorf("%w: subject and known kind are required", ErrInvalidArgument)
+	}
+
+	p, err := s.Preferences(ctx, n.Subject)
+	if err != nil {
+		return false, err
+	}
+
+	if !p.enabled(n.Kind) {
+		return false, nil
+	}
+
+	if n.At.IsZero() {
+		n.At = time.Now()
+	}
+
+	switch p.Channel {
+	case ChannelEmail:
+		err = s.sendEmail(ctx, n)
+	case ChannelTelegram:
+		err = s.publish(ctx, n)
+	default:
+		err = fmt.Errorf("%w: %q", ErrChannelUnavailable, p.Channel)
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"subject": n.Subject,
+		"kind":    n.Kind,
+		"channel": p.Channel,
+	}).Info("security notification sent")
+
+	return true, nil
+}
+
+// sendEmail отправляет уведомление на почту пользователя.
+func (s *Service) sendEmail(ctx context.Context, n Notification) error {
+	// канал мог быть отключен в конфигурации после того, как пользователь его выбрал
+	if s.mail == nil {
+		return fmt.Errorf("%w: %q", ErrChannelUnavailable, ChannelEmail)
+	}
+
+	to, err := s.emails.AccountEmail(ctx, n.Subject)
+	if err != nil {
+		return fmt.Errorf("notify: error get email: %w", err)
+	}
+
+	if to == "" {
+		return ErrNoAddress
+	}
+
+	subject, text := message(n)
+
+	if err := s.mail.Send(ctx, mail.Message{To: to, Subject: subject, Body: text}); err != nil {
+		return fmt.Errorf("notify: error send email: %w", err)
+	}
+
+	return nil
+}
+
+// publish публикует уведомление для доставки ботом в Telegram.
+func (s *Service) publish(ctx context.Context, n Notification) error {
+	if s.events == nil {
+		return fmt.Errorf("%w: %q", ErrChannelUnavailable, ChannelTelegram)
+	}
+
+	_, err := s.events.Publish(ctx, event.Event{
+		Type:    event.TypeNotifyPrefix + n.Kind,
+		Subject: n.Subject,
+		Source:  n.Detail,
+		At:      n.At,
+	})
+	if err != nil {
+		return fmt.Errorf("notify: error publish notification: %w", err)
+	}
+
+	return nil
+}
+
+// message возвращает тему и текст письма с уведомлением.
+func message(n Notification) (string, string) {
+	var subject, text string
+
+	switch n.Kind {
+	case KindPasswordChange:
+		subject, text = "Пароль изменен", "Пароль аккаунта изменен"
+	default:
+		subject, text = "Новый вход в аккаунт", "Выполнен вход в аккаунт"
+	}
+
+	text += " " + n.At.UTC().Format("02.01.2006 15:04 MST")
+
+	if n.Detail != "" {
+		text += " (" + n.Detail + ")"
+	}
+
+	return subject, text + ".\n\nЕсли это были не вы, смените пароль и завершите все сессии."
+}
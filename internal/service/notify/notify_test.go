@@ -0,0 +1,252 @@
+package notify
+
+import (
+	"auth-service/internal/service/event"
+	"auth-service/internal/service/mail"
+	"auth-service/internal/service/notify/mocks"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testDeps struct {
+	mail   *mocks.MockmailSender
+	emails *mocks.MockemailResolver
+	events *mocks.MockeventPublisher
+}
+
+func newService(t *testing.T, opts ...Option) (*Service, testDeps) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	ctrl := gomock.NewController(t)
+	deps := testDeps{
+		mail:   mocks.NewMockmailSender(ctrl),
+		emails: mocks.NewMockemailResolver(ctrl),
+		events: mocks.NewMockeventPublisher(ctrl),
+	}
+
+	s, err := New(append([]Option{
+		WithClient(client),
+		WithEmail(deps.mail, deps.emails),
+		WithTelegram(deps.events),
+	}, opts...)...)
+	require.NoError(t, err)
+
+	return s, deps
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	ctrl := gomock.NewController(t)
+	sender := mocks.NewMockmailSender(ctrl)
+	emails := mocks.NewMockemailResolver(ctrl)
+	events := mocks.NewMockeventPublisher(ctrl)
+
+	tests := []struct {
+		name         string
+		opts         []Option
+		wantDefaults Preferences
+		wantErr      bool
+	}{
+		{
+			name:         "positive case: email is default channel",
+			opts:         []Option{WithClient(client), WithEmail(sender, emails), WithTelegram(events)},
+			wantDefaults: Preferences{NewLogin: true, PasswordChange: true, Channel: ChannelEmail},
+		},
+		{
+			name:         "positive case: telegram only",
+			opts:         []Option{WithClient(client), WithTelegram(events)},
+			wantDefaults: Preferences{NewLogin: true, PasswordChange: true, Channel: ChannelTelegram},
+		},
+		{
+			name: "positive case: custom defaults",
+			opts: []Option{
+				WithClient(client),
+				WithEmail(sender, emails),
+				WithTelegram(events),
+				WithDefaults(Preferences{NewLogin: true, Channel: ChannelTelegram}),
+			},
+			wantDefaults: Preferences{NewLogin: true, Channel: ChannelTelegram},
+		},
+		{
+			name:         "positive case: default channel is not set",
+			opts:         []Option{WithClient(client), WithTelegram(events), WithDefaults(Preferences{PasswordChange: true})},
+			wantDefaults: Preferences{PasswordChange: true, Channel: ChannelTelegram},
+		},
+		{
+			name:    "error case: no client",
+			opts:    []Option{WithTelegram(events)},
+			wantErr: true,
+		},
+		{
+			name:    "error case: no channels",
+			opts:    []Option{WithClient(client)},
+			wantErr: true,
+		},
+		{
+			name:    "error case: email without resolver",
+			opts:    []Option{WithClient(client), WithEmail(sender, nil)},
+			wantErr: true,
+		},
+		{
+			name:    "error case: default channel is not available",
+			opts:    []Option{WithClient(client), WithTelegram(events), WithDefaults(Preferences{Channel: ChannelEmail})},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := New(tt.opts...)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			got, err := s.Preferences(t.Context(), "user-"+tt.name)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDefaults, got)
+		})
+	}
+}
+
+func TestService_SetPreferences(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newService(t)
+
+	want := Preferences{NewLogin: false, PasswordChange: true, Channel: ChannelTelegram}
+	require.NoError(t, s.SetPreferences(t.Context(), "user-1", want))
+
+	got, err := s.Preferences(t.Context(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	err = s.SetPreferences(t.Context(), "user-1", Preferences{Channel: "sms"})
+	require.ErrorIs(t, err, ErrChannelUnavailable)
+
+	err = s.SetPreferences(t.Context(), "", want)
+	require.ErrorIs(t, err, ErrInvalidArgument)
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService_Notify(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		preferences *Preferences
+		n           Notification
+		prepare     func(deps testDeps)
+		wantSent    bool
+		wantErr     error
+	}{
+		{
+			name: "positive case: email by default",
+			n:    Notification{Kind: KindNewLogin, Subject: "user-1", Detail: "oauth/google", At: at},
+			prepare: func(deps testDeps) {
+				deps.emails.EXPECT().AccountEmail(gomock.Any(), "user-1").Return("user@example.com", nil)
+				deps.mail.EXPECT().Send(gomock.Any(), mail.Message{
+					To:      "user@example.com",
+					Subject: "Новый вход в аккаунт",
+					Body: "Выполнен вход в аккаунт 01.03.2026 12:00 UTC (oauth/google).\n\n" +
+						"Если это были не вы, смените пароль и завершите все сессии.",
+				}).Return(nil)
+			},
+			wantSent: true,
+		},
+		{
+			name:        "positive case: telegram",
+			preferences: &Preferences{PasswordChange: true, Channel: ChannelTelegram},
+			n:           Notification{Kind: KindPasswordChange, Subject: "user-1", Detail: "ip 192.0.2.1", At: at},
+			prepare: func(deps testDeps) {
+				deps.events.EXPECT().Publish(gomock.Any(), event.Event{
+					Type:    "notify.password_change",
+					Subject: "user-1",
+					Source:  "ip 192.0.2.1",
+					At:      at,
+				}).Return("1-0", nil)
+			},
+			wantSent: true,
+		},
+		{
+			name:        "positive case: disabled by user",
+			preferences: &Preferences{PasswordChange: true, Channel: ChannelEmail},
+			n:           Notification{Kind: KindNewLogin, Subject: "user-1", At: at},
+		},
+		{
+			name:    "error case: unknown kind",
+			n:       Notification{Kind: "unknown", Subject: "user-1"},
+			wantErr: ErrInvalidArgument,
+		},
+		{
+			name: "error case: no email",
+			n:    Notification{Kind: KindNewLogin, Subject: "user-1"},
+			prepare: func(deps testDeps) {
+				deps.emails.EXPECT().AccountEmail(gomock.Any(), "user-1").Return("", nil)
+			},
+			wantErr: ErrNoAddress,
+		},
+		{
+			name: "error case: send failed",
+			n:    Notification{Kind: KindNewLogin, Subject: "user-1"},
+			prepare: func(deps testDeps) {
+				deps.emails.EXPECT().AccountEmail(gomock.Any(), "user-1").Return("user@example.com", nil)
+				deps.mail.EXPECT().Send(gomock.Any(), gomock.Any()).Return(errors.New("smtp is down"))
+			},
+			wantErr: errors.New("notify: error send email: smtp is down"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, deps := newService(t)
+
+			if tt.preferences != nil {
+				require.NoError(t, s.SetPreferences(t.Context(), tt.n.Subject, *tt.preferences))
+			}
+
+			if tt.prepare != nil {
+				tt.prepare(deps)
+			}
+
+			sent, err := s.Notify(t.Context(), tt.n)
+
+			switch {
+			case tt.wantErr == nil:
+				require.NoError(t, err)
+			case errors.Is(err, tt.wantErr):
+			default:
+				require.EqualError(t, err, tt.wantErr.Error())
+			}
+
+			assert.Equal(t, tt.wantSent, sent)
+		})
+	}
+}
@@ -0,0 +1,483 @@
+package oauth
+
+import (
+	"auth-service/internal/service/id"
+	"auth-service/internal/service/job"
+	"auth-service/internal/service/mail"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	netmail "net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultEmailChangeTTL - сколько ждет подтверждения смена почты.
+	DefaultEmailChangeTTL = 24 * time.Hour
+
+	// emailChangeCodeLength - длина кода подтверждения смены почты.
+	emailChangeCodeLength = 32
+
+	// emailSideOld и emailSideNew - какой адрес подтверждает код.
+	emailSideOld = "old"
+	emailSideNew = "new"
+)
+
+var (
+	// ErrEmailChangeDisabled - отправка писем не настроена, смена почты недоступна.
+	ErrEmailChangeDisabled = errors.New("email change is not configured")
+	// ErrNoEmail - к субъекту не привязана подтвержденная почта.
+	ErrNoEmail = errors.New("no email is linked to the account")
+	// ErrEmailTaken - новая почта уже привязана к другому субъекту.
+	ErrEmailTaken = errors.New("email is already in use")
+	// ErrEmailChangeNotFound - смена почты не начата, истекла или код неверен.
+	ErrEmailChangeNotFound = errors.New("email change not found")
+)
+
+//go:generate mockgen -source=email.go -destination=mocks/email_mock.go -package=mocks
+type mailSender interface {
+	Send(ctx context.Context, msg mail.Message) error
+}
+
+type sessionRevoker interface {
+	Enqueue(ctx context.Context, subject string) (*job.Job, error)
+}
+
+// EmailChange - смена почты, ожидающая подтверждения со старого и нового адресов.
+type EmailChange struct {
+	OldEmail     string    `json:"old_email"`
+	NewEmail     string    `json:"new_email"`
+	OldConfirmed bool      `json:"old_confirmed"`
+	NewConfirmed bool      `json:"new_confirmed"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	// Completed - оба адреса подтверждены, почта изменена.
+	Completed bool `json:"completed"`
+	// RevocationJob - задание на отзыв токенов пользователя после смены почты.
+	RevocationJob string `json:"revocation_job,omitempty"`
+}
+
+// WithMail устанавливает отправку писем. Без нее смена почты недоступна.
+func WithMail(sender mailSender) Option {
+	return func(s *Service) {
+		s.mail = sender
+	}
+}
+
+// WithEmailChangeTTL устанавливает, сколько ждет подтверждения смена почты. По умолчанию DefaultEmailChangeTTL.
+func WithEmailChangeTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		s.emailChangeTTL = ttl
+	}
+}
+
+// WithEmailChangeURL устанавливает адрес страницы фронтенда, которая подтверждает смену почты.
+// Код передается в параметре code. Если не задан, в письме отправляется только код.
+func WithEmailChangeURL(confirmURL string) Option {
+	return func(s *Service) {
+		s.emailChangeURL = confirmURL
+	}
+}
+
+// WithSessionRevoker устанавливает отзыв токенов пользователя после смены почты.
+// Без него выпущенные токены остаются действительными.
+func WithSessionRevoker(revoker sessionRevoker) Option {
+	return func(s *Service) {
+		s.revoker = revoker
+	}
+}
+
+func accountEmailKey(subject string) string {
+	return keyPrefix + "account-email:" + subject
+}
+
+func emailChangeKey(subject string) string {
+	return keyPrefix + "email-change:" + subject
+}
+
+func emailChangeCodeKey(code string) string {
+	sum := sha256.Sum256([]byte(code))
+
+	return keyPrefix + "email-change-code:" + hex.EncodeToString(sum[:])
+}
+
+// normalizeEmail приводит адрес к виду, в котором он хранится в связях.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// StartEmailChange начинает смену почты субъекта и отправляет коды подтверждения на старый и новый адреса.
+// Почта меняется только после подтверждения с обоих адресов: доступ к одной сессии или одному ящику
+// не позволяет увести аккаунт. currentEmail нужен только аккаунтам, почта которых привязана до появления
+// смены почты. Новая смена отменяет начатую.
+func (s *Service) StartEmailChange(ctx context.Context, subject, currentEmail, newEmail string) (*EmailChange, error) {
+	if s.mail == nil {
+		return nil, ErrEmailChangeDisabled
+	}
+
+	newEmail = normalizeEmail(newEmail)
+
+	oldEmail, err := s.accountEmail(ctx, subject, normalizeEmail(currentEmail))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkNewEmail(ctx, subject, oldEmail, newEmail); err != nil {
+		return nil, err
+	}
+
+	if err := s.CancelEmailChange(ctx, subject); err != nil {
+		return nil, err
+	}
+
+	codes, err := newEmailChangeCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	change := &EmailChange{
+		OldEmail:  oldEmail,
+		NewEmail:  newEmail,
+		ExpiresAt: s.now().UTC().Add(s.emailChangeTTL).Truncate(time.Second),
+	}
+
+	// ключи могут лежать в разных слотах кластера, поэтому пайплайн без транзакции
+	_, err = s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, emailChangeKey(subject),
+			"old", oldEmail,
+			"new", newEmail,
+			"old_code", emailChangeCodeKey(codes[emailSideOld]),
+			"new_code", emailChangeCodeKey(codes[emailSideNew]),
+			"expires_at", change.ExpiresAt.Unix(),
+		)
+		p.Expire(ctx, emailChangeKey(subject), s.emailChangeTTL)
+
+		for side, code := range codes {
+			p.HSet(ctx, emailChangeCodeKey(code), "subject", subject, "side", side)
+			p.Expire(ctx, emailChangeCodeKey(code), s.emailChangeTTL)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("oauth: error save email change: %w", err)
+	}
+
+	if err := s.sendEmailChangeCodes(ctx, change, codes); err != nil {
+		if cancelErr := s.CancelEmailChange(ctx, subject); cancelErr != nil {
+			logrus.WithError(cancelErr).WithField("subject", subject).Error("error cancel email change")
+		}
+
+		return nil, err
+	}
+
+	logrus.WithField("subject", subject).Info("email change started")
+
+	return change, nil
+}
+
+// accountEmail возвращает почту субъекта. Для аккаунтов, почта которых привязана до появления
+// смены почты, почта проверяется по связи и запоминается.
+func (s *Service) accountEmail(ctx context.Context, subject, currentEmail string) (string, error) {
+	email, err := s.client.Get(ctx, accountEmailKey(subject)).Result()
+	if err == nil {
+		return email, nil
+	}
+
+	if !errors.Is(err, redis.Nil) {
+		return "", fmt.Errorf("oauth: error get account email: %w", err)
+	}
+
+	if currentEmail == "" {
+		return "", ErrNoEmail
+	}
+
+	owner, err := s.client.Get(ctx, emailKey(currentEmail)).Result()
+	if errors.Is(err, redis.Nil) || (err == nil && owner != subject) {
+		return "", ErrNoEmail
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("oauth: error get email: %w", err)
+	}
+
+	if err := s.client.SetNX(ctx, accountEmailKey(subject), currentEmail, 0).Err(); err != nil {
+		return "", fmt.Errorf("oauth: error save account email: %w", err)
+	}
+
+	return currentEmail, nil
+}
+
+// checkNewEmail проверяет, что на новую почту можно сменить текущую.
+func (s *Service) checkNewEmail(ctx context.Context, subject, oldEmail, newEmail string) error {
+	if addr, err := netmail.ParseAddress(newEmail); err != nil || addr.Address != newEmail {
+		return fmt.Errorf("%w: invalid email %q", ErrInvalidArgument, newEmail)
+	}
+
+	if oldEmail == newEmail {
+		return fmt.Errorf("%w: new email is the same as current", ErrInvalidArgument)
+	}
+
+	owner, err := s.client.Get(ctx, emailKey(newEmail)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("oauth: error get email: %w", err)
+	}
+
+	if owner != subject {
+		return ErrEmailTaken
+	}
+
+	return nil
+}
+
+// newEmailChangeCodes генерирует коды подтверждения для старого и нового адресов.
+func newEmailChangeCodes() (map[string]string, error) {
+	codes := make(map[string]string, 2)
+
+	for _, side := range []string{emailSideOld, emailSideNew} {
+		code, err := id.Generate(emailChangeCodeLength)
+		if err != nil {
+			return nil, fmt.Errorf("oauth: error generate email change code: %w", err)
+		}
+
+		codes[side] = code
+	}
+
+	return codes, nil
+}
+
+func (s *Service) sendEmailChangeCodes(ctx context.Context, change *EmailChange, codes map[string]string) error {
+	messages := []mail.Message{
+		{
+			To:      change.OldEmail,
+			Subject: "Подтвердите смену почты",
+			Body: "Запрошена смена почты аккаунта на " + change.NewEmail + ".\n\n" +
+				s.emailChangeInstruction(codes[emailSideOld]) +
+				"\n\nЕсли вы не меняли почту, не подтверждайте смену и смените пароль.",
+		},
+		{
+			To:      change.NewEmail,
+			Subject: "Подтвердите новую почту",
+			Body:    "Этот адрес указан как новая почта аккаунта.\n\n" + s.emailChangeInstruction(codes[emailSideNew]),
+		},
+	}
+
+	for _, msg := range messages {
+		if err := s.mail.Send(ctx, msg); err != nil {
+			return fmt.Errorf("oauth: error send email change code: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) emailChangeInstruction(code string) string {
+	if s.emailChangeURL == "" {
+		return "Код подтверждения: " + code
+	}
+
+	separator := "?"
+	if strings.Contains(s.emailChangeURL, "?") {
+		separator = "&"
+	}
+
+	return "Для подтверждения перейдите по ссылке: " + s.emailChangeURL + separator + "code=" + url.QueryEscape(code)
+}
+
+// ConfirmEmailChange подтверждает смену почты кодом из письма. Код используется один раз.
+// После подтверждения с обоих адресов почта меняется, на старый адрес отправляется уведомление,
+// а если настроен отзыв, токены пользователя отзываются.
+func (s *Service) ConfirmEmailChange(ctx context.Context, code string) (*EmailChange, error) {
+	if code == "" {
+		return nil, fmt.Errorf("%w: code is required", ErrInvalidArgument)
+	}
+
+	var get *redis.MapStringStringCmd
+
+	_, err := s.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		get = p.HGetAll(ctx, emailChangeCodeKey(code))
+		p.Del(ctx, emailChangeCodeKey(code))
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("oauth: error get email change code: %w", err)
+	}
+
+	subject, side := get.Val()["subject"], get.Val()["side"]
+	if subject == "" {
+		return nil, ErrEmailChangeNotFound
+	}
+
+	change, err := s.EmailChange(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	// код остался от отмененной или замененной смены
+	if change == nil || s.client.HGet(ctx, emailChangeKey(subject), side+"_code").Val() != emailChangeCodeKey(code) {
+		return nil, ErrEmailChangeNotFound
+	}
+
+	if err := s.client.HSet(ctx, emailChangeKey(subject), side+"_confirmed", 1).Err(); err != nil {
+		return nil, fmt.Errorf("oauth: error save email change: %w", err)
+	}
+
+	change.OldConfirmed = change.OldConfirmed || side == emailSideOld
+	change.NewConfirmed = change.NewConfirmed || side == emailSideNew
+
+	logrus.WithFields(logrus.Fields{"subject": subject, "side": side}).Info("email change confirmed")
+
+	if !change.OldConfirmed || !change.NewConfirmed {
+		return change, nil
+	}
+
+	// параллельное подтверждение второго адреса завершает смену только один раз
+	ok, err := s.client.HSetNX(ctx, emailChangeKey(subject), "completed", 1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("oauth: error save email change: %w", err)
+	}
+
+	if !ok {
+		return change, nil
+	}
+
+	if err := s.completeEmailChange(ctx, subject, change); err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// completeEmailChange переносит связь с почтой на новый адрес.
+func (s *Service) completeEmailChange(ctx context.Context, subject string, change *EmailChange) error {
+	defer func() {
+		if err := s.client.Del(ctx, emailChangeKey(subject)).Err(); err != nil {
+			logrus.WithError(err).WithField("subject", subject).Error("error delete email change")
+		}
+	}()
+
+	ok, err := s.client.SetNX(ctx, emailKey(change.NewEmail), subject, 0).Result()
+	if err != nil {
+		return fmt.Errorf("oauth: error save email: %w", err)
+	}
+
+	// почту мог занять вход через провайдера, пока смена ждала подтверждения
+	if !ok && s.client.Get(ctx, emailKey(change.NewEmail)).Val() != subject {
+		return ErrEmailTaken
+	}
+
+	// старая почта больше не должна приводить в аккаунт: иначе ее новый владелец войдет через провайдера
+	if s.client.Get(ctx, emailKey(change.OldEmail)).Val() == subject {
+		if err := s.client.Del(ctx, emailKey(change.OldEmail)).Err(); err != nil {
+			return fmt.Errorf("oauth: error delete email: %w", err)
+		}
+	}
+
+	if err := s.client.Set(ctx, accountEmailKey(subject), change.NewEmail, 0).Err(); err != nil {
+		return fmt.Errorf("oauth: error save account email: %w", err)
+	}
+
+	change.Completed = true
+
+	log := logrus.WithField("subject", subject)
+	log.Info("email changed")
+
+	if s.revoker != nil {
+		queued, err := s.revoker.Enqueue(ctx, subject)
+		if err != nil {
+			return fmt.Errorf("oauth: error revoke sessions after email change: %w", err)
+		}
+
+		change.RevocationJob = queued.ID
+	}
+
+	// уведомление не отменяет смену: почта уже изменена
+	err = s.mail.Send(ctx, mail.Message{
+		To:      change.OldEmail,
+		Subject: "Почта аккаунта изменена",
+		Body:    "Почта аккаунта изменена на " + change.NewEmail + ". Этот адрес больше не привязан к аккаунту.",
+	})
+	if err != nil {
+		log.WithError(err).Error("error send email change notice")
+	}
+
+	return nil
+}
+
+// AccountEmail возвращает почту субъекта или пустую строку, если почта не привязана.
+func (s *Service) AccountEmail(ctx context.Context, subject string) (string, error) {
+	email, err := s.client.Get(ctx, accountEmailKey(subject)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("oauth: error get account email: %w", err)
+	}
+
+	return email, nil
+}
+
+// EmailChange возвращает ожидающую подтверждения смену почты или nil, если смена не начата.
+func (s *Service) EmailChange(ctx context.Context, subject string) (*EmailChange, error) {
+	data, err := s.client.HGetAll(ctx, emailChangeKey(subject)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("oauth: error get email change: %w", err)
+	}
+
+	if len(data) == 0 {
+		return nil, nil //nolint:nilnil // смена не начата - не ошибка
+	}
+
+	expiresAt, _ := strconv.ParseInt(data["expires_at"], 10, 64)
+
+	return &EmailChange{
+		OldEmail:     data["old"],
+		NewEmail:     data["new"],
+		OldConfirmed: data["old_confirmed"] == "1",
+		NewConfirmed: data["new_confirmed"] == "1",
+		ExpiresAt:    time.Unix(expiresAt, 0).UTC(),
+	}, nil
+}
+
+// CancelEmailChange отменяет начатую смену почты. Коды из отправленных писем перестают действовать.
+func (s *Service) CancelEmailChange(ctx context.Context, subject string) error {
+	codes, err := s.client.HMGet(ctx, emailChangeKey(subject), "old_code", "new_code").Result()
+	if err != nil {
+		return fmt.Errorf("oauth: error get email change: %w", err)
+	}
+
+	keys := []string{emailChangeKey(subject)}
+
+	for _, code := range codes {
+		if key, ok := code.(string); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	// ключи могут лежать в разных слотах кластера, поэтому удаляются по одному
+	_, err = s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for _, key := range keys {
+			p.Del(ctx, key)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("oauth: error delete email change: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,271 @@
+package oauth
+
+import (
+	"auth-service/internal/service/job"
+	"auth-service/internal/service/mail"
+	"auth-service/internal/service/oauth/mocks"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var codePattern = regexp.MustCompile(`code=(\w+)|Код подтверждения: (\w+)`)
+
+// mailbox - отправленные письма по адресам.
+type mailbox map[string][]mail.Message
+
+// code возвращает код из последнего письма на адрес.
+func (m mailbox) code(t *testing.T, to string) string {
+	t.Helper()
+
+	require.NotEmpty(t, m[to])
+
+	match := codePattern.FindStringSubmatch(m[to][len(m[to])-1].Body)
+	require.NotNil(t, match)
+
+	return match[1] + match[2]
+}
+
+func newEmailService(t *testing.T, opts ...Option) (*Service, mailbox, *mocks.MocksessionRevoker, *miniredis.Miniredis) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	sender := mocks.NewMockmailSender(ctrl)
+	revoker := mocks.NewMocksessionRevoker(ctrl)
+
+	box := mailbox{}
+
+	sender.EXPECT().Send(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, msg mail.Message) error {
+		box[msg.To] = append(box[msg.To], msg)
+		return nil
+	}).AnyTimes()
+
+	s, _, _, mr := newService(t, append([]Option{
+		WithProvider(Provider{Name: "google", Kind: KindGoogle, RedirectURL: "https://auth.example.com/cb"}),
+		WithMail(sender),
+		WithSessionRevoker(revoker),
+	}, opts...)...)
+
+	mr.Set(emailKey("old@example.com"), "user-1")
+	mr.Set(accountEmailKey("user-1"), "old@example.com")
+
+	return s, box, revoker, mr
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService_EmailChange(t *testing.T) {
+	t.Parallel()
+
+	s, box, revoker, mr := newEmailService(t, WithEmailChangeURL("https://app.example.com/email?lang=ru"))
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	change, err := s.StartEmailChange(t.Context(), "user-1", "", " New@Example.com ")
+	require.NoError(t, err)
+	assert.Equal(t, &EmailChange{
+		OldEmail:  "old@example.com",
+		NewEmail:  "new@example.com",
+		ExpiresAt: now.Add(DefaultEmailChangeTTL),
+	}, change)
+	assert.Equal(t, DefaultEmailChangeTTL, mr.TTL(emailChangeKey("user-1")))
+	assert.Contains(t, box["old@example.com"][0].Body, "https://app.example.com/email?lang=ru&code=")
+
+	oldCode := box.code(t, "old@example.com")
+	newCode := box.code(t, "new@example.com")
+	assert.NotEqual(t, oldCode, newCode)
+
+	// подтверждение только нового адреса не меняет почту
+	change, err = s.ConfirmEmailChange(t.Context(), newCode)
+	require.NoError(t, err)
+	assert.True(t, change.NewConfirmed)
+	assert.False(t, change.Completed)
+	assert.Equal(t, "user-1", mustGet(t, mr, emailKey("old@example.com")))
+
+	// код используется один раз
+	_, err = s.ConfirmEmailChange(t.Context(), newCode)
+	require.ErrorIs(t, err, ErrEmailChangeNotFound)
+
+	revoker.EXPECT().Enqueue(gomock.Any(), "user-1").Return(&job.Job{ID: "job-1"}, nil)
+
+	change, err = s.ConfirmEmailChange(t.Context(), oldCode)
+	require.NoError(t, err)
+	assert.Equal(t, &EmailChange{
+		OldEmail:      "old@example.com",
+		NewEmail:      "new@example.com",
+		OldConfirmed:  true,
+		NewConfirmed:  true,
+		ExpiresAt:     now.Add(DefaultEmailChangeTTL),
+		Completed:     true,
+		RevocationJob: "job-1",
+	}, change)
+
+	assert.Equal(t, "user-1", mustGet(t, mr, emailKey("new@example.com")))
+	assert.Equal(t, "new@example.com", mustGet(t, mr, accountEmailKey("user-1")))
+	assert.False(t, mr.Exists(emailKey("old@example.com")))
+	assert.False(t, mr.Exists(emailChangeKey("user-1")))
+
+	// старый адрес получил уведомление о смене
+	require.Len(t, box["old@example.com"], 2)
+	assert.Equal(t, "Почта аккаунта изменена", box["old@example.com"][1].Subject)
+
+	pending, err := s.EmailChange(t.Context(), "user-1")
+	require.NoError(t, err)
+	assert.Nil(t, pending)
+
+	email, err := s.AccountEmail(t.Context(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", email)
+
+	email, err = s.AccountEmail(t.Context(), "user-2")
+	require.NoError(t, err)
+	assert.Empty(t, email)
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService_StartEmailChange_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		subject      string
+		currentEmail string
+		newEmail     string
+		wantErr      error
+	}{
+		{
+			name:     "error case: invalid email",
+			subject:  "user-1",
+			newEmail: "Новый <new@example.com>",
+			wantErr:  ErrInvalidArgument,
+		},
+		{
+			name:     "error case: same email",
+			subject:  "user-1",
+			newEmail: "OLD@example.com",
+			wantErr:  ErrInvalidArgument,
+		},
+		{
+			name:     "error case: email is taken",
+			subject:  "user-1",
+			newEmail: "taken@example.com",
+			wantErr:  ErrEmailTaken,
+		},
+		{
+			name:     "error case: no email",
+			subject:  "user-2",
+			newEmail: "new@example.com",
+			wantErr:  ErrNoEmail,
+		},
+		{
+			name:         "error case: current email of another subject",
+			subject:      "user-2",
+			currentEmail: "old@example.com",
+			newEmail:     "new@example.com",
+			wantErr:      ErrNoEmail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, _, _, mr := newEmailService(t)
+			mr.Set(emailKey("taken@example.com"), "user-3")
+
+			_, err := s.StartEmailChange(t.Context(), tt.subject, tt.currentEmail, tt.newEmail)
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.False(t, mr.Exists(emailChangeKey(tt.subject)))
+		})
+	}
+}
+
+func TestService_StartEmailChange_LegacyAccount(t *testing.T) {
+	t.Parallel()
+
+	s, box, _, mr := newEmailService(t)
+
+	// почта привязана до появления смены почты
+	mr.Del(accountEmailKey("user-1"))
+
+	change, err := s.StartEmailChange(t.Context(), "user-1", "Old@example.com", "new@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "old@example.com", change.OldEmail)
+	assert.Equal(t, "old@example.com", mustGet(t, mr, accountEmailKey("user-1")))
+	assert.Contains(t, box["new@example.com"][0].Body, "Код подтверждения: ")
+}
+
+func TestService_EmailChange_Cancel(t *testing.T) {
+	t.Parallel()
+
+	s, box, _, mr := newEmailService(t)
+
+	_, err := s.StartEmailChange(t.Context(), "user-1", "", "new@example.com")
+	require.NoError(t, err)
+
+	first := box.code(t, "old@example.com")
+
+	// новая смена отменяет начатую
+	_, err = s.StartEmailChange(t.Context(), "user-1", "", "other@example.com")
+	require.NoError(t, err)
+
+	_, err = s.ConfirmEmailChange(t.Context(), first)
+	require.ErrorIs(t, err, ErrEmailChangeNotFound)
+
+	second := box.code(t, "old@example.com")
+
+	require.NoError(t, s.CancelEmailChange(t.Context(), "user-1"))
+
+	_, err = s.ConfirmEmailChange(t.Context(), second)
+	require.ErrorIs(t, err, ErrEmailChangeNotFound)
+	assert.False(t, mr.Exists(emailChangeKey("user-1")))
+}
+
+func TestService_EmailChange_TakenBeforeCompletion(t *testing.T) {
+	t.Parallel()
+
+	s, box, _, mr := newEmailService(t)
+
+	_, err := s.StartEmailChange(t.Context(), "user-1", "", "new@example.com")
+	require.NoError(t, err)
+
+	_, err = s.ConfirmEmailChange(t.Context(), box.code(t, "old@example.com"))
+	require.NoError(t, err)
+
+	// почту занял вход через провайдера, пока смена ждала подтверждения
+	mr.Set(emailKey("new@example.com"), "user-3")
+
+	_, err = s.ConfirmEmailChange(t.Context(), box.code(t, "new@example.com"))
+	require.ErrorIs(t, err, ErrEmailTaken)
+	assert.Equal(t, "user-1", mustGet(t, mr, emailKey("old@example.com")))
+	assert.False(t, mr.Exists(emailChangeKey("user-1")))
+}
+
+func TestService_StartEmailChange_SendFailed(t *testing.T) {
+	t.Parallel()
+
+	sender := mocks.NewMockmailSender(gomock.NewController(t))
+	sender.EXPECT().Send(gomock.Any(), gomock.Any()).Return(errors.New("smtp is down"))
+
+	s, _, _, mr := newService(t,
+		WithProvider(Provider{Name: "google", Kind: KindGoogle, RedirectURL: "https://auth.example.com/cb"}),
+		WithMail(sender),
+	)
+	mr.Set(accountEmailKey("user-1"), "old@example.com")
+
+	_, err := s.StartEmailChange(t.Context(), "user-1", "", "new@example.com")
+	require.Error(t, err)
+	assert.False(t, mr.Exists(emailChangeKey("user-1")))
+
+	// без отправки писем смена недоступна
+	s.mail = nil
+
+	_, err = s.StartEmailChange(t.Context(), "user-1", "", "new@example.com")
+	require.ErrorIs(t, err, ErrEmailChangeDisabled)
+}
@@ -0,0 +1,89 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: email.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	job "auth-service/internal/service/job"
+	mail "auth-service/internal/service/mail"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockmailSender is a mock of mailSender interface.
+type MockmailSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockmailSenderMockRecorder
+}
+
+// MockmailSenderMockRecorder is the mock recorder for MockmailSender.
+type MockmailSenderMockRecorder struct {
+	mock *MockmailSender
+}
+
+// NewMockmailSender creates a new mock instance.
+func NewMockmailSender(ctrl *gomock.Controller) *MockmailSender {
+	mock := &MockmailSender{ctrl: ctrl}
+	mock.recorder = &MockmailSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockmailSender) EXPECT() *MockmailSenderMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockmailSender) Send(ctx context.Context, msg mail.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockmailSenderMockRecorder) Send(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockmailSender)(nil).Send), ctx, msg)
+}
+
+// MocksessionRevoker is a mock of sessionRevoker interface.
+type MocksessionRevoker struct {
+	ctrl     *gomock.Controller
+	recorder *MocksessionRevokerMockRecorder
+}
+
+// MocksessionRevokerMockRecorder is the mock recorder for MocksessionRevoker.
+type MocksessionRevokerMockRecorder struct {
+	mock *MocksessionRevoker
+}
+
+// NewMocksessionRevoker creates a new mock instance.
+func NewMocksessionRevoker(ctrl *gomock.Controller) *MocksessionRevoker {
+	mock := &MocksessionRevoker{ctrl: ctrl}
+	mock.recorder = &MocksessionRevokerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocksessionRevoker) EXPECT() *MocksessionRevokerMockRecorder {
+	return m.recorder
+}
+
+// Enqueue mocks base method.
+func (m *MocksessionRevoker) Enqueue(ctx context.Context, subject string) (*job.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", ctx, subject)
+	ret0, _ := ret[0].(*job.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MocksessionRevokerMockRecorder) Enqueue(ctx, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MocksessionRevoker)(nil).Enqueue), ctx, subject)
+}
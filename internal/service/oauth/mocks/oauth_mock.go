@@ -0,0 +1,144 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: oauth.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	token "auth-service/internal/service/token"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MocktokenIssuer is a mock of tokenIssuer interface.
+type MocktokenIssuer struct {
+	ctrl     *gomock.Controller
+	recorder *MocktokenIssuerMockRecorder
+}
+
+// MocktokenIssuerMockRecorder is the mock recorder for MocktokenIssuer.
+type MocktokenIssuerMockRecorder struct {
+	mock *MocktokenIssuer
+}
+
+// NewMocktokenIssuer creates a new mock instance.
+func NewMocktokenIssuer(ctrl *gomock.Controller) *MocktokenIssuer {
+	mock := &MocktokenIssuer{ctrl: ctrl}
+	mock.recorder = &MocktokenIssuerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktokenIssuer) EXPECT() *MocktokenIssuerMockRecorder {
+	return m.recorder
+}
+
+// Issue mocks base method.
+func (m *MocktokenIssuer) Issue(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Issue", ctx, req)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*token.Claims)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Issue indicates an expected call of Issue.
+func (mr *MocktokenIssuerMockRecorder) Issue(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Issue", reflect.TypeOf((*MocktokenIssuer)(nil).Issue), ctx, req)
+}
+
+// MocksecretReader is a mock of secretReader interface.
+type MocksecretReader struct {
+	ctrl     *gomock.Controller
+	recorder *MocksecretReaderMockRecorder
+}
+
+// MocksecretReaderMockRecorder is the mock recorder for MocksecretReader.
+type MocksecretReaderMockRecorder struct {
+	mock *MocksecretReader
+}
+
+// NewMocksecretReader creates a new mock instance.
+func NewMocksecretReader(ctrl *gomock.Controller) *MocksecretReader {
+	mock := &MocksecretReader{ctrl: ctrl}
+	mock.recorder = &MocksecretReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocksecretReader) EXPECT() *MocksecretReaderMockRecorder {
+	return m.recorder
+}
+
+// ReadKV mocks base method.
+func (m *MocksecretReader) ReadKV(ctx context.Context, path string) (map[string]interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadKV", ctx, path)
+	ret0, _ := ret[0].(map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadKV indicates an expected call of ReadKV.
+func (mr *MocksecretReaderMockRecorder) ReadKV(ctx, path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadKV", reflect.TypeOf((*MocksecretReader)(nil).ReadKV), ctx, path)
+}
+
+// MockstateSealer is a mock of stateSealer interface.
+type MockstateSealer struct {
+	ctrl     *gomock.Controller
+	recorder *MockstateSealerMockRecorder
+}
+
+// MockstateSealerMockRecorder is the mock recorder for MockstateSealer.
+type MockstateSealerMockRecorder struct {
+	mock *MockstateSealer
+}
+
+// NewMockstateSealer creates a new mock instance.
+func NewMockstateSealer(ctrl *gomock.Controller) *MockstateSealer {
+	mock := &MockstateSealer{ctrl: ctrl}
+	mock.recorder = &MockstateSealerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockstateSealer) EXPECT() *MockstateSealerMockRecorder {
+	return m.recorder
+}
+
+// Open mocks base method.
+func (m *MockstateSealer) Open(ctx context.Context, purpose, value string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", ctx, purpose, value)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockstateSealerMockRecorder) Open(ctx, purpose, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockstateSealer)(nil).Open), ctx, purpose, value)
+}
+
+// Seal mocks base method.
+func (m *MockstateSealer) Seal(purpose string, data []byte, ttl time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Seal", purpose, data, ttl)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Seal indicates an expected call of Seal.
+func (mr *MockstateSealerMockRecorder) Seal(purpose, data, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Seal", reflect.TypeOf((*MockstateSealer)(nil).Seal), purpose, data, ttl)
+}
@@ -0,0 +1,527 @@
+// Package oauth реализует вход через внешних провайдеров OAuth (Google, GitHub, OpenID Connect).
+// Пользователь провайдера привязывается к локальному субъекту: по уже сохраненной связи,
+// по подтвержденной почте или к новому субъекту. Учетные данные клиентов хранятся в Vault.
+package oauth
+
+import (
+	"auth-service/internal/service/id"
+	"auth-service/internal/service/token"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	keyPrefix = "auth:oauth:"
+
+	// stateLength - длина параметра state.
+	stateLength = 32
+	// statePurpose - назначение зашифрованного state (см. WithStateSealer).
+	statePurpose = "oauth_state"
+	// verifierLength - длина PKCE code_verifier (RFC 7636: от 43 до 128 символов).
+	verifierLength = 64
+	// subjectLength - длина субъекта, создаваемого для нового пользователя.
+	subjectLength = 24
+
+	// DefaultStateTTL - сколько действует начатый вход.
+	DefaultStateTTL = 10 * time.Minute
+	// DefaultTokenTTL - время жизни выпущенного токена.
+	DefaultTokenTTL = time.Hour
+	// DefaultHTTPTimeout - таймаут запросов к провайдеру.
+	DefaultHTTPTimeout = 10 * time.Second
+)
+
+var (
+	// ErrUnknownProvider - провайдер не настроен.
+	ErrUnknownProvider = errors.New("unknown oauth provider")
+	// ErrInvalidState - state не найден, истек, уже использован или выдан другому провайдеру.
+	ErrInvalidState = errors.New("invalid oauth state")
+	// ErrUpstream - провайдер вернул ошибку или неожиданный ответ.
+	ErrUpstream = errors.New("oauth provider error")
+	// ErrInvalidArgument - не заполнены обязательные параметры.
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+//go:generate mockgen -source=oauth.go -destination=mocks/oauth_mock.go -package=mocks
+type tokenIssuer interface {
+	Issue(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error)
+}
+
+type secretReader interface {
+	ReadKV(ctx context.Context, path string) (map[string]interface{}, error)
+}
+
+// stateSealer - шифрование значений, которые отдаются браузеру.
+type stateSealer interface {
+	Seal(purpose string, data []byte, ttl time.Duration) (string, error)
+	Open(ctx context.Context, purpose, value string) ([]byte, error)
+}
+
+// Login - результат входа через провайдера.
+type Login struct {
+	Token   string
+	Claims  *token.Claims
+	Subject string
+	// Created - для пользователя создан новый субъект.
+	Created bool
+}
+
+// Service - вход через внешних провайдеров OAuth.
+//
+// Ключи:
+//   - auth:oauth:state:<state> - hash начатого входа (provider, verifier), TTL - срок действия входа;
+//   - auth:oauth:identity:<провайдер>:<id> - субъект, к которому привязан пользователь провайдера;
+//   - auth:oauth:email:<почта> - субъект, к которому привязана подтвержденная почта;
+//   - auth:oauth:account-email:<субъект> - почта субъекта;
+//   - auth:oauth:email-change:<субъект> - hash смены почты, ожидающей подтверждения, TTL - срок подтверждения;
+//   - auth:oauth:email-change-code:<sha256 кода> - hash (subject, side) кода подтверждения смены почты.
+type Service struct {
+	client     redis.UniversalClient
+	issuer     tokenIssuer
+	secrets    secretReader
+	httpClient *http.Client
+	mail       mailSender
+	revoker    sessionRevoker
+	sealer     stateSealer
+
+	providers  map[string]Provider
+	stateTTL   time.Duration
+	tokenTTL   time.Duration
+	audience   []string
+	successURL string
+
+	emailChangeTTL time.Duration
+	emailChangeURL string
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Service.
+type Option func(*Service)
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(s *Service) {
+		s.client = client
+	}
+}
+
+// WithIssuer устанавливает выпуск токенов.
+func WithIssuer(issuer tokenIssuer) Option {
+	return func(s *Service) {
+		s.issuer = issuer
+	}
+}
+
+// WithSecrets устанавливает хранилище учетных данных клиентов (Vault KV).
+func WithSecrets(secrets secretReader) Option {
+	return func(s *Service) {
+		s.secrets = secrets
+	}
+}
+
+// WithHTTPClient устанавливает HTTP клиент для запросов к провайдерам.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Service) {
+		s.httpClient = client
+	}
+}
+
+// WithProvider добавляет провайдера.
+func WithProvider(p Provider) Option {
+	return func(s *Service) {
+		s.providers[p.Name] = p.withDefaults()
+	}
+}
+
+// WithStateTTL устанавливает, сколько действует начатый вход. По умолчанию DefaultStateTTL.
+func WithStateTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		s.stateTTL = ttl
+	}
+}
+
+// WithStateSealer включает шифрование state: браузер получает зашифрованные провайдера и id входа,
+// поэтому подделанный, истекший или выданный другому провайдеру state отклоняется без обращения к Redis.
+func WithStateSealer(sealer stateSealer) Option {
+	return func(s *Service) {
+		s.sealer = sealer
+	}
+}
+
+// WithToken устанавливает время жизни и аудиторию выпускаемых токенов.
+func WithToken(ttl time.Duration, audience []string) Option {
+	return func(s *Service) {
+		s.tokenTTL = ttl
+		s.audience = audience
+	}
+}
+
+// WithSuccessURL устанавливает адрес фронтенда, куда перенаправляется браузер после входа.
+// Токен передается во фрагменте адреса. Если не задан, callback отвечает токеном в JSON.
+func WithSuccessURL(successURL string) Option {
+	return func(s *Service) {
+		s.successURL = successURL
+	}
+}
+
+// New создает новый Service.
+func New(opts ...Option) (*Service, error) {
+	s := &Service{
+		httpClient: &http.Client{Timeout: DefaultHTTPTimeout},
+		providers:  make(map[string]Provider),
+		stateTTL:   DefaultStateTTL,
+		tokenTTL:   DefaultTokenTTL,
+
+		emailChangeTTL: DefaultEmailChangeTTL,
+		now:            time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.client == nil {
+		return nil, errors.New("redis client is required")
+	}
+
+	if s.issuer == nil {
+		return nil, errors.New("issuer is required")
+	}
+
+	if s.secrets == nil {
+		return nil, errors.New("secrets reader is required")
+	}
+
+	if len(s.providers) == 0 {
+		return nil, errors.New("at least one provider is required")
+	}
+
+	for _, p := range s.providers {
+		if err := p.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.stateTTL <= 0 {
+		return nil, errors.New("state ttl must be positive")
+	}
+
+	if s.tokenTTL <= 0 {
+		return nil, errors.New("token ttl must be positive")
+	}
+
+	if s.emailChangeTTL <= 0 {
+		return nil, errors.New("email change ttl must be positive")
+	}
+
+	return s, nil
+}
+
+func stateKey(state string) string {
+	return keyPrefix + "state:" + state
+}
+
+func identityKey(provider, identityID string) string {
+	return keyPrefix + "identity:" + provider + ":" + identityID
+}
+
+func emailKey(email string) string {
+	return keyPrefix + "email:" + email
+}
+
+// Providers возвращает имена настроенных провайдеров.
+func (s *Service) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// SuccessURL возвращает адрес фронтенда для перенаправления после входа.
+func (s *Service) SuccessURL() string {
+	return s.successURL
+}
+
+func (s *Service) provider(name string) (Provider, error) {
+	p, ok := s.providers[name]
+	if !ok {
+		return Provider{}, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+	}
+
+	return p, nil
+}
+
+// credentials читает client_id и client_secret провайдера из Vault. Секрет читается на каждый вход,
+// поэтому ротация в Vault не требует перезапуска.
+func (s *Service) credentials(ctx context.Context, p Provider) (credentials, error) {
+	data, err := s.secrets.ReadKV(ctx, p.CredentialsPath)
+	if err != nil {
+		return credentials{}, fmt.Errorf("oauth: error read credentials of %s: %w", p.Name, err)
+	}
+
+	clientID, _ := data["client_id"].(string)
+	clientSecret, _ := data["client_secret"].(string)
+
+	if clientID == "" || clientSecret == "" {
+		return credentials{}, fmt.Errorf("oauth: credentials of %s must contain client_id and client_secret", p.Name)
+	}
+
+	return credentials{ClientID: clientID, ClientSecret: clientSecret}, nil
+}
+
+// Start начинает вход через провайдера и возвращает адрес, куда перенаправить браузер.
+func (s *Service) Start(ctx context.Context, provider string) (string, error) {
+	p, err := s.provider(provider)
+	if err != nil {
+		return "", err
+	}
+
+	creds, err := s.credentials(ctx, p)
+	if err != nil {
+		return "", err
+	}
+
+	state, err := id.Generate(stateLength)
+	if err != nil {
+		return "", fmt.Errorf("oauth: error generate state: %w", err)
+	}
+
+	verifier, err := id.Generate(verifierLength)
+	if err != nil {
+		return "", fmt.Errorf("oauth: error generate verifier: %w", err)
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, stateKey(state), "provider", p.Name, "verifier", verifier)
+		pipe.Expire(ctx, stateKey(state), s.stateTTL)
+
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("oauth: error save state: %w", err)
+	}
+
+	param, err := s.sealState(p.Name, state)
+	if err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {creds.ClientID},
+		"redirect_uri":          {p.RedirectURL},
+		"scope":                 {strings.Join(p.Scopes, " ")},
+		"state":                 {param},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(p.AuthURL, "?") {
+		separator = "&"
+	}
+
+	return p.AuthURL + separator + query.Encode(), nil
+}
+
+// Callback завершает вход: обменивает код на токен провайдера, получает пользователя,
+// привязывает его к локальному субъекту и выпускает токен.
+func (s *Service) Callback(ctx context.Context, provider, code, state string) (*Login, error) {
+	if code == "" || state == "" {
+		return nil, fmt.Errorf("%w: code and state are required", ErrInvalidArgument)
+	}
+
+	p, err := s.provider(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err = s.openState(ctx, p.Name, state)
+	if err != nil {
+		return nil, err
+	}
+
+	verifier, err := s.consumeState(ctx, p.Name, state)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := s.credentials(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.exchange(ctx, p, creds, code, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := s.identity(ctx, p, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, created, err := s.link(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, claims, err := s.issuer.Issue(ctx, token.IssueRequest{
+		Subject:  subject,
+		Audience: s.audience,
+		TTL:      s.tokenTTL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("oauth: error issue token: %w", err)
+	}
+
+	return &Login{Token: raw, Claims: claims, Subject: subject, Created: created}, nil
+}
+
+// sealState возвращает значение параметра state: id входа или, если включено шифрование,
+// зашифрованные провайдер и id входа.
+func (s *Service) sealState(provider, state string) (string, error) {
+	if s.sealer == nil {
+		return state, nil
+	}
+
+	sealed, err := s.sealer.Seal(statePurpose, []byte(provider+":"+state), s.stateTTL)
+	if err != nil {
+		return "", fmt.Errorf("oauth: error seal state: %w", err)
+	}
+
+	return sealed, nil
+}
+
+// openState возвращает id входа из параметра state.
+func (s *Service) openState(ctx context.Context, provider, param string) (string, error) {
+	if s.sealer == nil {
+		return param, nil
+	}
+
+	data, err := s.sealer.Open(ctx, statePurpose, param)
+	if err != nil {
+		return "", ErrInvalidState
+	}
+
+	sealedProvider, state, ok := strings.Cut(string(data), ":")
+	if !ok || sealedProvider != provider {
+		return "", ErrInvalidState
+	}
+
+	return state, nil
+}
+
+// consumeState забирает state. State используется один раз, даже если вход дальше не удался.
+func (s *Service) consumeState(ctx context.Context, provider, state string) (string, error) {
+	var get *redis.MapStringStringCmd
+
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.HGetAll(ctx, stateKey(state))
+		pipe.Del(ctx, stateKey(state))
+
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("oauth: error get state: %w", err)
+	}
+
+	saved := get.Val()
+	if len(saved) == 0 || saved["provider"] != provider {
+		return "", ErrInvalidState
+	}
+
+	return saved["verifier"], nil
+}
+
+// link находит субъект пользователя провайдера: по сохраненной связи, по подтвержденной почте
+// или создает новый. Неподтвержденная почта не используется для привязки, иначе можно
+// войти в чужой аккаунт, указав у провайдера чужой адрес.
+func (s *Service) link(ctx context.Context, identity *Identity) (string, bool, error) {
+	subject, err := s.client.Get(ctx, identityKey(identity.Provider, identity.ID)).Result()
+	if err == nil {
+		return subject, false, nil
+	}
+
+	if !errors.Is(err, redis.Nil) {
+		return "", false, fmt.Errorf("oauth: error get identity: %w", err)
+	}
+
+	email := strings.ToLower(strings.TrimSpace(identity.Email))
+	verifiedEmail := identity.EmailVerified && email != ""
+
+	created := true
+
+	if verifiedEmail {
+		subject, err = s.client.Get(ctx, emailKey(email)).Result()
+		switch {
+		case err == nil:
+			created = false
+		case !errors.Is(err, redis.Nil):
+			return "", false, fmt.Errorf("oauth: error get email: %w", err)
+		}
+	}
+
+	if created {
+		subject, err = id.Generate(subjectLength)
+		if err != nil {
+			return "", false, fmt.Errorf("oauth: error generate subject: %w", err)
+		}
+
+		if verifiedEmail {
+			// почту мог параллельно занять другой вход - тогда привязываемся к его субъекту
+			ok, err := s.client.SetNX(ctx, emailKey(email), subject, 0).Result()
+			if err != nil {
+				return "", false, fmt.Errorf("oauth: error save email: %w", err)
+			}
+
+			if !ok {
+				if subject, err = s.client.Get(ctx, emailKey(email)).Result(); err != nil {
+					return "", false, fmt.Errorf("oauth: error get email: %w", err)
+				}
+
+				created = false
+			}
+		}
+	}
+
+	if verifiedEmail {
+		// почта субъекта не перезаписывается: вход с другой подтвержденной почтой ее не меняет
+		if err := s.client.SetNX(ctx, accountEmailKey(subject), email, 0).Err(); err != nil {
+			return "", false, fmt.Errorf("oauth: error save account email: %w", err)
+		}
+	}
+
+	ok, err := s.client.SetNX(ctx, identityKey(identity.Provider, identity.ID), subject, 0).Result()
+	if err != nil {
+		return "", false, fmt.Errorf("oauth: error save identity: %w", err)
+	}
+
+	if !ok {
+		// параллельный вход того же пользователя уже сохранил связь
+		subject, err = s.client.Get(ctx, identityKey(identity.Provider, identity.ID)).Result()
+		if err != nil {
+			return "", false, fmt.Errorf("oauth: error get identity: %w", err)
+		}
+
+		created = false
+	}
+
+	return subject, created, nil
+}
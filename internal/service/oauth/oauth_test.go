@@ -0,0 +1,479 @@
+package oauth
+
+import (
+	"auth-service/internal/service/oauth/mocks"
+	"auth-service/internal/service/token"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upstream - тестовый провайдер OAuth.
+type upstream struct {
+	server *httptest.Server
+	// verifiers - code_verifier, с которыми обменивались коды.
+	verifiers []string
+	userinfo  any
+	emails    any
+}
+
+func newUpstream(t *testing.T) *upstream {
+	t.Helper()
+
+	u := &upstream{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+
+		if r.PostForm.Get("client_secret") != "secret" || r.PostForm.Get("code") != "good-code" {
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "bad_verification_code"})
+			return
+		}
+
+		u.verifiers = append(u.verifiers, r.PostForm.Get("code_verifier"))
+
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "upstream-token"})
+	})
+	mux.HandleFunc("GET /userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer upstream-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(u.userinfo)
+	})
+	mux.HandleFunc("GET /emails", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(u.emails)
+	})
+
+	u.server = httptest.NewServer(mux)
+	t.Cleanup(u.server.Close)
+
+	return u
+}
+
+func (u *upstream) provider(name, kind string) Provider {
+	return Provider{
+		Name:        name,
+		Kind:        kind,
+		AuthURL:     u.server.URL + "/authorize",
+		TokenURL:    u.server.URL + "/token",
+		UserInfoURL: u.server.URL + "/userinfo",
+		EmailsURL:   u.server.URL + "/emails",
+		RedirectURL: "https://auth.example.com/api/v0/oauth/" + name + "/callback",
+	}
+}
+
+func newService(t *testing.T, opts ...Option) (*Service, *mocks.MocktokenIssuer, *mocks.MocksecretReader, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	ctrl := gomock.NewController(t)
+	issuer := mocks.NewMocktokenIssuer(ctrl)
+	secrets := mocks.NewMocksecretReader(ctrl)
+
+	s, err := New(append([]Option{WithClient(client), WithIssuer(issuer), WithSecrets(secrets)}, opts...)...)
+	require.NoError(t, err)
+
+	return s, issuer, secrets, mr
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	t.Cleanup(func() { _ = client.Close() })
+
+	ctrl := gomock.NewController(t)
+	issuer := mocks.NewMocktokenIssuer(ctrl)
+	secrets := mocks.NewMocksecretReader(ctrl)
+
+	google := Provider{Name: "google", Kind: KindGoogle, RedirectURL: "https://auth.example.com/callback"}
+	required := []Option{WithClient(client), WithIssuer(issuer), WithSecrets(secrets)}
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			opts:    append(required, WithProvider(google)),
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: all options",
+			opts: append(required,
+				WithProvider(google),
+				WithProvider(Provider{Name: "github", Kind: KindGitHub, RedirectURL: "https://auth.example.com/callback"}),
+				WithHTTPClient(http.DefaultClient),
+				WithStateTTL(time.Minute),
+				WithToken(time.Hour, []string{"dashboard"}),
+				WithSuccessURL("https://app.example.com/login"),
+			),
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: client is nil",
+			opts:    []Option{WithIssuer(issuer), WithSecrets(secrets), WithProvider(google)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: issuer is nil",
+			opts:    []Option{WithClient(client), WithSecrets(secrets), WithProvider(google)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: secrets is nil",
+			opts:    []Option{WithClient(client), WithIssuer(issuer), WithProvider(google)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: no providers",
+			opts:    required,
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: unsupported kind",
+			opts:    append(required, WithProvider(Provider{Name: "x", Kind: "saml", RedirectURL: "https://a"})),
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: oidc without urls",
+			opts:    append(required, WithProvider(Provider{Name: "corp", Kind: KindOIDC, RedirectURL: "https://a"})),
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: no redirect url",
+			opts:    append(required, WithProvider(Provider{Name: "google", Kind: KindGoogle})),
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: zero state ttl",
+			opts:    append(required, WithProvider(google), WithStateTTL(0)),
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: zero token ttl",
+			opts:    append(required, WithProvider(google), WithToken(0, nil)),
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(tt.opts...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+func TestProvider_withDefaults(t *testing.T) {
+	t.Parallel()
+
+	p := Provider{Name: "gh", Kind: KindGitHub}.withDefaults()
+	assert.Equal(t, "https://github.com/login/oauth/authorize", p.AuthURL)
+	assert.Equal(t, "https://api.github.com/user/emails", p.EmailsURL)
+	assert.Equal(t, []string{"read:user", "user:email"}, p.Scopes)
+	assert.Equal(t, "secret/data/auth/oauth/gh", p.CredentialsPath)
+
+	// явно заданные значения не перезаписываются
+	p = Provider{Name: "google", Kind: KindGoogle, TokenURL: "https://proxy/token", Scopes: []string{"openid"}}.withDefaults()
+	assert.Equal(t, "https://proxy/token", p.TokenURL)
+	assert.Equal(t, []string{"openid"}, p.Scopes)
+}
+
+// start начинает вход и возвращает state из адреса перенаправления.
+func start(t *testing.T, s *Service, provider string) string {
+	t.Helper()
+
+	redirect, err := s.Start(t.Context(), provider)
+	require.NoError(t, err)
+
+	u, err := url.Parse(redirect)
+	require.NoError(t, err)
+
+	return u.Query().Get("state")
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService_Google(t *testing.T) {
+	t.Parallel()
+
+	up := newUpstream(t)
+	up.userinfo = map[string]any{"sub": "g-1", "email": "User@Example.com", "email_verified": true}
+
+	s, issuer, secrets, mr := newService(t,
+		WithProvider(up.provider("google", KindGoogle)),
+		WithToken(30*time.Minute, []string{"dashboard"}),
+	)
+
+	secrets.EXPECT().ReadKV(gomock.Any(), "secret/data/auth/oauth/google").
+		Return(map[string]interface{}{"client_id": "client", "client_secret": "secret"}, nil).AnyTimes()
+
+	redirect, err := s.Start(t.Context(), "google")
+	require.NoError(t, err)
+
+	u, err := url.Parse(redirect)
+	require.NoError(t, err)
+	assert.Equal(t, up.server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(t, "client", u.Query().Get("client_id"))
+	assert.Equal(t, "code", u.Query().Get("response_type"))
+	assert.Equal(t, "openid email profile", u.Query().Get("scope"))
+	assert.Equal(t, "S256", u.Query().Get("code_challenge_method"))
+
+	state := u.Query().Get("state")
+	assert.Equal(t, DefaultStateTTL, mr.TTL(stateKey(state)))
+
+	issuer.EXPECT().Issue(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, req token.IssueRequest) (string, *token.Claims, error) {
+			assert.Equal(t, []string{"dashboard"}, req.Audience)
+			assert.Equal(t, 30*time.Minute, req.TTL)
+
+			return "jwt", &token.Claims{Subject: req.Subject}, nil
+		}).Times(2)
+
+	login, err := s.Callback(t.Context(), "google", "good-code", state)
+	require.NoError(t, err)
+	assert.Equal(t, "jwt", login.Token)
+	assert.True(t, login.Created)
+	assert.Len(t, login.Subject, subjectLength)
+
+	// провайдер получил verifier, соответствующий code_challenge
+	require.Len(t, up.verifiers, 1)
+
+	sum := sha256.Sum256([]byte(up.verifiers[0]))
+	assert.Equal(t, u.Query().Get("code_challenge"), base64.RawURLEncoding.EncodeToString(sum[:]))
+
+	// state используется один раз
+	_, err = s.Callback(t.Context(), "google", "good-code", state)
+	require.ErrorIs(t, err, ErrInvalidState)
+
+	// почта сохраняется в нижнем регистре
+	assert.Equal(t, login.Subject, mustGet(t, mr, emailKey("user@example.com")))
+	assert.Equal(t, login.Subject, mustGet(t, mr, identityKey("google", "g-1")))
+
+	// повторный вход находит тот же субъект
+	state = start(t, s, "google")
+
+	again, err := s.Callback(t.Context(), "google", "good-code", state)
+	require.NoError(t, err)
+	assert.Equal(t, login.Subject, again.Subject)
+	assert.False(t, again.Created)
+}
+
+func mustGet(t *testing.T, mr *miniredis.Miniredis, key string) string {
+	t.Helper()
+
+	value, err := mr.Get(key)
+	require.NoError(t, err)
+
+	return value
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService_LinkByEmail(t *testing.T) {
+	t.Parallel()
+
+	google := newUpstream(t)
+	google.userinfo = map[string]any{"sub": "g-1", "email": "user@example.com", "email_verified": "true"}
+
+	github := newUpstream(t)
+	github.userinfo = map[string]any{"id": 42, "login": "user"}
+	github.emails = []map[string]any{
+		{"email": "old@example.com", "primary": false, "verified": true},
+		{"email": "user@example.com", "primary": true, "verified": true},
+	}
+
+	s, issuer, secrets, mr := newService(t,
+		WithProvider(google.provider("google", KindGoogle)),
+		WithProvider(github.provider("github", KindGitHub)),
+	)
+
+	secrets.EXPECT().ReadKV(gomock.Any(), gomock.Any()).
+		Return(map[string]interface{}{"client_id": "client", "client_secret": "secret"}, nil).AnyTimes()
+	issuer.EXPECT().Issue(gomock.Any(), gomock.Any()).Return("jwt", &token.Claims{}, nil).AnyTimes()
+
+	state := start(t, s, "google")
+
+	first, err := s.Callback(t.Context(), "google", "good-code", state)
+	require.NoError(t, err)
+
+	// GitHub с той же подтвержденной почтой привязывается к существующему субъекту
+	state = start(t, s, "github")
+
+	second, err := s.Callback(t.Context(), "github", "good-code", state)
+	require.NoError(t, err)
+	assert.Equal(t, first.Subject, second.Subject)
+	assert.False(t, second.Created)
+	assert.Equal(t, first.Subject, mustGet(t, mr, identityKey("github", "42")))
+	assert.Equal(t, "user@example.com", mustGet(t, mr, accountEmailKey(first.Subject)))
+
+	// state одного провайдера не принимается другим
+	state = start(t, s, "google")
+
+	_, err = s.Callback(t.Context(), "github", "good-code", state)
+	require.ErrorIs(t, err, ErrInvalidState)
+
+	// неподтвержденная почта не используется для привязки
+	github.userinfo = map[string]any{"id": 43}
+	github.emails = []map[string]any{{"email": "user@example.com", "primary": true, "verified": false}}
+
+	state = start(t, s, "github")
+
+	third, err := s.Callback(t.Context(), "github", "good-code", state)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Subject, third.Subject)
+	assert.True(t, third.Created)
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService_Errors(t *testing.T) {
+	t.Parallel()
+
+	up := newUpstream(t)
+	up.userinfo = map[string]any{"email": "user@example.com"}
+
+	s, issuer, secrets, mr := newService(t, WithProvider(up.provider("corp", KindOIDC)))
+
+	_, err := s.Start(t.Context(), "unknown")
+	require.ErrorIs(t, err, ErrUnknownProvider)
+
+	_, err = s.Callback(t.Context(), "corp", "", "state")
+	require.ErrorIs(t, err, ErrInvalidArgument)
+
+	// в Vault нет секрета
+	secrets.EXPECT().ReadKV(gomock.Any(), "secret/data/auth/oauth/corp").Return(nil, errors.New("secret not found"))
+
+	_, err = s.Start(t.Context(), "corp")
+	require.Error(t, err)
+
+	// в секрете нет client_secret
+	secrets.EXPECT().ReadKV(gomock.Any(), "secret/data/auth/oauth/corp").Return(map[string]interface{}{"client_id": "client"}, nil)
+
+	_, err = s.Start(t.Context(), "corp")
+	require.Error(t, err)
+
+	secrets.EXPECT().ReadKV(gomock.Any(), gomock.Any()).
+		Return(map[string]interface{}{"client_id": "client", "client_secret": "secret"}, nil).AnyTimes()
+
+	// провайдер не принял код
+	state := start(t, s, "corp")
+
+	_, err = s.Callback(t.Context(), "corp", "bad-code", state)
+	require.ErrorIs(t, err, ErrUpstream)
+
+	// userinfo без sub
+	state = start(t, s, "corp")
+
+	_, err = s.Callback(t.Context(), "corp", "good-code", state)
+	require.ErrorIs(t, err, ErrUpstream)
+
+	// ошибка выпуска токена
+	up.userinfo = map[string]any{"sub": "c-1"}
+	issuer.EXPECT().Issue(gomock.Any(), gomock.Any()).Return("", nil, errors.New("vault is down"))
+
+	state = start(t, s, "corp")
+
+	_, err = s.Callback(t.Context(), "corp", "good-code", state)
+	require.Error(t, err)
+
+	// Redis недоступен
+	mr.Close()
+
+	_, err = s.Start(t.Context(), "corp")
+	require.Error(t, err)
+}
+
+func TestService_Providers(t *testing.T) {
+	t.Parallel()
+
+	s, _, _, _ := newService(t,
+		WithProvider(Provider{Name: "google", Kind: KindGoogle, RedirectURL: "https://a"}),
+		WithProvider(Provider{Name: "github", Kind: KindGitHub, RedirectURL: "https://a"}),
+		WithSuccessURL("https://app.example.com/login"),
+	)
+
+	assert.Equal(t, []string{"github", "google"}, s.Providers())
+	assert.Equal(t, "https://app.example.com/login", s.SuccessURL())
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService_StateSealer(t *testing.T) {
+	t.Parallel()
+
+	up := newUpstream(t)
+	up.userinfo = map[string]any{"sub": "g-1", "email": "user@example.com", "email_verified": true}
+
+	sealer := mocks.NewMockstateSealer(gomock.NewController(t))
+
+	s, issuer, secrets, mr := newService(t,
+		WithProvider(up.provider("google", KindGoogle)),
+		WithProvider(up.provider("github", KindGitHub)),
+		WithStateSealer(sealer),
+	)
+
+	secrets.EXPECT().ReadKV(gomock.Any(), gomock.Any()).
+		Return(map[string]interface{}{"client_id": "client", "client_secret": "secret"}, nil).AnyTimes()
+	issuer.EXPECT().Issue(gomock.Any(), gomock.Any()).Return("jwt", &token.Claims{}, nil)
+
+	var sealed string
+
+	sealer.EXPECT().Seal("oauth_state", gomock.Any(), DefaultStateTTL).DoAndReturn(
+		func(_ string, data []byte, _ time.Duration) (string, error) {
+			sealed = string(data)
+
+			return "sealed-state", nil
+		})
+
+	state := start(t, s, "google")
+	assert.Equal(t, "sealed-state", state)
+
+	// в Redis сохраняется id входа, браузер получает зашифрованные провайдера и id
+	provider, id, ok := strings.Cut(sealed, ":")
+	require.True(t, ok)
+	assert.Equal(t, "google", provider)
+	assert.Equal(t, DefaultStateTTL, mr.TTL(stateKey(id)))
+
+	// state другого провайдера не принимается, Redis не затрагивается
+	sealer.EXPECT().Open(gomock.Any(), "oauth_state", "sealed-state").Return([]byte(sealed), nil).Times(2)
+
+	_, err := s.Callback(t.Context(), "github", "good-code", "sealed-state")
+	require.ErrorIs(t, err, ErrInvalidState)
+	assert.True(t, mr.Exists(stateKey(id)))
+
+	_, err = s.Callback(t.Context(), "google", "good-code", "sealed-state")
+	require.NoError(t, err)
+
+	// поддельный state отклоняется
+	sealer.EXPECT().Open(gomock.Any(), "oauth_state", "forged").Return(nil, errors.New("invalid sealed value"))
+
+	_, err = s.Callback(t.Context(), "google", "good-code", "forged")
+	require.ErrorIs(t, err, ErrInvalidState)
+
+	// ошибка шифрования
+	sealer.EXPECT().Seal(gomock.Any(), gomock.Any(), gomock.Any()).Return("", errors.New("no key"))
+
+	_, err = s.Start(t.Context(), "google")
+	require.EqualError(t, err, "oauth: error seal state: no key")
+}
@@ -0,0 +1,272 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Типы провайдеров.
+const (
+	// KindGoogle - вход через Google (OpenID Connect).
+	KindGoogle = "google"
+	// KindGitHub - вход через GitHub. Почта берется из /user/emails, у GitHub нет claim email_verified.
+	KindGitHub = "github"
+	// KindOIDC - произвольный провайдер OpenID Connect, адреса задаются в конфигурации.
+	KindOIDC = "oidc"
+)
+
+// DefaultCredentialsPrefix - путь Vault KV v2, под которым по умолчанию лежат секреты провайдеров: <prefix><name>.
+const DefaultCredentialsPrefix = "secret/data/auth/oauth/"
+
+// maxResponseSize - максимальный размер ответа провайдера.
+const maxResponseSize = 1 << 20
+
+// Provider - настройки внешнего провайдера.
+type Provider struct {
+	Name string
+	Kind string
+
+	// Адреса провайдера. Для google и github по умолчанию берутся известные адреса.
+	AuthURL     string
+	TokenURL    string
+	UserInfoURL string
+	// EmailsURL - список адресов почты пользователя (только github).
+	EmailsURL string
+
+	Scopes []string
+	// RedirectURL - адрес callback этого сервиса, зарегистрированный у провайдера.
+	RedirectURL string
+	// CredentialsPath - путь к секрету Vault KV v2 с полями client_id и client_secret.
+	CredentialsPath string
+}
+
+// withDefaults заполняет адреса и scopes известных провайдеров.
+func (p Provider) withDefaults() Provider {
+	switch p.Kind {
+	case KindGoogle:
+		p.AuthURL = or(p.AuthURL, "https://accounts.google.com/o/oauth2/v2/auth")
+		p.TokenURL = or(p.TokenURL, "https://oauth2.googleapis.com/token")
+		p.UserInfoURL = or(p.UserInfoURL, "https://openidconnect.googleapis.com/v1/userinfo")
+
+		if len(p.Scopes) == 0 {
+			p.Scopes = []string{"openid", "email", "profile"}
+		}
+	case KindGitHub:
+		p.AuthURL = or(p.AuthURL, "https://github.com/login/oauth/authorize")
+		p.TokenURL = or(p.TokenURL, "https://github.com/login/oauth/access_token")
+		p.UserInfoURL = or(p.UserInfoURL, "https://api.github.com/user")
+		p.EmailsURL = or(p.EmailsURL, "https://api.github.com/user/emails")
+
+		if len(p.Scopes) == 0 {
+			p.Scopes = []string{"read:user", "user:email"}
+		}
+	case KindOIDC:
+		if len(p.Scopes) == 0 {
+			p.Scopes = []string{"openid", "email"}
+		}
+	}
+
+	if p.CredentialsPath == "" {
+		p.CredentialsPath = DefaultCredentialsPrefix + p.Name
+	}
+
+	return p
+}
+
+func (p Provider) validate() error {
+	switch p.Kind {
+	case KindGoogle, KindGitHub, KindOIDC:
+	default:
+		return fmt.Errorf("provider %s: unsupported kind %q", p.Name, p.Kind)
+	}
+
+	if p.Name == "" {
+		return fmt.Errorf("provider name is required")
+	}
+
+	if p.AuthURL == "" || p.TokenURL == "" || p.UserInfoURL == "" {
+		return fmt.Errorf("provider %s: auth, token and userinfo urls are required", p.Name)
+	}
+
+	if p.RedirectURL == "" {
+		return fmt.Errorf("provider %s: redirect url is required", p.Name)
+	}
+
+	return nil
+}
+
+func or(value, fallback string) string {
+	if value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+// credentials - учетные данные клиента у провайдера.
+type credentials struct {
+	ClientID     string
+	ClientSecret string
+}
+
+// Identity - пользователь у внешнего провайдера.
+type Identity struct {
+	Provider      string
+	ID            string
+	Email         string
+	EmailVerified bool
+}
+
+// exchange обменивает код авторизации на access token провайдера.
+func (s *Service) exchange(ctx context.Context, p Provider, creds credentials, code, verifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.RedirectURL},
+		"client_id":     {creds.ClientID},
+		"client_secret": {creds.ClientSecret},
+		"code_verifier": {verifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}
+
+	if err := s.doJSON(req, &resp); err != nil {
+		return "", fmt.Errorf("%w: token exchange: %w", ErrUpstream, err)
+	}
+
+	// GitHub возвращает ошибку обмена со статусом 200
+	if resp.AccessToken == "" {
+		return "", fmt.Errorf("%w: token exchange: %s", ErrUpstream, or(resp.Error, "no access token"))
+	}
+
+	return resp.AccessToken, nil
+}
+
+// identity получает пользователя провайдера по access token.
+func (s *Service) identity(ctx context.Context, p Provider, accessToken string) (*Identity, error) {
+	if p.Kind == KindGitHub {
+		return s.githubIdentity(ctx, p, accessToken)
+	}
+
+	var info struct {
+		Sub           string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified any    `json:"email_verified"`
+	}
+
+	if err := s.get(ctx, p.UserInfoURL, accessToken, &info); err != nil {
+		return nil, err
+	}
+
+	if info.Sub == "" {
+		return nil, fmt.Errorf("%w: userinfo without sub", ErrUpstream)
+	}
+
+	return &Identity{
+		Provider:      p.Name,
+		ID:            info.Sub,
+		Email:         info.Email,
+		EmailVerified: verified(info.EmailVerified),
+	}, nil
+}
+
+// verified разбирает email_verified: часть провайдеров возвращает его строкой.
+func verified(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	default:
+		return false
+	}
+}
+
+func (s *Service) githubIdentity(ctx context.Context, p Provider, accessToken string) (*Identity, error) {
+	var user struct {
+		ID int64 `json:"id"`
+	}
+
+	if err := s.get(ctx, p.UserInfoURL, accessToken, &user); err != nil {
+		return nil, err
+	}
+
+	if user.ID == 0 {
+		return nil, fmt.Errorf("%w: user without id", ErrUpstream)
+	}
+
+	identity := &Identity{Provider: p.Name, ID: strconv.FormatInt(user.ID, 10)}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+
+	if err := s.get(ctx, p.EmailsURL, accessToken, &emails); err != nil {
+		return nil, err
+	}
+
+	for _, e := range emails {
+		if e.Primary {
+			identity.Email = e.Email
+			identity.EmailVerified = e.Verified
+		}
+	}
+
+	return identity, nil
+}
+
+func (s *Service) get(ctx context.Context, target, accessToken string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	if err := s.doJSON(req, dst); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrUpstream, target, err)
+	}
+
+	return nil
+}
+
+func (s *Service) doJSON(req *http.Request, dst any) error {
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return json.Unmarshal(body, dst)
+}
@@ -0,0 +1,336 @@
+// Package peer проверяет jwt, которые выпускают другие сервисы bot-zanuda (peer сервисы) для
+// вызовов между сервисами. Доверенные издатели и адреса их JWKS задаются в конфигурации,
+// открытые ключи загружаются по JWKS (authclient.JWKS), кэшируются и периодически обновляются.
+// Токен с неизвестным kid вызывает внеочередную загрузку ключей издателя: так подхватывается
+// ротация ключей peer сервиса.
+package peer
+
+import (
+	"auth-service/pkg/authclient"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// Значения по умолчанию.
+const (
+	DefaultRefreshInterval = 10 * time.Minute
+	DefaultHTTPTimeout     = 5 * time.Second
+	DefaultLeeway          = 30 * time.Second
+)
+
+// Результаты в метриках.
+const (
+	resultOK    = "ok"
+	resultError = "error"
+)
+
+var (
+	// ErrUntrustedIssuer - издатель токена не входит в доверенные.
+	ErrUntrustedIssuer = errors.New("untrusted token issuer")
+	// ErrUnknownKey - у издателя нет ключа с kid токена.
+	ErrUnknownKey = errors.New("unknown peer signing key")
+	// ErrInvalidToken - подпись или claims токена неверны.
+	ErrInvalidToken = errors.New("invalid peer token")
+)
+
+// allowedMethods - алгоритмы подписи с открытым ключом. HMAC не принимается: общий секрет
+// с peer сервисом нельзя получить по JWKS.
+func allowedMethods() []string {
+	return []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+}
+
+// Issuer - доверенный издатель.
+type Issuer struct {
+	// Name - имя peer сервиса в метриках, логах и claims.
+	Name string
+	// Issuer - значение claim iss токенов сервиса.
+	Issuer string
+	// JWKSURL - адрес JWKS с открытыми ключами сервиса.
+	JWKSURL string
+}
+
+// Claims - проверенные claims токена peer сервиса.
+type Claims struct {
+	// Peer - имя peer сервиса, выпустившего токен.
+	Peer      string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time
+}
+
+// keySet - ключи одного издателя.
+type keySet struct {
+	issuer Issuer
+	jwks   *authclient.JWKS
+}
+
+// TrustStore - доверенные peer издатели и кэш их ключей.
+type TrustStore struct {
+	issuers  map[string]*keySet // iss -> ключи
+	audience string
+	interval time.Duration
+	leeway   time.Duration
+	client   *http.Client
+
+	registerer prometheus.Registerer
+	refreshes  *prometheus.CounterVec
+	verified   *prometheus.CounterVec
+}
+
+// Option - опция для настройки TrustStore.
+type Option func(*TrustStore)
+
+// WithIssuers устанавливает доверенных издателей.
+func WithIssuers(issuers ...Issuer) Option {
+	return func(s *TrustStore) {
+		for _, issuer := range issuers {
+			s.issuers[issuer.Issuer] = &keySet{issuer: issuer}
+		}
+	}
+}
+
+// WithAudience устанавливает aud, который должен быть в токенах peer сервисов - имя этого сервиса.
+func WithAudience(audience string) Option {
+	return func(s *TrustStore) {
+		s.audience = audience
+	}
+}
+
+// WithRefreshInterval устанавливает периодичность обновления ключей. По умолчанию DefaultRefreshInterval.
+func WithRefreshInterval(interval time.Duration) Option {
+	return func(s *TrustStore) {
+		s.interval = interval
+	}
+}
+
+// WithHTTPClient устанавливает HTTP клиент для загрузки JWKS. По умолчанию клиент с таймаутом DefaultHTTPTimeout.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *TrustStore) {
+		s.client = client
+	}
+}
+
+// WithRegisterer устанавливает реестр метрик. По умолчанию используется prometheus.DefaultRegisterer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(s *TrustStore) {
+		s.registerer = registerer
+	}
+}
+
+// New создает хранилище доверенных издателей и загружает их ключи. Недоступный JWKS не мешает
+// запуску: ключи издателя загрузятся при следующем обновлении или при первом токене.
+func New(ctx context.Context, opts ...Option) (*TrustStore, error) {
+	s := &TrustStore{
+		issuers:    map[string]*keySet{},
+		interval:   DefaultRefreshInterval,
+		leeway:     DefaultLeeway,
+		client:     &http.Client{Timeout: DefaultHTTPTimeout},
+		registerer: prometheus.DefaultRegisterer,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	for _, set := range s.issuers {
+		jwks, err := authclient.NewJWKS(set.issuer.JWKSURL,
+			authclient.WithHTTPClient(s.client),
+			authclient.WithRefreshInterval(s.interval),
+			authclient.WithMaxStale(max(authclient.DefaultMaxStale, s.interval)),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("peer %s: %w", set.issuer.Name, err)
+		}
+
+		set.jwks = jwks
+	}
+
+	s.refreshes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_peer_jwks_refresh_total",
+		Help: "Количество плановых загрузок JWKS peer сервисов по результату.",
+	}, []string{"peer", "result"})
+
+	s.verified = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_peer_tokens_total",
+		Help: "Количество проверенных токенов peer сервисов по результату.",
+	}, []string{"peer", "result"})
+
+	for _, c := range []prometheus.Collector{s.refreshes, s.verified} {
+		if err := s.registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.Refresh(ctx); err != nil {
+		logrus.WithError(err).Warn("error load peer jwks, will retry")
+	}
+
+	return s, nil
+}
+
+func (s *TrustStore) validate() error {
+	if len(s.issuers) == 0 {
+		return errors.New("at least one issuer is required")
+	}
+
+	names := map[string]struct{}{}
+
+	for iss, set := range s.issuers {
+		if iss == "" || set.issuer.Name == "" || set.issuer.JWKSURL == "" {
+			return errors.New("issuer name, iss and jwks url are required")
+		}
+
+		if _, ok := names[set.issuer.Name]; ok {
+			return fmt.Errorf("duplicate peer name %q", set.issuer.Name)
+		}
+
+		names[set.issuer.Name] = struct{}{}
+	}
+
+	if s.audience == "" {
+		return errors.New("audience is required")
+	}
+
+	if s.interval <= 0 {
+		return errors.New("refresh interval must be positive")
+	}
+
+	if s.client == nil {
+		return errors.New("http client is required")
+	}
+
+	if s.registerer == nil {
+		return errors.New("registerer is required")
+	}
+
+	return nil
+}
+
+// Refresh загружает ключи всех издателей. Ошибка одного издателя не мешает остальным,
+// его ранее загруженные ключи продолжают действовать.
+func (s *TrustStore) Refresh(ctx context.Context) error {
+	var errs []error
+
+	for _, set := range s.issuers {
+		if err := s.refresh(ctx, set); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *TrustStore) refresh(ctx context.Context, set *keySet) error {
+	err := set.jwks.Refresh(ctx)
+	if err != nil {
+		err = fmt.Errorf("peer %s: %w", set.issuer.Name, err)
+	}
+
+	result := resultOK
+	if err != nil {
+		result = resultError
+	}
+
+	s.refreshes.WithLabelValues(set.issuer.Name, result).Inc()
+
+	return err
+}
+
+// Start периодически обновляет ключи до отмены контекста.
+func (s *TrustStore) Start(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+				logrus.WithError(err).Warn("error refresh peer jwks, keeping previous keys")
+			}
+		}
+	}
+}
+
+// Verify проверяет токен peer сервиса: издатель должен быть доверенным, подпись - верной,
+// aud - содержать имя этого сервиса, срок действия - обязателен.
+func (s *TrustStore) Verify(ctx context.Context, raw string) (*Claims, error) {
+	var set *keySet
+
+	claims := &jwt.RegisteredClaims{}
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(allowedMethods()),
+		jwt.WithAudience(s.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(s.leeway),
+	)
+
+	_, err := parser.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		var ok bool
+
+		set, ok = s.issuers[claims.Issuer]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUntrustedIssuer, claims.Issuer)
+		}
+
+		key, err := set.jwks.Keyfunc(ctx)(t)
+		if errors.Is(err, authclient.ErrUnknownKey) {
+			return nil, fmt.Errorf("%w: peer %s: %w", ErrUnknownKey, set.issuer.Name, err)
+		}
+
+		return key, err
+	})
+	if err != nil {
+		s.observe(set, resultError)
+
+		if errors.Is(err, ErrUntrustedIssuer) || errors.Is(err, ErrUnknownKey) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	s.observe(set, resultOK)
+
+	return &Claims{
+		Peer:      set.issuer.Name,
+		Subject:   claims.Subject,
+		Audience:  claims.Audience,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+func (s *TrustStore) observe(set *keySet, result string) {
+	peer := "untrusted"
+	if set != nil {
+		peer = set.issuer.Name
+	}
+
+	s.verified.WithLabelValues(peer, result).Inc()
+}
+
+// Peers возвращает имена доверенных peer сервисов.
+func (s *TrustStore) Peers() []string {
+	names := make([]string, 0, len(s.issuers))
+	for _, set := range s.issuers {
+		names = append(names, set.issuer.Name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
@@ -0,0 +1,317 @@
+package peer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "https://notes.example"
+	testAudience = "auth-service"
+)
+
+// jwksServer - JWKS peer сервиса.
+type jwksServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	keys     []jose.JSONWebKey
+	requests atomic.Int32
+}
+
+func newJWKSServer(t *testing.T, keys ...jose.JSONWebKey) *jwksServer {
+	t.Helper()
+
+	s := &jwksServer{keys: keys}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		s.requests.Add(1)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: s.keys})
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *jwksServer) setKeys(keys ...jose.JSONWebKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys = keys
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	return key
+}
+
+func newECKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return key
+}
+
+func publicJWK(key crypto.Signer, kid, alg string) jose.JSONWebKey {
+	return jose.JSONWebKey{Key: key.Public(), KeyID: kid, Algorithm: alg, Use: "sig"}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.RegisteredClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+
+	raw, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return raw
+}
+
+func validClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Issuer:    testIssuer,
+		Subject:   "notes-bot",
+		Audience:  jwt.ClaimStrings{testAudience},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute).Truncate(time.Second)),
+	}
+}
+
+func newStore(t *testing.T, jwksURL string) *TrustStore {
+	t.Helper()
+
+	s, err := New(t.Context(),
+		WithIssuers(Issuer{Name: "notes", Issuer: testIssuer, JWKSURL: jwksURL}),
+		WithAudience(testAudience),
+		WithRegisterer(prometheus.NewRegistry()),
+	)
+	require.NoError(t, err)
+
+	return s
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	server := newJWKSServer(t, publicJWK(newRSAKey(t), "rsa-1", "RS256"))
+	issuer := Issuer{Name: "notes", Issuer: testIssuer, JWKSURL: server.URL}
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{
+			name: "positive case",
+			opts: []Option{WithIssuers(issuer), WithAudience(testAudience)},
+		},
+		{
+			name: "positive case: jwks is unavailable",
+			opts: []Option{
+				WithIssuers(Issuer{Name: "notes", Issuer: testIssuer, JWKSURL: "http://127.0.0.1:1/jwks.json"}),
+				WithAudience(testAudience),
+			},
+		},
+		{
+			name:    "error case: no issuers",
+			opts:    []Option{WithAudience(testAudience)},
+			wantErr: true,
+		},
+		{
+			name:    "error case: no audience",
+			opts:    []Option{WithIssuers(issuer)},
+			wantErr: true,
+		},
+		{
+			name:    "error case: no jwks url",
+			opts:    []Option{WithIssuers(Issuer{Name: "notes", Issuer: testIssuer}), WithAudience(testAudience)},
+			wantErr: true,
+		},
+		{
+			name: "error case: duplicate name",
+			opts: []Option{
+				WithIssuers(issuer, Issuer{Name: "notes", Issuer: "https://other.example", JWKSURL: server.URL}),
+				WithAudience(testAudience),
+			},
+			wantErr: true,
+		},
+		{
+			name:    "error case: invalid refresh interval",
+			opts:    []Option{WithIssuers(issuer), WithAudience(testAudience), WithRefreshInterval(0)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := New(t.Context(), append(tt.opts, WithRegisterer(prometheus.NewRegistry()))...)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, []string{"notes"}, s.Peers())
+		})
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestTrustStore_Verify(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, ecKey, otherKey := newRSAKey(t), newECKey(t), newRSAKey(t)
+
+	server := newJWKSServer(t, publicJWK(rsaKey, "rsa-1", "RS256"), publicJWK(ecKey, "ec-1", ""))
+	s := newStore(t, server.URL)
+
+	claims := func(modify func(c *jwt.RegisteredClaims)) jwt.RegisteredClaims {
+		c := validClaims()
+		if modify != nil {
+			modify(&c)
+		}
+
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr error
+	}{
+		{
+			name: "positive case: rsa",
+			raw:  sign(t, jwt.SigningMethodRS256, rsaKey, "rsa-1", claims(nil)),
+		},
+		{
+			name: "positive case: ecdsa",
+			raw:  sign(t, jwt.SigningMethodES256, ecKey, "ec-1", claims(nil)),
+		},
+		{
+			name:    "error case: untrusted issuer",
+			raw:     sign(t, jwt.SigningMethodRS256, rsaKey, "rsa-1", claims(func(c *jwt.RegisteredClaims) { c.Issuer = "https://evil.example" })),
+			wantErr: ErrUntrustedIssuer,
+		},
+		{
+			name:    "error case: wrong audience",
+			raw:     sign(t, jwt.SigningMethodRS256, rsaKey, "rsa-1", claims(func(c *jwt.RegisteredClaims) { c.Audience = jwt.ClaimStrings{"billing"} })),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "error case: expired",
+			raw:     sign(t, jwt.SigningMethodRS256, rsaKey, "rsa-1", claims(func(c *jwt.RegisteredClaims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour)) })),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "error case: no expiration",
+			raw:     sign(t, jwt.SigningMethodRS256, rsaKey, "rsa-1", claims(func(c *jwt.RegisteredClaims) { c.ExpiresAt = nil })),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "error case: signed by other key",
+			raw:     sign(t, jwt.SigningMethodRS256, otherKey, "rsa-1", claims(nil)),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "error case: algorithm does not match key",
+			raw:     sign(t, jwt.SigningMethodPS256, rsaKey, "rsa-1", claims(nil)),
+			wantErr: ErrUnknownKey,
+		},
+		{
+			name:    "error case: hmac",
+			raw:     sign(t, jwt.SigningMethodHS256, []byte("secret"), "rsa-1", claims(nil)),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "error case: not a jwt",
+			raw:     "not-a-jwt",
+			wantErr: ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := s.Verify(t.Context(), tt.raw)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, &Claims{
+				Peer:      "notes",
+				Subject:   "notes-bot",
+				Audience:  []string{testAudience},
+				ExpiresAt: validClaims().ExpiresAt.Time,
+			}, got)
+		})
+	}
+}
+
+func TestTrustStore_Rotation(t *testing.T) {
+	t.Parallel()
+
+	oldKey, newKey := newRSAKey(t), newRSAKey(t)
+
+	server := newJWKSServer(t, publicJWK(oldKey, "rsa-1", "RS256"))
+	s := newStore(t, server.URL)
+	assert.Equal(t, int32(1), server.requests.Load())
+
+	// peer сервис сменил ключ - неизвестный kid вызывает загрузку JWKS
+	server.setKeys(publicJWK(oldKey, "rsa-1", "RS256"), publicJWK(newKey, "rsa-2", "RS256"))
+
+	_, err := s.Verify(t.Context(), sign(t, jwt.SigningMethodRS256, newKey, "rsa-2", validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), server.requests.Load())
+
+	// повторные неизвестные kid не загружают JWKS чаще authclient.DefaultMinRefetchInterval
+	for range 3 {
+		_, err = s.Verify(t.Context(), sign(t, jwt.SigningMethodRS256, newKey, "rsa-3", validClaims()))
+		require.ErrorIs(t, err, ErrUnknownKey)
+	}
+
+	assert.Equal(t, int32(2), server.requests.Load())
+
+	// ошибка загрузки не удаляет ранее загруженные ключи
+	server.Close()
+	require.Error(t, s.Refresh(t.Context()))
+
+	_, err = s.Verify(t.Context(), sign(t, jwt.SigningMethodRS256, oldKey, "rsa-1", validClaims()))
+	require.NoError(t, err)
+
+	// внеочередные загрузки не учитываются в метрике плановых
+	assert.InDelta(t, 1, testutil.ToFloat64(s.refreshes.WithLabelValues("notes", resultOK)), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(s.refreshes.WithLabelValues("notes", resultError)), 0)
+	assert.InDelta(t, 3, testutil.ToFloat64(s.verified.WithLabelValues("notes", resultError)), 0)
+}
@@ -0,0 +1,191 @@
+// Package policy встраивает движок политик Casbin для проверки доступа.
+// Модель и правила загружаются из файлов или Vault и перечитываются без перезапуска сервиса,
+// поэтому сложные правила (время суток, владение ресурсом) задаются в политиках, а не в коде.
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	stringadapter "github.com/casbin/casbin/v2/persist/string-adapter"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultReloadInterval = 30 * time.Second
+
+// Env - атрибуты запроса, доступные в матчерах модели как r.env.<Поле>.
+type Env struct {
+	// Role - роль субъекта в группе, если ресурс - группа.
+	Role         string
+	ResourceType string
+	ResourceID   string
+	// Owner - владелец ресурса, если его передал вызывающий сервис.
+	Owner string
+	// Hour и Weekday - время запроса в UTC (Weekday: 0 - воскресенье).
+	Hour    int
+	Weekday int
+}
+
+// Input - запрос к движку: r = sub, obj, act, env.
+type Input struct {
+	Subject  string
+	Resource string
+	Action   string
+	Env      Env
+}
+
+// source - источник модели и правил.
+type source interface {
+	Load(ctx context.Context) (Policy, error)
+}
+
+// Engine - движок политик с горячей перезагрузкой.
+type Engine struct {
+	source   source
+	interval time.Duration
+
+	mu       sync.RWMutex
+	enforcer *casbin.Enforcer
+	loaded   Policy
+}
+
+// Option - опция для настройки Engine.
+type Option func(*Engine)
+
+// WithSource устанавливает источник модели и правил.
+func WithSource(src source) Option {
+	return func(e *Engine) {
+		e.source = src
+	}
+}
+
+// WithReloadInterval устанавливает периодичность перечитывания политик. По умолчанию 30s.
+func WithReloadInterval(interval time.Duration) Option {
+	return func(e *Engine) {
+		e.interval = interval
+	}
+}
+
+// New создает движок и загружает политики. Ошибка загрузки при старте фатальна:
+// сервис не должен принимать решения без политик.
+func New(ctx context.Context, opts ...Option) (*Engine, error) {
+	e := &Engine{interval: defaultReloadInterval}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if e.source == nil {
+		return nil, errors.New("source is required")
+	}
+
+	if e.interval <= 0 {
+		return nil, errors.New("reload interval must be positive")
+	}
+
+	if _, err := e.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Reload перечитывает политики. Если они не изменились, движок не пересоздается.
+// При ошибке продолжают действовать ранее загруженные политики. Возвращает true, если политики обновлены.
+func (e *Engine) Reload(ctx context.Context) (bool, error) {
+	p, err := e.source.Load(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	e.mu.RLock()
+	unchanged := e.enforcer != nil && p == e.loaded
+	e.mu.RUnlock()
+
+	if unchanged {
+		return false, nil
+	}
+
+	m, err := model.NewModelFromString(p.Model)
+	if err != nil {
+		return false, fmt.Errorf("policy: invalid model: %w", err)
+	}
+
+	enforcer, err := casbin.NewEnforcer(m, stringadapter.NewAdapter(p.Rules))
+	if err != nil {
+		return false, fmt.Errorf("policy: invalid rules: %w", err)
+	}
+
+	enforcer.AddFunction("int", toInt)
+
+	e.mu.Lock()
+	e.enforcer = enforcer
+	e.loaded = p
+	e.mu.Unlock()
+
+	logrus.Info("authorization policies loaded")
+
+	return true, nil
+}
+
+// Start периодически перечитывает политики до отмены контекста.
+func (e *Engine) Start(ctx context.Context) error {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := e.Reload(ctx); err != nil {
+				logrus.WithError(err).Error("error reload authorization policies, keeping previous")
+			}
+		}
+	}
+}
+
+// Enforce возвращает решение движка для запроса.
+func (e *Engine) Enforce(in Input) (bool, error) {
+	e.mu.RLock()
+	enforcer := e.enforcer
+	e.mu.RUnlock()
+
+	allowed, err := enforcer.Enforce(in.Subject, in.Resource, in.Action, in.Env)
+	if err != nil {
+		return false, fmt.Errorf("policy: error enforce: %w", err)
+	}
+
+	return allowed, nil
+}
+
+// toInt - функция int(x) для матчеров: значения правил всегда строки,
+// а сравнивать их нужно с числовыми атрибутами (например, r.env.Hour >= int(p.from)).
+func toInt(args ...interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, errors.New("int: expected 1 argument")
+	}
+
+	switch v := args[0].(type) {
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("int: %w", err)
+		}
+
+		return float64(n), nil
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	}
+
+	return nil, fmt.Errorf("int: unsupported type %T", args[0])
+}
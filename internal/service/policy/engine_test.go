@@ -0,0 +1,193 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testModel - модель с ролями в группах и ограничением по времени суток.
+const testModel = `
+[request_definition]
+r = sub, obj, act, env
+
+[policy_definition]
+p = role, act, from, to
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = r.env.Role == p.role && r.act == p.act && r.env.Hour >= int(p.from) && r.env.Hour < int(p.to) || r.env.Owner == r.sub
+`
+
+const testRules = `
+p, editor, write, 9, 18
+p, viewer, read, 0, 24
+`
+
+// fakeSource - источник, возвращающий политики по очереди. Последний ответ повторяется.
+type fakeSource struct {
+	mu        sync.Mutex
+	responses []sourceResponse
+	calls     int
+}
+
+type sourceResponse struct {
+	policy Policy
+	err    error
+}
+
+func newFakeSource(responses ...sourceResponse) *fakeSource {
+	return &fakeSource{responses: responses}
+}
+
+func (f *fakeSource) Load(_ context.Context) (Policy, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r := f.responses[min(f.calls, len(f.responses)-1)]
+	f.calls++
+
+	return r.policy, r.err
+}
+
+func (f *fakeSource) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls
+}
+
+var validPolicy = sourceResponse{policy: Policy{Model: testModel, Rules: testRules}}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		src     *fakeSource
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			src:     newFakeSource(validPolicy),
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: invalid model",
+			src:     newFakeSource(sourceResponse{policy: Policy{Model: "[request_definition]"}}),
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: source error",
+			src:     newFakeSource(sourceResponse{err: errors.New("vault is sealed")}),
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: invalid interval",
+			src:     newFakeSource(validPolicy),
+			opts:    []Option{WithReloadInterval(0)},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(t.Context(), append([]Option{WithSource(tt.src)}, tt.opts...)...)
+			tt.wantErr(t, err)
+		})
+	}
+
+	_, err := New(t.Context())
+	require.Error(t, err)
+}
+
+func TestEngine_Enforce(t *testing.T) {
+	t.Parallel()
+
+	e, err := New(t.Context(), WithSource(newFakeSource(validPolicy)))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		in   Input
+		want bool
+	}{
+		{name: "editor writes in working hours", in: Input{Subject: "u1", Action: "write", Env: Env{Role: "editor", Hour: 10}}, want: true},
+		{name: "editor writes at night", in: Input{Subject: "u1", Action: "write", Env: Env{Role: "editor", Hour: 23}}, want: false},
+		{name: "viewer writes", in: Input{Subject: "u1", Action: "write", Env: Env{Role: "viewer", Hour: 10}}, want: false},
+		{name: "owner writes at night", in: Input{Subject: "u1", Action: "write", Env: Env{Owner: "u1", Hour: 23}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := e.Enforce(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEngine_Reload(t *testing.T) {
+	t.Parallel()
+
+	src := newFakeSource(
+		validPolicy,
+		// политики не изменились
+		validPolicy,
+		// некорректная модель - остаются прежние политики
+		sourceResponse{policy: Policy{Model: "broken"}},
+		// editor может писать круглосуточно
+		sourceResponse{policy: Policy{Model: testModel, Rules: "p, editor, write, 0, 24"}},
+	)
+
+	e, err := New(t.Context(), WithSource(src))
+	require.NoError(t, err)
+
+	night := Input{Subject: "u1", Action: "write", Env: Env{Role: "editor", Hour: 23}}
+
+	updated, err := e.Reload(t.Context())
+	require.NoError(t, err)
+	assert.False(t, updated)
+
+	_, err = e.Reload(t.Context())
+	require.Error(t, err)
+
+	allowed, err := e.Enforce(night)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	updated, err = e.Reload(t.Context())
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	allowed, err = e.Enforce(night)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestEngine_Start(t *testing.T) {
+	t.Parallel()
+
+	src := newFakeSource(validPolicy)
+
+	e, err := New(t.Context(), WithSource(src), WithReloadInterval(10*time.Millisecond))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	require.NoError(t, e.Start(ctx))
+	assert.GreaterOrEqual(t, src.Calls(), 2)
+}
@@ -0,0 +1,63 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Policy - модель Casbin и правила в формате CSV.
+type Policy struct {
+	Model string
+	Rules string
+}
+
+// FileSource - модель и правила в файлах.
+type FileSource struct {
+	ModelPath string
+	RulesPath string
+}
+
+// Load читает модель и правила из файлов.
+func (s FileSource) Load(_ context.Context) (Policy, error) {
+	model, err := os.ReadFile(s.ModelPath)
+	if err != nil {
+		return Policy{}, fmt.Errorf("policy: error read model: %w", err)
+	}
+
+	rules, err := os.ReadFile(s.RulesPath)
+	if err != nil {
+		return Policy{}, fmt.Errorf("policy: error read rules: %w", err)
+	}
+
+	return Policy{Model: string(model), Rules: string(rules)}, nil
+}
+
+// kvReader - интерфейс для чтения секретов KV из Vault.
+type kvReader interface {
+	ReadKV(ctx context.Context, path string) (map[string]interface{}, error)
+}
+
+// VaultSource - модель и правила в секрете Vault KV v2 (поля model и policy).
+type VaultSource struct {
+	Client kvReader
+	Path   string
+}
+
+// Load читает модель и правила из Vault.
+func (s VaultSource) Load(ctx context.Context) (Policy, error) {
+	data, err := s.Client.ReadKV(ctx, s.Path)
+	if err != nil {
+		return Policy{}, fmt.Errorf("policy: error read policy from vault: %w", err)
+	}
+
+	model, _ := data["model"].(string)
+	rules, _ := data["policy"].(string)
+
+	if model == "" {
+		return Policy{}, errors.New("policy: model is empty")
+	}
+
+	return Policy{Model: model, Rules: rules}, nil
+}
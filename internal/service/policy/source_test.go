@@ -0,0 +1,88 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeKV struct {
+	data map[string]interface{}
+	err  error
+}
+
+func (f fakeKV) ReadKV(_ context.Context, _ string) (map[string]interface{}, error) {
+	return f.data, f.err
+}
+
+func TestFileSource_Load(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	modelPath := filepath.Join(dir, "model.conf")
+	rulesPath := filepath.Join(dir, "policy.csv")
+
+	require.NoError(t, os.WriteFile(modelPath, []byte(testModel), 0o600))
+	require.NoError(t, os.WriteFile(rulesPath, []byte(testRules), 0o600))
+
+	got, err := FileSource{ModelPath: modelPath, RulesPath: rulesPath}.Load(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, Policy{Model: testModel, Rules: testRules}, got)
+
+	_, err = FileSource{ModelPath: filepath.Join(dir, "missing"), RulesPath: rulesPath}.Load(t.Context())
+	require.Error(t, err)
+
+	_, err = FileSource{ModelPath: modelPath, RulesPath: filepath.Join(dir, "missing")}.Load(t.Context())
+	require.Error(t, err)
+}
+
+func TestVaultSource_Load(t *testing.T) {
+	t.Parallel()
+
+	got, err := VaultSource{Client: fakeKV{data: map[string]interface{}{"model": testModel, "policy": testRules}}}.Load(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, Policy{Model: testModel, Rules: testRules}, got)
+
+	_, err = VaultSource{Client: fakeKV{data: map[string]interface{}{"policy": testRules}}}.Load(t.Context())
+	require.Error(t, err)
+
+	_, err = VaultSource{Client: fakeKV{err: errors.New("vault is sealed")}}.Load(t.Context())
+	require.ErrorContains(t, err, "vault is sealed")
+}
+
+// TestExamplePolicies проверяет, что политики из репозитория загружаются и работают.
+func TestExamplePolicies(t *testing.T) {
+	t.Parallel()
+
+	e, err := New(t.Context(), WithSource(FileSource{
+		ModelPath: "../../../policies/authz_model.conf",
+		RulesPath: "../../../policies/authz_policy.csv",
+	}))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		in   Input
+		want bool
+	}{
+		{name: "editor writes", in: Input{Subject: "u1", Action: "write", Env: Env{Role: "editor", ResourceType: "group", Hour: 3}}, want: true},
+		{name: "viewer writes", in: Input{Subject: "u1", Action: "write", Env: Env{Role: "viewer", ResourceType: "group", Hour: 12}}, want: false},
+		{name: "owner manages at night", in: Input{Subject: "u1", Action: "manage", Env: Env{Role: "owner", ResourceType: "group", Hour: 23}}, want: false},
+		{name: "resource owner", in: Input{Subject: "u1", Action: "write", Env: Env{Owner: "u1", ResourceType: "note"}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := e.Enforce(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+// Package pow реализует proof-of-work защиту (в стиле hashcash) для часто атакуемых
+// неаутентифицированных эндпоинтов. Это легкая альтернатива сторонним CAPTCHA:
+// клиент получает challenge и перебирает nonce, пока sha256(challenge:nonce)
+// не начнется с заданного количества нулевых бит.
+//
+// Challenge не хранится на сервере: он подписан HMAC и содержит время истечения и маршрут.
+// Использованные challenge запоминаются до истечения, чтобы решение нельзя было переиспользовать.
+package pow
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDifficulty = 20
+	defaultTTL        = 2 * time.Minute
+
+	// maxDifficulty - ограничение сложности, чтобы легитимные клиенты решали задачу за разумное время.
+	maxDifficulty = 32
+
+	secretLength = 32
+	nonceLength  = 16
+)
+
+var (
+	// ErrInvalidSolution - решение отсутствует, подделано или не удовлетворяет сложности.
+	ErrInvalidSolution = errors.New("invalid proof-of-work solution")
+	// ErrExpired - срок действия challenge истек.
+	ErrExpired = errors.New("proof-of-work challenge expired")
+	// ErrReused - решение уже использовано.
+	ErrReused = errors.New("proof-of-work solution already used")
+)
+
+// Service - выдача и проверка proof-of-work задач.
+type Service struct {
+	secret     []byte
+	difficulty int
+	ttl        time.Duration
+
+	mu   sync.Mutex
+	used map[string]time.Time
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Service.
+type Option func(*Service)
+
+// WithSecret устанавливает ключ подписи challenge. Нужен, если сервис запущен в нескольких экземплярах:
+// challenge, выданный одним экземпляром, должен проверяться другим. По умолчанию генерируется случайный.
+func WithSecret(secret string) Option {
+	return func(s *Service) {
+		s.secret = []byte(secret)
+	}
+}
+
+// WithDifficulty устанавливает количество нулевых бит в начале хэша. По умолчанию 20.
+func WithDifficulty(difficulty int) Option {
+	return func(s *Service) {
+		s.difficulty = difficulty
+	}
+}
+
+// WithTTL устанавливает время жизни challenge. По умолчанию 2m.
+func WithTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		s.ttl = ttl
+	}
+}
+
+// New создает новый Service.
+func New(opts ...Option) (*Service, error) {
+	s := &Service{
+		difficulty: defaultDifficulty,
+		ttl:        defaultTTL,
+		used:       map[string]time.Time{},
+		now:        time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.difficulty < 1 || s.difficulty > maxDifficulty {
+		return nil, fmt.Errorf("difficulty must be in [1, %d]", maxDifficulty)
+	}
+
+	if s.ttl <= 0 {
+		return nil, errors.New("ttl must be positive")
+	}
+
+	if len(s.secret) == 0 {
+		s.secret = make([]byte, secretLength)
+
+		if _, err := rand.Read(s.secret); err != nil {
+			return nil, fmt.Errorf("pow: error generate secret: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+// Difficulty возвращает сложность задач.
+func (s *Service) Difficulty() int {
+	return s.difficulty
+}
+
+// Challenge выдает новый challenge для маршрута в формате <expires>.<nonce>.<подпись>.
+func (s *Service) Challenge(route string) (string, error) {
+	nonce := make([]byte, nonceLength)
+
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("pow: error generate nonce: %w", err)
+	}
+
+	payload := strconv.FormatInt(s.now().Add(s.ttl).Unix(), 10) + "." + hex.EncodeToString(nonce)
+
+	return payload + "." + s.sign(route, payload), nil
+}
+
+// Verify проверяет решение вида <challenge>:<nonce> для маршрута.
+// Успешно проверенное решение больше не принимается.
+func (s *Service) Verify(route, solution string) error {
+	challenge, nonce, ok := strings.Cut(solution, ":")
+	if !ok || nonce == "" {
+		return ErrInvalidSolution
+	}
+
+	parts := strings.Split(challenge, ".")
+	if len(parts) != 3 {
+		return ErrInvalidSolution
+	}
+
+	payload := parts[0] + "." + parts[1]
+
+	if !hmac.Equal([]byte(parts[2]), []byte(s.sign(route, payload))) {
+		return ErrInvalidSolution
+	}
+
+	expires, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return ErrInvalidSolution
+	}
+
+	now := s.now()
+	expiresAt := time.Unix(expires, 0)
+
+	if !now.Before(expiresAt) {
+		return ErrExpired
+	}
+
+	if leadingZeroBits(sha256.Sum256([]byte(solution))) < s.difficulty {
+		return ErrInvalidSolution
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	if _, ok := s.used[challenge]; ok {
+		return ErrReused
+	}
+
+	s.used[challenge] = expiresAt
+
+	return nil
+}
+
+// sign подписывает challenge вместе с маршрутом и сложностью,
+// чтобы решение для одного эндпоинта нельзя было использовать для другого.
+func (s *Service) sign(route, payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	_, _ = fmt.Fprintf(mac, "%s|%d|%s", route, s.difficulty, payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// sweep удаляет истекшие challenge. Вызывается под мьютексом.
+func (s *Service) sweep(now time.Time) {
+	for challenge, expiresAt := range s.used {
+		if !now.Before(expiresAt) {
+			delete(s.used, challenge)
+		}
+	}
+}
+
+// Solve перебирает nonce для challenge. Используется в тестах и клиентах на Go.
+func Solve(challenge string, difficulty int) string {
+	for i := 0; ; i++ {
+		solution := challenge + ":" + strconv.Itoa(i)
+
+		if leadingZeroBits(sha256.Sum256([]byte(solution))) >= difficulty {
+			return solution
+		}
+	}
+}
+
+// leadingZeroBits возвращает количество нулевых бит в начале хэша.
+func leadingZeroBits(hash [sha256.Size]byte) int {
+	n := 0
+
+	for _, b := range hash {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+
+		n += 8
+	}
+
+	return n
+}
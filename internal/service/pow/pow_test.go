@@ -0,0 +1,212 @@
+package pow
+
+import (
+	"crypto/sha256"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "defaults",
+			wantErr: require.NoError,
+		},
+		{
+			name:    "custom",
+			opts:    []Option{WithSecret("secret"), WithDifficulty(8), WithTTL(time.Minute)},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "zero difficulty",
+			opts:    []Option{WithDifficulty(0)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "too high difficulty",
+			opts:    []Option{WithDifficulty(maxDifficulty + 1)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative ttl",
+			opts:    []Option{WithTTL(-time.Second)},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := New(tt.opts...)
+			tt.wantErr(t, err)
+
+			if err == nil {
+				assert.NotEmpty(t, s.secret)
+			}
+		})
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	const route = "/api/v0/otp/request"
+
+	s, err := New(WithSecret("secret"), WithDifficulty(8), WithTTL(time.Minute))
+	require.NoError(t, err)
+
+	challenge, err := s.Challenge(route)
+	require.NoError(t, err)
+
+	solution := Solve(challenge, s.Difficulty())
+
+	other, err := New(WithSecret("other"), WithDifficulty(8))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		svc      *Service
+		route    string
+		solution string
+		wantErr  error
+	}{
+		{
+			name:     "empty",
+			svc:      s,
+			route:    route,
+			solution: "",
+			wantErr:  ErrInvalidSolution,
+		},
+		{
+			name:     "malformed challenge",
+			svc:      s,
+			route:    route,
+			solution: "abc:1",
+			wantErr:  ErrInvalidSolution,
+		},
+		{
+			name:     "other route",
+			svc:      s,
+			route:    "/api/v0/register",
+			solution: solution,
+			wantErr:  ErrInvalidSolution,
+		},
+		{
+			name:     "other secret",
+			svc:      other,
+			route:    route,
+			solution: solution,
+			wantErr:  ErrInvalidSolution,
+		},
+		{
+			name:     "tampered expiry",
+			svc:      s,
+			route:    route,
+			solution: "9" + solution,
+			wantErr:  ErrInvalidSolution,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.ErrorIs(t, tt.svc.Verify(tt.route, tt.solution), tt.wantErr)
+		})
+	}
+
+	t.Run("valid then reused", func(t *testing.T) {
+		t.Parallel()
+
+		require.NoError(t, s.Verify(route, solution))
+		require.ErrorIs(t, s.Verify(route, solution), ErrReused)
+	})
+}
+
+func TestVerify_InsufficientWork(t *testing.T) {
+	t.Parallel()
+
+	s, err := New(WithDifficulty(16))
+	require.NoError(t, err)
+
+	challenge, err := s.Challenge("/r")
+	require.NoError(t, err)
+
+	for i := 0; ; i++ {
+		solution := challenge + ":" + strconv.Itoa(i)
+		if leadingZeroBits(sha256.Sum256([]byte(solution))) < s.Difficulty() {
+			require.ErrorIs(t, s.Verify("/r", solution), ErrInvalidSolution)
+
+			return
+		}
+	}
+}
+
+func TestVerify_Expired(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+
+	s, err := New(WithDifficulty(4), WithTTL(time.Minute))
+	require.NoError(t, err)
+
+	s.now = func() time.Time { return now }
+
+	challenge, err := s.Challenge("/r")
+	require.NoError(t, err)
+
+	solution := Solve(challenge, s.Difficulty())
+
+	s.now = func() time.Time { return now.Add(time.Minute) }
+
+	require.ErrorIs(t, s.Verify("/r", solution), ErrExpired)
+}
+
+func TestVerify_SweepsExpired(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+
+	s, err := New(WithDifficulty(4), WithTTL(time.Minute))
+	require.NoError(t, err)
+
+	s.now = func() time.Time { return now }
+
+	first, err := s.Challenge("/r")
+	require.NoError(t, err)
+	require.NoError(t, s.Verify("/r", Solve(first, s.Difficulty())))
+	assert.Len(t, s.used, 1)
+
+	s.now = func() time.Time { return now.Add(2 * time.Minute) }
+
+	second, err := s.Challenge("/r")
+	require.NoError(t, err)
+	require.NoError(t, s.Verify("/r", Solve(second, s.Difficulty())))
+	assert.Len(t, s.used, 1)
+}
+
+func TestLeadingZeroBits(t *testing.T) {
+	t.Parallel()
+
+	var hash [32]byte
+
+	assert.Equal(t, 256, leadingZeroBits(hash))
+
+	hash[1] = 0x10
+	assert.Equal(t, 11, leadingZeroBits(hash))
+
+	hash[0] = 0x80
+	assert.Equal(t, 0, leadingZeroBits(hash))
+}
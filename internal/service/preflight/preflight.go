@@ -0,0 +1,177 @@
+// Package preflight проверяет при запуске, что у токена Vault есть права, которые нужны
+// включенным функциям сервиса (sys/capabilities-self). Без проверки нехватка прав проявляется
+// позже - ошибкой посреди запроса или фоновой задачи; с ней сервис сразу останавливается
+// с отчетом, каких прав и на какие пути не хватает.
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Права Vault.
+const (
+	CapabilityRead   = "read"
+	CapabilityCreate = "create"
+	CapabilityUpdate = "update"
+
+	// capabilityRoot - все права.
+	capabilityRoot = "root"
+	// capabilityDeny - нет прав, даже если они выданы другими политиками.
+	capabilityDeny = "deny"
+)
+
+// capabilitySource - источник прав токена на путь.
+type capabilitySource interface {
+	Capabilities(ctx context.Context, path string) ([]string, error)
+}
+
+// Requirement - права, которые нужны на путь Vault.
+type Requirement struct {
+	// Purpose - параметр конфигурации, которому нужен доступ, например token.keys_path.
+	Purpose      string
+	Path         string
+	Capabilities []string
+}
+
+// Result - результат проверки требования.
+type Result struct {
+	Requirement
+
+	// Granted - права токена на путь.
+	Granted []string
+	// Missing - требуемые права, которых у токена нет.
+	Missing []string
+}
+
+// Report - результат проверки всех требований.
+type Report struct {
+	Results []Result
+}
+
+// Failed возвращает требования, для которых не хватает прав.
+func (r *Report) Failed() []Result {
+	var failed []Result
+
+	for _, res := range r.Results {
+		if len(res.Missing) != 0 {
+			failed = append(failed, res)
+		}
+	}
+
+	return failed
+}
+
+// Err возвращает ошибку со списком недостающих прав или nil, если прав хватает.
+func (r *Report) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+
+	lines := make([]string, 0, len(failed))
+
+	for _, res := range failed {
+		lines = append(lines, fmt.Sprintf("%s: %s: missing %s (granted: %s)",
+			res.Purpose, res.Path, strings.Join(res.Missing, ","), strings.Join(res.Granted, ",")))
+	}
+
+	return fmt.Errorf("vault token lacks capabilities:\n  %s", strings.Join(lines, "\n  "))
+}
+
+// Checker - проверка прав токена Vault.
+type Checker struct {
+	source       capabilitySource
+	requirements []Requirement
+}
+
+// Option - опция для настройки Checker.
+type Option func(*Checker)
+
+// WithSource устанавливает источник прав токена - клиент Vault.
+func WithSource(source capabilitySource) Option {
+	return func(c *Checker) {
+		c.source = source
+	}
+}
+
+// WithRequirements добавляет требования к правам.
+func WithRequirements(requirements ...Requirement) Option {
+	return func(c *Checker) {
+		c.requirements = append(c.requirements, requirements...)
+	}
+}
+
+// New создает новый Checker.
+func New(opts ...Option) (*Checker, error) {
+	c := &Checker{}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.source == nil {
+		return nil, errors.New("capability source is required")
+	}
+
+	for _, req := range c.requirements {
+		if req.Path == "" || len(req.Capabilities) == 0 {
+			return nil, fmt.Errorf("requirement %q: path and capabilities are required", req.Purpose)
+		}
+	}
+
+	return c, nil
+}
+
+// Check запрашивает права токена на пути требований. Права на каждый путь запрашиваются один раз.
+// Ошибка возвращается, если права не удалось узнать; нехватку прав показывает Report.Err.
+func (c *Checker) Check(ctx context.Context) (*Report, error) {
+	granted := make(map[string][]string, len(c.requirements))
+	report := &Report{Results: make([]Result, 0, len(c.requirements))}
+
+	for _, req := range c.requirements {
+		capabilities, ok := granted[req.Path]
+		if !ok {
+			var err error
+
+			capabilities, err = c.source.Capabilities(ctx, req.Path)
+			if err != nil {
+				return nil, fmt.Errorf("preflight: error check %s (%s): %w", req.Path, req.Purpose, err)
+			}
+
+			granted[req.Path] = capabilities
+		}
+
+		report.Results = append(report.Results, Result{
+			Requirement: req,
+			Granted:     capabilities,
+			Missing:     missing(req.Capabilities, capabilities),
+		})
+	}
+
+	return report, nil
+}
+
+// missing возвращает права из required, которых нет в granted.
+func missing(required, granted []string) []string {
+	if slices.Contains(granted, capabilityDeny) {
+		return slices.Clone(required)
+	}
+
+	if slices.Contains(granted, capabilityRoot) {
+		return nil
+	}
+
+	var res []string
+
+	for _, capability := range required {
+		if !slices.Contains(granted, capability) {
+			res = append(res, capability)
+		}
+	}
+
+	return res
+}
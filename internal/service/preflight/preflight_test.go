@@ -0,0 +1,119 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticCapabilities - права токена по путям для тестов, считает запросы.
+type staticCapabilities struct {
+	paths map[string][]string
+	calls map[string]int
+}
+
+func (s *staticCapabilities) Capabilities(_ context.Context, path string) ([]string, error) {
+	s.calls[path]++
+
+	if path == "sys/broken" {
+		return nil, errors.New("vault is sealed")
+	}
+
+	if capabilities, ok := s.paths[path]; ok {
+		return capabilities, nil
+	}
+
+	return []string{capabilityDeny}, nil
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	source := &staticCapabilities{}
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr string
+	}{
+		{
+			name: "positive case",
+			opts: []Option{WithSource(source), WithRequirements(Requirement{Purpose: "keys", Path: "secret/data/keys", Capabilities: []string{CapabilityRead}})},
+		},
+		{name: "positive case: no requirements", opts: []Option{WithSource(source)}},
+		{name: "error case: no source", wantErr: "capability source is required"},
+		{
+			name:    "error case: no capabilities",
+			opts:    []Option{WithSource(source), WithRequirements(Requirement{Purpose: "keys", Path: "secret/data/keys"})},
+			wantErr: `requirement "keys": path and capabilities are required`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(tt.opts...)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	source := &staticCapabilities{
+		paths: map[string][]string{
+			"secret/data/auth/signing-keys": {"read", "list"},
+			"secret/data/auth/state-keys":   {"read"},
+			"pki/issue/auth":                {"root"},
+		},
+		calls: map[string]int{},
+	}
+
+	c, err := New(WithSource(source), WithRequirements(
+		Requirement{Purpose: "token.keys_path", Path: "secret/data/auth/signing-keys", Capabilities: []string{CapabilityRead}},
+		Requirement{Purpose: "state_keys.vault_path", Path: "secret/data/auth/state-keys", Capabilities: []string{CapabilityRead, CapabilityCreate, CapabilityUpdate}},
+		Requirement{Purpose: "server.tls.vault_pki.issue_path", Path: "pki/issue/auth", Capabilities: []string{CapabilityUpdate}},
+		Requirement{Purpose: "telegram.vault_path", Path: "secret/data/auth/telegram", Capabilities: []string{CapabilityRead}},
+		Requirement{Purpose: "token.keys_path (warmup)", Path: "secret/data/auth/signing-keys", Capabilities: []string{CapabilityRead}},
+	))
+	require.NoError(t, err)
+
+	report, err := c.Check(t.Context())
+	require.NoError(t, err)
+	require.Len(t, report.Results, 5)
+
+	// права на путь запрашиваются один раз
+	assert.Equal(t, 1, source.calls["secret/data/auth/signing-keys"])
+
+	failed := report.Failed()
+	require.Len(t, failed, 2)
+	assert.Equal(t, []string{CapabilityCreate, CapabilityUpdate}, failed[0].Missing)
+	assert.Equal(t, []string{CapabilityRead}, failed[1].Missing)
+
+	require.EqualError(t, report.Err(), "vault token lacks capabilities:\n"+
+		"  state_keys.vault_path: secret/data/auth/state-keys: missing create,update (granted: read)\n"+
+		"  telegram.vault_path: secret/data/auth/telegram: missing read (granted: deny)")
+
+	// ошибка Vault - не нехватка прав: отчета нет
+	c, err = New(WithSource(source), WithRequirements(Requirement{Purpose: "x", Path: "sys/broken", Capabilities: []string{CapabilityRead}}))
+	require.NoError(t, err)
+
+	_, err = c.Check(t.Context())
+	require.ErrorContains(t, err, "vault is sealed")
+
+	c, err = New(WithSource(source))
+	require.NoError(t, err)
+
+	report, err = c.Check(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, report.Err())
+}
@@ -0,0 +1,325 @@
+// Package qrlogin реализует вход по QR коду: браузер получает короткий код и показывает его
+// в QR, пользователь сканирует его в уже авторизованном приложении или Telegram и подтверждает
+// вход, после чего браузер забирает токен. Так же устроен вход по QR в самом Telegram.
+package qrlogin
+
+import (
+	"auth-service/internal/service/id"
+	"auth-service/internal/service/token"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	keyPrefix = "auth:qrlogin:"
+
+	// codeLength - длина кода в QR. Код живет TTL и подтверждается только авторизованным
+	// пользователем, поэтому короткого кода достаточно.
+	codeLength = 10
+	// secretLength - длина секрета браузера, которым он забирает токен.
+	secretLength = 32
+
+	// DefaultTTL - сколько действует код.
+	DefaultTTL = 2 * time.Minute
+	// DefaultTokenTTL - время жизни токена, выпущенного браузеру.
+	DefaultTokenTTL = time.Hour
+	// DefaultPayloadPrefix - префикс содержимого QR, после него идет код.
+	DefaultPayloadPrefix = "auth-service://qr-login/"
+)
+
+// Status - состояние входа по QR.
+type Status string
+
+const (
+	// StatusPending - код показан в браузере и ждет подтверждения.
+	StatusPending Status = "pending"
+	// StatusConfirmed - вход подтвержден, браузер может забрать токен.
+	StatusConfirmed Status = "confirmed"
+)
+
+var (
+	// ErrNotFound - код не найден, истек, уже использован или секрет не совпал.
+	ErrNotFound = errors.New("qr login not found")
+	// ErrPending - вход еще не подтвержден.
+	ErrPending = errors.New("qr login is not confirmed yet")
+	// ErrAlreadyConfirmed - вход уже подтвержден.
+	ErrAlreadyConfirmed = errors.New("qr login is already confirmed")
+	// ErrInvalidArgument - не заполнены обязательные параметры.
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+//go:generate mockgen -source=qrlogin.go -destination=mocks/qrlogin_mock.go -package=mocks
+type tokenIssuer interface {
+	Issue(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error)
+}
+
+// Challenge - код для показа в QR.
+type Challenge struct {
+	Code string `json:"code"`
+	// Payload - содержимое QR.
+	Payload string `json:"payload"`
+	// Secret - секрет браузера, им забирается токен. В QR не показывается.
+	Secret    string    `json:"secret"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Login - выпущенный браузеру токен.
+type Login struct {
+	Token  string
+	Claims *token.Claims
+}
+
+// Service - вход по QR коду.
+//
+// Ключи:
+//   - auth:qrlogin:<код> - hash с состоянием входа (status, secret_hash, subject, expires_at), TTL - срок действия кода.
+type Service struct {
+	client redis.UniversalClient
+	issuer tokenIssuer
+
+	ttl           time.Duration
+	tokenTTL      time.Duration
+	audience      []string
+	payloadPrefix string
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Service.
+type Option func(*Service)
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(s *Service) {
+		s.client = client
+	}
+}
+
+// WithIssuer устанавливает выпуск токенов для браузера.
+func WithIssuer(issuer tokenIssuer) Option {
+	return func(s *Service) {
+		s.issuer = issuer
+	}
+}
+
+// WithTTL устанавливает, сколько действует код. По умолчанию DefaultTTL.
+func WithTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		s.ttl = ttl
+	}
+}
+
+// WithToken устанавливает время жизни и аудиторию токенов, выпускаемых браузеру.
+func WithToken(ttl time.Duration, audience []string) Option {
+	return func(s *Service) {
+		s.tokenTTL = ttl
+		s.audience = audience
+	}
+}
+
+// WithPayloadPrefix устанавливает префикс содержимого QR, например ссылку на бота
+// https://t.me/<бот>?start=login-. По умолчанию DefaultPayloadPrefix.
+func WithPayloadPrefix(prefix string) Option {
+	return func(s *Service) {
+		s.payloadPrefix = prefix
+	}
+}
+
+// New создает новый Service.
+func New(opts ...Option) (*Service, error) {
+	s := &Service{
+		ttl:           DefaultTTL,
+		tokenTTL:      DefaultTokenTTL,
+		payloadPrefix: DefaultPayloadPrefix,
+		now:           time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.client == nil {
+		return nil, errors.New("redis client is required")
+	}
+
+	if s.issuer == nil {
+		return nil, errors.New("issuer is required")
+	}
+
+	if s.ttl <= 0 {
+		return nil, errors.New("ttl must be positive")
+	}
+
+	if s.tokenTTL <= 0 {
+		return nil, errors.New("token ttl must be positive")
+	}
+
+	return s, nil
+}
+
+func loginKey(code string) string {
+	return keyPrefix + code
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Start создает код для показа в QR.
+func (s *Service) Start(ctx context.Context) (*Challenge, error) {
+	code, err := id.Generate(codeLength)
+	if err != nil {
+		return nil, fmt.Errorf("qrlogin: error generate code: %w", err)
+	}
+
+	secret, err := id.Generate(secretLength)
+	if err != nil {
+		return nil, fmt.Errorf("qrlogin: error generate secret: %w", err)
+	}
+
+	expiresAt := s.now().Add(s.ttl).UTC().Truncate(time.Second)
+
+	_, err = s.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, loginKey(code),
+			"status", string(StatusPending),
+			"secret_hash", hashSecret(secret),
+			"expires_at", expiresAt.Unix(),
+		)
+		p.Expire(ctx, loginKey(code), s.ttl)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("qrlogin: error save code: %w", err)
+	}
+
+	return &Challenge{
+		Code:      code,
+		Payload:   s.payloadPrefix + code,
+		Secret:    secret,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Confirm подтверждает вход по коду от имени авторизованного субъекта.
+func (s *Service) Confirm(ctx context.Context, code, subject string) error {
+	if code == "" || subject == "" {
+		return fmt.Errorf("%w: code and subject are required", ErrInvalidArgument)
+	}
+
+	key := loginKey(code)
+
+	err := s.client.Watch(ctx, func(tx *redis.Tx) error {
+		state, err := s.load(ctx, tx, code)
+		if err != nil {
+			return err
+		}
+
+		if Status(state["status"]) != StatusPending {
+			return ErrAlreadyConfirmed
+		}
+
+		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			p.HSet(ctx, key, "status", string(StatusConfirmed), "subject", subject)
+
+			return nil
+		})
+
+		return err
+	}, key)
+	if errors.Is(err, redis.TxFailedErr) {
+		// код параллельно подтвердили из другого запроса
+		return ErrAlreadyConfirmed
+	}
+
+	return err
+}
+
+// Claim выдает токен браузеру, если вход подтвержден. Код можно использовать один раз.
+func (s *Service) Claim(ctx context.Context, code, secret string) (*Login, error) {
+	if code == "" || secret == "" {
+		return nil, fmt.Errorf("%w: code and secret are required", ErrInvalidArgument)
+	}
+
+	key := loginKey(code)
+
+	var login *Login
+
+	err := s.client.Watch(ctx, func(tx *redis.Tx) error {
+		state, err := s.load(ctx, tx, code)
+		if err != nil {
+			return err
+		}
+
+		want := []byte(state["secret_hash"])
+		if subtle.ConstantTimeCompare(want, []byte(hashSecret(secret))) != 1 {
+			return ErrNotFound
+		}
+
+		if Status(state["status"]) != StatusConfirmed {
+			return ErrPending
+		}
+
+		// токен выпускается до удаления кода, но возвращается только если удаление прошло:
+		// при параллельном запросе код заберет один из них
+		raw, claims, err := s.issuer.Issue(ctx, token.IssueRequest{
+			Subject:  state["subject"],
+			Audience: s.audience,
+			TTL:      s.tokenTTL,
+		})
+		if err != nil {
+			return fmt.Errorf("qrlogin: error issue token: %w", err)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			p.Del(ctx, key)
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		login = &Login{Token: raw, Claims: claims}
+
+		return nil
+	}, key)
+	if errors.Is(err, redis.TxFailedErr) {
+		// код параллельно забрал другой запрос
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return login, nil
+}
+
+// load читает состояние входа. Истекшие коды считаются ненайденными, даже если Redis еще не удалил ключ.
+func (s *Service) load(ctx context.Context, tx *redis.Tx, code string) (map[string]string, error) {
+	state, err := tx.HGetAll(ctx, loginKey(code)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("qrlogin: error get code: %w", err)
+	}
+
+	if len(state) == 0 {
+		return nil, ErrNotFound
+	}
+
+	expiresAt, err := strconv.ParseInt(state["expires_at"], 10, 64)
+	if err != nil || !s.now().Before(time.Unix(expiresAt, 0)) {
+		return nil, ErrNotFound
+	}
+
+	return state, nil
+}
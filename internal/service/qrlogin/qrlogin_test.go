@@ -0,0 +1,189 @@
+package qrlogin
+
+import (
+	"auth-service/internal/service/qrlogin/mocks"
+	"auth-service/internal/service/token"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newService(t *testing.T, opts ...Option) (*Service, *mocks.MocktokenIssuer, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	issuer := mocks.NewMocktokenIssuer(gomock.NewController(t))
+
+	s, err := New(append([]Option{WithClient(client), WithIssuer(issuer)}, opts...)...)
+	require.NoError(t, err)
+
+	return s, issuer, mr
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	t.Cleanup(func() { _ = client.Close() })
+
+	issuer := mocks.NewMocktokenIssuer(gomock.NewController(t))
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			opts:    []Option{WithClient(client), WithIssuer(issuer)},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: all options",
+			opts: []Option{
+				WithClient(client),
+				WithIssuer(issuer),
+				WithTTL(time.Minute),
+				WithToken(time.Hour, []string{"dashboard"}),
+				WithPayloadPrefix("https://t.me/zanuda_bot?start=login-"),
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: client is nil",
+			opts:    []Option{WithIssuer(issuer)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: issuer is nil",
+			opts:    []Option{WithClient(client)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: negative ttl",
+			opts:    []Option{WithClient(client), WithIssuer(issuer), WithTTL(-time.Minute)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: zero token ttl",
+			opts:    []Option{WithClient(client), WithIssuer(issuer), WithToken(0, nil)},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(tt.opts...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService(t *testing.T) {
+	t.Parallel()
+
+	s, issuer, mr := newService(t,
+		WithToken(30*time.Minute, []string{"dashboard"}),
+		WithPayloadPrefix("https://t.me/zanuda_bot?start=login-"),
+	)
+
+	challenge, err := s.Start(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, challenge.Code, codeLength)
+	assert.Equal(t, "https://t.me/zanuda_bot?start=login-"+challenge.Code, challenge.Payload)
+	assert.NotContains(t, challenge.Payload, challenge.Secret)
+	assert.Equal(t, DefaultTTL, mr.TTL(loginKey(challenge.Code)))
+
+	// секрет хранится только в виде хеша
+	assert.Equal(t, hashSecret(challenge.Secret), mr.HGet(loginKey(challenge.Code), "secret_hash"))
+
+	// до подтверждения браузер получает ErrPending
+	_, err = s.Claim(t.Context(), challenge.Code, challenge.Secret)
+	require.ErrorIs(t, err, ErrPending)
+
+	// чужой секрет не отличается от несуществующего кода
+	_, err = s.Claim(t.Context(), challenge.Code, "wrong")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, s.Confirm(t.Context(), challenge.Code, "user-1"))
+	require.ErrorIs(t, s.Confirm(t.Context(), challenge.Code, "user-2"), ErrAlreadyConfirmed)
+
+	issuer.EXPECT().Issue(gomock.Any(), token.IssueRequest{
+		Subject:  "user-1",
+		Audience: []string{"dashboard"},
+		TTL:      30 * time.Minute,
+	}).Return("jwt", &token.Claims{Subject: "user-1"}, nil)
+
+	login, err := s.Claim(t.Context(), challenge.Code, challenge.Secret)
+	require.NoError(t, err)
+	assert.Equal(t, "jwt", login.Token)
+	assert.Equal(t, "user-1", login.Claims.Subject)
+
+	// код используется один раз
+	_, err = s.Claim(t.Context(), challenge.Code, challenge.Secret)
+	require.ErrorIs(t, err, ErrNotFound)
+	assert.False(t, mr.Exists(loginKey(challenge.Code)))
+}
+
+func TestService_Expired(t *testing.T) {
+	t.Parallel()
+
+	s, _, _ := newService(t)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	challenge, err := s.Start(t.Context())
+	require.NoError(t, err)
+
+	// ключ еще не удален Redis, но срок кода уже прошел
+	now = now.Add(DefaultTTL)
+
+	require.ErrorIs(t, s.Confirm(t.Context(), challenge.Code, "user-1"), ErrNotFound)
+
+	_, err = s.Claim(t.Context(), challenge.Code, challenge.Secret)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestService_Errors(t *testing.T) {
+	t.Parallel()
+
+	s, issuer, mr := newService(t)
+
+	require.ErrorIs(t, s.Confirm(t.Context(), "", "user-1"), ErrInvalidArgument)
+	require.ErrorIs(t, s.Confirm(t.Context(), "code", ""), ErrInvalidArgument)
+	require.ErrorIs(t, s.Confirm(t.Context(), "unknown", "user-1"), ErrNotFound)
+
+	_, err := s.Claim(t.Context(), "code", "")
+	require.ErrorIs(t, err, ErrInvalidArgument)
+
+	challenge, err := s.Start(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, s.Confirm(t.Context(), challenge.Code, "user-1"))
+
+	// при ошибке выпуска код не расходуется
+	issuer.EXPECT().Issue(gomock.Any(), gomock.Any()).Return("", nil, errors.New("vault is down"))
+
+	_, err = s.Claim(t.Context(), challenge.Code, challenge.Secret)
+	require.Error(t, err)
+	assert.True(t, mr.Exists(loginKey(challenge.Code)))
+
+	// Redis недоступен
+	mr.Close()
+
+	_, err = s.Start(t.Context())
+	require.Error(t, err)
+}
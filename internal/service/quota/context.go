@@ -0,0 +1,17 @@
+package quota
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext возвращает контекст с аутентифицированным API ключом.
+func NewContext(ctx context.Context, key Key) context.Context {
+	return context.WithValue(ctx, contextKey{}, key)
+}
+
+// FromContext возвращает API ключ запроса, если он был предъявлен.
+func FromContext(ctx context.Context) (Key, bool) {
+	key, ok := ctx.Value(contextKey{}).(Key)
+
+	return key, ok
+}
@@ -0,0 +1,285 @@
+// Package quota ведет учет использования API ключей по дням и месяцам и ограничивает его квотами.
+// В отличие от ограничения частоты запросов, квоты считаются за календарный период (UTC) и
+// хранятся в Redis, поэтому общие для всех экземпляров сервиса.
+package quota
+
+import (
+	"auth-service/internal/service/apikey"
+	"auth-service/internal/service/ratelimit"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrInvalidKey - API ключ не найден или секрет не совпадает.
+	ErrInvalidKey = errors.New("invalid api key")
+	// ErrExhausted - квота API ключа исчерпана.
+	ErrExhausted = errors.New("api key quota exhausted")
+)
+
+// Limits - квоты API ключа. Нулевое значение означает отсутствие ограничения.
+type Limits struct {
+	Daily   int64
+	Monthly int64
+}
+
+// Key - аутентифицированный API ключ.
+type Key struct {
+	ID     string
+	Limits Limits
+	// RateLimit - ограничение частоты запросов ключа.
+	RateLimit RateLimit
+}
+
+// Period - использование за период.
+type Period struct {
+	Used int64 `json:"used"`
+	// Limit - квота за период, 0 - без ограничения.
+	Limit int64 `json:"limit"`
+	// Remaining - остаток квоты, для периода без ограничения не заполняется.
+	Remaining *int64    `json:"remaining,omitempty"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// Usage - использование API ключа за текущие сутки и месяц.
+type Usage struct {
+	ID      string `json:"id"`
+	Daily   Period `json:"daily"`
+	Monthly Period `json:"monthly"`
+}
+
+// Service - учет квот API ключей в Redis.
+//
+// Ключи:
+//   - auth:apikey:<id> - запись ключа (см. пакет apikey), поля quota_daily и quota_monthly
+//     (опционально) переопределяют квоты из конфигурации, rate_limit_tier или rate_limit_requests
+//     и rate_limit_window (в секундах) - ограничение частоты запросов;
+//   - auth:apikey:<id>:usage:d:<YYYYMMDD> и auth:apikey:<id>:usage:m:<YYYYMM> - счетчики запросов,
+//     истекают после окончания периода.
+type Service struct {
+	client redis.UniversalClient
+
+	defaults Limits
+	keys     map[string]Limits
+
+	rateLimit ratelimit.Rule
+	tiers     map[string]ratelimit.Rule
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Service.
+type Option func(*Service)
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(s *Service) {
+		s.client = client
+	}
+}
+
+// WithDefaultLimits устанавливает квоты для ключей, у которых они не заданы.
+func WithDefaultLimits(limits Limits) Option {
+	return func(s *Service) {
+		s.defaults = limits
+	}
+}
+
+// WithKeyLimits устанавливает квоты отдельных ключей по их ID.
+// Квоты из записи ключа в Redis имеют приоритет.
+func WithKeyLimits(keys map[string]Limits) Option {
+	return func(s *Service) {
+		s.keys = keys
+	}
+}
+
+// New создает новый Service.
+func New(opts ...Option) (*Service, error) {
+	s := &Service{now: time.Now}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.client == nil {
+		return nil, errors.New("redis client is required")
+	}
+
+	return s, nil
+}
+
+func usageKey(id, period string) string {
+	return apikey.RecordKey(id) + ":usage:" + period
+}
+
+// Authenticate проверяет API ключ в формате <id>.<секрет> и возвращает его квоты.
+func (s *Service) Authenticate(ctx context.Context, apiKey string) (Key, error) {
+	id, secret, ok := strings.Cut(apiKey, ".")
+	if !ok || id == "" || secret == "" {
+		return Key{}, ErrInvalidKey
+	}
+
+	record, err := s.client.HGetAll(ctx, apikey.RecordKey(id)).Result()
+	if err != nil {
+		return Key{}, fmt.Errorf("quota: error get api key: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(apikey.HashSecret(secret)), []byte(record[apikey.FieldSecretHash])) != 1 {
+		return Key{}, ErrInvalidKey
+	}
+
+	limits, ok := s.keys[id]
+	if !ok {
+		limits = s.defaults
+	}
+
+	if limits.Daily, err = limit(record, apikey.FieldQuotaDaily, limits.Daily); err != nil {
+		return Key{}, err
+	}
+
+	if limits.Monthly, err = limit(record, apikey.FieldQuotaMonthly, limits.Monthly); err != nil {
+		return Key{}, err
+	}
+
+	rateLimit, err := s.rateLimitOf(id, record)
+	if err != nil {
+		return Key{}, err
+	}
+
+	return Key{ID: id, Limits: limits, RateLimit: rateLimit}, nil
+}
+
+// limit возвращает квоту из записи ключа, если она там задана.
+func limit(record map[string]string, field string, fallback int64) (int64, error) {
+	raw, ok := record[field]
+	if !ok {
+		return fallback, nil
+	}
+
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("quota: invalid %s %q", field, raw)
+	}
+
+	return n, nil
+}
+
+// Consume учитывает один запрос. Если квота за сутки или месяц исчерпана, возвращает ErrExhausted,
+// запрос при этом не учитывается.
+func (s *Service) Consume(ctx context.Context, key Key) (Usage, error) {
+	day, month := s.periods()
+
+	var daily, monthly *redis.IntCmd
+
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		daily = p.Incr(ctx, usageKey(key.ID, day.name))
+		p.ExpireAt(ctx, usageKey(key.ID, day.name), day.resetAt)
+		monthly = p.Incr(ctx, usageKey(key.ID, month.name))
+		p.ExpireAt(ctx, usageKey(key.ID, month.name), month.resetAt)
+
+		return nil
+	})
+	if err != nil {
+		return Usage{}, fmt.Errorf("quota: error consume: %w", err)
+	}
+
+	usage := Usage{
+		ID:      key.ID,
+		Daily:   newPeriod(daily.Val(), key.Limits.Daily, day.resetAt),
+		Monthly: newPeriod(monthly.Val(), key.Limits.Monthly, month.resetAt),
+	}
+
+	if exceeded(usage.Daily) || exceeded(usage.Monthly) {
+		// отклоненный запрос не должен расходовать квоту
+		_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+			p.Decr(ctx, usageKey(key.ID, day.name))
+			p.Decr(ctx, usageKey(key.ID, month.name))
+
+			return nil
+		})
+		if err != nil {
+			return Usage{}, fmt.Errorf("quota: error rollback consume: %w", err)
+		}
+
+		usage.Daily = newPeriod(usage.Daily.Used-1, key.Limits.Daily, day.resetAt)
+		usage.Monthly = newPeriod(usage.Monthly.Used-1, key.Limits.Monthly, month.resetAt)
+
+		return usage, ErrExhausted
+	}
+
+	return usage, nil
+}
+
+// Usage возвращает использование ключа за текущие сутки и месяц.
+func (s *Service) Usage(ctx context.Context, key Key) (Usage, error) {
+	day, month := s.periods()
+
+	values, err := s.client.MGet(ctx, usageKey(key.ID, day.name), usageKey(key.ID, month.name)).Result()
+	if err != nil {
+		return Usage{}, fmt.Errorf("quota: error get usage: %w", err)
+	}
+
+	used := make([]int64, len(values))
+
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+
+		if used[i], err = strconv.ParseInt(fmt.Sprint(v), 10, 64); err != nil {
+			return Usage{}, fmt.Errorf("quota: invalid usage counter: %w", err)
+		}
+	}
+
+	return Usage{
+		ID:      key.ID,
+		Daily:   newPeriod(used[0], key.Limits.Daily, day.resetAt),
+		Monthly: newPeriod(used[1], key.Limits.Monthly, month.resetAt),
+	}, nil
+}
+
+// RetryAfter возвращает время до сброса исчерпанной квоты.
+func (u Usage) RetryAfter(now time.Time) time.Duration {
+	if u.Monthly.Limit != 0 && u.Monthly.Used >= u.Monthly.Limit {
+		return u.Monthly.ResetAt.Sub(now)
+	}
+
+	return u.Daily.ResetAt.Sub(now)
+}
+
+type period struct {
+	name    string
+	resetAt time.Time
+}
+
+// periods возвращает текущие сутки и месяц в UTC.
+func (s *Service) periods() (period, period) {
+	now := s.now().UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	return period{name: "d:" + now.Format("20060102"), resetAt: dayStart.AddDate(0, 0, 1)},
+		period{name: "m:" + now.Format("200601"), resetAt: monthStart.AddDate(0, 1, 0)}
+}
+
+func newPeriod(used, limit int64, resetAt time.Time) Period {
+	p := Period{Used: used, Limit: limit, ResetAt: resetAt}
+
+	if limit != 0 {
+		remaining := max(0, limit-used)
+		p.Remaining = &remaining
+	}
+
+	return p
+}
+
+func exceeded(p Period) bool {
+	return p.Limit != 0 && p.Used > p.Limit
+}
@@ -0,0 +1,215 @@
+package quota
+
+import (
+	"auth-service/internal/service/apikey"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newService(t *testing.T, opts ...Option) (*Service, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	s, err := New(append([]Option{WithClient(client)}, opts...)...)
+	require.NoError(t, err)
+
+	now := time.Date(2026, time.March, 31, 22, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	mr.SetTime(now)
+
+	return s, mr
+}
+
+func addKey(t *testing.T, mr *miniredis.Miniredis, id, secret string, fields ...string) {
+	t.Helper()
+
+	mr.HSet(apikey.RecordKey(id), append([]string{apikey.FieldSecretHash, apikey.HashSecret(secret)}, fields...)...)
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	_, err := New()
+	require.Error(t, err)
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	defaultRateLimit := RateLimit{Source: RateLimitSourceDefault}
+
+	s, mr := newService(t,
+		WithDefaultLimits(Limits{Daily: 100, Monthly: 1000}),
+		WithKeyLimits(map[string]Limits{"partner": {Daily: 10}}),
+	)
+
+	addKey(t, mr, "bot", "s3cret")
+	addKey(t, mr, "partner", "s3cret")
+	addKey(t, mr, "vip", "s3cret", apikey.FieldQuotaDaily, "0", apikey.FieldQuotaMonthly, "50000")
+	addKey(t, mr, "broken", "s3cret", apikey.FieldQuotaDaily, "many")
+
+	tests := []struct {
+		name    string
+		apiKey  string
+		want    Key
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "defaults",
+			apiKey:  "bot.s3cret",
+			want:    Key{ID: "bot", Limits: Limits{Daily: 100, Monthly: 1000}, RateLimit: defaultRateLimit},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "limits from config",
+			apiKey:  "partner.s3cret",
+			want:    Key{ID: "partner", Limits: Limits{Daily: 10}, RateLimit: defaultRateLimit},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "limits from record",
+			apiKey:  "vip.s3cret",
+			want:    Key{ID: "vip", Limits: Limits{Daily: 0, Monthly: 50000}, RateLimit: defaultRateLimit},
+			wantErr: require.NoError,
+		},
+		{
+			name:   "wrong secret",
+			apiKey: "bot.other",
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrInvalidKey)
+			},
+		},
+		{
+			name:   "unknown key",
+			apiKey: "unknown.s3cret",
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrInvalidKey)
+			},
+		},
+		{
+			name:   "malformed",
+			apiKey: "bot",
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrInvalidKey)
+			},
+		},
+		{
+			name:   "invalid record",
+			apiKey: "broken.s3cret",
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "invalid quota_daily")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := s.Authenticate(t.Context(), tt.apiKey)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConsume(t *testing.T) {
+	t.Parallel()
+
+	s, mr := newService(t)
+	ctx := t.Context()
+	key := Key{ID: "bot", Limits: Limits{Daily: 2, Monthly: 3}}
+
+	for range 2 {
+		_, err := s.Consume(ctx, key)
+		require.NoError(t, err)
+	}
+
+	usage, err := s.Consume(ctx, key)
+	require.ErrorIs(t, err, ErrExhausted)
+	assert.Equal(t, int64(2), usage.Daily.Used)
+	assert.Equal(t, int64(0), *usage.Daily.Remaining)
+	assert.Equal(t, 2*time.Hour, usage.RetryAfter(s.now()))
+
+	// счетчики живут до конца периода
+	assert.Equal(t, "2", mustGet(t, mr, usageKey("bot", "d:20260331")))
+	assert.Equal(t, 2*time.Hour, mr.TTL(usageKey("bot", "d:20260331")).Round(time.Hour))
+
+	// следующие сутки: дневная квота сброшена, месячная - нет
+	s.now = func() time.Time { return time.Date(2026, time.March, 31, 23, 59, 0, 0, time.UTC).Add(time.Hour) }
+
+	usage, err = s.Consume(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), usage.Daily.Used)
+
+	s.now = func() time.Time { return time.Date(2026, time.March, 31, 12, 0, 0, 0, time.UTC) }
+
+	got, err := s.Usage(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, "bot", got.ID)
+	assert.Equal(t, int64(2), got.Daily.Used)
+	assert.Equal(t, int64(2), got.Monthly.Used)
+	assert.Equal(t, time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC), got.Monthly.ResetAt)
+}
+
+func TestConsume_Unlimited(t *testing.T) {
+	t.Parallel()
+
+	s, mr := newService(t)
+
+	now := time.Date(2026, time.March, 15, 22, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	mr.SetTime(now)
+
+	usage, err := s.Consume(t.Context(), Key{ID: "bot", Limits: Limits{Monthly: 1}})
+	require.NoError(t, err)
+	assert.Nil(t, usage.Daily.Remaining)
+	assert.Equal(t, int64(0), *usage.Monthly.Remaining)
+
+	usage, err = s.Consume(t.Context(), Key{ID: "bot", Limits: Limits{Monthly: 1}})
+	require.ErrorIs(t, err, ErrExhausted)
+	assert.Equal(t, (16*24+2)*time.Hour, usage.RetryAfter(now))
+}
+
+func TestUsage_Empty(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newService(t)
+
+	got, err := s.Usage(t.Context(), Key{ID: "bot", Limits: Limits{Daily: 5}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), got.Daily.Used)
+	assert.Equal(t, int64(5), *got.Daily.Remaining)
+}
+
+func mustGet(t *testing.T, mr *miniredis.Miniredis, key string) string {
+	t.Helper()
+
+	v, err := mr.Get(key)
+	require.NoError(t, err)
+
+	return v
+}
+
+func TestContext(t *testing.T) {
+	t.Parallel()
+
+	_, ok := FromContext(t.Context())
+	assert.False(t, ok)
+
+	key := Key{ID: "bot", Limits: Limits{Daily: 1}}
+
+	got, ok := FromContext(NewContext(t.Context(), key))
+	require.True(t, ok)
+	assert.Equal(t, key, got)
+}
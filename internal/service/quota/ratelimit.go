@@ -0,0 +1,172 @@
+package quota
+
+import (
+	"auth-service/internal/service/apikey"
+	"auth-service/internal/service/ratelimit"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// Источники ограничения частоты запросов API ключа.
+const (
+	// RateLimitSourceKey - собственное ограничение из записи ключа.
+	RateLimitSourceKey = "key"
+	// RateLimitSourceTier - тариф из записи ключа.
+	RateLimitSourceTier = "tier"
+	// RateLimitSourceDefault - ограничение по умолчанию из конфигурации.
+	RateLimitSourceDefault = "default"
+)
+
+var (
+	// ErrNotFound - API ключ не найден.
+	ErrNotFound = errors.New("api key not found")
+	// ErrUnknownTier - тариф не задан в конфигурации.
+	ErrUnknownTier = errors.New("unknown rate limit tier")
+	// ErrInvalidRateLimit - ограничение частоты запросов задано неверно.
+	ErrInvalidRateLimit = errors.New("invalid rate limit")
+)
+
+// RateLimit - действующее ограничение частоты запросов API ключа.
+type RateLimit struct {
+	// Source - откуда взято ограничение: key, tier или default.
+	Source string
+	// Tier - тариф, если ограничение взято из тарифа.
+	Tier string
+	// Rule - правило ограничения. Пустое правило - без ограничения.
+	Rule ratelimit.Rule
+}
+
+// RateLimitOverride - ограничение частоты запросов, которое сохраняется в записи ключа:
+// тариф из конфигурации или собственное правило.
+type RateLimitOverride struct {
+	Tier string
+	Rule ratelimit.Rule
+}
+
+// WithRateLimits устанавливает ограничение частоты запросов для ключей без собственного
+// ограничения и тарифы, которые можно назначить ключу. Пустое правило - без ограничения.
+func WithRateLimits(defaults ratelimit.Rule, tiers map[string]ratelimit.Rule) Option {
+	return func(s *Service) {
+		s.rateLimit = defaults
+		s.tiers = tiers
+	}
+}
+
+// rateLimitOf возвращает ограничение частоты запросов по записи ключа. Собственное правило ключа
+// имеет приоритет над тарифом. Если тариф удален из конфигурации, действует ограничение по умолчанию.
+func (s *Service) rateLimitOf(id string, record map[string]string) (RateLimit, error) {
+	if _, ok := record[apikey.FieldRateLimitRequests]; ok {
+		requests, err := strconv.Atoi(record[apikey.FieldRateLimitRequests])
+		if err != nil || requests < 1 {
+			return RateLimit{}, fmt.Errorf("quota: invalid %s %q", apikey.FieldRateLimitRequests, record[apikey.FieldRateLimitRequests])
+		}
+
+		window, err := strconv.ParseInt(record[apikey.FieldRateLimitWindow], 10, 64)
+		if err != nil || window < 1 {
+			return RateLimit{}, fmt.Errorf("quota: invalid %s %q", apikey.FieldRateLimitWindow, record[apikey.FieldRateLimitWindow])
+		}
+
+		return RateLimit{
+			Source: RateLimitSourceKey,
+			Rule:   ratelimit.Rule{Requests: requests, Window: time.Duration(window) * time.Second},
+		}, nil
+	}
+
+	if tier, ok := record[apikey.FieldRateLimitTier]; ok {
+		if rule, ok := s.tiers[tier]; ok {
+			return RateLimit{Source: RateLimitSourceTier, Tier: tier, Rule: rule}, nil
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"api_key": id,
+			"tier":    tier,
+		}).Warn("api key rate limit tier is not configured, using default")
+	}
+
+	return RateLimit{Source: RateLimitSourceDefault, Rule: s.rateLimit}, nil
+}
+
+// RateLimit возвращает действующее ограничение частоты запросов ключа.
+func (s *Service) RateLimit(ctx context.Context, id string) (RateLimit, error) {
+	record, err := s.record(ctx, id)
+	if err != nil {
+		return RateLimit{}, err
+	}
+
+	return s.rateLimitOf(id, record)
+}
+
+// SetRateLimit сохраняет ограничение частоты запросов в записи ключа и возвращает действующее.
+// Задается либо тариф, либо собственное правило с окном в целых секундах.
+func (s *Service) SetRateLimit(ctx context.Context, id string, override RateLimitOverride) (RateLimit, error) {
+	var fields []any
+
+	switch {
+	case override.Tier != "" && override.Rule != (ratelimit.Rule{}):
+		return RateLimit{}, fmt.Errorf("%w: tier and rule are mutually exclusive", ErrInvalidRateLimit)
+	case override.Tier != "":
+		if _, ok := s.tiers[override.Tier]; !ok {
+			return RateLimit{}, fmt.Errorf("%w: %q", ErrUnknownTier, override.Tier)
+		}
+
+		fields = []any{apikey.FieldRateLimitTier, override.Tier}
+	case override.Rule.Enabled() && override.Rule.Window%time.Second == 0:
+		fields = []any{
+			apikey.FieldRateLimitRequests, override.Rule.Requests,
+			apikey.FieldRateLimitWindow, int64(override.Rule.Window / time.Second),
+		}
+	default:
+		return RateLimit{}, fmt.Errorf("%w: tier or positive requests and window in whole seconds are required", ErrInvalidRateLimit)
+	}
+
+	if _, err := s.record(ctx, id); err != nil {
+		return RateLimit{}, err
+	}
+
+	_, err := s.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.HDel(ctx, apikey.RecordKey(id), apikey.FieldRateLimitTier, apikey.FieldRateLimitRequests, apikey.FieldRateLimitWindow)
+		p.HSet(ctx, apikey.RecordKey(id), fields...)
+
+		return nil
+	})
+	if err != nil {
+		return RateLimit{}, fmt.Errorf("quota: error save rate limit: %w", err)
+	}
+
+	return s.RateLimit(ctx, id)
+}
+
+// ResetRateLimit удаляет ограничение частоты запросов из записи ключа: начинает действовать
+// ограничение по умолчанию.
+func (s *Service) ResetRateLimit(ctx context.Context, id string) error {
+	if _, err := s.record(ctx, id); err != nil {
+		return err
+	}
+
+	err := s.client.HDel(ctx, apikey.RecordKey(id), apikey.FieldRateLimitTier, apikey.FieldRateLimitRequests, apikey.FieldRateLimitWindow).Err()
+	if err != nil {
+		return fmt.Errorf("quota: error delete rate limit: %w", err)
+	}
+
+	return nil
+}
+
+// record возвращает запись ключа.
+func (s *Service) record(ctx context.Context, id string) (map[string]string, error) {
+	record, err := s.client.HGetAll(ctx, apikey.RecordKey(id)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("quota: error get api key: %w", err)
+	}
+
+	if record[apikey.FieldSecretHash] == "" {
+		return nil, ErrNotFound
+	}
+
+	return record, nil
+}
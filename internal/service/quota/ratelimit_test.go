@@ -0,0 +1,113 @@
+package quota
+
+import (
+	"auth-service/internal/service/apikey"
+	"auth-service/internal/service/ratelimit"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+
+	defaults := ratelimit.Rule{Requests: 60, Window: time.Minute}
+	partner := ratelimit.Rule{Requests: 600, Window: time.Minute}
+
+	s, mr := newService(t, WithRateLimits(defaults, map[string]ratelimit.Rule{"partner": partner}))
+
+	addKey(t, mr, "bot", "s3cret")
+	addKey(t, mr, "removed", "s3cret", apikey.FieldRateLimitTier, "legacy")
+	addKey(t, mr, "broken", "s3cret", apikey.FieldRateLimitRequests, "10", apikey.FieldRateLimitWindow, "soon")
+
+	tests := []struct {
+		name     string
+		id       string
+		override *RateLimitOverride
+		want     RateLimit
+		wantErr  error
+	}{
+		{
+			name: "default",
+			id:   "bot",
+			want: RateLimit{Source: RateLimitSourceDefault, Rule: defaults},
+		},
+		{
+			name:     "tier",
+			id:       "bot",
+			override: &RateLimitOverride{Tier: "partner"},
+			want:     RateLimit{Source: RateLimitSourceTier, Tier: "partner", Rule: partner},
+		},
+		{
+			name:     "own rule",
+			id:       "bot",
+			override: &RateLimitOverride{Rule: ratelimit.Rule{Requests: 5, Window: time.Hour}},
+			want:     RateLimit{Source: RateLimitSourceKey, Rule: ratelimit.Rule{Requests: 5, Window: time.Hour}},
+		},
+		{
+			name: "tier removed from config",
+			id:   "removed",
+			want: RateLimit{Source: RateLimitSourceDefault, Rule: defaults},
+		},
+		{
+			name:     "unknown tier",
+			id:       "bot",
+			override: &RateLimitOverride{Tier: "gold"},
+			wantErr:  ErrUnknownTier,
+		},
+		{
+			name:     "tier and rule",
+			id:       "bot",
+			override: &RateLimitOverride{Tier: "partner", Rule: partner},
+			wantErr:  ErrInvalidRateLimit,
+		},
+		{
+			name:     "fractional window",
+			id:       "bot",
+			override: &RateLimitOverride{Rule: ratelimit.Rule{Requests: 5, Window: 1500 * time.Millisecond}},
+			wantErr:  ErrInvalidRateLimit,
+		},
+		{
+			name:     "unknown key",
+			id:       "unknown",
+			override: &RateLimitOverride{Tier: "partner"},
+			wantErr:  ErrNotFound,
+		},
+	}
+
+	// шаги выполняются по порядку: каждый меняет запись ключа для следующих
+	for _, tt := range tests {
+		var (
+			got RateLimit
+			err error
+		)
+
+		if tt.override != nil {
+			got, err = s.SetRateLimit(t.Context(), tt.id, *tt.override)
+		} else {
+			got, err = s.RateLimit(t.Context(), tt.id)
+		}
+
+		require.ErrorIs(t, err, tt.wantErr, tt.name)
+		assert.Equal(t, tt.want, got, tt.name)
+	}
+
+	// ключ с собственным правилом аутентифицируется с ним
+	key, err := s.Authenticate(t.Context(), "bot.s3cret")
+	require.NoError(t, err)
+	assert.Equal(t, RateLimit{Source: RateLimitSourceKey, Rule: ratelimit.Rule{Requests: 5, Window: time.Hour}}, key.RateLimit)
+
+	require.NoError(t, s.ResetRateLimit(t.Context(), "bot"))
+
+	got, err := s.RateLimit(t.Context(), "bot")
+	require.NoError(t, err)
+	assert.Equal(t, RateLimit{Source: RateLimitSourceDefault, Rule: defaults}, got)
+
+	require.ErrorIs(t, s.ResetRateLimit(t.Context(), "unknown"), ErrNotFound)
+
+	_, err = s.Authenticate(t.Context(), "broken.s3cret")
+	require.ErrorContains(t, err, "invalid rate_limit_window")
+}
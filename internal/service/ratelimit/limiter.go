@@ -0,0 +1,94 @@
+// Package ratelimit реализует ограничение частоты запросов с фиксированным окном.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepEvery - через сколько вызовов Allow удаляются истекшие окна.
+const sweepEvery = 1000
+
+// Rule - правило ограничения: не более Requests запросов за Window.
+type Rule struct {
+	Requests int
+	Window   time.Duration
+}
+
+// Enabled возвращает true, если правило задано.
+func (r Rule) Enabled() bool {
+	return r.Requests > 0 && r.Window > 0
+}
+
+// Result - результат проверки лимита.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration // через сколько окно сбросится
+}
+
+type window struct {
+	start time.Time
+	count int
+}
+
+// Limiter - ограничитель частоты запросов, хранящий счетчики в памяти процесса.
+type Limiter struct {
+	mu      sync.Mutex
+	windows map[string]*window
+	calls   int
+
+	now func() time.Time
+}
+
+// New создает новый Limiter.
+func New() *Limiter {
+	return &Limiter{
+		windows: map[string]*window{},
+		now:     time.Now,
+	}
+}
+
+// Allow учитывает запрос по ключу и возвращает, укладывается ли он в правило.
+func (l *Limiter) Allow(key string, rule Rule) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	l.calls++
+	if l.calls%sweepEvery == 0 {
+		l.sweep(now, rule.Window)
+	}
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= rule.Window {
+		w = &window{start: now}
+		l.windows[key] = w
+	}
+
+	resetAfter := rule.Window - now.Sub(w.start)
+
+	if w.count >= rule.Requests {
+		return Result{Allowed: false, Limit: rule.Requests, Remaining: 0, ResetAfter: resetAfter}
+	}
+
+	w.count++
+
+	return Result{
+		Allowed:    true,
+		Limit:      rule.Requests,
+		Remaining:  rule.Requests - w.count,
+		ResetAfter: resetAfter,
+	}
+}
+
+// sweep удаляет окна, которые истекли. Вызывается под мьютексом.
+func (l *Limiter) sweep(now time.Time, maxWindow time.Duration) {
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= maxWindow {
+			delete(l.windows, key)
+		}
+	}
+}
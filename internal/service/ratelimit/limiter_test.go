@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRule_Enabled(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, Rule{Requests: 1, Window: time.Second}.Enabled())
+	assert.False(t, Rule{Requests: 0, Window: time.Second}.Enabled())
+	assert.False(t, Rule{Requests: 1}.Enabled())
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	l := New()
+	l.now = func() time.Time { return now }
+
+	rule := Rule{Requests: 2, Window: time.Minute}
+
+	assert.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 1, ResetAfter: time.Minute}, l.Allow("ip", rule))
+
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 0, ResetAfter: 50 * time.Second}, l.Allow("ip", rule))
+	assert.Equal(t, Result{Allowed: false, Limit: 2, Remaining: 0, ResetAfter: 50 * time.Second}, l.Allow("ip", rule))
+
+	// другой ключ считается отдельно
+	assert.True(t, l.Allow("other", rule).Allowed)
+
+	// окно сбросилось
+	now = now.Add(50 * time.Second)
+	assert.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 1, ResetAfter: time.Minute}, l.Allow("ip", rule))
+}
+
+func TestLimiter_Sweep(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	l := New()
+	l.now = func() time.Time { return now }
+
+	rule := Rule{Requests: 1, Window: time.Second}
+
+	l.Allow("old", rule)
+
+	now = now.Add(time.Minute)
+
+	for range sweepEvery {
+		l.Allow("new", rule)
+	}
+
+	_, ok := l.windows["old"]
+	assert.False(t, ok, "expired window must be removed")
+}
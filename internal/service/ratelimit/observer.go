@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// Observer - режим наблюдения: запросы сверх лимита не отклоняются, а только учитываются в метрике
+// auth_ratelimit_observed_total и в логе. Позволяет подобрать правила на реальном трафике
+// до включения ограничений.
+type Observer struct {
+	observed *prometheus.CounterVec
+}
+
+// NewObserver создает Observer и регистрирует его метрику в registerer.
+// Если метрика уже зарегистрирована, используется существующая.
+func NewObserver(registerer prometheus.Registerer) (*Observer, error) {
+	if registerer == nil {
+		return nil, errors.New("registerer is required")
+	}
+
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_ratelimit_observed_total",
+		Help: "Количество запросов, которые были бы отклонены ограничением частоты без режима наблюдения, по группам эндпоинтов.",
+	}, []string{"group"})
+
+	if err := registerer.Register(counter); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if !errors.As(err, &already) {
+			return nil, err
+		}
+
+		existing, ok := already.ExistingCollector.(*prometheus.CounterVec)
+		if !ok {
+			return nil, err
+		}
+
+		counter = existing
+	}
+
+	return &Observer{observed: counter}, nil
+}
+
+// Observe учитывает запрос с ключом key из группы group, который был бы отклонен правилом rule.
+func (o *Observer) Observe(group, key string, rule Rule) {
+	o.observed.WithLabelValues(group).Inc()
+
+	logrus.WithFields(logrus.Fields{
+		"group":    group,
+		"key":      key,
+		"requests": rule.Requests,
+		"window":   rule.Window,
+	}).Warn("request would be rate limited (observe mode)")
+}
@@ -0,0 +1,34 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserver(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewRegistry()
+
+	o, err := NewObserver(registry)
+	require.NoError(t, err)
+
+	o.Observe("admin", "192.0.2.1", Rule{Requests: 1, Window: time.Minute})
+	o.Observe("admin", "192.0.2.2", Rule{Requests: 1, Window: time.Minute})
+
+	// повторное создание использует уже зарегистрированную метрику
+	again, err := NewObserver(registry)
+	require.NoError(t, err)
+
+	again.Observe("admin", "192.0.2.1", Rule{Requests: 1, Window: time.Minute})
+
+	assert.InDelta(t, 3, testutil.ToFloat64(o.observed.WithLabelValues("admin")), 0)
+
+	_, err = NewObserver(nil)
+	require.Error(t, err)
+}
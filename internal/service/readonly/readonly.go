@@ -0,0 +1,269 @@
+// Package readonly - режим только для чтения. В нем проверка и интроспекция токенов продолжают
+// работать, а выпуск, регистрация и отзыв, которые пишут в Redis, отклоняются с 503. Режим
+// включает оператор через API администратора или сам сервис, когда подряд не проходят записи
+// в Redis: после паузы запись пробуется снова, и первая успешная запись выключает режим.
+package readonly
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultFailureThreshold - сколько записей подряд должно не пройти, чтобы режим включился сам.
+	DefaultFailureThreshold = 5
+	// DefaultCooldown - сколько режим, включенный сам, действует до следующей попытки записи.
+	DefaultCooldown = 30 * time.Second
+)
+
+// Source - кто включил режим.
+type Source string
+
+const (
+	// SourceManual - оператор через API администратора.
+	SourceManual Source = "manual"
+	// SourceAuto - сервис после ошибок записи в Redis.
+	SourceAuto Source = "auto"
+)
+
+// Status - состояние режима только для чтения. Если режим включен и оператором, и сервисом,
+// показывается включение оператором: оно не истекает само.
+type Status struct {
+	Enabled bool       `json:"enabled"`
+	Source  Source     `json:"source,omitempty"`
+	Reason  string     `json:"reason,omitempty"`
+	Since   *time.Time `json:"since,omitempty"`
+	// Until - когда сервис снова попробует писать. Только для режима, включенного сервисом
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// Mode - режим только для чтения.
+type Mode struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu           sync.Mutex
+	manual       bool
+	manualReason string
+	manualSince  time.Time
+	// failures - записи, не прошедшие подряд. Сбрасывается только успешной записью
+	failures   int
+	autoReason string
+	autoSince  time.Time
+	// autoUntil - конец паузы перед следующей попыткой записи. Нулевое, пока режим не включался сам
+	autoUntil time.Time
+
+	registerer prometheus.Registerer
+	rejected   *prometheus.CounterVec
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Mode.
+type Option func(*Mode)
+
+// WithFailureThreshold устанавливает, сколько записей подряд должно не пройти, чтобы режим
+// включился сам. По умолчанию DefaultFailureThreshold.
+func WithFailureThreshold(threshold int) Option {
+	return func(m *Mode) {
+		m.threshold = threshold
+	}
+}
+
+// WithCooldown устанавливает, сколько режим, включенный сам, действует до следующей попытки записи.
+// По умолчанию DefaultCooldown.
+func WithCooldown(cooldown time.Duration) Option {
+	return func(m *Mode) {
+		m.cooldown = cooldown
+	}
+}
+
+// WithRegisterer устанавливает реестр метрик. По умолчанию используется prometheus.DefaultRegisterer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(m *Mode) {
+		m.registerer = registerer
+	}
+}
+
+// New создает новый Mode и регистрирует его метрики.
+func New(opts ...Option) (*Mode, error) {
+	m := &Mode{
+		threshold:  DefaultFailureThreshold,
+		cooldown:   DefaultCooldown,
+		registerer: prometheus.DefaultRegisterer,
+		now:        time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.threshold <= 0 {
+		return nil, errors.New("failure threshold must be positive")
+	}
+
+	if m.cooldown <= 0 {
+		return nil, errors.New("cooldown must be positive")
+	}
+
+	if m.registerer == nil {
+		return nil, errors.New("registerer is required")
+	}
+
+	m.rejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_read_only_rejected_total",
+		Help: "Количество запросов, отклоненных в режиме только для чтения, по классам эндпоинтов.",
+	}, []string{"class"})
+
+	collectors := []prometheus.Collector{m.rejected}
+
+	for _, source := range []Source{SourceManual, SourceAuto} {
+		collectors = append(collectors, prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "auth_read_only",
+			Help:        "1, если включен режим только для чтения, по тому, кто его включил.",
+			ConstLabels: prometheus.Labels{"source": string(source)},
+		}, func() float64 {
+			if m.active(source) {
+				return 1
+			}
+
+			return 0
+		}))
+	}
+
+	for _, c := range collectors {
+		if err := m.registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// Enable включает режим от имени оператора. Режим действует до вызова Disable.
+func (m *Mode) Enable(reason string) Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.manual {
+		m.manual = true
+		m.manualSince = m.now()
+
+		logrus.WithField("reason", reason).Warn("read-only mode enabled by operator")
+	}
+
+	m.manualReason = reason
+
+	return m.status()
+}
+
+// Disable выключает режим, в том числе включенный сервисом: оператор подтверждает, что запись
+// восстановлена. Если записи снова не пройдут, режим включится сам.
+func (m *Mode) Disable() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.manual || !m.autoUntil.IsZero() {
+		logrus.Info("read-only mode disabled by operator")
+	}
+
+	m.manual = false
+	m.manualReason = ""
+	m.manualSince = time.Time{}
+	m.resetAuto()
+
+	return m.status()
+}
+
+// ObserveWrite учитывает результат записи в Redis: nil - запись прошла, иначе ошибка хранилища.
+// Отмена запроса вызывающим не учитывается.
+func (m *Mode) ObserveWrite(err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err == nil {
+		if !m.autoUntil.IsZero() {
+			logrus.Info("redis writes recovered, read-only mode disabled")
+		}
+
+		m.resetAuto()
+
+		return
+	}
+
+	m.failures++
+	if m.failures < m.threshold {
+		return
+	}
+
+	now := m.now()
+
+	if m.autoUntil.IsZero() {
+		m.autoSince = now
+
+		logrus.WithError(err).WithField("failures", m.failures).Warn("redis writes failing, read-only mode enabled")
+	}
+
+	m.autoReason = err.Error()
+	m.autoUntil = now.Add(m.cooldown)
+}
+
+// Status возвращает состояние режима.
+func (m *Mode) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.status()
+}
+
+// Reject учитывает запрос эндпоинта класса class, отклоненный в режиме только для чтения.
+func (m *Mode) Reject(class string) {
+	m.rejected.WithLabelValues(class).Inc()
+}
+
+// active сообщает, включен ли режим источником source.
+func (m *Mode) active(source Source) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if source == SourceManual {
+		return m.manual
+	}
+
+	return m.autoActive()
+}
+
+func (m *Mode) autoActive() bool {
+	return !m.autoUntil.IsZero() && m.now().Before(m.autoUntil)
+}
+
+func (m *Mode) resetAuto() {
+	m.failures = 0
+	m.autoReason = ""
+	m.autoSince = time.Time{}
+	m.autoUntil = time.Time{}
+}
+
+func (m *Mode) status() Status {
+	switch {
+	case m.manual:
+		since := m.manualSince
+
+		return Status{Enabled: true, Source: SourceManual, Reason: m.manualReason, Since: &since}
+	case m.autoActive():
+		since, until := m.autoSince, m.autoUntil
+
+		return Status{Enabled: true, Source: SourceAuto, Reason: m.autoReason, Since: &since, Until: &until}
+	}
+
+	return Status{}
+}
@@ -0,0 +1,176 @@
+package readonly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr string
+	}{
+		{name: "ok"},
+		{name: "zero threshold", opts: []Option{WithFailureThreshold(0)}, wantErr: "failure threshold must be positive"},
+		{name: "zero cooldown", opts: []Option{WithCooldown(0)}, wantErr: "cooldown must be positive"},
+		{name: "nil registerer", opts: []Option{WithRegisterer(nil)}, wantErr: "registerer is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := New(append([]Option{WithRegisterer(prometheus.NewRegistry())}, tt.opts...)...)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, m)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, Status{}, m.Status())
+		})
+	}
+}
+
+func newTestMode(t *testing.T, registry *prometheus.Registry) (*Mode, *time.Time) {
+	t.Helper()
+
+	m, err := New(WithFailureThreshold(3), WithCooldown(time.Minute), WithRegisterer(registry))
+	require.NoError(t, err)
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	return m, &now
+}
+
+func TestMode_Manual(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewRegistry()
+	m, now := newTestMode(t, registry)
+
+	status := m.Enable("redis failover")
+	assert.True(t, status.Enabled)
+	assert.Equal(t, SourceManual, status.Source)
+	assert.Equal(t, "redis failover", status.Reason)
+	assert.Equal(t, *now, *status.Since)
+	assert.Nil(t, status.Until)
+
+	// повторное включение меняет причину, но не время включения
+	*now = now.Add(time.Hour)
+
+	status = m.Enable("maintenance")
+	assert.Equal(t, "maintenance", status.Reason)
+	assert.Equal(t, now.Add(-time.Hour), *status.Since)
+
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP auth_read_only 1, если включен режим только для чтения, по тому, кто его включил.
+# TYPE auth_read_only gauge
+auth_read_only{source="auto"} 0
+auth_read_only{source="manual"} 1
+`), "auth_read_only"))
+
+	assert.Equal(t, Status{}, m.Disable())
+	assert.Equal(t, Status{}, m.Status())
+}
+
+func TestMode_ObserveWrite(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewRegistry()
+	m, now := newTestMode(t, registry)
+
+	failure := errors.New("READONLY You can't write against a read only replica.")
+
+	// ошибки не подряд не включают режим
+	m.ObserveWrite(failure)
+	m.ObserveWrite(failure)
+	m.ObserveWrite(nil)
+	m.ObserveWrite(failure)
+	m.ObserveWrite(context.Canceled)
+	m.ObserveWrite(fmt.Errorf("wrapped: %w", context.Canceled))
+	m.ObserveWrite(failure)
+	assert.False(t, m.Status().Enabled)
+
+	m.ObserveWrite(failure)
+
+	status := m.Status()
+	require.True(t, status.Enabled)
+	assert.Equal(t, SourceAuto, status.Source)
+	assert.Equal(t, failure.Error(), status.Reason)
+	assert.Equal(t, *now, *status.Since)
+	assert.Equal(t, now.Add(time.Minute), *status.Until)
+
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP auth_read_only 1, если включен режим только для чтения, по тому, кто его включил.
+# TYPE auth_read_only gauge
+auth_read_only{source="auto"} 1
+auth_read_only{source="manual"} 0
+`), "auth_read_only"))
+
+	// после паузы запись пробуется снова: одна ошибка снова включает режим
+	since := *now
+	*now = now.Add(time.Minute)
+	assert.False(t, m.Status().Enabled)
+
+	m.ObserveWrite(failure)
+
+	status = m.Status()
+	require.True(t, status.Enabled)
+	assert.Equal(t, since, *status.Since)
+	assert.Equal(t, now.Add(time.Minute), *status.Until)
+
+	// успешная запись выключает режим и сбрасывает счетчик ошибок
+	*now = now.Add(time.Minute)
+	m.ObserveWrite(nil)
+	m.ObserveWrite(failure)
+	assert.Equal(t, Status{}, m.Status())
+}
+
+func TestMode_ManualOverridesAuto(t *testing.T) {
+	t.Parallel()
+
+	m, _ := newTestMode(t, prometheus.NewRegistry())
+
+	for range 3 {
+		m.ObserveWrite(errors.New("i/o timeout"))
+	}
+
+	m.Enable("investigating")
+	assert.Equal(t, SourceManual, m.Status().Source)
+
+	// выключение оператором сбрасывает и режим, включенный сервисом
+	m.Disable()
+	assert.Equal(t, Status{}, m.Status())
+
+	m.ObserveWrite(errors.New("i/o timeout"))
+	assert.False(t, m.Status().Enabled)
+}
+
+func TestMode_Reject(t *testing.T) {
+	t.Parallel()
+
+	m, _ := newTestMode(t, prometheus.NewRegistry())
+
+	m.Reject("issuance")
+	m.Reject("issuance")
+	m.Reject("session")
+
+	assert.InDelta(t, 2, testutil.ToFloat64(m.rejected.WithLabelValues("issuance")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.rejected.WithLabelValues("session")), 0)
+}
@@ -0,0 +1,53 @@
+package redis
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// statsCollector отдает статистику пула соединений Redis в метрики при каждом сборе.
+type statsCollector struct {
+	svc *Service
+
+	hits        *prometheus.Desc
+	misses      *prometheus.Desc
+	timeouts    *prometheus.Desc
+	connections *prometheus.Desc
+}
+
+// Collector возвращает коллектор метрик пула соединений. Пока соединения нет, метрики не отдаются.
+func (s *Service) Collector() prometheus.Collector {
+	return &statsCollector{
+		svc: s,
+		hits: prometheus.NewDesc("auth_redis_pool_hits_total",
+			"Количество соединений с Redis, взятых из пула.", nil, nil),
+		misses: prometheus.NewDesc("auth_redis_pool_misses_total",
+			"Количество соединений с Redis, которые пришлось создать, потому что в пуле не было свободных.", nil, nil),
+		timeouts: prometheus.NewDesc("auth_redis_pool_timeouts_total",
+			"Количество запросов, не дождавшихся свободного соединения с Redis.", nil, nil),
+		connections: prometheus.NewDesc("auth_redis_pool_connections",
+			"Количество соединений с Redis в пуле: total - все, idle - свободные, stale - закрытые как устаревшие.", []string{"state"}, nil),
+	}
+}
+
+// Describe отправляет описания метрик.
+func (c *statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.connections
+}
+
+// Collect отправляет текущую статистику пула.
+func (c *statsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats, err := c.svc.Stats()
+	if err != nil {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, float64(stats.TotalConns), "total")
+	ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, float64(stats.IdleConns), "idle")
+	ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, float64(stats.StaleConns), "stale")
+}
@@ -5,10 +5,12 @@
 package mocks
 
 import (
+	redis "auth-service/internal/storage/redis"
 	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	redis0 "github.com/redis/go-redis/v9"
 )
 
 // MockredisClient is a mock of redisClient interface.
@@ -48,6 +50,20 @@ func (mr *MockredisClientMockRecorder) Close(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockredisClient)(nil).Close), ctx)
 }
 
+// Cmd mocks base method.
+func (m *MockredisClient) Cmd() redis0.UniversalClient {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cmd")
+	ret0, _ := ret[0].(redis0.UniversalClient)
+	return ret0
+}
+
+// Cmd indicates an expected call of Cmd.
+func (mr *MockredisClientMockRecorder) Cmd() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cmd", reflect.TypeOf((*MockredisClient)(nil).Cmd))
+}
+
 // Connect mocks base method.
 func (m *MockredisClient) Connect(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -61,3 +77,51 @@ func (mr *MockredisClientMockRecorder) Connect(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Connect", reflect.TypeOf((*MockredisClient)(nil).Connect), ctx)
 }
+
+// Info mocks base method.
+func (m *MockredisClient) Info(ctx context.Context, sections ...string) (map[string]map[string]string, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range sections {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Info", varargs...)
+	ret0, _ := ret[0].(map[string]map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Info indicates an expected call of Info.
+func (mr *MockredisClientMockRecorder) Info(ctx interface{}, sections ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, sections...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Info", reflect.TypeOf((*MockredisClient)(nil).Info), varargs...)
+}
+
+// Ping mocks base method.
+func (m *MockredisClient) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockredisClientMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockredisClient)(nil).Ping), ctx)
+}
+
+// Stats mocks base method.
+func (m *MockredisClient) Stats() redis.Stats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(redis.Stats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockredisClientMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockredisClient)(nil).Stats))
+}
@@ -4,9 +4,12 @@ import (
 	"auth-service/internal/config"
 	"auth-service/internal/storage/redis"
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
+	goredis "github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 )
 
@@ -14,6 +17,7 @@ import (
 type Service struct {
 	cfg    *config.Redis
 	client redisClient
+	hooks  []goredis.Hook
 
 	once sync.Once
 	err  error
@@ -26,7 +30,16 @@ type Service struct {
 //go:generate mockgen -source=service.go -destination=mocks/mocks.go -package=mocks redisClient
 type redisClient interface {
 	Connect(ctx context.Context) error
+	Ping(ctx context.Context) error
 	Close(ctx context.Context) error
+	Cmd() goredis.UniversalClient
+	Info(ctx context.Context, sections ...string) (map[string]map[string]string, error)
+	Stats() redis.Stats
+}
+
+// clusterHook - хук, которому нужны узлы кластерного клиента (например, redis.TopologyHook).
+type clusterHook interface {
+	Attach(client goredis.UniversalClient)
 }
 
 // Option определяет опции для Service.
@@ -39,6 +52,13 @@ func WithCfg(cfg *config.Redis) Option {
 	}
 }
 
+// WithHooks устанавливает хуки go-redis, которые добавляются к клиенту при подключении.
+func WithHooks(hooks ...goredis.Hook) Option {
+	return func(s *Service) {
+		s.hooks = append(s.hooks, hooks...)
+	}
+}
+
 // New создает новый экземпляр Service для работы с Redis.
 func New(opts ...Option) (*Service, error) {
 	s := &Service{}
@@ -80,6 +100,14 @@ func (s *Service) Connect(ctx context.Context) error {
 			return
 		}
 
+		for _, hook := range s.hooks {
+			client.Cmd().AddHook(hook)
+
+			if hook, ok := hook.(clusterHook); ok {
+				hook.Attach(client.Cmd())
+			}
+		}
+
 		if s.err = client.Connect(ctx); s.err != nil {
 			s.err = fmt.Errorf("error connecting to redis: %w", s.err)
 			return
@@ -97,6 +125,73 @@ func (s *Service) Connect(ctx context.Context) error {
 	return s.err
 }
 
+// Ping проверяет соединение с Redis.
+func (s *Service) Ping(ctx context.Context) error {
+	s.mu.Lock()
+	client := s.client
+	s.mu.Unlock()
+
+	if client == nil {
+		return errors.New("redis is not connected")
+	}
+
+	return client.Ping(ctx)
+}
+
+// Info возвращает результат команды INFO каждого узла (для кластера - каждого мастера):
+// адрес узла -> поле -> значение. Без sections возвращаются секции по умолчанию.
+func (s *Service) Info(ctx context.Context, sections ...string) (map[string]map[string]string, error) {
+	s.mu.Lock()
+	client := s.client
+	s.mu.Unlock()
+
+	if client == nil {
+		return nil, errors.New("redis is not connected")
+	}
+
+	return client.Info(ctx, sections...)
+}
+
+// Time возвращает время сервера Redis (команда TIME). В кластере отвечает один из узлов.
+func (s *Service) Time(ctx context.Context) (time.Time, error) {
+	s.mu.Lock()
+	client := s.client
+	s.mu.Unlock()
+
+	if client == nil {
+		return time.Time{}, errors.New("redis is not connected")
+	}
+
+	return client.Cmd().Time(ctx).Result()
+}
+
+// Stats возвращает статистику пула соединений.
+func (s *Service) Stats() (redis.Stats, error) {
+	s.mu.Lock()
+	client := s.client
+	s.mu.Unlock()
+
+	if client == nil {
+		return redis.Stats{}, errors.New("redis is not connected")
+	}
+
+	return client.Stats(), nil
+}
+
+// Client возвращает клиент go-redis для хранилищ, работающих поверх Redis.
+// Одинаково работает с одиночным Redis и кластером.
+func (s *Service) Client() (goredis.UniversalClient, error) {
+	s.mu.Lock()
+	client := s.client
+	s.mu.Unlock()
+
+	if client == nil {
+		return nil, errors.New("redis is not connected")
+	}
+
+	return client.Cmd(), nil
+}
+
 // Stop закрывает соединение с Redis.
 func (s *Service) Stop(ctx context.Context) error {
 	logrus.WithFields(logrus.Fields{
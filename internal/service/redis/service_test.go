@@ -3,10 +3,17 @@ package redis
 import (
 	"auth-service/internal/config"
 	"auth-service/internal/service/redis/mocks"
+	"auth-service/internal/storage/redis"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	goredis "github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -34,6 +41,22 @@ func TestNew(t *testing.T) {
 			},
 			wantErr: require.NoError,
 		},
+		{
+			name: "positive case: with hooks",
+			opts: []Option{
+				WithCfg(&config.Redis{
+					Type: config.RedisTypeSingle,
+				}),
+				WithHooks(testHook{}),
+			},
+			want: &Service{
+				cfg: &config.Redis{
+					Type: config.RedisTypeSingle,
+				},
+				hooks: []goredis.Hook{testHook{}},
+			},
+			wantErr: require.NoError,
+		},
 		{
 			name:    "negative case: cfg is nil",
 			opts:    []Option{},
@@ -54,6 +77,17 @@ func TestNew(t *testing.T) {
 	}
 }
 
+// testHook - хук go-redis, который ничего не меняет.
+type testHook struct{}
+
+func (testHook) DialHook(next goredis.DialHook) goredis.DialHook { return next }
+
+func (testHook) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook { return next }
+
+func (testHook) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return next
+}
+
 //nolint:funlen // длинный тест - это ок
 func TestStop(t *testing.T) {
 	t.Parallel()
@@ -127,3 +161,160 @@ func TestStop(t *testing.T) {
 		})
 	}
 }
+
+func TestPing(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		createSvc func(t *testing.T, mockRedisClient *mocks.MockredisClient) *Service
+		wantErr   require.ErrorAssertionFunc
+	}{
+		{
+			name: "positive case",
+			createSvc: func(t *testing.T, mockRedisClient *mocks.MockredisClient) *Service {
+				t.Helper()
+
+				mockRedisClient.EXPECT().Ping(t.Context()).Return(nil)
+
+				return &Service{client: mockRedisClient}
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "negative case: ping error",
+			createSvc: func(t *testing.T, mockRedisClient *mocks.MockredisClient) *Service {
+				t.Helper()
+
+				mockRedisClient.EXPECT().Ping(t.Context()).Return(errors.New("connection refused"))
+
+				return &Service{client: mockRedisClient}
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "negative case: not connected",
+			createSvc: func(t *testing.T, mockRedisClient *mocks.MockredisClient) *Service {
+				t.Helper()
+
+				return &Service{}
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "redis is not connected")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRedisClient := mocks.NewMockredisClient(ctrl)
+
+			svc := tt.createSvc(t, mockRedisClient)
+
+			err := svc.Ping(t.Context())
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRedisClient := mocks.NewMockredisClient(ctrl)
+
+	cmd := goredis.NewClient(&goredis.Options{Addr: "localhost:6379"})
+	t.Cleanup(func() { _ = cmd.Close() })
+
+	mockRedisClient.EXPECT().Cmd().Return(cmd)
+
+	got, err := (&Service{client: mockRedisClient}).Client()
+	require.NoError(t, err)
+	assert.Equal(t, cmd, got)
+
+	_, err = (&Service{}).Client()
+	require.ErrorContains(t, err, "redis is not connected")
+}
+
+func TestInfoStats(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRedisClient := mocks.NewMockredisClient(ctrl)
+
+	info := map[string]map[string]string{"localhost:6379": {"connected_clients": "1"}}
+	stats := redis.Stats{Hits: 2, Misses: 1, TotalConns: 1, IdleConns: 1}
+
+	mockRedisClient.EXPECT().Info(t.Context(), "clients").Return(info, nil)
+	mockRedisClient.EXPECT().Stats().Return(stats).Times(2)
+
+	svc := &Service{client: mockRedisClient}
+
+	gotInfo, err := svc.Info(t.Context(), "clients")
+	require.NoError(t, err)
+	assert.Equal(t, info, gotInfo)
+
+	gotStats, err := svc.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, stats, gotStats)
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(svc.Collector()))
+
+	err = testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP auth_redis_pool_hits_total Количество соединений с Redis, взятых из пула.
+# TYPE auth_redis_pool_hits_total counter
+auth_redis_pool_hits_total 2
+# HELP auth_redis_pool_connections Количество соединений с Redis в пуле: total - все, idle - свободные, stale - закрытые как устаревшие.
+# TYPE auth_redis_pool_connections gauge
+auth_redis_pool_connections{state="idle"} 1
+auth_redis_pool_connections{state="stale"} 0
+auth_redis_pool_connections{state="total"} 1
+`), "auth_redis_pool_hits_total", "auth_redis_pool_connections")
+	require.NoError(t, err)
+
+	// без соединения метрики не отдаются
+	notConnected := &Service{}
+
+	_, err = notConnected.Info(t.Context())
+	require.ErrorContains(t, err, "redis is not connected")
+
+	_, err = notConnected.Stats()
+	require.ErrorContains(t, err, "redis is not connected")
+
+	assert.Equal(t, 0, testutil.CollectAndCount(notConnected.Collector()))
+}
+
+func TestTime(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mr := miniredis.RunT(t)
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	mr.SetTime(now)
+
+	cmd := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = cmd.Close() })
+
+	mockRedisClient := mocks.NewMockredisClient(ctrl)
+	mockRedisClient.EXPECT().Cmd().Return(cmd)
+
+	got, err := (&Service{client: mockRedisClient}).Time(t.Context())
+	require.NoError(t, err)
+	assert.True(t, now.Equal(got), "got %s", got)
+
+	_, err = (&Service{}).Time(t.Context())
+	require.ErrorContains(t, err, "redis is not connected")
+}
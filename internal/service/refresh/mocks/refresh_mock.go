@@ -0,0 +1,104 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: refresh.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	token "auth-service/internal/service/token"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MocktokenIssuer is a mock of tokenIssuer interface.
+type MocktokenIssuer struct {
+	ctrl     *gomock.Controller
+	recorder *MocktokenIssuerMockRecorder
+}
+
+// MocktokenIssuerMockRecorder is the mock recorder for MocktokenIssuer.
+type MocktokenIssuerMockRecorder struct {
+	mock *MocktokenIssuer
+}
+
+// NewMocktokenIssuer creates a new mock instance.
+func NewMocktokenIssuer(ctrl *gomock.Controller) *MocktokenIssuer {
+	mock := &MocktokenIssuer{ctrl: ctrl}
+	mock.recorder = &MocktokenIssuerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktokenIssuer) EXPECT() *MocktokenIssuerMockRecorder {
+	return m.recorder
+}
+
+// Issue mocks base method.
+func (m *MocktokenIssuer) Issue(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Issue", ctx, req)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*token.Claims)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Issue indicates an expected call of Issue.
+func (mr *MocktokenIssuerMockRecorder) Issue(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Issue", reflect.TypeOf((*MocktokenIssuer)(nil).Issue), ctx, req)
+}
+
+// MocksessionStats is a mock of sessionStats interface.
+type MocksessionStats struct {
+	ctrl     *gomock.Controller
+	recorder *MocksessionStatsMockRecorder
+}
+
+// MocksessionStatsMockRecorder is the mock recorder for MocksessionStats.
+type MocksessionStatsMockRecorder struct {
+	mock *MocksessionStats
+}
+
+// NewMocksessionStats creates a new mock instance.
+func NewMocksessionStats(ctrl *gomock.Controller) *MocksessionStats {
+	mock := &MocksessionStats{ctrl: ctrl}
+	mock.recorder = &MocksessionStatsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocksessionStats) EXPECT() *MocksessionStatsMockRecorder {
+	return m.recorder
+}
+
+// SessionEnded mocks base method.
+func (m *MocksessionStats) SessionEnded(ctx context.Context, session string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SessionEnded", ctx, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SessionEnded indicates an expected call of SessionEnded.
+func (mr *MocksessionStatsMockRecorder) SessionEnded(ctx, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SessionEnded", reflect.TypeOf((*MocksessionStats)(nil).SessionEnded), ctx, session)
+}
+
+// SessionStarted mocks base method.
+func (m *MocksessionStats) SessionStarted(ctx context.Context, session string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SessionStarted", ctx, session, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SessionStarted indicates an expected call of SessionStarted.
+func (mr *MocksessionStatsMockRecorder) SessionStarted(ctx, session, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SessionStarted", reflect.TypeOf((*MocksessionStats)(nil).SessionStarted), ctx, session, expiresAt)
+}
@@ -0,0 +1,858 @@
+// Package refresh выпускает refresh токены с ротацией: при каждом обновлении токен меняется на новый,
+// а старый перестает действовать. Токены одного входа образуют семейство, каждый токен записывает
+// своего родителя. Повторное использование уже замененного токена означает, что токен украден
+// (OAuth 2.0 Security BCP, refresh token rotation), поэтому отзывается все семейство: и у вора,
+// и у пользователя, которому придется войти заново.
+//
+// Семейство - это сессия входа: его id совпадает с claim sid токенов доступа входа, поэтому
+// после отзыва семейства проверка сессии (SessionRevoked) отклоняет и уже выпущенные токены доступа.
+package refresh
+
+import (
+	"auth-service/internal/service/codec"
+	"auth-service/internal/service/id"
+	"auth-service/internal/service/token"
+	"auth-service/internal/service/ttlcheck"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	keyPrefix = "auth:refresh:"
+
+	// tokenLength - длина секретной части refresh токена.
+	tokenLength = 48
+	// tokenSeparator отделяет id семейства от секретной части refresh токена.
+	tokenSeparator = "."
+	// idLength - длина идентификаторов токена и семейства.
+	idLength = 16
+	// rotateAttempts - сколько раз обновление повторяется, если токен или семейство изменили параллельно.
+	rotateAttempts = 3
+
+	// DefaultTTL - время жизни refresh токена: если токен не обновлялся столько времени, вход истекает.
+	DefaultTTL = 30 * 24 * time.Hour
+	// DefaultFamilyTTL - время жизни семейства: после него нужно войти заново, даже если токен обновлялся.
+	DefaultFamilyTTL = 90 * 24 * time.Hour
+	// DefaultAccessTTL - время жизни токенов доступа, выпущенных при обновлении.
+	DefaultAccessTTL = time.Hour
+)
+
+// Причины отзыва семейства.
+const (
+	// ReasonReuse - повторно использован замененный токен.
+	ReasonReuse = "reuse"
+	// ReasonAdmin - сессию завершил администратор.
+	ReasonAdmin = "admin"
+	// ReasonUser - сессию завершил сам пользователь.
+	ReasonUser = "user"
+	// ReasonLogout - пользователь вышел из сессии или из всех сессий.
+	ReasonLogout = "logout"
+)
+
+var (
+	// ErrInvalidToken - токен не найден, истек или его семейство отозвано.
+	ErrInvalidToken = errors.New("invalid refresh token")
+	// ErrReused - повторно использован замененный токен, семейство отозвано.
+	ErrReused = errors.New("refresh token reuse detected")
+	// ErrNotFound - семейство не найдено.
+	ErrNotFound = errors.New("refresh token family not found")
+	// ErrSessionExists - семейство для сессии входа уже начато.
+	ErrSessionExists = errors.New("refresh token family already exists for session")
+	// ErrConflict - токен или семейство все время меняли параллельно, обновление можно повторить.
+	ErrConflict = errors.New("refresh token was modified concurrently")
+)
+
+//go:generate mockgen -source=refresh.go -destination=mocks/refresh_mock.go -package=mocks
+type tokenIssuer interface {
+	Issue(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error)
+}
+
+// sessionStats - статистика активных сессий для продуктовой аналитики. Семейство refresh токенов - это сессия.
+type sessionStats interface {
+	SessionStarted(ctx context.Context, session string, expiresAt time.Time) error
+	SessionEnded(ctx context.Context, session string) error
+}
+
+// Token - выпущенный refresh токен.
+type Token struct {
+	Raw       string
+	ID        string
+	Family    string
+	ExpiresAt time.Time
+}
+
+// Rotation - результат обновления: новый refresh токен и токен доступа.
+type Rotation struct {
+	Refresh     *Token
+	AccessToken string
+	Claims      *token.Claims
+}
+
+// Family - семейство refresh токенов одного входа.
+type Family struct {
+	ID       string   `json:"id"`
+	Subject  string   `json:"subject"`
+	Audience []string `json:"audience,omitempty"`
+	// TelegramID - ID пользователя в Telegram при входе через Telegram, переносится в токены доступа семейства.
+	TelegramID int64 `json:"tg_id,omitempty"`
+	// Device - устройство, с которого выполнен вход.
+	Device    Device    `json:"device"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// RevokedAt - когда семейство отозвано, nil - действует.
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	RevokeReason string     `json:"revoke_reason,omitempty"`
+	// MaxExpiresAt - абсолютный срок скользящей сессии (см. WithSliding): ExpiresAt продлевается
+	// при активности, но не дальше. nil - сессия не скользящая.
+	MaxExpiresAt *time.Time `json:"max_expires_at,omitempty"`
+	// LastSeenAt - последняя записанная активность скользящей сессии.
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+	// Tokens - токены семейства в порядке выпуска.
+	Tokens []Node `json:"tokens"`
+
+	// idle - через сколько без активности истекает скользящая сессия, 0 - сессия не скользящая.
+	idle time.Duration
+}
+
+// Node - токен в цепочке обновлений.
+type Node struct {
+	ID string `json:"id"`
+	// Parent - токен, при обновлении которого выпущен этот. Пусто у первого токена семейства.
+	Parent    string    `json:"parent,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// RotatedAt - когда токен заменен следующим, nil - действующий токен семейства.
+	RotatedAt *time.Time `json:"rotated_at,omitempty"`
+}
+
+// Service - выпуск и ротация refresh токенов.
+//
+// Refresh токен имеет вид <id семейства>.<секрет>: по нему находится слот семейства.
+//
+// Ключи:
+//   - auth:refresh:{<id>}:token:<sha256 токена> - hash с токеном (id, family, parent, created_at, expires_at, rotated_at),
+//     TTL - время жизни токена;
+//   - auth:refresh:{<id>}:family - hash с семейством (subject, audience, user_agent, ip, created_at, expires_at, revoked_at,
+//     revoke_reason, у скользящих сессий idle, max_expires_at, last_seen), TTL - время жизни семейства;
+//   - auth:refresh:{<id>}:lineage - hash id токена: узел цепочки в формате codec (JSON или MessagePack),
+//     TTL - время жизни семейства;
+//   - auth:refresh:sessions:<subject> - sorted set id семейств субъекта со сроком семейства в score,
+//     TTL - самый поздний срок семейства.
+//
+// Ключи семейства содержат хэш-тег с его id и в Redis Cluster лежат в одном слоте, поэтому токены,
+// семейство и цепочка меняются в одной транзакции. Список сессий субъекта лежит в другом слоте
+// и пишется отдельно.
+//
+// TTL ключей выставляется по expires_at с запасом (WithTTLSlack), а срок действия проверяется по expires_at.
+type Service struct {
+	client redis.UniversalClient
+	issuer tokenIssuer
+	stats  sessionStats
+	codec  *codec.Codec
+
+	ttl       time.Duration
+	familyTTL time.Duration
+	accessTTL time.Duration
+	slack     time.Duration
+	// sliding - скользящее истечение сессий по аудиториям
+	sliding map[string]Sliding
+
+	registerer prometheus.Registerer
+	reuse      prometheus.Counter
+	rotations  *prometheus.CounterVec
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Service.
+type Option func(*Service)
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(s *Service) {
+		s.client = client
+	}
+}
+
+// WithIssuer устанавливает выпуск токенов доступа при обновлении.
+func WithIssuer(issuer tokenIssuer) Option {
+	return func(s *Service) {
+		s.issuer = issuer
+	}
+}
+
+// WithStats устанавливает статистику сессий: семейство учитывается как сессия до истечения или отзыва.
+// Ошибка учета не мешает выпуску и отзыву и только пишется в журнал.
+func WithStats(stats sessionStats) Option {
+	return func(s *Service) {
+		s.stats = stats
+	}
+}
+
+// WithCodec устанавливает кодирование узлов цепочки. По умолчанию JSON. Узлы читаются
+// в любом формате, поэтому формат можно сменить без удаления сохраненных семейств.
+func WithCodec(c *codec.Codec) Option {
+	return func(s *Service) {
+		s.codec = c
+	}
+}
+
+// WithTTL устанавливает время жизни refresh токена. По умолчанию DefaultTTL.
+func WithTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		s.ttl = ttl
+	}
+}
+
+// WithFamilyTTL устанавливает время жизни семейства. По умолчанию DefaultFamilyTTL.
+func WithFamilyTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		s.familyTTL = ttl
+	}
+}
+
+// WithAccessTTL устанавливает время жизни токенов доступа, выпущенных при обновлении. По умолчанию DefaultAccessTTL.
+func WithAccessTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		s.accessTTL = ttl
+	}
+}
+
+// WithTTLSlack устанавливает запас TTL ключей сверх срока действия токенов и семейств. По умолчанию ttlcheck.DefaultSlack.
+func WithTTLSlack(slack time.Duration) Option {
+	return func(s *Service) {
+		s.slack = slack
+	}
+}
+
+// WithRegisterer устанавливает реестр метрик. По умолчанию используется prometheus.DefaultRegisterer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(s *Service) {
+		s.registerer = registerer
+	}
+}
+
+// New создает новый Service и регистрирует его метрики.
+func New(opts ...Option) (*Service, error) {
+	s := &Service{
+		ttl:        DefaultTTL,
+		familyTTL:  DefaultFamilyTTL,
+		accessTTL:  DefaultAccessTTL,
+		slack:      ttlcheck.DefaultSlack,
+		registerer: prometheus.DefaultRegisterer,
+		now:        time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.client == nil {
+		return nil, errors.New("redis client is required")
+	}
+
+	if s.issuer == nil {
+		return nil, errors.New("issuer is required")
+	}
+
+	if s.registerer == nil {
+		return nil, errors.New("registerer is required")
+	}
+
+	if s.codec == nil {
+		var err error
+
+		if s.codec, err = codec.New(); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.ttl <= 0 || s.familyTTL <= 0 || s.accessTTL <= 0 {
+		return nil, errors.New("ttl, family ttl and access ttl must be positive")
+	}
+
+	if s.ttl > s.familyTTL {
+		return nil, errors.New("ttl must not exceed family ttl")
+	}
+
+	if s.slack < 0 {
+		return nil, errors.New("ttl slack must not be negative")
+	}
+
+	if err := s.validateSliding(); err != nil {
+		return nil, err
+	}
+
+	s.reuse = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "auth_refresh_reuse_detected_total",
+		Help: "Количество повторных использований замененных refresh токенов. Каждое отзывает семейство токенов.",
+	})
+
+	s.rotations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_refresh_rotations_total",
+		Help: "Количество обновлений refresh токенов: ok - токен заменен, invalid - токен не принят, reuse - повторное использование, conflict - токен или семейство меняли параллельно.",
+	}, []string{"result"})
+
+	for _, c := range []prometheus.Collector{s.reuse, s.rotations} {
+		if err := s.registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// familyTag возвращает общий префикс ключей семейства с хэш-тегом его id.
+func familyTag(family string) string {
+	return keyPrefix + "{" + family + "}:"
+}
+
+func tokenKey(family, raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return familyTag(family) + "token:" + hex.EncodeToString(sum[:])
+}
+
+func familyKey(family string) string {
+	return familyTag(family) + "family"
+}
+
+func lineageKey(family string) string {
+	return familyTag(family) + "lineage"
+}
+
+// familyOf возвращает id семейства refresh токена: часть до последнего разделителя.
+func familyOf(raw string) (string, bool) {
+	i := strings.LastIndex(raw, tokenSeparator)
+	if i <= 0 || i == len(raw)-len(tokenSeparator) {
+		return "", false
+	}
+
+	return raw[:i], true
+}
+
+func sessionsKey(subject string) string {
+	return keyPrefix + "sessions:" + subject
+}
+
+// Issue начинает семейство для входа, которым выпущен токен доступа claims, с устройства device
+// и выпускает его первый refresh токен. Семейство получает id сессии токена (claim sid);
+// у токена без sid id семейства создается.
+func (s *Service) Issue(ctx context.Context, claims *token.Claims, device Device) (*Token, error) {
+	if claims == nil || claims.Subject == "" {
+		return nil, errors.New("refresh: subject is required")
+	}
+
+	family, err := s.familyID(ctx, claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC().Truncate(time.Second)
+	familyExpiresAt := now.Add(s.familyTTL)
+	limit := familyExpiresAt
+
+	device = device.truncate()
+
+	fields := []any{
+		"subject", claims.Subject,
+		"audience", strings.Join(claims.Audience, " "),
+		"user_agent", device.UserAgent,
+		"ip", device.IP,
+		"created_at", now.Unix(),
+	}
+
+	if claims.TelegramID != 0 {
+		fields = append(fields, "tg_id", claims.TelegramID)
+	}
+
+	if sliding, ok := s.slidingFor(claims.Audience); ok {
+		limit = now.Add(sliding.MaxAge)
+		familyExpiresAt = now.Add(sliding.Idle)
+		fields = append(fields,
+			"idle", int64(sliding.Idle/time.Second),
+			"max_expires_at", limit.Unix(),
+			"last_seen", now.Unix(),
+		)
+	}
+
+	fields = append(fields, "expires_at", familyExpiresAt.Unix())
+
+	tok, node, err := s.newToken(family, "", now, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// список сессий пишется первым: если запись семейства не пройдет, лишний id в списке
+	// пропускается при чтении, а семейство вне списка не было бы видно пользователю
+	if err := s.indexSession(ctx, claims.Subject, family, now, limit); err != nil {
+		return nil, err
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, familyKey(family), fields...)
+		p.ExpireAt(ctx, familyKey(family), familyExpiresAt.Add(s.slack))
+
+		return s.saveToken(ctx, p, tok, node, familyExpiresAt)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("refresh: error save token: %w", err)
+	}
+
+	if s.stats != nil {
+		// скользящая сессия учитывается до абсолютного срока: продления статистика не видит
+		if err := s.stats.SessionStarted(ctx, family, limit); err != nil {
+			logrus.WithError(err).Warn("error count started session")
+		}
+	}
+
+	return tok, nil
+}
+
+// Rotate меняет refresh токен на новый того же семейства и выпускает токен доступа.
+// Если токен уже был заменен, семейство отзывается и возвращается ErrReused.
+// Если токен или семейство меняли параллельно (например, отметка активности сессии),
+// обновление повторяется, а после rotateAttempts попыток возвращается ErrConflict.
+func (s *Service) Rotate(ctx context.Context, raw string) (*Rotation, error) {
+	family, ok := familyOf(raw)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	var (
+		rotation *Rotation
+		tokenID  string
+		err      error
+	)
+
+	for range rotateAttempts {
+		// после конфликта токен читается заново: если его заменил параллельный запрос,
+		// повторная попытка увидит rotated_at и вернет ErrReused
+		rotation, tokenID, err = s.rotate(ctx, family, raw)
+		if !errors.Is(err, redis.TxFailedErr) {
+			break
+		}
+	}
+
+	switch {
+	case errors.Is(err, redis.TxFailedErr):
+		s.rotations.WithLabelValues("conflict").Inc()
+
+		return nil, ErrConflict
+	case errors.Is(err, ErrReused):
+		s.detectReuse(ctx, family, tokenID)
+
+		return nil, ErrReused
+	case errors.Is(err, ErrInvalidToken):
+		s.rotations.WithLabelValues("invalid").Inc()
+
+		return nil, err
+	case err != nil:
+		return nil, err
+	}
+
+	s.rotations.WithLabelValues("ok").Inc()
+
+	return rotation, nil
+}
+
+// rotate выполняет одну попытку обновления под WATCH токена и семейства: отзыв семейства
+// и обновление его токена не проходят одновременно. Возвращает id предъявленного токена
+// и redis.TxFailedErr, если ключи изменили параллельно.
+func (s *Service) rotate(ctx context.Context, family, raw string) (*Rotation, string, error) {
+	key := tokenKey(family, raw)
+
+	var (
+		rotation *Rotation
+		tokenID  string
+	)
+
+	err := s.client.Watch(ctx, func(tx *redis.Tx) error {
+		state, err := tx.HGetAll(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("refresh: error get token: %w", err)
+		}
+
+		if len(state) == 0 {
+			return ErrInvalidToken
+		}
+
+		if state["family"] != family {
+			return ErrInvalidToken
+		}
+
+		tokenID = state["id"]
+
+		fam, err := s.loadFamily(ctx, tx, family)
+		if errors.Is(err, ErrNotFound) {
+			return ErrInvalidToken
+		}
+
+		if err != nil {
+			return err
+		}
+
+		now := s.now().UTC().Truncate(time.Second)
+
+		if fam.RevokedAt != nil || !now.Before(fam.ExpiresAt) {
+			return ErrInvalidToken
+		}
+
+		// у токенов, выпущенных до записи expires_at, срок действия задает TTL ключа
+		if expiresAt := state["expires_at"]; expiresAt != "" && !now.Before(unixTime(expiresAt)) {
+			return ErrInvalidToken
+		}
+
+		if state["rotated_at"] != "" {
+			return ErrReused
+		}
+
+		// обновление - активность сессии: скользящая сессия продлевается
+		expiresAt := fam.ExpiresAt
+		if fam.idle > 0 {
+			expiresAt = fam.slide(now)
+		}
+
+		next, node, err := s.newToken(family, tokenID, now, fam.limit())
+		if err != nil {
+			return err
+		}
+
+		// токен доступа выпускается до записи, но возвращается только если запись прошла:
+		// при параллельном обновлении токен заменит один из запросов
+		access, claims, err := s.issuer.Issue(ctx, token.IssueRequest{
+			Subject:    fam.Subject,
+			Audience:   fam.Audience,
+			TTL:        s.accessTTL,
+			SessionID:  family,
+			TelegramID: fam.TelegramID,
+		})
+		if err != nil {
+			return fmt.Errorf("refresh: error issue access token: %w", err)
+		}
+
+		parent, err := s.loadNode(ctx, tx, family, tokenID)
+		if err != nil {
+			return err
+		}
+
+		parent.RotatedAt = &now
+
+		parentData, err := s.codec.Marshal(parent)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			p.HSet(ctx, key, "rotated_at", now.Unix())
+			p.HSet(ctx, lineageKey(family), parent.ID, parentData)
+
+			if fam.idle > 0 {
+				p.HSet(ctx, familyKey(family), "expires_at", expiresAt.Unix(), "last_seen", now.Unix())
+				p.ExpireAt(ctx, familyKey(family), expiresAt.Add(s.slack))
+			}
+
+			return s.saveToken(ctx, p, next, node, expiresAt)
+		})
+		if err != nil {
+			return err
+		}
+
+		rotation = &Rotation{Refresh: next, AccessToken: access, Claims: claims}
+
+		return nil
+	}, key, familyKey(family))
+
+	return rotation, tokenID, err
+}
+
+// detectReuse учитывает повторное использование и отзывает семейство.
+func (s *Service) detectReuse(ctx context.Context, family, tokenID string) {
+	s.reuse.Inc()
+	s.rotations.WithLabelValues("reuse").Inc()
+
+	log := logrus.WithFields(logrus.Fields{
+		"family": family,
+		"token":  tokenID,
+	})
+
+	// запрос мог быть отменен клиентом, а семейство нужно отозвать в любом случае
+	if err := s.Revoke(context.WithoutCancel(ctx), family, ReasonReuse); err != nil {
+		log.WithError(err).Error("error revoke refresh token family after reuse")
+
+		return
+	}
+
+	log.Warn("refresh token reuse detected, family revoked")
+}
+
+// Revoke отзывает семейство: ни один его токен больше не обновляется, а сессия пропадает из списка сессий субъекта.
+func (s *Service) Revoke(ctx context.Context, family, reason string) error {
+	key := familyKey(family)
+
+	subject, err := s.client.HGet(ctx, key, "subject").Result()
+	if errors.Is(err, redis.Nil) {
+		return ErrNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("refresh: error get family: %w", err)
+	}
+
+	if err := s.client.HSet(ctx, key, "revoked_at", s.now().Unix(), "revoke_reason", reason).Err(); err != nil {
+		return fmt.Errorf("refresh: error revoke family: %w", err)
+	}
+
+	// список сессий в другом слоте кластера; отозванное семейство из списка не возвращается
+	// и без удаления, поэтому ошибка только пишется в журнал
+	if err := s.client.ZRem(ctx, sessionsKey(subject), family).Err(); err != nil {
+		logrus.WithError(err).WithField("family", family).Warn("error remove revoked family from sessions")
+	}
+
+	if s.stats != nil {
+		if err := s.stats.SessionEnded(ctx, family); err != nil {
+			logrus.WithError(err).Warn("error count ended session")
+		}
+	}
+
+	return nil
+}
+
+// SessionRevoked возвращает true, если сессия sid - семейство refresh токенов - отозвана.
+// Неизвестная сессия не считается отозванной: токен мог быть выпущен без refresh токена,
+// а семейство живет дольше токенов доступа, выпущенных при его обновлении.
+func (s *Service) SessionRevoked(ctx context.Context, sid string) (bool, error) {
+	revokedAt, err := s.client.HGet(ctx, familyKey(sid), "revoked_at").Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("refresh: error get session: %w", err)
+	}
+
+	return revokedAt != "", nil
+}
+
+// Family возвращает семейство и цепочку его токенов.
+func (s *Service) Family(ctx context.Context, family string) (*Family, error) {
+	fam, err := s.loadFamily(ctx, s.client, family)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := s.client.HGetAll(ctx, lineageKey(family)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("refresh: error get lineage: %w", err)
+	}
+
+	children := make(map[string]Node, len(nodes))
+
+	for _, data := range nodes {
+		var node Node
+
+		if err := s.codec.Unmarshal([]byte(data), &node); err != nil {
+			return nil, fmt.Errorf("refresh: error decode lineage: %w", err)
+		}
+
+		children[node.Parent] = node
+	}
+
+	fam.Tokens = chain(children)
+
+	return fam, nil
+}
+
+// chain выстраивает токены семейства от первого к действующему. У каждого токена не больше
+// одного потомка: замененный токен больше не обновляется.
+func chain(children map[string]Node) []Node {
+	tokens := make([]Node, 0, len(children))
+
+	for node, ok := children[""]; ok && len(tokens) < len(children); node, ok = children[node.ID] {
+		tokens = append(tokens, node)
+	}
+
+	return tokens
+}
+
+// familyID возвращает id семейства для сессии sid. Семейство не начинается повторно: иначе
+// вход с чужим sid снял бы отметку об отзыве уже завершенной сессии.
+func (s *Service) familyID(ctx context.Context, sid string) (string, error) {
+	if sid == "" {
+		family, err := id.Generate(idLength)
+		if err != nil {
+			return "", fmt.Errorf("refresh: error generate family id: %w", err)
+		}
+
+		return family, nil
+	}
+
+	n, err := s.client.Exists(ctx, familyKey(sid)).Result()
+	if err != nil {
+		return "", fmt.Errorf("refresh: error get family: %w", err)
+	}
+
+	if n != 0 {
+		return "", ErrSessionExists
+	}
+
+	return sid, nil
+}
+
+// newToken создает токен семейства. Токен живет TTL, но не дольше limit - срока семейства.
+func (s *Service) newToken(family, parent string, now, limit time.Time) (*Token, *Node, error) {
+	secret, err := id.Generate(tokenLength)
+	if err != nil {
+		return nil, nil, fmt.Errorf("refresh: error generate token: %w", err)
+	}
+
+	raw := family + tokenSeparator + secret
+
+	tokenID, err := id.Generate(idLength)
+	if err != nil {
+		return nil, nil, fmt.Errorf("refresh: error generate token id: %w", err)
+	}
+
+	tok := &Token{
+		Raw:       raw,
+		ID:        tokenID,
+		Family:    family,
+		ExpiresAt: now.Add(s.ttl),
+	}
+
+	if tok.ExpiresAt.After(limit) {
+		tok.ExpiresAt = limit
+	}
+
+	return tok, &Node{ID: tokenID, Parent: parent, CreatedAt: now}, nil
+}
+
+func (s *Service) saveToken(ctx context.Context, p redis.Pipeliner, tok *Token, node *Node, familyExpiresAt time.Time) error {
+	data, err := s.codec.Marshal(node)
+	if err != nil {
+		return err
+	}
+
+	key := tokenKey(tok.Family, tok.Raw)
+
+	p.HSet(ctx, key,
+		"id", tok.ID,
+		"family", tok.Family,
+		"parent", node.Parent,
+		"created_at", node.CreatedAt.Unix(),
+		"expires_at", tok.ExpiresAt.Unix(),
+	)
+	p.ExpireAt(ctx, key, tok.ExpiresAt.Add(s.slack))
+	p.HSet(ctx, lineageKey(tok.Family), tok.ID, data)
+	p.ExpireAt(ctx, lineageKey(tok.Family), familyExpiresAt.Add(s.slack))
+
+	return nil
+}
+
+func (s *Service) loadFamily(ctx context.Context, client redis.Cmdable, family string) (*Family, error) {
+	if family == "" {
+		return nil, ErrNotFound
+	}
+
+	state, err := client.HGetAll(ctx, familyKey(family)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("refresh: error get family: %w", err)
+	}
+
+	if len(state) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return parseFamily(family, state), nil
+}
+
+// parseFamily разбирает hash семейства.
+func parseFamily(family string, state map[string]string) *Family {
+	fam := &Family{
+		ID:           family,
+		Subject:      state["subject"],
+		Audience:     strings.Fields(state["audience"]),
+		Device:       Device{UserAgent: state["user_agent"], IP: state["ip"]},
+		CreatedAt:    unixTime(state["created_at"]),
+		ExpiresAt:    unixTime(state["expires_at"]),
+		RevokeReason: state["revoke_reason"],
+	}
+
+	if state["tg_id"] != "" {
+		fam.TelegramID, _ = strconv.ParseInt(state["tg_id"], 10, 64)
+	}
+
+	if state["revoked_at"] != "" {
+		revokedAt := unixTime(state["revoked_at"])
+		fam.RevokedAt = &revokedAt
+	}
+
+	fam.loadSliding(state)
+
+	return fam
+}
+
+func (s *Service) loadNode(ctx context.Context, client redis.Cmdable, family, tokenID string) (*Node, error) {
+	data, err := client.HGet(ctx, lineageKey(family), tokenID).Result()
+	if errors.Is(err, redis.Nil) {
+		return &Node{ID: tokenID}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("refresh: error get lineage: %w", err)
+	}
+
+	var node Node
+
+	if err := s.codec.Unmarshal([]byte(data), &node); err != nil {
+		return nil, fmt.Errorf("refresh: error decode lineage: %w", err)
+	}
+
+	return &node, nil
+}
+
+// TTLRecords возвращает записи токенов и семейств для проверки согласованности TTL (ttlcheck).
+func (s *Service) TTLRecords() []ttlcheck.Record {
+	return []ttlcheck.Record{
+		{Name: "refresh_token", Pattern: keyPrefix + "{*}:token:*", Expiry: s.fieldExpiry(func(key string) string { return key })},
+		{Name: "refresh_family", Pattern: keyPrefix + "{*}:family", Expiry: s.fieldExpiry(func(key string) string { return key })},
+		{
+			// цепочка живет столько же, сколько семейство
+			Name:    "refresh_lineage",
+			Pattern: keyPrefix + "{*}:lineage",
+			Expiry: s.fieldExpiry(func(key string) string {
+				return strings.TrimSuffix(key, "lineage") + "family"
+			}),
+		},
+	}
+}
+
+// fieldExpiry возвращает срок действия из поля expires_at hash, ключ которого получается из ключа записи.
+func (s *Service) fieldExpiry(hashKey func(key string) string) func(ctx context.Context, key string) (time.Time, bool, error) {
+	return func(ctx context.Context, key string) (time.Time, bool, error) {
+		value, err := s.client.HGet(ctx, hashKey(key), "expires_at").Result()
+		if errors.Is(err, redis.Nil) {
+			return time.Time{}, false, nil
+		}
+
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("refresh: error get expiry: %w", err)
+		}
+
+		return unixTime(value), true, nil
+	}
+}
+
+func unixTime(value string) time.Time {
+	ts, _ := strconv.ParseInt(value, 10, 64)
+
+	return time.Unix(ts, 0).UTC()
+}
@@ -0,0 +1,630 @@
+package refresh
+
+import (
+	"auth-service/internal/service/codec"
+	"auth-service/internal/service/refresh/mocks"
+	"auth-service/internal/service/token"
+	"auth-service/internal/service/ttlcheck"
+	redisstorage "auth-service/internal/storage/redis"
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newService(t *testing.T, opts ...Option) (*Service, *mocks.MocktokenIssuer, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	issuer := mocks.NewMocktokenIssuer(gomock.NewController(t))
+
+	s, err := New(append([]Option{WithClient(client), WithIssuer(issuer), WithRegisterer(prometheus.NewRegistry())}, opts...)...)
+	require.NoError(t, err)
+
+	return s, issuer, mr
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	t.Cleanup(func() { _ = client.Close() })
+
+	issuer := mocks.NewMocktokenIssuer(gomock.NewController(t))
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			opts:    []Option{WithClient(client), WithIssuer(issuer)},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: no client",
+			opts:    []Option{WithIssuer(issuer)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: no issuer",
+			opts:    []Option{WithClient(client)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: ttl exceeds family ttl",
+			opts:    []Option{WithClient(client), WithIssuer(issuer), WithTTL(48 * time.Hour), WithFamilyTTL(24 * time.Hour)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: negative access ttl",
+			opts:    []Option{WithClient(client), WithIssuer(issuer), WithAccessTTL(-time.Minute)},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(append(tt.opts, WithRegisterer(prometheus.NewRegistry()))...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+func expectIssue(issuer *mocks.MocktokenIssuer) {
+	issuer.EXPECT().Issue(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, req token.IssueRequest) (string, *token.Claims, error) {
+			claims := &token.Claims{Subject: req.Subject, Audience: req.Audience}
+			claims.TelegramID = req.TelegramID
+
+			return "access-" + req.Subject, claims, nil
+		},
+	).AnyTimes()
+}
+
+func TestService_Rotate(t *testing.T) {
+	t.Parallel()
+
+	s, issuer, _ := newService(t, WithAccessTTL(10*time.Minute))
+	expectIssue(issuer)
+
+	first, err := s.Issue(t.Context(), &token.Claims{Subject: "user-1", Audience: []string{"telegram-bot"}}, Device{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, first.Raw)
+	assert.NotEmpty(t, first.Family)
+
+	second, err := s.Rotate(t.Context(), first.Raw)
+	require.NoError(t, err)
+	assert.Equal(t, "access-user-1", second.AccessToken)
+	assert.Equal(t, []string{"telegram-bot"}, second.Claims.Audience)
+	assert.Equal(t, first.Family, second.Refresh.Family)
+	assert.NotEqual(t, first.Raw, second.Refresh.Raw)
+
+	third, err := s.Rotate(t.Context(), second.Refresh.Raw)
+	require.NoError(t, err)
+
+	family, err := s.Family(t.Context(), first.Family)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", family.Subject)
+	assert.Nil(t, family.RevokedAt)
+	require.Len(t, family.Tokens, 3)
+
+	ids := map[string]Node{}
+	for _, node := range family.Tokens {
+		ids[node.ID] = node
+	}
+
+	assert.Empty(t, ids[first.ID].Parent)
+	assert.NotNil(t, ids[first.ID].RotatedAt)
+	assert.Equal(t, first.ID, ids[second.Refresh.ID].Parent)
+	assert.NotNil(t, ids[second.Refresh.ID].RotatedAt)
+	assert.Equal(t, second.Refresh.ID, ids[third.Refresh.ID].Parent)
+	assert.Nil(t, ids[third.Refresh.ID].RotatedAt)
+
+	assert.InDelta(t, 2, testutil.ToFloat64(s.rotations.WithLabelValues("ok")), 0)
+}
+
+func TestService_Rotate_TelegramID(t *testing.T) {
+	t.Parallel()
+
+	s, issuer, _ := newService(t)
+	expectIssue(issuer)
+
+	claims := &token.Claims{Subject: "telegram:42", Audience: []string{"telegram-bot"}}
+	claims.TelegramID = 42
+
+	first, err := s.Issue(t.Context(), claims, Device{})
+	require.NoError(t, err)
+
+	// tg_id переносится в access-токены, выпущенные при ротации
+	second, err := s.Rotate(t.Context(), first.Raw)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), second.Claims.TelegramID)
+
+	family, err := s.Family(t.Context(), first.Family)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), family.TelegramID)
+}
+
+func TestService_Codec(t *testing.T) {
+	t.Parallel()
+
+	// семейство начато экземпляром, который записывает узлы в JSON
+	legacy, issuer, mr := newService(t)
+	expectIssue(issuer)
+
+	first, err := legacy.Issue(t.Context(), &token.Claims{Subject: "user-1"}, Device{})
+	require.NoError(t, err)
+
+	records, err := codec.New(codec.WithFormat(codec.FormatMsgpack))
+	require.NoError(t, err)
+
+	s, err := New(WithClient(legacy.client), WithIssuer(issuer), WithCodec(records), WithRegisterer(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	second, err := s.Rotate(t.Context(), first.Raw)
+	require.NoError(t, err)
+
+	// оба узла перезаписаны в MessagePack, прежний экземпляр их читает
+	for _, id := range []string{first.ID, second.Refresh.ID} {
+		data := mr.HGet(lineageKey(first.Family), id)
+		require.NotEmpty(t, data)
+		assert.NotEqual(t, byte('{'), data[0])
+	}
+
+	family, err := legacy.Family(t.Context(), first.Family)
+	require.NoError(t, err)
+	require.Len(t, family.Tokens, 2)
+	assert.Equal(t, first.ID, family.Tokens[1].Parent)
+	assert.NotNil(t, family.Tokens[0].RotatedAt)
+}
+
+func TestService_TTLRecords(t *testing.T) {
+	t.Parallel()
+
+	s, issuer, mr := newService(t, WithTTL(time.Hour), WithFamilyTTL(2*time.Hour), WithTTLSlack(time.Minute))
+	expectIssue(issuer)
+
+	tok, err := s.Issue(t.Context(), &token.Claims{Subject: "user-1"}, Device{})
+	require.NoError(t, err)
+
+	// TTL ключей - срок действия с запасом
+	assert.InDelta(t, time.Hour+time.Minute, mr.TTL(tokenKey(tok.Family, tok.Raw)), float64(time.Second))
+	assert.InDelta(t, 2*time.Hour+time.Minute, mr.TTL(familyKey(tok.Family)), float64(time.Second))
+	assert.InDelta(t, 2*time.Hour+time.Minute, mr.TTL(lineageKey(tok.Family)), float64(time.Second))
+
+	fam, err := s.Family(t.Context(), tok.Family)
+	require.NoError(t, err)
+
+	want := map[string]struct {
+		key       string
+		expiresAt time.Time
+	}{
+		"refresh_token":   {key: tokenKey(tok.Family, tok.Raw), expiresAt: tok.ExpiresAt},
+		"refresh_family":  {key: familyKey(tok.Family), expiresAt: fam.ExpiresAt},
+		"refresh_lineage": {key: lineageKey(tok.Family), expiresAt: fam.ExpiresAt},
+	}
+
+	records := s.TTLRecords()
+	require.Len(t, records, len(want))
+
+	for _, record := range records {
+		w, ok := want[record.Name]
+		require.True(t, ok, record.Name)
+
+		matched, err := path.Match(record.Pattern, w.key)
+		require.NoError(t, err)
+		assert.True(t, matched, record.Name)
+
+		expiresAt, ok, err := record.Expiry(t.Context(), w.key)
+		require.NoError(t, err)
+		assert.True(t, ok, record.Name)
+		assert.True(t, w.expiresAt.Equal(expiresAt), record.Name)
+	}
+
+	// у токенов, записанных до expires_at, срок не проверяется
+	mr.HDel(tokenKey(tok.Family, tok.Raw), "expires_at")
+
+	_, ok, err := records[0].Expiry(t.Context(), tokenKey(tok.Family, tok.Raw))
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestService_Rotate_Reuse(t *testing.T) {
+	t.Parallel()
+
+	s, issuer, _ := newService(t)
+	expectIssue(issuer)
+
+	first, err := s.Issue(t.Context(), &token.Claims{Subject: "user-1"}, Device{})
+	require.NoError(t, err)
+
+	second, err := s.Rotate(t.Context(), first.Raw)
+	require.NoError(t, err)
+
+	// украденный первый токен использован повторно
+	_, err = s.Rotate(t.Context(), first.Raw)
+	require.ErrorIs(t, err, ErrReused)
+
+	assert.InDelta(t, 1, testutil.ToFloat64(s.reuse), 0)
+
+	// все семейство отозвано, в том числе действующий токен пользователя
+	_, err = s.Rotate(t.Context(), second.Refresh.Raw)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	family, err := s.Family(t.Context(), first.Family)
+	require.NoError(t, err)
+	require.NotNil(t, family.RevokedAt)
+	assert.Equal(t, ReasonReuse, family.RevokeReason)
+}
+
+func TestService_Rotate_Conflict(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// touches - сколько попыток обновления семейство меняется параллельно
+		touches int
+		wantErr error
+	}{
+		{name: "positive case: retried after concurrent touch", touches: 1},
+		{name: "error case: family changes on every attempt", touches: rotateAttempts, wantErr: ErrConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, issuer, _ := newService(t)
+
+			touches := 0
+
+			// параллельная отметка активности сессии меняет семейство между чтением и записью
+			issuer.EXPECT().Issue(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error) {
+					if touches < tt.touches {
+						touches++
+
+						require.NoError(t, s.client.HSet(ctx, familyKey(req.SessionID), "last_seen", touches).Err())
+					}
+
+					return "access-" + req.Subject, &token.Claims{Subject: req.Subject}, nil
+				},
+			).AnyTimes()
+
+			first, err := s.Issue(t.Context(), &token.Claims{Subject: "user-1"}, Device{})
+			require.NoError(t, err)
+
+			_, err = s.Rotate(t.Context(), first.Raw)
+			require.ErrorIs(t, err, tt.wantErr)
+
+			// конфликт не считается повторным использованием: семейство не отозвано
+			assert.InDelta(t, 0, testutil.ToFloat64(s.reuse), 0)
+
+			family, err := s.Family(t.Context(), first.Family)
+			require.NoError(t, err)
+			assert.Nil(t, family.RevokedAt)
+
+			if tt.wantErr != nil {
+				assert.InDelta(t, 1, testutil.ToFloat64(s.rotations.WithLabelValues("conflict")), 0)
+
+				// токен не заменен и обновляется следующим запросом
+				_, err = s.Rotate(t.Context(), first.Raw)
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestService_Rotate_ConcurrentReuse(t *testing.T) {
+	t.Parallel()
+
+	s, issuer, _ := newService(t)
+
+	var (
+		first    *Token
+		inner    *Rotation
+		innerErr error
+	)
+
+	// пока запрос обновляет токен, тот же токен обновляет другой запрос
+	issuer.EXPECT().Issue(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error) {
+			if first != nil && inner == nil && innerErr == nil {
+				raw := first.Raw
+				first = nil
+
+				inner, innerErr = s.Rotate(ctx, raw)
+			}
+
+			return "access-" + req.Subject, &token.Claims{Subject: req.Subject}, nil
+		},
+	).AnyTimes()
+
+	tok, err := s.Issue(t.Context(), &token.Claims{Subject: "user-1"}, Device{})
+	require.NoError(t, err)
+
+	first = tok
+
+	_, err = s.Rotate(t.Context(), tok.Raw)
+	require.ErrorIs(t, err, ErrReused)
+	require.NoError(t, innerErr)
+	require.NotNil(t, inner)
+
+	// повторная попытка увидела, что токен уже заменен: семейство отозвано
+	family, err := s.Family(t.Context(), tok.Family)
+	require.NoError(t, err)
+	require.NotNil(t, family.RevokedAt)
+	assert.Equal(t, ReasonReuse, family.RevokeReason)
+}
+
+func TestService_Rotate_Invalid(t *testing.T) {
+	t.Parallel()
+
+	s, issuer, mr := newService(t, WithTTL(time.Hour), WithFamilyTTL(2*time.Hour))
+	expectIssue(issuer)
+
+	_, err := s.Rotate(t.Context(), "")
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	for _, raw := range []string{"unknown", "unknown.", ".unknown", "family.unknown"} {
+		_, err = s.Rotate(t.Context(), raw)
+		require.ErrorIs(t, err, ErrInvalidToken, raw)
+	}
+
+	first, err := s.Issue(t.Context(), &token.Claims{Subject: "user-1"}, Device{})
+	require.NoError(t, err)
+
+	// секрет токена с чужим id семейства не находится
+	_, secret, _ := strings.Cut(first.Raw, tokenSeparator)
+	_, err = s.Rotate(t.Context(), "other"+tokenSeparator+secret)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// токен не обновлялся дольше своего времени жизни: ключ еще хранится с запасом TTL,
+	// но срок действия истек
+	s.now = func() time.Time { return time.Now().Add(time.Hour + time.Second) }
+
+	_, err = s.Rotate(t.Context(), first.Raw)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// после запаса ключ удаляется
+	mr.FastForward(time.Hour + ttlcheck.DefaultSlack + time.Second)
+
+	_, err = s.Rotate(t.Context(), first.Raw)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	s.now = time.Now
+
+	// токен не переживает семейство
+	second, err := s.Issue(t.Context(), &token.Claims{Subject: "user-1"}, Device{})
+	require.NoError(t, err)
+
+	s.now = func() time.Time { return time.Now().Add(50 * time.Minute) }
+
+	rotated, err := s.Rotate(t.Context(), second.Raw)
+	require.NoError(t, err)
+
+	s.now = func() time.Time { return time.Now().Add(100 * time.Minute) }
+
+	third, err := s.Rotate(t.Context(), rotated.Refresh.Raw)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(2*time.Hour), third.Refresh.ExpiresAt, 2*time.Second)
+
+	_, err = s.Issue(t.Context(), &token.Claims{}, Device{})
+	require.Error(t, err)
+}
+
+func TestService_Revoke(t *testing.T) {
+	t.Parallel()
+
+	s, issuer, _ := newService(t)
+	expectIssue(issuer)
+
+	require.ErrorIs(t, s.Revoke(t.Context(), "unknown", "logout"), ErrNotFound)
+
+	first, err := s.Issue(t.Context(), &token.Claims{Subject: "user-1"}, Device{})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Revoke(t.Context(), first.Family, "logout"))
+
+	_, err = s.Rotate(t.Context(), first.Raw)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = s.Family(t.Context(), "unknown")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestService_Stats(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	stats := mocks.NewMocksessionStats(ctrl)
+
+	s, _, _ := newService(t, WithStats(stats), WithFamilyTTL(48*time.Hour), WithTTL(time.Hour))
+
+	var family string
+
+	stats.EXPECT().SessionStarted(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, session string, expiresAt time.Time) error {
+			family = session
+
+			assert.WithinDuration(t, time.Now().Add(48*time.Hour), expiresAt, 2*time.Second)
+
+			return nil
+		})
+
+	first, err := s.Issue(t.Context(), &token.Claims{Subject: "user-1"}, Device{})
+	require.NoError(t, err)
+	assert.Equal(t, first.Family, family)
+
+	// ошибка статистики не мешает отзыву
+	stats.EXPECT().SessionEnded(gomock.Any(), first.Family).Return(errors.New("redis is down"))
+	require.NoError(t, s.Revoke(t.Context(), first.Family, "logout"))
+}
+
+func TestService_Session(t *testing.T) {
+	t.Parallel()
+
+	s, issuer, mr := newService(t)
+
+	var sessions []string
+
+	issuer.EXPECT().Issue(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, req token.IssueRequest) (string, *token.Claims, error) {
+			sessions = append(sessions, req.SessionID)
+
+			return "access", &token.Claims{Subject: req.Subject, SessionID: req.SessionID}, nil
+		},
+	).AnyTimes()
+
+	// семейство получает id сессии токена входа
+	first, err := s.Issue(t.Context(), &token.Claims{Subject: "user-1", SessionID: "session-1"}, Device{})
+	require.NoError(t, err)
+	assert.Equal(t, "session-1", first.Family)
+
+	// токены доступа, выпущенные при обновлении, относятся к той же сессии
+	second, err := s.Rotate(t.Context(), first.Raw)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"session-1"}, sessions)
+	assert.Equal(t, "session-1", second.Claims.SessionID)
+
+	// сессия не начинается повторно
+	_, err = s.Issue(t.Context(), &token.Claims{Subject: "user-2", SessionID: "session-1"}, Device{})
+	require.ErrorIs(t, err, ErrSessionExists)
+
+	revoked, err := s.SessionRevoked(t.Context(), "session-1")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	revoked, err = s.SessionRevoked(t.Context(), "unknown")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	require.NoError(t, s.Revoke(t.Context(), "session-1", ReasonAdmin))
+
+	revoked, err = s.SessionRevoked(t.Context(), "session-1")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	mr.Close()
+
+	_, err = s.SessionRevoked(t.Context(), "session-1")
+	require.Error(t, err)
+}
+
+// sameSlotHook проверяет, что ключи каждой транзакции лежат в одном слоте Redis Cluster.
+// Транзакции через ClusterClient go-redis проверяет сам (ErrCrossSlot), а транзакции внутри
+// WATCH выполняются клиентом узла и без хука не проверяются: miniredis не возвращает CROSSSLOT.
+type sameSlotHook struct {
+	t *testing.T
+}
+
+func (h sameSlotHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h sameSlotHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return next
+}
+
+func (h sameSlotHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if len(cmds) > 0 && cmds[0].Name() == "multi" {
+			slots := map[int][]string{}
+
+			for _, cmd := range cmds[1 : len(cmds)-1] {
+				if key, ok := cmd.Args()[1].(string); ok {
+					slot := redisstorage.Slot(key)
+					slots[slot] = append(slots[slot], key)
+				}
+			}
+
+			assert.Len(h.t, slots, 1, "transaction keys in different slots: %v", slots)
+		}
+
+		return next(ctx, cmds)
+	}
+}
+
+func TestService_Cluster(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClusterClient(&redis.ClusterOptions{Addrs: []string{mr.Addr()}})
+	t.Cleanup(func() { _ = client.Close() })
+
+	client.OnNewNode(func(node *redis.Client) {
+		node.AddHook(sameSlotHook{t: t})
+	})
+
+	issuer := mocks.NewMocktokenIssuer(gomock.NewController(t))
+	expectIssue(issuer)
+
+	s, err := New(
+		WithClient(client),
+		WithIssuer(issuer),
+		WithRegisterer(prometheus.NewRegistry()),
+		WithSliding("telegram-bot", Sliding{Idle: time.Hour, MaxAge: 3 * time.Hour}),
+	)
+	require.NoError(t, err)
+
+	first, err := s.Issue(t.Context(), &token.Claims{Subject: "user-1", SessionID: "sid-1", Audience: []string{"telegram-bot"}}, Device{})
+	require.NoError(t, err)
+
+	// ключи семейства в одном слоте с его токенами
+	assert.Equal(t, redisstorage.Slot(familyKey("sid-1")), redisstorage.Slot(tokenKey(first.Family, first.Raw)))
+	assert.Equal(t, redisstorage.Slot(familyKey("sid-1")), redisstorage.Slot(lineageKey("sid-1")))
+
+	second, err := s.Rotate(t.Context(), first.Raw)
+	require.NoError(t, err)
+
+	s.now = func() time.Time { return time.Now().Add(10 * time.Minute) }
+
+	active, err := s.TouchSession(t.Context(), first.Family)
+	require.NoError(t, err)
+	assert.True(t, active)
+
+	fam, err := s.Family(t.Context(), first.Family)
+	require.NoError(t, err)
+	assert.Len(t, fam.Tokens, 2)
+
+	sessions, err := s.Sessions(t.Context(), "user-1")
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+
+	// повторное использование отзывает семейство
+	_, err = s.Rotate(t.Context(), first.Raw)
+	require.ErrorIs(t, err, ErrReused)
+
+	_, err = s.Rotate(t.Context(), second.Refresh.Raw)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	revoked, err := s.SessionRevoked(t.Context(), "sid-1")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	_, err = s.Issue(t.Context(), &token.Claims{Subject: "user-1"}, Device{})
+	require.NoError(t, err)
+
+	n, err := s.RevokeAll(t.Context(), "user-1", ReasonLogout)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
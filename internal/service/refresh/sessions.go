@@ -0,0 +1,168 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// maxUserAgentLength - сколько символов User-Agent сохраняется в семействе.
+const maxUserAgentLength = 256
+
+// Device - устройство, с которого выполнен вход.
+type Device struct {
+	UserAgent string `json:"user_agent,omitempty"`
+	IP        string `json:"ip,omitempty"`
+}
+
+// truncate обрезает User-Agent: его передает клиент, и длина не должна зависеть от клиента.
+func (d Device) truncate() Device {
+	if runes := []rune(d.UserAgent); len(runes) > maxUserAgentLength {
+		d.UserAgent = string(runes[:maxUserAgentLength])
+	}
+
+	return d
+}
+
+// Session - действующая сессия входа пользователя: семейство refresh токенов без цепочки токенов.
+type Session struct {
+	ID        string    `json:"id"`
+	Device    Device    `json:"device"`
+	Audience  []string  `json:"audience,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// LastSeenAt - последняя активность скользящей сессии.
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+}
+
+// Sessions возвращает действующие сессии субъекта, начиная с последней. Отозванные и истекшие
+// сессии не возвращаются, а истекшие еще и удаляются из списка.
+func (s *Service) Sessions(ctx context.Context, subject string) ([]Session, error) {
+	key := sessionsKey(subject)
+	now := s.now().UTC().Truncate(time.Second)
+
+	var ids *redis.StringSliceCmd
+
+	_, err := s.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(now.Unix(), 10))
+		ids = p.ZRange(ctx, key, 0, -1)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("refresh: error get sessions: %w", err)
+	}
+
+	if len(ids.Val()) == 0 {
+		return []Session{}, nil
+	}
+
+	states := make([]*redis.MapStringStringCmd, len(ids.Val()))
+
+	// HGetAll не возвращает redis.Nil, поэтому ошибка конвейера - ошибка Redis
+	_, err = s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, family := range ids.Val() {
+			states[i] = p.HGetAll(ctx, familyKey(family))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("refresh: error get sessions: %w", err)
+	}
+
+	sessions := make([]Session, 0, len(states))
+
+	for i, state := range states {
+		if len(state.Val()) == 0 {
+			continue
+		}
+
+		fam := parseFamily(ids.Val()[i], state.Val())
+		if fam.RevokedAt != nil || !now.Before(fam.ExpiresAt) {
+			continue
+		}
+
+		sessions = append(sessions, Session{
+			ID:         fam.ID,
+			Device:     fam.Device,
+			Audience:   fam.Audience,
+			CreatedAt:  fam.CreatedAt,
+			ExpiresAt:  fam.ExpiresAt,
+			LastSeenAt: fam.LastSeenAt,
+		})
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+	})
+
+	return sessions, nil
+}
+
+// RevokeSession завершает сессию family субъекта по причине reason: ее refresh токены больше не обновляются.
+// Если сессия принадлежит другому субъекту, уже отозвана или истекла, возвращается ErrNotFound.
+func (s *Service) RevokeSession(ctx context.Context, subject, family, reason string) error {
+	fam, err := s.loadFamily(ctx, s.client, family)
+	if err != nil {
+		return err
+	}
+
+	if fam.Subject != subject || fam.RevokedAt != nil || !s.now().Before(fam.ExpiresAt) {
+		return ErrNotFound
+	}
+
+	return s.Revoke(ctx, family, reason)
+}
+
+// RevokeAll завершает все действующие сессии субъекта по причине reason и возвращает их число.
+// Сессия, отозванная или удаленная параллельно, не считается ошибкой.
+func (s *Service) RevokeAll(ctx context.Context, subject, reason string) (int, error) {
+	sessions, err := s.Sessions(ctx, subject)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+
+	for _, session := range sessions {
+		err := s.Revoke(ctx, session.ID, reason)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return revoked, err
+		}
+
+		revoked++
+	}
+
+	return revoked, nil
+}
+
+// indexSession добавляет семейство в список сессий субъекта до срока семейства limit.
+// Список живет до самого позднего срока своих семейств.
+func (s *Service) indexSession(ctx context.Context, subject, family string, now, limit time.Time) error {
+	key := sessionsKey(subject)
+	ttl := limit.Add(s.slack).Sub(now)
+
+	_, err := s.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.ZAdd(ctx, key, redis.Z{Score: float64(limit.Unix()), Member: family})
+		// NX выставляет TTL новому списку, GT продлевает его, если семейство живет дольше остальных
+		p.ExpireNX(ctx, key, ttl)
+		p.ExpireGT(ctx, key, ttl)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("refresh: error index session: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package refresh
+
+import (
+	"auth-service/internal/service/token"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Sessions(t *testing.T) {
+	t.Parallel()
+
+	s, issuer, mr := newService(t, WithTTL(time.Hour), WithFamilyTTL(24*time.Hour))
+	expectIssue(issuer)
+
+	// EXPIREAT в miniredis сравнивается с его временем
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	mr.SetTime(now)
+
+	phone := Device{UserAgent: "Telegram/10.0 (iPhone)", IP: "203.0.113.10"}
+
+	first, err := s.Issue(t.Context(), &token.Claims{Subject: "user-1", Audience: []string{"telegram-bot"}}, phone)
+	require.NoError(t, err)
+
+	now = now.Add(time.Hour)
+	mr.SetTime(now)
+
+	laptop := Device{UserAgent: strings.Repeat("x", maxUserAgentLength+10), IP: "198.51.100.7"}
+
+	second, err := s.Issue(t.Context(), &token.Claims{Subject: "user-1"}, laptop)
+	require.NoError(t, err)
+
+	_, err = s.Issue(t.Context(), &token.Claims{Subject: "user-2"}, Device{})
+	require.NoError(t, err)
+
+	// у списка сессий есть TTL: ключи refresh токенов должны истекать
+	assert.Equal(t, 24*time.Hour+s.slack, mr.TTL(sessionsKey("user-1")))
+
+	sessions, err := s.Sessions(t.Context(), "user-1")
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+
+	// последняя сессия первой, длинный User-Agent обрезан
+	assert.Equal(t, second.Family, sessions[0].ID)
+	assert.Equal(t, strings.Repeat("x", maxUserAgentLength), sessions[0].Device.UserAgent)
+	assert.Equal(t, Session{
+		ID:        first.Family,
+		Device:    phone,
+		Audience:  []string{"telegram-bot"},
+		CreatedAt: now.Add(-time.Hour),
+		ExpiresAt: now.Add(23 * time.Hour),
+	}, sessions[1])
+
+	// семейство тоже показывает устройство
+	family, err := s.Family(t.Context(), first.Family)
+	require.NoError(t, err)
+	assert.Equal(t, phone, family.Device)
+
+	// отозванная сессия пропадает из списка
+	require.NoError(t, s.Revoke(t.Context(), second.Family, ReasonAdmin))
+
+	sessions, err = s.Sessions(t.Context(), "user-1")
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, first.Family, sessions[0].ID)
+
+	// истекшие сессии удаляются из списка
+	now = now.Add(24 * time.Hour)
+
+	sessions, err = s.Sessions(t.Context(), "user-1")
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	members, err := mr.ZMembers(sessionsKey("user-1"))
+	require.Error(t, err, "empty sorted set must be removed, got %v", members)
+
+	sessions, err = s.Sessions(t.Context(), "unknown")
+	require.NoError(t, err)
+	assert.NotNil(t, sessions)
+	assert.Empty(t, sessions)
+
+	mr.Close()
+
+	_, err = s.Sessions(t.Context(), "user-1")
+	require.Error(t, err)
+}
+
+func TestService_RevokeSession(t *testing.T) {
+	t.Parallel()
+
+	s, issuer, _ := newService(t)
+	expectIssue(issuer)
+
+	tok, err := s.Issue(t.Context(), &token.Claims{Subject: "user-1"}, Device{})
+	require.NoError(t, err)
+
+	// чужую сессию завершить нельзя
+	require.ErrorIs(t, s.RevokeSession(t.Context(), "user-2", tok.Family, ReasonUser), ErrNotFound)
+	require.ErrorIs(t, s.RevokeSession(t.Context(), "user-1", "unknown", ReasonUser), ErrNotFound)
+
+	require.NoError(t, s.RevokeSession(t.Context(), "user-1", tok.Family, ReasonUser))
+
+	family, err := s.Family(t.Context(), tok.Family)
+	require.NoError(t, err)
+	assert.Equal(t, ReasonUser, family.RevokeReason)
+
+	// refresh токены завершенной сессии больше не обновляются
+	_, err = s.Rotate(t.Context(), tok.Raw)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	require.ErrorIs(t, s.RevokeSession(t.Context(), "user-1", tok.Family, ReasonUser), ErrNotFound)
+}
+
+func TestService_RevokeAll(t *testing.T) {
+	t.Parallel()
+
+	s, issuer, _ := newService(t)
+	expectIssue(issuer)
+
+	first, err := s.Issue(t.Context(), &token.Claims{Subject: "user-1"}, Device{})
+	require.NoError(t, err)
+
+	second, err := s.Issue(t.Context(), &token.Claims{Subject: "user-1"}, Device{})
+	require.NoError(t, err)
+
+	other, err := s.Issue(t.Context(), &token.Claims{Subject: "user-2"}, Device{})
+	require.NoError(t, err)
+
+	// уже завершенная сессия не считается
+	require.NoError(t, s.Revoke(t.Context(), second.Family, ReasonAdmin))
+
+	revoked, err := s.RevokeAll(t.Context(), "user-1", ReasonLogout)
+	require.NoError(t, err)
+	assert.Equal(t, 1, revoked)
+
+	family, err := s.Family(t.Context(), first.Family)
+	require.NoError(t, err)
+	assert.Equal(t, ReasonLogout, family.RevokeReason)
+
+	family, err = s.Family(t.Context(), second.Family)
+	require.NoError(t, err)
+	assert.Equal(t, ReasonAdmin, family.RevokeReason)
+
+	sessions, err := s.Sessions(t.Context(), "user-1")
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	// сессии других субъектов не затрагиваются
+	_, err = s.Rotate(t.Context(), other.Raw)
+	require.NoError(t, err)
+
+	revoked, err = s.RevokeAll(t.Context(), "user-1", ReasonLogout)
+	require.NoError(t, err)
+	assert.Zero(t, revoked)
+}
@@ -0,0 +1,165 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// maxTouchInterval - как часто активность скользящей сессии записывается в Redis: отметки чаще
+// пропускаются, чтобы каждый запрос не был записью. Для коротких Idle - десятая часть Idle.
+const maxTouchInterval = time.Minute
+
+// Sliding - скользящее истечение сессии: сессия истекает, если не была активна Idle
+// (токен доступа не проверялся и refresh токен не обновлялся), но не позже MaxAge после входа.
+type Sliding struct {
+	Idle time.Duration
+	// MaxAge - абсолютное время жизни сессии. По умолчанию время жизни семейства (WithFamilyTTL).
+	MaxAge time.Duration
+}
+
+// WithSliding включает скользящее истечение сессий, начатых токенами аудитории audience.
+// Если у токена несколько аудиторий со скользящим истечением, действует первая из них.
+// Правило применяется при входе и сохраняется в семействе: смена настроек не меняет начатые сессии.
+func WithSliding(audience string, sliding Sliding) Option {
+	return func(s *Service) {
+		if s.sliding == nil {
+			s.sliding = make(map[string]Sliding)
+		}
+
+		s.sliding[audience] = sliding
+	}
+}
+
+// validateSliding проверяет правила скользящего истечения и подставляет MaxAge по умолчанию.
+func (s *Service) validateSliding() error {
+	for audience, sliding := range s.sliding {
+		if audience == "" {
+			return errors.New("sliding audience is required")
+		}
+
+		if sliding.MaxAge == 0 {
+			sliding.MaxAge = s.familyTTL
+		}
+
+		if sliding.Idle <= 0 || sliding.MaxAge < sliding.Idle {
+			return fmt.Errorf("sliding %s: idle must be positive and not exceed max age", audience)
+		}
+
+		s.sliding[audience] = sliding
+	}
+
+	return nil
+}
+
+// slidingFor возвращает правило скользящего истечения для аудиторий токена.
+func (s *Service) slidingFor(audience []string) (Sliding, bool) {
+	for _, aud := range audience {
+		if sliding, ok := s.sliding[aud]; ok {
+			return sliding, true
+		}
+	}
+
+	return Sliding{}, false
+}
+
+// touchScript отмечает активность сессии и продлевает ее, если сессия скользящая.
+// KEYS[1] - ключ семейства; ARGV[1] - текущее время (unix), ARGV[2] - максимальный интервал между записями (секунды).
+// Возвращает {активна ли сессия (0/1), новый срок действия или 0, если срок не менялся}.
+// Неизвестная сессия считается активной: токен мог быть выпущен без refresh токена.
+var touchScript = redis.NewScript(`
+local f = redis.call("HMGET", KEYS[1], "revoked_at", "expires_at", "idle", "max_expires_at", "last_seen")
+if not f[2] then
+	return {1, 0}
+end
+local now = tonumber(ARGV[1])
+if f[1] or now >= tonumber(f[2]) then
+	return {0, 0}
+end
+if not f[3] then
+	return {1, 0}
+end
+local idle = tonumber(f[3])
+if now - tonumber(f[5] or 0) < math.min(tonumber(ARGV[2]), math.floor(idle / 10)) then
+	return {1, 0}
+end
+local expires = math.min(now + idle, tonumber(f[4]))
+redis.call("HSET", KEYS[1], "expires_at", expires, "last_seen", now)
+return {1, expires}
+`)
+
+// TouchSession отмечает активность сессии sid - семейства refresh токенов. Скользящая сессия
+// продлевается на Idle, но не дальше MaxAge. Возвращает false, если сессия отозвана или истекла
+// (в том числе по неактивности); неизвестная сессия считается активной.
+func (s *Service) TouchSession(ctx context.Context, sid string) (bool, error) {
+	now := s.now().UTC().Truncate(time.Second)
+
+	res, err := touchScript.Run(ctx, s.client, []string{familyKey(sid)},
+		now.Unix(), int64(maxTouchInterval/time.Second)).Int64Slice()
+	if err != nil {
+		return false, fmt.Errorf("refresh: error touch session: %w", err)
+	}
+
+	if len(res) != 2 {
+		return false, fmt.Errorf("refresh: unexpected touch result %v", res)
+	}
+
+	if expires := res[1]; expires != 0 {
+		// TTL ключей продлевается после скрипта: скрипт проверяет и меняет только семейство
+		at := time.Unix(expires, 0).Add(s.slack)
+
+		if _, err := s.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			p.ExpireAt(ctx, familyKey(sid), at)
+			p.ExpireAt(ctx, lineageKey(sid), at)
+
+			return nil
+		}); err != nil {
+			return false, fmt.Errorf("refresh: error extend session: %w", err)
+		}
+	}
+
+	return res[0] == 1, nil
+}
+
+// slide возвращает срок действия скользящего семейства после активности в now.
+func (f *Family) slide(now time.Time) time.Time {
+	expiresAt := now.Add(f.idle)
+
+	if f.MaxExpiresAt != nil && expiresAt.After(*f.MaxExpiresAt) {
+		return *f.MaxExpiresAt
+	}
+
+	return expiresAt
+}
+
+// limit возвращает, дольше какого времени токены семейства не живут: абсолютный срок
+// скользящего семейства (его ExpiresAt продлевается) или срок действия обычного.
+func (f *Family) limit() time.Time {
+	if f.MaxExpiresAt != nil {
+		return *f.MaxExpiresAt
+	}
+
+	return f.ExpiresAt
+}
+
+// loadSliding читает поля скользящего семейства.
+func (f *Family) loadSliding(state map[string]string) {
+	if state["idle"] == "" {
+		return
+	}
+
+	idle, _ := strconv.ParseInt(state["idle"], 10, 64)
+	f.idle = time.Duration(idle) * time.Second
+
+	maxExpiresAt := unixTime(state["max_expires_at"])
+	f.MaxExpiresAt = &maxExpiresAt
+
+	if state["last_seen"] != "" {
+		lastSeen := unixTime(state["last_seen"])
+		f.LastSeenAt = &lastSeen
+	}
+}
@@ -0,0 +1,180 @@
+package refresh
+
+import (
+	"auth-service/internal/service/token"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_Sliding(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		sliding Sliding
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case: max age defaults to family ttl",
+			sliding: Sliding{Idle: time.Hour},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: no idle",
+			sliding: Sliding{MaxAge: time.Hour},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: idle exceeds max age",
+			sliding: Sliding{Idle: 2 * time.Hour, MaxAge: time.Hour},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, _, _ := newService(t)
+
+			_, err := New(WithClient(s.client), WithIssuer(s.issuer), WithSliding("telegram-bot", tt.sliding),
+				WithRegisterer(prometheus.NewRegistry()))
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+func TestService_TouchSession(t *testing.T) {
+	t.Parallel()
+
+	s, issuer, mr := newService(t,
+		WithTTL(24*time.Hour),
+		WithFamilyTTL(48*time.Hour),
+		WithSliding("telegram-bot", Sliding{Idle: time.Hour, MaxAge: 3 * time.Hour}),
+		WithTTLSlack(time.Minute),
+	)
+	expectIssue(issuer)
+
+	start := time.Now().UTC().Truncate(time.Second)
+	now := start
+	s.now = func() time.Time { return now }
+
+	tok, err := s.Issue(t.Context(), &token.Claims{Subject: "user-1", Audience: []string{"web", "telegram-bot"}}, Device{})
+	require.NoError(t, err)
+	// refresh токен скользящей сессии живет до ее абсолютного срока
+	assert.Equal(t, start.Add(3*time.Hour), tok.ExpiresAt)
+
+	fam, err := s.Family(t.Context(), tok.Family)
+	require.NoError(t, err)
+	assert.Equal(t, start.Add(time.Hour), fam.ExpiresAt)
+	require.NotNil(t, fam.MaxExpiresAt)
+	assert.Equal(t, start.Add(3*time.Hour), *fam.MaxExpiresAt)
+
+	// активность чаще интервала записи не продлевает сессию
+	now = start.Add(30 * time.Second)
+
+	active, err := s.TouchSession(t.Context(), tok.Family)
+	require.NoError(t, err)
+	assert.True(t, active)
+
+	fam, err = s.Family(t.Context(), tok.Family)
+	require.NoError(t, err)
+	assert.Equal(t, start.Add(time.Hour), fam.ExpiresAt)
+
+	// активность продлевает сессию и ключи семейства
+	now = start.Add(50 * time.Minute)
+
+	active, err = s.TouchSession(t.Context(), tok.Family)
+	require.NoError(t, err)
+	assert.True(t, active)
+
+	fam, err = s.Family(t.Context(), tok.Family)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), fam.ExpiresAt)
+	require.NotNil(t, fam.LastSeenAt)
+	assert.Equal(t, now, *fam.LastSeenAt)
+	assert.InDelta(t, time.Until(now.Add(time.Hour+time.Minute)), mr.TTL(lineageKey(tok.Family)), float64(time.Second))
+
+	// обновление refresh токена - тоже активность
+	now = start.Add(100 * time.Minute)
+
+	rotated, err := s.Rotate(t.Context(), tok.Raw)
+	require.NoError(t, err)
+
+	fam, err = s.Family(t.Context(), tok.Family)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), fam.ExpiresAt)
+
+	// продление не выходит за абсолютный срок
+	now = start.Add(150 * time.Minute)
+
+	active, err = s.TouchSession(t.Context(), tok.Family)
+	require.NoError(t, err)
+	assert.True(t, active)
+
+	fam, err = s.Family(t.Context(), tok.Family)
+	require.NoError(t, err)
+	assert.Equal(t, start.Add(3*time.Hour), fam.ExpiresAt)
+
+	// после абсолютного срока сессия истекла
+	now = start.Add(3 * time.Hour)
+
+	active, err = s.TouchSession(t.Context(), tok.Family)
+	require.NoError(t, err)
+	assert.False(t, active)
+
+	_, err = s.Rotate(t.Context(), rotated.Refresh.Raw)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// неизвестная сессия считается активной
+	active, err = s.TouchSession(t.Context(), "unknown")
+	require.NoError(t, err)
+	assert.True(t, active)
+}
+
+func TestService_TouchSession_Idle(t *testing.T) {
+	t.Parallel()
+
+	s, issuer, _ := newService(t, WithSliding("telegram-bot", Sliding{Idle: time.Hour}))
+	expectIssue(issuer)
+
+	start := time.Now().UTC().Truncate(time.Second)
+	now := start
+	s.now = func() time.Time { return now }
+
+	sliding, err := s.Issue(t.Context(), &token.Claims{Subject: "user-1", Audience: []string{"telegram-bot"}}, Device{})
+	require.NoError(t, err)
+
+	// сессии других аудиторий не скользящие
+	regular, err := s.Issue(t.Context(), &token.Claims{Subject: "user-1", Audience: []string{"web"}}, Device{})
+	require.NoError(t, err)
+
+	fam, err := s.Family(t.Context(), regular.Family)
+	require.NoError(t, err)
+	assert.Nil(t, fam.MaxExpiresAt)
+
+	// без активности скользящая сессия истекает
+	now = start.Add(time.Hour)
+
+	active, err := s.TouchSession(t.Context(), sliding.Family)
+	require.NoError(t, err)
+	assert.False(t, active)
+
+	_, err = s.Rotate(t.Context(), sliding.Raw)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	active, err = s.TouchSession(t.Context(), regular.Family)
+	require.NoError(t, err)
+	assert.True(t, active)
+
+	// отозванная сессия не активна
+	require.NoError(t, s.Revoke(t.Context(), regular.Family, ReasonAdmin))
+
+	active, err = s.TouchSession(t.Context(), regular.Family)
+	require.NoError(t, err)
+	assert.False(t, active)
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: replication.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	event "auth-service/internal/service/event"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// Mockapplier is a mock of applier interface.
+type Mockapplier struct {
+	ctrl     *gomock.Controller
+	recorder *MockapplierMockRecorder
+}
+
+// MockapplierMockRecorder is the mock recorder for Mockapplier.
+type MockapplierMockRecorder struct {
+	mock *Mockapplier
+}
+
+// NewMockapplier creates a new mock instance.
+func NewMockapplier(ctrl *gomock.Controller) *Mockapplier {
+	mock := &Mockapplier{ctrl: ctrl}
+	mock.recorder = &MockapplierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockapplier) EXPECT() *MockapplierMockRecorder {
+	return m.recorder
+}
+
+// Apply mocks base method.
+func (m *Mockapplier) Apply(ctx context.Context, e event.Event) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Apply", ctx, e)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Apply indicates an expected call of Apply.
+func (mr *MockapplierMockRecorder) Apply(ctx, e interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*Mockapplier)(nil).Apply), ctx, e)
+}
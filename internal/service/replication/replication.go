@@ -0,0 +1,312 @@
+// Package replication реплицирует отзыв токенов между регионами. Каждый регион публикует события
+// об отзыве, отключении и включении пользователей в stream своего Redis, а Replicator читает stream
+// Redis других регионов своей группой и применяет события к локальному отзыву. Применение не зависит
+// от порядка доставки (побеждает отзыв), поэтому регионы сходятся к одному состоянию, а токен,
+// отозванный в одном регионе, отклоняется во всех через несколько секунд.
+package replication
+
+import (
+	"auth-service/internal/service/event"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// groupPrefix - префикс группы, которой регион читает stream другого региона.
+	groupPrefix = "replication:"
+
+	// readBlock - сколько репликация ждет новые события в одном чтении.
+	readBlock = time.Second
+	// readCount - сколько событий читается за раз.
+	readCount = 100
+	// retryInterval - пауза перед повторным чтением после ошибки.
+	retryInterval = time.Second
+)
+
+//go:generate mockgen -source=replication.go -destination=mocks/replication_mock.go -package=mocks
+type applier interface {
+	// Apply применяет событие из другого региона. Возвращает false, если событие ничего не изменило.
+	Apply(ctx context.Context, e event.Event) (bool, error)
+}
+
+// Replicator - репликация отзыва токенов из других регионов.
+type Replicator struct {
+	region   string
+	stream   string
+	consumer string
+	peers    map[string]redis.UniversalClient
+	applier  applier
+
+	registerer prometheus.Registerer
+	applied    *prometheus.CounterVec
+	lag        *prometheus.GaugeVec
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Replicator.
+type Option func(*Replicator)
+
+// WithRegion устанавливает регион экземпляра. Из stream другого региона применяются только события,
+// которые произошли в нем самом.
+func WithRegion(region string) Option {
+	return func(r *Replicator) {
+		r.region = region
+	}
+}
+
+// WithStream устанавливает stream событий регионов. По умолчанию event.DefaultStream.
+func WithStream(stream string) Option {
+	return func(r *Replicator) {
+		r.stream = stream
+	}
+}
+
+// WithConsumer устанавливает имя обработчика в группе. По умолчанию имя хоста.
+func WithConsumer(name string) Option {
+	return func(r *Replicator) {
+		r.consumer = name
+	}
+}
+
+// WithPeers устанавливает клиенты Redis других регионов по их именам.
+func WithPeers(peers map[string]redis.UniversalClient) Option {
+	return func(r *Replicator) {
+		r.peers = peers
+	}
+}
+
+// WithApplier устанавливает локальный отзыв токенов, к которому применяются события.
+func WithApplier(applier applier) Option {
+	return func(r *Replicator) {
+		r.applier = applier
+	}
+}
+
+// WithRegisterer устанавливает реестр метрик. По умолчанию используется prometheus.DefaultRegisterer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(r *Replicator) {
+		r.registerer = registerer
+	}
+}
+
+// New создает новый Replicator и регистрирует его метрики.
+func New(opts ...Option) (*Replicator, error) {
+	r := &Replicator{
+		stream:     event.DefaultStream,
+		registerer: prometheus.DefaultRegisterer,
+		now:        time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+
+	if r.consumer == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("replication: error get hostname: %w", err)
+		}
+
+		r.consumer = hostname
+	}
+
+	r.applied = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_replication_applied_total",
+		Help: "Количество событий других регионов, изменивших локальный отзыв токенов, по регионам и типам.",
+	}, []string{"peer", "type"})
+
+	r.lag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "auth_replication_lag_seconds",
+		Help: "Задержка последнего полученного события другого региона: от события до его обработки.",
+	}, []string{"peer"})
+
+	for _, c := range []prometheus.Collector{r.applied, r.lag} {
+		if err := r.registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+func (r *Replicator) validate() error {
+	switch {
+	case r.region == "":
+		return errors.New("region is required")
+	case r.stream == "":
+		return errors.New("stream is required")
+	case len(r.peers) == 0:
+		return errors.New("peers are required")
+	case r.applier == nil:
+		return errors.New("applier is required")
+	case r.registerer == nil:
+		return errors.New("registerer is required")
+	}
+
+	if _, ok := r.peers[r.region]; ok {
+		return fmt.Errorf("region %q is listed among its peers", r.region)
+	}
+
+	return nil
+}
+
+// Start читает stream всех регионов. Блокирует до отмены контекста.
+func (r *Replicator) Start(ctx context.Context) error {
+	var wg sync.WaitGroup
+
+	for peer, client := range r.peers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			r.replicate(ctx, peer, client)
+		}()
+	}
+
+	wg.Wait()
+
+	return nil
+}
+
+// replicate читает stream региона peer, пока не отменен контекст. После ошибки сначала
+// повторно обрабатываются полученные, но не подтвержденные события.
+func (r *Replicator) replicate(ctx context.Context, peer string, client redis.UniversalClient) {
+	log := logrus.WithFields(logrus.Fields{"peer": peer, "consumer": r.consumer})
+	log.Info("starting revocation replication")
+
+	created := false
+	pending := true
+
+	for ctx.Err() == nil {
+		var (
+			n   int
+			err error
+		)
+
+		if !created {
+			err = r.createGroup(ctx, client)
+			created = err == nil
+		}
+
+		if err == nil {
+			n, err = r.process(ctx, peer, client, pending)
+		}
+
+		if err != nil && ctx.Err() == nil {
+			log.WithError(err).Error("error replicate revocations")
+
+			pending = true
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(retryInterval):
+			}
+
+			continue
+		}
+
+		if pending && n == 0 {
+			pending = false
+		}
+	}
+}
+
+func (r *Replicator) createGroup(ctx context.Context, client redis.UniversalClient) error {
+	err := client.XGroupCreateMkStream(ctx, r.stream, groupPrefix+r.region, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("error create consumer group: %w", err)
+	}
+
+	return nil
+}
+
+// process читает и применяет одну пачку событий региона peer: неподтвержденные ранее, если pending,
+// иначе новые. Возвращает количество прочитанных событий.
+func (r *Replicator) process(ctx context.Context, peer string, client redis.UniversalClient, pending bool) (int, error) {
+	id := ">"
+	if pending {
+		id = "0"
+	}
+
+	streams, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    groupPrefix + r.region,
+		Consumer: r.consumer,
+		Streams:  []string{r.stream, id},
+		Count:    readCount,
+		Block:    readBlock,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("error read events: %w", err)
+	}
+
+	n := 0
+
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			if err := r.handle(ctx, peer, msg); err != nil {
+				return n, err
+			}
+
+			if err := client.XAck(ctx, r.stream, groupPrefix+r.region, msg.ID).Err(); err != nil {
+				return n, fmt.Errorf("error ack event: %w", err)
+			}
+
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// handle применяет событие региона peer. События, которые произошли в других регионах
+// (их применяет репликация из этих регионов), и некорректные события пропускаются.
+func (r *Replicator) handle(ctx context.Context, peer string, msg redis.XMessage) error {
+	e, err := event.Parse(msg)
+	if err != nil {
+		logrus.WithError(err).WithField("peer", peer).Warn("skip invalid event")
+
+		return nil
+	}
+
+	if e.Region != peer {
+		return nil
+	}
+
+	r.lag.WithLabelValues(peer).Set(r.now().Sub(e.At).Seconds())
+
+	applied, err := r.applier.Apply(ctx, e)
+	if err != nil {
+		return fmt.Errorf("error apply event %s: %w", msg.ID, err)
+	}
+
+	if applied {
+		r.applied.WithLabelValues(peer, e.Type).Inc()
+
+		logrus.WithFields(logrus.Fields{
+			"peer":    peer,
+			"type":    e.Type,
+			"subject": e.Subject,
+		}).Info("applied replicated revocation")
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package replication
+
+import (
+	"auth-service/internal/service/event"
+	"auth-service/internal/service/replication/mocks"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T) redis.UniversalClient {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	peers := map[string]redis.UniversalClient{"us": newClient(t)}
+	applier := mocks.NewMockapplier(gomock.NewController(t))
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{
+			name: "positive case",
+			opts: []Option{WithRegion("eu"), WithPeers(peers), WithApplier(applier)},
+		},
+		{
+			name:    "error case: no region",
+			opts:    []Option{WithPeers(peers), WithApplier(applier)},
+			wantErr: true,
+		},
+		{
+			name:    "error case: no peers",
+			opts:    []Option{WithRegion("eu"), WithApplier(applier)},
+			wantErr: true,
+		},
+		{
+			name:    "error case: region is its own peer",
+			opts:    []Option{WithRegion("us"), WithPeers(peers), WithApplier(applier)},
+			wantErr: true,
+		},
+		{
+			name:    "error case: no applier",
+			opts:    []Option{WithRegion("eu"), WithPeers(peers)},
+			wantErr: true,
+		},
+		{
+			name:    "error case: empty stream",
+			opts:    []Option{WithRegion("eu"), WithPeers(peers), WithApplier(applier), WithStream("")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(append([]Option{WithRegisterer(prometheus.NewRegistry()), WithConsumer("test")}, tt.opts...)...)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestReplicator_Start(t *testing.T) {
+	t.Parallel()
+
+	peer := newClient(t)
+
+	publisher, err := event.New(event.WithClient(peer), event.WithStream("events"), event.WithRegion("us"))
+	require.NoError(t, err)
+
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	revoked := event.Event{Type: event.TypeTokensRevoked, Subject: "user-1", Source: "revocation", At: at, Region: "us"}
+	deactivated := event.Event{Type: event.TypeUserDeactivated, Subject: "user-2", Source: "admin", At: at, Region: "us"}
+
+	// события, опубликованные до запуска репликации, тоже применяются
+	for _, e := range []event.Event{
+		revoked,
+		// событие реплицировано в регион us из региона ap - его применяет репликация из ap
+		{Type: event.TypeUserDeactivated, Subject: "user-3", At: at, Region: "ap"},
+	} {
+		_, err := publisher.Publish(t.Context(), e)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, peer.XAdd(t.Context(), &redis.XAddArgs{
+		Stream: "events",
+		Values: map[string]interface{}{"type": event.TypeTokensRevoked, "subject": "user-1", "at": "yesterday"},
+	}).Err())
+
+	applier := mocks.NewMockapplier(gomock.NewController(t))
+	registry := prometheus.NewRegistry()
+
+	r, err := New(
+		WithRegion("eu"),
+		WithStream("events"),
+		WithConsumer("test"),
+		WithPeers(map[string]redis.UniversalClient{"us": peer}),
+		WithApplier(applier),
+		WithRegisterer(registry),
+	)
+	require.NoError(t, err)
+
+	r.now = func() time.Time { return at.Add(2 * time.Second) }
+
+	applied := make(chan event.Event, 2)
+
+	// событие, которое не удалось применить, применяется повторно
+	gomock.InOrder(
+		applier.EXPECT().Apply(gomock.Any(), revoked).Return(false, errors.New("redis is down")),
+		applier.EXPECT().Apply(gomock.Any(), revoked).DoAndReturn(func(_ context.Context, e event.Event) (bool, error) {
+			applied <- e
+
+			return true, nil
+		}),
+		applier.EXPECT().Apply(gomock.Any(), deactivated).DoAndReturn(func(_ context.Context, e event.Event) (bool, error) {
+			applied <- e
+
+			return false, nil
+		}),
+	)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+
+	go func() { done <- r.Start(ctx) }()
+
+	assert.Equal(t, revoked, <-applied)
+
+	_, err = publisher.Publish(t.Context(), deactivated)
+	require.NoError(t, err)
+
+	assert.Equal(t, deactivated, <-applied)
+
+	require.Eventually(t, func() bool {
+		pending, err := peer.XPending(t.Context(), "events", "replication:eu").Result()
+		return err == nil && pending.Count == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.InDelta(t, 1, testutil.ToFloat64(r.applied.WithLabelValues("us", event.TypeTokensRevoked)), 0)
+	assert.InDelta(t, 0, testutil.ToFloat64(r.applied.WithLabelValues("us", event.TypeUserDeactivated)), 0)
+	assert.InDelta(t, 2, testutil.ToFloat64(r.lag.WithLabelValues("us")), 0)
+}
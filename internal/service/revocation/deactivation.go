@@ -0,0 +1,321 @@
+package revocation
+
+import (
+	"auth-service/internal/service/event"
+	"auth-service/internal/service/job"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// CheckJobType - тип задания проверки согласованности отключенных пользователей.
+	CheckJobType = "check-deactivations"
+
+	// sourceCheck - источник отключений, найденных проверкой согласованности.
+	sourceCheck = "consistency-check"
+)
+
+//go:generate mockgen -source=deactivation.go -destination=mocks/deactivation_mock.go -package=mocks
+type eventPublisher interface {
+	Publish(ctx context.Context, e event.Event) (string, error)
+}
+
+// accountSource - хранилище учетных записей, которое само отключает пользователей (например, SCIM).
+type accountSource interface {
+	// InactiveSubjects возвращает субъекты токенов отключенных пользователей.
+	InactiveSubjects(ctx context.Context) ([]string, error)
+}
+
+// Deactivation - отключение пользователя.
+type Deactivation struct {
+	Subject       string    `json:"subject"`
+	Source        string    `json:"source"`
+	DeactivatedAt time.Time `json:"deactivated_at"`
+	// EventID - ID события в stream, пусто - событие еще не опубликовано.
+	EventID string `json:"event_id,omitempty"`
+	// Region - регион, в котором пользователь отключен, если отключение получено репликацией.
+	Region string `json:"region,omitempty"`
+}
+
+// CheckResult - результат проверки согласованности.
+type CheckResult struct {
+	// Checked - сколько отключенных пользователей проверено.
+	Checked int `json:"checked"`
+	// Deactivated - пользователи, отключенные в источнике, токены которых еще принимались.
+	Deactivated []string `json:"deactivated"`
+	// Republished - отключения, событие о которых не было опубликовано.
+	Republished []string `json:"republished"`
+}
+
+// WithEvents устанавливает публикацию событий об отключении пользователей.
+func WithEvents(events eventPublisher) Option {
+	return func(s *Service) {
+		s.events = events
+	}
+}
+
+func deactivationKey(subject string) string {
+	return keyPrefix + "deactivated:" + subject
+}
+
+// reactivationKey - unix time последнего включения пользователя. По нему репликация определяет,
+// какое из отключения и включения в разных регионах произошло позже.
+func reactivationKey(subject string) string {
+	return keyPrefix + "reactivated:" + subject
+}
+
+func deactivationsKey() string {
+	return keyPrefix + "deactivations"
+}
+
+// RegisterSource добавляет хранилище учетных записей, отключенных пользователей которого
+// проверяет задание CheckJobType. name записывается источником найденных отключений.
+func (s *Service) RegisterSource(name string, source accountSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sources[name] = source
+}
+
+// Deactivate отключает пользователя: сразу отзывает все его токены, в том числе выпущенные
+// после отключения, и публикует событие TypeUserDeactivated. Повторное отключение возвращает
+// существующее. Ошибка публикации не отменяет отключение: событие допубликует проверка согласованности.
+func (s *Service) Deactivate(ctx context.Context, subject, source string) (*Deactivation, error) {
+	if subject == "" {
+		return nil, fmt.Errorf("%w: subject is required", ErrInvalidArgument)
+	}
+
+	d, err := s.Deactivation(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	if d == nil {
+		d = &Deactivation{Subject: subject, Source: source, DeactivatedAt: s.now().UTC().Truncate(time.Second)}
+
+		if err := s.deactivate(ctx, d); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.EventID == "" {
+		s.publish(ctx, d)
+	}
+
+	return d, nil
+}
+
+// deactivate сохраняет отключение и отзывает токены пользователя.
+func (s *Service) deactivate(ctx context.Context, d *Deactivation) error {
+	fields := []any{"at", d.DeactivatedAt.Unix(), "source", d.Source}
+	if d.Region != "" {
+		fields = append(fields, "region", d.Region)
+	}
+
+	// запись отключения пишется первой: с ней токены отклоняются, даже если отметка об отзыве не записалась
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, deactivationKey(d.Subject), fields...)
+		p.SAdd(ctx, deactivationsKey(), d.Subject)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("revocation: error save deactivation: %w", err)
+	}
+
+	if err := s.revoke(ctx, d.Subject, strconv.FormatInt(d.DeactivatedAt.Unix(), 10)); err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"subject": d.Subject,
+		"source":  d.Source,
+		"region":  d.Region,
+	}).Info("user deactivated")
+
+	return nil
+}
+
+// Reactivate снова включает пользователя. Токены, выпущенные до включения, остаются отозванными.
+func (s *Service) Reactivate(ctx context.Context, subject, source string) error {
+	d, err := s.Deactivation(ctx, subject)
+	if err != nil || d == nil {
+		return err
+	}
+
+	return s.reactivate(ctx, event.Event{
+		Type:    event.TypeUserReactivated,
+		Subject: subject,
+		Source:  source,
+		At:      s.now().UTC().Truncate(time.Second),
+	})
+}
+
+// reactivate удаляет отключение пользователя и публикует событие e о включении.
+func (s *Service) reactivate(ctx context.Context, e event.Event) error {
+	// токены, выпущенные во время отключения, не должны начать приниматься
+	if err := s.revoke(ctx, e.Subject, strconv.FormatInt(e.At.Unix(), 10)); err != nil {
+		return err
+	}
+
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Del(ctx, deactivationKey(e.Subject))
+		p.SRem(ctx, deactivationsKey(), e.Subject)
+		p.Set(ctx, reactivationKey(e.Subject), e.At.Unix(), s.retention)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("revocation: error delete deactivation: %w", err)
+	}
+
+	log := logrus.WithFields(logrus.Fields{"subject": e.Subject, "source": e.Source, "region": e.Region})
+	log.Info("user reactivated")
+
+	if s.events == nil {
+		return nil
+	}
+
+	if _, err := s.events.Publish(ctx, e); err != nil {
+		log.WithError(err).Error("error publish reactivation")
+	}
+
+	return nil
+}
+
+// Deactivation возвращает отключение пользователя или nil, если пользователь не отключен.
+func (s *Service) Deactivation(ctx context.Context, subject string) (*Deactivation, error) {
+	data, err := s.client.HGetAll(ctx, deactivationKey(subject)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("revocation: error get deactivation: %w", err)
+	}
+
+	if len(data) == 0 {
+		return nil, nil //nolint:nilnil // пользователь не отключен - не ошибка
+	}
+
+	at, _ := strconv.ParseInt(data["at"], 10, 64)
+
+	return &Deactivation{
+		Subject:       subject,
+		Source:        data["source"],
+		DeactivatedAt: time.Unix(at, 0).UTC(),
+		EventID:       data["event"],
+		Region:        data["region"],
+	}, nil
+}
+
+// publish публикует событие об отключении и запоминает его ID.
+func (s *Service) publish(ctx context.Context, d *Deactivation) {
+	if s.events == nil {
+		return
+	}
+
+	log := logrus.WithField("subject", d.Subject)
+
+	eventID, err := s.events.Publish(ctx, event.Event{
+		Type:    event.TypeUserDeactivated,
+		Subject: d.Subject,
+		Source:  d.Source,
+		At:      d.DeactivatedAt,
+		Region:  d.Region,
+	})
+	if err != nil {
+		log.WithError(err).Error("error publish deactivation")
+
+		return
+	}
+
+	if err := s.client.HSet(ctx, deactivationKey(d.Subject), "event", eventID).Err(); err != nil {
+		log.WithError(err).Error("error save deactivation event")
+
+		return
+	}
+
+	d.EventID = eventID
+}
+
+// EnqueueCheck ставит в очередь проверку согласованности отключенных пользователей.
+func (s *Service) EnqueueCheck(ctx context.Context) (*job.Job, error) {
+	return s.jobs.Enqueue(ctx, CheckJobType, nil)
+}
+
+// check ищет пользователей, отключенных в источниках учетных записей, токены которых еще принимаются,
+// и отключает их, а также допубликовывает события о прошлых отключениях.
+func (s *Service) check(ctx context.Context, _ map[string]string, progress job.Progress) (any, error) {
+	result := CheckResult{Deactivated: []string{}, Republished: []string{}}
+
+	s.mu.RLock()
+	sources := maps.Clone(s.sources)
+	s.mu.RUnlock()
+
+	for name, source := range sources {
+		subjects, err := source.InactiveSubjects(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("revocation: error list inactive subjects of %s: %w", name, err)
+		}
+
+		for _, subject := range subjects {
+			d, err := s.Deactivation(ctx, subject)
+			if err != nil {
+				return nil, err
+			}
+
+			if d != nil {
+				continue
+			}
+
+			if _, err := s.Deactivate(ctx, subject, name+"/"+sourceCheck); err != nil {
+				return nil, err
+			}
+
+			logrus.WithFields(logrus.Fields{"subject": subject, "source": name}).Warn("found live sessions of deactivated user")
+
+			result.Deactivated = append(result.Deactivated, subject)
+		}
+	}
+
+	progress(50)
+
+	subjects, err := s.client.SMembers(ctx, deactivationsKey()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("revocation: error list deactivations: %w", err)
+	}
+
+	slices.Sort(subjects)
+
+	for _, subject := range subjects {
+		d, err := s.Deactivation(ctx, subject)
+		if err != nil {
+			return nil, err
+		}
+
+		// запись удалена при включении, а удаление из множества не выполнилось
+		if d == nil {
+			if err := s.client.SRem(ctx, deactivationsKey(), subject).Err(); err != nil {
+				return nil, fmt.Errorf("revocation: error delete deactivation: %w", err)
+			}
+
+			continue
+		}
+
+		result.Checked++
+
+		if d.EventID == "" && s.events != nil {
+			s.publish(ctx, d)
+
+			if d.EventID != "" {
+				result.Republished = append(result.Republished, subject)
+			}
+		}
+	}
+
+	return result, nil
+}
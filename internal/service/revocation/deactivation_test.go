@@ -0,0 +1,213 @@
+package revocation
+
+import (
+	"auth-service/internal/service/event"
+	"auth-service/internal/service/revocation/mocks"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource - источник учетных записей с заданными отключенными субъектами.
+type fakeSource struct {
+	subjects []string
+	err      error
+}
+
+func (f fakeSource) InactiveSubjects(context.Context) ([]string, error) {
+	return f.subjects, f.err
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService_Deactivate(t *testing.T) {
+	t.Parallel()
+
+	s, _, mr := newService(t)
+
+	events := mocks.NewMockeventPublisher(gomock.NewController(t))
+	s.events = events
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	_, err := s.Deactivate(t.Context(), "", "admin")
+	require.ErrorIs(t, err, ErrInvalidArgument)
+
+	events.EXPECT().Publish(gomock.Any(), event.Event{
+		Type:    event.TypeUserDeactivated,
+		Subject: "user-1",
+		Source:  "admin",
+		At:      now,
+	}).Return("1-0", nil)
+
+	d, err := s.Deactivate(t.Context(), "user-1", "admin")
+	require.NoError(t, err)
+	assert.Equal(t, &Deactivation{Subject: "user-1", Source: "admin", DeactivatedAt: now, EventID: "1-0"}, d)
+
+	// токены, выпущенные после отключения, тоже отклоняются
+	s.now = func() time.Time { return now.Add(time.Hour) }
+
+	before, err := s.RevokedBefore(t.Context(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), before)
+
+	// повторное отключение не публикует событие снова
+	again, err := s.Deactivate(t.Context(), "user-1", "scim")
+	require.NoError(t, err)
+	assert.Equal(t, d, again)
+
+	events.EXPECT().Publish(gomock.Any(), event.Event{
+		Type:    event.TypeUserReactivated,
+		Subject: "user-1",
+		Source:  "admin",
+		At:      now.Add(time.Hour),
+	}).Return("2-0", nil)
+
+	require.NoError(t, s.Reactivate(t.Context(), "user-1", "admin"))
+
+	got, err := s.Deactivation(t.Context(), "user-1")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	// токены, выпущенные во время отключения, остаются отозванными
+	s.now = func() time.Time { return now.Add(2 * time.Hour) }
+
+	before, err = s.RevokedBefore(t.Context(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), before)
+	assert.Equal(t, DefaultRetention, mr.TTL(subjectKey("user-1")))
+
+	// включение не отключенного пользователя ничего не делает
+	require.NoError(t, s.Reactivate(t.Context(), "user-2", "admin"))
+	assert.False(t, mr.Exists(subjectKey("user-2")))
+}
+
+func TestService_Deactivate_PublishFailed(t *testing.T) {
+	t.Parallel()
+
+	s, _, _ := newService(t)
+
+	events := mocks.NewMockeventPublisher(gomock.NewController(t))
+	s.events = events
+
+	events.EXPECT().Publish(gomock.Any(), gomock.Any()).Return("", errors.New("redis is down"))
+
+	// ошибка публикации не отменяет отключение
+	d, err := s.Deactivate(t.Context(), "user-1", "admin")
+	require.NoError(t, err)
+	assert.Empty(t, d.EventID)
+
+	before, err := s.RevokedBefore(t.Context(), "user-1")
+	require.NoError(t, err)
+	assert.False(t, before.IsZero())
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService_Check(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		source  fakeSource
+		prepare func(t *testing.T, s *Service, events *mocks.MockeventPublisher)
+		want    CheckResult
+		wantErr bool
+	}{
+		{
+			name:   "positive case: finds user deactivated in source",
+			source: fakeSource{subjects: []string{"ldap:alice", "ldap:bob"}},
+			prepare: func(t *testing.T, s *Service, events *mocks.MockeventPublisher) {
+				t.Helper()
+
+				events.EXPECT().Publish(gomock.Any(), gomock.Any()).Return("1-0", nil)
+
+				_, err := s.Deactivate(t.Context(), "ldap:alice", "scim")
+				require.NoError(t, err)
+
+				events.EXPECT().Publish(gomock.Any(), event.Event{
+					Type:    event.TypeUserDeactivated,
+					Subject: "ldap:bob",
+					Source:  "scim/consistency-check",
+					At:      s.now().UTC(),
+				}).Return("2-0", nil)
+			},
+			want: CheckResult{Checked: 2, Deactivated: []string{"ldap:bob"}, Republished: []string{}},
+		},
+		{
+			name: "positive case: republishes lost event",
+			prepare: func(t *testing.T, s *Service, events *mocks.MockeventPublisher) {
+				t.Helper()
+
+				events.EXPECT().Publish(gomock.Any(), gomock.Any()).Return("", errors.New("redis is down"))
+
+				_, err := s.Deactivate(t.Context(), "user-1", "admin")
+				require.NoError(t, err)
+
+				events.EXPECT().Publish(gomock.Any(), gomock.Any()).Return("3-0", nil)
+			},
+			want: CheckResult{Checked: 1, Deactivated: []string{}, Republished: []string{"user-1"}},
+		},
+		{
+			name: "positive case: removes stale subject",
+			prepare: func(t *testing.T, s *Service, _ *mocks.MockeventPublisher) {
+				t.Helper()
+
+				require.NoError(t, s.client.SAdd(t.Context(), deactivationsKey(), "user-1").Err())
+			},
+			want: CheckResult{Checked: 0, Deactivated: []string{}, Republished: []string{}},
+		},
+		{
+			name:    "error case: source failed",
+			source:  fakeSource{err: errors.New("redis is down")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, _, _ := newService(t)
+
+			now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+			s.now = func() time.Time { return now }
+
+			events := mocks.NewMockeventPublisher(gomock.NewController(t))
+			s.events = events
+
+			s.RegisterSource("scim", tt.source)
+
+			if tt.prepare != nil {
+				tt.prepare(t, s, events)
+			}
+
+			result, err := s.check(t.Context(), nil, func(int) {})
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result)
+
+			members, err := s.client.SMembers(t.Context(), deactivationsKey()).Result()
+			require.NoError(t, err)
+			assert.Len(t, members, tt.want.Checked)
+		})
+	}
+}
+
+func TestService_EnqueueCheck(t *testing.T) {
+	t.Parallel()
+
+	s, _, _ := newService(t)
+
+	queued, err := s.EnqueueCheck(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, CheckJobType, queued.Type)
+}
@@ -0,0 +1,89 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: deactivation.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	event "auth-service/internal/service/event"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockeventPublisher is a mock of eventPublisher interface.
+type MockeventPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockeventPublisherMockRecorder
+}
+
+// MockeventPublisherMockRecorder is the mock recorder for MockeventPublisher.
+type MockeventPublisherMockRecorder struct {
+	mock *MockeventPublisher
+}
+
+// NewMockeventPublisher creates a new mock instance.
+func NewMockeventPublisher(ctrl *gomock.Controller) *MockeventPublisher {
+	mock := &MockeventPublisher{ctrl: ctrl}
+	mock.recorder = &MockeventPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockeventPublisher) EXPECT() *MockeventPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockeventPublisher) Publish(ctx context.Context, e event.Event) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, e)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockeventPublisherMockRecorder) Publish(ctx, e interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockeventPublisher)(nil).Publish), ctx, e)
+}
+
+// MockaccountSource is a mock of accountSource interface.
+type MockaccountSource struct {
+	ctrl     *gomock.Controller
+	recorder *MockaccountSourceMockRecorder
+}
+
+// MockaccountSourceMockRecorder is the mock recorder for MockaccountSource.
+type MockaccountSourceMockRecorder struct {
+	mock *MockaccountSource
+}
+
+// NewMockaccountSource creates a new mock instance.
+func NewMockaccountSource(ctrl *gomock.Controller) *MockaccountSource {
+	mock := &MockaccountSource{ctrl: ctrl}
+	mock.recorder = &MockaccountSourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockaccountSource) EXPECT() *MockaccountSourceMockRecorder {
+	return m.recorder
+}
+
+// InactiveSubjects mocks base method.
+func (m *MockaccountSource) InactiveSubjects(ctx context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InactiveSubjects", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InactiveSubjects indicates an expected call of InactiveSubjects.
+func (mr *MockaccountSourceMockRecorder) InactiveSubjects(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InactiveSubjects", reflect.TypeOf((*MockaccountSource)(nil).InactiveSubjects), ctx)
+}
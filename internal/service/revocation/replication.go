@@ -0,0 +1,78 @@
+package revocation
+
+import (
+	"auth-service/internal/service/event"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Apply применяет событие об отзыве, отключении или включении пользователя, полученное из другого
+// региона. Состояние регионов сходится независимо от порядка доставки событий (побеждает отзыв):
+//   - отметка об отзыве только сдвигается вперед;
+//   - отозванный токен добавляется в черный список, если еще не истек;
+//   - отключение применяется, если пользователь не был включен позже него;
+//   - включение применяется, если пользователь был отключен раньше него.
+//
+// При совпадении времени отключение побеждает включение. Примененные отключение и включение
+// публикуются в локальный stream с регионом события. Возвращает false, если событие ничего не изменило
+// или не относится к отзыву.
+func (s *Service) Apply(ctx context.Context, e event.Event) (bool, error) {
+	if e.Subject == "" {
+		return false, fmt.Errorf("%w: subject is required", ErrInvalidArgument)
+	}
+
+	switch e.Type {
+	case event.TypeTokensRevoked:
+		return s.advance(ctx, e.Subject, e.At.Unix())
+	case event.TypeTokenRevoked:
+		return s.blacklist(ctx, e.Subject, e.ExpiresAt)
+	case event.TypeUserDeactivated:
+		return s.applyDeactivation(ctx, e)
+	case event.TypeUserReactivated:
+		return s.applyReactivation(ctx, e)
+	default:
+		return false, nil
+	}
+}
+
+func (s *Service) applyDeactivation(ctx context.Context, e event.Event) (bool, error) {
+	d, err := s.Deactivation(ctx, e.Subject)
+	if err != nil || d != nil {
+		return false, err
+	}
+
+	reactivated, err := s.client.Get(ctx, reactivationKey(e.Subject)).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return false, fmt.Errorf("revocation: error get reactivation: %w", err)
+	}
+
+	if reactivated > e.At.Unix() {
+		return false, nil
+	}
+
+	d = &Deactivation{Subject: e.Subject, Source: e.Source, DeactivatedAt: e.At, Region: e.Region}
+
+	if err := s.deactivate(ctx, d); err != nil {
+		return false, err
+	}
+
+	s.publish(ctx, d)
+
+	return true, nil
+}
+
+func (s *Service) applyReactivation(ctx context.Context, e event.Event) (bool, error) {
+	d, err := s.Deactivation(ctx, e.Subject)
+	if err != nil || d == nil || !d.DeactivatedAt.Before(e.At) {
+		return false, err
+	}
+
+	if err := s.reactivate(ctx, e); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,137 @@
+package revocation
+
+import (
+	"auth-service/internal/service/event"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestService_Apply(t *testing.T) {
+	t.Parallel()
+
+	s, _, mr := newService(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	events, err := event.New(event.WithClient(client), event.WithStream("events"), event.WithRegion("eu"))
+	require.NoError(t, err)
+
+	s.events = events
+
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		name        string
+		e           event.Event
+		wantApplied bool
+	}{
+		{
+			name:        "revocation",
+			e:           event.Event{Type: event.TypeTokensRevoked, Subject: "user-1", At: at, Region: "us"},
+			wantApplied: true,
+		},
+		{
+			name: "earlier revocation does not move mark back",
+			e:    event.Event{Type: event.TypeTokensRevoked, Subject: "user-1", At: at.Add(-time.Hour), Region: "us"},
+		},
+		{
+			name:        "deactivation",
+			e:           event.Event{Type: event.TypeUserDeactivated, Subject: "user-1", Source: "admin", At: at, Region: "us"},
+			wantApplied: true,
+		},
+		{
+			name: "repeated deactivation",
+			e:    event.Event{Type: event.TypeUserDeactivated, Subject: "user-1", Source: "scim", At: at.Add(time.Minute), Region: "ap"},
+		},
+		{
+			name: "concurrent reactivation loses",
+			e:    event.Event{Type: event.TypeUserReactivated, Subject: "user-1", Source: "admin", At: at, Region: "ap"},
+		},
+		{
+			name:        "later reactivation",
+			e:           event.Event{Type: event.TypeUserReactivated, Subject: "user-1", Source: "admin", At: at.Add(time.Hour), Region: "us"},
+			wantApplied: true,
+		},
+		{
+			name: "deactivation before reactivation",
+			e:    event.Event{Type: event.TypeUserDeactivated, Subject: "user-1", Source: "scim", At: at.Add(30 * time.Minute), Region: "ap"},
+		},
+		{
+			name:        "concurrent deactivation wins",
+			e:           event.Event{Type: event.TypeUserDeactivated, Subject: "user-1", Source: "scim", At: at.Add(time.Hour), Region: "ap"},
+			wantApplied: true,
+		},
+		{
+			name: "unrelated event",
+			e:    event.Event{Type: event.TypeSourceBanned, Subject: "192.0.2.1", At: at, Region: "us"},
+		},
+	}
+
+	for _, step := range steps {
+		applied, err := s.Apply(t.Context(), step.e)
+		require.NoError(t, err, step.name)
+		assert.Equal(t, step.wantApplied, applied, step.name)
+	}
+
+	d, err := s.Deactivation(t.Context(), "user-1")
+	require.NoError(t, err)
+	require.NotNil(t, d)
+	assert.Equal(t, "ap", d.Region)
+	assert.Equal(t, at.Add(time.Hour), d.DeactivatedAt)
+	assert.NotEmpty(t, d.EventID)
+
+	// примененные отключения и включения публикуются в локальный stream с регионом события
+	messages, err := client.XRange(t.Context(), "events", "-", "+").Result()
+	require.NoError(t, err)
+
+	var published []event.Event
+
+	for _, msg := range messages {
+		e, err := event.Parse(msg)
+		require.NoError(t, err)
+
+		published = append(published, e)
+	}
+
+	assert.Equal(t, []event.Event{steps[2].e, steps[5].e, steps[7].e}, published)
+
+	_, err = s.Apply(t.Context(), event.Event{Type: event.TypeTokensRevoked})
+	require.ErrorIs(t, err, ErrInvalidArgument)
+}
+
+func TestRun_PublishRevocation(t *testing.T) {
+	t.Parallel()
+
+	s, _, mr := newService(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	events, err := event.New(event.WithClient(client), event.WithStream("events"), event.WithRegion("eu"))
+	require.NoError(t, err)
+
+	s.events = events
+
+	_, err = s.run(t.Context(), map[string]string{"subject": "user-1", "at": "1772366400"}, nil)
+	require.NoError(t, err)
+
+	messages, err := client.XRange(t.Context(), "events", "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+
+	e, err := event.Parse(messages[0])
+	require.NoError(t, err)
+	assert.Equal(t, event.Event{
+		Type:    event.TypeTokensRevoked,
+		Subject: "user-1",
+		Source:  "revocation",
+		At:      time.Unix(1772366400, 0).UTC(),
+		Region:  "eu",
+	}, e)
+}
@@ -0,0 +1,286 @@
+// Package revocation отзывает все токены пользователя. Токены сервиса не хранятся,
+// поэтому отзыв записывается как момент времени: токены субъекта, выпущенные не позже него,
+// перестают приниматься. Запросы на отзыв выполняются асинхронно заданиями job,
+// чтобы административный запрос не ждал обработки. Отключение пользователя отзывает его токены
+// сразу и действует до включения: токены отключенного пользователя не принимаются, даже если
+// выпущены после отключения.
+package revocation
+
+import (
+	"auth-service/internal/service/event"
+	"auth-service/internal/service/job"
+	"auth-service/internal/service/ttlcheck"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	keyPrefix = "auth:revocation:"
+
+	// JobType - тип задания на отзыв токенов пользователя.
+	JobType = "revoke-user-tokens"
+
+	// sourceRevocation - источник событий об отзыве токенов.
+	sourceRevocation = "revocation"
+
+	// DefaultRetention - сколько хранится отметка об отзыве. Должно быть не меньше
+	// максимального срока жизни токенов, иначе отозванные токены снова начнут приниматься.
+	DefaultRetention = 30 * 24 * time.Hour
+)
+
+// ErrInvalidArgument - не заполнены обязательные параметры.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// Result - результат задания на отзыв.
+type Result struct {
+	Subject       string    `json:"subject"`
+	RevokedBefore time.Time `json:"revoked_before"`
+}
+
+// Service - отзыв токенов пользователей.
+//
+// Ключи:
+//   - auth:revocation:subject:<id> - unix time, до которого (включительно) токены субъекта отозваны;
+//   - auth:revocation:deactivated:<id> - hash с отключением пользователя (at, source, event, region);
+//   - auth:revocation:reactivated:<id> - unix time последнего включения пользователя;
+//   - auth:revocation:deactivations - множество отключенных субъектов;
+//   - auth:revocation:jti:<jti> - отозванный токен (черный список), хранится до истечения токена.
+type Service struct {
+	client redis.UniversalClient
+	jobs   *job.Service
+	events eventPublisher
+
+	mu      sync.RWMutex
+	sources map[string]accountSource
+
+	retention   time.Duration
+	tokenLeeway time.Duration
+	slack       time.Duration
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Service.
+type Option func(*Service)
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(s *Service) {
+		s.client = client
+	}
+}
+
+// WithJobs устанавливает очередь заданий, в которой выполняется отзыв.
+func WithJobs(jobs *job.Service) Option {
+	return func(s *Service) {
+		s.jobs = jobs
+	}
+}
+
+// WithRetention устанавливает, сколько хранится отметка об отзыве. По умолчанию DefaultRetention.
+func WithRetention(retention time.Duration) Option {
+	return func(s *Service) {
+		s.retention = retention
+	}
+}
+
+// WithTokenLeeway устанавливает, сколько отозванный токен остается в черном списке после истечения.
+// Должно быть не меньше периода мягкой проверки истекших токенов, иначе отозванный токен снова
+// начнет приниматься после истечения.
+func WithTokenLeeway(leeway time.Duration) Option {
+	return func(s *Service) {
+		s.tokenLeeway = leeway
+	}
+}
+
+// WithTTLSlack устанавливает запас TTL записей черного списка сверх срока, пока они нужны.
+// По умолчанию ttlcheck.DefaultSlack.
+func WithTTLSlack(slack time.Duration) Option {
+	return func(s *Service) {
+		s.slack = slack
+	}
+}
+
+// New создает новый Service и регистрирует обработчики заданий JobType и CheckJobType.
+func New(opts ...Option) (*Service, error) {
+	s := &Service{
+		retention: DefaultRetention,
+		slack:     ttlcheck.DefaultSlack,
+		sources:   make(map[string]accountSource),
+		now:       time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.client == nil {
+		return nil, errors.New("redis client is required")
+	}
+
+	if s.jobs == nil {
+		return nil, errors.New("jobs are required")
+	}
+
+	if s.retention <= 0 {
+		return nil, errors.New("retention must be positive")
+	}
+
+	if s.tokenLeeway < 0 || s.slack < 0 {
+		return nil, errors.New("token leeway and ttl slack must not be negative")
+	}
+
+	s.jobs.Register(JobType, s.run)
+	s.jobs.Register(CheckJobType, s.check)
+
+	return s, nil
+}
+
+func subjectKey(subject string) string {
+	return keyPrefix + "subject:" + subject
+}
+
+// Enqueue ставит в очередь отзыв всех токенов субъекта, выпущенных до текущего момента.
+func (s *Service) Enqueue(ctx context.Context, subject string) (*job.Job, error) {
+	if subject == "" {
+		return nil, fmt.Errorf("%w: subject is required", ErrInvalidArgument)
+	}
+
+	return s.jobs.Enqueue(ctx, JobType, map[string]string{
+		"subject": subject,
+		"at":      strconv.FormatInt(s.now().Unix(), 10),
+	})
+}
+
+// RevokeSubject сразу отзывает все токены субъекта, выпущенные до текущего момента, без задания.
+// Нужен, когда отзыв должен действовать к ответу на запрос, например, при выходе из всех сессий.
+func (s *Service) RevokeSubject(ctx context.Context, subject string) error {
+	at := strconv.FormatInt(s.now().Unix(), 10)
+
+	if err := s.revoke(ctx, subject, at); err != nil {
+		return err
+	}
+
+	s.publishRevocation(ctx, subject, at)
+
+	return nil
+}
+
+// RevokedBefore возвращает момент, до которого (включительно) отозваны токены субъекта.
+// Нулевое время означает, что токены субъекта не отзывались. Для отключенного пользователя
+// возвращается текущий момент: отозваны все его токены.
+func (s *Service) RevokedBefore(ctx context.Context, subject string) (time.Time, error) {
+	var (
+		mark   *redis.StringCmd
+		exists *redis.IntCmd
+	)
+
+	// ключи могут лежать в разных слотах кластера, поэтому пайплайн без транзакции
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		mark = p.Get(ctx, subjectKey(subject))
+		exists = p.Exists(ctx, deactivationKey(subject))
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return time.Time{}, fmt.Errorf("revocation: error get revocation: %w", err)
+	}
+
+	if exists.Val() > 0 {
+		return s.now().UTC(), nil
+	}
+
+	value, err := mark.Int64()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("revocation: error get revocation: %w", err)
+	}
+
+	return time.Unix(value, 0).UTC(), nil
+}
+
+// run выполняет задание на отзыв.
+func (s *Service) run(ctx context.Context, params map[string]string, _ job.Progress) (any, error) {
+	subject := params["subject"]
+
+	if err := s.revoke(ctx, subject, params["at"]); err != nil {
+		return nil, err
+	}
+
+	s.publishRevocation(ctx, subject, params["at"])
+
+	revokedBefore, err := s.RevokedBefore(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.WithField("subject", subject).Info("revoked user tokens")
+
+	return Result{Subject: subject, RevokedBefore: revokedBefore}, nil
+}
+
+// publishRevocation публикует событие об отзыве токенов, чтобы отзыв получили другие регионы.
+// Ошибка публикации не отменяет отзыв.
+func (s *Service) publishRevocation(ctx context.Context, subject, at string) {
+	if s.events == nil {
+		return
+	}
+
+	ts, _ := strconv.ParseInt(at, 10, 64) // время уже проверено в revoke
+
+	_, err := s.events.Publish(ctx, event.Event{
+		Type:    event.TypeTokensRevoked,
+		Subject: subject,
+		Source:  sourceRevocation,
+		At:      time.Unix(ts, 0).UTC(),
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("subject", subject).Error("error publish revocation")
+	}
+}
+
+// revoke записывает момент отзыва токенов субъекта. Момент не сдвигается назад,
+// если задания обработаны не по порядку.
+func (s *Service) revoke(ctx context.Context, subject, at string) error {
+	if subject == "" {
+		return fmt.Errorf("%w: subject is required", ErrInvalidArgument)
+	}
+
+	ts, err := strconv.ParseInt(at, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid revocation time %q", ErrInvalidArgument, at)
+	}
+
+	_, err = s.advance(ctx, subject, ts)
+
+	return err
+}
+
+// advance сдвигает отметку об отзыве вперед до ts. Возвращает false, если отметка уже не раньше ts.
+func (s *Service) advance(ctx context.Context, subject string, ts int64) (bool, error) {
+	// отметка читается напрямую: для отключенного пользователя RevokedBefore возвращает текущий момент
+	current, err := s.client.Get(ctx, subjectKey(subject)).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return false, fmt.Errorf("revocation: error get revocation: %w", err)
+	}
+
+	if current >= ts {
+		return false, nil
+	}
+
+	if err := s.client.Set(ctx, subjectKey(subject), ts, s.retention).Err(); err != nil {
+		return false, fmt.Errorf("revocation: error save revocation: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,183 @@
+package revocation
+
+import (
+	"auth-service/internal/service/job"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newService(t *testing.T) (*Service, *job.Service, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	jobs, err := job.New(job.WithClient(client), job.WithConsumer("test"))
+	require.NoError(t, err)
+
+	s, err := New(WithClient(client), WithJobs(jobs))
+	require.NoError(t, err)
+
+	return s, jobs, mr
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	t.Cleanup(func() { _ = client.Close() })
+
+	jobs, err := job.New(job.WithClient(client), job.WithConsumer("test"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			opts:    []Option{WithClient(client), WithJobs(jobs)},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: custom retention",
+			opts:    []Option{WithClient(client), WithJobs(jobs), WithRetention(time.Hour)},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: negative token leeway",
+			opts:    []Option{WithClient(client), WithJobs(jobs), WithTokenLeeway(-time.Minute)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: client is nil",
+			opts:    []Option{WithJobs(jobs)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: jobs are nil",
+			opts:    []Option{WithClient(client)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: negative retention",
+			opts:    []Option{WithClient(client), WithJobs(jobs), WithRetention(-time.Hour)},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(tt.opts...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService(t *testing.T) {
+	t.Parallel()
+
+	s, jobs, mr := newService(t)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	_, err := s.Enqueue(t.Context(), "")
+	require.ErrorIs(t, err, ErrInvalidArgument)
+
+	queued, err := s.Enqueue(t.Context(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, JobType, queued.Type)
+	assert.Equal(t, job.StatusQueued, queued.Status)
+
+	// до обработки токены не отозваны
+	before, err := s.RevokedBefore(t.Context(), "user-1")
+	require.NoError(t, err)
+	assert.True(t, before.IsZero())
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+
+	go func() { done <- jobs.Start(ctx) }()
+
+	var got *job.Job
+
+	require.Eventually(t, func() bool {
+		got, err = jobs.Get(t.Context(), queued.ID)
+		return err == nil && got.Status == job.StatusDone
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	var result Result
+
+	require.NoError(t, json.Unmarshal(got.Result, &result))
+	assert.Equal(t, Result{Subject: "user-1", RevokedBefore: now}, result)
+
+	before, err = s.RevokedBefore(t.Context(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, now, before)
+
+	// отметка об отзыве удаляется через retention
+	assert.Equal(t, DefaultRetention, mr.TTL(subjectKey("user-1")))
+}
+
+func TestRevoke(t *testing.T) {
+	t.Parallel()
+
+	s, _, _ := newService(t)
+
+	require.NoError(t, s.revoke(t.Context(), "user-1", "200"))
+
+	// более раннее задание не сдвигает момент отзыва назад
+	require.NoError(t, s.revoke(t.Context(), "user-1", "100"))
+
+	before, err := s.RevokedBefore(t.Context(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(200), before.Unix())
+
+	require.ErrorIs(t, s.revoke(t.Context(), "", "100"), ErrInvalidArgument)
+	require.ErrorIs(t, s.revoke(t.Context(), "user-1", "abc"), ErrInvalidArgument)
+}
+
+func TestRevokeSubject(t *testing.T) {
+	t.Parallel()
+
+	s, _, _ := newService(t)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	require.ErrorIs(t, s.RevokeSubject(t.Context(), ""), ErrInvalidArgument)
+
+	// отзыв действует сразу, без обработки задания
+	require.NoError(t, s.RevokeSubject(t.Context(), "user-1"))
+
+	before, err := s.RevokedBefore(t.Context(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, now, before)
+}
+
+func TestRun_Failed(t *testing.T) {
+	t.Parallel()
+
+	s, _, _ := newService(t)
+
+	_, err := s.run(t.Context(), map[string]string{"subject": "user-1", "at": "abc"}, nil)
+	require.ErrorIs(t, err, ErrInvalidArgument)
+	require.ErrorContains(t, err, "invalid revocation time")
+}
@@ -0,0 +1,85 @@
+package revocation
+
+import (
+	"auth-service/internal/service/backup"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Snapshot - все действующие отметки об отзыве на момент выгрузки.
+type Snapshot struct {
+	// RevokedBefore - субъект -> момент, до которого (включительно) отозваны его токены.
+	RevokedBefore map[string]time.Time `json:"revoked_before"`
+	// RevokedTokens - jti отозванного токена -> срок его действия. После срока действия запись не нужна.
+	RevokedTokens map[string]time.Time `json:"revoked_tokens"`
+	// Deactivated - отключенные пользователи: их токены не принимаются, когда бы ни были выпущены.
+	Deactivated []string  `json:"deactivated"`
+	TakenAt     time.Time `json:"taken_at"`
+}
+
+// Snapshot выгружает все отметки об отзыве, черный список токенов и отключенных пользователей, например,
+// для проверки токенов без обращения к сервису. Отметки читаются обходом ключей, поэтому снимок
+// не атомарен: отзыв, записанный во время выгрузки, может в него не попасть.
+func (s *Service) Snapshot(ctx context.Context) (*Snapshot, error) {
+	revokedBefore, err := s.scanTimes(ctx, subjectKey(""))
+	if err != nil {
+		return nil, fmt.Errorf("revocation: error read revocations: %w", err)
+	}
+
+	revokedTokens, err := s.scanTimes(ctx, tokenKey(""))
+	if err != nil {
+		return nil, fmt.Errorf("revocation: error read revoked tokens: %w", err)
+	}
+
+	deactivated, err := s.client.SMembers(ctx, deactivationsKey()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("revocation: error read deactivations: %w", err)
+	}
+
+	slices.Sort(deactivated)
+
+	return &Snapshot{
+		RevokedBefore: revokedBefore,
+		RevokedTokens: revokedTokens,
+		Deactivated:   deactivated,
+		TakenAt:       s.now().UTC(),
+	}, nil
+}
+
+// scanTimes читает все ключи с префиксом prefix, значение которых - unix time. Ключ в результате без префикса.
+func (s *Service) scanTimes(ctx context.Context, prefix string) (map[string]time.Time, error) {
+	times := make(map[string]time.Time)
+
+	// в кластере узлы обходятся параллельно
+	var mu sync.Mutex
+
+	err := backup.Scan(ctx, s.client, prefix+"*", func(ctx context.Context, client redis.UniversalClient, key string) error {
+		value, err := client.Get(ctx, key).Int64()
+		if errors.Is(err, redis.Nil) {
+			// отметка истекла во время обхода
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		times[strings.TrimPrefix(key, prefix)] = time.Unix(value, 0).UTC()
+		mu.Unlock()
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return times, nil
+}
@@ -0,0 +1,54 @@
+package revocation
+
+import (
+	"auth-service/internal/service/revocation/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Snapshot(t *testing.T) {
+	t.Parallel()
+
+	s, _, mr := newService(t)
+
+	events := mocks.NewMockeventPublisher(gomock.NewController(t))
+	events.EXPECT().Publish(gomock.Any(), gomock.Any()).Return("1-0", nil).AnyTimes()
+	s.events = events
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	empty, err := s.Snapshot(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, empty.RevokedBefore)
+	assert.Empty(t, empty.RevokedTokens)
+	assert.Empty(t, empty.Deactivated)
+
+	require.NoError(t, s.revoke(t.Context(), "user-1", "100"))
+	require.NoError(t, s.revoke(t.Context(), "user-2", "200"))
+
+	_, err = s.Deactivate(t.Context(), "user-3", "admin")
+	require.NoError(t, err)
+
+	require.NoError(t, s.RevokeToken(t.Context(), "token-1", now.Add(time.Hour)))
+
+	snapshot, err := s.Snapshot(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, now, snapshot.TakenAt)
+	assert.Equal(t, map[string]time.Time{
+		"user-1": time.Unix(100, 0).UTC(),
+		"user-2": time.Unix(200, 0).UTC(),
+		"user-3": now,
+	}, snapshot.RevokedBefore)
+	assert.Equal(t, map[string]time.Time{"token-1": now.Add(time.Hour)}, snapshot.RevokedTokens)
+	assert.Equal(t, []string{"user-3"}, snapshot.Deactivated)
+
+	mr.Close()
+
+	_, err = s.Snapshot(t.Context())
+	require.Error(t, err)
+}
@@ -0,0 +1,109 @@
+package revocation
+
+import (
+	"auth-service/internal/service/event"
+	"auth-service/internal/service/ttlcheck"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// tokenKey - ключ отзыва одного токена в черном списке.
+func tokenKey(jti string) string {
+	return keyPrefix + "jti:" + jti
+}
+
+// RevokeToken отзывает один токен по его jti. Запись в черном списке хранится, пока токен
+// мог бы приниматься: до expiresAt плюс допуск, заданный WithTokenLeeway, и еще запас WithTTLSlack.
+// Уже истекший с учетом допуска токен не записывается. Отзыв публикуется, чтобы его получили другие регионы.
+func (s *Service) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	if jti == "" {
+		return fmt.Errorf("%w: jti is required", ErrInvalidArgument)
+	}
+
+	if _, err := s.blacklist(ctx, jti, expiresAt); err != nil {
+		return err
+	}
+
+	s.publishTokenRevocation(ctx, jti, expiresAt)
+
+	return nil
+}
+
+// blacklist записывает токен в черный список. Возвращает false, если токен уже истек или уже записан.
+func (s *Service) blacklist(ctx context.Context, jti string, expiresAt time.Time) (bool, error) {
+	needed := expiresAt.Add(s.tokenLeeway).Sub(s.now())
+	if needed <= 0 {
+		return false, nil
+	}
+
+	added, err := s.client.SetNX(ctx, tokenKey(jti), expiresAt.Unix(), needed+s.slack).Result()
+	if err != nil {
+		return false, fmt.Errorf("revocation: error revoke token: %w", err)
+	}
+
+	return added, nil
+}
+
+// publishTokenRevocation публикует событие об отзыве токена. Ошибка публикации не отменяет отзыв.
+func (s *Service) publishTokenRevocation(ctx context.Context, jti string, expiresAt time.Time) {
+	if s.events == nil || !expiresAt.Add(s.tokenLeeway).After(s.now()) {
+		return
+	}
+
+	_, err := s.events.Publish(ctx, event.Event{
+		Type:      event.TypeTokenRevoked,
+		Subject:   jti,
+		Source:    sourceRevocation,
+		At:        s.now().UTC(),
+		ExpiresAt: expiresAt.UTC(),
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("jti", jti).Error("error publish token revocation")
+	}
+}
+
+// IsRevoked возвращает true, если токен с этим jti отозван.
+func (s *Service) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	if jti == "" {
+		return false, nil
+	}
+
+	n, err := s.client.Exists(ctx, tokenKey(jti)).Result()
+	if err != nil {
+		return false, fmt.Errorf("revocation: error check token revocation: %w", err)
+	}
+
+	return n > 0, nil
+}
+
+// TTLRecords возвращает записи черного списка для проверки согласованности TTL (ttlcheck).
+// Запись нужна до истечения токена плюс допуск WithTokenLeeway.
+func (s *Service) TTLRecords() []ttlcheck.Record {
+	return []ttlcheck.Record{{
+		Name:    "revoked_token",
+		Pattern: tokenKey("*"),
+		Expiry: func(ctx context.Context, key string) (time.Time, bool, error) {
+			value, err := s.client.Get(ctx, key).Result()
+			if errors.Is(err, redis.Nil) {
+				return time.Time{}, false, nil
+			}
+
+			if err != nil {
+				return time.Time{}, false, fmt.Errorf("revocation: error get revoked token: %w", err)
+			}
+
+			exp, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return time.Time{}, false, nil //nolint:nilerr // запись не своего формата не проверяется
+			}
+
+			return time.Unix(exp, 0).Add(s.tokenLeeway), true, nil
+		},
+	}}
+}
@@ -0,0 +1,151 @@
+package revocation
+
+import (
+	"auth-service/internal/service/event"
+	"auth-service/internal/service/ttlcheck"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_RevokeToken(t *testing.T) {
+	t.Parallel()
+
+	s, _, mr := newService(t)
+	s.tokenLeeway = time.Minute
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	require.ErrorIs(t, s.RevokeToken(t.Context(), "", now.Add(time.Hour)), ErrInvalidArgument)
+
+	revoked, err := s.IsRevoked(t.Context(), "token-1")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	// запись хранится до истечения токена, еще допуск мягкой проверки и запас TTL
+	require.NoError(t, s.RevokeToken(t.Context(), "token-1", now.Add(time.Hour)))
+	assert.Equal(t, time.Hour+time.Minute+ttlcheck.DefaultSlack, mr.TTL(tokenKey("token-1")))
+
+	revoked, err = s.IsRevoked(t.Context(), "token-1")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// отзыв не затрагивает другие токены субъекта
+	revoked, err = s.IsRevoked(t.Context(), "token-2")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	// истекший с учетом допуска токен уже не принимается, записывать его не нужно
+	require.NoError(t, s.RevokeToken(t.Context(), "token-3", now.Add(-time.Minute)))
+	assert.False(t, mr.Exists(tokenKey("token-3")))
+
+	mr.FastForward(time.Hour + time.Minute + ttlcheck.DefaultSlack)
+
+	revoked, err = s.IsRevoked(t.Context(), "token-1")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	mr.Close()
+
+	_, err = s.IsRevoked(t.Context(), "token-1")
+	require.Error(t, err)
+}
+
+func TestService_RevokeToken_Replication(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// отзыв в регионе eu
+	s, _, mr := newService(t)
+	s.now = func() time.Time { return now }
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	events, err := event.New(event.WithClient(client), event.WithStream("events"), event.WithRegion("eu"))
+	require.NoError(t, err)
+
+	s.events = events
+
+	require.NoError(t, s.RevokeToken(t.Context(), "token-1", now.Add(time.Hour)))
+
+	// истекший токен не публикуется
+	require.NoError(t, s.RevokeToken(t.Context(), "token-2", now.Add(-time.Minute)))
+
+	messages, err := client.XRange(t.Context(), "events", "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+
+	e, err := event.Parse(messages[0])
+	require.NoError(t, err)
+	assert.Equal(t, event.Event{
+		Type:      event.TypeTokenRevoked,
+		Subject:   "token-1",
+		Source:    "revocation",
+		At:        now,
+		Region:    "eu",
+		ExpiresAt: now.Add(time.Hour),
+	}, e)
+
+	// применение в другом регионе
+	peer, _, peerMR := newService(t)
+	peer.now = func() time.Time { return now.Add(time.Minute) }
+
+	applied, err := peer.Apply(t.Context(), e)
+	require.NoError(t, err)
+	assert.True(t, applied)
+	assert.Equal(t, 59*time.Minute+ttlcheck.DefaultSlack, peerMR.TTL(tokenKey("token-1")))
+
+	revoked, err := peer.IsRevoked(t.Context(), "token-1")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// повторная доставка ничего не меняет
+	applied, err = peer.Apply(t.Context(), e)
+	require.NoError(t, err)
+	assert.False(t, applied)
+
+	// событие о токене, истекшем до доставки, не применяется
+	peer.now = func() time.Time { return now.Add(2 * time.Hour) }
+
+	applied, err = peer.Apply(t.Context(), event.Event{
+		Type: event.TypeTokenRevoked, Subject: "token-3", At: now, Region: "eu", ExpiresAt: now.Add(time.Hour),
+	})
+	require.NoError(t, err)
+	assert.False(t, applied)
+	assert.False(t, peerMR.Exists(tokenKey("token-3")))
+}
+
+func TestService_TTLRecords(t *testing.T) {
+	t.Parallel()
+
+	s, _, mr := newService(t)
+	s.tokenLeeway = time.Minute
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	require.NoError(t, s.RevokeToken(t.Context(), "token-1", now.Add(time.Hour)))
+	require.NoError(t, mr.Set(tokenKey("token-2"), "not-a-timestamp"))
+
+	records := s.TTLRecords()
+	require.Len(t, records, 1)
+
+	expiry := records[0].Expiry
+
+	expiresAt, ok, err := expiry(t.Context(), tokenKey("token-1"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(time.Hour+time.Minute), expiresAt.UTC())
+
+	for _, key := range []string{tokenKey("token-2"), tokenKey("unknown")} {
+		_, ok, err = expiry(t.Context(), key)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	}
+}
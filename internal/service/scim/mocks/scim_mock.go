@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: scim.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	revocation "auth-service/internal/service/revocation"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// Mockdeactivator is a mock of deactivator interface.
+type Mockdeactivator struct {
+	ctrl     *gomock.Controller
+	recorder *MockdeactivatorMockRecorder
+}
+
+// MockdeactivatorMockRecorder is the mock recorder for Mockdeactivator.
+type MockdeactivatorMockRecorder struct {
+	mock *Mockdeactivator
+}
+
+// NewMockdeactivator creates a new mock instance.
+func NewMockdeactivator(ctrl *gomock.Controller) *Mockdeactivator {
+	mock := &Mockdeactivator{ctrl: ctrl}
+	mock.recorder = &MockdeactivatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockdeactivator) EXPECT() *MockdeactivatorMockRecorder {
+	return m.recorder
+}
+
+// Deactivate mocks base method.
+func (m *Mockdeactivator) Deactivate(ctx context.Context, subject, source string) (*revocation.Deactivation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deactivate", ctx, subject, source)
+	ret0, _ := ret[0].(*revocation.Deactivation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Deactivate indicates an expected call of Deactivate.
+func (mr *MockdeactivatorMockRecorder) Deactivate(ctx, subject, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deactivate", reflect.TypeOf((*Mockdeactivator)(nil).Deactivate), ctx, subject, source)
+}
+
+// Reactivate mocks base method.
+func (m *Mockdeactivator) Reactivate(ctx context.Context, subject, source string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reactivate", ctx, subject, source)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reactivate indicates an expected call of Reactivate.
+func (mr *MockdeactivatorMockRecorder) Reactivate(ctx, subject, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reactivate", reflect.TypeOf((*Mockdeactivator)(nil).Reactivate), ctx, subject, source)
+}
@@ -0,0 +1,201 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Операции PATCH.
+const (
+	opAdd     = "add"
+	opReplace = "replace"
+	opRemove  = "remove"
+)
+
+// PatchRequest - тело запроса PATCH.
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation - операция PATCH. Путь и значение - в терминах атрибутов User.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// applyOperation применяет операцию к учетной записи. Имена операций и атрибутов
+// сравниваются без учета регистра: так их отправляют популярные IdP (например, Entra ID
+// присылает "Replace" и active строкой "False").
+func applyOperation(u *User, op PatchOperation) error {
+	kind := strings.ToLower(op.Op)
+
+	switch kind {
+	case opAdd, opReplace:
+	case opRemove:
+		if op.Path == "" {
+			return fmt.Errorf("%w: remove requires path", ErrInvalidPath)
+		}
+	default:
+		return fmt.Errorf("%w: unknown op %q", ErrInvalidValue, op.Op)
+	}
+
+	if op.Path == "" {
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &values); err != nil {
+			return fmt.Errorf("%w: value must be an object: %w", ErrInvalidValue, err)
+		}
+
+		for path, value := range values {
+			if err := applyPath(u, kind, path, value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return applyPath(u, kind, op.Path, op.Value)
+}
+
+//nolint:cyclop,gocognit // плоский перечень атрибутов читается проще, чем таблица
+func applyPath(u *User, kind, path string, value json.RawMessage) error {
+	remove := kind == opRemove
+
+	switch strings.ToLower(strings.TrimPrefix(path, SchemaUser+":")) {
+	case "active":
+		if remove {
+			return fmt.Errorf("%w: active is required", ErrMutability)
+		}
+
+		active, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+
+		u.Active = active
+	case "username":
+		if remove {
+			return fmt.Errorf("%w: userName is required", ErrMutability)
+		}
+
+		return decodeString(value, &u.UserName)
+	case "id":
+		return fmt.Errorf("%w: id", ErrMutability)
+	case "externalid":
+		return setString(remove, value, &u.ExternalID)
+	case "displayname":
+		return setString(remove, value, &u.DisplayName)
+	case "name":
+		if remove {
+			u.Name = nil
+
+			return nil
+		}
+
+		var name Name
+		if err := json.Unmarshal(value, &name); err != nil {
+			return fmt.Errorf("%w: name: %w", ErrInvalidValue, err)
+		}
+
+		u.Name = &name
+	case "name.givenname":
+		return setString(remove, value, &ensureName(u).GivenName)
+	case "name.familyname":
+		return setString(remove, value, &ensureName(u).FamilyName)
+	case "name.formatted":
+		return setString(remove, value, &ensureName(u).Formatted)
+	case "emails":
+		if remove {
+			u.Emails = nil
+
+			return nil
+		}
+
+		var emails []Email
+		if err := json.Unmarshal(value, &emails); err != nil {
+			return fmt.Errorf("%w: emails: %w", ErrInvalidValue, err)
+		}
+
+		if kind == opAdd {
+			u.Emails = append(u.Emails, emails...)
+		} else {
+			u.Emails = emails
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidPath, path)
+	}
+
+	return nil
+}
+
+func ensureName(u *User) *Name {
+	if u.Name == nil {
+		u.Name = &Name{}
+	}
+
+	return u.Name
+}
+
+func setString(remove bool, value json.RawMessage, dst *string) error {
+	if remove {
+		*dst = ""
+
+		return nil
+	}
+
+	return decodeString(value, dst)
+}
+
+func decodeString(value json.RawMessage, dst *string) error {
+	if err := json.Unmarshal(value, dst); err != nil {
+		return fmt.Errorf("%w: expected string: %w", ErrInvalidValue, err)
+	}
+
+	return nil
+}
+
+// parseBool принимает true/false и строки "True"/"False".
+func parseBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+
+	var str string
+	if err := json.Unmarshal(value, &str); err == nil {
+		if b, err := strconv.ParseBool(str); err == nil {
+			return b, nil
+		}
+	}
+
+	return false, fmt.Errorf("%w: expected boolean, got %s", ErrInvalidValue, value)
+}
+
+// parseFilter разбирает фильтр вида `userName eq "value"` и возвращает атрибут в нижнем регистре и значение.
+func parseFilter(filter string) (string, string, error) {
+	attr, rest, ok := strings.Cut(strings.TrimSpace(filter), " ")
+	if !ok {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidFilter, filter)
+	}
+
+	op, quoted, ok := strings.Cut(strings.TrimSpace(rest), " ")
+	if !ok || !strings.EqualFold(op, "eq") {
+		return "", "", fmt.Errorf("%w: only eq is supported", ErrInvalidFilter)
+	}
+
+	attr = strings.ToLower(attr)
+	if attr != "username" && attr != "externalid" {
+		return "", "", fmt.Errorf("%w: only userName and externalId are supported", ErrInvalidFilter)
+	}
+
+	value, err := strconv.Unquote(strings.TrimSpace(quoted))
+	if err != nil {
+		return "", "", fmt.Errorf("%w: value must be a quoted string", ErrInvalidFilter)
+	}
+
+	return attr, value, nil
+}
@@ -0,0 +1,586 @@
+// Package scim хранит учетные записи администраторов, которые заводит и отключает корпоративный
+// IdP по протоколу SCIM 2.0 (RFC 7643, RFC 7644). Поддерживается ресурс User: создание, чтение,
+// поиск по userName и externalId, замена, PATCH и удаление.
+package scim
+
+import (
+	"auth-service/internal/service/id"
+	"auth-service/internal/service/revocation"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Схемы SCIM.
+const (
+	SchemaUser         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaListResponse = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp      = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError        = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+const (
+	keyPrefix = "auth:scim:"
+
+	// idLength - длина ID учетной записи.
+	idLength = 32
+
+	// DefaultPageSize - размер страницы поиска по умолчанию.
+	DefaultPageSize = 100
+	// MaxPageSize - максимальный размер страницы поиска.
+	MaxPageSize = 500
+
+	resourceType = "User"
+
+	// deactivationSource - источник отключений пользователей через SCIM.
+	deactivationSource = "scim"
+)
+
+// Ошибки соответствуют scimType из RFC 7644, раздел 3.12.
+var (
+	// ErrNotFound - учетная запись не найдена.
+	ErrNotFound = errors.New("user not found")
+	// ErrUniqueness - userName уже занят другой учетной записью.
+	ErrUniqueness = errors.New("userName is already taken")
+	// ErrInvalidValue - не заполнены обязательные атрибуты или значение неверного типа.
+	ErrInvalidValue = errors.New("invalid value")
+	// ErrInvalidFilter - фильтр поиска не поддерживается.
+	ErrInvalidFilter = errors.New("invalid filter")
+	// ErrInvalidPath - путь атрибута в PATCH не поддерживается.
+	ErrInvalidPath = errors.New("invalid path")
+	// ErrMutability - попытка изменить неизменяемый атрибут.
+	ErrMutability = errors.New("attribute is immutable")
+)
+
+// Name - имя пользователя.
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// Email - адрес почты пользователя.
+type Email struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// Meta - служебные атрибуты ресурса.
+type Meta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+// User - учетная запись администратора.
+type User struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id"`
+	ExternalID  string   `json:"externalId,omitempty"`
+	UserName    string   `json:"userName"`
+	Name        *Name    `json:"name,omitempty"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Emails      []Email  `json:"emails,omitempty"`
+	Active      bool     `json:"active"`
+	Meta        Meta     `json:"meta"`
+}
+
+// ListResponse - результат поиска.
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []User   `json:"Resources"`
+}
+
+// ParseUser разбирает тело запроса на создание или замену. Если active не передан, учетная запись активна.
+func ParseUser(data []byte) (*User, error) {
+	u := &User{Active: true}
+
+	if err := json.Unmarshal(data, u); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidValue, err)
+	}
+
+	return u, nil
+}
+
+//go:generate mockgen -source=scim.go -destination=mocks/scim_mock.go -package=mocks
+type deactivator interface {
+	Deactivate(ctx context.Context, subject, source string) (*revocation.Deactivation, error)
+	Reactivate(ctx context.Context, subject, source string) error
+}
+
+// Service - учетные записи SCIM.
+//
+// Ключи:
+//   - auth:scim:user:<id> - учетная запись в JSON;
+//   - auth:scim:username:<userName в нижнем регистре> - ID учетной записи;
+//   - auth:scim:users - множество ID всех учетных записей.
+type Service struct {
+	client redis.UniversalClient
+
+	// отключение пользователей: отзыв токенов и событие для других сервисов
+	deactivations deactivator
+	subject       func(userName string) string
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Service.
+type Option func(*Service)
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(s *Service) {
+		s.client = client
+	}
+}
+
+// WithDeactivations включает отзыв токенов отключенных и удаленных пользователей.
+// subject возвращает субъект токенов пользователя по userName.
+func WithDeactivations(deactivations deactivator, subject func(userName string) string) Option {
+	return func(s *Service) {
+		s.deactivations = deactivations
+		s.subject = subject
+	}
+}
+
+// New создает новый Service.
+func New(opts ...Option) (*Service, error) {
+	s := &Service{
+		subject: func(userName string) string { return userName },
+		now:     time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.client == nil {
+		return nil, errors.New("redis client is required")
+	}
+
+	return s, nil
+}
+
+func userKey(userID string) string {
+	return keyPrefix + "user:" + userID
+}
+
+// userNameKey - userName уникален без учета регистра (RFC 7643, раздел 4.1.1).
+func userNameKey(userName string) string {
+	return keyPrefix + "username:" + strings.ToLower(userName)
+}
+
+func usersKey() string {
+	return keyPrefix + "users"
+}
+
+func validate(u *User) error {
+	if strings.TrimSpace(u.UserName) == "" {
+		return fmt.Errorf("%w: userName is required", ErrInvalidValue)
+	}
+
+	return nil
+}
+
+// Create создает учетную запись.
+func (s *Service) Create(ctx context.Context, u *User) (*User, error) {
+	if err := validate(u); err != nil {
+		return nil, err
+	}
+
+	userID, err := id.Generate(idLength)
+	if err != nil {
+		return nil, fmt.Errorf("scim: error generate id: %w", err)
+	}
+
+	now := s.now().UTC().Truncate(time.Second)
+
+	created := *u
+	created.Schemas = []string{SchemaUser}
+	created.ID = userID
+	created.Meta = Meta{ResourceType: resourceType, Created: now, LastModified: now}
+
+	if err := s.save(ctx, nil, &created); err != nil {
+		return nil, err
+	}
+
+	if err := s.sync(ctx, &created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// Get возвращает учетную запись по ID.
+func (s *Service) Get(ctx context.Context, userID string) (*User, error) {
+	return s.get(ctx, s.client, userID)
+}
+
+func (s *Service) get(ctx context.Context, c redis.Cmdable, userID string) (*User, error) {
+	data, err := c.Get(ctx, userKey(userID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("scim: error get user: %w", err)
+	}
+
+	var u User
+	if err := json.Unmarshal(data, &u); err != nil {
+		return nil, fmt.Errorf("scim: error decode user %s: %w", userID, err)
+	}
+
+	return &u, nil
+}
+
+// FindByUserName возвращает учетную запись по userName без учета регистра.
+func (s *Service) FindByUserName(ctx context.Context, userName string) (*User, error) {
+	userID, err := s.client.Get(ctx, userNameKey(userName)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("scim: error get user name: %w", err)
+	}
+
+	return s.Get(ctx, userID)
+}
+
+// Active возвращает true, если учетная запись с userName заведена и активна.
+func (s *Service) Active(ctx context.Context, userName string) (bool, error) {
+	u, err := s.FindByUserName(ctx, userName)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return u.Active, nil
+}
+
+// Replace заменяет учетную запись (PUT). ID и время создания сохраняются.
+func (s *Service) Replace(ctx context.Context, userID string, u *User) (*User, error) {
+	if err := validate(u); err != nil {
+		return nil, err
+	}
+
+	return s.update(ctx, userID, func(prev *User) (*User, error) {
+		next := *u
+		next.Schemas = []string{SchemaUser}
+		next.ID = prev.ID
+		next.Meta = prev.Meta
+
+		return &next, nil
+	})
+}
+
+// Patch изменяет учетную запись операциями PATCH (RFC 7644, раздел 3.5.2).
+func (s *Service) Patch(ctx context.Context, userID string, req PatchRequest) (*User, error) {
+	if len(req.Operations) == 0 {
+		return nil, fmt.Errorf("%w: no operations", ErrInvalidValue)
+	}
+
+	return s.update(ctx, userID, func(prev *User) (*User, error) {
+		next := *prev
+		next.Emails = slices.Clone(prev.Emails)
+
+		if prev.Name != nil {
+			name := *prev.Name
+			next.Name = &name
+		}
+
+		for _, op := range req.Operations {
+			if err := applyOperation(&next, op); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := validate(&next); err != nil {
+			return nil, err
+		}
+
+		return &next, nil
+	})
+}
+
+// update читает учетную запись, изменяет ее и сохраняет, если ее не изменили параллельно.
+func (s *Service) update(ctx context.Context, userID string, change func(prev *User) (*User, error)) (*User, error) {
+	var updated *User
+
+	err := s.client.Watch(ctx, func(tx *redis.Tx) error {
+		prev, err := s.get(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		next, err := change(prev)
+		if err != nil {
+			return err
+		}
+
+		next.Meta.LastModified = s.now().UTC().Truncate(time.Second)
+
+		if err := s.write(ctx, tx, prev, next); err != nil {
+			return err
+		}
+
+		updated = next
+
+		return nil
+	}, userKey(userID))
+	if errors.Is(err, redis.TxFailedErr) {
+		return nil, fmt.Errorf("scim: user %s was modified concurrently: %w", userID, err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sync(ctx, updated); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// sync отключает или включает пользователя по атрибуту active. Вызывается после каждого изменения:
+// если отключение не удалось, IdP повторит запрос и отключение выполнится при повторе.
+func (s *Service) sync(ctx context.Context, u *User) error {
+	if s.deactivations == nil {
+		return nil
+	}
+
+	subject := s.subject(u.UserName)
+
+	if u.Active {
+		if err := s.deactivations.Reactivate(ctx, subject, deactivationSource); err != nil {
+			return fmt.Errorf("scim: error reactivate user: %w", err)
+		}
+
+		return nil
+	}
+
+	if _, err := s.deactivations.Deactivate(ctx, subject, deactivationSource); err != nil {
+		return fmt.Errorf("scim: error deactivate user: %w", err)
+	}
+
+	return nil
+}
+
+// InactiveSubjects возвращает субъекты токенов отключенных пользователей для проверки согласованности.
+func (s *Service) InactiveSubjects(ctx context.Context) ([]string, error) {
+	users, err := s.all(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subjects := make([]string, 0, len(users))
+
+	for _, u := range users {
+		if !u.Active {
+			subjects = append(subjects, s.subject(u.UserName))
+		}
+	}
+
+	return subjects, nil
+}
+
+// save сохраняет новую учетную запись.
+func (s *Service) save(ctx context.Context, prev, next *User) error {
+	err := s.client.Watch(ctx, func(tx *redis.Tx) error {
+		return s.write(ctx, tx, prev, next)
+	})
+	if errors.Is(err, redis.TxFailedErr) {
+		return ErrUniqueness
+	}
+
+	return err
+}
+
+// write записывает учетную запись и обновляет индекс userName. Ключ нового userName
+// ставится под WATCH, чтобы два запроса не заняли одно имя.
+func (s *Service) write(ctx context.Context, tx *redis.Tx, prev, next *User) error {
+	newKey := userNameKey(next.UserName)
+
+	if err := tx.Watch(ctx, newKey).Err(); err != nil {
+		return fmt.Errorf("scim: error watch user name: %w", err)
+	}
+
+	owner, err := tx.Get(ctx, newKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("scim: error get user name: %w", err)
+	}
+
+	if owner != "" && owner != next.ID {
+		return ErrUniqueness
+	}
+
+	data, err := json.Marshal(next)
+	if err != nil {
+		return fmt.Errorf("scim: error encode user: %w", err)
+	}
+
+	_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		if prev != nil && userNameKey(prev.UserName) != newKey {
+			p.Del(ctx, userNameKey(prev.UserName))
+		}
+
+		p.Set(ctx, newKey, next.ID, 0)
+		p.Set(ctx, userKey(next.ID), data, 0)
+		p.SAdd(ctx, usersKey(), next.ID)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("scim: error save user: %w", err)
+	}
+
+	return nil
+}
+
+// Delete удаляет учетную запись. Токены удаленного пользователя отзываются, как при отключении.
+func (s *Service) Delete(ctx context.Context, userID string) (*User, error) {
+	u, err := s.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// отключение до удаления: если удаление не выполнится, IdP повторит его
+	if err := s.sync(ctx, &User{UserName: u.UserName}); err != nil {
+		return nil, err
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.Del(ctx, userKey(userID), userNameKey(u.UserName))
+		p.SRem(ctx, usersKey(), userID)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scim: error delete user: %w", err)
+	}
+
+	return u, nil
+}
+
+// List ищет учетные записи. Поддерживаются фильтры userName eq "..." и externalId eq "...",
+// которыми IdP проверяют наличие пользователя перед созданием. startIndex считается с 1.
+func (s *Service) List(ctx context.Context, filter string, startIndex, count int) (*ListResponse, error) {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+
+	if count <= 0 {
+		count = DefaultPageSize
+	}
+
+	count = min(count, MaxPageSize)
+
+	users, err := s.find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: len(users),
+		StartIndex:   startIndex,
+		Resources:    []User{},
+	}
+
+	if startIndex <= len(users) {
+		resp.Resources = users[startIndex-1 : min(startIndex-1+count, len(users))]
+	}
+
+	resp.ItemsPerPage = len(resp.Resources)
+
+	return resp, nil
+}
+
+func (s *Service) find(ctx context.Context, filter string) ([]User, error) {
+	if filter != "" {
+		attr, value, err := parseFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+
+		if attr == "username" {
+			u, err := s.FindByUserName(ctx, value)
+			if errors.Is(err, ErrNotFound) {
+				return nil, nil
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			return []User{*u}, nil
+		}
+
+		all, err := s.all(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return slices.DeleteFunc(all, func(u User) bool { return u.ExternalID != value }), nil
+	}
+
+	return s.all(ctx)
+}
+
+// all возвращает все учетные записи в порядке ID. Учетных записей администраторов немного,
+// поэтому они читаются целиком.
+func (s *Service) all(ctx context.Context) ([]User, error) {
+	ids, err := s.client.SMembers(ctx, usersKey()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("scim: error list users: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	slices.Sort(ids)
+
+	keys := make([]string, 0, len(ids))
+	for _, userID := range ids {
+		keys = append(keys, userKey(userID))
+	}
+
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("scim: error get users: %w", err)
+	}
+
+	users := make([]User, 0, len(values))
+
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		var u User
+		if err := json.Unmarshal([]byte(data), &u); err != nil {
+			return nil, fmt.Errorf("scim: error decode user: %w", err)
+		}
+
+		users = append(users, u)
+	}
+
+	return users, nil
+}
@@ -0,0 +1,438 @@
+package scim
+
+import (
+	"auth-service/internal/service/revocation"
+	"auth-service/internal/service/scim/mocks"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	t.Cleanup(func() { _ = client.Close() })
+
+	s, err := New(WithClient(client))
+	require.NoError(t, err)
+
+	s.now = func() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	return s
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	_, err := New()
+	require.Error(t, err)
+}
+
+func TestParseUser(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantActive bool
+		wantErr    bool
+	}{
+		{name: "active by default", body: `{"userName":"alice"}`, wantActive: true},
+		{name: "inactive", body: `{"userName":"alice","active":false}`, wantActive: false},
+		{name: "invalid json", body: `{`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			u, err := ParseUser([]byte(tt.body))
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidValue)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantActive, u.Active)
+		})
+	}
+}
+
+func TestService_Create(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	ctx := context.Background()
+
+	created, err := s.Create(ctx, &User{UserName: "Alice", ExternalID: "ext-1", Active: true})
+	require.NoError(t, err)
+	assert.Len(t, created.ID, idLength)
+	assert.Equal(t, []string{SchemaUser}, created.Schemas)
+	assert.Equal(t, "User", created.Meta.ResourceType)
+	assert.Equal(t, s.now(), created.Meta.Created)
+
+	got, err := s.Get(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created, got)
+
+	_, err = s.Create(ctx, &User{UserName: "alice"})
+	require.ErrorIs(t, err, ErrUniqueness, "userName уникален без учета регистра")
+
+	_, err = s.Create(ctx, &User{UserName: " "})
+	require.ErrorIs(t, err, ErrInvalidValue)
+
+	_, err = s.Get(ctx, "missing")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestService_Active(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	ctx := context.Background()
+
+	_, err := s.Create(ctx, &User{UserName: "alice", Active: true})
+	require.NoError(t, err)
+
+	_, err = s.Create(ctx, &User{UserName: "bob", Active: false})
+	require.NoError(t, err)
+
+	tests := []struct {
+		userName string
+		want     bool
+	}{
+		{userName: "alice", want: true},
+		{userName: "ALICE", want: true},
+		{userName: "bob", want: false},
+		{userName: "carol", want: false},
+	}
+
+	for _, tt := range tests {
+		active, err := s.Active(ctx, tt.userName)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, active, tt.userName)
+	}
+}
+
+func TestService_Replace(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	ctx := context.Background()
+
+	alice, err := s.Create(ctx, &User{UserName: "alice", Active: true})
+	require.NoError(t, err)
+
+	_, err = s.Create(ctx, &User{UserName: "bob", Active: true})
+	require.NoError(t, err)
+
+	replaced, err := s.Replace(ctx, alice.ID, &User{ID: "ignored", UserName: "alice2", DisplayName: "Alice", Active: false})
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, replaced.ID)
+	assert.Equal(t, alice.Meta.Created, replaced.Meta.Created)
+	assert.Equal(t, "Alice", replaced.DisplayName)
+	assert.False(t, replaced.Active)
+
+	_, err = s.FindByUserName(ctx, "alice")
+	require.ErrorIs(t, err, ErrNotFound, "старое имя освобождается")
+
+	found, err := s.FindByUserName(ctx, "alice2")
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, found.ID)
+
+	_, err = s.Replace(ctx, alice.ID, &User{UserName: "bob"})
+	require.ErrorIs(t, err, ErrUniqueness)
+
+	_, err = s.Replace(ctx, "missing", &User{UserName: "carol"})
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService_Patch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		ops     []PatchOperation
+		check   func(t *testing.T, u *User)
+		wantErr error
+	}{
+		{
+			name: "deactivate",
+			ops:  []PatchOperation{{Op: "replace", Path: "active", Value: json.RawMessage(`false`)}},
+			check: func(t *testing.T, u *User) {
+				t.Helper()
+				assert.False(t, u.Active)
+			},
+		},
+		{
+			name: "deactivate with string value",
+			ops:  []PatchOperation{{Op: "Replace", Path: "active", Value: json.RawMessage(`"False"`)}},
+			check: func(t *testing.T, u *User) {
+				t.Helper()
+				assert.False(t, u.Active)
+			},
+		},
+		{
+			name: "replace without path",
+			ops:  []PatchOperation{{Op: "replace", Value: json.RawMessage(`{"active":false,"displayName":"Alice"}`)}},
+			check: func(t *testing.T, u *User) {
+				t.Helper()
+				assert.False(t, u.Active)
+				assert.Equal(t, "Alice", u.DisplayName)
+			},
+		},
+		{
+			name: "name and emails",
+			ops: []PatchOperation{
+				{Op: "add", Path: "name.givenName", Value: json.RawMessage(`"Alice"`)},
+				{Op: "add", Path: "emails", Value: json.RawMessage(`[{"value":"alice@example.com","primary":true}]`)},
+				{Op: "remove", Path: "externalId"},
+			},
+			check: func(t *testing.T, u *User) {
+				t.Helper()
+				assert.Equal(t, &Name{GivenName: "Alice"}, u.Name)
+				assert.Equal(t, []Email{{Value: "alice@example.com", Primary: true}}, u.Emails)
+				assert.Empty(t, u.ExternalID)
+			},
+		},
+		{
+			name: "full attribute path",
+			ops:  []PatchOperation{{Op: "replace", Path: SchemaUser + ":userName", Value: json.RawMessage(`"alice2"`)}},
+			check: func(t *testing.T, u *User) {
+				t.Helper()
+				assert.Equal(t, "alice2", u.UserName)
+			},
+		},
+		{
+			name:    "rename to taken name",
+			ops:     []PatchOperation{{Op: "replace", Path: "userName", Value: json.RawMessage(`"BOB"`)}},
+			wantErr: ErrUniqueness,
+		},
+		{
+			name:    "unknown path",
+			ops:     []PatchOperation{{Op: "replace", Path: "password", Value: json.RawMessage(`"x"`)}},
+			wantErr: ErrInvalidPath,
+		},
+		{
+			name:    "remove without path",
+			ops:     []PatchOperation{{Op: "remove"}},
+			wantErr: ErrInvalidPath,
+		},
+		{
+			name:    "unknown op",
+			ops:     []PatchOperation{{Op: "move", Path: "active"}},
+			wantErr: ErrInvalidValue,
+		},
+		{
+			name:    "active is not boolean",
+			ops:     []PatchOperation{{Op: "replace", Path: "active", Value: json.RawMessage(`"maybe"`)}},
+			wantErr: ErrInvalidValue,
+		},
+		{
+			name:    "remove userName",
+			ops:     []PatchOperation{{Op: "remove", Path: "userName"}},
+			wantErr: ErrMutability,
+		},
+		{
+			name:    "no operations",
+			wantErr: ErrInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := newTestService(t)
+			ctx := context.Background()
+
+			alice, err := s.Create(ctx, &User{UserName: "alice", ExternalID: "ext-1", Active: true})
+			require.NoError(t, err)
+
+			_, err = s.Create(ctx, &User{UserName: "bob", Active: true})
+			require.NoError(t, err)
+
+			patched, err := s.Patch(ctx, alice.ID, PatchRequest{Schemas: []string{SchemaPatchOp}, Operations: tt.ops})
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				got, err := s.Get(ctx, alice.ID)
+				require.NoError(t, err)
+				assert.Equal(t, alice, got, "при ошибке учетная запись не меняется")
+
+				return
+			}
+
+			require.NoError(t, err)
+			tt.check(t, patched)
+
+			got, err := s.Get(ctx, alice.ID)
+			require.NoError(t, err)
+			assert.Equal(t, patched, got)
+		})
+	}
+}
+
+func TestService_Delete(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	ctx := context.Background()
+
+	alice, err := s.Create(ctx, &User{UserName: "alice", Active: true})
+	require.NoError(t, err)
+
+	_, err = s.Delete(ctx, alice.ID)
+	require.NoError(t, err)
+
+	_, err = s.Get(ctx, alice.ID)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	active, err := s.Active(ctx, "alice")
+	require.NoError(t, err)
+	assert.False(t, active)
+
+	_, err = s.Delete(ctx, alice.ID)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = s.Create(ctx, &User{UserName: "alice"})
+	require.NoError(t, err, "имя удаленной учетной записи свободно")
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService_List(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	ctx := context.Background()
+
+	for _, u := range []User{
+		{UserName: "alice", ExternalID: "ext-1"},
+		{UserName: "bob", ExternalID: "ext-2"},
+		{UserName: "carol", ExternalID: "ext-2"},
+	} {
+		_, err := s.Create(ctx, &u)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name       string
+		filter     string
+		startIndex int
+		count      int
+		wantTotal  int
+		wantNames  []string
+		wantErr    error
+	}{
+		{name: "all", wantTotal: 3, wantNames: []string{"alice", "bob", "carol"}},
+		{name: "by userName", filter: `userName eq "ALICE"`, wantTotal: 1, wantNames: []string{"alice"}},
+		{name: "by missing userName", filter: `userName eq "dave"`, wantTotal: 0, wantNames: []string{}},
+		{name: "by externalId", filter: `externalId eq "ext-2"`, wantTotal: 2, wantNames: []string{"bob", "carol"}},
+		{name: "page", startIndex: 2, count: 1, wantTotal: 3, wantNames: []string{"x"}},
+		{name: "page out of range", startIndex: 10, wantTotal: 3, wantNames: []string{}},
+		{name: "unsupported operator", filter: `userName co "a"`, wantErr: ErrInvalidFilter},
+		{name: "unsupported attribute", filter: `displayName eq "a"`, wantErr: ErrInvalidFilter},
+		{name: "unquoted value", filter: `userName eq alice`, wantErr: ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp, err := s.List(ctx, tt.filter, tt.startIndex, tt.count)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, []string{SchemaListResponse}, resp.Schemas)
+			assert.Equal(t, tt.wantTotal, resp.TotalResults)
+			assert.Len(t, resp.Resources, len(tt.wantNames))
+			assert.Equal(t, len(tt.wantNames), resp.ItemsPerPage)
+
+			// порядок определяется случайными ID, поэтому для страницы проверяется только размер
+			if tt.count == 0 && len(tt.wantNames) > 0 {
+				names := make([]string, 0, len(resp.Resources))
+				for _, u := range resp.Resources {
+					names = append(names, u.UserName)
+				}
+
+				assert.ElementsMatch(t, tt.wantNames, names)
+			}
+		})
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService_Deactivations(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	ctx := context.Background()
+
+	deactivations := mocks.NewMockdeactivator(gomock.NewController(t))
+	s.deactivations = deactivations
+	s.subject = func(userName string) string { return "ldap:" + userName }
+
+	deactivations.EXPECT().Reactivate(gomock.Any(), "ldap:alice", "scim").Return(nil)
+
+	alice, err := s.Create(ctx, &User{UserName: "alice", Active: true})
+	require.NoError(t, err)
+
+	deactivations.EXPECT().Deactivate(gomock.Any(), "ldap:bob", "scim").Return(&revocation.Deactivation{}, nil)
+
+	_, err = s.Create(ctx, &User{UserName: "bob", Active: false})
+	require.NoError(t, err)
+
+	subjects, err := s.InactiveSubjects(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ldap:bob"}, subjects)
+
+	// отключение через PATCH отзывает токены
+	deactivations.EXPECT().Deactivate(gomock.Any(), "ldap:alice", "scim").Return(&revocation.Deactivation{}, nil)
+
+	_, err = s.Patch(ctx, alice.ID, PatchRequest{Operations: []PatchOperation{
+		{Op: "replace", Path: "active", Value: json.RawMessage(`false`)},
+	}})
+	require.NoError(t, err)
+
+	// ошибка отключения возвращается, чтобы IdP повторил запрос
+	deactivations.EXPECT().Deactivate(gomock.Any(), "ldap:alice", "scim").Return(nil, errors.New("redis is down"))
+
+	_, err = s.Patch(ctx, alice.ID, PatchRequest{Operations: []PatchOperation{
+		{Op: "replace", Path: "displayName", Value: json.RawMessage(`"Alice"`)},
+	}})
+	require.Error(t, err)
+
+	deactivations.EXPECT().Reactivate(gomock.Any(), "ldap:alice", "scim").Return(nil)
+
+	_, err = s.Replace(ctx, alice.ID, &User{UserName: "alice", Active: true})
+	require.NoError(t, err)
+
+	// удаление отзывает токены до удаления учетной записи
+	deactivations.EXPECT().Deactivate(gomock.Any(), "ldap:alice", "scim").Return(nil, errors.New("redis is down"))
+
+	_, err = s.Delete(ctx, alice.ID)
+	require.Error(t, err)
+
+	_, err = s.Get(ctx, alice.ID)
+	require.NoError(t, err)
+
+	deactivations.EXPECT().Deactivate(gomock.Any(), "ldap:alice", "scim").Return(&revocation.Deactivation{}, nil)
+
+	_, err = s.Delete(ctx, alice.ID)
+	require.NoError(t, err)
+}
@@ -0,0 +1,138 @@
+// Package securitytxt формирует файл /.well-known/security.txt (RFC 9116) с контактами
+// для сообщений об уязвимостях.
+package securitytxt
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Path - путь, по которому отдается файл.
+const Path = "/.well-known/security.txt"
+
+// File - содержимое security.txt.
+type File struct {
+	contacts           []string
+	expires            time.Time
+	encryption         string
+	acknowledgments    string
+	preferredLanguages []string
+	canonical          string
+	policy             string
+
+	body string
+}
+
+// Option - опция для настройки File.
+type Option func(*File)
+
+// WithContacts устанавливает контакты для сообщений об уязвимостях (mailto:, tel: или https:// URI).
+func WithContacts(contacts []string) Option {
+	return func(f *File) {
+		f.contacts = contacts
+	}
+}
+
+// WithExpires устанавливает момент, после которого данные файла считаются устаревшими.
+func WithExpires(expires time.Time) Option {
+	return func(f *File) {
+		f.expires = expires
+	}
+}
+
+// WithEncryption устанавливает ссылку на ключ для шифрования сообщений.
+func WithEncryption(encryption string) Option {
+	return func(f *File) {
+		f.encryption = encryption
+	}
+}
+
+// WithAcknowledgments устанавливает ссылку на страницу благодарностей.
+func WithAcknowledgments(acknowledgments string) Option {
+	return func(f *File) {
+		f.acknowledgments = acknowledgments
+	}
+}
+
+// WithPreferredLanguages устанавливает предпочитаемые языки сообщений.
+func WithPreferredLanguages(languages []string) Option {
+	return func(f *File) {
+		f.preferredLanguages = languages
+	}
+}
+
+// WithCanonical устанавливает канонический адрес файла.
+func WithCanonical(canonical string) Option {
+	return func(f *File) {
+		f.canonical = canonical
+	}
+}
+
+// WithPolicy устанавливает ссылку на политику раскрытия уязвимостей.
+func WithPolicy(policy string) Option {
+	return func(f *File) {
+		f.policy = policy
+	}
+}
+
+// New создает новый File. Контакты и срок действия обязательны.
+func New(opts ...Option) (*File, error) {
+	f := &File{}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	if len(f.contacts) == 0 {
+		return nil, errors.New("at least one contact is required")
+	}
+
+	if f.expires.IsZero() {
+		return nil, errors.New("expires is required")
+	}
+
+	f.body = f.render()
+
+	return f, nil
+}
+
+// render формирует текст файла.
+func (f *File) render() string {
+	var b strings.Builder
+
+	for _, contact := range f.contacts {
+		writeField(&b, "Contact", contact)
+	}
+
+	writeField(&b, "Expires", f.expires.UTC().Format(time.RFC3339))
+	writeField(&b, "Encryption", f.encryption)
+	writeField(&b, "Acknowledgments", f.acknowledgments)
+	writeField(&b, "Preferred-Languages", strings.Join(f.preferredLanguages, ", "))
+	writeField(&b, "Canonical", f.canonical)
+	writeField(&b, "Policy", f.policy)
+
+	return b.String()
+}
+
+// writeField записывает поле, если значение задано.
+func writeField(b *strings.Builder, name, value string) {
+	if value == "" {
+		return
+	}
+
+	b.WriteString(name)
+	b.WriteString(": ")
+	b.WriteString(value)
+	b.WriteString("\n")
+}
+
+// String возвращает текст файла.
+func (f *File) String() string {
+	return f.body
+}
+
+// Expired сообщает, истек ли срок действия файла к моменту now.
+func (f *File) Expired(now time.Time) bool {
+	return !now.Before(f.expires)
+}
@@ -0,0 +1,89 @@
+package securitytxt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	expires := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "positive case: required fields only",
+			opts: []Option{
+				WithContacts([]string{"mailto:security@example.com"}),
+				WithExpires(expires),
+			},
+			want: "Contact: mailto:security@example.com\nExpires: 2027-01-01T00:00:00Z\n",
+		},
+		{
+			name: "positive case: all fields",
+			opts: []Option{
+				WithContacts([]string{"mailto:security@example.com", "https://example.com/security"}),
+				WithExpires(time.Date(2027, 1, 1, 3, 0, 0, 0, time.FixedZone("MSK", 3*60*60))),
+				WithEncryption("https://example.com/pgp-key.txt"),
+				WithAcknowledgments("https://example.com/hall-of-fame"),
+				WithPreferredLanguages([]string{"ru", "en"}),
+				WithCanonical("https://auth.example.com/.well-known/security.txt"),
+				WithPolicy("https://example.com/disclosure-policy"),
+			},
+			want: "Contact: mailto:security@example.com\n" +
+				"Contact: https://example.com/security\n" +
+				"Expires: 2027-01-01T00:00:00Z\n" +
+				"Encryption: https://example.com/pgp-key.txt\n" +
+				"Acknowledgments: https://example.com/hall-of-fame\n" +
+				"Preferred-Languages: ru, en\n" +
+				"Canonical: https://auth.example.com/.well-known/security.txt\n" +
+				"Policy: https://example.com/disclosure-policy\n",
+		},
+		{
+			name:    "error case: no contacts",
+			opts:    []Option{WithExpires(expires)},
+			wantErr: true,
+		},
+		{
+			name:    "error case: no expires",
+			opts:    []Option{WithContacts([]string{"mailto:security@example.com"})},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := New(tt.opts...)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, f.String())
+		})
+	}
+}
+
+func TestFile_Expired(t *testing.T) {
+	t.Parallel()
+
+	expires := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	f, err := New(WithContacts([]string{"mailto:security@example.com"}), WithExpires(expires))
+	require.NoError(t, err)
+
+	assert.False(t, f.Expired(expires.Add(-time.Second)))
+	assert.True(t, f.Expired(expires))
+}
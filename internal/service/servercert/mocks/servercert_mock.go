@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: servercert.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	vault "auth-service/internal/storage/vault"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockcertificateIssuer is a mock of certificateIssuer interface.
+type MockcertificateIssuer struct {
+	ctrl     *gomock.Controller
+	recorder *MockcertificateIssuerMockRecorder
+}
+
+// MockcertificateIssuerMockRecorder is the mock recorder for MockcertificateIssuer.
+type MockcertificateIssuerMockRecorder struct {
+	mock *MockcertificateIssuer
+}
+
+// NewMockcertificateIssuer creates a new mock instance.
+func NewMockcertificateIssuer(ctrl *gomock.Controller) *MockcertificateIssuer {
+	mock := &MockcertificateIssuer{ctrl: ctrl}
+	mock.recorder = &MockcertificateIssuerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockcertificateIssuer) EXPECT() *MockcertificateIssuerMockRecorder {
+	return m.recorder
+}
+
+// IssueCertificate mocks base method.
+func (m *MockcertificateIssuer) IssueCertificate(ctx context.Context, path string, req vault.IssueRequest) (*vault.Certificate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueCertificate", ctx, path, req)
+	ret0, _ := ret[0].(*vault.Certificate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueCertificate indicates an expected call of IssueCertificate.
+func (mr *MockcertificateIssuerMockRecorder) IssueCertificate(ctx, path, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueCertificate", reflect.TypeOf((*MockcertificateIssuer)(nil).IssueCertificate), ctx, path, req)
+}
@@ -0,0 +1,189 @@
+// Package servercert получает TLS сертификат API сервера из Vault PKI и продлевает его
+// до истечения. Новый сертификат подменяется на лету: уже установленные соединения
+// продолжают работать, новые получают свежий сертификат.
+package servercert
+
+import (
+	"auth-service/internal/storage/vault"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultRetryInterval - пауза перед повторным запросом сертификата после ошибки.
+	DefaultRetryInterval = 30 * time.Second
+
+	// renewFraction - доля срока действия, после которой сертификат продлевается.
+	renewFraction = 2.0 / 3
+)
+
+//go:generate mockgen -source=servercert.go -destination=mocks/servercert_mock.go -package=mocks
+type certificateIssuer interface {
+	IssueCertificate(ctx context.Context, path string, req vault.IssueRequest) (*vault.Certificate, error)
+}
+
+// Manager - выпуск и продление сертификата сервера.
+type Manager struct {
+	issuer        certificateIssuer
+	path          string
+	req           vault.IssueRequest
+	retryInterval time.Duration
+
+	current atomic.Pointer[tls.Certificate]
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Manager.
+type Option func(*Manager)
+
+// WithIssuer устанавливает Vault и путь роли PKI, например pki_int/issue/auth-service.
+func WithIssuer(issuer certificateIssuer, path string) Option {
+	return func(m *Manager) {
+		m.issuer = issuer
+		m.path = path
+	}
+}
+
+// WithRequest устанавливает параметры выпускаемого сертификата.
+func WithRequest(req vault.IssueRequest) Option {
+	return func(m *Manager) {
+		m.req = req
+	}
+}
+
+// WithRetryInterval устанавливает паузу перед повторным запросом после ошибки.
+func WithRetryInterval(d time.Duration) Option {
+	return func(m *Manager) {
+		m.retryInterval = d
+	}
+}
+
+// New создает новый Manager.
+func New(opts ...Option) (*Manager, error) {
+	m := &Manager{
+		retryInterval: DefaultRetryInterval,
+		now:           time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.issuer == nil {
+		return nil, errors.New("certificate issuer is required")
+	}
+
+	if m.path == "" {
+		return nil, errors.New("pki issue path is required")
+	}
+
+	if m.req.CommonName == "" {
+		return nil, errors.New("common name is required")
+	}
+
+	if m.retryInterval <= 0 {
+		return nil, errors.New("retry interval must be positive")
+	}
+
+	return m, nil
+}
+
+// Load выпускает новый сертификат и делает его текущим.
+func (m *Manager) Load(ctx context.Context) error {
+	issued, err := m.issuer.IssueCertificate(ctx, m.path, m.req)
+	if err != nil {
+		return fmt.Errorf("servercert: error issue certificate: %w", err)
+	}
+
+	chain := issued.Certificate
+	for _, ca := range issued.CAChain {
+		chain += "\n" + ca
+	}
+
+	if len(issued.CAChain) == 0 && issued.IssuingCA != "" {
+		chain += "\n" + issued.IssuingCA
+	}
+
+	cert, err := tls.X509KeyPair([]byte(chain), []byte(issued.PrivateKey))
+	if err != nil {
+		return fmt.Errorf("servercert: error parse certificate: %w", err)
+	}
+
+	// Leaf заполняется X509KeyPair начиная с Go 1.23, но не полагаемся на это
+	if cert.Leaf == nil {
+		cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return fmt.Errorf("servercert: error parse certificate: %w", err)
+		}
+	}
+
+	m.current.Store(&cert)
+
+	logrus.WithFields(logrus.Fields{
+		"serial_number": issued.SerialNumber,
+		"not_after":     cert.Leaf.NotAfter,
+	}).Info("server certificate loaded")
+
+	return nil
+}
+
+// GetCertificate возвращает текущий сертификат. Используется в tls.Config.GetCertificate.
+func (m *Manager) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert := m.current.Load()
+	if cert == nil {
+		return nil, errors.New("servercert: certificate is not loaded")
+	}
+
+	return cert, nil
+}
+
+// Start продлевает сертификат после 2/3 срока действия. Если продлить не удалось,
+// запрос повторяется через retry interval, текущий сертификат продолжает использоваться.
+// Завершается при отмене контекста.
+func (m *Manager) Start(ctx context.Context) error {
+	timer := time.NewTimer(m.renewIn())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+		}
+
+		next := m.retryInterval
+
+		if err := m.Load(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			logrus.WithError(err).WithField("retry_in", next).Warn("error renew server certificate")
+		} else {
+			next = m.renewIn()
+		}
+
+		timer.Reset(next)
+	}
+}
+
+// renewIn возвращает время до продления текущего сертификата.
+func (m *Manager) renewIn() time.Duration {
+	cert := m.current.Load()
+	if cert == nil || cert.Leaf == nil {
+		return 0
+	}
+
+	lifetime := cert.Leaf.NotAfter.Sub(cert.Leaf.NotBefore)
+	renewAt := cert.Leaf.NotBefore.Add(time.Duration(float64(lifetime) * renewFraction))
+
+	return max(renewAt.Sub(m.now()), 0)
+}
@@ -0,0 +1,172 @@
+package servercert
+
+import (
+	"auth-service/internal/service/servercert/mocks"
+	"auth-service/internal/storage/vault"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCertificate(t *testing.T, serial int64, notBefore, notAfter time.Time) *vault.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "auth.internal"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return &vault.Certificate{
+		Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+		ExpiresAt:   notAfter,
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	issuer := mocks.NewMockcertificateIssuer(gomock.NewController(t))
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr string
+	}{
+		{name: "without issuer", wantErr: "certificate issuer is required"},
+		{name: "without path", opts: []Option{WithIssuer(issuer, "")}, wantErr: "pki issue path is required"},
+		{name: "without common name", opts: []Option{WithIssuer(issuer, "pki/issue/auth")}, wantErr: "common name is required"},
+		{
+			name:    "invalid retry interval",
+			opts:    []Option{WithIssuer(issuer, "pki/issue/auth"), WithRequest(vault.IssueRequest{CommonName: "auth"}), WithRetryInterval(-1)},
+			wantErr: "retry interval must be positive",
+		},
+		{
+			name: "positive case",
+			opts: []Option{WithIssuer(issuer, "pki/issue/auth"), WithRequest(vault.IssueRequest{CommonName: "auth"})},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(tt.opts...)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	issuer := mocks.NewMockcertificateIssuer(gomock.NewController(t))
+	req := vault.IssueRequest{CommonName: "auth.internal", TTL: time.Hour}
+
+	m, err := New(WithIssuer(issuer, "pki/issue/auth"), WithRequest(req))
+	require.NoError(t, err)
+
+	_, err = m.GetCertificate(nil)
+	require.ErrorContains(t, err, "certificate is not loaded")
+
+	now := time.Now().Truncate(time.Second)
+	m.now = func() time.Time { return now }
+
+	issuer.EXPECT().IssueCertificate(gomock.Any(), "pki/issue/auth", req).
+		Return(newCertificate(t, 1, now, now.Add(3*time.Hour)), nil)
+
+	require.NoError(t, m.Load(t.Context()))
+
+	cert, err := m.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), cert.Leaf.SerialNumber.Int64())
+	assert.Equal(t, 2*time.Hour, m.renewIn())
+
+	issuer.EXPECT().IssueCertificate(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("vault is down"))
+	require.ErrorContains(t, m.Load(t.Context()), "vault is down")
+
+	issuer.EXPECT().IssueCertificate(gomock.Any(), gomock.Any(), gomock.Any()).Return(&vault.Certificate{Certificate: "bad", PrivateKey: "bad"}, nil)
+	require.ErrorContains(t, m.Load(t.Context()), "error parse certificate")
+
+	// после ошибок остается предыдущий сертификат
+	cert, err = m.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), cert.Leaf.SerialNumber.Int64())
+}
+
+func TestStart(t *testing.T) {
+	t.Parallel()
+
+	issuer := mocks.NewMockcertificateIssuer(gomock.NewController(t))
+
+	m, err := New(
+		WithIssuer(issuer, "pki/issue/auth"),
+		WithRequest(vault.IssueRequest{CommonName: "auth.internal"}),
+		WithRetryInterval(10*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	// сертификат, который пора продлевать
+	issuer.EXPECT().IssueCertificate(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(newCertificate(t, 1, now.Add(-time.Hour), now.Add(time.Minute)), nil)
+	require.NoError(t, m.Load(t.Context()))
+
+	renewed := make(chan struct{})
+
+	gomock.InOrder(
+		issuer.EXPECT().IssueCertificate(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("vault is down")),
+		issuer.EXPECT().IssueCertificate(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(context.Context, string, vault.IssueRequest) (*vault.Certificate, error) {
+				defer close(renewed)
+
+				return newCertificate(t, 2, now, now.Add(time.Hour)), nil
+			}),
+	)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+
+	go func() { done <- m.Start(ctx) }()
+
+	select {
+	case <-renewed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("certificate was not renewed")
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+
+	cert, err := m.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), cert.Leaf.SerialNumber.Int64())
+}
@@ -0,0 +1,224 @@
+// Package slo считает индикаторы уровня обслуживания (SLI) по классам эндпоинтов: сколько запросов
+// класса обработано хорошо и сколько плохо по доступности (код ответа) и по задержке. Что считается
+// плохим, задается целью (Objective) класса. Счетчики хороших и плохих запросов позволяют строить
+// оповещения по скорости расходования бюджета ошибок в нескольких окнах, например:
+//
+//	sum(rate(auth_slo_requests_total{sli="availability",result="bad"}[1h])) by (class)
+//	  / sum(rate(auth_slo_requests_total{sli="availability"}[1h])) by (class)
+package slo
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Индикаторы уровня обслуживания.
+const (
+	// SLIAvailability - доступность: запрос плохой, если код ответа входит в BadStatuses цели.
+	SLIAvailability = "availability"
+	// SLILatency - задержка: запрос плохой, если обрабатывался дольше Latency цели.
+	// Запросы, плохие по доступности, по задержке не учитываются: быстрая ошибка не делает задержку лучше.
+	SLILatency = "latency"
+)
+
+// Результаты запроса.
+const (
+	ResultGood = "good"
+	ResultBad  = "bad"
+)
+
+// DefaultLatency - порог задержки по умолчанию.
+const DefaultLatency = 500 * time.Millisecond
+
+// DefaultBadStatuses - плохие коды ответа по умолчанию.
+var DefaultBadStatuses = []string{"5xx"}
+
+// Objective - цель класса эндпоинтов: какие запросы считаются плохими.
+type Objective struct {
+	// BadStatuses - коды ответа, при которых запрос плохой по доступности: точный код (429)
+	// или класс кодов (5xx). По умолчанию DefaultBadStatuses.
+	BadStatuses []string
+	// Latency - порог задержки. По умолчанию DefaultLatency.
+	Latency time.Duration
+}
+
+// objective - разобранная цель класса.
+type objective struct {
+	codes   map[int]struct{}
+	classes map[int]struct{} // первая цифра кода: 5 для 5xx
+	latency time.Duration
+}
+
+func newObjective(o Objective) (objective, error) {
+	if o.Latency < 0 {
+		return objective{}, errors.New("latency must not be negative")
+	}
+
+	if o.Latency == 0 {
+		o.Latency = DefaultLatency
+	}
+
+	if len(o.BadStatuses) == 0 {
+		o.BadStatuses = DefaultBadStatuses
+	}
+
+	obj := objective{
+		codes:   make(map[int]struct{}),
+		classes: make(map[int]struct{}),
+		latency: o.Latency,
+	}
+
+	for _, status := range o.BadStatuses {
+		if class, ok := strings.CutSuffix(strings.ToLower(status), "xx"); ok {
+			n, err := strconv.Atoi(class)
+			if err != nil || n < 1 || n > 5 {
+				return objective{}, fmt.Errorf("invalid status class %q", status)
+			}
+
+			obj.classes[n] = struct{}{}
+
+			continue
+		}
+
+		code, err := strconv.Atoi(status)
+		if err != nil || code < 100 || code > 599 {
+			return objective{}, fmt.Errorf("invalid status %q", status)
+		}
+
+		obj.codes[code] = struct{}{}
+	}
+
+	return obj, nil
+}
+
+// bad сообщает, плох ли ответ с кодом status по доступности.
+func (o objective) bad(status int) bool {
+	if _, ok := o.codes[status]; ok {
+		return true
+	}
+
+	_, ok := o.classes[status/100]
+
+	return ok
+}
+
+// Tracker - учет хороших и плохих запросов по классам эндпоинтов.
+type Tracker struct {
+	objectives map[string]Objective
+	parsed     map[string]objective
+
+	registerer prometheus.Registerer
+	requests   *prometheus.CounterVec
+	latency    *prometheus.GaugeVec
+}
+
+// Option - опция для настройки Tracker.
+type Option func(*Tracker)
+
+// WithObjective устанавливает цель класса эндпоинтов. Запросы классов без цели не учитываются.
+func WithObjective(class string, o Objective) Option {
+	return func(t *Tracker) {
+		if t.objectives == nil {
+			t.objectives = make(map[string]Objective)
+		}
+
+		t.objectives[class] = o
+	}
+}
+
+// WithRegisterer устанавливает реестр метрик. По умолчанию используется prometheus.DefaultRegisterer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(t *Tracker) {
+		t.registerer = registerer
+	}
+}
+
+// New создает новый Tracker и регистрирует его метрики.
+func New(opts ...Option) (*Tracker, error) {
+	t := &Tracker{registerer: prometheus.DefaultRegisterer}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	if len(t.objectives) == 0 {
+		return nil, errors.New("objectives are required")
+	}
+
+	if t.registerer == nil {
+		return nil, errors.New("registerer is required")
+	}
+
+	t.parsed = make(map[string]objective, len(t.objectives))
+
+	for class, o := range t.objectives {
+		if class == "" {
+			return nil, errors.New("objective class is required")
+		}
+
+		obj, err := newObjective(o)
+		if err != nil {
+			return nil, fmt.Errorf("objective %s: %w", class, err)
+		}
+
+		t.parsed[class] = obj
+	}
+
+	t.requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_slo_requests_total",
+		Help: "Количество запросов по классам эндпоинтов, индикаторам (availability, latency) и результату (good, bad).",
+	}, []string{"class", "sli", "result"})
+
+	t.latency = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "auth_slo_latency_threshold_seconds",
+		Help: "Порог задержки класса эндпоинтов: запросы дольше порога плохие по задержке.",
+	}, []string{"class"})
+
+	for _, collector := range []prometheus.Collector{t.requests, t.latency} {
+		if err := t.registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	// счетчики видны с нуля до первого плохого запроса, иначе rate() по ним пуст
+	for class, obj := range t.parsed {
+		for _, sli := range []string{SLIAvailability, SLILatency} {
+			t.requests.WithLabelValues(class, sli, ResultGood)
+			t.requests.WithLabelValues(class, sli, ResultBad)
+		}
+
+		t.latency.WithLabelValues(class).Set(obj.latency.Seconds())
+	}
+
+	return t, nil
+}
+
+// Observe учитывает запрос класса class с кодом ответа status, обработанный за duration.
+// Запросы классов без цели не учитываются.
+func (t *Tracker) Observe(class string, status int, duration time.Duration) {
+	obj, ok := t.parsed[class]
+	if !ok {
+		return
+	}
+
+	if obj.bad(status) {
+		t.requests.WithLabelValues(class, SLIAvailability, ResultBad).Inc()
+
+		return
+	}
+
+	t.requests.WithLabelValues(class, SLIAvailability, ResultGood).Inc()
+
+	if duration > obj.latency {
+		t.requests.WithLabelValues(class, SLILatency, ResultBad).Inc()
+
+		return
+	}
+
+	t.requests.WithLabelValues(class, SLILatency, ResultGood).Inc()
+}
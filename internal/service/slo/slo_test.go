@@ -0,0 +1,113 @@
+package slo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case: defaults",
+			opts:    []Option{WithObjective("session", Objective{})},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: codes and classes",
+			opts: []Option{WithObjective("issuance", Objective{
+				BadStatuses: []string{"5xx", "429", "4XX"},
+				Latency:     time.Second,
+			})},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: no objectives",
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: no class",
+			opts:    []Option{WithObjective("", Objective{})},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: invalid status class",
+			opts:    []Option{WithObjective("session", Objective{BadStatuses: []string{"6xx"}})},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: invalid status",
+			opts:    []Option{WithObjective("session", Objective{BadStatuses: []string{"five hundred"}})},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: negative latency",
+			opts:    []Option{WithObjective("session", Objective{Latency: -time.Second})},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(append(tt.opts, WithRegisterer(prometheus.NewRegistry()))...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+func TestTracker_Observe(t *testing.T) {
+	t.Parallel()
+
+	tr, err := New(
+		WithObjective("session", Objective{}),
+		WithObjective("issuance", Objective{BadStatuses: []string{"5xx", "429"}, Latency: time.Second}),
+		WithRegisterer(prometheus.NewRegistry()),
+	)
+	require.NoError(t, err)
+
+	count := func(class, sli, result string) float64 {
+		return testutil.ToFloat64(tr.requests.WithLabelValues(class, sli, result))
+	}
+
+	// счетчики есть до первого запроса
+	assert.InDelta(t, 0, count("session", SLIAvailability, ResultBad), 0)
+	assert.InDelta(t, 0.5, testutil.ToFloat64(tr.latency.WithLabelValues("session")), 0)
+
+	tr.Observe("session", http.StatusOK, 100*time.Millisecond)
+	tr.Observe("session", http.StatusNotFound, 100*time.Millisecond)
+	tr.Observe("session", http.StatusOK, 600*time.Millisecond)
+	tr.Observe("session", http.StatusServiceUnavailable, 10*time.Millisecond)
+	tr.Observe("session", http.StatusTooManyRequests, 10*time.Millisecond)
+
+	assert.InDelta(t, 4, count("session", SLIAvailability, ResultGood), 0)
+	assert.InDelta(t, 1, count("session", SLIAvailability, ResultBad), 0)
+	assert.InDelta(t, 3, count("session", SLILatency, ResultGood), 0)
+	assert.InDelta(t, 1, count("session", SLILatency, ResultBad), 0)
+
+	// своя цель класса
+	tr.Observe("issuance", http.StatusTooManyRequests, 10*time.Millisecond)
+	tr.Observe("issuance", http.StatusOK, 600*time.Millisecond)
+	// плохой по доступности запрос не учитывается по задержке
+	tr.Observe("issuance", http.StatusInternalServerError, 2*time.Second)
+
+	assert.InDelta(t, 2, count("issuance", SLIAvailability, ResultBad), 0)
+	assert.InDelta(t, 1, count("issuance", SLILatency, ResultGood), 0)
+	assert.InDelta(t, 0, count("issuance", SLILatency, ResultBad), 0)
+
+	// классы без цели не учитываются
+	tr.Observe("info", http.StatusInternalServerError, time.Second)
+	assert.Equal(t, 8, testutil.CollectAndCount(tr.requests))
+}
@@ -0,0 +1,174 @@
+package spiffe
+
+import (
+	"auth-service/internal/service/token"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// jwtKeyField - поле секрета Vault с закрытым ключом подписи JWT-SVID в PEM.
+	jwtKeyField = "private_key"
+	// bundleKeyUse - назначение ключа в SPIFFE trust bundle для проверки JWT-SVID.
+	bundleKeyUse = "jwt-svid"
+	// minRSABits - минимальный размер ключа RSA.
+	minRSABits = 2048
+)
+
+// kvReader - интерфейс для чтения секретов KV из Vault.
+type kvReader interface {
+	ReadKV(ctx context.Context, path string) (map[string]interface{}, error)
+}
+
+// Bundle - SPIFFE trust bundle с ключами проверки JWT-SVID (JWKS, use=jwt-svid).
+type Bundle struct {
+	Keys []BundleKey `json:"keys"`
+}
+
+// BundleKey - открытый ключ подписи JWT-SVID в формате JWK.
+type BundleKey struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// svidClaims - claims JWT-SVID.
+type svidClaims struct {
+	jwt.RegisteredClaims
+	Cnf *token.Confirmation `json:"cnf,omitempty"`
+}
+
+// jwtKey - ключ подписи JWT-SVID и его открытая часть для trust bundle.
+type jwtKey struct {
+	signer crypto.Signer
+	method jwt.SigningMethod
+	public BundleKey
+}
+
+// LoadJWTKey читает закрытый ключ подписи JWT-SVID из поля private_key секрета KV Vault по пути path.
+// Ключ в PEM (PKCS#8, SEC 1 или PKCS#1): ECDSA P-256 (ES256) или RSA от 2048 бит (RS256).
+func LoadJWTKey(ctx context.Context, client kvReader, path string) (crypto.Signer, error) {
+	data, err := client.ReadKV(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("spiffe: error read jwt svid key: %w", err)
+	}
+
+	raw, _ := data[jwtKeyField].(string)
+	if raw == "" {
+		return nil, fmt.Errorf("spiffe: field %s is missing in %s", jwtKeyField, path)
+	}
+
+	key, err := parsePrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("spiffe: invalid jwt svid key in %s: %w", path, err)
+	}
+
+	return key, nil
+}
+
+// parsePrivateKey разбирает закрытый ключ в PEM.
+func parsePrivateKey(raw string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(raw))
+	if block == nil {
+		return nil, errors.New("key must be PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported key type %T", key)
+		}
+
+		return signer, nil
+	}
+
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+}
+
+// newJWTKey проверяет ключ подписи и вычисляет его kid (отпечаток JWK, RFC 7638).
+func newJWTKey(signer crypto.Signer) (*jwtKey, error) {
+	var method jwt.SigningMethod
+
+	switch key := signer.(type) {
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return nil, errors.New("ecdsa jwt svid key must use P-256")
+		}
+
+		method = jwt.SigningMethodES256
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("rsa jwt svid key must be at least %d bits", minRSABits)
+		}
+
+		method = jwt.SigningMethodRS256
+	default:
+		return nil, fmt.Errorf("unsupported jwt svid key type %T", signer)
+	}
+
+	jwk := jose.JSONWebKey{Key: signer.Public(), Algorithm: method.Alg(), Use: bundleKeyUse}
+
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("error compute jwt svid key id: %w", err)
+	}
+
+	jwk.KeyID = base64.RawURLEncoding.EncodeToString(thumbprint)
+
+	data, err := jwk.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("error encode jwt svid key: %w", err)
+	}
+
+	k := &jwtKey{signer: signer, method: method}
+
+	if err := json.Unmarshal(data, &k.public); err != nil {
+		return nil, fmt.Errorf("error encode jwt svid key: %w", err)
+	}
+
+	return k, nil
+}
+
+// sign подписывает claims JWT-SVID. Заголовок typ=JWT-SVID не дает принять SVID как токен пользователя.
+func (k *jwtKey) sign(claims *svidClaims) (string, error) {
+	tok := jwt.NewWithClaims(k.method, claims)
+	tok.Header["kid"] = k.public.KeyID
+	tok.Header["typ"] = token.SVIDType
+
+	return tok.SignedString(k.signer)
+}
+
+// Bundle возвращает trust bundle с ключом проверки JWT-SVID. Если выпуск JWT-SVID не настроен, возвращает ErrNotConfigured.
+func (s *Service) Bundle() (*Bundle, error) {
+	if s.jwtKey == nil {
+		return nil, ErrNotConfigured
+	}
+
+	return &Bundle{Keys: []BundleKey{s.jwtKey.public}}, nil
+}
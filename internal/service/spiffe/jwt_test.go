@@ -0,0 +1,118 @@
+package spiffe
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKV - секрет Vault в памяти.
+type fakeKV map[string]interface{}
+
+func (f fakeKV) ReadKV(_ context.Context, _ string) (map[string]interface{}, error) {
+	if f == nil {
+		return nil, errors.New("secret not found")
+	}
+
+	return f, nil
+}
+
+func TestLoadJWTKey(t *testing.T) {
+	t.Parallel()
+
+	ec := newECKey(t)
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(ec)
+	require.NoError(t, err)
+
+	sec1, err := x509.MarshalECPrivateKey(ec)
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	encode := func(typ string, der []byte) string {
+		return string(pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}))
+	}
+
+	for _, raw := range []string{
+		encode("PRIVATE KEY", pkcs8),
+		encode("EC PRIVATE KEY", sec1),
+		encode("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)),
+	} {
+		key, err := LoadJWTKey(t.Context(), fakeKV{"private_key": raw}, "secret/data/auth/spiffe")
+		require.NoError(t, err)
+
+		_, err = newJWTKey(key)
+		require.NoError(t, err)
+	}
+
+	_, err = LoadJWTKey(t.Context(), fakeKV(nil), "secret/data/auth/spiffe")
+	require.ErrorContains(t, err, "secret not found")
+
+	_, err = LoadJWTKey(t.Context(), fakeKV{}, "secret/data/auth/spiffe")
+	require.ErrorContains(t, err, "private_key is missing")
+
+	_, err = LoadJWTKey(t.Context(), fakeKV{"private_key": "not a key"}, "secret/data/auth/spiffe")
+	require.ErrorContains(t, err, "PEM encoded")
+}
+
+func TestNewJWTKey(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	key, err := newJWTKey(rsaKey)
+	require.NoError(t, err)
+	assert.Equal(t, "RS256", key.public.Algorithm)
+	assert.Equal(t, "RSA", key.public.KeyType)
+	assert.NotEmpty(t, key.public.N)
+
+	// kid - отпечаток открытого ключа, не зависит от экземпляра сервиса
+	again, err := newJWTKey(rsaKey)
+	require.NoError(t, err)
+	assert.Equal(t, key.public.KeyID, again.public.KeyID)
+
+	weak, err := rsa.GenerateKey(rand.Reader, 1024) //nolint:gosec // проверка отказа слабого ключа
+	require.NoError(t, err)
+
+	_, err = newJWTKey(weak)
+	require.ErrorContains(t, err, "at least 2048 bits")
+}
+
+func TestBundle(t *testing.T) {
+	t.Parallel()
+
+	svc, err := New(
+		WithTrustDomain("example.org"),
+		WithWorkloads([]Workload{{Path: "/bot", TokenSHA256: tokenHash(t, bootstrap)}}),
+		WithJWTKey(newECKey(t)),
+		WithJWTAudiences([]string{"notes"}),
+	)
+	require.NoError(t, err)
+
+	bundle, err := svc.Bundle()
+	require.NoError(t, err)
+	require.Len(t, bundle.Keys, 1)
+
+	key := bundle.Keys[0]
+	assert.Equal(t, "EC", key.KeyType)
+	assert.Equal(t, "P-256", key.Curve)
+	assert.NotEmpty(t, key.X)
+	assert.NotEmpty(t, key.Y)
+	assert.NotEmpty(t, key.KeyID)
+	assert.Empty(t, key.N)
+
+	svc.jwtKey = nil
+
+	_, err = svc.Bundle()
+	require.ErrorIs(t, err, ErrNotConfigured)
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: spiffe.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	vault "auth-service/internal/storage/vault"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockcertificateSigner is a mock of certificateSigner interface.
+type MockcertificateSigner struct {
+	ctrl     *gomock.Controller
+	recorder *MockcertificateSignerMockRecorder
+}
+
+// MockcertificateSignerMockRecorder is the mock recorder for MockcertificateSigner.
+type MockcertificateSignerMockRecorder struct {
+	mock *MockcertificateSigner
+}
+
+// NewMockcertificateSigner creates a new mock instance.
+func NewMockcertificateSigner(ctrl *gomock.Controller) *MockcertificateSigner {
+	mock := &MockcertificateSigner{ctrl: ctrl}
+	mock.recorder = &MockcertificateSignerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockcertificateSigner) EXPECT() *MockcertificateSignerMockRecorder {
+	return m.recorder
+}
+
+// SignCertificate mocks base method.
+func (m *MockcertificateSigner) SignCertificate(ctx context.Context, path string, req vault.SignRequest) (*vault.Certificate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignCertificate", ctx, path, req)
+	ret0, _ := ret[0].(*vault.Certificate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignCertificate indicates an expected call of SignCertificate.
+func (mr *MockcertificateSignerMockRecorder) SignCertificate(ctx, path, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignCertificate", reflect.TypeOf((*MockcertificateSigner)(nil).SignCertificate), ctx, path, req)
+}
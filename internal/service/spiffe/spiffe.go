@@ -0,0 +1,321 @@
+// Package spiffe выпускает короткоживущие SPIFFE SVID для внутренних сервисов (экспериментально).
+// Сервис предъявляет bootstrap токен, по которому определяется его SPIFFE ID, и получает
+// X.509-SVID (CSR подписывается промежуточным CA из Vault PKI) или JWT-SVID (подписывается отдельным
+// асимметричным ключом, открытая часть которого публикуется в trust bundle).
+package spiffe
+
+import (
+	"auth-service/internal/service/token"
+	"auth-service/internal/storage/vault"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// DefaultTTL - время жизни SVID по умолчанию.
+	DefaultTTL = 5 * time.Minute
+	// MaxTTL - максимальное время жизни SVID.
+	MaxTTL = time.Hour
+
+	scheme = "spiffe://"
+)
+
+var (
+	// ErrUnauthorized - bootstrap токен не найден.
+	ErrUnauthorized = errors.New("invalid bootstrap token")
+	// ErrInvalidArgument - некорректный CSR или параметры запроса.
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrNotConfigured - запрошенный тип SVID не настроен.
+	ErrNotConfigured = errors.New("svid type is not configured")
+)
+
+//go:generate mockgen -source=spiffe.go -destination=mocks/spiffe_mock.go -package=mocks
+type certificateSigner interface {
+	SignCertificate(ctx context.Context, path string, req vault.SignRequest) (*vault.Certificate, error)
+}
+
+// Workload - сервис, которому выдаются SVID.
+type Workload struct {
+	Path        string // Путь SPIFFE ID внутри trust domain, например /ns/bots/sa/notes
+	TokenSHA256 string // SHA-256 (hex) bootstrap токена
+}
+
+// X509SVID - выпущенный X.509-SVID.
+type X509SVID struct {
+	SPIFFEID     string    `json:"spiffe_id"`
+	Certificate  string    `json:"certificate"`
+	Bundle       []string  `json:"bundle"`
+	SerialNumber string    `json:"serial_number"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// JWTSVID - выпущенный JWT-SVID.
+type JWTSVID struct {
+	SPIFFEID  string    `json:"spiffe_id"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Service - выпуск SVID.
+type Service struct {
+	trustDomain string
+	workloads   map[string]string // sha256 токена -> SPIFFE ID
+	ttl         time.Duration
+
+	signer   certificateSigner
+	signPath string
+
+	jwtSigner    crypto.Signer
+	jwtKey       *jwtKey
+	jwtAudiences []string
+	now          func() time.Time
+}
+
+// Option - опция для настройки Service.
+type Option func(*Service)
+
+// WithTrustDomain устанавливает trust domain, например example.org.
+func WithTrustDomain(domain string) Option {
+	return func(s *Service) {
+		s.trustDomain = domain
+	}
+}
+
+// WithWorkloads устанавливает сервисы, которым выдаются SVID.
+func WithWorkloads(workloads []Workload) Option {
+	return func(s *Service) {
+		for _, w := range workloads {
+			s.workloads[strings.ToLower(w.TokenSHA256)] = w.Path
+		}
+	}
+}
+
+// WithTTL устанавливает время жизни SVID.
+func WithTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		s.ttl = ttl
+	}
+}
+
+// WithX509Signer включает выпуск X.509-SVID: CSR подписывается ролью PKI по пути path (например, pki_int/sign/spiffe).
+func WithX509Signer(signer certificateSigner, path string) Option {
+	return func(s *Service) {
+		s.signer = signer
+		s.signPath = path
+	}
+}
+
+// WithJWTKey включает выпуск JWT-SVID, подписанных ключом key (ECDSA P-256 или RSA, см. LoadJWTKey).
+// Ключ не должен совпадать с ключами токенов пользователей: открытая часть публикуется в Bundle.
+func WithJWTKey(key crypto.Signer) Option {
+	return func(s *Service) {
+		s.jwtSigner = key
+	}
+}
+
+// WithJWTAudiences устанавливает аудитории, для которых выдаются JWT-SVID. Запрос с другой аудиторией отклоняется.
+func WithJWTAudiences(audiences []string) Option {
+	return func(s *Service) {
+		s.jwtAudiences = audiences
+	}
+}
+
+// New создает новый Service.
+func New(opts ...Option) (*Service, error) {
+	s := &Service{
+		workloads: make(map[string]string),
+		ttl:       DefaultTTL,
+		now:       time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.trustDomain == "" {
+		return nil, errors.New("trust domain is required")
+	}
+
+	if len(s.workloads) == 0 {
+		return nil, errors.New("workloads are required")
+	}
+
+	for hash, path := range s.workloads {
+		if len(hash) != sha256.Size*2 {
+			return nil, fmt.Errorf("invalid token hash for workload %s", path)
+		}
+
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("workload path must start with /: %s", path)
+		}
+
+		s.workloads[hash] = scheme + s.trustDomain + path
+	}
+
+	if s.ttl <= 0 || s.ttl > MaxTTL {
+		return nil, fmt.Errorf("ttl must be in (0, %s]", MaxTTL)
+	}
+
+	if s.signer != nil && s.signPath == "" {
+		return nil, errors.New("pki sign path is required")
+	}
+
+	if s.signer == nil && s.jwtSigner == nil {
+		return nil, errors.New("x509 signer or jwt key is required")
+	}
+
+	if s.jwtSigner != nil {
+		if len(s.jwtAudiences) == 0 {
+			return nil, errors.New("jwt audiences are required")
+		}
+
+		key, err := newJWTKey(s.jwtSigner)
+		if err != nil {
+			return nil, err
+		}
+
+		s.jwtKey = key
+	}
+
+	return s, nil
+}
+
+// Authenticate возвращает SPIFFE ID сервиса по bootstrap токену.
+func (s *Service) Authenticate(bootstrap string) (string, error) {
+	if bootstrap == "" {
+		return "", ErrUnauthorized
+	}
+
+	sum := sha256.Sum256([]byte(bootstrap))
+	hash := hex.EncodeToString(sum[:])
+
+	// сравниваем все хэши за постоянное время, чтобы не раскрывать совпадение по времени ответа
+	var found string
+
+	for h, id := range s.workloads {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+			found = id
+		}
+	}
+
+	if found == "" {
+		return "", ErrUnauthorized
+	}
+
+	return found, nil
+}
+
+// IssueX509 подписывает CSR сервиса и возвращает X.509-SVID с его SPIFFE ID в URI SAN.
+func (s *Service) IssueX509(ctx context.Context, bootstrap, csrPEM string) (*X509SVID, error) {
+	if s.signer == nil {
+		return nil, ErrNotConfigured
+	}
+
+	id, err := s.Authenticate(bootstrap)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkCSR(csrPEM); err != nil {
+		return nil, err
+	}
+
+	cert, err := s.signer.SignCertificate(ctx, s.signPath, vault.SignRequest{
+		CSR:     csrPEM,
+		URISANs: []string{id},
+		TTL:     s.ttl,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("spiffe: error sign certificate: %w", err)
+	}
+
+	bundle := cert.CAChain
+	if len(bundle) == 0 && cert.IssuingCA != "" {
+		bundle = []string{cert.IssuingCA}
+	}
+
+	return &X509SVID{
+		SPIFFEID:     id,
+		Certificate:  cert.Certificate,
+		Bundle:       bundle,
+		SerialNumber: cert.SerialNumber,
+		ExpiresAt:    cert.ExpiresAt,
+	}, nil
+}
+
+// IssueJWT выпускает JWT-SVID для указанных аудиторий из WithJWTAudiences. Если передан отпечаток
+// клиентского сертификата mTLS, токен привязывается к нему (claim cnf, RFC 8705).
+func (s *Service) IssueJWT(_ context.Context, bootstrap string, audience []string, thumbprint string) (*JWTSVID, error) {
+	if s.jwtKey == nil {
+		return nil, ErrNotConfigured
+	}
+
+	id, err := s.Authenticate(bootstrap)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(audience) == 0 {
+		return nil, fmt.Errorf("%w: audience is required", ErrInvalidArgument)
+	}
+
+	for _, aud := range audience {
+		if !slices.Contains(s.jwtAudiences, aud) {
+			return nil, fmt.Errorf("%w: audience %q is not allowed", ErrInvalidArgument, aud)
+		}
+	}
+
+	now := s.now().Truncate(time.Second)
+	expiresAt := now.Add(s.ttl)
+
+	claims := &svidClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   id,
+			Audience:  audience,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	if thumbprint != "" {
+		claims.Cnf = &token.Confirmation{X5tS256: thumbprint}
+	}
+
+	raw, err := s.jwtKey.sign(claims)
+	if err != nil {
+		return nil, fmt.Errorf("spiffe: error sign jwt svid: %w", err)
+	}
+
+	return &JWTSVID{SPIFFEID: id, Token: raw, ExpiresAt: expiresAt}, nil
+}
+
+// checkCSR проверяет, что CSR корректен и подписан ключом, для которого запрошен сертификат.
+func checkCSR(csrPEM string) error {
+	block, _ := pem.Decode([]byte(csrPEM))
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return fmt.Errorf("%w: csr must be a PEM encoded certificate request", ErrInvalidArgument)
+	}
+
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidArgument, err)
+	}
+
+	if err := csr.CheckSignature(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidArgument, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,284 @@
+package spiffe
+
+import (
+	"auth-service/internal/service/spiffe/mocks"
+	"auth-service/internal/service/token"
+	"auth-service/internal/storage/vault"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bootstrap = "bootstrap-token"
+
+func tokenHash(t *testing.T, tok string) string {
+	t.Helper()
+
+	sum := sha256.Sum256([]byte(tok))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// staticKey - ключ токенов пользователей для проверки, что SVID ими не принимаются.
+type staticKey struct{}
+
+func (staticKey) Key(context.Context, string) ([]byte, error) {
+	return []byte("secret"), nil
+}
+
+func newECKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return key
+}
+
+func newP384Key(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	return key
+}
+
+func newCSR(t *testing.T) string {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	signer := mocks.NewMockcertificateSigner(ctrl)
+	workloads := []Workload{{Path: "/bot", TokenSHA256: tokenHash(t, bootstrap)}}
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr string
+	}{
+		{
+			name:    "without trust domain",
+			opts:    []Option{WithWorkloads(workloads), WithX509Signer(signer, "pki/sign/spiffe")},
+			wantErr: "trust domain is required",
+		},
+		{
+			name:    "without workloads",
+			opts:    []Option{WithTrustDomain("example.org"), WithX509Signer(signer, "pki/sign/spiffe")},
+			wantErr: "workloads are required",
+		},
+		{
+			name: "invalid token hash",
+			opts: []Option{
+				WithTrustDomain("example.org"),
+				WithWorkloads([]Workload{{Path: "/bot", TokenSHA256: "abc"}}),
+				WithX509Signer(signer, "pki/sign/spiffe"),
+			},
+			wantErr: "invalid token hash",
+		},
+		{
+			name: "invalid path",
+			opts: []Option{
+				WithTrustDomain("example.org"),
+				WithWorkloads([]Workload{{Path: "bot", TokenSHA256: tokenHash(t, bootstrap)}}),
+				WithX509Signer(signer, "pki/sign/spiffe"),
+			},
+			wantErr: "must start with /",
+		},
+		{
+			name: "ttl too long",
+			opts: []Option{
+				WithTrustDomain("example.org"), WithWorkloads(workloads),
+				WithX509Signer(signer, "pki/sign/spiffe"), WithTTL(2 * time.Hour),
+			},
+			wantErr: "ttl must be in",
+		},
+		{
+			name:    "without sign path",
+			opts:    []Option{WithTrustDomain("example.org"), WithWorkloads(workloads), WithX509Signer(signer, "")},
+			wantErr: "pki sign path is required",
+		},
+		{
+			name:    "without issuers",
+			opts:    []Option{WithTrustDomain("example.org"), WithWorkloads(workloads)},
+			wantErr: "x509 signer or jwt key is required",
+		},
+		{
+			name:    "jwt without audiences",
+			opts:    []Option{WithTrustDomain("example.org"), WithWorkloads(workloads), WithJWTKey(newECKey(t))},
+			wantErr: "jwt audiences are required",
+		},
+		{
+			name: "jwt key of unsupported curve",
+			opts: []Option{
+				WithTrustDomain("example.org"), WithWorkloads(workloads),
+				WithJWTKey(newP384Key(t)), WithJWTAudiences([]string{"notes"}),
+			},
+			wantErr: "must use P-256",
+		},
+		{
+			name: "positive case",
+			opts: []Option{WithTrustDomain("example.org"), WithWorkloads(workloads), WithX509Signer(signer, "pki/sign/spiffe")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc, err := New(tt.opts...)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			id, err := svc.Authenticate(bootstrap)
+			require.NoError(t, err)
+			assert.Equal(t, "spiffe://example.org/bot", id)
+
+			_, err = svc.Authenticate("other")
+			require.ErrorIs(t, err, ErrUnauthorized)
+		})
+	}
+}
+
+func TestIssueX509(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	signer := mocks.NewMockcertificateSigner(ctrl)
+	csr := newCSR(t)
+	expiresAt := time.Unix(1767225600, 0)
+
+	svc, err := New(
+		WithTrustDomain("example.org"),
+		WithWorkloads([]Workload{{Path: "/bot", TokenSHA256: tokenHash(t, bootstrap)}}),
+		WithX509Signer(signer, "pki_int/sign/spiffe"),
+		WithTTL(10*time.Minute),
+	)
+	require.NoError(t, err)
+
+	signer.EXPECT().SignCertificate(gomock.Any(), "pki_int/sign/spiffe", vault.SignRequest{
+		CSR:     csr,
+		URISANs: []string{"spiffe://example.org/bot"},
+		TTL:     10 * time.Minute,
+	}).Return(&vault.Certificate{
+		Certificate:  "CERT",
+		IssuingCA:    "CA",
+		SerialNumber: "01",
+		ExpiresAt:    expiresAt,
+	}, nil)
+
+	svid, err := svc.IssueX509(t.Context(), bootstrap, csr)
+	require.NoError(t, err)
+	assert.Equal(t, &X509SVID{
+		SPIFFEID:     "spiffe://example.org/bot",
+		Certificate:  "CERT",
+		Bundle:       []string{"CA"},
+		SerialNumber: "01",
+		ExpiresAt:    expiresAt,
+	}, svid)
+
+	_, err = svc.IssueX509(t.Context(), "other", csr)
+	require.ErrorIs(t, err, ErrUnauthorized)
+
+	_, err = svc.IssueX509(t.Context(), bootstrap, "not a csr")
+	require.ErrorIs(t, err, ErrInvalidArgument)
+
+	signer.EXPECT().SignCertificate(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("vault is down"))
+
+	_, err = svc.IssueX509(t.Context(), bootstrap, csr)
+	require.ErrorContains(t, err, "vault is down")
+
+	_, err = svc.IssueJWT(t.Context(), bootstrap, []string{"notes"}, "")
+	require.ErrorIs(t, err, ErrNotConfigured)
+}
+
+func TestIssueJWT(t *testing.T) {
+	t.Parallel()
+
+	key := newECKey(t)
+	now := time.Now().Truncate(time.Second)
+
+	svc, err := New(
+		WithTrustDomain("example.org"),
+		WithWorkloads([]Workload{{Path: "/bot", TokenSHA256: tokenHash(t, bootstrap)}}),
+		WithJWTKey(key),
+		WithJWTAudiences([]string{"notes", "reminders"}),
+	)
+	require.NoError(t, err)
+
+	svc.now = func() time.Time { return now }
+
+	svid, err := svc.IssueJWT(t.Context(), bootstrap, []string{"notes"}, "thumb")
+	require.NoError(t, err)
+	assert.Equal(t, "spiffe://example.org/bot", svid.SPIFFEID)
+	assert.Equal(t, now.Add(DefaultTTL), svid.ExpiresAt)
+
+	// SVID проверяется ключом из trust bundle
+	bundle, err := svc.Bundle()
+	require.NoError(t, err)
+	require.Len(t, bundle.Keys, 1)
+	assert.Equal(t, "jwt-svid", bundle.Keys[0].Use)
+	assert.Equal(t, "ES256", bundle.Keys[0].Algorithm)
+
+	claims := &svidClaims{}
+
+	tok, err := jwt.ParseWithClaims(svid.Token, claims, func(tok *jwt.Token) (interface{}, error) {
+		assert.Equal(t, bundle.Keys[0].KeyID, tok.Header["kid"])
+
+		return key.Public(), nil
+	}, jwt.WithValidMethods([]string{"ES256"}))
+	require.NoError(t, err)
+	assert.Equal(t, token.SVIDType, tok.Header["typ"])
+	assert.Equal(t, "spiffe://example.org/bot", claims.Subject)
+	assert.Equal(t, jwt.ClaimStrings{"notes"}, claims.Audience)
+	assert.Equal(t, &token.Confirmation{X5tS256: "thumb"}, claims.Cnf)
+
+	// SVID не принимается как токен пользователя
+	validator, err := token.NewValidator(token.WithKeys(staticKey{}))
+	require.NoError(t, err)
+
+	_, err = validator.Validate(t.Context(), svid.Token)
+	require.ErrorIs(t, err, token.ErrInvalidToken)
+
+	_, err = svc.IssueJWT(t.Context(), bootstrap, []string{"notes", "admin"}, "")
+	require.ErrorIs(t, err, ErrInvalidArgument)
+
+	_, err = svc.IssueJWT(t.Context(), bootstrap, nil, "")
+	require.ErrorIs(t, err, ErrInvalidArgument)
+
+	_, err = svc.IssueJWT(t.Context(), "", []string{"notes"}, "")
+	require.ErrorIs(t, err, ErrUnauthorized)
+
+	_, err = svc.IssueX509(t.Context(), bootstrap, newCSR(t))
+	require.ErrorIs(t, err, ErrNotConfigured)
+}
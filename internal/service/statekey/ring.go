@@ -0,0 +1,407 @@
+// Package statekey управляет ключами шифрования значений, которые сервис отдает клиенту и потом
+// получает обратно: state входа через OAuth, CSRF токенов и cookie. Эти ключи отделены от ключей
+// подписи jwt: у них свой секрет Vault и своя ротация, и их компрометация или смена не затрагивает токены.
+//
+// Секрет Vault KV v2 хранит ключи в виде kid -> ключ (32 байта в base64) и служебные поля:
+// current - kid ключа, которым шифруются новые значения, previous - kid предыдущего ключа,
+// которым значения только расшифровываются, rotated_at - время последней ротации (RFC 3339).
+// При ротации новый ключ становится текущим, текущий - предыдущим, а ключ до него удаляется,
+// поэтому период ротации должен быть больше срока жизни зашифрованных значений.
+package statekey
+
+import (
+	"auth-service/internal/service/id"
+	"auth-service/internal/storage/vault"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+// Значения по умолчанию.
+const (
+	DefaultPath           = "secret/data/auth/state-keys"
+	DefaultReloadInterval = time.Minute
+)
+
+// Служебные поля секрета.
+const (
+	fieldCurrent   = "current"
+	fieldPrevious  = "previous"
+	fieldRotatedAt = "rotated_at"
+)
+
+const (
+	keySize   = 32 // AES-256
+	kidLength = 8
+
+	// minUnknownReload - как часто неизвестный kid может вызвать перечитывание секрета:
+	// значение могло быть зашифровано ключом, который другой экземпляр только что создал.
+	minUnknownReload = 5 * time.Second
+)
+
+// Операции и результаты в метриках.
+const (
+	operationReload = "reload"
+	operationRotate = "rotate"
+	resultOK        = "ok"
+	resultError     = "error"
+)
+
+// kvClient - интерфейс для чтения и записи секретов KV в Vault.
+type kvClient interface {
+	ReadKV(ctx context.Context, path string) (map[string]interface{}, error)
+	WriteKV(ctx context.Context, path string, data map[string]interface{}) error
+}
+
+// Ring - ключи шифрования state и cookie из Vault.
+type Ring struct {
+	client         kvClient
+	path           string
+	reloadInterval time.Duration
+	rotationPeriod time.Duration
+	now            func() time.Time
+
+	mu         sync.RWMutex
+	keys       map[string][]byte
+	current    string
+	rotatedAt  time.Time
+	lastReload time.Time
+
+	group singleflight.Group
+
+	registerer prometheus.Registerer
+	operations *prometheus.CounterVec
+}
+
+// Option - опция для настройки Ring.
+type Option func(*Ring)
+
+// WithClient устанавливает клиент Vault.
+func WithClient(client kvClient) Option {
+	return func(r *Ring) {
+		r.client = client
+	}
+}
+
+// WithPath устанавливает путь к секрету с ключами. По умолчанию DefaultPath.
+func WithPath(path string) Option {
+	return func(r *Ring) {
+		r.path = path
+	}
+}
+
+// WithReloadInterval устанавливает периодичность перечитывания секрета. По умолчанию DefaultReloadInterval.
+func WithReloadInterval(interval time.Duration) Option {
+	return func(r *Ring) {
+		r.reloadInterval = interval
+	}
+}
+
+// WithRotationPeriod включает ротацию: ключ, созданный больше period назад, заменяется новым.
+// Если секрета еще нет, первый ключ создается при старте. По умолчанию ротация отключена
+// и ключи в секрете меняются вручную.
+func WithRotationPeriod(period time.Duration) Option {
+	return func(r *Ring) {
+		r.rotationPeriod = period
+	}
+}
+
+// WithRegisterer устанавливает реестр метрик. По умолчанию используется prometheus.DefaultRegisterer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(r *Ring) {
+		r.registerer = registerer
+	}
+}
+
+// New создает набор ключей и читает секрет из Vault. Если секрета нет и ротация включена,
+// создает первый ключ.
+func New(ctx context.Context, opts ...Option) (*Ring, error) {
+	r := &Ring{
+		path:           DefaultPath,
+		reloadInterval: DefaultReloadInterval,
+		now:            time.Now,
+		keys:           map[string][]byte{},
+		registerer:     prometheus.DefaultRegisterer,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+
+	r.operations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_state_keys_operations_total",
+		Help: "Количество операций с ключами шифрования state и cookie: reload - чтение из Vault, rotate - ротация.",
+	}, []string{"operation", "result"})
+
+	if err := r.registerer.Register(r.operations); err != nil {
+		return nil, err
+	}
+
+	err := r.Reload(ctx)
+	if errors.Is(err, vault.ErrSecretNotFound) && r.rotationPeriod > 0 {
+		err = r.Rotate(ctx)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *Ring) validate() error {
+	if r.client == nil {
+		return errors.New("vault client is required")
+	}
+
+	if r.path == "" {
+		return errors.New("path is required")
+	}
+
+	if r.reloadInterval <= 0 {
+		return errors.New("reload interval must be positive")
+	}
+
+	if r.rotationPeriod < 0 {
+		return errors.New("rotation period must not be negative")
+	}
+
+	if r.registerer == nil {
+		return errors.New("registerer is required")
+	}
+
+	return nil
+}
+
+// Reload перечитывает ключи из Vault. При ошибке продолжают действовать прочитанные ранее.
+func (r *Ring) Reload(ctx context.Context) error {
+	_, err, _ := r.group.Do(operationReload, func() (interface{}, error) {
+		data, err := r.client.ReadKV(ctx, r.path)
+		if err != nil {
+			return nil, fmt.Errorf("statekey: error read keys: %w", err)
+		}
+
+		s, err := parseSecret(data)
+		if err != nil {
+			return nil, err
+		}
+
+		r.mu.Lock()
+		r.keys, r.current, r.rotatedAt = s.keys, s.current, s.rotatedAt
+		r.lastReload = r.now()
+		r.mu.Unlock()
+
+		return nil, nil //nolint:nilnil // результат не нужен
+	})
+
+	r.observe(operationReload, err)
+
+	return err
+}
+
+// Rotate создает новый ключ и делает его текущим. Секрет перечитывается перед записью:
+// если другой экземпляр уже выполнил ротацию в этом периоде, применяются его ключи.
+func (r *Ring) Rotate(ctx context.Context) error {
+	_, err, _ := r.group.Do(operationRotate, func() (interface{}, error) {
+		return nil, r.rotate(ctx)
+	})
+
+	r.observe(operationRotate, err)
+
+	return err
+}
+
+func (r *Ring) rotate(ctx context.Context) error {
+	var s secret
+
+	data, err := r.client.ReadKV(ctx, r.path)
+
+	switch {
+	case errors.Is(err, vault.ErrSecretNotFound):
+	case err != nil:
+		return fmt.Errorf("statekey: error read keys: %w", err)
+	default:
+		if s, err = parseSecret(data); err != nil {
+			return err
+		}
+	}
+
+	now := r.now()
+
+	if s.current != "" && !r.due(s.rotatedAt, now) {
+		r.apply(s, now)
+
+		return nil
+	}
+
+	kid, key, err := generate()
+	if err != nil {
+		return err
+	}
+
+	next := secret{
+		keys:      map[string][]byte{kid: key},
+		current:   kid,
+		previous:  s.current,
+		rotatedAt: now.UTC().Truncate(time.Second),
+	}
+
+	if s.current != "" {
+		next.keys[s.current] = s.keys[s.current]
+	}
+
+	if err := r.client.WriteKV(ctx, r.path, next.data()); err != nil {
+		return fmt.Errorf("statekey: error write keys: %w", err)
+	}
+
+	r.apply(next, now)
+
+	logrus.WithFields(logrus.Fields{
+		"vault_path": r.path,
+		"kid":        kid,
+		"previous":   next.previous,
+	}).Info("state encryption key rotated")
+
+	return nil
+}
+
+func (r *Ring) apply(s secret, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.keys, r.current, r.rotatedAt = s.keys, s.current, s.rotatedAt
+	r.lastReload = now
+}
+
+// due сообщает, пора ли заменить ключ, созданный в rotatedAt.
+func (r *Ring) due(rotatedAt, now time.Time) bool {
+	return r.rotationPeriod > 0 && !now.Before(rotatedAt.Add(r.rotationPeriod))
+}
+
+// Start периодически перечитывает ключи и, если включена ротация, заменяет устаревший ключ.
+// Работает до отмены контекста. Ошибки не останавливают цикл: действуют прочитанные ранее ключи.
+func (r *Ring) Start(ctx context.Context) error {
+	ticker := time.NewTicker(r.reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		if err := r.Reload(ctx); err != nil && ctx.Err() == nil {
+			logrus.WithError(err).Error("error reload state encryption keys, keeping previous")
+
+			continue
+		}
+
+		r.mu.RLock()
+		due := r.due(r.rotatedAt, r.now())
+		r.mu.RUnlock()
+
+		if due {
+			if err := r.Rotate(ctx); err != nil && ctx.Err() == nil {
+				logrus.WithError(err).Error("error rotate state encryption key")
+			}
+		}
+	}
+}
+
+func (r *Ring) observe(operation string, err error) {
+	result := resultOK
+	if err != nil {
+		result = resultError
+	}
+
+	r.operations.WithLabelValues(operation, result).Inc()
+}
+
+// secret - содержимое секрета с ключами.
+type secret struct {
+	keys      map[string][]byte
+	current   string
+	previous  string
+	rotatedAt time.Time
+}
+
+func parseSecret(data map[string]interface{}) (secret, error) {
+	s := secret{keys: map[string][]byte{}}
+
+	s.current, _ = data[fieldCurrent].(string)
+	s.previous, _ = data[fieldPrevious].(string)
+
+	if s.current == "" {
+		return secret{}, errors.New("statekey: current key is not set")
+	}
+
+	if rotatedAt, _ := data[fieldRotatedAt].(string); rotatedAt != "" {
+		t, err := time.Parse(time.RFC3339, rotatedAt)
+		if err != nil {
+			return secret{}, fmt.Errorf("statekey: invalid %s: %w", fieldRotatedAt, err)
+		}
+
+		s.rotatedAt = t
+	}
+
+	for _, kid := range []string{s.current, s.previous} {
+		if kid == "" {
+			continue
+		}
+
+		encoded, _ := data[kid].(string)
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != keySize {
+			return secret{}, fmt.Errorf("statekey: key %s must be %d bytes in base64", kid, keySize)
+		}
+
+		s.keys[kid] = key
+	}
+
+	return s, nil
+}
+
+func (s secret) data() map[string]interface{} {
+	data := map[string]interface{}{
+		fieldCurrent:   s.current,
+		fieldRotatedAt: s.rotatedAt.Format(time.RFC3339),
+	}
+
+	if s.previous != "" {
+		data[fieldPrevious] = s.previous
+	}
+
+	for kid, key := range s.keys {
+		data[kid] = base64.StdEncoding.EncodeToString(key)
+	}
+
+	return data
+}
+
+func generate() (string, []byte, error) {
+	kid, err := id.Generate(kidLength)
+	if err != nil {
+		return "", nil, fmt.Errorf("statekey: error generate kid: %w", err)
+	}
+
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", nil, fmt.Errorf("statekey: error generate key: %w", err)
+	}
+
+	return kid, key, nil
+}
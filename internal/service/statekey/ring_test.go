@@ -0,0 +1,226 @@
+package statekey
+
+import (
+	"auth-service/internal/storage/vault"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKV - секрет Vault в памяти.
+type fakeKV struct {
+	mu     sync.Mutex
+	data   map[string]interface{}
+	err    error
+	writes int
+}
+
+func (f *fakeKV) ReadKV(_ context.Context, path string) (map[string]interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.data == nil {
+		return nil, fmt.Errorf("%w: %s", vault.ErrSecretNotFound, path)
+	}
+
+	data := make(map[string]interface{}, len(f.data))
+	for k, v := range f.data {
+		data[k] = v
+	}
+
+	return data, nil
+}
+
+func (f *fakeKV) WriteKV(_ context.Context, _ string, data map[string]interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return f.err
+	}
+
+	f.data = data
+	f.writes++
+
+	return nil
+}
+
+func (f *fakeKV) set(data map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.data = data
+}
+
+func key(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), keySize)))
+}
+
+func newRing(t *testing.T, kv *fakeKV, opts ...Option) *Ring {
+	t.Helper()
+
+	r, err := New(t.Context(), append([]Option{WithClient(kv), WithRegisterer(prometheus.NewRegistry())}, opts...)...)
+	require.NoError(t, err)
+
+	return r
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    map[string]interface{}
+		opts    []Option
+		wantErr string
+		check   func(t *testing.T, r *Ring, kv *fakeKV)
+	}{
+		{
+			name: "positive case: keys from vault",
+			data: map[string]interface{}{"current": "k2", "previous": "k1", "k1": key('a'), "k2": key('b'), "rotated_at": "2026-01-01T00:00:00Z"},
+			check: func(t *testing.T, r *Ring, kv *fakeKV) {
+				t.Helper()
+
+				assert.Equal(t, "k2", r.current)
+				assert.Len(t, r.keys, 2)
+				assert.Equal(t, 0, kv.writes)
+			},
+		},
+		{
+			name: "positive case: first key is created when rotation is enabled",
+			opts: []Option{WithRotationPeriod(time.Hour)},
+			check: func(t *testing.T, r *Ring, kv *fakeKV) {
+				t.Helper()
+
+				assert.Equal(t, 1, kv.writes)
+				assert.Equal(t, r.current, kv.data["current"])
+				assert.Len(t, r.keys[r.current], keySize)
+			},
+		},
+		{
+			name:    "error case: no secret without rotation",
+			wantErr: "statekey: error read keys: vault: secret not found: secret/data/auth/state-keys",
+		},
+		{
+			name:    "error case: current key is not set",
+			data:    map[string]interface{}{"k1": key('a')},
+			wantErr: "statekey: current key is not set",
+		},
+		{
+			name:    "error case: invalid key",
+			data:    map[string]interface{}{"current": "k1", "k1": "c2hvcnQ="},
+			wantErr: "statekey: key k1 must be 32 bytes in base64",
+		},
+		{
+			name:    "error case: negative rotation period",
+			opts:    []Option{WithRotationPeriod(-time.Hour)},
+			wantErr: "rotation period must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			kv := &fakeKV{data: tt.data}
+
+			r, err := New(t.Context(), append([]Option{WithClient(kv), WithRegisterer(prometheus.NewRegistry())}, tt.opts...)...)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			tt.check(t, r, kv)
+		})
+	}
+}
+
+func TestRing_Rotate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	kv := &fakeKV{data: map[string]interface{}{"current": "k1", "k1": key('a'), "rotated_at": "2026-03-01T11:30:00Z"}}
+
+	r := newRing(t, kv, WithRotationPeriod(time.Hour))
+	r.now = func() time.Time { return now }
+
+	sealed, err := r.Seal("oauth_state", []byte("state"), 3*time.Hour)
+	require.NoError(t, err)
+
+	// период не истек - ключ не меняется
+	require.NoError(t, r.Rotate(t.Context()))
+	assert.Equal(t, "k1", r.current)
+	assert.Equal(t, 0, kv.writes)
+
+	// период истек - новый ключ становится текущим, старый - предыдущим
+	now = now.Add(time.Hour)
+
+	require.NoError(t, r.Rotate(t.Context()))
+	assert.NotEqual(t, "k1", r.current)
+	assert.Equal(t, "k1", kv.data["previous"])
+	assert.Equal(t, "2026-03-01T13:00:00Z", kv.data["rotated_at"])
+
+	// значение, зашифрованное предыдущим ключом, расшифровывается
+	got, err := r.Open(t.Context(), "oauth_state", sealed)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("state"), got)
+
+	// после следующей ротации ключ до предыдущего удаляется
+	now = now.Add(time.Hour)
+
+	require.NoError(t, r.Rotate(t.Context()))
+	assert.NotContains(t, kv.data, "k1")
+
+	_, err = r.Open(t.Context(), "oauth_state", sealed)
+	require.ErrorIs(t, err, ErrInvalid)
+
+	// ошибка записи - ключи не меняются
+	current := r.current
+	kv.err = errors.New("permission denied")
+	now = now.Add(time.Hour)
+
+	require.Error(t, r.Rotate(t.Context()))
+	assert.Equal(t, current, r.current)
+
+	assert.InDelta(t, 1, testutil.ToFloat64(r.operations.WithLabelValues(operationRotate, resultError)), 0)
+}
+
+func TestRing_Start(t *testing.T) {
+	t.Parallel()
+
+	kv := &fakeKV{data: map[string]interface{}{"current": "k1", "k1": key('a'), "rotated_at": "2026-03-01T11:30:00Z"}}
+
+	r := newRing(t, kv, WithReloadInterval(time.Millisecond))
+
+	// ключ сменили в Vault - новый ключ подхватывается
+	kv.set(map[string]interface{}{"current": "k2", "previous": "k1", "k1": key('a'), "k2": key('b'), "rotated_at": "2026-03-02T00:00:00Z"})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- r.Start(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+
+		return r.current == "k2"
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,139 @@
+package statekey
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// expiresSize - размер срока действия в начале зашифрованных данных (unix время, big endian).
+const expiresSize = 8
+
+var (
+	// ErrInvalid - значение повреждено, подделано, зашифровано для другого назначения или неизвестным ключом.
+	ErrInvalid = errors.New("invalid sealed value")
+	// ErrExpired - срок действия значения истек.
+	ErrExpired = errors.New("sealed value expired")
+)
+
+// Seal шифрует data текущим ключом (AES-256-GCM) и возвращает значение вида <kid>.<base64url>,
+// которое можно отдать клиенту. purpose - назначение значения (например, "oauth_state"): оно
+// аутентифицируется вместе с данными, поэтому значение одного назначения не принимается для другого.
+// Значение действует ttl.
+func (r *Ring) Seal(purpose string, data []byte, ttl time.Duration) (string, error) {
+	if ttl <= 0 {
+		return "", errors.New("statekey: ttl must be positive")
+	}
+
+	r.mu.RLock()
+	kid, key := r.current, r.keys[r.current]
+	expires := r.now().Add(ttl)
+	r.mu.RUnlock()
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext := make([]byte, expiresSize, expiresSize+len(data))
+	binary.BigEndian.PutUint64(plaintext, uint64(expires.Unix())) //nolint:gosec // время после 1970 года
+	plaintext = append(plaintext, data...)
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("statekey: error generate nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(purpose))
+
+	return kid + "." + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Open расшифровывает значение, полученное от Seal с тем же purpose, и возвращает данные.
+// Принимаются значения, зашифрованные текущим и предыдущим ключом.
+func (r *Ring) Open(ctx context.Context, purpose, value string) ([]byte, error) {
+	kid, encoded, ok := strings.Cut(value, ".")
+	if !ok {
+		return nil, ErrInvalid
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	key, ok := r.key(ctx, kid)
+	if !ok {
+		return nil, ErrInvalid
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrInvalid
+	}
+
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(purpose))
+	if err != nil || len(plaintext) < expiresSize {
+		return nil, ErrInvalid
+	}
+
+	expires := time.Unix(int64(binary.BigEndian.Uint64(plaintext)), 0) //nolint:gosec // записано в Seal
+	if !r.now().Before(expires) {
+		return nil, ErrExpired
+	}
+
+	return plaintext[expiresSize:], nil
+}
+
+// key возвращает ключ по kid. Неизвестный kid может означать, что другой экземпляр только что
+// выполнил ротацию, поэтому секрет перечитывается, но не чаще minUnknownReload.
+func (r *Ring) key(ctx context.Context, kid string) ([]byte, bool) {
+	r.mu.Lock()
+
+	key, ok := r.keys[kid]
+	if ok || r.now().Sub(r.lastReload) < minUnknownReload {
+		r.mu.Unlock()
+
+		return key, ok
+	}
+
+	// попытка учитывается и при ошибке, чтобы недоступный Vault не получал запрос на каждое значение
+	r.lastReload = r.now()
+	r.mu.Unlock()
+
+	if err := r.Reload(ctx); err != nil {
+		return nil, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok = r.keys[kid]
+
+	return key, ok
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("statekey: error create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("statekey: error create gcm: %w", err)
+	}
+
+	return aead, nil
+}
@@ -0,0 +1,115 @@
+package statekey
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestRing_Open(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	kv := &fakeKV{data: map[string]interface{}{"current": "k1", "k1": key('a')}}
+
+	r := newRing(t, kv)
+	r.now = func() time.Time { return now }
+
+	sealed, err := r.Seal("oauth_state", []byte("github:abc"), time.Minute)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sealed, "k1."))
+
+	tests := []struct {
+		name    string
+		purpose string
+		value   func() string
+		at      time.Time
+		want    []byte
+		wantErr error
+	}{
+		{
+			name:    "positive case",
+			purpose: "oauth_state",
+			value:   func() string { return sealed },
+			at:      now,
+			want:    []byte("github:abc"),
+		},
+		{
+			name:    "error case: other purpose",
+			purpose: "csrf",
+			value:   func() string { return sealed },
+			at:      now,
+			wantErr: ErrInvalid,
+		},
+		{
+			name:    "error case: expired",
+			purpose: "oauth_state",
+			value:   func() string { return sealed },
+			at:      now.Add(time.Minute),
+			wantErr: ErrExpired,
+		},
+		{
+			name:    "error case: tampered",
+			purpose: "oauth_state",
+			value:   func() string { return sealed[:len(sealed)-2] + "AA" },
+			at:      now,
+			wantErr: ErrInvalid,
+		},
+		{
+			name:    "error case: unknown key",
+			purpose: "oauth_state",
+			value:   func() string { return "k9" + strings.TrimPrefix(sealed, "k1") },
+			at:      now,
+			wantErr: ErrInvalid,
+		},
+		{
+			name:    "error case: malformed",
+			purpose: "oauth_state",
+			value:   func() string { return "garbage" },
+			at:      now,
+			wantErr: ErrInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ring := newRing(t, kv)
+			ring.now = func() time.Time { return tt.at }
+
+			got, err := ring.Open(t.Context(), tt.purpose, tt.value())
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRing_Open_UnknownKey(t *testing.T) {
+	t.Parallel()
+
+	kv := &fakeKV{data: map[string]interface{}{"current": "k1", "k1": key('a')}}
+
+	// другой экземпляр выполнил ротацию и зашифровал значение новым ключом
+	other := newRing(t, kv, WithRotationPeriod(time.Nanosecond))
+	require.NoError(t, other.Rotate(t.Context()))
+
+	sealed, err := other.Seal("oauth_state", []byte("state"), time.Minute)
+	require.NoError(t, err)
+
+	r := newRing(t, kv)
+	r.lastReload = time.Time{}
+
+	got, err := r.Open(t.Context(), "oauth_state", sealed)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("state"), got)
+}
@@ -0,0 +1,309 @@
+// Package stats ведет агрегированную статистику для продуктовой аналитики: количество входов
+// и уникальных пользователей по дням, активные сессии и выпущенные токены по аудиториям.
+// Счетчики хранятся в Redis и общие для всех экземпляров сервиса, поэтому для ответа на
+// вопросы вида "сколько пользователей входило на прошлой неделе" не нужно разбирать журналы.
+package stats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	keyPrefix = "auth:stats:"
+
+	// dayLayout - формат дня в ключах и ответе.
+	dayLayout = "2006-01-02"
+
+	// DefaultRetention - сколько хранится статистика за день по умолчанию.
+	DefaultRetention = 90 * 24 * time.Hour
+
+	// NoAudience - аудитория, под которой учитываются токены без claim aud.
+	NoAudience = "-"
+)
+
+// ErrInvalidPeriod - запрошенный период пуст или длиннее срока хранения статистики.
+var ErrInvalidPeriod = errors.New("invalid stats period")
+
+// Day - статистика за календарный день (UTC).
+type Day struct {
+	Date        string `json:"date"`
+	Logins      int64  `json:"logins"`
+	UniqueUsers int64  `json:"unique_users"`
+	// Issued - количество выпущенных токенов по аудиториям.
+	Issued map[string]int64 `json:"issued"`
+}
+
+// Summary - статистика за период.
+type Summary struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Logins int64  `json:"logins"`
+	// UniqueUsers - оценка количества разных пользователей, входивших за период (HyperLogLog,
+	// погрешность около 1%).
+	UniqueUsers int64 `json:"unique_users"`
+	// ActiveSessions - количество действующих сессий на момент запроса.
+	ActiveSessions int64            `json:"active_sessions"`
+	Issued         map[string]int64 `json:"issued"`
+	Days           []Day            `json:"days"`
+}
+
+// Service - статистика в Redis.
+//
+// Ключи:
+//   - auth:stats:logins:<YYYY-MM-DD> - количество входов за день;
+//   - auth:stats:{users}:<YYYY-MM-DD> - HyperLogLog пользователей, входивших за день. Ключи всех
+//     дней в одном слоте кластера, чтобы считать уникальных пользователей за период одной командой;
+//   - auth:stats:issued:<YYYY-MM-DD> - hash аудитория: количество выпущенных токенов за день;
+//   - auth:stats:sessions - sorted set сессий со временем истечения (unix) в качестве score.
+//
+// Ключи дней истекают через срок хранения.
+type Service struct {
+	client    redis.UniversalClient
+	retention time.Duration
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Service.
+type Option func(*Service)
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(s *Service) {
+		s.client = client
+	}
+}
+
+// WithRetention устанавливает срок хранения статистики за день. По умолчанию DefaultRetention.
+func WithRetention(retention time.Duration) Option {
+	return func(s *Service) {
+		s.retention = retention
+	}
+}
+
+// New создает новый Service.
+func New(opts ...Option) (*Service, error) {
+	s := &Service{
+		retention: DefaultRetention,
+		now:       time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.client == nil {
+		return nil, errors.New("redis client is required")
+	}
+
+	if s.retention < 24*time.Hour {
+		return nil, errors.New("retention must be at least 24h")
+	}
+
+	return s, nil
+}
+
+func loginsKey(day string) string {
+	return keyPrefix + "logins:" + day
+}
+
+func usersKey(day string) string {
+	return keyPrefix + "{users}:" + day
+}
+
+func issuedKey(day string) string {
+	return keyPrefix + "issued:" + day
+}
+
+func sessionsKey() string {
+	return keyPrefix + "sessions"
+}
+
+// MaxDays возвращает наибольшую длину периода в днях, за который хранится статистика.
+func (s *Service) MaxDays() int {
+	return int(s.retention / (24 * time.Hour))
+}
+
+// day возвращает текущий день и время, когда истекает его статистика.
+func (s *Service) day() (string, time.Time) {
+	today := s.now().UTC().Truncate(24 * time.Hour)
+
+	return today.Format(dayLayout), today.Add(s.retention)
+}
+
+// TokenIssued учитывает выпуск токена для каждой его аудитории.
+func (s *Service) TokenIssued(ctx context.Context, audience []string) error {
+	if len(audience) == 0 {
+		audience = []string{NoAudience}
+	}
+
+	day, expireAt := s.day()
+	key := issuedKey(day)
+
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for _, aud := range audience {
+			p.HIncrBy(ctx, key, aud, 1)
+		}
+
+		p.ExpireAt(ctx, key, expireAt)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("stats: error count issued token: %w", err)
+	}
+
+	return nil
+}
+
+// Login учитывает вход пользователя subject.
+func (s *Service) Login(ctx context.Context, subject string) error {
+	day, expireAt := s.day()
+
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Incr(ctx, loginsKey(day))
+		p.ExpireAt(ctx, loginsKey(day), expireAt)
+		p.PFAdd(ctx, usersKey(day), subject)
+		p.ExpireAt(ctx, usersKey(day), expireAt)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("stats: error count login: %w", err)
+	}
+
+	return nil
+}
+
+// SessionStarted учитывает сессию session, которая действует до expiresAt.
+func (s *Service) SessionStarted(ctx context.Context, session string, expiresAt time.Time) error {
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.ZAdd(ctx, sessionsKey(), redis.Z{Score: float64(expiresAt.Unix()), Member: session})
+		// истекшие сессии удаляются здесь же, чтобы множество не росло без чтения статистики
+		p.ZRemRangeByScore(ctx, sessionsKey(), "-inf", strconv.FormatInt(s.now().Unix(), 10))
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("stats: error count session: %w", err)
+	}
+
+	return nil
+}
+
+// SessionEnded учитывает досрочное завершение сессии (выход или отзыв).
+func (s *Service) SessionEnded(ctx context.Context, session string) error {
+	if err := s.client.ZRem(ctx, sessionsKey(), session).Err(); err != nil {
+		return fmt.Errorf("stats: error end session: %w", err)
+	}
+
+	return nil
+}
+
+// Summary возвращает статистику за последние days дней, включая текущий.
+func (s *Service) Summary(ctx context.Context, days int) (*Summary, error) {
+	if days < 1 || days > s.MaxDays() {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidPeriod, s.MaxDays())
+	}
+
+	now := s.now()
+	today := now.UTC().Truncate(24 * time.Hour)
+
+	dates := make([]string, days)
+	users := make([]string, days)
+
+	for i := range dates {
+		dates[i] = today.AddDate(0, 0, i-days+1).Format(dayLayout)
+		users[i] = usersKey(dates[i])
+	}
+
+	var (
+		logins   = make([]*redis.StringCmd, days)
+		unique   = make([]*redis.IntCmd, days)
+		issued   = make([]*redis.MapStringStringCmd, days)
+		total    *redis.IntCmd
+		sessions *redis.IntCmd
+	)
+
+	// первой в конвейере должна идти команда, которая не возвращает redis.Nil: ошибка первой
+	// команды проставляется клиентом всем остальным
+	cmds, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.ZRemRangeByScore(ctx, sessionsKey(), "-inf", strconv.FormatInt(now.Unix(), 10))
+		sessions = p.ZCard(ctx, sessionsKey())
+		total = p.PFCount(ctx, users...)
+
+		for i, date := range dates {
+			logins[i] = p.Get(ctx, loginsKey(date))
+			unique[i] = p.PFCount(ctx, users[i])
+			issued[i] = p.HGetAll(ctx, issuedKey(date))
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("stats: error get stats: %w", err)
+	}
+
+	// Pipelined возвращает только первую ошибку, отсутствующий счетчик дня (redis.Nil) ошибкой не является
+	for _, cmd := range cmds {
+		if err := cmd.Err(); err != nil && !errors.Is(err, redis.Nil) {
+			return nil, fmt.Errorf("stats: error get stats: %w", err)
+		}
+	}
+
+	summary := &Summary{
+		From:           dates[0],
+		To:             dates[days-1],
+		UniqueUsers:    total.Val(),
+		ActiveSessions: sessions.Val(),
+		Issued:         map[string]int64{},
+		Days:           make([]Day, days),
+	}
+
+	for i, date := range dates {
+		day := Day{
+			Date:        date,
+			UniqueUsers: unique[i].Val(),
+			Issued:      map[string]int64{},
+		}
+
+		if day.Logins, err = counter(logins[i]); err != nil {
+			return nil, err
+		}
+
+		for aud, raw := range issued[i].Val() {
+			n, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("stats: invalid issued counter %q for %s: %w", raw, aud, err)
+			}
+
+			day.Issued[aud] = n
+			summary.Issued[aud] += n
+		}
+
+		summary.Logins += day.Logins
+		summary.Days[i] = day
+	}
+
+	return summary, nil
+}
+
+// counter возвращает значение счетчика, отсутствующий счетчик - 0.
+func counter(cmd *redis.StringCmd) (int64, error) {
+	n, err := cmd.Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("stats: invalid counter %s: %w", cmd.Args()[1], err)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,118 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newService(t *testing.T, opts ...Option) (*Service, *miniredis.Miniredis, *time.Time) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	s, err := New(append([]Option{WithClient(client)}, opts...)...)
+	require.NoError(t, err)
+
+	now := time.Date(2026, time.October, 15, 23, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	mr.SetTime(now)
+
+	return s, mr, &now
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	t.Cleanup(func() { _ = client.Close() })
+
+	_, err := New()
+	require.EqualError(t, err, "redis client is required")
+
+	_, err = New(WithClient(client), WithRetention(time.Hour))
+	require.EqualError(t, err, "retention must be at least 24h")
+
+	s, err := New(WithClient(client), WithRetention(30*24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 30, s.MaxDays())
+}
+
+func TestSummary(t *testing.T) {
+	t.Parallel()
+
+	s, mr, now := newService(t)
+	ctx := t.Context()
+
+	// вчера: два входа одного пользователя
+	require.NoError(t, s.Login(ctx, "42"))
+	require.NoError(t, s.SessionStarted(ctx, "f1", now.Add(time.Hour)))
+	require.NoError(t, s.Login(ctx, "42"))
+	require.NoError(t, s.SessionStarted(ctx, "f2", now.Add(48*time.Hour)))
+	require.NoError(t, s.TokenIssued(ctx, []string{"bot", "web"}))
+	require.NoError(t, s.TokenIssued(ctx, nil))
+
+	// сегодня: другие пользователи, первая сессия вчерашнего пользователя истекла.
+	// miniredis складывает оценки PFCOUNT нескольких ключей вместо объединения, поэтому
+	// пользователи разных дней не пересекаются
+	*now = now.Add(2 * time.Hour)
+
+	require.NoError(t, s.Login(ctx, "7"))
+	require.NoError(t, s.SessionStarted(ctx, "f3", now.Add(time.Hour)))
+	require.NoError(t, s.Login(ctx, "13"))
+	require.NoError(t, s.SessionStarted(ctx, "f4", now.Add(time.Hour)))
+	require.NoError(t, s.SessionEnded(ctx, "f4"))
+	require.NoError(t, s.TokenIssued(ctx, []string{"bot"}))
+
+	summary, err := s.Summary(ctx, 3)
+	require.NoError(t, err)
+
+	assert.Equal(t, "2026-10-14", summary.From)
+	assert.Equal(t, "2026-10-16", summary.To)
+	assert.Equal(t, int64(4), summary.Logins)
+	assert.Equal(t, int64(3), summary.UniqueUsers)
+	assert.Equal(t, int64(2), summary.ActiveSessions, "f2 and f3 are active")
+	assert.Equal(t, map[string]int64{"bot": 2, "web": 1, NoAudience: 1}, summary.Issued)
+
+	assert.Equal(t, []Day{
+		{Date: "2026-10-14", Issued: map[string]int64{}},
+		{Date: "2026-10-15", Logins: 2, UniqueUsers: 1, Issued: map[string]int64{"bot": 1, "web": 1, NoAudience: 1}},
+		{Date: "2026-10-16", Logins: 2, UniqueUsers: 2, Issued: map[string]int64{"bot": 1}},
+	}, summary.Days)
+
+	// статистика дня истекает через срок хранения, считая от начала дня
+	assert.Equal(t, DefaultRetention-23*time.Hour, mr.TTL(loginsKey("2026-10-15")))
+}
+
+func TestSummary_InvalidPeriod(t *testing.T) {
+	t.Parallel()
+
+	s, _, _ := newService(t, WithRetention(7*24*time.Hour))
+
+	_, err := s.Summary(t.Context(), 0)
+	require.ErrorIs(t, err, ErrInvalidPeriod)
+
+	_, err = s.Summary(t.Context(), 8)
+	require.ErrorIs(t, err, ErrInvalidPeriod)
+
+	_, err = s.Summary(t.Context(), 7)
+	require.NoError(t, err)
+}
+
+func TestSummary_InvalidCounter(t *testing.T) {
+	t.Parallel()
+
+	s, mr, _ := newService(t)
+
+	require.NoError(t, mr.Set(loginsKey("2026-10-15"), "many"))
+
+	_, err := s.Summary(t.Context(), 1)
+	require.ErrorContains(t, err, "invalid counter")
+}
@@ -0,0 +1,339 @@
+package telegram
+
+import (
+	"auth-service/internal/service/token"
+	"auth-service/internal/service/userstore"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Значения входа по умолчанию.
+const (
+	// DefaultMaxAge - сколько действуют данные входа с момента подписи (auth_date).
+	DefaultMaxAge = 24 * time.Hour
+	// DefaultTokenTTL - время жизни токена, выпущенного при входе.
+	DefaultTokenTTL = time.Hour
+	// SubjectPrefix - префикс субъекта токена, если хранилище пользователей не задано: telegram:<id>.
+	SubjectPrefix = "telegram:"
+)
+
+// maxClockSkew - насколько auth_date может опережать часы сервиса.
+const maxClockSkew = time.Minute
+
+var (
+	// ErrInvalidArgument - данные входа не переданы или не разбираются.
+	ErrInvalidArgument = errors.New("invalid telegram login data")
+	// ErrInvalidSignature - подпись данных не совпала: данные подделаны или подписаны другим ботом.
+	ErrInvalidSignature = errors.New("invalid telegram signature")
+	// ErrExpired - данные подписаны слишком давно.
+	ErrExpired = errors.New("telegram login data expired")
+	// ErrUserNotFound - пользователя с этим ID в Telegram нет в хранилище пользователей.
+	ErrUserNotFound = errors.New("telegram user is not registered")
+	// ErrUserDisabled - пользователь отключен в хранилище пользователей.
+	ErrUserDisabled = errors.New("user is disabled")
+)
+
+//go:generate mockgen -source=login.go -destination=mocks/login_mock.go -package=mocks
+type keySource interface {
+	SecretKey() []byte
+	WidgetKey() []byte
+}
+
+type tokenIssuer interface {
+	Issue(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error)
+}
+
+type userStore interface {
+	GetByTelegramID(ctx context.Context, telegramID int64) (*userstore.User, error)
+}
+
+// User - пользователь Telegram из подписанных данных входа.
+type User struct {
+	ID           int64  `json:"id"`
+	FirstName    string `json:"first_name,omitempty"`
+	LastName     string `json:"last_name,omitempty"`
+	Username     string `json:"username,omitempty"`
+	PhotoURL     string `json:"photo_url,omitempty"`
+	LanguageCode string `json:"language_code,omitempty"`
+}
+
+// WidgetData - данные виджета входа Telegram (https://core.telegram.org/widgets/login).
+type WidgetData struct {
+	ID        int64  `json:"id"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+	Username  string `json:"username,omitempty"`
+	PhotoURL  string `json:"photo_url,omitempty"`
+	AuthDate  int64  `json:"auth_date"`
+	Hash      string `json:"hash"`
+}
+
+// Login - токен, выпущенный при входе.
+type Login struct {
+	Token  string
+	Claims *token.Claims
+	User   User
+}
+
+// Authenticator - вход пользователей Telegram: проверяет подпись initData мини-приложения или
+// данных виджета входа ключом, полученным из токена бота, и выпускает токен для пользователя.
+// Если задано хранилище пользователей, субъект токена - ID пользователя с этим ID в Telegram,
+// иначе - telegram:<ID в Telegram>.
+type Authenticator struct {
+	keys   keySource
+	issuer tokenIssuer
+	users  userStore
+
+	maxAge   time.Duration
+	tokenTTL time.Duration
+	audience []string
+
+	now func() time.Time
+}
+
+// AuthenticatorOption - опция для настройки Authenticator.
+type AuthenticatorOption func(*Authenticator)
+
+// WithKeys устанавливает ключи проверки подписи, обычно Secrets.
+func WithKeys(keys keySource) AuthenticatorOption {
+	return func(a *Authenticator) {
+		a.keys = keys
+	}
+}
+
+// WithIssuer устанавливает выпуск токенов.
+func WithIssuer(issuer tokenIssuer) AuthenticatorOption {
+	return func(a *Authenticator) {
+		a.issuer = issuer
+	}
+}
+
+// WithUsers устанавливает хранилище пользователей, в котором ищется пользователь по ID в Telegram.
+// Без него входить может любой пользователь Telegram.
+func WithUsers(users userStore) AuthenticatorOption {
+	return func(a *Authenticator) {
+		a.users = users
+	}
+}
+
+// WithMaxAge устанавливает, сколько действуют данные входа с момента подписи. По умолчанию DefaultMaxAge.
+func WithMaxAge(maxAge time.Duration) AuthenticatorOption {
+	return func(a *Authenticator) {
+		a.maxAge = maxAge
+	}
+}
+
+// WithToken устанавливает время жизни и аудиторию токенов, выпускаемых при входе.
+func WithToken(ttl time.Duration, audience []string) AuthenticatorOption {
+	return func(a *Authenticator) {
+		a.tokenTTL = ttl
+		a.audience = audience
+	}
+}
+
+// NewAuthenticator создает новый Authenticator.
+func NewAuthenticator(opts ...AuthenticatorOption) (*Authenticator, error) {
+	a := &Authenticator{
+		maxAge:   DefaultMaxAge,
+		tokenTTL: DefaultTokenTTL,
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	if a.keys == nil {
+		return nil, errors.New("keys are required")
+	}
+
+	if a.issuer == nil {
+		return nil, errors.New("issuer is required")
+	}
+
+	if a.maxAge <= 0 {
+		return nil, errors.New("max age must be positive")
+	}
+
+	if a.tokenTTL <= 0 {
+		return nil, errors.New("token ttl must be positive")
+	}
+
+	return a, nil
+}
+
+// LoginInitData выполняет вход по initData мини-приложения (Telegram.WebApp.initData).
+func (a *Authenticator) LoginInitData(ctx context.Context, initData string) (*Login, error) {
+	user, err := a.verifyInitData(initData)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.login(ctx, user)
+}
+
+// LoginWidget выполняет вход по данным виджета входа.
+func (a *Authenticator) LoginWidget(ctx context.Context, data WidgetData) (*Login, error) {
+	user, err := a.verifyWidget(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.login(ctx, user)
+}
+
+// verifyInitData проверяет подпись initData и возвращает пользователя из поля user
+// (https://core.telegram.org/bots/webapps#validating-data-received-via-the-mini-app).
+func (a *Authenticator) verifyInitData(initData string) (*User, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidArgument, err)
+	}
+
+	fields := make(map[string]string, len(values))
+
+	for k, v := range values {
+		if len(v) != 1 {
+			return nil, fmt.Errorf("%w: field %s is repeated", ErrInvalidArgument, k)
+		}
+
+		fields[k] = v[0]
+	}
+
+	if err := a.verify(fields, a.keys.SecretKey()); err != nil {
+		return nil, err
+	}
+
+	var user User
+
+	if err := json.Unmarshal([]byte(fields["user"]), &user); err != nil || user.ID == 0 {
+		return nil, fmt.Errorf("%w: user is required", ErrInvalidArgument)
+	}
+
+	return &user, nil
+}
+
+// verifyWidget проверяет подпись данных виджета входа
+// (https://core.telegram.org/widgets/login#checking-authorization).
+func (a *Authenticator) verifyWidget(data WidgetData) (*User, error) {
+	if data.ID == 0 {
+		return nil, fmt.Errorf("%w: id is required", ErrInvalidArgument)
+	}
+
+	fields := map[string]string{
+		"id":         strconv.FormatInt(data.ID, 10),
+		"first_name": data.FirstName,
+		"last_name":  data.LastName,
+		"username":   data.Username,
+		"photo_url":  data.PhotoURL,
+		"auth_date":  strconv.FormatInt(data.AuthDate, 10),
+		"hash":       data.Hash,
+	}
+
+	// виджет подписывает только переданные поля
+	for k, v := range fields {
+		if v == "" {
+			delete(fields, k)
+		}
+	}
+
+	if err := a.verify(fields, a.keys.WidgetKey()); err != nil {
+		return nil, err
+	}
+
+	return &User{
+		ID:        data.ID,
+		FirstName: data.FirstName,
+		LastName:  data.LastName,
+		Username:  data.Username,
+		PhotoURL:  data.PhotoURL,
+	}, nil
+}
+
+// verify проверяет подпись hash полей fields ключом key и срок auth_date. Подписывается строка
+// из всех полей, кроме hash, в порядке имен в формате имя=значение через перевод строки.
+func (a *Authenticator) verify(fields map[string]string, key []byte) error {
+	hash, err := hex.DecodeString(fields["hash"])
+	if err != nil || len(hash) == 0 {
+		return fmt.Errorf("%w: hash is required", ErrInvalidArgument)
+	}
+
+	authDate, err := strconv.ParseInt(fields["auth_date"], 10, 64)
+	if err != nil || authDate <= 0 {
+		return fmt.Errorf("%w: auth_date is required", ErrInvalidArgument)
+	}
+
+	pairs := make([]string, 0, len(fields))
+
+	for k, v := range fields {
+		if k != "hash" {
+			pairs = append(pairs, k+"="+v)
+		}
+	}
+
+	sort.Strings(pairs)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+
+	if !hmac.Equal(mac.Sum(nil), hash) {
+		return ErrInvalidSignature
+	}
+
+	age := a.now().Sub(time.Unix(authDate, 0))
+	if age > a.maxAge || age < -maxClockSkew {
+		return ErrExpired
+	}
+
+	return nil
+}
+
+// login выпускает токен пользователю Telegram.
+func (a *Authenticator) login(ctx context.Context, user *User) (*Login, error) {
+	subject, err := a.subject(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, claims, err := a.issuer.Issue(ctx, token.IssueRequest{
+		Subject:    subject,
+		Audience:   a.audience,
+		TTL:        a.tokenTTL,
+		TelegramID: user.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("telegram: error issue token: %w", err)
+	}
+
+	return &Login{Token: raw, Claims: claims, User: *user}, nil
+}
+
+// subject возвращает субъект токена для пользователя Telegram.
+func (a *Authenticator) subject(ctx context.Context, telegramID int64) (string, error) {
+	if a.users == nil {
+		return SubjectPrefix + strconv.FormatInt(telegramID, 10), nil
+	}
+
+	user, err := a.users.GetByTelegramID(ctx, telegramID)
+
+	switch {
+	case errors.Is(err, userstore.ErrNotFound):
+		return "", ErrUserNotFound
+	case err != nil:
+		return "", fmt.Errorf("telegram: error get user: %w", err)
+	case user.Disabled:
+		return "", ErrUserDisabled
+	}
+
+	return user.ID, nil
+}
@@ -0,0 +1,256 @@
+package telegram
+
+import (
+	"auth-service/internal/service/telegram/mocks"
+	"auth-service/internal/service/token"
+	"auth-service/internal/service/userstore"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBotToken = "123:abc"
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// sign возвращает подпись полей fields ключом key так, как их подписывает Telegram.
+func sign(fields map[string]string, key []byte) string {
+	pairs := make([]string, 0, len(fields))
+
+	for k, v := range fields {
+		pairs = append(pairs, k+"="+v)
+	}
+
+	sort.Strings(pairs)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// initData возвращает подписанный initData мини-приложения пользователя 42, подписанный в момент authDate.
+func initData(authDate time.Time) string {
+	fields := map[string]string{
+		"query_id":  "AAHdF6IQAAAAAN0XohDhrOrc",
+		"user":      `{"id":42,"first_name":"Ivan","username":"ivan","language_code":"ru"}`,
+		"auth_date": strconv.FormatInt(authDate.Unix(), 10),
+	}
+
+	values := url.Values{"hash": {sign(fields, secretKey(testBotToken))}}
+	for k, v := range fields {
+		values.Set(k, v)
+	}
+
+	return values.Encode()
+}
+
+// widgetData возвращает подписанные данные виджета входа пользователя 42.
+func widgetData(authDate time.Time) WidgetData {
+	data := WidgetData{ID: 42, FirstName: "Ivan", Username: "ivan", AuthDate: authDate.Unix()}
+	data.Hash = sign(map[string]string{
+		"id":         "42",
+		"first_name": "Ivan",
+		"username":   "ivan",
+		"auth_date":  strconv.FormatInt(authDate.Unix(), 10),
+	}, widgetKey(testBotToken))
+
+	return data
+}
+
+func newTestAuthenticator(t *testing.T, opts ...AuthenticatorOption) (*Authenticator, *mocks.MocktokenIssuer) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+
+	keys := mocks.NewMockkeySource(ctrl)
+	keys.EXPECT().SecretKey().Return(secretKey(testBotToken)).AnyTimes()
+	keys.EXPECT().WidgetKey().Return(widgetKey(testBotToken)).AnyTimes()
+
+	issuer := mocks.NewMocktokenIssuer(ctrl)
+
+	a, err := NewAuthenticator(append([]AuthenticatorOption{
+		WithKeys(keys),
+		WithIssuer(issuer),
+		WithToken(time.Hour, []string{"bot"}),
+	}, opts...)...)
+	require.NoError(t, err)
+
+	a.now = func() time.Time { return testNow }
+
+	return a, issuer
+}
+
+func TestNewAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	keys := mocks.NewMockkeySource(ctrl)
+	issuer := mocks.NewMocktokenIssuer(ctrl)
+
+	_, err := NewAuthenticator(WithIssuer(issuer))
+	require.ErrorContains(t, err, "keys are required")
+
+	_, err = NewAuthenticator(WithKeys(keys))
+	require.ErrorContains(t, err, "issuer is required")
+
+	_, err = NewAuthenticator(WithKeys(keys), WithIssuer(issuer), WithMaxAge(-time.Second))
+	require.ErrorContains(t, err, "max age must be positive")
+
+	_, err = NewAuthenticator(WithKeys(keys), WithIssuer(issuer), WithToken(0, nil))
+	require.ErrorContains(t, err, "token ttl must be positive")
+
+	a, err := NewAuthenticator(WithKeys(keys), WithIssuer(issuer))
+	require.NoError(t, err)
+	assert.Equal(t, DefaultMaxAge, a.maxAge)
+	assert.Equal(t, DefaultTokenTTL, a.tokenTTL)
+}
+
+func TestAuthenticator_LoginInitData(t *testing.T) {
+	t.Parallel()
+
+	a, issuer := newTestAuthenticator(t)
+
+	claims := &token.Claims{}
+	issuer.EXPECT().Issue(gomock.Any(), token.IssueRequest{
+		Subject:    "telegram:42",
+		Audience:   []string{"bot"},
+		TTL:        time.Hour,
+		TelegramID: 42,
+	}).Return("jwt", claims, nil)
+
+	login, err := a.LoginInitData(t.Context(), initData(testNow.Add(-time.Minute)))
+	require.NoError(t, err)
+	assert.Equal(t, "jwt", login.Token)
+	assert.Same(t, claims, login.Claims)
+	assert.Equal(t, User{ID: 42, FirstName: "Ivan", Username: "ivan", LanguageCode: "ru"}, login.User)
+
+	tests := []struct {
+		name     string
+		initData string
+		wantErr  error
+	}{
+		{name: "empty", initData: "", wantErr: ErrInvalidArgument},
+		{name: "malformed", initData: "%zz", wantErr: ErrInvalidArgument},
+		{name: "without hash", initData: "auth_date=1&user=%7B%7D", wantErr: ErrInvalidArgument},
+		{
+			name:     "tampered",
+			initData: strings.Replace(initData(testNow), "ivan", "admin", 1),
+			wantErr:  ErrInvalidSignature,
+		},
+		{
+			name:     "repeated field",
+			initData: initData(testNow) + "&auth_date=1",
+			wantErr:  ErrInvalidArgument,
+		},
+		{name: "expired", initData: initData(testNow.Add(-DefaultMaxAge - time.Second)), wantErr: ErrExpired},
+		{name: "from the future", initData: initData(testNow.Add(time.Hour)), wantErr: ErrExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := a.LoginInitData(t.Context(), tt.initData)
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestAuthenticator_LoginWidget(t *testing.T) {
+	t.Parallel()
+
+	a, issuer := newTestAuthenticator(t)
+
+	issuer.EXPECT().Issue(gomock.Any(), gomock.Any()).Return("jwt", &token.Claims{}, nil)
+
+	login, err := a.LoginWidget(t.Context(), widgetData(testNow))
+	require.NoError(t, err)
+	assert.Equal(t, User{ID: 42, FirstName: "Ivan", Username: "ivan"}, login.User)
+
+	// подпись initData не подходит виджету: у них разные ключи
+	data := widgetData(testNow)
+	data.Hash = sign(map[string]string{
+		"id": "42", "first_name": "Ivan", "username": "ivan", "auth_date": strconv.FormatInt(testNow.Unix(), 10),
+	}, secretKey(testBotToken))
+
+	_, err = a.LoginWidget(t.Context(), data)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	data = widgetData(testNow)
+	data.Username = "admin"
+
+	_, err = a.LoginWidget(t.Context(), data)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	_, err = a.LoginWidget(t.Context(), WidgetData{})
+	require.ErrorIs(t, err, ErrInvalidArgument)
+}
+
+func TestAuthenticator_Users(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		user    *userstore.User
+		err     error
+		want    string
+		wantErr error
+	}{
+		{name: "registered user", user: &userstore.User{ID: "user-1", TelegramID: 42}, want: "user-1"},
+		{name: "unknown user", err: userstore.ErrNotFound, wantErr: ErrUserNotFound},
+		{name: "disabled user", user: &userstore.User{ID: "user-1", Disabled: true}, wantErr: ErrUserDisabled},
+		{name: "store unavailable", err: userstore.ErrUnavailable, wantErr: userstore.ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			users := mocks.NewMockuserStore(gomock.NewController(t))
+			users.EXPECT().GetByTelegramID(gomock.Any(), int64(42)).Return(tt.user, tt.err)
+
+			a, issuer := newTestAuthenticator(t, WithUsers(users))
+
+			if tt.wantErr == nil {
+				issuer.EXPECT().Issue(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ any, req token.IssueRequest) (string, *token.Claims, error) {
+						assert.Equal(t, tt.want, req.Subject)
+
+						return "jwt", &token.Claims{}, nil
+					})
+			}
+
+			_, err := a.LoginInitData(t.Context(), initData(testNow))
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestAuthenticator_IssueError(t *testing.T) {
+	t.Parallel()
+
+	a, issuer := newTestAuthenticator(t)
+
+	issuer.EXPECT().Issue(gomock.Any(), gomock.Any()).Return("", nil, errors.New("vault is down"))
+
+	_, err := a.LoginWidget(t.Context(), widgetData(testNow))
+	require.ErrorContains(t, err, "vault is down")
+}
@@ -0,0 +1,142 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: login.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	token "auth-service/internal/service/token"
+	userstore "auth-service/internal/service/userstore"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockkeySource is a mock of keySource interface.
+type MockkeySource struct {
+	ctrl     *gomock.Controller
+	recorder *MockkeySourceMockRecorder
+}
+
+// MockkeySourceMockRecorder is the mock recorder for MockkeySource.
+type MockkeySourceMockRecorder struct {
+	mock *MockkeySource
+}
+
+// NewMockkeySource creates a new mock instance.
+func NewMockkeySource(ctrl *gomock.Controller) *MockkeySource {
+	mock := &MockkeySource{ctrl: ctrl}
+	mock.recorder = &MockkeySourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockkeySource) EXPECT() *MockkeySourceMockRecorder {
+	return m.recorder
+}
+
+// SecretKey mocks base method.
+func (m *MockkeySource) SecretKey() []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SecretKey")
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// SecretKey indicates an expected call of SecretKey.
+func (mr *MockkeySourceMockRecorder) SecretKey() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SecretKey", reflect.TypeOf((*MockkeySource)(nil).SecretKey))
+}
+
+// WidgetKey mocks base method.
+func (m *MockkeySource) WidgetKey() []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WidgetKey")
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// WidgetKey indicates an expected call of WidgetKey.
+func (mr *MockkeySourceMockRecorder) WidgetKey() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WidgetKey", reflect.TypeOf((*MockkeySource)(nil).WidgetKey))
+}
+
+// MocktokenIssuer is a mock of tokenIssuer interface.
+type MocktokenIssuer struct {
+	ctrl     *gomock.Controller
+	recorder *MocktokenIssuerMockRecorder
+}
+
+// MocktokenIssuerMockRecorder is the mock recorder for MocktokenIssuer.
+type MocktokenIssuerMockRecorder struct {
+	mock *MocktokenIssuer
+}
+
+// NewMocktokenIssuer creates a new mock instance.
+func NewMocktokenIssuer(ctrl *gomock.Controller) *MocktokenIssuer {
+	mock := &MocktokenIssuer{ctrl: ctrl}
+	mock.recorder = &MocktokenIssuerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktokenIssuer) EXPECT() *MocktokenIssuerMockRecorder {
+	return m.recorder
+}
+
+// Issue mocks base method.
+func (m *MocktokenIssuer) Issue(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Issue", ctx, req)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*token.Claims)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Issue indicates an expected call of Issue.
+func (mr *MocktokenIssuerMockRecorder) Issue(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Issue", reflect.TypeOf((*MocktokenIssuer)(nil).Issue), ctx, req)
+}
+
+// MockuserStore is a mock of userStore interface.
+type MockuserStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockuserStoreMockRecorder
+}
+
+// MockuserStoreMockRecorder is the mock recorder for MockuserStore.
+type MockuserStoreMockRecorder struct {
+	mock *MockuserStore
+}
+
+// NewMockuserStore creates a new mock instance.
+func NewMockuserStore(ctrl *gomock.Controller) *MockuserStore {
+	mock := &MockuserStore{ctrl: ctrl}
+	mock.recorder = &MockuserStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockuserStore) EXPECT() *MockuserStoreMockRecorder {
+	return m.recorder
+}
+
+// GetByTelegramID mocks base method.
+func (m *MockuserStore) GetByTelegramID(ctx context.Context, telegramID int64) (*userstore.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByTelegramID", ctx, telegramID)
+	ret0, _ := ret[0].(*userstore.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByTelegramID indicates an expected call of GetByTelegramID.
+func (mr *MockuserStoreMockRecorder) GetByTelegramID(ctx, telegramID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByTelegramID", reflect.TypeOf((*MockuserStore)(nil).GetByTelegramID), ctx, telegramID)
+}
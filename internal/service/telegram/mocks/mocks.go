@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: secrets.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockkvReader is a mock of kvReader interface.
+type MockkvReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockkvReaderMockRecorder
+}
+
+// MockkvReaderMockRecorder is the mock recorder for MockkvReader.
+type MockkvReaderMockRecorder struct {
+	mock *MockkvReader
+}
+
+// NewMockkvReader creates a new mock instance.
+func NewMockkvReader(ctrl *gomock.Controller) *MockkvReader {
+	mock := &MockkvReader{ctrl: ctrl}
+	mock.recorder = &MockkvReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockkvReader) EXPECT() *MockkvReaderMockRecorder {
+	return m.recorder
+}
+
+// ReadKV mocks base method.
+func (m *MockkvReader) ReadKV(ctx context.Context, path string) (map[string]interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadKV", ctx, path)
+	ret0, _ := ret[0].(map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadKV indicates an expected call of ReadKV.
+func (mr *MockkvReaderMockRecorder) ReadKV(ctx, path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadKV", reflect.TypeOf((*MockkvReader)(nil).ReadKV), ctx, path)
+}
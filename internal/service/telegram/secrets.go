@@ -0,0 +1,238 @@
+// Package telegram хранит секреты Telegram бота: токен бота и производные от него ключи проверки
+// подписи initData мини-приложений и данных виджета входа, и выполняет вход по этим данным. Токен читается из Vault при старте и периодически
+// перечитывается, поэтому смена токена бота не требует перезапуска сервиса. Если Vault недоступен,
+// продолжает действовать последний успешно прочитанный токен.
+package telegram
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// Значения по умолчанию.
+const (
+	DefaultPath           = "secret/data/auth/telegram"
+	DefaultReloadInterval = 5 * time.Minute
+)
+
+// tokenField - поле секрета с токеном бота.
+const tokenField = "bot_token"
+
+// webAppDataKey - ключ HMAC, которым из токена бота получается ключ проверки initData
+// (https://core.telegram.org/bots/webapps#validating-data-received-via-the-mini-app).
+const webAppDataKey = "WebAppData"
+
+// Результаты перечитывания секрета в метриках.
+const (
+	reloadOK    = "ok"
+	reloadError = "error"
+)
+
+// kvReader - интерфейс для чтения секретов KV из Vault.
+//
+//go:generate mockgen -source=secrets.go -destination=mocks/mocks.go -package=mocks
+type kvReader interface {
+	ReadKV(ctx context.Context, path string) (map[string]interface{}, error)
+}
+
+// Secrets - секреты Telegram бота из Vault.
+type Secrets struct {
+	client   kvReader
+	path     string
+	interval time.Duration
+
+	mu        sync.RWMutex
+	token     string
+	secretKey []byte
+	widgetKey []byte
+
+	registerer prometheus.Registerer
+	reloads    *prometheus.CounterVec
+	loadedAt   prometheus.Gauge
+}
+
+// Option - опция для настройки Secrets.
+type Option func(*Secrets)
+
+// WithKVReader устанавливает клиент Vault.
+func WithKVReader(client kvReader) Option {
+	return func(s *Secrets) {
+		s.client = client
+	}
+}
+
+// WithPath устанавливает путь к секрету Vault KV v2 с полем bot_token. По умолчанию DefaultPath.
+func WithPath(path string) Option {
+	return func(s *Secrets) {
+		s.path = path
+	}
+}
+
+// WithReloadInterval устанавливает периодичность перечитывания секрета. По умолчанию DefaultReloadInterval.
+func WithReloadInterval(interval time.Duration) Option {
+	return func(s *Secrets) {
+		s.interval = interval
+	}
+}
+
+// WithRegisterer устанавливает реестр метрик. По умолчанию используется prometheus.DefaultRegisterer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(s *Secrets) {
+		s.registerer = registerer
+	}
+}
+
+// New создает секреты и читает токен бота из Vault. Ошибка чтения при старте фатальна:
+// без токена нельзя проверить ни одну подпись.
+func New(ctx context.Context, opts ...Option) (*Secrets, error) {
+	s := &Secrets{
+		path:       DefaultPath,
+		interval:   DefaultReloadInterval,
+		registerer: prometheus.DefaultRegisterer,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.client == nil {
+		return nil, errors.New("vault client is required")
+	}
+
+	if s.path == "" {
+		return nil, errors.New("path is required")
+	}
+
+	if s.interval <= 0 {
+		return nil, errors.New("reload interval must be positive")
+	}
+
+	if s.registerer == nil {
+		return nil, errors.New("registerer is required")
+	}
+
+	s.reloads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_telegram_secret_reloads_total",
+		Help: "Количество чтений токена Telegram бота из Vault: ok - прочитан, error - действует предыдущий.",
+	}, []string{"result"})
+
+	s.loadedAt = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "auth_telegram_secret_loaded_timestamp_seconds",
+		Help: "Время последнего успешного чтения токена Telegram бота из Vault.",
+	})
+
+	for _, c := range []prometheus.Collector{s.reloads, s.loadedAt} {
+		if err := s.registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Reload перечитывает токен бота из Vault. При ошибке продолжает действовать предыдущий токен.
+func (s *Secrets) Reload(ctx context.Context) error {
+	token, err := s.read(ctx)
+	if err != nil {
+		s.reloads.WithLabelValues(reloadError).Inc()
+
+		return err
+	}
+
+	s.mu.Lock()
+	changed := s.token != "" && s.token != token
+	s.token = token
+	s.secretKey = secretKey(token)
+	s.widgetKey = widgetKey(token)
+	s.mu.Unlock()
+
+	s.reloads.WithLabelValues(reloadOK).Inc()
+	s.loadedAt.SetToCurrentTime()
+
+	if changed {
+		logrus.WithField("vault_path", s.path).Info("telegram bot token changed")
+	}
+
+	return nil
+}
+
+// Start периодически перечитывает токен бота до отмены контекста.
+func (s *Secrets) Start(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := s.Reload(ctx); err != nil && ctx.Err() == nil {
+				logrus.WithError(err).Error("error reload telegram bot token, keeping previous")
+			}
+		}
+	}
+}
+
+// BotToken возвращает токен бота.
+func (s *Secrets) BotToken() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.token
+}
+
+// SecretKey возвращает ключ проверки подписи initData: HMAC-SHA256 токена бота с ключом "WebAppData".
+func (s *Secrets) SecretKey() []byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.secretKey
+}
+
+// WidgetKey возвращает ключ проверки подписи данных виджета входа: SHA256 токена бота
+// (https://core.telegram.org/widgets/login#checking-authorization).
+func (s *Secrets) WidgetKey() []byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.widgetKey
+}
+
+func (s *Secrets) read(ctx context.Context) (string, error) {
+	data, err := s.client.ReadKV(ctx, s.path)
+	if err != nil {
+		return "", fmt.Errorf("telegram: error read bot token: %w", err)
+	}
+
+	token, _ := data[tokenField].(string)
+	if token == "" {
+		return "", fmt.Errorf("telegram: %s is empty in %s", tokenField, s.path)
+	}
+
+	return token, nil
+}
+
+func secretKey(token string) []byte {
+	mac := hmac.New(sha256.New, []byte(webAppDataKey))
+	mac.Write([]byte(token))
+
+	return mac.Sum(nil)
+}
+
+func widgetKey(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+
+	return sum[:]
+}
@@ -0,0 +1,164 @@
+package telegram
+
+import (
+	"auth-service/internal/service/telegram/mocks"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		prepare func(client *mocks.MockkvReader)
+		opts    func(client *mocks.MockkvReader) []Option
+		wantErr string
+	}{
+		{
+			name: "positive case",
+			prepare: func(client *mocks.MockkvReader) {
+				client.EXPECT().ReadKV(gomock.Any(), "secret/data/telegram").Return(map[string]interface{}{"bot_token": "123:abc"}, nil)
+			},
+			opts: func(client *mocks.MockkvReader) []Option {
+				return []Option{WithKVReader(client), WithPath("secret/data/telegram")}
+			},
+		},
+		{
+			name: "error case: no client",
+			opts: func(*mocks.MockkvReader) []Option {
+				return nil
+			},
+			wantErr: "vault client is required",
+		},
+		{
+			name: "error case: invalid reload interval",
+			opts: func(client *mocks.MockkvReader) []Option {
+				return []Option{WithKVReader(client), WithReloadInterval(-time.Second)}
+			},
+			wantErr: "reload interval must be positive",
+		},
+		{
+			name: "error case: vault is down at start",
+			prepare: func(client *mocks.MockkvReader) {
+				client.EXPECT().ReadKV(gomock.Any(), DefaultPath).Return(nil, errors.New("vault is down"))
+			},
+			opts: func(client *mocks.MockkvReader) []Option {
+				return []Option{WithKVReader(client)}
+			},
+			wantErr: "telegram: error read bot token: vault is down",
+		},
+		{
+			name: "error case: empty token",
+			prepare: func(client *mocks.MockkvReader) {
+				client.EXPECT().ReadKV(gomock.Any(), DefaultPath).Return(map[string]interface{}{}, nil)
+			},
+			opts: func(client *mocks.MockkvReader) []Option {
+				return []Option{WithKVReader(client)}
+			},
+			wantErr: "telegram: bot_token is empty in secret/data/auth/telegram",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := mocks.NewMockkvReader(gomock.NewController(t))
+			if tt.prepare != nil {
+				tt.prepare(client)
+			}
+
+			s, err := New(t.Context(), append(tt.opts(client), WithRegisterer(prometheus.NewRegistry()))...)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "123:abc", s.BotToken())
+		})
+	}
+}
+
+func TestSecrets_Reload(t *testing.T) {
+	t.Parallel()
+
+	client := mocks.NewMockkvReader(gomock.NewController(t))
+
+	gomock.InOrder(
+		client.EXPECT().ReadKV(gomock.Any(), DefaultPath).Return(map[string]interface{}{"bot_token": "123:abc"}, nil),
+		client.EXPECT().ReadKV(gomock.Any(), DefaultPath).Return(nil, errors.New("vault is down")),
+		client.EXPECT().ReadKV(gomock.Any(), DefaultPath).Return(map[string]interface{}{"bot_token": "123:def"}, nil),
+	)
+
+	s, err := New(t.Context(), WithKVReader(client), WithRegisterer(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	mac := hmac.New(sha256.New, []byte("WebAppData"))
+	mac.Write([]byte("123:abc"))
+	assert.Equal(t, mac.Sum(nil), s.SecretKey())
+
+	widget := sha256.Sum256([]byte("123:abc"))
+	assert.Equal(t, widget[:], s.WidgetKey())
+
+	// Vault недоступен - действует последний прочитанный токен
+	require.Error(t, s.Reload(t.Context()))
+	assert.Equal(t, "123:abc", s.BotToken())
+	assert.Equal(t, mac.Sum(nil), s.SecretKey())
+
+	// токен бота сменился
+	require.NoError(t, s.Reload(t.Context()))
+	assert.Equal(t, "123:def", s.BotToken())
+	assert.NotEqual(t, mac.Sum(nil), s.SecretKey())
+	assert.NotEqual(t, widget[:], s.WidgetKey())
+
+	assert.InDelta(t, 2, testutil.ToFloat64(s.reloads.WithLabelValues(reloadOK)), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(s.reloads.WithLabelValues(reloadError)), 0)
+}
+
+func TestSecrets_Start(t *testing.T) {
+	t.Parallel()
+
+	client := mocks.NewMockkvReader(gomock.NewController(t))
+
+	reloaded := make(chan struct{})
+
+	gomock.InOrder(
+		client.EXPECT().ReadKV(gomock.Any(), DefaultPath).Return(map[string]interface{}{"bot_token": "123:abc"}, nil),
+		client.EXPECT().ReadKV(gomock.Any(), DefaultPath).Return(nil, errors.New("vault is down")),
+		client.EXPECT().ReadKV(gomock.Any(), DefaultPath).DoAndReturn(func(_, _ any) (map[string]interface{}, error) {
+			close(reloaded)
+
+			return map[string]interface{}{"bot_token": "123:def"}, nil
+		}),
+		client.EXPECT().ReadKV(gomock.Any(), DefaultPath).Return(map[string]interface{}{"bot_token": "123:def"}, nil).AnyTimes(),
+	)
+
+	s, err := New(t.Context(), WithKVReader(client), WithReloadInterval(time.Millisecond), WithRegisterer(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- s.Start(ctx)
+	}()
+
+	<-reloaded
+	cancel()
+
+	require.NoError(t, <-done)
+	assert.Equal(t, "123:def", s.BotToken())
+}
@@ -0,0 +1,52 @@
+package token
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// Confirmation - claim cnf (RFC 8705): токен привязан к клиентскому сертификату mTLS.
+type Confirmation struct {
+	// X5tS256 - SHA-256 отпечаток DER сертификата в base64url без выравнивания.
+	X5tS256 string `json:"x5t#S256"`
+}
+
+// ErrCertificateMismatch - токен привязан к другому сертификату или сертификат не предъявлен.
+var ErrCertificateMismatch = errors.New("certificate binding mismatch")
+
+// CertThumbprint возвращает отпечаток сертификата для claim cnf.x5t#S256.
+func CertThumbprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// PeerThumbprint возвращает отпечаток проверенного клиентского сертификата mTLS из TLS соединения
+// запроса или пустую строку, если сертификат не предъявлен или не проверен.
+func PeerThumbprint(state *tls.ConnectionState) string {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.PeerCertificates) == 0 {
+		return ""
+	}
+
+	return CertThumbprint(state.PeerCertificates[0])
+}
+
+// CheckBinding проверяет, что токен, привязанный к сертификату, предъявлен с этим сертификатом.
+// thumbprint - отпечаток сертификата, с которым клиент обратился к ресурсу. Токены без привязки
+// принимаются с любым отпечатком.
+func CheckBinding(claims *Claims, thumbprint string) error {
+	if claims.CertThumbprint == "" {
+		return nil
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.CertThumbprint), []byte(thumbprint)) != 1 {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, ErrCertificateMismatch)
+	}
+
+	return nil
+}
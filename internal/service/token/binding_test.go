@@ -0,0 +1,73 @@
+package token
+
+import (
+	"auth-service/internal/service/token/mocks"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertThumbprint(t *testing.T) {
+	t.Parallel()
+
+	cert := &x509.Certificate{Raw: []byte("certificate")}
+	sum := sha256.Sum256(cert.Raw)
+
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(sum[:]), CertThumbprint(cert))
+}
+
+func TestPeerThumbprint(t *testing.T) {
+	t.Parallel()
+
+	cert := &x509.Certificate{Raw: []byte("certificate")}
+
+	assert.Empty(t, PeerThumbprint(nil))
+	assert.Empty(t, PeerThumbprint(&tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}), "certificate is not verified")
+	assert.Equal(t, CertThumbprint(cert), PeerThumbprint(&tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert}},
+	}))
+}
+
+func TestCheckBinding(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+
+	keys := mocks.NewMocksigningKeyProvider(gomock.NewController(t))
+	keys.EXPECT().SigningKey(gomock.Any()).Return("key-1", key, nil).Times(2)
+
+	issuer, err := NewIssuer(WithSigningKeys(keys))
+	require.NoError(t, err)
+
+	validator, err := NewValidator(WithKeys(staticKeys{"key-1": key}))
+	require.NoError(t, err)
+
+	bound, _, err := issuer.Issue(t.Context(), IssueRequest{Subject: "svc", TTL: time.Minute, CertThumbprint: "thumb"})
+	require.NoError(t, err)
+
+	unbound, _, err := issuer.Issue(t.Context(), IssueRequest{Subject: "svc", TTL: time.Minute})
+	require.NoError(t, err)
+
+	claims, err := validator.Validate(t.Context(), bound)
+	require.NoError(t, err)
+	assert.Equal(t, "thumb", claims.CertThumbprint)
+
+	require.NoError(t, CheckBinding(claims, "thumb"))
+	require.ErrorIs(t, CheckBinding(claims, "other"), ErrCertificateMismatch)
+	require.ErrorIs(t, CheckBinding(claims, ""), ErrInvalidToken)
+
+	claims, err = validator.Validate(t.Context(), unbound)
+	require.NoError(t, err)
+	assert.Empty(t, claims.CertThumbprint)
+
+	require.NoError(t, CheckBinding(claims, ""))
+	require.NoError(t, CheckBinding(claims, "thumb"))
+}
@@ -0,0 +1,310 @@
+package token
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Типы значений в схеме claims (как в JSON Schema).
+const (
+	ClaimTypeString  = "string"
+	ClaimTypeNumber  = "number"
+	ClaimTypeInteger = "integer"
+	ClaimTypeBoolean = "boolean"
+	ClaimTypeArray   = "array"
+	ClaimTypeObject  = "object"
+)
+
+var claimTypes = []string{ClaimTypeString, ClaimTypeNumber, ClaimTypeInteger, ClaimTypeBoolean, ClaimTypeArray, ClaimTypeObject}
+
+// ClaimSchema - схема пользовательских claims токенов аудитории, подмножество JSON Schema.
+// Описывает только допустимые claims: обязательных нет, так как входы выпускают токены без
+// пользовательских claims.
+type ClaimSchema struct {
+	Properties map[string]ClaimProperty
+	// AllowUnknown - разрешить claims, не описанные в Properties. По умолчанию они отклоняются.
+	AllowUnknown bool
+}
+
+// ClaimProperty - ограничения значения claim. Нулевые ограничения не проверяются.
+type ClaimProperty struct {
+	Type string
+	// Enum - допустимые значения строки.
+	Enum []string
+	// Pattern - регулярное выражение (RE2), которое должно находиться в строке. Как и в JSON Schema,
+	// не привязано к началу и концу строки: для полного совпадения используйте ^ и $.
+	Pattern string
+	// MaxLength - наибольшая длина строки в символах.
+	MaxLength int
+	Minimum   *float64
+	Maximum   *float64
+	// MaxItems - наибольшее количество элементов массива.
+	MaxItems int
+	// Items - схема элементов массива.
+	Items *ClaimProperty
+}
+
+// ClaimViolation - нарушение схемы claims.
+type ClaimViolation struct {
+	Audience string
+	// Claim - путь к значению, например tags[1].
+	Claim  string
+	Reason string
+}
+
+// ClaimSchemaError - пользовательские claims не соответствуют схеме аудитории. Содержит все
+// нарушения, чтобы вызывающий мог исправить запрос за один раз.
+type ClaimSchemaError struct {
+	Violations []ClaimViolation
+}
+
+func (e *ClaimSchemaError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+
+	for _, v := range e.Violations {
+		parts = append(parts, fmt.Sprintf("audience %q: claim %q %s", v.Audience, v.Claim, v.Reason))
+	}
+
+	return ErrClaimsRejected.Error() + ": " + strings.Join(parts, "; ")
+}
+
+// Unwrap позволяет проверять ошибку через errors.Is(err, ErrClaimsRejected).
+func (e *ClaimSchemaError) Unwrap() error {
+	return ErrClaimsRejected
+}
+
+// claimSchema - схема с заранее разобранными регулярными выражениями.
+type claimSchema struct {
+	properties   map[string]*claimProperty
+	allowUnknown bool
+}
+
+type claimProperty struct {
+	ClaimProperty
+
+	pattern *regexp.Regexp
+	items   *claimProperty
+}
+
+// compileClaimSchemas проверяет схемы и разбирает регулярные выражения.
+func compileClaimSchemas(schemas map[string]ClaimSchema) (map[string]*claimSchema, error) {
+	res := make(map[string]*claimSchema, len(schemas))
+
+	for aud, schema := range schemas {
+		compiled := &claimSchema{
+			properties:   make(map[string]*claimProperty, len(schema.Properties)),
+			allowUnknown: schema.AllowUnknown,
+		}
+
+		for name, prop := range schema.Properties {
+			if slices.Contains(reservedClaims, name) {
+				return nil, fmt.Errorf("claim schema %s: claim %q is reserved", aud, name)
+			}
+
+			p, err := compileClaimProperty(prop)
+			if err != nil {
+				return nil, fmt.Errorf("claim schema %s: claim %q: %w", aud, name, err)
+			}
+
+			compiled.properties[name] = p
+		}
+
+		res[aud] = compiled
+	}
+
+	return res, nil
+}
+
+func compileClaimProperty(prop ClaimProperty) (*claimProperty, error) {
+	if !slices.Contains(claimTypes, prop.Type) {
+		return nil, fmt.Errorf("unknown type %q", prop.Type)
+	}
+
+	if prop.MaxLength < 0 || prop.MaxItems < 0 {
+		return nil, errors.New("max length and max items must not be negative")
+	}
+
+	if prop.Minimum != nil && prop.Maximum != nil && *prop.Minimum > *prop.Maximum {
+		return nil, errors.New("minimum must not exceed maximum")
+	}
+
+	p := &claimProperty{ClaimProperty: prop}
+
+	if prop.Pattern != "" {
+		re, err := regexp.Compile(prop.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+
+		p.pattern = re
+	}
+
+	if prop.Items != nil {
+		if prop.Type != ClaimTypeArray {
+			return nil, errors.New("items are allowed only for arrays")
+		}
+
+		items, err := compileClaimProperty(*prop.Items)
+		if err != nil {
+			return nil, fmt.Errorf("items: %w", err)
+		}
+
+		p.items = items
+	}
+
+	return p, nil
+}
+
+// checkClaimSchemas проверяет пользовательские claims по схемам всех аудиторий токена.
+// Аудитории без схемы не ограничивают claims.
+func checkClaimSchemas(schemas map[string]*claimSchema, audience []string, claims map[string]interface{}) error {
+	if len(schemas) == 0 || len(claims) == 0 {
+		return nil
+	}
+
+	// значения приводятся к виду, в котором их увидит потребитель токена
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrClaimsRejected, err)
+	}
+
+	var values map[string]interface{}
+
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("%w: %w", ErrClaimsRejected, err)
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var violations []ClaimViolation
+
+	for _, aud := range audience {
+		schema, ok := schemas[aud]
+		if !ok {
+			continue
+		}
+
+		for _, name := range names {
+			prop, ok := schema.properties[name]
+			if !ok {
+				if !schema.allowUnknown {
+					violations = append(violations, ClaimViolation{Audience: aud, Claim: name, Reason: "is not allowed"})
+				}
+
+				continue
+			}
+
+			for _, v := range prop.check(name, values[name]) {
+				v.Audience = aud
+				violations = append(violations, v)
+			}
+		}
+	}
+
+	if len(violations) != 0 {
+		return &ClaimSchemaError{Violations: violations}
+	}
+
+	return nil
+}
+
+// check возвращает нарушения значения value по пути path.
+func (p *claimProperty) check(path string, value interface{}) []ClaimViolation {
+	violation := func(format string, args ...interface{}) []ClaimViolation {
+		return []ClaimViolation{{Claim: path, Reason: fmt.Sprintf(format, args...)}}
+	}
+
+	switch p.Type {
+	case ClaimTypeString:
+		s, ok := value.(string)
+		if !ok {
+			return violation("must be %s", p.Type)
+		}
+
+		return p.checkString(path, s)
+	case ClaimTypeNumber, ClaimTypeInteger:
+		n, ok := value.(float64)
+		if !ok || (p.Type == ClaimTypeInteger && n != math.Trunc(n)) {
+			return violation("must be %s", p.Type)
+		}
+
+		if p.Minimum != nil && n < *p.Minimum {
+			return violation("must be at least %s", formatNumber(*p.Minimum))
+		}
+
+		if p.Maximum != nil && n > *p.Maximum {
+			return violation("must be at most %s", formatNumber(*p.Maximum))
+		}
+	case ClaimTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return violation("must be %s", p.Type)
+		}
+	case ClaimTypeArray:
+		items, ok := value.([]interface{})
+		if !ok {
+			return violation("must be %s", p.Type)
+		}
+
+		if p.MaxItems > 0 && len(items) > p.MaxItems {
+			return violation("must have at most %d items", p.MaxItems)
+		}
+
+		if p.items == nil {
+			return nil
+		}
+
+		var res []ClaimViolation
+
+		for i, item := range items {
+			res = append(res, p.items.check(path+"["+strconv.Itoa(i)+"]", item)...)
+		}
+
+		return res
+	case ClaimTypeObject:
+		if _, ok := value.(map[string]interface{}); !ok {
+			return violation("must be %s", p.Type)
+		}
+	}
+
+	return nil
+}
+
+func (p *claimProperty) checkString(path, s string) []ClaimViolation {
+	var reasons []string
+
+	if len(p.Enum) != 0 && !slices.Contains(p.Enum, s) {
+		reasons = append(reasons, "must be one of ["+strings.Join(p.Enum, ", ")+"]")
+	}
+
+	if p.MaxLength > 0 && utf8.RuneCountInString(s) > p.MaxLength {
+		reasons = append(reasons, fmt.Sprintf("must be at most %d characters", p.MaxLength))
+	}
+
+	if p.pattern != nil && !p.pattern.MatchString(s) {
+		reasons = append(reasons, fmt.Sprintf("must match %q", p.Pattern))
+	}
+
+	res := make([]ClaimViolation, 0, len(reasons))
+	for _, reason := range reasons {
+		res = append(res, ClaimViolation{Claim: path, Reason: reason})
+	}
+
+	return res
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
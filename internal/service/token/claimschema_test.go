@@ -0,0 +1,187 @@
+package token
+
+import (
+	"auth-service/internal/service/token/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestCompileClaimSchemas(t *testing.T) {
+	t.Parallel()
+
+	keys := mocks.NewMocksigningKeyProvider(gomock.NewController(t))
+
+	tests := []struct {
+		name    string
+		prop    ClaimProperty
+		claim   string
+		wantErr string
+	}{
+		{name: "positive case", prop: ClaimProperty{Type: ClaimTypeString, Pattern: "^[a-z]+$"}},
+		{name: "unknown type", prop: ClaimProperty{Type: "date"}, wantErr: `claim "plan": unknown type "date"`},
+		{name: "invalid pattern", prop: ClaimProperty{Type: ClaimTypeString, Pattern: "("}, wantErr: "invalid pattern"},
+		{
+			name:    "minimum exceeds maximum",
+			prop:    ClaimProperty{Type: ClaimTypeNumber, Minimum: ptr(10.0), Maximum: ptr(1.0)},
+			wantErr: "minimum must not exceed maximum",
+		},
+		{
+			name:    "items of string",
+			prop:    ClaimProperty{Type: ClaimTypeString, Items: &ClaimProperty{Type: ClaimTypeString}},
+			wantErr: "items are allowed only for arrays",
+		},
+		{
+			name:    "invalid items",
+			prop:    ClaimProperty{Type: ClaimTypeArray, Items: &ClaimProperty{Type: "any"}},
+			wantErr: `items: unknown type "any"`,
+		},
+		{name: "reserved claim", prop: ClaimProperty{Type: ClaimTypeString}, claim: "sub", wantErr: `claim "sub" is reserved`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			claim := tt.claim
+			if claim == "" {
+				claim = "plan"
+			}
+
+			_, err := NewIssuer(WithSigningKeys(keys), WithClaimSchemas(map[string]ClaimSchema{
+				"web": {Properties: map[string]ClaimProperty{claim: tt.prop}},
+			}))
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestIssuer_Issue_ClaimSchemas(t *testing.T) {
+	t.Parallel()
+
+	keys := mocks.NewMocksigningKeyProvider(gomock.NewController(t))
+	keys.EXPECT().SigningKey(gomock.Any()).Return("key-1", []byte("secret"), nil).AnyTimes()
+
+	issuer, err := NewIssuer(WithSigningKeys(keys), WithClaimSchemas(map[string]ClaimSchema{
+		"web": {Properties: map[string]ClaimProperty{
+			"plan":   {Type: ClaimTypeString, Enum: []string{"free", "pro"}},
+			"org_id": {Type: ClaimTypeInteger, Minimum: ptr(1.0)},
+			"tags": {Type: ClaimTypeArray, MaxItems: 3, Items: &ClaimProperty{
+				Type: ClaimTypeString, MaxLength: 8, Pattern: "^[a-z-]+$",
+			}},
+			"beta": {Type: ClaimTypeBoolean},
+		}},
+		"partner": {AllowUnknown: true, Properties: map[string]ClaimProperty{
+			"plan": {Type: ClaimTypeString},
+		}},
+	}))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		audience   []string
+		claims     map[string]interface{}
+		violations []ClaimViolation
+	}{
+		{
+			name:     "positive case",
+			audience: []string{"web"},
+			claims:   map[string]interface{}{"plan": "pro", "org_id": 42, "tags": []string{"early", "qa"}, "beta": true},
+		},
+		{
+			name:     "audience without schema",
+			audience: []string{"bot"},
+			claims:   map[string]interface{}{"anything": []int{1, 2}},
+		},
+		{
+			name:     "unknown claims allowed",
+			audience: []string{"partner"},
+			claims:   map[string]interface{}{"plan": "gold", "region": "eu"},
+		},
+		{
+			name:     "ill-typed and unknown claims",
+			audience: []string{"web"},
+			claims: map[string]interface{}{
+				"plan":   "gold",
+				"org_id": 1.5,
+				"tags":   []string{"early", "Very-Long-Tag"},
+				"beta":   "yes",
+				"role":   "admin",
+			},
+			violations: []ClaimViolation{
+				{Audience: "web", Claim: "beta", Reason: "must be boolean"},
+				{Audience: "web", Claim: "org_id", Reason: "must be integer"},
+				{Audience: "web", Claim: "plan", Reason: "must be one of [free, pro]"},
+				{Audience: "web", Claim: "role", Reason: "is not allowed"},
+				{Audience: "web", Claim: "tags[1]", Reason: "must be at most 8 characters"},
+				{Audience: "web", Claim: "tags[1]", Reason: `must match "^[a-z-]+$"`},
+			},
+		},
+		{
+			name:     "limits",
+			audience: []string{"web"},
+			claims:   map[string]interface{}{"org_id": 0, "tags": []string{"a", "b", "c", "d"}},
+			violations: []ClaimViolation{
+				{Audience: "web", Claim: "org_id", Reason: "must be at least 1"},
+				{Audience: "web", Claim: "tags", Reason: "must have at most 3 items"},
+			},
+		},
+		{
+			name:     "every audience is checked",
+			audience: []string{"partner", "web"},
+			claims:   map[string]interface{}{"plan": 1},
+			violations: []ClaimViolation{
+				{Audience: "partner", Claim: "plan", Reason: "must be string"},
+				{Audience: "web", Claim: "plan", Reason: "must be string"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, _, err := issuer.Issue(t.Context(), IssueRequest{
+				Subject:  "user-1",
+				Audience: tt.audience,
+				TTL:      time.Minute,
+				Claims:   tt.claims,
+			})
+			if len(tt.violations) == 0 {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, ErrClaimsRejected)
+
+			var schemaErr *ClaimSchemaError
+
+			require.ErrorAs(t, err, &schemaErr)
+			assert.Equal(t, tt.violations, schemaErr.Violations)
+		})
+	}
+}
+
+func TestClaimSchemaError(t *testing.T) {
+	t.Parallel()
+
+	err := &ClaimSchemaError{Violations: []ClaimViolation{
+		{Audience: "web", Claim: "plan", Reason: "must be string"},
+		{Audience: "web", Claim: "role", Reason: "is not allowed"},
+	}}
+
+	assert.EqualError(t, err, `claims rejected: audience "web": claim "plan" must be string; audience "web": claim "role" is not allowed`)
+}
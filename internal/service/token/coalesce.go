@@ -0,0 +1,86 @@
+package token
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+)
+
+// Результаты проверки токена в метриках объединения.
+const (
+	coalesceValidated = "validated" // токен проверен этим вызовом
+	coalesceShared    = "coalesced" // результат получен от параллельной проверки того же токена
+)
+
+// WithCoalescing включает объединение параллельных проверок одного и того же токена:
+// пока токен проверяется, остальные запросы с ним ждут результат этой проверки.
+// Снижает нагрузку на проверку подписи, когда шлюзы агрессивно повторяют запросы.
+// Счетчик проверок регистрируется в registerer.
+func WithCoalescing(registerer prometheus.Registerer) ValidatorOption {
+	return func(v *Validator) {
+		v.coalesceRegisterer = registerer
+	}
+}
+
+// coalescing - объединение параллельных проверок токенов по хэшу токена.
+type coalescing struct {
+	group  singleflight.Group
+	checks *prometheus.CounterVec
+}
+
+func newCoalescing(registerer prometheus.Registerer) (*coalescing, error) {
+	c := &coalescing{
+		checks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "auth_token_validations_total",
+			Help: "Количество проверок токенов: validated - проверен, coalesced - результат получен от параллельной проверки.",
+		}, []string{"result"}),
+	}
+
+	if err := registerer.Register(c.checks); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// validate выполняет проверку fn или дожидается результата параллельной проверки того же токена.
+// Отмена контекста одного из ожидающих не прерывает общую проверку.
+func (c *coalescing) validate(ctx context.Context, raw string, fn func(context.Context, string) (*Claims, error)) (*Claims, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("token: error validate token: %w", err)
+	}
+
+	sum := sha256.Sum256([]byte(raw))
+	executed := false
+
+	ch := c.group.DoChan(hex.EncodeToString(sum[:]), func() (interface{}, error) {
+		executed = true
+
+		return fn(context.WithoutCancel(ctx), raw)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("token: error validate token: %w", ctx.Err())
+	case res := <-ch:
+		result := coalesceValidated
+		if !executed {
+			result = coalesceShared
+		}
+
+		c.checks.WithLabelValues(result).Inc()
+
+		if res.Err != nil {
+			return nil, res.Err
+		}
+
+		// каждый вызывающий получает свою копию результата
+		claims := *res.Val.(*Claims) //nolint:forcetypeassert // fn возвращает только *Claims
+
+		return &claims, nil
+	}
+}
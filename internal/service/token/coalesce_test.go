@@ -0,0 +1,111 @@
+package token
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingKeys - источник ключей, который отдает ключ только после закрытия release.
+type blockingKeys struct {
+	key     []byte
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (k *blockingKeys) Key(_ context.Context, _ string) ([]byte, error) {
+	k.calls.Add(1)
+	<-k.release
+
+	return k.key, nil
+}
+
+func TestValidator_Validate_Coalescing(t *testing.T) {
+	t.Parallel()
+
+	const callers = 10
+
+	keys := &blockingKeys{key: []byte("secret"), release: make(chan struct{})}
+
+	v, err := NewValidator(WithKeys(keys), WithCoalescing(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	raw := sign(t, "key-1", keys.key, jwt.RegisteredClaims{
+		Subject:   "user",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+
+	var wg sync.WaitGroup
+
+	results := make([]*Claims, callers)
+
+	for i := range callers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			claims, err := v.Validate(t.Context(), raw)
+			assert.NoError(t, err)
+
+			results[i] = claims
+		}()
+	}
+
+	// даем всем вызовам дойти до ожидания проверки
+	time.Sleep(50 * time.Millisecond)
+	close(keys.release)
+	wg.Wait()
+
+	// подпись проверена один раз, остальные получили ее результат
+	assert.Equal(t, int32(1), keys.calls.Load())
+	assert.InDelta(t, 1, testutil.ToFloat64(v.coalescing.checks.WithLabelValues(coalesceValidated)), 0)
+	assert.InDelta(t, callers-1, testutil.ToFloat64(v.coalescing.checks.WithLabelValues(coalesceShared)), 0)
+
+	// каждый вызывающий получил свою копию claims
+	for _, claims := range results {
+		require.NotNil(t, claims)
+		assert.Equal(t, "user", claims.Subject)
+	}
+
+	assert.NotSame(t, results[0], results[1])
+}
+
+func TestValidator_Validate_CoalescingErrors(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+
+	v, err := NewValidator(WithKeys(staticKeys{"key-1": key}), WithCoalescing(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	// ошибка проверки возвращается как есть
+	expired := sign(t, "key-1", key, jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour))})
+
+	_, err = v.Validate(t.Context(), expired)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// отмена контекста прекращает ожидание
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, err = v.Validate(ctx, expired)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// повторная регистрация метрик в том же реестре - ошибка
+	registry := prometheus.NewRegistry()
+
+	_, err = NewValidator(WithKeys(staticKeys{}), WithCoalescing(registry))
+	require.NoError(t, err)
+
+	_, err = NewValidator(WithKeys(staticKeys{}), WithCoalescing(registry))
+	require.Error(t, err)
+}
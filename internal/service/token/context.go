@@ -0,0 +1,17 @@
+package token
+
+import "context"
+
+type claimsKey struct{}
+
+// NewContext возвращает контекст с claims проверенного токена.
+func NewContext(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// FromContext возвращает claims токена, сохраненные NewContext.
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+
+	return claims, ok && claims != nil
+}
@@ -0,0 +1,78 @@
+package token
+
+import (
+	"auth-service/internal/service/id"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// ScopeGuest - scope гостевого токена: доступ только к публичным API.
+	ScopeGuest = "guest"
+	// GuestSubjectPrefix - префикс субъекта гостевого токена. Субъекты с этим префиксом
+	// не принадлежат пользователям и не выдаются обычным токенам.
+	GuestSubjectPrefix = "guest:"
+	// DefaultGuestTTL - время жизни гостевого токена по умолчанию.
+	DefaultGuestTTL = 15 * time.Minute
+	// MaxGuestTTL - максимальное время жизни гостевого токена.
+	MaxGuestTTL = time.Hour
+)
+
+// ErrGuestDisabled - гостевые токены не настроены.
+var ErrGuestDisabled = errors.New("guest tokens are disabled")
+
+// Guest - гостевые токены для еще не зарегистрированных пользователей: без пользователя,
+// со scope guest, аудиториями Audiences и временем жизни TTL. Без аудиторий гостевые токены выключены.
+type Guest struct {
+	Audiences []string
+	TTL       time.Duration
+}
+
+func (g Guest) enabled() bool {
+	return len(g.Audiences) != 0
+}
+
+func (g Guest) validate() error {
+	if !g.enabled() {
+		return nil
+	}
+
+	if g.TTL <= 0 || g.TTL > MaxGuestTTL {
+		return fmt.Errorf("guest ttl must be in (0, %s]", MaxGuestTTL)
+	}
+
+	return nil
+}
+
+// IsGuest возвращает true для гостевых токенов.
+func (c *Claims) IsGuest() bool {
+	return isGuestSubject(c.Subject) && slices.Contains(c.Scopes, ScopeGuest)
+}
+
+func isGuestSubject(subject string) bool {
+	return strings.HasPrefix(subject, GuestSubjectPrefix)
+}
+
+// IssueGuest выпускает гостевой токен со случайным субъектом guest:<id>. По субъекту сервисы
+// связывают данные, собранные до регистрации, с пользователем после обмена токена.
+func (i *Issuer) IssueGuest(ctx context.Context) (string, *Claims, error) {
+	if !i.guest.enabled() {
+		return "", nil, ErrGuestDisabled
+	}
+
+	guestID, err := id.Generate(idLength)
+	if err != nil {
+		return "", nil, fmt.Errorf("token: error generate guest id: %w", err)
+	}
+
+	return i.issue(ctx, IssueRequest{
+		Subject:  GuestSubjectPrefix + guestID,
+		Audience: i.guest.Audiences,
+		TTL:      i.guest.TTL,
+		Scopes:   []string{ScopeGuest},
+	})
+}
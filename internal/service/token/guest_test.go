@@ -0,0 +1,98 @@
+package token
+
+import (
+	"auth-service/internal/service/token/mocks"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuest_Validate(t *testing.T) {
+	t.Parallel()
+
+	keys := mocks.NewMocksigningKeyProvider(gomock.NewController(t))
+
+	_, err := NewIssuer(WithSigningKeys(keys), WithGuest(Guest{Audiences: []string{"telegram-bot"}}))
+	require.ErrorContains(t, err, "guest ttl must be in")
+
+	_, err = NewIssuer(WithSigningKeys(keys), WithGuest(Guest{Audiences: []string{"telegram-bot"}, TTL: 2 * time.Hour}))
+	require.ErrorContains(t, err, "guest ttl must be in")
+
+	_, err = NewIssuer(WithSigningKeys(keys), WithGuest(Guest{Audiences: []string{"telegram-bot"}, TTL: time.Minute}))
+	require.NoError(t, err)
+}
+
+func TestIssuer_IssueGuest(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+	now := time.Now().Truncate(time.Second)
+
+	keys := mocks.NewMocksigningKeyProvider(gomock.NewController(t))
+	keys.EXPECT().SigningKey(gomock.Any()).Return("key-1", key, nil).AnyTimes()
+
+	issuer, err := NewIssuer(WithSigningKeys(keys), WithGuest(Guest{Audiences: []string{"telegram-bot"}, TTL: 10 * time.Minute}))
+	require.NoError(t, err)
+
+	issuer.now = func() time.Time { return now }
+
+	validator, err := NewValidator(WithKeys(staticKeys{"key-1": key}))
+	require.NoError(t, err)
+
+	raw, issued, err := issuer.IssueGuest(context.Background())
+	require.NoError(t, err)
+
+	claims, err := validator.Validate(context.Background(), raw)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(claims.Subject, GuestSubjectPrefix))
+	assert.Equal(t, issued.Subject, claims.Subject)
+	assert.Equal(t, []string{ScopeGuest}, claims.Scopes)
+	assert.Equal(t, []string{"telegram-bot"}, claims.Audience)
+	assert.Equal(t, now.Add(10*time.Minute), claims.ExpiresAt)
+	assert.True(t, claims.IsGuest())
+
+	_, other, err := issuer.IssueGuest(context.Background())
+	require.NoError(t, err)
+	assert.NotEqual(t, issued.Subject, other.Subject)
+}
+
+func TestIssuer_IssueGuest_Disabled(t *testing.T) {
+	t.Parallel()
+
+	keys := mocks.NewMocksigningKeyProvider(gomock.NewController(t))
+
+	issuer, err := NewIssuer(WithSigningKeys(keys))
+	require.NoError(t, err)
+
+	_, _, err = issuer.IssueGuest(context.Background())
+	require.ErrorIs(t, err, ErrGuestDisabled)
+}
+
+func TestIssuer_Issue_GuestReserved(t *testing.T) {
+	t.Parallel()
+
+	keys := mocks.NewMocksigningKeyProvider(gomock.NewController(t))
+
+	issuer, err := NewIssuer(WithSigningKeys(keys))
+	require.NoError(t, err)
+
+	_, _, err = issuer.Issue(context.Background(), IssueRequest{Subject: "guest:abc", TTL: time.Minute})
+	require.Error(t, err)
+
+	_, _, err = issuer.Issue(context.Background(), IssueRequest{Subject: "user-1", TTL: time.Minute, Scopes: []string{ScopeGuest}})
+	require.Error(t, err)
+}
+
+func TestClaims_IsGuest(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, (&Claims{Subject: "guest:abc", Scopes: []string{ScopeGuest}}).IsGuest())
+	assert.False(t, (&Claims{Subject: "guest:abc"}).IsGuest())
+	assert.False(t, (&Claims{Subject: "user-1", Scopes: []string{ScopeGuest}}).IsGuest())
+}
@@ -0,0 +1,113 @@
+package token
+
+import (
+	"auth-service/internal/service/token/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestIssuer_Impersonate(t *testing.T) {
+	t.Parallel()
+
+	policy := Impersonation{MaxTTL: 10 * time.Minute, Scopes: []string{"read", "read:notes"}}
+
+	tests := []struct {
+		name       string
+		req        ImpersonateRequest
+		wantTTL    time.Duration
+		wantScopes []string
+		wantErr    require.ErrorAssertionFunc
+	}{
+		{
+			name:       "positive case: defaults",
+			req:        ImpersonateRequest{Actor: "admin", Subject: "user-1", Reason: "ticket-1"},
+			wantTTL:    10 * time.Minute,
+			wantScopes: []string{"read", "read:notes"},
+			wantErr:    require.NoError,
+		},
+		{
+			name: "positive case: narrowed",
+			req: ImpersonateRequest{
+				Actor: "admin", Subject: "user-1", Reason: "ticket-1",
+				TTL: time.Minute, Scopes: []string{"read:notes"},
+			},
+			wantTTL:    time.Minute,
+			wantScopes: []string{"read:notes"},
+			wantErr:    require.NoError,
+		},
+		{
+			name: "error case: write scope",
+			req: ImpersonateRequest{
+				Actor: "admin", Subject: "user-1", Reason: "ticket-1",
+				Scopes: []string{"write"},
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrImpersonationDenied)
+			},
+		},
+		{
+			name: "error case: ttl too long",
+			req: ImpersonateRequest{
+				Actor: "admin", Subject: "user-1", Reason: "ticket-1",
+				TTL: time.Hour,
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrImpersonationDenied)
+			},
+		},
+		{
+			name: "error case: no reason",
+			req:  ImpersonateRequest{Actor: "admin", Subject: "user-1"},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrImpersonationDenied)
+			},
+		},
+		{
+			name: "error case: no actor",
+			req:  ImpersonateRequest{Subject: "user-1", Reason: "ticket-1"},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrImpersonationDenied)
+			},
+		},
+		{
+			name: "error case: self impersonation",
+			req:  ImpersonateRequest{Actor: "admin", Subject: "admin", Reason: "ticket-1"},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrImpersonationDenied)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			keys := mocks.NewMocksigningKeyProvider(ctrl)
+			keys.EXPECT().SigningKey(gomock.Any()).Return("key-1", []byte("secret"), nil).AnyTimes()
+
+			issuer, err := NewIssuer(WithSigningKeys(keys), WithImpersonation(policy))
+			require.NoError(t, err)
+
+			now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+			issuer.now = func() time.Time { return now }
+
+			_, claims, err := issuer.Impersonate(t.Context(), tt.req)
+			tt.wantErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, &Actor{Subject: tt.req.Actor}, claims.Actor)
+			assert.Equal(t, tt.req.Subject, claims.Subject)
+			assert.Equal(t, now.Add(tt.wantTTL), claims.ExpiresAt)
+			assert.Equal(t, tt.wantScopes, claims.Scopes)
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package token
+
+import (
+	"auth-service/internal/service/id"
+	"auth-service/internal/service/keystats"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// idLength - длина идентификатора токена (jti).
+const idLength = 16
+
+// signingKeyProvider - источник ключа для подписи новых токенов.
+//
+//go:generate mockgen -source=issuer.go -destination=mocks/issuer_mock.go -package=mocks
+type signingKeyProvider interface {
+	SigningKey(ctx context.Context) (string, []byte, error)
+}
+
+// groupSource - источник членства пользователей в группах для claim groups.
+type groupSource interface {
+	Memberships(ctx context.Context, userID string) (map[string]string, error)
+}
+
+// issueStats - статистика выпуска токенов по аудиториям для продуктовой аналитики.
+type issueStats interface {
+	TokenIssued(ctx context.Context, audience []string) error
+}
+
+// IssueRequest - параметры выпускаемого токена.
+type IssueRequest struct {
+	Subject  string
+	Audience []string
+	TTL      time.Duration
+	Scopes   []string
+	// Actor - кто действует от имени субъекта (claim act). Заполняется только для делегированных токенов.
+	Actor *Actor
+	// CertThumbprint - отпечаток клиентского сертификата mTLS, к которому привязывается токен (RFC 8705).
+	CertThumbprint string
+	// SessionID - сессия входа, к которой относится токен (claim sid), например семейство refresh токенов
+	// при обновлении. Пусто - токен начинает новую сессию, и ее id создается.
+	SessionID string
+	// TelegramID - проверенный ID пользователя в Telegram (claim tg_id). Заполняется только входом через Telegram.
+	TelegramID int64
+	// Claims - пользовательские claims. Ограничены по размеру и не могут переопределять claims сервиса.
+	Claims map[string]interface{}
+}
+
+// Issuer - выпускает токены, подписанные текущим ключом сервиса.
+type Issuer struct {
+	keys          signingKeyProvider
+	keyStats      *keystats.Tracker
+	stats         issueStats
+	impersonation Impersonation
+	groups        groupSource
+	limits        Limits
+	claimSchemas  map[string]ClaimSchema
+	schemas       map[string]*claimSchema
+	sandbox       Sandbox
+	guest         Guest
+	// url - внешний адрес сервиса, записывается в claim iss. Пусто - claim не записывается
+	url string
+
+	now func() time.Time
+}
+
+// IssuerOption - опция для настройки Issuer.
+type IssuerOption func(*Issuer)
+
+// WithSigningKeys устанавливает источник ключа подписи.
+func WithSigningKeys(keys signingKeyProvider) IssuerOption {
+	return func(i *Issuer) {
+		i.keys = keys
+	}
+}
+
+// WithIssuerKeyStats устанавливает счетчик использования ключей: каждый выпуск учитывается по kid.
+func WithIssuerKeyStats(tracker *keystats.Tracker) IssuerOption {
+	return func(i *Issuer) {
+		i.keyStats = tracker
+	}
+}
+
+// WithIssueStats устанавливает статистику выпуска: каждый выпуск учитывается по аудиториям токена.
+// Ошибка учета не мешает выпуску и только пишется в журнал.
+func WithIssueStats(stats issueStats) IssuerOption {
+	return func(i *Issuer) {
+		i.stats = stats
+	}
+}
+
+// WithImpersonation устанавливает ограничения токенов имперсонации.
+func WithImpersonation(impersonation Impersonation) IssuerOption {
+	return func(i *Issuer) {
+		i.impersonation = impersonation
+	}
+}
+
+// WithGroups устанавливает источник членства в группах: роли субъекта в группах попадают в claim groups.
+func WithGroups(groups groupSource) IssuerOption {
+	return func(i *Issuer) {
+		i.groups = groups
+	}
+}
+
+// WithLimits устанавливает ограничения размера токенов и имен пользовательских claims.
+func WithLimits(limits Limits) IssuerOption {
+	return func(i *Issuer) {
+		i.limits = limits
+	}
+}
+
+// WithClaimSchemas устанавливает схемы пользовательских claims по аудиториям: токен не выпускается,
+// если его claims не соответствуют схеме хотя бы одной из его аудиторий.
+func WithClaimSchemas(schemas map[string]ClaimSchema) IssuerOption {
+	return func(i *Issuer) {
+		i.claimSchemas = schemas
+	}
+}
+
+// WithSandbox включает песочницу: токены ее аудиторий помечаются env=sandbox,
+// а их время жизни ограничивается TTL песочницы.
+func WithSandbox(sandbox Sandbox) IssuerOption {
+	return func(i *Issuer) {
+		i.sandbox = sandbox
+	}
+}
+
+// WithGuest включает гостевые токены для еще не зарегистрированных пользователей.
+func WithGuest(guest Guest) IssuerOption {
+	return func(i *Issuer) {
+		i.guest = guest
+	}
+}
+
+// WithIssuerURL устанавливает внешний адрес сервиса, который записывается в claim iss.
+func WithIssuerURL(url string) IssuerOption {
+	return func(i *Issuer) {
+		i.url = url
+	}
+}
+
+// NewIssuer создает новый Issuer.
+func NewIssuer(opts ...IssuerOption) (*Issuer, error) {
+	i := &Issuer{
+		impersonation: Impersonation{
+			MaxTTL: DefaultImpersonationTTL,
+			Scopes: []string{ScopeRead},
+		},
+		limits: Limits{
+			MaxTokenSize:        DefaultMaxTokenSize,
+			MaxCustomClaimsSize: DefaultMaxCustomClaimsSize,
+		},
+		now: time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	if i.keys == nil {
+		return nil, errors.New("signing keys are required")
+	}
+
+	if i.impersonation.MaxTTL <= 0 {
+		return nil, errors.New("impersonation max ttl must be positive")
+	}
+
+	if len(i.impersonation.Scopes) == 0 {
+		return nil, errors.New("impersonation scopes are required")
+	}
+
+	if err := i.limits.validate(); err != nil {
+		return nil, err
+	}
+
+	schemas, err := compileClaimSchemas(i.claimSchemas)
+	if err != nil {
+		return nil, err
+	}
+
+	i.schemas = schemas
+
+	if err := i.sandbox.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := i.guest.validate(); err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// Issue выпускает и подписывает токен.
+func (i *Issuer) Issue(ctx context.Context, req IssueRequest) (string, *Claims, error) {
+	if req.Subject == "" {
+		return "", nil, errors.New("subject is required")
+	}
+
+	if isGuestSubject(req.Subject) || slices.Contains(req.Scopes, ScopeGuest) {
+		return "", nil, errors.New("guest subject and scope are reserved for guest tokens")
+	}
+
+	return i.issue(ctx, req)
+}
+
+// issue выпускает и подписывает токен без проверки зарезервированных субъектов.
+func (i *Issuer) issue(ctx context.Context, req IssueRequest) (string, *Claims, error) {
+	if req.TTL <= 0 {
+		return "", nil, errors.New("ttl must be positive")
+	}
+
+	if err := i.limits.checkCustom(req.Claims); err != nil {
+		return "", nil, err
+	}
+
+	if err := checkClaimSchemas(i.schemas, req.Audience, req.Claims); err != nil {
+		return "", nil, err
+	}
+
+	jti, err := id.Generate(idLength)
+	if err != nil {
+		return "", nil, fmt.Errorf("token: error generate id: %w", err)
+	}
+
+	sid := req.SessionID
+	if sid == "" {
+		sid, err = id.Generate(idLength)
+		if err != nil {
+			return "", nil, fmt.Errorf("token: error generate session id: %w", err)
+		}
+	}
+
+	kid, key, err := i.keys.SigningKey(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var groups map[string]string
+
+	if i.groups != nil {
+		groups, err = i.groups.Memberships(ctx, req.Subject)
+		if err != nil {
+			return "", nil, fmt.Errorf("token: error get groups: %w", err)
+		}
+	}
+
+	now := i.now()
+	ttl := req.TTL
+
+	var env string
+
+	if i.sandbox.matches(req.Audience) {
+		env = EnvSandbox
+		ttl = min(ttl, i.sandbox.TTL)
+	}
+
+	claims := &jwtClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Issuer:    i.url,
+			Subject:   req.Subject,
+			Audience:  req.Audience,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		principalClaims: principalClaims{SessionID: sid, TelegramID: req.TelegramID},
+
+		Scope:  strings.Join(req.Scopes, " "),
+		Act:    req.Actor,
+		Groups: groups,
+		Env:    env,
+		Custom: req.Claims,
+	}
+
+	if req.CertThumbprint != "" {
+		claims.Cnf = &Confirmation{X5tS256: req.CertThumbprint}
+	}
+
+	tok := jwt.NewWithClaims(signingMethod, claims)
+	tok.Header["kid"] = kid
+
+	raw, err := tok.SignedString(key)
+	if err != nil {
+		return "", nil, fmt.Errorf("token: error sign token: %w", err)
+	}
+
+	if err := i.limits.checkToken(raw); err != nil {
+		return "", nil, err
+	}
+
+	if i.keyStats != nil {
+		i.keyStats.Issued(kid)
+	}
+
+	if i.stats != nil {
+		if err := i.stats.TokenIssued(ctx, req.Audience); err != nil {
+			logrus.WithError(err).Warn("error count issued token")
+		}
+	}
+
+	return raw, newClaims(kid, claims), nil
+}
@@ -0,0 +1,175 @@
+package token
+
+import (
+	"auth-service/internal/service/keystats"
+	"auth-service/internal/service/token/mocks"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIssuer(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	keys := mocks.NewMocksigningKeyProvider(ctrl)
+
+	tests := []struct {
+		name    string
+		opts    []IssuerOption
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			opts:    []IssuerOption{WithSigningKeys(keys)},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: keys are nil",
+			wantErr: require.Error,
+		},
+		{
+			name: "error case: invalid impersonation ttl",
+			opts: []IssuerOption{
+				WithSigningKeys(keys),
+				WithImpersonation(Impersonation{Scopes: []string{ScopeRead}}),
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "error case: impersonation without scopes",
+			opts: []IssuerOption{
+				WithSigningKeys(keys),
+				WithImpersonation(Impersonation{MaxTTL: time.Minute}),
+			},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewIssuer(tt.opts...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+func TestIssuer_Issue(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+
+	ctrl := gomock.NewController(t)
+	keys := mocks.NewMocksigningKeyProvider(ctrl)
+	keys.EXPECT().SigningKey(gomock.Any()).Return("key-1", key, nil)
+
+	tracker, err := keystats.New(keystats.WithRegisterer(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	groups := mocks.NewMockgroupSource(ctrl)
+	groups.EXPECT().Memberships(gomock.Any(), "user-1").Return(map[string]string{"group-1": "editor"}, nil)
+
+	stats := mocks.NewMockissueStats(ctrl)
+	stats.EXPECT().TokenIssued(gomock.Any(), []string{"web"}).Return(nil)
+
+	issuer, err := NewIssuer(WithSigningKeys(keys), WithIssuerKeyStats(tracker), WithIssueStats(stats), WithGroups(groups))
+	require.NoError(t, err)
+
+	raw, claims, err := issuer.Issue(t.Context(), IssueRequest{
+		Subject:    "user-1",
+		Audience:   []string{"web"},
+		TTL:        time.Minute,
+		Scopes:     []string{"read", "write"},
+		Actor:      &Actor{Subject: "admin"},
+		TelegramID: 42,
+	})
+	require.NoError(t, err)
+	assert.Len(t, claims.ID, idLength)
+	// токен без сессии начинает новую
+	assert.Len(t, claims.SessionID, idLength)
+
+	// выпущенный токен проходит проверку с теми же claims
+	validator, err := NewValidator(WithKeys(staticKeys{"key-1": key}))
+	require.NoError(t, err)
+
+	got, err := validator.Validate(t.Context(), raw)
+	require.NoError(t, err)
+
+	assert.Equal(t, claims.ID, got.ID)
+	assert.Equal(t, "key-1", got.Kid)
+	assert.Equal(t, "user-1", got.Subject)
+	assert.Equal(t, []string{"web"}, got.Audience)
+	assert.Equal(t, []string{"read", "write"}, got.Scopes)
+	assert.Equal(t, &Actor{Subject: "admin"}, got.Actor)
+	assert.Equal(t, map[string]string{"group-1": "editor"}, got.Groups)
+	assert.Equal(t, claims.ExpiresAt.Unix(), got.ExpiresAt.Unix())
+	assert.Equal(t, claims.SessionID, got.SessionID)
+	assert.Equal(t, int64(42), got.TelegramID)
+
+	usage := tracker.Usage()
+	require.Len(t, usage, 1)
+	assert.Equal(t, uint64(1), usage[0].Issued)
+}
+
+func TestIssuer_Issue_Error(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	keys := mocks.NewMocksigningKeyProvider(ctrl)
+	keys.EXPECT().SigningKey(gomock.Any()).Return("", nil, errors.New("vault is sealed"))
+
+	issuer, err := NewIssuer(WithSigningKeys(keys))
+	require.NoError(t, err)
+
+	_, _, err = issuer.Issue(t.Context(), IssueRequest{Subject: "user-1", TTL: time.Minute})
+	require.ErrorContains(t, err, "vault is sealed")
+
+	_, _, err = issuer.Issue(t.Context(), IssueRequest{TTL: time.Minute})
+	require.Error(t, err)
+
+	_, _, err = issuer.Issue(t.Context(), IssueRequest{Subject: "user-1"})
+	require.Error(t, err)
+}
+
+func TestIssuer_Issue_StatsError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	keys := mocks.NewMocksigningKeyProvider(ctrl)
+	keys.EXPECT().SigningKey(gomock.Any()).Return("key-1", []byte("secret"), nil)
+
+	stats := mocks.NewMockissueStats(ctrl)
+	stats.EXPECT().TokenIssued(gomock.Any(), gomock.Nil()).Return(errors.New("redis is down"))
+
+	issuer, err := NewIssuer(WithSigningKeys(keys), WithIssueStats(stats))
+	require.NoError(t, err)
+
+	// статистика не должна мешать выпуску токенов
+	raw, _, err := issuer.Issue(t.Context(), IssueRequest{Subject: "user-1", TTL: time.Minute})
+	require.NoError(t, err)
+	assert.NotEmpty(t, raw)
+}
+
+func TestIssuer_Issue_GroupsError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	keys := mocks.NewMocksigningKeyProvider(ctrl)
+	keys.EXPECT().SigningKey(gomock.Any()).Return("key-1", []byte("secret"), nil)
+
+	groups := mocks.NewMockgroupSource(ctrl)
+	groups.EXPECT().Memberships(gomock.Any(), "user-1").Return(nil, errors.New("redis is down"))
+
+	issuer, err := NewIssuer(WithSigningKeys(keys), WithGroups(groups))
+	require.NoError(t, err)
+
+	_, _, err = issuer.Issue(t.Context(), IssueRequest{Subject: "user-1", TTL: time.Minute})
+	require.ErrorContains(t, err, "redis is down")
+}
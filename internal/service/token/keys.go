@@ -0,0 +1,356 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultKeysPath - путь к секрету Vault с ключами подписи по умолчанию.
+const DefaultKeysPath = "secret/data/auth/signing-keys"
+
+// currentField - поле секрета с kid ключа, которым подписываются новые токены.
+const currentField = "current"
+
+const (
+	// DefaultKeysRefreshInterval - как долго SigningKey подписывает текущим ключом из кэша, не перечитывая секрет.
+	DefaultKeysRefreshInterval = time.Minute
+	// DefaultKeysMinReloadInterval - минимальный интервал между чтениями секрета из-за неизвестного kid.
+	DefaultKeysMinReloadInterval = 5 * time.Second
+)
+
+// Источники результата чтения ключей в метриках.
+const (
+	keysLoadVault        = "vault"        // секрет прочитан из Vault
+	keysLoadDeduplicated = "deduplicated" // результат получен от параллельного чтения
+)
+
+// ErrUnknownKey - ключ с указанным kid не найден.
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// kvReader - интерфейс для чтения секретов KV из Vault.
+//
+//go:generate mockgen -source=keys.go -destination=mocks/keys_mock.go -package=mocks
+type kvReader interface {
+	ReadKV(ctx context.Context, path string) (map[string]interface{}, error)
+}
+
+// VaultKeys - ключи подписи, хранящиеся в Vault в одном KV секрете в виде kid -> секрет.
+// Поле "current" секрета содержит kid ключа, которым подписываются новые токены.
+// Если включена ротация (WithRotation), секрет также хранит время последней ротации и время,
+// когда каждый замененный ключ перестал быть текущим, см. Rotate.
+// Прочитанные ключи кэшируются: ключ с заданным kid не меняется, поэтому проверка токенов
+// продолжает работать по кэшу, даже если Vault недоступен. Каждое успешное чтение секрета
+// заменяет кэш, поэтому ключ, удаленный из секрета при ротации, перестает приниматься.
+// Параллельные чтения секрета объединяются в одно: Vault получает один запрос, остальные ждут его результат.
+// Промах кэша перечитывает секрет не чаще раза в MinReloadInterval, поэтому токены со случайными kid
+// не превращаются в поток запросов к Vault. Текущий ключ берется из кэша и перечитывается раз
+// в RefreshInterval или сразу после ротации этим экземпляром.
+type VaultKeys struct {
+	client kvReader
+	path   string
+
+	// запись секрета и параметры ротации, nil и нулевой период - ротация выключена
+	writer   kvWriter
+	rotation KeyRotation
+
+	mu    sync.RWMutex
+	cache map[string][]byte
+	// current - kid текущего ключа, loadedAt - время последнего успешного чтения секрета
+	current  string
+	loadedAt time.Time
+
+	refreshInterval   time.Duration
+	minReloadInterval time.Duration
+
+	group singleflight.Group
+
+	registerer prometheus.Registerer
+	loads      *prometheus.CounterVec
+	rotations  *prometheus.CounterVec
+	rotatedAt  prometheus.Gauge
+
+	now func() time.Time
+}
+
+// KeysOption - опция для настройки VaultKeys.
+type KeysOption func(*VaultKeys)
+
+// WithKVReader устанавливает клиент Vault.
+func WithKVReader(client kvReader) KeysOption {
+	return func(k *VaultKeys) {
+		k.client = client
+	}
+}
+
+// WithKeysPath устанавливает путь к секрету с ключами. По умолчанию DefaultKeysPath.
+func WithKeysPath(path string) KeysOption {
+	return func(k *VaultKeys) {
+		k.path = path
+	}
+}
+
+// WithKeysRefreshInterval устанавливает, как долго SigningKey использует текущий ключ из кэша.
+// Ротация другим экземпляром подхватывается не позже чем через interval. 0 - секрет перечитывается
+// при каждом выпуске токена. По умолчанию DefaultKeysRefreshInterval.
+func WithKeysRefreshInterval(interval time.Duration) KeysOption {
+	return func(k *VaultKeys) {
+		k.refreshInterval = interval
+	}
+}
+
+// WithKeysMinReloadInterval устанавливает минимальный интервал между чтениями секрета из-за
+// неизвестного kid: пока он не прошел, такой kid отклоняется без обращения к Vault.
+// По умолчанию DefaultKeysMinReloadInterval.
+func WithKeysMinReloadInterval(interval time.Duration) KeysOption {
+	return func(k *VaultKeys) {
+		k.minReloadInterval = interval
+	}
+}
+
+// WithKeysRegisterer включает метрики чтения ключей и регистрирует их в registerer.
+// По умолчанию метрики не собираются.
+func WithKeysRegisterer(registerer prometheus.Registerer) KeysOption {
+	return func(k *VaultKeys) {
+		k.registerer = registerer
+	}
+}
+
+// NewVaultKeys создает новый источник ключей подписи из Vault.
+func NewVaultKeys(opts ...KeysOption) (*VaultKeys, error) {
+	k := &VaultKeys{
+		path:  DefaultKeysPath,
+		cache: map[string][]byte{},
+		rotation: KeyRotation{
+			Grace: DefaultRotationGrace,
+		},
+		refreshInterval:   DefaultKeysRefreshInterval,
+		minReloadInterval: DefaultKeysMinReloadInterval,
+		now:               time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(k)
+	}
+
+	if k.client == nil {
+		return nil, errors.New("vault client is required")
+	}
+
+	if k.path == "" {
+		return nil, errors.New("keys path is required")
+	}
+
+	if k.refreshInterval < 0 || k.minReloadInterval < 0 {
+		return nil, errors.New("keys reload intervals must not be negative")
+	}
+
+	if err := k.rotation.validate(k.writer); err != nil {
+		return nil, err
+	}
+
+	if k.registerer != nil {
+		k.loads = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "auth_signing_keys_loads_total",
+			Help: "Количество чтений ключей подписи: vault - прочитаны из Vault, deduplicated - получены от параллельного чтения.",
+		}, []string{"source"})
+
+		if err := k.registerer.Register(k.loads); err != nil {
+			return nil, err
+		}
+	}
+
+	if k.registerer != nil && k.rotation.Period > 0 {
+		k.rotations = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "auth_signing_key_rotations_total",
+			Help: "Количество ротаций ключа подписи: ok - новый ключ стал текущим, error - ротация не удалась," +
+				" conflict - ротацию одновременно выполнил другой экземпляр.",
+		}, []string{"result"})
+
+		k.rotatedAt = prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "auth_signing_key_rotated_timestamp_seconds",
+			Help: "Время последней ротации ключа подписи (unix time) по секрету Vault.",
+		})
+
+		for _, c := range []prometheus.Collector{k.rotations, k.rotatedAt} {
+			if err := k.registerer.Register(c); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return k, nil
+}
+
+// Key возвращает ключ подписи по kid. Если ключа нет в кэше, перечитывает секрет из Vault,
+// но не чаще раза в MinReloadInterval: до этого неизвестный kid сразу отклоняется.
+func (k *VaultKeys) Key(ctx context.Context, kid string) ([]byte, error) {
+	k.mu.RLock()
+	key, ok := k.cache[kid]
+	recent := k.loadedWithin(k.minReloadInterval)
+	k.mu.RUnlock()
+
+	if ok {
+		return key, nil
+	}
+
+	if recent {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, kid)
+	}
+
+	if _, err := k.load(ctx); err != nil {
+		return nil, err
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, ok = k.cache[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, kid)
+	}
+
+	return key, nil
+}
+
+// SigningKey возвращает kid и ключ, которым нужно подписывать новые токены. Текущий ключ берется
+// из кэша, если секрет читался не раньше RefreshInterval назад, иначе секрет перечитывается из Vault,
+// чтобы подхватить смену текущего ключа.
+func (k *VaultKeys) SigningKey(ctx context.Context) (string, []byte, error) {
+	k.mu.RLock()
+	kid := k.current
+	key, ok := k.cache[kid]
+	fresh := k.loadedWithin(k.refreshInterval)
+	k.mu.RUnlock()
+
+	if fresh && kid != "" && ok {
+		return kid, key, nil
+	}
+
+	kid, err := k.load(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if kid == "" {
+		return "", nil, fmt.Errorf("token: current signing key is not set in %s", k.path)
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, ok = k.cache[kid]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %s", ErrUnknownKey, kid)
+	}
+
+	return kid, key, nil
+}
+
+// loadedWithin возвращает true, если секрет успешно читался не раньше interval назад. Вызывается под k.mu.
+func (k *VaultKeys) loadedWithin(interval time.Duration) bool {
+	return !k.loadedAt.IsZero() && k.now().Sub(k.loadedAt) < interval
+}
+
+// KeyIDs перечитывает секрет из Vault и возвращает kid всех ключей, которыми принимаются токены, по возрастанию.
+// Сами ключи не возвращаются.
+func (k *VaultKeys) KeyIDs(ctx context.Context) ([]string, error) {
+	if _, err := k.load(ctx); err != nil {
+		return nil, err
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return slices.Sorted(maps.Keys(k.cache)), nil
+}
+
+// load перечитывает секрет с ключами в кэш и возвращает kid текущего ключа.
+// Параллельные вызовы объединяются: секрет читает первый вызов, остальные получают его результат.
+// Чтение не прерывается отменой контекста одного из ожидающих, но каждый из них перестает ждать
+// при отмене своего контекста.
+func (k *VaultKeys) load(ctx context.Context) (string, error) {
+	executed := false
+
+	ch := k.group.DoChan(k.path, func() (interface{}, error) {
+		executed = true
+
+		return k.read(context.WithoutCancel(ctx))
+	})
+
+	select {
+	case <-ctx.Done():
+		return "", fmt.Errorf("token: error read signing keys: %w", ctx.Err())
+	case res := <-ch:
+		source := keysLoadVault
+		if !executed {
+			source = keysLoadDeduplicated
+		}
+
+		if k.loads != nil {
+			k.loads.WithLabelValues(source).Inc()
+		}
+
+		if res.Err != nil {
+			return "", res.Err
+		}
+
+		current, _ := res.Val.(string)
+
+		return current, nil
+	}
+}
+
+// read читает секрет с ключами из Vault в кэш и возвращает kid текущего ключа.
+func (k *VaultKeys) read(ctx context.Context) (string, error) {
+	data, err := k.client.ReadKV(ctx, k.path)
+	if err != nil {
+		return "", fmt.Errorf("token: error read signing keys: %w", err)
+	}
+
+	return k.apply(data), nil
+}
+
+// apply заменяет кэш ключами из секрета и возвращает kid текущего ключа.
+func (k *VaultKeys) apply(data map[string]interface{}) string {
+	current, _ := data[currentField].(string)
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	// кэш заменяется целиком: ключи, удаленные из секрета при ротации, перестают приниматься.
+	// Неизменившиеся ключи переиспользуются, чтобы не копировать их при каждом чтении.
+	cache := make(map[string][]byte, len(data))
+
+	for id, v := range data {
+		secret, ok := v.(string)
+		if !ok || secret == "" || isServiceField(id) {
+			continue
+		}
+
+		if key, ok := k.cache[id]; ok && string(key) == secret {
+			cache[id] = key
+			continue
+		}
+
+		cache[id] = []byte(secret)
+	}
+
+	k.cache = cache
+	k.current = current
+	k.loadedAt = k.now()
+
+	return current
+}
+
+// isServiceField возвращает true для служебных полей секрета, которые не являются ключами.
+func isServiceField(name string) bool {
+	return name == currentField || name == rotatedAtField || name == retiredField
+}
@@ -0,0 +1,469 @@
+package token
+
+import (
+	"auth-service/internal/service/token/mocks"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVaultKeys(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	client := mocks.NewMockkvReader(ctrl)
+
+	tests := []struct {
+		name    string
+		opts    []KeysOption
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			opts:    []KeysOption{WithKVReader(client)},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: client is nil",
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: empty path",
+			opts:    []KeysOption{WithKVReader(client), WithKeysPath("")},
+			wantErr: require.Error,
+		},
+		{
+			name:    "positive case: rotation",
+			opts:    []KeysOption{WithKVReader(client), WithKVWriter(&fakeKV{}), WithRotation(KeyRotation{Period: time.Hour})},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: rotation without writer",
+			opts:    []KeysOption{WithKVReader(client), WithRotation(KeyRotation{Period: time.Hour})},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: negative rotation grace",
+			opts:    []KeysOption{WithKVReader(client), WithKVWriter(&fakeKV{}), WithRotation(KeyRotation{Period: time.Hour, Grace: -time.Hour})},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: negative refresh interval",
+			opts:    []KeysOption{WithKVReader(client), WithKeysRefreshInterval(-time.Second)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: negative min reload interval",
+			opts:    []KeysOption{WithKVReader(client), WithKeysMinReloadInterval(-time.Second)},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewVaultKeys(tt.opts...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestVaultKeys_Key(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		kid     string
+		setup   func(t *testing.T, client *mocks.MockkvReader)
+		want    []byte
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "positive case",
+			kid:  "key-1",
+			setup: func(t *testing.T, client *mocks.MockkvReader) {
+				t.Helper()
+
+				client.EXPECT().ReadKV(gomock.Any(), "secret/data/keys").
+					Return(map[string]interface{}{"key-1": "secret-1", "key-2": "secret-2"}, nil)
+			},
+			want:    []byte("secret-1"),
+			wantErr: require.NoError,
+		},
+		{
+			name: "error case: unknown kid",
+			kid:  "key-3",
+			setup: func(t *testing.T, client *mocks.MockkvReader) {
+				t.Helper()
+
+				client.EXPECT().ReadKV(gomock.Any(), "secret/data/keys").
+					Return(map[string]interface{}{"key-1": "secret-1", "key-3": 42}, nil)
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrUnknownKey)
+			},
+		},
+		{
+			name: "error case: vault error",
+			kid:  "key-1",
+			setup: func(t *testing.T, client *mocks.MockkvReader) {
+				t.Helper()
+
+				client.EXPECT().ReadKV(gomock.Any(), "secret/data/keys").Return(nil, errors.New("vault is sealed"))
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "vault is sealed")
+				require.NotErrorIs(t, err, ErrUnknownKey)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockkvReader(ctrl)
+			tt.setup(t, client)
+
+			keys, err := NewVaultKeys(WithKVReader(client), WithKeysPath("secret/data/keys"))
+			require.NoError(t, err)
+
+			got, err := keys.Key(t.Context(), tt.kid)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVaultKeys_Key_Cached(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	client := mocks.NewMockkvReader(ctrl)
+
+	// Vault читается один раз, дальше ключи берутся из кэша
+	client.EXPECT().ReadKV(gomock.Any(), DefaultKeysPath).
+		Return(map[string]interface{}{"key-1": "secret-1", "key-2": "secret-2"}, nil).Times(1)
+
+	keys, err := NewVaultKeys(WithKVReader(client))
+	require.NoError(t, err)
+
+	for _, kid := range []string{"key-1", "key-2", "key-1"} {
+		_, err := keys.Key(t.Context(), kid)
+		require.NoError(t, err)
+	}
+}
+
+func TestVaultKeys_Rotation(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	client := mocks.NewMockkvReader(ctrl)
+
+	gomock.InOrder(
+		client.EXPECT().ReadKV(gomock.Any(), DefaultKeysPath).
+			Return(map[string]interface{}{"current": "key-1", "key-1": "secret-1"}, nil),
+		// ротация: key-1 удален из секрета
+		client.EXPECT().ReadKV(gomock.Any(), DefaultKeysPath).
+			Return(map[string]interface{}{"current": "key-2", "key-2": "secret-2"}, nil),
+		// промах кэша по key-1 перечитывает секрет
+		client.EXPECT().ReadKV(gomock.Any(), DefaultKeysPath).
+			Return(map[string]interface{}{"current": "key-2", "key-2": "secret-2"}, nil),
+	)
+
+	keys, err := NewVaultKeys(WithKVReader(client))
+	require.NoError(t, err)
+
+	now := time.Now()
+	keys.now = func() time.Time { return now }
+
+	key, err := keys.Key(t.Context(), "key-1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret-1"), key)
+
+	// до окончания интервала обновления подписывается ключом из кэша
+	kid, _, err := keys.SigningKey(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", kid)
+
+	now = now.Add(DefaultKeysRefreshInterval)
+
+	kid, _, err = keys.SigningKey(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "key-2", kid)
+
+	now = now.Add(DefaultKeysMinReloadInterval)
+
+	_, err = keys.Key(t.Context(), "key-1")
+	require.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestVaultKeys_Key_MinReloadInterval(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	client := mocks.NewMockkvReader(ctrl)
+
+	gomock.InOrder(
+		client.EXPECT().ReadKV(gomock.Any(), DefaultKeysPath).
+			Return(map[string]interface{}{"current": "key-1", "key-1": "secret-1"}, nil),
+		client.EXPECT().ReadKV(gomock.Any(), DefaultKeysPath).
+			Return(map[string]interface{}{"current": "key-2", "key-1": "secret-1", "key-2": "secret-2"}, nil),
+	)
+
+	keys, err := NewVaultKeys(WithKVReader(client), WithKeysMinReloadInterval(10*time.Second))
+	require.NoError(t, err)
+
+	now := time.Now()
+	keys.now = func() time.Time { return now }
+
+	_, err = keys.Key(t.Context(), "key-1")
+	require.NoError(t, err)
+
+	// неизвестные kid сразу после чтения отклоняются без обращения к Vault
+	for _, kid := range []string{"random-1", "random-2", "key-2"} {
+		_, err = keys.Key(t.Context(), kid)
+		require.ErrorIs(t, err, ErrUnknownKey)
+	}
+
+	now = now.Add(10 * time.Second)
+
+	key, err := keys.Key(t.Context(), "key-2")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret-2"), key)
+}
+
+func TestVaultKeys_SigningKey_Cached(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	client := mocks.NewMockkvReader(ctrl)
+
+	// выпуск токенов не читает Vault, пока не прошел интервал обновления
+	client.EXPECT().ReadKV(gomock.Any(), DefaultKeysPath).
+		Return(map[string]interface{}{"current": "key-1", "key-1": "secret-1"}, nil).Times(2)
+
+	keys, err := NewVaultKeys(WithKVReader(client), WithKeysRefreshInterval(time.Minute))
+	require.NoError(t, err)
+
+	now := time.Now()
+	keys.now = func() time.Time { return now }
+
+	for range 3 {
+		kid, key, err := keys.SigningKey(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, "key-1", kid)
+		assert.Equal(t, []byte("secret-1"), key)
+	}
+
+	now = now.Add(time.Minute)
+
+	_, _, err = keys.SigningKey(t.Context())
+	require.NoError(t, err)
+}
+
+func TestVaultKeys_KeyIDs(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	client := mocks.NewMockkvReader(ctrl)
+
+	gomock.InOrder(
+		client.EXPECT().ReadKV(gomock.Any(), DefaultKeysPath).
+			Return(map[string]interface{}{"current": "key-2", "key-2": "secret-2", "key-1": "secret-1"}, nil),
+		client.EXPECT().ReadKV(gomock.Any(), DefaultKeysPath).Return(nil, errors.New("vault is sealed")),
+	)
+
+	keys, err := NewVaultKeys(WithKVReader(client))
+	require.NoError(t, err)
+
+	kids, err := keys.KeyIDs(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"key-1", "key-2"}, kids)
+
+	_, err = keys.KeyIDs(t.Context())
+	require.Error(t, err)
+}
+
+func TestVaultKeys_Key_SingleFlight(t *testing.T) {
+	t.Parallel()
+
+	const callers = 10
+
+	ctrl := gomock.NewController(t)
+	client := mocks.NewMockkvReader(ctrl)
+
+	release := make(chan struct{})
+
+	// параллельные промахи кэша приводят к одному чтению из Vault
+	client.EXPECT().ReadKV(gomock.Any(), DefaultKeysPath).
+		DoAndReturn(func(context.Context, string) (map[string]interface{}, error) {
+			<-release
+			return map[string]interface{}{"key-1": "secret-1"}, nil
+		}).Times(1)
+
+	keys, err := NewVaultKeys(WithKVReader(client), WithKeysRegisterer(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+
+	for range callers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			key, err := keys.Key(t.Context(), "key-1")
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("secret-1"), key)
+		}()
+	}
+
+	// даем всем вызовам дойти до ожидания чтения
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.InDelta(t, 1, testutil.ToFloat64(keys.loads.WithLabelValues(keysLoadVault)), 0)
+	assert.InDelta(t, callers-1, testutil.ToFloat64(keys.loads.WithLabelValues(keysLoadDeduplicated)), 0)
+}
+
+func TestVaultKeys_Key_CanceledWaiter(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	client := mocks.NewMockkvReader(ctrl)
+
+	release := make(chan struct{})
+	defer close(release)
+
+	client.EXPECT().ReadKV(gomock.Any(), DefaultKeysPath).
+		DoAndReturn(func(ctx context.Context, _ string) (map[string]interface{}, error) {
+			// отмена контекста вызывающего не прерывает общее чтение
+			assert.NoError(t, ctx.Err())
+			<-release
+
+			return map[string]interface{}{}, nil
+		}).AnyTimes()
+
+	keys, err := NewVaultKeys(WithKVReader(client))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = keys.Key(ctx, "key-1")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestVaultKeys_SigningKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    map[string]interface{}
+		err     error
+		wantKid string
+		wantKey []byte
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			data:    map[string]interface{}{"current": "key-2", "key-1": "secret-1", "key-2": "secret-2"},
+			wantKid: "key-2",
+			wantKey: []byte("secret-2"),
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: current is not set",
+			data:    map[string]interface{}{"key-1": "secret-1"},
+			wantErr: require.Error,
+		},
+		{
+			name: "error case: current key is missing",
+			data: map[string]interface{}{"current": "key-2", "key-1": "secret-1"},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrUnknownKey)
+			},
+		},
+		{
+			name: "error case: vault error",
+			err:  errors.New("vault is sealed"),
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "vault is sealed")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockkvReader(ctrl)
+			client.EXPECT().ReadKV(gomock.Any(), DefaultKeysPath).Return(tt.data, tt.err)
+
+			keys, err := NewVaultKeys(WithKVReader(client))
+			require.NoError(t, err)
+
+			kid, key, err := keys.SigningKey(t.Context())
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.wantKid, kid)
+			assert.Equal(t, tt.wantKey, key)
+		})
+	}
+}
+
+func BenchmarkVaultKeys_Key(b *testing.B) {
+	ctrl := gomock.NewController(b)
+	client := mocks.NewMockkvReader(ctrl)
+
+	client.EXPECT().ReadKV(gomock.Any(), DefaultKeysPath).
+		Return(map[string]interface{}{"key-1": "secret-1"}, nil).Times(1)
+
+	keys, err := NewVaultKeys(WithKVReader(client))
+	require.NoError(b, err)
+
+	_, err = keys.Key(b.Context(), "key-1")
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if _, err := keys.Key(b.Context(), "key-1"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVaultKeys_Reload(b *testing.B) {
+	ctrl := gomock.NewController(b)
+	client := mocks.NewMockkvReader(ctrl)
+
+	data := map[string]interface{}{"current": "key-3", "key-1": "secret-1", "key-2": "secret-2", "key-3": "secret-3"}
+
+	client.EXPECT().ReadKV(gomock.Any(), DefaultKeysPath).Return(data, nil).AnyTimes()
+
+	keys, err := NewVaultKeys(WithKVReader(client))
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if _, _, err := keys.SigningKey(b.Context()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package token
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+const (
+	// DefaultMaxTokenSize - максимальный размер подписанного токена по умолчанию. Большинство шлюзов
+	// ограничивают размер заголовков 8KB, а токен часто передается еще и в cookie.
+	DefaultMaxTokenSize = 4096
+	// DefaultMaxCustomClaimsSize - максимальный размер пользовательских claims в JSON по умолчанию.
+	DefaultMaxCustomClaimsSize = 1024
+)
+
+// reservedClaims - claims, которые заполняет сам сервис. Их нельзя передать как пользовательские.
+var reservedClaims = []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti", "scope", "act", "groups", "cnf", "env", "sid"}
+
+// ErrClaimsRejected - токен не выпущен из-за ограничений на размер или имена claims.
+var ErrClaimsRejected = errors.New("claims rejected")
+
+// Limits - ограничения выпускаемых токенов.
+type Limits struct {
+	MaxTokenSize        int      // Максимальный размер подписанного токена в байтах
+	MaxCustomClaimsSize int      // Максимальный размер пользовательских claims в JSON в байтах
+	ForbiddenClaims     []string // Запрещенные имена пользовательских claims в дополнение к зарезервированным
+}
+
+func (l Limits) validate() error {
+	if l.MaxTokenSize <= 0 {
+		return errors.New("max token size must be positive")
+	}
+
+	if l.MaxCustomClaimsSize <= 0 {
+		return errors.New("max custom claims size must be positive")
+	}
+
+	if l.MaxCustomClaimsSize >= l.MaxTokenSize {
+		return errors.New("max custom claims size must be less than max token size")
+	}
+
+	return nil
+}
+
+// checkCustom проверяет имена и размер пользовательских claims.
+func (l Limits) checkCustom(claims map[string]interface{}) error {
+	if len(claims) == 0 {
+		return nil
+	}
+
+	for name := range claims {
+		if slices.Contains(reservedClaims, name) {
+			return fmt.Errorf("%w: claim %q is reserved", ErrClaimsRejected, name)
+		}
+
+		if slices.Contains(l.ForbiddenClaims, name) {
+			return fmt.Errorf("%w: claim %q is forbidden", ErrClaimsRejected, name)
+		}
+	}
+
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrClaimsRejected, err)
+	}
+
+	// claims пользователя должны разбираться при проверке токена
+	if err := json.Unmarshal(data, &principalClaims{}); err != nil {
+		return fmt.Errorf("%w: invalid user claim type: %w", ErrClaimsRejected, err)
+	}
+
+	if len(data) > l.MaxCustomClaimsSize {
+		return fmt.Errorf("%w: custom claims size %d bytes exceeds limit %d", ErrClaimsRejected, len(data), l.MaxCustomClaimsSize)
+	}
+
+	return nil
+}
+
+// checkToken проверяет размер подписанного токена.
+func (l Limits) checkToken(raw string) error {
+	if len(raw) > l.MaxTokenSize {
+		return fmt.Errorf("%w: token size %d bytes exceeds limit %d", ErrClaimsRejected, len(raw), l.MaxTokenSize)
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package token
+
+import (
+	"auth-service/internal/service/token/mocks"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimits_Validate(t *testing.T) {
+	t.Parallel()
+
+	keys := mocks.NewMocksigningKeyProvider(gomock.NewController(t))
+
+	tests := []struct {
+		name    string
+		limits  Limits
+		wantErr string
+	}{
+		{name: "zero token size", limits: Limits{MaxCustomClaimsSize: 1}, wantErr: "max token size must be positive"},
+		{name: "zero claims size", limits: Limits{MaxTokenSize: 1}, wantErr: "max custom claims size must be positive"},
+		{name: "claims larger than token", limits: Limits{MaxTokenSize: 100, MaxCustomClaimsSize: 100}, wantErr: "must be less than max token size"},
+		{name: "positive case", limits: Limits{MaxTokenSize: 100, MaxCustomClaimsSize: 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewIssuer(WithSigningKeys(keys), WithLimits(tt.limits))
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestIssuer_Issue_Limits(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+
+	keys := mocks.NewMocksigningKeyProvider(gomock.NewController(t))
+	keys.EXPECT().SigningKey(gomock.Any()).Return("key-1", key, nil).AnyTimes()
+
+	issuer, err := NewIssuer(WithSigningKeys(keys), WithLimits(Limits{
+		MaxTokenSize:        600,
+		MaxCustomClaimsSize: 100,
+		ForbiddenClaims:     []string{"role"},
+	}))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		req     IssueRequest
+		wantErr string
+	}{
+		{
+			name: "custom claims",
+			req:  IssueRequest{Subject: "user-1", TTL: time.Minute, Claims: map[string]interface{}{"tenant": "acme", "plan": 2}},
+		},
+		{
+			name:    "reserved claim",
+			req:     IssueRequest{Subject: "user-1", TTL: time.Minute, Claims: map[string]interface{}{"sub": "admin"}},
+			wantErr: `claim "sub" is reserved`,
+		},
+		{
+			name:    "session claim is reserved",
+			req:     IssueRequest{Subject: "user-1", TTL: time.Minute, Claims: map[string]interface{}{"sid": "other-session"}},
+			wantErr: `claim "sid" is reserved`,
+		},
+		{
+			name:    "forbidden claim",
+			req:     IssueRequest{Subject: "user-1", TTL: time.Minute, Claims: map[string]interface{}{"role": "admin"}},
+			wantErr: `claim "role" is forbidden`,
+		},
+		{
+			name:    "user claim of wrong type",
+			req:     IssueRequest{Subject: "user-1", TTL: time.Minute, Claims: map[string]interface{}{"tg_id": "12345"}},
+			wantErr: "invalid user claim type",
+		},
+		{
+			name:    "custom claims too large",
+			req:     IssueRequest{Subject: "user-1", TTL: time.Minute, Claims: map[string]interface{}{"blob": strings.Repeat("a", 100)}},
+			wantErr: "custom claims size",
+		},
+		{
+			name:    "token too large",
+			req:     IssueRequest{Subject: "user-1", TTL: time.Minute, Audience: []string{strings.Repeat("a", 600)}},
+			wantErr: "token size",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			raw, _, err := issuer.Issue(t.Context(), tt.req)
+			if tt.wantErr != "" {
+				require.ErrorIs(t, err, ErrClaimsRejected)
+				require.ErrorContains(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			payload, err := base64.RawURLEncoding.DecodeString(strings.Split(raw, ".")[1])
+			require.NoError(t, err)
+
+			var got map[string]interface{}
+
+			require.NoError(t, json.Unmarshal(payload, &got))
+			assert.Equal(t, "user-1", got["sub"])
+			assert.Equal(t, "acme", got["tenant"])
+			assert.InDelta(t, 2, got["plan"], 0)
+
+			// токен с пользовательскими claims проходит проверку
+			validator, err := NewValidator(WithKeys(staticKeys{"key-1": key}))
+			require.NoError(t, err)
+
+			claims, err := validator.Validate(t.Context(), raw)
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", claims.Subject)
+			assert.Equal(t, "acme", claims.Tenant)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: issuer.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MocksigningKeyProvider is a mock of signingKeyProvider interface.
+type MocksigningKeyProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MocksigningKeyProviderMockRecorder
+}
+
+// MocksigningKeyProviderMockRecorder is the mock recorder for MocksigningKeyProvider.
+type MocksigningKeyProviderMockRecorder struct {
+	mock *MocksigningKeyProvider
+}
+
+// NewMocksigningKeyProvider creates a new mock instance.
+func NewMocksigningKeyProvider(ctrl *gomock.Controller) *MocksigningKeyProvider {
+	mock := &MocksigningKeyProvider{ctrl: ctrl}
+	mock.recorder = &MocksigningKeyProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocksigningKeyProvider) EXPECT() *MocksigningKeyProviderMockRecorder {
+	return m.recorder
+}
+
+// SigningKey mocks base method.
+func (m *MocksigningKeyProvider) SigningKey(ctx context.Context) (string, []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SigningKey", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].([]byte)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SigningKey indicates an expected call of SigningKey.
+func (mr *MocksigningKeyProviderMockRecorder) SigningKey(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SigningKey", reflect.TypeOf((*MocksigningKeyProvider)(nil).SigningKey), ctx)
+}
+
+// MockgroupSource is a mock of groupSource interface.
+type MockgroupSource struct {
+	ctrl     *gomock.Controller
+	recorder *MockgroupSourceMockRecorder
+}
+
+// MockgroupSourceMockRecorder is the mock recorder for MockgroupSource.
+type MockgroupSourceMockRecorder struct {
+	mock *MockgroupSource
+}
+
+// NewMockgroupSource creates a new mock instance.
+func NewMockgroupSource(ctrl *gomock.Controller) *MockgroupSource {
+	mock := &MockgroupSource{ctrl: ctrl}
+	mock.recorder = &MockgroupSourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockgroupSource) EXPECT() *MockgroupSourceMockRecorder {
+	return m.recorder
+}
+
+// Memberships mocks base method.
+func (m *MockgroupSource) Memberships(ctx context.Context, userID string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Memberships", ctx, userID)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Memberships indicates an expected call of Memberships.
+func (mr *MockgroupSourceMockRecorder) Memberships(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Memberships", reflect.TypeOf((*MockgroupSource)(nil).Memberships), ctx, userID)
+}
+
+// MockissueStats is a mock of issueStats interface.
+type MockissueStats struct {
+	ctrl     *gomock.Controller
+	recorder *MockissueStatsMockRecorder
+}
+
+// MockissueStatsMockRecorder is the mock recorder for MockissueStats.
+type MockissueStatsMockRecorder struct {
+	mock *MockissueStats
+}
+
+// NewMockissueStats creates a new mock instance.
+func NewMockissueStats(ctrl *gomock.Controller) *MockissueStats {
+	mock := &MockissueStats{ctrl: ctrl}
+	mock.recorder = &MockissueStatsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockissueStats) EXPECT() *MockissueStatsMockRecorder {
+	return m.recorder
+}
+
+// TokenIssued mocks base method.
+func (m *MockissueStats) TokenIssued(ctx context.Context, audience []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TokenIssued", ctx, audience)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TokenIssued indicates an expected call of TokenIssued.
+func (mr *MockissueStatsMockRecorder) TokenIssued(ctx, audience interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TokenIssued", reflect.TypeOf((*MockissueStats)(nil).TokenIssued), ctx, audience)
+}
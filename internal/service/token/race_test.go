@@ -0,0 +1,6 @@
+//go:build race
+
+package token
+
+// raceEnabled - тесты собраны с детектором гонок, который добавляет выделения памяти.
+const raceEnabled = true
@@ -0,0 +1,266 @@
+package token
+
+import (
+	"auth-service/internal/service/id"
+	"auth-service/internal/storage/vault"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Служебные поля секрета ключей подписи, которые ведет ротация.
+const (
+	// rotatedAtField - время последней ротации (RFC 3339).
+	rotatedAtField = "rotated_at"
+	// retiredField - kid -> время (RFC 3339), когда ключ перестал быть текущим.
+	retiredField = "retired"
+)
+
+const (
+	// DefaultRotationGrace - сколько замененный ключ принимается после ротации по умолчанию.
+	DefaultRotationGrace = 24 * time.Hour
+
+	// rotationKeySize - размер нового ключа подписи в байтах (HS256).
+	rotationKeySize = 32
+	// rotationKidLength - длина kid нового ключа.
+	rotationKidLength = 8
+)
+
+// Результаты ротации в метриках.
+const (
+	rotationOK       = "ok"
+	rotationError    = "error"
+	rotationConflict = "conflict"
+)
+
+// kvWriter - интерфейс для записи секретов KV в Vault с проверкой версии (check-and-set).
+type kvWriter interface {
+	ReadKVVersion(ctx context.Context, path string) (map[string]interface{}, int, error)
+	WriteKVCAS(ctx context.Context, path string, data map[string]interface{}, version int) error
+}
+
+// KeyRotation - параметры автоматической ротации ключей подписи.
+type KeyRotation struct {
+	// Period - как долго ключ остается текущим. 0 - ротация выключена, ключи меняются вручную.
+	Period time.Duration
+	// Grace - сколько замененный ключ еще принимается при проверке. Должен быть не меньше
+	// времени жизни токенов, иначе токены, выпущенные перед ротацией, перестанут приниматься раньше срока.
+	Grace time.Duration
+}
+
+func (r KeyRotation) validate(writer kvWriter) error {
+	if r.Period < 0 {
+		return errors.New("rotation period must not be negative")
+	}
+
+	if r.Period == 0 {
+		return nil
+	}
+
+	if r.Grace <= 0 {
+		return errors.New("rotation grace must be positive")
+	}
+
+	if writer == nil {
+		return errors.New("vault writer is required for rotation")
+	}
+
+	return nil
+}
+
+// Rotation - результат ротации ключа подписи.
+type Rotation struct {
+	// Kid - новый текущий ключ.
+	Kid string
+	// Previous - замененный ключ, принимается до окончания Grace. Пусто, если текущего ключа не было.
+	Previous string
+	// Removed - ключи, у которых закончился Grace и которые удалены из секрета.
+	Removed   []string
+	RotatedAt time.Time
+}
+
+// WithKVWriter устанавливает запись секрета с ключами в Vault. Нужна для ротации.
+func WithKVWriter(client kvWriter) KeysOption {
+	return func(k *VaultKeys) {
+		k.writer = client
+	}
+}
+
+// WithRotation включает автоматическую ротацию ключей подписи, см. Rotate.
+// Если Grace не задан, используется DefaultRotationGrace.
+func WithRotation(rotation KeyRotation) KeysOption {
+	return func(k *VaultKeys) {
+		k.rotation.Period = rotation.Period
+
+		if rotation.Grace != 0 {
+			k.rotation.Grace = rotation.Grace
+		}
+	}
+}
+
+// Rotate перечитывает секрет с ключами и, если текущий ключ стал текущим не меньше Period назад,
+// создает новый ключ и делает его текущим. Замененный ключ остается в секрете и принимается
+// при проверке еще Grace, после чего удаляется при следующей ротации. Ключи, добавленные в секрет
+// вручную, не удаляются, пока не станут текущими и не будут заменены.
+//
+// Секрет без времени ротации (созданный вручную) ротируется при первом вызове. Если ротация не нужна,
+// возвращает nil: так несколько экземпляров сервиса, проверяющих секрет, выполняют одну ротацию за период.
+// Секрет записывается с check-and-set по прочитанной версии: если другой экземпляр успел выполнить
+// ротацию между чтением и записью, запись отклоняется, ключи перечитываются и возвращается nil.
+func (k *VaultKeys) Rotate(ctx context.Context) (*Rotation, error) {
+	if k.rotation.Period <= 0 {
+		return nil, errors.New("token: signing key rotation is not configured")
+	}
+
+	data, version, err := k.writer.ReadKVVersion(ctx, k.path)
+	if err != nil {
+		return nil, fmt.Errorf("token: error read signing keys: %w", err)
+	}
+
+	now := k.now().UTC().Truncate(time.Second)
+	current, _ := data[currentField].(string)
+	rotatedAt := parseRotationTime(data[rotatedAtField])
+
+	if current != "" && !rotatedAt.IsZero() && now.Before(rotatedAt.Add(k.rotation.Period)) {
+		k.apply(data)
+		k.observeRotatedAt(rotatedAt)
+
+		return nil, nil //nolint:nilnil // ротация не нужна
+	}
+
+	rotation, next, err := k.nextSecret(data, current, now)
+	if err == nil {
+		err = k.writer.WriteKVCAS(ctx, k.path, next, version)
+		if errors.Is(err, vault.ErrCASMismatch) {
+			return nil, k.reloadAfterConflict(ctx)
+		}
+
+		if err != nil {
+			err = fmt.Errorf("token: error write signing keys: %w", err)
+		}
+	}
+
+	if err != nil {
+		k.observeRotation(rotationError)
+
+		return nil, err
+	}
+
+	k.apply(next)
+	k.observeRotation(rotationOK)
+	k.observeRotatedAt(now)
+
+	logrus.WithFields(logrus.Fields{
+		"vault_path": k.path,
+		"kid":        rotation.Kid,
+		"previous":   rotation.Previous,
+		"removed":    rotation.Removed,
+		"grace":      k.rotation.Grace,
+	}).Info("signing key rotated")
+
+	return rotation, nil
+}
+
+// reloadAfterConflict перечитывает ключи после того, как ротацию выполнил другой экземпляр.
+func (k *VaultKeys) reloadAfterConflict(ctx context.Context) error {
+	k.observeRotation(rotationConflict)
+
+	data, err := k.client.ReadKV(ctx, k.path)
+	if err != nil {
+		return fmt.Errorf("token: error read signing keys: %w", err)
+	}
+
+	k.apply(data)
+	k.observeRotatedAt(parseRotationTime(data[rotatedAtField]))
+
+	logrus.WithField("vault_path", k.path).Info("signing key already rotated by another instance, keys reloaded")
+
+	return nil
+}
+
+// nextSecret возвращает секрет после ротации: с новым текущим ключом, отметкой о замене
+// предыдущего и без ключей, у которых закончился Grace.
+func (k *VaultKeys) nextSecret(data map[string]interface{}, current string, now time.Time) (*Rotation, map[string]interface{}, error) {
+	kid, secret, err := generateSigningKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	next := maps.Clone(data)
+	retired := map[string]interface{}{}
+
+	if old, ok := data[retiredField].(map[string]interface{}); ok {
+		maps.Copy(retired, old)
+	}
+
+	if current != "" {
+		retired[current] = now.Format(time.RFC3339)
+	}
+
+	rotation := &Rotation{Kid: kid, Previous: current, RotatedAt: now}
+
+	for _, old := range slices.Sorted(maps.Keys(retired)) {
+		retiredAt := parseRotationTime(retired[old])
+
+		// ключ, удаленный из секрета вручную, или с испорченной отметкой больше не отслеживается
+		if _, ok := next[old]; !ok || retiredAt.IsZero() {
+			delete(retired, old)
+			continue
+		}
+
+		if !now.Before(retiredAt.Add(k.rotation.Grace)) {
+			delete(next, old)
+			delete(retired, old)
+
+			rotation.Removed = append(rotation.Removed, old)
+		}
+	}
+
+	next[kid] = secret
+	next[currentField] = kid
+	next[rotatedAtField] = now.Format(time.RFC3339)
+	next[retiredField] = retired
+
+	return rotation, next, nil
+}
+
+func (k *VaultKeys) observeRotation(result string) {
+	if k.rotations != nil {
+		k.rotations.WithLabelValues(result).Inc()
+	}
+}
+
+func (k *VaultKeys) observeRotatedAt(rotatedAt time.Time) {
+	if k.rotatedAt != nil {
+		k.rotatedAt.Set(float64(rotatedAt.Unix()))
+	}
+}
+
+func parseRotationTime(v interface{}) time.Time {
+	s, _ := v.(string)
+	t, _ := time.Parse(time.RFC3339, s)
+
+	return t
+}
+
+// generateSigningKey создает kid и секрет нового ключа подписи.
+func generateSigningKey() (string, string, error) {
+	kid, err := id.Generate(rotationKidLength)
+	if err != nil {
+		return "", "", fmt.Errorf("token: error generate kid: %w", err)
+	}
+
+	key := make([]byte, rotationKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", "", fmt.Errorf("token: error generate signing key: %w", err)
+	}
+
+	return kid, base64.RawURLEncoding.EncodeToString(key), nil
+}
@@ -0,0 +1,224 @@
+package token
+
+import (
+	"auth-service/internal/storage/vault"
+	"context"
+	"errors"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKV - секрет Vault KV v2 в памяти.
+type fakeKV struct {
+	mu       sync.Mutex
+	data     map[string]interface{}
+	version  int
+	writeErr error
+	writes   int
+	// afterRead вызывается после чтения версии секрета, если задан.
+	afterRead func()
+}
+
+func (f *fakeKV) ReadKV(_ context.Context, _ string) (map[string]interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return maps.Clone(f.data), nil
+}
+
+func (f *fakeKV) ReadKVVersion(_ context.Context, _ string) (map[string]interface{}, int, error) {
+	f.mu.Lock()
+	data, version := maps.Clone(f.data), f.version
+	f.mu.Unlock()
+
+	if f.afterRead != nil {
+		f.afterRead()
+	}
+
+	return data, version, nil
+}
+
+func (f *fakeKV) WriteKVCAS(_ context.Context, _ string, data map[string]interface{}, version int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.writeErr != nil {
+		return f.writeErr
+	}
+
+	if version != f.version {
+		return vault.ErrCASMismatch
+	}
+
+	f.data = data
+	f.version++
+	f.writes++
+
+	return nil
+}
+
+func newRotatingKeys(t *testing.T, kv *fakeKV, rotation KeyRotation) *VaultKeys {
+	t.Helper()
+
+	keys, err := NewVaultKeys(
+		WithKVReader(kv),
+		WithKVWriter(kv),
+		WithRotation(rotation),
+		WithKeysRegisterer(prometheus.NewRegistry()),
+	)
+	require.NoError(t, err)
+
+	return keys
+}
+
+func TestVaultKeys_Rotate(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// секрет создан вручную: без времени ротации
+	kv := &fakeKV{data: map[string]interface{}{"current": "k1", "k1": "secret-1", "manual": "secret-2"}}
+	keys := newRotatingKeys(t, kv, KeyRotation{Period: 24 * time.Hour, Grace: 2 * time.Hour})
+
+	now := start
+	keys.now = func() time.Time { return now }
+
+	first, err := keys.Rotate(t.Context())
+	require.NoError(t, err)
+	require.NotNil(t, first)
+	assert.Equal(t, "k1", first.Previous)
+	assert.Empty(t, first.Removed)
+
+	kid, key, err := keys.SigningKey(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, first.Kid, kid)
+	assert.Len(t, key, 43)
+
+	// замененный ключ еще принимается, служебные поля не считаются ключами
+	ids, err := keys.KeyIDs(t.Context())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"k1", "manual", first.Kid}, ids)
+
+	// до окончания периода ротация не нужна
+	now = start.Add(23 * time.Hour)
+
+	rotation, err := keys.Rotate(t.Context())
+	require.NoError(t, err)
+	assert.Nil(t, rotation)
+	assert.Equal(t, 1, kv.writes)
+
+	// после периода ключ заменяется, а k1 с закончившимся Grace удаляется
+	now = start.Add(24 * time.Hour)
+
+	second, err := keys.Rotate(t.Context())
+	require.NoError(t, err)
+	require.NotNil(t, second)
+	assert.Equal(t, first.Kid, second.Previous)
+	assert.Equal(t, []string{"k1"}, second.Removed)
+
+	ids, err = keys.KeyIDs(t.Context())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"manual", first.Kid, second.Kid}, ids)
+
+	_, err = keys.Key(t.Context(), "k1")
+	require.ErrorIs(t, err, ErrUnknownKey)
+
+	assert.InDelta(t, 2, testutil.ToFloat64(keys.rotations.WithLabelValues(rotationOK)), 0)
+	assert.InDelta(t, float64(now.Unix()), testutil.ToFloat64(keys.rotatedAt), 0)
+}
+
+func TestVaultKeys_Rotate_Error(t *testing.T) {
+	t.Parallel()
+
+	kv := &fakeKV{data: map[string]interface{}{"current": "k1", "k1": "secret-1"}, writeErr: errors.New("permission denied")}
+	keys := newRotatingKeys(t, kv, KeyRotation{Period: time.Hour})
+
+	_, err := keys.Rotate(t.Context())
+	require.Error(t, err)
+
+	// при ошибке записи действует прежний ключ
+	kid, _, err := keys.SigningKey(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "k1", kid)
+
+	assert.InDelta(t, 1, testutil.ToFloat64(keys.rotations.WithLabelValues(rotationError)), 0)
+
+	disabled, err := NewVaultKeys(WithKVReader(kv))
+	require.NoError(t, err)
+
+	_, err = disabled.Rotate(t.Context())
+	require.Error(t, err)
+}
+
+func TestVaultKeys_Rotate_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	kv := &fakeKV{data: map[string]interface{}{"current": "k1", "k1": "secret-1"}, version: 1}
+
+	// оба экземпляра читают секрет до того, как кто-то из них запишет новый ключ
+	var read sync.WaitGroup
+
+	read.Add(2)
+
+	kv.afterRead = func() {
+		read.Done()
+		read.Wait()
+	}
+
+	instances := []*VaultKeys{
+		newRotatingKeys(t, kv, KeyRotation{Period: time.Hour}),
+		newRotatingKeys(t, kv, KeyRotation{Period: time.Hour}),
+	}
+
+	results := make([]*Rotation, len(instances))
+
+	var wg sync.WaitGroup
+
+	for i, keys := range instances {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			var err error
+
+			results[i], err = keys.Rotate(t.Context())
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	// ротацию выполнил один экземпляр, второй перечитал его ключ
+	assert.Equal(t, 1, kv.writes)
+	assert.Equal(t, 2, kv.version)
+
+	var rotated []*Rotation
+
+	for _, r := range results {
+		if r != nil {
+			rotated = append(rotated, r)
+		}
+	}
+
+	require.Len(t, rotated, 1)
+
+	conflicts := 0.0
+
+	for _, keys := range instances {
+		kid, _, err := keys.SigningKey(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, rotated[0].Kid, kid)
+
+		conflicts += testutil.ToFloat64(keys.rotations.WithLabelValues(rotationConflict))
+	}
+
+	assert.InDelta(t, 1, conflicts, 0)
+}
@@ -0,0 +1,44 @@
+package token
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+const (
+	// EnvSandbox - значение claim env для токенов песочницы.
+	EnvSandbox = "sandbox"
+	// MaxSandboxTTL - максимальное время жизни данных песочницы.
+	MaxSandboxTTL = 24 * time.Hour
+)
+
+// Sandbox - песочница для разработчиков партнеров: токены аудиторий Audiences
+// помечаются claim env=sandbox и живут не дольше TTL.
+type Sandbox struct {
+	Audiences []string
+	TTL       time.Duration
+}
+
+func (s Sandbox) validate() error {
+	if len(s.Audiences) == 0 {
+		return nil
+	}
+
+	if s.TTL <= 0 || s.TTL > MaxSandboxTTL {
+		return errors.New("sandbox ttl must be in (0, 24h]")
+	}
+
+	return nil
+}
+
+// matches возвращает true, если одна из аудиторий токена относится к песочнице.
+func (s Sandbox) matches(audience []string) bool {
+	for _, aud := range audience {
+		if slices.Contains(s.Audiences, aud) {
+			return true
+		}
+	}
+
+	return false
+}
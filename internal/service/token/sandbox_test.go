@@ -0,0 +1,84 @@
+package token
+
+import (
+	"auth-service/internal/service/token/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSandbox_Validate(t *testing.T) {
+	t.Parallel()
+
+	keys := mocks.NewMocksigningKeyProvider(gomock.NewController(t))
+
+	_, err := NewIssuer(WithSigningKeys(keys), WithSandbox(Sandbox{Audiences: []string{"partner-sandbox"}}))
+	require.ErrorContains(t, err, "sandbox ttl must be in")
+
+	_, err = NewIssuer(WithSigningKeys(keys), WithSandbox(Sandbox{Audiences: []string{"partner-sandbox"}, TTL: 48 * time.Hour}))
+	require.ErrorContains(t, err, "sandbox ttl must be in")
+
+	_, err = NewIssuer(WithSigningKeys(keys), WithSandbox(Sandbox{Audiences: []string{"partner-sandbox"}, TTL: time.Hour}))
+	require.NoError(t, err)
+}
+
+func TestIssuer_Issue_Sandbox(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+	now := time.Now().Truncate(time.Second)
+
+	keys := mocks.NewMocksigningKeyProvider(gomock.NewController(t))
+	keys.EXPECT().SigningKey(gomock.Any()).Return("key-1", key, nil).AnyTimes()
+
+	issuer, err := NewIssuer(WithSigningKeys(keys), WithSandbox(Sandbox{Audiences: []string{"partner-sandbox"}, TTL: time.Hour}))
+	require.NoError(t, err)
+
+	issuer.now = func() time.Time { return now }
+
+	validator, err := NewValidator(WithKeys(staticKeys{"key-1": key}))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		req     IssueRequest
+		wantEnv string
+		wantExp time.Time
+	}{
+		{
+			name:    "sandbox audience, ttl is capped",
+			req:     IssueRequest{Subject: "dev-1", Audience: []string{"partner-sandbox"}, TTL: 30 * 24 * time.Hour},
+			wantEnv: EnvSandbox,
+			wantExp: now.Add(time.Hour),
+		},
+		{
+			name:    "sandbox audience, short ttl",
+			req:     IssueRequest{Subject: "dev-1", Audience: []string{"web", "partner-sandbox"}, TTL: time.Minute},
+			wantEnv: EnvSandbox,
+			wantExp: now.Add(time.Minute),
+		},
+		{
+			name:    "production audience",
+			req:     IssueRequest{Subject: "user-1", Audience: []string{"web"}, TTL: 48 * time.Hour},
+			wantExp: now.Add(48 * time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			raw, claims, err := issuer.Issue(t.Context(), tt.req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantEnv, claims.Env)
+			assert.Equal(t, tt.wantExp, claims.ExpiresAt)
+
+			got, err := validator.Validate(t.Context(), raw)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantEnv, got.Env)
+		})
+	}
+}
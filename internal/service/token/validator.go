@@ -0,0 +1,633 @@
+// Package token проверяет JWT токены, подписанные ключами сервиса.
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"auth-service/internal/service/keystats"
+	"auth-service/pkg/authclient"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// signingMethod - алгоритм подписи токенов сервиса.
+var signingMethod = jwt.SigningMethodHS256
+
+// SVIDType - заголовок typ JWT-SVID. SVID подписываются отдельным асимметричным ключом и предназначены
+// сервисам, поэтому Validator отклоняет их, даже если подпись совпала бы с ключом токенов пользователей.
+const SVIDType = "JWT-SVID"
+
+// userTokenType - заголовок typ токенов пользователей. Validator принимает только его или токены без typ:
+// другие документы, подписанные ключами сервиса (например, пакет проверки authclient.BundleType),
+// токенами пользователей не являются.
+const userTokenType = "JWT"
+
+// ErrInvalidToken - токен не прошел проверку.
+var ErrInvalidToken = errors.New("invalid token")
+
+// ErrRevoked - токен отозван: по jti или вместе со всеми токенами субъекта после его выпуска.
+var ErrRevoked = errors.New("token is revoked")
+
+// ErrSessionRevoked - сессия входа, к которой относится токен (claim sid), завершена или истекла по неактивности.
+var ErrSessionRevoked = errors.New("token session is revoked")
+
+// ErrUnexpectedIssuer - токен выпущен другим сервисом (claim iss не совпадает с внешним адресом).
+var ErrUnexpectedIssuer = errors.New("unexpected token issuer")
+
+// ErrUnexpectedAudience - токен выпущен для другой аудитории (claim aud не содержит ожидаемых).
+var ErrUnexpectedAudience = errors.New("unexpected token audience")
+
+// ErrGraceReadOnly - истекший токен, принятый в режиме мягкой проверки, предъявлен для изменяющего запроса.
+var ErrGraceReadOnly = errors.New("token accepted in grace mode is read-only")
+
+// revocationChecker - источник отметок об отзыве токенов пользователя и черный список токенов.
+type revocationChecker interface {
+	RevokedBefore(ctx context.Context, subject string) (time.Time, error)
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// sessionChecker - источник отметок о завершении сессий входа.
+type sessionChecker interface {
+	SessionRevoked(ctx context.Context, sid string) (bool, error)
+}
+
+// sessionToucher - отметка активности сессий входа со скользящим истечением.
+type sessionToucher interface {
+	TouchSession(ctx context.Context, sid string) (bool, error)
+}
+
+// SessionCheck - проверка сессии входа: токены аудиторий Audiences отклоняются, если их сессия
+// (claim sid) завершена, даже до истечения. Каждая проверка - запрос к хранилищу сессий, поэтому
+// включается только для аудиторий, которым нужен немедленный выход.
+type SessionCheck struct {
+	Audiences []string
+}
+
+// keyProvider - источник ключей подписи.
+type keyProvider interface {
+	Key(ctx context.Context, kid string) ([]byte, error)
+}
+
+// Grace - режим мягкой проверки: токены аудиторий Audiences принимаются,
+// если истекли не более чем Period назад. Нужен ботам на long polling,
+// которые иногда присылают токен чуть позже его истечения.
+type Grace struct {
+	Period    time.Duration
+	Audiences []string
+}
+
+// CheckGrace проверяет, что токен, принятый в режиме мягкой проверки (Claims.Grace), используется
+// только для чтения: запоздавший токен бота может получить данные, но не изменить состояние.
+// method - HTTP метод запроса.
+func CheckGrace(claims *Claims, method string) error {
+	if !claims.Grace || method == http.MethodGet || method == http.MethodHead {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %w", ErrInvalidToken, ErrGraceReadOnly)
+}
+
+// Actor - claim act (RFC 8693): кто действует от имени субъекта токена.
+type Actor struct {
+	Subject string `json:"sub"`
+}
+
+// principalClaims - claims пользователя с фиксированными типами (authclient.Claims). Сервис заполняет
+// sid и tg_id, остальные передаются как пользовательские claims и проверяются по типу при выпуске.
+type principalClaims struct {
+	TelegramID int64    `json:"tg_id,omitempty"`
+	Roles      []string `json:"roles,omitempty"`
+	SessionID  string   `json:"sid,omitempty"`
+	AuthLevel  string   `json:"acr,omitempty"`
+	Tenant     string   `json:"tenant,omitempty"`
+}
+
+// jwtClaims - claims токенов сервиса.
+type jwtClaims struct {
+	jwt.RegisteredClaims
+	principalClaims
+
+	Scope  string            `json:"scope,omitempty"`
+	Act    *Actor            `json:"act,omitempty"`
+	Groups map[string]string `json:"groups,omitempty"`
+	Cnf    *Confirmation     `json:"cnf,omitempty"`
+	Env    string            `json:"env,omitempty"`
+
+	// Custom - пользовательские claims верхнего уровня, добавляются при выпуске.
+	Custom map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON добавляет пользовательские claims к claims сервиса.
+func (c jwtClaims) MarshalJSON() ([]byte, error) {
+	type plain jwtClaims
+
+	data, err := json.Marshal(plain(c))
+	if err != nil || len(c.Custom) == 0 {
+		return data, err
+	}
+
+	merged := make(map[string]json.RawMessage, len(c.Custom))
+
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return nil, err
+	}
+
+	for name, value := range c.Custom {
+		// claims сервиса не переопределяются, даже если проверка имен отключена
+		if _, ok := merged[name]; ok {
+			continue
+		}
+
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		merged[name] = raw
+	}
+
+	return json.Marshal(merged)
+}
+
+// Claims - результат проверки токена.
+type Claims struct {
+	ID       string
+	Kid      string
+	Issuer   string
+	Subject  string
+	Audience []string
+	Scopes   []string
+	Actor    *Actor
+	// Groups - группы субъекта и его роли в них.
+	Groups    map[string]string
+	ExpiresAt time.Time
+	IssuedAt  time.Time
+	// CertThumbprint - отпечаток клиентского сертификата, к которому привязан токен (cnf.x5t#S256).
+	CertThumbprint string
+	// Env - окружение токена: sandbox для токенов песочницы, пусто для рабочих.
+	Env string
+	// Grace - токен истек, но принят в режиме мягкой проверки.
+	Grace bool
+
+	// claims пользователя, см. authclient.Claims
+	TelegramID int64
+	Roles      []string
+	SessionID  string
+	AuthLevel  string
+	Tenant     string
+}
+
+// Principal возвращает claims пользователя в виде, который используют обработчики и SDK.
+func (c *Claims) Principal() *authclient.Claims {
+	return &authclient.Claims{
+		UserID:     c.Subject,
+		TelegramID: c.TelegramID,
+		Roles:      c.Roles,
+		Scopes:     c.Scopes,
+		SessionID:  c.SessionID,
+		AuthLevel:  c.AuthLevel,
+		Tenant:     c.Tenant,
+	}
+}
+
+// Validator - проверяет подпись и срок действия токенов.
+type Validator struct {
+	keys     keyProvider
+	grace    Grace
+	keyStats *keystats.Tracker
+
+	// отзыв токенов по jti и всех токенов пользователя, nil - не проверяется
+	revocations revocationChecker
+
+	// завершение сессий входа для аудиторий sessionCheck, nil - не проверяется
+	sessions     sessionChecker
+	sessionCheck SessionCheck
+	// активность сессий входа аудиторий activityAudiences, nil - не отмечается
+	activity          sessionToucher
+	activityAudiences []string
+
+	// ожидаемый claim iss, пусто - не проверяется
+	issuer string
+	// ожидаемые аудитории, пусто - не проверяются
+	audiences []string
+
+	// объединение параллельных проверок одного токена, nil - выключено
+	coalescing         *coalescing
+	coalesceRegisterer prometheus.Registerer
+
+	// парсер и проверки сроков создаются один раз, чтобы не выделять память на каждый запрос
+	parser      *jwt.Parser
+	expiry      *jwt.Validator
+	expiryGrace *jwt.Validator
+
+	now func() time.Time
+}
+
+// claimsPool - переиспользуемые структуры для разбора claims токена.
+var claimsPool = sync.Pool{
+	New: func() any { return new(jwtClaims) },
+}
+
+// ValidatorOption - опция для настройки Validator.
+type ValidatorOption func(*Validator)
+
+// WithKeys устанавливает источник ключей подписи.
+func WithKeys(keys keyProvider) ValidatorOption {
+	return func(v *Validator) {
+		v.keys = keys
+	}
+}
+
+// WithGrace включает мягкую проверку истекших токенов для указанных аудиторий.
+func WithGrace(grace Grace) ValidatorOption {
+	return func(v *Validator) {
+		v.grace = grace
+	}
+}
+
+// WithKeyStats устанавливает счетчик использования ключей: каждая успешная проверка учитывается по kid.
+func WithKeyStats(tracker *keystats.Tracker) ValidatorOption {
+	return func(v *Validator) {
+		v.keyStats = tracker
+	}
+}
+
+// WithRevocations включает проверку отзыва: токен отклоняется, если его jti в черном списке
+// или он выпущен не позже отметки об отзыве его субъекта.
+func WithRevocations(revocations revocationChecker) ValidatorOption {
+	return func(v *Validator) {
+		v.revocations = revocations
+	}
+}
+
+// WithSessionCheck включает проверку сессий входа для аудиторий check.Audiences.
+func WithSessionCheck(sessions sessionChecker, check SessionCheck) ValidatorOption {
+	return func(v *Validator) {
+		v.sessions = sessions
+		v.sessionCheck = check
+	}
+}
+
+// WithSessionActivity включает отметку активности сессий входа со скользящим истечением: каждая
+// принятая проверка токена аудиторий audiences продлевает его сессию (claim sid). Токен сессии,
+// истекшей по неактивности или завершенной, отклоняется с ErrSessionRevoked - для этих аудиторий
+// отдельная проверка сессии (WithSessionCheck) не нужна.
+func WithSessionActivity(sessions sessionToucher, audiences []string) ValidatorOption {
+	return func(v *Validator) {
+		v.activity = sessions
+		v.activityAudiences = audiences
+	}
+}
+
+// WithExpectedIssuer включает проверку claim iss: токен с другим iss отклоняется.
+// Токены без iss, выпущенные до настройки внешнего адреса, принимаются.
+func WithExpectedIssuer(issuer string) ValidatorOption {
+	return func(v *Validator) {
+		v.issuer = issuer
+	}
+}
+
+// WithExpectedAudience включает проверку claim aud: токен принимается, только если среди его аудиторий
+// есть одна из audiences. Токен без aud отклоняется.
+func WithExpectedAudience(audiences ...string) ValidatorOption {
+	return func(v *Validator) {
+		v.audiences = audiences
+	}
+}
+
+// NewValidator создает новый Validator.
+func NewValidator(opts ...ValidatorOption) (*Validator, error) {
+	v := &Validator{
+		now: time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	if v.keys == nil {
+		return nil, errors.New("keys are required")
+	}
+
+	if v.grace.Period < 0 {
+		return nil, errors.New("grace period must not be negative")
+	}
+
+	if v.grace.Period > 0 && len(v.grace.Audiences) == 0 {
+		return nil, errors.New("grace audiences are required")
+	}
+
+	if v.sessions != nil && len(v.sessionCheck.Audiences) == 0 {
+		return nil, errors.New("session check audiences are required")
+	}
+
+	if v.activity != nil && len(v.activityAudiences) == 0 {
+		return nil, errors.New("session activity audiences are required")
+	}
+
+	if slices.Contains(v.audiences, "") {
+		return nil, errors.New("expected audience must not be empty")
+	}
+
+	// время берется через замыкание, чтобы тесты могли подменить now после создания
+	now := func() time.Time { return v.now() }
+
+	v.parser = jwt.NewParser(
+		jwt.WithValidMethods([]string{signingMethod.Alg()}),
+		jwt.WithoutClaimsValidation(),
+	)
+	v.expiry = jwt.NewValidator(jwt.WithExpirationRequired(), jwt.WithTimeFunc(now))
+	v.expiryGrace = jwt.NewValidator(
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(now),
+		jwt.WithLeeway(v.grace.Period),
+	)
+
+	if v.coalesceRegisterer != nil {
+		c, err := newCoalescing(v.coalesceRegisterer)
+		if err != nil {
+			return nil, err
+		}
+
+		v.coalescing = c
+	}
+
+	return v, nil
+}
+
+// ForAudience возвращает Validator с теми же ключами и проверками, который дополнительно принимает
+// только токены аудиторий audiences (см. WithExpectedAudience). Нужен, чтобы группы маршрутов
+// принимали только свои токены. Объединение проверок и счетчики общие с v.
+func (v *Validator) ForAudience(audiences ...string) *Validator {
+	c := *v
+	c.audiences = audiences
+
+	return &c
+}
+
+// Validate проверяет токен и возвращает его claims.
+// Все ошибки проверки оборачивают ErrInvalidToken, кроме ошибок получения ключа из Vault и отметок об отзыве из Redis.
+// Если включено объединение проверок, параллельные проверки одного токена выполняются один раз.
+func (v *Validator) Validate(ctx context.Context, raw string) (*Claims, error) {
+	var (
+		claims *Claims
+		err    error
+	)
+
+	if v.coalescing == nil {
+		claims, err = v.validate(ctx, raw)
+	} else {
+		claims, err = v.coalescing.validate(ctx, raw, v.validate)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// аудитория проверяется после объединения: Validator из ForAudience делят его с исходным
+	// и могут получить результат проверки, начатой без ожидаемой аудитории
+	if err := v.checkAudience(claims.Audience); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	return claims, nil
+}
+
+// checkAudience проверяет, что токен выпущен для одной из ожидаемых аудиторий.
+func (v *Validator) checkAudience(audience []string) error {
+	if len(v.audiences) == 0 {
+		return nil
+	}
+
+	for _, aud := range audience {
+		if slices.Contains(v.audiences, aud) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnexpectedAudience, audience)
+}
+
+// validate проверяет токен.
+func (v *Validator) validate(ctx context.Context, raw string) (*Claims, error) {
+	claims, _ := claimsPool.Get().(*jwtClaims)
+
+	defer func() {
+		*claims = jwtClaims{}
+		claimsPool.Put(claims)
+	}()
+
+	var (
+		kid    string
+		keyErr error
+	)
+
+	_, err := v.parser.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		if typ, _ := t.Header["typ"].(string); typ != "" && !strings.EqualFold(typ, userTokenType) {
+			return nil, fmt.Errorf("typ %q is not a user token", typ)
+		}
+
+		kid, _ = t.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("kid is required")
+		}
+
+		key, err := v.keys.Key(ctx, kid)
+		if err != nil && !errors.Is(err, ErrUnknownKey) {
+			keyErr = err
+		}
+
+		return key, err
+	})
+	if keyErr != nil {
+		return nil, keyErr
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	grace, err := v.validateClaims(&claims.RegisteredClaims)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	if err := v.checkRevoked(ctx, &claims.RegisteredClaims); err != nil {
+		return nil, err
+	}
+
+	if err := v.checkSession(ctx, claims); err != nil {
+		return nil, err
+	}
+
+	if v.keyStats != nil {
+		v.keyStats.Verified(kid)
+	}
+
+	res := newClaims(kid, claims)
+	res.Grace = grace
+
+	return res, nil
+}
+
+// newClaims преобразует claims JWT в результат проверки.
+func newClaims(kid string, claims *jwtClaims) *Claims {
+	res := &Claims{
+		ID:       claims.ID,
+		Kid:      kid,
+		Issuer:   claims.Issuer,
+		Subject:  claims.Subject,
+		Audience: claims.Audience,
+		Scopes:   strings.Fields(claims.Scope),
+		Actor:    claims.Act,
+		Groups:   claims.Groups,
+		Env:      claims.Env,
+
+		TelegramID: claims.TelegramID,
+		Roles:      claims.Roles,
+		SessionID:  claims.SessionID,
+		AuthLevel:  claims.AuthLevel,
+		Tenant:     claims.Tenant,
+	}
+
+	if claims.ExpiresAt != nil {
+		res.ExpiresAt = claims.ExpiresAt.Time
+	}
+
+	if claims.IssuedAt != nil {
+		res.IssuedAt = claims.IssuedAt.Time
+	}
+
+	if claims.Cnf != nil {
+		res.CertThumbprint = claims.Cnf.X5tS256
+	}
+
+	return res
+}
+
+// validateClaims проверяет субъект, издателя и сроки действия токена. Возвращает true, если истекший токен
+// принят в режиме мягкой проверки.
+func (v *Validator) validateClaims(claims *jwt.RegisteredClaims) (bool, error) {
+	// токен без субъекта нельзя ни отозвать, ни связать с пользователем
+	if claims.Subject == "" {
+		return false, errors.New("sub is required")
+	}
+
+	if v.issuer != "" && claims.Issuer != "" && claims.Issuer != v.issuer {
+		return false, fmt.Errorf("%w: %q", ErrUnexpectedIssuer, claims.Issuer)
+	}
+
+	err := v.expiry.Validate(claims)
+	if err == nil {
+		return false, nil
+	}
+
+	if !errors.Is(err, jwt.ErrTokenExpired) || !v.graceAllowed(claims.Audience) {
+		return false, err
+	}
+
+	err = v.expiryGrace.Validate(claims)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// checkRevoked проверяет, не отозван ли токен по jti и не отозваны ли токены субъекта после его выпуска.
+// Ошибка хранилища отзывов не оборачивает ErrInvalidToken: токен нельзя ни принять, ни отклонить.
+func (v *Validator) checkRevoked(ctx context.Context, claims *jwt.RegisteredClaims) error {
+	if v.revocations == nil {
+		return nil
+	}
+
+	revoked, err := v.revocations.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return err
+	}
+
+	if revoked {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, ErrRevoked)
+	}
+
+	revokedBefore, err := v.revocations.RevokedBefore(ctx, claims.Subject)
+	if err != nil {
+		return err
+	}
+
+	if revokedBefore.IsZero() {
+		return nil
+	}
+
+	// токен без iat считается выпущенным до отзыва
+	if claims.IssuedAt == nil || !claims.IssuedAt.After(revokedBefore) {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, ErrRevoked)
+	}
+
+	return nil
+}
+
+// checkSession проверяет, не завершена ли сессия входа токена, если его аудитория этого требует,
+// и отмечает активность скользящей сессии. Токены без sid, выпущенные до включения claim, принимаются.
+// Ошибка хранилища сессий не оборачивает ErrInvalidToken: токен нельзя ни принять, ни отклонить.
+func (v *Validator) checkSession(ctx context.Context, claims *jwtClaims) error {
+	if claims.SessionID == "" {
+		return nil
+	}
+
+	var (
+		revoked bool
+		err     error
+	)
+
+	switch {
+	case v.activity != nil && matchAudience(v.activityAudiences, claims.Audience):
+		var active bool
+
+		active, err = v.activity.TouchSession(ctx, claims.SessionID)
+		revoked = !active
+	case v.sessions != nil && matchAudience(v.sessionCheck.Audiences, claims.Audience):
+		revoked, err = v.sessions.SessionRevoked(ctx, claims.SessionID)
+	default:
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if revoked {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, ErrSessionRevoked)
+	}
+
+	return nil
+}
+
+// graceAllowed возвращает true, если для одной из аудиторий токена разрешена мягкая проверка.
+func (v *Validator) graceAllowed(audience []string) bool {
+	if v.grace.Period == 0 {
+		return false
+	}
+
+	return matchAudience(v.grace.Audiences, audience)
+}
+
+// matchAudience возвращает true, если одна из аудиторий токена есть в audiences.
+func matchAudience(audiences, audience []string) bool {
+	for _, aud := range audience {
+		if slices.Contains(audiences, aud) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,862 @@
+package token
+
+import (
+	"auth-service/internal/service/keystats"
+	"auth-service/internal/service/token/mocks"
+	"auth-service/pkg/authclient"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticKeys - источник ключей для тестов.
+type staticKeys map[string][]byte
+
+func (k staticKeys) Key(_ context.Context, kid string) ([]byte, error) {
+	if kid == "broken" {
+		return nil, errors.New("vault is sealed")
+	}
+
+	key, ok := k[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, kid)
+	}
+
+	return key, nil
+}
+
+func sign(t *testing.T, kid string, key []byte, claims jwt.RegisteredClaims) string {
+	t.Helper()
+
+	tok := jwt.NewWithClaims(signingMethod, claims)
+	if kid != "" {
+		tok.Header["kid"] = kid
+	}
+
+	raw, err := tok.SignedString(key)
+	require.NoError(t, err)
+
+	return raw
+}
+
+func TestNewValidator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []ValidatorOption
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			opts:    []ValidatorOption{WithKeys(staticKeys{})},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: with grace",
+			opts: []ValidatorOption{
+				WithKeys(staticKeys{}),
+				WithGrace(Grace{Period: time.Minute, Audiences: []string{"bot"}}),
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: keys are nil",
+			wantErr: require.Error,
+		},
+		{
+			name: "error case: grace without audiences",
+			opts: []ValidatorOption{
+				WithKeys(staticKeys{}),
+				WithGrace(Grace{Period: time.Minute}),
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "error case: session check without audiences",
+			opts: []ValidatorOption{
+				WithKeys(staticKeys{}),
+				WithSessionCheck(staticSessions{}, SessionCheck{}),
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "error case: session activity without audiences",
+			opts: []ValidatorOption{
+				WithKeys(staticKeys{}),
+				WithSessionActivity(staticSessions{}, nil),
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "error case: empty expected audience",
+			opts: []ValidatorOption{
+				WithKeys(staticKeys{}),
+				WithExpectedAudience("admin", ""),
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "error case: negative grace period",
+			opts: []ValidatorOption{
+				WithKeys(staticKeys{}),
+				WithGrace(Grace{Period: -time.Minute, Audiences: []string{"bot"}}),
+			},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewValidator(tt.opts...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestValidator_Validate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	key := []byte("secret")
+	keys := staticKeys{"key-1": key}
+
+	claims := func(aud string, exp time.Time) jwt.RegisteredClaims {
+		return jwt.RegisteredClaims{
+			Subject:   "user-1",
+			Audience:  jwt.ClaimStrings{aud},
+			ExpiresAt: jwt.NewNumericDate(exp),
+			IssuedAt:  jwt.NewNumericDate(exp.Add(-time.Hour)),
+		}
+	}
+
+	grace := Grace{Period: 2 * time.Minute, Audiences: []string{"bot"}}
+
+	tests := []struct {
+		name      string
+		raw       func(t *testing.T) string
+		grace     Grace
+		wantGrace bool
+		wantErr   require.ErrorAssertionFunc
+	}{
+		{
+			name: "positive case",
+			raw: func(t *testing.T) string {
+				t.Helper()
+
+				return sign(t, "key-1", key, claims("web", now.Add(time.Minute)))
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: expired token accepted in grace mode",
+			raw: func(t *testing.T) string {
+				t.Helper()
+
+				return sign(t, "key-1", key, claims("bot", now.Add(-time.Minute)))
+			},
+			grace:     grace,
+			wantGrace: true,
+			wantErr:   require.NoError,
+		},
+		{
+			name: "error case: jwt svid",
+			raw: func(t *testing.T) string {
+				t.Helper()
+
+				tok := jwt.NewWithClaims(signingMethod, claims("web", now.Add(time.Minute)))
+				tok.Header["kid"] = "key-1"
+				tok.Header["typ"] = SVIDType
+
+				raw, err := tok.SignedString(key)
+				require.NoError(t, err)
+
+				return raw
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrInvalidToken)
+				require.ErrorContains(t, err, "not a user token")
+			},
+		},
+		{
+			name: "error case: verification bundle",
+			raw: func(t *testing.T) string {
+				t.Helper()
+
+				tok := jwt.NewWithClaims(signingMethod, claims("web", now.Add(time.Minute)))
+				tok.Header["kid"] = "key-1"
+				tok.Header["typ"] = authclient.BundleType
+
+				raw, err := tok.SignedString(key)
+				require.NoError(t, err)
+
+				return raw
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrInvalidToken)
+				require.ErrorContains(t, err, "not a user token")
+			},
+		},
+		{
+			name: "error case: no subject",
+			raw: func(t *testing.T) string {
+				t.Helper()
+
+				c := claims("web", now.Add(time.Minute))
+				c.Subject = ""
+
+				return sign(t, "key-1", key, c)
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrInvalidToken)
+				require.ErrorContains(t, err, "sub is required")
+			},
+		},
+		{
+			name: "error case: expired beyond grace period",
+			raw: func(t *testing.T) string {
+				t.Helper()
+
+				return sign(t, "key-1", key, claims("bot", now.Add(-3*time.Minute)))
+			},
+			grace: grace,
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrInvalidToken)
+				require.ErrorIs(t, err, jwt.ErrTokenExpired)
+			},
+		},
+		{
+			name: "error case: expired token of audience without grace",
+			raw: func(t *testing.T) string {
+				t.Helper()
+
+				return sign(t, "key-1", key, claims("web", now.Add(-time.Minute)))
+			},
+			grace: grace,
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, jwt.ErrTokenExpired)
+			},
+		},
+		{
+			name: "error case: expired token, grace disabled",
+			raw: func(t *testing.T) string {
+				t.Helper()
+
+				return sign(t, "key-1", key, claims("bot", now.Add(-time.Minute)))
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, jwt.ErrTokenExpired)
+			},
+		},
+		{
+			name: "error case: no exp",
+			raw: func(t *testing.T) string {
+				t.Helper()
+
+				return sign(t, "key-1", key, jwt.RegisteredClaims{Subject: "user-1"})
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrInvalidToken)
+			},
+		},
+		{
+			name: "error case: wrong signature",
+			raw: func(t *testing.T) string {
+				t.Helper()
+
+				return sign(t, "key-1", []byte("other"), claims("web", now.Add(time.Minute)))
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrInvalidToken)
+			},
+		},
+		{
+			name: "error case: no kid",
+			raw: func(t *testing.T) string {
+				t.Helper()
+
+				return sign(t, "", key, claims("web", now.Add(time.Minute)))
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrInvalidToken)
+			},
+		},
+		{
+			name: "error case: unknown kid",
+			raw: func(t *testing.T) string {
+				t.Helper()
+
+				return sign(t, "key-2", key, claims("web", now.Add(time.Minute)))
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrInvalidToken)
+			},
+		},
+		{
+			name: "error case: key provider error",
+			raw: func(t *testing.T) string {
+				t.Helper()
+
+				return sign(t, "broken", key, claims("web", now.Add(time.Minute)))
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "vault is sealed")
+				require.NotErrorIs(t, err, ErrInvalidToken)
+			},
+		},
+		{
+			name: "error case: malformed",
+			raw: func(t *testing.T) string {
+				t.Helper()
+
+				return "not-a-token"
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrInvalidToken)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			v, err := NewValidator(WithKeys(keys), WithGrace(tt.grace))
+			require.NoError(t, err)
+
+			v.now = func() time.Time { return now }
+
+			got, err := v.Validate(t.Context(), tt.raw(t))
+			tt.wantErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, "key-1", got.Kid)
+			assert.Equal(t, "user-1", got.Subject)
+			assert.Equal(t, tt.wantGrace, got.Grace)
+		})
+	}
+}
+
+func TestValidator_Validate_KeyStats(t *testing.T) {
+	t.Parallel()
+
+	tracker, err := keystats.New(keystats.WithRegisterer(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	key := []byte("secret")
+
+	v, err := NewValidator(WithKeys(staticKeys{"key-1": key}), WithKeyStats(tracker))
+	require.NoError(t, err)
+
+	raw := sign(t, "key-1", key, jwt.RegisteredClaims{Subject: "user-1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))})
+
+	_, err = v.Validate(t.Context(), raw)
+	require.NoError(t, err)
+
+	usage := tracker.Usage()
+	require.Len(t, usage, 1)
+	assert.Equal(t, uint64(1), usage[0].Verified)
+}
+
+func TestValidator_Validate_Principal(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+
+	keys := mocks.NewMocksigningKeyProvider(gomock.NewController(t))
+	keys.EXPECT().SigningKey(gomock.Any()).Return("key-1", key, nil)
+
+	issuer, err := NewIssuer(WithSigningKeys(keys))
+	require.NoError(t, err)
+
+	raw, _, err := issuer.Issue(t.Context(), IssueRequest{
+		Subject:   "user-1",
+		TTL:       time.Minute,
+		Scopes:    []string{"read:notes"},
+		SessionID: "session-1",
+		Claims: map[string]interface{}{
+			"tg_id":  int64(12345),
+			"roles":  []string{"editor"},
+			"acr":    "mfa",
+			"tenant": "acme",
+		},
+	})
+	require.NoError(t, err)
+
+	v, err := NewValidator(WithKeys(staticKeys{"key-1": key}))
+	require.NoError(t, err)
+
+	claims, err := v.Validate(t.Context(), raw)
+	require.NoError(t, err)
+
+	assert.Equal(t, &authclient.Claims{
+		UserID:     "user-1",
+		TelegramID: 12345,
+		Roles:      []string{"editor"},
+		Scopes:     []string{"read:notes"},
+		SessionID:  "session-1",
+		AuthLevel:  "mfa",
+		Tenant:     "acme",
+	}, claims.Principal())
+}
+
+// maxValidateAllocs - бюджет выделений памяти на одну проверку токена.
+// Тест ниже падает, если изменение горячего пути проверки увеличивает количество выделений.
+const maxValidateAllocs = 45
+
+// benchToken возвращает подписанный токен, похожий на рабочие токены сервиса.
+func benchToken(tb testing.TB, key []byte) string {
+	tb.Helper()
+
+	tok := jwt.NewWithClaims(signingMethod, jwtClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "user",
+			Audience:  jwt.ClaimStrings{"telegram-bot"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        "jti",
+		},
+		Scope: "read:notes write:notes",
+	})
+	tok.Header["kid"] = "key-1"
+
+	raw, err := tok.SignedString(key)
+	require.NoError(tb, err)
+
+	return raw
+}
+
+func TestValidator_Validate_Allocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("race detector changes allocation count")
+	}
+
+	key := []byte("secret")
+
+	v, err := NewValidator(WithKeys(staticKeys{"key-1": key}))
+	require.NoError(t, err)
+
+	raw := benchToken(t, key)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, err := v.Validate(t.Context(), raw)
+		require.NoError(t, err)
+	})
+
+	assert.LessOrEqual(t, allocs, float64(maxValidateAllocs), "validate allocations regressed")
+}
+
+func BenchmarkValidator_Validate(b *testing.B) {
+	key := []byte("secret")
+
+	v, err := NewValidator(WithKeys(staticKeys{"key-1": key}))
+	require.NoError(b, err)
+
+	raw := benchToken(b, key)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if _, err := v.Validate(b.Context(), raw); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// staticRevocations - отметки об отзыве для тестов. Отозванные токены записаны как jti:<jti>.
+type staticRevocations map[string]time.Time
+
+func (r staticRevocations) IsRevoked(_ context.Context, jti string) (bool, error) {
+	if jti == "broken" {
+		return false, errors.New("redis is unavailable")
+	}
+
+	_, ok := r["jti:"+jti]
+
+	return ok, nil
+}
+
+func (r staticRevocations) RevokedBefore(_ context.Context, subject string) (time.Time, error) {
+	if subject == "broken" {
+		return time.Time{}, errors.New("redis is unavailable")
+	}
+
+	return r[subject], nil
+}
+
+func TestValidator_Validate_Revoked(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+	revokedAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	v, err := NewValidator(
+		WithKeys(staticKeys{"key-1": key}),
+		WithRevocations(staticRevocations{"user-1": revokedAt, "jti:token-1": time.Time{}}),
+	)
+	require.NoError(t, err)
+
+	token := func(subject string, issuedAt time.Time) string {
+		return sign(t, "key-1", key, jwt.RegisteredClaims{
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		})
+	}
+
+	tokenWithID := func(jti string) string {
+		return sign(t, "key-1", key, jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   "user-2",
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		})
+	}
+
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr func(t require.TestingT, err error)
+	}{
+		{
+			name:    "positive case: issued after revocation",
+			raw:     token("user-1", time.Now()),
+			wantErr: func(t require.TestingT, err error) { require.NoError(t, err) },
+		},
+		{
+			name:    "positive case: subject was not revoked",
+			raw:     token("user-2", revokedAt.Add(-time.Hour)),
+			wantErr: func(t require.TestingT, err error) { require.NoError(t, err) },
+		},
+		{
+			name:    "positive case: other token revoked",
+			raw:     tokenWithID("token-2"),
+			wantErr: func(t require.TestingT, err error) { require.NoError(t, err) },
+		},
+		{
+			name: "error case: token revoked by jti",
+			raw:  tokenWithID("token-1"),
+			wantErr: func(t require.TestingT, err error) {
+				require.ErrorIs(t, err, ErrInvalidToken)
+				require.ErrorIs(t, err, ErrRevoked)
+			},
+		},
+		{
+			name: "error case: token blacklist is unavailable",
+			raw:  tokenWithID("broken"),
+			wantErr: func(t require.TestingT, err error) {
+				require.Error(t, err)
+				require.NotErrorIs(t, err, ErrInvalidToken)
+			},
+		},
+		{
+			name: "error case: issued before revocation",
+			raw:  token("user-1", revokedAt.Add(-time.Hour)),
+			wantErr: func(t require.TestingT, err error) {
+				require.ErrorIs(t, err, ErrInvalidToken)
+				require.ErrorIs(t, err, ErrRevoked)
+			},
+		},
+		{
+			name: "error case: issued at revocation time",
+			raw:  token("user-1", revokedAt),
+			wantErr: func(t require.TestingT, err error) {
+				require.ErrorIs(t, err, ErrRevoked)
+			},
+		},
+		{
+			name: "error case: revocations are unavailable",
+			raw:  token("broken", time.Now()),
+			wantErr: func(t require.TestingT, err error) {
+				require.Error(t, err)
+				require.NotErrorIs(t, err, ErrInvalidToken)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := v.Validate(t.Context(), tt.raw)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+// staticSessions - завершенные сессии для тестов.
+type staticSessions map[string]bool
+
+func (s staticSessions) SessionRevoked(_ context.Context, sid string) (bool, error) {
+	if sid == "broken" {
+		return false, errors.New("redis is unavailable")
+	}
+
+	return s[sid], nil
+}
+
+func (s staticSessions) TouchSession(ctx context.Context, sid string) (bool, error) {
+	revoked, err := s.SessionRevoked(ctx, sid)
+
+	return !revoked, err
+}
+
+func TestValidator_Validate_Session(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+
+	v, err := NewValidator(
+		WithKeys(staticKeys{"key-1": key}),
+		WithSessionCheck(staticSessions{"session-1": true}, SessionCheck{Audiences: []string{"admin-panel"}}),
+	)
+	require.NoError(t, err)
+
+	token := func(audience, sid string) string {
+		tok := jwt.NewWithClaims(signingMethod, &jwtClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "user-1",
+				Audience:  jwt.ClaimStrings{audience},
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+			principalClaims: principalClaims{SessionID: sid},
+		})
+		tok.Header["kid"] = "key-1"
+
+		raw, err := tok.SignedString(key)
+		require.NoError(t, err)
+
+		return raw
+	}
+
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr func(t require.TestingT, err error)
+	}{
+		{
+			name:    "positive case: session is active",
+			raw:     token("admin-panel", "session-2"),
+			wantErr: func(t require.TestingT, err error) { require.NoError(t, err) },
+		},
+		{
+			name:    "positive case: audience without session check",
+			raw:     token("telegram-bot", "session-1"),
+			wantErr: func(t require.TestingT, err error) { require.NoError(t, err) },
+		},
+		{
+			name:    "positive case: token without sid",
+			raw:     token("admin-panel", ""),
+			wantErr: func(t require.TestingT, err error) { require.NoError(t, err) },
+		},
+		{
+			name: "error case: session is revoked",
+			raw:  token("admin-panel", "session-1"),
+			wantErr: func(t require.TestingT, err error) {
+				require.ErrorIs(t, err, ErrInvalidToken)
+				require.ErrorIs(t, err, ErrSessionRevoked)
+			},
+		},
+		{
+			name: "error case: sessions are unavailable",
+			raw:  token("admin-panel", "broken"),
+			wantErr: func(t require.TestingT, err error) {
+				require.Error(t, err)
+				require.NotErrorIs(t, err, ErrInvalidToken)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := v.Validate(t.Context(), tt.raw)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+func TestValidator_Validate_SessionActivity(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+
+	v, err := NewValidator(
+		WithKeys(staticKeys{"key-1": key}),
+		WithSessionActivity(staticSessions{"expired": true}, []string{"telegram-bot"}),
+	)
+	require.NoError(t, err)
+
+	token := func(audience, sid string) string {
+		tok := jwt.NewWithClaims(signingMethod, &jwtClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "user-1",
+				Audience:  jwt.ClaimStrings{audience},
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+			principalClaims: principalClaims{SessionID: sid},
+		})
+		tok.Header["kid"] = "key-1"
+
+		raw, err := tok.SignedString(key)
+		require.NoError(t, err)
+
+		return raw
+	}
+
+	_, err = v.Validate(t.Context(), token("telegram-bot", "active"))
+	require.NoError(t, err)
+
+	// сессии других аудиторий не отмечаются
+	_, err = v.Validate(t.Context(), token("web", "expired"))
+	require.NoError(t, err)
+
+	_, err = v.Validate(t.Context(), token("telegram-bot", "expired"))
+	require.ErrorIs(t, err, ErrInvalidToken)
+	require.ErrorIs(t, err, ErrSessionRevoked)
+
+	_, err = v.Validate(t.Context(), token("telegram-bot", "broken"))
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestValidator_Validate_Issuer(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+
+	ctrl := gomock.NewController(t)
+	keys := mocks.NewMocksigningKeyProvider(ctrl)
+	keys.EXPECT().SigningKey(gomock.Any()).Return("key-1", key, nil)
+
+	issuer, err := NewIssuer(WithSigningKeys(keys), WithIssuerURL("https://auth.zanuda.example"))
+	require.NoError(t, err)
+
+	raw, claims, err := issuer.Issue(t.Context(), IssueRequest{Subject: "user-1", TTL: time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, "https://auth.zanuda.example", claims.Issuer)
+
+	v, err := NewValidator(WithKeys(staticKeys{"key-1": key}), WithExpectedIssuer("https://auth.zanuda.example"))
+	require.NoError(t, err)
+
+	token := func(iss string) string {
+		return sign(t, "key-1", key, jwt.RegisteredClaims{
+			Issuer:    iss,
+			Subject:   "user-1",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		})
+	}
+
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr error
+	}{
+		{name: "positive case: issued by service", raw: raw},
+		{name: "positive case: issued before issuer was configured", raw: token("")},
+		{name: "error case: other issuer", raw: token("https://evil.example"), wantErr: ErrUnexpectedIssuer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := v.Validate(t.Context(), tt.raw)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, ErrInvalidToken)
+				require.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", got.Subject)
+		})
+	}
+}
+
+func TestValidator_Validate_Audience(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+
+	// объединение проверок общее: результат проверки без ожидаемой аудитории не должен
+	// попасть в проверку с ней
+	base, err := NewValidator(WithKeys(staticKeys{"key-1": key}), WithCoalescing(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	optioned, err := NewValidator(WithKeys(staticKeys{"key-1": key}), WithExpectedAudience("admin"))
+	require.NoError(t, err)
+
+	token := func(aud ...string) string {
+		return sign(t, "key-1", key, jwt.RegisteredClaims{
+			Subject:   "user-1",
+			Audience:  aud,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		})
+	}
+
+	tests := []struct {
+		name    string
+		v       *Validator
+		raw     string
+		wantErr error
+	}{
+		{name: "positive case: expected audience", v: optioned, raw: token("admin")},
+		{name: "positive case: one of audiences", v: base.ForAudience("web", "admin"), raw: token("dashboard", "admin")},
+		{name: "positive case: audience not checked", v: base, raw: token("dashboard")},
+		{name: "error case: other audience", v: optioned, raw: token("dashboard"), wantErr: ErrUnexpectedAudience},
+		{name: "error case: no audience", v: base.ForAudience("admin"), raw: token(), wantErr: ErrUnexpectedAudience},
+		{name: "error case: derived validator", v: base.ForAudience("admin"), raw: token("dashboard"), wantErr: ErrUnexpectedAudience},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.v.Validate(t.Context(), tt.raw)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, ErrInvalidToken)
+				require.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", got.Subject)
+		})
+	}
+
+	// исходный Validator не меняется
+	_, err = base.Validate(t.Context(), token("dashboard"))
+	require.NoError(t, err)
+}
+
+func TestCheckGrace(t *testing.T) {
+	t.Parallel()
+
+	grace := &Claims{Subject: "bot-1", Grace: true}
+
+	require.NoError(t, CheckGrace(grace, http.MethodGet))
+	require.NoError(t, CheckGrace(grace, http.MethodHead))
+	require.ErrorIs(t, CheckGrace(grace, http.MethodPost), ErrGraceReadOnly)
+	require.ErrorIs(t, CheckGrace(grace, http.MethodDelete), ErrInvalidToken)
+
+	require.NoError(t, CheckGrace(&Claims{Subject: "user-1"}, http.MethodPost))
+}
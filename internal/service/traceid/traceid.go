@@ -0,0 +1,84 @@
+// Package traceid извлекает идентификатор трассировки из заголовков входящего запроса (W3C Trace Context
+// и B3) и передает его через контекст. Идентификатор прикрепляется к наблюдениям гистограмм задержки
+// как exemplar: из панели Grafana можно перейти к трассировке медленного запроса.
+package traceid
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ExemplarLabel - метка exemplar с идентификатором трассировки. Имя ожидает Grafana по умолчанию.
+const ExemplarLabel = "trace_id"
+
+type contextKey struct{}
+
+// FromHeader возвращает идентификатор трассировки из заголовков traceparent, b3 или X-B3-TraceId.
+// Если заголовков нет или идентификатор некорректен, возвращает пустую строку.
+func FromHeader(h http.Header) string {
+	// traceparent: версия-trace_id-span_id-флаги
+	if parts := strings.Split(h.Get("Traceparent"), "-"); len(parts) >= 4 && valid(parts[1], 32) {
+		return parts[1]
+	}
+
+	// b3: trace_id-span_id[-sampled[-parent_span_id]]
+	if id, _, ok := strings.Cut(h.Get("B3"), "-"); ok && (valid(id, 16) || valid(id, 32)) {
+		return id
+	}
+
+	if id := strings.ToLower(h.Get("X-B3-Traceid")); valid(id, 16) || valid(id, 32) {
+		return id
+	}
+
+	return ""
+}
+
+// valid проверяет, что id - ненулевой идентификатор из size шестнадцатеричных символов в нижнем регистре.
+func valid(id string, size int) bool {
+	if len(id) != size || strings.Trim(id, "0") == "" {
+		return false
+	}
+
+	for _, r := range id {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewContext возвращает контекст с идентификатором трассировки.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext возвращает идентификатор трассировки из контекста или пустую строку.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+
+	return id
+}
+
+// Observe передает значение в observer. Если в контексте есть идентификатор трассировки,
+// он прикрепляется к наблюдению как exemplar.
+func Observe(ctx context.Context, observer prometheus.Observer, value float64) {
+	id := FromContext(ctx)
+	if id == "" {
+		observer.Observe(value)
+
+		return
+	}
+
+	exemplar, ok := observer.(prometheus.ExemplarObserver)
+	if !ok {
+		observer.Observe(value)
+
+		return
+	}
+
+	exemplar.ObserveWithExemplar(value, prometheus.Labels{ExemplarLabel: id})
+}
@@ -0,0 +1,101 @@
+package traceid
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromHeader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   string
+	}{
+		{
+			name:   "positive case: traceparent",
+			header: http.Header{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}},
+			want:   "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name:   "positive case: b3 single header",
+			header: http.Header{"B3": {"80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1"}},
+			want:   "80f198ee56343ba864fe8b2a57d3eff7",
+		},
+		{
+			name:   "positive case: b3 multi header with 64-bit id",
+			header: http.Header{"X-B3-Traceid": {"A3CE929D0E0E4736"}},
+			want:   "a3ce929d0e0e4736",
+		},
+		{
+			name: "positive case: traceparent takes precedence",
+			header: http.Header{
+				"Traceparent":  {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+				"X-B3-Traceid": {"a3ce929d0e0e4736"},
+			},
+			want: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name:   "negative case: no headers",
+			header: http.Header{},
+		},
+		{
+			name:   "negative case: zero trace id",
+			header: http.Header{"Traceparent": {"00-00000000000000000000000000000000-00f067aa0ba902b7-01"}},
+		},
+		{
+			name:   "negative case: invalid trace id",
+			header: http.Header{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01"}},
+		},
+		{
+			name:   "negative case: b3 sampling only",
+			header: http.Header{"B3": {"0"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, FromHeader(tt.header))
+		})
+	}
+}
+
+func TestObserve(t *testing.T) {
+	t.Parallel()
+
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_seconds", Buckets: []float64{1}})
+
+	Observe(t.Context(), histogram, 0.5)
+	assert.Nil(t, exemplar(t, histogram))
+
+	ctx := NewContext(t.Context(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", FromContext(ctx))
+
+	Observe(ctx, histogram, 0.7)
+
+	e := exemplar(t, histogram)
+	require.NotNil(t, e)
+	assert.InDelta(t, 0.7, e.GetValue(), 0)
+	require.Len(t, e.GetLabel(), 1)
+	assert.Equal(t, ExemplarLabel, e.GetLabel()[0].GetName())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", e.GetLabel()[0].GetValue())
+}
+
+// exemplar возвращает exemplar первого интервала гистограммы.
+func exemplar(t *testing.T, histogram prometheus.Histogram) *dto.Exemplar {
+	t.Helper()
+
+	var m dto.Metric
+
+	require.NoError(t, histogram.Write(&m))
+
+	return m.GetHistogram().GetBucket()[0].GetExemplar()
+}
@@ -0,0 +1,235 @@
+// Package tracing настраивает трассировку OpenTelemetry: провайдер спанов с выборкой и экспорт по OTLP
+// (gRPC или HTTP) в коллектор. Спаны создают сервер (входящие запросы), клиент Vault и хук Redis,
+// получая провайдер через опции. Контекст трассировки входящего запроса берется из заголовка
+// traceparent (W3C Trace Context), поэтому решение о выборке вызывающей стороны соблюдается.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Protocol - протокол экспорта OTLP.
+type Protocol string
+
+const (
+	ProtocolGRPC Protocol = "grpc" // OTLP/gRPC, порт коллектора 4317
+	ProtocolHTTP Protocol = "http" // OTLP/HTTP (protobuf), порт коллектора 4318
+)
+
+const (
+	// DefaultServiceName - имя сервиса в спанах по умолчанию.
+	DefaultServiceName = "auth-service"
+	// DefaultSampleRatio - доля трассировок, которые начинает сервис, по умолчанию: все.
+	DefaultSampleRatio = 1.0
+	// DefaultTimeout - таймаут отправки пачки спанов по умолчанию.
+	DefaultTimeout = 10 * time.Second
+
+	// tracerName - имя инструментирования в спанах сервиса.
+	tracerName = "auth-service"
+)
+
+// Propagator - формат контекста трассировки в заголовках: W3C Trace Context.
+var Propagator propagation.TextMapPropagator = propagation.TraceContext{}
+
+// Provider - провайдер спанов с экспортом по OTLP.
+type Provider struct {
+	protocol Protocol
+	endpoint string
+	insecure bool
+	headers  map[string]string
+	timeout  time.Duration
+
+	serviceName    string
+	serviceVersion string
+	sampleRatio    float64
+
+	// exporter - экспорт спанов; если не задан, создается экспорт OTLP по protocol и endpoint
+	exporter sdktrace.SpanExporter
+
+	provider *sdktrace.TracerProvider
+}
+
+// Option - опция для настройки Provider.
+type Option func(*Provider)
+
+// WithProtocol устанавливает протокол экспорта. По умолчанию ProtocolGRPC.
+func WithProtocol(protocol Protocol) Option {
+	return func(p *Provider) {
+		p.protocol = protocol
+	}
+}
+
+// WithEndpoint устанавливает адрес коллектора (host:port).
+func WithEndpoint(endpoint string) Option {
+	return func(p *Provider) {
+		p.endpoint = endpoint
+	}
+}
+
+// WithInsecure отключает TLS при отправке спанов в коллектор.
+func WithInsecure(insecure bool) Option {
+	return func(p *Provider) {
+		p.insecure = insecure
+	}
+}
+
+// WithHeaders устанавливает заголовки запросов к коллектору, например для авторизации.
+func WithHeaders(headers map[string]string) Option {
+	return func(p *Provider) {
+		p.headers = headers
+	}
+}
+
+// WithTimeout устанавливает таймаут отправки пачки спанов. По умолчанию DefaultTimeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(p *Provider) {
+		p.timeout = timeout
+	}
+}
+
+// WithServiceName устанавливает имя сервиса (service.name). По умолчанию DefaultServiceName.
+func WithServiceName(name string) Option {
+	return func(p *Provider) {
+		p.serviceName = name
+	}
+}
+
+// WithServiceVersion устанавливает версию сервиса (service.version).
+func WithServiceVersion(version string) Option {
+	return func(p *Provider) {
+		p.serviceVersion = version
+	}
+}
+
+// WithSampleRatio устанавливает долю трассировок, которые начинает сервис, от 0 до 1.
+// Для запросов с контекстом трассировки решение о выборке берется у вызывающей стороны.
+// По умолчанию DefaultSampleRatio.
+func WithSampleRatio(ratio float64) Option {
+	return func(p *Provider) {
+		p.sampleRatio = ratio
+	}
+}
+
+// WithExporter устанавливает экспорт спанов вместо OTLP, например для тестов.
+func WithExporter(exporter sdktrace.SpanExporter) Option {
+	return func(p *Provider) {
+		p.exporter = exporter
+	}
+}
+
+// New создает провайдер спанов. Соединение с коллектором устанавливается при первой отправке,
+// поэтому недоступный коллектор не мешает запуску: спаны, которые не удалось отправить, теряются.
+func New(opts ...Option) (*Provider, error) {
+	p := &Provider{
+		protocol:    ProtocolGRPC,
+		timeout:     DefaultTimeout,
+		serviceName: DefaultServiceName,
+		sampleRatio: DefaultSampleRatio,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if p.sampleRatio < 0 || p.sampleRatio > 1 {
+		return nil, fmt.Errorf("sample ratio must be between 0 and 1, got %v", p.sampleRatio)
+	}
+
+	if p.timeout <= 0 {
+		return nil, errors.New("timeout must be positive")
+	}
+
+	if p.serviceName == "" {
+		return nil, errors.New("service name is required")
+	}
+
+	if p.exporter == nil {
+		exporter, err := p.newExporter()
+		if err != nil {
+			return nil, err
+		}
+
+		p.exporter = exporter
+	}
+
+	attrs := []attribute.KeyValue{semconv.ServiceName(p.serviceName)}
+	if p.serviceVersion != "" {
+		attrs = append(attrs, semconv.ServiceVersion(p.serviceVersion))
+	}
+
+	p.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(p.exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attrs...)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(p.sampleRatio))),
+	)
+
+	return p, nil
+}
+
+// newExporter создает экспорт OTLP. Экспорт не подключается к коллектору до первой отправки.
+func (p *Provider) newExporter() (sdktrace.SpanExporter, error) {
+	if p.endpoint == "" {
+		return nil, errors.New("endpoint is required")
+	}
+
+	switch p.protocol {
+	case ProtocolGRPC:
+		opts := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(p.endpoint),
+			otlptracegrpc.WithHeaders(p.headers),
+			otlptracegrpc.WithTimeout(p.timeout),
+		}
+
+		if p.insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+
+		return otlptracegrpc.New(context.Background(), opts...)
+	case ProtocolHTTP:
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(p.endpoint),
+			otlptracehttp.WithHeaders(p.headers),
+			otlptracehttp.WithTimeout(p.timeout),
+		}
+
+		if p.insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+
+		return otlptracehttp.New(context.Background(), opts...)
+	default:
+		return nil, fmt.Errorf("unknown protocol %q", p.protocol)
+	}
+}
+
+// TracerProvider возвращает провайдер спанов для опций сервера, клиента Vault и хука Redis.
+func (p *Provider) TracerProvider() trace.TracerProvider {
+	return p.provider
+}
+
+// Shutdown отправляет накопленные спаны и останавливает экспорт. Спаны, созданные после
+// остановки, не отправляются.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if err := p.provider.Shutdown(ctx); err != nil {
+		return fmt.Errorf("tracing: error shutdown: %w", err)
+	}
+
+	return nil
+}
+
+// Tracer возвращает трассировщик сервиса из provider.
+func Tracer(provider trace.TracerProvider) trace.Tracer {
+	return provider.Tracer(tracerName)
+}
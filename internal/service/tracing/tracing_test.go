@@ -0,0 +1,111 @@
+package tracing
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case: grpc",
+			opts:    []Option{WithEndpoint("localhost:4317"), WithInsecure(true)},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: http",
+			opts:    []Option{WithProtocol(ProtocolHTTP), WithEndpoint("localhost:4318"), WithHeaders(map[string]string{"Authorization": "Bearer x"})},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: exporter",
+			opts:    []Option{WithExporter(tracetest.NewInMemoryExporter()), WithSampleRatio(0)},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: no endpoint",
+			opts:    []Option{},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: unknown protocol",
+			opts:    []Option{WithProtocol("udp"), WithEndpoint("localhost:4317")},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: sample ratio",
+			opts:    []Option{WithExporter(tracetest.NewInMemoryExporter()), WithSampleRatio(1.5)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: timeout",
+			opts:    []Option{WithExporter(tracetest.NewInMemoryExporter()), WithTimeout(0)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: service name",
+			opts:    []Option{WithExporter(tracetest.NewInMemoryExporter()), WithServiceName("")},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := New(tt.opts...)
+			tt.wantErr(t, err)
+
+			if p != nil {
+				require.NoError(t, p.Shutdown(t.Context()))
+			}
+		})
+	}
+}
+
+func TestProvider_Sampling(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracetest.NewInMemoryExporter()
+
+	p, err := New(WithExporter(exporter), WithSampleRatio(0), WithServiceVersion("1.0.0"))
+	require.NoError(t, err)
+
+	tracer := Tracer(p.TracerProvider())
+
+	// сервис сам трассировки не начинает
+	_, root := tracer.Start(t.Context(), "root")
+	root.End()
+
+	// но продолжает трассировку, которую вызывающая сторона выбрала для записи
+	headers := http.Header{}
+	headers.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	ctx := Propagator.Extract(t.Context(), propagation.HeaderCarrier(headers))
+
+	_, child := tracer.Start(ctx, "child")
+	child.End()
+
+	require.NoError(t, p.provider.ForceFlush(t.Context()))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+	assert.Contains(t, spans[0].Resource.Attributes(), semconv.ServiceName(DefaultServiceName))
+	assert.Contains(t, spans[0].Resource.Attributes(), semconv.ServiceVersion("1.0.0"))
+
+	require.NoError(t, p.Shutdown(t.Context()))
+}
@@ -0,0 +1,323 @@
+// Package ttlcheck проверяет, что TTL записей Redis согласован со сроком действия, записанным
+// в самих записях: черного списка токенов, сессий и refresh токенов. Владельцы записей выставляют
+// TTL как срок действия плюс запас (Slack), но TTL может разойтись со сроком: после ручных правок,
+// восстановления из резервной копии или ошибок старых версий сервиса. Слишком короткий TTL
+// опасен - запись исчезнет раньше срока, и, например, отозванный токен снова начнет приниматься;
+// слишком длинный - лишняя память. Расхождения учитываются в метриках и журнале, записи не меняются.
+package ttlcheck
+
+import (
+	"auth-service/internal/service/backup"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultSlack - запас TTL записи сверх срока ее действия по умолчанию: покрывает расхождение
+	// часов экземпляров сервиса и Redis.
+	DefaultSlack = time.Minute
+	// DefaultTolerance - допустимое расхождение TTL с ожидаемым по умолчанию. Больше DefaultSlack,
+	// чтобы записи, сохраненные без запаса предыдущими версиями сервиса, не считались расхождением.
+	DefaultTolerance = 5 * time.Minute
+	// DefaultInterval - периодичность проверки по умолчанию.
+	DefaultInterval = time.Hour
+)
+
+// Проблемы TTL записи.
+const (
+	// ProblemMissing - у записи нет TTL.
+	ProblemMissing = "missing"
+	// ProblemShort - запись истечет раньше срока действия.
+	ProblemShort = "short"
+	// ProblemLong - запись хранится дольше срока действия с запасом.
+	ProblemLong = "long"
+)
+
+// noExpire - значение TTL ключа без срока жизни.
+const noExpire = time.Duration(-1)
+
+// Record - вид записей со сроком действия внутри записи.
+type Record struct {
+	// Name - вид записи в метриках и журнале, например refresh_token.
+	Name string
+	// Pattern - шаблон ключей записей.
+	Pattern string
+	// Expiry возвращает, до какого времени запись нужна, без запаса Slack. false - срок в записи
+	// не найден (запись старой версии или удалена во время проверки), такая запись не проверяется.
+	Expiry func(ctx context.Context, key string) (time.Time, bool, error)
+}
+
+// Report - результат проверки.
+type Report struct {
+	// Scanned - сколько записей проверено.
+	Scanned int `json:"scanned"`
+	// Mismatched - записи с расходящимся TTL: вид записи -> проблема -> количество.
+	Mismatched map[string]map[string]int `json:"mismatched"`
+}
+
+// Checker - проверка согласованности TTL записей со сроком их действия.
+type Checker struct {
+	client    redis.UniversalClient
+	records   []Record
+	slack     time.Duration
+	tolerance time.Duration
+	interval  time.Duration
+
+	registerer prometheus.Registerer
+	mismatched *prometheus.GaugeVec
+	runs       *prometheus.CounterVec
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Checker.
+type Option func(*Checker)
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(c *Checker) {
+		c.client = client
+	}
+}
+
+// WithRecords добавляет проверяемые виды записей.
+func WithRecords(records ...Record) Option {
+	return func(c *Checker) {
+		c.records = append(c.records, records...)
+	}
+}
+
+// WithSlack устанавливает запас, с которым владельцы выставляют TTL записей. По умолчанию DefaultSlack.
+func WithSlack(slack time.Duration) Option {
+	return func(c *Checker) {
+		c.slack = slack
+	}
+}
+
+// WithTolerance устанавливает допустимое расхождение TTL с ожидаемым. По умолчанию DefaultTolerance.
+func WithTolerance(tolerance time.Duration) Option {
+	return func(c *Checker) {
+		c.tolerance = tolerance
+	}
+}
+
+// WithInterval устанавливает периодичность проверки. По умолчанию DefaultInterval.
+func WithInterval(interval time.Duration) Option {
+	return func(c *Checker) {
+		c.interval = interval
+	}
+}
+
+// WithRegisterer устанавливает реестр метрик. По умолчанию используется prometheus.DefaultRegisterer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(c *Checker) {
+		c.registerer = registerer
+	}
+}
+
+// New создает новый Checker и регистрирует его метрики.
+func New(opts ...Option) (*Checker, error) {
+	c := &Checker{
+		slack:      DefaultSlack,
+		tolerance:  DefaultTolerance,
+		interval:   DefaultInterval,
+		registerer: prometheus.DefaultRegisterer,
+		now:        time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.client == nil {
+		return nil, errors.New("redis client is required")
+	}
+
+	if len(c.records) == 0 {
+		return nil, errors.New("records are required")
+	}
+
+	for _, r := range c.records {
+		if r.Name == "" || r.Pattern == "" || r.Expiry == nil {
+			return nil, fmt.Errorf("record %q: name, pattern and expiry are required", r.Name)
+		}
+	}
+
+	if c.slack < 0 || c.tolerance < 0 {
+		return nil, errors.New("slack and tolerance must not be negative")
+	}
+
+	if c.interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+
+	if c.registerer == nil {
+		return nil, errors.New("registerer is required")
+	}
+
+	c.mismatched = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "auth_redis_ttl_mismatched_keys",
+		Help: "Количество записей, TTL которых расходится со сроком действия в записи, в последней проверке: " +
+			"missing - TTL нет, short - запись истечет раньше срока, long - хранится дольше срока с запасом.",
+	}, []string{"record", "problem"})
+
+	c.runs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_redis_ttl_checks_total",
+		Help: "Количество проверок TTL записей по результату.",
+	}, []string{"result"})
+
+	for _, collector := range []prometheus.Collector{c.mismatched, c.runs} {
+		if err := c.registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Start периодически проверяет записи до отмены контекста.
+func (c *Checker) Start(ctx context.Context) error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := c.Run(ctx); err != nil && ctx.Err() == nil {
+				logrus.WithError(err).Warn("error check redis ttl alignment")
+			}
+		}
+	}
+}
+
+// Run проверяет все виды записей и обновляет метрики.
+func (c *Checker) Run(ctx context.Context) (*Report, error) {
+	report, err := c.run(ctx)
+	if err != nil {
+		c.runs.WithLabelValues("error").Inc()
+
+		return nil, err
+	}
+
+	c.runs.WithLabelValues("ok").Inc()
+
+	log := logrus.WithFields(logrus.Fields{
+		"scanned":    report.Scanned,
+		"mismatched": report.Mismatched,
+	})
+
+	if len(report.Mismatched) > 0 {
+		log.Warn("redis records with misaligned ttl found")
+	} else {
+		log.Debug("redis record ttls are aligned")
+	}
+
+	return report, nil
+}
+
+func (c *Checker) run(ctx context.Context) (*Report, error) {
+	report := &Report{Mismatched: make(map[string]map[string]int)}
+
+	for _, record := range c.records {
+		var mu sync.Mutex
+
+		counts := map[string]int{ProblemMissing: 0, ProblemShort: 0, ProblemLong: 0}
+
+		// в кластере узлы обходятся параллельно
+		err := backup.Scan(ctx, c.client, record.Pattern, func(ctx context.Context, client redis.UniversalClient, key string) error {
+			problem, checked, err := c.check(ctx, client, record, key)
+			if err != nil || !checked {
+				return err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Scanned++
+
+			if problem != "" {
+				counts[problem]++
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("ttlcheck: error scan %s: %w", record.Pattern, err)
+		}
+
+		for problem, n := range counts {
+			c.mismatched.WithLabelValues(record.Name, problem).Set(float64(n))
+
+			if n == 0 {
+				continue
+			}
+
+			if report.Mismatched[record.Name] == nil {
+				report.Mismatched[record.Name] = make(map[string]int)
+			}
+
+			report.Mismatched[record.Name][problem] = n
+		}
+	}
+
+	return report, nil
+}
+
+// check возвращает проблему TTL записи key или пустую строку, если TTL согласован.
+// checked = false, если срок действия записи неизвестен.
+func (c *Checker) check(ctx context.Context, client redis.UniversalClient, record Record, key string) (string, bool, error) {
+	expiresAt, ok, err := record.Expiry(ctx, key)
+	if err != nil || !ok {
+		return "", false, err
+	}
+
+	ttl, err := client.PTTL(ctx, key).Result()
+	if err != nil {
+		return "", false, err
+	}
+
+	// ключ истек или удален после чтения срока
+	if ttl < 0 && ttl != noExpire {
+		return "", false, nil
+	}
+
+	problem := c.problem(ttl, expiresAt)
+	if problem != "" {
+		logrus.WithFields(logrus.Fields{
+			"record":     record.Name,
+			"key":        key,
+			"ttl":        ttl,
+			"expires_at": expiresAt,
+			"problem":    problem,
+		}).Debug("redis record ttl is misaligned")
+	}
+
+	return problem, true, nil
+}
+
+// problem сравнивает TTL с ожидаемым: срок действия плюс запас, с допуском tolerance.
+func (c *Checker) problem(ttl time.Duration, expiresAt time.Time) string {
+	if ttl == noExpire {
+		return ProblemMissing
+	}
+
+	diff := c.now().Add(ttl).Sub(expiresAt.Add(c.slack))
+
+	switch {
+	case diff < -c.tolerance:
+		return ProblemShort
+	case diff > c.tolerance:
+		return ProblemLong
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,154 @@
+package ttlcheck
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// valueExpiry - срок действия записи в ее значении (unix time).
+func valueExpiry(client redis.UniversalClient) func(ctx context.Context, key string) (time.Time, bool, error) {
+	return func(ctx context.Context, key string) (time.Time, bool, error) {
+		value, err := client.Get(ctx, key).Result()
+		if err != nil {
+			return time.Time{}, false, err
+		}
+
+		ts, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, false, nil //nolint:nilerr // запись без срока не проверяется
+		}
+
+		return time.Unix(ts, 0), true, nil
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	t.Cleanup(func() { _ = client.Close() })
+
+	record := Record{Name: "test", Pattern: "test:*", Expiry: valueExpiry(client)}
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			opts:    []Option{WithClient(client), WithRecords(record)},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: no client",
+			opts:    []Option{WithRecords(record)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: no records",
+			opts:    []Option{WithClient(client)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: record without expiry",
+			opts:    []Option{WithClient(client), WithRecords(Record{Name: "test", Pattern: "test:*"})},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: negative slack",
+			opts:    []Option{WithClient(client), WithRecords(record), WithSlack(-time.Second)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: zero interval",
+			opts:    []Option{WithClient(client), WithRecords(record), WithInterval(0)},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(append(tt.opts, WithRegisterer(prometheus.NewRegistry()))...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+func TestChecker_Run(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	c, err := New(
+		WithClient(client),
+		WithRecords(Record{Name: "test", Pattern: "test:*", Expiry: valueExpiry(client)}),
+		WithSlack(time.Minute),
+		WithTolerance(10*time.Second),
+		WithRegisterer(prometheus.NewRegistry()),
+	)
+	require.NoError(t, err)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	expiresAt := strconv.FormatInt(now.Add(time.Hour).Unix(), 10)
+
+	set := func(key, value string, ttl time.Duration) {
+		require.NoError(t, mr.Set(key, value))
+
+		if ttl != 0 {
+			mr.SetTTL(key, ttl)
+		}
+	}
+
+	set("test:aligned", expiresAt, time.Hour+time.Minute)
+	set("test:within-tolerance", expiresAt, time.Hour+time.Minute+5*time.Second)
+	set("test:missing", expiresAt, 0)
+	set("test:short", expiresAt, time.Hour)
+	set("test:long", expiresAt, 2*time.Hour)
+	// срок в записи не найден
+	set("test:unknown", "value", 0)
+	// другие ключи не проверяются
+	set("other:long", expiresAt, 2*time.Hour)
+
+	report, err := c.Run(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 5, report.Scanned)
+	assert.Equal(t, map[string]map[string]int{
+		"test": {ProblemMissing: 1, ProblemShort: 1, ProblemLong: 1},
+	}, report.Mismatched)
+
+	assert.InDelta(t, 1, testutil.ToFloat64(c.mismatched.WithLabelValues("test", ProblemShort)), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(c.runs.WithLabelValues("ok")), 0)
+
+	// после исправления TTL расхождения нет
+	for _, key := range []string{"test:missing", "test:short", "test:long"} {
+		mr.SetTTL(key, time.Hour+time.Minute)
+	}
+
+	report, err = c.Run(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, report.Mismatched)
+	assert.InDelta(t, 0, testutil.ToFloat64(c.mismatched.WithLabelValues("test", ProblemShort)), 0)
+
+	mr.Close()
+
+	_, err = c.Run(t.Context())
+	require.Error(t, err)
+	assert.InDelta(t, 1, testutil.ToFloat64(c.runs.WithLabelValues("error")), 0)
+}
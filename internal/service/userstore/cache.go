@@ -0,0 +1,286 @@
+package userstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	cacheKeyPrefix = "auth:users:"
+
+	// notFoundValue - значение ключа кэша для пользователя, которого нет в хранилище.
+	notFoundValue = "-"
+
+	// DefaultCacheTTL - сколько по умолчанию кэшируется найденный пользователь.
+	DefaultCacheTTL = 30 * time.Second
+	// DefaultNegativeCacheTTL - сколько по умолчанию кэшируется отсутствие пользователя.
+	DefaultNegativeCacheTTL = 5 * time.Second
+)
+
+// Результаты поиска пользователя в метриках кэша.
+const (
+	cacheHit         = "hit"          // пользователь найден в кэше
+	cacheNegativeHit = "negative_hit" // в кэше записано, что пользователя нет
+	cacheMiss        = "miss"         // запрос ушел в хранилище
+	cacheCoalesced   = "coalesced"    // результат получен от параллельного запроса того же пользователя
+)
+
+// Cache - кэш хранилища пользователей в Redis. Защищает внешний сервис пользователей от
+// всплесков входов: найденный пользователь кэшируется на ttl, отсутствие пользователя - отдельно
+// на более короткий negativeTTL, чтобы только что созданный пользователь быстро становился виден.
+// Параллельные промахи по одному ключу на экземпляре объединяются в один запрос к хранилищу.
+// Ошибки хранилища не кэшируются, а при недоступности Redis запросы идут в хранилище напрямую.
+//
+// Проверка пароля не кэшируется: смена пароля должна действовать сразу. Пользователь из
+// успешной проверки обновляет кэш поиска по ID и по ID в Telegram.
+//
+// Ключи:
+//   - auth:users:id:<id> - пользователь по ID;
+//   - auth:users:tg:<telegram_id> - пользователь по ID в Telegram.
+type Cache struct {
+	store       Store
+	client      redis.UniversalClient
+	ttl         time.Duration
+	negativeTTL time.Duration
+
+	group singleflight.Group
+
+	registerer prometheus.Registerer
+	lookups    *prometheus.CounterVec
+}
+
+// CacheOption - опция для настройки Cache.
+type CacheOption func(*Cache)
+
+// WithStore устанавливает кэшируемое хранилище.
+func WithStore(store Store) CacheOption {
+	return func(c *Cache) {
+		c.store = store
+	}
+}
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) CacheOption {
+	return func(c *Cache) {
+		c.client = client
+	}
+}
+
+// WithTTL устанавливает, сколько кэшируется найденный пользователь. По умолчанию DefaultCacheTTL.
+func WithTTL(ttl time.Duration) CacheOption {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// WithNegativeTTL устанавливает, сколько кэшируется отсутствие пользователя. По умолчанию DefaultNegativeCacheTTL.
+func WithNegativeTTL(ttl time.Duration) CacheOption {
+	return func(c *Cache) {
+		c.negativeTTL = ttl
+	}
+}
+
+// WithRegisterer устанавливает реестр метрик. По умолчанию используется prometheus.DefaultRegisterer.
+func WithRegisterer(registerer prometheus.Registerer) CacheOption {
+	return func(c *Cache) {
+		c.registerer = registerer
+	}
+}
+
+// NewCache создает новый Cache и регистрирует его метрики.
+func NewCache(opts ...CacheOption) (*Cache, error) {
+	c := &Cache{
+		ttl:         DefaultCacheTTL,
+		negativeTTL: DefaultNegativeCacheTTL,
+		registerer:  prometheus.DefaultRegisterer,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.store == nil {
+		return nil, errors.New("store is required")
+	}
+
+	if c.client == nil {
+		return nil, errors.New("redis client is required")
+	}
+
+	if c.registerer == nil {
+		return nil, errors.New("registerer is required")
+	}
+
+	if c.ttl <= 0 || c.negativeTTL <= 0 {
+		return nil, errors.New("ttl and negative ttl must be positive")
+	}
+
+	c.lookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_user_store_cache_lookups_total",
+		Help: "Количество поисков пользователя: hit и negative_hit - ответ из кэша, miss - запрос в хранилище, " +
+			"coalesced - результат получен от параллельного запроса.",
+	}, []string{"result"})
+
+	if err := c.registerer.Register(c.lookups); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func idKey(id string) string {
+	return cacheKeyPrefix + "id:" + id
+}
+
+func telegramKey(telegramID int64) string {
+	return cacheKeyPrefix + "tg:" + strconv.FormatInt(telegramID, 10)
+}
+
+// GetByID возвращает пользователя по ID.
+func (c *Cache) GetByID(ctx context.Context, id string) (*User, error) {
+	if id == "" {
+		return nil, ErrNotFound
+	}
+
+	return c.get(ctx, idKey(id), func(ctx context.Context) (*User, error) {
+		return c.store.GetByID(ctx, id)
+	})
+}
+
+// GetByTelegramID возвращает пользователя по ID в Telegram.
+func (c *Cache) GetByTelegramID(ctx context.Context, telegramID int64) (*User, error) {
+	if telegramID <= 0 {
+		return nil, ErrNotFound
+	}
+
+	return c.get(ctx, telegramKey(telegramID), func(ctx context.Context) (*User, error) {
+		return c.store.GetByTelegramID(ctx, telegramID)
+	})
+}
+
+// VerifyCredentials проверяет логин и пароль в хранилище без кэша.
+func (c *Cache) VerifyCredentials(ctx context.Context, login, password string) (*User, error) {
+	user, err := c.store.VerifyCredentials(ctx, login, password)
+	if err != nil {
+		return nil, err
+	}
+
+	c.set(ctx, idKey(user.ID), user)
+
+	if user.TelegramID > 0 {
+		c.set(ctx, telegramKey(user.TelegramID), user)
+	}
+
+	return user, nil
+}
+
+// get возвращает пользователя из кэша по ключу key или запрашивает его через fetch и кэширует ответ.
+func (c *Cache) get(ctx context.Context, key string, fetch func(context.Context) (*User, error)) (*User, error) {
+	user, found, err := c.cached(ctx, key)
+	if err != nil {
+		logrus.WithError(err).WithField("key", key).Warn("error read user store cache")
+	}
+
+	if found {
+		if user == nil {
+			c.lookups.WithLabelValues(cacheNegativeHit).Inc()
+
+			return nil, ErrNotFound
+		}
+
+		c.lookups.WithLabelValues(cacheHit).Inc()
+
+		return user, nil
+	}
+
+	executed := false
+
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		executed = true
+
+		// запрос к хранилищу общий для всех ожидающих: отмена одного из них его не прерывает
+		user, err := fetch(context.WithoutCancel(ctx))
+
+		switch {
+		case err == nil:
+			c.set(context.WithoutCancel(ctx), key, user)
+		case errors.Is(err, ErrNotFound):
+			c.set(context.WithoutCancel(ctx), key, nil)
+		}
+
+		return user, err
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("userstore: error get user: %w", ctx.Err())
+	case res := <-ch:
+		result := cacheMiss
+		if !executed {
+			result = cacheCoalesced
+		}
+
+		c.lookups.WithLabelValues(result).Inc()
+
+		if res.Err != nil {
+			return nil, res.Err
+		}
+
+		// каждый вызывающий получает свою копию пользователя
+		user := *res.Val.(*User) //nolint:forcetypeassert // fetch возвращает только *User
+
+		return &user, nil
+	}
+}
+
+// cached читает ключ кэша. found - ключ есть в кэше; при этом user == nil означает, что
+// пользователя нет в хранилище.
+func (c *Cache) cached(ctx context.Context, key string) (user *User, found bool, err error) {
+	data, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	if string(data) == notFoundValue {
+		return nil, true, nil
+	}
+
+	user = &User{}
+
+	if err := json.Unmarshal(data, user); err != nil {
+		return nil, false, err
+	}
+
+	return user, true, nil
+}
+
+// set кэширует пользователя, а если user == nil - его отсутствие. Ошибка Redis не мешает ответу.
+func (c *Cache) set(ctx context.Context, key string, user *User) {
+	value, ttl := []byte(notFoundValue), c.negativeTTL
+
+	if user != nil {
+		data, err := json.Marshal(user)
+		if err != nil {
+			return
+		}
+
+		value, ttl = data, c.ttl
+	}
+
+	if err := c.client.Set(ctx, key, value, ttl).Err(); err != nil {
+		logrus.WithError(err).WithField("key", key).Warn("error write user store cache")
+	}
+}
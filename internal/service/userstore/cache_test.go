@@ -0,0 +1,258 @@
+package userstore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore - хранилище с одним пользователем и паролем "secret", считающее запросы.
+type fakeStore struct {
+	user  User
+	err   error
+	calls atomic.Int64
+	// wait, если задан, задерживает ответы до своего закрытия.
+	wait chan struct{}
+}
+
+func (s *fakeStore) lookup(match bool) (*User, error) {
+	s.calls.Add(1)
+
+	if s.wait != nil {
+		<-s.wait
+	}
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	if !match {
+		return nil, ErrNotFound
+	}
+
+	user := s.user
+
+	return &user, nil
+}
+
+func (s *fakeStore) GetByID(_ context.Context, id string) (*User, error) {
+	return s.lookup(id == s.user.ID)
+}
+
+func (s *fakeStore) GetByTelegramID(_ context.Context, telegramID int64) (*User, error) {
+	return s.lookup(telegramID == s.user.TelegramID)
+}
+
+func (s *fakeStore) VerifyCredentials(_ context.Context, login, password string) (*User, error) {
+	user, err := s.lookup(login == s.user.Username && password == "secret")
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+
+	return user, err
+}
+
+func newCache(t *testing.T, store Store) (*Cache, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	c, err := NewCache(
+		WithStore(store),
+		WithClient(client),
+		WithTTL(time.Minute),
+		WithNegativeTTL(5*time.Second),
+		WithRegisterer(prometheus.NewRegistry()),
+	)
+	require.NoError(t, err)
+
+	return c, mr
+}
+
+func lookups(c *Cache, result string) float64 {
+	return testutil.ToFloat64(c.lookups.WithLabelValues(result))
+}
+
+func TestNewCache(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeStore{}
+	client := redis.NewClient(&redis.Options{})
+
+	tests := []struct {
+		name    string
+		opts    []CacheOption
+		wantErr string
+	}{
+		{
+			name: "positive case",
+			opts: []CacheOption{WithStore(store), WithClient(client), WithRegisterer(prometheus.NewRegistry())},
+		},
+		{
+			name:    "error case: no store",
+			opts:    []CacheOption{WithClient(client), WithRegisterer(prometheus.NewRegistry())},
+			wantErr: "store is required",
+		},
+		{
+			name:    "error case: no client",
+			opts:    []CacheOption{WithStore(store), WithRegisterer(prometheus.NewRegistry())},
+			wantErr: "redis client is required",
+		},
+		{
+			name:    "error case: no registerer",
+			opts:    []CacheOption{WithStore(store), WithClient(client), WithRegisterer(nil)},
+			wantErr: "registerer is required",
+		},
+		{
+			name:    "error case: negative ttl is zero",
+			opts:    []CacheOption{WithStore(store), WithClient(client), WithRegisterer(prometheus.NewRegistry()), WithNegativeTTL(0)},
+			wantErr: "ttl and negative ttl must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewCache(tt.opts...)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCache_GetByID(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeStore{user: User{ID: "user-1", TelegramID: 42, Username: "alice"}}
+	c, mr := newCache(t, store)
+
+	// повторные поиски отвечают из кэша: хранилище получает по одному запросу на пользователя
+	for range 3 {
+		got, err := c.GetByID(t.Context(), "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, &store.user, got)
+
+		_, err = c.GetByID(t.Context(), "user-2")
+		require.ErrorIs(t, err, ErrNotFound)
+	}
+
+	assert.Equal(t, int64(2), store.calls.Load())
+	assert.Equal(t, time.Minute, mr.TTL(idKey("user-1")))
+	assert.Equal(t, 5*time.Second, mr.TTL(idKey("user-2")))
+
+	assert.InDelta(t, 2, lookups(c, cacheMiss), 0)
+	assert.InDelta(t, 2, lookups(c, cacheHit), 0)
+	assert.InDelta(t, 2, lookups(c, cacheNegativeHit), 0)
+
+	// после истечения отрицательного ответа пользователь запрашивается снова
+	mr.FastForward(5 * time.Second)
+
+	_, err := c.GetByID(t.Context(), "user-2")
+	require.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, int64(3), store.calls.Load())
+}
+
+func TestCache_Errors(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeStore{user: User{ID: "user-1", TelegramID: 42}, err: ErrUnavailable}
+	c, mr := newCache(t, store)
+
+	// ошибка хранилища не кэшируется
+	_, err := c.GetByTelegramID(t.Context(), 42)
+	require.ErrorIs(t, err, ErrUnavailable)
+
+	store.err = nil
+
+	got, err := c.GetByTelegramID(t.Context(), 42)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", got.ID)
+	assert.Equal(t, int64(2), store.calls.Load())
+
+	// без Redis запросы идут в хранилище напрямую
+	mr.Close()
+
+	got, err = c.GetByTelegramID(t.Context(), 42)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", got.ID)
+	assert.Equal(t, int64(3), store.calls.Load())
+}
+
+func TestCache_Stampede(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeStore{user: User{ID: "user-1"}, wait: make(chan struct{})}
+	c, _ := newCache(t, store)
+
+	const callers = 10
+
+	var wg sync.WaitGroup
+
+	for range callers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			user, err := c.GetByID(context.Background(), "user-1")
+			assert.NoError(t, err)
+			assert.Equal(t, "user-1", user.ID)
+		}()
+	}
+
+	// даем вызывающим дойти до общего запроса
+	require.Eventually(t, func() bool { return store.calls.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	close(store.wait)
+	wg.Wait()
+
+	// опоздавшие к общему запросу получают пользователя из кэша, но хранилище запрошено один раз
+	assert.Equal(t, int64(1), store.calls.Load())
+	assert.InDelta(t, 1, lookups(c, cacheMiss), 0)
+	assert.InDelta(t, callers-1, lookups(c, cacheCoalesced)+lookups(c, cacheHit), 0)
+}
+
+func TestCache_VerifyCredentials(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeStore{user: User{ID: "user-1", TelegramID: 42, Username: "alice"}}
+	c, _ := newCache(t, store)
+
+	// проверка пароля всегда идет в хранилище, а найденный пользователь попадает в кэш
+	for range 2 {
+		got, err := c.VerifyCredentials(t.Context(), "alice", "secret")
+		require.NoError(t, err)
+		assert.Equal(t, &store.user, got)
+	}
+
+	_, err := c.VerifyCredentials(t.Context(), "alice", "wrong")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	assert.Equal(t, int64(3), store.calls.Load())
+
+	got, err := c.GetByID(t.Context(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, &store.user, got)
+
+	got, err = c.GetByTelegramID(t.Context(), 42)
+	require.NoError(t, err)
+	assert.Equal(t, &store.user, got)
+	assert.Equal(t, int64(3), store.calls.Load())
+}
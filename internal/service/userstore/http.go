@@ -0,0 +1,222 @@
+package userstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout - таймаут запроса к сервису пользователей по умолчанию.
+	DefaultTimeout = 2 * time.Second
+	// DefaultHealthPath - путь проверки доступности сервиса пользователей по умолчанию.
+	DefaultHealthPath = "/health"
+
+	// maxResponseSize - наибольший размер читаемого ответа.
+	maxResponseSize = 1 << 20
+)
+
+// HTTPStore - хранилище пользователей во внешнем сервисе с HTTP API:
+//
+//	GET  <url>/users/<id>                   - пользователь по ID;
+//	GET  <url>/users/by-telegram/<id>       - пользователь по ID в Telegram;
+//	POST <url>/credentials/verify           - проверка {"login", "password"};
+//	GET  <url><health path>                 - проверка доступности.
+//
+// Пользователь возвращается в JSON (см. User) с кодом 200. 404 означает, что пользователь
+// не найден, 401 и 403 на проверку пароля - что логин или пароль не подошли.
+// Если задан токен, он передается в заголовке Authorization: Bearer.
+type HTTPStore struct {
+	httpClient *http.Client
+	url        string
+	token      string
+	healthPath string
+}
+
+// HTTPOption - опция для настройки HTTPStore.
+type HTTPOption func(*HTTPStore)
+
+// WithURL устанавливает адрес API сервиса пользователей.
+func WithURL(url string) HTTPOption {
+	return func(s *HTTPStore) {
+		s.url = url
+	}
+}
+
+// WithToken устанавливает токен, с которым сервис авторизации обращается к сервису пользователей.
+func WithToken(token string) HTTPOption {
+	return func(s *HTTPStore) {
+		s.token = token
+	}
+}
+
+// WithHTTPClient устанавливает HTTP клиент. По умолчанию клиент с таймаутом DefaultTimeout.
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return func(s *HTTPStore) {
+		s.httpClient = client
+	}
+}
+
+// WithHealthPath устанавливает путь проверки доступности. По умолчанию DefaultHealthPath.
+func WithHealthPath(path string) HTTPOption {
+	return func(s *HTTPStore) {
+		s.healthPath = path
+	}
+}
+
+// NewHTTP создает новый HTTPStore.
+func NewHTTP(opts ...HTTPOption) (*HTTPStore, error) {
+	s := &HTTPStore{
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		healthPath: DefaultHealthPath,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.httpClient == nil {
+		return nil, errors.New("http client is required")
+	}
+
+	u, err := url.Parse(s.url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("url must be an absolute http(s) url")
+	}
+
+	if !strings.HasPrefix(s.healthPath, "/") {
+		return nil, errors.New("health path must start with /")
+	}
+
+	s.url = strings.TrimRight(s.url, "/")
+
+	return s, nil
+}
+
+// GetByID возвращает пользователя по ID.
+func (s *HTTPStore) GetByID(ctx context.Context, id string) (*User, error) {
+	if id == "" {
+		return nil, ErrNotFound
+	}
+
+	return s.user(ctx, http.MethodGet, "/users/"+url.PathEscape(id), nil, ErrNotFound)
+}
+
+// GetByTelegramID возвращает пользователя по ID в Telegram.
+func (s *HTTPStore) GetByTelegramID(ctx context.Context, telegramID int64) (*User, error) {
+	if telegramID <= 0 {
+		return nil, ErrNotFound
+	}
+
+	return s.user(ctx, http.MethodGet, "/users/by-telegram/"+strconv.FormatInt(telegramID, 10), nil, ErrNotFound)
+}
+
+// credentialsRequest - тело запроса проверки пароля.
+type credentialsRequest struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+// VerifyCredentials проверяет логин и пароль.
+func (s *HTTPStore) VerifyCredentials(ctx context.Context, login, password string) (*User, error) {
+	if login == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	body, err := json.Marshal(credentialsRequest{Login: login, Password: password})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.user(ctx, http.MethodPost, "/credentials/verify", body, ErrInvalidCredentials)
+}
+
+// Health проверяет доступность сервиса пользователей.
+func (s *HTTPStore) Health(ctx context.Context) error {
+	resp, err := s.do(ctx, http.MethodGet, s.healthPath, nil)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: unexpected status %d", ErrUnavailable, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// user выполняет запрос и разбирает пользователя из ответа. notFound возвращается на 404,
+// а для проверки пароля и на 401 и 403.
+func (s *HTTPStore) user(ctx context.Context, method, path string, body []byte, notFound error) (*User, error) {
+	resp, err := s.do(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, notFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		if errors.Is(notFound, ErrInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+
+		return nil, fmt.Errorf("%w: access denied with status %d", ErrUnavailable, resp.StatusCode)
+	default:
+		return nil, fmt.Errorf("%w: unexpected status %d", ErrUnavailable, resp.StatusCode)
+	}
+
+	var user User
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&user); err != nil {
+		return nil, fmt.Errorf("%w: error decode user: %w", ErrUnavailable, err)
+	}
+
+	if user.ID == "" {
+		return nil, fmt.Errorf("%w: user without id", ErrUnavailable)
+	}
+
+	return &user, nil
+}
+
+func (s *HTTPStore) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.url+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("userstore: error create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	return resp, nil
+}
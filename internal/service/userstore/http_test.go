@@ -0,0 +1,178 @@
+package userstore
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStore(t *testing.T, handler http.HandlerFunc, opts ...HTTPOption) *HTTPStore {
+	t.Helper()
+
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	s, err := NewHTTP(append([]HTTPOption{WithURL(ts.URL + "/")}, opts...)...)
+	require.NoError(t, err)
+
+	return s
+}
+
+// usersHandler - сервис пользователей с одним пользователем и паролем "secret".
+func usersHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	user := User{ID: "user-1", TelegramID: 42, Username: "alice"}
+
+	switch {
+	case r.Method == http.MethodGet && (r.URL.Path == "/users/user-1" || r.URL.Path == "/users/by-telegram/42"):
+		_ = json.NewEncoder(w).Encode(user)
+	case r.Method == http.MethodPost && r.URL.Path == "/credentials/verify":
+		var req credentialsRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if req.Login != "alice" || req.Password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(user)
+	case r.URL.Path == "/users/broken":
+		_, _ = w.Write([]byte(`{"username":"no id"}`))
+	case r.URL.Path == "/users/failing":
+		w.WriteHeader(http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestNewHTTP(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []HTTPOption
+		wantErr string
+	}{
+		{name: "positive case", opts: []HTTPOption{WithURL("https://users.example.com/api")}},
+		{name: "error case: no url", wantErr: "url must be an absolute http(s) url"},
+		{name: "error case: relative url", opts: []HTTPOption{WithURL("/api")}, wantErr: "url must be an absolute http(s) url"},
+		{
+			name:    "error case: no http client",
+			opts:    []HTTPOption{WithURL("https://users.example.com"), WithHTTPClient(nil)},
+			wantErr: "http client is required",
+		},
+		{
+			name:    "error case: invalid health path",
+			opts:    []HTTPOption{WithURL("https://users.example.com"), WithHealthPath("health")},
+			wantErr: "health path must start with /",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewHTTP(tt.opts...)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHTTPStore_GetByID(t *testing.T) {
+	t.Parallel()
+
+	s := newStore(t, usersHandler, WithToken("token"))
+
+	user, err := s.GetByID(t.Context(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, &User{ID: "user-1", TelegramID: 42, Username: "alice"}, user)
+
+	_, err = s.GetByID(t.Context(), "user-2")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = s.GetByID(t.Context(), "")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = s.GetByID(t.Context(), "broken")
+	require.ErrorIs(t, err, ErrUnavailable)
+
+	_, err = s.GetByID(t.Context(), "failing")
+	require.ErrorIs(t, err, ErrUnavailable)
+
+	// без токена сервис пользователей отказывает: это ошибка настройки, а не отсутствие пользователя
+	_, err = newStore(t, usersHandler).GetByID(t.Context(), "user-1")
+	require.ErrorIs(t, err, ErrUnavailable)
+}
+
+func TestHTTPStore_GetByTelegramID(t *testing.T) {
+	t.Parallel()
+
+	s := newStore(t, usersHandler, WithToken("token"))
+
+	user, err := s.GetByTelegramID(t.Context(), 42)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", user.ID)
+
+	_, err = s.GetByTelegramID(t.Context(), 43)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = s.GetByTelegramID(t.Context(), 0)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestHTTPStore_VerifyCredentials(t *testing.T) {
+	t.Parallel()
+
+	s := newStore(t, usersHandler, WithToken("token"))
+
+	user, err := s.VerifyCredentials(t.Context(), "alice", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", user.ID)
+
+	_, err = s.VerifyCredentials(t.Context(), "alice", "wrong")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = s.VerifyCredentials(t.Context(), "alice", "")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	unavailable := newStore(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	_, err = unavailable.VerifyCredentials(t.Context(), "alice", "secret")
+	require.ErrorIs(t, err, ErrUnavailable)
+}
+
+func TestHTTPStore_Health(t *testing.T) {
+	t.Parallel()
+
+	s := newStore(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ready" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}, WithHealthPath("/ready"))
+
+	require.NoError(t, s.Health(t.Context()))
+
+	s = newStore(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	require.ErrorIs(t, s.Health(t.Context()), ErrUnavailable)
+}
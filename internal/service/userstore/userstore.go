@@ -0,0 +1,38 @@
+// Package userstore описывает хранилище пользователей. В части инсталляций данные пользователей
+// живут в отдельном сервисе, а здесь нужна только логика токенов: сервис авторизации запрашивает
+// пользователя или проверку пароля у хранилища и выпускает токен по ответу.
+package userstore
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNotFound - пользователь не найден.
+	ErrNotFound = errors.New("user not found")
+	// ErrInvalidCredentials - логин или пароль не подошли.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrUnavailable - хранилище недоступно или ответило ошибкой.
+	ErrUnavailable = errors.New("user store is unavailable")
+)
+
+// User - пользователь хранилища.
+type User struct {
+	ID         string `json:"id"`
+	TelegramID int64  `json:"telegram_id,omitempty"`
+	Username   string `json:"username,omitempty"`
+	Email      string `json:"email,omitempty"`
+	// Disabled - пользователь отключен: хранилище его возвращает, но выпускать ему токены нельзя.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// Store - хранилище пользователей.
+type Store interface {
+	// GetByID возвращает пользователя по ID или ErrNotFound.
+	GetByID(ctx context.Context, id string) (*User, error)
+	// GetByTelegramID возвращает пользователя по ID в Telegram или ErrNotFound.
+	GetByTelegramID(ctx context.Context, telegramID int64) (*User, error)
+	// VerifyCredentials проверяет логин и пароль и возвращает пользователя или ErrInvalidCredentials.
+	VerifyCredentials(ctx context.Context, login, password string) (*User, error)
+}
@@ -0,0 +1,166 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: warmup.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	token "auth-service/internal/service/token"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockkeyLoader is a mock of keyLoader interface.
+type MockkeyLoader struct {
+	ctrl     *gomock.Controller
+	recorder *MockkeyLoaderMockRecorder
+}
+
+// MockkeyLoaderMockRecorder is the mock recorder for MockkeyLoader.
+type MockkeyLoaderMockRecorder struct {
+	mock *MockkeyLoader
+}
+
+// NewMockkeyLoader creates a new mock instance.
+func NewMockkeyLoader(ctrl *gomock.Controller) *MockkeyLoader {
+	mock := &MockkeyLoader{ctrl: ctrl}
+	mock.recorder = &MockkeyLoaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockkeyLoader) EXPECT() *MockkeyLoaderMockRecorder {
+	return m.recorder
+}
+
+// SigningKey mocks base method.
+func (m *MockkeyLoader) SigningKey(ctx context.Context) (string, []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SigningKey", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].([]byte)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SigningKey indicates an expected call of SigningKey.
+func (mr *MockkeyLoaderMockRecorder) SigningKey(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SigningKey", reflect.TypeOf((*MockkeyLoader)(nil).SigningKey), ctx)
+}
+
+// Mockpinger is a mock of pinger interface.
+type Mockpinger struct {
+	ctrl     *gomock.Controller
+	recorder *MockpingerMockRecorder
+}
+
+// MockpingerMockRecorder is the mock recorder for Mockpinger.
+type MockpingerMockRecorder struct {
+	mock *Mockpinger
+}
+
+// NewMockpinger creates a new mock instance.
+func NewMockpinger(ctrl *gomock.Controller) *Mockpinger {
+	mock := &Mockpinger{ctrl: ctrl}
+	mock.recorder = &MockpingerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockpinger) EXPECT() *MockpingerMockRecorder {
+	return m.recorder
+}
+
+// Ping mocks base method.
+func (m *Mockpinger) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockpingerMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*Mockpinger)(nil).Ping), ctx)
+}
+
+// MocktokenIssuer is a mock of tokenIssuer interface.
+type MocktokenIssuer struct {
+	ctrl     *gomock.Controller
+	recorder *MocktokenIssuerMockRecorder
+}
+
+// MocktokenIssuerMockRecorder is the mock recorder for MocktokenIssuer.
+type MocktokenIssuerMockRecorder struct {
+	mock *MocktokenIssuer
+}
+
+// NewMocktokenIssuer creates a new mock instance.
+func NewMocktokenIssuer(ctrl *gomock.Controller) *MocktokenIssuer {
+	mock := &MocktokenIssuer{ctrl: ctrl}
+	mock.recorder = &MocktokenIssuerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktokenIssuer) EXPECT() *MocktokenIssuerMockRecorder {
+	return m.recorder
+}
+
+// Issue mocks base method.
+func (m *MocktokenIssuer) Issue(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Issue", ctx, req)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*token.Claims)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Issue indicates an expected call of Issue.
+func (mr *MocktokenIssuerMockRecorder) Issue(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Issue", reflect.TypeOf((*MocktokenIssuer)(nil).Issue), ctx, req)
+}
+
+// MocktokenValidator is a mock of tokenValidator interface.
+type MocktokenValidator struct {
+	ctrl     *gomock.Controller
+	recorder *MocktokenValidatorMockRecorder
+}
+
+// MocktokenValidatorMockRecorder is the mock recorder for MocktokenValidator.
+type MocktokenValidatorMockRecorder struct {
+	mock *MocktokenValidator
+}
+
+// NewMocktokenValidator creates a new mock instance.
+func NewMocktokenValidator(ctrl *gomock.Controller) *MocktokenValidator {
+	mock := &MocktokenValidator{ctrl: ctrl}
+	mock.recorder = &MocktokenValidatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktokenValidator) EXPECT() *MocktokenValidatorMockRecorder {
+	return m.recorder
+}
+
+// Validate mocks base method.
+func (m *MocktokenValidator) Validate(ctx context.Context, raw string) (*token.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", ctx, raw)
+	ret0, _ := ret[0].(*token.Claims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MocktokenValidatorMockRecorder) Validate(ctx, raw interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MocktokenValidator)(nil).Validate), ctx, raw)
+}
@@ -0,0 +1,225 @@
+// Package warmup выполняет прогрев сервиса перед тем, как он начнет принимать запросы:
+// заранее читает ключи подписи из Vault, открывает соединения с Redis и выпускает
+// и проверяет один токен, чтобы первые запросы после деплоя не платили за холодный старт.
+package warmup
+
+import (
+	"auth-service/internal/service/token"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultTimeout - таймаут прогрева по умолчанию.
+	DefaultTimeout = 10 * time.Second
+	// DefaultRedisConnections - количество соединений с Redis, открываемых при прогреве, по умолчанию.
+	DefaultRedisConnections = 10
+	// Audience - аудитория токена, который выпускается при прогреве.
+	Audience = "auth-service-warmup"
+)
+
+// selfCheckTTL - время жизни токена, выпускаемого при прогреве.
+const selfCheckTTL = time.Minute
+
+//go:generate mockgen -source=warmup.go -destination=mocks/mocks.go -package=mocks
+type keyLoader interface {
+	SigningKey(ctx context.Context) (string, []byte, error)
+}
+
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+type tokenIssuer interface {
+	Issue(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error)
+}
+
+type tokenValidator interface {
+	Validate(ctx context.Context, raw string) (*token.Claims, error)
+}
+
+// Runner - прогрев сервиса. Шаги, для которых не заданы зависимости, пропускаются.
+type Runner struct {
+	keys keyLoader
+
+	redis            pinger
+	redisConnections int
+
+	issuer    tokenIssuer
+	validator tokenValidator
+
+	timeout time.Duration
+}
+
+// Option - опция для настройки Runner.
+type Option func(*Runner)
+
+// WithKeys устанавливает источник ключей подписи, которые нужно прочитать заранее.
+func WithKeys(keys keyLoader) Option {
+	return func(r *Runner) {
+		r.keys = keys
+	}
+}
+
+// WithRedis устанавливает Redis, с которым нужно открыть connections соединений.
+// Если connections не больше 0, используется DefaultRedisConnections.
+func WithRedis(redis pinger, connections int) Option {
+	return func(r *Runner) {
+		r.redis = redis
+		r.redisConnections = connections
+	}
+}
+
+// WithSelfCheck устанавливает выпуск и проверку токена при прогреве.
+func WithSelfCheck(issuer tokenIssuer, validator tokenValidator) Option {
+	return func(r *Runner) {
+		r.issuer = issuer
+		r.validator = validator
+	}
+}
+
+// WithTimeout устанавливает таймаут прогрева. По умолчанию DefaultTimeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(r *Runner) {
+		r.timeout = timeout
+	}
+}
+
+// New создает новый Runner.
+func New(opts ...Option) (*Runner, error) {
+	r := &Runner{
+		timeout:          DefaultTimeout,
+		redisConnections: DefaultRedisConnections,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.timeout <= 0 {
+		return nil, errors.New("timeout must be positive")
+	}
+
+	if r.redisConnections <= 0 {
+		r.redisConnections = DefaultRedisConnections
+	}
+
+	if (r.issuer == nil) != (r.validator == nil) {
+		return nil, errors.New("self check requires both issuer and validator")
+	}
+
+	return r, nil
+}
+
+type step struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Run выполняет шаги прогрева по порядку. Ошибка шага не прерывает остальные шаги,
+// все ошибки возвращаются вместе.
+func (r *Runner) Run(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var errs []error
+
+	for _, s := range r.steps() {
+		started := time.Now()
+		err := s.fn(ctx)
+
+		entry := logrus.WithFields(logrus.Fields{
+			"step":     s.name,
+			"duration": time.Since(started),
+		})
+
+		if err != nil {
+			entry.WithError(err).Warn("warm-up step failed")
+			errs = append(errs, fmt.Errorf("warmup: %s: %w", s.name, err))
+
+			continue
+		}
+
+		entry.Info("warm-up step completed")
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r *Runner) steps() []step {
+	var steps []step
+
+	if r.keys != nil {
+		steps = append(steps, step{name: "signing-keys", fn: r.loadKeys})
+	}
+
+	if r.redis != nil {
+		steps = append(steps, step{name: "redis", fn: r.warmRedis})
+	}
+
+	if r.issuer != nil {
+		steps = append(steps, step{name: "self-check", fn: r.selfCheck})
+	}
+
+	return steps
+}
+
+func (r *Runner) loadKeys(ctx context.Context) error {
+	_, _, err := r.keys.SigningKey(ctx)
+	return err
+}
+
+// warmRedis параллельно пингует Redis, чтобы клиент открыл соединения в пуле заранее.
+func (r *Runner) warmRedis(ctx context.Context) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	for range r.redisConnections {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := r.redis.Ping(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// selfCheck выпускает токен и проверяет его, прогревая весь путь подписи и проверки.
+func (r *Runner) selfCheck(ctx context.Context) error {
+	raw, _, err := r.issuer.Issue(ctx, token.IssueRequest{
+		Subject:  Audience,
+		Audience: []string{Audience},
+		TTL:      selfCheckTTL,
+	})
+	if err != nil {
+		return fmt.Errorf("error issue token: %w", err)
+	}
+
+	claims, err := r.validator.Validate(ctx, raw)
+	if err != nil {
+		return fmt.Errorf("error validate token: %w", err)
+	}
+
+	if claims.Subject != Audience {
+		return fmt.Errorf("unexpected subject %q", claims.Subject)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package warmup
+
+import (
+	"auth-service/internal/service/token"
+	"auth-service/internal/service/warmup/mocks"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case: defaults",
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: all steps",
+			opts: []Option{
+				WithKeys(mocks.NewMockkeyLoader(ctrl)),
+				WithRedis(mocks.NewMockpinger(ctrl), 5),
+				WithSelfCheck(mocks.NewMocktokenIssuer(ctrl), mocks.NewMocktokenValidator(ctrl)),
+				WithTimeout(time.Second),
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: zero timeout",
+			opts:    []Option{WithTimeout(0)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: issuer without validator",
+			opts:    []Option{WithSelfCheck(mocks.NewMocktokenIssuer(ctrl), nil)},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(tt.opts...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		setup   func(keys *mocks.MockkeyLoader, redis *mocks.Mockpinger, issuer *mocks.MocktokenIssuer, validator *mocks.MocktokenValidator)
+		wantErr []string
+	}{
+		{
+			name: "positive case",
+			setup: func(keys *mocks.MockkeyLoader, redis *mocks.Mockpinger, issuer *mocks.MocktokenIssuer, validator *mocks.MocktokenValidator) {
+				keys.EXPECT().SigningKey(gomock.Any()).Return("key-1", []byte("secret"), nil)
+				redis.EXPECT().Ping(gomock.Any()).Return(nil).Times(3)
+				issuer.EXPECT().Issue(gomock.Any(), token.IssueRequest{
+					Subject:  Audience,
+					Audience: []string{Audience},
+					TTL:      selfCheckTTL,
+				}).Return("raw", &token.Claims{Subject: Audience}, nil)
+				validator.EXPECT().Validate(gomock.Any(), "raw").Return(&token.Claims{Subject: Audience}, nil)
+			},
+		},
+		{
+			name: "error case: failed steps do not stop others",
+			setup: func(keys *mocks.MockkeyLoader, redis *mocks.Mockpinger, issuer *mocks.MocktokenIssuer, validator *mocks.MocktokenValidator) {
+				keys.EXPECT().SigningKey(gomock.Any()).Return("", nil, errors.New("vault is sealed"))
+				redis.EXPECT().Ping(gomock.Any()).Return(errors.New("connection refused")).Times(3)
+				issuer.EXPECT().Issue(gomock.Any(), gomock.Any()).Return("", nil, errors.New("vault is sealed"))
+			},
+			wantErr: []string{"signing-keys: vault is sealed", "redis: connection refused", "self-check: error issue token"},
+		},
+		{
+			name: "error case: unexpected subject",
+			setup: func(keys *mocks.MockkeyLoader, redis *mocks.Mockpinger, issuer *mocks.MocktokenIssuer, validator *mocks.MocktokenValidator) {
+				keys.EXPECT().SigningKey(gomock.Any()).Return("key-1", []byte("secret"), nil)
+				redis.EXPECT().Ping(gomock.Any()).Return(nil).Times(3)
+				issuer.EXPECT().Issue(gomock.Any(), gomock.Any()).Return("raw", &token.Claims{}, nil)
+				validator.EXPECT().Validate(gomock.Any(), "raw").Return(&token.Claims{Subject: "user"}, nil)
+			},
+			wantErr: []string{"self-check: unexpected subject"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+
+			keys := mocks.NewMockkeyLoader(ctrl)
+			redis := mocks.NewMockpinger(ctrl)
+			issuer := mocks.NewMocktokenIssuer(ctrl)
+			validator := mocks.NewMocktokenValidator(ctrl)
+
+			tt.setup(keys, redis, issuer, validator)
+
+			r, err := New(
+				WithKeys(keys),
+				WithRedis(redis, 3),
+				WithSelfCheck(issuer, validator),
+			)
+			require.NoError(t, err)
+
+			err = r.Run(t.Context())
+			if len(tt.wantErr) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			for _, want := range tt.wantErr {
+				assert.ErrorContains(t, err, want)
+			}
+		})
+	}
+}
+
+func TestRun_NoSteps(t *testing.T) {
+	t.Parallel()
+
+	r, err := New()
+	require.NoError(t, err)
+
+	require.NoError(t, r.Run(t.Context()))
+}
@@ -0,0 +1,185 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// Алгоритмы ключей COSE (https://www.iana.org/assignments/cose/cose.xhtml#algorithms).
+const (
+	AlgES256 = -7
+	AlgEdDSA = -8
+	AlgRS256 = -257
+)
+
+// supportedAlgs - алгоритмы в порядке предпочтения, передаются клиенту в pubKeyCredParams.
+var supportedAlgs = []int{AlgES256, AlgEdDSA, AlgRS256}
+
+// Флаги authenticatorData.
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttested     = 0x40
+)
+
+// authDataMinLength - rpIdHash (32) + flags (1) + signCount (4).
+const authDataMinLength = 37
+
+// clientData - clientDataJSON из ответа браузера.
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// authData - разобранные authenticatorData.
+type authData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	credentialID []byte
+}
+
+// parseClientData разбирает clientDataJSON и проверяет тип операции, challenge и origin.
+func (s *Service) parseClientData(raw []byte, wantType string) (*clientData, error) {
+	var cd clientData
+
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return nil, fmt.Errorf("%w: invalid client data", ErrVerification)
+	}
+
+	if cd.Type != wantType {
+		return nil, fmt.Errorf("%w: unexpected client data type %q", ErrVerification, cd.Type)
+	}
+
+	if _, err := base64.RawURLEncoding.DecodeString(cd.Challenge); err != nil || cd.Challenge == "" {
+		return nil, fmt.Errorf("%w: invalid challenge", ErrVerification)
+	}
+
+	if !slices.Contains(s.origins, cd.Origin) {
+		return nil, fmt.Errorf("%w: origin %q is not allowed", ErrVerification, cd.Origin)
+	}
+
+	return &cd, nil
+}
+
+// parseAuthData разбирает authenticatorData. Если attested, читает ID ключа из attested credential data.
+// Публичный ключ из authenticatorData (COSE) не разбирается: браузер отдает его в SPKI
+// через AuthenticatorAttestationResponse.getPublicKey().
+func parseAuthData(raw []byte, attested bool) (*authData, error) {
+	if len(raw) < authDataMinLength {
+		return nil, fmt.Errorf("%w: authenticator data is too short", ErrVerification)
+	}
+
+	ad := &authData{
+		rpIDHash:  raw[:32],
+		flags:     raw[32],
+		signCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+
+	if !attested {
+		return ad, nil
+	}
+
+	if ad.flags&flagAttested == 0 {
+		return nil, fmt.Errorf("%w: attested credential data is missing", ErrVerification)
+	}
+
+	// aaguid (16) + длина ID ключа (2) + ID ключа
+	rest := raw[authDataMinLength:]
+	if len(rest) < 18 {
+		return nil, fmt.Errorf("%w: attested credential data is too short", ErrVerification)
+	}
+
+	idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	if len(rest) < 18+idLength {
+		return nil, fmt.Errorf("%w: credential id is truncated", ErrVerification)
+	}
+
+	ad.credentialID = rest[18 : 18+idLength]
+
+	return ad, nil
+}
+
+// checkAuthData проверяет, что данные подписаны для нашего RP и пользователь подтвердил операцию.
+func (s *Service) checkAuthData(ad *authData) error {
+	rpIDHash := sha256.Sum256([]byte(s.rpID))
+	if !bytes.Equal(ad.rpIDHash, rpIDHash[:]) {
+		return fmt.Errorf("%w: rp id hash mismatch", ErrVerification)
+	}
+
+	if ad.flags&flagUserPresent == 0 {
+		return fmt.Errorf("%w: user is not present", ErrVerification)
+	}
+
+	if s.userVerification == UserVerificationRequired && ad.flags&flagUserVerified == 0 {
+		return fmt.Errorf("%w: user is not verified", ErrVerification)
+	}
+
+	return nil
+}
+
+// parsePublicKey разбирает публичный ключ в SPKI и проверяет, что он соответствует алгоритму.
+func parsePublicKey(der []byte, alg int) (crypto.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid public key", ErrVerification)
+	}
+
+	var ok bool
+
+	switch alg {
+	case AlgES256:
+		var ec *ecdsa.PublicKey
+
+		ec, ok = key.(*ecdsa.PublicKey)
+		ok = ok && ec.Curve.Params().Name == "P-256"
+	case AlgEdDSA:
+		_, ok = key.(ed25519.PublicKey)
+	case AlgRS256:
+		_, ok = key.(*rsa.PublicKey)
+	default:
+		return nil, fmt.Errorf("%w: unsupported algorithm %d", ErrVerification, alg)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%w: public key does not match algorithm %d", ErrVerification, alg)
+	}
+
+	return key, nil
+}
+
+// verifySignature проверяет подпись authenticatorData || sha256(clientDataJSON).
+func verifySignature(key crypto.PublicKey, alg int, authenticatorData, clientDataJSON, signature []byte) error {
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := slices.Concat(authenticatorData, clientDataHash[:])
+
+	var ok bool
+
+	switch alg {
+	case AlgES256:
+		digest := sha256.Sum256(signed)
+		ok = ecdsa.VerifyASN1(key.(*ecdsa.PublicKey), digest[:], signature)
+	case AlgEdDSA:
+		ok = ed25519.Verify(key.(ed25519.PublicKey), signed, signature)
+	case AlgRS256:
+		digest := sha256.Sum256(signed)
+		ok = rsa.VerifyPKCS1v15(key.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+	}
+
+	if !ok {
+		return fmt.Errorf("%w: invalid signature", ErrVerification)
+	}
+
+	return nil
+}
@@ -0,0 +1,668 @@
+// Package webauthn реализует вход по passkey (WebAuthn) для веб-интерфейса бота.
+// Ключи регистрируются пользователем, который уже вошел, и хранятся в Redis.
+// Сервис не разбирает CBOR: публичный ключ браузер передает в SPKI (getPublicKey()),
+// а authenticatorData - отдельно (getAuthenticatorData()). Поэтому attestation statement
+// не проверяется и поддерживается только attestation "none".
+package webauthn
+
+import (
+	"auth-service/internal/service/token"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	keyPrefix = "auth:webauthn:"
+
+	challengeLength = 32
+
+	// DefaultTimeout - сколько действует challenge.
+	DefaultTimeout = 5 * time.Minute
+	// DefaultTokenTTL - время жизни токена, выпущенного после входа по ключу.
+	DefaultTokenTTL = time.Hour
+
+	// AttestationNone - attestation не запрашивается.
+	AttestationNone = "none"
+
+	// UserVerificationRequired - аутентификатор обязан проверить пользователя (PIN, биометрия).
+	UserVerificationRequired = "required"
+	// UserVerificationPreferred - проверка пользователя желательна, но не обязательна.
+	UserVerificationPreferred = "preferred"
+
+	typeCreate = "webauthn.create"
+	typeGet    = "webauthn.get"
+
+	purposeRegistration = "registration"
+	purposeLogin        = "login"
+)
+
+var (
+	// ErrVerification - ответ браузера не прошел проверку.
+	ErrVerification = errors.New("webauthn verification failed")
+	// ErrChallenge - challenge не найден, истек или выдан для другой операции.
+	ErrChallenge = errors.New("invalid or expired challenge")
+	// ErrUnknownCredential - ключ не зарегистрирован.
+	ErrUnknownCredential = errors.New("unknown credential")
+	// ErrCredentialExists - ключ уже зарегистрирован.
+	ErrCredentialExists = errors.New("credential is already registered")
+	// ErrCloned - счетчик подписей не вырос: возможно, ключ скопирован.
+	ErrCloned = errors.New("credential sign counter did not increase")
+	// ErrInvalidArgument - не заполнены обязательные параметры.
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+//go:generate mockgen -source=webauthn.go -destination=mocks/webauthn_mock.go -package=mocks
+type tokenIssuer interface {
+	Issue(ctx context.Context, req token.IssueRequest) (string, *token.Claims, error)
+}
+
+// Bytes - бинарные данные, в JSON передаются в base64url без дополнения, как в WebAuthn.
+type Bytes []byte
+
+// MarshalJSON кодирует данные в base64url.
+func (b Bytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// UnmarshalJSON декодирует данные из base64url.
+func (b *Bytes) UnmarshalJSON(data []byte) error {
+	var s string
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("invalid base64url: %w", err)
+	}
+
+	*b = decoded
+
+	return nil
+}
+
+// RelyingParty - сайт, для которого регистрируются ключи.
+type RelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// User - пользователь, которому регистрируется ключ.
+type User struct {
+	ID          Bytes  `json:"id" swaggertype:"string"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// CredentialParameter - допустимый алгоритм ключа.
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+// CredentialDescriptor - ссылка на зарегистрированный ключ.
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         Bytes    `json:"id" swaggertype:"string"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+// AuthenticatorSelection - требования к аутентификатору.
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// CreationOptions - параметры navigator.credentials.create({publicKey}).
+type CreationOptions struct {
+	Challenge              Bytes                  `json:"challenge" swaggertype:"string"`
+	RP                     RelyingParty           `json:"rp"`
+	User                   User                   `json:"user"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	Attestation            string                 `json:"attestation"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials,omitempty"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+}
+
+// RequestOptions - параметры navigator.credentials.get({publicKey}).
+type RequestOptions struct {
+	Challenge        Bytes                  `json:"challenge" swaggertype:"string"`
+	RPID             string                 `json:"rpId"`
+	Timeout          int64                  `json:"timeout"`
+	UserVerification string                 `json:"userVerification"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials,omitempty"`
+}
+
+// RegistrationResponse - ответ браузера на create(). Поля берутся из AuthenticatorAttestationResponse:
+// getAuthenticatorData(), getPublicKey() и getPublicKeyAlgorithm().
+type RegistrationResponse struct {
+	ID                 Bytes    `json:"id" swaggertype:"string"`
+	ClientDataJSON     Bytes    `json:"clientDataJSON" swaggertype:"string"`
+	AuthenticatorData  Bytes    `json:"authenticatorData" swaggertype:"string"`
+	PublicKey          Bytes    `json:"publicKey" swaggertype:"string"`
+	PublicKeyAlgorithm int      `json:"publicKeyAlgorithm"`
+	Transports         []string `json:"transports,omitempty"`
+	Name               string   `json:"name,omitempty"` // название ключа для пользователя, например "MacBook"
+}
+
+// AssertionResponse - ответ браузера на get().
+type AssertionResponse struct {
+	ID                Bytes `json:"id" swaggertype:"string"`
+	ClientDataJSON    Bytes `json:"clientDataJSON" swaggertype:"string"`
+	AuthenticatorData Bytes `json:"authenticatorData" swaggertype:"string"`
+	Signature         Bytes `json:"signature" swaggertype:"string"`
+}
+
+// Credential - зарегистрированный ключ.
+type Credential struct {
+	ID         Bytes     `json:"id" swaggertype:"string"`
+	Subject    string    `json:"subject"`
+	Name       string    `json:"name,omitempty"`
+	Algorithm  int       `json:"algorithm"`
+	Transports []string  `json:"transports,omitempty"`
+	SignCount  uint32    `json:"sign_count"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	publicKey []byte
+}
+
+// Login - результат входа по ключу.
+type Login struct {
+	Token      string
+	Claims     *token.Claims
+	Credential *Credential
+}
+
+// Service - регистрация ключей и вход по ним.
+//
+// Ключи:
+//   - auth:webauthn:challenge:<challenge> - hash с назначением challenge и субъектом, TTL - timeout;
+//   - auth:webauthn:credential:<id> - hash с ключом;
+//   - auth:webauthn:user:<subject> - set ID ключей пользователя.
+type Service struct {
+	client redis.UniversalClient
+	issuer tokenIssuer
+
+	tokenTTL time.Duration
+	audience []string
+
+	rpID             string
+	rpName           string
+	origins          []string
+	timeout          time.Duration
+	userVerification string
+
+	now func() time.Time
+}
+
+// Option - опция для настройки Service.
+type Option func(*Service)
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(s *Service) {
+		s.client = client
+	}
+}
+
+// WithIssuer устанавливает выпуск токенов после входа.
+func WithIssuer(issuer tokenIssuer) Option {
+	return func(s *Service) {
+		s.issuer = issuer
+	}
+}
+
+// WithToken устанавливает время жизни и аудиторию токенов, выпускаемых после входа.
+func WithToken(ttl time.Duration, audience []string) Option {
+	return func(s *Service) {
+		s.tokenTTL = ttl
+		s.audience = audience
+	}
+}
+
+// WithRelyingParty устанавливает RP: id - домен сайта, name - название для пользователя,
+// origins - адреса страниц, с которых разрешены вызовы WebAuthn.
+func WithRelyingParty(id, name string, origins []string) Option {
+	return func(s *Service) {
+		s.rpID = id
+		s.rpName = name
+		s.origins = origins
+	}
+}
+
+// WithTimeout устанавливает, сколько действует challenge. По умолчанию DefaultTimeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *Service) {
+		s.timeout = timeout
+	}
+}
+
+// WithUserVerification устанавливает требование проверки пользователя: required или preferred.
+// По умолчанию preferred.
+func WithUserVerification(requirement string) Option {
+	return func(s *Service) {
+		s.userVerification = requirement
+	}
+}
+
+// New создает новый Service.
+func New(opts ...Option) (*Service, error) {
+	s := &Service{
+		timeout:          DefaultTimeout,
+		tokenTTL:         DefaultTokenTTL,
+		userVerification: UserVerificationPreferred,
+		now:              time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.client == nil {
+		return nil, errors.New("redis client is required")
+	}
+
+	if s.issuer == nil {
+		return nil, errors.New("issuer is required")
+	}
+
+	if s.tokenTTL <= 0 {
+		return nil, errors.New("token ttl must be positive")
+	}
+
+	if s.rpID == "" {
+		return nil, errors.New("relying party id is required")
+	}
+
+	if len(s.origins) == 0 {
+		return nil, errors.New("origins are required")
+	}
+
+	if s.timeout <= 0 {
+		return nil, errors.New("timeout must be positive")
+	}
+
+	if s.userVerification != UserVerificationRequired && s.userVerification != UserVerificationPreferred {
+		return nil, fmt.Errorf("unsupported user verification %q", s.userVerification)
+	}
+
+	if s.rpName == "" {
+		s.rpName = s.rpID
+	}
+
+	return s, nil
+}
+
+func challengeKey(challenge string) string {
+	return keyPrefix + "challenge:" + challenge
+}
+
+func credentialKey(id []byte) string {
+	return keyPrefix + "credential:" + base64.RawURLEncoding.EncodeToString(id)
+}
+
+func userKey(subject string) string {
+	return keyPrefix + "user:" + subject
+}
+
+// BeginRegistration создает challenge для регистрации нового ключа пользователя.
+func (s *Service) BeginRegistration(ctx context.Context, subject string) (*CreationOptions, error) {
+	if subject == "" {
+		return nil, fmt.Errorf("%w: subject is required", ErrInvalidArgument)
+	}
+
+	existing, err := s.Credentials(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.newChallenge(ctx, purposeRegistration, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]CredentialParameter, 0, len(supportedAlgs))
+	for _, alg := range supportedAlgs {
+		params = append(params, CredentialParameter{Type: "public-key", Alg: alg})
+	}
+
+	return &CreationOptions{
+		Challenge:          challenge,
+		RP:                 RelyingParty{ID: s.rpID, Name: s.rpName},
+		User:               User{ID: Bytes(subject), Name: subject, DisplayName: subject},
+		PubKeyCredParams:   params,
+		Timeout:            s.timeout.Milliseconds(),
+		Attestation:        AttestationNone,
+		ExcludeCredentials: descriptors(existing),
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:      "preferred",
+			UserVerification: s.userVerification,
+		},
+	}, nil
+}
+
+// FinishRegistration проверяет ответ браузера и сохраняет ключ пользователя.
+func (s *Service) FinishRegistration(ctx context.Context, subject string, resp RegistrationResponse) (*Credential, error) {
+	if subject == "" || len(resp.ID) == 0 {
+		return nil, fmt.Errorf("%w: subject and credential id are required", ErrInvalidArgument)
+	}
+
+	cd, err := s.parseClientData(resp.ClientDataJSON, typeCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.consumeChallenge(ctx, cd.Challenge, purposeRegistration, subject); err != nil {
+		return nil, err
+	}
+
+	ad, err := parseAuthData(resp.AuthenticatorData, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAuthData(ad); err != nil {
+		return nil, err
+	}
+
+	if !slices.Equal(ad.credentialID, resp.ID) {
+		return nil, fmt.Errorf("%w: credential id mismatch", ErrVerification)
+	}
+
+	if _, err := parsePublicKey(resp.PublicKey, resp.PublicKeyAlgorithm); err != nil {
+		return nil, err
+	}
+
+	cred := &Credential{
+		ID:         resp.ID,
+		Subject:    subject,
+		Name:       resp.Name,
+		Algorithm:  resp.PublicKeyAlgorithm,
+		Transports: resp.Transports,
+		SignCount:  ad.signCount,
+		CreatedAt:  s.now().UTC().Truncate(time.Second),
+		publicKey:  resp.PublicKey,
+	}
+
+	if err := s.save(ctx, cred); err != nil {
+		return nil, err
+	}
+
+	return cred, nil
+}
+
+// BeginLogin создает challenge для входа. Если subject не указан, браузер предложит
+// ключи, сохраненные на устройстве (discoverable credentials).
+func (s *Service) BeginLogin(ctx context.Context, subject string) (*RequestOptions, error) {
+	var allowed []*Credential
+
+	if subject != "" {
+		creds, err := s.Credentials(ctx, subject)
+		if err != nil {
+			return nil, err
+		}
+
+		allowed = creds
+	}
+
+	challenge, err := s.newChallenge(ctx, purposeLogin, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &RequestOptions{
+		Challenge:        challenge,
+		RPID:             s.rpID,
+		Timeout:          s.timeout.Milliseconds(),
+		UserVerification: s.userVerification,
+		AllowCredentials: descriptors(allowed),
+	}, nil
+}
+
+// FinishLogin проверяет подпись ключа и возвращает ключ, по которому выполнен вход.
+func (s *Service) FinishLogin(ctx context.Context, resp AssertionResponse) (*Credential, error) {
+	if len(resp.ID) == 0 {
+		return nil, fmt.Errorf("%w: credential id is required", ErrInvalidArgument)
+	}
+
+	cd, err := s.parseClientData(resp.ClientDataJSON, typeGet)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.consumeChallenge(ctx, cd.Challenge, purposeLogin, ""); err != nil {
+		return nil, err
+	}
+
+	cred, err := s.credential(ctx, resp.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ad, err := parseAuthData(resp.AuthenticatorData, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAuthData(ad); err != nil {
+		return nil, err
+	}
+
+	key, err := parsePublicKey(cred.publicKey, cred.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifySignature(key, cred.Algorithm, resp.AuthenticatorData, resp.ClientDataJSON, resp.Signature); err != nil {
+		return nil, err
+	}
+
+	// аутентификаторы без счетчика всегда присылают 0
+	if (ad.signCount != 0 || cred.SignCount != 0) && ad.signCount <= cred.SignCount {
+		return nil, ErrCloned
+	}
+
+	cred.SignCount = ad.signCount
+
+	if err := s.client.HSet(ctx, credentialKey(cred.ID), "sign_count", cred.SignCount).Err(); err != nil {
+		return nil, fmt.Errorf("webauthn: error update sign count: %w", err)
+	}
+
+	return cred, nil
+}
+
+// Login проверяет подпись ключа и выпускает токен его владельцу.
+func (s *Service) Login(ctx context.Context, resp AssertionResponse) (*Login, error) {
+	cred, err := s.FinishLogin(ctx, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, claims, err := s.issuer.Issue(ctx, token.IssueRequest{
+		Subject:  cred.Subject,
+		Audience: s.audience,
+		TTL:      s.tokenTTL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("webauthn: error issue token: %w", err)
+	}
+
+	return &Login{Token: raw, Claims: claims, Credential: cred}, nil
+}
+
+// Credentials возвращает ключи пользователя.
+func (s *Service) Credentials(ctx context.Context, subject string) ([]*Credential, error) {
+	ids, err := s.client.SMembers(ctx, userKey(subject)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("webauthn: error get credentials: %w", err)
+	}
+
+	slices.Sort(ids)
+
+	creds := make([]*Credential, 0, len(ids))
+
+	for _, encoded := range ids {
+		id, err := base64.RawURLEncoding.DecodeString(encoded)
+		if err != nil {
+			continue
+		}
+
+		cred, err := s.credential(ctx, id)
+		if errors.Is(err, ErrUnknownCredential) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		creds = append(creds, cred)
+	}
+
+	return creds, nil
+}
+
+// credential читает ключ по ID.
+func (s *Service) credential(ctx context.Context, id []byte) (*Credential, error) {
+	data, err := s.client.HGetAll(ctx, credentialKey(id)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("webauthn: error get credential: %w", err)
+	}
+
+	if len(data) == 0 {
+		return nil, ErrUnknownCredential
+	}
+
+	publicKey, err := base64.RawURLEncoding.DecodeString(data["public_key"])
+	if err != nil {
+		return nil, fmt.Errorf("webauthn: invalid stored public key: %w", err)
+	}
+
+	alg, _ := strconv.Atoi(data["algorithm"])
+	signCount, _ := strconv.ParseUint(data["sign_count"], 10, 32)
+	createdAt, _ := strconv.ParseInt(data["created_at"], 10, 64)
+
+	cred := &Credential{
+		ID:        id,
+		Subject:   data["subject"],
+		Name:      data["name"],
+		Algorithm: alg,
+		SignCount: uint32(signCount),
+		CreatedAt: time.Unix(createdAt, 0).UTC(),
+		publicKey: publicKey,
+	}
+
+	if raw := data["transports"]; raw != "" {
+		_ = json.Unmarshal([]byte(raw), &cred.Transports)
+	}
+
+	return cred, nil
+}
+
+// save сохраняет новый ключ. Ключ с тем же ID не перезаписывается.
+func (s *Service) save(ctx context.Context, cred *Credential) error {
+	transports, err := json.Marshal(cred.Transports)
+	if err != nil {
+		return fmt.Errorf("webauthn: error marshal transports: %w", err)
+	}
+
+	created, err := s.client.HSetNX(ctx, credentialKey(cred.ID), "subject", cred.Subject).Result()
+	if err != nil {
+		return fmt.Errorf("webauthn: error save credential: %w", err)
+	}
+
+	if !created {
+		return ErrCredentialExists
+	}
+
+	// ключ пользователя и запись ключа могут лежать в разных слотах кластера
+	_, err = s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, credentialKey(cred.ID),
+			"name", cred.Name,
+			"public_key", base64.RawURLEncoding.EncodeToString(cred.publicKey),
+			"algorithm", cred.Algorithm,
+			"transports", string(transports),
+			"sign_count", cred.SignCount,
+			"created_at", cred.CreatedAt.Unix(),
+		)
+		p.SAdd(ctx, userKey(cred.Subject), base64.RawURLEncoding.EncodeToString(cred.ID))
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("webauthn: error save credential: %w", err)
+	}
+
+	return nil
+}
+
+// newChallenge создает одноразовый challenge для операции.
+func (s *Service) newChallenge(ctx context.Context, purpose, subject string) (Bytes, error) {
+	challenge := make([]byte, challengeLength)
+
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, fmt.Errorf("webauthn: error generate challenge: %w", err)
+	}
+
+	key := challengeKey(base64.RawURLEncoding.EncodeToString(challenge))
+
+	_, err := s.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, key, "purpose", purpose, "subject", subject)
+		p.Expire(ctx, key, s.timeout)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("webauthn: error save challenge: %w", err)
+	}
+
+	return challenge, nil
+}
+
+// consumeChallenge удаляет challenge и проверяет, что он выдан для этой операции и субъекта.
+func (s *Service) consumeChallenge(ctx context.Context, challenge, purpose, subject string) error {
+	key := challengeKey(challenge)
+
+	var data *redis.MapStringStringCmd
+
+	_, err := s.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		data = p.HGetAll(ctx, key)
+		p.Del(ctx, key)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("webauthn: error get challenge: %w", err)
+	}
+
+	values := data.Val()
+	if values["purpose"] != purpose || values["subject"] != subject {
+		return ErrChallenge
+	}
+
+	return nil
+}
+
+func descriptors(creds []*Credential) []CredentialDescriptor {
+	if len(creds) == 0 {
+		return nil
+	}
+
+	res := make([]CredentialDescriptor, 0, len(creds))
+	for _, c := range creds {
+		res = append(res, CredentialDescriptor{Type: "public-key", ID: c.ID, Transports: c.Transports})
+	}
+
+	return res
+}
@@ -0,0 +1,503 @@
+package webauthn
+
+import (
+	"auth-service/internal/service/token"
+	"auth-service/internal/service/webauthn/mocks"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRPID   = "zanuda.example"
+	testOrigin = "https://zanuda.example"
+)
+
+func newService(t *testing.T, opts ...Option) (*Service, *mocks.MocktokenIssuer, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	issuer := mocks.NewMocktokenIssuer(gomock.NewController(t))
+
+	s, err := New(append([]Option{
+		WithClient(client),
+		WithIssuer(issuer),
+		WithRelyingParty(testRPID, "Zanuda", []string{testOrigin}),
+	}, opts...)...)
+	require.NoError(t, err)
+
+	return s, issuer, mr
+}
+
+// authenticator - программный аутентификатор для тестов.
+type authenticator struct {
+	t         *testing.T
+	id        []byte
+	alg       int
+	signer    crypto.Signer
+	signCount uint32
+	flags     byte
+	rpID      string
+	origin    string
+}
+
+func newAuthenticator(t *testing.T, alg int) *authenticator {
+	t.Helper()
+
+	var (
+		signer crypto.Signer
+		err    error
+	)
+
+	switch alg {
+	case AlgES256:
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	case AlgRS256:
+		signer, err = rsa.GenerateKey(rand.Reader, 2048)
+	}
+
+	require.NoError(t, err)
+
+	id := make([]byte, 16)
+	_, err = rand.Read(id)
+	require.NoError(t, err)
+
+	return &authenticator{
+		t:      t,
+		id:     id,
+		alg:    alg,
+		signer: signer,
+		flags:  flagUserPresent | flagUserVerified,
+		rpID:   testRPID,
+		origin: testOrigin,
+	}
+}
+
+func (a *authenticator) clientData(typ string, challenge []byte) []byte {
+	raw, err := json.Marshal(clientData{
+		Type:      typ,
+		Challenge: base64.RawURLEncoding.EncodeToString(challenge),
+		Origin:    a.origin,
+	})
+	require.NoError(a.t, err)
+
+	return raw
+}
+
+func (a *authenticator) authData(attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+	flags := a.flags
+
+	if attested {
+		flags |= flagAttested
+	}
+
+	data := slices.Concat(rpIDHash[:], []byte{flags}, binary.BigEndian.AppendUint32(nil, a.signCount))
+
+	if attested {
+		data = slices.Concat(data, make([]byte, 16), binary.BigEndian.AppendUint16(nil, uint16(len(a.id))), a.id)
+	}
+
+	return data
+}
+
+func (a *authenticator) register(options *CreationOptions) RegistrationResponse {
+	publicKey, err := x509.MarshalPKIXPublicKey(a.signer.Public())
+	require.NoError(a.t, err)
+
+	return RegistrationResponse{
+		ID:                 a.id,
+		ClientDataJSON:     a.clientData(typeCreate, options.Challenge),
+		AuthenticatorData:  a.authData(true),
+		PublicKey:          publicKey,
+		PublicKeyAlgorithm: a.alg,
+		Transports:         []string{"internal"},
+		Name:               "laptop",
+	}
+}
+
+func (a *authenticator) assert(options *RequestOptions) AssertionResponse {
+	a.signCount++
+
+	clientDataJSON := a.clientData(typeGet, options.Challenge)
+	authenticatorData := a.authData(false)
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := slices.Concat(authenticatorData, clientDataHash[:])
+
+	var (
+		signature []byte
+		err       error
+	)
+
+	if a.alg == AlgEdDSA {
+		signature, err = a.signer.Sign(rand.Reader, signed, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(signed)
+		signature, err = a.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+
+	require.NoError(a.t, err)
+
+	return AssertionResponse{
+		ID:                a.id,
+		ClientDataJSON:    clientDataJSON,
+		AuthenticatorData: authenticatorData,
+		Signature:         signature,
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	t.Cleanup(func() { _ = client.Close() })
+
+	rp := WithRelyingParty(testRPID, "Zanuda", []string{testOrigin})
+	issuer := WithIssuer(mocks.NewMocktokenIssuer(gomock.NewController(t)))
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			opts:    []Option{WithClient(client), issuer, rp},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: all options",
+			opts: []Option{
+				WithClient(client), issuer, rp,
+				WithToken(time.Hour, []string{"dashboard"}),
+				WithTimeout(time.Minute),
+				WithUserVerification(UserVerificationRequired),
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "error case: client is nil",
+			opts:    []Option{issuer, rp},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: rp id is empty",
+			opts:    []Option{WithClient(client), issuer, WithRelyingParty("", "", []string{testOrigin})},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: origins are empty",
+			opts:    []Option{WithClient(client), issuer, WithRelyingParty(testRPID, "", nil)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: issuer is nil",
+			opts:    []Option{WithClient(client), rp},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: zero token ttl",
+			opts:    []Option{WithClient(client), issuer, rp, WithToken(0, nil)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: zero timeout",
+			opts:    []Option{WithClient(client), issuer, rp, WithTimeout(0)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: unsupported user verification",
+			opts:    []Option{WithClient(client), issuer, rp, WithUserVerification("discouraged")},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(tt.opts...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestService(t *testing.T) {
+	t.Parallel()
+
+	for _, alg := range supportedAlgs {
+		t.Run(algName(alg), func(t *testing.T) {
+			t.Parallel()
+
+			s, issuer, mr := newService(t, WithToken(30*time.Minute, []string{"dashboard"}))
+			a := newAuthenticator(t, alg)
+
+			creation, err := s.BeginRegistration(t.Context(), "user-1")
+			require.NoError(t, err)
+			assert.Equal(t, testRPID, creation.RP.ID)
+			assert.Equal(t, AttestationNone, creation.Attestation)
+			assert.Equal(t, DefaultTimeout.Milliseconds(), creation.Timeout)
+			assert.Empty(t, creation.ExcludeCredentials)
+
+			cred, err := s.FinishRegistration(t.Context(), "user-1", a.register(creation))
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", cred.Subject)
+			assert.Equal(t, "laptop", cred.Name)
+
+			// challenge одноразовый
+			_, err = s.FinishRegistration(t.Context(), "user-1", a.register(creation))
+			require.ErrorIs(t, err, ErrChallenge)
+
+			// повторная регистрация того же ключа
+			creation, err = s.BeginRegistration(t.Context(), "user-1")
+			require.NoError(t, err)
+			require.Len(t, creation.ExcludeCredentials, 1)
+			assert.Equal(t, a.id, []byte(creation.ExcludeCredentials[0].ID))
+
+			_, err = s.FinishRegistration(t.Context(), "user-1", a.register(creation))
+			require.ErrorIs(t, err, ErrCredentialExists)
+
+			request, err := s.BeginLogin(t.Context(), "user-1")
+			require.NoError(t, err)
+			require.Len(t, request.AllowCredentials, 1)
+
+			got, err := s.FinishLogin(t.Context(), a.assert(request))
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", got.Subject)
+			assert.Equal(t, uint32(1), got.SignCount)
+
+			// вход без subject: discoverable credential
+			request, err = s.BeginLogin(t.Context(), "")
+			require.NoError(t, err)
+			assert.Empty(t, request.AllowCredentials)
+
+			issuer.EXPECT().Issue(gomock.Any(), token.IssueRequest{
+				Subject:  "user-1",
+				Audience: []string{"dashboard"},
+				TTL:      30 * time.Minute,
+			}).Return("jwt", &token.Claims{Subject: "user-1"}, nil)
+
+			login, err := s.Login(t.Context(), a.assert(request))
+			require.NoError(t, err)
+			assert.Equal(t, "jwt", login.Token)
+			assert.Equal(t, "user-1", login.Credential.Subject)
+
+			// счетчик не вырос - ключ мог быть скопирован
+			a.signCount = 0
+
+			request, err = s.BeginLogin(t.Context(), "")
+			require.NoError(t, err)
+
+			_, err = s.FinishLogin(t.Context(), a.assert(request))
+			require.ErrorIs(t, err, ErrCloned)
+
+			// использованные challenge удалены
+			for _, key := range mr.Keys() {
+				assert.NotContains(t, key, keyPrefix+"challenge:")
+			}
+		})
+	}
+}
+
+func algName(alg int) string {
+	switch alg {
+	case AlgES256:
+		return "ES256"
+	case AlgEdDSA:
+		return "EdDSA"
+	default:
+		return "RS256"
+	}
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestFinishRegistration_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		subject string
+		opts    []Option
+		mutate  func(a *authenticator, resp *RegistrationResponse)
+		wantErr error
+	}{
+		{
+			name:    "another subject",
+			subject: "user-2",
+			wantErr: ErrChallenge,
+		},
+		{
+			name:    "wrong origin",
+			subject: "user-1",
+			mutate: func(a *authenticator, resp *RegistrationResponse) {
+				a.origin = "https://evil.example"
+				resp.ClientDataJSON = a.clientData(typeCreate, mustChallenge(t, resp.ClientDataJSON))
+			},
+			wantErr: ErrVerification,
+		},
+		{
+			name:    "wrong type",
+			subject: "user-1",
+			mutate: func(a *authenticator, resp *RegistrationResponse) {
+				resp.ClientDataJSON = a.clientData(typeGet, mustChallenge(t, resp.ClientDataJSON))
+			},
+			wantErr: ErrVerification,
+		},
+		{
+			name:    "wrong rp id",
+			subject: "user-1",
+			mutate: func(a *authenticator, resp *RegistrationResponse) {
+				a.rpID = "evil.example"
+				resp.AuthenticatorData = a.authData(true)
+			},
+			wantErr: ErrVerification,
+		},
+		{
+			name:    "user is not verified",
+			subject: "user-1",
+			opts:    []Option{WithUserVerification(UserVerificationRequired)},
+			mutate: func(a *authenticator, resp *RegistrationResponse) {
+				a.flags = flagUserPresent
+				resp.AuthenticatorData = a.authData(true)
+			},
+			wantErr: ErrVerification,
+		},
+		{
+			name:    "credential id mismatch",
+			subject: "user-1",
+			mutate: func(_ *authenticator, resp *RegistrationResponse) {
+				resp.ID = []byte("another")
+			},
+			wantErr: ErrVerification,
+		},
+		{
+			name:    "algorithm mismatch",
+			subject: "user-1",
+			mutate: func(_ *authenticator, resp *RegistrationResponse) {
+				resp.PublicKeyAlgorithm = AlgRS256
+			},
+			wantErr: ErrVerification,
+		},
+		{
+			name:    "truncated authenticator data",
+			subject: "user-1",
+			mutate: func(_ *authenticator, resp *RegistrationResponse) {
+				resp.AuthenticatorData = resp.AuthenticatorData[:40]
+			},
+			wantErr: ErrVerification,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, _, _ := newService(t, tt.opts...)
+			a := newAuthenticator(t, AlgES256)
+
+			creation, err := s.BeginRegistration(t.Context(), "user-1")
+			require.NoError(t, err)
+
+			resp := a.register(creation)
+
+			if tt.mutate != nil {
+				tt.mutate(a, &resp)
+			}
+
+			_, err = s.FinishRegistration(t.Context(), tt.subject, resp)
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestFinishLogin_Errors(t *testing.T) {
+	t.Parallel()
+
+	s, _, _ := newService(t)
+	a := newAuthenticator(t, AlgES256)
+
+	creation, err := s.BeginRegistration(t.Context(), "user-1")
+	require.NoError(t, err)
+
+	_, err = s.FinishRegistration(t.Context(), "user-1", a.register(creation))
+	require.NoError(t, err)
+
+	// подпись от другого ключа
+	request, err := s.BeginLogin(t.Context(), "")
+	require.NoError(t, err)
+
+	resp := a.assert(request)
+	resp.Signature = newAuthenticator(t, AlgES256).assert(request).Signature
+
+	_, err = s.FinishLogin(t.Context(), resp)
+	require.ErrorIs(t, err, ErrVerification)
+
+	// challenge регистрации не подходит для входа
+	creation, err = s.BeginRegistration(t.Context(), "user-1")
+	require.NoError(t, err)
+
+	_, err = s.FinishLogin(t.Context(), a.assert(&RequestOptions{Challenge: creation.Challenge}))
+	require.ErrorIs(t, err, ErrChallenge)
+
+	// неизвестный ключ
+	request, err = s.BeginLogin(t.Context(), "")
+	require.NoError(t, err)
+
+	_, err = s.FinishLogin(t.Context(), newAuthenticator(t, AlgES256).assert(request))
+	require.ErrorIs(t, err, ErrUnknownCredential)
+
+	_, err = s.FinishLogin(t.Context(), AssertionResponse{})
+	require.ErrorIs(t, err, ErrInvalidArgument)
+}
+
+func TestBytes_JSON(t *testing.T) {
+	t.Parallel()
+
+	raw, err := json.Marshal(Bytes{0xfb, 0xff})
+	require.NoError(t, err)
+	assert.JSONEq(t, `"-_8"`, string(raw))
+
+	var b Bytes
+
+	require.NoError(t, json.Unmarshal(raw, &b))
+	assert.Equal(t, Bytes{0xfb, 0xff}, b)
+
+	require.Error(t, json.Unmarshal([]byte(`"+/8="`), &b))
+}
+
+func mustChallenge(t *testing.T, clientDataJSON []byte) []byte {
+	t.Helper()
+
+	var cd clientData
+
+	require.NoError(t, json.Unmarshal(clientDataJSON, &cd))
+
+	challenge, err := base64.RawURLEncoding.DecodeString(cd.Challenge)
+	require.NoError(t, err)
+
+	return challenge
+}
@@ -45,6 +45,16 @@ func (c *client) Connect(ctx context.Context) error {
 	return c.cache.Ping(ctx).Err()
 }
 
+// Ping проверяет соединение с Redis в режиме single.
+func (c *client) Ping(ctx context.Context) error {
+	return c.cache.Ping(ctx).Err()
+}
+
+// Cmd возвращает клиент go-redis для выполнения команд в режиме single.
+func (c *client) Cmd() redis.UniversalClient {
+	return c.cache
+}
+
 // Close закрывает соединение с Redis в режиме single.
 func (c *client) Close(ctx context.Context) error {
 	logrus.WithFields(logrus.Fields{
@@ -26,13 +26,22 @@ func NewClusterClient(cfg *config.Redis) (*cluster, error) {
 	}).Info("creating cluster client for redis")
 
 	return &cluster{
-		cfg: cfg,
-		cache: redis.NewClusterClient(&redis.ClusterOptions{
-			Addrs: cfg.Addrs,
-		}),
+		cfg:   cfg,
+		cache: redis.NewClusterClient(clusterOptions(cfg)),
 	}, nil
 }
 
+// clusterOptions возвращает опции кластерного клиента. Незаданные перенаправления и паузы
+// между повторами берутся по умолчанию go-redis.
+func clusterOptions(cfg *config.Redis) *redis.ClusterOptions {
+	return &redis.ClusterOptions{
+		Addrs:           cfg.Addrs,
+		MaxRedirects:    cfg.Cluster.MaxRedirects,
+		MinRetryBackoff: cfg.Cluster.MinRetryBackoff,
+		MaxRetryBackoff: cfg.Cluster.MaxRetryBackoff,
+	}
+}
+
 // Connect соединяется с Redis в режиме cluster.
 func (c *cluster) Connect(ctx context.Context) error {
 	logrus.WithFields(logrus.Fields{
@@ -43,6 +52,16 @@ func (c *cluster) Connect(ctx context.Context) error {
 	return c.cache.Ping(ctx).Err()
 }
 
+// Ping проверяет соединение с Redis в режиме cluster.
+func (c *cluster) Ping(ctx context.Context) error {
+	return c.cache.Ping(ctx).Err()
+}
+
+// Cmd возвращает клиент go-redis для выполнения команд в режиме cluster.
+func (c *cluster) Cmd() redis.UniversalClient {
+	return c.cache
+}
+
 // Close закрывает соединение с Redis в режиме cluster.
 func (c *cluster) Close(ctx context.Context) error {
 	logrus.WithFields(logrus.Fields{
@@ -4,6 +4,7 @@ import (
 	"auth-service/internal/config"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
@@ -69,3 +70,26 @@ func TestNewCluster(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterOptions(t *testing.T) {
+	t.Parallel()
+
+	opts := clusterOptions(&config.Redis{
+		Addrs: []string{"localhost:7001", "localhost:7002"},
+		Cluster: config.RedisCluster{
+			MaxRedirects:    5,
+			MinRetryBackoff: 10 * time.Millisecond,
+			MaxRetryBackoff: time.Second,
+		},
+	})
+
+	assert.Equal(t, []string{"localhost:7001", "localhost:7002"}, opts.Addrs)
+	assert.Equal(t, 5, opts.MaxRedirects)
+	assert.Equal(t, 10*time.Millisecond, opts.MinRetryBackoff)
+	assert.Equal(t, time.Second, opts.MaxRetryBackoff)
+
+	// незаданные значения остаются нулевыми: go-redis подставит свои по умолчанию
+	opts = clusterOptions(&config.Redis{Addrs: []string{"localhost:7001"}})
+	assert.Zero(t, opts.MaxRedirects)
+	assert.Zero(t, opts.MinRetryBackoff)
+}
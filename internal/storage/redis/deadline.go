@@ -0,0 +1,180 @@
+package redis
+
+import (
+	"auth-service/internal/service/traceid"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultCommandTimeout - таймаут команды Redis, если в контексте вызывающего нет дедлайна.
+const DefaultCommandTimeout = 2 * time.Second
+
+// Виды ошибок команд Redis в метриках.
+const (
+	ErrorKindCanceled = "canceled" // контекст вызывающего отменен
+	ErrorKindTimeout  = "timeout"  // истек дедлайн контекста или сетевой таймаут
+	ErrorKindNetwork  = "network"  // соединение оборвано или недоступно
+	ErrorKindOther    = "other"    // ошибка, которую вернул сам Redis
+)
+
+// pipelineCommand - имя команды в метриках для пайплайнов и транзакций.
+const pipelineCommand = "pipeline"
+
+// commandBuckets - интервалы гистограммы времени выполнения команд: от 0.5ms до таймаута по умолчанию.
+var commandBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2}
+
+// DeadlineHook - хук go-redis, который ограничивает время выполнения команд, учитывает ошибки
+// в метриках по видам (отмена, таймаут, сетевая ошибка) и время выполнения с идентификатором
+// трассировки вызывающего запроса (exemplar).
+// Если в контексте вызывающего уже есть дедлайн, он не меняется.
+type DeadlineHook struct {
+	timeout  time.Duration
+	errors   *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+var _ redis.Hook = (*DeadlineHook)(nil)
+
+// NewDeadlineHook создает хук с таймаутом команд timeout (по умолчанию DefaultCommandTimeout)
+// и регистрирует метрики в registerer. Если метрики уже зарегистрированы, используются существующие.
+func NewDeadlineHook(timeout time.Duration, registerer prometheus.Registerer) (*DeadlineHook, error) {
+	if registerer == nil {
+		return nil, errors.New("registerer is required")
+	}
+
+	if timeout <= 0 {
+		timeout = DefaultCommandTimeout
+	}
+
+	counter, err := register(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "redis_command_errors_total",
+		Help: "Количество ошибок команд Redis по видам: canceled, timeout, network, other.",
+	}, []string{"command", "kind"}))
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := register(registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "redis_command_duration_seconds",
+		Help:    "Время выполнения команд Redis в секундах, для пайплайнов и транзакций - всего пайплайна.",
+		Buckets: commandBuckets,
+	}, []string{"command"}))
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeadlineHook{timeout: timeout, errors: counter, duration: duration}, nil
+}
+
+// register регистрирует метрику в registerer. Если метрика уже зарегистрирована, возвращает существующую.
+func register[T prometheus.Collector](registerer prometheus.Registerer, collector T) (T, error) {
+	err := registerer.Register(collector)
+	if err == nil {
+		return collector, nil
+	}
+
+	var already prometheus.AlreadyRegisteredError
+	if !errors.As(err, &already) {
+		return collector, err
+	}
+
+	existing, ok := already.ExistingCollector.(T)
+	if !ok {
+		return collector, err
+	}
+
+	return existing, nil
+}
+
+// DialHook не меняет установку соединения: таймаут подключения задается опциями клиента.
+func (h *DeadlineHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook выполняет команду с таймаутом и учитывает ошибку.
+func (h *DeadlineHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, cancel := h.withDeadline(ctx)
+		defer cancel()
+
+		start := time.Now()
+
+		err := next(ctx, cmd)
+		h.observe(ctx, cmd.Name(), time.Since(start), err)
+
+		return err
+	}
+}
+
+// ProcessPipelineHook выполняет пайплайн с таймаутом и учитывает ошибку.
+func (h *DeadlineHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, cancel := h.withDeadline(ctx)
+		defer cancel()
+
+		start := time.Now()
+
+		err := next(ctx, cmds)
+		h.observe(ctx, pipelineCommand, time.Since(start), err)
+
+		return err
+	}
+}
+
+func (h *DeadlineHook) withDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, h.timeout)
+}
+
+func (h *DeadlineHook) observe(ctx context.Context, command string, elapsed time.Duration, err error) {
+	traceid.Observe(ctx, h.duration.WithLabelValues(command), elapsed.Seconds())
+
+	kind := errorKind(err)
+	if kind == "" {
+		return
+	}
+
+	h.errors.WithLabelValues(command, kind).Inc()
+}
+
+// errorKind определяет вид ошибки команды. Для успешной команды и redis.Nil возвращает пустую строку.
+func errorKind(err error) string {
+	if err == nil || errors.Is(err, redis.Nil) {
+		return ""
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return ErrorKindCanceled
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorKindTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorKindTimeout
+		}
+
+		return ErrorKindNetwork
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, redis.ErrClosed) {
+		return ErrorKindNetwork
+	}
+
+	return ErrorKindOther
+}
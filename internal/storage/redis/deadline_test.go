@@ -0,0 +1,207 @@
+package redis
+
+import (
+	"auth-service/internal/service/traceid"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDeadlineHook(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewDeadlineHook(time.Second, nil)
+	require.Error(t, err)
+
+	registry := prometheus.NewRegistry()
+
+	first, err := NewDeadlineHook(0, registry)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultCommandTimeout, first.timeout)
+
+	// повторное создание использует уже зарегистрированные метрики
+	second, err := NewDeadlineHook(time.Second, registry)
+	require.NoError(t, err)
+	assert.Same(t, first.errors, second.errors)
+	assert.Same(t, first.duration, second.duration)
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestDeadlineHook_ProcessHook(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		ctx          func(t *testing.T) (context.Context, context.CancelFunc)
+		err          error
+		wantDeadline time.Duration
+		wantKind     string
+	}{
+		{
+			name: "positive case: default deadline",
+			ctx: func(t *testing.T) (context.Context, context.CancelFunc) {
+				t.Helper()
+				return t.Context(), func() {}
+			},
+			wantDeadline: 100 * time.Millisecond,
+		},
+		{
+			name: "positive case: caller deadline is kept",
+			ctx: func(t *testing.T) (context.Context, context.CancelFunc) {
+				t.Helper()
+				return context.WithTimeout(t.Context(), time.Hour)
+			},
+			wantDeadline: time.Hour,
+		},
+		{
+			name: "positive case: redis nil is not an error",
+			ctx: func(t *testing.T) (context.Context, context.CancelFunc) {
+				t.Helper()
+				return t.Context(), func() {}
+			},
+			err:          redis.Nil,
+			wantDeadline: 100 * time.Millisecond,
+		},
+		{
+			name: "error case: timeout",
+			ctx: func(t *testing.T) (context.Context, context.CancelFunc) {
+				t.Helper()
+				return t.Context(), func() {}
+			},
+			err:          context.DeadlineExceeded,
+			wantDeadline: 100 * time.Millisecond,
+			wantKind:     ErrorKindTimeout,
+		},
+		{
+			name: "error case: network",
+			ctx: func(t *testing.T) (context.Context, context.CancelFunc) {
+				t.Helper()
+				return t.Context(), func() {}
+			},
+			err:          &net.OpError{Op: "read", Err: errors.New("connection reset")},
+			wantDeadline: 100 * time.Millisecond,
+			wantKind:     ErrorKindNetwork,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			hook, err := NewDeadlineHook(100*time.Millisecond, prometheus.NewRegistry())
+			require.NoError(t, err)
+
+			ctx, cancel := tt.ctx(t)
+			defer cancel()
+
+			started := time.Now()
+			cmd := redis.NewStringCmd(ctx, "get", "key")
+
+			got := hook.ProcessHook(func(ctx context.Context, _ redis.Cmder) error {
+				deadline, ok := ctx.Deadline()
+				require.True(t, ok, "command context must have deadline")
+				assert.WithinDuration(t, started.Add(tt.wantDeadline), deadline, 50*time.Millisecond)
+
+				return tt.err
+			})(ctx, cmd)
+			require.ErrorIs(t, got, tt.err)
+
+			for _, kind := range []string{ErrorKindCanceled, ErrorKindTimeout, ErrorKindNetwork, ErrorKindOther} {
+				want := 0.0
+				if kind == tt.wantKind {
+					want = 1
+				}
+
+				assert.InDelta(t, want, testutil.ToFloat64(hook.errors.WithLabelValues("get", kind)), 0, kind)
+			}
+		})
+	}
+}
+
+func TestDeadlineHook_ProcessPipelineHook(t *testing.T) {
+	t.Parallel()
+
+	hook, err := NewDeadlineHook(time.Second, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	got := hook.ProcessPipelineHook(func(ctx context.Context, _ []redis.Cmder) error {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok, "pipeline context must have deadline")
+
+		return context.Canceled
+	})(t.Context(), nil)
+	require.ErrorIs(t, got, context.Canceled)
+
+	assert.InDelta(t, 1, testutil.ToFloat64(hook.errors.WithLabelValues(pipelineCommand, ErrorKindCanceled)), 0)
+}
+
+func TestDeadlineHook_Duration(t *testing.T) {
+	t.Parallel()
+
+	hook, err := NewDeadlineHook(time.Second, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	ctx := traceid.NewContext(t.Context(), "4bf92f3577b34da6a3ce929d0e0e4736")
+
+	require.NoError(t, hook.ProcessHook(func(context.Context, redis.Cmder) error {
+		return nil
+	})(ctx, redis.NewStringCmd(ctx, "get", "key")))
+
+	var m dto.Metric
+
+	observer, ok := hook.duration.WithLabelValues("get").(prometheus.Metric)
+	require.True(t, ok)
+	require.NoError(t, observer.Write(&m))
+
+	assert.Equal(t, uint64(1), m.GetHistogram().GetSampleCount())
+
+	var traces []string
+
+	for _, b := range m.GetHistogram().GetBucket() {
+		for _, l := range b.GetExemplar().GetLabel() {
+			traces = append(traces, l.GetValue())
+		}
+	}
+
+	assert.Equal(t, []string{"4bf92f3577b34da6a3ce929d0e0e4736"}, traces)
+}
+
+func TestErrorKind(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "nil", err: nil, want: ""},
+		{name: "redis nil", err: redis.Nil, want: ""},
+		{name: "canceled", err: fmt.Errorf("get: %w", context.Canceled), want: ErrorKindCanceled},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, want: ErrorKindTimeout},
+		{name: "net timeout", err: &net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}, want: ErrorKindTimeout},
+		{name: "net error", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, want: ErrorKindNetwork},
+		{name: "eof", err: io.EOF, want: ErrorKindNetwork},
+		{name: "client closed", err: redis.ErrClosed, want: ErrorKindNetwork},
+		{name: "redis error", err: errors.New("WRONGTYPE Operation against a key holding the wrong kind of value"), want: ErrorKindOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, errorKind(tt.err))
+		})
+	}
+}
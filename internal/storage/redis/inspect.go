@@ -0,0 +1,322 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// Пороги InspectHook по умолчанию.
+const (
+	DefaultSlowCommand   = 100 * time.Millisecond
+	DefaultBigPayload    = 64 << 10
+	DefaultBigCollection = 1000
+	DefaultLogInterval   = time.Minute
+)
+
+// Направления данных команды в метриках.
+const (
+	DirectionRequest  = "request"
+	DirectionResponse = "response"
+)
+
+// Проблемы команды в метриках и журнале.
+const (
+	problemSlow          = "slow"
+	problemBigPayload    = "big_payload"
+	problemBigCollection = "big_collection"
+)
+
+// collectionSizeCommands - команды, которые возвращают количество элементов коллекции.
+var collectionSizeCommands = map[string]struct{}{
+	"hlen": {}, "scard": {}, "zcard": {}, "llen": {}, "xlen": {},
+}
+
+// scriptCommands - команды, ключи которых идут после количества ключей: eval script numkeys key...
+var scriptCommands = map[string]struct{}{
+	"eval": {}, "evalsha": {}, "eval_ro": {}, "evalsha_ro": {}, "fcall": {}, "fcall_ro": {},
+}
+
+// payloadBuckets - интервалы гистограммы размеров данных команд: от 64B до 4MB.
+var payloadBuckets = prometheus.ExponentialBuckets(64, 4, 9)
+
+// Thresholds - пороги, после которых команда считается проблемной. Нулевое значение - порог по умолчанию.
+type Thresholds struct {
+	// SlowCommand - время выполнения медленной команды. По умолчанию DefaultSlowCommand.
+	SlowCommand time.Duration
+	// BigPayload - размер аргументов или ответа команды в байтах. По умолчанию DefaultBigPayload.
+	BigPayload int
+	// BigCollection - количество элементов коллекции в ответе (HGETALL, SMEMBERS) или в ответе
+	// команд размера коллекции (HLEN, SCARD). По умолчанию DefaultBigCollection.
+	BigCollection int
+	// LogInterval - как часто пишется предупреждение об одной проблеме одной команды. По умолчанию DefaultLogInterval.
+	LogInterval time.Duration
+}
+
+func (t Thresholds) withDefaults() Thresholds {
+	if t.SlowCommand == 0 {
+		t.SlowCommand = DefaultSlowCommand
+	}
+
+	if t.BigPayload == 0 {
+		t.BigPayload = DefaultBigPayload
+	}
+
+	if t.BigCollection == 0 {
+		t.BigCollection = DefaultBigCollection
+	}
+
+	if t.LogInterval == 0 {
+		t.LogInterval = DefaultLogInterval
+	}
+
+	return t
+}
+
+// InspectHook - хук go-redis, который измеряет размер аргументов и ответов команд и находит медленные
+// команды и большие ключи: строки и поля больше BigPayload, коллекции больше BigCollection элементов.
+// Проблемы учитываются в метриках и пишутся в журнал не чаще LogInterval на команду: рост записи
+// (например, хэша сессии) виден до того, как он станет проблемой самого Redis.
+type InspectHook struct {
+	thresholds Thresholds
+
+	payload  *prometheus.HistogramVec
+	problems *prometheus.CounterVec
+
+	mu     sync.Mutex
+	logged map[string]time.Time
+
+	now func() time.Time
+}
+
+var _ redis.Hook = (*InspectHook)(nil)
+
+// NewInspectHook создает хук с порогами thresholds и регистрирует метрики в registerer.
+// Если метрики уже зарегистрированы, используются существующие.
+func NewInspectHook(thresholds Thresholds, registerer prometheus.Registerer) (*InspectHook, error) {
+	if registerer == nil {
+		return nil, errors.New("registerer is required")
+	}
+
+	thresholds = thresholds.withDefaults()
+
+	if thresholds.SlowCommand < 0 || thresholds.BigPayload < 0 || thresholds.BigCollection < 0 || thresholds.LogInterval < 0 {
+		return nil, errors.New("thresholds must not be negative")
+	}
+
+	payload, err := register(registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "redis_command_payload_bytes",
+		Help:    "Размер аргументов (request) и ответов (response) команд Redis в байтах.",
+		Buckets: payloadBuckets,
+	}, []string{"command", "direction"}))
+	if err != nil {
+		return nil, err
+	}
+
+	problems, err := register(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "redis_command_problems_total",
+		Help: "Количество проблемных команд Redis: slow - дольше порога, big_payload - аргументы или ответ " +
+			"больше порога, big_collection - коллекция больше порога элементов.",
+	}, []string{"command", "problem"}))
+	if err != nil {
+		return nil, err
+	}
+
+	return &InspectHook{
+		thresholds: thresholds,
+		payload:    payload,
+		problems:   problems,
+		logged:     make(map[string]time.Time),
+		now:        time.Now,
+	}, nil
+}
+
+// DialHook не меняет установку соединения.
+func (h *InspectHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook измеряет время выполнения и размер данных команды.
+func (h *InspectHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+
+		err := next(ctx, cmd)
+
+		if elapsed := time.Since(start); elapsed > h.thresholds.SlowCommand {
+			h.report(cmd.Name(), problemSlow, logrus.Fields{"key": commandKey(cmd), "duration": elapsed})
+		}
+
+		h.inspect(cmd)
+
+		return err
+	}
+}
+
+// ProcessPipelineHook измеряет время выполнения пайплайна и размер данных каждой его команды.
+func (h *InspectHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+
+		err := next(ctx, cmds)
+
+		if elapsed := time.Since(start); elapsed > h.thresholds.SlowCommand {
+			h.report(pipelineCommand, problemSlow, logrus.Fields{"commands": len(cmds), "duration": elapsed})
+		}
+
+		for _, cmd := range cmds {
+			h.inspect(cmd)
+		}
+
+		return err
+	}
+}
+
+// inspect учитывает размер аргументов и ответа команды и сообщает о больших.
+func (h *InspectHook) inspect(cmd redis.Cmder) {
+	name := cmd.Name()
+
+	request := requestSize(cmd)
+	h.payload.WithLabelValues(name, DirectionRequest).Observe(float64(request))
+
+	if request > h.thresholds.BigPayload {
+		h.report(name, problemBigPayload, logrus.Fields{"key": commandKey(cmd), "direction": DirectionRequest, "bytes": request})
+	}
+
+	if cmd.Err() != nil {
+		return
+	}
+
+	if response, ok := responseSize(cmd); ok {
+		h.payload.WithLabelValues(name, DirectionResponse).Observe(float64(response))
+
+		if response > h.thresholds.BigPayload {
+			h.report(name, problemBigPayload, logrus.Fields{"key": commandKey(cmd), "direction": DirectionResponse, "bytes": response})
+		}
+	}
+
+	if elements, ok := collectionSize(cmd); ok && elements > h.thresholds.BigCollection {
+		h.report(name, problemBigCollection, logrus.Fields{"key": commandKey(cmd), "elements": elements})
+	}
+}
+
+// report учитывает проблему команды и пишет предупреждение, если о ней давно не сообщалось.
+func (h *InspectHook) report(command, problem string, fields logrus.Fields) {
+	h.problems.WithLabelValues(command, problem).Inc()
+
+	key := command + " " + problem
+	now := h.now()
+
+	h.mu.Lock()
+	if now.Sub(h.logged[key]) < h.thresholds.LogInterval {
+		h.mu.Unlock()
+
+		return
+	}
+
+	h.logged[key] = now
+	h.mu.Unlock()
+
+	fields["command"] = command
+	fields["problem"] = problem
+
+	logrus.WithFields(fields).Warn("redis command exceeds threshold")
+}
+
+// requestSize возвращает размер строковых аргументов команды в байтах без имени команды.
+func requestSize(cmd redis.Cmder) int {
+	args := cmd.Args()
+	if len(args) == 0 {
+		return 0
+	}
+
+	size := 0
+
+	for _, arg := range args[1:] {
+		switch v := arg.(type) {
+		case string:
+			size += len(v)
+		case []byte:
+			size += len(v)
+		}
+	}
+
+	return size
+}
+
+// responseSize возвращает размер ответа команды в байтах. false - размер ответа этого типа
+// не измеряется (например, ответ скрипта или число).
+func responseSize(cmd redis.Cmder) (int, bool) {
+	size := 0
+
+	switch c := cmd.(type) {
+	case *redis.StringCmd:
+		return len(c.Val()), true
+	case *redis.StringSliceCmd:
+		for _, v := range c.Val() {
+			size += len(v)
+		}
+
+		return size, true
+	case *redis.MapStringStringCmd:
+		for k, v := range c.Val() {
+			size += len(k) + len(v)
+		}
+
+		return size, true
+	case *redis.SliceCmd:
+		for _, v := range c.Val() {
+			if s, ok := v.(string); ok {
+				size += len(s)
+			}
+		}
+
+		return size, true
+	}
+
+	return 0, false
+}
+
+// collectionSize возвращает количество элементов коллекции в ответе команды. false - ответ не коллекция.
+func collectionSize(cmd redis.Cmder) (int, bool) {
+	switch c := cmd.(type) {
+	case *redis.StringSliceCmd:
+		return len(c.Val()), true
+	case *redis.MapStringStringCmd:
+		return len(c.Val()), true
+	case *redis.SliceCmd:
+		return len(c.Val()), true
+	case *redis.IntCmd:
+		if _, ok := collectionSizeCommands[c.Name()]; ok {
+			return int(c.Val()), true
+		}
+	}
+
+	return 0, false
+}
+
+// commandKey возвращает первый ключ команды для журнала или пустую строку, если ключа нет.
+func commandKey(cmd redis.Cmder) string {
+	args := cmd.Args()
+	pos := 1
+
+	if _, ok := scriptCommands[cmd.Name()]; ok {
+		if len(args) < 3 || fmt.Sprint(args[2]) == "0" {
+			return ""
+		}
+
+		pos = 3
+	}
+
+	if len(args) <= pos {
+		return ""
+	}
+
+	return fmt.Sprint(args[pos])
+}
@@ -0,0 +1,168 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInspectHook(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewInspectHook(Thresholds{}, nil)
+	require.Error(t, err)
+
+	_, err = NewInspectHook(Thresholds{BigPayload: -1}, prometheus.NewRegistry())
+	require.Error(t, err)
+
+	registry := prometheus.NewRegistry()
+
+	first, err := NewInspectHook(Thresholds{}, registry)
+	require.NoError(t, err)
+	assert.Equal(t, Thresholds{
+		SlowCommand:   DefaultSlowCommand,
+		BigPayload:    DefaultBigPayload,
+		BigCollection: DefaultBigCollection,
+		LogInterval:   DefaultLogInterval,
+	}, first.thresholds)
+
+	// повторное создание использует уже зарегистрированные метрики
+	second, err := NewInspectHook(Thresholds{}, registry)
+	require.NoError(t, err)
+	assert.Same(t, first.payload, second.payload)
+	assert.Same(t, first.problems, second.problems)
+}
+
+//nolint:paralleltest // перехватывает стандартный логгер logrus
+func TestInspectHook(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	hook, err := NewInspectHook(Thresholds{BigPayload: 100, BigCollection: 3, LogInterval: time.Minute}, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	hook.now = func() time.Time { return now }
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	client.AddHook(hook)
+
+	logs := test.NewGlobal()
+	t.Cleanup(logs.Reset)
+
+	ctx := t.Context()
+	problems := func(command, problem string) float64 {
+		return testutil.ToFloat64(hook.problems.WithLabelValues(command, problem))
+	}
+
+	// небольшие записи не проблемные
+	require.NoError(t, client.HSet(ctx, "session:1", "subject", "user-1").Err())
+	require.NoError(t, client.HGetAll(ctx, "session:1").Err())
+	assert.Empty(t, logs.AllEntries())
+
+	// большая запись: аргументы записи и ответ чтения
+	big := strings.Repeat("x", 200)
+
+	require.NoError(t, client.Set(ctx, "blob", big, 0).Err())
+	require.NoError(t, client.Get(ctx, "blob").Err())
+	assert.InDelta(t, 1, problems("set", problemBigPayload), 0)
+	assert.InDelta(t, 1, problems("get", problemBigPayload), 0)
+
+	entry := logs.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, logrus.WarnLevel, entry.Level)
+	assert.Equal(t, "blob", entry.Data["key"])
+	assert.Equal(t, DirectionResponse, entry.Data["direction"])
+
+	// хэш сессии вырос: видно и по HGETALL, и по HLEN
+	for i := range 5 {
+		require.NoError(t, client.HSet(ctx, "session:1", "device:"+strconv.Itoa(i), "phone").Err())
+	}
+
+	require.NoError(t, client.HGetAll(ctx, "session:1").Err())
+	require.NoError(t, client.HLen(ctx, "session:1").Err())
+	assert.InDelta(t, 1, problems("hgetall", problemBigCollection), 0)
+	assert.InDelta(t, 1, problems("hlen", problemBigCollection), 0)
+
+	// проблемы в пайплайне учитываются по командам
+	_, err = client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Get(ctx, "blob")
+		p.HGetAll(ctx, "session:1")
+
+		return nil
+	})
+	require.NoError(t, err)
+	assert.InDelta(t, 2, problems("get", problemBigPayload), 0)
+	assert.InDelta(t, 2, problems("hgetall", problemBigCollection), 0)
+
+	// повторная проблема учитывается, но не пишется в журнал до истечения интервала
+	logged := len(logs.AllEntries())
+
+	require.NoError(t, client.Get(ctx, "blob").Err())
+	assert.InDelta(t, 3, problems("get", problemBigPayload), 0)
+	assert.Len(t, logs.AllEntries(), logged)
+
+	now = now.Add(time.Minute)
+
+	require.NoError(t, client.Get(ctx, "blob").Err())
+	assert.Len(t, logs.AllEntries(), logged+1)
+}
+
+func TestInspectHook_Slow(t *testing.T) {
+	t.Parallel()
+
+	hook, err := NewInspectHook(Thresholds{SlowCommand: 10 * time.Millisecond}, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	cmd := redis.NewStringCmd(t.Context(), "get", "key")
+
+	require.NoError(t, hook.ProcessHook(func(context.Context, redis.Cmder) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})(t.Context(), cmd))
+
+	require.NoError(t, hook.ProcessPipelineHook(func(context.Context, []redis.Cmder) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})(t.Context(), []redis.Cmder{cmd}))
+
+	assert.InDelta(t, 1, testutil.ToFloat64(hook.problems.WithLabelValues("get", problemSlow)), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(hook.problems.WithLabelValues(pipelineCommand, problemSlow)), 0)
+}
+
+func TestCommandKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	tests := []struct {
+		name string
+		cmd  redis.Cmder
+		want string
+	}{
+		{name: "key command", cmd: redis.NewStringCmd(ctx, "get", "session:1"), want: "session:1"},
+		{name: "script with keys", cmd: redis.NewCmd(ctx, "evalsha", "sha", 1, "family:1", "arg"), want: "family:1"},
+		{name: "script without keys", cmd: redis.NewCmd(ctx, "evalsha", "sha", 0, "arg")},
+		{name: "no key", cmd: redis.NewStatusCmd(ctx, "ping")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, commandKey(tt.cmd))
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Stats - статистика пула соединений клиента. Для кластера - сумма по всем узлам.
+type Stats struct {
+	// Hits - сколько раз соединение взято из пула.
+	Hits uint32 `json:"hits"`
+	// Misses - сколько раз соединение пришлось создать.
+	Misses uint32 `json:"misses"`
+	// Timeouts - сколько раз не дождались свободного соединения.
+	Timeouts uint32 `json:"timeouts"`
+
+	TotalConns uint32 `json:"totalConns"`
+	IdleConns  uint32 `json:"idleConns"`
+	StaleConns uint32 `json:"staleConns"`
+}
+
+func statsOf(s *redis.PoolStats) Stats {
+	return Stats{
+		Hits:       s.Hits,
+		Misses:     s.Misses,
+		Timeouts:   s.Timeouts,
+		TotalConns: s.TotalConns,
+		IdleConns:  s.IdleConns,
+		StaleConns: s.StaleConns,
+	}
+}
+
+// Stats возвращает статистику пула соединений в режиме single.
+func (c *client) Stats() Stats {
+	return statsOf(c.cache.PoolStats())
+}
+
+// Stats возвращает статистику пула соединений всех узлов кластера.
+func (c *cluster) Stats() Stats {
+	return statsOf(c.cache.PoolStats())
+}
+
+// Info возвращает результат команды INFO в режиме single: адрес узла -> поле -> значение.
+// Без sections возвращаются секции по умолчанию.
+func (c *client) Info(ctx context.Context, sections ...string) (map[string]map[string]string, error) {
+	raw, err := c.cache.Info(ctx, sections...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]map[string]string{c.cache.Options().Addr: parseInfo(raw)}, nil
+}
+
+// Info возвращает результат команды INFO каждого мастера кластера: адрес узла -> поле -> значение.
+// Без sections возвращаются секции по умолчанию.
+func (c *cluster) Info(ctx context.Context, sections ...string) (map[string]map[string]string, error) {
+	var mu sync.Mutex
+
+	nodes := map[string]map[string]string{}
+
+	err := c.cache.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		raw, err := node.Info(ctx, sections...).Result()
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		nodes[node.Options().Addr] = parseInfo(raw)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+// parseInfo разбирает ответ INFO: строки "поле:значение", заголовки секций и пустые строки пропускаются.
+func parseInfo(raw string) map[string]string {
+	fields := map[string]string{}
+
+	scanner := bufio.NewScanner(strings.NewReader(raw))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			fields[key] = value
+		}
+	}
+
+	return fields
+}
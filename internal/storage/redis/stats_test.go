@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"auth-service/internal/config"
+	"strconv"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInfo(t *testing.T) {
+	t.Parallel()
+
+	raw := "# Server\r\nredis_version:7.2.4\r\nrole:master\r\n\r\n# Clients\r\nconnected_clients:3\r\nbroken line\r\n"
+
+	assert.Equal(t, map[string]string{
+		"redis_version":     "7.2.4",
+		"role":              "master",
+		"connected_clients": "3",
+	}, parseInfo(raw))
+}
+
+func TestClient_InfoStats(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+
+	c, err := NewSingleClient(&config.Redis{Type: config.RedisTypeSingle, Host: mr.Host(), Port: port})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = c.Close(t.Context()) })
+
+	require.NoError(t, c.Ping(t.Context()))
+	require.NoError(t, c.Ping(t.Context()))
+
+	info, err := c.Info(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{mr.Addr(): {"connected_clients": "1"}}, info)
+
+	stats := c.Stats()
+	assert.Equal(t, uint32(1), stats.Misses)
+	assert.Equal(t, uint32(2), stats.Hits)
+	assert.Equal(t, uint32(1), stats.TotalConns)
+	assert.Equal(t, uint32(1), stats.IdleConns)
+
+	mr.Close()
+
+	_, err = c.Info(t.Context())
+	require.Error(t, err)
+}
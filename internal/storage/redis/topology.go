@@ -0,0 +1,469 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// Деградация слотов TopologyHook по умолчанию.
+const (
+	DefaultSlotFailures = 5
+	DefaultSlotCooldown = 5 * time.Second
+)
+
+// slotCount - количество хэш-слотов Redis Cluster.
+const slotCount = 16384
+
+// Перенаправления в метриках.
+const (
+	RedirectMoved = "moved" // слот переехал на другой узел
+	RedirectAsk   = "ask"   // слот переезжает, ключ уже на другом узле
+)
+
+// Причины ошибок слота в метриках.
+const (
+	slotErrorClusterDown = "clusterdown" // кластер или узел слота недоступен
+	slotErrorTryAgain    = "tryagain"    // ключи команды разъехались по узлам во время решардинга
+	slotErrorRedirect    = "redirect"    // перенаправления исчерпаны, топология еще не сошлась
+	slotErrorNetwork     = "network"     // узел слота недоступен по сети или не ответил вовремя
+)
+
+// keylessCommands - команды без ключа, в том числе те, которые go-redis отправляет при установке
+// соединения (HELLO, CLIENT SETINFO): первый аргумент у них не ключ.
+var keylessCommands = map[string]struct{}{
+	"asking": {}, "auth": {}, "client": {}, "cluster": {}, "command": {}, "config": {}, "dbsize": {},
+	"discard": {}, "echo": {}, "exec": {}, "function": {}, "hello": {}, "info": {}, "keys": {},
+	"memory": {}, "multi": {}, "ping": {}, "publish": {}, "pubsub": {}, "quit": {}, "readonly": {},
+	"readwrite": {}, "role": {}, "scan": {}, "script": {}, "select": {}, "slowlog": {}, "time": {},
+	"wait": {},
+}
+
+// ErrSlotDegraded - хэш-слот ключа команды деградировал: команда не отправлялась в Redis.
+var ErrSlotDegraded = errors.New("redis: hash slot is degraded")
+
+// SlotOptions - когда хэш-слот считается деградировавшим. Нулевое значение - значение по умолчанию.
+type SlotOptions struct {
+	// Failures - сколько ошибок подряд переводят слот в деградацию. По умолчанию DefaultSlotFailures.
+	Failures int
+	// Cooldown - сколько слот остается в деградации. По умолчанию DefaultSlotCooldown.
+	Cooldown time.Duration
+}
+
+func (o SlotOptions) withDefaults() SlotOptions {
+	if o.Failures == 0 {
+		o.Failures = DefaultSlotFailures
+	}
+
+	if o.Cooldown == 0 {
+		o.Cooldown = DefaultSlotCooldown
+	}
+
+	return o
+}
+
+// slotState - ошибки слота подряд и срок его деградации.
+type slotState struct {
+	failures int
+	until    time.Time
+}
+
+// TopologyHook - хук go-redis, который делает кластерный клиент устойчивым к решардингу и отказам
+// отдельных узлов. Перенаправления MOVED/ASK выполняет сам go-redis, хук учитывает их в метриках
+// на узлах кластера (Attach). Если команды слота Failures раз подряд не выполнились даже после
+// перенаправлений и повторов, слот на Cooldown деградирует: команды с его ключами сразу получают
+// ErrSlotDegraded, а не ждут таймаута, команды остальных слотов выполняются. При деградации слота
+// топология кластера перечитывается. После Cooldown команды слота снова отправляются в Redis:
+// первая успешная снимает деградацию, ошибка возвращает ее.
+type TopologyHook struct {
+	opts SlotOptions
+
+	redirects  *prometheus.CounterVec
+	slotErrors *prometheus.CounterVec
+	rejected   prometheus.Counter
+	reloads    prometheus.Counter
+
+	mu     sync.Mutex
+	slots  map[int]*slotState
+	reload func(ctx context.Context)
+
+	now func() time.Time
+}
+
+var _ redis.Hook = (*TopologyHook)(nil)
+
+// NewTopologyHook создает хук с деградацией слотов opts и регистрирует метрики в registerer.
+// Если метрики уже зарегистрированы, используются существующие.
+func NewTopologyHook(opts SlotOptions, registerer prometheus.Registerer) (*TopologyHook, error) {
+	if registerer == nil {
+		return nil, errors.New("registerer is required")
+	}
+
+	opts = opts.withDefaults()
+
+	if opts.Failures < 0 || opts.Cooldown < 0 {
+		return nil, errors.New("slot failures and cooldown must not be negative")
+	}
+
+	h := &TopologyHook{
+		opts:  opts,
+		slots: make(map[int]*slotState),
+		now:   time.Now,
+	}
+
+	var err error
+
+	h.redirects, err = register(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "redis_cluster_redirects_total",
+		Help: "Количество перенаправлений команд Redis Cluster по видам: moved, ask.",
+	}, []string{"type"}))
+	if err != nil {
+		return nil, err
+	}
+
+	h.slotErrors, err = register(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "redis_cluster_slot_errors_total",
+		Help: "Количество команд Redis Cluster, не выполненных из-за состояния слота, по причинам: clusterdown, tryagain, redirect, network.",
+	}, []string{"reason"}))
+	if err != nil {
+		return nil, err
+	}
+
+	h.rejected, err = register(registerer, prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "redis_cluster_slot_rejected_total",
+		Help: "Количество команд Redis Cluster, сразу отклоненных из-за деградации слота.",
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	h.reloads, err = register(registerer, prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "redis_cluster_topology_reloads_total",
+		Help: "Количество запросов на перечитывание топологии Redis Cluster из-за деградации слотов.",
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = register(registerer, prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "redis_cluster_degraded_slots",
+		Help: "Количество деградировавших хэш-слотов Redis Cluster.",
+	}, func() float64 { return float64(h.degradedSlots()) }))
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// Attach подключает хук к узлам кластерного клиента client: перенаправления учитываются на узлах,
+// а при деградации слота перечитывается топология. Для клиента одного узла ничего не делает.
+// Вызывается до первой команды: узлы, созданные раньше, перенаправления не учитывают.
+func (h *TopologyHook) Attach(client redis.UniversalClient) {
+	cluster, ok := client.(*redis.ClusterClient)
+	if !ok {
+		return
+	}
+
+	h.mu.Lock()
+	h.reload = cluster.ReloadState
+	h.mu.Unlock()
+
+	cluster.OnNewNode(func(node *redis.Client) {
+		node.AddHook(redirectHook{hook: h})
+	})
+}
+
+// DialHook не меняет установку соединения.
+func (h *TopologyHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook отклоняет команду деградировавшего слота и учитывает результат команды в ее слоте.
+func (h *TopologyHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		slot := commandSlot(cmd)
+
+		if err := h.check(slot); err != nil {
+			return err
+		}
+
+		err := next(ctx, cmd)
+		h.observe(ctx, slot, err)
+
+		return err
+	}
+}
+
+// ProcessPipelineHook отклоняет пайплайн, если деградировал слот любой его команды, и учитывает
+// результаты команд в их слотах. Сетевая ошибка пайплайна относится ко всем его слотам:
+// go-redis не всегда записывает ее в команды.
+func (h *TopologyHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		slots := make([]int, len(cmds))
+
+		for i, cmd := range cmds {
+			slots[i] = commandSlot(cmd)
+
+			if err := h.check(slots[i]); err != nil {
+				for _, cmd := range cmds {
+					cmd.SetErr(err)
+				}
+
+				return err
+			}
+		}
+
+		err := next(ctx, cmds)
+
+		for i, cmd := range cmds {
+			cmdErr := cmd.Err()
+			if kind := errorKind(err); kind == ErrorKindTimeout || kind == ErrorKindNetwork {
+				cmdErr = err
+			}
+
+			h.observe(ctx, slots[i], cmdErr)
+		}
+
+		return err
+	}
+}
+
+// check возвращает ошибку, если слот деградировал.
+func (h *TopologyHook) check(slot int) error {
+	if slot < 0 {
+		return nil
+	}
+
+	h.mu.Lock()
+	state, ok := h.slots[slot]
+	degraded := ok && h.now().Before(state.until)
+	h.mu.Unlock()
+
+	if !degraded {
+		return nil
+	}
+
+	h.rejected.Inc()
+
+	return fmt.Errorf("%w: slot %d", ErrSlotDegraded, slot)
+}
+
+// observe учитывает результат команды слота: успех снимает деградацию, Failures ошибок слота
+// подряд переводят его в деградацию. Остальные ошибки (например, WRONGTYPE) и отмена вызывающим
+// состояние слота не меняют.
+func (h *TopologyHook) observe(ctx context.Context, slot int, err error) {
+	if slot < 0 {
+		return
+	}
+
+	if err == nil || errors.Is(err, redis.Nil) {
+		h.recover(slot)
+
+		return
+	}
+
+	reason, ok := slotError(err)
+	if !ok {
+		return
+	}
+
+	h.slotErrors.WithLabelValues(reason).Inc()
+
+	h.mu.Lock()
+
+	state, exists := h.slots[slot]
+	if !exists {
+		state = &slotState{}
+		h.slots[slot] = state
+	}
+
+	state.failures++
+
+	now := h.now()
+	failures := state.failures
+	degrade := failures >= h.opts.Failures && !now.Before(state.until)
+
+	if degrade {
+		state.until = now.Add(h.opts.Cooldown)
+	}
+
+	reload := h.reload
+	h.mu.Unlock()
+
+	if !degrade {
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"slot":     slot,
+		"reason":   reason,
+		"failures": failures,
+		"cooldown": h.opts.Cooldown,
+	}).WithError(err).Warn("redis cluster slot degraded")
+
+	if reload != nil {
+		h.reloads.Inc()
+		reload(ctx)
+	}
+}
+
+// recover снимает деградацию слота после успешной команды.
+func (h *TopologyHook) recover(slot int) {
+	h.mu.Lock()
+	state, ok := h.slots[slot]
+	delete(h.slots, slot)
+	h.mu.Unlock()
+
+	if ok && !state.until.IsZero() {
+		logrus.WithField("slot", slot).Info("redis cluster slot recovered")
+	}
+}
+
+// degradedSlots возвращает количество слотов в деградации.
+func (h *TopologyHook) degradedSlots() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	degraded := 0
+
+	for _, state := range h.slots {
+		if now.Before(state.until) {
+			degraded++
+		}
+	}
+
+	return degraded
+}
+
+// redirectHook - хук узла кластера, который учитывает перенаправления MOVED и ASK.
+// Перенаправление видно только на узле: кластерный клиент выполняет его сам.
+type redirectHook struct {
+	hook *TopologyHook
+}
+
+func (r redirectHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (r redirectHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := next(ctx, cmd)
+		r.count(err)
+
+		return err
+	}
+}
+
+func (r redirectHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := next(ctx, cmds)
+
+		for _, cmd := range cmds {
+			r.count(cmd.Err())
+		}
+
+		return err
+	}
+}
+
+func (r redirectHook) count(err error) {
+	if redirect := redirectType(err); redirect != "" {
+		r.hook.redirects.WithLabelValues(redirect).Inc()
+	}
+}
+
+// redirectType возвращает вид перенаправления в ошибке Redis или пустую строку.
+func redirectType(err error) string {
+	var redisErr redis.Error
+	if !errors.As(err, &redisErr) {
+		return ""
+	}
+
+	switch msg := redisErr.Error(); {
+	case strings.HasPrefix(msg, "MOVED "):
+		return RedirectMoved
+	case strings.HasPrefix(msg, "ASK "):
+		return RedirectAsk
+	}
+
+	return ""
+}
+
+// slotError возвращает причину ошибки, если ошибка вызвана состоянием слота или его узла.
+func slotError(err error) (string, bool) {
+	if kind := errorKind(err); kind == ErrorKindTimeout || kind == ErrorKindNetwork {
+		// дедлайн вызывающего тоже считается: узел слота не ответил вовремя
+		return slotErrorNetwork, true
+	}
+
+	if redirectType(err) != "" {
+		return slotErrorRedirect, true
+	}
+
+	var redisErr redis.Error
+	if !errors.As(err, &redisErr) {
+		return "", false
+	}
+
+	switch msg := redisErr.Error(); {
+	case strings.HasPrefix(msg, "CLUSTERDOWN"):
+		return slotErrorClusterDown, true
+	case strings.HasPrefix(msg, "TRYAGAIN"):
+		return slotErrorTryAgain, true
+	}
+
+	return "", false
+}
+
+// commandSlot возвращает хэш-слот первого ключа команды или -1, если у команды нет ключа.
+func commandSlot(cmd redis.Cmder) int {
+	if _, ok := keylessCommands[cmd.Name()]; ok {
+		return -1
+	}
+
+	key := commandKey(cmd)
+	if key == "" {
+		return -1
+	}
+
+	return Slot(key)
+}
+
+// Slot возвращает хэш-слот ключа Redis Cluster: CRC16 ключа или его хэш-тега {...} по модулю 16384.
+func Slot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+
+	return int(crc16(key)) % slotCount
+}
+
+// crc16 - CRC16-CCITT (XMODEM), которым Redis Cluster распределяет ключи по слотам.
+func crc16(key string) uint16 {
+	var crc uint16
+
+	for i := range len(key) {
+		crc ^= uint16(key[i]) << 8
+
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
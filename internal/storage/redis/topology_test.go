@@ -0,0 +1,221 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlot(t *testing.T) {
+	t.Parallel()
+
+	// значения из спецификации Redis Cluster
+	assert.Equal(t, 12739, Slot("123456789"))
+	assert.Equal(t, 12182, Slot("foo"))
+
+	// ключи с одним хэш-тегом в одном слоте
+	assert.Equal(t, Slot("user1000"), Slot("{user1000}.following"))
+	assert.Equal(t, Slot("user1000"), Slot("{user1000}.followers"))
+	assert.Equal(t, Slot("bar"), Slot("foo{bar}{zap}"))
+
+	// пустой хэш-тег не считается, хэшируется весь ключ
+	assert.NotEqual(t, Slot(""), Slot("foo{}{bar}"))
+	assert.Equal(t, int(crc16("foo{}{bar}"))%slotCount, Slot("foo{}{bar}"))
+	assert.Equal(t, Slot("{bar"), Slot("foo{{bar}}zap"))
+}
+
+func TestNewTopologyHook(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewTopologyHook(SlotOptions{}, nil)
+	require.EqualError(t, err, "registerer is required")
+
+	_, err = NewTopologyHook(SlotOptions{Failures: -1}, prometheus.NewRegistry())
+	require.EqualError(t, err, "slot failures and cooldown must not be negative")
+
+	hook, err := NewTopologyHook(SlotOptions{}, prometheus.NewRegistry())
+	require.NoError(t, err)
+	assert.Equal(t, SlotOptions{Failures: DefaultSlotFailures, Cooldown: DefaultSlotCooldown}, hook.opts)
+}
+
+// newTopologyClient возвращает клиент с TopologyHook, который переводит слот в деградацию
+// после двух ошибок на минуту, и текущее время хука.
+func newTopologyClient(t *testing.T) (*miniredis.Miniredis, *redis.Client, *TopologyHook, *time.Time) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	hook, err := NewTopologyHook(SlotOptions{Failures: 2, Cooldown: time.Minute}, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	hook.now = func() time.Time { return now }
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+
+	client.AddHook(hook)
+
+	return mr, client, hook, &now
+}
+
+//nolint:funlen // длинный тест - это ок
+func TestTopologyHook_Process(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	mr, client, hook, now := newTopologyClient(t)
+
+	require.NotEqual(t, Slot("a"), Slot("b"))
+
+	// ошибка самой команды и отмена вызывающим слот не затрагивают
+	require.NoError(t, client.Set(ctx, "a", "value", 0).Err())
+	require.Error(t, client.IncrBy(ctx, "a", 1).Err())
+	require.Error(t, client.IncrBy(ctx, "a", 1).Err())
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	require.Error(t, client.Get(canceled, "a").Err())
+	require.Error(t, client.Get(canceled, "a").Err())
+	require.NoError(t, client.Get(ctx, "a").Err())
+
+	mr.SetError("CLUSTERDOWN Hash slot not served")
+
+	require.ErrorContains(t, client.Get(ctx, "a").Err(), "CLUSTERDOWN")
+	assert.Equal(t, 0, hook.degradedSlots())
+
+	// успешная команда сбрасывает ошибки подряд
+	mr.SetError("")
+	require.NoError(t, client.Get(ctx, "a").Err())
+
+	mr.SetError("TRYAGAIN Multiple keys request during rehashing of slot")
+
+	require.ErrorContains(t, client.Get(ctx, "a").Err(), "TRYAGAIN")
+	require.ErrorContains(t, client.Get(ctx, "a").Err(), "TRYAGAIN")
+	assert.Equal(t, 1, hook.degradedSlots())
+	assert.InDelta(t, 1, testutil.ToFloat64(hook.slotErrors.WithLabelValues(slotErrorClusterDown)), 0)
+	assert.InDelta(t, 2, testutil.ToFloat64(hook.slotErrors.WithLabelValues(slotErrorTryAgain)), 0)
+
+	// команды деградировавшего слота сразу отклоняются, остальные слоты работают
+	mr.SetError("")
+
+	require.ErrorIs(t, client.Get(ctx, "a").Err(), ErrSlotDegraded)
+	require.ErrorIs(t, client.Get(ctx, "b").Err(), redis.Nil)
+	require.NoError(t, client.Ping(ctx).Err())
+	assert.InDelta(t, 1, testutil.ToFloat64(hook.rejected), 0)
+
+	// после cooldown команда снова отправляется, ошибка сразу возвращает деградацию
+	*now = now.Add(time.Minute)
+	mr.SetError("CLUSTERDOWN Hash slot not served")
+
+	require.ErrorContains(t, client.Get(ctx, "a").Err(), "CLUSTERDOWN")
+	require.ErrorIs(t, client.Get(ctx, "a").Err(), ErrSlotDegraded)
+
+	// успешная команда после cooldown снимает деградацию
+	*now = now.Add(time.Minute)
+	mr.SetError("")
+
+	require.NoError(t, client.Get(ctx, "a").Err())
+	require.NoError(t, client.Get(ctx, "a").Err())
+	assert.Equal(t, 0, hook.degradedSlots())
+
+	// недоступный узел - тоже ошибка слота
+	mr.Close()
+
+	require.Error(t, client.Get(ctx, "b").Err())
+	require.Error(t, client.Get(ctx, "b").Err())
+	require.ErrorIs(t, client.Get(ctx, "b").Err(), ErrSlotDegraded)
+	assert.InDelta(t, 2, testutil.ToFloat64(hook.slotErrors.WithLabelValues(slotErrorNetwork)), 0)
+}
+
+func TestTopologyHook_Pipeline(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	mr, client, hook, _ := newTopologyClient(t)
+
+	pipeline := func(keys ...string) ([]redis.Cmder, error) {
+		return client.Pipelined(ctx, func(p redis.Pipeliner) error {
+			for _, key := range keys {
+				p.Get(ctx, key)
+			}
+
+			return nil
+		})
+	}
+
+	mr.SetError("CLUSTERDOWN Hash slot not served")
+
+	for range 2 {
+		_, err := pipeline("a", "b")
+		require.Error(t, err)
+	}
+
+	assert.Equal(t, 2, hook.degradedSlots())
+
+	// пайплайн с ключом деградировавшего слота не отправляется
+	mr.SetError("")
+
+	cmds, err := pipeline("c", "a")
+	require.ErrorIs(t, err, ErrSlotDegraded)
+	require.ErrorIs(t, cmds[0].Err(), ErrSlotDegraded)
+
+	_, err = pipeline("c")
+	require.ErrorIs(t, err, redis.Nil)
+
+	// сетевая ошибка пайплайна относится ко всем его слотам
+	mr.Close()
+
+	for range 2 {
+		_, err = pipeline("c", "d")
+		require.Error(t, err)
+	}
+
+	assert.Equal(t, 4, hook.degradedSlots())
+}
+
+func TestTopologyHook_Cluster(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	mr := miniredis.RunT(t)
+
+	hook, err := NewTopologyHook(SlotOptions{Failures: 1, Cooldown: time.Minute}, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	client := redis.NewClusterClient(&redis.ClusterOptions{Addrs: []string{mr.Addr()}, MaxRedirects: 2})
+	t.Cleanup(func() { _ = client.Close() })
+
+	client.AddHook(hook)
+	hook.Attach(client)
+
+	require.NoError(t, client.Set(ctx, "a", "value", 0).Err())
+
+	// узел все время перенаправляет слот: go-redis следует перенаправлениям, пока они не исчерпаны.
+	// Ошибку получает и перечитывание топологии, поэтому перенаправлений не меньше трех
+	mr.SetError("MOVED 15495 " + mr.Addr())
+
+	require.ErrorContains(t, client.Get(ctx, "a").Err(), "MOVED")
+	assert.GreaterOrEqual(t, testutil.ToFloat64(hook.redirects.WithLabelValues(RedirectMoved)), float64(3))
+	assert.InDelta(t, 1, testutil.ToFloat64(hook.slotErrors.WithLabelValues(slotErrorRedirect)), 0)
+
+	// деградация слота перечитывает топологию
+	assert.Equal(t, 1, hook.degradedSlots())
+	assert.InDelta(t, 1, testutil.ToFloat64(hook.reloads), 0)
+
+	mr.SetError("ASK 3300 " + mr.Addr())
+
+	require.Error(t, client.Get(ctx, "b").Err())
+	assert.Positive(t, testutil.ToFloat64(hook.redirects.WithLabelValues(RedirectAsk)))
+
+	// для клиента одного узла Attach ничего не делает
+	(&TopologyHook{}).Attach(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+}
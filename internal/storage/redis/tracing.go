@@ -0,0 +1,89 @@
+package redis
+
+import (
+	"auth-service/internal/service/tracing"
+	"context"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingHook - хук go-redis, который создает клиентский спан на каждую команду и пайплайн.
+// Спаны создаются только внутри уже начатой трассировки (запроса к серверу): фоновые команды
+// и команды подключения трассировки не начинают. Аргументы команд в спаны не пишутся:
+// в них ключи сессий и токены.
+type TracingHook struct {
+	tracer trace.Tracer
+}
+
+var _ redis.Hook = (*TracingHook)(nil)
+
+// NewTracingHook создает хук, который пишет спаны в provider.
+func NewTracingHook(provider trace.TracerProvider) (*TracingHook, error) {
+	if provider == nil {
+		return nil, errors.New("tracer provider is required")
+	}
+
+	return &TracingHook{tracer: tracing.Tracer(provider)}, nil
+}
+
+// DialHook не меняет установку соединения.
+func (h *TracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook выполняет команду в спане "redis <команда>".
+func (h *TracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			return next(ctx, cmd)
+		}
+
+		ctx, span := h.start(ctx, cmd.Name())
+		defer span.End()
+
+		err := next(ctx, cmd)
+		finishSpan(span, err)
+
+		return err
+	}
+}
+
+// ProcessPipelineHook выполняет пайплайн или транзакцию в спане "redis pipeline".
+func (h *TracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			return next(ctx, cmds)
+		}
+
+		ctx, span := h.start(ctx, pipelineCommand, semconv.DBOperationBatchSize(len(cmds)))
+		defer span.End()
+
+		err := next(ctx, cmds)
+		finishSpan(span, err)
+
+		return err
+	}
+}
+
+func (h *TracingHook) start(ctx context.Context, command string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return h.tracer.Start(ctx, "redis "+command,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemNameRedis, semconv.DBOperationName(command)),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// finishSpan отмечает ошибку команды в спане. redis.Nil - не ошибка: ключа просто нет.
+func finishSpan(span trace.Span, err error) {
+	if err == nil || errors.Is(err, redis.Nil) {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, errorKind(err))
+}
@@ -0,0 +1,75 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+func TestNewTracingHook(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewTracingHook(nil)
+	require.Error(t, err)
+
+	_, err = NewTracingHook(sdktrace.NewTracerProvider())
+	require.NoError(t, err)
+}
+
+func TestTracingHook(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	hook, err := NewTracingHook(provider)
+	require.NoError(t, err)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	client.AddHook(hook)
+
+	// вне трассировки спаны не создаются
+	require.NoError(t, client.Set(t.Context(), "key", "value", 0).Err())
+	assert.Empty(t, recorder.Ended())
+
+	ctx, parent := provider.Tracer("test").Start(t.Context(), "request")
+
+	require.ErrorIs(t, client.Get(ctx, "missing").Err(), redis.Nil)
+
+	_, err = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Get(ctx, "key")
+		pipe.Incr(ctx, "key")
+
+		return nil
+	})
+	require.Error(t, err)
+
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	get, pipeline := spans[0], spans[1]
+
+	assert.Equal(t, "redis get", get.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), get.Parent().SpanID())
+	assert.Contains(t, get.Attributes(), semconv.DBSystemNameRedis)
+	assert.Contains(t, get.Attributes(), semconv.DBOperationName("get"))
+	assert.Equal(t, codes.Unset, get.Status().Code, "redis.Nil is not an error")
+
+	assert.Equal(t, "redis pipeline", pipeline.Name())
+	assert.Contains(t, pipeline.Attributes(), semconv.DBOperationBatchSize(2))
+	assert.Equal(t, codes.Error, pipeline.Status().Code)
+	assert.Equal(t, ErrorKindOther, pipeline.Status().Description)
+}
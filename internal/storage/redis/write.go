@@ -0,0 +1,154 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// writeCommands - команды, которые меняют данные. Скрипты считаются записью: скрипты сервиса
+// обновляют сессии, семейства refresh токенов и счетчики.
+var writeCommands = map[string]struct{}{
+	"set": {}, "setex": {}, "psetex": {}, "setnx": {}, "getdel": {}, "getex": {}, "mset": {},
+	"del": {}, "unlink": {}, "expire": {}, "pexpire": {}, "expireat": {}, "pexpireat": {}, "persist": {},
+	"incr": {}, "incrby": {}, "decr": {}, "decrby": {},
+	"hset": {}, "hsetnx": {}, "hdel": {}, "hincrby": {},
+	"sadd": {}, "srem": {}, "zadd": {}, "zrem": {}, "zremrangebyscore": {},
+	"lpush": {}, "rpush": {}, "lpop": {}, "rpop": {}, "ltrim": {},
+	"xadd": {}, "xtrim": {}, "xack": {},
+	"eval": {}, "evalsha": {}, "fcall": {},
+}
+
+// storageErrorPrefixes - ответы Redis, при которых запись невозможна из-за состояния самого
+// хранилища: реплика только для чтения, нехватка памяти, ошибка сохранения на диск, недоступный кластер.
+var storageErrorPrefixes = []string{"READONLY", "OOM", "MISCONF", "NOREPLICAS", "MASTERDOWN", "CLUSTERDOWN", "LOADING"}
+
+// WriteObserver получает результат команды записи: nil - запись прошла, иначе ошибка хранилища.
+type WriteObserver func(err error)
+
+// WriteHook - хук go-redis, который сообщает WriteObserver результаты команд записи. Ошибки самих
+// команд (неверные аргументы, ошибка скрипта, конфликт WATCH) и отмена запроса вызывающим
+// о состоянии хранилища не говорят и не передаются. Пайплайн с командами записи - одна запись.
+type WriteHook struct {
+	observe WriteObserver
+}
+
+var _ redis.Hook = (*WriteHook)(nil)
+
+// NewWriteHook создает хук, который передает результаты команд записи в observe.
+func NewWriteHook(observe WriteObserver) (*WriteHook, error) {
+	if observe == nil {
+		return nil, errors.New("observer is required")
+	}
+
+	return &WriteHook{observe: observe}, nil
+}
+
+// DialHook не меняет установку соединения.
+func (h *WriteHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook сообщает результат команды записи.
+func (h *WriteHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := next(ctx, cmd)
+
+		if _, ok := writeCommands[cmd.Name()]; !ok {
+			return err
+		}
+
+		if ok, failure := writeResult(err); ok {
+			h.observe(failure)
+		}
+
+		return err
+	}
+}
+
+// ProcessPipelineHook сообщает результат пайплайна, если в нем есть команды записи:
+// ошибку хранилища первой неудачной команды или успех.
+func (h *WriteHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := next(ctx, cmds)
+
+		writes := false
+
+		for _, cmd := range cmds {
+			if _, ok := writeCommands[cmd.Name()]; ok {
+				writes = true
+
+				break
+			}
+		}
+
+		if !writes {
+			return err
+		}
+
+		// при отмене и обрыве соединения ошибка пайплайна не всегда попадает в команды
+		switch errorKind(err) {
+		case ErrorKindCanceled:
+			return err
+		case ErrorKindTimeout, ErrorKindNetwork:
+			h.observe(err)
+
+			return err
+		}
+
+		observed := false
+
+		for _, cmd := range cmds {
+			if _, ok := writeCommands[cmd.Name()]; !ok {
+				continue
+			}
+
+			ok, failure := writeResult(cmd.Err())
+			if !ok {
+				continue
+			}
+
+			if failure != nil {
+				h.observe(failure)
+
+				return err
+			}
+
+			observed = true
+		}
+
+		if observed {
+			h.observe(nil)
+		}
+
+		return err
+	}
+}
+
+// writeResult возвращает ошибку хранилища по результату команды записи. false - результат
+// не говорит о состоянии хранилища.
+func writeResult(err error) (bool, error) {
+	switch errorKind(err) {
+	case "":
+		return true, nil
+	case ErrorKindCanceled:
+		return false, nil
+	case ErrorKindTimeout, ErrorKindNetwork:
+		return true, err
+	}
+
+	var redisErr redis.Error
+	if !errors.As(err, &redisErr) {
+		return false, nil
+	}
+
+	for _, prefix := range storageErrorPrefixes {
+		if strings.HasPrefix(redisErr.Error(), prefix) {
+			return true, err
+		}
+	}
+
+	return false, nil
+}
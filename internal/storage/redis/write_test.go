@@ -0,0 +1,129 @@
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWriteHook(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewWriteHook(nil)
+	require.EqualError(t, err, "observer is required")
+}
+
+// newWriteClient возвращает клиент с WriteHook и список результатов записей, переданных хуком.
+func newWriteClient(t *testing.T) (*miniredis.Miniredis, *redis.Client, *[]error) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	var observed []error
+
+	hook, err := NewWriteHook(func(err error) { observed = append(observed, err) })
+	require.NoError(t, err)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+
+	client.AddHook(hook)
+
+	return mr, client, &observed
+}
+
+func TestWriteHook_Process(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	mr, client, observed := newWriteClient(t)
+
+	// чтения не учитываются, redis.Nil при записи - успешная запись
+	require.ErrorIs(t, client.Get(ctx, "key").Err(), redis.Nil)
+	require.NoError(t, client.Set(ctx, "key", "value", 0).Err())
+	require.ErrorIs(t, client.SetArgs(ctx, "key", "other", redis.SetArgs{Mode: "NX"}).Err(), redis.Nil)
+	assert.Equal(t, []error{nil, nil}, *observed)
+
+	// ошибка самой команды о хранилище не говорит
+	*observed = nil
+
+	require.Error(t, client.IncrBy(ctx, "key", 1).Err())
+	assert.Empty(t, *observed)
+
+	// отмена вызывающим не учитывается
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	require.Error(t, client.Set(canceled, "key", "value", 0).Err())
+	assert.Empty(t, *observed)
+
+	mr.SetError("READONLY You can't write against a read only replica.")
+
+	require.Error(t, client.Del(ctx, "key").Err())
+	require.Len(t, *observed, 1)
+	assert.ErrorContains(t, (*observed)[0], "READONLY")
+
+	mr.SetError("")
+	mr.Close()
+
+	require.Error(t, client.Set(ctx, "key", "value", 0).Err())
+	require.Len(t, *observed, 2)
+	assert.Error(t, (*observed)[1])
+}
+
+func TestWriteHook_Pipeline(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	mr, client, observed := newWriteClient(t)
+
+	_, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Get(ctx, "key")
+		pipe.Exists(ctx, "key")
+
+		return nil
+	})
+	require.ErrorIs(t, err, redis.Nil)
+	assert.Empty(t, *observed)
+
+	_, err = client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, "key", "value", 0)
+		pipe.Expire(ctx, "key", 0)
+
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []error{nil}, *observed)
+
+	*observed = nil
+
+	mr.SetError("OOM command not allowed when used memory > 'maxmemory'.")
+
+	_, err = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, "key", "value", 0)
+		pipe.HSet(ctx, "hash", "field", "value")
+
+		return nil
+	})
+	require.Error(t, err)
+	require.Len(t, *observed, 1)
+	assert.ErrorContains(t, (*observed)[0], "OOM")
+
+	*observed = nil
+
+	mr.SetError("")
+	mr.Close()
+
+	_, err = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, "key", "value", 0)
+
+		return nil
+	})
+	require.Error(t, err)
+	require.Len(t, *observed, 1)
+	assert.Error(t, (*observed)[0])
+}
@@ -0,0 +1,21 @@
+package vault
+
+import (
+	"context"
+)
+
+// Capabilities возвращает права токена сервиса на путь (sys/capabilities-self), например
+// ["read", "list"]. Для пути без прав Vault возвращает ["deny"].
+func (vc *Client) Capabilities(ctx context.Context, path string) ([]string, error) {
+	client, err := vc.apiClient()
+	if err != nil {
+		return nil, err
+	}
+
+	capabilities, err := client.Sys().CapabilitiesSelfWithContext(ctx, path)
+	if err != nil {
+		return nil, wrapError("check capabilities", path, err)
+	}
+
+	return capabilities, nil
+}
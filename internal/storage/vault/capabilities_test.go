@@ -0,0 +1,56 @@
+package vault
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilities(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Path string `json:"path"`
+		}
+
+		assert.Equal(t, "/v1/sys/capabilities-self", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if req.Path == "sys/forbidden" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":{"capabilities":["read","list"],"` + req.Path + `":["read","list"]}}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	cfg := api.DefaultConfig()
+	cfg.Address = ts.URL
+	cfg.MaxRetries = 0
+
+	client, err := api.NewClient(cfg)
+	require.NoError(t, err)
+
+	vc := &Client{client: client}
+
+	got, err := vc.Capabilities(t.Context(), "secret/data/auth/signing-keys")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"read", "list"}, got)
+
+	_, err = vc.Capabilities(t.Context(), "sys/forbidden")
+	require.ErrorIs(t, err, ErrPermissionDenied)
+
+	_, err = (&Client{}).Capabilities(t.Context(), "secret/data/x")
+	require.ErrorContains(t, err, "client is not connected")
+}
@@ -0,0 +1,141 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// ErrSecretNotFound - секрет по указанному пути не найден.
+var ErrSecretNotFound = errors.New("vault: secret not found")
+
+// ErrCASMismatch - секрет изменился после чтения: версия для check-and-set не совпала с текущей.
+var ErrCASMismatch = errors.New("vault: check-and-set version mismatch")
+
+// ReadKV читает секрет KV v2 по полному пути (например, "secret/data/auth/keys") и возвращает его данные.
+func (vc *Client) ReadKV(ctx context.Context, path string) (map[string]interface{}, error) {
+	vc.mu.RLock()
+	client := vc.client
+	vc.mu.RUnlock()
+
+	if client == nil {
+		return nil, errors.New("vault: client is not connected")
+	}
+
+	secret, err := client.Logical().ReadWithContext(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("vault: error read secret %s: %w", path, err)
+	}
+
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSecretNotFound, path)
+	}
+
+	// KV v2 хранит данные секрета во вложенном поле data
+	data, ok := secret.Data["data"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSecretNotFound, path)
+	}
+
+	return data, nil
+}
+
+// WriteKV записывает секрет KV v2 по полному пути (например, "secret/data/auth/apikeys/bot"),
+// создавая новую версию секрета.
+func (vc *Client) WriteKV(ctx context.Context, path string, data map[string]interface{}) error {
+	vc.mu.RLock()
+	client := vc.client
+	vc.mu.RUnlock()
+
+	if client == nil {
+		return errors.New("vault: client is not connected")
+	}
+
+	// KV v2 ожидает данные секрета во вложенном поле data
+	if _, err := client.Logical().WriteWithContext(ctx, path, map[string]interface{}{"data": data}); err != nil {
+		return fmt.Errorf("vault: error write secret %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// ReadKVVersion читает секрет KV v2 по полному пути и возвращает его данные и номер версии
+// для последующей записи через WriteKVCAS.
+func (vc *Client) ReadKVVersion(ctx context.Context, path string) (map[string]interface{}, int, error) {
+	client, err := vc.apiClient()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	secret, err := client.Logical().ReadWithContext(ctx, path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("vault: error read secret %s: %w", path, err)
+	}
+
+	if secret == nil || secret.Data == nil {
+		return nil, 0, fmt.Errorf("%w: %s", ErrSecretNotFound, path)
+	}
+
+	data, ok := secret.Data["data"].(map[string]interface{})
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: %s", ErrSecretNotFound, path)
+	}
+
+	var version int
+
+	if metadata, ok := secret.Data["metadata"].(map[string]interface{}); ok {
+		if v, ok := metadata["version"].(json.Number); ok {
+			n, _ := v.Int64()
+			version = int(n)
+		}
+	}
+
+	return data, version, nil
+}
+
+// WriteKVCAS записывает секрет KV v2, только если его текущая версия равна version
+// (check-and-set, version 0 - секрет еще не создан). Если секрет успел измениться,
+// возвращает ErrCASMismatch.
+func (vc *Client) WriteKVCAS(ctx context.Context, path string, data map[string]interface{}, version int) error {
+	client, err := vc.apiClient()
+	if err != nil {
+		return err
+	}
+
+	body := map[string]interface{}{
+		"data":    data,
+		"options": map[string]interface{}{"cas": version},
+	}
+
+	if _, err := client.Logical().WriteWithContext(ctx, path, body); err != nil {
+		if isCASMismatch(err) {
+			return fmt.Errorf("%w: %s", ErrCASMismatch, path)
+		}
+
+		return fmt.Errorf("vault: error write secret %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// isCASMismatch возвращает true, если Vault отклонил запись из-за несовпадения версии check-and-set.
+func isCASMismatch(err error) bool {
+	var respErr *api.ResponseError
+
+	if !errors.As(err, &respErr) || respErr.StatusCode != http.StatusBadRequest {
+		return false
+	}
+
+	for _, msg := range respErr.Errors {
+		if strings.Contains(msg, "check-and-set") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,204 @@
+package vault
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // длинный тест - это ок
+func TestReadKV(t *testing.T) {
+	t.Parallel()
+
+	newVault := func(t *testing.T, status int, body string) *api.Client {
+		t.Helper()
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v1/secret/data/auth/keys", r.URL.Path)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+		}))
+		t.Cleanup(ts.Close)
+
+		cfg := api.DefaultConfig()
+		cfg.Address = ts.URL
+		cfg.MaxRetries = 0
+
+		client, err := api.NewClient(cfg)
+		require.NoError(t, err)
+
+		return client
+	}
+
+	testCases := []struct {
+		name         string
+		createClient func(t *testing.T) *api.Client
+		want         map[string]interface{}
+		wantErr      require.ErrorAssertionFunc
+	}{
+		{
+			name: "positive case",
+			createClient: func(t *testing.T) *api.Client {
+				t.Helper()
+
+				return newVault(t, http.StatusOK, `{"data":{"data":{"key-1":"secret"},"metadata":{"version":1}}}`)
+			},
+			want:    map[string]interface{}{"key-1": "secret"},
+			wantErr: require.NoError,
+		},
+		{
+			name: "error case: not found",
+			createClient: func(t *testing.T) *api.Client {
+				t.Helper()
+
+				return newVault(t, http.StatusNotFound, `{"errors":[]}`)
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorIs(t, err, ErrSecretNotFound)
+			},
+		},
+		{
+			name: "error case: client is not connected",
+			createClient: func(t *testing.T) *api.Client {
+				t.Helper()
+
+				return nil
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "client is not connected")
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			vc := &Client{client: tt.createClient(t)}
+
+			got, err := vc.ReadKV(t.Context(), "secret/data/auth/keys")
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWriteKV(t *testing.T) {
+	t.Parallel()
+
+	var got map[string]interface{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+
+		if r.URL.Path != "/v1/secret/data/auth/apikeys/bot" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+
+			return
+		}
+
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"version":1}}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	cfg := api.DefaultConfig()
+	cfg.Address = ts.URL
+	cfg.MaxRetries = 0
+
+	client, err := api.NewClient(cfg)
+	require.NoError(t, err)
+
+	vc := &Client{client: client}
+
+	require.NoError(t, vc.WriteKV(t.Context(), "secret/data/auth/apikeys/bot", map[string]interface{}{"secret": "s3cret"}))
+	assert.Equal(t, map[string]interface{}{"data": map[string]interface{}{"secret": "s3cret"}}, got)
+
+	err = vc.WriteKV(t.Context(), "secret/data/other", map[string]interface{}{"secret": "s3cret"})
+	require.ErrorContains(t, err, "permission denied")
+
+	require.ErrorContains(t, (&Client{}).WriteKV(t.Context(), "secret/data/x", nil), "client is not connected")
+}
+
+func TestKVCAS(t *testing.T) {
+	t.Parallel()
+
+	var (
+		version = 3
+		data    = map[string]interface{}{"current": "k1"}
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == http.MethodGet {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"data": data, "metadata": map[string]interface{}{"version": version}},
+			})
+
+			return
+		}
+
+		var body struct {
+			Data    map[string]interface{} `json:"data"`
+			Options struct {
+				CAS int `json:"cas"`
+			} `json:"options"`
+		}
+
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		if body.Options.CAS != version {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":["check-and-set parameter did not match the current version"]}`))
+
+			return
+		}
+
+		version++
+		data = body.Data
+
+		_, _ = w.Write([]byte(`{"data":{"version":` + strconv.Itoa(version) + `}}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	cfg := api.DefaultConfig()
+	cfg.Address = ts.URL
+	cfg.MaxRetries = 0
+
+	client, err := api.NewClient(cfg)
+	require.NoError(t, err)
+
+	vc := &Client{client: client}
+
+	got, v, err := vc.ReadKVVersion(t.Context(), "secret/data/auth/keys")
+	require.NoError(t, err)
+	assert.Equal(t, 3, v)
+	assert.Equal(t, map[string]interface{}{"current": "k1"}, got)
+
+	require.NoError(t, vc.WriteKVCAS(t.Context(), "secret/data/auth/keys", map[string]interface{}{"current": "k2"}, v))
+
+	// запись по устаревшей версии отклоняется
+	err = vc.WriteKVCAS(t.Context(), "secret/data/auth/keys", map[string]interface{}{"current": "k3"}, v)
+	require.ErrorIs(t, err, ErrCASMismatch)
+
+	got, v, err = vc.ReadKVVersion(t.Context(), "secret/data/auth/keys")
+	require.NoError(t, err)
+	assert.Equal(t, 4, v)
+	assert.Equal(t, map[string]interface{}{"current": "k2"}, got)
+
+	_, _, err = (&Client{}).ReadKVVersion(t.Context(), "secret/data/x")
+	require.ErrorContains(t, err, "client is not connected")
+	require.ErrorContains(t, (&Client{}).WriteKVCAS(t.Context(), "secret/data/x", nil, 0), "client is not connected")
+}
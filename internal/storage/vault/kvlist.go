@@ -0,0 +1,199 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// ErrPermissionDenied - политика токена не разрешает операцию по пути.
+var ErrPermissionDenied = errors.New("vault: permission denied")
+
+// KVMetadata - метаданные секрета KV v2. Значения секрета не читаются.
+type KVMetadata struct {
+	CurrentVersion int
+	Versions       int
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// KVEntry - элемент дерева секретов KV v2.
+type KVEntry struct {
+	// Path - путь относительно mount, у папок оканчивается на /.
+	Path string
+	Dir  bool
+	// Metadata - метаданные секрета, nil у папок и при ошибке.
+	Metadata *KVMetadata
+	// Err - ошибка list папки или чтения метаданных секрета, например ErrPermissionDenied.
+	Err error
+}
+
+// ListKV возвращает содержимое папки KV v2 path в mount (например, mount "secret", path "auth").
+// Папки оканчиваются на /.
+func (vc *Client) ListKV(ctx context.Context, mount, path string) ([]string, error) {
+	client, err := vc.apiClient()
+	if err != nil {
+		return nil, err
+	}
+
+	full := metadataPath(mount, path)
+
+	secret, err := client.Logical().ListWithContext(ctx, full)
+	if err != nil {
+		return nil, wrapError("list", full, err)
+	}
+
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSecretNotFound, full)
+	}
+
+	raw, _ := secret.Data["keys"].([]interface{})
+	keys := make([]string, 0, len(raw))
+
+	for _, key := range raw {
+		if s, ok := key.(string); ok {
+			keys = append(keys, s)
+		}
+	}
+
+	return keys, nil
+}
+
+// ReadKVMetadata читает метаданные секрета KV v2 path в mount.
+func (vc *Client) ReadKVMetadata(ctx context.Context, mount, path string) (*KVMetadata, error) {
+	client, err := vc.apiClient()
+	if err != nil {
+		return nil, err
+	}
+
+	full := metadataPath(mount, path)
+
+	secret, err := client.Logical().ReadWithContext(ctx, full)
+	if err != nil {
+		return nil, wrapError("read metadata", full, err)
+	}
+
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSecretNotFound, full)
+	}
+
+	md := &KVMetadata{}
+
+	if v, ok := secret.Data["current_version"].(json.Number); ok {
+		n, _ := v.Int64()
+		md.CurrentVersion = int(n)
+	}
+
+	if versions, ok := secret.Data["versions"].(map[string]interface{}); ok {
+		md.Versions = len(versions)
+	}
+
+	md.CreatedAt = parseTime(secret.Data["created_time"])
+	md.UpdatedAt = parseTime(secret.Data["updated_time"])
+
+	return md, nil
+}
+
+// WalkKV обходит дерево секретов KV v2 от папки path в mount на глубину до maxDepth уровней
+// (0 - без ограничения) и возвращает папки и секреты с метаданными. Ошибки отдельных путей,
+// например отсутствие прав, записываются в KVEntry.Err и не прерывают обход: по ним видно,
+// какие пути политика токена не разрешает. Ошибка возвращается, только если обход невозможен.
+func (vc *Client) WalkKV(ctx context.Context, mount, path string, maxDepth int) ([]KVEntry, error) {
+	if _, err := vc.apiClient(); err != nil {
+		return nil, err
+	}
+
+	root := strings.Trim(path, "/")
+	if root != "" {
+		root += "/"
+	}
+
+	var entries []KVEntry
+
+	var walk func(dir string, depth int) error
+
+	walk = func(dir string, depth int) error {
+		keys, err := vc.ListKV(ctx, mount, dir)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			entry := KVEntry{Path: dir + key, Dir: strings.HasSuffix(key, "/")}
+
+			if !entry.Dir {
+				entry.Metadata, entry.Err = vc.ReadKVMetadata(ctx, mount, entry.Path)
+			}
+
+			entries = append(entries, entry)
+
+			if !entry.Dir || (maxDepth > 0 && depth >= maxDepth) {
+				continue
+			}
+
+			i := len(entries) - 1
+
+			if err := walk(entry.Path, depth+1); err != nil {
+				if ctx.Err() != nil {
+					return err
+				}
+
+				entries[i].Err = err
+			}
+		}
+
+		return nil
+	}
+
+	if err := walk(root, 1); err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+
+		return []KVEntry{{Path: root, Dir: true, Err: err}}, nil
+	}
+
+	return entries, nil
+}
+
+// apiClient возвращает подключенный клиент Vault.
+func (vc *Client) apiClient() (*api.Client, error) {
+	vc.mu.RLock()
+	client := vc.client
+	vc.mu.RUnlock()
+
+	if client == nil {
+		return nil, errors.New("vault: client is not connected")
+	}
+
+	return client, nil
+}
+
+// metadataPath возвращает путь метаданных KV v2: <mount>/metadata/<path>.
+func metadataPath(mount, path string) string {
+	return strings.Trim(mount, "/") + "/metadata/" + strings.TrimLeft(path, "/")
+}
+
+// wrapError оборачивает ошибку Vault. Отказ политики оборачивается в ErrPermissionDenied.
+func wrapError(op, path string, err error) error {
+	var respErr *api.ResponseError
+
+	if errors.As(err, &respErr) && respErr.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%w: %s %s", ErrPermissionDenied, op, path)
+	}
+
+	return fmt.Errorf("vault: error %s %s: %w", op, path, err)
+}
+
+func parseTime(v interface{}) time.Time {
+	s, _ := v.(string)
+	t, _ := time.Parse(time.RFC3339Nano, s)
+
+	return t
+}
@@ -0,0 +1,122 @@
+package vault
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTreeVault - Vault с деревом secret/auth: папка oauth закрыта политикой.
+func newTreeVault(t *testing.T) *Client {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		list := r.Method == "LIST" || r.URL.Query().Get("list") == "true"
+		// клиент Vault отправляет путь папки без завершающего /
+		path := strings.TrimSuffix(r.URL.Path, "/")
+
+		switch {
+		case list && path == "/v1/secret/metadata/auth":
+			_, _ = w.Write([]byte(`{"data":{"keys":["signing-keys","oauth/","apikeys/"]}}`))
+		case list && path == "/v1/secret/metadata/auth/apikeys":
+			_, _ = w.Write([]byte(`{"data":{"keys":["bot","archive/"]}}`))
+		case list && path == "/v1/secret/metadata/auth/apikeys/archive":
+			_, _ = w.Write([]byte(`{"data":{"keys":["old"]}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/secret/metadata/auth/signing-keys":
+			_, _ = w.Write([]byte(`{"data":{"current_version":3,"versions":{"1":{},"2":{},"3":{}},` +
+				`"created_time":"2026-01-01T00:00:00Z","updated_time":"2026-02-01T00:00:00Z"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/secret/metadata/auth/apikeys/bot":
+			_, _ = w.Write([]byte(`{"data":{"current_version":1,"versions":{"1":{}}}}`))
+		case path == "/v1/secret/metadata/auth/oauth":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	cfg := api.DefaultConfig()
+	cfg.Address = ts.URL
+	cfg.MaxRetries = 0
+
+	client, err := api.NewClient(cfg)
+	require.NoError(t, err)
+
+	return &Client{client: client}
+}
+
+func TestReadKVMetadata(t *testing.T) {
+	t.Parallel()
+
+	vc := newTreeVault(t)
+
+	md, err := vc.ReadKVMetadata(t.Context(), "secret", "auth/signing-keys")
+	require.NoError(t, err)
+	assert.Equal(t, &KVMetadata{
+		CurrentVersion: 3,
+		Versions:       3,
+		CreatedAt:      time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt:      time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+	}, md)
+
+	_, err = vc.ReadKVMetadata(t.Context(), "secret", "auth/missing")
+	require.ErrorIs(t, err, ErrSecretNotFound)
+
+	_, err = vc.ListKV(t.Context(), "secret", "auth/oauth/")
+	require.ErrorIs(t, err, ErrPermissionDenied)
+
+	_, err = (&Client{}).ListKV(t.Context(), "secret", "auth/")
+	require.ErrorContains(t, err, "client is not connected")
+}
+
+func TestWalkKV(t *testing.T) {
+	t.Parallel()
+
+	vc := newTreeVault(t)
+
+	entries, err := vc.WalkKV(t.Context(), "secret", "/auth", 0)
+	require.NoError(t, err)
+
+	paths := make([]string, 0, len(entries))
+	for _, e := range entries {
+		paths = append(paths, e.Path)
+	}
+
+	assert.Equal(t, []string{
+		"auth/signing-keys",
+		"auth/oauth/",
+		"auth/apikeys/", "auth/apikeys/bot", "auth/apikeys/archive/", "auth/apikeys/archive/old",
+	}, paths)
+
+	// закрытая папка видна в дереве с ошибкой прав, обход продолжается
+	assert.ErrorIs(t, entries[1].Err, ErrPermissionDenied)
+	assert.Equal(t, 3, entries[0].Metadata.CurrentVersion)
+	assert.Equal(t, 1, entries[3].Metadata.Versions)
+	// секрет из списка без метаданных
+	assert.ErrorIs(t, entries[5].Err, ErrSecretNotFound)
+
+	// глубина ограничивает вложенные папки
+	entries, err = vc.WalkKV(t.Context(), "secret", "auth", 1)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+
+	// недоступная начальная папка возвращается одним элементом с ошибкой
+	entries, err = vc.WalkKV(t.Context(), "secret", "auth/oauth", 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "auth/oauth/", entries[0].Path)
+	assert.ErrorIs(t, entries[0].Err, ErrPermissionDenied)
+
+	_, err = (&Client{}).WalkKV(t.Context(), "secret", "auth", 0)
+	require.Error(t, err)
+}
@@ -0,0 +1,95 @@
+package vault
+
+import (
+	"auth-service/internal/service/traceid"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// LatencyObserver получает задержку и результат каждого запроса к Vault. Ошибкой считаются
+// сетевые ошибки и ответы 5xx: они говорят о проблемах самого Vault, а не запроса.
+type LatencyObserver func(latency time.Duration, err error)
+
+// WithLatencyObserver устанавливает функцию, которой сообщается задержка каждого запроса к Vault.
+func WithLatencyObserver(observe LatencyObserver) ClientOption {
+	return func(vc *Client) {
+		vc.latency = observe
+	}
+}
+
+// WithMetrics включает учет времени запросов к Vault в метрике vault_request_duration_seconds
+// реестра registerer. К наблюдениям прикрепляется идентификатор трассировки запроса (exemplar).
+// Если метрика уже зарегистрирована другим клиентом, используется существующая.
+func WithMetrics(registerer prometheus.Registerer) ClientOption {
+	return func(vc *Client) {
+		vc.registerer = registerer
+	}
+}
+
+// registerDuration регистрирует гистограмму времени запросов к Vault.
+func registerDuration(registerer prometheus.Registerer) (*prometheus.HistogramVec, error) {
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "vault_request_duration_seconds",
+		Help:    "Время запросов к Vault в секундах по HTTP методу и коду ответа (error - ответа нет).",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	err := registerer.Register(duration)
+	if err == nil {
+		return duration, nil
+	}
+
+	var already prometheus.AlreadyRegisteredError
+	if !errors.As(err, &already) {
+		return nil, err
+	}
+
+	existing, ok := already.ExistingCollector.(*prometheus.HistogramVec)
+	if !ok {
+		return nil, err
+	}
+
+	return existing, nil
+}
+
+// latencyTransport измеряет задержку запросов к Vault.
+type latencyTransport struct {
+	next     http.RoundTripper
+	observe  LatencyObserver
+	duration *prometheus.HistogramVec
+}
+
+func (t *latencyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+
+	resp, err := t.next.RoundTrip(req)
+
+	elapsed := time.Since(start)
+
+	if t.duration != nil {
+		code := "error"
+		if err == nil {
+			code = strconv.Itoa(resp.StatusCode)
+		}
+
+		traceid.Observe(req.Context(), t.duration.WithLabelValues(req.Method, code), elapsed.Seconds())
+	}
+
+	if t.observe == nil {
+		return resp, err
+	}
+
+	observed := err
+	if err == nil && resp.StatusCode >= http.StatusInternalServerError {
+		observed = fmt.Errorf("vault: status %d", resp.StatusCode)
+	}
+
+	t.observe(elapsed, observed)
+
+	return resp, err
+}
@@ -0,0 +1,150 @@
+package vault
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/sirupsen/logrus"
+)
+
+// WithTLSPin включает проверку сертификата Vault по отпечатку (trust on first use): при первом
+// подключении SHA-256 отпечаток предъявленного сертификата записывается в file, дальше принимается
+// только сертификат с этим отпечатком. Цепочка и имя в сертификате не проверяются, поэтому CA не нужен.
+// Замена для insecure_skip_tls в окружениях без PKI: подмена сервера после первого подключения
+// обнаруживается. После плановой замены сертификата Vault файл нужно удалить.
+func WithTLSPin(file string) ClientOption {
+	return func(vc *Client) {
+		vc.pinFile = file
+	}
+}
+
+// validatePin проверяет, что проверка по отпечатку не сочетается с другими способами проверки сервера.
+func (vc *Client) validatePin() error {
+	if vc.insecureSkipTLS {
+		return errors.New("tls pin and insecure skip TLS are mutually exclusive")
+	}
+
+	if vc.caPath != "" {
+		return errors.New("tls pin and CA certificate are mutually exclusive")
+	}
+
+	if !strings.HasPrefix(vc.address, "https://") {
+		return errors.New("tls pin requires https address")
+	}
+
+	return nil
+}
+
+// certPinner проверяет отпечаток сертификата Vault и запоминает его при первом подключении.
+type certPinner struct {
+	file string
+
+	mu          sync.Mutex
+	fingerprint []byte
+}
+
+// newCertPinner читает отпечаток из file. Если файла нет, отпечаток будет записан при первом подключении.
+func newCertPinner(file string) (*certPinner, error) {
+	p := &certPinner{file: file}
+
+	data, err := os.ReadFile(file) //nolint:gosec // путь из конфигурации сервиса
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("vault: error read tls pin: %w", err)
+	}
+
+	fingerprint, err := parseFingerprint(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("vault: invalid tls pin in %s: %w", file, err)
+	}
+
+	p.fingerprint = fingerprint
+
+	return p, nil
+}
+
+// parseFingerprint разбирает SHA-256 отпечаток в hex, в том числе в формате
+// openssl x509 -fingerprint -sha256 (с двоеточиями и префиксом).
+func parseFingerprint(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+
+	if _, value, ok := strings.Cut(s, "="); ok {
+		s = value
+	}
+
+	fingerprint, err := hex.DecodeString(strings.ReplaceAll(s, ":", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fingerprint) != sha256.Size {
+		return nil, fmt.Errorf("expected %d bytes, got %d", sha256.Size, len(fingerprint))
+	}
+
+	return fingerprint, nil
+}
+
+// verify проверяет сертификат сервера из TLS рукопожатия.
+func (p *certPinner) verify(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("vault: server presented no certificate")
+	}
+
+	sum := sha256.Sum256(cs.PeerCertificates[0].Raw)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.fingerprint == nil {
+		if err := os.WriteFile(p.file, []byte(hex.EncodeToString(sum[:])+"\n"), 0o600); err != nil {
+			return fmt.Errorf("vault: error write tls pin: %w", err)
+		}
+
+		p.fingerprint = sum[:]
+
+		logrus.WithFields(logrus.Fields{
+			"file":        p.file,
+			"fingerprint": hex.EncodeToString(sum[:]),
+			"subject":     cs.PeerCertificates[0].Subject.String(),
+		}).Warn("vault certificate pinned on first use")
+
+		return nil
+	}
+
+	if subtle.ConstantTimeCompare(p.fingerprint, sum[:]) != 1 {
+		return fmt.Errorf("vault: certificate fingerprint %s does not match pinned %s (remove %s after a planned certificate change)",
+			hex.EncodeToString(sum[:]), hex.EncodeToString(p.fingerprint), p.file)
+	}
+
+	return nil
+}
+
+// configurePin включает проверку по отпечатку в уже настроенной TLS конфигурации клиента.
+// Стандартная проверка цепочки при этом отключена: ее заменяет сравнение отпечатка.
+func (vc *Client) configurePin(config *api.Config) error {
+	pinner, err := newCertPinner(vc.pinFile)
+	if err != nil {
+		return err
+	}
+
+	transport, ok := config.HttpClient.Transport.(*http.Transport)
+	if !ok || transport.TLSClientConfig == nil {
+		return errors.New("vault: unexpected transport for tls pin")
+	}
+
+	transport.TLSClientConfig.VerifyConnection = pinner.verify
+
+	return nil
+}
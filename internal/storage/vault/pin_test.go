@@ -0,0 +1,113 @@
+package vault
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPinTestServer запускает TLS сервер с сертификатом httptest или, если certs переданы, с ними.
+func newPinTestServer(t *testing.T, certs ...tls.Certificate) *httptest.Server {
+	t.Helper()
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"initialized":true,"sealed":false,"version":"1.18.0"}`))
+	}))
+
+	if len(certs) > 0 {
+		ts.TLS = &tls.Config{Certificates: certs, MinVersion: tls.VersionTLS12}
+	}
+
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func TestNewClient_TLSPin(t *testing.T) {
+	t.Parallel()
+
+	pin := filepath.Join(t.TempDir(), "vault.pin")
+
+	_, err := NewClient(WithAddress("https://vault:8200"), WithToken("token"), WithTLSPin(pin))
+	require.NoError(t, err, "CA certificate is not required")
+
+	_, err = NewClient(WithAddress("https://vault:8200"), WithToken("token"), WithTLSPin(pin), WithInsecureSkipTLS(true))
+	require.ErrorContains(t, err, "mutually exclusive")
+
+	_, err = NewClient(WithAddress("https://vault:8200"), WithToken("token"), WithTLSPin(pin), WithTLSConfig("ca.pem", "", ""))
+	require.ErrorContains(t, err, "mutually exclusive")
+
+	_, err = NewClient(WithAddress("http://vault:8200"), WithToken("token"), WithTLSPin(pin))
+	require.ErrorContains(t, err, "requires https")
+}
+
+func TestWithTLSPin(t *testing.T) {
+	t.Parallel()
+
+	ts := newPinTestServer(t)
+	pin := filepath.Join(t.TempDir(), "vault.pin")
+
+	connect := func(address string) error {
+		vc, err := NewClient(WithAddress(address), WithToken("token"), WithTLSPin(pin))
+		require.NoError(t, err)
+
+		return vc.Connect()
+	}
+
+	// первое подключение запоминает отпечаток
+	require.NoError(t, connect(ts.URL))
+
+	data, err := os.ReadFile(pin)
+	require.NoError(t, err)
+
+	sum := sha256.Sum256(ts.Certificate().Raw)
+	assert.Equal(t, hex.EncodeToString(sum[:])+"\n", string(data))
+
+	// повторное подключение к тому же серверу проходит по сохраненному отпечатку
+	require.NoError(t, connect(ts.URL))
+
+	// другой сертификат отклоняется
+	cert, err := tls.LoadX509KeyPair(filepath.Join("testdata", "client-cert.pem"), filepath.Join("testdata", "client-key.pem"))
+	require.NoError(t, err)
+
+	other := newPinTestServer(t, cert)
+	require.ErrorContains(t, connect(other.URL), "does not match pinned")
+
+	require.NoError(t, os.WriteFile(pin, []byte("not a fingerprint"), 0o600))
+	require.ErrorContains(t, connect(ts.URL), "invalid tls pin")
+}
+
+func TestParseFingerprint(t *testing.T) {
+	t.Parallel()
+
+	sum := sha256.Sum256([]byte("certificate"))
+	plain := hex.EncodeToString(sum[:])
+
+	var openssl []string
+	for i := 0; i < len(plain); i += 2 {
+		openssl = append(openssl, strings.ToUpper(plain[i:i+2]))
+	}
+
+	for _, s := range []string{plain, plain + "\n", "sha256 Fingerprint=" + strings.Join(openssl, ":")} {
+		got, err := parseFingerprint(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, sum[:], got)
+	}
+
+	_, err := parseFingerprint("abcd")
+	require.Error(t, err)
+
+	_, err = parseFingerprint("zz")
+	require.Error(t, err)
+}
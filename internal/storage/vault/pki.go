@@ -0,0 +1,144 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// SignRequest - запрос на подпись CSR через PKI secrets engine.
+type SignRequest struct {
+	CSR     string        // CSR в формате PEM
+	URISANs []string      // URI SAN выпускаемого сертификата (например, SPIFFE ID)
+	TTL     time.Duration // Время жизни сертификата. 0 - TTL роли
+}
+
+// IssueRequest - запрос на выпуск сертификата с ключом через PKI secrets engine.
+type IssueRequest struct {
+	CommonName string        // CN сертификата
+	AltNames   []string      // DNS SAN
+	IPSANs     []string      // IP SAN
+	TTL        time.Duration // Время жизни сертификата. 0 - TTL роли
+}
+
+// Certificate - подписанный сертификат.
+type Certificate struct {
+	Certificate  string    // Сертификат в формате PEM
+	PrivateKey   string    // Закрытый ключ в формате PEM, только для выпущенных Vault сертификатов
+	IssuingCA    string    // Сертификат выпустившего CA в формате PEM
+	CAChain      []string  // Цепочка CA в формате PEM
+	SerialNumber string    // Серийный номер
+	ExpiresAt    time.Time // Срок действия
+}
+
+// SignCertificate подписывает CSR по пути роли PKI (например, "pki_int/sign/spiffe").
+func (vc *Client) SignCertificate(ctx context.Context, path string, req SignRequest) (*Certificate, error) {
+	vc.mu.RLock()
+	client := vc.client
+	vc.mu.RUnlock()
+
+	if client == nil {
+		return nil, errors.New("vault: client is not connected")
+	}
+
+	data := map[string]interface{}{
+		"csr":                  req.CSR,
+		"exclude_cn_from_sans": true,
+	}
+
+	if len(req.URISANs) > 0 {
+		data["uri_sans"] = req.URISANs
+	}
+
+	if req.TTL > 0 {
+		data["ttl"] = req.TTL.String()
+	}
+
+	secret, err := client.Logical().WriteWithContext(ctx, path, data)
+	if err != nil {
+		return nil, fmt.Errorf("vault: error sign certificate %s: %w", path, err)
+	}
+
+	return parseCertificate(secret, path)
+}
+
+// IssueCertificate выпускает сертификат вместе с закрытым ключом по пути роли PKI (например, "pki_int/issue/auth-service").
+func (vc *Client) IssueCertificate(ctx context.Context, path string, req IssueRequest) (*Certificate, error) {
+	vc.mu.RLock()
+	client := vc.client
+	vc.mu.RUnlock()
+
+	if client == nil {
+		return nil, errors.New("vault: client is not connected")
+	}
+
+	data := map[string]interface{}{
+		"common_name": req.CommonName,
+	}
+
+	if len(req.AltNames) > 0 {
+		data["alt_names"] = strings.Join(req.AltNames, ",")
+	}
+
+	if len(req.IPSANs) > 0 {
+		data["ip_sans"] = strings.Join(req.IPSANs, ",")
+	}
+
+	if req.TTL > 0 {
+		data["ttl"] = req.TTL.String()
+	}
+
+	secret, err := client.Logical().WriteWithContext(ctx, path, data)
+	if err != nil {
+		return nil, fmt.Errorf("vault: error issue certificate %s: %w", path, err)
+	}
+
+	cert, err := parseCertificate(secret, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if cert.PrivateKey == "" {
+		return nil, fmt.Errorf("vault: private key is missing in response from %s", path)
+	}
+
+	return cert, nil
+}
+
+// parseCertificate разбирает ответ эндпоинтов sign и issue PKI secrets engine.
+func parseCertificate(secret *api.Secret, path string) (*Certificate, error) {
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("vault: empty response from %s", path)
+	}
+
+	cert := &Certificate{}
+	cert.Certificate, _ = secret.Data["certificate"].(string)
+	cert.IssuingCA, _ = secret.Data["issuing_ca"].(string)
+	cert.SerialNumber, _ = secret.Data["serial_number"].(string)
+	cert.PrivateKey, _ = secret.Data["private_key"].(string)
+
+	if cert.Certificate == "" {
+		return nil, fmt.Errorf("vault: certificate is missing in response from %s", path)
+	}
+
+	if chain, ok := secret.Data["ca_chain"].([]interface{}); ok {
+		for _, c := range chain {
+			if s, ok := c.(string); ok {
+				cert.CAChain = append(cert.CAChain, s)
+			}
+		}
+	}
+
+	if exp, ok := secret.Data["expiration"].(json.Number); ok {
+		if sec, err := exp.Int64(); err == nil {
+			cert.ExpiresAt = time.Unix(sec, 0)
+		}
+	}
+
+	return cert, nil
+}
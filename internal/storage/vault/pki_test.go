@@ -0,0 +1,152 @@
+package vault
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignCertificate(t *testing.T) {
+	t.Parallel()
+
+	var got map[string]interface{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/v1/pki_int/sign/spiffe":
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+			_, _ = w.Write([]byte(`{"data":{
+				"certificate":"CERT",
+				"issuing_ca":"CA",
+				"ca_chain":["CA","ROOT"],
+				"serial_number":"01:02",
+				"expiration":1767225600
+			}}`))
+		case "/v1/pki_int/sign/empty":
+			_, _ = w.Write([]byte(`{"data":{"issuing_ca":"CA"}}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	cfg := api.DefaultConfig()
+	cfg.Address = ts.URL
+	cfg.MaxRetries = 0
+
+	client, err := api.NewClient(cfg)
+	require.NoError(t, err)
+
+	vc := &Client{client: client}
+
+	cert, err := vc.SignCertificate(t.Context(), "pki_int/sign/spiffe", SignRequest{
+		CSR:     "CSR",
+		URISANs: []string{"spiffe://example.org/bot"},
+		TTL:     5 * time.Minute,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, &Certificate{
+		Certificate:  "CERT",
+		IssuingCA:    "CA",
+		CAChain:      []string{"CA", "ROOT"},
+		SerialNumber: "01:02",
+		ExpiresAt:    time.Unix(1767225600, 0),
+	}, cert)
+	assert.Equal(t, map[string]interface{}{
+		"csr":                  "CSR",
+		"exclude_cn_from_sans": true,
+		"uri_sans":             []interface{}{"spiffe://example.org/bot"},
+		"ttl":                  "5m0s",
+	}, got)
+
+	_, err = vc.SignCertificate(t.Context(), "pki_int/sign/empty", SignRequest{CSR: "CSR"})
+	require.ErrorContains(t, err, "certificate is missing")
+
+	_, err = vc.SignCertificate(t.Context(), "pki_int/sign/other", SignRequest{CSR: "CSR"})
+	require.ErrorContains(t, err, "permission denied")
+
+	_, err = (&Client{}).SignCertificate(t.Context(), "pki_int/sign/spiffe", SignRequest{})
+	require.ErrorContains(t, err, "client is not connected")
+}
+
+func TestIssueCertificate(t *testing.T) {
+	t.Parallel()
+
+	var got map[string]interface{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/v1/pki_int/issue/auth-service":
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+			_, _ = w.Write([]byte(`{"data":{
+				"certificate":"CERT",
+				"private_key":"KEY",
+				"issuing_ca":"CA",
+				"serial_number":"01",
+				"expiration":1767225600
+			}}`))
+		case "/v1/pki_int/issue/nokey":
+			_, _ = w.Write([]byte(`{"data":{"certificate":"CERT"}}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	cfg := api.DefaultConfig()
+	cfg.Address = ts.URL
+	cfg.MaxRetries = 0
+
+	client, err := api.NewClient(cfg)
+	require.NoError(t, err)
+
+	vc := &Client{client: client}
+
+	cert, err := vc.IssueCertificate(t.Context(), "pki_int/issue/auth-service", IssueRequest{
+		CommonName: "auth.internal",
+		AltNames:   []string{"auth.internal", "auth"},
+		IPSANs:     []string{"10.0.0.1"},
+		TTL:        time.Hour,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, &Certificate{
+		Certificate:  "CERT",
+		PrivateKey:   "KEY",
+		IssuingCA:    "CA",
+		SerialNumber: "01",
+		ExpiresAt:    time.Unix(1767225600, 0),
+	}, cert)
+	assert.Equal(t, map[string]interface{}{
+		"common_name": "auth.internal",
+		"alt_names":   "auth.internal,auth",
+		"ip_sans":     "10.0.0.1",
+		"ttl":         "1h0m0s",
+	}, got)
+
+	_, err = vc.IssueCertificate(t.Context(), "pki_int/issue/nokey", IssueRequest{CommonName: "auth"})
+	require.ErrorContains(t, err, "private key is missing")
+
+	_, err = vc.IssueCertificate(t.Context(), "pki_int/issue/other", IssueRequest{CommonName: "auth"})
+	require.ErrorContains(t, err, "permission denied")
+
+	_, err = (&Client{}).IssueCertificate(t.Context(), "pki_int/issue/auth-service", IssueRequest{})
+	require.ErrorContains(t, err, "client is not connected")
+}
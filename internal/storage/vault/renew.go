@@ -0,0 +1,110 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrTokenNotRenewed - токен сервиса больше нельзя продлить: Vault отказал в продлении или
+// токен достиг максимального срока жизни. После истечения токена все запросы к Vault будут отклонены.
+var ErrTokenNotRenewed = errors.New("vault: token can no longer be renewed")
+
+// WithRenewalIncrement устанавливает срок, на который запрашивается продление токена.
+// Vault может выдать меньший срок, если он ограничен политикой или максимальным сроком токена.
+// По умолчанию запрашивается исходный срок токена.
+func WithRenewalIncrement(increment time.Duration) ClientOption {
+	return func(vc *Client) {
+		vc.renewIncrement = increment
+	}
+}
+
+// WithRenewalFailure устанавливает функцию, которой сообщается, что токен больше нельзя продлить.
+// Вызывается из WatchToken до возврата ошибки, например, чтобы остановить сервис или получить новый токен.
+func WithRenewalFailure(onFailure func(error)) ClientOption {
+	return func(vc *Client) {
+		vc.onRenewalFailure = onFailure
+	}
+}
+
+// WatchToken продлевает токен сервиса до отмены ctx. Продление начинается сразу и повторяется
+// по истечении 2/3 срока токена. Токен без срока (например, корневой) продлевать не нужно,
+// тогда метод сразу возвращает nil. Если токен больше нельзя продлить, вызывает функцию
+// WithRenewalFailure и возвращает ошибку, обернутую в ErrTokenNotRenewed.
+func (vc *Client) WatchToken(ctx context.Context) error {
+	client, err := vc.apiClient()
+	if err != nil {
+		return err
+	}
+
+	self, err := client.Auth().Token().LookupSelfWithContext(ctx)
+	if err != nil {
+		return wrapError("lookup", "auth/token/lookup-self", err)
+	}
+
+	ttl, err := self.TokenTTL()
+	if err != nil {
+		return fmt.Errorf("vault: error parse token ttl: %w", err)
+	}
+
+	if ttl == 0 {
+		logrus.Info("vault token has no expiration, renewal is not needed")
+
+		return nil
+	}
+
+	renewable, err := self.TokenIsRenewable()
+	if err != nil {
+		return fmt.Errorf("vault: error parse token renewable flag: %w", err)
+	}
+
+	// без продления watcher дождется конца срока токена и сообщит о завершении
+	if !renewable {
+		logrus.WithField("ttl", ttl).Warn("vault token is not renewable")
+	}
+
+	watcher, err := client.NewLifetimeWatcher(&api.LifetimeWatcherInput{
+		Secret: &api.Secret{
+			Auth: &api.SecretAuth{
+				ClientToken:   client.Token(),
+				Renewable:     renewable,
+				LeaseDuration: int(ttl.Seconds()),
+			},
+		},
+		Increment: int(vc.renewIncrement.Seconds()),
+	})
+	if err != nil {
+		return fmt.Errorf("vault: error create token watcher: %w", err)
+	}
+
+	go watcher.Start()
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case renewal := <-watcher.RenewCh():
+			logrus.WithFields(logrus.Fields{
+				"ttl":        time.Duration(renewal.Secret.Auth.LeaseDuration) * time.Second,
+				"renewed_at": renewal.RenewedAt,
+			}).Info("vault token renewed")
+		case err := <-watcher.DoneCh():
+			// watcher завершается без ошибки, когда токен подходит к концу срока и продлить его нельзя
+			failure := ErrTokenNotRenewed
+			if err != nil {
+				failure = fmt.Errorf("%w: %w", ErrTokenNotRenewed, err)
+			}
+
+			if vc.onRenewalFailure != nil {
+				vc.onRenewalFailure(failure)
+			}
+
+			return failure
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package vault
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTokenVault возвращает клиент фейкового Vault: lookup-self отвечает токеном со сроком ttl
+// секунд, renew-self - ответом renew со статусом status. Возвращает счетчик запросов продления.
+func newTokenVault(t *testing.T, ttl int, renewable bool, status int, renew string) (*Client, *atomic.Int32) {
+	t.Helper()
+
+	var renewals atomic.Int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/v1/auth/token/lookup-self":
+			_, _ = w.Write([]byte(`{"data":{"ttl":` + strconv.Itoa(ttl) + `,"renewable":` + strconv.FormatBool(renewable) + `}}`))
+		case "/v1/auth/token/renew-self":
+			renewals.Add(1)
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(renew))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	cfg := api.DefaultConfig()
+	cfg.Address = ts.URL
+	cfg.MaxRetries = 0
+
+	client, err := api.NewClient(cfg)
+	require.NoError(t, err)
+
+	client.SetToken("vault-token")
+
+	return &Client{client: client}, &renewals
+}
+
+func TestWatchToken(t *testing.T) {
+	t.Parallel()
+
+	t.Run("positive case: token is renewed until context is canceled", func(t *testing.T) {
+		t.Parallel()
+
+		vc, renewals := newTokenVault(t, 3600, true, http.StatusOK,
+			`{"auth":{"client_token":"vault-token","renewable":true,"lease_duration":3600}}`)
+
+		var failed atomic.Bool
+
+		vc.onRenewalFailure = func(error) { failed.Store(true) }
+
+		ctx, cancel := context.WithCancel(t.Context())
+		done := make(chan error, 1)
+
+		go func() { done <- vc.WatchToken(ctx) }()
+
+		assert.Eventually(t, func() bool { return renewals.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+		cancel()
+		require.NoError(t, <-done)
+		assert.False(t, failed.Load())
+	})
+
+	t.Run("positive case: token without expiration", func(t *testing.T) {
+		t.Parallel()
+
+		vc, renewals := newTokenVault(t, 0, false, http.StatusOK, `{}`)
+
+		require.NoError(t, vc.WatchToken(t.Context()))
+		assert.Zero(t, renewals.Load())
+	})
+
+	t.Run("negative case: renewal fails", func(t *testing.T) {
+		t.Parallel()
+
+		// срок токена короче интервала повтора: после первой ошибки продлить токен уже не успеть
+		vc, _ := newTokenVault(t, 1, true, http.StatusInternalServerError, `{"errors":["internal error"]}`)
+
+		var failure error
+
+		vc.onRenewalFailure = func(err error) { failure = err }
+
+		err := vc.WatchToken(t.Context())
+		require.ErrorIs(t, err, ErrTokenNotRenewed)
+		assert.Equal(t, err, failure)
+	})
+
+	t.Run("negative case: token is not renewable", func(t *testing.T) {
+		t.Parallel()
+
+		vc, renewals := newTokenVault(t, 1, false, http.StatusOK, `{}`)
+
+		require.ErrorIs(t, vc.WatchToken(t.Context()), ErrTokenNotRenewed)
+		assert.Zero(t, renewals.Load())
+	})
+
+	t.Run("negative case: client is not connected", func(t *testing.T) {
+		t.Parallel()
+
+		require.ErrorContains(t, (&Client{}).WatchToken(t.Context()), "client is not connected")
+	})
+}
@@ -1,25 +1,39 @@
 package vault
 
 import (
+	"auth-service/internal/service/tracing"
 	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/vault/api"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Client - клиент для работы с Vault.
 type Client struct {
+	mu              sync.RWMutex
 	client          *api.Client
 	address         string
 	token           string
 	insecureSkipTLS bool
+	pinFile         string
 	caPath          string
 	clientCertPath  string
 	clientKeyPath   string
+	latency         LatencyObserver
+	registerer      prometheus.Registerer
+	duration        *prometheus.HistogramVec
+	tracing         trace.TracerProvider
+
+	renewIncrement   time.Duration
+	onRenewalFailure func(error)
 }
 
 // ClientOption - опция для настройки клиента Vault.
@@ -71,7 +85,11 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 		return nil, errors.New("token is required")
 	}
 
-	if !vaultClient.insecureSkipTLS {
+	if vaultClient.pinFile != "" {
+		if err := vaultClient.validatePin(); err != nil {
+			return nil, err
+		}
+	} else if !vaultClient.insecureSkipTLS {
 		if vaultClient.caPath == "" {
 			return nil, errors.New("CA certificate is required")
 		}
@@ -82,6 +100,19 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 		return nil, errors.New("client certificate and key must be provided together")
 	}
 
+	if vaultClient.renewIncrement < 0 {
+		return nil, errors.New("renewal increment must not be negative")
+	}
+
+	if vaultClient.registerer != nil {
+		duration, err := registerDuration(vaultClient.registerer)
+		if err != nil {
+			return nil, fmt.Errorf("vault: error register metrics: %w", err)
+		}
+
+		vaultClient.duration = duration
+	}
+
 	return vaultClient, nil
 }
 
@@ -151,13 +182,14 @@ func (vc *Client) configureClientCertificates(tlsConfig *api.TLSConfig) error {
 
 // shouldConfigureTLS проверяет, нужно ли настраивать TLS.
 func (vc *Client) shouldConfigureTLS() bool {
-	return vc.insecureSkipTLS || vc.caPath != "" || (vc.clientCertPath != "" && vc.clientKeyPath != "")
+	return vc.insecureSkipTLS || vc.pinFile != "" || vc.caPath != "" || (vc.clientCertPath != "" && vc.clientKeyPath != "")
 }
 
 // configureTLS настраивает TLS конфигурацию для клиента Vault.
 func (vc *Client) configureTLS(config *api.Config) error {
 	tlsConfig := &api.TLSConfig{
-		Insecure: vc.insecureSkipTLS,
+		// при проверке по отпечатку цепочка не проверяется, см. configurePin
+		Insecure: vc.insecureSkipTLS || vc.pinFile != "",
 	}
 
 	if err := vc.configureCA(tlsConfig); err != nil {
@@ -176,6 +208,10 @@ func (vc *Client) configureTLS(config *api.Config) error {
 		return fmt.Errorf("vault: error configuring TLS: %w", err)
 	}
 
+	if vc.pinFile != "" {
+		return vc.configurePin(config)
+	}
+
 	return nil
 }
 
@@ -188,6 +224,21 @@ func (vc *Client) createAPIClient() (*api.Client, error) {
 		return nil, err
 	}
 
+	if vc.latency != nil || vc.duration != nil {
+		config.HttpClient.Transport = &latencyTransport{
+			next:     config.HttpClient.Transport,
+			observe:  vc.latency,
+			duration: vc.duration,
+		}
+	}
+
+	if vc.tracing != nil {
+		config.HttpClient.Transport = &tracingTransport{
+			next:   config.HttpClient.Transport,
+			tracer: tracing.Tracer(vc.tracing),
+		}
+	}
+
 	client, err := api.NewClient(config)
 	if err != nil {
 		return nil, fmt.Errorf("vault: error creating client: %w", err)
@@ -203,6 +254,7 @@ func (vc *Client) verifyConnection(client *api.Client) error {
 	logrus.WithFields(logrus.Fields{
 		"address":           vc.address,
 		"insecure_skip_tls": vc.insecureSkipTLS,
+		"tls_pin":           vc.pinFile,
 	}).Info("trying to connect to vault...")
 
 	health, err := client.Sys().Health()
@@ -231,16 +283,67 @@ func (vc *Client) Connect() error {
 		return err
 	}
 
+	vc.mu.Lock()
 	vc.client = client
+	vc.mu.Unlock()
+
+	return nil
+}
+
+// Health проверяет, что Vault отвечает и не запечатан.
+// Используется реестром зависимостей для периодической проверки.
+func (vc *Client) Health(ctx context.Context) error {
+	vc.mu.RLock()
+	client := vc.client
+	vc.mu.RUnlock()
+
+	if client == nil {
+		return errors.New("vault: client is not connected")
+	}
+
+	health, err := client.Sys().HealthWithContext(ctx)
+	if err != nil {
+		return fmt.Errorf("vault: health check failed: %w", err)
+	}
+
+	if health.Sealed {
+		return errors.New("vault: vault is sealed")
+	}
 
 	return nil
 }
 
+// ServerTime возвращает время сервера Vault из sys/health (с точностью до секунды).
+// Используется для обнаружения расхождения локальных часов.
+func (vc *Client) ServerTime(ctx context.Context) (time.Time, error) {
+	vc.mu.RLock()
+	client := vc.client
+	vc.mu.RUnlock()
+
+	if client == nil {
+		return time.Time{}, errors.New("vault: client is not connected")
+	}
+
+	health, err := client.Sys().HealthWithContext(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("vault: health check failed: %w", err)
+	}
+
+	if health.ServerTimeUTC == 0 {
+		return time.Time{}, errors.New("vault: server time is not reported")
+	}
+
+	return time.Unix(health.ServerTimeUTC, 0), nil
+}
+
 // Stop останавливает клиент Vault.
 // Vault API клиент использует стандартный http.Client, который автоматически
 // управляет соединениями. При завершении работы приложения все соединения
 // будут закрыты автоматически. Здесь мы просто обнуляем ссылку на клиент.
 func (vc *Client) Stop(ctx context.Context) error {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
 	if vc.client == nil {
 		return nil
 	}
@@ -1,13 +1,21 @@
 package vault
 
 import (
+	"auth-service/internal/service/traceid"
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/vault/api"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -149,6 +157,19 @@ func TestNewClient(t *testing.T) {
 				require.ErrorContains(t, err, "CA certificate is required")
 			},
 		},
+		{
+			name: "error case: negative renewal increment",
+			options: []ClientOption{
+				WithAddress("https://localhost:8200"),
+				WithToken("vault-token"),
+				WithInsecureSkipTLS(true),
+				WithRenewalIncrement(-time.Hour),
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.Error(t, err)
+				require.ErrorContains(t, err, "renewal increment must not be negative")
+			},
+		},
 		{
 			name: "positive case: client cert and key without CA when insecureSkipTLS is true",
 			options: []ClientOption{
@@ -241,6 +262,110 @@ func TestStop(t *testing.T) {
 	}
 }
 
+//nolint:funlen // длинный тест - это ок
+func TestHealth(t *testing.T) {
+	t.Parallel()
+
+	newVault := func(t *testing.T, status int, body string) *api.Client {
+		t.Helper()
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+		}))
+		t.Cleanup(ts.Close)
+
+		cfg := api.DefaultConfig()
+		cfg.Address = ts.URL
+
+		client, err := api.NewClient(cfg)
+		require.NoError(t, err)
+
+		return client
+	}
+
+	testCases := []struct {
+		name         string
+		createClient func(t *testing.T) *api.Client
+		wantErr      require.ErrorAssertionFunc
+	}{
+		{
+			name: "positive case",
+			createClient: func(t *testing.T) *api.Client {
+				t.Helper()
+
+				return newVault(t, http.StatusOK, `{"initialized":true,"sealed":false,"version":"1.18.0"}`)
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "error case: vault is sealed",
+			createClient: func(t *testing.T) *api.Client {
+				t.Helper()
+
+				return newVault(t, 299, `{"initialized":true,"sealed":true}`) // vault отвечает sealedcode=299
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "vault is sealed")
+			},
+		},
+		{
+			name: "error case: client is not connected",
+			createClient: func(t *testing.T) *api.Client {
+				t.Helper()
+
+				return nil
+			},
+			wantErr: func(t require.TestingT, err error, i ...interface{}) {
+				require.ErrorContains(t, err, "client is not connected")
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			vc := &Client{client: tt.createClient(t)}
+
+			tt.wantErr(t, vc.Health(t.Context()))
+		})
+	}
+}
+
+func TestServerTime(t *testing.T) {
+	t.Parallel()
+
+	newClient := func(t *testing.T, body string) *Client {
+		t.Helper()
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(body))
+		}))
+		t.Cleanup(ts.Close)
+
+		cfg := api.DefaultConfig()
+		cfg.Address = ts.URL
+
+		client, err := api.NewClient(cfg)
+		require.NoError(t, err)
+
+		return &Client{client: client}
+	}
+
+	got, err := newClient(t, `{"initialized":true,"sealed":false,"server_time_utc":1792152000}`).ServerTime(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1792152000), got.Unix())
+
+	_, err = newClient(t, `{"initialized":true,"sealed":false}`).ServerTime(t.Context())
+	require.ErrorContains(t, err, "server time is not reported")
+
+	_, err = (&Client{}).ServerTime(t.Context())
+	require.ErrorContains(t, err, "client is not connected")
+}
+
 //nolint:funlen // длинный тест - это ок
 func TestValidateAndResolvePath(t *testing.T) {
 	t.Parallel()
@@ -573,3 +698,89 @@ func TestConfigureTLS(t *testing.T) {
 		})
 	}
 }
+
+func TestLatencyObserver(t *testing.T) {
+	t.Parallel()
+
+	var status atomic.Int32
+
+	status.Store(http.StatusOK)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(int(status.Load()))
+		_, _ = w.Write([]byte(`{"initialized":true,"sealed":false,"version":"1.18.0"}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	var errs []error
+
+	vc := &Client{address: ts.URL, latency: func(latency time.Duration, err error) {
+		assert.Positive(t, latency)
+
+		errs = append(errs, err)
+	}}
+
+	client, err := vc.createAPIClient()
+	require.NoError(t, err)
+
+	client.SetMaxRetries(0)
+	vc.client = client
+
+	require.NoError(t, vc.Health(t.Context()))
+
+	status.Store(http.StatusInternalServerError)
+
+	require.Error(t, vc.Health(t.Context()))
+
+	require.Len(t, errs, 2)
+	require.NoError(t, errs[0])
+	require.EqualError(t, errs[1], "vault: status 500")
+}
+
+func TestWithMetrics(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"initialized":true,"sealed":false,"version":"1.18.0"}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	registry := prometheus.NewRegistry()
+
+	vc, err := NewClient(WithAddress(ts.URL), WithToken("token"), WithInsecureSkipTLS(true), WithMetrics(registry))
+	require.NoError(t, err)
+
+	// второй клиент использует уже зарегистрированную метрику
+	other, err := NewClient(WithAddress(ts.URL), WithToken("token"), WithInsecureSkipTLS(true), WithMetrics(registry))
+	require.NoError(t, err)
+	assert.Same(t, vc.duration, other.duration)
+
+	client, err := vc.createAPIClient()
+	require.NoError(t, err)
+
+	vc.client = client
+
+	ctx := traceid.NewContext(t.Context(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, vc.Health(ctx))
+
+	assert.Equal(t, 1, testutil.CollectAndCount(vc.duration))
+
+	var m dto.Metric
+
+	histogram, ok := vc.duration.WithLabelValues(http.MethodGet, "200").(prometheus.Metric)
+	require.True(t, ok)
+	require.NoError(t, histogram.Write(&m))
+	assert.Equal(t, uint64(1), m.GetHistogram().GetSampleCount())
+
+	var traces []string
+
+	for _, b := range m.GetHistogram().GetBucket() {
+		for _, l := range b.GetExemplar().GetLabel() {
+			traces = append(traces, l.GetValue())
+		}
+	}
+
+	assert.Equal(t, []string{"4bf92f3577b34da6a3ce929d0e0e4736"}, traces)
+}
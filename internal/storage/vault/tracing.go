@@ -0,0 +1,59 @@
+package vault
+
+import (
+	"auth-service/internal/service/tracing"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithTracing включает спаны запросов к Vault. Контекст трассировки передается в Vault
+// заголовком traceparent: Vault может писать его в audit log (sys/config/auditing/request-headers).
+func WithTracing(provider trace.TracerProvider) ClientOption {
+	return func(vc *Client) {
+		vc.tracing = provider
+	}
+}
+
+// tracingTransport создает клиентский спан на каждый запрос к Vault. Путь запроса пишется
+// в атрибуты, но не в имя спана: пути содержат имена секретов и ролей.
+type tracingTransport struct {
+	next   http.RoundTripper
+	tracer trace.Tracer
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := t.tracer.Start(req.Context(), "vault "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.URLPath(req.URL.Path),
+			semconv.ServerAddress(req.URL.Hostname()),
+		),
+	)
+	defer span.End()
+
+	// RoundTripper не должен менять запрос вызывающего
+	req = req.Clone(ctx)
+	tracing.Propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "request failed")
+
+		return resp, err
+	}
+
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, strconv.Itoa(resp.StatusCode))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,75 @@
+package vault
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWithTracing(t *testing.T) {
+	t.Parallel()
+
+	var (
+		status      atomic.Int32
+		traceparent atomic.Value
+	)
+
+	status.Store(http.StatusOK)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent.Store(r.Header.Get("Traceparent"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(int(status.Load()))
+		_, _ = w.Write([]byte(`{"initialized":true,"sealed":false,"version":"1.18.0"}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	vc, err := NewClient(WithAddress(ts.URL), WithToken("token"), WithInsecureSkipTLS(true), WithTracing(provider))
+	require.NoError(t, err)
+
+	client, err := vc.createAPIClient()
+	require.NoError(t, err)
+
+	client.SetMaxRetries(0)
+	vc.client = client
+
+	ctx, parent := provider.Tracer("test").Start(t.Context(), "request")
+
+	require.NoError(t, vc.Health(ctx))
+
+	status.Store(http.StatusInternalServerError)
+
+	require.Error(t, vc.Health(ctx))
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	ok, failed := spans[0], spans[1]
+
+	assert.Equal(t, "vault GET", ok.Name())
+	assert.Equal(t, trace.SpanKindClient, ok.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), ok.Parent().SpanID())
+	assert.Contains(t, ok.Attributes(), semconv.URLPath("/v1/sys/health"))
+	assert.Contains(t, ok.Attributes(), semconv.HTTPResponseStatusCode(http.StatusOK))
+	assert.Equal(t, codes.Unset, ok.Status().Code)
+
+	assert.Contains(t, failed.Attributes(), semconv.HTTPResponseStatusCode(http.StatusInternalServerError))
+	assert.Equal(t, codes.Error, failed.Status().Code)
+
+	// Vault получает контекст трассировки спана запроса
+	assert.Equal(t, "00-"+failed.SpanContext().TraceID().String()+"-"+failed.SpanContext().SpanID().String()+"-01", traceparent.Load())
+}
@@ -0,0 +1,28 @@
+// Package authclient - клиентская библиотека для сервисов, которые проверяют токены auth-service
+// и других сервисов bot-zanuda.
+//
+// Claims - claims пользователя в токене auth-service с типизированными полями. Сервис кладет их
+// в контекст запроса (NewContext), обработчики получают их через ClaimsFromContext.
+//
+// JWKS кэширует открытые ключи, загруженные по адресу JWKS, для проверки токенов, которые peer
+// сервисы bot-zanuda выпускают асимметричными ключами для вызовов между сервисами. auth-service
+// сам JWKS не публикует: токены пользователей подписаны HS256 ключами из Vault KV и проверяются
+// через POST /api/v0/token/introspect или пакетом проверки (Bundle). Ключи обновляются в фоне (Start),
+// токен с неизвестным kid вызывает внеочередную загрузку, а при недоступности JWKS продолжают
+// действовать ранее загруженные ключи, пока они не старше MaxStale. Так кратковременная
+// недоступность peer сервиса не мешает проверять его токены.
+//
+//	keys, err := authclient.NewJWKS("https://notes.zanuda.example/.well-known/jwks.json")
+//	if err != nil {
+//		return err
+//	}
+//
+//	go keys.Start(ctx)
+//
+//	token, err := jwt.Parse(raw, keys.Keyfunc(ctx),
+//		jwt.WithValidMethods([]string{"ES256", "RS256"}),
+//		jwt.WithIssuer("https://notes.zanuda.example"),
+//		jwt.WithAudience("auth-service"),
+//		jwt.WithExpirationRequired(),
+//	)
+package authclient
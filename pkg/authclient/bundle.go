@@ -0,0 +1,88 @@
+package authclient
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// BundleType - значение заголовка typ пакета проверки.
+const BundleType = "auth-bundle+jwt"
+
+// ErrInvalidBundle - пакет проверки поврежден, подписан неизвестным ключом или истек.
+var ErrInvalidBundle = errors.New("invalid verification bundle")
+
+// Bundle - пакет для проверки токенов без обращения к auth-service: ключи, которыми принимаются токены,
+// отметки об отзыве и ожидаемые iss и aud на момент выгрузки. Пакет подписан текущим ключом подписи
+// и действует до exp: после этого сервис должен загрузить новый, иначе он не узнает о новых отзывах.
+//
+//	bundle, err := authclient.ParseBundle(raw, keyfunc)
+//	if err != nil {
+//		return err
+//	}
+//
+//	if !bundle.Trusted(kid) || bundle.Revoked(jti, claims.UserID, issuedAt) {
+//		return errForbidden
+//	}
+type Bundle struct {
+	jwt.RegisteredClaims
+
+	// TokenIssuer - значение iss в токенах. Пусто - iss не проверяется.
+	TokenIssuer string `json:"token_iss,omitempty"`
+	// Audiences - аудитории, для которых выпускаются токены.
+	Audiences []string `json:"audiences,omitempty"`
+	// Kids - kid ключей, которыми принимаются токены. Сами ключи в пакет не входят.
+	Kids []string `json:"kids"`
+	// RevokedBefore - субъект -> unix time, до которого (включительно) отозваны его токены.
+	RevokedBefore map[string]int64 `json:"revoked_before,omitempty"`
+	// RevokedTokens - jti отозванного токена -> unix time истечения токена.
+	RevokedTokens map[string]int64 `json:"revoked_tokens,omitempty"`
+	// Deactivated - отключенные пользователи: их токены не принимаются, когда бы ни были выпущены.
+	Deactivated []string `json:"deactivated,omitempty"`
+}
+
+// ParseBundle проверяет подпись и срок действия пакета и возвращает его. keyfunc выбирает ключ по kid пакета.
+func ParseBundle(raw string, keyfunc jwt.Keyfunc) (*Bundle, error) {
+	bundle := &Bundle{}
+
+	token, err := jwt.ParseWithClaims(raw, bundle, keyfunc,
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+	}
+
+	if typ, _ := token.Header["typ"].(string); typ != BundleType {
+		return nil, fmt.Errorf("%w: unexpected typ %q", ErrInvalidBundle, typ)
+	}
+
+	return bundle, nil
+}
+
+// Trusted возвращает true, если токены с этим kid принимаются.
+func (b *Bundle) Trusted(kid string) bool {
+	return slices.Contains(b.Kids, kid)
+}
+
+// Revoked возвращает true, если токен с этим jti отозван сам по себе или как токен субъекта,
+// выпущенный в issuedAt. Токен без iat (нулевое время) считается выпущенным до отзыва.
+func (b *Bundle) Revoked(jti, subject string, issuedAt time.Time) bool {
+	if _, ok := b.RevokedTokens[jti]; ok && jti != "" {
+		return true
+	}
+
+	if slices.Contains(b.Deactivated, subject) {
+		return true
+	}
+
+	revokedBefore, ok := b.RevokedBefore[subject]
+	if !ok {
+		return false
+	}
+
+	return issuedAt.Unix() <= revokedBefore
+}
@@ -0,0 +1,104 @@
+package authclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signBundle(t *testing.T, b *Bundle, typ string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, b)
+	token.Header["kid"] = "key-1"
+	token.Header["typ"] = typ
+
+	raw, err := token.SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	return raw
+}
+
+func TestParseBundle(t *testing.T) {
+	t.Parallel()
+
+	keyfunc := func(*jwt.Token) (interface{}, error) { return []byte("secret"), nil }
+	valid := &Bundle{
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+		Kids:             []string{"key-1"},
+	}
+
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			raw:     signBundle(t, valid, BundleType),
+			wantErr: require.NoError,
+		},
+		{
+			name: "error case: expired",
+			raw: signBundle(t, &Bundle{
+				RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))},
+			}, BundleType),
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: no expiration",
+			raw:     signBundle(t, &Bundle{}, BundleType),
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: not a bundle",
+			raw:     signBundle(t, valid, "JWT"),
+			wantErr: require.Error,
+		},
+		{
+			name:    "error case: malformed",
+			raw:     "bundle",
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParseBundle(tt.raw, keyfunc)
+			tt.wantErr(t, err)
+		})
+	}
+
+	_, err := ParseBundle(signBundle(t, valid, BundleType), func(*jwt.Token) (interface{}, error) {
+		return []byte("other"), nil
+	})
+	require.ErrorIs(t, err, ErrInvalidBundle)
+}
+
+func TestBundle_Revoked(t *testing.T) {
+	t.Parallel()
+
+	b := &Bundle{
+		Kids:          []string{"key-1"},
+		RevokedBefore: map[string]int64{"user-1": 100},
+		RevokedTokens: map[string]int64{"token-1": 200},
+		Deactivated:   []string{"user-2"},
+	}
+
+	assert.True(t, b.Trusted("key-1"))
+	assert.False(t, b.Trusted("key-2"))
+
+	assert.True(t, b.Revoked("", "user-1", time.Unix(100, 0)))
+	assert.False(t, b.Revoked("", "user-1", time.Unix(101, 0)))
+	assert.True(t, b.Revoked("", "user-2", time.Now()))
+	assert.False(t, b.Revoked("", "user-3", time.Unix(1, 0)))
+
+	// отозванный по jti токен отклоняется, другие токены субъекта - нет
+	assert.True(t, b.Revoked("token-1", "user-3", time.Unix(1, 0)))
+	assert.False(t, b.Revoked("token-2", "user-3", time.Unix(1, 0)))
+}
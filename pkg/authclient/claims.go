@@ -0,0 +1,103 @@
+package authclient
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// Claims - claims пользователя в токене auth-service.
+type Claims struct {
+	// UserID - идентификатор пользователя (sub).
+	UserID string
+	// TelegramID - идентификатор пользователя в Telegram (tg_id), 0 - не задан.
+	TelegramID int64
+	// Roles - роли пользователя (roles).
+	Roles []string
+	// Scopes - разрешения токена (scope, через пробел).
+	Scopes []string
+	// SessionID - идентификатор сессии входа (sid).
+	SessionID string
+	// AuthLevel - уровень аутентификации (acr).
+	AuthLevel string
+	// Tenant - организация пользователя (tenant).
+	Tenant string
+}
+
+// claimsJSON - представление Claims в токене.
+type claimsJSON struct {
+	Subject    string   `json:"sub,omitempty"`
+	TelegramID int64    `json:"tg_id,omitempty"`
+	Roles      []string `json:"roles,omitempty"`
+	Scope      string   `json:"scope,omitempty"`
+	SessionID  string   `json:"sid,omitempty"`
+	AuthLevel  string   `json:"acr,omitempty"`
+	Tenant     string   `json:"tenant,omitempty"`
+}
+
+// MarshalJSON сериализует claims с именами, которые используются в токене.
+func (c Claims) MarshalJSON() ([]byte, error) {
+	return json.Marshal(claimsJSON{
+		Subject:    c.UserID,
+		TelegramID: c.TelegramID,
+		Roles:      c.Roles,
+		Scope:      strings.Join(c.Scopes, " "),
+		SessionID:  c.SessionID,
+		AuthLevel:  c.AuthLevel,
+		Tenant:     c.Tenant,
+	})
+}
+
+// UnmarshalJSON разбирает claims из payload токена. Остальные claims пропускаются.
+func (c *Claims) UnmarshalJSON(data []byte) error {
+	var raw claimsJSON
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*c = Claims{
+		UserID:     raw.Subject,
+		TelegramID: raw.TelegramID,
+		Roles:      raw.Roles,
+		SessionID:  raw.SessionID,
+		AuthLevel:  raw.AuthLevel,
+		Tenant:     raw.Tenant,
+	}
+
+	if raw.Scope != "" {
+		c.Scopes = strings.Fields(raw.Scope)
+	}
+
+	return nil
+}
+
+// HasRole сообщает, есть ли у пользователя роль.
+func (c *Claims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
+
+// HasScope сообщает, выдано ли токену разрешение.
+func (c *Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes, scope)
+}
+
+// Telegram возвращает идентификатор пользователя в Telegram, если он есть в токене.
+func (c *Claims) Telegram() (int64, bool) {
+	return c.TelegramID, c.TelegramID != 0
+}
+
+type claimsKey struct{}
+
+// NewContext возвращает контекст с claims пользователя.
+func NewContext(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext возвращает claims пользователя, сохраненные NewContext.
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+
+	return claims, ok && claims != nil
+}
@@ -0,0 +1,80 @@
+package authclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaims_JSON(t *testing.T) {
+	t.Parallel()
+
+	claims := Claims{
+		UserID:     "user-1",
+		TelegramID: 12345,
+		Roles:      []string{"editor"},
+		Scopes:     []string{"read:notes", "write:notes"},
+		SessionID:  "session-1",
+		AuthLevel:  "mfa",
+		Tenant:     "acme",
+	}
+
+	data, err := json.Marshal(claims)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"sub": "user-1",
+		"tg_id": 12345,
+		"roles": ["editor"],
+		"scope": "read:notes write:notes",
+		"sid": "session-1",
+		"acr": "mfa",
+		"tenant": "acme"
+	}`, string(data))
+
+	var got Claims
+
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, claims, got)
+
+	// остальные claims токена пропускаются, неверный тип - ошибка
+	require.NoError(t, json.Unmarshal([]byte(`{"sub":"user-2","exp":1,"groups":{"g":"owner"}}`), &got))
+	assert.Equal(t, Claims{UserID: "user-2"}, got)
+
+	require.Error(t, json.Unmarshal([]byte(`{"tg_id":"12345"}`), &got))
+}
+
+func TestClaims_Accessors(t *testing.T) {
+	t.Parallel()
+
+	claims := &Claims{Roles: []string{"editor"}, Scopes: []string{"read:notes"}, TelegramID: 12345}
+
+	assert.True(t, claims.HasRole("editor"))
+	assert.False(t, claims.HasRole("admin"))
+	assert.True(t, claims.HasScope("read:notes"))
+	assert.False(t, claims.HasScope("write:notes"))
+
+	id, ok := claims.Telegram()
+	assert.True(t, ok)
+	assert.Equal(t, int64(12345), id)
+
+	_, ok = (&Claims{}).Telegram()
+	assert.False(t, ok)
+}
+
+func TestClaimsFromContext(t *testing.T) {
+	t.Parallel()
+
+	_, ok := ClaimsFromContext(t.Context())
+	assert.False(t, ok)
+
+	_, ok = ClaimsFromContext(NewContext(t.Context(), nil))
+	assert.False(t, ok)
+
+	claims := &Claims{UserID: "user-1"}
+
+	got, ok := ClaimsFromContext(NewContext(t.Context(), claims))
+	require.True(t, ok)
+	assert.Same(t, claims, got)
+}
@@ -0,0 +1,302 @@
+package authclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/sync/singleflight"
+)
+
+// Значения по умолчанию.
+const (
+	DefaultRefreshInterval    = 5 * time.Minute
+	DefaultMinRefetchInterval = 30 * time.Second
+	DefaultMaxStale           = 24 * time.Hour
+	DefaultHTTPTimeout        = 5 * time.Second
+
+	// maxJWKSSize - максимальный размер ответа JWKS.
+	maxJWKSSize = 1 << 20
+)
+
+var (
+	// ErrUnknownKey - в JWKS нет ключа с kid токена.
+	ErrUnknownKey = errors.New("unknown signing key")
+	// ErrStaleKeys - ключи не удается обновить дольше MaxStale.
+	ErrStaleKeys = errors.New("jwks keys are stale")
+)
+
+// JWKS - кэш открытых ключей, загруженных по адресу JWKS.
+type JWKS struct {
+	url    string
+	client *http.Client
+
+	refreshInterval    time.Duration
+	minRefetchInterval time.Duration
+	maxStale           time.Duration
+	onError            func(error)
+	now                func() time.Time
+
+	mu          sync.RWMutex
+	keys        map[string]jose.JSONWebKey
+	fetchedAt   time.Time // последняя успешная загрузка
+	lastRefetch time.Time // последняя внеочередная загрузка
+
+	group singleflight.Group
+}
+
+// JWKSOption - опция для настройки JWKS.
+type JWKSOption func(*JWKS)
+
+// WithHTTPClient устанавливает HTTP клиент для загрузки JWKS. Таймаут запроса задается в клиенте.
+func WithHTTPClient(client *http.Client) JWKSOption {
+	return func(j *JWKS) {
+		j.client = client
+	}
+}
+
+// WithHTTPTimeout устанавливает таймаут запроса JWKS. По умолчанию DefaultHTTPTimeout.
+// Не действует вместе с WithHTTPClient.
+func WithHTTPTimeout(timeout time.Duration) JWKSOption {
+	return func(j *JWKS) {
+		j.client = &http.Client{Timeout: timeout}
+	}
+}
+
+// WithRefreshInterval устанавливает периодичность фонового обновления ключей. По умолчанию DefaultRefreshInterval.
+func WithRefreshInterval(interval time.Duration) JWKSOption {
+	return func(j *JWKS) {
+		j.refreshInterval = interval
+	}
+}
+
+// WithMinRefetchInterval устанавливает, как часто токены с неизвестным kid могут вызывать
+// загрузку JWKS. По умолчанию DefaultMinRefetchInterval.
+func WithMinRefetchInterval(interval time.Duration) JWKSOption {
+	return func(j *JWKS) {
+		j.minRefetchInterval = interval
+	}
+}
+
+// WithMaxStale устанавливает, сколько действуют ранее загруженные ключи, если JWKS недоступен.
+// По умолчанию DefaultMaxStale.
+func WithMaxStale(maxStale time.Duration) JWKSOption {
+	return func(j *JWKS) {
+		j.maxStale = maxStale
+	}
+}
+
+// WithErrorHandler устанавливает обработчик ошибок фонового обновления, например для логирования.
+func WithErrorHandler(handler func(error)) JWKSOption {
+	return func(j *JWKS) {
+		j.onError = handler
+	}
+}
+
+// NewJWKS создает кэш ключей JWKS по адресу url. Ключи загружаются при первом обращении
+// или в Start, поэтому недоступность JWKS при создании не является ошибкой.
+func NewJWKS(url string, opts ...JWKSOption) (*JWKS, error) {
+	j := &JWKS{
+		url:                url,
+		client:             &http.Client{Timeout: DefaultHTTPTimeout},
+		refreshInterval:    DefaultRefreshInterval,
+		minRefetchInterval: DefaultMinRefetchInterval,
+		maxStale:           DefaultMaxStale,
+		onError:            func(error) {},
+		now:                time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	if err := j.validate(); err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+func (j *JWKS) validate() error {
+	if j.url == "" {
+		return errors.New("jwks url is required")
+	}
+
+	if j.client == nil {
+		return errors.New("http client is required")
+	}
+
+	if j.refreshInterval <= 0 || j.minRefetchInterval < 0 {
+		return errors.New("refresh interval must be positive")
+	}
+
+	if j.maxStale < j.refreshInterval {
+		return errors.New("max stale must not be less than refresh interval")
+	}
+
+	if j.onError == nil {
+		return errors.New("error handler is required")
+	}
+
+	return nil
+}
+
+// Start обновляет ключи при запуске и затем каждые RefreshInterval до отмены контекста.
+// Ошибки обновления передаются обработчику ошибок, действующие ключи сохраняются.
+func (j *JWKS) Start(ctx context.Context) error {
+	if err := j.Refresh(ctx); err != nil && ctx.Err() == nil {
+		j.onError(err)
+	}
+
+	ticker := time.NewTicker(j.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := j.Refresh(ctx); err != nil && ctx.Err() == nil {
+				j.onError(err)
+			}
+		}
+	}
+}
+
+// Refresh загружает JWKS и заменяет ключи. Параллельные загрузки объединяются.
+// При ошибке ранее загруженные ключи сохраняются.
+func (j *JWKS) Refresh(ctx context.Context) error {
+	_, err, _ := j.group.Do(j.url, func() (interface{}, error) {
+		keys, err := j.fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		j.mu.Lock()
+		j.keys = keys
+		j.fetchedAt = j.now()
+		j.mu.Unlock()
+
+		return nil, nil //nolint:nilnil // результат не нужен
+	})
+
+	return err
+}
+
+// Key возвращает открытый ключ по kid. Если ключа нет или ключи устарели, JWKS загружается заново,
+// но не чаще MinRefetchInterval. Если ключи не удается обновить дольше MaxStale, возвращает ErrStaleKeys.
+func (j *JWKS) Key(ctx context.Context, kid string) (jose.JSONWebKey, error) {
+	key, found, fresh := j.lookup(kid)
+	if found && fresh {
+		return key, nil
+	}
+
+	// ключи устарели или неизвестен kid - пробуем загрузить JWKS
+	var err error
+
+	if j.refetchAllowed() {
+		err = j.Refresh(ctx)
+	}
+
+	key, found, fresh = j.lookup(kid)
+
+	switch {
+	case !fresh:
+		return jose.JSONWebKey{}, errors.Join(ErrStaleKeys, err)
+	case !found:
+		return jose.JSONWebKey{}, fmt.Errorf("%w: kid %q", ErrUnknownKey, kid)
+	}
+
+	return key, nil
+}
+
+// Keyfunc возвращает jwt.Keyfunc, который выбирает ключ по заголовку kid токена и проверяет,
+// что алгоритм токена совпадает с алгоритмом ключа, если он указан в JWKS.
+func (j *JWKS) Keyfunc(ctx context.Context) jwt.Keyfunc {
+	return func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+
+		key, err := j.Key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+
+		if key.Algorithm != "" && key.Algorithm != t.Method.Alg() {
+			return nil, fmt.Errorf("%w: kid %q is not for %s", ErrUnknownKey, kid, t.Method.Alg())
+		}
+
+		return key.Key, nil
+	}
+}
+
+// lookup ищет ключ по kid и сообщает, не устарели ли ключи.
+func (j *JWKS) lookup(kid string) (jose.JSONWebKey, bool, bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	key, found := j.keys[kid]
+	fresh := !j.fetchedAt.IsZero() && j.now().Sub(j.fetchedAt) <= j.maxStale
+
+	return key, found, fresh
+}
+
+// refetchAllowed сообщает, можно ли загрузить JWKS из-за неизвестного kid, и учитывает попытку.
+func (j *JWKS) refetchAllowed() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := j.now()
+	if now.Sub(j.lastRefetch) < j.minRefetchInterval {
+		return false
+	}
+
+	j.lastRefetch = now
+
+	return true
+}
+
+// fetch загружает JWKS. Ключи без kid, закрытые и не предназначенные для подписи пропускаются.
+func (j *JWKS) fetch(ctx context.Context) (map[string]jose.JSONWebKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("authclient: error create jwks request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("authclient: error get jwks: %w", err)
+	}
+
+	defer resp.Body.Close() //nolint:errcheck // тело прочитано
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("authclient: error get jwks: unexpected status %d", resp.StatusCode)
+	}
+
+	var set jose.JSONWebKeySet
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxJWKSSize)).Decode(&set); err != nil {
+		return nil, fmt.Errorf("authclient: error decode jwks: %w", err)
+	}
+
+	keys := make(map[string]jose.JSONWebKey, len(set.Keys))
+
+	for _, key := range set.Keys {
+		if key.KeyID == "" || !key.IsPublic() || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+
+		keys[key.KeyID] = key
+	}
+
+	return keys, nil
+}
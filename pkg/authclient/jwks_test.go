@@ -0,0 +1,295 @@
+package authclient
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jwksServer - JWKS, который можно выключить и на котором можно сменить ключи.
+type jwksServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	keys     []jose.JSONWebKey
+	down     bool
+	delay    time.Duration
+	requests atomic.Int32
+}
+
+func newJWKSServer(t *testing.T, keys ...jose.JSONWebKey) *jwksServer {
+	t.Helper()
+
+	s := &jwksServer{keys: keys}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		s.requests.Add(1)
+
+		s.mu.Lock()
+		keys, down, delay := s.keys, s.down, s.delay
+		s.mu.Unlock()
+
+		time.Sleep(delay)
+
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: keys})
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *jwksServer) set(down bool, keys ...jose.JSONWebKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.down = down
+	if keys != nil {
+		s.keys = keys
+	}
+}
+
+func newKey(t *testing.T, kid string) (*rsa.PrivateKey, jose.JSONWebKey) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	return key, jose.JSONWebKey{Key: key.Public(), KeyID: kid, Algorithm: "RS256", Use: "sig"}
+}
+
+// fakeClock - управляемое время.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func newJWKS(t *testing.T, url string, opts ...JWKSOption) (*JWKS, *fakeClock) {
+	t.Helper()
+
+	j, err := NewJWKS(url, opts...)
+	require.NoError(t, err)
+
+	clock := &fakeClock{now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	j.now = clock.Now
+
+	return j, clock
+}
+
+func TestNewJWKS(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		url     string
+		opts    []JWKSOption
+		wantErr bool
+	}{
+		{
+			name: "positive case",
+			url:  "https://auth.example.com/.well-known/jwks.json",
+			opts: []JWKSOption{WithHTTPTimeout(time.Second), WithRefreshInterval(time.Minute), WithMaxStale(time.Hour)},
+		},
+		{
+			name:    "error case: no url",
+			wantErr: true,
+		},
+		{
+			name:    "error case: max stale less than refresh interval",
+			url:     "https://auth.example.com/.well-known/jwks.json",
+			opts:    []JWKSOption{WithRefreshInterval(time.Hour), WithMaxStale(time.Minute)},
+			wantErr: true,
+		},
+		{
+			name:    "error case: no http client",
+			url:     "https://auth.example.com/.well-known/jwks.json",
+			opts:    []JWKSOption{WithHTTPClient(nil)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewJWKS(tt.url, tt.opts...)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestJWKS_Key(t *testing.T) {
+	t.Parallel()
+
+	_, first := newKey(t, "key-1")
+	_, second := newKey(t, "key-2")
+
+	server := newJWKSServer(t, first)
+	j, clock := newJWKS(t, server.URL, WithMaxStale(time.Hour), WithRefreshInterval(time.Minute))
+
+	// ключи загружаются при первом обращении
+	key, err := j.Key(t.Context(), "key-1")
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", key.KeyID)
+
+	// неизвестный kid вызывает загрузку
+	server.set(false, first, second)
+	clock.Advance(DefaultMinRefetchInterval)
+
+	key, err = j.Key(t.Context(), "key-2")
+	require.NoError(t, err)
+	assert.Equal(t, "key-2", key.KeyID)
+	assert.Equal(t, int32(2), server.requests.Load())
+
+	// но не чаще MinRefetchInterval
+	for range 3 {
+		_, err = j.Key(t.Context(), "key-3")
+		require.ErrorIs(t, err, ErrUnknownKey)
+	}
+
+	assert.Equal(t, int32(2), server.requests.Load())
+
+	// JWKS недоступен - действуют ранее загруженные ключи
+	server.set(true)
+	clock.Advance(30 * time.Minute)
+	require.Error(t, j.Refresh(t.Context()))
+
+	_, err = j.Key(t.Context(), "key-1")
+	require.NoError(t, err)
+
+	// ключи старше MaxStale не используются
+	clock.Advance(time.Hour)
+
+	_, err = j.Key(t.Context(), "key-1")
+	require.ErrorIs(t, err, ErrStaleKeys)
+
+	// JWKS снова доступен
+	server.set(false)
+	clock.Advance(DefaultMinRefetchInterval)
+
+	_, err = j.Key(t.Context(), "key-1")
+	require.NoError(t, err)
+}
+
+func TestJWKS_Timeout(t *testing.T) {
+	t.Parallel()
+
+	_, key := newKey(t, "key-1")
+
+	server := newJWKSServer(t, key)
+	server.delay = 200 * time.Millisecond
+
+	j, _ := newJWKS(t, server.URL, WithHTTPTimeout(20*time.Millisecond))
+
+	start := time.Now()
+
+	_, err := j.Key(t.Context(), "key-1")
+	require.ErrorIs(t, err, ErrStaleKeys)
+	assert.Less(t, time.Since(start), 200*time.Millisecond)
+}
+
+func TestJWKS_Keyfunc(t *testing.T) {
+	t.Parallel()
+
+	private, key := newKey(t, "key-1")
+
+	server := newJWKSServer(t, key)
+	j, _ := newJWKS(t, server.URL)
+
+	sign := func(method jwt.SigningMethod) string {
+		token := jwt.NewWithClaims(method, jwt.RegisteredClaims{Subject: "user-1"})
+		token.Header["kid"] = "key-1"
+
+		raw, err := token.SignedString(private)
+		require.NoError(t, err)
+
+		return raw
+	}
+
+	token, err := jwt.Parse(sign(jwt.SigningMethodRS256), j.Keyfunc(t.Context()))
+	require.NoError(t, err)
+
+	subject, err := token.Claims.GetSubject()
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", subject)
+
+	// алгоритм токена не совпадает с алгоритмом ключа
+	_, err = jwt.Parse(sign(jwt.SigningMethodPS256), j.Keyfunc(t.Context()))
+	require.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestJWKS_Start(t *testing.T) {
+	t.Parallel()
+
+	_, key := newKey(t, "key-1")
+
+	server := newJWKSServer(t, key)
+	server.set(true)
+
+	errs := make(chan error, 1)
+
+	j, err := NewJWKS(server.URL,
+		WithRefreshInterval(10*time.Millisecond),
+		WithMinRefetchInterval(time.Hour),
+		WithErrorHandler(func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		}),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+
+	go func() { done <- j.Start(ctx) }()
+
+	// ошибки фонового обновления передаются обработчику
+	require.Error(t, <-errs)
+
+	server.set(false)
+
+	assert.Eventually(t, func() bool {
+		_, err := j.Key(t.Context(), "key-1")
+
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}